@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/config"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/server"
+)
+
+func main() {
+	defaultAddr := os.Getenv("ACADEMIC_MCP_HTTP_ADDR")
+	if defaultAddr == "" {
+		defaultAddr = "localhost:8080"
+	}
+	addr := flag.String("addr", defaultAddr, "address to listen on")
+	flag.Parse()
+
+	// Initialize logger with default configuration
+	log, err := logger.NewLogger(logger.LogConfig{})
+	if err != nil {
+		// Fall back to stderr if logger initialization fails
+		panic(err)
+	}
+
+	log.Info("Starting academic-mcp HTTP server")
+
+	authToken := os.Getenv("ACADEMIC_MCP_AUTH_TOKEN")
+	if authToken == "" {
+		log.Warn("ACADEMIC_MCP_AUTH_TOKEN not set; the server accepts unauthenticated requests")
+	}
+
+	// Credentials are read once and reloaded on SIGHUP or server-reload-config
+	credentials, err := config.ConfiguredProvider()
+	if err != nil {
+		log.Warn("Using credentials from the environment only: %v", err)
+	}
+
+	store, err := server.InitializeStorage(log)
+	if err != nil {
+		log.Fatal("Failed to initialize storage: %v", err)
+	}
+	defer func() {
+		if err := store.Close(); err != nil {
+			log.Error("Failed to close storage: %v", err)
+		}
+	}()
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		store.Close()
+		log.Fatal("Failed to listen on %s: %v", *addr, err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	config.ReloadOnHangup(ctx, credentials, log)
+
+	handler := server.NewHTTPHandler(server.NewServer(store, credentials, log), authToken)
+	if err := server.RunHTTP(ctx, listener, handler, log); err != nil {
+		log.Error("Server failed: %v", err)
+	}
+	log.Info("academic-mcp HTTP server stopped")
+}
@@ -2,8 +2,13 @@ package main
 
 import (
 	"context"
+	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/Epistemic-Technology/academic-mcp/internal/config"
 	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
 	"github.com/Epistemic-Technology/academic-mcp/server"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -16,11 +21,48 @@ func main() {
 		panic(err)
 	}
 
+	// encrypt-db encrypts an existing plaintext database with ACADEMIC_MCP_DB_KEY
+	if len(os.Args) > 1 && os.Args[1] == "encrypt-db" {
+		encryptDatabase(log)
+		return
+	}
+
 	log.Info("Starting academic-mcp server")
 
-	srv := server.CreateServer(log)
-	err = srv.Run(context.Background(), &mcp.StdioTransport{})
+	// Credentials are read once and reloaded on SIGHUP or server-reload-config
+	credentials, err := config.ConfiguredProvider()
+	if err != nil {
+		log.Warn("Using credentials from the environment only: %v", err)
+	}
+
+	store, err := server.InitializeStorage(log)
+	if err != nil {
+		log.Fatal("Failed to initialize storage: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	config.ReloadOnHangup(ctx, credentials, log)
+
+	// RunStdio closes the store when it returns
+	err = server.RunStdio(ctx, server.NewServer(store, credentials, log), &mcp.StdioTransport{}, store, log)
 	if err != nil {
 		log.Fatal("Server failed: %v", err)
 	}
+	log.Info("academic-mcp server stopped")
+}
+
+// encryptDatabase encrypts the content of the database at ACADEMIC_MCP_DB_PATH
+// (or the default path) with ACADEMIC_MCP_DB_KEY. The server must be stopped.
+func encryptDatabase(log logger.Logger) {
+	dbPath, err := storage.DatabasePath()
+	if err != nil {
+		log.Fatal("Failed to find the database: %v", err)
+	}
+	log.Info("Encrypting database at: %s", dbPath)
+	count, err := storage.EncryptDatabase(context.Background(), dbPath, storage.DatabaseKey(), log)
+	if err != nil {
+		log.Fatal("Failed to encrypt database: %v", err)
+	}
+	log.Info("Encrypted %d values; start the server with the same ACADEMIC_MCP_DB_KEY", count)
 }
@@ -0,0 +1,34 @@
+package citations
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// arXivPathPattern matches the path of an arXiv abstract or PDF page: a new
+// style identifier (2101.01234) or an old style one (hep-th/9901001), with an
+// optional version and, for PDFs, ".pdf"
+var arXivPathPattern = regexp.MustCompile(`^/(abs|pdf)/(\d{4}\.\d{4,5}|[a-z\-]+(\.[A-Z]{2})?/\d{7})(v\d+)?(\.pdf)?/?$`)
+
+// arXivHosts are the hosts serving arXiv abstract and PDF pages
+var arXivHosts = map[string]bool{
+	"arxiv.org":        true,
+	"www.arxiv.org":    true,
+	"export.arxiv.org": true,
+}
+
+// ParseArXivURL returns the arXiv identifier of an arXiv abstract or PDF URL
+// (e.g., "2101.01234v2" for https://arxiv.org/pdf/2101.01234v2.pdf), with its
+// version if the URL names one. It reports false for other URLs.
+func ParseArXivURL(rawURL string) (string, bool) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || !arXivHosts[strings.ToLower(u.Host)] {
+		return "", false
+	}
+	match := arXivPathPattern.FindStringSubmatch(u.Path)
+	if match == nil {
+		return "", false
+	}
+	return match[2] + match[4], true
+}
@@ -0,0 +1,34 @@
+package citations
+
+import "testing"
+
+func TestParseArXivURL(t *testing.T) {
+	tests := []struct {
+		url   string
+		want  string
+		valid bool
+	}{
+		{"https://arxiv.org/abs/2101.01234", "2101.01234", true},
+		{"https://arxiv.org/abs/2101.01234v2", "2101.01234v2", true},
+		{"https://arxiv.org/pdf/2101.01234", "2101.01234", true},
+		{"https://arxiv.org/pdf/2101.01234v2.pdf", "2101.01234v2", true},
+		{"http://www.arxiv.org/abs/1706.03762?context=cs", "1706.03762", true},
+		{"https://export.arxiv.org/abs/0704.0001v1", "0704.0001v1", true},
+		{"https://arxiv.org/abs/hep-th/9901001", "hep-th/9901001", true},
+		{"https://arxiv.org/pdf/math.GT/0309136v1", "math.GT/0309136v1", true},
+		{" https://ArXiv.org/abs/2101.01234/ ", "2101.01234", true},
+		{"https://arxiv.org/list/cs.CL/recent", "", false},
+		{"https://arxiv.org/abs/", "", false},
+		{"https://arxiv.org/html/2101.01234v2", "", false},
+		{"https://example.com/abs/2101.01234", "", false},
+		{"ftp://arxiv.org/abs/2101.01234", "", false},
+		{"arXiv:2101.01234", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseArXivURL(tt.url)
+		if got != tt.want || ok != tt.valid {
+			t.Errorf("ParseArXivURL(%q) = %q, %v; want %q, %v", tt.url, got, ok, tt.want, tt.valid)
+		}
+	}
+}
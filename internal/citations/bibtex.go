@@ -30,7 +30,7 @@ func GenerateBibTeXEntry(docID string, metadata *models.ItemMetadata, citekey st
 
 	// Authors
 	if len(metadata.Authors) > 0 {
-		authorsStr := formatBibTeXAuthors(metadata.Authors)
+		authorsStr := formatBibTeXAuthors(metadata)
 		builder.WriteString(fmt.Sprintf("  author = {%s},\n", authorsStr))
 	}
 
@@ -42,7 +42,7 @@ func GenerateBibTeXEntry(docID string, metadata *models.ItemMetadata, citekey st
 
 	// Year
 	if metadata.PublicationDate != "" {
-		year := extractYear(metadata.PublicationDate)
+		year := ExtractYear(metadata.PublicationDate)
 		if year != "" {
 			builder.WriteString(fmt.Sprintf("  year = {%s},\n", year))
 		}
@@ -88,6 +88,11 @@ func GenerateBibTeXEntry(docID string, metadata *models.ItemMetadata, citekey st
 		builder.WriteString(fmt.Sprintf("  url = {%s},\n", metadata.URL))
 	}
 
+	// Keywords, comma-separated as biblatex expects
+	if len(metadata.Keywords) > 0 {
+		builder.WriteString(fmt.Sprintf("  keywords = {%s},\n", escapeBibTeX(strings.Join(metadata.Keywords, ", "))))
+	}
+
 	// Abstract (optional, but useful)
 	if metadata.Abstract != "" {
 		builder.WriteString(fmt.Sprintf("  abstract = {%s},\n", escapeBibTeX(metadata.Abstract)))
@@ -153,10 +158,16 @@ func getPublicationFieldName(entryType string) string {
 // formatBibTeXAuthors formats an author list for BibTeX
 // BibTeX format: "Last1, First1 and Last2, First2 and Last3, First3"
 // We receive authors in various formats, so we need to normalize
-func formatBibTeXAuthors(authors []string) string {
+// Organizations are written in braces ("{The LIGO Scientific Collaboration}")
+// so BibTeX does not split their names into given and family names.
+func formatBibTeXAuthors(metadata *models.ItemMetadata) string {
 	var formattedAuthors []string
 
-	for _, author := range authors {
+	for _, author := range metadata.Authors {
+		if isCorporate(metadata, author) {
+			formattedAuthors = append(formattedAuthors, "{"+escapeBibTeX(CorporateName(author))+"}")
+			continue
+		}
 		// Try to parse and reformat if needed
 		// If already in "Last, First" format, keep it
 		// If in "First Last" format, convert it
@@ -234,3 +245,90 @@ func GenerateBibTeXFile(entries []string) string {
 
 	return builder.String()
 }
+
+// bibTeXNonEntries are the @-commands that do not define a citable entry
+var bibTeXNonEntries = map[string]bool{"comment": true, "string": true, "preamble": true}
+
+// ScanBibTeXKeys returns the citekeys of the entries in a .bib file, in file
+// order. It reads only what identifies an entry, @type{key, or @type(key, and
+// skips each entry's body by matching its delimiters, so braces and
+// parentheses in field values do not end it early. Text outside entries,
+// @comment, @string, and @preamble are ignored. A truncated last entry still
+// yields its key.
+func ScanBibTeXKeys(content string) []string {
+	var keys []string
+	for i := 0; i < len(content); {
+		at := strings.IndexByte(content[i:], '@')
+		if at < 0 {
+			break
+		}
+		i += at + 1
+
+		// Entry type
+		start := i
+		for i < len(content) && isBibTeXNameChar(content[i]) {
+			i++
+		}
+		entryType := strings.ToLower(content[start:i])
+		for i < len(content) && isBibTeXSpace(content[i]) {
+			i++
+		}
+		if entryType == "" || i >= len(content) || (content[i] != '{' && content[i] != '(') {
+			continue
+		}
+		open := content[i]
+		bodyStart := i + 1
+		i = skipBibTeXBody(content, i)
+
+		if bibTeXNonEntries[entryType] {
+			continue
+		}
+		// The key runs to the first comma, whitespace, or closing delimiter
+		key := strings.TrimLeft(content[bodyStart:i], " \t\r\n")
+		stop := ", \t\r\n{}"
+		if open == '(' {
+			stop += ")"
+		}
+		if end := strings.IndexAny(key, stop); end >= 0 {
+			key = key[:end]
+		}
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// skipBibTeXBody returns the index just past the delimiter closing the entry
+// body that opens at content[open], or len(content) if it is never closed.
+// Braces nest inside either kind of body; a body opened with a parenthesis
+// ends at the first closing parenthesis outside braces.
+func skipBibTeXBody(content string, open int) int {
+	depth := 0
+	for i := open + 1; i < len(content); i++ {
+		switch content[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 && content[open] == '{' {
+				return i + 1
+			}
+			if depth > 0 {
+				depth--
+			}
+		case ')':
+			if depth == 0 && content[open] == '(' {
+				return i + 1
+			}
+		}
+	}
+	return len(content)
+}
+
+func isBibTeXNameChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+func isBibTeXSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
@@ -1,6 +1,7 @@
 package citations
 
 import (
+	"slices"
 	"strings"
 	"testing"
 
@@ -29,6 +30,7 @@ func TestGenerateBibTeXEntry(t *testing.T) {
 				Issue:           "5",
 				Pages:           "123-130",
 				Abstract:        "This paper explores machine learning applications.",
+				Keywords:        []string{"climate modelling", "R&D"},
 			},
 			citekey: "smithDoe2020",
 			want: []string{
@@ -41,6 +43,7 @@ func TestGenerateBibTeXEntry(t *testing.T) {
 				"number = {5}",
 				"pages = {123--130}",
 				"doi = {10.1038/s41558-020-0000-0}",
+				"keywords = {climate modelling, R\\&D}",
 			},
 		},
 		{
@@ -220,11 +223,26 @@ func TestFormatBibTeXAuthors(t *testing.T) {
 			authors: []string{"Smith"},
 			want:    "Smith",
 		},
+		{
+			name:    "organization kept whole",
+			authors: []string{"The LIGO Scientific Collaboration"},
+			want:    "{The LIGO Scientific Collaboration}",
+		},
+		{
+			name:    "organization with a comma",
+			authors: []string{"Smith, John", "University of California, Berkeley"},
+			want:    "Smith, John and {University of California, Berkeley}",
+		},
+		{
+			name:    "organization already braced",
+			authors: []string{"{Research & Development Group}"},
+			want:    "{Research \\& Development Group}",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := formatBibTeXAuthors(tt.authors)
+			got := formatBibTeXAuthors(&models.ItemMetadata{Authors: tt.authors})
 			if got != tt.want {
 				t.Errorf("formatBibTeXAuthors(%v) = %q, want %q", tt.authors, got, tt.want)
 			}
@@ -298,3 +316,55 @@ func TestGenerateBibTeXFile(t *testing.T) {
 		}
 	}
 }
+
+func TestScanBibTeXKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "generated file",
+			content: GenerateBibTeXFile([]string{"@article{smith2020,\n  title = {First Paper}\n}\n", "@book{doe2021,\n  title = {Second Book}\n}\n"}),
+			want:    []string{"smith2020", "doe2021"},
+		},
+		{
+			name:    "nested braces and @ in values",
+			content: "@article{a1,\n  title = {The {DNA} of {\\it {Nested}} @ work},\n  note = {email me@example.org}\n}\n@Misc{b2, title={}}",
+			want:    []string{"a1", "b2"},
+		},
+		{
+			name:    "parenthesized entries and spacing",
+			content: "@book( paren2019 ,\n  title = {Chapter (One)}\n)\n@inproceedings {spaced:2020,\n}",
+			want:    []string{"paren2019", "spaced:2020"},
+		},
+		{
+			name:    "non-entries ignored",
+			content: "@comment{skip, me}\n@string{jnl = {Journal}}\n@preamble{\"\\newcommand\"}\nsome text with an @ sign\n@article{kept,\n}",
+			want:    []string{"kept"},
+		},
+		{
+			name:    "key without fields",
+			content: "@misc{lonely}",
+			want:    []string{"lonely"},
+		},
+		{
+			name:    "truncated last entry",
+			content: "@article{whole,\n}\n@article{cut,\n  title = {Unfinished",
+			want:    []string{"whole", "cut"},
+		},
+		{
+			name:    "empty",
+			content: "% nothing here\n",
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ScanBibTeXKeys(tt.content); !slices.Equal(got, tt.want) {
+				t.Errorf("ScanBibTeXKeys() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
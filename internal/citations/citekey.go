@@ -2,8 +2,10 @@ package citations
 
 import (
 	"regexp"
+	"slices"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/Epistemic-Technology/academic-mcp/models"
 )
@@ -11,12 +13,20 @@ import (
 // GenerateCitekey creates a pandoc-style citekey from metadata.
 // Format: author(s)Year (e.g., "smith2020", "smithJones2021", "smithEtAl2020")
 // If a collision is detected, appends a letter suffix (a, b, c, etc.)
+// Accented letters are kept; see GenerateCitekeyWithMode.
 func GenerateCitekey(metadata *models.ItemMetadata, existingCitekeys map[string]bool) string {
+	return GenerateCitekeyWithMode(metadata, existingCitekeys, CitekeyUnicode)
+}
+
+// GenerateCitekeyWithMode creates a citekey like GenerateCitekey, with its
+// letters folded to ASCII if mode asks for it ("garcia2020" for García). The
+// folded key is what is checked for collisions.
+func GenerateCitekeyWithMode(metadata *models.ItemMetadata, existingCitekeys map[string]bool, mode CitekeyMode) string {
 	// Extract year from publication date
-	year := extractYear(metadata.PublicationDate)
+	year := ExtractYear(metadata.PublicationDate)
 
 	// Extract author part
-	authorPart := extractAuthorPart(metadata.Authors)
+	authorPart := extractAuthorPart(metadata)
 
 	// Create base citekey
 	baseCitekey := authorPart + year
@@ -27,7 +37,7 @@ func GenerateCitekey(metadata *models.ItemMetadata, existingCitekeys map[string]
 	}
 
 	// Ensure pandoc compatibility (alphanumerics, underscores, internal punctuation)
-	baseCitekey = sanitizeCitekey(baseCitekey)
+	baseCitekey = sanitizeCitekey(baseCitekey, mode)
 
 	// Handle collisions by adding suffix
 	citekey := baseCitekey
@@ -50,9 +60,9 @@ func GenerateCitekey(metadata *models.ItemMetadata, existingCitekeys map[string]
 	return citekey
 }
 
-// extractYear extracts a 4-digit year from a publication date string
+// ExtractYear extracts a 4-digit year from a publication date string.
 // Handles formats like "2020", "2020-01-15", "January 2020", etc.
-func extractYear(pubDate string) string {
+func ExtractYear(pubDate string) string {
 	if pubDate == "" {
 		return ""
 	}
@@ -73,64 +83,113 @@ func extractYear(pubDate string) string {
 // - 1 author: use last name
 // - 2 authors: use both last names (e.g., "smithJones")
 // - 3+ authors: use first author's last name + "EtAl"
-func extractAuthorPart(authors []string) string {
+// Organizations are named by a token of their name instead (see corporateToken).
+func extractAuthorPart(metadata *models.ItemMetadata) string {
+	authors := metadata.Authors
 	if len(authors) == 0 {
 		return ""
 	}
+	name := func(author string) string {
+		if isCorporate(metadata, author) {
+			return corporateToken(author)
+		}
+		return formatAuthorName(author)
+	}
 
 	if len(authors) == 1 {
-		return formatAuthorName(authors[0])
+		return name(authors[0])
 	}
 
 	if len(authors) == 2 {
-		first := formatAuthorName(authors[0])
-		second := formatAuthorName(authors[1])
+		first := name(authors[0])
+		second := name(authors[1])
 		// Capitalize first letter of second author
-		if len(second) > 0 {
-			second = strings.ToUpper(string(second[0])) + second[1:]
-		}
-		return first + second
+		return first + capitalize(second)
 	}
 
 	// 3 or more authors
-	first := formatAuthorName(authors[0])
+	first := name(authors[0])
 	return first + "EtAl"
 }
 
-// formatAuthorName extracts and formats the last name from an author string
-// Handles formats like:
+// isCorporate reports whether an author of metadata is an organization, as its
+// source said (CorporateAuthors) or IsCorporateAuthor finds
+func isCorporate(metadata *models.ItemMetadata, author string) bool {
+	return slices.Contains(metadata.CorporateAuthors, author) || IsCorporateAuthor(author)
+}
+
+// corporateStopWords are the words of an organization's name passed over for
+// its citekey token
+var corporateStopWords = map[string]bool{
+	"the": true, "of": true, "and": true, "for": true, "on": true, "in": true, "at": true,
+	"a": true, "an": true, "to": true, "&": true, "de": true, "des": true, "du": true,
+	"la": true, "le": true, "der": true, "die": true, "für": true, "y": true,
+}
+
+// corporateToken creates the citekey portion for an organization's name:
+//   - An acronym in the name: "The LIGO Scientific Collaboration" -> "ligo",
+//     "World Health Organization (WHO)" -> "who"
+//   - A name of three or more words with one of corporateWords: the initials of
+//     its words, "Intergovernmental Panel on Climate Change" -> "ipcc"
+//   - Otherwise its first word that is not one of corporateWords:
+//     "University of Chicago" -> "chicago", "Google DeepMind" -> "google"
+func corporateToken(author string) string {
+	var words []string
+	for _, word := range strings.Fields(CorporateName(author)) {
+		word = strings.TrimFunc(word, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+		if word != "" && !corporateStopWords[strings.ToLower(word)] {
+			words = append(words, word)
+		}
+	}
+	for _, word := range words {
+		if acronymPattern.MatchString(word) {
+			return strings.ToLower(word)
+		}
+	}
+
+	corporate := false
+	for _, word := range words {
+		corporate = corporate || corporateWords[strings.ToLower(word)]
+	}
+	if corporate && len(words) >= 3 {
+		var initials strings.Builder
+		for _, word := range words {
+			r, _ := utf8.DecodeRuneInString(word)
+			initials.WriteRune(unicode.ToLower(r))
+		}
+		return initials.String()
+	}
+	for _, word := range words {
+		if !corporateWords[strings.ToLower(word)] {
+			return strings.ToLower(word)
+		}
+	}
+	if len(words) > 0 {
+		return strings.ToLower(words[0])
+	}
+	return ""
+}
+
+// formatAuthorName extracts and formats the family name from an author string
+// (see ParseAuthor). Handles formats like:
 // - "Smith, John" -> "smith"
 // - "John Smith" -> "smith"
 // - "Smith" -> "smith"
-// - "von Neumann, John" -> "vonNeumann"
+// - "von Neumann, John" or "John von Neumann" -> "vonNeumann"
+// - "Martin Luther King Jr." -> "king"
 func formatAuthorName(author string) string {
-	if author == "" {
+	lastName := ParseAuthor(author).Family
+	if lastName == "" {
 		return ""
 	}
 
-	var lastName string
-
-	// Check if comma-separated (Last, First)
-	if strings.Contains(author, ",") {
-		parts := strings.Split(author, ",")
-		lastName = strings.TrimSpace(parts[0])
-	} else {
-		// Assume "First Last" or just "Last"
-		parts := strings.Fields(author)
-		if len(parts) > 0 {
-			lastName = parts[len(parts)-1]
-		}
-	}
-
 	// Handle multi-part last names (e.g., "von Neumann" -> "vonNeumann")
 	if strings.Contains(lastName, " ") {
 		parts := strings.Fields(lastName)
 		// Lowercase first part, capitalize subsequent parts
 		result := strings.ToLower(parts[0])
 		for i := 1; i < len(parts); i++ {
-			if len(parts[i]) > 0 {
-				result += strings.ToUpper(string(parts[i][0])) + strings.ToLower(parts[i][1:])
-			}
+			result += capitalize(strings.ToLower(parts[i]))
 		}
 		return result
 	}
@@ -139,13 +198,23 @@ func formatAuthorName(author string) string {
 	return strings.ToLower(lastName)
 }
 
+// capitalize uppercases the first letter of s
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if size == 0 {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
 // sanitizeCitekey ensures the citekey is pandoc-compatible
 // Pandoc allows: alphanumerics, underscores, and internal punctuation
 // We'll be conservative and only allow: letters, digits, underscores
-func sanitizeCitekey(citekey string) string {
+// In the ASCII modes, letters are folded to ASCII first (see foldCitekey).
+func sanitizeCitekey(citekey string, mode CitekeyMode) string {
 	var result strings.Builder
 
-	for _, r := range citekey {
+	for _, r := range foldCitekey(citekey, mode) {
 		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
 			result.WriteRune(r)
 		}
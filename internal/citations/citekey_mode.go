@@ -0,0 +1,114 @@
+package citations
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// citekeyModeEnv names the environment variable that sets the CitekeyMode
+const citekeyModeEnv = "ACADEMIC_MCP_CITEKEY_MODE"
+
+// CitekeyMode controls which characters generated citekeys may contain
+type CitekeyMode string
+
+const (
+	// CitekeyUnicode keeps letters as they are, accents included ("garcía2020";
+	// the default)
+	CitekeyUnicode CitekeyMode = "unicode"
+	// CitekeyASCII folds letters to ASCII, dropping diacritics ("garcia2020",
+	// "muller2019") and dropping letters without an ASCII form
+	CitekeyASCII CitekeyMode = "ascii"
+	// CitekeyASCIIGerman folds like CitekeyASCII but writes umlauts the German
+	// way ("mueller2019")
+	CitekeyASCIIGerman CitekeyMode = "ascii-german"
+)
+
+// ParseCitekeyMode returns the mode named by value, or unicode if it is empty
+func ParseCitekeyMode(value string) (CitekeyMode, error) {
+	switch value = strings.ToLower(strings.TrimSpace(value)); value {
+	case "", string(CitekeyUnicode):
+		return CitekeyUnicode, nil
+	case string(CitekeyASCII):
+		return CitekeyASCII, nil
+	case string(CitekeyASCIIGerman):
+		return CitekeyASCIIGerman, nil
+	default:
+		return CitekeyUnicode, fmt.Errorf("invalid citekey mode %q (expected unicode, ascii, or ascii-german)", value)
+	}
+}
+
+// ConfiguredCitekeyMode returns the mode set by ACADEMIC_MCP_CITEKEY_MODE, or
+// unicode if it is unset. An unrecognized value returns unicode and an error.
+func ConfiguredCitekeyMode() (CitekeyMode, error) {
+	mode, err := ParseCitekeyMode(os.Getenv(citekeyModeEnv))
+	if err != nil {
+		return mode, fmt.Errorf("invalid %s: %w", citekeyModeEnv, err)
+	}
+	return mode, nil
+}
+
+// CitekeyModes lists the citekey modes, the default first
+var CitekeyModes = []CitekeyMode{CitekeyUnicode, CitekeyASCII, CitekeyASCIIGerman}
+
+// asciiFolds spells the letters that do not decompose into an ASCII letter and
+// combining marks. Uppercase digraphs are capitalized rather than uppercased,
+// as a citekey only capitalizes the first letter of a name.
+var asciiFolds = map[rune]string{
+	'ß': "ss", 'ẞ': "Ss",
+	'æ': "ae", 'Æ': "Ae",
+	'œ': "oe", 'Œ': "Oe",
+	'ø': "o", 'Ø': "O",
+	'đ': "d", 'Đ': "D",
+	'ð': "d", 'Ð': "D",
+	'þ': "th", 'Þ': "Th",
+	'ł': "l", 'Ł': "L",
+	'ŀ': "l", 'Ŀ': "L",
+	'ħ': "h", 'Ħ': "H",
+	'ŋ': "n", 'Ŋ': "N",
+	'ı': "i", 'ĸ': "k",
+}
+
+// germanFolds spells umlauts as German does without them
+var germanFolds = map[rune]string{
+	'ä': "ae", 'Ä': "Ae",
+	'ö': "oe", 'Ö': "Oe",
+	'ü': "ue", 'Ü': "Ue",
+}
+
+// foldCitekey folds text to ASCII for mode: letters with diacritics lose them,
+// letters such as ß and ø are spelled out (asciiFolds), and anything left
+// without an ASCII form is dropped. Text is returned as it is in unicode mode.
+func foldCitekey(text string, mode CitekeyMode) string {
+	if mode != CitekeyASCII && mode != CitekeyASCIIGerman {
+		return text
+	}
+	var b strings.Builder
+	// Compose first, so an umlaut written as u and a combining diaeresis is
+	// found in germanFolds
+	for _, r := range norm.NFC.String(text) {
+		if r < utf8.RuneSelf {
+			b.WriteRune(r)
+			continue
+		}
+		if folded, ok := germanFolds[r]; ok && mode == CitekeyASCIIGerman {
+			b.WriteString(folded)
+			continue
+		}
+		if folded, ok := asciiFolds[r]; ok {
+			b.WriteString(folded)
+			continue
+		}
+		// Keep the ASCII letters of the compatibility decomposition, dropping its
+		// combining marks; this also splits ligatures such as ﬁ
+		for _, d := range norm.NFKD.String(string(r)) {
+			if d < utf8.RuneSelf {
+				b.WriteRune(d)
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,143 @@
+package citations
+
+import (
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestFoldCitekey(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		ascii  string
+		german string
+	}{
+		{"plain ASCII", "smith2020", "smith2020", "smith2020"},
+		{"acute accent", "garcía", "garcia", "garcia"},
+		{"umlauts", "müllerÖzdemirKäse", "mullerOzdemirKase", "muellerOezdemirKaese"},
+		{"decomposed umlaut", "müller", "muller", "mueller"},
+		{"cedilla", "françois", "francois", "francois"},
+		{"tilde", "nuñez", "nunez", "nunez"},
+		{"ring", "ångström", "angstrom", "angstroem"},
+		{"caron", "dvořák", "dvorak", "dvorak"},
+		{"double acute", "erdős", "erdos", "erdos"},
+		{"ogonek", "wałęsa", "walesa", "walesa"},
+		{"stroke", "łukasiewiczØstergaard", "lukasiewiczOstergaard", "lukasiewiczOstergaard"},
+		{"sharp s", "strauß", "strauss", "strauss"},
+		{"capital sharp s", "ẞ", "Ss", "Ss"},
+		{"ash", "ærøskøbing", "aeroskobing", "aeroskobing"},
+		{"ligature oe", "œuvre", "oeuvre", "oeuvre"},
+		{"eth and thorn", "guðmundurÞórsson", "gudmundurThorsson", "gudmundurThorsson"},
+		{"dotless i", "yılmaz", "yilmaz", "yilmaz"},
+		{"d with stroke", "đorđević", "dordevic", "dordevic"},
+		{"vietnamese", "nguyễn", "nguyen", "nguyen"},
+		{"compatibility ligature", "ﬁscher", "fischer", "fischer"},
+		{"fullwidth digits", "ｓｍｉｔｈ２０２０", "smith2020", "smith2020"},
+		{"no ASCII form", "ζήσιμος", "", ""},
+		{"mixed scripts", "ли2020", "2020", "2020"},
+		{"CJK", "王2020", "2020", "2020"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := foldCitekey(tt.text, CitekeyASCII); got != tt.ascii {
+				t.Errorf("foldCitekey(%q, ascii) = %q, want %q", tt.text, got, tt.ascii)
+			}
+			if got := foldCitekey(tt.text, CitekeyASCIIGerman); got != tt.german {
+				t.Errorf("foldCitekey(%q, ascii-german) = %q, want %q", tt.text, got, tt.german)
+			}
+			if got := foldCitekey(tt.text, CitekeyUnicode); got != tt.text {
+				t.Errorf("foldCitekey(%q, unicode) = %q, want it unchanged", tt.text, got)
+			}
+		})
+	}
+}
+
+func TestGenerateCitekeyWithMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		authors  []string
+		mode     CitekeyMode
+		existing []string
+		want     string
+	}{
+		{"unicode keeps accents", []string{"García, José"}, CitekeyUnicode, nil, "garcía2020"},
+		{"ascii drops accents", []string{"García, José"}, CitekeyASCII, nil, "garcia2020"},
+		{"ascii umlaut", []string{"Müller, Hans"}, CitekeyASCII, nil, "muller2020"},
+		{"german umlaut", []string{"Müller, Hans"}, CitekeyASCIIGerman, nil, "mueller2020"},
+		{"german leaves other accents", []string{"Gödel, Kurt", "Poincaré, Henri"}, CitekeyASCIIGerman, nil, "goedelPoincare2020"},
+		{"second author capitalized before folding", []string{"Smith, Jane", "Özdemir, Ayşe"}, CitekeyASCII, nil, "smithOzdemir2020"},
+		{"second author capitalized in unicode", []string{"Smith, Jane", "Özdemir, Ayşe"}, CitekeyUnicode, nil, "smithÖzdemir2020"},
+		{"german capital umlaut", []string{"Smith, Jane", "Özdemir, Ayşe"}, CitekeyASCIIGerman, nil, "smithOezdemir2020"},
+		{"particle name", []string{"de la Peña, María"}, CitekeyASCII, nil, "deLaPena2020"},
+		{"et al", []string{"Ødegård, Ola", "Smith, Jane", "Lee, Ann"}, CitekeyASCII, nil, "odegardEtAl2020"},
+		{"sharp s", []string{"Strauß, Anna"}, CitekeyASCII, nil, "strauss2020"},
+		{"no ASCII letters", []string{"Ζήσιμος, Νίκος"}, CitekeyASCII, nil, "ref2020"},
+		{"collision on the folded form", []string{"Garcia, Ana"}, CitekeyASCII, []string{"garcia2020"}, "garcia2020a"},
+		{"folded form collides with existing", []string{"García, José"}, CitekeyASCII, []string{"garcia2020", "garcia2020a"}, "garcia2020b"},
+		{"unfolded key does not collide", []string{"García, José"}, CitekeyASCII, []string{"garcía2020"}, "garcia2020"},
+		{"german collision", []string{"Müller, Hans"}, CitekeyASCIIGerman, []string{"mueller2020"}, "mueller2020a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing := make(map[string]bool)
+			for _, key := range tt.existing {
+				existing[key] = true
+			}
+			metadata := &models.ItemMetadata{Authors: tt.authors, PublicationDate: "2020"}
+			if got := GenerateCitekeyWithMode(metadata, existing, tt.mode); got != tt.want {
+				t.Errorf("GenerateCitekeyWithMode(%v, %s) = %q, want %q", tt.authors, tt.mode, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeCitekey_Modes(t *testing.T) {
+	tests := []struct {
+		citekey string
+		mode    CitekeyMode
+		want    string
+	}{
+		{"garcía2020", CitekeyUnicode, "garcía2020"},
+		{"garcía2020", CitekeyASCII, "garcia2020"},
+		{"müller-lüdenscheidt2019", CitekeyASCII, "mullerludenscheidt2019"},
+		{"müller-lüdenscheidt2019", CitekeyASCIIGerman, "muellerluedenscheidt2019"},
+		{"2020müller", CitekeyASCIIGerman, "ref2020mueller"},
+		{"ζήσιμος", CitekeyASCII, "unknown"},
+		{"ζήσιμος", CitekeyUnicode, "ζήσιμος"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode)+" "+tt.citekey, func(t *testing.T) {
+			if got := sanitizeCitekey(tt.citekey, tt.mode); got != tt.want {
+				t.Errorf("sanitizeCitekey(%q, %s) = %q, want %q", tt.citekey, tt.mode, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfiguredCitekeyMode(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected CitekeyMode
+		wantErr  bool
+	}{
+		{"", CitekeyUnicode, false},
+		{"unicode", CitekeyUnicode, false},
+		{" ASCII ", CitekeyASCII, false},
+		{"ascii-german", CitekeyASCIIGerman, false},
+		{"latin1", CitekeyUnicode, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv(citekeyModeEnv, tt.value)
+			mode, err := ConfiguredCitekeyMode()
+			if mode != tt.expected || (err != nil) != tt.wantErr {
+				t.Errorf("ConfiguredCitekeyMode() = %q, %v; expected %q (error %v)", mode, err, tt.expected, tt.wantErr)
+			}
+		})
+	}
+}
@@ -292,9 +292,9 @@ func TestExtractYear(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := extractYear(tt.pubDate)
+			got := ExtractYear(tt.pubDate)
 			if got != tt.want {
-				t.Errorf("extractYear(%q) = %v, want %v", tt.pubDate, got, tt.want)
+				t.Errorf("ExtractYear(%q) = %v, want %v", tt.pubDate, got, tt.want)
 			}
 		})
 	}
@@ -315,7 +315,7 @@ func TestExtractAuthorPart(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := extractAuthorPart(tt.authors)
+			got := extractAuthorPart(&models.ItemMetadata{Authors: tt.authors})
 			if got != tt.want {
 				t.Errorf("extractAuthorPart() = %v, want %v", got, tt.want)
 			}
@@ -332,8 +332,11 @@ func TestFormatAuthorName(t *testing.T) {
 		{"last, first", "Smith, John", "smith"},
 		{"first last", "John Smith", "smith"},
 		{"single name", "Smith", "smith"},
-		{"multi-part last name", "von Neumann", "neumann"}, // Takes last part when space-separated
-		{"three part name", "John von Neumann", "neumann"},
+		{"multi-part last name", "von Neumann", "neumann"},    // Takes last part when space-separated
+		{"three part name", "John von Neumann", "vonNeumann"}, // Same key as "von Neumann, John"
+		{"inverted particle name", "von Neumann, John", "vonNeumann"},
+		{"suffix", "Martin Luther King Jr.", "king"},
+		{"pubmed initials", "Smith JR", "smith"},
 		{"empty string", "", ""},
 	}
 
@@ -365,7 +368,7 @@ func TestSanitizeCitekey(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := sanitizeCitekey(tt.citekey)
+			got := sanitizeCitekey(tt.citekey, CitekeyUnicode)
 			if got != tt.want {
 				t.Errorf("sanitizeCitekey(%q) = %v, want %v", tt.citekey, got, tt.want)
 			}
@@ -419,3 +422,35 @@ func TestGenerateCitekey_RealWorldExamples(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateCitekey_CorporateAuthors(t *testing.T) {
+	tests := []struct {
+		name      string
+		authors   []string
+		corporate []string
+		want      string
+	}{
+		{"acronym in the name", []string{"The LIGO Scientific Collaboration"}, nil, "ligo2020"},
+		{"acronym alone", []string{"OECD"}, nil, "oecd2020"},
+		{"acronym in parentheses", []string{"World Health Organization (WHO)"}, nil, "who2020"},
+		{"initials of a long name", []string{"Intergovernmental Panel on Climate Change"}, nil, "ipcc2020"},
+		{"initials skip stop words", []string{"World Health Organization"}, nil, "who2020"},
+		{"initials of a collaboration", []string{"Open Science Collaboration"}, nil, "osc2020"},
+		{"first word that names it", []string{"University of Chicago"}, nil, "chicago2020"},
+		{"short name", []string{"Cochrane Collaboration"}, nil, "cochrane2020"},
+		{"BibTeX literal", []string{"{Google DeepMind}"}, nil, "google2020"},
+		{"flagged by the source", []string{"Google DeepMind"}, []string{"Google DeepMind"}, "google2020"},
+		{"not flagged", []string{"Google DeepMind"}, nil, "deepmind2020"},
+		{"with a person", []string{"Smith, Jane", "ATLAS Collaboration"}, nil, "smithAtlas2020"},
+		{"first of several", []string{"CMS Collaboration", "Smith, Jane", "Lee, Ann"}, nil, "cmsEtAl2020"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata := &models.ItemMetadata{Authors: tt.authors, CorporateAuthors: tt.corporate, PublicationDate: "2020"}
+			if got := GenerateCitekey(metadata, map[string]bool{}); got != tt.want {
+				t.Errorf("GenerateCitekey(%v) = %q, want %q", tt.authors, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package citations
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// doiPattern is the syntax of a normalized DOI: the "10." directory indicator,
+// a registrant code of four to nine digits (optionally with dot-separated
+// sub-codes), a slash, and a suffix without whitespace
+var doiPattern = regexp.MustCompile(`^10\.\d{4,9}(\.\d+)*/\S+$`)
+
+// doiPrefixes are the resolver URLs and labels stripped from DOIs, lowercase
+var doiPrefixes = []string{
+	"https://doi.org/", "http://doi.org/", "https://dx.doi.org/", "http://dx.doi.org/",
+	"https://www.doi.org/", "http://www.doi.org/", "doi.org/", "dx.doi.org/",
+	"doi:", "doi ", "doi.",
+}
+
+// NormalizeDOI returns a DOI in its canonical form: resolver URLs and "doi:"
+// labels stripped, percent-encoding decoded, surrounding punctuation and
+// brackets trimmed, and lowercased (DOIs are case-insensitive). It reports false,
+// with an empty DOI, if the result is not a syntactically valid DOI.
+func NormalizeDOI(value string) (string, bool) {
+	doi := strings.ToLower(strings.TrimSpace(value))
+	// Some sources repeat the label ("DOI: https://doi.org/10...")
+	for stripped := true; stripped; {
+		stripped = false
+		for _, prefix := range doiPrefixes {
+			if rest, ok := strings.CutPrefix(doi, prefix); ok {
+				doi, stripped = strings.TrimSpace(rest), true
+			}
+		}
+	}
+	if decoded, err := url.PathUnescape(doi); err == nil {
+		doi = decoded
+	}
+	doi = trimDOIPunctuation(doi)
+	if !doiPattern.MatchString(doi) {
+		return "", false
+	}
+	return doi, true
+}
+
+// trimDOIPunctuation removes sentence punctuation and quotes after a DOI, and
+// closing brackets that do not close an opening bracket within it, so
+// "10.1000/abc(1)." keeps its parentheses but "(10.1000/abc)" does not
+func trimDOIPunctuation(doi string) string {
+	doi = strings.TrimLeft(doi, "([{<\"'")
+	for doi != "" {
+		last := doi[len(doi)-1]
+		switch {
+		case strings.IndexByte(".,;:\"'>", last) >= 0:
+			doi = doi[:len(doi)-1]
+		case last == ')' && strings.Count(doi, "(") < strings.Count(doi, ")"),
+			last == ']' && strings.Count(doi, "[") < strings.Count(doi, "]"),
+			last == '}' && strings.Count(doi, "{") < strings.Count(doi, "}"):
+			doi = doi[:len(doi)-1]
+		default:
+			return doi
+		}
+	}
+	return doi
+}
@@ -0,0 +1,50 @@
+package citations
+
+import "testing"
+
+func TestNormalizeDOI(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		valid bool
+	}{
+		{"10.1038/nature12373", "10.1038/nature12373", true},
+		{"  10.1038/NATURE12373  ", "10.1038/nature12373", true},
+		{"https://doi.org/10.1145/3292500.3330701", "10.1145/3292500.3330701", true},
+		{"http://dx.doi.org/10.1016/j.cell.2009.01.042", "10.1016/j.cell.2009.01.042", true},
+		{"HTTPS://DOI.ORG/10.1093/MIND/LIX.236.433", "10.1093/mind/lix.236.433", true},
+		{"https://www.doi.org/10.2307/1912352", "10.2307/1912352", true},
+		{"doi:10.1371/journal.pone.0000217", "10.1371/journal.pone.0000217", true},
+		{"DOI: 10.1371/journal.pone.0000217", "10.1371/journal.pone.0000217", true},
+		{"DOI 10.1080/00048408112340011", "10.1080/00048408112340011", true},
+		{"doi: https://doi.org/10.1086/289019", "10.1086/289019", true},
+		{"doi.org/10.5555/12345678", "10.5555/12345678", true},
+		{"10.1000/xyz123.", "10.1000/xyz123", true},
+		{"10.1000/xyz123,", "10.1000/xyz123", true},
+		{"(10.1000/xyz123)", "10.1000/xyz123", true},
+		{"\"10.1000/xyz123\";", "10.1000/xyz123", true},
+		{"10.1002/(sici)1097-4571(199806)49:8<693::aid-asi4>3.0.co;2-0", "10.1002/(sici)1097-4571(199806)49:8<693::aid-asi4>3.0.co;2-0", true},
+		{"10.1016/S0140-6736(97)11096-0", "10.1016/s0140-6736(97)11096-0", true},
+		{"10.1016/S0140-6736(97)11096-0).", "10.1016/s0140-6736(97)11096-0", true},
+		{"https://doi.org/10.1007%2F978-3-319-24277-4_9", "10.1007/978-3-319-24277-4_9", true},
+		{"10.1000.10/abc", "10.1000.10/abc", true},
+		{"", "", false},
+		{"n/a", "", false},
+		{"doi:", "", false},
+		{"10.1038", "", false},
+		{"10.12/abc", "", false},
+		{"11.1038/nature12373", "", false},
+		{"10.1038/ nature12373", "", false},
+		{"https://example.com/10.1038/nature12373", "", false},
+		{"ISBN 978-3-16-148410-0", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, valid := NormalizeDOI(tt.input)
+			if got != tt.want || valid != tt.valid {
+				t.Errorf("NormalizeDOI(%q) = %q, %v; want %q, %v", tt.input, got, valid, tt.want, tt.valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package citations
+
+import (
+	"regexp"
+	"strings"
+)
+
+// isbnLabelPattern matches an "ISBN", "ISBN-10:", or "ISBN-13:" label
+var isbnLabelPattern = regexp.MustCompile(`^ISBN(-1[03])?:?`)
+
+// NormalizeISBN returns an ISBN as the 13 digits of its ISBN-13 form: an
+// "ISBN" label, hyphens, and spaces are removed, and an ISBN-10 is converted.
+// It reports false, with an empty ISBN, if the value is not a valid ISBN-10 or
+// ISBN-13 (the check digit must match).
+func NormalizeISBN(value string) (string, bool) {
+	isbn := strings.ToUpper(strings.TrimSpace(value))
+	isbn = isbnLabelPattern.ReplaceAllString(isbn, "")
+	isbn = strings.NewReplacer("-", "", " ", "").Replace(isbn)
+
+	switch len(isbn) {
+	case 10:
+		if !validISBN10(isbn) {
+			return "", false
+		}
+		isbn = "978" + isbn[:9]
+		return isbn + isbn13CheckDigit(isbn), true
+	case 13:
+		if !allDigits(isbn) || isbn13CheckDigit(isbn[:12]) != isbn[12:] {
+			return "", false
+		}
+		return isbn, true
+	}
+	return "", false
+}
+
+// ISBN10 returns the ISBN-10 form of a normalized ISBN-13, if it has one
+// (only 978-prefixed ISBNs do)
+func ISBN10(isbn13 string) (string, bool) {
+	if len(isbn13) != 13 || !strings.HasPrefix(isbn13, "978") || !allDigits(isbn13) {
+		return "", false
+	}
+	body := isbn13[3:12]
+	sum := 0
+	for i, r := range body {
+		sum += (10 - i) * int(r-'0')
+	}
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		return body + "X", true
+	}
+	return body + string(rune('0'+check)), true
+}
+
+func validISBN10(isbn string) bool {
+	sum := 0
+	for i, r := range isbn {
+		var digit int
+		switch {
+		case r >= '0' && r <= '9':
+			digit = int(r - '0')
+		case r == 'X' && i == 9:
+			digit = 10
+		default:
+			return false
+		}
+		sum += (10 - i) * digit
+	}
+	return sum%11 == 0
+}
+
+// isbn13CheckDigit returns the check digit for the first 12 digits of an ISBN-13
+func isbn13CheckDigit(first12 string) string {
+	sum := 0
+	for i, r := range first12 {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += weight * int(r-'0')
+	}
+	return string(rune('0' + (10-sum%10)%10))
+}
+
+func allDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
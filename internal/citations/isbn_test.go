@@ -0,0 +1,53 @@
+package citations
+
+import "testing"
+
+func TestNormalizeISBN(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+		valid    bool
+	}{
+		{"978-0-262-03384-8", "9780262033848", true},
+		{"9780262033848", "9780262033848", true},
+		{"ISBN 978 0 262 03384 8", "9780262033848", true},
+		{"ISBN-13: 978-0-262-03384-8", "9780262033848", true},
+		{"0-262-03384-4", "9780262033848", true},
+		{"0-8044-2957-x", "9780804429573", true},
+		{"978-0-262-03384-9", "", false},
+		{"0-262-03384-5", "", false},
+		{"12345", "", false},
+		{"97802620338X8", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := NormalizeISBN(tt.value)
+			if got != tt.expected || ok != tt.valid {
+				t.Errorf("NormalizeISBN(%q) = %q, %v; expected %q, %v", tt.value, got, ok, tt.expected, tt.valid)
+			}
+		})
+	}
+}
+
+func TestISBN10(t *testing.T) {
+	tests := []struct {
+		isbn13   string
+		expected string
+		ok       bool
+	}{
+		{"9780262033848", "0262033844", true},
+		{"9780804429573", "080442957X", true},
+		{"9791034304472", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.isbn13, func(t *testing.T) {
+			got, ok := ISBN10(tt.isbn13)
+			if got != tt.expected || ok != tt.ok {
+				t.Errorf("ISBN10(%q) = %q, %v; expected %q, %v", tt.isbn13, got, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
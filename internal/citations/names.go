@@ -0,0 +1,286 @@
+package citations
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// nameSuffixes are the generational suffixes recognized after a name. They are
+// matched case-sensitively so PubMed-style initials such as "JR" are not mistaken
+// for "Jr".
+var nameSuffixes = map[string]bool{
+	"Jr": true, "Jr.": true, "Sr": true, "Sr.": true,
+	"II": true, "III": true, "IV": true,
+}
+
+// familyParticles are the lowercase words that begin a family name written after
+// the given names (e.g., "Jan van der Berg", "Ludwig von Mises")
+var familyParticles = map[string]bool{
+	"af": true, "al": true, "bin": true, "da": true, "das": true, "de": true,
+	"del": true, "della": true, "den": true, "der": true, "di": true, "do": true,
+	"dos": true, "du": true, "el": true, "ibn": true, "la": true, "le": true,
+	"ten": true, "ter": true, "van": true, "von": true, "zu": true,
+}
+
+// initialsPattern matches a word made only of initials, such as "J", "J.", "J.R.R.",
+// or "J.-P."
+var initialsPattern = regexp.MustCompile(`^(\p{Lu}\.?(-\p{Lu}\.?)?)+$`)
+
+// pubMedInitialsPattern matches the undotted initials PubMed writes after a
+// family name (e.g., the "JR" of "Smith JR")
+var pubMedInitialsPattern = regexp.MustCompile(`^\p{Lu}{1,3}$`)
+
+// corporateWords are words that mark an author as an organization rather than a
+// person (e.g., "The LIGO Scientific Collaboration", "World Health Organization")
+var corporateWords = map[string]bool{
+	"academy": true, "agency": true, "alliance": true, "association": true, "bank": true,
+	"board": true, "bureau": true, "center": true, "centre": true, "collaboration": true,
+	"commission": true, "committee": true, "company": true, "consortium": true,
+	"corporation": true, "council": true, "department": true, "federation": true,
+	"forum": true, "foundation": true, "group": true, "inc": true, "initiative": true,
+	"institute": true, "institution": true, "laboratory": true, "league": true, "ltd": true,
+	"ministry": true, "network": true, "office": true, "organisation": true,
+	"organization": true, "panel": true, "project": true, "society": true, "team": true,
+	"union": true, "university": true, "working": true,
+}
+
+// acronymPattern matches a word that is an acronym, such as "OECD", "LIGO", or
+// "G20"
+var acronymPattern = regexp.MustCompile(`^\p{Lu}[\p{Lu}\d]+$`)
+
+// apostrophes maps the apostrophe variants found in names to a plain apostrophe
+var apostrophes = strings.NewReplacer("’", "'", "‘", "'", "ʼ", "'", "`", "'")
+
+// ParseAuthor splits an author string into its family name, given names, and
+// suffix. It accepts "Family, Given", "Family, Given, Suffix", "Given Family",
+// PubMed's "Family Initials", and single names, normalizing the text to NFKC,
+// collapsing whitespace, and writing initials as "J. R.". Lowercase particles
+// such as "van der" stay with the family name. CJK names written without
+// spaces are kept whole as the family name, and with spaces are read family
+// name first. The name of an organization (see IsCorporateAuthor) is kept
+// whole as the family name, without the braces of a BibTeX literal.
+func ParseAuthor(raw string) models.Author {
+	name := strings.Join(strings.Fields(apostrophes.Replace(norm.NFKC.String(raw))), " ")
+	author := models.Author{Raw: raw}
+	if name == "" {
+		return author
+	}
+	if IsCorporateAuthor(name) {
+		author.Family = CorporateName(name)
+		return author
+	}
+
+	if strings.Contains(name, ",") {
+		var parts []string
+		for _, part := range strings.Split(name, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				parts = append(parts, part)
+			}
+		}
+		last := len(parts) - 1
+		switch {
+		case len(parts) == 1:
+			return parseUninvertedName(author, parts[0])
+		case len(parts) == 2 && nameSuffixes[parts[1]]:
+			// "John Smith, Jr."
+			author = parseUninvertedName(author, parts[0])
+			author.Suffix = parts[1]
+		case nameSuffixes[parts[1]]:
+			// BibTeX's "Smith, Jr., John"
+			author.Family, author.Suffix = parts[0], parts[1]
+			author.Given = formatGivenNames(strings.Join(parts[2:], " "))
+		case len(parts) > 2 && nameSuffixes[parts[last]]:
+			// "Smith, John, Jr."
+			author.Family, author.Suffix = parts[0], parts[last]
+			author.Given = formatGivenNames(strings.Join(parts[1:last], " "))
+		case len(parts) == 2 && pubMedInitialsPattern.MatchString(parts[1]):
+			// "Smith, JR"
+			author.Family = parts[0]
+			author.Given = formatGivenNames(strings.Join(strings.Split(parts[1], ""), " "))
+		default:
+			author.Family = parts[0]
+			author.Given = formatGivenNames(strings.Join(parts[1:], " "))
+		}
+		return author
+	}
+
+	return parseUninvertedName(author, name)
+}
+
+// IsCorporateAuthor reports whether an author string names an organization
+// rather than a person: a name in braces (a BibTeX literal), a name with a word
+// such as Collaboration, Consortium, Committee, Group, or University (before
+// any comma, so "University of California, Berkeley" is one but a person whose
+// family name is Group is not), a name starting with "The", a single acronym
+// such as "OECD", or a name of more than three words without a comma that has
+// no particles, initials, or suffix.
+func IsCorporateAuthor(name string) bool {
+	name = strings.TrimSpace(name)
+	if strings.HasPrefix(name, "{") && strings.HasSuffix(name, "}") {
+		return true
+	}
+	head, _, inverted := strings.Cut(name, ",")
+	words := strings.Fields(head)
+	if inverted && len(words) < 2 {
+		return false
+	}
+	for _, word := range words {
+		if corporateWords[strings.ToLower(strings.Trim(word, ".()"))] {
+			return true
+		}
+	}
+	if inverted || len(words) == 0 {
+		return false
+	}
+	if strings.EqualFold(words[0], "the") && len(words) > 1 {
+		return true
+	}
+	if len(words) == 1 {
+		return acronymPattern.MatchString(strings.Trim(words[0], "()"))
+	}
+	if len(words) <= 3 {
+		return false
+	}
+	for _, word := range words {
+		if familyParticles[word] || nameSuffixes[word] || isInitials(word) {
+			return false
+		}
+	}
+	return true
+}
+
+// CorporateName returns an organization's name without the braces of a BibTeX
+// literal ("{Google DeepMind}")
+func CorporateName(name string) string {
+	name = strings.TrimSpace(name)
+	for strings.HasPrefix(name, "{") && strings.HasSuffix(name, "}") {
+		name = strings.TrimSpace(name[1 : len(name)-1])
+	}
+	return name
+}
+
+// parseUninvertedName parses a name written without a comma after the family name
+func parseUninvertedName(author models.Author, name string) models.Author {
+	words := strings.Fields(name)
+	if len(words) > 1 && nameSuffixes[words[len(words)-1]] {
+		author.Suffix = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+
+	switch {
+	case len(words) == 1:
+		// A single name (e.g., "Plato"), or a CJK name written without spaces
+		author.Family = words[0]
+	case isCJKName(words):
+		author.Family = words[0]
+		author.Given = strings.Join(words[1:], " ")
+	case pubMedInitialsPattern.MatchString(words[len(words)-1]) && !isInitials(words[0]):
+		// "Smith JR" or "van der Berg J"
+		author.Family = strings.Join(words[:len(words)-1], " ")
+		author.Given = formatGivenNames(strings.Join(strings.Split(words[len(words)-1], ""), " "))
+	default:
+		// The family name starts at the first particle after the given names,
+		// or is the last word
+		start := len(words) - 1
+		for i := 1; i < len(words)-1; i++ {
+			if familyParticles[words[i]] {
+				start = i
+				break
+			}
+		}
+		author.Family = strings.Join(words[start:], " ")
+		author.Given = formatGivenNames(strings.Join(words[:start], " "))
+	}
+	return author
+}
+
+// formatGivenNames writes initials as "J." separated by spaces and leaves full
+// given names as they are (e.g., "J.R.R." becomes "J. R. R." and "Mary J" becomes "Mary J.")
+func formatGivenNames(given string) string {
+	var words []string
+	for _, word := range strings.Fields(given) {
+		if !isInitials(word) {
+			words = append(words, word)
+			continue
+		}
+		for _, initial := range splitInitials(word) {
+			words = append(words, initial)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// isInitials reports whether a word consists only of initials
+func isInitials(word string) bool {
+	if !initialsPattern.MatchString(word) {
+		return false
+	}
+	// Undotted capitals longer than one letter are a word (e.g., an acronym), not initials
+	return strings.Contains(word, ".") || len([]rune(word)) == 1
+}
+
+// splitInitials splits a word of initials into one dotted initial each, keeping
+// hyphenated initials such as "J.-P." together
+func splitInitials(word string) []string {
+	var initials []string
+	runes := []rune(word)
+	for i := 0; i < len(runes); i++ {
+		if !unicode.IsUpper(runes[i]) {
+			continue
+		}
+		initial := string(runes[i]) + "."
+		// Join a hyphenated initial to the one before it
+		if i > 0 && runes[i-1] == '-' && len(initials) > 0 {
+			initials[len(initials)-1] += "-" + initial
+			continue
+		}
+		initials = append(initials, initial)
+	}
+	return initials
+}
+
+// isCJKName reports whether a name is written in Chinese, Japanese, or Korean script
+func isCJKName(words []string) bool {
+	letters := 0
+	for _, word := range words {
+		for _, r := range word {
+			if !unicode.IsLetter(r) {
+				continue
+			}
+			if !unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+				return false
+			}
+			letters++
+		}
+	}
+	return letters > 0
+}
+
+// NameKey reduces a name to lowercase letters and digits so variants compare
+// equal (e.g., "O'Neill" and "ONeill", or "van der Berg" and "Van Der Berg")
+func NameKey(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(norm.NFKC.String(name)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// FormatAuthor writes a parsed author as "Family, Given, Suffix", omitting the
+// parts it lacks
+func FormatAuthor(author models.Author) string {
+	name := author.Family
+	for _, part := range []string{author.Given, author.Suffix} {
+		if part != "" {
+			name += ", " + part
+		}
+	}
+	return name
+}
@@ -0,0 +1,158 @@
+package citations
+
+import (
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestParseAuthor(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want models.Author
+	}{
+		// Inverted and uninverted forms
+		{"Smith, John", models.Author{Family: "Smith", Given: "John"}},
+		{"John Smith", models.Author{Family: "Smith", Given: "John"}},
+		{"  John   Q.  Smith ", models.Author{Family: "Smith", Given: "John Q."}},
+
+		// Initials
+		{"Smith, J.R.", models.Author{Family: "Smith", Given: "J. R."}},
+		{"J.R.R. Tolkien", models.Author{Family: "Tolkien", Given: "J. R. R."}},
+		{"J R Smith", models.Author{Family: "Smith", Given: "J. R."}},
+		{"Smith JR", models.Author{Family: "Smith", Given: "J. R."}},
+		{"Smith, JR", models.Author{Family: "Smith", Given: "J. R."}},
+		{"Sartre, J.-P.", models.Author{Family: "Sartre", Given: "J.-P."}},
+
+		// Particles
+		{"Jan van der Berg", models.Author{Family: "van der Berg", Given: "Jan"}},
+		{"van der Berg, Jan", models.Author{Family: "van der Berg", Given: "Jan"}},
+		{"Ludwig von Mises", models.Author{Family: "von Mises", Given: "Ludwig"}},
+		{"Van Morrison", models.Author{Family: "Morrison", Given: "Van"}},
+
+		// Apostrophes and hyphens
+		{"Onora O’Neill", models.Author{Family: "O'Neill", Given: "Onora"}},
+		{"O'Neill, Onora", models.Author{Family: "O'Neill", Given: "Onora"}},
+		{"Mary Smith-Jones", models.Author{Family: "Smith-Jones", Given: "Mary"}},
+		{"Jean-Paul Sartre", models.Author{Family: "Sartre", Given: "Jean-Paul"}},
+
+		// Suffixes
+		{"Martin Luther King Jr.", models.Author{Family: "King", Given: "Martin Luther", Suffix: "Jr."}},
+		{"King, Martin Luther, Jr.", models.Author{Family: "King", Given: "Martin Luther", Suffix: "Jr."}},
+		{"King, Jr., Martin Luther", models.Author{Family: "King", Given: "Martin Luther", Suffix: "Jr."}},
+		{"John Smith, III", models.Author{Family: "Smith", Given: "John", Suffix: "III"}},
+
+		// Single names
+		{"Plato", models.Author{Family: "Plato"}},
+		{"Aristotle,", models.Author{Family: "Aristotle"}},
+
+		// CJK names
+		{"毛泽东", models.Author{Family: "毛泽东"}},
+		{"山田 太郎", models.Author{Family: "山田", Given: "太郎"}},
+		{"김 민준", models.Author{Family: "김", Given: "민준"}},
+
+		// Organizations
+		{"The LIGO Scientific Collaboration", models.Author{Family: "The LIGO Scientific Collaboration"}},
+		{"University of California, Berkeley", models.Author{Family: "University of California, Berkeley"}},
+		{"{Google DeepMind}", models.Author{Family: "Google DeepMind"}},
+
+		// Unicode normalization: full-width letters and a decomposed accent
+		{"Ｓｍｉｔｈ, Ｊｏｈｎ", models.Author{Family: "Smith", Given: "John"}},
+		{"René Descartes", models.Author{Family: "Descartes", Given: "René"}},
+
+		{"", models.Author{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			tt.want.Raw = tt.raw
+			if got := ParseAuthor(tt.raw); got != tt.want {
+				t.Errorf("ParseAuthor(%q) = %+v, want %+v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNameKey(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"O'Neill", "ONeill"},
+		{"van der Berg", "Van Der Berg"},
+		{"Smith-Jones", "smith jones"},
+		{"Ｓｍｉｔｈ", "Smith"},
+	}
+	for _, tt := range tests {
+		if NameKey(tt.a) != NameKey(tt.b) {
+			t.Errorf("Expected %q and %q to have the same key, got %q and %q", tt.a, tt.b, NameKey(tt.a), NameKey(tt.b))
+		}
+	}
+}
+
+func TestFormatAuthor(t *testing.T) {
+	tests := []struct {
+		author models.Author
+		want   string
+	}{
+		{models.Author{Family: "Smith", Given: "J. R."}, "Smith, J. R."},
+		{models.Author{Family: "King", Given: "Martin Luther", Suffix: "Jr."}, "King, Martin Luther, Jr."},
+		{models.Author{Family: "Plato"}, "Plato"},
+	}
+	for _, tt := range tests {
+		if got := FormatAuthor(tt.author); got != tt.want {
+			t.Errorf("FormatAuthor(%+v) = %q, want %q", tt.author, got, tt.want)
+		}
+	}
+}
+
+func TestIsCorporateAuthor(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		// Organizations
+		{"The LIGO Scientific Collaboration", true},
+		{"LIGO Scientific Collaboration and Virgo Collaboration", true},
+		{"ATLAS Collaboration", true},
+		{"OECD", true},
+		{"WHO", true},
+		{"G20", true},
+		{"World Health Organization", true},
+		{"World Health Organisation (WHO)", true},
+		{"Intergovernmental Panel on Climate Change", true},
+		{"Open Science Collaboration", true},
+		{"Cochrane Consortium", true},
+		{"Committee on Publication Ethics", true},
+		{"National Academies of Sciences Engineering and Medicine", true},
+		{"University of California, Berkeley", true},
+		{"Massachusetts Institute of Technology", true},
+		{"Pew Research Center", true},
+		{"Google Inc.", true},
+		{"The Beatles", true},
+		{"{Google DeepMind}", true},
+		{"European Centre for Disease Prevention and Control", true},
+
+		// People
+		{"Smith, John", false},
+		{"John Smith", false},
+		{"Group, John", false},
+		{"John Group", true}, // Indistinguishable from an organization named Group
+		{"Plato", false},
+		{"Smith JR", false},
+		{"J. R. R. Tolkien", false},
+		{"Jan van der Berg", false},
+		{"Martin Luther King Jr.", false},
+		{"Gabriel José de la Concordia García", false},
+		{"King, Martin Luther, Jr.", false},
+		{"Theodore Roosevelt", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsCorporateAuthor(tt.name); got != tt.want {
+				t.Errorf("IsCorporateAuthor(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,299 @@
+package citations
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Ways a reference can be matched to the work it cites
+const (
+	ReferenceMatchDOI   = "doi"
+	ReferenceMatchTitle = "title"
+)
+
+const (
+	// minTitleSimilarity is the lowest similarity between a work's title and the
+	// words of a reference at which the reference can cite the work. It allows a
+	// letter or two of OCR damage in a title of a few words.
+	minTitleSimilarity = 0.9
+	// minTitleOverlap is the fraction of a title's distinct words a reference must
+	// contain before its similarity is computed
+	minTitleOverlap = 0.6
+	// minUncorroboratedTitleWords is the fewest words a title needs to match a
+	// reference when neither the work's first author nor its year is found in it
+	minUncorroboratedTitleWords = 4
+)
+
+// referenceDOIPattern finds DOIs written in reference text
+var referenceDOIPattern = regexp.MustCompile(`(?i)10\.\d{4,9}(\.\d+)*/[^\s"<>]+`)
+
+// referenceYearPattern finds the years written in reference text
+var referenceYearPattern = regexp.MustCompile(`\b(1[5-9]|20)\d{2}\b`)
+
+// Work is a document a reference may cite
+type Work struct {
+	DocumentID  string
+	Title       string
+	DOI         string
+	Year        string // Four-digit publication year, or ""
+	FirstAuthor string // As stored, in any form ParseAuthor accepts
+}
+
+// ReferenceMatch is the work a reference was found to cite
+type ReferenceMatch struct {
+	DocumentID string
+	Method     string  // ReferenceMatchDOI or ReferenceMatchTitle
+	Score      float64 // 1 for a DOI match; the title similarity otherwise
+}
+
+// MatchReference returns the work a reference cites, given its text and the DOI
+// parsed for it. A DOI given or written in the text that matches a work's DOI
+// decides the match. Otherwise the work's title, or its title without subtitle,
+// must appear in the text as a run of words set off by punctuation, allowing for
+// small spelling differences, so a title that is the start of a longer title does
+// not match. A work whose first author or year is known must not be contradicted
+// by the text, and a short title must be corroborated by one of them. Of several
+// matching works the most similar, then the first, is returned.
+func MatchReference(text string, doi string, works []Work) (ReferenceMatch, bool) {
+	dois := referenceDOIs(text, doi)
+	for _, work := range works {
+		workDOI, ok := NormalizeDOI(work.DOI)
+		if ok && dois[workDOI] {
+			return ReferenceMatch{DocumentID: work.DocumentID, Method: ReferenceMatchDOI, Score: 1}, true
+		}
+	}
+
+	words := splitReferenceWords(text)
+	if len(words) == 0 {
+		return ReferenceMatch{}, false
+	}
+	present := make(map[string]bool, len(words))
+	for _, word := range words {
+		present[word.text] = true
+	}
+	years := referenceYearPattern.FindAllString(text, -1)
+
+	var best ReferenceMatch
+	for _, work := range works {
+		score, titleWords := titleScore(text, words, present, work.Title)
+		if score < minTitleSimilarity || score <= best.Score {
+			continue
+		}
+		if !corroborated(work, words, years, titleWords) {
+			continue
+		}
+		best = ReferenceMatch{DocumentID: work.DocumentID, Method: ReferenceMatchTitle, Score: score}
+	}
+	return best, best.DocumentID != ""
+}
+
+// referenceDOIs returns the normalized DOIs of a reference: the one parsed for it
+// and any written in its text
+func referenceDOIs(text string, doi string) map[string]bool {
+	dois := make(map[string]bool)
+	if normalized, ok := NormalizeDOI(doi); ok {
+		dois[normalized] = true
+	}
+	for _, found := range referenceDOIPattern.FindAllString(text, -1) {
+		if normalized, ok := NormalizeDOI(found); ok {
+			dois[normalized] = true
+		}
+	}
+	return dois
+}
+
+// referenceWord is a lowercase word of reference text and its byte span in the
+// text
+type referenceWord struct {
+	text       string
+	start, end int
+}
+
+// splitReferenceWords splits text into lowercase runs of letters and digits
+func splitReferenceWords(text string) []referenceWord {
+	var words []referenceWord
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWord && start < 0 {
+			start = i
+		} else if !isWord && start >= 0 {
+			words = append(words, referenceWord{strings.ToLower(text[start:i]), start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		words = append(words, referenceWord{strings.ToLower(text[start:]), start, len(text)})
+	}
+	return words
+}
+
+// titleScore returns the best similarity between a title, or its main title
+// before a colon, and a delimited run of the reference's words, with the number
+// of words in the title that scored it
+func titleScore(text string, words []referenceWord, present map[string]bool, title string) (float64, int) {
+	candidates := []string{title}
+	if main, _, found := strings.Cut(title, ":"); found {
+		candidates = append(candidates, main)
+	}
+
+	var best float64
+	var bestWords int
+	for _, candidate := range candidates {
+		titleWords := splitReferenceWords(candidate)
+		if len(titleWords) == 0 || wordsPresent(titleWords, present) < minTitleOverlap {
+			continue
+		}
+		if score := bestWindowSimilarity(text, words, joinWords(titleWords), len(titleWords)); score > best {
+			best, bestWords = score, len(titleWords)
+		}
+	}
+	return best, bestWords
+}
+
+// wordsPresent returns the fraction of a title's distinct words found in the
+// reference
+func wordsPresent(titleWords []referenceWord, present map[string]bool) float64 {
+	distinct := make(map[string]bool, len(titleWords))
+	found := 0
+	for _, word := range titleWords {
+		if distinct[word.text] {
+			continue
+		}
+		distinct[word.text] = true
+		if present[word.text] {
+			found++
+		}
+	}
+	return float64(found) / float64(len(distinct))
+}
+
+// bestWindowSimilarity compares a title of n words with each run of n-1 to n+1
+// reference words that is set off by punctuation or the ends of the text
+func bestWindowSimilarity(text string, words []referenceWord, title string, n int) float64 {
+	var best float64
+	for size := max(n-1, 1); size <= n+1; size++ {
+		for start := 0; start+size <= len(words); start++ {
+			window := words[start : start+size]
+			if !delimited(text, window) {
+				continue
+			}
+			if score := similarity(title, joinWords(window)); score > best {
+				best = score
+			}
+		}
+	}
+	return best
+}
+
+// delimited reports whether a run of words is preceded and followed by
+// punctuation or an end of the text, rather than only by spaces that would make
+// it part of a longer phrase
+func delimited(text string, window []referenceWord) bool {
+	before := text[:window[0].start]
+	after := text[window[len(window)-1].end:]
+	return boundary(before, true) && boundary(after, false)
+}
+
+// boundary reports whether the separator between a run of words and the
+// neighboring word, or the end of the text, contains punctuation. before selects
+// the separator at the end of s rather than the start.
+func boundary(s string, before bool) bool {
+	var separator string
+	if before {
+		i := strings.LastIndexFunc(s, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) })
+		if i < 0 {
+			return true
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		separator = s[i+size:]
+	} else {
+		i := strings.IndexFunc(s, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) })
+		if i < 0 {
+			return true
+		}
+		separator = s[:i]
+	}
+	return strings.TrimSpace(separator) != ""
+}
+
+func joinWords(words []referenceWord) string {
+	texts := make([]string, len(words))
+	for i, word := range words {
+		texts[i] = word.text
+	}
+	return strings.Join(texts, " ")
+}
+
+// similarity returns 1 minus the edit distance between a and b relative to the
+// longer of them
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longer := max(len(ra), len(rb))
+	if longer == 0 {
+		return 1
+	}
+	return 1 - float64(editDistance(ra, rb))/float64(longer)
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// corroborated reports whether a reference whose words match a work's title is
+// consistent with the work's first author and year, and, for a short title,
+// names at least one of them
+func corroborated(work Work, words []referenceWord, years []string, titleWords int) bool {
+	authorFound, yearFound := false, false
+	if family := NameKey(ParseAuthor(work.FirstAuthor).Family); family != "" {
+		if !containsName(words, family) {
+			return false
+		}
+		authorFound = true
+	}
+	if work.Year != "" && len(years) > 0 {
+		for _, year := range years {
+			if year == work.Year {
+				yearFound = true
+			}
+		}
+		if !yearFound {
+			return false
+		}
+	}
+	return authorFound || yearFound || titleWords >= minUncorroboratedTitleWords
+}
+
+// containsName reports whether the reference contains a name key, which may
+// span several words (e.g., "van der Berg")
+func containsName(words []referenceWord, key string) bool {
+	for start := range words {
+		var name strings.Builder
+		for _, word := range words[start:min(start+3, len(words))] {
+			name.WriteString(NameKey(word.text))
+			if name.String() == key {
+				return true
+			}
+		}
+	}
+	return false
+}
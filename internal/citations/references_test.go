@@ -0,0 +1,119 @@
+package citations
+
+import "testing"
+
+func TestMatchReference(t *testing.T) {
+	works := []Work{
+		{DocumentID: "archive", Title: "Memory and the Archive", Year: "2020", FirstAuthor: "Smith, Jane", DOI: "10.1000/jms.2020.1"},
+		{DocumentID: "carbon", Title: "Soil Carbon Dynamics: A Global Synthesis", Year: "2018", FirstAuthor: "Wei Chen"},
+		{DocumentID: "dutch", Title: "Trade Networks of the Early Modern Netherlands", Year: "1999", FirstAuthor: "van der Berg, Jan"},
+		{DocumentID: "anonymous", Title: "Annual Report on Coastal Erosion Monitoring"},
+		{DocumentID: "short", Title: "Capital"},
+	}
+
+	tests := []struct {
+		name     string
+		text     string
+		doi      string
+		expected ReferenceMatch
+	}{
+		{
+			name:     "parsed DOI",
+			text:     "Smith, J. Unrelated title. 2020.",
+			doi:      "https://doi.org/10.1000/JMS.2020.1",
+			expected: ReferenceMatch{DocumentID: "archive", Method: ReferenceMatchDOI, Score: 1},
+		},
+		{
+			name:     "DOI in the text",
+			text:     "Smith, J. (2020). Memory and the archive. J. Hist. doi:10.1000/jms.2020.1.",
+			expected: ReferenceMatch{DocumentID: "archive", Method: ReferenceMatchDOI, Score: 1},
+		},
+		{
+			name:     "exact title",
+			text:     "Smith, J. (2020). Memory and the Archive. Journal of History, 12, 1-20.",
+			expected: ReferenceMatch{DocumentID: "archive", Method: ReferenceMatchTitle, Score: 1},
+		},
+		{
+			name:     "quoted title",
+			text:     `Jane Smith, "Memory and the Archive," Journal of History 12 (2020): 1-20.`,
+			expected: ReferenceMatch{DocumentID: "archive", Method: ReferenceMatchTitle, Score: 1},
+		},
+		{
+			name:     "title without its subtitle",
+			text:     "Chen W. Soil carbon dynamics. Nature. 2018;5:1-9.",
+			expected: ReferenceMatch{DocumentID: "carbon", Method: ReferenceMatchTitle, Score: 1},
+		},
+		{
+			name:     "particle in the family name",
+			text:     "van der Berg, J. Trade networks of the early modern Netherlands. Leiden, 1999.",
+			expected: ReferenceMatch{DocumentID: "dutch", Method: ReferenceMatchTitle, Score: 1},
+		},
+		{
+			name:     "long title without author or year",
+			text:     "Annual report on coastal erosion monitoring. Ministry of Environment.",
+			expected: ReferenceMatch{DocumentID: "anonymous", Method: ReferenceMatchTitle, Score: 1},
+		},
+		{
+			name: "title continues past the work's title",
+			text: "Smith, J. (2020). Memory and the Archive of Empire. London: Verso.",
+		},
+		{
+			name: "title preceded by more words",
+			text: "Smith, J. (2020). On Memory and the Archive. London: Verso.",
+		},
+		{
+			name: "one word different",
+			text: "Smith, J. (2020). Memory and the Museum. London: Verso.",
+		},
+		{
+			name: "same title by another author",
+			text: "Jones, K. (2020). Memory and the Archive. London: Verso.",
+		},
+		{
+			name: "same title in another year",
+			text: "Smith, J. (2014). Memory and the Archive. London: Verso.",
+		},
+		{
+			name: "short title without corroboration",
+			text: "Anonymous. Capital. London, 1867.",
+		},
+		{
+			name: "no title",
+			text: "Ibid., 45.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := MatchReference(tt.text, tt.doi, works)
+			if ok != (tt.expected.DocumentID != "") {
+				t.Fatalf("Expected match %v, got %+v (ok=%v)", tt.expected, got, ok)
+			}
+			if ok && got != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestMatchReference_OCRDamage(t *testing.T) {
+	works := []Work{{DocumentID: "carbon", Title: "Soil Carbon Dynamics", Year: "2018", FirstAuthor: "Wei Chen"}}
+	got, ok := MatchReference("Chen, W. (2018). Soil Carbon Dynamlcs. Nature, 5, 1-9.", "", works)
+	if !ok || got.DocumentID != "carbon" || got.Method != ReferenceMatchTitle {
+		t.Fatalf("Expected a title match, got %+v (ok=%v)", got, ok)
+	}
+	if got.Score >= 1 || got.Score < minTitleSimilarity {
+		t.Errorf("Expected a score below 1 and at least %v, got %v", minTitleSimilarity, got.Score)
+	}
+}
+
+func TestMatchReference_MostSimilarWork(t *testing.T) {
+	works := []Work{
+		{DocumentID: "first", Title: "Soil Carbon Dynamics", Year: "2018"},
+		{DocumentID: "second", Title: "Soil Carbon Dynamlcs", Year: "2018"},
+	}
+	got, ok := MatchReference("Chen, W. (2018). Soil Carbon Dynamlcs. Nature.", "", works)
+	if !ok || got.DocumentID != "second" {
+		t.Errorf("Expected the exact title to win, got %+v (ok=%v)", got, ok)
+	}
+}
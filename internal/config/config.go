@@ -0,0 +1,238 @@
+// Package config provides the credentials the server calls OpenAI and Zotero
+// with, and where OpenAI requests are sent. They are read once from the
+// environment and an optional config file, and can be reloaded while the
+// server runs so keys can be rotated.
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// configFileEnv names the environment variable that points at the config file
+const configFileEnv = "ACADEMIC_MCP_CONFIG"
+
+// Names of the credentials, as environment variables and config file keys
+const (
+	OpenAIAPIKeyName      = "OPENAI_API_KEY"
+	ZoteroAPIKeyName      = "ZOTERO_API_KEY"
+	ZoteroLibraryTypeName = "ZOTERO_LIBRARY_TYPE"
+	ZoteroLibraryIDName   = "ZOTERO_LIBRARY_ID"
+
+	OpenAIBaseURLName         = "OPENAI_BASE_URL"
+	OpenAIOrganizationName    = "OPENAI_ORG_ID"
+	OpenAIProjectName         = "OPENAI_PROJECT"
+	AzureOpenAIEndpointName   = "AZURE_OPENAI_ENDPOINT"
+	AzureOpenAIAPIVersionName = "AZURE_OPENAI_API_VERSION"
+)
+
+// credentialNames are the settings a config file may hold
+var credentialNames = []string{
+	OpenAIAPIKeyName, ZoteroAPIKeyName, ZoteroLibraryTypeName, ZoteroLibraryIDName,
+	OpenAIBaseURLName, OpenAIOrganizationName, OpenAIProjectName, AzureOpenAIEndpointName, AzureOpenAIAPIVersionName,
+}
+
+// Credentials are the keys and default Zotero library used for upstream calls,
+// and the OpenAI endpoint settings
+type Credentials struct {
+	OpenAIAPIKey      string
+	ZoteroAPIKey      string
+	ZoteroLibraryType string
+	ZoteroLibraryID   string
+
+	// Where OpenAI requests go, if not to the OpenAI API: a base URL (e.g., an
+	// institution's gateway), with an optional organization and project, or
+	// an Azure OpenAI resource's endpoint and API version, which takes
+	// precedence
+	OpenAIBaseURL         string
+	OpenAIOrganization    string
+	OpenAIProject         string
+	AzureOpenAIEndpoint   string
+	AzureOpenAIAPIVersion string
+}
+
+// Provider holds the current Credentials. It is safe for concurrent use, and
+// Reload swaps the credentials for calls that read them afterwards; calls
+// already running keep the keys they started with.
+type Provider struct {
+	path string
+
+	mu          sync.RWMutex
+	credentials Credentials
+	loadedAt    time.Time
+}
+
+// NewProvider returns a provider with the credentials set in the environment,
+// overridden by those in the config file at path, if path is not empty. If the
+// file cannot be read, the provider holds the environment's credentials alone
+// and the error is returned with it.
+func NewProvider(path string) (*Provider, error) {
+	p := &Provider{path: path}
+	credentials, err := load(path)
+	if err != nil {
+		credentials, _ = load("")
+	}
+	p.credentials = credentials
+	p.loadedAt = time.Now()
+	return p, err
+}
+
+// ConfiguredProvider returns NewProvider for the config file named by
+// ACADEMIC_MCP_CONFIG, if any
+func ConfiguredProvider() (*Provider, error) {
+	return NewProvider(strings.TrimSpace(os.Getenv(configFileEnv)))
+}
+
+// Path returns the config file the provider reads, or "" if it has none
+func (p *Provider) Path() string {
+	return p.path
+}
+
+// Credentials returns the current credentials
+func (p *Provider) Credentials() Credentials {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.credentials
+}
+
+// LoadedAt returns when the current credentials were read
+func (p *Provider) LoadedAt() time.Time {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.loadedAt
+}
+
+// Reload reads the environment and config file again and replaces the
+// credentials, returning the names of the settings that changed. If the file
+// cannot be read, the current credentials are kept.
+func (p *Provider) Reload() ([]string, error) {
+	credentials, err := load(p.path)
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	changed := changedNames(p.credentials, credentials)
+	p.credentials = credentials
+	p.loadedAt = time.Now()
+	return changed, nil
+}
+
+// OpenAIAPIKey returns the OpenAI API key, or an error with the configuration
+// code if none is set
+func (p *Provider) OpenAIAPIKey() (string, error) {
+	return required(p.Credentials().OpenAIAPIKey, OpenAIAPIKeyName)
+}
+
+// ZoteroAPIKey returns the Zotero API key, or an error with the configuration
+// code if none is set
+func (p *Provider) ZoteroAPIKey() (string, error) {
+	return required(p.Credentials().ZoteroAPIKey, ZoteroAPIKeyName)
+}
+
+func required(value, name string) (string, error) {
+	if value == "" {
+		return "", models.WithErrorCode(models.ErrorConfiguration, fmt.Errorf("%s is not set in the environment or config file", name))
+	}
+	return value, nil
+}
+
+// load reads the credentials from the environment and the config file at path
+func load(path string) (Credentials, error) {
+	values := make(map[string]string, len(credentialNames))
+	for _, name := range credentialNames {
+		values[name] = strings.TrimSpace(os.Getenv(name))
+	}
+	if path != "" {
+		file, err := readFile(path)
+		if err != nil {
+			return Credentials{}, models.WithErrorCode(models.ErrorConfiguration, err)
+		}
+		for name, value := range file {
+			if value = strings.TrimSpace(value); value != "" {
+				values[name] = value
+			}
+		}
+	}
+	return Credentials{
+		OpenAIAPIKey:      values[OpenAIAPIKeyName],
+		ZoteroAPIKey:      values[ZoteroAPIKeyName],
+		ZoteroLibraryType: values[ZoteroLibraryTypeName],
+		ZoteroLibraryID:   values[ZoteroLibraryIDName],
+
+		OpenAIBaseURL:         values[OpenAIBaseURLName],
+		OpenAIOrganization:    values[OpenAIOrganizationName],
+		OpenAIProject:         values[OpenAIProjectName],
+		AzureOpenAIEndpoint:   values[AzureOpenAIEndpointName],
+		AzureOpenAIAPIVersion: values[AzureOpenAIAPIVersionName],
+	}, nil
+}
+
+// readFile reads a config file: a JSON object whose keys are setting names
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	var values map[string]string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	for name := range values {
+		if !slices.Contains(credentialNames, name) {
+			return nil, fmt.Errorf("invalid config file %s: unknown setting %q (expected one of %s)", path, name, strings.Join(credentialNames, ", "))
+		}
+	}
+	return values, nil
+}
+
+// changedNames returns the names of the settings that differ between a and b
+func changedNames(a, b Credentials) []string {
+	var changed []string
+	for _, field := range []struct {
+		name string
+		a, b string
+	}{
+		{OpenAIAPIKeyName, a.OpenAIAPIKey, b.OpenAIAPIKey},
+		{ZoteroAPIKeyName, a.ZoteroAPIKey, b.ZoteroAPIKey},
+		{ZoteroLibraryTypeName, a.ZoteroLibraryType, b.ZoteroLibraryType},
+		{ZoteroLibraryIDName, a.ZoteroLibraryID, b.ZoteroLibraryID},
+		{OpenAIBaseURLName, a.OpenAIBaseURL, b.OpenAIBaseURL},
+		{OpenAIOrganizationName, a.OpenAIOrganization, b.OpenAIOrganization},
+		{OpenAIProjectName, a.OpenAIProject, b.OpenAIProject},
+		{AzureOpenAIEndpointName, a.AzureOpenAIEndpoint, b.AzureOpenAIEndpoint},
+		{AzureOpenAIAPIVersionName, a.AzureOpenAIAPIVersion, b.AzureOpenAIAPIVersion},
+	} {
+		if field.a != field.b {
+			changed = append(changed, field.name)
+		}
+	}
+	return changed
+}
+
+// contextKey is the key of the provider stored in a context
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying p, for code further down the call
+// to take credentials from
+func NewContext(ctx context.Context, p *Provider) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext returns the provider carried by ctx or, if it has none, one
+// holding the credentials set in the environment now
+func FromContext(ctx context.Context) *Provider {
+	if p, ok := ctx.Value(contextKey{}).(*Provider); ok && p != nil {
+		return p
+	}
+	p, _ := NewProvider("")
+	return p
+}
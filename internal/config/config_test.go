@@ -0,0 +1,170 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// clearCredentials unsets every credential in the environment
+func clearCredentials(t *testing.T) {
+	t.Helper()
+	for _, name := range credentialNames {
+		t.Setenv(name, "")
+	}
+}
+
+// isConfigurationError reports whether err has the configuration error code
+func isConfigurationError(err error) bool {
+	var coded *models.CodedError
+	return errors.As(err, &coded) && coded.Code == models.ErrorConfiguration
+}
+
+// writeConfig writes a config file to path
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	clearCredentials(t)
+	t.Setenv(OpenAIAPIKeyName, "env-openai")
+	t.Setenv(ZoteroLibraryIDName, "111")
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	tests := []struct {
+		name     string
+		file     string
+		expected Credentials
+		wantErr  bool
+	}{
+		{
+			name:     "Environment only",
+			expected: Credentials{OpenAIAPIKey: "env-openai", ZoteroLibraryID: "111"},
+		},
+		{
+			name:     "File overrides the environment",
+			file:     `{"OPENAI_API_KEY": "file-openai", "ZOTERO_API_KEY": "file-zotero", "ZOTERO_LIBRARY_ID": ""}`,
+			expected: Credentials{OpenAIAPIKey: "file-openai", ZoteroAPIKey: "file-zotero", ZoteroLibraryID: "111"},
+		},
+		{
+			name:     "OpenAI endpoint settings",
+			file:     `{"OPENAI_BASE_URL": "https://gateway.example.edu/v1", "OPENAI_ORG_ID": "org-1", "OPENAI_PROJECT": "proj-1", "AZURE_OPENAI_API_VERSION": "2025-04-01-preview"}`,
+			expected: Credentials{OpenAIAPIKey: "env-openai", ZoteroLibraryID: "111", OpenAIBaseURL: "https://gateway.example.edu/v1", OpenAIOrganization: "org-1", OpenAIProject: "proj-1", AzureOpenAIAPIVersion: "2025-04-01-preview"},
+		},
+		{
+			name:     "Invalid JSON falls back to the environment",
+			file:     `{"OPENAI_API_KEY": `,
+			expected: Credentials{OpenAIAPIKey: "env-openai", ZoteroLibraryID: "111"},
+			wantErr:  true,
+		},
+		{
+			name:     "Unknown setting falls back to the environment",
+			file:     `{"OPENAI_KEY": "typo"}`,
+			expected: Credentials{OpenAIAPIKey: "env-openai", ZoteroLibraryID: "111"},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := ""
+			if tt.file != "" {
+				writeConfig(t, path, tt.file)
+				file = path
+			}
+			provider, err := NewProvider(file)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if err != nil && !isConfigurationError(err) {
+				t.Errorf("Expected a configuration error, got %v", err)
+			}
+			if got := provider.Credentials(); got != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+
+	if _, err := NewProvider(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected an error for a missing config file")
+	}
+}
+
+func TestProviderReload(t *testing.T) {
+	clearCredentials(t)
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeConfig(t, path, `{"OPENAI_API_KEY": "old-key", "ZOTERO_LIBRARY_ID": "111"}`)
+	provider, err := NewProvider(path)
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	loadedAt := provider.LoadedAt()
+
+	// Rotating the key swaps it for later calls
+	writeConfig(t, path, `{"OPENAI_API_KEY": "new-key", "ZOTERO_LIBRARY_ID": "111"}`)
+	changed, err := provider.Reload()
+	if err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if !reflect.DeepEqual(changed, []string{OpenAIAPIKeyName}) {
+		t.Errorf("Expected only the OpenAI key to change, got %v", changed)
+	}
+	if key, err := provider.OpenAIAPIKey(); err != nil || key != "new-key" {
+		t.Errorf("Expected the new key, got %q (%v)", key, err)
+	}
+	if provider.LoadedAt().Before(loadedAt) {
+		t.Errorf("Expected the load time to advance")
+	}
+
+	// An unreadable file keeps the current credentials
+	writeConfig(t, path, `not json`)
+	if _, err := provider.Reload(); err == nil {
+		t.Error("Expected an error for an invalid config file")
+	}
+	if key, _ := provider.OpenAIAPIKey(); key != "new-key" {
+		t.Errorf("Expected the key to be kept after a failed reload, got %q", key)
+	}
+
+	// Removing the key makes calls that need it fail with the configuration code
+	writeConfig(t, path, `{"ZOTERO_LIBRARY_ID": "111"}`)
+	if _, err := provider.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if _, err := provider.OpenAIAPIKey(); !isConfigurationError(err) {
+		t.Errorf("Expected a configuration error for a missing key, got %v", err)
+	}
+	if _, err := provider.ZoteroAPIKey(); !isConfigurationError(err) {
+		t.Errorf("Expected a configuration error for a missing Zotero key, got %v", err)
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	clearCredentials(t)
+	t.Setenv(OpenAIAPIKeyName, "env-key")
+
+	// Without a provider, the environment is read
+	if key, err := FromContext(context.Background()).OpenAIAPIKey(); err != nil || key != "env-key" {
+		t.Errorf("Expected the environment's key, got %q (%v)", key, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeConfig(t, path, `{"OPENAI_API_KEY": "file-key"}`)
+	provider, err := NewProvider(path)
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	ctx := NewContext(context.Background(), provider)
+	if FromContext(ctx) != provider {
+		t.Error("Expected the provider carried by the context")
+	}
+	if key, _ := FromContext(ctx).OpenAIAPIKey(); key != "file-key" {
+		t.Errorf("Expected the file's key, got %q", key)
+	}
+}
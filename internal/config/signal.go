@@ -0,0 +1,42 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+)
+
+// ReloadOnHangup reloads p each time the process receives SIGHUP, until ctx is
+// done. A failed reload is logged and leaves the credentials unchanged.
+func ReloadOnHangup(ctx context.Context, p *Provider, log logger.Logger) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(hangup)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hangup:
+				changed, err := p.Reload()
+				if err != nil {
+					log.Error("Failed to reload configuration: %v", err)
+					continue
+				}
+				log.Info("Reloaded configuration on SIGHUP; changed: %s", changedList(changed))
+			}
+		}
+	}()
+}
+
+// changedList formats the names of changed settings for the log
+func changedList(changed []string) string {
+	if len(changed) == 0 {
+		return "none"
+	}
+	return strings.Join(changed, ", ")
+}
@@ -0,0 +1,151 @@
+package documents
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+const (
+	// arXivURLEnv names the environment variable that overrides the arXiv base URL
+	arXivURLEnv = "ACADEMIC_MCP_ARXIV_URL"
+
+	// defaultArXivURL serves both the API and PDFs, and is the host arXiv asks
+	// programmatic clients to use
+	defaultArXivURL = "https://export.arxiv.org"
+
+	// MetadataSourceArXiv marks metadata retrieved from the arXiv API
+	MetadataSourceArXiv = "arxiv"
+)
+
+// ArXivClient fetches papers and their metadata from arXiv
+type ArXivClient struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// NewArXivClient creates a client for https://export.arxiv.org, or
+// ACADEMIC_MCP_ARXIV_URL if set
+func NewArXivClient() *ArXivClient {
+	baseURL := strings.TrimSuffix(strings.TrimSpace(os.Getenv(arXivURLEnv)), "/")
+	if baseURL == "" {
+		baseURL = defaultArXivURL
+	}
+	return &ArXivClient{BaseURL: baseURL, HTTPClient: http.DefaultClient}
+}
+
+// FetchPDF downloads the PDF of an arXiv paper (e.g., "2101.01234v2"; the
+// latest version if the identifier has none), with where and when it was
+// fetched from
+func (c *ArXivClient) FetchPDF(ctx context.Context, arXivID string) ([]byte, *models.FetchInfo, error) {
+	pdfURL := c.BaseURL + "/pdf/" + arXivID
+	resp, err := c.get(ctx, pdfURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("arXiv returned status %d for the PDF of %s", resp.StatusCode, arXivID)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, describeFetch(data, pdfURL, resp), nil
+}
+
+// arXivFeed is the Atom feed the arXiv API answers queries with
+type arXivFeed struct {
+	Entries []arXivEntry `xml:"http://www.w3.org/2005/Atom entry"`
+}
+
+type arXivEntry struct {
+	ID        string `xml:"http://www.w3.org/2005/Atom id"`
+	Title     string `xml:"http://www.w3.org/2005/Atom title"`
+	Summary   string `xml:"http://www.w3.org/2005/Atom summary"`
+	Published string `xml:"http://www.w3.org/2005/Atom published"`
+	Authors   []struct {
+		Name string `xml:"http://www.w3.org/2005/Atom name"`
+	} `xml:"http://www.w3.org/2005/Atom author"`
+	DOI             string `xml:"http://arxiv.org/schemas/atom doi"`
+	PrimaryCategory struct {
+		Term string `xml:"term,attr"`
+	} `xml:"http://arxiv.org/schemas/atom primary_category"`
+}
+
+// FetchMetadata looks up an arXiv paper with the arXiv API. The metadata has
+// the paper's title, authors, abstract, submission date, abstract page URL,
+// and the DOI of its published version if arXiv knows it, and is tagged with
+// the paper's primary category (e.g., "cs.CL").
+func (c *ArXivClient) FetchMetadata(ctx context.Context, arXivID string) (*models.ItemMetadata, error) {
+	resp, err := c.get(ctx, c.BaseURL+"/api/query?"+url.Values{"id_list": {arXivID}}.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("arXiv API returned status %d for %s", resp.StatusCode, arXivID)
+	}
+
+	var feed arXivFeed
+	if err := xml.NewDecoder(resp.Body).Decode(&feed); err != nil {
+		return nil, fmt.Errorf("failed to decode arXiv API response for %s: %w", arXivID, err)
+	}
+	if len(feed.Entries) == 0 {
+		return nil, fmt.Errorf("arXiv has no paper %s", arXivID)
+	}
+	entry := feed.Entries[0]
+	// Errors come as an entry whose ID is an error page and whose summary
+	// describes the error
+	if strings.Contains(entry.ID, "/api/errors") {
+		return nil, fmt.Errorf("arXiv API rejected %s: %s", arXivID, collapseSpace(entry.Summary))
+	}
+
+	metadata := &models.ItemMetadata{
+		Title:          collapseSpace(entry.Title),
+		Abstract:       collapseSpace(entry.Summary),
+		DOI:            strings.TrimSpace(entry.DOI),
+		ItemType:       "preprint",
+		Publisher:      "arXiv",
+		URL:            "https://arxiv.org/abs/" + arXivID,
+		MetadataSource: MetadataSourceArXiv,
+	}
+	// Dates are timestamps (2021-01-04T18:59:59Z)
+	if published, _, _ := strings.Cut(strings.TrimSpace(entry.Published), "T"); published != "" {
+		metadata.PublicationDate = published
+	}
+	for _, author := range entry.Authors {
+		if name := collapseSpace(author.Name); name != "" {
+			metadata.Authors = append(metadata.Authors, name)
+		}
+	}
+	if category := strings.TrimSpace(entry.PrimaryCategory.Term); category != "" {
+		metadata.Tags = []string{category}
+	}
+	return metadata, nil
+}
+
+func (c *ArXivClient) get(ctx context.Context, requestURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Do(req)
+}
+
+// collapseSpace joins the words of s with single spaces, as arXiv wraps
+// titles and abstracts over several lines
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
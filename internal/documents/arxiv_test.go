@@ -0,0 +1,120 @@
+package documents
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// fakeArXiv serves the Atom fixtures in testdata/arxiv by the queried ID
+// ("error" and "empty" for those responses) and a PDF for every paper,
+// recording the paths requested
+func fakeArXiv(t *testing.T) (*httptest.Server, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.URL.Path)
+		mu.Unlock()
+		switch {
+		case r.URL.Path == "/api/query":
+			fixture, err := os.ReadFile(filepath.Join("testdata", "arxiv", r.URL.Query().Get("id_list")+".xml"))
+			if err != nil {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", "application/atom+xml")
+			w.Write(fixture)
+		case strings.HasPrefix(r.URL.Path, "/pdf/"):
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Write([]byte("%PDF-1.5\nThe paper " + strings.TrimPrefix(r.URL.Path, "/pdf/")))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(requests)
+	}
+}
+
+func TestArXivClient_FetchMetadata(t *testing.T) {
+	server, _ := fakeArXiv(t)
+	client := &ArXivClient{BaseURL: server.URL, HTTPClient: server.Client()}
+	ctx := context.Background()
+
+	metadata, err := client.FetchMetadata(ctx, "2101.01234v2")
+	if err != nil {
+		t.Fatalf("FetchMetadata failed: %v", err)
+	}
+	if metadata.Title != "Archival Practices in Early Modern Natural History" {
+		t.Errorf("Expected the title on one line, got %q", metadata.Title)
+	}
+	if !slices.Equal(metadata.Authors, []string{"Jane Smith", "Robert Jones"}) {
+		t.Errorf("Unexpected authors: %q", metadata.Authors)
+	}
+	if metadata.Abstract != "We examine how naturalists of the seventeenth century kept records of their observations." {
+		t.Errorf("Unexpected abstract: %q", metadata.Abstract)
+	}
+	if metadata.PublicationDate != "2021-01-04" || metadata.DOI != "10.1000/hist.2021.42" || metadata.URL != "https://arxiv.org/abs/2101.01234v2" {
+		t.Errorf("Unexpected date, DOI, or URL: %+v", metadata)
+	}
+	if metadata.ItemType != "preprint" || metadata.MetadataSource != MetadataSourceArXiv || !slices.Equal(metadata.Tags, []string{"physics.hist-ph"}) {
+		t.Errorf("Unexpected item type, source, or category: %+v", metadata)
+	}
+
+	if _, err := client.FetchMetadata(ctx, "error"); err == nil || !strings.Contains(err.Error(), "incorrect id format") {
+		t.Errorf("Expected the API's error, got %v", err)
+	}
+	if _, err := client.FetchMetadata(ctx, "empty"); err == nil {
+		t.Error("Expected an error for an unknown paper")
+	}
+	if _, err := client.FetchMetadata(ctx, "missing"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected the status in the error, got %v", err)
+	}
+}
+
+func TestGetDataWithMetadata_ArXiv(t *testing.T) {
+	server, requests := fakeArXiv(t)
+	t.Setenv("ACADEMIC_MCP_ARXIV_URL", server.URL)
+
+	data, metadata, warning, err := GetDataWithMetadata(context.Background(), models.SourceInfo{URL: "https://arxiv.org/abs/2101.01234v2"})
+	if err != nil {
+		t.Fatalf("GetDataWithMetadata failed: %v", err)
+	}
+	if data.Type != "pdf" || !strings.Contains(string(data.Data), "2101.01234v2") {
+		t.Errorf("Expected the paper's PDF, got %s: %q", data.Type, data.Data)
+	}
+	if metadata == nil || metadata.Title != "Archival Practices in Early Modern Natural History" || warning != nil {
+		t.Errorf("Expected the arXiv metadata, got %+v (warning %+v)", metadata, warning)
+	}
+	if fetch := data.Fetch; fetch == nil || fetch.SourceURL != server.URL+"/pdf/2101.01234v2" || fetch.SHA256 != data.Checksum() || fetch.Size != int64(len(data.Data)) || fetch.FetchedAt == "" {
+		t.Errorf("Expected the PDF's fetch info, got %+v", fetch)
+	}
+	if got := requests(); !slices.Equal(got, []string{"/pdf/2101.01234v2", "/api/query"}) {
+		t.Errorf("Expected the PDF and the API to be requested, got %q", got)
+	}
+
+	// Without metadata from the API the paper is still fetched
+	data, metadata, warning, err = GetDataWithMetadata(context.Background(), models.SourceInfo{URL: "https://arxiv.org/pdf/2912.99999.pdf"})
+	if err != nil {
+		t.Fatalf("GetDataWithMetadata failed: %v", err)
+	}
+	if data.Type != "pdf" || metadata != nil {
+		t.Errorf("Expected the PDF without metadata, got %s and %+v", data.Type, metadata)
+	}
+	if warning == nil || warning.Source != "arxiv" || !strings.Contains(warning.Message, "404") {
+		t.Errorf("Expected a warning that the arXiv metadata could not be fetched, got %+v", warning)
+	}
+}
@@ -0,0 +1,177 @@
+package documents
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/citations"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// MetadataSourceBasic marks metadata found by ParseDocumentBasic's patterns
+// rather than read by the model
+const MetadataSourceBasic = "extracted-basic"
+
+// BasicParserVersion is the parser version recorded for documents parsed by
+// ParseDocumentBasic. It names no model or prompt version.
+const BasicParserVersion = "basic-1"
+
+// basicDOIPattern matches a DOI in running text. Trailing punctuation is trimmed
+// from the match, as it usually ends the sentence rather than the DOI.
+var basicDOIPattern = regexp.MustCompile(`(?i)\b10\.\d{4,9}(\.\d+)*/[^\s"<>]+`)
+
+// arXivIDPattern matches an arXiv identifier, new style (2101.01234v2) or old
+// style (hep-th/9901001), after its "arXiv:" label
+var arXivIDPattern = regexp.MustCompile(`(?i)\barXiv:\s?(\d{4}\.\d{4,5}|[a-z\-]+(\.[A-Z]{2})?/\d{7})(v\d+)?`)
+
+// titleExcludedPattern matches first-page lines that are running heads, journal
+// details, or notices rather than the title
+var titleExcludedPattern = regexp.MustCompile(`(?i)(doi|https?:|www\.|©|copyright|journal|proceedings|vol\.|volume|issn|isbn|arxiv|received|accepted|published|preprint|licen[cs]e|page \d|pp\. \d|^\d+$)`)
+
+// Bounds on the length of a line taken for the title
+const (
+	titleMinLength = 10
+	titleMaxLength = 250
+	titleMaxLines  = 3
+)
+
+// ParseDocumentBasic parses a document without the model: the text of each PDF
+// page is extracted from its content stream (see ExtractPDFText), and text,
+// Markdown, RTF, HTML, and unrecognized XML documents become a single page of
+// their text. The metadata is what BasicMetadata finds in the first page, and
+// there are no images, tables, references, or notes. The item is marked Basic,
+// so a later parse with the model can replace it. JATS and TEI documents are
+// parsed in full from their markup by ParseStructuredXML instead.
+func ParseDocumentBasic(data models.DocumentData) (*models.ParsedItem, error) {
+	item := &models.ParsedItem{Basic: true}
+	switch data.Type {
+	case "pdf":
+		pages, err := ExtractPDFText(data)
+		if err != nil {
+			return nil, models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("failed to extract PDF text: %w", err))
+		}
+		item.Pages = pages
+		if imageOnly, err := DetectImageOnlyPages(data); err == nil {
+			item.IsScanned = IsScannedDocument(imageOnly)
+		}
+	case "jats", "tei":
+		// Structured XML is read from its markup, which is all the model would do
+		return ParseStructuredXML(data)
+	case "md", "txt", "xml":
+		item.Pages = []string{string(data.Data)}
+	case "rtf":
+		text, err := RTFToText(data.Data)
+		if err != nil {
+			return nil, models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("failed to read RTF: %w", err))
+		}
+		item.Pages = []string{text}
+	case "html":
+		mode, err := ConfiguredHTMLExtractionMode()
+		if err != nil {
+			return nil, models.WithErrorCode(models.ErrorInvalidInput, err)
+		}
+		text, err := PreprocessHTML(data.Data, mode)
+		if err != nil {
+			return nil, models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("failed to convert HTML: %w", err))
+		}
+		item.Pages = []string{text}
+	case "":
+		return nil, models.WithErrorCode(models.ErrorInvalidInput, errors.New("the basic parser needs a document type"))
+	default:
+		return nil, models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("the basic parser does not support %s documents", data.Type))
+	}
+
+	item.PageNumbers = make([]string, len(item.Pages))
+	for i := range item.Pages {
+		item.PageNumbers[i] = fmt.Sprint(data.Pages.Offset() + i + 1)
+	}
+	firstPage := ""
+	for _, page := range item.Pages {
+		if strings.TrimSpace(page) != "" {
+			firstPage = page
+			break
+		}
+	}
+	item.Metadata = BasicMetadata(firstPage)
+	item.Provenance = &models.Provenance{ParserVersion: BasicParserVersion}
+	return item, nil
+}
+
+// BasicMetadata finds what it can of a document's metadata in the text of its
+// first page: a DOI, or failing that an arXiv identifier (as its arXiv DOI and
+// abstract page URL), and a title, taken from the first lines that do not look
+// like a running head or journal details. Only the first page is searched, as
+// later pages cite the DOIs of other works.
+func BasicMetadata(firstPage string) models.ItemMetadata {
+	metadata := models.ItemMetadata{MetadataSource: MetadataSourceBasic}
+
+	if match := basicDOIPattern.FindString(firstPage); match != "" {
+		if doi, ok := citations.NormalizeDOI(strings.TrimRight(match, ".,;:)]}")); ok {
+			metadata.DOI = doi
+		}
+	}
+	if match := arXivIDPattern.FindStringSubmatch(firstPage); match != nil {
+		id := match[1]
+		metadata.URL = "https://arxiv.org/abs/" + id
+		if metadata.DOI == "" {
+			metadata.DOI = "10.48550/arxiv." + strings.ToLower(id)
+		}
+		// New-style identifiers begin with the year and month of submission
+		if id[4] == '.' {
+			metadata.PublicationDate = "20" + id[:2]
+		}
+	}
+
+	metadata.Title = basicTitle(firstPage)
+	return metadata
+}
+
+// basicTitle returns the first run of lines of a first page that could be its
+// title. A line continues the title if it starts in lower case or the title so
+// far ends with a colon, up to titleMaxLines lines.
+func basicTitle(firstPage string) string {
+	var title []string
+	for _, line := range strings.Split(firstPage, "\n") {
+		line = strings.TrimSpace(line)
+		if len(title) > 0 {
+			last := title[len(title)-1]
+			if len(title) < titleMaxLines && line != "" && (startsLower(line) || strings.HasSuffix(last, ":")) && isTitleLine(line, 1) {
+				title = append(title, line)
+				continue
+			}
+			break
+		}
+		if isTitleLine(line, titleMinLength) {
+			title = append(title, line)
+		}
+	}
+	return strings.Join(title, " ")
+}
+
+// isTitleLine reports whether a line could be (part of) a title: long enough,
+// not too long, mostly letters, and not journal details or a notice
+func isTitleLine(line string, minLength int) bool {
+	if len(line) < minLength || len(line) > titleMaxLength || titleExcludedPattern.MatchString(line) {
+		return false
+	}
+	letters, digits := 0, 0
+	for _, r := range line {
+		switch {
+		case unicode.IsLetter(r):
+			letters++
+		case unicode.IsDigit(r):
+			digits++
+		}
+	}
+	return letters > 2*digits && letters >= minLength/2
+}
+
+func startsLower(line string) bool {
+	for _, r := range line {
+		return unicode.IsLower(r)
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package documents
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestBasicMetadata(t *testing.T) {
+	tests := []struct {
+		name      string
+		firstPage string
+		expected  models.ItemMetadata
+	}{
+		{
+			name:      "title and DOI",
+			firstPage: "Journal of Memory Studies, Vol. 12\nMemory and the Archive\nJane Smith\nhttps://doi.org/10.1234/JMS.2020.15.",
+			expected:  models.ItemMetadata{Title: "Memory and the Archive", DOI: "10.1234/jms.2020.15"},
+		},
+		{
+			name:      "wrapped title",
+			firstPage: "Deep Learning for Soil Carbon:\nA Review of Methods\nand Datasets\nA. Author",
+			expected:  models.ItemMetadata{Title: "Deep Learning for Soil Carbon: A Review of Methods and Datasets"},
+		},
+		{
+			name:      "arXiv identifier",
+			firstPage: "arXiv:2101.01234v2 [cs.CL] 5 Feb 2021\nAttention Over Archives\nJ. Smith",
+			expected:  models.ItemMetadata{Title: "Attention Over Archives", DOI: "10.48550/arxiv.2101.01234", URL: "https://arxiv.org/abs/2101.01234", PublicationDate: "2021"},
+		},
+		{
+			name:      "old-style arXiv identifier with DOI",
+			firstPage: "String Dualities\narXiv:hep-th/9901001\ndoi:10.1016/S0550-3213(99)00001-1",
+			expected:  models.ItemMetadata{Title: "String Dualities", DOI: "10.1016/s0550-3213(99)00001-1", URL: "https://arxiv.org/abs/hep-th/9901001"},
+		},
+		{
+			name:      "nothing found",
+			firstPage: "12\n2020",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BasicMetadata(tt.firstPage)
+			if got.Title != tt.expected.Title || got.DOI != tt.expected.DOI || got.URL != tt.expected.URL || got.PublicationDate != tt.expected.PublicationDate {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+			if got.MetadataSource != MetadataSourceBasic {
+				t.Errorf("Expected metadata source %q, got %q", MetadataSourceBasic, got.MetadataSource)
+			}
+		})
+	}
+}
+
+func TestParseDocumentBasic(t *testing.T) {
+	pdfBytes := buildTestPdf(
+		"BT /F1 18 Tf 72 720 Td (Memory and the Archive) Tj 0 -20 Td (DOI: 10.1234/jms.2020.15) Tj ET",
+		"q 612 0 0 792 0 0 cm /Im0 Do Q",
+		"BT /F1 12 Tf 72 720 Td (The archive remembers.) Tj ET",
+	)
+	item, err := ParseDocumentBasic(models.DocumentData{Data: pdfBytes, Type: "pdf"})
+	if err != nil {
+		t.Fatalf("ParseDocumentBasic failed: %v", err)
+	}
+	if !item.Basic || len(item.Pages) != 3 || item.Pages[1] != "" || item.Pages[2] != "The archive remembers." {
+		t.Fatalf("Expected three basic pages, got %+v", item)
+	}
+	if item.Metadata.Title != "Memory and the Archive" || item.Metadata.DOI != "10.1234/jms.2020.15" {
+		t.Errorf("Unexpected metadata: %+v", item.Metadata)
+	}
+	if len(item.PageNumbers) != 3 || item.PageNumbers[2] != "3" || item.Provenance == nil || item.Provenance.ParserVersion != BasicParserVersion {
+		t.Errorf("Expected page numbers and provenance, got %v and %+v", item.PageNumbers, item.Provenance)
+	}
+
+	for _, data := range []models.DocumentData{
+		{Data: []byte("PK\x03\x04"), Type: "docx"},
+		{Data: []byte("This is not a PDF"), Type: "pdf"},
+	} {
+		_, err := ParseDocumentBasic(data)
+		var coded *models.CodedError
+		if !errors.As(err, &coded) || coded.Code != models.ErrorInvalidInput {
+			t.Errorf("Expected invalid_input for a %s document, got %v", data.Type, err)
+		}
+	}
+}
+
+func TestParseDocumentBasic_Samples(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("..", "samples", "*.pdf"))
+	if err != nil {
+		t.Fatalf("Failed to list sample PDFs: %v", err)
+	}
+	if len(files) == 0 {
+		t.Skip("No sample PDFs found in samples directory")
+	}
+
+	for _, filePath := range files {
+		t.Run(filepath.Base(filePath), func(t *testing.T) {
+			pdfBytes, err := os.ReadFile(filePath)
+			if err != nil {
+				t.Fatalf("Failed to read PDF file %s: %v", filePath, err)
+			}
+			item, err := ParseDocumentBasic(models.DocumentData{Data: pdfBytes, Type: "pdf"})
+			if err != nil {
+				t.Fatalf("ParseDocumentBasic failed: %v", err)
+			}
+			pages, err := SplitPdf(models.DocumentData{Data: pdfBytes, Type: "pdf"})
+			if err != nil {
+				t.Fatalf("SplitPdf failed: %v", err)
+			}
+			if len(item.Pages) != len(pages) {
+				t.Errorf("Expected %d pages, got %d", len(pages), len(item.Pages))
+			}
+			t.Logf("Title %q, DOI %q", item.Metadata.Title, item.Metadata.DOI)
+		})
+	}
+}
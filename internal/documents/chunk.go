@@ -0,0 +1,97 @@
+package documents
+
+import (
+	"strings"
+)
+
+// SplitMarkdownChunks splits a markdown document into chunks of at most maxTokens
+// tokens as measured by countTokens. Chunks break at heading boundaries where
+// possible; sections that are too large on their own are split between paragraphs,
+// and paragraphs that are still too large are split between lines. Headings inside
+// fenced code blocks are not treated as boundaries. No text is dropped, although
+// blank lines at split points are not preserved.
+func SplitMarkdownChunks(markdown string, maxTokens int, countTokens func(string) int) []string {
+	if countTokens(markdown) <= maxTokens {
+		return []string{markdown}
+	}
+
+	var chunks []string
+	var current []string
+	currentTokens := 0
+	flush := func() {
+		if len(current) > 0 {
+			chunks = append(chunks, strings.Join(current, "\n"))
+			current = nil
+			currentTokens = 0
+		}
+	}
+
+	for _, section := range splitMarkdownSections(markdown) {
+		for _, piece := range fitToTokenLimit(section, maxTokens, countTokens) {
+			pieceTokens := countTokens(piece)
+			if len(current) > 0 && currentTokens+pieceTokens > maxTokens {
+				flush()
+			}
+			current = append(current, piece)
+			currentTokens += pieceTokens
+		}
+	}
+	flush()
+
+	return chunks
+}
+
+// splitMarkdownSections splits markdown into sections that each start at a heading
+// (the first section may be preamble text without a heading)
+func splitMarkdownSections(markdown string) []string {
+	var sections []string
+	var current []string
+	inFence := false
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		}
+		if !inFence && strings.HasPrefix(trimmed, "#") && len(current) > 0 {
+			sections = append(sections, strings.Join(current, "\n"))
+			current = nil
+		}
+		current = append(current, line)
+	}
+	return append(sections, strings.Join(current, "\n"))
+}
+
+// fitToTokenLimit splits text that exceeds maxTokens into pieces, first between
+// paragraphs and then between lines. A single line longer than maxTokens is
+// returned as is.
+func fitToTokenLimit(text string, maxTokens int, countTokens func(string) int) []string {
+	if countTokens(text) <= maxTokens {
+		return []string{text}
+	}
+	if pieces := groupUnits(strings.Split(text, "\n\n"), "\n\n", maxTokens, countTokens); len(pieces) > 1 {
+		var result []string
+		for _, piece := range pieces {
+			result = append(result, fitToTokenLimit(piece, maxTokens, countTokens)...)
+		}
+		return result
+	}
+	return groupUnits(strings.Split(text, "\n"), "\n", maxTokens, countTokens)
+}
+
+// groupUnits greedily joins consecutive units with sep into groups of at most maxTokens
+func groupUnits(units []string, sep string, maxTokens int, countTokens func(string) int) []string {
+	var groups []string
+	var current []string
+	currentTokens := 0
+	for _, unit := range units {
+		unitTokens := countTokens(unit) + countTokens(sep)
+		if len(current) > 0 && currentTokens+unitTokens > maxTokens {
+			groups = append(groups, strings.Join(current, sep))
+			current = nil
+			currentTokens = 0
+		}
+		current = append(current, unit)
+		currentTokens += unitTokens
+	}
+	return append(groups, strings.Join(current, sep))
+}
@@ -0,0 +1,77 @@
+package documents
+
+import (
+	"strings"
+	"testing"
+)
+
+// wordCount is a simple token counter for chunking tests
+func wordCount(s string) int {
+	return len(strings.Fields(s))
+}
+
+func TestSplitMarkdownChunks(t *testing.T) {
+	section := func(heading string, words int) string {
+		return heading + "\n\n" + strings.TrimSpace(strings.Repeat("word ", words))
+	}
+
+	t.Run("fits in one chunk", func(t *testing.T) {
+		doc := section("# Title", 10)
+		chunks := SplitMarkdownChunks(doc, 100, wordCount)
+		if len(chunks) != 1 || chunks[0] != doc {
+			t.Errorf("Expected the document as a single chunk, got %d chunks", len(chunks))
+		}
+	})
+
+	t.Run("splits on headings", func(t *testing.T) {
+		doc := strings.Join([]string{
+			section("# Introduction", 40),
+			section("## Methods", 40),
+			section("## Results", 40),
+		}, "\n")
+		chunks := SplitMarkdownChunks(doc, 90, wordCount)
+		if len(chunks) != 2 {
+			t.Fatalf("Expected 2 chunks, got %d", len(chunks))
+		}
+		if !strings.HasPrefix(chunks[0], "# Introduction") || !strings.Contains(chunks[0], "## Methods") {
+			t.Errorf("Expected first chunk to hold Introduction and Methods, got %q", chunks[0][:40])
+		}
+		if !strings.HasPrefix(chunks[1], "## Results") {
+			t.Errorf("Expected second chunk to start at a heading, got %q", chunks[1][:20])
+		}
+		for i, chunk := range chunks {
+			if wordCount(chunk) > 90 {
+				t.Errorf("Chunk %d has %d tokens, exceeding the limit", i, wordCount(chunk))
+			}
+		}
+	})
+
+	t.Run("splits oversized sections by paragraph", func(t *testing.T) {
+		paragraph := strings.TrimSpace(strings.Repeat("word ", 30))
+		doc := "# Long section\n\n" + strings.Join([]string{paragraph, paragraph, paragraph, paragraph}, "\n\n")
+		chunks := SplitMarkdownChunks(doc, 70, wordCount)
+		if len(chunks) < 2 {
+			t.Fatalf("Expected the section to be split, got %d chunks", len(chunks))
+		}
+		total := 0
+		for i, chunk := range chunks {
+			if wordCount(chunk) > 70 {
+				t.Errorf("Chunk %d has %d tokens, exceeding the limit", i, wordCount(chunk))
+			}
+			total += wordCount(chunk)
+		}
+		if total != wordCount(doc) {
+			t.Errorf("Expected %d words across chunks, got %d", wordCount(doc), total)
+		}
+	})
+
+	t.Run("ignores headings in code fences", func(t *testing.T) {
+		doc := section("# Intro", 40) + "\n```\n# not a heading\n```\n" + section("# Next", 40)
+		chunks := SplitMarkdownChunks(doc, 60, wordCount)
+		for _, chunk := range chunks {
+			if strings.HasPrefix(chunk, "# not a heading") {
+				t.Error("Expected heading inside code fence not to start a chunk")
+			}
+		}
+	})
+}
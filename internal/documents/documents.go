@@ -4,16 +4,19 @@ import (
 	"archive/zip"
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/Epistemic-Technology/academic-mcp/internal/citations"
+	"github.com/Epistemic-Technology/academic-mcp/internal/config"
 	"github.com/Epistemic-Technology/academic-mcp/models"
-	"github.com/Epistemic-Technology/zotero/zotero"
 	"github.com/JohannesKaufmann/html-to-markdown/v2/converter"
 	"github.com/JohannesKaufmann/html-to-markdown/v2/plugin/base"
 	"github.com/JohannesKaufmann/html-to-markdown/v2/plugin/commonmark"
@@ -48,9 +51,18 @@ func DetectDocumentType(data []byte) string {
 		return "html"
 	}
 
+	// XML: JATS and TEI are told apart by their root element; XHTML is HTML
+	if xmlType := detectXMLType(data); xmlType != "" {
+		return xmlType
+	}
+
 	// ZIP-based formats: ZIP file starting with PK (0x504B)
 	if len(data) >= 4 && data[0] == 0x50 && data[1] == 0x4B &&
 		(data[2] == 0x03 || data[2] == 0x05 || data[2] == 0x07) {
+		// EPUBs hold XHTML chapters, so they must be told apart from snapshots first
+		if isEPUB(data) {
+			return "epub"
+		}
 		// Check if it contains word/ directory (DOCX)
 		if bytes.Contains(data[:min(len(data), 1024)], []byte("word/")) {
 			return "docx"
@@ -62,6 +74,11 @@ func DetectDocumentType(data []byte) string {
 		return "zip"
 	}
 
+	// RTF: plain text, so it must be told apart from other text first
+	if IsRTF(data) {
+		return "rtf"
+	}
+
 	// Plain text / Markdown (if it's valid UTF-8 and has no binary characters)
 	if isLikelyText(data) {
 		// Simple markdown detection: look for common markdown patterns
@@ -104,46 +121,69 @@ func isLikelyText(data []byte) bool {
 
 // GetData retrieves document data from a source and detects its type
 func GetData(ctx context.Context, sourceInfo models.SourceInfo) (models.DocumentData, error) {
-	docData, _, err := GetDataWithMetadata(ctx, sourceInfo)
+	docData, _, _, err := GetDataWithMetadata(ctx, sourceInfo)
 	return docData, err
 }
 
 // GetDataWithMetadata retrieves document data from a source and detects its type,
-// also returning external metadata if available (e.g., from Zotero).
-// Returns the document data and external metadata (nil if not available).
-func GetDataWithMetadata(ctx context.Context, sourceInfo models.SourceInfo) (models.DocumentData, *models.ItemMetadata, error) {
+// also returning external metadata if available (e.g., from Zotero or the
+// arXiv API).
+// Returns the document data, with the source's page range, external metadata
+// (nil if not available), and a warning if the external metadata could not be
+// fetched, which does not fail the call. A Zotero metadata fetch that fails for
+// a reason that may pass is retried (see FetchZoteroMetadataWithRetry).
+func GetDataWithMetadata(ctx context.Context, sourceInfo models.SourceInfo) (models.DocumentData, *models.ItemMetadata, *models.MetadataWarning, error) {
 	var data []byte
+	var fetch *models.FetchInfo
 	var err error
 	var externalMetadata *models.ItemMetadata
+	var warning *models.MetadataWarning
 
 	if sourceInfo.ZoteroID != "" {
-		zoteroAPIKey := os.Getenv("ZOTERO_API_KEY")
-		libraryID := os.Getenv("ZOTERO_LIBRARY_ID")
+		zoteroAPIKey := config.FromContext(ctx).Credentials().ZoteroAPIKey
+		library, err := ResolveZoteroLibrary(ctx, sourceInfo.ZoteroLibraryType, sourceInfo.ZoteroLibraryID)
+		if err != nil {
+			return models.DocumentData{}, nil, nil, err
+		}
 
 		// Fetch document data
-		data, err = GetFromZotero(ctx, sourceInfo.ZoteroID, zoteroAPIKey, libraryID)
+		data, fetch, err = GetFromZotero(ctx, sourceInfo.ZoteroID, zoteroAPIKey, library)
 		if err != nil {
-			return models.DocumentData{}, nil, err
+			return models.DocumentData{}, nil, nil, err
 		}
 
-		// Fetch external metadata from Zotero
-		externalMetadata, err = FetchZoteroMetadata(ctx, sourceInfo.ZoteroID, zoteroAPIKey, libraryID)
+		// Fetch external metadata from Zotero; the document can still be parsed
+		// without it
+		externalMetadata, err = FetchZoteroMetadataWithRetry(ctx, sourceInfo.ZoteroID, zoteroAPIKey, library)
+		if err != nil {
+			externalMetadata = nil
+			warning = &models.MetadataWarning{Source: "zotero", Message: err.Error()}
+		}
+	} else if arXivID, ok := citations.ParseArXivURL(sourceInfo.URL); ok {
+		// The abstract page of an arXiv paper is a poor copy of it, so its PDF
+		// is fetched, with its metadata from the arXiv API
+		client := NewArXivClient()
+		data, fetch, err = client.FetchPDF(ctx, arXivID)
+		if err != nil {
+			return models.DocumentData{}, nil, nil, err
+		}
+		externalMetadata, err = client.FetchMetadata(ctx, arXivID)
 		if err != nil {
-			// Log error but don't fail - we can still parse without external metadata
-			// The error will be logged by the caller
+			// As for Zotero, the paper can be parsed without it
 			externalMetadata = nil
+			warning = &models.MetadataWarning{Source: "arxiv", Message: err.Error()}
 		}
 	} else if sourceInfo.URL != "" {
-		data, err = GetFromURL(ctx, sourceInfo.URL)
+		data, fetch, err = GetFromURL(ctx, sourceInfo.URL)
 		if err != nil {
-			return models.DocumentData{}, nil, err
+			return models.DocumentData{}, nil, nil, err
 		}
 	} else {
-		return models.DocumentData{}, nil, errors.New("no data provided")
+		return models.DocumentData{}, nil, nil, models.WithErrorCode(models.ErrorInvalidInput, errors.New("no data provided"))
 	}
 
 	if data == nil {
-		return models.DocumentData{}, nil, errors.New("no data retrieved")
+		return models.DocumentData{}, nil, nil, errors.New("no data retrieved")
 	}
 
 	// Detect document type from content
@@ -153,43 +193,110 @@ func GetDataWithMetadata(ctx context.Context, sourceInfo models.SourceInfo) (mod
 	if docType == "zotero-snapshot" {
 		htmlData, err := ExtractHTMLFromZip(data)
 		if err != nil {
-			return models.DocumentData{}, nil, fmt.Errorf("failed to extract HTML from Zotero snapshot: %w", err)
+			return models.DocumentData{}, nil, nil, fmt.Errorf("failed to extract HTML from Zotero snapshot: %w", err)
 		}
 		// Return the extracted HTML with type "html"
 		return models.DocumentData{
-			Data: htmlData,
-			Type: "html",
-		}, externalMetadata, nil
+			Data:  htmlData,
+			Type:  "html",
+			Fetch: fetch,
+		}, externalMetadata, warning, nil
 	}
 
 	return models.DocumentData{
-		Data: data,
-		Type: docType,
-	}, externalMetadata, nil
+		Data:  data,
+		Type:  docType,
+		Pages: sourceInfo.Pages,
+		Fetch: fetch,
+	}, externalMetadata, warning, nil
+}
+
+// DescribeData returns fetch info for document data that was not fetched,
+// such as raw data passed to a tool: only its checksum and size
+func DescribeData(data []byte) *models.FetchInfo {
+	hash := sha256.Sum256(data)
+	return &models.FetchInfo{SHA256: hex.EncodeToString(hash[:]), Size: int64(len(data))}
+}
+
+// describeFetch returns fetch info for data read from a response to a request
+// for sourceURL, fetched now
+func describeFetch(data []byte, sourceURL string, resp *http.Response) *models.FetchInfo {
+	fetch := DescribeData(data)
+	fetch.SourceURL = sourceURL
+	fetch.FinalURL = resp.Request.URL.String()
+	fetch.ContentType = resp.Header.Get("Content-Type")
+	fetch.FetchedAt = time.Now().UTC().Format(time.RFC3339)
+	return fetch
 }
 
-// GetFromURL fetches document data from a URL
-func GetFromURL(ctx context.Context, url string) ([]byte, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+// GetFromURL fetches document data from a URL, with where and when it was
+// fetched from. The request carries the headers of the context's
+// FetchOptions and goes through its proxy prefix, or the default one for the
+// URL's domain. The fetch info's source URL is the URL as given; its final
+// URL is the one the data came from, through the proxy.
+func GetFromURL(ctx context.Context, url string) ([]byte, *models.FetchInfo, error) {
+	opts := fetchOptionsFrom(ctx)
+	req, err := http.NewRequestWithContext(ctx, "GET", proxiedURL(url, opts), nil)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	for name, value := range opts.Headers {
+		req.Header.Set(name, value)
 	}
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer resp.Body.Close()
-	return io.ReadAll(resp.Body)
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, describeFetch(data, url, resp), nil
 }
 
-// GetFromZotero fetches document data from a Zotero library
-func GetFromZotero(ctx context.Context, zoteroID string, apiKey string, libraryID string) ([]byte, error) {
-	client := zotero.NewClient(libraryID, zotero.LibraryTypeUser, zotero.WithAPIKey(apiKey))
-	data, err := client.File(ctx, zoteroID)
+// GetFromZotero fetches document data from a Zotero attachment. Stored files
+// (imported_file, imported_url, and embedded_image attachments) are downloaded
+// from Zotero, and linked_url attachments are fetched from their URL. Linked
+// files and items that are not attachments have no data Zotero can serve.
+// A stored file's fetch info has its API download URL and the attachment's
+// content type, as the redirect to the file itself is followed by the client.
+func GetFromZotero(ctx context.Context, zoteroID string, apiKey string, library models.ZoteroLibrary) ([]byte, *models.FetchInfo, error) {
+	client := NewZoteroClient(library, apiKey)
+	attachment, err := getZoteroAttachment(ctx, client, zoteroID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	if attachment.ItemType != "attachment" {
+		return nil, nil, models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("Zotero item %s is a %s, not an attachment; use the key of its PDF or snapshot attachment", zoteroID, attachment.ItemType))
+	}
+
+	switch attachment.LinkMode {
+	case "imported_file", "imported_url", "embedded_image":
+		data, err := client.File(ctx, zoteroID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to download Zotero attachment %s (%s, %s): %w", zoteroID, attachment.LinkMode, attachment.describeContentType(), err)
+		}
+		fetch := DescribeData(data)
+		fetch.SourceURL = fmt.Sprintf("%s/%s/%s/items/%s/file", client.BaseURL, client.LibraryType, client.LibraryID, zoteroID)
+		fetch.ContentType = attachment.ContentType
+		fetch.FetchedAt = time.Now().UTC().Format(time.RFC3339)
+		return data, fetch, nil
+	case "linked_url":
+		if attachment.URL == "" {
+			return nil, nil, fmt.Errorf("Zotero attachment %s is a linked URL with no URL set", zoteroID)
+		}
+		data, fetch, err := GetFromURL(ctx, attachment.URL)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to fetch linked URL %s of Zotero attachment %s: %w", attachment.URL, zoteroID, err)
+		}
+		return data, fetch, nil
+	case "linked_file":
+		return nil, nil, models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("Zotero attachment %s is a linked file (%s) at %q on the computer that added it, which the Zotero API cannot serve; store the file in Zotero or parse it by URL", zoteroID, attachment.describeContentType(), attachment.Path))
+	default:
+		return nil, nil, fmt.Errorf("Zotero attachment %s has unsupported link mode %q (%s)", zoteroID, attachment.LinkMode, attachment.describeContentType())
 	}
-	return data, nil
 }
 
 // ExtractHTMLFromZip attempts to extract HTML content from a ZIP archive
@@ -278,9 +385,20 @@ func isZoteroSnapshotZip(data []byte) bool {
 }
 
 // PreprocessHTML converts HTML to markdown to reduce context window usage.
-// This strips unnecessary markup, scripts, styling, and images while preserving
-// document structure (headings, lists, tables, links).
-func PreprocessHTML(htmlData []byte) (string, error) {
+// Boilerplate such as navigation menus and cookie banners is removed first (see
+// ExtractMainContent), then the conversion strips unnecessary markup, scripts,
+// styling, and images while preserving document structure (headings, lists,
+// tables, links).
+func PreprocessHTML(htmlData []byte, mode HTMLExtractionMode) (string, error) {
+	content, err := ExtractMainContent(htmlData, mode)
+	if err != nil {
+		return "", err
+	}
+	return convertHTML(content)
+}
+
+// convertHTML converts HTML to markdown
+func convertHTML(htmlData []byte) (string, error) {
 	// Create converter with base and commonmark plugins
 	conv := converter.NewConverter(
 		converter.WithPlugins(
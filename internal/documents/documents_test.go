@@ -3,7 +3,15 @@ package documents
 import (
 	"archive/zip"
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
 )
 
 func TestDetectDocumentType(t *testing.T) {
@@ -37,6 +45,46 @@ func TestDetectDocumentType(t *testing.T) {
 			data:     []byte("  \n  <!DOCTYPE html><html><body>test</body></html>"),
 			expected: "html",
 		},
+		{
+			name:     "JATS article with a doctype",
+			data:     []byte(`<?xml version="1.0"?><!DOCTYPE article PUBLIC "-//NLM//DTD JATS (Z39.96) Journal Publishing DTD v1.2//EN" "JATS.dtd"><article><front/></article>`),
+			expected: "jats",
+		},
+		{
+			name:     "JATS article in the JATS namespace",
+			data:     []byte(`<article xmlns="http://jats.nlm.nih.gov/ns/archiving/1.3/"><body/></article>`),
+			expected: "jats",
+		},
+		{
+			name:     "JATS article without namespace or doctype",
+			data:     []byte("<?xml version=\"1.0\"?>\n<article article-type=\"research-article\">\n  <front></front>\n</article>"),
+			expected: "jats",
+		},
+		{
+			name:     "TEI document",
+			data:     []byte(`<?xml version="1.0" encoding="UTF-8"?><TEI xmlns="http://www.tei-c.org/ns/1.0"><teiHeader/></TEI>`),
+			expected: "tei",
+		},
+		{
+			name:     "XHTML",
+			data:     []byte(`<?xml version="1.0"?><html xmlns="http://www.w3.org/1999/xhtml"><body>test</body></html>`),
+			expected: "html",
+		},
+		{
+			name:     "Other XML",
+			data:     []byte(`<?xml version="1.0"?><rss version="2.0"><channel/></rss>`),
+			expected: "xml",
+		},
+		{
+			name:     "Article element that is not JATS",
+			data:     []byte(`<article><p>News</p></article>`),
+			expected: "xml",
+		},
+		{
+			name:     "Text beginning with a tag",
+			data:     []byte("<b>Note</b> the text that follows is <i>not XML"),
+			expected: "txt",
+		},
 		{
 			name:     "DOCX (ZIP with word/ directory)",
 			data:     append([]byte{0x50, 0x4B, 0x03, 0x04}, []byte("word/document.xml")...),
@@ -47,6 +95,16 @@ func TestDetectDocumentType(t *testing.T) {
 			data:     []byte{0x50, 0x4B, 0x03, 0x04, 0x00, 0x00, 0x00, 0x00},
 			expected: "zip",
 		},
+		{
+			name:     "RTF document",
+			data:     []byte(`{\rtf1\ansi\deff0 {\fonttbl {\f0 Times;}}\f0 Hello\par}`),
+			expected: "rtf",
+		},
+		{
+			name:     "RTF after a byte order mark",
+			data:     append([]byte{0xEF, 0xBB, 0xBF}, []byte(`{\rtf1 Hello}`)...),
+			expected: "rtf",
+		},
 		{
 			name:     "Markdown with heading",
 			data:     []byte("# Title\n\nSome markdown content"),
@@ -320,3 +378,82 @@ func TestDetectDocumentType_ZoteroSnapshot(t *testing.T) {
 		t.Errorf("DetectDocumentType() for Zotero snapshot = %v, want zotero-snapshot", result)
 	}
 }
+
+func TestGetFromURL(t *testing.T) {
+	pdf := []byte("%PDF-1.4 fetched paper")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/doi/paper":
+			http.Redirect(w, r, "/files/paper.pdf", http.StatusFound)
+		case "/files/paper.pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Write(pdf)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	before := time.Now().UTC().Truncate(time.Second)
+	data, fetch, err := GetFromURL(context.Background(), server.URL+"/doi/paper")
+	if err != nil {
+		t.Fatalf("GetFromURL failed: %v", err)
+	}
+	if !bytes.Equal(data, pdf) {
+		t.Errorf("Expected the PDF, got %q", data)
+	}
+	hash := sha256.Sum256(pdf)
+	expected := models.FetchInfo{
+		SourceURL:   server.URL + "/doi/paper",
+		FinalURL:    server.URL + "/files/paper.pdf",
+		ContentType: "application/pdf",
+		SHA256:      hex.EncodeToString(hash[:]),
+		Size:        int64(len(pdf)),
+	}
+	if fetch == nil {
+		t.Fatal("Expected fetch info, got nil")
+	}
+	fetchedAt, err := time.Parse(time.RFC3339, fetch.FetchedAt)
+	if err != nil || fetchedAt.Before(before) {
+		t.Errorf("Expected an RFC 3339 fetch time from now, got %q", fetch.FetchedAt)
+	}
+	fetch.FetchedAt = ""
+	if *fetch != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *fetch)
+	}
+}
+
+func TestGetDataWithMetadata_FetchInfo(t *testing.T) {
+	snapshot, err := createTestZip(map[string]string{"index.html": "<html><body>Snapshot</body></html>"})
+	if err != nil {
+		t.Fatalf("Failed to create test ZIP: %v", err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/zip")
+		w.Write(snapshot)
+	}))
+	defer server.Close()
+
+	// The checksum is of the bytes as fetched, not the HTML unzipped from them
+	data, _, _, err := GetDataWithMetadata(context.Background(), models.SourceInfo{URL: server.URL + "/snapshot.zip"})
+	if err != nil {
+		t.Fatalf("GetDataWithMetadata failed: %v", err)
+	}
+	hash := sha256.Sum256(snapshot)
+	if data.Type != "html" || data.Fetch == nil || data.Fetch.SHA256 != hex.EncodeToString(hash[:]) || data.Fetch.Size != int64(len(snapshot)) || data.Fetch.ContentType != "application/zip" {
+		t.Errorf("Expected the snapshot's HTML with the fetch info of the ZIP, got %s with %+v", data.Type, data.Fetch)
+	}
+	if data.Checksum() != data.Fetch.SHA256 {
+		t.Errorf("Expected the checksum from the fetch info, got %s", data.Checksum())
+	}
+}
+
+func TestDescribeData(t *testing.T) {
+	fetch := DescribeData([]byte("hello"))
+	if fetch.SHA256 != "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" || fetch.Size != 5 || fetch.SourceURL != "" || fetch.FetchedAt != "" {
+		t.Errorf("Expected the checksum and size only, got %+v", fetch)
+	}
+	if got := (models.DocumentData{Data: []byte("hello")}).Checksum(); got != fetch.SHA256 {
+		t.Errorf("Expected the checksum of data without fetch info to be computed, got %s", got)
+	}
+}
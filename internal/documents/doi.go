@@ -0,0 +1,85 @@
+package documents
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/citations"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+const (
+	// doiResolverEnv names the environment variable that overrides the DOI resolver
+	doiResolverEnv = "ACADEMIC_MCP_DOI_RESOLVER_URL"
+
+	defaultDOIResolver = "https://doi.org"
+	doiVerifyTimeout   = 10 * time.Second
+)
+
+// NormalizeDOIs puts the DOIs of a parsed document's metadata and references,
+// and of the external metadata for its source (which may be nil), into
+// canonical form (see citations.NormalizeDOI). DOIs that are not valid are
+// cleared and returned, so they are reported rather than stored.
+func NormalizeDOIs(item *models.ParsedItem, external *models.ItemMetadata) []models.InvalidDOI {
+	var invalid []models.InvalidDOI
+	normalize := func(doi *string, source string, referenceIndex *int) {
+		if strings.TrimSpace(*doi) == "" {
+			*doi = ""
+			return
+		}
+		normalized, ok := citations.NormalizeDOI(*doi)
+		if !ok {
+			invalid = append(invalid, models.InvalidDOI{Value: *doi, Reason: models.DOIMalformed, Source: source, ReferenceIndex: referenceIndex})
+		}
+		*doi = normalized
+	}
+
+	if external != nil {
+		normalize(&external.DOI, FieldSourceExternal, nil)
+	}
+	normalize(&item.Metadata.DOI, FieldSourceExtracted, nil)
+	for i := range item.References {
+		normalize(&item.References[i].DOI, "reference", &i)
+	}
+	return invalid
+}
+
+// VerifyDOI asks the DOI resolver (https://doi.org, or ACADEMIC_MCP_DOI_RESOLVER_URL)
+// whether a DOI is registered, without following the redirect to the publisher.
+// It reports false only if the resolver answers that the DOI does not exist; an
+// error means the DOI could not be checked.
+func VerifyDOI(ctx context.Context, doi string) (bool, error) {
+	resolver := strings.TrimSuffix(strings.TrimSpace(os.Getenv(doiResolverEnv)), "/")
+	if resolver == "" {
+		resolver = defaultDOIResolver
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, doiVerifyTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, resolver+"/"+(&url.URL{Path: doi}).EscapedPath(), nil)
+	if err != nil {
+		return false, err
+	}
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return false, nil
+	case resp.StatusCode < 400:
+		return true, nil
+	default:
+		return false, fmt.Errorf("DOI resolver returned status %d for %s", resp.StatusCode, doi)
+	}
+}
@@ -0,0 +1,77 @@
+package documents
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestNormalizeDOIs(t *testing.T) {
+	item := &models.ParsedItem{
+		Metadata: models.ItemMetadata{DOI: "https://doi.org/10.1000/ABC"},
+		References: []models.Reference{
+			{ReferenceText: "Valid", DOI: "doi:10.2000/Ref.1."},
+			{ReferenceText: "No DOI"},
+			{ReferenceText: "Malformed", DOI: "10.12/short"},
+		},
+	}
+	external := &models.ItemMetadata{DOI: "pending"}
+
+	invalid := NormalizeDOIs(item, external)
+
+	if item.Metadata.DOI != "10.1000/abc" || item.References[0].DOI != "10.2000/ref.1" {
+		t.Errorf("Expected normalized DOIs, got %q and %q", item.Metadata.DOI, item.References[0].DOI)
+	}
+	if external.DOI != "" || item.References[2].DOI != "" {
+		t.Errorf("Expected invalid DOIs cleared, got %q and %q", external.DOI, item.References[2].DOI)
+	}
+	refIndex := 2
+	want := []models.InvalidDOI{
+		{Value: "pending", Reason: models.DOIMalformed, Source: FieldSourceExternal},
+		{Value: "10.12/short", Reason: models.DOIMalformed, Source: "reference", ReferenceIndex: &refIndex},
+	}
+	if !reflect.DeepEqual(invalid, want) {
+		t.Errorf("Expected invalid DOIs %+v, got %+v", want, invalid)
+	}
+}
+
+func TestVerifyDOI(t *testing.T) {
+	resolver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("Expected a HEAD request, got %s", r.Method)
+		}
+		switch r.URL.Path {
+		case "/10.1000/registered":
+			w.Header().Set("Location", "https://publisher.example/article")
+			w.WriteHeader(http.StatusFound)
+		case "/10.1000/unregistered":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer resolver.Close()
+	t.Setenv(doiResolverEnv, resolver.URL)
+
+	tests := []struct {
+		doi     string
+		want    bool
+		wantErr bool
+	}{
+		{"10.1000/registered", true, false},
+		{"10.1000/unregistered", false, false},
+		{"10.1000/unavailable", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.doi, func(t *testing.T) {
+			got, err := VerifyDOI(context.Background(), tt.doi)
+			if got != tt.want || (err != nil) != tt.wantErr {
+				t.Errorf("VerifyDOI(%q) = %v, %v; want %v, error %v", tt.doi, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
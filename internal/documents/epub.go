@@ -0,0 +1,278 @@
+package documents
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// epubMediaType is the content of an EPUB's mimetype file
+const epubMediaType = "application/epub+zip"
+
+// EPUBChapter is one document of an EPUB's reading order
+type EPUBChapter struct {
+	ID       string // The spine item's idref, used as the chapter's page number
+	Markdown string
+}
+
+// EPUBBook is the content and package metadata of an EPUB
+type EPUBBook struct {
+	Metadata *models.ItemMetadata // nil if the package lists no title, creators, or identifiers
+	Chapters []EPUBChapter
+}
+
+// epubContainer is META-INF/container.xml, which locates the package document
+type epubContainer struct {
+	Rootfiles []struct {
+		FullPath  string `xml:"full-path,attr"`
+		MediaType string `xml:"media-type,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+// epubPackage is the OPF package document. Elements are matched by local name,
+// so the dc: and opf: prefixes need not be declared any particular way.
+type epubPackage struct {
+	Metadata struct {
+		Titles      []string         `xml:"title"`
+		Creators    []epubCreator    `xml:"creator"`
+		Identifiers []epubIdentifier `xml:"identifier"`
+		Dates       []string         `xml:"date"`
+		Publishers  []string         `xml:"publisher"`
+		Languages   []string         `xml:"language"`
+		Metas       []struct {
+			Refines  string `xml:"refines,attr"`
+			Property string `xml:"property,attr"`
+			Value    string `xml:",chardata"`
+		} `xml:"meta"`
+	} `xml:"metadata"`
+	Manifest []struct {
+		ID        string `xml:"id,attr"`
+		Href      string `xml:"href,attr"`
+		MediaType string `xml:"media-type,attr"`
+	} `xml:"manifest>item"`
+	Spine []struct {
+		IDRef string `xml:"idref,attr"`
+	} `xml:"spine>itemref"`
+}
+
+type epubCreator struct {
+	ID   string `xml:"id,attr"`
+	Role string `xml:"role,attr"` // EPUB 2 opf:role; EPUB 3 uses a refining meta instead
+	Name string `xml:",chardata"`
+}
+
+type epubIdentifier struct {
+	Scheme string `xml:"scheme,attr"` // EPUB 2 opf:scheme
+	Value  string `xml:",chardata"`
+}
+
+// selfClosingTagPattern matches XHTML's self-closing tags such as <a id="p12"/>
+var selfClosingTagPattern = regexp.MustCompile(`<([a-zA-Z][\w:-]*)(\s[^<>]*)?/>`)
+
+// voidElements are the HTML elements that have no closing tag
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// isEPUB reports whether a ZIP archive is an EPUB, identified by its mimetype file
+func isEPUB(data []byte) bool {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return false
+	}
+	content, err := readZipFile(reader, "mimetype")
+	return err == nil && strings.TrimSpace(string(content)) == epubMediaType
+}
+
+// ExtractEPUB reads an EPUB's chapters in reading order, as given by the spine
+// of its OPF package document, and the package's bibliographic metadata. Each
+// XHTML chapter is converted to markdown with PreprocessHTML. Chapters left with
+// no text (cover pages, or a table of contents that is all navigation) are
+// dropped.
+func ExtractEPUB(data []byte, mode HTMLExtractionMode) (*EPUBBook, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open EPUB archive: %w", err)
+	}
+
+	containerData, err := readZipFile(reader, "META-INF/container.xml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read EPUB container: %w", err)
+	}
+	var container epubContainer
+	if err := xml.Unmarshal(containerData, &container); err != nil {
+		return nil, fmt.Errorf("failed to parse EPUB container: %w", err)
+	}
+	packagePath := ""
+	for _, rootfile := range container.Rootfiles {
+		if rootfile.MediaType == "" || rootfile.MediaType == "application/oebps-package+xml" {
+			packagePath = rootfile.FullPath
+			break
+		}
+	}
+	if packagePath == "" {
+		return nil, errors.New("EPUB container does not name a package document")
+	}
+
+	packageData, err := readZipFile(reader, packagePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read EPUB package document: %w", err)
+	}
+	var pkg epubPackage
+	if err := xml.Unmarshal(packageData, &pkg); err != nil {
+		return nil, fmt.Errorf("failed to parse EPUB package document %s: %w", packagePath, err)
+	}
+
+	hrefs := make(map[string]string, len(pkg.Manifest))
+	for _, item := range pkg.Manifest {
+		if item.MediaType == "application/xhtml+xml" || item.MediaType == "text/html" {
+			hrefs[item.ID] = item.Href
+		}
+	}
+
+	book := &EPUBBook{Metadata: epubMetadata(&pkg)}
+	for _, itemref := range pkg.Spine {
+		href, ok := hrefs[itemref.IDRef]
+		if !ok {
+			continue
+		}
+		chapterPath, err := resolveEPUBHref(packagePath, href)
+		if err != nil {
+			return nil, err
+		}
+		chapterData, err := readZipFile(reader, chapterPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read EPUB chapter %s: %w", itemref.IDRef, err)
+		}
+		markdown, err := PreprocessHTML(removeSelfClosingTags(chapterData), mode)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert EPUB chapter %s: %w", itemref.IDRef, err)
+		}
+		if strings.TrimSpace(markdown) == "" {
+			continue
+		}
+		book.Chapters = append(book.Chapters, EPUBChapter{ID: itemref.IDRef, Markdown: markdown})
+	}
+
+	if len(book.Chapters) == 0 {
+		return nil, errors.New("EPUB has no chapters with text")
+	}
+	return book, nil
+}
+
+// epubMetadata reads the title, authors, date, publisher, and DOI or ISBN from a
+// package document. Creators are the authors unless a role marks them as
+// something else (e.g., an editor), and all creators are used if none are authors.
+func epubMetadata(pkg *epubPackage) *models.ItemMetadata {
+	metadata := &models.ItemMetadata{ItemType: "book"}
+	if len(pkg.Metadata.Titles) > 0 {
+		metadata.Title = strings.TrimSpace(pkg.Metadata.Titles[0])
+	}
+	if len(pkg.Metadata.Dates) > 0 {
+		metadata.PublicationDate = strings.TrimSpace(pkg.Metadata.Dates[0])
+	}
+	if len(pkg.Metadata.Publishers) > 0 {
+		metadata.Publisher = strings.TrimSpace(pkg.Metadata.Publishers[0])
+	}
+	if len(pkg.Metadata.Languages) > 0 {
+		metadata.Language = NormalizeLanguage(pkg.Metadata.Languages[0])
+	}
+
+	// EPUB 3 gives creator roles in meta elements refining the creator's id
+	roles := make(map[string]string)
+	for _, meta := range pkg.Metadata.Metas {
+		if meta.Property == "role" && strings.HasPrefix(meta.Refines, "#") {
+			roles[strings.TrimPrefix(meta.Refines, "#")] = strings.TrimSpace(meta.Value)
+		}
+	}
+	var authors, creators []string
+	for _, creator := range pkg.Metadata.Creators {
+		name := normalizeAuthorName(creator.Name)
+		if name == "" {
+			continue
+		}
+		creators = append(creators, name)
+		role := creator.Role
+		if role == "" {
+			role = roles[creator.ID]
+		}
+		if role == "" || role == "aut" {
+			authors = append(authors, name)
+		}
+	}
+	if len(authors) == 0 {
+		authors = creators
+	}
+	metadata.Authors = authors
+
+	for _, identifier := range pkg.Metadata.Identifiers {
+		value := strings.TrimSpace(identifier.Value)
+		lower := strings.ToLower(value)
+		if strings.HasPrefix(lower, "urn:doi:") || strings.HasPrefix(lower, "urn:isbn:") {
+			value = value[len("urn:"):]
+			lower = lower[len("urn:"):]
+		}
+		if doi := cleanDOI(value); looksLikeDOI(doi) {
+			setIfEmpty(&metadata.DOI, doi)
+		} else if strings.HasPrefix(lower, "isbn:") {
+			setIfEmpty(&metadata.ISBN, strings.TrimSpace(value[len("isbn:"):]))
+		} else if strings.EqualFold(identifier.Scheme, "isbn") {
+			setIfEmpty(&metadata.ISBN, value)
+		}
+	}
+
+	if metadata.Title == "" && len(metadata.Authors) == 0 && metadata.DOI == "" && metadata.ISBN == "" {
+		return nil
+	}
+	return metadata
+}
+
+// resolveEPUBHref resolves a manifest href, which is a URL relative to the
+// package document, to the path of a file in the archive
+func resolveEPUBHref(packagePath string, href string) (string, error) {
+	href, _, _ = strings.Cut(href, "#")
+	unescaped, err := url.PathUnescape(href)
+	if err != nil {
+		return "", fmt.Errorf("invalid EPUB manifest href %q: %w", href, err)
+	}
+	return path.Join(path.Dir(packagePath), unescaped), nil
+}
+
+// removeSelfClosingTags removes XHTML's self-closing non-void tags, such as the
+// page anchor <a id="p12"/>. They have no content, and HTML parsing would treat
+// them as open tags and swallow the text that follows into them.
+func removeSelfClosingTags(xhtml []byte) []byte {
+	return selfClosingTagPattern.ReplaceAllFunc(xhtml, func(tag []byte) []byte {
+		name := selfClosingTagPattern.FindSubmatch(tag)[1]
+		if voidElements[strings.ToLower(string(name))] {
+			return tag
+		}
+		return nil
+	})
+}
+
+// readZipFile reads the file at a path in a ZIP archive
+func readZipFile(reader *zip.Reader, name string) ([]byte, error) {
+	for _, file := range reader.File {
+		if file.Name != name {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+	return nil, fmt.Errorf("%s not found in archive", name)
+}
@@ -0,0 +1,177 @@
+package documents
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// syntheticEPUB is a small EPUB 3 book: a cover page, a navigation document, and
+// two chapters (one in a subdirectory with a space in its name) whose spine order
+// differs from their manifest order
+var syntheticEPUB = map[string]string{
+	"mimetype": "application/epub+zip",
+	"META-INF/container.xml": `<?xml version="1.0"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>`,
+	"OEBPS/content.opf": `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="pub-id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="pub-id">urn:isbn:9780226123456</dc:identifier>
+    <dc:identifier>https://doi.org/10.7208/chicago/9780226123456.001.0001</dc:identifier>
+    <dc:title>Archives of the Ordinary</dc:title>
+    <dc:creator id="creator1">Smith, Jane</dc:creator>
+    <meta refines="#creator1" property="role" scheme="marc:relators">aut</meta>
+    <dc:creator id="creator2">Ravi Patel</dc:creator>
+    <dc:creator id="creator3">Lee Wong</dc:creator>
+    <meta refines="#creator3" property="role" scheme="marc:relators">edt</meta>
+    <dc:publisher>University of Chicago Press</dc:publisher>
+    <dc:date>2021-03-15</dc:date>
+    <dc:language>en-GB</dc:language>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="cover" href="cover.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch02" href="text/chapter%202.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch01" href="text/chapter1.xhtml" media-type="application/xhtml+xml"/>
+    <item id="css" href="style.css" media-type="text/css"/>
+  </manifest>
+  <spine>
+    <itemref idref="cover"/>
+    <itemref idref="nav"/>
+    <itemref idref="ch01"/>
+    <itemref idref="ch02"/>
+  </spine>
+</package>`,
+	"OEBPS/nav.xhtml": `<?xml version="1.0" encoding="UTF-8"?>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops">
+<head><title>Contents</title></head>
+<body><nav epub:type="toc"><ol><li><a href="text/chapter1.xhtml">Introduction</a></li></ol></nav></body>
+</html>`,
+	"OEBPS/cover.xhtml": `<?xml version="1.0" encoding="UTF-8"?>
+<html xmlns="http://www.w3.org/1999/xhtml">
+<head><title>Cover</title></head>
+<body><img src="cover.jpg" alt=""/></body>
+</html>`,
+	"OEBPS/text/chapter1.xhtml": `<?xml version="1.0" encoding="UTF-8"?>
+<html xmlns="http://www.w3.org/1999/xhtml">
+<head><title/><link rel="stylesheet" href="../style.css"/></head>
+<body>
+<section epub:type="chapter">
+<h1>Introduction</h1>
+<p>Everyday records<a id="page_1"/> are the raw material of social history.</p>
+<p>This book follows them<br/>through three archives.</p>
+</section>
+</body>
+</html>`,
+	"OEBPS/text/chapter 2.xhtml": `<?xml version="1.0" encoding="UTF-8"?>
+<html xmlns="http://www.w3.org/1999/xhtml">
+<head><title>Parish Registers</title></head>
+<body>
+<h1>Parish Registers</h1>
+<p>Registers of baptisms, marriages, and burials survive from 1538.</p>
+</body>
+</html>`,
+}
+
+func TestDetectDocumentType_EPUB(t *testing.T) {
+	epubData, err := createTestZip(syntheticEPUB)
+	if err != nil {
+		t.Fatalf("Failed to create EPUB: %v", err)
+	}
+	if got := DetectDocumentType(epubData); got != "epub" {
+		t.Errorf("DetectDocumentType() for EPUB = %v, want epub", got)
+	}
+}
+
+func TestExtractEPUB(t *testing.T) {
+	epubData, err := createTestZip(syntheticEPUB)
+	if err != nil {
+		t.Fatalf("Failed to create EPUB: %v", err)
+	}
+
+	book, err := ExtractEPUB(epubData, HTMLExtractionLenient)
+	if err != nil {
+		t.Fatalf("ExtractEPUB failed: %v", err)
+	}
+
+	// The cover and navigation document have no text and are dropped
+	var ids []string
+	for _, chapter := range book.Chapters {
+		ids = append(ids, chapter.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{"ch01", "ch02"}) {
+		t.Fatalf("Expected chapters [ch01 ch02] in spine order, got %v", ids)
+	}
+
+	intro := book.Chapters[0].Markdown
+	for _, want := range []string{"# Introduction", "Everyday records are the raw material of social history.", "This book follows them"} {
+		if !strings.Contains(intro, want) {
+			t.Errorf("Expected chapter 1 to contain %q:\n%s", want, intro)
+		}
+	}
+	if !strings.Contains(book.Chapters[1].Markdown, "Registers of baptisms") {
+		t.Errorf("Expected chapter 2 content, got:\n%s", book.Chapters[1].Markdown)
+	}
+
+	expected := &models.ItemMetadata{
+		Title:           "Archives of the Ordinary",
+		Authors:         []string{"Jane Smith", "Ravi Patel"},
+		PublicationDate: "2021-03-15",
+		Publisher:       "University of Chicago Press",
+		DOI:             "10.7208/chicago/9780226123456.001.0001",
+		ISBN:            "9780226123456",
+		Language:        "en",
+		ItemType:        "book",
+	}
+	if !reflect.DeepEqual(book.Metadata, expected) {
+		t.Errorf("Expected metadata %+v, got %+v", expected, book.Metadata)
+	}
+}
+
+func TestExtractEPUB_Errors(t *testing.T) {
+	withoutContainer := make(map[string]string)
+	withoutChapters := make(map[string]string)
+	for name, content := range syntheticEPUB {
+		if name != "META-INF/container.xml" {
+			withoutContainer[name] = content
+		}
+		if !strings.Contains(name, "chapter") {
+			withoutChapters[name] = content
+		}
+	}
+	withoutChapters["OEBPS/content.opf"] = strings.NewReplacer(`<itemref idref="ch01"/>`, "", `<itemref idref="ch02"/>`, "").Replace(syntheticEPUB["OEBPS/content.opf"])
+
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{"Missing container", withoutContainer, "failed to read EPUB container"},
+		{"No chapters with text", withoutChapters, "no chapters with text"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			epubData, err := createTestZip(tt.files)
+			if err != nil {
+				t.Fatalf("Failed to create EPUB: %v", err)
+			}
+			if _, err := ExtractEPUB(epubData, HTMLExtractionLenient); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRemoveSelfClosingTags(t *testing.T) {
+	input := `<p>One<a id="p1"/> two<br/><span class="x" /></p><img src="a.png"/>`
+	expected := `<p>One two<br/></p><img src="a.png"/>`
+	if got := string(removeSelfClosingTags([]byte(input))); got != expected {
+		t.Errorf("removeSelfClosingTags() = %q, want %q", got, expected)
+	}
+}
@@ -0,0 +1,103 @@
+package documents
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+const (
+	// urlProxyPrefixEnv names the environment variable that sets the default
+	// proxy prefix, such as an institution's EZproxy login URL
+	urlProxyPrefixEnv = "ACADEMIC_MCP_URL_PROXY_PREFIX"
+	// urlProxyDomainsEnv names the environment variable that lists the domains,
+	// separated by commas, whose URLs are fetched through the default prefix
+	urlProxyDomainsEnv = "ACADEMIC_MCP_URL_PROXY_DOMAINS"
+)
+
+// FetchOptions are settings for fetching one document by URL, for publisher
+// URLs that need a session or an institutional proxy
+type FetchOptions struct {
+	// Headers sent with the request (e.g., Cookie, Authorization, or
+	// User-Agent). Their values may be credentials: they are never logged or
+	// stored with the fetch info.
+	Headers map[string]string
+	// ProxyPrefix is prepended to the URL, e.g.
+	// "https://ezproxy.example.edu/login?url=". It takes the place of the
+	// default prefix, and applies whatever the URL's domain.
+	ProxyPrefix string
+}
+
+type fetchOptionsKey struct{}
+
+// WithFetchOptions returns ctx with documents fetched by URL (see GetFromURL)
+// using opts
+func WithFetchOptions(ctx context.Context, opts FetchOptions) context.Context {
+	return context.WithValue(ctx, fetchOptionsKey{}, opts)
+}
+
+// fetchOptionsFrom returns the fetch options of ctx, or none
+func fetchOptionsFrom(ctx context.Context) FetchOptions {
+	opts, _ := ctx.Value(fetchOptionsKey{}).(FetchOptions)
+	return opts
+}
+
+// ValidateFetchOptions checks that opts can be sent: header names are tokens
+// without spaces or colons, header values are on one line, and the proxy
+// prefix is an http or https URL. Errors name headers but never their values.
+func ValidateFetchOptions(opts FetchOptions) error {
+	for name, value := range opts.Headers {
+		if name == "" || strings.ContainsAny(name, " \t\r\n:") {
+			return models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("invalid header name %q", name))
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("header %s has a line break in its value", name))
+		}
+	}
+	if opts.ProxyPrefix != "" {
+		if err := validateProxyPrefix(opts.ProxyPrefix); err != nil {
+			return models.WithErrorCode(models.ErrorInvalidInput, err)
+		}
+	}
+	return nil
+}
+
+// validateProxyPrefix checks that prefix is an absolute http or https URL
+func validateProxyPrefix(prefix string) error {
+	u, err := url.Parse(prefix)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("proxy prefix %q is not an http or https URL", prefix)
+	}
+	return nil
+}
+
+// proxiedURL returns the URL to request for rawURL: rawURL behind the
+// options' proxy prefix if there is one, or else behind
+// ACADEMIC_MCP_URL_PROXY_PREFIX if its host is in one of the
+// ACADEMIC_MCP_URL_PROXY_DOMAINS (or a subdomain of one), or else rawURL
+// itself. The URL is appended to the prefix as it is, as EZproxy expects.
+func proxiedURL(rawURL string, opts FetchOptions) string {
+	if opts.ProxyPrefix != "" {
+		return opts.ProxyPrefix + rawURL
+	}
+	prefix := strings.TrimSpace(os.Getenv(urlProxyPrefixEnv))
+	if prefix == "" || validateProxyPrefix(prefix) != nil {
+		return rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, domain := range strings.Split(os.Getenv(urlProxyDomainsEnv), ",") {
+		domain = strings.ToLower(strings.Trim(strings.TrimSpace(domain), "."))
+		if domain != "" && (host == domain || strings.HasSuffix(host, "."+domain)) {
+			return prefix + rawURL
+		}
+	}
+	return rawURL
+}
@@ -0,0 +1,139 @@
+package documents
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGetFromURL_Headers(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write([]byte("%PDF-1.4 paywalled paper"))
+	}))
+	defer server.Close()
+
+	ctx := WithFetchOptions(context.Background(), FetchOptions{Headers: map[string]string{
+		"Cookie":        "session=secret-cookie",
+		"Authorization": "Bearer secret-token",
+		"User-Agent":    "LibraryBot/1.0",
+	}})
+	_, fetch, err := GetFromURL(ctx, server.URL+"/paper.pdf")
+	if err != nil {
+		t.Fatalf("GetFromURL failed: %v", err)
+	}
+	if got.Get("Cookie") != "session=secret-cookie" || got.Get("Authorization") != "Bearer secret-token" || got.Get("User-Agent") != "LibraryBot/1.0" {
+		t.Errorf("Expected the headers to be sent, got %v", got)
+	}
+
+	// The fetch info is stored, so it must not hold the header values
+	data, err := json.Marshal(fetch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret") {
+		t.Errorf("Expected fetch info without header values, got %s", data)
+	}
+	if fetch.SourceURL != server.URL+"/paper.pdf" {
+		t.Errorf("Expected source URL %s, got %s", server.URL+"/paper.pdf", fetch.SourceURL)
+	}
+}
+
+func TestGetFromURL_Proxy(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.RequestURI())
+		w.Write([]byte("%PDF-1.4 proxied paper"))
+	}))
+	defer server.Close()
+
+	t.Setenv(urlProxyPrefixEnv, server.URL+"/login?url=")
+	t.Setenv(urlProxyDomainsEnv, "publisher.example, .journals.example")
+
+	// A URL of a configured domain goes through the proxy
+	_, fetch, err := GetFromURL(context.Background(), "https://www.publisher.example/doi/10.1000/paper.pdf")
+	if err != nil {
+		t.Fatalf("GetFromURL failed: %v", err)
+	}
+	if len(requested) != 1 || requested[0] != "/login?url=https://www.publisher.example/doi/10.1000/paper.pdf" {
+		t.Errorf("Expected the proxied request, got %v", requested)
+	}
+	if fetch.SourceURL != "https://www.publisher.example/doi/10.1000/paper.pdf" {
+		t.Errorf("Expected the source URL as given, got %s", fetch.SourceURL)
+	}
+
+	// Other URLs are fetched directly
+	requested = nil
+	if _, _, err := GetFromURL(context.Background(), server.URL+"/open/paper.pdf"); err != nil {
+		t.Fatalf("GetFromURL failed: %v", err)
+	}
+	if len(requested) != 1 || requested[0] != "/open/paper.pdf" {
+		t.Errorf("Expected a direct request, got %v", requested)
+	}
+
+	// A prefix given with the request applies to any domain
+	requested = nil
+	ctx := WithFetchOptions(context.Background(), FetchOptions{ProxyPrefix: server.URL + "/custom?qurl="})
+	if _, _, err := GetFromURL(ctx, "https://other.example/paper.pdf"); err != nil {
+		t.Fatalf("GetFromURL failed: %v", err)
+	}
+	if len(requested) != 1 || requested[0] != "/custom?qurl=https://other.example/paper.pdf" {
+		t.Errorf("Expected the request through the given prefix, got %v", requested)
+	}
+}
+
+func TestProxiedURL(t *testing.T) {
+	t.Setenv(urlProxyPrefixEnv, "https://ezproxy.example.edu/login?url=")
+	t.Setenv(urlProxyDomainsEnv, "sciencedirect.com,JSTOR.org")
+
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"https://www.sciencedirect.com/article/pii/1", "https://ezproxy.example.edu/login?url=https://www.sciencedirect.com/article/pii/1"},
+		{"https://sciencedirect.com/a", "https://ezproxy.example.edu/login?url=https://sciencedirect.com/a"},
+		{"https://www.jstor.org/stable/1", "https://ezproxy.example.edu/login?url=https://www.jstor.org/stable/1"},
+		{"https://notsciencedirect.com/a", "https://notsciencedirect.com/a"},
+		{"https://arxiv.org/pdf/2101.01234", "https://arxiv.org/pdf/2101.01234"},
+	}
+	for _, tt := range tests {
+		if got := proxiedURL(tt.url, FetchOptions{}); got != tt.expected {
+			t.Errorf("proxiedURL(%q) = %q, want %q", tt.url, got, tt.expected)
+		}
+	}
+
+	t.Setenv(urlProxyDomainsEnv, "")
+	if got := proxiedURL("https://www.sciencedirect.com/a", FetchOptions{}); got != "https://www.sciencedirect.com/a" {
+		t.Errorf("Expected no proxy without configured domains, got %q", got)
+	}
+}
+
+func TestValidateFetchOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    FetchOptions
+		wantErr bool
+	}{
+		{name: "none", opts: FetchOptions{}},
+		{name: "headers and prefix", opts: FetchOptions{Headers: map[string]string{"Cookie": "a=b"}, ProxyPrefix: "https://ezproxy.example.edu/login?url="}},
+		{name: "header name with colon", opts: FetchOptions{Headers: map[string]string{"Cookie:": "a=b"}}, wantErr: true},
+		{name: "header value with line break", opts: FetchOptions{Headers: map[string]string{"Cookie": "a=b\r\nX-Other: c"}}, wantErr: true},
+		{name: "relative prefix", opts: FetchOptions{ProxyPrefix: "/login?url="}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateFetchOptions(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateFetchOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && strings.Contains(err.Error(), "a=b") {
+				t.Errorf("Expected the error not to include header values, got %v", err)
+			}
+		})
+	}
+}
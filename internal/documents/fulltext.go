@@ -0,0 +1,204 @@
+package documents
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// hyphenationEnv names the environment variable that sets the HyphenationMode
+const hyphenationEnv = "ACADEMIC_MCP_HYPHENATION"
+
+// HyphenationMode controls how FullText treats a word hyphenated across a line
+// or page break
+type HyphenationMode string
+
+const (
+	// HyphenationJoin rejoins the word, dropping the hyphen unless the document
+	// spells the word with a hyphen elsewhere and never without (the default)
+	HyphenationJoin HyphenationMode = "join"
+	// HyphenationKeep rejoins the word but always keeps the hyphen
+	HyphenationKeep HyphenationMode = "keep"
+	// HyphenationOff leaves hyphenated breaks as they are
+	HyphenationOff HyphenationMode = "off"
+)
+
+// ConfiguredHyphenationMode returns the mode set by ACADEMIC_MCP_HYPHENATION,
+// or join if it is unset. An unrecognized value returns join and an error.
+func ConfiguredHyphenationMode() (HyphenationMode, error) {
+	switch value := strings.ToLower(strings.TrimSpace(os.Getenv(hyphenationEnv))); value {
+	case "", string(HyphenationJoin):
+		return HyphenationJoin, nil
+	case string(HyphenationKeep):
+		return HyphenationKeep, nil
+	case string(HyphenationOff):
+		return HyphenationOff, nil
+	default:
+		return HyphenationJoin, fmt.Errorf("invalid %s %q (expected join, keep, or off)", hyphenationEnv, value)
+	}
+}
+
+// lineHyphenPattern matches a word hyphenated at the end of a line and its
+// lowercase continuation at the start of the next
+var lineHyphenPattern = regexp.MustCompile(`(\p{L}+)-[ \t]*\n[ \t]*(\p{Ll}+)`)
+
+// wordPattern matches the words and hyphenated compounds of the text
+var wordPattern = regexp.MustCompile(`\p{L}+(?:-\p{L}+)*`)
+
+// FullText joins a document's pages into continuous text, undoing the breaks
+// the page layout put into it. A word hyphenated at the end of a line or page
+// is rejoined according to mode, and a paragraph cut off at the bottom of a page
+// (the page ends without terminal punctuation and the next page begins with a
+// lowercase letter) is joined with its continuation by a space rather than a
+// paragraph break. Other pages are separated by blank lines, as in JoinPages.
+// The page contents are not modified. It also returns the byte offset in the
+// text at which each page's content begins; a page starting with the rest of a
+// word from the previous page begins at that rest.
+func FullText(pages []string, mode HyphenationMode) (string, []int) {
+	offsets := make([]int, len(pages))
+	if len(pages) == 0 {
+		return "", offsets
+	}
+
+	joiner := newHyphenJoiner(pages, mode)
+	cleaned := make([]string, len(pages))
+	for i, page := range pages {
+		cleaned[i] = joiner.joinLines(page)
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(cleaned[0], " \t\r\n"))
+	for i := 1; i < len(pages); i++ {
+		text := b.String()
+		next := strings.TrimSpace(cleaned[i])
+		switch {
+		case next == "":
+			b.WriteString(pageSeparator)
+		case endsWithHyphenatedWord(text) && startsLowercase(next):
+			if mode == HyphenationOff {
+				b.WriteString(pageSeparator)
+			} else if !joiner.keepHyphen(lastWord(text[:len(text)-1]), firstWord(next)) {
+				b.Reset()
+				b.WriteString(text[:len(text)-1])
+			}
+		case continuesParagraph(text, next):
+			b.WriteString(" ")
+		default:
+			b.WriteString(pageSeparator)
+		}
+		offsets[i] = b.Len()
+		b.WriteString(next)
+	}
+	return b.String(), offsets
+}
+
+// hyphenJoiner decides, for one document, whether a word hyphenated at a
+// break keeps its hyphen
+type hyphenJoiner struct {
+	mode       HyphenationMode
+	hyphenated map[string]bool // lowercase compounds written with a hyphen within a line
+	plain      map[string]bool // lowercase words written without one
+}
+
+func newHyphenJoiner(pages []string, mode HyphenationMode) *hyphenJoiner {
+	j := &hyphenJoiner{mode: mode, hyphenated: make(map[string]bool), plain: make(map[string]bool)}
+	if mode != HyphenationJoin {
+		return j
+	}
+	for _, page := range pages {
+		for _, word := range wordPattern.FindAllString(page, -1) {
+			word = strings.ToLower(word)
+			if strings.Contains(word, "-") {
+				j.hyphenated[word] = true
+			} else {
+				j.plain[word] = true
+			}
+		}
+	}
+	return j
+}
+
+// keepHyphen reports whether stem-rest, split at a break, is a compound that
+// keeps its hyphen. Without a dictionary the document is the evidence: the
+// hyphen is kept only if the compound appears hyphenated elsewhere and the
+// joined word never appears.
+func (j *hyphenJoiner) keepHyphen(stem, rest string) bool {
+	if j.mode != HyphenationJoin {
+		return true
+	}
+	return j.hyphenated[strings.ToLower(stem+"-"+rest)] && !j.plain[strings.ToLower(stem+rest)]
+}
+
+// joinLines rejoins the words hyphenated at line breaks within a page
+func (j *hyphenJoiner) joinLines(page string) string {
+	if j.mode == HyphenationOff {
+		return page
+	}
+	return lineHyphenPattern.ReplaceAllStringFunc(page, func(match string) string {
+		parts := lineHyphenPattern.FindStringSubmatch(match)
+		if j.keepHyphen(parts[1], parts[2]) {
+			return parts[1] + "-" + parts[2]
+		}
+		return parts[1] + parts[2]
+	})
+}
+
+// endsWithHyphenatedWord reports whether text ends with a letter followed by a hyphen
+func endsWithHyphenatedWord(text string) bool {
+	stem, ok := strings.CutSuffix(text, "-")
+	if !ok || lastLineIsStructure(text) {
+		return false
+	}
+	r, _ := utf8.DecodeLastRuneInString(stem)
+	return unicode.IsLetter(r)
+}
+
+// continuesParagraph reports whether next, the start of a page, continues the
+// paragraph at the end of text: text ends mid-sentence in plain prose and next
+// begins with a lowercase letter
+func continuesParagraph(text, next string) bool {
+	if text == "" || !startsLowercase(next) || lastLineIsStructure(text) {
+		return false
+	}
+	last := strings.TrimRight(text, `"'”’)]*_`)
+	if last == "" {
+		return false
+	}
+	r, _ := utf8.DecodeLastRuneInString(last)
+	return !strings.ContainsRune(".!?:;", r)
+}
+
+// lastLineIsStructure reports whether the last line of text is a markdown
+// heading, table row, or code fence, which a page break never splits a
+// paragraph out of
+func lastLineIsStructure(text string) bool {
+	line := text[strings.LastIndex(text, "\n")+1:]
+	line = strings.TrimSpace(line)
+	return strings.HasPrefix(line, "#") || strings.HasPrefix(line, "|") || strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~")
+}
+
+func startsLowercase(text string) bool {
+	r, _ := utf8.DecodeRuneInString(text)
+	return unicode.IsLower(r)
+}
+
+// lastWord returns the letters at the end of text
+func lastWord(text string) string {
+	i := strings.LastIndexFunc(text, func(r rune) bool { return !unicode.IsLetter(r) })
+	if i < 0 {
+		return text
+	}
+	_, size := utf8.DecodeRuneInString(text[i:])
+	return text[i+size:]
+}
+
+// firstWord returns the letters at the start of text
+func firstWord(text string) string {
+	if i := strings.IndexFunc(text, func(r rune) bool { return !unicode.IsLetter(r) }); i >= 0 {
+		return text[:i]
+	}
+	return text
+}
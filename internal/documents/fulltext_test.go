@@ -0,0 +1,149 @@
+package documents
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFullText_PagePairs(t *testing.T) {
+	tests := []struct {
+		name     string
+		pages    []string
+		mode     HyphenationMode
+		expected string
+	}{
+		{
+			name:     "word hyphenated across pages",
+			pages:    []string{"The results were inter-", "preted with care."},
+			expected: "The results were interpreted with care.",
+		},
+		{
+			name:     "compound hyphenated elsewhere keeps its hyphen",
+			pages:    []string{"Self-regulation matters. It shapes self-", "regulation in class."},
+			expected: "Self-regulation matters. It shapes self-regulation in class.",
+		},
+		{
+			name:     "joined spelling elsewhere wins over a hyphenated one",
+			pages:    []string{"Co-operation and cooperation. Both co-", "operation forms."},
+			expected: "Co-operation and cooperation. Both cooperation forms.",
+		},
+		{
+			name:     "hyphen before a capital is a paragraph break",
+			pages:    []string{"See the pre-", "Roman period."},
+			expected: "See the pre-\n\nRoman period.",
+		},
+		{
+			name:     "keep mode keeps the hyphen",
+			pages:    []string{"The results were inter-", "preted with care."},
+			mode:     HyphenationKeep,
+			expected: "The results were inter-preted with care.",
+		},
+		{
+			name:     "off mode leaves the break",
+			pages:    []string{"The results were inter-", "preted with care."},
+			mode:     HyphenationOff,
+			expected: "The results were inter-\n\npreted with care.",
+		},
+		{
+			name:     "paragraph continues onto the next page",
+			pages:    []string{"The model was trained on", "three corpora."},
+			expected: "The model was trained on three corpora.",
+		},
+		{
+			name:     "continuation after a note anchor",
+			pages:    []string{"as argued earlier[^smith2020-fn1]", "and again below."},
+			expected: "as argued earlier[^smith2020-fn1] and again below.",
+		},
+		{
+			name:     "sentence ends at the page break",
+			pages:    []string{"The model was trained.", "three corpora were used."},
+			expected: "The model was trained.\n\nthree corpora were used.",
+		},
+		{
+			name:     "next page starts with a capital",
+			pages:    []string{"The model was trained on", "Three corpora."},
+			expected: "The model was trained on\n\nThree corpora.",
+		},
+		{
+			name:     "page ending with a heading",
+			pages:    []string{"Text.\n\n## Results of the", "experiment were clear."},
+			expected: "Text.\n\n## Results of the\n\nexperiment were clear.",
+		},
+		{
+			name:     "page ending with a table",
+			pages:    []string{"| a | b |\n| --- | --- |\n| x | y |", "continued text."},
+			expected: "| a | b |\n| --- | --- |\n| x | y |\n\ncontinued text.",
+		},
+		{
+			name:     "hyphenated line break within a page",
+			pages:    []string{"The treat-\nment group improved.\n- a list item\n- another"},
+			expected: "The treatment group improved.\n- a list item\n- another",
+		},
+		{
+			name:     "non-ASCII word across pages",
+			pages:    []string{"Die Unter-", "suchung zeigt."},
+			expected: "Die Untersuchung zeigt.",
+		},
+		{
+			name:     "empty page",
+			pages:    []string{"First page.", "  ", "Third page."},
+			expected: "First page.\n\n\n\nThird page.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mode := tt.mode
+			if mode == "" {
+				mode = HyphenationJoin
+			}
+			text, _ := FullText(tt.pages, mode)
+			if text != tt.expected {
+				t.Errorf("FullText() = %q, expected %q", text, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFullText_PageOffsets(t *testing.T) {
+	pages := []string{"The results were inter-", "preted with care. The model was trained on", "three corpora.", "New paragraph."}
+	text, offsets := FullText(pages, HyphenationJoin)
+
+	if want := []int{0, 22, 65, 81}; !reflect.DeepEqual(offsets, want) {
+		t.Fatalf("Expected offsets %v, got %v (text %q)", want, offsets, text)
+	}
+	starts := []string{"The results", "preted with", "three corpora", "New paragraph"}
+	for i, start := range starts {
+		if !strings.HasPrefix(text[offsets[i]:], start) {
+			t.Errorf("Page %d: expected text at offset %d to start with %q, got %q", i+1, offsets[i], start, text[offsets[i]:])
+		}
+	}
+
+	if text, offsets := FullText(nil, HyphenationJoin); text != "" || len(offsets) != 0 {
+		t.Errorf("Expected no text for no pages, got %q %v", text, offsets)
+	}
+}
+
+func TestConfiguredHyphenationMode(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected HyphenationMode
+		wantErr  bool
+	}{
+		{"", HyphenationJoin, false},
+		{"keep", HyphenationKeep, false},
+		{" OFF ", HyphenationOff, false},
+		{"sometimes", HyphenationJoin, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv(hyphenationEnv, tt.value)
+			mode, err := ConfiguredHyphenationMode()
+			if mode != tt.expected || (err != nil) != tt.wantErr {
+				t.Errorf("ConfiguredHyphenationMode() = %q, %v; expected %q (error %v)", mode, err, tt.expected, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package documents
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// htmlExtractionEnv names the environment variable that sets the HTMLExtractionMode
+const htmlExtractionEnv = "ACADEMIC_MCP_HTML_EXTRACTION"
+
+// HTMLExtractionMode controls what PreprocessHTML does when the main content it
+// extracts from a page is suspiciously short
+type HTMLExtractionMode string
+
+const (
+	// HTMLExtractionLenient converts the whole page instead (the default)
+	HTMLExtractionLenient HTMLExtractionMode = "lenient"
+	// HTMLExtractionStrict converts the extracted content regardless
+	HTMLExtractionStrict HTMLExtractionMode = "strict"
+)
+
+const (
+	// minMainContentLength is the fewest characters of text extracted main content
+	// may have before lenient extraction falls back to the whole page
+	minMainContentLength = 200
+	// minMainContentShare is the smallest share of the page's remaining text that
+	// extracted main content may have before lenient extraction falls back
+	minMainContentShare = 0.25
+	// mainContainerShare is the share of the largest container's text a nested
+	// container must hold to be preferred over it as the main content
+	mainContainerShare = 0.8
+)
+
+// boilerplatePattern matches the class and id words that mark page furniture
+// such as menus, cookie banners, sidebars, and sharing widgets
+var boilerplatePattern = regexp.MustCompile(`(?i)(^|[\s_-])(nav|navbar|navigation|menu|breadcrumbs?|cookies?|consent|gdpr|onetrust|banner|sidebar|related|recommended|recommendations|share|sharing|social|footer|masthead|advert|advertisement|ads?|promo|newsletter|subscribe|popup|modal|skip)([\s_-]|$)`)
+
+// boilerplateRoles are the ARIA landmark roles that never hold article content
+var boilerplateRoles = map[string]bool{
+	"navigation": true, "complementary": true, "search": true, "dialog": true, "alertdialog": true,
+}
+
+// ConfiguredHTMLExtractionMode returns the mode set by ACADEMIC_MCP_HTML_EXTRACTION,
+// or lenient if it is unset. An unrecognized value returns lenient and an error.
+func ConfiguredHTMLExtractionMode() (HTMLExtractionMode, error) {
+	switch value := strings.ToLower(strings.TrimSpace(os.Getenv(htmlExtractionEnv))); value {
+	case "", string(HTMLExtractionLenient):
+		return HTMLExtractionLenient, nil
+	case string(HTMLExtractionStrict):
+		return HTMLExtractionStrict, nil
+	default:
+		return HTMLExtractionLenient, fmt.Errorf("invalid %s %q (expected strict or lenient)", htmlExtractionEnv, value)
+	}
+}
+
+// ExtractMainContent returns the HTML of a page's main content, readability
+// style. Navigation, sidebars, cookie banners, page headers and footers, and
+// elements whose class or id names such furniture are removed, keeping any that
+// hold figures, tables, or the title. The main content is then the smallest
+// <article>, <main>, or role="main" container holding nearly all the text of the
+// largest one, or the body if there is none. In lenient mode, main content that
+// is suspiciously short compared to the rest of the page is discarded and the
+// whole page is returned unchanged.
+func ExtractMainContent(htmlData []byte, mode HTMLExtractionMode) ([]byte, error) {
+	doc, err := html.Parse(bytes.NewReader(htmlData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+	body := findElement(doc, atom.Body)
+	if body == nil {
+		return htmlData, nil
+	}
+
+	removeBoilerplate(body, false)
+	content := findMainContainer(body)
+	if content == nil {
+		content = body
+	}
+
+	if mode != HTMLExtractionStrict && content != body {
+		contentLength := textLength(content)
+		if contentLength < minMainContentLength || float64(contentLength) < minMainContentShare*float64(textLength(body)) {
+			return htmlData, nil
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := html.Render(&buf, content); err != nil {
+		return nil, fmt.Errorf("failed to render main content: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// removeBoilerplate removes the page furniture below n. Headers and footers are
+// only furniture outside an <article> or <section>, where they belong to the page
+// rather than its content.
+func removeBoilerplate(n *html.Node, inSection bool) {
+	for child := n.FirstChild; child != nil; {
+		next := child.NextSibling
+		if child.Type == html.ElementNode {
+			if isBoilerplate(child, inSection) {
+				n.RemoveChild(child)
+			} else {
+				removeBoilerplate(child, inSection || child.DataAtom == atom.Article || child.DataAtom == atom.Section)
+			}
+		}
+		child = next
+	}
+}
+
+// isBoilerplate reports whether an element is page furniture
+func isBoilerplate(n *html.Node, inSection bool) bool {
+	switch n.DataAtom {
+	case atom.Nav, atom.Aside, atom.Noscript, atom.Dialog, atom.Iframe, atom.Template, atom.Button:
+		return true
+	case atom.Header, atom.Footer:
+		return !inSection
+	case atom.Article, atom.Main, atom.Figure, atom.Figcaption, atom.Table, atom.Caption:
+		return false
+	}
+
+	role := nodeAttr(n, "role")
+	if boilerplateRoles[role] || (!inSection && (role == "banner" || role == "contentinfo")) {
+		return true
+	}
+	if nodeAttr(n, "aria-hidden") == "true" {
+		return true
+	}
+	if !boilerplatePattern.MatchString(nodeAttr(n, "class")) && !boilerplatePattern.MatchString(nodeAttr(n, "id")) {
+		return false
+	}
+	// A container of figures, tables, or the title is content despite its name
+	return findElement(n, atom.Figure) == nil && findElement(n, atom.Table) == nil && findElement(n, atom.H1) == nil
+}
+
+// findMainContainer returns the smallest main content container holding nearly
+// all of the text of the largest one, or nil if the page has none
+func findMainContainer(body *html.Node) *html.Node {
+	var candidates []*html.Node
+	var collect func(n *html.Node)
+	collect = func(n *html.Node) {
+		if n.Type == html.ElementNode && (n.DataAtom == atom.Article || n.DataAtom == atom.Main || nodeAttr(n, "role") == "main") {
+			candidates = append(candidates, n)
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			collect(child)
+		}
+	}
+	collect(body)
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	lengths := make([]int, len(candidates))
+	largest := 0
+	for i, candidate := range candidates {
+		lengths[i] = textLength(candidate)
+		largest = max(largest, lengths[i])
+	}
+	// Candidates are in document order, so a nested container follows its parent
+	var best *html.Node
+	for i, candidate := range candidates {
+		if float64(lengths[i]) >= mainContainerShare*float64(largest) {
+			best = candidate
+		}
+	}
+	return best
+}
+
+// findElement returns the first element of type a at or below n
+func findElement(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if found := findElement(child, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// textLength counts the characters of visible text below n, with runs of
+// whitespace counted once
+func textLength(n *html.Node) int {
+	switch {
+	case n.Type == html.TextNode:
+		return utf8.RuneCountInString(strings.Join(strings.Fields(n.Data), " "))
+	case n.Type == html.ElementNode && (n.DataAtom == atom.Script || n.DataAtom == atom.Style):
+		return 0
+	}
+	length := 0
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		length += textLength(child)
+	}
+	return length
+}
+
+// nodeAttr returns the value of an element's attribute, or "" if it has none
+func nodeAttr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
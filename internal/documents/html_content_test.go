@@ -0,0 +1,139 @@
+package documents
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPreprocessHTML_JournalPages(t *testing.T) {
+	tests := []struct {
+		fixture        string
+		wantContain    []string
+		wantNotContain []string
+	}{
+		{
+			fixture: "journal_article_semantic.html",
+			wantContain: []string{
+				"# Drought legacy effects on soil carbon cycling",
+				"## Abstract",
+				"Repeated droughts leave a legacy in soil microbial communities",
+				"## Introduction", "## Methods", "## Results", "## Discussion",
+				"Figure 1. Cumulative respiration",
+				"Table 1. Litter carbon recovered",
+				"Mineral-associated organic matter",
+				"Received: 3 May 2023",
+			},
+			wantNotContain: []string{
+				"Accept all cookies",
+				"Skip to main content",
+				"Biochemistry",
+				"Share on X",
+				"Related study number",
+				"newsletter",
+				"Editorial board",
+				"All rights reserved",
+			},
+		},
+		{
+			fixture: "journal_article_divs.html",
+			wantContain: []string{
+				"# Policy feedback and welfare state retrenchment",
+				"## Abstract",
+				"Why do some welfare programmes survive periods of fiscal austerity",
+				"## 1 Introduction", "## 2 Data and methods", "## 3 Results", "## 4 Conclusion",
+				"Unemployment insurance",
+			},
+			wantNotContain: []string{
+				"This website uses cookies",
+				"Topic collection",
+				"Sign in",
+				"Advertisement",
+				"You may also be interested in",
+				"LinkedIn",
+				"All rights reserved",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			page, err := os.ReadFile(filepath.Join("testdata", tt.fixture))
+			if err != nil {
+				t.Fatalf("Failed to read fixture: %v", err)
+			}
+			whole, err := convertHTML(page)
+			if err != nil {
+				t.Fatalf("convertHTML failed: %v", err)
+			}
+
+			for _, mode := range []HTMLExtractionMode{HTMLExtractionLenient, HTMLExtractionStrict} {
+				markdown, err := PreprocessHTML(page, mode)
+				if err != nil {
+					t.Fatalf("PreprocessHTML(%s) failed: %v", mode, err)
+				}
+				for _, want := range tt.wantContain {
+					if !strings.Contains(markdown, want) {
+						t.Errorf("%s: expected markdown to contain %q:\n%s", mode, want, markdown)
+					}
+				}
+				for _, notWant := range tt.wantNotContain {
+					if strings.Contains(markdown, notWant) {
+						t.Errorf("%s: expected markdown not to contain %q:\n%s", mode, notWant, markdown)
+					}
+				}
+				// Boilerplate is most of these pages
+				if reduction := 1 - float64(len(markdown))/float64(len(whole)); reduction < 0.5 {
+					t.Errorf("%s: expected extraction to remove at least 50%% of the whole-page markdown, removed %.0f%% (%d of %d bytes left)",
+						mode, 100*reduction, len(markdown), len(whole))
+				}
+			}
+		})
+	}
+}
+
+func TestPreprocessHTML_ShortMainContent(t *testing.T) {
+	// A listing page whose only <article> is a teaser: the real content is outside it
+	page := []byte(`<html><body>
+	<h1>Conference proceedings</h1>
+	<p>` + strings.Repeat("The proceedings collect the papers presented at the annual meeting. ", 20) + `</p>
+	<article><h2>Featured paper</h2><p>A short teaser.</p></article>
+	</body></html>`)
+
+	lenient, err := PreprocessHTML(page, HTMLExtractionLenient)
+	if err != nil {
+		t.Fatalf("PreprocessHTML failed: %v", err)
+	}
+	if !strings.Contains(lenient, "# Conference proceedings") || !strings.Contains(lenient, "Featured paper") {
+		t.Errorf("Expected lenient extraction to fall back to the whole page, got:\n%s", lenient)
+	}
+
+	strict, err := PreprocessHTML(page, HTMLExtractionStrict)
+	if err != nil {
+		t.Fatalf("PreprocessHTML failed: %v", err)
+	}
+	if strings.Contains(strict, "Conference proceedings") || !strings.Contains(strict, "Featured paper") {
+		t.Errorf("Expected strict extraction to keep only the article, got:\n%s", strict)
+	}
+}
+
+func TestConfiguredHTMLExtractionMode(t *testing.T) {
+	tests := []struct {
+		value     string
+		expected  HTMLExtractionMode
+		expectErr bool
+	}{
+		{"", HTMLExtractionLenient, false},
+		{"lenient", HTMLExtractionLenient, false},
+		{" Strict ", HTMLExtractionStrict, false},
+		{"aggressive", HTMLExtractionLenient, true},
+	}
+	for _, tt := range tests {
+		t.Setenv(htmlExtractionEnv, tt.value)
+		mode, err := ConfiguredHTMLExtractionMode()
+		if mode != tt.expected || (err != nil) != tt.expectErr {
+			t.Errorf("%s=%q: expected %s (error %v), got %s, %v", htmlExtractionEnv, tt.value, tt.expected, tt.expectErr, mode, err)
+		}
+	}
+}
@@ -0,0 +1,375 @@
+package documents
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// HTMLMetadata is the bibliographic metadata embedded in an HTML page
+type HTMLMetadata struct {
+	Metadata *models.ItemMetadata // nil if the page carries no recognized metadata
+	PDFURL   string               // Full-text PDF advertised by the page (citation_pdf_url)
+}
+
+// ExtractHTMLMetadata reads the Highwire Press (citation_*), Dublin Core (DC.*),
+// and JSON-LD metadata that academic publishers embed in their article pages.
+// JSON-LD scripts are read wherever they appear, since some sites place them in
+// the body rather than the head.
+// Per field, Highwire tags take precedence over Dublin Core, which takes
+// precedence over JSON-LD.
+func ExtractHTMLMetadata(htmlData []byte) HTMLMetadata {
+	highwire := &models.ItemMetadata{}
+	dublinCore := &models.ItemMetadata{}
+	jsonLD := &models.ItemMetadata{}
+	var firstPage, lastPage, pdfURL string
+
+	tokenizer := html.NewTokenizer(bytes.NewReader(htmlData))
+	inJSONLD := false
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+		token := tokenizer.Token()
+
+		switch tokenType {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			switch token.DataAtom {
+			case atom.Meta:
+				name, content := metaNameAndContent(token)
+				if content == "" {
+					continue
+				}
+				switch {
+				case strings.HasPrefix(name, "citation_"):
+					if name == "citation_pdf_url" {
+						if pdfURL == "" {
+							pdfURL = content
+						}
+						continue
+					}
+					applyHighwireTag(highwire, name, content, &firstPage, &lastPage)
+				case strings.HasPrefix(name, "dc.") || strings.HasPrefix(name, "dcterms."):
+					applyDublinCoreTag(dublinCore, name[strings.Index(name, ".")+1:], content)
+				}
+			case atom.Script:
+				inJSONLD = strings.EqualFold(tokenAttr(token, "type"), "application/ld+json")
+			}
+		case html.TextToken:
+			if inJSONLD {
+				applyJSONLD(jsonLD, []byte(token.Data))
+			}
+		case html.EndTagToken:
+			if token.DataAtom == atom.Script {
+				inJSONLD = false
+			}
+		}
+	}
+	return buildHTMLMetadata(pdfURL, finishPages(highwire, firstPage, lastPage), dublinCore, jsonLD)
+}
+
+// buildHTMLMetadata layers the metadata sources in priority order
+func buildHTMLMetadata(pdfURL string, sources ...*models.ItemMetadata) HTMLMetadata {
+	result := HTMLMetadata{PDFURL: pdfURL}
+	merged := &models.ItemMetadata{}
+	for _, source := range sources {
+		fillEmptyFields(merged, source)
+	}
+	if merged.Title == "" && len(merged.Authors) == 0 && merged.DOI == "" {
+		// A stray tag or two isn't enough to describe the document
+		return result
+	}
+	result.Metadata = merged
+	return result
+}
+
+// metaNameAndContent returns the lowercased name (or property) and the content of a meta tag
+func metaNameAndContent(token html.Token) (string, string) {
+	name := tokenAttr(token, "name")
+	if name == "" {
+		name = tokenAttr(token, "property")
+	}
+	return strings.ToLower(strings.TrimSpace(name)), strings.TrimSpace(tokenAttr(token, "content"))
+}
+
+func tokenAttr(token html.Token, key string) string {
+	for _, attr := range token.Attr {
+		if strings.EqualFold(attr.Key, key) {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// applyHighwireTag records a Highwire Press citation_* tag
+func applyHighwireTag(metadata *models.ItemMetadata, name, content string, firstPage, lastPage *string) {
+	switch name {
+	case "citation_title":
+		setIfEmpty(&metadata.Title, content)
+	case "citation_author":
+		metadata.Authors = append(metadata.Authors, normalizeAuthorName(content))
+	case "citation_publication_date", "citation_date", "citation_online_date", "citation_cover_date":
+		setIfEmpty(&metadata.PublicationDate, content)
+	case "citation_journal_title", "citation_conference_title", "citation_book_title", "citation_inbook_title":
+		setIfEmpty(&metadata.Publication, content)
+	case "citation_doi":
+		setIfEmpty(&metadata.DOI, cleanDOI(content))
+	case "citation_abstract":
+		setIfEmpty(&metadata.Abstract, content)
+	case "citation_publisher":
+		setIfEmpty(&metadata.Publisher, content)
+	case "citation_volume":
+		setIfEmpty(&metadata.Volume, content)
+	case "citation_issue":
+		setIfEmpty(&metadata.Issue, content)
+	case "citation_firstpage":
+		setIfEmpty(firstPage, content)
+	case "citation_lastpage":
+		setIfEmpty(lastPage, content)
+	case "citation_issn":
+		setIfEmpty(&metadata.ISSN, content)
+	case "citation_isbn":
+		setIfEmpty(&metadata.ISBN, content)
+	case "citation_abstract_html_url", "citation_fulltext_html_url", "citation_public_url":
+		setIfEmpty(&metadata.URL, content)
+	case "citation_language":
+		setIfEmpty(&metadata.Language, NormalizeLanguage(content))
+	}
+}
+
+// applyDublinCoreTag records a Dublin Core tag, given its element name without the DC. prefix
+func applyDublinCoreTag(metadata *models.ItemMetadata, element, content string) {
+	switch element {
+	case "title":
+		setIfEmpty(&metadata.Title, content)
+	case "creator":
+		metadata.Authors = append(metadata.Authors, normalizeAuthorName(content))
+	case "date", "issued", "date.issued":
+		setIfEmpty(&metadata.PublicationDate, content)
+	case "identifier":
+		if doi := cleanDOI(content); looksLikeDOI(doi) {
+			setIfEmpty(&metadata.DOI, doi)
+		}
+	case "description", "abstract":
+		setIfEmpty(&metadata.Abstract, content)
+	case "publisher":
+		setIfEmpty(&metadata.Publisher, content)
+	case "source", "ispartof":
+		setIfEmpty(&metadata.Publication, content)
+	case "language":
+		setIfEmpty(&metadata.Language, NormalizeLanguage(content))
+	}
+}
+
+// jsonLDTypes are the schema.org types whose JSON-LD describes a citable work
+var jsonLDTypes = []string{"ScholarlyArticle", "Article", "MedicalScholarlyArticle", "Report", "Thesis", "Book", "Chapter"}
+
+// applyJSONLD records the first citable work described by a JSON-LD script.
+// Malformed JSON-LD is ignored; it is common in the wild and not worth failing over.
+func applyJSONLD(metadata *models.ItemMetadata, data []byte) {
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return
+	}
+	work := findJSONLDWork(doc)
+	if work == nil {
+		return
+	}
+
+	title := jsonLDString(work["headline"])
+	if title == "" {
+		title = jsonLDString(work["name"])
+	}
+	setIfEmpty(&metadata.Title, title)
+
+	if len(metadata.Authors) == 0 {
+		for _, author := range jsonLDList(work["author"]) {
+			if name := jsonLDPersonName(author); name != "" {
+				metadata.Authors = append(metadata.Authors, name)
+			}
+		}
+	}
+
+	setIfEmpty(&metadata.PublicationDate, jsonLDString(work["datePublished"]))
+	abstract := jsonLDString(work["abstract"])
+	if abstract == "" {
+		abstract = jsonLDString(work["description"])
+	}
+	setIfEmpty(&metadata.Abstract, abstract)
+	if publisher, ok := work["publisher"].(map[string]any); ok {
+		setIfEmpty(&metadata.Publisher, jsonLDString(publisher["name"]))
+	} else {
+		setIfEmpty(&metadata.Publisher, jsonLDString(work["publisher"]))
+	}
+	setIfEmpty(&metadata.URL, jsonLDString(work["url"]))
+	setIfEmpty(&metadata.Language, NormalizeLanguage(jsonLDString(work["inLanguage"])))
+	setIfEmpty(&metadata.Pages, jsonLDString(work["pagination"]))
+	if metadata.Pages == "" {
+		metadata.Pages = joinPageRange(jsonLDString(work["pageStart"]), jsonLDString(work["pageEnd"]))
+	}
+
+	// The journal, volume, and issue are nested through isPartOf
+	for parent, ok := work["isPartOf"].(map[string]any); ok; parent, ok = parent["isPartOf"].(map[string]any) {
+		switch {
+		case jsonLDHasType(parent, "PublicationIssue"):
+			setIfEmpty(&metadata.Issue, jsonLDString(parent["issueNumber"]))
+		case jsonLDHasType(parent, "PublicationVolume"):
+			setIfEmpty(&metadata.Volume, jsonLDString(parent["volumeNumber"]))
+		default:
+			setIfEmpty(&metadata.Publication, jsonLDString(parent["name"]))
+			setIfEmpty(&metadata.ISSN, jsonLDString(parent["issn"]))
+		}
+	}
+
+	for _, identifier := range append(jsonLDList(work["identifier"]), jsonLDList(work["sameAs"])...) {
+		value := jsonLDString(identifier)
+		if object, ok := identifier.(map[string]any); ok {
+			if !strings.EqualFold(jsonLDString(object["propertyID"]), "doi") {
+				continue
+			}
+			value = jsonLDString(object["value"])
+		}
+		if doi := cleanDOI(value); looksLikeDOI(doi) {
+			setIfEmpty(&metadata.DOI, doi)
+			break
+		}
+	}
+}
+
+// findJSONLDWork searches a JSON-LD document (an object, an array, or an @graph)
+// for the first node with a citable type
+func findJSONLDWork(doc any) map[string]any {
+	switch node := doc.(type) {
+	case []any:
+		for _, element := range node {
+			if work := findJSONLDWork(element); work != nil {
+				return work
+			}
+		}
+	case map[string]any:
+		for _, t := range jsonLDTypes {
+			if jsonLDHasType(node, t) {
+				return node
+			}
+		}
+		if graph, ok := node["@graph"]; ok {
+			return findJSONLDWork(graph)
+		}
+	}
+	return nil
+}
+
+func jsonLDHasType(node map[string]any, want string) bool {
+	for _, t := range jsonLDList(node["@type"]) {
+		if s, ok := t.(string); ok && s == want {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonLDList returns a JSON-LD value as a list, since most properties may hold one value or many
+func jsonLDList(value any) []any {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []any:
+		return v
+	default:
+		return []any{v}
+	}
+}
+
+// jsonLDString returns a JSON-LD value as a string, accepting numbers for fields like volumeNumber
+func jsonLDString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+// jsonLDPersonName returns the name of a Person or Organization node, or of a plain string author
+func jsonLDPersonName(value any) string {
+	person, ok := value.(map[string]any)
+	if !ok {
+		return normalizeAuthorName(jsonLDString(value))
+	}
+	if name := jsonLDString(person["name"]); name != "" {
+		return normalizeAuthorName(name)
+	}
+	return strings.TrimSpace(jsonLDString(person["givenName"]) + " " + jsonLDString(person["familyName"]))
+}
+
+// normalizeAuthorName converts "Last, First" to the "First Last" form used elsewhere
+func normalizeAuthorName(name string) string {
+	name = strings.TrimSpace(name)
+	last, first, found := strings.Cut(name, ",")
+	if !found || strings.Contains(first, ",") {
+		return name
+	}
+	return strings.TrimSpace(strings.TrimSpace(first) + " " + strings.TrimSpace(last))
+}
+
+// cleanDOI strips the resolver URL or "doi:" prefix publishers often include
+func cleanDOI(value string) string {
+	value = strings.TrimSpace(value)
+	lower := strings.ToLower(value)
+	for _, prefix := range []string{"https://doi.org/", "http://doi.org/", "https://dx.doi.org/", "http://dx.doi.org/", "doi:"} {
+		if strings.HasPrefix(lower, prefix) {
+			return strings.TrimSpace(value[len(prefix):])
+		}
+	}
+	return value
+}
+
+func looksLikeDOI(value string) bool {
+	return strings.HasPrefix(value, "10.") && strings.Contains(value, "/")
+}
+
+// finishPages records the page range from citation_firstpage and citation_lastpage
+func finishPages(metadata *models.ItemMetadata, firstPage, lastPage string) *models.ItemMetadata {
+	setIfEmpty(&metadata.Pages, joinPageRange(firstPage, lastPage))
+	return metadata
+}
+
+func joinPageRange(first, last string) string {
+	if first == "" || last == "" || first == last {
+		return first
+	}
+	return first + "-" + last
+}
+
+// fillEmptyFields copies each field of src into dst where dst has no value yet
+func fillEmptyFields(dst, src *models.ItemMetadata) {
+	setIfEmpty(&dst.Title, src.Title)
+	if len(dst.Authors) == 0 {
+		dst.Authors = src.Authors
+	}
+	setIfEmpty(&dst.PublicationDate, src.PublicationDate)
+	setIfEmpty(&dst.Publication, src.Publication)
+	setIfEmpty(&dst.DOI, src.DOI)
+	setIfEmpty(&dst.Abstract, src.Abstract)
+	setIfEmpty(&dst.Publisher, src.Publisher)
+	setIfEmpty(&dst.Volume, src.Volume)
+	setIfEmpty(&dst.Issue, src.Issue)
+	setIfEmpty(&dst.Pages, src.Pages)
+	setIfEmpty(&dst.ISSN, src.ISSN)
+	setIfEmpty(&dst.ISBN, src.ISBN)
+	setIfEmpty(&dst.URL, src.URL)
+	setIfEmpty(&dst.Language, src.Language)
+}
+
+func setIfEmpty(field *string, value string) {
+	if *field == "" {
+		*field = value
+	}
+}
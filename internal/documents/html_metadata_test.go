@@ -0,0 +1,163 @@
+package documents
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestExtractHTMLMetadata(t *testing.T) {
+	tests := []struct {
+		name       string
+		html       string
+		want       *models.ItemMetadata
+		wantPDFURL string
+	}{
+		{
+			name: "highwire tags",
+			html: `<!DOCTYPE html>
+<html>
+<head>
+	<title>Journal of Examples | Article</title>
+	<meta name="citation_title" content="Sampling the Unsampleable">
+	<meta name="citation_author" content="Smith, Jane">
+	<meta name="citation_author" content="Robert Jones">
+	<meta name="citation_publication_date" content="2021/03/15">
+	<meta name="citation_journal_title" content="Journal of Examples">
+	<meta name="citation_volume" content="12">
+	<meta name="citation_issue" content="3">
+	<meta name="citation_firstpage" content="101">
+	<meta name="citation_lastpage" content="120">
+	<meta name="citation_doi" content="doi:10.1234/example.5678">
+	<meta name="citation_pdf_url" content="https://example.org/article.pdf">
+	<meta name="citation_language" content="German">
+	<meta name="DC.Publisher" content="Example Press">
+	<meta name="DC.Title" content="Ignored Because Highwire Wins">
+	<meta name="DC.Language" content="fr">
+</head>
+<body><h1>Sampling the Unsampleable</h1></body>
+</html>`,
+			want: &models.ItemMetadata{
+				Title:           "Sampling the Unsampleable",
+				Authors:         []string{"Jane Smith", "Robert Jones"},
+				PublicationDate: "2021/03/15",
+				Publication:     "Journal of Examples",
+				DOI:             "10.1234/example.5678",
+				Publisher:       "Example Press",
+				Volume:          "12",
+				Issue:           "3",
+				Pages:           "101-120",
+				Language:        "de",
+			},
+			wantPDFURL: "https://example.org/article.pdf",
+		},
+		{
+			name: "JSON-LD only",
+			html: `<html>
+<head>
+	<script type="application/ld+json">
+	{
+		"@context": "https://schema.org",
+		"@graph": [
+			{"@type": "WebPage", "name": "Article page"},
+			{
+				"@type": "ScholarlyArticle",
+				"headline": "Structured Data for Scholars",
+				"author": [
+					{"@type": "Person", "name": "Ada Lovelace"},
+					{"@type": "Person", "givenName": "Charles", "familyName": "Babbage"}
+				],
+				"datePublished": "2019-07-01",
+				"identifier": {"@type": "PropertyValue", "propertyID": "DOI", "value": "10.5555/sds.2019"},
+				"publisher": {"@type": "Organization", "name": "Analytical Press"},
+				"isPartOf": {
+					"@type": "PublicationIssue",
+					"issueNumber": 4,
+					"isPartOf": {
+						"@type": "PublicationVolume",
+						"volumeNumber": "7",
+						"isPartOf": {"@type": "Periodical", "name": "Engine Studies", "issn": "1234-5678"}
+					}
+				}
+			}
+		]
+	}
+	</script>
+</head>
+<body><p>Body text.</p></body>
+</html>`,
+			want: &models.ItemMetadata{
+				Title:           "Structured Data for Scholars",
+				Authors:         []string{"Ada Lovelace", "Charles Babbage"},
+				PublicationDate: "2019-07-01",
+				Publication:     "Engine Studies",
+				DOI:             "10.5555/sds.2019",
+				Publisher:       "Analytical Press",
+				Volume:          "7",
+				Issue:           "4",
+				ISSN:            "1234-5678",
+			},
+		},
+		{
+			name: "no embedded metadata",
+			html: `<html>
+<head>
+	<title>A Blog Post</title>
+	<meta name="description" content="Just a page">
+	<meta name="viewport" content="width=device-width">
+	<script type="application/ld+json">{"@type": "WebSite", "name": "My Blog"}</script>
+</head>
+<body><h1>A Blog Post</h1></body>
+</html>`,
+			want: nil,
+		},
+		{
+			name: "malformed JSON-LD is ignored",
+			html: `<html><head>
+	<meta name="citation_title" content="Still Parsed">
+	<script type="application/ld+json">{"@type": "ScholarlyArticle", </script>
+</head></html>`,
+			want: &models.ItemMetadata{Title: "Still Parsed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExtractHTMLMetadata([]byte(tt.html))
+			if !reflect.DeepEqual(got.Metadata, tt.want) {
+				t.Errorf("ExtractHTMLMetadata() metadata = %+v, want %+v", got.Metadata, tt.want)
+			}
+			if got.PDFURL != tt.wantPDFURL {
+				t.Errorf("ExtractHTMLMetadata() PDFURL = %q, want %q", got.PDFURL, tt.wantPDFURL)
+			}
+		})
+	}
+}
+
+func TestExtractHTMLMetadata_MergeWithExtracted(t *testing.T) {
+	html := `<html><head>
+	<meta name="citation_title" content="Publisher Title">
+	<meta name="citation_author" content="Jane Smith">
+</head></html>`
+	extracted := &models.ItemMetadata{
+		Title:    "LLM Title",
+		Authors:  []string{"J. Smith"},
+		Abstract: "Abstract read from the page text.",
+	}
+
+	merged := MergeMetadata(ExtractHTMLMetadata([]byte(html)).Metadata, extracted)
+
+	if merged.Title != "Publisher Title" {
+		t.Errorf("Title = %q, want meta tag title", merged.Title)
+	}
+	if !reflect.DeepEqual(merged.Authors, []string{"Jane Smith"}) {
+		t.Errorf("Authors = %v, want meta tag authors", merged.Authors)
+	}
+	if merged.Abstract != extracted.Abstract {
+		t.Errorf("Abstract = %q, want extracted abstract", merged.Abstract)
+	}
+	if merged.MetadataSource != "merged" {
+		t.Errorf("MetadataSource = %q, want merged", merged.MetadataSource)
+	}
+}
@@ -6,9 +6,9 @@ import (
 
 func TestPreprocessHTML(t *testing.T) {
 	tests := []struct {
-		name        string
-		html        string
-		wantContain []string
+		name           string
+		html           string
+		wantContain    []string
 		wantNotContain []string
 	}{
 		{
@@ -93,7 +93,7 @@ func TestPreprocessHTML(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			markdown, err := PreprocessHTML([]byte(tt.html))
+			markdown, err := PreprocessHTML([]byte(tt.html), HTMLExtractionLenient)
 			if err != nil {
 				t.Errorf("PreprocessHTML() error = %v", err)
 				return
@@ -123,7 +123,7 @@ func TestPreprocessHTML(t *testing.T) {
 
 // Helper function to check if a string contains a substring (case-insensitive)
 func contains(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr || len(substr) == 0 || 
+	return len(s) >= len(substr) && (s == substr || len(substr) == 0 ||
 		findSubstring(s, substr))
 }
 
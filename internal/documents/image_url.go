@@ -0,0 +1,64 @@
+package documents
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// imageURLPlaceholders are values the parser gives for an image it has no
+// URL for
+var imageURLPlaceholders = map[string]bool{
+	"-": true, "n/a": true, "na": true, "none": true, "null": true, "unknown": true, "undefined": true,
+}
+
+// SanitizeImageURL returns an image URL from a parsed document if it can be
+// fetched, or "" if not. Only absolute http(s) URLs are kept; in an HTML
+// document, URLs relative to the page are resolved against its source URL.
+// PDFs have no image URLs, so whatever the parser gave for one is dropped, as
+// are data URIs and other schemes.
+func SanitizeImageURL(value string, docType string, sourceURL string) string {
+	value = strings.Trim(strings.TrimSpace(value), "<>\"'")
+	if value == "" || docType == "pdf" || imageURLPlaceholders[strings.ToLower(value)] || strings.ContainsAny(value, " \t\r\n") {
+		return ""
+	}
+	u, err := url.Parse(value)
+	if err != nil {
+		return ""
+	}
+	if u.Scheme == "" && docType == "html" {
+		base, err := url.Parse(sourceURL)
+		if err != nil || !isWebURL(base) {
+			return ""
+		}
+		u = base.ResolveReference(u)
+	}
+	if !isWebURL(u) {
+		return ""
+	}
+	return u.String()
+}
+
+// isWebURL reports whether u is an absolute http(s) URL
+func isWebURL(u *url.URL) bool {
+	scheme := strings.ToLower(u.Scheme)
+	return (scheme == "http" || scheme == "https") && u.Host != ""
+}
+
+// SanitizeImages replaces the URLs of a parsed document's images with what
+// SanitizeImageURL makes of them and sets each image's source kind from its
+// data and remaining URL. It returns the number of URLs dropped.
+func SanitizeImages(images []models.Image, docType string, sourceURL string) int {
+	dropped := 0
+	for i := range images {
+		img := &images[i]
+		sanitized := SanitizeImageURL(img.ImageURL, docType, sourceURL)
+		if sanitized == "" && strings.TrimSpace(img.ImageURL) != "" {
+			dropped++
+		}
+		img.ImageURL = sanitized
+		img.SourceKind = img.ImpliedSourceKind()
+	}
+	return dropped
+}
@@ -0,0 +1,72 @@
+package documents
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestSanitizeImageURL(t *testing.T) {
+	const article = "https://journal.example.org/articles/42/full.html"
+	tests := []struct {
+		name      string
+		value     string
+		docType   string
+		sourceURL string
+		expected  string
+	}{
+		{"absolute URL", "https://cdn.example.org/fig1.png", "html", article, "https://cdn.example.org/fig1.png"},
+		{"http URL in plain text", "http://example.org/fig1.png", "md", "", "http://example.org/fig1.png"},
+		{"uppercase scheme", "HTTPS://example.org/fig1.png", "md", "", "https://example.org/fig1.png"},
+		{"padded and bracketed", "  <https://example.org/fig1.png> ", "md", "", "https://example.org/fig1.png"},
+		{"relative to the page", "figures/fig1.png", "html", article, "https://journal.example.org/articles/42/figures/fig1.png"},
+		{"relative to the site", "/media/fig1.png", "html", article, "https://journal.example.org/media/fig1.png"},
+		{"parent directory", "../fig1.png", "html", article, "https://journal.example.org/articles/fig1.png"},
+		{"protocol-relative", "//cdn.example.org/fig1.png", "html", article, "https://cdn.example.org/fig1.png"},
+		{"relative without a source URL", "figures/fig1.png", "html", "", ""},
+		{"relative to a non-web source", "figures/fig1.png", "html", "file:///tmp/page.html", ""},
+		{"relative outside HTML", "figures/fig1.png", "md", article, ""},
+		{"made-up PDF file name", "figure1.png", "pdf", "", ""},
+		{"URL given for a PDF", "https://example.org/fig1.png", "pdf", "https://example.org/paper.pdf", ""},
+		{"data URI", "data:image/png;base64,iVBORw0KGgoAAAANSUhEUg==", "html", article, ""},
+		{"garbage data URI", "data:image/png;base64,<image>", "pdf", "", ""},
+		{"javascript", "javascript:alert(1)", "html", article, ""},
+		{"file URL", "file:///Users/me/fig1.png", "md", "", ""},
+		{"scheme without host", "https:fig1.png", "md", "", ""},
+		{"placeholder", "N/A", "html", article, ""},
+		{"empty", "", "html", article, ""},
+		{"whitespace", "   ", "html", article, ""},
+		{"description instead of URL", "Figure 1 showing the results", "html", article, ""},
+		{"malformed", "http://[::1", "html", article, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeImageURL(tt.value, tt.docType, tt.sourceURL); got != tt.expected {
+				t.Errorf("SanitizeImageURL(%q, %q, %q) = %q, want %q", tt.value, tt.docType, tt.sourceURL, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSanitizeImages(t *testing.T) {
+	images := []models.Image{
+		{ImageURL: "img/map.jpg", Caption: "Map"},
+		{ImageURL: "figure2.png", MIMEType: "image/png", Data: []byte("png")},
+		{ImageURL: "data:image/gif;base64,garbage", Caption: "Chart"},
+		{Caption: "Diagram"},
+	}
+	dropped := SanitizeImages(images, "html", "https://example.org/post/")
+	if dropped != 1 {
+		t.Errorf("Expected 1 URL dropped, got %d", dropped)
+	}
+	expected := []models.Image{
+		{ImageURL: "https://example.org/post/img/map.jpg", Caption: "Map", SourceKind: models.ImageSourceExternalURL},
+		{ImageURL: "https://example.org/post/figure2.png", MIMEType: "image/png", Data: []byte("png"), SourceKind: models.ImageSourceEmbedded},
+		{Caption: "Chart", SourceKind: models.ImageSourceUnavailable},
+		{Caption: "Diagram", SourceKind: models.ImageSourceUnavailable},
+	}
+	if !reflect.DeepEqual(images, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, images)
+	}
+}
@@ -0,0 +1,449 @@
+package documents
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/citations"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// MetadataSourceImported marks documents parsed elsewhere and imported as
+	// they are
+	MetadataSourceImported = "imported"
+
+	// ImportParserVersion is the provenance parser version of imported documents
+	ImportParserVersion = "import-1"
+)
+
+// ImportProblem is a problem with one field of an imported document
+type ImportProblem struct {
+	Field   string `json:"field"`   // e.g., "metadata.doi", "pages[2]"
+	Message string `json:"message"` // What is wrong with it
+}
+
+// ImportError lists every problem found with an imported document, so they can
+// all be fixed at once
+type ImportError struct {
+	Problems []ImportProblem
+}
+
+func (e *ImportError) Error() string {
+	problems := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		problems[i] = problem.Field + ": " + problem.Message
+	}
+	return "invalid document: " + strings.Join(problems, "; ")
+}
+
+// importProblem returns an ImportError with a single problem
+func importProblem(field, message string) error {
+	return &ImportError{Problems: []ImportProblem{{Field: field, Message: message}}}
+}
+
+// DecodeImportJSON decodes a parsed document from JSON with the fields of
+// models.ParsedItem, as written by the document-export tool (whose
+// "document_id" is ignored). Unknown fields and values of the wrong type are
+// reported as an ImportError naming the field.
+func DecodeImportJSON(data []byte) (*models.ParsedItem, error) {
+	var payload struct {
+		DocumentID string `json:"document_id"` // Written by document-export; the ID is assigned on import
+		*models.ParsedItem
+	}
+	payload.ParsedItem = &models.ParsedItem{}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&payload); err != nil {
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &syntaxErr):
+			return nil, importProblem("json", fmt.Sprintf("invalid JSON at offset %d: %v", syntaxErr.Offset, syntaxErr))
+		case errors.As(err, &typeErr):
+			field := typeErr.Field
+			if field == "" {
+				field = "json"
+			}
+			return nil, importProblem(field, fmt.Sprintf("expected %s, got %s", typeErr.Type, typeErr.Value))
+		}
+		// Unknown fields have no error type of their own
+		if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			unquoted, unquoteErr := strconv.Unquote(name)
+			if unquoteErr == nil {
+				name = unquoted
+			}
+			return nil, importProblem(name, "unknown field")
+		}
+		return nil, importProblem("json", err.Error())
+	}
+	if decoder.More() {
+		return nil, importProblem("json", "unexpected data after the document")
+	}
+	return payload.ParsedItem, nil
+}
+
+var (
+	importPageComment   = regexp.MustCompile(`(?m)^[ \t]*<!--\s*page\s+(.*?)\s*-->[ \t]*$`)
+	importHeading       = regexp.MustCompile(`(?m)^#{1,6}[ \t]+(.+?)[ \t#]*$`)
+	importReferenceItem = regexp.MustCompile(`^(?:[-*+]|\d+[.)])[ \t]+`)
+)
+
+// ParseMarkdownImport reads a document written as Markdown, as exported by
+// ExportMarkdown: optional YAML front matter with the metadata (the keys
+// ExportMarkdown writes, plus language and tags), pages separated by
+// "<!-- page N -->" comments holding their source page numbers, and a final
+// References section listing one reference per item. Without page comments the
+// body is a single page; without a title in the front matter the first heading
+// is the title. Notes stay in the page text.
+func ParseMarkdownImport(content string) (*models.ParsedItem, error) {
+	content = strings.ReplaceAll(strings.TrimPrefix(content, "\ufeff"), "\r\n", "\n")
+	item := &models.ParsedItem{}
+
+	body, frontMatter, hasFrontMatter := cutFrontMatter(content)
+	if hasFrontMatter {
+		if err := parseFrontMatter(frontMatter, &item.Metadata); err != nil {
+			return nil, err
+		}
+	}
+
+	body, item.References = cutReferences(body)
+
+	locations := importPageComment.FindAllStringSubmatchIndex(body, -1)
+	if len(locations) == 0 {
+		item.Pages = []string{strings.TrimSpace(body)}
+		item.PageNumbers = []string{"1"}
+	} else {
+		// Text before the first page comment belongs to the first page
+		lead := strings.TrimSpace(body[:locations[0][0]])
+		for i, loc := range locations {
+			end := len(body)
+			if i+1 < len(locations) {
+				end = locations[i+1][0]
+			}
+			page := strings.TrimSpace(body[loc[1]:end])
+			if i == 0 && lead != "" {
+				page = strings.TrimSpace(lead + "\n\n" + page)
+			}
+			item.Pages = append(item.Pages, page)
+			item.PageNumbers = append(item.PageNumbers, body[loc[2]:loc[3]])
+		}
+	}
+
+	if item.Metadata.Title == "" {
+		for _, page := range item.Pages {
+			if match := importHeading.FindStringSubmatch(page); match != nil {
+				item.Metadata.Title = strings.TrimSpace(match[1])
+				break
+			}
+		}
+	}
+	return item, nil
+}
+
+// cutFrontMatter splits YAML front matter, delimited by "---" lines at the start
+// of content, from the body
+func cutFrontMatter(content string) (body, frontMatter string, ok bool) {
+	rest, found := strings.CutPrefix(content, "---\n")
+	if !found {
+		return content, "", false
+	}
+	for offset := 0; offset < len(rest); {
+		line, _, _ := strings.Cut(rest[offset:], "\n")
+		if trimmed := strings.TrimRight(line, " \t"); trimmed == "---" || trimmed == "..." {
+			end := min(offset+len(line)+1, len(rest))
+			return rest[end:], rest[:offset], true
+		}
+		offset += len(line) + 1
+	}
+	return content, "", false
+}
+
+// frontMatterFields maps front matter keys to the metadata fields they set;
+// author, authors, and tags are handled separately as they may be lists
+var frontMatterFields = []struct {
+	key   string
+	field string
+}{
+	{"title", "title"},
+	{"date", "publication_date"},
+	{"publication_date", "publication_date"},
+	{"citekey", ""}, // Not a settable metadata field
+	{"item_type", "item_type"},
+	{"publication", "publication"},
+	{"publisher", "publisher"},
+	{"volume", "volume"},
+	{"issue", "issue"},
+	{"pages", "pages"},
+	{"doi", "doi"},
+	{"isbn", "isbn"},
+	{"issn", "issn"},
+	{"url", "url"},
+	{"abstract", "abstract"},
+	{"language", "language"},
+}
+
+// parseFrontMatter sets metadata from YAML front matter. Unknown keys (such as
+// the document_id ExportMarkdown writes) are ignored.
+func parseFrontMatter(frontMatter string, metadata *models.ItemMetadata) error {
+	var values map[string]interface{}
+	if err := yaml.Unmarshal([]byte(frontMatter), &values); err != nil {
+		return importProblem("front_matter", fmt.Sprintf("invalid YAML: %v", err))
+	}
+
+	var problems []ImportProblem
+	for _, entry := range frontMatterFields {
+		value, ok := values[entry.key]
+		if !ok || value == nil {
+			continue
+		}
+		text, ok := yamlScalar(value)
+		if !ok {
+			problems = append(problems, ImportProblem{Field: "front_matter." + entry.key, Message: "must be a single value"})
+			continue
+		}
+		// Language is validated with the rest of the document, not dropped
+		// here when unrecognized
+		switch entry.key {
+		case "citekey":
+			metadata.Citekey = text
+			continue
+		case "language":
+			metadata.Language = text
+			continue
+		}
+		for _, field := range metadataFields {
+			if field.name == entry.field {
+				field.set(metadata, text)
+			}
+		}
+	}
+	for _, key := range []string{"author", "authors", "tags"} {
+		value, ok := values[key]
+		if !ok || value == nil {
+			continue
+		}
+		list, ok := yamlList(value)
+		if !ok {
+			problems = append(problems, ImportProblem{Field: "front_matter." + key, Message: "must be a name or a list of names"})
+			continue
+		}
+		if key == "tags" {
+			metadata.Tags = list
+		} else {
+			metadata.Authors = append(metadata.Authors, list...)
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ImportError{Problems: problems}
+	}
+	return nil
+}
+
+// yamlScalar returns a YAML scalar (a string, number, or boolean) as text
+func yamlScalar(value interface{}) (string, bool) {
+	switch value.(type) {
+	case string, int, int64, uint64, float64, bool:
+		return strings.TrimSpace(fmt.Sprint(value)), true
+	}
+	return "", false
+}
+
+// yamlList returns a YAML scalar or list of scalars as a list of text. A single
+// string may hold several names separated by semicolons.
+func yamlList(value interface{}) ([]string, bool) {
+	if list, ok := value.([]interface{}); ok {
+		var result []string
+		for _, element := range list {
+			text, ok := yamlScalar(element)
+			if !ok {
+				return nil, false
+			}
+			result = append(result, text)
+		}
+		return result, true
+	}
+	text, ok := yamlScalar(value)
+	if !ok {
+		return nil, false
+	}
+	return splitAuthors(text), true
+}
+
+// cutReferences removes the last References section from body, returning its
+// list items as references. The section runs to the next heading.
+func cutReferences(body string) (string, []models.Reference) {
+	start, end := -1, -1
+	for _, loc := range importHeading.FindAllStringSubmatchIndex(body, -1) {
+		if start >= 0 && end < 0 {
+			end = loc[0]
+		}
+		if strings.EqualFold(strings.TrimSpace(body[loc[2]:loc[3]]), "References") {
+			start, end = loc[0], -1
+		}
+	}
+	if start < 0 {
+		return body, nil
+	}
+	if end < 0 {
+		end = len(body)
+	}
+
+	var references []models.Reference
+	for _, line := range strings.Split(body[strings.Index(body[start:], "\n")+start+1:end], "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+		case importReferenceItem.MatchString(line):
+			text := importReferenceItem.ReplaceAllString(line, "")
+			references = append(references, models.Reference{ReferenceText: text})
+		case len(references) > 0:
+			// A reference wrapped onto the next line
+			last := &references[len(references)-1]
+			last.ReferenceText += " " + line
+		}
+	}
+	return body[:start] + body[end:], references
+}
+
+var (
+	// importCitekey matches pandoc citekeys: letters, digits, and underscores,
+	// with internal punctuation
+	importCitekey = regexp.MustCompile(`^[\p{L}\p{N}_]+(?:[:.#$%&+?<>~/-][\p{L}\p{N}_]+)*$`)
+	importYear    = regexp.MustCompile(`\b\d{4}\b`)
+)
+
+// ValidateImport checks a document parsed elsewhere before it is stored,
+// returning an ImportError listing every problem found. The document must have
+// pages with content, matching page numbers (numbered sequentially if there
+// are none), and a title; identifiers, the date, the URL, and the citekey must
+// be well formed when given. DOIs, ISBNs, and the language are normalized,
+// surrounding whitespace is trimmed, and metadata without field sources is
+// marked as external, so the document is stored in the same form as a parsed
+// one.
+func ValidateImport(item *models.ParsedItem) error {
+	var problems []ImportProblem
+	add := func(field, format string, args ...any) {
+		problems = append(problems, ImportProblem{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(item.Pages) == 0 {
+		add("pages", "at least one page is required")
+	}
+	for i, page := range item.Pages {
+		if strings.TrimSpace(page) == "" {
+			add(fmt.Sprintf("pages[%d]", i), "is empty")
+		}
+	}
+	switch {
+	case len(item.PageNumbers) == 0:
+		for i := range item.Pages {
+			item.PageNumbers = append(item.PageNumbers, strconv.Itoa(i+1))
+		}
+	case len(item.PageNumbers) != len(item.Pages):
+		add("page_numbers", "has %d entries for %d pages", len(item.PageNumbers), len(item.Pages))
+	default:
+		for i, number := range item.PageNumbers {
+			if item.PageNumbers[i] = strings.TrimSpace(number); item.PageNumbers[i] == "" {
+				add(fmt.Sprintf("page_numbers[%d]", i), "is empty")
+			}
+		}
+	}
+	if len(item.PageQuality) > 0 && len(item.PageQuality) != len(item.Pages) {
+		add("page_quality", "has %d entries for %d pages", len(item.PageQuality), len(item.Pages))
+	}
+
+	metadata := &item.Metadata
+	for _, field := range metadataFields {
+		// Authors are trimmed below; setting the language would drop it if unrecognized
+		if field.name != "authors" && field.name != "language" {
+			field.set(metadata, strings.TrimSpace(field.get(metadata)))
+		}
+	}
+	if metadata.Title == "" {
+		add("metadata.title", "is required")
+	}
+	for i, author := range metadata.Authors {
+		if metadata.Authors[i] = strings.TrimSpace(author); metadata.Authors[i] == "" {
+			add(fmt.Sprintf("metadata.authors[%d]", i), "is empty")
+		}
+	}
+	if metadata.PublicationDate != "" && !importYear.MatchString(metadata.PublicationDate) {
+		add("metadata.publication_date", "%q has no four-digit year", metadata.PublicationDate)
+	}
+	if metadata.DOI != "" {
+		if doi, ok := citations.NormalizeDOI(metadata.DOI); ok {
+			metadata.DOI = doi
+		} else {
+			add("metadata.doi", "%q is not a DOI (expected the form 10.1234/suffix)", metadata.DOI)
+		}
+	}
+	if metadata.ISBN != "" {
+		if isbn, ok := citations.NormalizeISBN(metadata.ISBN); ok {
+			metadata.ISBN = isbn
+		} else {
+			add("metadata.isbn", "%q is not a valid ISBN", metadata.ISBN)
+		}
+	}
+	if metadata.Language = strings.TrimSpace(metadata.Language); metadata.Language != "" {
+		if language := NormalizeLanguage(metadata.Language); language != "" {
+			metadata.Language = language
+		} else {
+			add("metadata.language", "unrecognized language %q (use an ISO 639-1 code such as \"en\")", metadata.Language)
+		}
+	}
+	if metadata.URL != "" {
+		if parsed, err := url.Parse(metadata.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			add("metadata.url", "%q is not an http or https URL", metadata.URL)
+		}
+	}
+	if metadata.Citekey = strings.TrimSpace(metadata.Citekey); metadata.Citekey != "" && !importCitekey.MatchString(metadata.Citekey) {
+		add("metadata.citekey", "%q is not a valid citekey (use letters, digits, and underscores)", metadata.Citekey)
+	}
+	for field, source := range metadata.FieldSources {
+		if source != FieldSourceExternal && source != FieldSourceExtracted && source != FieldSourceManual {
+			add("metadata.field_sources."+field, "unknown source %q", source)
+		}
+	}
+	if metadata.FieldSources == nil {
+		metadata.FieldSources = fieldSources(metadata, FieldSourceExternal)
+	}
+
+	for i := range item.References {
+		ref := &item.References[i]
+		if ref.ReferenceText = strings.TrimSpace(ref.ReferenceText); ref.ReferenceText == "" {
+			add(fmt.Sprintf("references[%d].reference_text", i), "is empty")
+		}
+		if ref.DOI != "" {
+			if doi, ok := citations.NormalizeDOI(ref.DOI); ok {
+				ref.DOI = doi
+			} else {
+				add(fmt.Sprintf("references[%d].doi", i), "%q is not a DOI", ref.DOI)
+			}
+		}
+	}
+	for i, footnote := range item.Footnotes {
+		if strings.TrimSpace(footnote.Text) == "" {
+			add(fmt.Sprintf("footnotes[%d].text", i), "is empty")
+		}
+	}
+	for i, endnote := range item.Endnotes {
+		if strings.TrimSpace(endnote.Text) == "" {
+			add(fmt.Sprintf("endnotes[%d].text", i), "is empty")
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ImportError{Problems: problems}
+	}
+	return nil
+}
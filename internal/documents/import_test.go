@@ -0,0 +1,220 @@
+package documents
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// importProblems returns the problems of an ImportError, failing if err is not one
+func importProblems(t *testing.T, err error) []ImportProblem {
+	t.Helper()
+	var importErr *ImportError
+	if !errors.As(err, &importErr) {
+		t.Fatalf("Expected an ImportError, got %v", err)
+	}
+	return importErr.Problems
+}
+
+func TestParseMarkdownImport_RoundTrip(t *testing.T) {
+	item := &models.ParsedItem{
+		Metadata: models.ItemMetadata{
+			Title:           "Memory and the \"Archive\"",
+			Authors:         []string{"Smith, Jane", "Robert Jones"},
+			PublicationDate: "2020-05-01",
+			Citekey:         "smithJones2020",
+			Publication:     "Journal of Memory Studies",
+			Volume:          "12",
+			DOI:             "10.1000/jms.2020.1",
+		},
+		Pages:       []string{"# Introduction\n\nThe archive is a place.", "## Method\n\nWe read letters."},
+		PageNumbers: []string{"45", "46"},
+		References: []models.Reference{
+			{ReferenceText: "Derrida, J. (1995). Archive Fever."},
+			{ReferenceText: "Steedman, C. (2001). Dust."},
+		},
+	}
+
+	imported, err := ParseMarkdownImport(ExportMarkdown("doc-1", item))
+	if err != nil {
+		t.Fatalf("ParseMarkdownImport failed: %v", err)
+	}
+	if imported.Metadata.Title != item.Metadata.Title || !slices.Equal(imported.Metadata.Authors, item.Metadata.Authors) {
+		t.Errorf("Unexpected title or authors: %+v", imported.Metadata)
+	}
+	if imported.Metadata.PublicationDate != "2020-05-01" || imported.Metadata.Citekey != "smithJones2020" || imported.Metadata.Volume != "12" || imported.Metadata.DOI != "10.1000/jms.2020.1" {
+		t.Errorf("Unexpected metadata: %+v", imported.Metadata)
+	}
+	if !slices.Equal(imported.Pages, item.Pages) || !slices.Equal(imported.PageNumbers, item.PageNumbers) {
+		t.Errorf("Unexpected pages:\n got %q %q\nwant %q %q", imported.Pages, imported.PageNumbers, item.Pages, item.PageNumbers)
+	}
+	if !reflect.DeepEqual(imported.References, item.References) {
+		t.Errorf("Unexpected references: %+v", imported.References)
+	}
+}
+
+func TestParseMarkdownImport(t *testing.T) {
+	content := "---\n" +
+		"author: Jane Smith; Robert Jones\n" +
+		"date: 2020\n" +
+		"volume: 12\n" +
+		"language: German\n" +
+		"tags: [memory, archives]\n" +
+		"document_id: ignored\n" +
+		"---\n" +
+		"# Field Notes\n\n" +
+		"Observations from the archive.\n\n" +
+		"## References\n\n" +
+		"1. Smith, J. (2001). A long reference\n" +
+		"   that wraps.\n" +
+		"2. Jones, R. (2005). Another.\n\n" +
+		"## Appendix\n\n" +
+		"Further material.\n"
+
+	item, err := ParseMarkdownImport(content)
+	if err != nil {
+		t.Fatalf("ParseMarkdownImport failed: %v", err)
+	}
+	if item.Metadata.Title != "Field Notes" {
+		t.Errorf("Expected the heading as the title, got %q", item.Metadata.Title)
+	}
+	if !slices.Equal(item.Metadata.Authors, []string{"Jane Smith", "Robert Jones"}) || !slices.Equal(item.Metadata.Tags, []string{"memory", "archives"}) {
+		t.Errorf("Unexpected authors or tags: %+v", item.Metadata)
+	}
+	if item.Metadata.PublicationDate != "2020" || item.Metadata.Volume != "12" || item.Metadata.Language != "German" {
+		t.Errorf("Expected numbers as text and the language as given, got %+v", item.Metadata)
+	}
+	wantPage := "# Field Notes\n\nObservations from the archive.\n\n## Appendix\n\nFurther material."
+	if !slices.Equal(item.Pages, []string{wantPage}) || !slices.Equal(item.PageNumbers, []string{"1"}) {
+		t.Errorf("Expected a single page without the references, got %q %q", item.Pages, item.PageNumbers)
+	}
+	wantRefs := []models.Reference{
+		{ReferenceText: "Smith, J. (2001). A long reference that wraps."},
+		{ReferenceText: "Jones, R. (2005). Another."},
+	}
+	if !reflect.DeepEqual(item.References, wantRefs) {
+		t.Errorf("Unexpected references: %+v", item.References)
+	}
+}
+
+func TestParseMarkdownImport_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		fields  []string
+	}{
+		{"invalid YAML", "---\ntitle: [unclosed\n---\nText", []string{"front_matter"}},
+		{"list title", "---\ntitle: [a, b]\nauthor: {name: x}\n---\nText", []string{"front_matter.title", "front_matter.author"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseMarkdownImport(tt.content)
+			var fields []string
+			for _, problem := range importProblems(t, err) {
+				fields = append(fields, problem.Field)
+			}
+			if !slices.Equal(fields, tt.fields) {
+				t.Errorf("Expected problems with %q, got %q", tt.fields, fields)
+			}
+		})
+	}
+}
+
+func TestDecodeImportJSON(t *testing.T) {
+	item, err := DecodeImportJSON([]byte(`{"document_id": "data_abc", "metadata": {"title": "Field Notes"}, "pages": ["Text"]}`))
+	if err != nil {
+		t.Fatalf("DecodeImportJSON failed: %v", err)
+	}
+	if item.Metadata.Title != "Field Notes" || !slices.Equal(item.Pages, []string{"Text"}) {
+		t.Errorf("Unexpected item: %+v", item)
+	}
+
+	tests := []struct {
+		json  string
+		field string
+	}{
+		{`{"pages": "Text"}`, "pages"},
+		{`{"metadata": {"authors": "Jane Smith"}}`, "metadata.authors"},
+		{`{"pagez": ["Text"]}`, "pagez"},
+		{`{"pages": [`, "json"},
+		{`{"pages": ["Text"]} {}`, "json"},
+	}
+	for _, tt := range tests {
+		_, err := DecodeImportJSON([]byte(tt.json))
+		problems := importProblems(t, err)
+		if len(problems) != 1 || problems[0].Field != tt.field {
+			t.Errorf("DecodeImportJSON(%s): expected a problem with %q, got %+v", tt.json, tt.field, problems)
+		}
+	}
+}
+
+func TestValidateImport(t *testing.T) {
+	item := &models.ParsedItem{
+		Metadata: models.ItemMetadata{
+			Title:           "  Field Notes ",
+			Authors:         []string{" Jane Smith "},
+			PublicationDate: "1887",
+			DOI:             "https://doi.org/10.1000/ABC",
+			ISBN:            "0-306-40615-2",
+			Language:        "German",
+			URL:             "https://example.com/notes",
+			Citekey:         "smith:notes",
+		},
+		Pages:      []string{"Page one", "Page two"},
+		References: []models.Reference{{ReferenceText: " Ref ", DOI: "doi:10.1000/XYZ"}},
+	}
+	if err := ValidateImport(item); err != nil {
+		t.Fatalf("ValidateImport failed: %v", err)
+	}
+	metadata := item.Metadata
+	if metadata.Title != "Field Notes" || metadata.Authors[0] != "Jane Smith" || metadata.DOI != "10.1000/abc" || metadata.ISBN != "9780306406157" || metadata.Language != "de" {
+		t.Errorf("Expected normalized metadata, got %+v", metadata)
+	}
+	if !slices.Equal(item.PageNumbers, []string{"1", "2"}) {
+		t.Errorf("Expected sequential page numbers, got %q", item.PageNumbers)
+	}
+	if item.References[0].ReferenceText != "Ref" || item.References[0].DOI != "10.1000/xyz" {
+		t.Errorf("Expected a normalized reference, got %+v", item.References[0])
+	}
+	if metadata.FieldSources["title"] != FieldSourceExternal || metadata.FieldSources["publication"] != "" {
+		t.Errorf("Expected the given fields marked external, got %v", metadata.FieldSources)
+	}
+
+	invalid := &models.ParsedItem{
+		Metadata: models.ItemMetadata{
+			Authors:         []string{"Jane Smith", " "},
+			PublicationDate: "n.d.",
+			DOI:             "not-a-doi",
+			ISBN:            "123",
+			Language:        "Klingon",
+			URL:             "file:///etc/passwd",
+			Citekey:         "smith 2020",
+			FieldSources:    map[string]string{"title": "guessed"},
+		},
+		Pages:       []string{"Text", " \n"},
+		PageNumbers: []string{"1"},
+		PageQuality: []models.PageQuality{{}},
+		References:  []models.Reference{{ReferenceText: ""}},
+		Endnotes:    []models.Endnote{{Marker: "1"}},
+	}
+	var fields []string
+	for _, problem := range importProblems(t, ValidateImport(invalid)) {
+		fields = append(fields, problem.Field)
+	}
+	want := []string{
+		"pages[1]", "page_numbers", "page_quality",
+		"metadata.title", "metadata.authors[1]", "metadata.publication_date", "metadata.doi", "metadata.isbn",
+		"metadata.language", "metadata.url", "metadata.citekey", "metadata.field_sources.title",
+		"references[0].reference_text", "endnotes[0].text",
+	}
+	if !slices.Equal(fields, want) {
+		t.Errorf("Unexpected problems:\n got %q\nwant %q", fields, want)
+	}
+
+	if problems := importProblems(t, ValidateImport(&models.ParsedItem{Metadata: models.ItemMetadata{Title: "Empty"}})); len(problems) != 1 || problems[0].Field != "pages" {
+		t.Errorf("Expected a document without pages to be refused, got %+v", problems)
+	}
+}
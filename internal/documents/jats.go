@@ -0,0 +1,273 @@
+package documents
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/citations"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// MetadataSourceJATS marks metadata read from a JATS article's front matter
+const MetadataSourceJATS = "jats"
+
+// jatsFormat renders JATS body markup
+var jatsFormat = &markupFormat{
+	section:   "sec",
+	heading:   "title",
+	paragraph: "p",
+	list:      "list",
+	listItem:  "list-item",
+	quote:     "disp-quote",
+	skipped: map[string]bool{
+		"table-wrap": true, "table-wrap-group": true, "fig": true, "fig-group": true,
+		"fn": true, "fn-group": true, "label": true, "ref-list": true,
+	},
+	emphasis: func(n *xmlNode) string {
+		switch n.name {
+		case "italic":
+			return "*"
+		case "bold":
+			return "**"
+		}
+		return ""
+	},
+}
+
+// jatsPubDateTypes are the pub-date types taken for the publication date, in
+// order of preference, before any other
+var jatsPubDateTypes = []string{"epub", "ppub", "pub", "epub-ppub", "collection"}
+
+// parseJATS reads a JATS article: metadata from its article-meta and
+// journal-meta, the body, the back matter's references and notes, and the
+// tables and figures wherever they float
+func parseJATS(article *xmlNode) *models.ParsedItem {
+	front := article.child("front")
+	meta := front.find("article-meta")
+	item := &models.ParsedItem{Metadata: jatsMetadata(article, meta, front.find("journal-meta"))}
+
+	var page strings.Builder
+	if item.Metadata.Title != "" {
+		writeMarkdownBlock(&page, "# "+item.Metadata.Title)
+	}
+	if item.Metadata.Abstract != "" {
+		writeMarkdownBlock(&page, "## Abstract")
+		writeMarkdownBlock(&page, item.Metadata.Abstract)
+	}
+	if body := article.child("body"); body != nil {
+		jatsFormat.writeBlocks(&page, body, 1)
+	}
+	item.Pages = []string{page.String()}
+
+	for _, ref := range article.child("back").findAll("ref") {
+		if reference, ok := jatsReference(ref); ok {
+			item.References = append(item.References, reference)
+		}
+	}
+	for i, fn := range article.findAll("fn") {
+		text := jatsFormat.inline(fn)
+		if text == "" {
+			continue
+		}
+		marker := fn.child("label").plainText()
+		if marker == "" {
+			marker = fmt.Sprint(i + 1)
+		}
+		item.Endnotes = append(item.Endnotes, models.Endnote{Marker: marker, Text: text, PageNumber: "1"})
+	}
+	for _, wrap := range article.findAll("table-wrap") {
+		item.Tables = append(item.Tables, jatsTable(wrap))
+	}
+	for _, fig := range article.findAll("fig") {
+		caption := joinLabel(fig.child("label").plainText(), jatsFormat.inline(fig.child("caption")))
+		if caption != "" {
+			item.Images = append(item.Images, models.Image{Caption: caption})
+		}
+	}
+	return item
+}
+
+// jatsMetadata reads the article's metadata, and its language from the root
+func jatsMetadata(article, meta, journal *xmlNode) models.ItemMetadata {
+	metadata := models.ItemMetadata{
+		ItemType:       "journalArticle",
+		MetadataSource: MetadataSourceJATS,
+		Language:       NormalizeLanguage(article.attr("lang")),
+		Volume:         meta.child("volume").plainText(),
+		Issue:          meta.child("issue").plainText(),
+		Pages:          joinPageRange(meta.child("fpage").plainText(), meta.child("lpage").plainText()),
+	}
+	if metadata.Pages == "" {
+		metadata.Pages = meta.child("elocation-id").plainText()
+	}
+
+	titles := meta.child("title-group")
+	metadata.Title = titles.child("article-title").plainText()
+	if subtitle := titles.child("subtitle").plainText(); subtitle != "" && metadata.Title != "" {
+		metadata.Title += ": " + subtitle
+	}
+
+	for _, contrib := range meta.findAll("contrib") {
+		if kind := contrib.attr("contrib-type"); kind != "" && kind != "author" {
+			continue
+		}
+		if name := jatsPersonName(contrib); name != "" {
+			metadata.Authors = append(metadata.Authors, name)
+		}
+	}
+
+	metadata.PublicationDate = jatsDate(meta.childrenNamed("pub-date"))
+	for _, id := range meta.childrenNamed("article-id") {
+		if id.attr("pub-id-type") == "doi" {
+			if doi, ok := citations.NormalizeDOI(id.plainText()); ok {
+				metadata.DOI = doi
+			}
+		}
+	}
+
+	var abstract []string
+	if summary := meta.child("abstract"); summary != nil {
+		for _, p := range summary.findAll("p") {
+			if text := jatsFormat.inline(p); text != "" {
+				abstract = append(abstract, text)
+			}
+		}
+	}
+	metadata.Abstract = strings.Join(abstract, "\n\n")
+
+	metadata.Publication = journal.find("journal-title").plainText()
+	metadata.Publisher = journal.find("publisher-name").plainText()
+	metadata.ISSN = journal.child("issn").plainText()
+	return metadata
+}
+
+// jatsPersonName returns the name of a contributor or cited author as "Given
+// Surname", or a collaboration's name
+func jatsPersonName(n *xmlNode) string {
+	name := n.child("name")
+	if name == nil {
+		name = n.child("string-name")
+	}
+	if name == nil {
+		if n.name == "name" || n.name == "string-name" {
+			name = n
+		} else {
+			return n.child("collab").plainText()
+		}
+	}
+	given, surname := name.child("given-names").plainText(), name.child("surname").plainText()
+	if given == "" && surname == "" {
+		return name.plainText()
+	}
+	return strings.TrimSpace(given + " " + surname)
+}
+
+// jatsDate formats the preferred of an article's pub-date elements as
+// YYYY-MM-DD, YYYY-MM, or YYYY
+func jatsDate(dates []*xmlNode) string {
+	chosen := preferredPubDate(dates)
+	date := chosen.child("year").plainText()
+	for _, part := range []string{"month", "day"} {
+		value := chosen.child(part).plainText()
+		if date == "" || value == "" {
+			break
+		}
+		if len(value) == 1 {
+			value = "0" + value
+		}
+		date += "-" + value
+	}
+	return date
+}
+
+// preferredPubDate returns the first pub-date of the most preferred type, or
+// the first of any type
+func preferredPubDate(dates []*xmlNode) *xmlNode {
+	for _, want := range jatsPubDateTypes {
+		for _, date := range dates {
+			if date.attr("pub-type") == want || date.attr("date-type") == want {
+				return date
+			}
+		}
+	}
+	if len(dates) == 0 {
+		return nil
+	}
+	return dates[0]
+}
+
+// jatsReference reads a reference from a ref-list entry: the text of a mixed
+// citation as given, or an element citation's fields formatted, with the DOI
+// of its pub-id
+func jatsReference(ref *xmlNode) (models.Reference, bool) {
+	var citation *xmlNode
+	for _, name := range []string{"mixed-citation", "element-citation", "citation", "nlm-citation"} {
+		if citation = ref.child(name); citation != nil {
+			break
+		}
+	}
+	if citation == nil {
+		return models.Reference{}, false
+	}
+
+	var reference models.Reference
+	if citation.name == "element-citation" {
+		parts := referenceParts{
+			year:   citation.child("year").plainText(),
+			title:  firstNonEmpty(citation.child("article-title").plainText(), citation.child("chapter-title").plainText()),
+			source: citation.child("source").plainText(),
+			volume: citation.child("volume").plainText(),
+			issue:  citation.child("issue").plainText(),
+			pages:  joinPageRange(citation.child("fpage").plainText(), citation.child("lpage").plainText()),
+		}
+		for _, name := range citation.findAll("name") {
+			parts.authors = append(parts.authors, jatsPersonName(name))
+		}
+		reference.ReferenceText = parts.text()
+	} else {
+		reference.ReferenceText = jatsFormat.inline(citation)
+	}
+	if reference.ReferenceText == "" {
+		return models.Reference{}, false
+	}
+	for _, id := range citation.findAll("pub-id") {
+		if id.attr("pub-id-type") == "doi" {
+			if doi, ok := citations.NormalizeDOI(id.plainText()); ok {
+				reference.DOI = doi
+			}
+		}
+	}
+	return reference, true
+}
+
+// jatsTable reads a table-wrap: its label, its caption, and its rows as markdown
+func jatsTable(wrap *xmlNode) models.Table {
+	table := models.Table{TableID: wrap.child("label").plainText()}
+	if table.TableID == "" {
+		table.TableID = wrap.attr("id")
+	}
+	caption := wrap.child("caption")
+	table.TableTitle = firstNonEmpty(jatsFormat.inline(caption.child("title")), jatsFormat.inline(caption))
+
+	var rows [][]string
+	for _, tr := range wrap.find("table").findAll("tr") {
+		var cells []string
+		for _, cell := range tr.children {
+			if cell.name == "th" || cell.name == "td" {
+				cells = append(cells, jatsFormat.inline(cell))
+			}
+		}
+		rows = append(rows, cells)
+	}
+	table.TableData = markdownTable(rows)
+	return table
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,99 @@
+package documents
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// readXMLFixture reads a fixture from testdata as a document of its detected type
+func readXMLFixture(t *testing.T, name string) models.DocumentData {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	return models.DocumentData{Data: data, Type: DetectDocumentType(data)}
+}
+
+func TestParseStructuredXML_JATS(t *testing.T) {
+	data := readXMLFixture(t, "jats/article.xml")
+	if data.Type != "jats" {
+		t.Fatalf("Expected the fixture detected as JATS, got %q", data.Type)
+	}
+	item, err := ParseStructuredXML(data)
+	if err != nil {
+		t.Fatalf("ParseStructuredXML failed: %v", err)
+	}
+
+	want := models.ItemMetadata{
+		Title:           "Letters in the Archive: A Case Study",
+		Authors:         []string{"Jane Smith", "Robert Jones"},
+		PublicationDate: "2020-05-01",
+		Publication:     "Journal of Memory Studies",
+		Publisher:       "Example Press",
+		Volume:          "12",
+		Issue:           "3",
+		Pages:           "45-67",
+		ISSN:            "1750-6980",
+		DOI:             "10.1000/jms.2020.7",
+		Abstract:        "We read letters kept in a village archive.\n\nThey record how memory is kept.",
+		Language:        "en",
+		ItemType:        "journalArticle",
+		MetadataSource:  MetadataSourceJATS,
+	}
+	if !reflect.DeepEqual(item.Metadata, want) {
+		t.Errorf("Unexpected metadata:\n got %+v\nwant %+v", item.Metadata, want)
+	}
+
+	wantPage := "# Letters in the Archive: A Case Study\n\n" +
+		"## Abstract\n\n" +
+		"We read letters kept in a village archive.\n\nThey record how memory is kept.\n\n" +
+		"## Introduction\n\n" +
+		"The archive is a place of **memory** (Derrida, 1995).1\n\n" +
+		"### Sources\n\n" +
+		"We drew on three collections:\n\n" +
+		"- parish letters\n- estate papers\n\n" +
+		"## Results\n\n" +
+		"Most letters were sent in winter (Table 1).\n\n" +
+		"> The past is a foreign country."
+	if !slices.Equal(item.Pages, []string{wantPage}) || !slices.Equal(item.PageNumbers, []string{"1"}) {
+		t.Errorf("Unexpected page:\n got %q\nwant %q", item.Pages, wantPage)
+	}
+
+	wantRefs := []models.Reference{
+		{ReferenceText: "Derrida, J. (1995). *Archive Fever*. University of Chicago Press."},
+		{ReferenceText: "C Steedman (2001). The space of memory. History of the Human Sciences 11(4), 65-83.", DOI: "10.1177/095269519801100405"},
+	}
+	if !reflect.DeepEqual(item.References, wantRefs) {
+		t.Errorf("Unexpected references:\n got %+v\nwant %+v", item.References, wantRefs)
+	}
+	if !reflect.DeepEqual(item.Endnotes, []models.Endnote{{Marker: "1", Text: "The archive was catalogued in 1987.", PageNumber: "1"}}) {
+		t.Errorf("Unexpected notes: %+v", item.Endnotes)
+	}
+
+	wantTable := models.Table{
+		TableID:    "Table 1",
+		TableTitle: "Letters by season",
+		TableData:  "| Season | Letters |\n| --- | --- |\n| Winter | 120 |\n| Summer | 45 |\n",
+	}
+	if !reflect.DeepEqual(item.Tables, []models.Table{wantTable}) {
+		t.Errorf("Unexpected tables: %+v", item.Tables)
+	}
+	if !reflect.DeepEqual(item.Images, []models.Image{{Caption: "Figure 1: Map of the village."}}) {
+		t.Errorf("Unexpected figures: %+v", item.Images)
+	}
+	if item.Basic || item.Provenance == nil || item.Provenance.ParserVersion != XMLParserVersion || item.Provenance.ParsedModel != "" {
+		t.Errorf("Expected a full parse with the XML parser's provenance, got basic=%v %+v", item.Basic, item.Provenance)
+	}
+
+	// The basic parser reads it the same way
+	basic, err := ParseDocumentBasic(data)
+	if err != nil || !reflect.DeepEqual(basic, item) {
+		t.Errorf("Expected the basic parser to parse the markup: %v", err)
+	}
+}
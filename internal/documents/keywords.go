@@ -0,0 +1,31 @@
+package documents
+
+import (
+	"slices"
+	"strings"
+)
+
+// MergeKeywords returns the keywords of a followed by those of b that a lacks,
+// ignoring case. Each keyword is trimmed of whitespace and trailing
+// punctuation, and empty ones are dropped. A keyword list the model returned
+// as one string ("ethics; trust; AI") is split at its semicolons.
+func MergeKeywords(a, b []string) []string {
+	var merged []string
+	for _, value := range slices.Concat(a, b) {
+		for _, keyword := range strings.Split(value, ";") {
+			keyword = strings.TrimRight(strings.TrimSpace(keyword), ".,;")
+			if keyword == "" || HasKeyword(merged, keyword) {
+				continue
+			}
+			merged = append(merged, keyword)
+		}
+	}
+	return merged
+}
+
+// HasKeyword reports whether keywords include keyword, ignoring case and
+// surrounding whitespace
+func HasKeyword(keywords []string, keyword string) bool {
+	keyword = strings.TrimSpace(keyword)
+	return slices.ContainsFunc(keywords, func(k string) bool { return strings.EqualFold(strings.TrimSpace(k), keyword) })
+}
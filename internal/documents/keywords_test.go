@@ -0,0 +1,39 @@
+package documents
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeKeywords(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     []string
+		expected []string
+	}{
+		{"both empty", nil, nil, nil},
+		{"first only", []string{"Trust", "AI ethics"}, nil, []string{"Trust", "AI ethics"}},
+		{"second only", nil, []string{"Trust"}, []string{"Trust"}},
+		{"union keeps first spelling", []string{"AI Ethics", "trust"}, []string{"ai ethics", "Trust", "Governance"}, []string{"AI Ethics", "trust", "Governance"}},
+		{"duplicates within a list", []string{"trust", "Trust", " trust "}, nil, []string{"trust"}},
+		{"trimmed and empty dropped", []string{" survey methods. ", "", "  "}, []string{"Survey methods"}, []string{"survey methods"}},
+		{"semicolon separated", []string{"ethics; trust;AI"}, []string{"Trust"}, []string{"ethics", "trust", "AI"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MergeKeywords(tt.a, tt.b); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("MergeKeywords(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHasKeyword(t *testing.T) {
+	keywords := []string{"AI Ethics", "trust"}
+	if !HasKeyword(keywords, " ai ethics") {
+		t.Error("Expected a keyword to match ignoring case and whitespace")
+	}
+	if HasKeyword(keywords, "ethics") {
+		t.Error("Expected a partial keyword not to match")
+	}
+}
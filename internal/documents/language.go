@@ -0,0 +1,46 @@
+package documents
+
+import "strings"
+
+// languageNames maps English and native language names, and ISO 639-2 codes,
+// to ISO 639-1 codes for the languages most common in academic sources
+var languageNames = map[string]string{
+	"english": "en", "eng": "en",
+	"german": "de", "deutsch": "de", "ger": "de", "deu": "de",
+	"french": "fr", "français": "fr", "francais": "fr", "fre": "fr", "fra": "fr",
+	"spanish": "es", "español": "es", "espanol": "es", "spa": "es",
+	"italian": "it", "italiano": "it", "ita": "it",
+	"portuguese": "pt", "português": "pt", "portugues": "pt", "por": "pt",
+	"dutch": "nl", "nederlands": "nl", "dut": "nl", "nld": "nl",
+	"latin": "la", "lat": "la",
+	"greek": "el", "gre": "el", "ell": "el",
+	"russian": "ru", "rus": "ru",
+	"polish": "pl", "polski": "pl", "pol": "pl",
+	"swedish": "sv", "svenska": "sv", "swe": "sv",
+	"danish": "da", "dansk": "da", "dan": "da",
+	"norwegian": "no", "norsk": "no", "nor": "no",
+	"chinese": "zh", "chi": "zh", "zho": "zh",
+	"japanese": "ja", "jpn": "ja",
+	"korean": "ko", "kor": "ko",
+	"arabic": "ar", "ara": "ar",
+	"hebrew": "he", "heb": "he",
+	"turkish": "tr", "tur": "tr",
+}
+
+// NormalizeLanguage returns the lowercase ISO 639-1 code for a language given
+// as a code or BCP 47 tag ("de", "de-AT", "en_US"), an ISO 639-2 code ("ger"),
+// or a name ("German", "Deutsch"). It returns "" if the value is not recognized.
+func NormalizeLanguage(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if code, ok := languageNames[value]; ok {
+		return code
+	}
+	primary, _, _ := strings.Cut(strings.ReplaceAll(value, "_", "-"), "-")
+	if code, ok := languageNames[primary]; ok {
+		return code
+	}
+	if len(primary) == 2 && primary[0] >= 'a' && primary[0] <= 'z' && primary[1] >= 'a' && primary[1] <= 'z' {
+		return primary
+	}
+	return ""
+}
@@ -0,0 +1,28 @@
+package documents
+
+import "testing"
+
+func TestNormalizeLanguage(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"de", "de"},
+		{" FR ", "fr"},
+		{"de-AT", "de"},
+		{"en_US", "en"},
+		{"German", "de"},
+		{"Deutsch", "de"},
+		{"français", "fr"},
+		{"ger", "de"},
+		{"fra", "fr"},
+		{"", ""},
+		{"Klingon", ""},
+		{"d3", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeLanguage(tt.value); got != tt.expected {
+			t.Errorf("NormalizeLanguage(%q) = %q, want %q", tt.value, got, tt.expected)
+		}
+	}
+}
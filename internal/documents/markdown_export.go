@@ -0,0 +1,174 @@
+package documents
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// ExportMarkdown renders a parsed document as a single Markdown file: YAML front
+// matter with the metadata, the page contents separated by page-break comments
+// holding the source page numbers, each table after the first page that mentions
+// it, footnotes and endnotes as Markdown footnotes, and the references. docID
+// identifies the document in the front matter and prefixes note anchors when the
+// document has no citekey. item is not modified.
+func ExportMarkdown(docID string, item *models.ParsedItem) string {
+	// Link notes on a copy so documents stored before linking existed export the same way
+	linked := *item
+	linked.Pages = append([]string(nil), item.Pages...)
+	linked.Footnotes = append([]models.Footnote(nil), item.Footnotes...)
+	key := item.Metadata.Citekey
+	if key == "" {
+		key = docID
+	}
+	LinkNotes(&linked, key)
+
+	var b strings.Builder
+	writeFrontMatter(&b, docID, &item.Metadata)
+
+	// Place each table after the first page that refers to it by ID
+	tablesAfterPage := make(map[int][]models.Table)
+	var unplacedTables []models.Table
+	for _, table := range item.Tables {
+		page := -1
+		if id := strings.ToLower(strings.TrimSpace(table.TableID)); id != "" {
+			for i, content := range linked.Pages {
+				if strings.Contains(strings.ToLower(content), id) {
+					page = i
+					break
+				}
+			}
+		}
+		if page >= 0 {
+			tablesAfterPage[page] = append(tablesAfterPage[page], table)
+		} else {
+			unplacedTables = append(unplacedTables, table)
+		}
+	}
+
+	paginated := len(item.PageNumbers) > 0 && item.PageNumbers[0] != ""
+	for i, content := range linked.Pages {
+		if paginated {
+			fmt.Fprintf(&b, "<!-- page %s -->\n\n", pageLabel(item.PageNumbers, i))
+		}
+		if content = strings.TrimSpace(content); content != "" {
+			b.WriteString(content)
+			b.WriteString("\n\n")
+		}
+		for _, table := range tablesAfterPage[i] {
+			writeTable(&b, table)
+		}
+	}
+
+	if len(unplacedTables) > 0 {
+		b.WriteString("## Tables\n\n")
+		for _, table := range unplacedTables {
+			writeTable(&b, table)
+		}
+	}
+
+	writeNotes(&b, &linked, key)
+
+	if len(item.References) > 0 {
+		b.WriteString("## References\n\n")
+		for _, ref := range item.References {
+			fmt.Fprintf(&b, "- %s\n", strings.TrimSpace(ref.ReferenceText))
+		}
+		b.WriteString("\n")
+	}
+
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+// writeFrontMatter writes the document's metadata as YAML front matter. Strings
+// are written as double-quoted scalars, which YAML reads with Go's escapes.
+func writeFrontMatter(b *strings.Builder, docID string, metadata *models.ItemMetadata) {
+	b.WriteString("---\n")
+	field := func(name, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			fmt.Fprintf(b, "%s: %s\n", name, strconv.Quote(value))
+		}
+	}
+	field("title", metadata.Title)
+	if len(metadata.Authors) > 0 {
+		b.WriteString("author:\n")
+		for _, author := range metadata.Authors {
+			fmt.Fprintf(b, "  - %s\n", strconv.Quote(author))
+		}
+	}
+	field("date", metadata.PublicationDate)
+	field("citekey", metadata.Citekey)
+	field("document_id", docID)
+	field("item_type", metadata.ItemType)
+	field("publication", metadata.Publication)
+	field("publisher", metadata.Publisher)
+	field("volume", metadata.Volume)
+	field("issue", metadata.Issue)
+	field("pages", metadata.Pages)
+	field("doi", metadata.DOI)
+	field("isbn", metadata.ISBN)
+	field("issn", metadata.ISSN)
+	field("url", metadata.URL)
+	field("abstract", metadata.Abstract)
+	b.WriteString("---\n\n")
+}
+
+// writeTable writes a table with its ID and title as a bold caption
+func writeTable(b *strings.Builder, table models.Table) {
+	caption := strings.TrimSpace(strings.Join([]string{table.TableID, table.TableTitle}, ": "))
+	caption = strings.Trim(caption, ": ")
+	if caption != "" {
+		fmt.Fprintf(b, "**%s**\n\n", caption)
+	}
+	if data := strings.TrimSpace(table.TableData); data != "" {
+		b.WriteString(data)
+		b.WriteString("\n\n")
+	}
+}
+
+// writeNotes writes the definitions of notes linked to the text as Markdown
+// footnotes, and lists notes whose markers were not found under a Notes heading
+func writeNotes(b *strings.Builder, item *models.ParsedItem, key string) {
+	anchored := make(map[string]bool)
+	for _, ref := range NoteOrder(item.Pages) {
+		anchored[ref.Anchor] = true
+	}
+
+	var definitions, unlinked []string
+	note := func(kind string, index int, marker, text string) {
+		text = strings.Join(strings.Fields(text), " ")
+		anchor := NoteAnchor(key, kind, index)
+		if anchored[anchor] {
+			definitions = append(definitions, fmt.Sprintf("%s: %s", anchor, text))
+		} else {
+			unlinked = append(unlinked, fmt.Sprintf("- [%s] %s", NormalizeNoteMarker(marker), text))
+		}
+	}
+	for i, footnote := range item.Footnotes {
+		note(NoteKindFootnote, i, footnote.Marker, footnote.Text)
+	}
+	for i, endnote := range item.Endnotes {
+		note(NoteKindEndnote, i, endnote.Marker, endnote.Text)
+	}
+
+	if len(unlinked) > 0 {
+		b.WriteString("## Notes\n\n")
+		b.WriteString(strings.Join(unlinked, "\n"))
+		b.WriteString("\n\n")
+	}
+	if len(definitions) > 0 {
+		b.WriteString(strings.Join(definitions, "\n"))
+		b.WriteString("\n\n")
+	}
+}
+
+// pageLabel returns the source page number of the page at index, falling back to
+// its sequential number
+func pageLabel(pageNumbers []string, index int) string {
+	if index < len(pageNumbers) && pageNumbers[index] != "" {
+		return pageNumbers[index]
+	}
+	return strconv.Itoa(index + 1)
+}
@@ -0,0 +1,124 @@
+package documents
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func exportTestItem() *models.ParsedItem {
+	return &models.ParsedItem{
+		Metadata: models.ItemMetadata{
+			Title:           `Memory and the "Archive"`,
+			Authors:         []string{"Smith, Jane", "Doe, John"},
+			PublicationDate: "2020",
+			Publication:     "Journal of Memory Studies",
+			DOI:             "10.1000/jms.2020.1",
+			Citekey:         "smith2020",
+		},
+		Pages: []string{
+			"# Memory and the Archive\n\nMemory is reconstructive.[1]",
+			"As Table 1 shows, archives are partial.[*]",
+			"## References",
+		},
+		PageNumbers: []string{"125", "126", "127"},
+		Tables: []models.Table{
+			{TableID: "Table 1", TableTitle: "Archive holdings", TableData: "| Year | Items |\n|---|---|\n| 1900 | 12 |"},
+			{TableID: "Table 9", TableTitle: "Never mentioned", TableData: "| A |\n|---|\n| 1 |"},
+		},
+		Footnotes: []models.Footnote{
+			{Marker: "1", Text: "See Bartlett (1932).", PageNumber: "125"},
+			{Marker: "*", Text: "Archives are\nselective.", PageNumber: "126"},
+			{Marker: "4", Text: "A note without a marker.", PageNumber: "127"},
+		},
+		References: []models.Reference{
+			{ReferenceText: "Bartlett, F. C. (1932). Remembering. Cambridge University Press."},
+		},
+	}
+}
+
+func TestExportMarkdown(t *testing.T) {
+	item := exportTestItem()
+	original := append([]string(nil), item.Pages...)
+
+	output := ExportMarkdown("doc-1", item)
+
+	frontMatter, body, ok := strings.Cut(strings.TrimPrefix(output, "---\n"), "\n---\n")
+	if !strings.HasPrefix(output, "---\n") || !ok {
+		t.Fatalf("Expected YAML front matter delimited by ---, got:\n%s", output)
+	}
+	for _, want := range []string{
+		`title: "Memory and the \"Archive\""`,
+		"author:\n  - \"Smith, Jane\"\n  - \"Doe, John\"",
+		`date: "2020"`,
+		`citekey: "smith2020"`,
+		`document_id: "doc-1"`,
+		`publication: "Journal of Memory Studies"`,
+		`doi: "10.1000/jms.2020.1"`,
+	} {
+		if !strings.Contains(frontMatter, want) {
+			t.Errorf("Expected front matter to contain %q, got:\n%s", want, frontMatter)
+		}
+	}
+	if strings.Contains(frontMatter, "publisher:") {
+		t.Errorf("Expected empty fields to be omitted, got:\n%s", frontMatter)
+	}
+
+	// Page-break markers appear in page order with the source page numbers
+	last := -1
+	for _, marker := range []string{"<!-- page 125 -->", "<!-- page 126 -->", "<!-- page 127 -->"} {
+		index := strings.Index(body, marker)
+		if index <= last {
+			t.Fatalf("Expected %q after the previous page marker, got:\n%s", marker, body)
+		}
+		last = index
+	}
+
+	for _, want := range []string{
+		"Memory is reconstructive.[^smith2020-fn1]",
+		"archives are partial.[^smith2020-fn2]",
+		"[^smith2020-fn1]: See Bartlett (1932).",
+		"[^smith2020-fn2]: Archives are selective.",
+		"## Notes\n\n- [4] A note without a marker.",
+		"## References\n\n- Bartlett, F. C. (1932). Remembering. Cambridge University Press.",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected body to contain %q, got:\n%s", want, body)
+		}
+	}
+
+	// A table follows the page that mentions it; others go in a Tables section
+	table := strings.Index(body, "**Table 1: Archive holdings**\n\n| Year | Items |")
+	if table < strings.Index(body, "<!-- page 126 -->") || table > strings.Index(body, "<!-- page 127 -->") {
+		t.Errorf("Expected Table 1 after page 126, got:\n%s", body)
+	}
+	if !strings.Contains(body, "## Tables\n\n**Table 9: Never mentioned**") {
+		t.Errorf("Expected unmentioned table in a Tables section, got:\n%s", body)
+	}
+
+	for i := range original {
+		if item.Pages[i] != original[i] {
+			t.Errorf("ExportMarkdown should not modify the item, page %d is now %q", i, item.Pages[i])
+		}
+	}
+}
+
+func TestExportMarkdown_NonPaginated(t *testing.T) {
+	item := &models.ParsedItem{
+		Metadata:    models.ItemMetadata{Title: "A Web Article"},
+		Pages:       []string{"Body text."},
+		PageNumbers: []string{""},
+	}
+
+	output := ExportMarkdown("url_abc", item)
+	if strings.Contains(output, "<!-- page") {
+		t.Errorf("Expected no page-break markers for a document without pages, got:\n%s", output)
+	}
+	if !strings.HasSuffix(output, "Body text.\n") {
+		t.Errorf("Expected content to follow the front matter, got:\n%s", output)
+	}
+	if strings.Contains(output, "citekey:") {
+		t.Errorf("Expected no citekey field without a citekey, got:\n%s", output)
+	}
+}
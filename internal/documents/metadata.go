@@ -2,9 +2,17 @@ package documents
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
 	"strings"
+	"time"
+	"unicode"
 
+	"github.com/Epistemic-Technology/academic-mcp/internal/citations"
 	"github.com/Epistemic-Technology/academic-mcp/models"
 	"github.com/Epistemic-Technology/zotero/zotero"
 )
@@ -12,22 +20,22 @@ import (
 // FetchZoteroMetadata retrieves metadata for a Zotero item (attachment or parent item).
 // If the zoteroID is an attachment, it fetches the parent item's metadata.
 // Returns nil if the item is not found or has no useful metadata.
-func FetchZoteroMetadata(ctx context.Context, zoteroID string, apiKey string, libraryID string) (*models.ItemMetadata, error) {
-	if zoteroID == "" || apiKey == "" || libraryID == "" {
-		return nil, fmt.Errorf("zoteroID, apiKey, and libraryID are required")
+func FetchZoteroMetadata(ctx context.Context, zoteroID string, apiKey string, library models.ZoteroLibrary) (*models.ItemMetadata, error) {
+	if zoteroID == "" || apiKey == "" || library.ID == "" {
+		return nil, models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("zoteroID, apiKey, and libraryID are required"))
 	}
 
-	client := zotero.NewClient(libraryID, zotero.LibraryTypeUser, zotero.WithAPIKey(apiKey))
+	client := NewZoteroClient(library, apiKey)
 
 	// Fetch the item
-	item, err := client.Item(ctx, zoteroID, nil)
+	item, err := GetZoteroItem(ctx, client, zoteroID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch Zotero item %s: %w", zoteroID, err)
 	}
 
 	// If this is an attachment, fetch the parent item instead
 	if item.Data.ItemType == "attachment" && item.Data.ParentItem != "" {
-		parentItem, err := client.Item(ctx, item.Data.ParentItem, nil)
+		parentItem, err := GetZoteroItem(ctx, client, item.Data.ParentItem)
 		if err != nil {
 			return nil, fmt.Errorf("failed to fetch parent item %s: %w", item.Data.ParentItem, err)
 		}
@@ -46,6 +54,103 @@ func FetchZoteroMetadata(ctx context.Context, zoteroID string, apiKey string, li
 	return metadata, nil
 }
 
+// Retries of a Zotero metadata fetch that failed for a reason that may pass
+const (
+	zoteroMetadataAttempts   = 3
+	zoteroMetadataRetryDelay = 200 * time.Millisecond // Doubled after each attempt
+)
+
+// FetchZoteroMetadataWithRetry is FetchZoteroMetadata, tried again with backoff,
+// up to zoteroMetadataAttempts times in all, while it fails for a reason that
+// may pass: the API could not be reached, or answered with a rate limit or
+// server error. An item that does not exist or a rejected request fails at once.
+func FetchZoteroMetadataWithRetry(ctx context.Context, zoteroID string, apiKey string, library models.ZoteroLibrary) (*models.ItemMetadata, error) {
+	delay := zoteroMetadataRetryDelay
+	for attempt := 1; ; attempt++ {
+		metadata, err := FetchZoteroMetadata(ctx, zoteroID, apiKey, library)
+		if err == nil || !retryableZoteroError(ctx, err) {
+			return metadata, err
+		}
+		if attempt == zoteroMetadataAttempts {
+			return nil, fmt.Errorf("%w (after %d attempts)", err, attempt)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, models.WithErrorCode(models.ErrorCancelled, ctx.Err())
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// retryableZoteroError reports whether a Zotero request that failed with err
+// may succeed if made again
+func retryableZoteroError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var status *zoteroStatusError
+	if errors.As(err, &status) {
+		return status.status == http.StatusTooManyRequests || status.status >= http.StatusInternalServerError
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// ZoteroItemMetadata converts a Zotero item to ItemMetadata as
+// FetchZoteroMetadata does, without fetching it
+func ZoteroItemMetadata(item *zotero.Item) *models.ItemMetadata {
+	metadata := zoteroItemToMetadata(item)
+	metadata.MetadataSource = "zotero"
+	return metadata
+}
+
+// ZoteroItemIdentifiers returns the normalized DOIs and ISBN-13s of a Zotero
+// item, from its DOI and ISBN fields and from "DOI:" and "ISBN:" lines of its
+// Extra field, where Zotero keeps identifiers that its item type has no field
+// for. A book's ISBN field may list several ISBNs.
+func ZoteroItemIdentifiers(item *zotero.Item) (dois []string, isbns []string) {
+	var doiValues, isbnValues []string
+	if val, ok := item.Data.Extra["DOI"].(string); ok {
+		doiValues = append(doiValues, val)
+	}
+	if val, ok := item.Data.Extra["ISBN"].(string); ok {
+		isbnValues = append(isbnValues, val)
+	}
+	if extra, ok := item.Data.Extra["extra"].(string); ok {
+		for _, line := range strings.Split(extra, "\n") {
+			label, value, found := strings.Cut(line, ":")
+			if !found {
+				continue
+			}
+			switch strings.ToUpper(strings.TrimSpace(label)) {
+			case "DOI":
+				doiValues = append(doiValues, value)
+			case "ISBN":
+				isbnValues = append(isbnValues, value)
+			}
+		}
+	}
+
+	for _, value := range doiValues {
+		if doi, ok := citations.NormalizeDOI(value); ok && !slices.Contains(dois, doi) {
+			dois = append(dois, doi)
+		}
+	}
+	for _, value := range isbnValues {
+		candidates := []string{value}
+		if _, ok := citations.NormalizeISBN(value); !ok {
+			candidates = strings.FieldsFunc(value, func(r rune) bool { return unicode.IsSpace(r) || r == ',' || r == ';' })
+		}
+		for _, candidate := range candidates {
+			if isbn, ok := citations.NormalizeISBN(candidate); ok && !slices.Contains(isbns, isbn) {
+				isbns = append(isbns, isbn)
+			}
+		}
+	}
+	return dois, isbns
+}
+
 // zoteroItemToMetadata converts a Zotero Item to our ItemMetadata structure
 func zoteroItemToMetadata(item *zotero.Item) *models.ItemMetadata {
 	metadata := &models.ItemMetadata{
@@ -54,11 +159,13 @@ func zoteroItemToMetadata(item *zotero.Item) *models.ItemMetadata {
 		Abstract: item.Data.AbstractNote,
 	}
 
-	// Extract creator names (authors, editors, etc.)
+	// Extract creator names (authors, editors, etc.). A single-field name is
+	// usually an organization's.
 	for _, creator := range item.Data.Creators {
 		var name string
 		if creator.Name != "" {
 			name = creator.Name
+			metadata.CorporateAuthors = append(metadata.CorporateAuthors, name)
 		} else if creator.FirstName != "" || creator.LastName != "" {
 			name = strings.TrimSpace(creator.FirstName + " " + creator.LastName)
 		}
@@ -67,8 +174,8 @@ func zoteroItemToMetadata(item *zotero.Item) *models.ItemMetadata {
 		}
 	}
 
-	// Extract type-specific fields from Extra map
-	// The zotero library uses reflection to populate Extra with all additional fields
+	// Extract type-specific fields from Extra map, which GetZoteroItem
+	// populates with all of the item's fields
 	if item.Data.Extra != nil {
 		// Common bibliographic fields
 		if val, ok := item.Data.Extra["date"].(string); ok {
@@ -101,85 +208,323 @@ func zoteroItemToMetadata(item *zotero.Item) *models.ItemMetadata {
 		if val, ok := item.Data.Extra["url"].(string); ok {
 			metadata.URL = val
 		}
+		if val, ok := item.Data.Extra["language"].(string); ok {
+			metadata.Language = NormalizeLanguage(val)
+		}
+	}
+
+	for _, tag := range item.Data.Tags {
+		if name := strings.TrimSpace(tag.Tag); name != "" && !slices.Contains(metadata.Tags, name) {
+			metadata.Tags = append(metadata.Tags, name)
+		}
 	}
 
 	return metadata
 }
 
+// Metadata field sources recorded in ItemMetadata.FieldSources
+const (
+	FieldSourceExternal  = "external"
+	FieldSourceExtracted = "extracted"
+	FieldSourceManual    = "manual"
+)
+
+// metadataField is a bibliographic field that can be merged, compared, and set
+// manually. Authors are read and written as one string separated by semicolons.
+type metadataField struct {
+	name string
+	get  func(*models.ItemMetadata) string
+	set  func(*models.ItemMetadata, string)
+	// merged fields fall back to the extracted value and are checked for
+	// conflicts; the others are only known from external sources
+	merged bool
+	// equal reports whether two non-empty values agree
+	equal func(a, b string) bool
+}
+
+var metadataFields = []metadataField{
+	{"title", func(m *models.ItemMetadata) string { return m.Title }, func(m *models.ItemMetadata, v string) { m.Title = v }, true, sameText},
+	// Prefer external authors: LLM extraction can be unreliable
+	{"authors", func(m *models.ItemMetadata) string { return strings.Join(m.Authors, "; ") }, func(m *models.ItemMetadata, v string) { m.Authors = splitAuthors(v) }, true, sameAuthors},
+	{"publication_date", func(m *models.ItemMetadata) string { return m.PublicationDate }, func(m *models.ItemMetadata, v string) { m.PublicationDate = v }, true, sameDate},
+	{"publication", func(m *models.ItemMetadata) string { return m.Publication }, func(m *models.ItemMetadata, v string) { m.Publication = v }, true, sameText},
+	{"doi", func(m *models.ItemMetadata) string { return m.DOI }, func(m *models.ItemMetadata, v string) { m.DOI = v }, true, sameDOI},
+	{"abstract", func(m *models.ItemMetadata) string { return m.Abstract }, func(m *models.ItemMetadata, v string) { m.Abstract = v }, true, sameText},
+	// Only extracted from the document, but can be set manually
+	{models.StatementFunding, func(m *models.ItemMetadata) string { return m.FundingStatement }, func(m *models.ItemMetadata, v string) { m.FundingStatement = v }, true, sameText},
+	{models.StatementAcknowledgments, func(m *models.ItemMetadata) string { return m.Acknowledgments }, func(m *models.ItemMetadata, v string) { m.Acknowledgments = v }, true, sameText},
+	{models.StatementDataAvailability, func(m *models.ItemMetadata) string { return m.DataAvailability }, func(m *models.ItemMetadata, v string) { m.DataAvailability = v }, true, sameText},
+	// Prefer the language the source declares over the detected one
+	{"language", func(m *models.ItemMetadata) string { return m.Language }, func(m *models.ItemMetadata, v string) { m.Language = NormalizeLanguage(v) }, true, sameLanguage},
+	{"item_type", func(m *models.ItemMetadata) string { return m.ItemType }, func(m *models.ItemMetadata, v string) { m.ItemType = v }, false, nil},
+	{"publisher", func(m *models.ItemMetadata) string { return m.Publisher }, func(m *models.ItemMetadata, v string) { m.Publisher = v }, false, nil},
+	{"volume", func(m *models.ItemMetadata) string { return m.Volume }, func(m *models.ItemMetadata, v string) { m.Volume = v }, false, nil},
+	{"issue", func(m *models.ItemMetadata) string { return m.Issue }, func(m *models.ItemMetadata, v string) { m.Issue = v }, false, nil},
+	{"pages", func(m *models.ItemMetadata) string { return m.Pages }, func(m *models.ItemMetadata, v string) { m.Pages = v }, false, nil},
+	{"issn", func(m *models.ItemMetadata) string { return m.ISSN }, func(m *models.ItemMetadata, v string) { m.ISSN = v }, false, nil},
+	{"isbn", func(m *models.ItemMetadata) string { return m.ISBN }, func(m *models.ItemMetadata, v string) { m.ISBN = v }, false, nil},
+	{"url", func(m *models.ItemMetadata) string { return m.URL }, func(m *models.ItemMetadata, v string) { m.URL = v }, false, nil},
+}
+
+// MetadataFieldNames lists the fields that SetMetadataField accepts
+func MetadataFieldNames() []string {
+	names := make([]string, len(metadataFields))
+	for i, field := range metadataFields {
+		names[i] = field.name
+	}
+	return names
+}
+
 // MergeMetadata merges external metadata with extracted metadata.
 // External metadata takes priority for all fields.
 // Falls back to extracted metadata when external field is empty.
+// DOIs are put in canonical form, and one that is not a valid DOI is treated as
+// missing.
+//
+// The source of each non-empty field is recorded in FieldSources. Where both
+// sources have a value and they disagree, the external value is kept and the
+// extracted one is recorded in Conflicts for the user to review.
 func MergeMetadata(external *models.ItemMetadata, extracted *models.ItemMetadata) *models.ItemMetadata {
 	if external == nil && extracted == nil {
 		return &models.ItemMetadata{MetadataSource: "none"}
 	}
+	external, extracted = withNormalizedDOI(external), withNormalizedDOI(extracted)
 	if external == nil {
 		result := *extracted
 		result.MetadataSource = "extracted"
+		result.FieldSources = fieldSources(&result, FieldSourceExtracted)
 		return &result
 	}
 	if extracted == nil {
 		result := *external
 		result.MetadataSource = "external"
+		result.FieldSources = fieldSources(&result, FieldSourceExternal)
 		return &result
 	}
 
 	// Merge with external taking priority
 	merged := &models.ItemMetadata{
 		MetadataSource: "merged",
+		FieldSources:   make(map[string]string),
 	}
-
-	// Title: prefer external
-	if external.Title != "" {
-		merged.Title = external.Title
-	} else {
-		merged.Title = extracted.Title
+	for _, field := range metadataFields {
+		externalValue := field.get(external)
+		extractedValue := field.get(extracted)
+		switch {
+		case externalValue != "":
+			field.set(merged, externalValue)
+			merged.FieldSources[field.name] = FieldSourceExternal
+			if field.merged && extractedValue != "" && !field.equal(externalValue, extractedValue) {
+				merged.Conflicts = append(merged.Conflicts, models.MetadataConflict{
+					Field:          field.name,
+					ExternalValue:  externalValue,
+					ExtractedValue: extractedValue,
+				})
+			}
+		case field.merged && extractedValue != "":
+			field.set(merged, extractedValue)
+			merged.FieldSources[field.name] = FieldSourceExtracted
+		}
 	}
-
-	// Authors: prefer external (LLM extraction can be unreliable)
+	// Keep the author lists as given rather than as re-split strings. Only
+	// external sources say which authors are organizations.
 	if len(external.Authors) > 0 {
 		merged.Authors = external.Authors
+		merged.CorporateAuthors = external.CorporateAuthors
 	} else {
 		merged.Authors = extracted.Authors
 	}
+	// Only external sources have tags (Zotero's, or the arXiv category)
+	merged.Tags = external.Tags
+	merged.Keywords = MergeKeywords(external.Keywords, extracted.Keywords)
+
+	return merged
+}
 
-	// Publication date: prefer external
-	if external.PublicationDate != "" {
-		merged.PublicationDate = external.PublicationDate
+// RemergeMetadata merges fresh external metadata into a stored document's
+// metadata as MergeMetadata did when the document was parsed, without parsing
+// it again. What was extracted from the document is recovered from the stored
+// field sources and conflicts: the fields marked "extracted", and the extracted
+// side of each conflict. Fields set manually keep their values, and so do the
+// citekey and keywords. Metadata stored before field sources were recorded is
+// taken as extracted as a whole.
+func RemergeMetadata(external *models.ItemMetadata, stored *models.ItemMetadata) *models.ItemMetadata {
+	extracted := &models.ItemMetadata{}
+	if len(stored.FieldSources) == 0 {
+		*extracted = *stored
+		extracted.Tags, extracted.FieldSources, extracted.Conflicts = nil, nil, nil
 	} else {
-		merged.PublicationDate = extracted.PublicationDate
+		for _, field := range metadataFields {
+			if stored.FieldSources[field.name] == FieldSourceExtracted {
+				field.set(extracted, field.get(stored))
+			}
+		}
+		for _, conflict := range stored.Conflicts {
+			for _, field := range metadataFields {
+				if field.name == conflict.Field {
+					field.set(extracted, conflict.ExtractedValue)
+				}
+			}
+		}
+	}
+	if fieldSources(extracted, FieldSourceExtracted) == nil {
+		extracted = nil
 	}
 
-	// Publication/journal: prefer external
-	if external.Publication != "" {
-		merged.Publication = external.Publication
-	} else {
-		merged.Publication = extracted.Publication
+	merged := MergeMetadata(external, extracted)
+	for _, field := range metadataFields {
+		if stored.FieldSources[field.name] == FieldSourceManual {
+			// The value was checked when it was set
+			_ = SetMetadataField(merged, field.name, field.get(stored))
+		}
 	}
+	// Keywords have no field source; the stored ones are kept
+	merged.Keywords = MergeKeywords(merged.Keywords, stored.Keywords)
+	merged.Citekey = stored.Citekey
+	return merged
+}
 
-	// DOI: prefer external
-	if external.DOI != "" {
-		merged.DOI = external.DOI
-	} else {
-		merged.DOI = extracted.DOI
+// ChangedMetadataFields returns the names of the fields whose values differ
+// between two versions of a document's metadata, in MetadataFieldNames order
+func ChangedMetadataFields(before, after *models.ItemMetadata) []string {
+	var changed []string
+	for _, field := range metadataFields {
+		if field.get(before) != field.get(after) {
+			changed = append(changed, field.name)
+		}
 	}
+	return changed
+}
 
-	// Abstract: prefer external
-	if external.Abstract != "" {
-		merged.Abstract = external.Abstract
-	} else {
-		merged.Abstract = extracted.Abstract
+// withNormalizedDOI returns a copy of metadata with its DOI in canonical form,
+// or cleared if it is not valid
+func withNormalizedDOI(metadata *models.ItemMetadata) *models.ItemMetadata {
+	if metadata == nil {
+		return nil
 	}
+	normalized := *metadata
+	normalized.DOI, _ = citations.NormalizeDOI(metadata.DOI)
+	return &normalized
+}
 
-	// Additional fields (typically only from external sources)
-	merged.ItemType = external.ItemType
-	merged.Publisher = external.Publisher
-	merged.Volume = external.Volume
-	merged.Issue = external.Issue
-	merged.Pages = external.Pages
-	merged.ISSN = external.ISSN
-	merged.ISBN = external.ISBN
-	merged.URL = external.URL
+// SetMetadataField overrides a field with a value set by the user, which wins
+// over both external and extracted metadata. The field is marked "manual" and
+// any conflict recorded for it is resolved. Authors are separated by semicolons.
+func SetMetadataField(metadata *models.ItemMetadata, name string, value string) error {
+	for _, field := range metadataFields {
+		if field.name != name {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if field.name == "language" && value != "" && NormalizeLanguage(value) == "" {
+			return fmt.Errorf("unrecognized language %q (use an ISO 639-1 code such as \"en\")", value)
+		}
+		if field.name == "doi" && value != "" {
+			doi, ok := citations.NormalizeDOI(value)
+			if !ok {
+				return fmt.Errorf("invalid DOI %q (expected the form 10.1234/suffix)", value)
+			}
+			value = doi
+		}
+		field.set(metadata, value)
+		if metadata.FieldSources == nil {
+			metadata.FieldSources = make(map[string]string)
+		}
+		metadata.FieldSources[name] = FieldSourceManual
 
-	return merged
+		conflicts := metadata.Conflicts[:0]
+		for _, conflict := range metadata.Conflicts {
+			if conflict.Field != name {
+				conflicts = append(conflicts, conflict)
+			}
+		}
+		metadata.Conflicts = conflicts
+		if len(metadata.Conflicts) == 0 {
+			metadata.Conflicts = nil
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown metadata field %q (expected one of: %s)", name, strings.Join(MetadataFieldNames(), ", "))
+}
+
+// fieldSources marks every non-empty field of metadata as coming from source
+func fieldSources(metadata *models.ItemMetadata, source string) map[string]string {
+	sources := make(map[string]string)
+	for _, field := range metadataFields {
+		if field.get(metadata) != "" {
+			sources[field.name] = source
+		}
+	}
+	if len(sources) == 0 {
+		return nil
+	}
+	return sources
+}
+
+// splitAuthors splits a semicolon-separated list of author names
+func splitAuthors(value string) []string {
+	var authors []string
+	for _, author := range strings.Split(value, ";") {
+		if author = strings.TrimSpace(author); author != "" {
+			authors = append(authors, author)
+		}
+	}
+	return authors
+}
+
+// comparableText lowercases text and keeps only its letters and digits, so
+// differences in case, spacing, and punctuation are not reported as conflicts
+func comparableText(value string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(value) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func sameText(a, b string) bool {
+	return comparableText(a) == comparableText(b)
+}
+
+// sameDate treats a date as agreeing with a more precise form of itself, so
+// "2020" and "2020-05-15" agree but "2019" and "2020-05-15" do not
+func sameDate(a, b string) bool {
+	a, b = comparableText(a), comparableText(b)
+	return strings.HasPrefix(a, b) || strings.HasPrefix(b, a)
+}
+
+func sameDOI(a, b string) bool {
+	normalizedA, okA := citations.NormalizeDOI(a)
+	normalizedB, okB := citations.NormalizeDOI(b)
+	if !okA || !okB {
+		return strings.EqualFold(cleanDOI(a), cleanDOI(b))
+	}
+	return normalizedA == normalizedB
+}
+
+func sameLanguage(a, b string) bool {
+	return NormalizeLanguage(a) == NormalizeLanguage(b)
+}
+
+// sameAuthors compares author lists by the letters of each name regardless of
+// name order, so "Smith, John" and "John Smith" agree
+func sameAuthors(a, b string) bool {
+	aAuthors, bAuthors := splitAuthors(a), splitAuthors(b)
+	if len(aAuthors) != len(bAuthors) {
+		return false
+	}
+	for i := range aAuthors {
+		if sortedLetters(aAuthors[i]) != sortedLetters(bAuthors[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedLetters(name string) string {
+	letters := []rune(comparableText(name))
+	sort.Slice(letters, func(i, j int) bool { return letters[i] < letters[j] })
+	return string(letters)
 }
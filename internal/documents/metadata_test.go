@@ -0,0 +1,299 @@
+package documents
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+	"github.com/Epistemic-Technology/zotero/zotero"
+)
+
+func TestMergeMetadata(t *testing.T) {
+	tests := []struct {
+		name              string
+		external          *models.ItemMetadata
+		extracted         *models.ItemMetadata
+		expectedTitle     string
+		expectedSource    string
+		expectedSources   map[string]string
+		expectedConflicts []models.MetadataConflict
+	}{
+		{
+			name:           "Neither source",
+			expectedSource: "none",
+		},
+		{
+			name:            "Extracted only",
+			extracted:       &models.ItemMetadata{Title: "Paper", DOI: "10.1000/x"},
+			expectedTitle:   "Paper",
+			expectedSource:  "extracted",
+			expectedSources: map[string]string{"title": "extracted", "doi": "extracted"},
+		},
+		{
+			name:            "External only",
+			external:        &models.ItemMetadata{Title: "Paper", Volume: "3"},
+			expectedTitle:   "Paper",
+			expectedSource:  "external",
+			expectedSources: map[string]string{"title": "external", "volume": "external"},
+		},
+		{
+			name:            "Complementary fields",
+			external:        &models.ItemMetadata{Title: "Paper", Publisher: "Press"},
+			extracted:       &models.ItemMetadata{DOI: "10.1000/x", Abstract: "About things."},
+			expectedTitle:   "Paper",
+			expectedSource:  "merged",
+			expectedSources: map[string]string{"title": "external", "publisher": "external", "doi": "extracted", "abstract": "extracted"},
+		},
+		{
+			name:            "Equivalent values are not conflicts",
+			external:        &models.ItemMetadata{Title: "The Paper: A Study", Authors: []string{"Smith, John"}, PublicationDate: "2020", DOI: "10.1000/ABC", Language: "en"},
+			extracted:       &models.ItemMetadata{Title: "the paper - a study", Authors: []string{"John Smith"}, PublicationDate: "2020-05-15", DOI: "https://doi.org/10.1000/abc", Language: "English"},
+			expectedTitle:   "The Paper: A Study",
+			expectedSource:  "merged",
+			expectedSources: map[string]string{"title": "external", "authors": "external", "publication_date": "external", "doi": "external", "language": "external"},
+		},
+		{
+			name:            "Disagreements keep the external value",
+			external:        &models.ItemMetadata{Title: "Wrong Title", PublicationDate: "2019", Authors: []string{"Smith, John"}},
+			extracted:       &models.ItemMetadata{Title: "Right Title", PublicationDate: "2020-01-01", Authors: []string{"John Smith", "Jane Doe"}},
+			expectedTitle:   "Wrong Title",
+			expectedSource:  "merged",
+			expectedSources: map[string]string{"title": "external", "authors": "external", "publication_date": "external"},
+			expectedConflicts: []models.MetadataConflict{
+				{Field: "title", ExternalValue: "Wrong Title", ExtractedValue: "Right Title"},
+				{Field: "authors", ExternalValue: "Smith, John", ExtractedValue: "John Smith; Jane Doe"},
+				{Field: "publication_date", ExternalValue: "2019", ExtractedValue: "2020-01-01"},
+			},
+		},
+		{
+			name:            "Invalid DOIs are treated as missing",
+			external:        &models.ItemMetadata{Title: "Paper", DOI: "n/a"},
+			extracted:       &models.ItemMetadata{Title: "Paper", DOI: "DOI: 10.1000/ABC."},
+			expectedTitle:   "Paper",
+			expectedSource:  "merged",
+			expectedSources: map[string]string{"title": "external", "doi": "extracted"},
+		},
+		{
+			name:            "External-only fields are never conflicts",
+			external:        &models.ItemMetadata{Title: "Paper", Pages: "1-10"},
+			extracted:       &models.ItemMetadata{Title: "Paper", Pages: "2-11"},
+			expectedTitle:   "Paper",
+			expectedSource:  "merged",
+			expectedSources: map[string]string{"title": "external", "pages": "external"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged := MergeMetadata(tt.external, tt.extracted)
+			if merged.Title != tt.expectedTitle || merged.MetadataSource != tt.expectedSource {
+				t.Errorf("Expected title %q from %q, got %q from %q", tt.expectedTitle, tt.expectedSource, merged.Title, merged.MetadataSource)
+			}
+			if !reflect.DeepEqual(merged.FieldSources, tt.expectedSources) {
+				t.Errorf("Expected field sources %v, got %v", tt.expectedSources, merged.FieldSources)
+			}
+			if !reflect.DeepEqual(merged.Conflicts, tt.expectedConflicts) {
+				t.Errorf("Expected conflicts %+v, got %+v", tt.expectedConflicts, merged.Conflicts)
+			}
+		})
+	}
+}
+
+func TestMergeMetadata_KeepsAuthorLists(t *testing.T) {
+	external := &models.ItemMetadata{Authors: []string{"Smith, John", "Doe, Jane"}}
+	merged := MergeMetadata(external, &models.ItemMetadata{Title: "Paper"})
+	if !reflect.DeepEqual(merged.Authors, external.Authors) {
+		t.Errorf("Expected authors %v, got %v", external.Authors, merged.Authors)
+	}
+
+	extracted := &models.ItemMetadata{Authors: []string{"John Smith"}}
+	merged = MergeMetadata(&models.ItemMetadata{Title: "Paper"}, extracted)
+	if !reflect.DeepEqual(merged.Authors, extracted.Authors) || merged.FieldSources["authors"] != FieldSourceExtracted {
+		t.Errorf("Expected extracted authors %v, got %v (%v)", extracted.Authors, merged.Authors, merged.FieldSources)
+	}
+}
+
+func TestMergeMetadata_CorporateAuthors(t *testing.T) {
+	external := &models.ItemMetadata{Authors: []string{"Google DeepMind"}, CorporateAuthors: []string{"Google DeepMind"}}
+	merged := MergeMetadata(external, &models.ItemMetadata{Authors: []string{"Jane Smith"}})
+	if !reflect.DeepEqual(merged.CorporateAuthors, external.CorporateAuthors) {
+		t.Errorf("Expected corporate authors %v, got %v", external.CorporateAuthors, merged.CorporateAuthors)
+	}
+
+	merged = MergeMetadata(&models.ItemMetadata{Title: "Paper"}, &models.ItemMetadata{Authors: []string{"Jane Smith"}})
+	if merged.CorporateAuthors != nil {
+		t.Errorf("Expected no corporate authors with extracted authors, got %v", merged.CorporateAuthors)
+	}
+}
+
+func TestMergeMetadata_Keywords(t *testing.T) {
+	extracted := &models.ItemMetadata{Title: "Paper", Keywords: []string{"Trust", "survey methods"}}
+	merged := MergeMetadata(&models.ItemMetadata{Title: "Paper", Keywords: []string{"trust", "Nonresponse"}}, extracted)
+	if want := []string{"trust", "Nonresponse", "survey methods"}; !reflect.DeepEqual(merged.Keywords, want) {
+		t.Errorf("Expected keywords %q, got %q", want, merged.Keywords)
+	}
+
+	// Keywords have no field source, so a refresh keeps the stored ones even
+	// when no other field was extracted
+	stored := MergeMetadata(&models.ItemMetadata{Title: "Paper"}, &models.ItemMetadata{Keywords: extracted.Keywords})
+	refreshed := RemergeMetadata(&models.ItemMetadata{Title: "Paper", Volume: "3"}, stored)
+	if !reflect.DeepEqual(refreshed.Keywords, extracted.Keywords) {
+		t.Errorf("Expected the stored keywords kept, got %q", refreshed.Keywords)
+	}
+}
+
+func TestSetMetadataField(t *testing.T) {
+	merged := MergeMetadata(
+		&models.ItemMetadata{Title: "Wrong Title", PublicationDate: "2019"},
+		&models.ItemMetadata{Title: "Right Title", PublicationDate: "2020"},
+	)
+	if len(merged.Conflicts) != 2 {
+		t.Fatalf("Expected 2 conflicts, got %+v", merged.Conflicts)
+	}
+
+	if err := SetMetadataField(merged, "title", " Right Title "); err != nil {
+		t.Fatalf("SetMetadataField failed: %v", err)
+	}
+	if merged.Title != "Right Title" || merged.FieldSources["title"] != FieldSourceManual {
+		t.Errorf("Expected manual title, got %q (%v)", merged.Title, merged.FieldSources)
+	}
+	if len(merged.Conflicts) != 1 || merged.Conflicts[0].Field != "publication_date" {
+		t.Errorf("Expected only the date conflict to remain, got %+v", merged.Conflicts)
+	}
+
+	if err := SetMetadataField(merged, "publication_date", "2020"); err != nil {
+		t.Fatalf("SetMetadataField failed: %v", err)
+	}
+	if merged.Conflicts != nil {
+		t.Errorf("Expected all conflicts resolved, got %+v", merged.Conflicts)
+	}
+
+	if err := SetMetadataField(merged, "authors", "Smith, John; ; Doe, Jane"); err != nil {
+		t.Fatalf("SetMetadataField failed: %v", err)
+	}
+	if !reflect.DeepEqual(merged.Authors, []string{"Smith, John", "Doe, Jane"}) {
+		t.Errorf("Expected authors split on semicolons, got %v", merged.Authors)
+	}
+
+	if err := SetMetadataField(merged, "language", "German"); err != nil || merged.Language != "de" {
+		t.Errorf("Expected language normalized to de, got %q (%v)", merged.Language, err)
+	}
+
+	if err := SetMetadataField(merged, "doi", "https://doi.org/10.1000/ABC"); err != nil || merged.DOI != "10.1000/abc" {
+		t.Errorf("Expected DOI normalized to 10.1000/abc, got %q (%v)", merged.DOI, err)
+	}
+
+	metadata := &models.ItemMetadata{}
+	if err := SetMetadataField(metadata, "volume", "7"); err != nil || metadata.FieldSources["volume"] != FieldSourceManual {
+		t.Errorf("Expected field sources created for manual volume, got %v (%v)", metadata.FieldSources, err)
+	}
+
+	errorTests := []struct {
+		field string
+		value string
+		want  string
+	}{
+		{"citekey", "smith2020", "unknown metadata field"},
+		{"language", "Klingon", "unrecognized language"},
+		{"doi", "not a doi", "invalid DOI"},
+	}
+	for _, tt := range errorTests {
+		if err := SetMetadataField(metadata, tt.field, tt.value); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("SetMetadataField(%q, %q) error = %v, want %q", tt.field, tt.value, err, tt.want)
+		}
+	}
+}
+
+func TestRemergeMetadata(t *testing.T) {
+	// A document parsed while Zotero was unreachable has extracted metadata only
+	extractedOnly := MergeMetadata(nil, &models.ItemMetadata{Title: "Field Notes", PublicationDate: "2020", Abstract: "On marshes.", Citekey: "unknown2020"})
+	merged := RemergeMetadata(&models.ItemMetadata{Title: "Field Notes", Authors: []string{"Smith, Jane"}, PublicationDate: "2019", Volume: "4", Tags: []string{"wetlands"}}, extractedOnly)
+	if merged.MetadataSource != "merged" || !reflect.DeepEqual(merged.Authors, []string{"Smith, Jane"}) || merged.Volume != "4" || merged.Abstract != "On marshes." {
+		t.Errorf("Expected Zotero metadata merged with the extracted abstract, got %+v", merged)
+	}
+	if merged.Citekey != "unknown2020" || !reflect.DeepEqual(merged.Tags, []string{"wetlands"}) {
+		t.Errorf("Expected the citekey kept and the Zotero tags, got %q and %v", merged.Citekey, merged.Tags)
+	}
+	wantConflicts := []models.MetadataConflict{{Field: "publication_date", ExternalValue: "2019", ExtractedValue: "2020"}}
+	if !reflect.DeepEqual(merged.Conflicts, wantConflicts) {
+		t.Errorf("Expected %+v, got %+v", wantConflicts, merged.Conflicts)
+	}
+
+	// Refreshing again recovers the extracted values from the field sources and
+	// conflicts, and keeps manual fields
+	if err := SetMetadataField(merged, "volume", "5"); err != nil {
+		t.Fatalf("SetMetadataField failed: %v", err)
+	}
+	again := RemergeMetadata(&models.ItemMetadata{Title: "Field Notes", Authors: []string{"Smith, Jane"}, PublicationDate: "2020", Volume: "4"}, merged)
+	if again.Conflicts != nil || again.PublicationDate != "2020" || again.Abstract != "On marshes." || again.FieldSources["abstract"] != FieldSourceExtracted {
+		t.Errorf("Expected the date corrected in Zotero to resolve the conflict, got %+v", again)
+	}
+	if again.Volume != "5" || again.FieldSources["volume"] != FieldSourceManual {
+		t.Errorf("Expected the manual volume kept, got %q (%v)", again.Volume, again.FieldSources)
+	}
+
+	// Metadata stored before field sources were recorded is taken as extracted
+	legacy := &models.ItemMetadata{Title: "Old Title", DOI: "10.1000/old"}
+	refreshed := RemergeMetadata(&models.ItemMetadata{Title: "New Title"}, legacy)
+	if refreshed.Title != "New Title" || refreshed.DOI != "10.1000/old" || len(refreshed.Conflicts) != 1 {
+		t.Errorf("Expected the old metadata treated as extracted, got %+v", refreshed)
+	}
+
+	if changed := ChangedMetadataFields(extractedOnly, merged); !reflect.DeepEqual(changed, []string{"authors", "publication_date", "volume"}) {
+		t.Errorf("Expected authors, publication_date, and volume changed, got %v", changed)
+	}
+}
+
+func TestZoteroItemMetadata_Tags(t *testing.T) {
+	item := &zotero.Item{Data: zotero.ItemData{ItemType: "journalArticle", Tags: []zotero.Tag{{Tag: "to-read"}, {Tag: " archives ", Type: 1}, {Tag: "to-read"}, {Tag: ""}}}}
+	metadata := ZoteroItemMetadata(item)
+	if expected := []string{"to-read", "archives"}; !reflect.DeepEqual(metadata.Tags, expected) {
+		t.Errorf("Expected tags %v, got %v", expected, metadata.Tags)
+	}
+
+	merged := MergeMetadata(metadata, &models.ItemMetadata{Title: "Paper"})
+	if !reflect.DeepEqual(merged.Tags, metadata.Tags) {
+		t.Errorf("Expected the Zotero tags kept when merging, got %v", merged.Tags)
+	}
+}
+
+func TestZoteroItemIdentifiers(t *testing.T) {
+	tests := []struct {
+		name          string
+		extra         map[string]any
+		expectedDOIs  []string
+		expectedISBNs []string
+	}{
+		{
+			name:         "DOI field",
+			extra:        map[string]any{"DOI": "https://doi.org/10.1000/ABC"},
+			expectedDOIs: []string{"10.1000/abc"},
+		},
+		{
+			name:          "several ISBNs in the ISBN field",
+			extra:         map[string]any{"ISBN": "978-0-262-03384-8 0262033844"},
+			expectedISBNs: []string{"9780262033848"},
+		},
+		{
+			name:          "identifiers in the Extra field",
+			extra:         map[string]any{"extra": "Original date: 1990\nDOI: 10.1000/xyz\nISBN: 0-306-40615-2"},
+			expectedDOIs:  []string{"10.1000/xyz"},
+			expectedISBNs: []string{"9780306406157"},
+		},
+		{
+			name:  "invalid identifiers are ignored",
+			extra: map[string]any{"DOI": "not a doi", "ISBN": "12345"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := &zotero.Item{Data: zotero.ItemData{ItemType: "book", Extra: tt.extra}}
+			dois, isbns := ZoteroItemIdentifiers(item)
+			if !reflect.DeepEqual(dois, tt.expectedDOIs) || !reflect.DeepEqual(isbns, tt.expectedISBNs) {
+				t.Errorf("ZoteroItemIdentifiers() = %v, %v; expected %v, %v", dois, isbns, tt.expectedDOIs, tt.expectedISBNs)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package documents
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// Note kinds used in note anchors and by NoteOrder
+const (
+	NoteKindFootnote = "fn"
+	NoteKindEndnote  = "en"
+)
+
+// noteMarkerPattern matches an inline note marker such as [1], [*], or [iv]
+var noteMarkerPattern = regexp.MustCompile(`\[([^\[\]\s^]{1,8})\]`)
+
+// noteAnchorPattern matches a note anchor written by LinkNotes, capturing its
+// kind and 1-based note number
+var noteAnchorPattern = regexp.MustCompile(`\[\^[^\[\]\s]+-(fn|en)(\d+)\]`)
+
+// NoteAnchor returns the markdown footnote label linking the note of the given
+// kind and index (0-indexed) to its marker, e.g. "[^smith2020-fn1]"
+func NoteAnchor(key, kind string, index int) string {
+	return fmt.Sprintf("[^%s-%s%d]", key, kind, index+1)
+}
+
+// markerOccurrence is an inline note marker in a page
+type markerOccurrence struct {
+	page   int
+	start  int
+	end    int
+	marker string
+	anchor string // set once a note has claimed the occurrence
+}
+
+// LinkNotes rewrites the inline markers of item's footnotes and endnotes (e.g.,
+// "[1]") to stable anchors built from key (see NoteAnchor), and sets each
+// footnote's InTextPage to the sequential page (1-indexed) where its marker
+// occurs, or to "" if no marker is found. A footnote's marker is looked for on
+// the footnote's own page first and then on the pages next to it, so a marker
+// such as "*" reused on many pages links to the right occurrence. Endnote
+// markers are matched in order through the document. Anchors from an earlier
+// call are restored to markers first, so the call can be repeated after pages
+// or notes change. Returns the number of notes linked.
+func LinkNotes(item *models.ParsedItem, key string) int {
+	UnlinkNotes(item)
+
+	var occurrences []*markerOccurrence
+	pageOccurrences := make([][]*markerOccurrence, len(item.Pages))
+	for i, page := range item.Pages {
+		for _, loc := range noteMarkerPattern.FindAllStringSubmatchIndex(page, -1) {
+			start, end := loc[0], loc[1]
+			// Skip images, links, and link reference definitions
+			if start > 0 && page[start-1] == '!' || end < len(page) && strings.ContainsRune("([:", rune(page[end])) {
+				continue
+			}
+			occurrence := &markerOccurrence{page: i, start: start, end: end, marker: page[loc[2]:loc[3]]}
+			occurrences = append(occurrences, occurrence)
+			pageOccurrences[i] = append(pageOccurrences[i], occurrence)
+		}
+	}
+
+	pageIndex := make(map[string]int, len(item.PageNumbers))
+	for i, number := range item.PageNumbers {
+		if _, seen := pageIndex[number]; !seen && number != "" {
+			pageIndex[number] = i
+		}
+	}
+
+	claim := func(candidates []*markerOccurrence, marker, anchor string) *markerOccurrence {
+		for _, occurrence := range candidates {
+			if occurrence.anchor == "" && occurrence.marker == marker {
+				occurrence.anchor = anchor
+				return occurrence
+			}
+		}
+		return nil
+	}
+
+	linked := 0
+	next := 0 // position in occurrences after the last linked note, for notes without a known page
+	for i := range item.Footnotes {
+		footnote := &item.Footnotes[i]
+		marker := NormalizeNoteMarker(footnote.Marker)
+		anchor := NoteAnchor(key, NoteKindFootnote, i)
+
+		var found *markerOccurrence
+		if home, ok := pageIndex[footnote.PageNumber]; ok {
+			for _, page := range []int{home, home - 1, home + 1} {
+				if page >= 0 && page < len(pageOccurrences) {
+					if found = claim(pageOccurrences[page], marker, anchor); found != nil {
+						break
+					}
+				}
+			}
+		} else {
+			found = claim(occurrences[next:], marker, anchor)
+			if found == nil {
+				found = claim(occurrences[:next], marker, anchor)
+			}
+		}
+
+		footnote.InTextPage = ""
+		if found != nil {
+			footnote.InTextPage = strconv.Itoa(found.page + 1)
+			next = occurrenceAfter(occurrences, found)
+			linked++
+		}
+	}
+
+	next = 0
+	for i := range item.Endnotes {
+		marker := NormalizeNoteMarker(item.Endnotes[i].Marker)
+		anchor := NoteAnchor(key, NoteKindEndnote, i)
+		found := claim(occurrences[next:], marker, anchor)
+		if found == nil {
+			found = claim(occurrences[:next], marker, anchor)
+		}
+		if found != nil {
+			next = occurrenceAfter(occurrences, found)
+			linked++
+		}
+	}
+
+	for i, page := range item.Pages {
+		var b strings.Builder
+		last := 0
+		for _, occurrence := range pageOccurrences[i] {
+			if occurrence.anchor == "" {
+				continue
+			}
+			b.WriteString(page[last:occurrence.start])
+			b.WriteString(occurrence.anchor)
+			last = occurrence.end
+		}
+		if last > 0 {
+			b.WriteString(page[last:])
+			item.Pages[i] = b.String()
+		}
+	}
+	return linked
+}
+
+// UnlinkNotes restores note anchors written by LinkNotes to the markers of the
+// notes they refer to
+func UnlinkNotes(item *models.ParsedItem) {
+	for i, page := range item.Pages {
+		item.Pages[i] = noteAnchorPattern.ReplaceAllStringFunc(page, func(anchor string) string {
+			kind, index, ok := parseNoteAnchor(anchor)
+			if !ok {
+				return anchor
+			}
+			switch {
+			case kind == NoteKindFootnote && index < len(item.Footnotes):
+				return "[" + NormalizeNoteMarker(item.Footnotes[index].Marker) + "]"
+			case kind == NoteKindEndnote && index < len(item.Endnotes):
+				return "[" + NormalizeNoteMarker(item.Endnotes[index].Marker) + "]"
+			}
+			return anchor
+		})
+	}
+}
+
+// AssignEndnoteKeys gives each endnote a key that identifies it however often
+// its marker recurs: "ch3-n17" for the note marked 17 in the third chapter.
+// Chapters are found where the numbering restarts, so a numeric marker of 1, or
+// one lower than the last, begins the next chapter; other markers stay in the
+// chapter of the note before. An unmarked note is keyed by its place among the
+// chapter's unmarked notes ("ch3-u2"), and a key that is still taken gets a
+// count ("ch3-n17-2").
+func AssignEndnoteKeys(endnotes []models.Endnote) {
+	chapter, last, unmarked := 1, 0, 0
+	used := make(map[string]int, len(endnotes))
+	for i := range endnotes {
+		marker := NormalizeNoteMarker(endnotes[i].Marker)
+		if number, err := strconv.Atoi(marker); err == nil && number >= 0 {
+			if last > 0 && (number == 1 || number < last) {
+				chapter++
+				unmarked = 0
+			}
+			last = number
+		}
+
+		var key string
+		if marker == "" {
+			unmarked++
+			key = fmt.Sprintf("ch%d-u%d", chapter, unmarked)
+		} else {
+			key = fmt.Sprintf("ch%d-n%s", chapter, marker)
+		}
+		used[key]++
+		if used[key] > 1 {
+			key = fmt.Sprintf("%s-%d", key, used[key])
+		}
+		endnotes[i].Key = key
+	}
+}
+
+// NoteRef identifies a footnote or endnote by kind and index (0-indexed)
+type NoteRef struct {
+	Kind   string
+	Index  int
+	Anchor string // the note's anchor in the page text
+}
+
+// NoteOrder returns the footnotes and endnotes anchored in pages in the order
+// their anchors first occur
+func NoteOrder(pages []string) []NoteRef {
+	var order []NoteRef
+	seen := make(map[string]bool)
+	for _, page := range pages {
+		for _, anchor := range noteAnchorPattern.FindAllString(page, -1) {
+			kind, index, ok := parseNoteAnchor(anchor)
+			id := fmt.Sprintf("%s%d", kind, index)
+			if ok && !seen[id] {
+				seen[id] = true
+				order = append(order, NoteRef{Kind: kind, Index: index, Anchor: anchor})
+			}
+		}
+	}
+	return order
+}
+
+// parseNoteAnchor returns the kind and index (0-indexed) of a note anchor
+func parseNoteAnchor(anchor string) (string, int, bool) {
+	match := noteAnchorPattern.FindStringSubmatch(anchor)
+	if match == nil {
+		return "", 0, false
+	}
+	number, err := strconv.Atoi(match[2])
+	if err != nil || number < 1 {
+		return "", 0, false
+	}
+	return match[1], number - 1, true
+}
+
+// NormalizeNoteMarker strips brackets and whitespace the model sometimes
+// includes in a note's marker
+func NormalizeNoteMarker(marker string) string {
+	return strings.Trim(marker, "[]^ \t")
+}
+
+// occurrenceAfter returns the position in occurrences just after found
+func occurrenceAfter(occurrences []*markerOccurrence, found *markerOccurrence) int {
+	return sort.Search(len(occurrences), func(i int) bool {
+		o := occurrences[i]
+		return o.page > found.page || o.page == found.page && o.start > found.start
+	})
+}
@@ -0,0 +1,140 @@
+package documents
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestLinkNotes(t *testing.T) {
+	item := &models.ParsedItem{
+		Pages: []string{
+			"Memory is reconstructive.[1] See the figure ![chart](fig.png) and [the archive](https://example.com).",
+			"Archives are partial.[*] Historians disagree.[2]",
+			"Later work agrees.[*] The debate continues.[i]",
+			"## Notes\n\nEndnote definitions follow.",
+		},
+		PageNumbers: []string{"10", "11", "12", "13"},
+		Footnotes: []models.Footnote{
+			{Marker: "1", Text: "First note", PageNumber: "10", InTextPage: "99"},
+			{Marker: "*", Text: "Asterisk on page 11", PageNumber: "11"},
+			{Marker: "[2]", Text: "Note with a bracketed marker", PageNumber: "11"},
+			{Marker: "*", Text: "Asterisk on page 12", PageNumber: "12"},
+			{Marker: "7", Text: "Note without a marker", PageNumber: "12", InTextPage: "12"},
+		},
+		Endnotes: []models.Endnote{
+			{Marker: "i", Text: "An endnote", PageNumber: "13"},
+		},
+	}
+
+	linked := LinkNotes(item, "smith2020")
+	if linked != 5 {
+		t.Errorf("Expected 5 linked notes, got %d", linked)
+	}
+
+	expectedPages := []string{
+		"Memory is reconstructive.[^smith2020-fn1] See the figure ![chart](fig.png) and [the archive](https://example.com).",
+		"Archives are partial.[^smith2020-fn2] Historians disagree.[^smith2020-fn3]",
+		"Later work agrees.[^smith2020-fn4] The debate continues.[^smith2020-en1]",
+		"## Notes\n\nEndnote definitions follow.",
+	}
+	if !reflect.DeepEqual(item.Pages, expectedPages) {
+		t.Errorf("Unexpected pages:\n%q\nexpected:\n%q", item.Pages, expectedPages)
+	}
+
+	expectedInTextPages := []string{"1", "2", "2", "3", ""}
+	for i, footnote := range item.Footnotes {
+		if footnote.InTextPage != expectedInTextPages[i] {
+			t.Errorf("Footnote %d: expected in-text page %q, got %q", i, expectedInTextPages[i], footnote.InTextPage)
+		}
+	}
+
+	// Linking again after unlinking gives the same result
+	again := LinkNotes(item, "smith2020")
+	if again != linked || !reflect.DeepEqual(item.Pages, expectedPages) {
+		t.Errorf("Expected relinking to be stable, got %d links and pages %q", again, item.Pages)
+	}
+
+	UnlinkNotes(item)
+	if item.Pages[1] != "Archives are partial.[*] Historians disagree.[2]" {
+		t.Errorf("Expected anchors restored to markers, got %q", item.Pages[1])
+	}
+}
+
+func TestLinkNotes_MarkerOnAdjacentPage(t *testing.T) {
+	item := &models.ParsedItem{
+		Pages:       []string{"The claim ends the page.[3]", "The note is printed here."},
+		PageNumbers: []string{"5", "6"},
+		Footnotes:   []models.Footnote{{Marker: "3", Text: "Carried over", PageNumber: "6"}},
+	}
+
+	LinkNotes(item, "doc")
+	if item.Pages[0] != "The claim ends the page.[^doc-fn1]" || item.Footnotes[0].InTextPage != "1" {
+		t.Errorf("Expected marker on the previous page to be linked, got %q (in-text page %q)", item.Pages[0], item.Footnotes[0].InTextPage)
+	}
+}
+
+func TestLinkNotes_RestartedEndnoteNumbering(t *testing.T) {
+	item := &models.ParsedItem{
+		Pages: []string{
+			"# Chapter 1\n\nFirst claim.[1] Second claim.[2]",
+			"# Chapter 2\n\nThird claim.[1]",
+		},
+		Endnotes: []models.Endnote{
+			{Marker: "1", Text: "Chapter 1, note 1"},
+			{Marker: "2", Text: "Chapter 1, note 2"},
+			{Marker: "1", Text: "Chapter 2, note 1"},
+		},
+	}
+
+	LinkNotes(item, "doc")
+	expected := []string{
+		"# Chapter 1\n\nFirst claim.[^doc-en1] Second claim.[^doc-en2]",
+		"# Chapter 2\n\nThird claim.[^doc-en3]",
+	}
+	if !reflect.DeepEqual(item.Pages, expected) {
+		t.Errorf("Expected endnotes linked in document order, got %q", item.Pages)
+	}
+}
+
+func TestAssignEndnoteKeys(t *testing.T) {
+	markers := []string{"1", "2", "3", "[1]", "2", "", "*", "1", "1", "", "12", "4", "4"}
+	endnotes := make([]models.Endnote, len(markers))
+	for i, marker := range markers {
+		endnotes[i].Marker = marker
+	}
+
+	AssignEndnoteKeys(endnotes)
+	expected := []string{
+		"ch1-n1", "ch1-n2", "ch1-n3",
+		"ch2-n1", "ch2-n2", "ch2-u1", "ch2-n*",
+		"ch3-n1",
+		"ch4-n1", "ch4-u1", "ch4-n12",
+		"ch5-n4", "ch5-n4-2",
+	}
+	for i, want := range expected {
+		if endnotes[i].Key != want {
+			t.Errorf("Endnote %d (marker %q): expected key %q, got %q", i, markers[i], want, endnotes[i].Key)
+		}
+		if endnotes[i].Marker != markers[i] {
+			t.Errorf("Endnote %d: expected the marker kept as %q, got %q", i, markers[i], endnotes[i].Marker)
+		}
+	}
+}
+
+func TestNoteOrder(t *testing.T) {
+	pages := []string{
+		"Text.[^doc-en2] More.[^doc-fn1]",
+		"Again.[^doc-fn1] Then.[^doc-en1] Not an anchor: [1].",
+	}
+
+	expected := []NoteRef{
+		{Kind: NoteKindEndnote, Index: 1, Anchor: "[^doc-en2]"},
+		{Kind: NoteKindFootnote, Index: 0, Anchor: "[^doc-fn1]"},
+		{Kind: NoteKindEndnote, Index: 0, Anchor: "[^doc-en1]"},
+	}
+	if got := NoteOrder(pages); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, got)
+	}
+}
@@ -0,0 +1,158 @@
+package documents
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg" // Decodes DCT images
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/tiff" // Decodes CMYK images, which pdfcpu renders as TIFF
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+const (
+	// pageImageDPIEnv names the environment variable that sets the resolution of rendered page images
+	pageImageDPIEnv = "ACADEMIC_MCP_PAGE_IMAGE_DPI"
+
+	defaultPageImageDPI = 150
+	minPageImageDPI     = 36
+	maxPageImageDPI     = 600
+)
+
+// PageImageDPI returns the resolution page images are rendered at, set by
+// ACADEMIC_MCP_PAGE_IMAGE_DPI (default 150, from 36 to 600). An invalid value
+// returns the default and an error.
+func PageImageDPI() (int, error) {
+	value := strings.TrimSpace(os.Getenv(pageImageDPIEnv))
+	if value == "" {
+		return defaultPageImageDPI, nil
+	}
+	dpi, err := strconv.Atoi(value)
+	if err != nil || dpi < minPageImageDPI || dpi > maxPageImageDPI {
+		return defaultPageImageDPI, fmt.Errorf("invalid %s %q (expected a number from %d to %d)", pageImageDPIEnv, value, minPageImageDPI, maxPageImageDPI)
+	}
+	return dpi, nil
+}
+
+// RenderPDFPage renders a page of a PDF (counted from the first page of its
+// page range, if it has one) as a PNG at dpi dots per inch. Without a
+// rasterizer for text and vector graphics, only scanned pages can be rendered:
+// the page image is the largest image drawn on a page with no text layer,
+// scaled to the page's width. Pages with a text layer, and scanned pages whose
+// image cannot be decoded, return an invalid_input error.
+func RenderPDFPage(pdf models.DocumentData, pageIndex int, dpi int) ([]byte, error) {
+	conf := model.NewDefaultConfiguration()
+	conf.Cmd = model.EXTRACTIMAGES
+	ctx, err := api.ReadValidateAndOptimize(bytes.NewReader(pdf.Data), conf)
+	if err != nil {
+		return nil, err
+	}
+	first, last, err := pageSpan(pdf.Pages, ctx.PageCount)
+	if err != nil {
+		return nil, err
+	}
+	pageNr := first + pageIndex - 1
+	if pageIndex < 1 || pageNr > last {
+		return nil, models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("page %d is outside the document, which has %d pages", pageIndex, last-first+1))
+	}
+
+	hasText, err := pageHasText(ctx, pageNr)
+	if err != nil {
+		return nil, err
+	}
+	if hasText {
+		return nil, models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("page %d has a text layer; only scanned pages can be rendered", pageIndex))
+	}
+
+	img, err := pageScan(ctx, pageNr)
+	if err != nil {
+		return nil, fmt.Errorf("page %d: %w", pageIndex, err)
+	}
+	dims, err := ctx.PageDims()
+	if err != nil {
+		return nil, err
+	}
+
+	// Scans fill their page, so the image takes the page's width at dpi and
+	// keeps its own aspect ratio
+	bounds := img.Bounds()
+	width := int(math.Round(dims[pageNr-1].Width / 72 * float64(dpi)))
+	if width <= 0 {
+		width = bounds.Dx()
+	}
+	height := max(int(math.Round(float64(bounds.Dy())*float64(width)/float64(bounds.Dx()))), 1)
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(scaled, scaled.Bounds(), img, bounds, draw.Src, nil)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, scaled); err != nil {
+		return nil, fmt.Errorf("failed to encode page image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// pageScan decodes the largest image drawn on a page
+func pageScan(ctx *model.Context, pageNr int) (img image.Image, err error) {
+	// pdfcpu can panic on malformed image streams
+	defer func() {
+		if recover() != nil {
+			img, err = nil, models.WithErrorCode(models.ErrorInvalidInput, errors.New("the page image is malformed"))
+		}
+	}()
+
+	objNrs := pdfcpu.ImageObjNrs(ctx, pageNr)
+	sort.Ints(objNrs)
+	largest, largestArea := 0, 0
+	for _, objNr := range objNrs {
+		imageObj := ctx.Optimize.ImageObjects[objNr]
+		if imageObj == nil || imageObj.ImageDict == nil {
+			continue
+		}
+		stub, err := pdfcpu.ExtractImage(ctx, imageObj.ImageDict, false, imageResourceName(imageObj, pageNr), objNr, true)
+		if err != nil || stub == nil || stub.IsImgMask {
+			continue
+		}
+		if area := stub.Width * stub.Height; area > largestArea {
+			largest, largestArea = objNr, area
+		}
+	}
+	if largestArea == 0 {
+		return nil, models.WithErrorCode(models.ErrorInvalidInput, errors.New("the page has no image to render"))
+	}
+
+	imageObj := ctx.Optimize.ImageObjects[largest]
+	rendered, err := pdfcpu.ExtractImage(ctx, imageObj.ImageDict, false, imageResourceName(imageObj, pageNr), largest, false)
+	if err != nil || rendered == nil || rendered.Reader == nil {
+		return nil, models.WithErrorCode(models.ErrorInvalidInput, errors.New("the page image cannot be extracted"))
+	}
+	data, err := io.ReadAll(rendered)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the page image: %w", err)
+	}
+	img, _, err = image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("the page image is in a format that cannot be rendered (%s)", rendered.FileType))
+	}
+	return img, nil
+}
+
+// imageResourceName returns the name a page gives an image object in its resources
+func imageResourceName(imageObj *model.ImageObject, pageNr int) string {
+	if pageNr-1 < len(imageObj.ResourceNames) {
+		return imageObj.ResourceNames[pageNr-1]
+	}
+	return ""
+}
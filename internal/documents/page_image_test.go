@@ -0,0 +1,100 @@
+package documents
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestRenderPDFPage(t *testing.T) {
+	// The second page draws a small image and the scan, which is the larger one
+	pdf := models.DocumentData{Data: buildImagePdf([]int{64}, []int{40, 96}), Type: "pdf"}
+
+	data, err := RenderPDFPage(pdf, 2, 72)
+	if err != nil {
+		t.Fatalf("RenderPDFPage failed: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Expected a PNG, got %v", err)
+	}
+	// The page is 612 points (8.5 inches) wide; the scan is square
+	if bounds := img.Bounds(); bounds.Dx() != 612 || bounds.Dy() != 612 {
+		t.Errorf("Expected a 612x612 image at 72 dpi, got %dx%d", bounds.Dx(), bounds.Dy())
+	}
+
+	data, err = RenderPDFPage(pdf, 1, 144)
+	if err != nil {
+		t.Fatalf("RenderPDFPage failed: %v", err)
+	}
+	if img, err := png.Decode(bytes.NewReader(data)); err != nil || img.Bounds().Dx() != 1224 {
+		t.Errorf("Expected a 1224 pixel wide image at 144 dpi, got %v", err)
+	}
+
+	// Pages count from the start of the page range
+	ranged := models.DocumentData{Data: pdf.Data, Type: "pdf", Pages: models.PageRange{Start: 2, End: 2}}
+	if _, err := RenderPDFPage(ranged, 1, 72); err != nil {
+		t.Errorf("Expected the first page of the range rendered, got %v", err)
+	}
+	if _, err := RenderPDFPage(ranged, 2, 72); err == nil || !strings.Contains(err.Error(), "outside the document") {
+		t.Errorf("Expected a page past the range refused, got %v", err)
+	}
+}
+
+func TestRenderPDFPage_Unrenderable(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          []byte
+		page          int
+		expectedError string
+	}{
+		{"text layer", buildTestPdf("BT /F1 12 Tf 72 720 Td (Hello world) Tj ET"), 1, "has a text layer"},
+		{"no image", buildImagePdf(nil), 1, "no image to render"},
+		{"page out of range", buildImagePdf([]int{64}), 2, "outside the document"},
+		{"page zero", buildImagePdf([]int{64}), 0, "outside the document"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := RenderPDFPage(models.DocumentData{Data: tt.data, Type: "pdf"}, tt.page, 72)
+			var coded *models.CodedError
+			if !errors.As(err, &coded) || coded.Code != models.ErrorInvalidInput || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("Expected an invalid input error containing %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+
+	if _, err := RenderPDFPage(models.DocumentData{Data: []byte("This is not a PDF"), Type: "pdf"}, 1, 72); err == nil {
+		t.Error("Expected error for invalid PDF data, got nil")
+	}
+}
+
+func TestPageImageDPI(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected int
+		wantErr  bool
+	}{
+		{"", defaultPageImageDPI, false},
+		{"300", 300, false},
+		{" 72 ", 72, false},
+		{"10", defaultPageImageDPI, true},
+		{"1200", defaultPageImageDPI, true},
+		{"high", defaultPageImageDPI, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv(pageImageDPIEnv, tt.value)
+			dpi, err := PageImageDPI()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if dpi != tt.expected {
+				t.Errorf("Expected %d, got %d", tt.expected, dpi)
+			}
+		})
+	}
+}
@@ -2,37 +2,187 @@ package documents
 
 import (
 	"bytes"
+	"fmt"
 	"io"
+	"regexp"
+	"sync"
 
 	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
 
 	"github.com/Epistemic-Technology/academic-mcp/models"
 )
 
-// SplitPdf splits a PDF document into individual pages
-func SplitPdf(pdf models.DocumentData) (models.DocumentPages, error) {
-	var pages models.DocumentPages
-	reader := bytes.NewReader(pdf.Data)
-	conf := model.NewDefaultConfiguration()
-	pdfContext, err := api.ReadValidateAndOptimize(reader, conf)
+// textShowingOperator matches the PDF content stream operators that paint text
+// (Tj, TJ, ' and "). A page whose content stream contains none of them has no
+// extractable text layer.
+var textShowingOperator = regexp.MustCompile(`\bT[jJ]\b|[)\]>]\s*['"]`)
+
+// PDFPages gives the pages of a PDF (those in its page range, if it has one)
+// one at a time, each extracted as a single-page PDF when it is asked for.
+// Only the parsed document is held, not the extracted pages, so parsing a
+// large PDF with a few workers keeps only the pages in flight in memory.
+// Pages are extracted one at a time; it is safe for concurrent use.
+type PDFPages struct {
+	mu          sync.Mutex
+	pdfContext  *model.Context
+	first, last int
+}
+
+// OpenPdfPages reads a PDF for its pages to be extracted on demand. A PDF
+// without pages gives an empty PDFPages.
+func OpenPdfPages(pdf models.DocumentData) (*PDFPages, error) {
+	pdfContext, err := api.ReadValidateAndOptimize(bytes.NewReader(pdf.Data), model.NewDefaultConfiguration())
+	if err != nil {
+		return nil, err
+	}
+	if pdfContext.PageCount == 0 {
+		return &PDFPages{pdfContext: pdfContext}, nil
+	}
+	first, last, err := pageSpan(pdf.Pages, pdfContext.PageCount)
 	if err != nil {
-		return pages, err
+		return nil, err
 	}
-	pageCount := pdfContext.PageCount
-	if pageCount == 0 {
-		return pages, nil
+	return &PDFPages{pdfContext: pdfContext, first: first, last: last}, nil
+}
+
+// Len returns the number of pages
+func (p *PDFPages) Len() int {
+	if p.first == 0 {
+		return 0
+	}
+	return p.last - p.first + 1
+}
+
+// Page extracts a page (0-indexed from the start of the page range) as a
+// single-page PDF
+func (p *PDFPages) Page(index int) (models.DocumentPageData, error) {
+	if index < 0 || index >= p.Len() {
+		return nil, fmt.Errorf("page %d is out of range (document has %d pages)", index+1, p.Len())
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pageReader, err := api.ExtractPage(p.pdfContext, p.first+index)
+	if err != nil {
+		return nil, err
 	}
-	for pageNum := 1; pageNum <= pageCount; pageNum++ {
-		pageReader, err := api.ExtractPage(pdfContext, pageNum)
+	pageData, err := io.ReadAll(pageReader)
+	if err != nil {
+		return nil, err
+	}
+	return models.DocumentPageData(pageData), nil
+}
+
+// ImageOnly reports, for each page, whether it has no extractable text layer
+// (see DetectImageOnlyPages)
+func (p *PDFPages) ImageOnly() ([]bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	imageOnly := make([]bool, p.Len())
+	for i := range imageOnly {
+		hasText, err := pageHasText(p.pdfContext, p.first+i)
 		if err != nil {
-			return pages, err
+			return nil, err
 		}
-		pageData, err := io.ReadAll(pageReader)
-		if err != nil {
-			return pages, err
+		imageOnly[i] = !hasText
+	}
+	return imageOnly, nil
+}
+
+// SplitPdf splits a PDF document into individual pages, only those in its page
+// range if it has one. All pages are held in memory at once; parsing extracts
+// them as needed through OpenPdfPages instead.
+func SplitPdf(pdf models.DocumentData) (models.DocumentPages, error) {
+	pages, err := OpenPdfPages(pdf)
+	if err != nil {
+		return nil, err
+	}
+	split := make(models.DocumentPages, pages.Len())
+	for i := range split {
+		if split[i], err = pages.Page(i); err != nil {
+			return nil, err
 		}
-		pages = append(pages, models.DocumentPageData(pageData))
 	}
-	return pages, nil
+	return split, nil
+}
+
+// DetectImageOnlyPages reports, for each page of a PDF (in its page range, if
+// it has one), whether the page has no extractable text layer. Such pages are
+// typically scans and can only be read by transcribing the page image. Text
+// drawn inside form XObjects is not inspected, so pages that only reference
+// forms are reported as image-only.
+func DetectImageOnlyPages(pdf models.DocumentData) ([]bool, error) {
+	pages, err := OpenPdfPages(pdf)
+	if err != nil {
+		return nil, err
+	}
+	return pages.ImageOnly()
+}
+
+// pageHasText reports whether a page's content stream paints any text
+func pageHasText(pdfContext *model.Context, pageNum int) (bool, error) {
+	contentReader, err := pdfcpu.ExtractPageContent(pdfContext, pageNum)
+	if err != nil {
+		return false, err
+	}
+	content, err := io.ReadAll(contentReader)
+	if err != nil {
+		return false, err
+	}
+	return textShowingOperator.Match(content), nil
+}
+
+// IsScannedDocument reports whether a document should be treated as a scan,
+// which is the case when more than half of its pages are image-only
+func IsScannedDocument(imageOnly []bool) bool {
+	count := 0
+	for _, isImageOnly := range imageOnly {
+		if isImageOnly {
+			count++
+		}
+	}
+	return len(imageOnly) > 0 && count*2 > len(imageOnly)
+}
+
+// PDFPageCount returns the number of pages of a PDF, ignoring its page range
+func PDFPageCount(pdf models.DocumentData) (int, error) {
+	return api.PageCount(bytes.NewReader(pdf.Data), model.NewDefaultConfiguration())
+}
+
+// CheckPageRange returns an invalid_input error if a document's page range
+// does not fit its pages: ranges apply to PDFs only, and must start at or
+// before the last page and end at or after the start
+func CheckPageRange(pdf models.DocumentData) error {
+	if !pdf.Pages.IsSet() {
+		return nil
+	}
+	if pdf.Type != "pdf" {
+		return models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("a page range can only be parsed from a PDF, not a %s document", pdf.Type))
+	}
+	pageCount, err := PDFPageCount(pdf)
+	if err != nil {
+		return models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("failed to read PDF: %w", err))
+	}
+	_, _, err = pageSpan(pdf.Pages, pageCount)
+	return err
+}
+
+// pageSpan returns the first and last physical pages (1-indexed) of a range
+// of a PDF with pageCount pages
+func pageSpan(pages models.PageRange, pageCount int) (first, last int, err error) {
+	first, last = max(pages.Start, 1), pageCount
+	if pages.End > 0 {
+		last = pages.End
+	}
+	if pages.Start < 0 || pages.End < 0 {
+		return 0, 0, models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("invalid page range %s: pages are numbered from 1", pages))
+	}
+	if first > pageCount || last > pageCount {
+		return 0, 0, models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("page range %s is outside the document, which has %d pages", pages, pageCount))
+	}
+	if last < first {
+		return 0, 0, models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("invalid page range %s: the end is before the start", pages))
+	}
+	return first, last, nil
 }
@@ -0,0 +1,204 @@
+package documents
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+const (
+	// imageDataEnv names the environment variable that turns image extraction on or off
+	imageDataEnv = "ACADEMIC_MCP_IMAGE_DATA"
+	// maxImageBytesEnv names the environment variable that limits the size of one extracted image
+	maxImageBytesEnv = "ACADEMIC_MCP_MAX_IMAGE_BYTES"
+	// maxDocumentImageBytesEnv names the environment variable that limits the extracted images of a document
+	maxDocumentImageBytesEnv = "ACADEMIC_MCP_MAX_DOCUMENT_IMAGE_BYTES"
+
+	defaultMaxImageBytes         = 5 << 20
+	defaultMaxDocumentImageBytes = 25 << 20
+
+	// minImageDimension is the smallest width and height of an image worth
+	// keeping; smaller ones are rules, bullets, and logos rather than figures
+	minImageDimension = 32
+)
+
+// ImageLimits controls the extraction of embedded PDF images
+type ImageLimits struct {
+	Enabled          bool
+	MaxImageBytes    int // Larger images are skipped
+	MaxDocumentBytes int // Images that would take a document past this are skipped
+}
+
+// ConfiguredImageLimits returns the image extraction settings: extraction is on
+// unless ACADEMIC_MCP_IMAGE_DATA is false, and images are limited to
+// ACADEMIC_MCP_MAX_IMAGE_BYTES each (default 5 MiB) and
+// ACADEMIC_MCP_MAX_DOCUMENT_IMAGE_BYTES per document (default 25 MiB). Invalid
+// values fall back to the defaults and return an error.
+func ConfiguredImageLimits() (ImageLimits, error) {
+	limits := ImageLimits{Enabled: true, MaxImageBytes: defaultMaxImageBytes, MaxDocumentBytes: defaultMaxDocumentImageBytes}
+	var errs []string
+
+	if value := strings.TrimSpace(os.Getenv(imageDataEnv)); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("invalid %s %q (expected true or false)", imageDataEnv, value))
+		} else {
+			limits.Enabled = enabled
+		}
+	}
+	for _, setting := range []struct {
+		env   string
+		value *int
+	}{
+		{maxImageBytesEnv, &limits.MaxImageBytes},
+		{maxDocumentImageBytesEnv, &limits.MaxDocumentBytes},
+	} {
+		value := strings.TrimSpace(os.Getenv(setting.env))
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			errs = append(errs, fmt.Sprintf("invalid %s %q (expected a positive number of bytes)", setting.env, value))
+			continue
+		}
+		*setting.value = n
+	}
+
+	if len(errs) > 0 {
+		return limits, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return limits, nil
+}
+
+// PDFImage is an image embedded in a PDF page, rendered to a standard format
+type PDFImage struct {
+	PageIndex int // Sequential page (1-indexed)
+	MIMEType  string
+	Width     int
+	Height    int
+	Data      []byte
+}
+
+// imageMIMETypes maps the file types pdfcpu renders images to onto MIME types
+var imageMIMETypes = map[string]string{
+	"png": "image/png",
+	"jpg": "image/jpeg",
+	"tif": "image/tiff",
+	"jpx": "image/jp2",
+}
+
+// ExtractPDFImages returns the images embedded in each page of a PDF (in its
+// page range, if it has one, with PageIndex counted from the range's first
+// page), in page order and, within a page, in object order. Image masks,
+// thumbnails, images smaller than 32 pixels on a side, and images in formats
+// pdfcpu cannot render are skipped, as are images over the size limits. An
+// image that fails to extract is skipped rather than failing the document.
+func ExtractPDFImages(pdf models.DocumentData, limits ImageLimits) ([]PDFImage, error) {
+	if !limits.Enabled {
+		return nil, nil
+	}
+	conf := model.NewDefaultConfiguration()
+	conf.Cmd = model.EXTRACTIMAGES
+	ctx, err := api.ReadValidateAndOptimize(bytes.NewReader(pdf.Data), conf)
+	if err != nil {
+		return nil, err
+	}
+
+	first, last, err := pageSpan(pdf.Pages, ctx.PageCount)
+	if err != nil {
+		return nil, err
+	}
+
+	var images []PDFImage
+	total := 0
+	for pageNr := first; pageNr <= last; pageNr++ {
+		objNrs := pdfcpu.ImageObjNrs(ctx, pageNr)
+		sort.Ints(objNrs)
+		for _, objNr := range objNrs {
+			image, ok := extractPDFImage(ctx, pageNr, objNr, limits.MaxImageBytes)
+			if !ok || total+len(image.Data) > limits.MaxDocumentBytes {
+				continue
+			}
+			total += len(image.Data)
+			image.PageIndex = pageNr - first + 1
+			images = append(images, image)
+		}
+	}
+	return images, nil
+}
+
+// extractPDFImage renders one image object of a page, reporting false if it is
+// skipped
+func extractPDFImage(ctx *model.Context, pageNr int, objNr int, maxBytes int) (image PDFImage, ok bool) {
+	// pdfcpu can panic on malformed image streams
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+
+	imageObj := ctx.Optimize.ImageObjects[objNr]
+	if imageObj == nil || imageObj.ImageDict == nil {
+		return PDFImage{}, false
+	}
+	resourceName := imageResourceName(imageObj, pageNr)
+
+	// The stub reads the image's dimensions without decoding it
+	stub, err := pdfcpu.ExtractImage(ctx, imageObj.ImageDict, false, resourceName, objNr, true)
+	if err != nil || stub == nil || stub.IsImgMask || stub.Width < minImageDimension || stub.Height < minImageDimension {
+		return PDFImage{}, false
+	}
+
+	rendered, err := pdfcpu.ExtractImage(ctx, imageObj.ImageDict, false, resourceName, objNr, false)
+	if err != nil || rendered == nil || rendered.Reader == nil {
+		return PDFImage{}, false
+	}
+	mimeType, known := imageMIMETypes[rendered.FileType]
+	if !known {
+		return PDFImage{}, false
+	}
+	data, err := io.ReadAll(io.LimitReader(rendered, int64(maxBytes)+1))
+	if err != nil || len(data) == 0 || len(data) > maxBytes {
+		return PDFImage{}, false
+	}
+
+	return PDFImage{PageIndex: pageNr, MIMEType: mimeType, Width: stub.Width, Height: stub.Height, Data: data}, true
+}
+
+// AttachPDFImages gives the images the parser described on each page the data
+// of the images extracted from that page, matching them in order. Described
+// images without a match, such as charts drawn with vector graphics, keep no
+// data. It returns the number of images that received data.
+func AttachPDFImages(images []models.Image, extracted []PDFImage) int {
+	byPage := make(map[int][]PDFImage)
+	for _, image := range extracted {
+		byPage[image.PageIndex] = append(byPage[image.PageIndex], image)
+	}
+
+	attached := 0
+	for i := range images {
+		page := images[i].PageIndex
+		if len(byPage[page]) == 0 {
+			continue
+		}
+		image := byPage[page][0]
+		byPage[page] = byPage[page][1:]
+		images[i].MIMEType = image.MIMEType
+		images[i].Width = image.Width
+		images[i].Height = image.Height
+		images[i].Data = image.Data
+		attached++
+	}
+	return attached
+}
@@ -0,0 +1,179 @@
+package documents
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// buildImagePdf builds a PDF whose pages each draw square DeviceGray images of
+// the given sizes, in order
+func buildImagePdf(pages ...[]int) []byte {
+	objects := []string{"<< /Type /Catalog /Pages 2 0 R >>", ""}
+	pageRefs := ""
+	for _, sizes := range pages {
+		xobjects := ""
+		content := ""
+		for i, size := range sizes {
+			objects = append(objects, fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceGray /BitsPerComponent 8 /Length %d >>\nstream\n%s\nendstream",
+				size, size, size*size, strings.Repeat("\x80", size*size)))
+			xobjects += fmt.Sprintf("/Im%d %d 0 R ", i, len(objects))
+			content += fmt.Sprintf("q %d 0 0 %d 72 %d cm /Im%d Do Q ", size, size, 72+i*100, i)
+		}
+		objects = append(objects, fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+		objects = append(objects, fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /XObject << %s>> >> /Contents %d 0 R >>", xobjects, len(objects)))
+		pageRefs += fmt.Sprintf("%d 0 R ", len(objects))
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", pageRefs, len(pages))
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xrefOffset := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xrefOffset)
+	return buf.Bytes()
+}
+
+func TestExtractPDFImages(t *testing.T) {
+	pdf := models.DocumentData{Data: buildImagePdf([]int{40, 1, 64}, nil, []int{48}), Type: "pdf"}
+	unlimited := ImageLimits{Enabled: true, MaxImageBytes: 1 << 20, MaxDocumentBytes: 1 << 20}
+
+	tests := []struct {
+		name       string
+		limits     ImageLimits
+		wantPages  []int
+		wantWidths []int
+	}{
+		{"skips tiny images", unlimited, []int{1, 1, 3}, []int{40, 64, 48}},
+		{"disabled", ImageLimits{MaxImageBytes: 1 << 20, MaxDocumentBytes: 1 << 20}, nil, nil},
+		// The rendered PNGs are 109, 140, and 120 bytes
+		{"per-image limit", ImageLimits{Enabled: true, MaxImageBytes: 130, MaxDocumentBytes: 1 << 20}, []int{1, 3}, []int{40, 48}},
+		{"document limit", ImageLimits{Enabled: true, MaxImageBytes: 1 << 20, MaxDocumentBytes: 250}, []int{1, 1}, []int{40, 64}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			images, err := ExtractPDFImages(pdf, tt.limits)
+			if err != nil {
+				t.Fatalf("ExtractPDFImages failed: %v", err)
+			}
+			if len(images) != len(tt.wantPages) {
+				t.Fatalf("Expected %d images, got %d", len(tt.wantPages), len(images))
+			}
+			for i, image := range images {
+				if image.PageIndex != tt.wantPages[i] || image.Width != tt.wantWidths[i] || image.Height != tt.wantWidths[i] {
+					t.Errorf("Image %d: expected page %d and size %d, got page %d and %dx%d",
+						i, tt.wantPages[i], tt.wantWidths[i], image.PageIndex, image.Width, image.Height)
+				}
+				if image.MIMEType != "image/png" || !bytes.HasPrefix(image.Data, []byte("\x89PNG")) {
+					t.Errorf("Image %d: expected PNG data, got %q with %d bytes", i, image.MIMEType, len(image.Data))
+				}
+			}
+		})
+	}
+}
+
+func TestExtractPDFImages_Sample(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "samples", "hewitt.pdf"))
+	if err != nil {
+		t.Skipf("Sample PDF not available: %v", err)
+	}
+	limits := ImageLimits{Enabled: true, MaxImageBytes: defaultMaxImageBytes, MaxDocumentBytes: defaultMaxDocumentImageBytes}
+	images, err := ExtractPDFImages(models.DocumentData{Data: data, Type: "pdf"}, limits)
+	if err != nil {
+		t.Fatalf("ExtractPDFImages failed: %v", err)
+	}
+	if len(images) == 0 {
+		t.Fatal("Expected images from the sample PDF, got none")
+	}
+	total := 0
+	for i, image := range images {
+		if image.PageIndex < 1 || image.MIMEType == "" || len(image.Data) == 0 {
+			t.Errorf("Image %d: expected a page, MIME type, and data, got %+v", i, image)
+		}
+		if i > 0 && image.PageIndex < images[i-1].PageIndex {
+			t.Errorf("Image %d: expected page order, got page %d after %d", i, image.PageIndex, images[i-1].PageIndex)
+		}
+		total += len(image.Data)
+	}
+	if total > limits.MaxDocumentBytes {
+		t.Errorf("Expected at most %d bytes of images, got %d", limits.MaxDocumentBytes, total)
+	}
+}
+
+func TestExtractPDFImages_InvalidInput(t *testing.T) {
+	limits := ImageLimits{Enabled: true, MaxImageBytes: 1 << 20, MaxDocumentBytes: 1 << 20}
+	if _, err := ExtractPDFImages(models.DocumentData{Data: []byte("This is not a PDF"), Type: "pdf"}, limits); err == nil {
+		t.Error("Expected error for invalid PDF data, got nil")
+	}
+}
+
+func TestAttachPDFImages(t *testing.T) {
+	images := []models.Image{
+		{Caption: "Figure 1", PageIndex: 1},
+		{Caption: "Figure 2", PageIndex: 1},
+		{Caption: "Chart", PageIndex: 2},
+		{Caption: "Figure 3", PageIndex: 3},
+	}
+	extracted := []PDFImage{
+		{PageIndex: 1, MIMEType: "image/png", Data: []byte("a")},
+		{PageIndex: 1, MIMEType: "image/jpeg", Data: []byte("b")},
+		{PageIndex: 1, MIMEType: "image/png", Data: []byte("c")},
+		{PageIndex: 3, MIMEType: "image/png", Data: []byte("d")},
+	}
+
+	if attached := AttachPDFImages(images, extracted); attached != 3 {
+		t.Errorf("Expected 3 images attached, got %d", attached)
+	}
+	expected := []string{"a", "b", "", "d"}
+	for i, want := range expected {
+		if string(images[i].Data) != want {
+			t.Errorf("Image %d: expected data %q, got %q", i, want, images[i].Data)
+		}
+	}
+	if images[1].MIMEType != "image/jpeg" || images[2].MIMEType != "" {
+		t.Errorf("Expected MIME types to follow the data, got %q and %q", images[1].MIMEType, images[2].MIMEType)
+	}
+}
+
+func TestConfiguredImageLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  string
+		maxImage string
+		expected ImageLimits
+		wantErr  bool
+	}{
+		{"defaults", "", "", ImageLimits{true, defaultMaxImageBytes, defaultMaxDocumentImageBytes}, false},
+		{"disabled", "false", "", ImageLimits{false, defaultMaxImageBytes, defaultMaxDocumentImageBytes}, false},
+		{"custom limit", "", "1000", ImageLimits{true, 1000, defaultMaxDocumentImageBytes}, false},
+		{"invalid toggle", "sometimes", "", ImageLimits{true, defaultMaxImageBytes, defaultMaxDocumentImageBytes}, true},
+		{"invalid limit", "", "-5", ImageLimits{true, defaultMaxImageBytes, defaultMaxDocumentImageBytes}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(imageDataEnv, tt.enabled)
+			t.Setenv(maxImageBytesEnv, tt.maxImage)
+			t.Setenv(maxDocumentImageBytesEnv, "")
+			limits, err := ConfiguredImageLimits()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if limits != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, limits)
+			}
+		})
+	}
+}
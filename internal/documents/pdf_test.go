@@ -2,8 +2,13 @@ package documents
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/Epistemic-Technology/academic-mcp/models"
@@ -104,3 +109,327 @@ func TestSplitPdf_InvalidInput(t *testing.T) {
 		t.Error("Expected error for invalid PDF data, got nil")
 	}
 }
+
+func TestSplitPdf_PageRange(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("..", "samples", "*.pdf"))
+	if err != nil {
+		t.Fatalf("Failed to list sample PDFs: %v", err)
+	}
+	if len(files) == 0 {
+		t.Skip("No sample PDFs found in samples directory")
+	}
+
+	for _, filePath := range files {
+		t.Run(filepath.Base(filePath), func(t *testing.T) {
+			pdfBytes, err := os.ReadFile(filePath)
+			if err != nil {
+				t.Fatalf("Failed to read PDF file %s: %v", filePath, err)
+			}
+			pageCount, err := PDFPageCount(models.DocumentData{Data: pdfBytes, Type: "pdf"})
+			if err != nil {
+				t.Fatalf("PDFPageCount failed: %v", err)
+			}
+			if pageCount < 3 {
+				t.Skipf("Sample has only %d pages", pageCount)
+			}
+			all, err := SplitPdf(models.DocumentData{Data: pdfBytes, Type: "pdf"})
+			if err != nil {
+				t.Fatalf("SplitPdf failed: %v", err)
+			}
+
+			for _, pages := range []models.PageRange{{Start: 2, End: 3}, {Start: pageCount}, {End: 2}} {
+				data := models.DocumentData{Data: pdfBytes, Type: "pdf", Pages: pages}
+				if err := CheckPageRange(data); err != nil {
+					t.Errorf("CheckPageRange(%s) failed: %v", pages, err)
+				}
+				split, err := SplitPdf(data)
+				if err != nil {
+					t.Fatalf("SplitPdf(%s) failed: %v", pages, err)
+				}
+				first, last, _ := pageSpan(pages, pageCount)
+				if len(split) != last-first+1 {
+					t.Errorf("Range %s: expected %d pages, got %d", pages, last-first+1, len(split))
+				}
+				for i := range split {
+					got, _ := ExtractPDFPageText(split[i])
+					want, _ := ExtractPDFPageText(all[first-1+i])
+					if got != want {
+						t.Errorf("Range %s: page %d differs from physical page %d", pages, i+1, first+i)
+					}
+				}
+			}
+
+			for _, pages := range []models.PageRange{{Start: pageCount + 1}, {Start: 2, End: pageCount + 10}, {Start: 3, End: 2}} {
+				data := models.DocumentData{Data: pdfBytes, Type: "pdf", Pages: pages}
+				err := CheckPageRange(data)
+				var coded *models.CodedError
+				if !errors.As(err, &coded) || coded.Code != models.ErrorInvalidInput {
+					t.Errorf("Expected invalid_input for range %s, got %v", pages, err)
+				}
+				if _, err := SplitPdf(data); err == nil {
+					t.Errorf("Expected SplitPdf to refuse range %s", pages)
+				}
+			}
+			err = CheckPageRange(models.DocumentData{Data: pdfBytes, Type: "pdf", Pages: models.PageRange{Start: pageCount + 1}})
+			if err == nil || !strings.Contains(err.Error(), fmt.Sprintf("has %d pages", pageCount)) {
+				t.Errorf("Expected the error to give the page count, got %v", err)
+			}
+		})
+	}
+}
+
+func TestOpenPdfPages(t *testing.T) {
+	pdfBytes := buildTestPdf(
+		"BT /F1 12 Tf 72 720 Td (Front matter) Tj ET",
+		"q 612 0 0 792 0 0 cm /Im0 Do Q",
+		"BT /F1 12 Tf 72 720 Td (Chapter two) Tj ET",
+	)
+	data := models.DocumentData{Data: pdfBytes, Type: "pdf"}
+	split, err := SplitPdf(data)
+	if err != nil {
+		t.Fatalf("SplitPdf failed: %v", err)
+	}
+
+	pages, err := OpenPdfPages(data)
+	if err != nil {
+		t.Fatalf("OpenPdfPages failed: %v", err)
+	}
+	if pages.Len() != 3 {
+		t.Fatalf("Expected 3 pages, got %d", pages.Len())
+	}
+
+	// Pages extracted concurrently and out of order match the split pages
+	var wg sync.WaitGroup
+	extracted := make(models.DocumentPages, pages.Len())
+	errs := make([]error, pages.Len())
+	for i := pages.Len() - 1; i >= 0; i-- {
+		wg.Go(func() { extracted[i], errs[i] = pages.Page(i) })
+	}
+	wg.Wait()
+	for i := range extracted {
+		if errs[i] != nil {
+			t.Fatalf("Page(%d) failed: %v", i, errs[i])
+		}
+		got, _ := ExtractPDFPageText(extracted[i])
+		want, _ := ExtractPDFPageText(split[i])
+		if got != want {
+			t.Errorf("Page %d: expected %q, got %q", i+1, want, got)
+		}
+	}
+
+	for _, index := range []int{-1, 3} {
+		if _, err := pages.Page(index); err == nil || !strings.Contains(err.Error(), "out of range") {
+			t.Errorf("Expected page index %d refused, got %v", index, err)
+		}
+	}
+
+	// A page range counts from its first page
+	ranged, err := OpenPdfPages(models.DocumentData{Data: pdfBytes, Type: "pdf", Pages: models.PageRange{Start: 3}})
+	if err != nil {
+		t.Fatalf("OpenPdfPages failed: %v", err)
+	}
+	page, err := ranged.Page(0)
+	if err != nil || ranged.Len() != 1 {
+		t.Fatalf("Expected one page in the range, got %d (%v)", ranged.Len(), err)
+	}
+	if text, _ := ExtractPDFPageText(page); text != "Chapter two" {
+		t.Errorf("Expected the third page, got %q", text)
+	}
+	if imageOnly, err := ranged.ImageOnly(); err != nil || len(imageOnly) != 1 || imageOnly[0] {
+		t.Errorf("Expected image-only flags [false], got %v (%v)", imageOnly, err)
+	}
+
+	if _, err := OpenPdfPages(models.DocumentData{Data: []byte("This is not a PDF"), Type: "pdf"}); err == nil {
+		t.Error("Expected error for invalid PDF data, got nil")
+	}
+}
+
+// benchmarkScanPages and benchmarkScanSize describe the synthetic scan split
+// by the PDF splitting benchmarks: 300 pages of 64 KB images
+const (
+	benchmarkScanPages = 300
+	benchmarkScanSize  = 256
+)
+
+// benchmarkWorkers is how many pages the lazy benchmark handles at once, as
+// parsing does with its worker pool
+const benchmarkWorkers = 4
+
+func benchmarkScan(b *testing.B) models.DocumentData {
+	b.Helper()
+	pages := make([][]int, benchmarkScanPages)
+	for i := range pages {
+		pages[i] = []int{benchmarkScanSize}
+	}
+	return models.DocumentData{Data: buildImagePdf(pages...), Type: "pdf"}
+}
+
+// BenchmarkSplitPdf holds every page of the scan at once. peak-page-bytes is
+// the most page data held at any time.
+func BenchmarkSplitPdf(b *testing.B) {
+	data := benchmarkScan(b)
+	b.ReportAllocs()
+	peak := 0
+	for b.Loop() {
+		split, err := SplitPdf(data)
+		if err != nil {
+			b.Fatalf("SplitPdf failed: %v", err)
+		}
+		held := 0
+		for _, page := range split {
+			held += len(page)
+		}
+		peak = max(peak, held)
+	}
+	b.ReportMetric(float64(peak), "peak-page-bytes")
+}
+
+// BenchmarkOpenPdfPages extracts the scan's pages as benchmarkWorkers workers
+// take them, dropping each when it is done, as parsing does
+func BenchmarkOpenPdfPages(b *testing.B) {
+	data := benchmarkScan(b)
+	b.ReportAllocs()
+	var held, peak atomic.Int64
+	for b.Loop() {
+		pages, err := OpenPdfPages(data)
+		if err != nil {
+			b.Fatalf("OpenPdfPages failed: %v", err)
+		}
+		next := atomic.Int64{}
+		var wg sync.WaitGroup
+		for range benchmarkWorkers {
+			wg.Go(func() {
+				for i := int(next.Add(1)) - 1; i < pages.Len(); i = int(next.Add(1)) - 1 {
+					page, err := pages.Page(i)
+					if err != nil {
+						b.Errorf("Page(%d) failed: %v", i, err)
+						return
+					}
+					size := int64(len(page))
+					now := held.Add(size)
+					for p := peak.Load(); now > p && !peak.CompareAndSwap(p, now); p = peak.Load() {
+					}
+					held.Add(-size)
+				}
+			})
+		}
+		wg.Wait()
+	}
+	b.ReportMetric(float64(peak.Load()), "peak-page-bytes")
+}
+
+func TestPageRange_OtherFunctions(t *testing.T) {
+	pdfBytes := buildTestPdf(
+		"BT /F1 12 Tf 72 720 Td (Front matter) Tj ET",
+		"q 612 0 0 792 0 0 cm /Im0 Do Q",
+		"BT /F1 12 Tf 72 720 Td (Chapter two) Tj ET",
+	)
+	data := models.DocumentData{Data: pdfBytes, Type: "pdf", Pages: models.PageRange{Start: 2, End: 3}}
+
+	imageOnly, err := DetectImageOnlyPages(data)
+	if err != nil || len(imageOnly) != 2 || !imageOnly[0] || imageOnly[1] {
+		t.Errorf("Expected image-only flags [true false] for pages 2-3, got %v (%v)", imageOnly, err)
+	}
+	text, err := ExtractPDFText(data)
+	if err != nil || len(text) != 2 || text[1] != "Chapter two" {
+		t.Errorf("Expected the text of pages 2-3, got %q (%v)", text, err)
+	}
+	item, err := ParseDocumentBasic(data)
+	if err != nil {
+		t.Fatalf("ParseDocumentBasic failed: %v", err)
+	}
+	if len(item.PageNumbers) != 2 || item.PageNumbers[0] != "2" || item.PageNumbers[1] != "3" {
+		t.Errorf("Expected pages numbered from the start of the range, got %v", item.PageNumbers)
+	}
+
+	if err := CheckPageRange(models.DocumentData{Data: []byte("# Notes"), Type: "md", Pages: models.PageRange{Start: 2}}); err == nil {
+		t.Error("Expected a page range of a Markdown document to be refused")
+	}
+}
+
+// buildTestPdf assembles a minimal PDF with one page per content stream
+func buildTestPdf(contents ...string) []byte {
+	var objects []string
+	pageRefs := ""
+	for i, content := range contents {
+		pageObj := 5 + i*2
+		pageRefs += fmt.Sprintf("%d 0 R ", pageObj)
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 3 0 R >> /XObject << /Im0 4 0 R >> >> /Contents %d 0 R >>", pageObj+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		)
+	}
+	objects = append([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", pageRefs, len(contents)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		"<< /Type /XObject /Subtype /Image /Width 1 /Height 1 /ColorSpace /DeviceGray /BitsPerComponent 8 /Length 1 >>\nstream\n\x80\nendstream",
+	}, objects...)
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xrefOffset := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xrefOffset)
+	return buf.Bytes()
+}
+
+func TestDetectImageOnlyPages(t *testing.T) {
+	textPage := "BT /F1 12 Tf 72 720 Td (Hello world) Tj ET"
+	arrayTextPage := "BT /F1 12 Tf 72 720 Td [(Hel) -20 (lo)]TJ ET"
+	imagePage := "q 612 0 0 792 0 0 cm /Im0 Do Q"
+	blankPage := ""
+
+	pdfBytes := buildTestPdf(textPage, imagePage, arrayTextPage, blankPage)
+	imageOnly, err := DetectImageOnlyPages(models.DocumentData{Data: pdfBytes, Type: "pdf"})
+	if err != nil {
+		t.Fatalf("DetectImageOnlyPages failed: %v", err)
+	}
+
+	expected := []bool{false, true, false, true}
+	if len(imageOnly) != len(expected) {
+		t.Fatalf("Expected %d pages, got %d", len(expected), len(imageOnly))
+	}
+	for i := range expected {
+		if imageOnly[i] != expected[i] {
+			t.Errorf("Page %d: expected image-only=%v, got %v", i+1, expected[i], imageOnly[i])
+		}
+	}
+}
+
+func TestDetectImageOnlyPages_InvalidInput(t *testing.T) {
+	_, err := DetectImageOnlyPages(models.DocumentData{Data: []byte("This is not a PDF"), Type: "pdf"})
+	if err == nil {
+		t.Error("Expected error for invalid PDF data, got nil")
+	}
+}
+
+func TestIsScannedDocument(t *testing.T) {
+	tests := []struct {
+		name      string
+		imageOnly []bool
+		expected  bool
+	}{
+		{"no pages", nil, false},
+		{"all text", []bool{false, false, false}, false},
+		{"all images", []bool{true, true}, true},
+		{"half images", []bool{true, false}, false},
+		{"mostly images", []bool{true, true, false}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsScannedDocument(tt.imageOnly); got != tt.expected {
+				t.Errorf("IsScannedDocument(%v) = %v, want %v", tt.imageOnly, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,330 @@
+package documents
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// tjSpaceThreshold is the TJ kerning adjustment (in thousandths of a text space
+// unit) beyond which a gap between strings is taken for a word space
+const tjSpaceThreshold = -200
+
+// ExtractPDFPageText extracts the text a single-page PDF paints, one line per
+// text line, from the strings shown by its content stream's text operators.
+// Strings are decoded as UTF-16 when they carry its byte order mark and as
+// Latin-1 otherwise, so text in fonts with custom encodings may not come out
+// readable. As with DetectImageOnlyPages, text inside form XObjects is not read.
+func ExtractPDFPageText(page models.DocumentPageData) (string, error) {
+	pages, err := ExtractPDFText(models.DocumentData{Data: page, Type: "pdf"})
+	if err != nil {
+		return "", err
+	}
+	return tidyExtractedText(strings.Join(pages, "\n")), nil
+}
+
+// ExtractPDFText extracts the text of each page of a PDF (in its page range, if
+// it has one), as ExtractPDFPageText does for a single page. A page with no
+// text layer yields an empty string.
+func ExtractPDFText(pdf models.DocumentData) ([]string, error) {
+	conf := model.NewDefaultConfiguration()
+	pdfContext, err := api.ReadValidateAndOptimize(bytes.NewReader(pdf.Data), conf)
+	if err != nil {
+		return nil, err
+	}
+	first, last, err := pageSpan(pdf.Pages, pdfContext.PageCount)
+	if err != nil {
+		return nil, err
+	}
+	pages := make([]string, last-first+1)
+	for pageNum := first; pageNum <= last; pageNum++ {
+		contentReader, err := pdfcpu.ExtractPageContent(pdfContext, pageNum)
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(contentReader)
+		if err != nil {
+			return nil, err
+		}
+		pages[pageNum-first] = tidyExtractedText(contentStreamText(content))
+	}
+	return pages, nil
+}
+
+// contentStreamText returns the text shown by the text operators of a PDF
+// content stream, starting a new line wherever the text position moves down
+func contentStreamText(content []byte) string {
+	var out strings.Builder
+	var operands []any // string, float64, or []any for arrays
+	var arrays [][]any // Arrays being read, innermost last
+
+	push := func(operand any) {
+		if len(arrays) > 0 {
+			arrays[len(arrays)-1] = append(arrays[len(arrays)-1], operand)
+		} else {
+			operands = append(operands, operand)
+		}
+	}
+	number := func(i int) float64 {
+		if i < 0 || i >= len(operands) {
+			return 0
+		}
+		n, _ := operands[i].(float64)
+		return n
+	}
+	lastString := func() string {
+		if len(operands) == 0 {
+			return ""
+		}
+		s, _ := operands[len(operands)-1].(string)
+		return s
+	}
+
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case isPDFWhitespace(c):
+			i++
+		case c == '%':
+			for i < len(content) && content[i] != '\n' && content[i] != '\r' {
+				i++
+			}
+		case c == '(':
+			s, next := readLiteralString(content, i)
+			push(decodePDFString(s))
+			i = next
+		case c == '<' && i+1 < len(content) && content[i+1] == '<':
+			i += 2
+		case c == '>' && i+1 < len(content) && content[i+1] == '>':
+			i += 2
+		case c == '<':
+			end := bytes.IndexByte(content[i:], '>')
+			if end < 0 {
+				end = len(content) - i
+			}
+			push(decodePDFString(decodeHexString(content[i+1 : i+end])))
+			i += end + 1
+		case c == '[':
+			arrays = append(arrays, nil)
+			i++
+		case c == ']':
+			if len(arrays) > 0 {
+				array := arrays[len(arrays)-1]
+				arrays = arrays[:len(arrays)-1]
+				push(array)
+			}
+			i++
+		case c == '/':
+			i++
+			for i < len(content) && !isPDFWhitespace(content[i]) && !isPDFDelimiter(content[i]) {
+				i++
+			}
+			push(nil)
+		default:
+			start := i
+			for i < len(content) && !isPDFWhitespace(content[i]) && !isPDFDelimiter(content[i]) {
+				i++
+			}
+			if i == start {
+				i++ // A stray delimiter
+				continue
+			}
+			token := string(content[start:i])
+			if n, err := strconv.ParseFloat(token, 64); err == nil {
+				push(n)
+				continue
+			}
+
+			switch token {
+			case "Tj":
+				out.WriteString(lastString())
+			case "'", "\"":
+				out.WriteString("\n")
+				out.WriteString(lastString())
+			case "TJ":
+				if len(operands) > 0 {
+					array, _ := operands[len(operands)-1].([]any)
+					for _, element := range array {
+						switch v := element.(type) {
+						case string:
+							out.WriteString(v)
+						case float64:
+							if v < tjSpaceThreshold {
+								out.WriteString(" ")
+							}
+						}
+					}
+				}
+			case "Td", "TD":
+				if number(1) != 0 {
+					out.WriteString("\n")
+				} else if number(0) != 0 {
+					out.WriteString(" ")
+				}
+			case "T*", "Tm", "ET":
+				out.WriteString("\n")
+			case "ID":
+				// Inline image data runs to the EI operator
+				end := bytes.Index(content[i:], []byte("EI"))
+				for end >= 0 && i+end+2 < len(content) && !isPDFWhitespace(content[i+end+2]) {
+					next := bytes.Index(content[i+end+2:], []byte("EI"))
+					if next < 0 {
+						end = -1
+						break
+					}
+					end += 2 + next
+				}
+				if end < 0 {
+					i = len(content)
+				} else {
+					i += end + 2
+				}
+			}
+			operands = operands[:0]
+			arrays = arrays[:0]
+		}
+	}
+	return out.String()
+}
+
+// readLiteralString reads the parenthesized string starting at content[start],
+// resolving escapes, and returns it with the index just past it
+func readLiteralString(content []byte, start int) ([]byte, int) {
+	var s []byte
+	depth := 0
+	i := start
+	for ; i < len(content); i++ {
+		c := content[i]
+		switch c {
+		case '(':
+			depth++
+			if depth == 1 {
+				continue
+			}
+		case ')':
+			depth--
+			if depth == 0 {
+				return s, i + 1
+			}
+		case '\\':
+			i++
+			if i >= len(content) {
+				return s, i
+			}
+			switch e := content[i]; e {
+			case 'n':
+				s = append(s, '\n')
+			case 'r':
+				s = append(s, '\r')
+			case 't':
+				s = append(s, '\t')
+			case 'b':
+				s = append(s, '\b')
+			case 'f':
+				s = append(s, '\f')
+			case '\r':
+				if i+1 < len(content) && content[i+1] == '\n' {
+					i++
+				}
+			case '\n':
+				// A line continuation
+			default:
+				if e >= '0' && e <= '7' {
+					n := 0
+					j := i
+					for ; j < len(content) && j < i+3 && content[j] >= '0' && content[j] <= '7'; j++ {
+						n = n*8 + int(content[j]-'0')
+					}
+					s = append(s, byte(n))
+					i = j - 1
+				} else {
+					s = append(s, e)
+				}
+			}
+			continue
+		}
+		s = append(s, c)
+	}
+	return s, i
+}
+
+// decodeHexString decodes the digits of a <hex> string; an odd final digit is
+// followed by an implied 0
+func decodeHexString(hex []byte) []byte {
+	var digits []byte
+	for _, c := range hex {
+		if !isPDFWhitespace(c) {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	s := make([]byte, 0, len(digits)/2)
+	for i := 0; i+1 < len(digits); i += 2 {
+		b, err := strconv.ParseUint(string(digits[i:i+2]), 16, 8)
+		if err != nil {
+			continue
+		}
+		s = append(s, byte(b))
+	}
+	return s
+}
+
+// decodePDFString decodes a string's bytes as UTF-16 when it starts with a
+// byte order mark and as Latin-1 otherwise, dropping control characters
+func decodePDFString(s []byte) string {
+	var runes []rune
+	if len(s) >= 2 && s[0] == 0xFE && s[1] == 0xFF {
+		units := make([]uint16, 0, len(s)/2)
+		for i := 2; i+1 < len(s); i += 2 {
+			units = append(units, uint16(s[i])<<8|uint16(s[i+1]))
+		}
+		runes = utf16.Decode(units)
+	} else {
+		runes = make([]rune, len(s))
+		for i, b := range s {
+			runes[i] = rune(b)
+		}
+	}
+	var out strings.Builder
+	for _, r := range runes {
+		if r == '\t' || !unicode.IsControl(r) {
+			out.WriteRune(r)
+		}
+	}
+	return out.String()
+}
+
+// tidyExtractedText collapses runs of spaces within lines and drops blank lines
+func tidyExtractedText(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isPDFWhitespace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == 0
+}
+
+func isPDFDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package documents
+
+import (
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestContentStreamText(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"shown string", "BT /F1 12 Tf 72 720 Td (Hello world) Tj ET", "Hello world"},
+		{"kerned array", "BT [(Hel) -20 (lo) -450 (there)] TJ ET", "Hello there"},
+		{"lines moved down", "BT 72 720 Td (First line) Tj 0 -14 Td (Second line) Tj T* (Third) Tj ET", "First line\nSecond line\nThird"},
+		{"next-line operators", "BT (One) Tj (Two) ' 1 0 (Three) \" ET", "One\nTwo\nThree"},
+		{"escapes", `BT (Caf\351 \(1999\)\\ \101) Tj ET`, "Café (1999)\\ A"},
+		{"nested parentheses", "BT (f(x) = y) Tj ET", "f(x) = y"},
+		{"hex and UTF-16", "BT <48 69> Tj <FEFF00E9> Tj ET", "Hié"},
+		{"inline image skipped", "q BI /W 1 /H 1 /CS /G /BPC 8 ID \x00(Tj)EI\x01 EI Q BT (After) Tj ET", "After"},
+		{"no text", "q 612 0 0 792 0 0 cm /Im0 Do Q", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tidyExtractedText(contentStreamText([]byte(tt.content)))
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestExtractPDFPageText(t *testing.T) {
+	pdfBytes := buildTestPdf("BT /F1 12 Tf 72 720 Td (Fold-out table) Tj 0 -14 Td [(Row) -300 (one)] TJ ET")
+	text, err := ExtractPDFPageText(models.DocumentPageData(pdfBytes))
+	if err != nil {
+		t.Fatalf("ExtractPDFPageText failed: %v", err)
+	}
+	if text != "Fold-out table\nRow one" {
+		t.Errorf("Unexpected text: %q", text)
+	}
+
+	if _, err := ExtractPDFPageText(models.DocumentPageData("This is not a PDF")); err == nil {
+		t.Error("Expected error for invalid PDF data, got nil")
+	}
+}
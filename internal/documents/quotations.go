@@ -0,0 +1,281 @@
+package documents
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// QuotationVerificationThreshold is the lowest match score at which a quotation
+// counts as found verbatim in the source text
+const QuotationVerificationThreshold = 0.9
+
+// Scores for aligning quotation words against page words. Matches outweigh
+// edits so that a quotation with a few differing words still aligns in full.
+const (
+	alignMatch    = 2
+	alignMismatch = -1
+	alignGap      = -1
+)
+
+// lineBreakHyphenPattern matches, at the start of the text, the last letter
+// of a word hyphenated across a line break up to its continuation
+var lineBreakHyphenPattern = regexp.MustCompile(`^(\p{L})[-\x{00AD}][ \t]*\r?\n\s*(\p{Ll})`)
+
+// ellipsisPattern matches an ellipsis marking text left out of a quotation
+var ellipsisPattern = regexp.MustCompile(`\[?(?:\.\s?\.\s?\.|…)\]?`)
+
+// VerifyQuotations checks each quotation against the text of the document's
+// pages and returns copies with Verified and MatchScore set. A quotation is
+// looked for on the page it claims first and then on the adjacent pages; if it
+// is only found on an adjacent page, its page number is corrected. Quotations
+// with an unknown page number are looked for on every page. pageNumbers holds
+// the source page numbers corresponding to pages. A verified quotation also
+// gets the byte offsets of the matched text in its page (Span), from its first
+// matched word to its last, and the page's PageContentHash.
+//
+// Matching ignores case, punctuation (including straight and curly quotes),
+// whitespace, and words hyphenated at line breaks. Text omitted with an
+// ellipsis is not counted against the quotation.
+func VerifyQuotations(quotations []models.Quotation, pages []string, pageNumbers []string) []models.Quotation {
+	pageWords := make([][]word, len(pages))
+	pageTokens := make([][]string, len(pages))
+	for i, page := range pages {
+		pageWords[i] = pageWordsOf(page)
+		pageTokens[i] = wordTexts(pageWords[i])
+	}
+	paginated := len(pageNumbers) > 0 && pageNumbers[0] != ""
+
+	verified := make([]models.Quotation, len(quotations))
+	for i, q := range quotations {
+		segments := quotationSegments(q.QuotationText)
+
+		claimed := -1
+		if paginated && q.PageNumber != "" {
+			for j, number := range pageNumbers {
+				if number == q.PageNumber && j < len(pages) {
+					claimed = j
+					break
+				}
+			}
+		}
+
+		var candidates []int
+		if claimed >= 0 {
+			candidates = []int{claimed}
+			if claimed > 0 {
+				candidates = append(candidates, claimed-1)
+			}
+			if claimed < len(pages)-1 {
+				candidates = append(candidates, claimed+1)
+			}
+		} else {
+			for j := range pages {
+				candidates = append(candidates, j)
+			}
+		}
+
+		bestScore, bestPage := 0.0, -1
+		var bestSpan [2]int
+		for _, j := range candidates {
+			score, span := matchScore(segments, pageTokens[j])
+			if score > bestScore {
+				bestScore, bestPage, bestSpan = score, j, span
+			}
+			// The claimed page wins whenever the quotation is found on it
+			if j == claimed && score >= QuotationVerificationThreshold {
+				break
+			}
+		}
+
+		q.MatchScore = math.Round(bestScore*1000) / 1000
+		q.Verified = bestScore >= QuotationVerificationThreshold
+		q.Span, q.PageHash = nil, ""
+		if q.Verified {
+			words := pageWords[bestPage]
+			q.Span = &models.TextSpan{Start: words[bestSpan[0]].start, End: words[bestSpan[1]].end}
+			q.PageHash = PageContentHash(pages[bestPage])
+		}
+		if q.Verified && paginated && bestPage != claimed && pageNumbers[bestPage] != "" {
+			q.PageNumber = pageNumbers[bestPage]
+		}
+		verified[i] = q
+	}
+	return verified
+}
+
+// quotationSegments splits a quotation at ellipses into the word tokens of each
+// quoted segment
+func quotationSegments(text string) [][]string {
+	var segments [][]string
+	for _, part := range ellipsisPattern.Split(text, -1) {
+		if tokens := quotationTokens(part); len(tokens) > 0 {
+			segments = append(segments, tokens)
+		}
+	}
+	return segments
+}
+
+// quotationTokens normalizes text into lowercase words, rejoining words
+// hyphenated at line breaks and dropping punctuation and quote marks
+func quotationTokens(text string) []string {
+	return wordTexts(pageWordsOf(text))
+}
+
+// word is a normalized word of a page and the byte offsets it was read from
+type word struct {
+	text       string
+	start, end int
+}
+
+// pageWordsOf splits text into lowercase words of letters and numbers, with
+// their offsets in text. A soft hyphen inside a word is dropped, and a word
+// hyphenated across a line break (see lineBreakHyphenPattern) is rejoined,
+// spanning the break.
+func pageWordsOf(text string) []word {
+	var words []word
+	var current strings.Builder
+	start := -1
+	flush := func(end int) {
+		if start >= 0 {
+			words = append(words, word{text: strings.ToLower(current.String()), start: start, end: end})
+			current.Reset()
+		}
+		start = -1
+	}
+
+	end := 0
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		switch {
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			if start < 0 {
+				start = i
+			}
+			current.WriteRune(r)
+			i += size
+			end = i
+			continue
+		case (r == '-' || r == '\u00AD') && start >= 0:
+			prev, _ := utf8.DecodeLastRuneInString(text[:i])
+			if unicode.IsLetter(prev) {
+				if loc := lineBreakHyphenPattern.FindStringSubmatchIndex(text[i-len(string(prev)):]); loc != nil {
+					// Continue the word at its lowercase second half
+					i += loc[4] - len(string(prev))
+					continue
+				}
+			}
+			if r == '\u00AD' {
+				i += size
+				continue
+			}
+		case r == '\u00AD':
+			i += size
+			continue
+		}
+		flush(end)
+		i += size
+	}
+	flush(end)
+	return words
+}
+
+// wordTexts returns the text of each word
+func wordTexts(words []word) []string {
+	texts := make([]string, len(words))
+	for i, w := range words {
+		texts[i] = w.text
+	}
+	return texts
+}
+
+// matchScore returns the fraction of the quotation's words found, in order, in
+// page, and the first and last positions in page of the words matched. Each
+// segment is aligned separately, so text omitted with an ellipsis does not
+// lower the score.
+func matchScore(segments [][]string, page []string) (float64, [2]int) {
+	total, matched := 0, 0
+	span := [2]int{-1, -1}
+	for _, segment := range segments {
+		total += len(segment)
+		alignment := alignQuote(segment, page)
+		matched += alignment.matches
+		if alignment.matches > 0 {
+			if span[0] < 0 || alignment.first < span[0] {
+				span[0] = alignment.first
+			}
+			span[1] = max(span[1], alignment.last)
+		}
+	}
+	if total == 0 {
+		return 0, span
+	}
+	return float64(matched) / float64(total), span
+}
+
+// alignment is the best local alignment of a quotation within a page: the
+// number of words matched along it, and the positions in the page of its
+// first and last words, which are matches
+type alignment struct {
+	matches     int
+	first, last int
+}
+
+// alignQuote finds the best local alignment of quote within page
+// (Smith-Waterman)
+func alignQuote(quote, page []string) alignment {
+	if len(quote) == 0 || len(page) == 0 {
+		return alignment{}
+	}
+
+	// Rows are quote positions; only the previous row is kept. score holds the
+	// alignment score ending at each cell, matches the words matched along it,
+	// and first the page position it starts at.
+	prevScore := make([]int, len(page)+1)
+	prevMatches := make([]int, len(page)+1)
+	prevFirst := make([]int, len(page)+1)
+	score := make([]int, len(page)+1)
+	matches := make([]int, len(page)+1)
+	first := make([]int, len(page)+1)
+
+	bestScore := 0
+	var best alignment
+	for i := 1; i <= len(quote); i++ {
+		score[0], matches[0] = 0, 0
+		for j := 1; j <= len(page); j++ {
+			cellScore, cellMatches, cellFirst := 0, 0, 0
+
+			diagonal, diagonalMatches, diagonalFirst := prevScore[j-1]+alignMismatch, prevMatches[j-1], prevFirst[j-1]
+			if quote[i-1] == page[j-1] {
+				diagonal, diagonalMatches = prevScore[j-1]+alignMatch, prevMatches[j-1]+1
+				if prevScore[j-1] == 0 {
+					diagonalFirst = j - 1
+				}
+			}
+			if diagonal > cellScore {
+				cellScore, cellMatches, cellFirst = diagonal, diagonalMatches, diagonalFirst
+			}
+			if up := prevScore[j] + alignGap; up > cellScore {
+				cellScore, cellMatches, cellFirst = up, prevMatches[j], prevFirst[j]
+			}
+			if left := score[j-1] + alignGap; left > cellScore {
+				cellScore, cellMatches, cellFirst = left, matches[j-1], first[j-1]
+			}
+
+			score[j], matches[j], first[j] = cellScore, cellMatches, cellFirst
+			if cellScore > bestScore {
+				// A new best score is only reached on a match, which ends the alignment
+				bestScore = cellScore
+				best = alignment{matches: cellMatches, first: cellFirst, last: j - 1}
+			}
+		}
+		prevScore, score = score, prevScore
+		prevMatches, matches = matches, prevMatches
+		prevFirst, first = first, prevFirst
+	}
+	return best
+}
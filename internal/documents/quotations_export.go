@@ -0,0 +1,87 @@
+package documents
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// QuotationSet is the stored quotations of one document, with the metadata
+// that identifies them in an export
+type QuotationSet struct {
+	DocumentID string
+	Metadata   models.ItemMetadata
+	Quotations []models.Quotation
+}
+
+// citeKey returns the key quotations are cited by: the citekey, or the
+// document ID if the document has none
+func (s QuotationSet) citeKey() string {
+	if s.Metadata.Citekey != "" {
+		return s.Metadata.Citekey
+	}
+	return s.DocumentID
+}
+
+// ExportQuotationsMarkdown renders quotations as Markdown for note-taking
+// tools: a heading per document with its title and authors, then each
+// quotation as a blockquote ending in "(citekey, p. N)", followed by its
+// context and relevance
+func ExportQuotationsMarkdown(sets []QuotationSet) string {
+	var b strings.Builder
+	for _, set := range sets {
+		heading := strings.TrimSpace(set.Metadata.Title)
+		if heading == "" {
+			heading = set.citeKey()
+		}
+		if len(set.Metadata.Authors) > 0 {
+			heading += " — " + strings.Join(set.Metadata.Authors, "; ")
+		}
+		fmt.Fprintf(&b, "## %s\n\n", heading)
+
+		for _, quotation := range set.Quotations {
+			cite := set.citeKey()
+			if page := strings.TrimSpace(quotation.PageNumber); page != "" {
+				cite += ", p. " + page
+			}
+			lines := strings.Split(strings.TrimSpace(quotation.QuotationText), "\n")
+			lines[len(lines)-1] += fmt.Sprintf(" (%s)", cite)
+			for _, line := range lines {
+				fmt.Fprintf(&b, "> %s\n", strings.TrimSpace(line))
+			}
+			b.WriteString("\n")
+			if context := strings.TrimSpace(quotation.Context); context != "" {
+				fmt.Fprintf(&b, "Context: %s\n\n", context)
+			}
+			if relevance := strings.TrimSpace(quotation.Relevance); relevance != "" {
+				fmt.Fprintf(&b, "Relevance: %s\n\n", relevance)
+			}
+		}
+	}
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+// ExportQuotationsCSV renders quotations as CSV with a header row and the
+// columns citekey, page, quotation, context, and relevance
+func ExportQuotationsCSV(sets []QuotationSet) (string, error) {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	if err := w.Write([]string{"citekey", "page", "quotation", "context", "relevance"}); err != nil {
+		return "", err
+	}
+	for _, set := range sets {
+		for _, quotation := range set.Quotations {
+			record := []string{set.citeKey(), quotation.PageNumber, quotation.QuotationText, quotation.Context, quotation.Relevance}
+			if err := w.Write(record); err != nil {
+				return "", err
+			}
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,220 @@
+package documents
+
+import (
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestVerifyQuotations(t *testing.T) {
+	pages := []string{
+		"Memory is not a passive store. It is an active, reconstructive process shaped by the present.",
+		"The archive, as Foucault argued, is “the law of what can be said.” Scholars who treat it as a neutral repository miss the experi-\nmental conditions of its making.",
+		"We conclude that institutional memory is continually renegotiated by those who maintain the records.",
+	}
+	pageNumbers := []string{"10", "11", "12"}
+
+	tests := []struct {
+		name         string
+		quotation    models.Quotation
+		wantVerified bool
+		wantPage     string
+		minScore     float64
+		maxScore     float64
+	}{
+		{
+			name:         "exact quotation",
+			quotation:    models.Quotation{QuotationText: "Memory is not a passive store.", PageNumber: "10"},
+			wantVerified: true,
+			wantPage:     "10",
+			minScore:     1,
+			maxScore:     1,
+		},
+		{
+			name:         "wrapped in quotes with different case and spacing",
+			quotation:    models.Quotation{QuotationText: "\"it is an ACTIVE,  reconstructive\n process\"", PageNumber: "10"},
+			wantVerified: true,
+			wantPage:     "10",
+			minScore:     1,
+			maxScore:     1,
+		},
+		{
+			name:         "curly quotes in source, straight in quotation",
+			quotation:    models.Quotation{QuotationText: `as Foucault argued, is "the law of what can be said."`, PageNumber: "11"},
+			wantVerified: true,
+			wantPage:     "11",
+			minScore:     1,
+			maxScore:     1,
+		},
+		{
+			name:         "hyphenation at a line break",
+			quotation:    models.Quotation{QuotationText: "miss the experimental conditions of its making", PageNumber: "11"},
+			wantVerified: true,
+			wantPage:     "11",
+			minScore:     1,
+			maxScore:     1,
+		},
+		{
+			name:         "ellipsis omits text",
+			quotation:    models.Quotation{QuotationText: "Scholars who treat it … miss the experimental conditions of its making.", PageNumber: "11"},
+			wantVerified: true,
+			wantPage:     "11",
+			minScore:     1,
+			maxScore:     1,
+		},
+		{
+			name:         "found on an adjacent page",
+			quotation:    models.Quotation{QuotationText: "institutional memory is continually renegotiated", PageNumber: "11"},
+			wantVerified: true,
+			wantPage:     "12",
+			minScore:     1,
+			maxScore:     1,
+		},
+		{
+			name:         "one word differs in a long quotation",
+			quotation:    models.Quotation{QuotationText: "We conclude that institutional memory is constantly renegotiated by those who maintain the records.", PageNumber: "12"},
+			wantVerified: true,
+			wantPage:     "12",
+			minScore:     0.9,
+			maxScore:     0.99,
+		},
+		{
+			name:         "paraphrase",
+			quotation:    models.Quotation{QuotationText: "Memory is an active process of reconstruction rather than passive storage.", PageNumber: "10"},
+			wantVerified: false,
+			wantPage:     "10",
+			minScore:     0,
+			maxScore:     0.89,
+		},
+		{
+			name:         "not on the claimed or adjacent pages",
+			quotation:    models.Quotation{QuotationText: "Memory is not a passive store.", PageNumber: "12"},
+			wantVerified: false,
+			wantPage:     "12",
+			minScore:     0,
+			maxScore:     0.89,
+		},
+		{
+			name:         "unknown page number searches every page",
+			quotation:    models.Quotation{QuotationText: "maintain the records", PageNumber: "99"},
+			wantVerified: true,
+			wantPage:     "12",
+			minScore:     1,
+			maxScore:     1,
+		},
+		{
+			name:         "empty quotation",
+			quotation:    models.Quotation{QuotationText: "\"…\"", PageNumber: "10"},
+			wantVerified: false,
+			wantPage:     "10",
+			minScore:     0,
+			maxScore:     0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := VerifyQuotations([]models.Quotation{tt.quotation}, pages, pageNumbers)[0]
+			if got.Verified != tt.wantVerified {
+				t.Errorf("Expected verified=%v, got %v (score %.3f)", tt.wantVerified, got.Verified, got.MatchScore)
+			}
+			if got.PageNumber != tt.wantPage {
+				t.Errorf("Expected page %q, got %q", tt.wantPage, got.PageNumber)
+			}
+			if got.MatchScore < tt.minScore || got.MatchScore > tt.maxScore {
+				t.Errorf("Expected score in [%.2f, %.2f], got %.3f", tt.minScore, tt.maxScore, got.MatchScore)
+			}
+			if got.QuotationText != tt.quotation.QuotationText {
+				t.Errorf("Quotation text should not change, got %q", got.QuotationText)
+			}
+		})
+	}
+}
+
+func TestVerifyQuotations_NonPaginated(t *testing.T) {
+	pages := []string{"A single block of text without page numbers, as parsed from HTML."}
+	quotations := []models.Quotation{
+		{QuotationText: "without page numbers"},
+		{QuotationText: "entirely invented sentence"},
+	}
+
+	got := VerifyQuotations(quotations, pages, []string{""})
+	if !got[0].Verified || got[0].PageNumber != "" {
+		t.Errorf("Expected first quotation verified without a page number, got %+v", got[0])
+	}
+	if got[1].Verified {
+		t.Errorf("Expected invented quotation to be unverified, got %+v", got[1])
+	}
+	if quotations[0].Verified {
+		t.Error("VerifyQuotations should not modify its input")
+	}
+}
+
+func TestVerifyQuotations_Span(t *testing.T) {
+	pages := []string{
+		"Memory is not a passive store. It is an active, reconstructive process.",
+		"Scholars who treat the archive as neutral miss the experi-\nmental conditions of its making.",
+	}
+	pageNumbers := []string{"10", "11"}
+
+	tests := []struct {
+		name      string
+		quotation models.Quotation
+		wantPage  int
+		wantText  string
+	}{
+		{"exact", models.Quotation{QuotationText: "\"It is an active, reconstructive process.\"", PageNumber: "10"}, 0, "It is an active, reconstructive process"},
+		{"hyphenated at a line break", models.Quotation{QuotationText: "the experimental conditions", PageNumber: "11"}, 1, "the experi-\nmental conditions"},
+		{"ellipsis spans the omitted text", models.Quotation{QuotationText: "Scholars who … miss the experimental", PageNumber: "11"}, 1, "Scholars who treat the archive as neutral miss the experi-\nmental"},
+		{"found on the adjacent page", models.Quotation{QuotationText: "Memory is not a passive store", PageNumber: "11"}, 0, "Memory is not a passive store"},
+		{"one differing word", models.Quotation{QuotationText: "Scholars who treat the archive as neutral miss the practical conditions of its making", PageNumber: "11"}, 1, "Scholars who treat the archive as neutral miss the experi-\nmental conditions of its making"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := VerifyQuotations([]models.Quotation{tt.quotation}, pages, pageNumbers)[0]
+			if !got.Verified || got.Span == nil {
+				t.Fatalf("Expected a verified quotation with a span, got %+v", got)
+			}
+			if text := pages[tt.wantPage][got.Span.Start:got.Span.End]; text != tt.wantText {
+				t.Errorf("Expected span %q, got %q", tt.wantText, text)
+			}
+			if got.PageHash != PageContentHash(pages[tt.wantPage]) {
+				t.Errorf("Expected the hash of page %d, got %s", tt.wantPage, got.PageHash)
+			}
+		})
+	}
+
+	// An unverified quotation has no span, even if it had one before
+	stale := models.Quotation{QuotationText: "entirely invented sentence", PageNumber: "10", Span: &models.TextSpan{Start: 0, End: 6}, PageHash: "old"}
+	if got := VerifyQuotations([]models.Quotation{stale}, pages, pageNumbers)[0]; got.Verified || got.Span != nil || got.PageHash != "" {
+		t.Errorf("Expected no span for an unverified quotation, got %+v", got)
+	}
+}
+
+func TestQuotationTokens(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"“Don’t  panic,” she said.", []string{"don", "t", "panic", "she", "said"}},
+		{"recon-\nstructive", []string{"reconstructive"}},
+		{"self-\nRegulation", []string{"self", "regulation"}},
+		{"well-known", []string{"well", "known"}},
+		{"in\u00adformation", []string{"information"}},
+		{"*emphasis* and _more_", []string{"emphasis", "and", "more"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := quotationTokens(tt.input)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("Expected %q, got %q", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("Expected %q, got %q", tt.expected, got)
+				}
+			}
+		})
+	}
+}
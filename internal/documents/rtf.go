@@ -0,0 +1,202 @@
+package documents
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// rtfSignature starts every RTF document
+var rtfSignature = []byte(`{\rtf`)
+
+// rtfSkippedDestinations are groups whose text is not part of the document body:
+// tables of fonts, colors, and styles, document properties, pictures, headers
+// and footers, and field instructions (the field result is kept)
+var rtfSkippedDestinations = map[string]bool{
+	"fonttbl": true, "colortbl": true, "stylesheet": true, "info": true, "pict": true,
+	"header": true, "headerl": true, "headerr": true, "headerf": true,
+	"footer": true, "footerl": true, "footerr": true, "footerf": true,
+	"object": true, "fldinst": true, "listtable": true, "listoverridetable": true,
+	"rsidtbl": true, "revtbl": true, "filetbl": true, "pgdsctbl": true, "xmlnstbl": true,
+	"themedata": true, "colorschememapping": true, "datastore": true, "latentstyles": true,
+	"generator": true, "bkmkstart": true, "bkmkend": true, "footnote": true,
+}
+
+// rtfControlText is the text of control words that stand for characters
+var rtfControlText = map[string]string{
+	"par": "\n\n", "sect": "\n\n", "page": "\n\n", "line": "\n", "row": "\n",
+	"tab": "\t", "cell": "\t",
+	"emdash": "—", "endash": "–", "bullet": "•",
+	"lquote": "‘", "rquote": "’", "ldblquote": "“", "rdblquote": "”",
+	"emspace": " ", "enspace": " ", "qmspace": " ",
+}
+
+// rtfCodePages are the single-byte code pages \ansicpg may name for \'hh escapes
+var rtfCodePages = map[int]*charmap.Charmap{
+	437: charmap.CodePage437, 850: charmap.CodePage850,
+	1250: charmap.Windows1250, 1251: charmap.Windows1251, 1252: charmap.Windows1252,
+	1253: charmap.Windows1253, 1254: charmap.Windows1254, 1255: charmap.Windows1255,
+	1256: charmap.Windows1256, 1257: charmap.Windows1257, 1258: charmap.Windows1258,
+	10000: charmap.Macintosh,
+}
+
+// rtfBlankLines matches runs of blank lines left by empty paragraphs
+var rtfBlankLines = regexp.MustCompile(`\n[ \t]*\n(?:[ \t]*\n)+`)
+
+// rtfGroup is the state a group inherits from its parent
+type rtfGroup struct {
+	skip bool // Inside a destination whose text is dropped
+	uc   int  // Fallback characters that follow each \u escape
+}
+
+// IsRTF reports whether data is a Rich Text Format document
+func IsRTF(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n\ufeff"), rtfSignature)
+}
+
+// RTFToText extracts the body text of an RTF document, with paragraphs separated
+// by blank lines. Formatting is dropped, as are fonts, styles, document
+// properties, pictures, headers, footers, and footnotes. Unicode escapes and
+// characters in the document's code page (Windows-1252 unless \ansicpg names
+// another) are decoded.
+func RTFToText(data []byte) (string, error) {
+	if !IsRTF(data) {
+		return "", errors.New("not an RTF document")
+	}
+
+	var out strings.Builder
+	codePage := charmap.Windows1252
+	state := rtfGroup{uc: 1}
+	var stack []rtfGroup
+	skipFallback := 0 // Fallback characters of a \u escape still to skip
+
+	emit := func(s string) {
+		if !state.skip {
+			out.WriteString(s)
+		}
+	}
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch c {
+		case '{':
+			stack = append(stack, state)
+			skipFallback = 0
+		case '}':
+			if len(stack) == 0 {
+				continue
+			}
+			state = stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			skipFallback = 0
+		case '\r', '\n':
+			// Line breaks in RTF source are not part of the text
+		case '\\':
+			if i+1 >= len(data) {
+				continue
+			}
+			next := data[i+1]
+			if !isASCIILetter(next) {
+				i++
+				switch next {
+				case '\\', '{', '}':
+					if skipFallback > 0 {
+						skipFallback--
+					} else {
+						emit(string(next))
+					}
+				case '\'':
+					if i+2 < len(data) {
+						if b, err := strconv.ParseUint(string(data[i+1:i+3]), 16, 8); err == nil {
+							if skipFallback > 0 {
+								skipFallback--
+							} else {
+								emit(string(codePage.DecodeByte(byte(b))))
+							}
+						}
+						i += 2
+					}
+				case '~':
+					emit(" ")
+				case '_':
+					emit("-")
+				case '*':
+					// An ignorable destination: its text is dropped unless understood,
+					// and none of those are
+					state.skip = true
+				case '\r', '\n':
+					emit("\n\n")
+				}
+				continue
+			}
+
+			// Control word: letters, an optional signed number, and an optional
+			// space delimiter
+			start := i + 1
+			end := start
+			for end < len(data) && isASCIILetter(data[end]) {
+				end++
+			}
+			word := string(data[start:end])
+			numStart := end
+			if end < len(data) && data[end] == '-' {
+				end++
+			}
+			for end < len(data) && data[end] >= '0' && data[end] <= '9' {
+				end++
+			}
+			param, hasParam := 0, false
+			if end > numStart {
+				if n, err := strconv.Atoi(string(data[numStart:end])); err == nil {
+					param, hasParam = n, true
+				}
+			}
+			if end < len(data) && data[end] == ' ' {
+				end++
+			}
+			i = end - 1
+
+			switch {
+			case rtfSkippedDestinations[word]:
+				state.skip = true
+			case word == "ansicpg" && hasParam:
+				if cm, ok := rtfCodePages[param]; ok {
+					codePage = cm
+				}
+			case word == "mac":
+				codePage = charmap.Macintosh
+			case word == "uc" && hasParam:
+				state.uc = param
+			case word == "u" && hasParam:
+				if param < 0 {
+					param += 65536
+				}
+				emit(string(rune(param)))
+				skipFallback = state.uc
+			case word == "bin" && hasParam:
+				i += param
+			default:
+				if text, ok := rtfControlText[word]; ok {
+					emit(text)
+				}
+			}
+		default:
+			if skipFallback > 0 {
+				skipFallback--
+				continue
+			}
+			emit(string(codePage.DecodeByte(c)))
+		}
+	}
+
+	text := rtfBlankLines.ReplaceAllString(out.String(), "\n\n")
+	return strings.TrimSpace(text), nil
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
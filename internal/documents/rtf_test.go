@@ -0,0 +1,83 @@
+package documents
+
+import "testing"
+
+func TestRTFToText(t *testing.T) {
+	tests := []struct {
+		name     string
+		rtf      string
+		expected string
+	}{
+		{
+			name: "paragraphs with formatting",
+			rtf: `{\rtf1\ansi\ansicpg1252\deff0{\fonttbl{\f0\froman Times New Roman;}}{\colortbl;\red0\green0\blue0;}
+{\*\generator Riched20 10.0;}\viewkind4\uc1\pard\f0\fs24 Memory and the \b Archive\b0\par
+The archive does not \i speak\i0  for itself.\par
+}`,
+			expected: "Memory and the Archive\n\nThe archive does not speak for itself.",
+		},
+		{
+			name:     "document properties are dropped",
+			rtf:      `{\rtf1\ansi{\info{\title Draft}{\author Jane Smith}}Body text.\par}`,
+			expected: "Body text.",
+		},
+		{
+			name:     "code page escapes",
+			rtf:      `{\rtf1\ansi\ansicpg1252 Caf\'e9 \'93quoted\'94\par}`,
+			expected: "Café “quoted”",
+		},
+		{
+			name:     "other code page",
+			rtf:      `{\rtf1\ansi\ansicpg1251 \'cf\'f0\'e8\'e2\'e5\'f2\par}`,
+			expected: "Привет",
+		},
+		{
+			name:     "unicode escapes skip their fallback",
+			rtf:      `{\rtf1\ansi\uc1 Stra\u223?e and \u8212\'97 \uc2\u20013\'a4\'a4 end\par}`,
+			expected: "Straße and — 中 end",
+		},
+		{
+			name:     "negative unicode escape",
+			rtf:      `{\rtf1\ansi\uc1 \u-3913?\par}`,
+			expected: "\uf0b7",
+		},
+		{
+			name:     "escaped braces and backslashes",
+			rtf:      `{\rtf1\ansi Sets \{a, b\} and C:\\temp\par}`,
+			expected: "Sets {a, b} and C:\\temp",
+		},
+		{
+			name:     "ignorable destinations and fields",
+			rtf:      `{\rtf1\ansi See {\field{\*\fldinst HYPERLINK "https://example.com"}{\fldrslt the site}}{\*\unknowndest hidden} now.\par}`,
+			expected: "See the site now.",
+		},
+		{
+			name:     "headers, footers, and pictures",
+			rtf:      `{\rtf1\ansi{\header Running head}{\footer Page 1}{\pict\pngblip 89504e47}Main text\line second line\tab tabbed\par\par\par End\par}`,
+			expected: "Main text\nsecond line\ttabbed\n\nEnd",
+		},
+		{
+			name:     "special characters",
+			rtf:      `{\rtf1\ansi 1990\endash 2000\emdash a\~b \lquote x\rquote  \ldblquote y\rdblquote\par}`,
+			expected: "1990–2000—a b ‘x’ “y”",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RTFToText([]byte(tt.rtf))
+			if err != nil {
+				t.Fatalf("RTFToText failed: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("RTFToText() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRTFToText_NotRTF(t *testing.T) {
+	if _, err := RTFToText([]byte("Plain text")); err == nil {
+		t.Error("Expected an error for data that is not RTF")
+	}
+}
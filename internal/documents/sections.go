@@ -0,0 +1,212 @@
+package documents
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// pageSeparator joins page contents into the document text that section offsets refer to
+const pageSeparator = "\n\n"
+
+// maxHeadingContinuationLength is the longest line accepted as the rest of a
+// heading that was split across a page break
+const maxHeadingContinuationLength = 80
+
+// headingPattern matches an ATX markdown heading, capturing its level and title
+// without any closing hashes
+var headingPattern = regexp.MustCompile(`^(#{1,6})[ \t]+(.+?)(?:[ \t]+#+)?[ \t]*$`)
+
+// headingConnectives are words that cannot end a complete heading, so a heading
+// ending with one at the bottom of a page continues on the next
+var headingConnectives = map[string]bool{
+	"a": true, "an": true, "and": true, "as": true, "at": true, "by": true, "for": true,
+	"from": true, "in": true, "into": true, "of": true, "on": true, "or": true,
+	"the": true, "to": true, "via": true, "with": true,
+}
+
+// JoinPages returns the text of a document's pages separated by blank lines.
+// Section offsets from ExtractSections are byte offsets into this text.
+func JoinPages(pages []string) string {
+	return strings.Join(pages, pageSeparator)
+}
+
+// heading is a markdown heading found in the document text
+type heading struct {
+	level  int
+	title  string
+	offset int // byte offset of the heading line in the joined text
+}
+
+// ExtractSections builds a section index from the markdown headings in a
+// document's pages. Each section runs from its heading to the next heading of
+// the same or a higher level, so a section includes its subsections. A heading
+// cut off at the bottom of a page is joined with its continuation at the top of
+// the next page. pageNumbers holds the source page numbers corresponding to
+// pages; missing entries fall back to sequential numbers.
+func ExtractSections(pages []string, pageNumbers []string) []models.Section {
+	text := JoinPages(pages)
+	pageStarts := make([]int, len(pages))
+	for i := 1; i < len(pages); i++ {
+		pageStarts[i] = pageStarts[i-1] + len(pages[i-1]) + len(pageSeparator)
+	}
+
+	var headings []heading
+	inFence := false
+	endsWithHeading := false // the last non-blank line seen was a heading
+	for i, page := range pages {
+		continuable := endsWithHeading
+		lineStart := 0
+		for _, line := range strings.SplitAfter(page, "\n") {
+			lineOffset := pageStarts[i] + lineStart
+			lineStart += len(line)
+			trimmed := strings.TrimSpace(line)
+			if trimmed == "" {
+				continue
+			}
+			firstLine := continuable
+			continuable = false
+			endsWithHeading = false
+
+			if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+				inFence = !inFence
+				continue
+			}
+			if inFence {
+				continue
+			}
+
+			match := headingPattern.FindStringSubmatch(trimmed)
+			if firstLine {
+				// A split heading may continue as plain text or be repeated at the same level
+				last := &headings[len(headings)-1]
+				rest := trimmed
+				if match != nil {
+					rest = match[2]
+				}
+				rest = cleanHeadingTitle(rest)
+				if (match == nil || len(match[1]) == last.level) && isHeadingContinuation(last.title, rest) {
+					last.title = joinHeadingTitle(last.title, rest)
+					endsWithHeading = true
+					continue
+				}
+			}
+
+			if match != nil {
+				headings = append(headings, heading{
+					level:  len(match[1]),
+					title:  cleanHeadingTitle(match[2]),
+					offset: lineOffset,
+				})
+				endsWithHeading = true
+			}
+		}
+	}
+
+	pageAt := func(offset int) int {
+		return sort.Search(len(pageStarts), func(i int) bool { return pageStarts[i] > offset }) - 1
+	}
+	pageNumber := func(index int) string {
+		if index < len(pageNumbers) && pageNumbers[index] != "" {
+			return pageNumbers[index]
+		}
+		return strconv.Itoa(index + 1)
+	}
+
+	sections := make([]models.Section, 0, len(headings))
+	for i, h := range headings {
+		end := len(text)
+		for _, next := range headings[i+1:] {
+			if next.level <= h.level {
+				end = next.offset
+				break
+			}
+		}
+		// The end page holds the section's last non-blank character
+		last := max(len(strings.TrimRight(text[:end], " \t\r\n"))-1, h.offset)
+		startPage, endPage := pageAt(h.offset), pageAt(last)
+		sections = append(sections, models.Section{
+			Title:          h.title,
+			Level:          h.level,
+			StartPage:      pageNumber(startPage),
+			EndPage:        pageNumber(endPage),
+			StartPageIndex: startPage + 1,
+			EndPageIndex:   endPage + 1,
+			StartOffset:    h.offset,
+			EndOffset:      end,
+		})
+	}
+	return sections
+}
+
+// TopLevelSections returns the indexes of a document's top-level sections:
+// those at the shallowest heading level with at least two sections, so that a
+// title heading over the whole text does not make it a single section. With no
+// such level, the sections at the shallowest level are returned.
+func TopLevelSections(sections []models.Section) []int {
+	counts := make(map[int]int)
+	for _, section := range sections {
+		counts[section.Level]++
+	}
+	levels := make([]int, 0, len(counts))
+	for level := range counts {
+		levels = append(levels, level)
+	}
+	sort.Ints(levels)
+	if len(levels) == 0 {
+		return nil
+	}
+
+	top := levels[0]
+	for _, level := range levels {
+		if counts[level] >= 2 {
+			top = level
+			break
+		}
+	}
+	var indexes []int
+	for i, section := range sections {
+		if section.Level == top {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// cleanHeadingTitle removes emphasis markers wrapped around a heading title
+func cleanHeadingTitle(title string) string {
+	return strings.TrimSpace(strings.Trim(title, "*_"))
+}
+
+// isHeadingContinuation reports whether next, the first line of a page, is the
+// rest of title, a heading that ended the previous page
+func isHeadingContinuation(title, next string) bool {
+	if next == "" || len(next) > maxHeadingContinuationLength || strings.ContainsAny(next[len(next)-1:], ".!?") {
+		return false
+	}
+	if strings.HasSuffix(title, "-") {
+		return true
+	}
+	words := strings.Fields(title)
+	if len(words) > 0 && headingConnectives[strings.ToLower(words[len(words)-1])] {
+		return true
+	}
+	first := []rune(next)[0]
+	return unicode.IsLower(first)
+}
+
+// joinHeadingTitle joins the two halves of a heading split across pages. A word
+// hyphenated at the break is rejoined; a hyphen before a capital is kept (e.g., "Self-Regulation").
+func joinHeadingTitle(title, rest string) string {
+	if stem, ok := strings.CutSuffix(title, "-"); ok {
+		if unicode.IsLower([]rune(rest)[0]) {
+			return stem + rest
+		}
+		return title + rest
+	}
+	return title + " " + rest
+}
@@ -0,0 +1,151 @@
+package documents
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestExtractSections(t *testing.T) {
+	pages := []string{
+		"# A Study of Things\n\nAbstract text.\n\n## Introduction\n\nWe introduce things.",
+		"More introduction.\n\n## Methods\n\nWe did things.\n\n### Sampling\n\nWe sampled.",
+		"## Results\n\n```\n# not a heading\n```\n\nThings happened.",
+	}
+	pageNumbers := []string{"10", "11", "12"}
+
+	sections := ExtractSections(pages, pageNumbers)
+
+	expected := []models.Section{
+		{Title: "A Study of Things", Level: 1, StartPage: "10", EndPage: "12", StartPageIndex: 1, EndPageIndex: 3},
+		{Title: "Introduction", Level: 2, StartPage: "10", EndPage: "11", StartPageIndex: 1, EndPageIndex: 2},
+		{Title: "Methods", Level: 2, StartPage: "11", EndPage: "11", StartPageIndex: 2, EndPageIndex: 2},
+		{Title: "Sampling", Level: 3, StartPage: "11", EndPage: "11", StartPageIndex: 2, EndPageIndex: 2},
+		{Title: "Results", Level: 2, StartPage: "12", EndPage: "12", StartPageIndex: 3, EndPageIndex: 3},
+	}
+	if len(sections) != len(expected) {
+		t.Fatalf("Expected %d sections, got %d: %+v", len(expected), len(sections), sections)
+	}
+	for i, want := range expected {
+		got := sections[i]
+		got.StartOffset, got.EndOffset = 0, 0
+		if got != want {
+			t.Errorf("Section %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+
+	text := JoinPages(pages)
+	sectionText := func(s models.Section) string { return text[s.StartOffset:s.EndOffset] }
+
+	if got := sectionText(sections[1]); !strings.HasPrefix(got, "## Introduction") || !strings.Contains(got, "More introduction.") || strings.Contains(got, "Methods") {
+		t.Errorf("Introduction should span the page break and stop at Methods, got %q", got)
+	}
+	if got := sectionText(sections[2]); !strings.Contains(got, "### Sampling") || strings.Contains(got, "Results") {
+		t.Errorf("Methods should include its subsection and stop at Results, got %q", got)
+	}
+	if got := sectionText(sections[4]); !strings.HasSuffix(got, "Things happened.") {
+		t.Errorf("Last section should run to the end of the document, got %q", got)
+	}
+	if sections[0].StartOffset != 0 || sections[0].EndOffset != len(text) {
+		t.Errorf("Top-level section should cover the whole text, got %d-%d of %d", sections[0].StartOffset, sections[0].EndOffset, len(text))
+	}
+}
+
+func TestExtractSections_SplitHeadings(t *testing.T) {
+	tests := []struct {
+		name     string
+		pages    []string
+		expected []string
+	}{
+		{
+			name:     "continuation as plain text",
+			pages:    []string{"Body text.\n\n## Results and", "Discussion\n\nWe discuss."},
+			expected: []string{"Results and Discussion"},
+		},
+		{
+			name:     "heading repeated at same level",
+			pages:    []string{"Body text.\n\n## Theoretical Framework for the", "## Analysis of Texts\n\nBody."},
+			expected: []string{"Theoretical Framework for the Analysis of Texts"},
+		},
+		{
+			name:     "hyphenated word",
+			pages:    []string{"## Experi-", "mental Design\n\nBody."},
+			expected: []string{"Experimental Design"},
+		},
+		{
+			name:     "lowercase continuation",
+			pages:    []string{"## The Role of Memory", "in early modern Europe\n\nBody."},
+			expected: []string{"The Role of Memory in early modern Europe"},
+		},
+		{
+			name:     "complete heading followed by body text",
+			pages:    []string{"## Methods", "We collected data from participants.\n\nMore."},
+			expected: []string{"Methods"},
+		},
+		{
+			name:     "consecutive headings at a page break",
+			pages:    []string{"Body.\n\n## Results", "## Discussion\n\nBody."},
+			expected: []string{"Results", "Discussion"},
+		},
+		{
+			name:     "heading not at end of page",
+			pages:    []string{"## Results and\n\nBody text.", "discussion continues here"},
+			expected: []string{"Results and"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sections := ExtractSections(tt.pages, nil)
+			var titles []string
+			for _, s := range sections {
+				titles = append(titles, s.Title)
+			}
+			if strings.Join(titles, "|") != strings.Join(tt.expected, "|") {
+				t.Errorf("Expected titles %q, got %q", tt.expected, titles)
+			}
+		})
+	}
+}
+
+func TestExtractSections_NoHeadings(t *testing.T) {
+	if sections := ExtractSections([]string{"Plain text only.", "More text."}, nil); len(sections) != 0 {
+		t.Errorf("Expected no sections, got %+v", sections)
+	}
+	if sections := ExtractSections(nil, nil); len(sections) != 0 {
+		t.Errorf("Expected no sections for an empty document, got %+v", sections)
+	}
+}
+
+func TestExtractSections_DefaultPageNumbers(t *testing.T) {
+	sections := ExtractSections([]string{"Intro", "## Methods\n\nText"}, []string{"", ""})
+	if len(sections) != 1 || sections[0].StartPage != "2" || sections[0].EndPage != "2" {
+		t.Errorf("Expected a section on sequential page 2, got %+v", sections)
+	}
+}
+
+func TestTopLevelSections(t *testing.T) {
+	tests := []struct {
+		name     string
+		levels   []int
+		expected []int
+	}{
+		{"title over sections", []int{1, 2, 2, 3, 2}, []int{1, 2, 4}},
+		{"chapters", []int{1, 2, 1, 2}, []int{0, 2}},
+		{"single section", []int{2, 3}, []int{0}},
+		{"no sections", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sections := make([]models.Section, len(tt.levels))
+			for i, level := range tt.levels {
+				sections[i].Level = level
+			}
+			if got := TopLevelSections(sections); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package documents
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// sentenceAbbreviations are words that end in a period without ending a
+// sentence, lowercased and without the period
+var sentenceAbbreviations = map[string]bool{
+	"al": true, "approx": true, "c": true, "ca": true, "cf": true, "ch": true, "chap": true, "dr": true,
+	"ed": true, "eds": true, "eg": true, "esp": true, "etc": true, "fig": true, "figs": true, "ibid": true,
+	"ie": true, "jr": true, "mr": true, "mrs": true, "ms": true, "no": true, "nos": true, "op": true,
+	"p": true, "pp": true, "prof": true, "sr": true, "st": true, "trans": true, "viz": true, "vol": true,
+	"vols": true, "vs": true,
+}
+
+// sentenceClosers may follow a sentence's final punctuation and belong to it
+const sentenceClosers = "\"'”’)]*_"
+
+// PageContentHash returns the SHA-256 of a page's stored content, in hex.
+// Offsets into the page are only valid for the content with this hash.
+func PageContentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// SegmentSentences splits a page's markdown into sentences, returning the byte
+// offsets of each, without surrounding whitespace. Headings, table rows, and
+// list items are segments of their own, and blank lines end a sentence. A
+// period, question mark, or exclamation mark (with any closing quotes or
+// brackets) ends a sentence when whitespace and a capital letter, digit, or
+// opening quote or bracket follow, unless it ends a known abbreviation or an
+// initial. The result depends only on the content.
+func SegmentSentences(content string) []models.TextSpan {
+	var spans []models.TextSpan
+	start := -1
+	flush := func(end int) {
+		if start >= 0 {
+			if span, ok := trimmedSpan(content, start, end); ok {
+				spans = append(spans, span)
+			}
+		}
+		start = -1
+	}
+
+	for lineStart := 0; lineStart < len(content); {
+		lineEnd := strings.IndexByte(content[lineStart:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(content)
+		} else {
+			lineEnd += lineStart
+		}
+		line := strings.TrimSpace(content[lineStart:lineEnd])
+
+		switch {
+		case line == "":
+			flush(lineStart)
+		case isStandaloneLine(line):
+			flush(lineStart)
+			start = lineStart
+			flush(lineEnd)
+		default:
+			if isListItem(line) {
+				flush(lineStart)
+			}
+			for i := lineStart; i < lineEnd; {
+				r, size := utf8.DecodeRuneInString(content[i:])
+				if start < 0 && !unicode.IsSpace(r) {
+					start = i
+				}
+				i += size
+				if r == '.' || r == '?' || r == '!' || r == '…' {
+					end := i + len(content[i:lineEnd]) - len(strings.TrimLeft(content[i:lineEnd], sentenceClosers))
+					if endsSentence(content, start, i-size, r, end) {
+						flush(end)
+						i = end
+					}
+				}
+			}
+		}
+		lineStart = lineEnd + 1
+	}
+	flush(len(content))
+	return spans
+}
+
+// PageSentences segments each page into sentences with SegmentSentences
+func PageSentences(pages []string) [][]models.TextSpan {
+	sentences := make([][]models.TextSpan, len(pages))
+	for i, page := range pages {
+		sentences[i] = SegmentSentences(page)
+		if sentences[i] == nil {
+			sentences[i] = []models.TextSpan{}
+		}
+	}
+	return sentences
+}
+
+// isStandaloneLine reports whether a markdown line is a segment of its own: a
+// heading or a table row
+func isStandaloneLine(line string) bool {
+	return strings.HasPrefix(line, "#") || strings.HasPrefix(line, "|")
+}
+
+// isListItem reports whether a markdown line starts a list item
+func isListItem(line string) bool {
+	if len(line) > 1 && strings.ContainsRune("-*+", rune(line[0])) && line[1] == ' ' {
+		return true
+	}
+	digits := strings.TrimLeft(line, "0123456789")
+	return len(digits) < len(line) && (strings.HasPrefix(digits, ". ") || strings.HasPrefix(digits, ") "))
+}
+
+// endsSentence reports whether the punctuation r at content[punct:] ending at
+// end (after any closers) ends the sentence that began at start
+func endsSentence(content string, start, punct int, r rune, end int) bool {
+	rest := strings.TrimLeft(content[end:], " \t\r\n")
+	if rest == "" {
+		return true
+	}
+	if end == len(content) || !unicode.IsSpace(rune(content[end])) {
+		return false
+	}
+	next, _ := utf8.DecodeRuneInString(rest)
+	if !unicode.IsUpper(next) && !unicode.IsDigit(next) && !strings.ContainsRune("\"'“‘([*_", next) {
+		return false
+	}
+	if r != '.' {
+		return true
+	}
+
+	// The word before the period: an abbreviation or an initial does not end
+	// the sentence
+	wordStart := punct
+	for wordStart > start {
+		prev, size := utf8.DecodeLastRuneInString(content[:wordStart])
+		if !unicode.IsLetter(prev) && prev != '.' {
+			break
+		}
+		wordStart -= size
+	}
+	word := content[wordStart:punct]
+	if strings.Contains(word, ".") {
+		return false // e.g., i.e., U.S.
+	}
+	if sentenceAbbreviations[strings.ToLower(word)] {
+		return false
+	}
+	initial, size := utf8.DecodeRuneInString(word)
+	return size != len(word) || !unicode.IsUpper(initial)
+}
+
+// trimmedSpan returns the span of content[start:end] without surrounding
+// whitespace, and false if it is only whitespace
+func trimmedSpan(content string, start, end int) (models.TextSpan, bool) {
+	text := content[start:end]
+	trimmed := strings.TrimLeftFunc(text, unicode.IsSpace)
+	start += len(text) - len(trimmed)
+	trimmed = strings.TrimRightFunc(trimmed, unicode.IsSpace)
+	if trimmed == "" {
+		return models.TextSpan{}, false
+	}
+	return models.TextSpan{Start: start, End: start + len(trimmed)}, true
+}
+
+// SpanExcerpt is a span of a page's text with up to a given number of bytes of
+// the text on each side, cut at character boundaries
+type SpanExcerpt struct {
+	Text   string
+	Before string
+	After  string
+}
+
+// ExcerptSpan returns the text of a span of content and the text around it.
+// The span must lie within content and start and end at character
+// boundaries.
+func ExcerptSpan(content string, span models.TextSpan, context int) (SpanExcerpt, error) {
+	if span.Start < 0 || span.End < span.Start || span.End > len(content) {
+		return SpanExcerpt{}, fmt.Errorf("span %d-%d is outside the page, which has %d bytes", span.Start, span.End, len(content))
+	}
+	if !isCharBoundary(content, span.Start) || !isCharBoundary(content, span.End) {
+		return SpanExcerpt{}, fmt.Errorf("span %d-%d does not start and end at character boundaries", span.Start, span.End)
+	}
+	before := max(span.Start-context, 0)
+	for !isCharBoundary(content, before) {
+		before++
+	}
+	after := min(span.End+context, len(content))
+	for !isCharBoundary(content, after) {
+		after--
+	}
+	return SpanExcerpt{
+		Text:   content[span.Start:span.End],
+		Before: content[before:span.Start],
+		After:  content[span.End:after],
+	}, nil
+}
+
+// isCharBoundary reports whether offset is at the start of a character of s,
+// or its end
+func isCharBoundary(s string, offset int) bool {
+	return offset == len(s) || utf8.RuneStart(s[offset])
+}
+
+// OverlappingSentences returns the sentences that share text with a span, or
+// that contain it if it is empty
+func OverlappingSentences(sentences []models.TextSpan, span models.TextSpan) []models.TextSpan {
+	overlapping := []models.TextSpan{}
+	for _, sentence := range sentences {
+		if sentence.Start < span.End && span.Start < sentence.End ||
+			span.Start == span.End && sentence.Start <= span.Start && span.Start <= sentence.End {
+			overlapping = append(overlapping, sentence)
+		}
+	}
+	return overlapping
+}
@@ -0,0 +1,146 @@
+package documents
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// sentenceTexts returns the text of each span of content
+func sentenceTexts(content string, spans []models.TextSpan) []string {
+	texts := make([]string, len(spans))
+	for i, span := range spans {
+		texts[i] = content[span.Start:span.End]
+	}
+	return texts
+}
+
+func TestSegmentSentences(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{
+			name:     "sentences in a paragraph",
+			content:  "Memory is not a passive store. Is it active? It is!  It is reconstructive.",
+			expected: []string{"Memory is not a passive store.", "Is it active?", "It is!", "It is reconstructive."},
+		},
+		{
+			name:     "sentence wrapped across lines",
+			content:  "The archive is the law of\nwhat can be said. Scholars\ndisagree.",
+			expected: []string{"The archive is the law of\nwhat can be said.", "Scholars\ndisagree."},
+		},
+		{
+			name:     "abbreviations, initials, and acronyms",
+			content:  "As Smith et al. argue (see pp. 12-14), J. R. Smith was wrong, e.g. in the U.S. Army records. See Fig. 3 too.",
+			expected: []string{"As Smith et al. argue (see pp. 12-14), J. R. Smith was wrong, e.g. in the U.S. Army records.", "See Fig. 3 too."},
+		},
+		{
+			name:     "lowercase after a period and decimals",
+			content:  "The rate rose to 3.5 percent vs. the year before. it fell after.",
+			expected: []string{"The rate rose to 3.5 percent vs. the year before. it fell after."},
+		},
+		{
+			name:     "closing quotes and brackets belong to the sentence",
+			content:  "He wrote, “It is done.” (It was.) “Then what?” she asked.",
+			expected: []string{"He wrote, “It is done.”", "(It was.)", "“Then what?” she asked."},
+		},
+		{
+			name:     "headings, lists, tables, and paragraphs",
+			content:  "# Introduction\n\nFirst paragraph. Still first\n\nSecond paragraph\n- an item\n- another. With two\n1. numbered\n| a | b |\n|---|---|",
+			expected: []string{"# Introduction", "First paragraph.", "Still first", "Second paragraph", "- an item", "- another.", "With two", "1. numbered", "| a | b |", "|---|---|"},
+		},
+		{
+			name:     "multibyte text",
+			content:  "Über die Erinnerung. Ähnlich ist das Archiv… Ende.",
+			expected: []string{"Über die Erinnerung.", "Ähnlich ist das Archiv…", "Ende."},
+		},
+		{name: "empty", content: " \n\n ", expected: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spans := SegmentSentences(tt.content)
+			if got := sentenceTexts(tt.content, spans); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+			// Spans are in order and do not overlap
+			for i := 1; i < len(spans); i++ {
+				if spans[i].Start < spans[i-1].End {
+					t.Errorf("Span %d %+v overlaps span %d %+v", i, spans[i], i-1, spans[i-1])
+				}
+			}
+			if again := SegmentSentences(tt.content); !reflect.DeepEqual(spans, again) {
+				t.Errorf("Expected the same segmentation again, got %+v and %+v", spans, again)
+			}
+		})
+	}
+}
+
+func TestPageSentences(t *testing.T) {
+	sentences := PageSentences([]string{"One. Two.", ""})
+	if len(sentences) != 2 || len(sentences[0]) != 2 {
+		t.Fatalf("Expected two sentences on the first page, got %+v", sentences)
+	}
+	// A page without text has an empty segmentation, not a missing one
+	if sentences[1] == nil || len(sentences[1]) != 0 {
+		t.Errorf("Expected an empty segmentation for an empty page, got %#v", sentences[1])
+	}
+}
+
+func TestExcerptSpan(t *testing.T) {
+	content := "Über die Erinnerung. Das Archiv ist das Gesetz."
+	start := strings.Index(content, "Das")
+	span := models.TextSpan{Start: start, End: start + len("Das Archiv")}
+
+	excerpt, err := ExcerptSpan(content, span, 8)
+	if err != nil {
+		t.Fatalf("ExcerptSpan failed: %v", err)
+	}
+	if excerpt.Text != "Das Archiv" || excerpt.Before != "nerung. " || excerpt.After != " ist das" {
+		t.Errorf("Unexpected excerpt %+v", excerpt)
+	}
+
+	// Context is cut back to a character boundary rather than splitting "Ü"
+	excerpt, err = ExcerptSpan(content, models.TextSpan{Start: 2, End: 5}, 1)
+	if err != nil {
+		t.Fatalf("ExcerptSpan failed: %v", err)
+	}
+	if excerpt.Before != "" || excerpt.Text != "ber" || excerpt.After != " " {
+		t.Errorf("Unexpected excerpt %+v", excerpt)
+	}
+
+	// The whole page, with context past both ends
+	if excerpt, err := ExcerptSpan(content, models.TextSpan{Start: 0, End: len(content)}, 100); err != nil || excerpt.Text != content || excerpt.Before != "" || excerpt.After != "" {
+		t.Errorf("Expected the whole page, got %+v (%v)", excerpt, err)
+	}
+
+	for _, bad := range []models.TextSpan{{Start: 5, End: 4}, {Start: 0, End: len(content) + 1}, {Start: 1, End: 5}} {
+		if _, err := ExcerptSpan(content, bad, 10); err == nil {
+			t.Errorf("Expected span %+v refused", bad)
+		}
+	}
+}
+
+func TestOverlappingSentences(t *testing.T) {
+	content := "One two. Three four. Five six."
+	sentences := SegmentSentences(content)
+	tests := []struct {
+		span     models.TextSpan
+		expected []string
+	}{
+		{models.TextSpan{Start: 4, End: 7}, []string{"One two."}},
+		{models.TextSpan{Start: 4, End: 14}, []string{"One two.", "Three four."}},
+		{models.TextSpan{Start: 8, End: 9}, []string{}},
+		{models.TextSpan{Start: 12, End: 12}, []string{"Three four."}},
+		{models.TextSpan{Start: 0, End: len(content)}, []string{"One two.", "Three four.", "Five six."}},
+	}
+	for _, tt := range tests {
+		got := sentenceTexts(content, OverlappingSentences(sentences, tt.span))
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("Span %+v: expected %q, got %q", tt.span, tt.expected, got)
+		}
+	}
+}
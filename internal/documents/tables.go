@@ -0,0 +1,263 @@
+package documents
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// tableSeparatorCell matches a cell of a markdown table's delimiter row (e.g., "---" or ":-:")
+var tableSeparatorCell = regexp.MustCompile(`^:?-+:?$`)
+
+// StructureTables parses the markdown data of each table into columns and rows.
+// A table whose data cannot be parsed keeps its raw data and records why in
+// ParseError, so one malformed table does not fail the document.
+func StructureTables(item *models.ParsedItem) {
+	for i := range item.Tables {
+		StructureTable(&item.Tables[i])
+	}
+}
+
+// StructureTable parses a table's markdown data into its columns and rows,
+// setting ParseError instead if the data is not a markdown table
+func StructureTable(table *models.Table) {
+	table.Columns, table.Rows, table.ParseError = nil, nil, ""
+	if strings.TrimSpace(table.TableData) == "" {
+		return
+	}
+	columns, rows, err := ParseMarkdownTable(table.TableData)
+	if err != nil {
+		table.ParseError = err.Error()
+		return
+	}
+	table.Columns, table.Rows = columns, rows
+}
+
+// ParseMarkdownTable parses a GitHub-flavored markdown table into its column
+// names and rows of cells. It tolerates the ways tables come back malformed:
+//   - rows with or without leading and trailing pipes
+//   - a missing delimiter row, in which case the first row is the header
+//   - several header rows above the delimiter row (merged headers), which are
+//     combined into one name per column, with an empty cell in an upper row
+//     continuing the span of the cell to its left
+//   - ragged rows, which are padded with empty cells; cells beyond the header
+//     add unnamed columns unless they are empty
+//
+// Text before and after the table is ignored, but text between its rows is an error.
+func ParseMarkdownTable(data string) ([]string, [][]string, error) {
+	var lines [][]string
+	separator := -1
+	ended := false
+	for n, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if !hasUnescapedPipe(line) {
+			if len(lines) > 0 && line != "" {
+				ended = true
+			}
+			continue
+		}
+		if ended {
+			return nil, nil, fmt.Errorf("table rows are interrupted by text before line %d", n+1)
+		}
+		cells := splitTableRow(line)
+		if separator < 0 && isSeparatorRow(cells) {
+			if len(lines) == 0 {
+				return nil, nil, errors.New("table has a delimiter row but no header")
+			}
+			separator = len(lines)
+			continue
+		}
+		lines = append(lines, cells)
+	}
+	if len(lines) == 0 {
+		return nil, nil, errors.New("no markdown table rows found")
+	}
+
+	headerRows := 1
+	if separator > 0 {
+		headerRows = separator
+	}
+	columns := mergeHeaderRows(lines[:headerRows])
+	rows := lines[headerRows:]
+
+	// Widen the table for cells beyond the header that hold data
+	width := len(columns)
+	for _, row := range rows {
+		for len(row) > width && strings.TrimSpace(row[len(row)-1]) == "" {
+			row = row[:len(row)-1]
+		}
+		width = max(width, len(row))
+	}
+	for len(columns) < width {
+		columns = append(columns, "")
+	}
+	if len(columns) == 0 {
+		return nil, nil, errors.New("table has no columns")
+	}
+
+	structured := make([][]string, len(rows))
+	for i, row := range rows {
+		cells := make([]string, width)
+		copy(cells, row)
+		structured[i] = cells
+	}
+	return columns, structured, nil
+}
+
+// mergeHeaderRows combines header rows into one column name per column, joining
+// the names of a column's cells from the top row down with " / "
+func mergeHeaderRows(headers [][]string) []string {
+	width := 0
+	for _, row := range headers {
+		width = max(width, len(row))
+	}
+	// Trailing empty header cells come from a stray trailing pipe
+	for width > 0 && allEmptyAt(headers, width-1) {
+		width--
+	}
+
+	columns := make([]string, width)
+	for r, row := range headers {
+		span := ""
+		for c := 0; c < width; c++ {
+			cell := ""
+			if c < len(row) {
+				cell = row[c]
+			}
+			// In the rows above the last, an empty cell continues the merged cell to its left
+			if r < len(headers)-1 {
+				if cell == "" {
+					cell = span
+				} else {
+					span = cell
+				}
+			}
+			switch {
+			case cell == "":
+			case columns[c] == "":
+				columns[c] = cell
+			case columns[c] != cell:
+				columns[c] += " / " + cell
+			}
+		}
+	}
+	return columns
+}
+
+// allEmptyAt reports whether every row's cell in column c is missing or empty
+func allEmptyAt(rows [][]string, c int) bool {
+	for _, row := range rows {
+		if c < len(row) && row[c] != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// hasUnescapedPipe reports whether a line contains a pipe that is not escaped
+func hasUnescapedPipe(line string) bool {
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '|':
+			return true
+		}
+	}
+	return false
+}
+
+// splitTableRow splits a markdown table row into its trimmed cells, dropping the
+// optional leading and trailing pipes and unescaping escaped pipes
+func splitTableRow(line string) []string {
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = strings.TrimSuffix(line, "|")
+	}
+
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// isSeparatorRow reports whether cells form a markdown table's delimiter row
+func isSeparatorRow(cells []string) bool {
+	nonEmpty := 0
+	for _, cell := range cells {
+		if cell == "" {
+			continue
+		}
+		if !tableSeparatorCell.MatchString(strings.ReplaceAll(cell, " ", "")) {
+			return false
+		}
+		nonEmpty++
+	}
+	return nonEmpty > 0
+}
+
+// TableCSV writes a structured table as CSV with its columns as the header row
+func TableCSV(table models.Table) (string, error) {
+	if table.Columns == nil {
+		return "", tableNotStructuredError(table)
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(table.Columns); err != nil {
+		return "", fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	if err := w.WriteAll(table.Rows); err != nil {
+		return "", fmt.Errorf("failed to write CSV rows: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// TableMarkdown writes a table as a GitHub-flavored markdown table, normalized
+// from its columns and rows, or its raw data if it could not be parsed
+func TableMarkdown(table models.Table) string {
+	if table.Columns == nil {
+		return strings.TrimSpace(table.TableData) + "\n"
+	}
+	var b strings.Builder
+	writeRow := func(cells []string) {
+		b.WriteString("|")
+		for _, cell := range cells {
+			b.WriteString(" " + strings.ReplaceAll(cell, "|", `\|`) + " |")
+		}
+		b.WriteString("\n")
+	}
+	writeRow(table.Columns)
+	separator := make([]string, len(table.Columns))
+	for i := range separator {
+		separator[i] = "---"
+	}
+	writeRow(separator)
+	for _, row := range table.Rows {
+		writeRow(row)
+	}
+	return b.String()
+}
+
+// tableNotStructuredError explains why a table has no columns and rows
+func tableNotStructuredError(table models.Table) error {
+	if table.ParseError != "" {
+		return fmt.Errorf("table could not be parsed: %s", table.ParseError)
+	}
+	return errors.New("table has no data")
+}
@@ -0,0 +1,176 @@
+package documents
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestParseMarkdownTable(t *testing.T) {
+	tests := []struct {
+		name            string
+		data            string
+		expectedColumns []string
+		expectedRows    [][]string
+		expectedError   string
+	}{
+		{
+			name:            "well-formed table",
+			data:            "| Model | Accuracy |\n| :--- | ---: |\n| A | 0.91 |\n| B | 0.87 |",
+			expectedColumns: []string{"Model", "Accuracy"},
+			expectedRows:    [][]string{{"A", "0.91"}, {"B", "0.87"}},
+		},
+		{
+			name:            "rows without outer pipes",
+			data:            "Model | Accuracy\n--- | ---\nA | 0.91",
+			expectedColumns: []string{"Model", "Accuracy"},
+			expectedRows:    [][]string{{"A", "0.91"}},
+		},
+		{
+			name:            "escaped pipes stay in their cell",
+			data:            "| Expression | Meaning |\n|---|---|\n| a \\| b | a or b |",
+			expectedColumns: []string{"Expression", "Meaning"},
+			expectedRows:    [][]string{{"a | b", "a or b"}},
+		},
+		{
+			name: "merged header spans columns to its right",
+			data: "| | Accuracy | | Recall | |\n" +
+				"| Model | 2019 | 2020 | 2019 | 2020 |\n" +
+				"|---|---|---|---|---|\n" +
+				"| A | 0.8 | 0.9 | 0.7 | 0.75 |",
+			expectedColumns: []string{"Model", "Accuracy / 2019", "Accuracy / 2020", "Recall / 2019", "Recall / 2020"},
+			expectedRows:    [][]string{{"A", "0.8", "0.9", "0.7", "0.75"}},
+		},
+		{
+			name: "merged header repeated in each column",
+			data: "| Group | Score | Score |\n" +
+				"| | Mean | SD |\n" +
+				"|---|---|---|\n" +
+				"| Control | 4.1 | 0.3 |",
+			expectedColumns: []string{"Group", "Score / Mean", "Score / SD"},
+			expectedRows:    [][]string{{"Control", "4.1", "0.3"}},
+		},
+		{
+			name:            "short rows are padded",
+			data:            "| A | B | C |\n|---|---|---|\n| 1 | 2 |\n| 3 |\n| 4 | 5 | 6 |",
+			expectedColumns: []string{"A", "B", "C"},
+			expectedRows:    [][]string{{"1", "2", ""}, {"3", "", ""}, {"4", "5", "6"}},
+		},
+		{
+			name:            "long rows add unnamed columns",
+			data:            "| A | B |\n|---|---|\n| 1 | 2 | note |\n| 3 | 4 |",
+			expectedColumns: []string{"A", "B", ""},
+			expectedRows:    [][]string{{"1", "2", "note"}, {"3", "4", ""}},
+		},
+		{
+			name:            "empty trailing cells do not add columns",
+			data:            "| A | B | |\n|---|---|---|\n| 1 | 2 | |",
+			expectedColumns: []string{"A", "B"},
+			expectedRows:    [][]string{{"1", "2"}},
+		},
+		{
+			name:            "missing delimiter row",
+			data:            "| Year | Count |\n| 2019 | 12 |\n| 2020 | 15 |",
+			expectedColumns: []string{"Year", "Count"},
+			expectedRows:    [][]string{{"2019", "12"}, {"2020", "15"}},
+		},
+		{
+			name:            "text around the table is ignored",
+			data:            "Table 2: Results\n\n| A | B |\n|---|---|\n| 1 | 2 |\n\nNote: values rounded.",
+			expectedColumns: []string{"A", "B"},
+			expectedRows:    [][]string{{"1", "2"}},
+		},
+		{
+			name:            "header only",
+			data:            "| A | B |\n|---|---|",
+			expectedColumns: []string{"A", "B"},
+			expectedRows:    [][]string{},
+		},
+		{
+			name:          "not a table",
+			data:          "Year\tCount\n2019\t12",
+			expectedError: "no markdown table rows",
+		},
+		{
+			name:          "text between rows",
+			data:          "| A | B |\n|---|---|\n| 1 | 2 |\ncontinued below\n| 3 | 4 |",
+			expectedError: "interrupted",
+		},
+		{
+			name:          "delimiter row without header",
+			data:          "|---|---|\n| 1 | 2 |",
+			expectedError: "no header",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			columns, rows, err := ParseMarkdownTable(tt.data)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v (columns %q)", tt.expectedError, err, columns)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseMarkdownTable failed: %v", err)
+			}
+			if !reflect.DeepEqual(columns, tt.expectedColumns) {
+				t.Errorf("Expected columns %q, got %q", tt.expectedColumns, columns)
+			}
+			if !reflect.DeepEqual(rows, tt.expectedRows) {
+				t.Errorf("Expected rows %q, got %q", tt.expectedRows, rows)
+			}
+		})
+	}
+}
+
+func TestStructureTables(t *testing.T) {
+	item := &models.ParsedItem{Tables: []models.Table{
+		{TableID: "Table 1", TableData: "| A | B |\n|---|---|\n| 1 | 2 |"},
+		{TableID: "Table 2", TableData: "A: 1, B: 2"},
+		{TableID: "Table 3"},
+	}}
+	StructureTables(item)
+
+	if got := item.Tables[0]; !reflect.DeepEqual(got.Columns, []string{"A", "B"}) || len(got.Rows) != 1 || got.ParseError != "" {
+		t.Errorf("Expected Table 1 to be structured, got %+v", got)
+	}
+	if got := item.Tables[1]; got.Columns != nil || got.ParseError == "" || got.TableData != "A: 1, B: 2" {
+		t.Errorf("Expected Table 2 to keep its data with a parse error, got %+v", got)
+	}
+	if got := item.Tables[2]; got.Columns != nil || got.ParseError != "" {
+		t.Errorf("Expected Table 3 without data to be left alone, got %+v", got)
+	}
+}
+
+func TestTableFormats(t *testing.T) {
+	table := models.Table{TableData: "| Name | Note |\n|---|---|\n| Smith, J. | a \\| b |\n| Doe | \"quoted\" |"}
+	StructureTable(&table)
+
+	csv, err := TableCSV(table)
+	if err != nil {
+		t.Fatalf("TableCSV failed: %v", err)
+	}
+	expectedCSV := "Name,Note\n\"Smith, J.\",a | b\nDoe,\"\"\"quoted\"\"\"\n"
+	if csv != expectedCSV {
+		t.Errorf("Expected CSV %q, got %q", expectedCSV, csv)
+	}
+
+	markdown := TableMarkdown(table)
+	expectedMarkdown := "| Name | Note |\n| --- | --- |\n| Smith, J. | a \\| b |\n| Doe | \"quoted\" |\n"
+	if markdown != expectedMarkdown {
+		t.Errorf("Expected markdown %q, got %q", expectedMarkdown, markdown)
+	}
+
+	malformed := models.Table{TableData: "not a table"}
+	StructureTable(&malformed)
+	if _, err := TableCSV(malformed); err == nil || !strings.Contains(err.Error(), "could not be parsed") {
+		t.Errorf("Expected CSV of a malformed table to fail, got %v", err)
+	}
+	if got := TableMarkdown(malformed); got != "not a table\n" {
+		t.Errorf("Expected markdown of a malformed table to be its raw data, got %q", got)
+	}
+}
@@ -0,0 +1,291 @@
+package documents
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/citations"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// MetadataSourceTEI marks metadata read from a TEI document's header
+const MetadataSourceTEI = "tei"
+
+// teiFormat renders TEI body markup
+var teiFormat = &markupFormat{
+	section:   "div",
+	heading:   "head",
+	paragraph: "p",
+	list:      "list",
+	listItem:  "item",
+	quote:     "quote",
+	skipped: map[string]bool{
+		"figure": true, "note": true, "label": true, "listBibl": true,
+	},
+	emphasis: func(n *xmlNode) string {
+		if n.name != "hi" {
+			return ""
+		}
+		switch rend := n.attr("rend"); {
+		case strings.Contains(rend, "bold"):
+			return "**"
+		case strings.Contains(rend, "italic"):
+			return "*"
+		}
+		return ""
+	},
+}
+
+// parseTEI reads a TEI document: metadata from its header (including the
+// bibliographic description GROBID writes in sourceDesc), the body, the
+// bibliography and notes, and the tables and figures
+func parseTEI(tei *xmlNode) *models.ParsedItem {
+	// A corpus is read as its first document
+	if tei.name == "teiCorpus" {
+		if first := tei.find("TEI"); first != nil {
+			tei = first
+		}
+	}
+	text := tei.child("text")
+	item := &models.ParsedItem{Metadata: teiMetadata(tei.child("teiHeader"), text)}
+
+	var page strings.Builder
+	if item.Metadata.Title != "" {
+		writeMarkdownBlock(&page, "# "+item.Metadata.Title)
+	}
+	if item.Metadata.Abstract != "" {
+		writeMarkdownBlock(&page, "## Abstract")
+		writeMarkdownBlock(&page, item.Metadata.Abstract)
+	}
+	if body := text.child("body"); body != nil {
+		teiFormat.writeBlocks(&page, body, 1)
+	}
+	item.Pages = []string{page.String()}
+
+	for _, list := range text.findAll("listBibl") {
+		for _, entry := range list.children {
+			if reference, ok := teiReference(entry); ok {
+				item.References = append(item.References, reference)
+			}
+		}
+	}
+	for i, note := range text.findAll("note") {
+		if place := note.attr("place"); place != "foot" && place != "end" {
+			continue
+		}
+		noteText := teiFormat.inline(note)
+		if noteText == "" {
+			continue
+		}
+		marker := note.attr("n")
+		if marker == "" {
+			marker = fmt.Sprint(i + 1)
+		}
+		item.Endnotes = append(item.Endnotes, models.Endnote{Marker: marker, Text: noteText, PageNumber: "1"})
+	}
+	for _, figure := range text.findAll("figure") {
+		label := firstNonEmpty(figure.child("head").plainText(), figure.child("label").plainText())
+		caption := teiFormat.inline(figure.child("figDesc"))
+		if figure.attr("type") == "table" {
+			item.Tables = append(item.Tables, teiTable(figure, label, caption))
+		} else if caption := joinLabel(label, caption); caption != "" {
+			item.Images = append(item.Images, models.Image{Caption: caption})
+		}
+	}
+	return item
+}
+
+// teiMetadata reads the header's title statement, publication statement, and
+// source description, and the language of the header or text
+func teiMetadata(header, text *xmlNode) models.ItemMetadata {
+	fileDesc := header.child("fileDesc")
+	source := fileDesc.child("sourceDesc").find("biblStruct")
+	analytic, monogr := source.child("analytic"), source.child("monogr")
+	metadata := models.ItemMetadata{
+		MetadataSource: MetadataSourceTEI,
+		Language:       NormalizeLanguage(firstNonEmpty(header.attr("lang"), text.attr("lang"))),
+	}
+
+	titleStmt := fileDesc.child("titleStmt")
+	metadata.Title = teiTitle(titleStmt)
+	if metadata.Title == "" {
+		metadata.Title = teiTitle(analytic)
+	}
+	authors := titleStmt.childrenNamed("author")
+	if len(authors) == 0 {
+		authors = analytic.childrenNamed("author")
+	}
+	for _, author := range authors {
+		if name := teiPersonName(author); name != "" {
+			metadata.Authors = append(metadata.Authors, name)
+		}
+	}
+
+	publicationStmt := fileDesc.child("publicationStmt")
+	metadata.Publisher = publicationStmt.child("publisher").plainText()
+	metadata.PublicationDate = teiDate(publicationStmt.child("date"))
+	imprint := monogr.child("imprint")
+	if metadata.PublicationDate == "" {
+		metadata.PublicationDate = teiDate(imprint.child("date"))
+	}
+	for _, id := range fileDesc.findAll("idno") {
+		if strings.EqualFold(id.attr("type"), "doi") {
+			if doi, ok := citations.NormalizeDOI(id.plainText()); ok {
+				metadata.DOI = doi
+				break
+			}
+		}
+	}
+
+	if journal := teiLevelTitle(monogr, "j"); journal != "" {
+		metadata.Publication = journal
+		metadata.ItemType = "journalArticle"
+	}
+	metadata.Volume, metadata.Issue, metadata.Pages = teiScope(imprint)
+
+	var abstract []string
+	for _, p := range header.child("profileDesc").child("abstract").findAll("p") {
+		if text := teiFormat.inline(p); text != "" {
+			abstract = append(abstract, text)
+		}
+	}
+	metadata.Abstract = strings.Join(abstract, "\n\n")
+	return metadata
+}
+
+// teiTitle returns the main title among n's title elements
+func teiTitle(n *xmlNode) string {
+	titles := n.childrenNamed("title")
+	for _, title := range titles {
+		if title.attr("type") == "main" {
+			return title.plainText()
+		}
+	}
+	if len(titles) == 0 {
+		return ""
+	}
+	return titles[0].plainText()
+}
+
+// teiLevelTitle returns the title of n at a bibliographic level ("a" for an
+// article, "j" for a journal, "m" for a monograph)
+func teiLevelTitle(n *xmlNode, level string) string {
+	for _, title := range n.childrenNamed("title") {
+		if title.attr("level") == level {
+			return title.plainText()
+		}
+	}
+	return ""
+}
+
+// teiPersonName returns an author's name as "Forenames Surname", or the text of
+// an author given without a persName
+func teiPersonName(author *xmlNode) string {
+	person := author.child("persName")
+	if person == nil {
+		if org := author.child("orgName"); org != nil {
+			return org.plainText()
+		}
+		return author.plainText()
+	}
+	var parts []string
+	for _, forename := range person.childrenNamed("forename") {
+		parts = append(parts, forename.plainText())
+	}
+	parts = append(parts, person.child("surname").plainText())
+	name := strings.Join(strings.Fields(strings.Join(parts, " ")), " ")
+	if name == "" {
+		return person.plainText()
+	}
+	return name
+}
+
+// teiDate returns a date's when attribute, or its text
+func teiDate(date *xmlNode) string {
+	return firstNonEmpty(date.attr("when"), date.plainText())
+}
+
+// teiScope returns the volume, issue, and page range of an imprint's biblScope elements
+func teiScope(imprint *xmlNode) (volume, issue, pages string) {
+	for _, scope := range imprint.childrenNamed("biblScope") {
+		switch scope.attr("unit") {
+		case "volume":
+			volume = scope.plainText()
+		case "issue":
+			issue = scope.plainText()
+		case "page":
+			pages = firstNonEmpty(scope.plainText(), joinPageRange(scope.attr("from"), scope.attr("to")))
+		}
+	}
+	return volume, issue, pages
+}
+
+// teiReference reads a bibliography entry: a biblStruct's raw reference if
+// GROBID kept one, or its fields formatted, or a bibl's text, with the DOI of
+// its idno
+func teiReference(entry *xmlNode) (models.Reference, bool) {
+	var reference models.Reference
+	switch entry.name {
+	case "biblStruct":
+		for _, note := range entry.childrenNamed("note") {
+			if note.attr("type") == "raw_reference" {
+				reference.ReferenceText = note.plainText()
+			}
+		}
+		if reference.ReferenceText == "" {
+			analytic, monogr := entry.child("analytic"), entry.child("monogr")
+			imprint := monogr.child("imprint")
+			parts := referenceParts{
+				year:   teiDate(imprint.child("date")),
+				title:  teiLevelTitle(analytic, "a"),
+				source: firstNonEmpty(teiLevelTitle(monogr, "j"), teiLevelTitle(monogr, "m"), teiTitle(monogr)),
+			}
+			if len(parts.year) > 4 {
+				parts.year = parts.year[:4]
+			}
+			parts.volume, parts.issue, parts.pages = teiScope(imprint)
+			authors := analytic.childrenNamed("author")
+			if len(authors) == 0 {
+				authors = monogr.childrenNamed("author")
+			}
+			for _, author := range authors {
+				parts.authors = append(parts.authors, teiPersonName(author))
+			}
+			reference.ReferenceText = parts.text()
+		}
+	case "bibl":
+		reference.ReferenceText = teiFormat.inline(entry)
+	default:
+		return models.Reference{}, false
+	}
+	if reference.ReferenceText == "" {
+		return models.Reference{}, false
+	}
+	for _, id := range entry.findAll("idno") {
+		if strings.EqualFold(id.attr("type"), "doi") {
+			if doi, ok := citations.NormalizeDOI(id.plainText()); ok {
+				reference.DOI = doi
+			}
+		}
+	}
+	return reference, true
+}
+
+// teiTable reads a table figure: its head as the label, its figDesc as the
+// caption, and its rows as markdown
+func teiTable(figure *xmlNode, label, caption string) models.Table {
+	table := models.Table{TableID: label, TableTitle: caption}
+	if table.TableID == "" {
+		table.TableID = figure.attr("id")
+	}
+	var rows [][]string
+	for _, row := range figure.find("table").findAll("row") {
+		var cells []string
+		for _, cell := range row.childrenNamed("cell") {
+			cells = append(cells, teiFormat.inline(cell))
+		}
+		rows = append(rows, cells)
+	}
+	table.TableData = markdownTable(rows)
+	return table
+}
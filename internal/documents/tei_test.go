@@ -0,0 +1,72 @@
+package documents
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestParseStructuredXML_TEI(t *testing.T) {
+	data := readXMLFixture(t, "tei/article.xml")
+	if data.Type != "tei" {
+		t.Fatalf("Expected the fixture detected as TEI, got %q", data.Type)
+	}
+	item, err := ParseStructuredXML(data)
+	if err != nil {
+		t.Fatalf("ParseStructuredXML failed: %v", err)
+	}
+
+	want := models.ItemMetadata{
+		Title:           "Letters in the Archive",
+		Authors:         []string{"Jane Smith", "Robert Jones"},
+		PublicationDate: "2020-05-01",
+		Publication:     "Journal of Memory Studies",
+		Publisher:       "Example Press",
+		Volume:          "12",
+		Issue:           "3",
+		Pages:           "45-67",
+		DOI:             "10.1000/jms.2020.7",
+		Abstract:        "We read letters kept in a village archive.",
+		Language:        "en",
+		ItemType:        "journalArticle",
+		MetadataSource:  MetadataSourceTEI,
+	}
+	if !reflect.DeepEqual(item.Metadata, want) {
+		t.Errorf("Unexpected metadata:\n got %+v\nwant %+v", item.Metadata, want)
+	}
+
+	wantPage := "# Letters in the Archive\n\n" +
+		"## Abstract\n\n" +
+		"We read letters kept in a village archive.\n\n" +
+		"## Introduction\n\n" +
+		"The archive is a place of *memory* (Derrida, 1995).\n\n" +
+		"## Results\n\n" +
+		"Most letters were sent in winter."
+	if !slices.Equal(item.Pages, []string{wantPage}) {
+		t.Errorf("Unexpected page:\n got %q\nwant %q", item.Pages, wantPage)
+	}
+
+	wantRefs := []models.Reference{
+		{ReferenceText: "J Derrida (1995). Archive Fever."},
+		{ReferenceText: "C Steedman (2001). The space of memory. History of the Human Sciences 11, 65-83.", DOI: "10.1177/095269519801100405"},
+	}
+	if !reflect.DeepEqual(item.References, wantRefs) {
+		t.Errorf("Unexpected references:\n got %+v\nwant %+v", item.References, wantRefs)
+	}
+	if !reflect.DeepEqual(item.Endnotes, []models.Endnote{{Marker: "1", Text: "The archive was catalogued in 1987.", PageNumber: "1"}}) {
+		t.Errorf("Unexpected notes: %+v", item.Endnotes)
+	}
+	wantTable := models.Table{
+		TableID:    "Table 1",
+		TableTitle: "Letters by season",
+		TableData:  "| Season | Letters |\n| --- | --- |\n| Winter | 120 |\n",
+	}
+	if !reflect.DeepEqual(item.Tables, []models.Table{wantTable}) {
+		t.Errorf("Unexpected tables: %+v", item.Tables)
+	}
+	if !reflect.DeepEqual(item.Images, []models.Image{{Caption: "Figure 1: Map of the village."}}) {
+		t.Errorf("Unexpected figures: %+v", item.Images)
+	}
+}
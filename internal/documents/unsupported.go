@@ -0,0 +1,158 @@
+package documents
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// formatSignature identifies a file format by the bytes it starts with
+type formatSignature struct {
+	prefix []byte
+	name   string
+	advice string // What to do instead, if anything
+}
+
+// unsupportedSignatures are formats that are recognized but cannot be parsed
+var unsupportedSignatures = []formatSignature{
+	{[]byte("%!PS"), "a PostScript file", "convert it to PDF"},
+	{[]byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}, "a legacy Microsoft Office document (DOC, XLS, or PPT)", "save it as PDF"},
+	{[]byte("AT&TFORM"), "a DjVu document", "convert it to PDF"},
+	{[]byte{0x89, 'P', 'N', 'G'}, "a PNG image", "upload the document the image is of as PDF"},
+	{[]byte{0xFF, 0xD8, 0xFF}, "a JPEG image", "upload the document the image is of as PDF"},
+	{[]byte("GIF8"), "a GIF image", ""},
+	{[]byte("II*\x00"), "a TIFF image", "convert it to PDF"},
+	{[]byte("MM\x00*"), "a TIFF image", "convert it to PDF"},
+	{[]byte{0x1F, 0x8B}, "a gzip archive", "decompress it first"},
+	{[]byte("Rar!"), "a RAR archive", "extract the document first"},
+	{[]byte{'7', 'z', 0xBC, 0xAF, 0x27, 0x1C}, "a 7-Zip archive", "extract the document first"},
+}
+
+// openDocumentKinds names the OpenDocument formats by their mimetype suffix
+var openDocumentKinds = map[string]string{
+	"text":         "an OpenDocument text document (ODT)",
+	"spreadsheet":  "an OpenDocument spreadsheet (ODS)",
+	"presentation": "an OpenDocument presentation (ODP)",
+}
+
+// DescribeUnsupported explains why data of the detected type ("zip" or
+// "unknown") cannot be parsed: what a ZIP archive contains, or which format the
+// data appears to be, so that the error says more than that the type is
+// unsupported.
+func DescribeUnsupported(data []byte, docType string) string {
+	if docType == "zip" {
+		return describeZip(data)
+	}
+	if len(data) == 0 {
+		return "the document is empty"
+	}
+	for _, sig := range unsupportedSignatures {
+		if bytes.HasPrefix(data, sig.prefix) {
+			return unsupportedMessage(sig.name, sig.advice)
+		}
+	}
+	return fmt.Sprintf("unrecognized data starting with % X — not a supported document (PDF, HTML, EPUB, RTF, Markdown, or plain text)", data[:min(len(data), 8)])
+}
+
+// describeZip names an OpenDocument file, or else counts the files of a ZIP
+// archive by extension, most common first
+func describeZip(data []byte) string {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "damaged or incomplete ZIP archive — not a supported document"
+	}
+
+	if mimetype, err := readZipFile(reader, "mimetype"); err == nil {
+		kind, ok := strings.CutPrefix(strings.TrimSpace(string(mimetype)), "application/vnd.oasis.opendocument.")
+		if ok {
+			name := openDocumentKinds[kind]
+			if name == "" {
+				name = "an OpenDocument file"
+			}
+			return unsupportedMessage(name, "export it as PDF")
+		}
+	}
+
+	counts := make(map[string]int)
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		ext := strings.ToLower(path.Ext(file.Name))
+		if ext == "" {
+			ext = "(no extension)"
+		}
+		counts[ext]++
+	}
+	if len(counts) == 0 {
+		return "empty ZIP archive — not a supported document"
+	}
+
+	exts := make([]string, 0, len(counts))
+	total := 0
+	for ext, n := range counts {
+		exts = append(exts, ext)
+		total += n
+	}
+	slices.SortFunc(exts, func(a, b string) int {
+		if counts[a] != counts[b] {
+			return counts[b] - counts[a]
+		}
+		return strings.Compare(a, b)
+	})
+
+	const listed = 3
+	parts := make([]string, 0, listed+1)
+	for i, ext := range exts {
+		if i == listed {
+			parts = append(parts, fileCount(total-countListed(counts, exts[:listed]), "other"))
+			break
+		}
+		parts = append(parts, fileCount(counts[ext], ext))
+	}
+	return fmt.Sprintf("ZIP containing %s — not a supported document; extract the document and upload it on its own", joinList(parts))
+}
+
+func countListed(counts map[string]int, exts []string) int {
+	n := 0
+	for _, ext := range exts {
+		n += counts[ext]
+	}
+	return n
+}
+
+func fileCount(n int, ext string) string {
+	noun := "files"
+	if n == 1 {
+		noun = "file"
+	}
+	if ext == "other" {
+		return fmt.Sprintf("%d other %s", n, noun)
+	}
+	if ext == "(no extension)" {
+		return fmt.Sprintf("%d %s with no extension", n, noun)
+	}
+	return fmt.Sprintf("%d %s %s", n, ext, noun)
+}
+
+// joinList joins items as "a", "a and b", or "a, b, and c"
+func joinList(items []string) string {
+	switch len(items) {
+	case 1:
+		return items[0]
+	case 2:
+		return items[0] + " and " + items[1]
+	}
+	return strings.Join(items[:len(items)-1], ", ") + ", and " + items[len(items)-1]
+}
+
+func unsupportedMessage(name string, advice string) string {
+	message := fmt.Sprintf("looks like %s — not a supported document", name)
+	if advice != "" {
+		message += "; " + advice
+	}
+	return message
+}
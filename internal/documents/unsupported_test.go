@@ -0,0 +1,124 @@
+package documents
+
+import "testing"
+
+func TestDescribeUnsupported(t *testing.T) {
+	images := map[string]string{"notes.txt": "notes"}
+	for _, name := range []string{"a.png", "b.png", "c.PNG", "scans/d.png"} {
+		images[name] = "image"
+	}
+	imageZip, err := createTestZip(images)
+	if err != nil {
+		t.Fatalf("Failed to create test ZIP: %v", err)
+	}
+	mixedZip, err := createTestZip(map[string]string{
+		"a.png": "", "b.png": "", "c.csv": "", "d.csv": "", "e.txt": "", "f.json": "", "README": "",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test ZIP: %v", err)
+	}
+	odt, err := createTestZip(map[string]string{
+		"mimetype":    "application/vnd.oasis.opendocument.text",
+		"content.xml": "<office:document-content/>",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test ZIP: %v", err)
+	}
+	emptyZip, err := createTestZip(map[string]string{})
+	if err != nil {
+		t.Fatalf("Failed to create test ZIP: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		data     []byte
+		expected string
+	}{
+		{
+			name:     "ZIP of images",
+			data:     imageZip,
+			expected: "ZIP containing 4 .png files and 1 .txt file — not a supported document; extract the document and upload it on its own",
+		},
+		{
+			name:     "ZIP of many kinds of files",
+			data:     mixedZip,
+			expected: "ZIP containing 2 .csv files, 2 .png files, 1 file with no extension, and 2 other files — not a supported document; extract the document and upload it on its own",
+		},
+		{
+			name:     "empty ZIP",
+			data:     emptyZip,
+			expected: "empty ZIP archive — not a supported document",
+		},
+		{
+			name:     "ODT",
+			data:     odt,
+			expected: "looks like an OpenDocument text document (ODT) — not a supported document; export it as PDF",
+		},
+		{
+			name:     "truncated ZIP",
+			data:     []byte{0x50, 0x4B, 0x03, 0x04, 0x00, 0x00, 0x00, 0x00},
+			expected: "damaged or incomplete ZIP archive — not a supported document",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if docType := DetectDocumentType(tt.data); docType != "zip" {
+				t.Fatalf("DetectDocumentType() = %v, want zip", docType)
+			}
+			if got := DescribeUnsupported(tt.data, "zip"); got != tt.expected {
+				t.Errorf("DescribeUnsupported() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDescribeUnsupported_Signatures(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		expected string
+	}{
+		{
+			name:     "PostScript",
+			data:     []byte("%!PS-Adobe-3.0\n%%Title: paper.ps\n\x80\x81\x00"),
+			expected: "looks like a PostScript file — not a supported document; convert it to PDF",
+		},
+		{
+			name:     "legacy Word document",
+			data:     []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1, 0x00, 0x00},
+			expected: "looks like a legacy Microsoft Office document (DOC, XLS, or PPT) — not a supported document; save it as PDF",
+		},
+		{
+			name:     "PNG image",
+			data:     []byte{0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A, 0x00},
+			expected: "looks like a PNG image — not a supported document; upload the document the image is of as PDF",
+		},
+		{
+			name:     "gzip archive",
+			data:     []byte{0x1F, 0x8B, 0x08, 0x00, 0x00, 0x00},
+			expected: "looks like a gzip archive — not a supported document; decompress it first",
+		},
+		{
+			name:     "unrecognized binary data",
+			data:     []byte{0x00, 0x01, 0x02, 0xFF, 0xFE, 0x10, 0x20, 0x30, 0x40, 0x50},
+			expected: "unrecognized data starting with 00 01 02 FF FE 10 20 30 — not a supported document (PDF, HTML, EPUB, RTF, Markdown, or plain text)",
+		},
+		{
+			name:     "empty data",
+			data:     []byte{},
+			expected: "the document is empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if docType := DetectDocumentType(tt.data); docType != "unknown" {
+				t.Fatalf("DetectDocumentType() = %v, want unknown", docType)
+			}
+			if got := DescribeUnsupported(tt.data, "unknown"); got != tt.expected {
+				t.Errorf("DescribeUnsupported() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
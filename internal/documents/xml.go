@@ -0,0 +1,437 @@
+package documents
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// XMLParserVersion is the parser version recorded for JATS and TEI documents,
+// which are read from their markup rather than by the model
+const XMLParserVersion = "xml-1"
+
+// Namespaces identifying structured XML formats by their root element
+const (
+	jatsNamespacePrefix = "http://jats.nlm.nih.gov"
+	teiNamespace        = "http://www.tei-c.org/ns/1.0"
+)
+
+// detectXMLType returns "jats" or "tei" for a JATS article or TEI document,
+// "html" for XHTML, "xml" for other XML, or "" if data is not XML. Without an
+// XML declaration, data is only taken for XML if it is well formed, so text
+// that happens to begin with a tag is left to the text pipeline.
+func detectXMLType(data []byte) string {
+	data = bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\ufeff")))
+	declared := bytes.HasPrefix(data, []byte("<?xml"))
+	if !declared && (len(data) < 2 || data[0] != '<' || !isASCIILetter(data[1])) {
+		return ""
+	}
+	if !declared && !isWellFormedXML(data) {
+		return ""
+	}
+
+	decoder := newXMLDecoder(data)
+	doctype := ""
+	var root *xml.StartElement
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if root == nil {
+				return ""
+			}
+			break
+		}
+		if directive, ok := token.(xml.Directive); ok && root == nil {
+			doctype = string(directive)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if root != nil {
+			// A JATS article with neither namespace nor doctype still opens with its front matter
+			if root.Name.Local == "article" && start.Name.Local == "front" {
+				return "jats"
+			}
+			break
+		}
+		root = &start
+		if strings.EqualFold(start.Name.Local, "html") {
+			return "html"
+		}
+		if start.Name.Local == "TEI" || start.Name.Local == "teiCorpus" || start.Name.Space == teiNamespace {
+			return "tei"
+		}
+		if start.Name.Local == "article" && (strings.HasPrefix(start.Name.Space, jatsNamespacePrefix) ||
+			strings.Contains(doctype, "JATS") || strings.Contains(doctype, "NLM")) {
+			return "jats"
+		}
+	}
+	return "xml"
+}
+
+// isWellFormedXML reports whether data decodes as XML to its end
+func isWellFormedXML(data []byte) bool {
+	decoder := newXMLDecoder(data)
+	decoder.Strict = true
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			return true
+		}
+		if err != nil {
+			return false
+		}
+	}
+}
+
+func newXMLDecoder(data []byte) *xml.Decoder {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	return decoder
+}
+
+// xmlNode is an element of an XML document, or a run of text within one
+type xmlNode struct {
+	name     string            // Local name, without the namespace; empty for text
+	attrs    map[string]string // Attribute values by local name
+	children []*xmlNode        // Elements and text, in document order
+	text     string            // The text of a text node
+}
+
+// parseXMLTree reads data into a tree of its elements and text. Namespaces are
+// dropped, as JATS and TEI element names do not collide within a document.
+func parseXMLTree(data []byte) (*xmlNode, error) {
+	decoder := newXMLDecoder(data)
+	var root *xmlNode
+	var open []*xmlNode
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: t.Name.Local, attrs: make(map[string]string, len(t.Attr))}
+			for _, attr := range t.Attr {
+				node.attrs[attr.Name.Local] = attr.Value
+			}
+			if len(open) > 0 {
+				parent := open[len(open)-1]
+				parent.children = append(parent.children, node)
+			} else if root == nil {
+				root = node
+			}
+			open = append(open, node)
+		case xml.EndElement:
+			if len(open) > 0 {
+				open = open[:len(open)-1]
+			}
+		case xml.CharData:
+			if len(open) > 0 {
+				parent := open[len(open)-1]
+				parent.children = append(parent.children, &xmlNode{text: string(t)})
+			}
+		}
+	}
+	if root == nil {
+		return nil, errors.New("no root element")
+	}
+	return root, nil
+}
+
+// child returns the first child element of n named name, or nil
+func (n *xmlNode) child(name string) *xmlNode {
+	if n == nil {
+		return nil
+	}
+	for _, child := range n.children {
+		if child.name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// childrenNamed returns the child elements of n named name
+func (n *xmlNode) childrenNamed(name string) []*xmlNode {
+	if n == nil {
+		return nil
+	}
+	var children []*xmlNode
+	for _, child := range n.children {
+		if child.name == name {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// find returns the first element below n named name, depth first, or nil
+func (n *xmlNode) find(name string) *xmlNode {
+	if n == nil {
+		return nil
+	}
+	for _, child := range n.children {
+		if child.name == name {
+			return child
+		}
+		if found := child.find(name); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// findAll returns every element below n named name, in document order,
+// without looking inside the elements found
+func (n *xmlNode) findAll(name string) []*xmlNode {
+	if n == nil {
+		return nil
+	}
+	var found []*xmlNode
+	for _, child := range n.children {
+		if child.name == name {
+			found = append(found, child)
+		} else {
+			found = append(found, child.findAll(name)...)
+		}
+	}
+	return found
+}
+
+// attr returns the value of n's attribute name, or ""
+func (n *xmlNode) attr(name string) string {
+	if n == nil {
+		return ""
+	}
+	return n.attrs[name]
+}
+
+// plainText returns all of the text below n with its whitespace collapsed
+func (n *xmlNode) plainText() string {
+	if n == nil {
+		return ""
+	}
+	var b strings.Builder
+	var write func(*xmlNode)
+	write = func(node *xmlNode) {
+		for _, child := range node.children {
+			if child.name == "" {
+				b.WriteString(child.text)
+			} else {
+				write(child)
+			}
+		}
+	}
+	write(n)
+	return collapseSpace(b.String())
+}
+
+// markupFormat names the elements of a structured XML format that are
+// rendered as markdown blocks
+type markupFormat struct {
+	section, heading, paragraph, list, listItem, quote string
+
+	// skipped elements are left out of the text: tables, figures, and notes are
+	// collected separately, and labels are numbering
+	skipped map[string]bool
+
+	// emphasis returns the markdown marker wrapping an inline element, if any
+	emphasis func(n *xmlNode) string
+}
+
+// writeBlocks renders the block content of n as markdown paragraphs, with the
+// headings of sections directly within n at level
+func (f *markupFormat) writeBlocks(b *strings.Builder, n *xmlNode, level int) {
+	for _, child := range n.children {
+		switch {
+		case child.name == "":
+			writeMarkdownBlock(b, collapseSpace(child.text))
+		case f.skipped[child.name]:
+		case child.name == f.section:
+			f.writeBlocks(b, child, level+1)
+		case child.name == f.heading:
+			if heading := f.inline(child); heading != "" {
+				writeMarkdownBlock(b, strings.Repeat("#", min(level, 6))+" "+heading)
+			}
+		case child.name == f.paragraph:
+			writeMarkdownBlock(b, f.inline(child))
+		case child.name == f.list:
+			var items []string
+			for _, item := range child.childrenNamed(f.listItem) {
+				if text := f.inline(item); text != "" {
+					items = append(items, "- "+text)
+				}
+			}
+			writeMarkdownBlock(b, strings.Join(items, "\n"))
+		case child.name == f.quote:
+			writeMarkdownBlock(b, "> "+f.inline(child))
+		default:
+			// Containers without headings of their own (boxed text, unnamed divisions)
+			f.writeBlocks(b, child, level)
+		}
+	}
+}
+
+// inline renders the text of n with its emphasis, leaving out skipped elements
+func (f *markupFormat) inline(n *xmlNode) string {
+	if n == nil {
+		return ""
+	}
+	var b strings.Builder
+	var write func(*xmlNode)
+	write = func(node *xmlNode) {
+		for _, child := range node.children {
+			switch {
+			case child.name == "":
+				b.WriteString(child.text)
+			case f.skipped[child.name]:
+			default:
+				marker := f.emphasis(child)
+				b.WriteString(marker)
+				write(child)
+				b.WriteString(marker)
+			}
+		}
+	}
+	write(n)
+	return collapseSpace(b.String())
+}
+
+// writeMarkdownBlock appends a paragraph, separated from the last by a blank line
+func writeMarkdownBlock(b *strings.Builder, block string) {
+	if strings.TrimSpace(block) == "" {
+		return
+	}
+	if b.Len() > 0 {
+		b.WriteString("\n\n")
+	}
+	b.WriteString(block)
+}
+
+// markdownTable writes rows of cells as a GitHub-flavored markdown table, the
+// first row as its header
+func markdownTable(rows [][]string) string {
+	if len(rows) == 0 {
+		return ""
+	}
+	columns := 0
+	for _, row := range rows {
+		columns = max(columns, len(row))
+	}
+	var b strings.Builder
+	writeRow := func(cells []string) {
+		b.WriteString("|")
+		for i := range columns {
+			cell := ""
+			if i < len(cells) {
+				cell = strings.ReplaceAll(cells[i], "|", `\|`)
+			}
+			b.WriteString(" " + cell + " |")
+		}
+		b.WriteString("\n")
+	}
+	writeRow(rows[0])
+	writeRow(slices.Repeat([]string{"---"}, columns))
+	for _, row := range rows[1:] {
+		writeRow(row)
+	}
+	return b.String()
+}
+
+// joinLabel joins a figure or table label to its caption ("Figure 1: Sites")
+func joinLabel(label, caption string) string {
+	switch {
+	case label == "":
+		return caption
+	case caption == "":
+		return label
+	}
+	return strings.TrimRight(label, ".:") + ": " + caption
+}
+
+// referenceParts are the fields of a structured citation, for formatting as text
+type referenceParts struct {
+	authors                            []string
+	year, title, source, volume, issue string
+	pages                              string
+}
+
+// text formats the citation as "Authors (Year). Title. Source Volume(Issue), Pages."
+func (p referenceParts) text() string {
+	var parts []string
+	head := strings.Join(p.authors, ", ")
+	if p.year != "" {
+		head = strings.TrimSpace(head + " (" + p.year + ")")
+	}
+	parts = append(parts, head, p.title)
+	source := p.source
+	if p.volume != "" {
+		source = strings.TrimSpace(source + " " + p.volume)
+	}
+	if p.issue != "" {
+		source += "(" + p.issue + ")"
+	}
+	if p.pages != "" {
+		source = strings.TrimLeft(source+", "+p.pages, ", ")
+	}
+	parts = append(parts, source)
+
+	var b strings.Builder
+	for _, part := range parts {
+		part = strings.TrimRight(strings.TrimSpace(part), ".")
+		if part == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString(part + ".")
+	}
+	return b.String()
+}
+
+// IsStructuredXML reports whether documents of docType are parsed from their
+// markup by ParseStructuredXML rather than by the model
+func IsStructuredXML(docType string) bool {
+	return docType == "jats" || docType == "tei"
+}
+
+// ParseStructuredXML parses a JATS or TEI document from its markup, without the
+// model: its metadata, its body as a single page of markdown with a heading per
+// section, its references with their DOIs, its notes as endnotes, and its tables
+// and figures with their captions
+func ParseStructuredXML(data models.DocumentData) (*models.ParsedItem, error) {
+	var parse func(*xmlNode) *models.ParsedItem
+	switch data.Type {
+	case "jats":
+		parse = parseJATS
+	case "tei":
+		parse = parseTEI
+	default:
+		return nil, models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("%s documents are not structured XML", data.Type))
+	}
+	root, err := parseXMLTree(data.Data)
+	if err != nil {
+		return nil, models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("failed to read %s XML: %w", strings.ToUpper(data.Type), err))
+	}
+
+	item := parse(root)
+	if strings.TrimSpace(item.Pages[0]) == "" {
+		return nil, models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("%s document has no title, abstract, or body text", strings.ToUpper(data.Type)))
+	}
+	item.PageNumbers = []string{"1"}
+	item.Provenance = &models.Provenance{ParserVersion: XMLParserVersion}
+	return item, nil
+}
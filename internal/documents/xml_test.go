@@ -0,0 +1,42 @@
+package documents
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestParseStructuredXML_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data models.DocumentData
+		want string
+	}{
+		{"not structured XML", models.DocumentData{Data: []byte("<rss/>"), Type: "xml"}, "not structured XML"},
+		{"malformed", models.DocumentData{Data: []byte(`<?xml version="1.0"?><TEI><text>`), Type: "tei"}, "failed to read TEI XML"},
+		{"no text", models.DocumentData{Data: []byte(`<article><front><article-meta/></front></article>`), Type: "jats"}, "no title, abstract, or body text"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseStructuredXML(tt.data)
+			var coded *models.CodedError
+			if err == nil || !strings.Contains(err.Error(), tt.want) || !errors.As(err, &coded) || coded.Code != models.ErrorInvalidInput {
+				t.Errorf("Expected an invalid_input error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestParseDocumentBasic_UnknownXML(t *testing.T) {
+	data := []byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>Feed</title></channel></rss>`)
+	item, err := ParseDocumentBasic(models.DocumentData{Data: data, Type: DetectDocumentType(data)})
+	if err != nil {
+		t.Fatalf("ParseDocumentBasic failed: %v", err)
+	}
+	if !item.Basic || !slices.Equal(item.Pages, []string{string(data)}) {
+		t.Errorf("Expected unrecognized XML parsed as text, got %+v", item)
+	}
+}
@@ -0,0 +1,279 @@
+package documents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/config"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+	"github.com/Epistemic-Technology/zotero/zotero"
+)
+
+// Zotero library types accepted in tool parameters and ZOTERO_LIBRARY_TYPE
+const (
+	ZoteroLibraryTypeUser  = "user"
+	ZoteroLibraryTypeGroup = "group"
+)
+
+// ResolveZoteroLibrary determines which Zotero library to use. Explicit values take
+// priority; otherwise the library type falls back to ZOTERO_LIBRARY_TYPE (default
+// "user") and the library ID falls back to ZOTERO_LIBRARY_ID, as held by the
+// credentials provider in ctx (see config.FromContext).
+func ResolveZoteroLibrary(ctx context.Context, libraryType, libraryID string) (models.ZoteroLibrary, error) {
+	credentials := config.FromContext(ctx).Credentials()
+	if libraryType == "" {
+		libraryType = credentials.ZoteroLibraryType
+	}
+	if libraryType == "" {
+		libraryType = ZoteroLibraryTypeUser
+	}
+
+	libraryType = strings.ToLower(strings.TrimSpace(libraryType))
+	switch libraryType {
+	case ZoteroLibraryTypeUser, ZoteroLibraryTypeGroup:
+	case "users", "groups":
+		// Accept the plural forms used in Zotero API paths
+		libraryType = strings.TrimSuffix(libraryType, "s")
+	default:
+		return models.ZoteroLibrary{}, models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("invalid Zotero library type: %s (expected 'user' or 'group')", libraryType))
+	}
+
+	if libraryID == "" {
+		libraryID = credentials.ZoteroLibraryID
+	}
+	if libraryID == "" {
+		return models.ZoteroLibrary{}, models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("Zotero library ID not provided and ZOTERO_LIBRARY_ID not set in the environment or config file"))
+	}
+
+	return models.ZoteroLibrary{Type: libraryType, ID: libraryID}, nil
+}
+
+// DocumentIDSource returns the source a document ID is generated from (see
+// storage.GenerateDocumentID). An item in the user library of ZOTERO_LIBRARY_ID
+// is identified by its key alone, as it was before libraries could be chosen,
+// so its library is cleared; any other library stays in the ID.
+func DocumentIDSource(ctx context.Context, sourceInfo *models.SourceInfo) *models.SourceInfo {
+	if sourceInfo.ZoteroID == "" || sourceInfo.ZoteroLibraryType != ZoteroLibraryTypeUser {
+		return sourceInfo
+	}
+	credentials := config.FromContext(ctx).Credentials()
+	defaultType := strings.ToLower(strings.TrimSpace(credentials.ZoteroLibraryType))
+	if (defaultType == "" || strings.TrimSuffix(defaultType, "s") == ZoteroLibraryTypeUser) && sourceInfo.ZoteroLibraryID == credentials.ZoteroLibraryID {
+		idSource := *sourceInfo
+		idSource.ZoteroLibraryType, idSource.ZoteroLibraryID = "", ""
+		return &idSource
+	}
+	return sourceInfo
+}
+
+// NewZoteroClient creates a Zotero API client for the given library.
+// ZOTERO_API_BASE_URL can be set to point the client at a different API endpoint.
+func NewZoteroClient(library models.ZoteroLibrary, apiKey string) *zotero.Client {
+	libraryType := zotero.LibraryTypeUser
+	if library.Type == ZoteroLibraryTypeGroup {
+		libraryType = zotero.LibraryTypeGroup
+	}
+
+	opts := []zotero.ClientOption{zotero.WithAPIKey(apiKey)}
+	if baseURL := os.Getenv("ZOTERO_API_BASE_URL"); baseURL != "" {
+		opts = append(opts, zotero.WithBaseURL(baseURL))
+	}
+
+	return zotero.NewClient(library.ID, libraryType, opts...)
+}
+
+// ZoteroKeyInfo describes the Zotero user an API key belongs to
+type ZoteroKeyInfo struct {
+	UserID   int    `json:"userID"`
+	Username string `json:"username"`
+}
+
+// CheckZoteroKey looks up the user apiKey belongs to, a cheap request that
+// fails if the key is rejected or the API cannot be reached
+func CheckZoteroKey(ctx context.Context, apiKey string) (*ZoteroKeyInfo, error) {
+	client := NewZoteroClient(models.ZoteroLibrary{}, apiKey)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, client.BaseURL+"/keys/current", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Zotero request: %w", err)
+	}
+	req.Header.Set("Zotero-API-Key", apiKey)
+	req.Header.Set("Zotero-API-Version", "3")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach the Zotero API: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Zotero API key check failed: status %d", resp.StatusCode)
+	}
+
+	var info ZoteroKeyInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, fmt.Errorf("failed to decode Zotero API key: %w", err)
+	}
+	return &info, nil
+}
+
+// zoteroAttachment holds the fields of a Zotero item that determine how its
+// data is fetched. The zotero package does not decode an attachment's URL or
+// path, so the item is read as raw JSON.
+type zoteroAttachment struct {
+	ItemType    string `json:"itemType"`
+	LinkMode    string `json:"linkMode"`
+	ContentType string `json:"contentType"`
+	URL         string `json:"url"`
+	Path        string `json:"path"`
+}
+
+// describeContentType returns the attachment's content type for error messages
+func (a *zoteroAttachment) describeContentType() string {
+	if a.ContentType == "" {
+		return "unknown content type"
+	}
+	return a.ContentType
+}
+
+// getZoteroAttachment fetches the data fields of a Zotero item
+func getZoteroAttachment(ctx context.Context, client *zotero.Client, key string) (*zoteroAttachment, error) {
+	body, err := getZoteroJSON(ctx, client, "/items/"+key, nil, "item "+key)
+	if err != nil {
+		return nil, err
+	}
+
+	var item struct {
+		Data zoteroAttachment `json:"data"`
+	}
+	if err := json.Unmarshal(body, &item); err != nil {
+		return nil, fmt.Errorf("failed to decode Zotero item %s: %w", key, err)
+	}
+	return &item.Data, nil
+}
+
+// GetZoteroItem fetches a Zotero item with all of its fields. The zotero
+// package decodes only the fields common to every item type, so the item is
+// read as raw JSON and its data fields, such as DOI, ISBN, and publisher, are
+// put in Data.Extra.
+func GetZoteroItem(ctx context.Context, client *zotero.Client, key string) (*zotero.Item, error) {
+	body, err := getZoteroJSON(ctx, client, "/items/"+key, nil, "item "+key)
+	if err != nil {
+		return nil, err
+	}
+	var item zotero.Item
+	if err := decodeZoteroItem(body, &item); err != nil {
+		return nil, fmt.Errorf("failed to decode Zotero item %s: %w", key, err)
+	}
+	return &item, nil
+}
+
+// SearchZoteroItems searches a Zotero library, or one of its collections, with
+// the given parameters and returns the items found with all of their fields,
+// as GetZoteroItem does. Tags and item types are combined with OR, as in the
+// zotero package.
+func SearchZoteroItems(ctx context.Context, client *zotero.Client, collection string, params *zotero.QueryParams) ([]zotero.Item, error) {
+	path, what := "/items", "items"
+	if collection != "" {
+		path, what = "/collections/"+collection+"/items", "collection "+collection
+	}
+
+	query := url.Values{}
+	for name, value := range map[string]string{
+		"q":        params.Q,
+		"qmode":    params.QMode,
+		"tag":      strings.Join(params.Tag, " || "),
+		"itemType": strings.Join(params.ItemType, " || "),
+		"sort":     params.Sort,
+	} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	if params.Limit > 0 {
+		query.Set("limit", strconv.Itoa(params.Limit))
+	}
+
+	body, err := getZoteroJSON(ctx, client, path, query, what)
+	if err != nil {
+		return nil, err
+	}
+	var raw []json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("failed to decode Zotero %s: %w", what, err)
+	}
+	items := make([]zotero.Item, len(raw))
+	for i, data := range raw {
+		if err := decodeZoteroItem(data, &items[i]); err != nil {
+			return nil, fmt.Errorf("failed to decode Zotero %s: %w", what, err)
+		}
+	}
+	return items, nil
+}
+
+// decodeZoteroItem decodes an item from the Zotero API, putting all of its
+// data fields in Data.Extra
+func decodeZoteroItem(data []byte, item *zotero.Item) error {
+	if err := json.Unmarshal(data, item); err != nil {
+		return err
+	}
+	var fields struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	item.Data.Extra = fields.Data
+	return nil
+}
+
+// getZoteroJSON fetches a path of the client's library from the Zotero API,
+// returning ErrorNotFound if it does not exist. what names the object for
+// error messages.
+func getZoteroJSON(ctx context.Context, client *zotero.Client, path string, query url.Values, what string) ([]byte, error) {
+	endpoint := fmt.Sprintf("%s/%s/%s%s", client.BaseURL, client.LibraryType, client.LibraryID, path)
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Zotero request: %w", err)
+	}
+	req.Header.Set("Zotero-API-Key", client.APIKey)
+	req.Header.Set("Zotero-API-Version", "3")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Zotero %s: %w", what, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Zotero %s: %w", what, err)
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return body, nil
+	case http.StatusNotFound:
+		return nil, models.WithErrorCode(models.ErrorNotFound, fmt.Errorf("Zotero %s not found in %s library %s", what, strings.TrimSuffix(string(client.LibraryType), "s"), client.LibraryID))
+	default:
+		return nil, &zoteroStatusError{what: what, status: resp.StatusCode}
+	}
+}
+
+// zoteroStatusError is a Zotero API response with a status other than OK or
+// Not Found
+type zoteroStatusError struct {
+	what   string
+	status int
+}
+
+func (e *zoteroStatusError) Error() string {
+	return fmt.Sprintf("failed to fetch Zotero %s: status %d", e.what, e.status)
+}
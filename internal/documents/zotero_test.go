@@ -0,0 +1,351 @@
+package documents
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+	"github.com/Epistemic-Technology/zotero/zotero"
+)
+
+func TestResolveZoteroLibrary(t *testing.T) {
+	tests := []struct {
+		name        string
+		envType     string
+		envID       string
+		libraryType string
+		libraryID   string
+		want        models.ZoteroLibrary
+		wantErr     bool
+	}{
+		{
+			name:  "Defaults to user library from environment",
+			envID: "111",
+			want:  models.ZoteroLibrary{Type: "user", ID: "111"},
+		},
+		{
+			name:    "Environment library type",
+			envType: "group",
+			envID:   "222",
+			want:    models.ZoteroLibrary{Type: "group", ID: "222"},
+		},
+		{
+			name:        "Explicit parameters override environment",
+			envType:     "user",
+			envID:       "111",
+			libraryType: "group",
+			libraryID:   "333",
+			want:        models.ZoteroLibrary{Type: "group", ID: "333"},
+		},
+		{
+			name:        "Plural and mixed-case library type",
+			libraryType: "Groups",
+			libraryID:   "444",
+			want:        models.ZoteroLibrary{Type: "group", ID: "444"},
+		},
+		{
+			name:        "Invalid library type",
+			libraryType: "team",
+			libraryID:   "555",
+			wantErr:     true,
+		},
+		{
+			name:    "Missing library ID",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ZOTERO_LIBRARY_TYPE", tt.envType)
+			t.Setenv("ZOTERO_LIBRARY_ID", tt.envID)
+
+			got, err := ResolveZoteroLibrary(context.Background(), tt.libraryType, tt.libraryID)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDocumentIDSource(t *testing.T) {
+	t.Setenv("ZOTERO_LIBRARY_TYPE", "")
+	t.Setenv("ZOTERO_LIBRARY_ID", "111")
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		source models.SourceInfo
+		want   models.SourceInfo
+	}{
+		{
+			name:   "Default user library",
+			source: models.SourceInfo{ZoteroID: "KEY", ZoteroLibraryType: "user", ZoteroLibraryID: "111", Pages: models.PageRange{Start: 2}},
+			want:   models.SourceInfo{ZoteroID: "KEY", Pages: models.PageRange{Start: 2}},
+		},
+		{
+			name:   "Another user library",
+			source: models.SourceInfo{ZoteroID: "KEY", ZoteroLibraryType: "user", ZoteroLibraryID: "222"},
+			want:   models.SourceInfo{ZoteroID: "KEY", ZoteroLibraryType: "user", ZoteroLibraryID: "222"},
+		},
+		{
+			name:   "Group with the default library's ID",
+			source: models.SourceInfo{ZoteroID: "KEY", ZoteroLibraryType: "group", ZoteroLibraryID: "111"},
+			want:   models.SourceInfo{ZoteroID: "KEY", ZoteroLibraryType: "group", ZoteroLibraryID: "111"},
+		},
+		{
+			name:   "URL",
+			source: models.SourceInfo{URL: "https://example.com/paper.pdf"},
+			want:   models.SourceInfo{URL: "https://example.com/paper.pdf"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := tt.source
+			if got := DocumentIDSource(ctx, &source); *got != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, *got)
+			}
+			if source != tt.source {
+				t.Errorf("Expected the source to be left unchanged, got %+v", source)
+			}
+		})
+	}
+
+	t.Run("Default group library", func(t *testing.T) {
+		t.Setenv("ZOTERO_LIBRARY_TYPE", "group")
+		source := models.SourceInfo{ZoteroID: "KEY", ZoteroLibraryType: "user", ZoteroLibraryID: "111"}
+		if got := DocumentIDSource(ctx, &source); *got != source {
+			t.Errorf("Expected a user library to keep its library, got %+v", *got)
+		}
+	})
+}
+
+func TestNewZoteroClient(t *testing.T) {
+	t.Setenv("ZOTERO_API_BASE_URL", "http://localhost:1234")
+
+	tests := []struct {
+		library models.ZoteroLibrary
+		want    zotero.LibraryType
+	}{
+		{models.ZoteroLibrary{Type: "user", ID: "1"}, zotero.LibraryTypeUser},
+		{models.ZoteroLibrary{Type: "group", ID: "2"}, zotero.LibraryTypeGroup},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.library.Type, func(t *testing.T) {
+			client := NewZoteroClient(tt.library, "key")
+			if client.LibraryType != tt.want {
+				t.Errorf("Expected library type %s, got %s", tt.want, client.LibraryType)
+			}
+			if client.LibraryID != tt.library.ID {
+				t.Errorf("Expected library ID %s, got %s", tt.library.ID, client.LibraryID)
+			}
+			if client.BaseURL != "http://localhost:1234" {
+				t.Errorf("Expected base URL override, got %s", client.BaseURL)
+			}
+		})
+	}
+}
+
+func TestGetFromZotero_LinkModes(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		items := map[string]string{
+			"PDF1":   `{"key":"PDF1","data":{"itemType":"attachment","linkMode":"imported_file","contentType":"application/pdf"}}`,
+			"SNAP":   `{"key":"SNAP","data":{"itemType":"attachment","linkMode":"imported_url","contentType":"text/html","url":"https://example.com/article"}}`,
+			"LINK":   `{"key":"LINK","data":{"itemType":"attachment","linkMode":"linked_url","contentType":"text/html","url":"` + server.URL + `/article"}}`,
+			"NOURL":  `{"key":"NOURL","data":{"itemType":"attachment","linkMode":"linked_url"}}`,
+			"LOCAL":  `{"key":"LOCAL","data":{"itemType":"attachment","linkMode":"linked_file","contentType":"application/pdf","path":"/Users/someone/paper.pdf"}}`,
+			"PARENT": `{"key":"PARENT","data":{"itemType":"journalArticle","title":"A Paper"}}`,
+		}
+		files := map[string]string{
+			"PDF1": "%PDF-1.4 stored file",
+			"SNAP": "<html><body>Snapshot</body></html>",
+		}
+
+		switch {
+		case r.URL.Path == "/article":
+			w.Write([]byte("<html><body>Linked page</body></html>"))
+		case strings.HasSuffix(r.URL.Path, "/file"):
+			key := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/users/111/items/"), "/file")
+			if file, ok := files[key]; ok {
+				w.Write([]byte(file))
+				return
+			}
+			http.NotFound(w, r)
+		default:
+			if item, ok := items[strings.TrimPrefix(r.URL.Path, "/users/111/items/")]; ok {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(item))
+				return
+			}
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("ZOTERO_API_BASE_URL", server.URL)
+
+	library := models.ZoteroLibrary{Type: "user", ID: "111"}
+
+	tests := []struct {
+		key           string
+		expected      string
+		sourceURL     string
+		contentType   string
+		expectedError []string
+	}{
+		{key: "PDF1", expected: "%PDF-1.4 stored file", sourceURL: server.URL + "/users/111/items/PDF1/file", contentType: "application/pdf"},
+		{key: "SNAP", expected: "Snapshot", sourceURL: server.URL + "/users/111/items/SNAP/file", contentType: "text/html"},
+		{key: "LINK", expected: "Linked page", sourceURL: server.URL + "/article", contentType: "text/html; charset=utf-8"},
+		{key: "NOURL", expectedError: []string{"linked URL", "no URL"}},
+		{key: "LOCAL", expectedError: []string{"linked file", "application/pdf", "/Users/someone/paper.pdf"}},
+		{key: "PARENT", expectedError: []string{"journalArticle", "not an attachment"}},
+		{key: "MISSING", expectedError: []string{"not found"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			data, fetch, err := GetFromZotero(context.Background(), tt.key, "key", library)
+			if len(tt.expectedError) > 0 {
+				if err == nil {
+					t.Fatalf("Expected error, got data %q", data)
+				}
+				for _, want := range tt.expectedError {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("Expected error containing %q, got %v", want, err)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetFromZotero failed: %v", err)
+			}
+			if !strings.Contains(string(data), tt.expected) {
+				t.Errorf("Expected data containing %q, got %q", tt.expected, data)
+			}
+			hash := sha256.Sum256(data)
+			if fetch == nil || fetch.SourceURL != tt.sourceURL || fetch.ContentType != tt.contentType ||
+				fetch.SHA256 != hex.EncodeToString(hash[:]) || fetch.Size != int64(len(data)) || fetch.FetchedAt == "" {
+				t.Errorf("Expected fetch info from %s (%s) for %q, got %+v", tt.sourceURL, tt.contentType, data, fetch)
+			}
+		})
+	}
+}
+
+// fakeZoteroMetadata serves an attachment ATT of parent item PARENT, answering
+// the first failures requests for the parent with status
+func fakeZoteroMetadata(t *testing.T, status int, failures int) *atomic.Int32 {
+	t.Helper()
+	var parentRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/111/items/ATT":
+			w.Write([]byte(`{"key":"ATT","data":{"itemType":"attachment","linkMode":"imported_file","contentType":"application/pdf","parentItem":"PARENT"}}`))
+		case "/users/111/items/ATT/file":
+			w.Write([]byte("%PDF-1.4 stored file"))
+		case "/users/111/items/PARENT":
+			if int(parentRequests.Add(1)) <= failures {
+				w.WriteHeader(status)
+				return
+			}
+			w.Write([]byte(`{"key":"PARENT","data":{"itemType":"journalArticle","title":"Field Notes","creators":[{"creatorType":"author","firstName":"Jane","lastName":"Smith"}],"date":"2019"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("ZOTERO_API_BASE_URL", server.URL)
+	return &parentRequests
+}
+
+func TestFetchZoteroMetadataWithRetry(t *testing.T) {
+	library := models.ZoteroLibrary{Type: "user", ID: "111"}
+	tests := []struct {
+		name          string
+		status        int
+		failures      int
+		expectedCalls int32
+		expectedError []string
+	}{
+		{name: "recovers from server errors", status: http.StatusServiceUnavailable, failures: 2, expectedCalls: 3},
+		{name: "recovers from a rate limit", status: http.StatusTooManyRequests, failures: 1, expectedCalls: 2},
+		{name: "gives up after the last attempt", status: http.StatusBadGateway, failures: 5, expectedCalls: 3, expectedError: []string{"status 502", "after 3 attempts"}},
+		{name: "missing item is not retried", status: http.StatusNotFound, failures: 5, expectedCalls: 1, expectedError: []string{"not found"}},
+		{name: "rejected key is not retried", status: http.StatusForbidden, failures: 5, expectedCalls: 1, expectedError: []string{"status 403"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := fakeZoteroMetadata(t, tt.status, tt.failures)
+			metadata, err := FetchZoteroMetadataWithRetry(context.Background(), "ATT", "key", library)
+			if got := calls.Load(); got != tt.expectedCalls {
+				t.Errorf("Expected %d requests for the parent item, got %d", tt.expectedCalls, got)
+			}
+			if len(tt.expectedError) > 0 {
+				if err == nil {
+					t.Fatalf("Expected error, got %+v", metadata)
+				}
+				for _, want := range tt.expectedError {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("Expected error containing %q, got %v", want, err)
+					}
+				}
+				return
+			}
+			if err != nil || metadata == nil || metadata.Title != "Field Notes" {
+				t.Errorf("Expected the parent's metadata, got %+v, %v", metadata, err)
+			}
+		})
+	}
+
+	t.Run("cancelled while waiting", func(t *testing.T) {
+		fakeZoteroMetadata(t, http.StatusServiceUnavailable, 5)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		if _, err := FetchZoteroMetadataWithRetry(ctx, "ATT", "key", library); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected the deadline error, got %v", err)
+		}
+	})
+}
+
+func TestGetDataWithMetadata_ZoteroMetadataWarning(t *testing.T) {
+	t.Setenv("ZOTERO_API_KEY", "key")
+	source := models.SourceInfo{ZoteroID: "ATT", ZoteroLibraryType: "user", ZoteroLibraryID: "111"}
+
+	// Zotero serves the file but not the metadata: the data comes with a warning
+	fakeZoteroMetadata(t, http.StatusInternalServerError, 5)
+	data, metadata, warning, err := GetDataWithMetadata(context.Background(), source)
+	if err != nil {
+		t.Fatalf("GetDataWithMetadata failed: %v", err)
+	}
+	if data.Type != "pdf" || metadata != nil {
+		t.Errorf("Expected the PDF without metadata, got %s and %+v", data.Type, metadata)
+	}
+	if warning == nil || warning.Source != "zotero" || !strings.Contains(warning.Message, "status 500") {
+		t.Errorf("Expected a Zotero metadata warning, got %+v", warning)
+	}
+
+	// A brief failure is retried away
+	fakeZoteroMetadata(t, http.StatusInternalServerError, 1)
+	_, metadata, warning, err = GetDataWithMetadata(context.Background(), source)
+	if err != nil || warning != nil || metadata == nil || metadata.Title != "Field Notes" {
+		t.Errorf("Expected the metadata after a retry, got %+v (warning %+v, error %v)", metadata, warning, err)
+	}
+}
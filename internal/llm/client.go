@@ -0,0 +1,59 @@
+package llm
+
+import (
+	"context"
+	"strings"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/config"
+)
+
+// defaultAzureAPIVersion is the Azure OpenAI API version requests are sent
+// with when AZURE_OPENAI_API_VERSION is not set; it is the first to serve the
+// Responses API
+const defaultAzureAPIVersion = "2025-03-01-preview"
+
+// newClient returns an OpenAI client for apiKey, sending its requests where the
+// config provider in ctx says (see clientOptions). Every function of the
+// package creates its client here, so the settings apply to all of them. opts
+// are applied last.
+func newClient(ctx context.Context, apiKey string, opts ...option.RequestOption) openai.Client {
+	return openai.NewClient(append(clientOptions(config.FromContext(ctx).Credentials(), apiKey), opts...)...)
+}
+
+// clientOptions returns the options that authenticate with apiKey and point a
+// client at the configured endpoint. With an Azure OpenAI endpoint, requests go
+// to its /openai/ path with the api-version query parameter, and the key is
+// sent in the api-key header; the model of each request names the deployment,
+// so deployments must be named after the models (see Models). Otherwise the
+// key is a bearer token, and requests go to OPENAI_BASE_URL if it is set, with
+// the organization and project headers if those are.
+func clientOptions(credentials config.Credentials, apiKey string) []option.RequestOption {
+	if endpoint := strings.TrimSpace(credentials.AzureOpenAIEndpoint); endpoint != "" {
+		apiVersion := credentials.AzureOpenAIAPIVersion
+		if apiVersion == "" {
+			apiVersion = defaultAzureAPIVersion
+		}
+		return []option.RequestOption{
+			option.WithBaseURL(strings.TrimSuffix(endpoint, "/") + "/openai/"),
+			option.WithQueryAdd("api-version", apiVersion),
+			// The SDK sends OPENAI_API_KEY from the environment as a bearer token
+			option.WithHeaderDel("authorization"),
+			option.WithHeader("api-key", apiKey),
+		}
+	}
+
+	opts := []option.RequestOption{option.WithAPIKey(apiKey)}
+	if credentials.OpenAIBaseURL != "" {
+		opts = append(opts, option.WithBaseURL(credentials.OpenAIBaseURL))
+	}
+	if credentials.OpenAIOrganization != "" {
+		opts = append(opts, option.WithOrganization(credentials.OpenAIOrganization))
+	}
+	if credentials.OpenAIProject != "" {
+		opts = append(opts, option.WithProject(credentials.OpenAIProject))
+	}
+	return opts
+}
@@ -0,0 +1,103 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/config"
+)
+
+func TestNewClient_Options(t *testing.T) {
+	var mu sync.Mutex
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"object": "list", "data": [{"id": "gpt-5-mini", "object": "model"}]}`))
+	}))
+	defer server.Close()
+
+	// The SDK reads these itself; the provider's settings must win over them
+	t.Setenv("OPENAI_API_KEY", "env-key")
+	t.Setenv("OPENAI_BASE_URL", "http://127.0.0.1:1/unused/")
+	t.Setenv("OPENAI_ORG_ID", "")
+	t.Setenv("OPENAI_PROJECT_ID", "")
+
+	tests := []struct {
+		name      string
+		settings  string
+		path      string
+		query     string
+		headers   map[string]string
+		noHeaders []string
+	}{
+		{
+			name:      "gateway with organization and project",
+			settings:  `{"OPENAI_BASE_URL": "` + server.URL + `/gateway/v1/", "OPENAI_ORG_ID": "org-123", "OPENAI_PROJECT": "proj-456"}`,
+			path:      "/gateway/v1/models",
+			headers:   map[string]string{"Authorization": "Bearer sk-test", "OpenAI-Organization": "org-123", "OpenAI-Project": "proj-456"},
+			noHeaders: []string{"Api-Key"},
+		},
+		{
+			name:      "Azure with the default API version",
+			settings:  `{"AZURE_OPENAI_ENDPOINT": "` + server.URL + `", "OPENAI_BASE_URL": "http://127.0.0.1:1/ignored/"}`,
+			path:      "/openai/models",
+			query:     "api-version=" + defaultAzureAPIVersion,
+			headers:   map[string]string{"Api-Key": "sk-test"},
+			noHeaders: []string{"Authorization"},
+		},
+		{
+			name:     "Azure with an API version",
+			settings: `{"AZURE_OPENAI_ENDPOINT": "` + server.URL + `/", "AZURE_OPENAI_API_VERSION": "2025-04-01-preview"}`,
+			path:     "/openai/models",
+			query:    "api-version=2025-04-01-preview",
+			headers:  map[string]string{"Api-Key": "sk-test"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(path, []byte(tt.settings), 0600); err != nil {
+				t.Fatal(err)
+			}
+			provider, err := config.NewProvider(path)
+			if err != nil {
+				t.Fatalf("NewProvider failed: %v", err)
+			}
+			mu.Lock()
+			requests = nil
+			mu.Unlock()
+
+			count, err := CheckAPIKey(config.NewContext(context.Background(), provider), "sk-test")
+			if err != nil || count != 1 {
+				t.Fatalf("CheckAPIKey = %d, %v", count, err)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if len(requests) != 1 {
+				t.Fatalf("Expected 1 request, got %d", len(requests))
+			}
+			r := requests[0]
+			if r.URL.Path != tt.path || r.URL.RawQuery != tt.query {
+				t.Errorf("Expected request to %s?%s, got %s", tt.path, tt.query, r.URL)
+			}
+			for name, value := range tt.headers {
+				if got := r.Header.Get(name); got != value {
+					t.Errorf("Expected header %s %q, got %q", name, value, got)
+				}
+			}
+			for _, name := range tt.noHeaders {
+				if got := r.Header.Get(name); got != "" {
+					t.Errorf("Expected no %s header, got %q", name, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/responses"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+)
+
+// invalidJSONCorrection is sent with the retry when a response could not be decoded or repaired
+const invalidJSONCorrection = "Your previous output was invalid JSON (%v). Respond again with a single complete JSON object that matches the schema, with no other text."
+
+// StructuredOutputStats counts how often structured model output needed recovery
+type StructuredOutputStats struct {
+	Responses int64 `json:"responses"`
+	Repaired  int64 `json:"repaired"`
+	Retried   int64 `json:"retried"`
+	Failed    int64 `json:"failed"`
+	Truncated int64 `json:"truncated"` // Responses cut short by the output token limit
+}
+
+var structuredOutputCounters struct {
+	responses, repaired, retried, failed, truncated atomic.Int64
+}
+
+// GetStructuredOutputStats returns the recovery counts since the process started
+func GetStructuredOutputStats() StructuredOutputStats {
+	return StructuredOutputStats{
+		Responses: structuredOutputCounters.responses.Load(),
+		Repaired:  structuredOutputCounters.repaired.Load(),
+		Retried:   structuredOutputCounters.retried.Load(),
+		Failed:    structuredOutputCounters.failed.Load(),
+		Truncated: structuredOutputCounters.truncated.Load(),
+	}
+}
+
+// newStructuredResponse calls the Responses API and decodes its JSON output into T.
+// If the output cannot be decoded or repaired, the call is retried once with a
+// correction message appended to the input.
+func newStructuredResponse[T any](ctx context.Context, client *openai.Client, params responses.ResponseNewParams, name string, log logger.Logger) (T, error) {
+	result, _, err := newStructuredResponseWithTruncation[T](ctx, client, params, name, log)
+	return result, err
+}
+
+// newStructuredResponseWithTruncation is newStructuredResponse, also reporting
+// whether the decoded output was cut short (see outputTruncated). Repair keeps
+// what a truncated output completed, so the result is usable but may be
+// missing the elements the model did not get to.
+func newStructuredResponseWithTruncation[T any](ctx context.Context, client *openai.Client, params responses.ResponseNewParams, name string, log logger.Logger) (T, bool, error) {
+	var truncated bool
+	result, err := decodeStructuredOutput[T](ctx, name, log, func(ctx context.Context, correction string) (string, error) {
+		request := params
+		if correction != "" {
+			input := append(responses.ResponseInputParam{}, params.Input.OfInputItemList...)
+			input = append(input, responses.ResponseInputItemParamOfMessage(
+				responses.ResponseInputMessageContentListParam{
+					responses.ResponseInputContentParamOfInputText(correction),
+				},
+				"user",
+			))
+			request.Input = responses.ResponseNewParamsInputUnion{OfInputItemList: input}
+		}
+		response, err := client.Responses.New(ctx, request)
+		if err != nil {
+			return "", err
+		}
+		recordUsage(ctx, request.Model, response.Usage)
+		output := response.OutputText()
+		truncated = outputTruncated(string(response.Status), response.IncompleteDetails.Reason, output)
+		return output, nil
+	})
+	if err == nil && truncated {
+		count := structuredOutputCounters.truncated.Add(1)
+		log.Warn("Truncated %s output (%d truncated in %d responses)", name, count, structuredOutputCounters.responses.Load())
+	}
+	return result, truncated, err
+}
+
+// decodeStructuredOutput decodes the output of call into T, repairing malformed
+// JSON where possible and otherwise calling again with a correction message.
+// call receives an empty correction on the first attempt.
+func decodeStructuredOutput[T any](ctx context.Context, name string, log logger.Logger, call func(ctx context.Context, correction string) (string, error)) (T, error) {
+	var zero T
+	structuredOutputCounters.responses.Add(1)
+
+	output, err := call(ctx, "")
+	if err != nil {
+		return zero, err
+	}
+	result, decodeErr := decodeJSONOutput[T](output, name, log)
+	if decodeErr == nil {
+		return result, nil
+	}
+
+	retries := structuredOutputCounters.retried.Add(1)
+	log.Warn("Invalid JSON in %s output, retrying with correction (%d retries in %d responses): %v",
+		name, retries, structuredOutputCounters.responses.Load(), decodeErr)
+	output, err = call(ctx, fmt.Sprintf(invalidJSONCorrection, decodeErr))
+	if err != nil {
+		structuredOutputCounters.failed.Add(1)
+		return zero, err
+	}
+	result, err = decodeJSONOutput[T](output, name, log)
+	if err != nil {
+		structuredOutputCounters.failed.Add(1)
+		return zero, fmt.Errorf("invalid JSON in %s output after retry: %w", name, err)
+	}
+	return result, nil
+}
+
+// decodeJSONOutput unmarshals output into T, falling back to repairJSON
+func decodeJSONOutput[T any](output string, name string, log logger.Logger) (T, error) {
+	var result T
+	err := json.Unmarshal([]byte(output), &result)
+	if err == nil {
+		return result, nil
+	}
+
+	repaired, ok := repairJSON(output)
+	if !ok {
+		return result, err
+	}
+	var repairedResult T
+	if json.Unmarshal([]byte(repaired), &repairedResult) != nil {
+		return result, err
+	}
+	repairs := structuredOutputCounters.repaired.Add(1)
+	log.Warn("Repaired malformed JSON in %s output (%d repairs in %d responses): %v",
+		name, repairs, structuredOutputCounters.responses.Load(), err)
+	return repairedResult, nil
+}
+
+// repairJSON makes a best-effort attempt to turn malformed model output into
+// valid JSON. It strips markdown code fences and text around the JSON value,
+// removes trailing commas, and truncates an incomplete value to its last
+// complete element before closing any open objects and arrays. It reports
+// false if nothing resembling JSON could be recovered.
+func repairJSON(output string) (string, bool) {
+	text := stripCodeFences(output)
+
+	start := strings.IndexAny(text, "{[")
+	if start < 0 {
+		return "", false
+	}
+	text = text[start:]
+
+	scan, ok := scanJSONValue(text)
+	if !ok {
+		return "", false
+	}
+	if scan.end >= 0 {
+		text = text[:scan.end]
+	} else {
+		text = text[:scan.cutAt] + scan.cutClosers
+	}
+	return removeTrailingCommas(text), true
+}
+
+// isTruncatedJSON reports whether output holds the start of a JSON value that
+// never closes, as when the model stopped in the middle of it
+func isTruncatedJSON(output string) bool {
+	text := stripCodeFences(output)
+	start := strings.IndexAny(text, "{[")
+	if start < 0 {
+		return false
+	}
+	scan, ok := scanJSONValue(text[start:])
+	return ok && scan.end < 0
+}
+
+// jsonScan is where a JSON value ends or, if it is incomplete, the last point
+// where it could be cut and closed
+type jsonScan struct {
+	end        int    // Just past the value's closing bracket, or -1 if it has none
+	cutAt      int    // Where an incomplete value can be cut back to its last complete element
+	cutClosers string // The brackets that close the value cut at cutAt
+}
+
+// scanJSONValue scans text, which starts with an object or array, for the end
+// of that value. It reports false if a bracket closes one of another kind.
+func scanJSONValue(text string) (jsonScan, bool) {
+	var (
+		stack    []byte // closers for the open objects and arrays
+		inString bool
+		escaped  bool
+		scan     = jsonScan{end: -1}
+	)
+	markCut := func(i int) {
+		scan.cutAt = i
+		scan.cutClosers = closers(stack)
+	}
+	for i := 0; i < len(text) && scan.end < 0; i++ {
+		c := text[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			// Only the outermost value may be cut back to empty; an empty nested
+			// element would stand in for one the model never finished
+			if c == '{' {
+				stack = append(stack, '}')
+			} else {
+				stack = append(stack, ']')
+			}
+			if len(stack) == 1 {
+				markCut(i + 1)
+			}
+		case '}', ']':
+			if len(stack) == 0 || stack[len(stack)-1] != c {
+				return scan, false
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				scan.end = i + 1
+			} else {
+				markCut(i + 1)
+			}
+		case ',':
+			markCut(i)
+		}
+	}
+	return scan, true
+}
+
+// stripCodeFences removes a markdown code fence wrapped around the output
+func stripCodeFences(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "```") {
+		return text
+	}
+	// Drop the opening fence line, including any language tag
+	if newline := strings.IndexByte(text, '\n'); newline >= 0 {
+		text = text[newline+1:]
+	} else {
+		text = strings.TrimLeft(text, "`")
+	}
+	if fence := strings.LastIndex(text, "```"); fence >= 0 {
+		text = text[:fence]
+	}
+	return strings.TrimSpace(text)
+}
+
+// closers returns the closing brackets for stack, innermost first
+func closers(stack []byte) string {
+	var b strings.Builder
+	for i := len(stack) - 1; i >= 0; i-- {
+		b.WriteByte(stack[i])
+	}
+	return b.String()
+}
+
+// removeTrailingCommas drops commas that directly precede a closing bracket,
+// leaving string contents untouched
+func removeTrailingCommas(text string) string {
+	var b strings.Builder
+	inString, escaped := false, false
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			b.WriteByte(c)
+			continue
+		}
+		if c == '"' {
+			inString = true
+		}
+		if c == ',' {
+			next := strings.TrimLeft(text[i+1:], " \t\r\n")
+			if next == "" || next[0] == '}' || next[0] == ']' {
+				continue
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,197 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestRepairJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected string // compared as decoded JSON; empty means repair should fail
+	}{
+		{
+			name:     "json code fence",
+			output:   "```json\n{\"content\": \"Page text\", \"references\": []}\n```",
+			expected: `{"content": "Page text", "references": []}`,
+		},
+		{
+			name:     "bare code fence",
+			output:   "```\n[1, 2, 3]\n```",
+			expected: `[1, 2, 3]`,
+		},
+		{
+			name:     "surrounding prose",
+			output:   "Here is the result:\n{\"quotations\": []}\nLet me know if you need more.",
+			expected: `{"quotations": []}`,
+		},
+		{
+			name:     "trailing commas",
+			output:   `{"authors": ["A. Smith", "B. Jones",], "title": "T",}`,
+			expected: `{"authors": ["A. Smith", "B. Jones"], "title": "T"}`,
+		},
+		{
+			name:     "comma inside string is kept",
+			output:   `{"text": "a, ]", "n": 1,}`,
+			expected: `{"text": "a, ]", "n": 1}`,
+		},
+		{
+			name:     "truncated after complete element",
+			output:   `{"quotations": [{"quotation_text": "One", "page_number": "1"}, {"quotation_text": "Tw`,
+			expected: `{"quotations": [{"quotation_text": "One", "page_number": "1"}]}`,
+		},
+		{
+			name:     "truncated after comma",
+			output:   `{"content": "Text", "references": [{"reference_text": "R1", "doi": ""},`,
+			expected: `{"content": "Text", "references": [{"reference_text": "R1", "doi": ""}]}`,
+		},
+		{
+			name:     "truncated mid-key",
+			output:   `{"content": "Text", "refere`,
+			expected: `{"content": "Text"}`,
+		},
+		{
+			name:     "truncated in nested object",
+			output:   `{"metadata": {"title": "T", "authors": ["A", "B"`,
+			expected: `{"metadata": {"title": "T", "authors": ["A"]}}`,
+		},
+		{
+			name:     "escaped quote in truncated string",
+			output:   `{"content": "Text", "caption": "He said \"hi`,
+			expected: `{"content": "Text"}`,
+		},
+		{
+			name:   "no JSON",
+			output: "I'm sorry, I can't help with that.",
+		},
+		{
+			name:   "mismatched brackets",
+			output: `{"a": [1, 2}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repaired, ok := repairJSON(tt.output)
+			if tt.expected == "" {
+				if ok {
+					t.Errorf("repairJSON(%q) = %q, expected failure", tt.output, repaired)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("repairJSON(%q) failed", tt.output)
+			}
+			var got, want any
+			if err := json.Unmarshal([]byte(repaired), &got); err != nil {
+				t.Fatalf("repaired output %q is not valid JSON: %v", repaired, err)
+			}
+			if err := json.Unmarshal([]byte(tt.expected), &want); err != nil {
+				t.Fatalf("invalid expected JSON: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("repairJSON(%q) = %s, want %s", tt.output, repaired, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDecodeStructuredOutput(t *testing.T) {
+	log := logger.NewNoOpLogger()
+	valid := `{"quotations": [{"quotation_text": "Q", "page_number": "3", "context": "", "relevance": ""}]}`
+
+	tests := []struct {
+		name          string
+		outputs       []string
+		expectedCalls int
+		expectError   bool
+	}{
+		{"valid output", []string{valid}, 1, false},
+		{"repaired without retry", []string{"```json\n" + valid + "\n```"}, 1, false},
+		{"retried after unrepairable output", []string{"not json", valid}, 2, false},
+		{"invalid after retry", []string{"not json", "still not json"}, 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var corrections []string
+			call := func(ctx context.Context, correction string) (string, error) {
+				corrections = append(corrections, correction)
+				return tt.outputs[len(corrections)-1], nil
+			}
+
+			result, err := decodeStructuredOutput[quotationsResult](context.Background(), "test", log, call)
+			if len(corrections) != tt.expectedCalls {
+				t.Errorf("Expected %d calls, got %d", tt.expectedCalls, len(corrections))
+			}
+			if corrections[0] != "" {
+				t.Errorf("First call should have no correction, got %q", corrections[0])
+			}
+			if len(corrections) > 1 && !strings.Contains(corrections[1], "invalid JSON") {
+				t.Errorf("Retry should include a correction message, got %q", corrections[1])
+			}
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			want := []models.Quotation{{QuotationText: "Q", PageNumber: "3"}}
+			if !reflect.DeepEqual(result.Quotations, want) {
+				t.Errorf("Expected %+v, got %+v", want, result.Quotations)
+			}
+		})
+	}
+
+	t.Run("call error is not retried", func(t *testing.T) {
+		calls := 0
+		_, err := decodeStructuredOutput[quotationsResult](context.Background(), "test", log, func(ctx context.Context, correction string) (string, error) {
+			calls++
+			return "", errors.New("api error")
+		})
+		if err == nil || calls != 1 {
+			t.Errorf("Expected one failed call, got %d calls and error %v", calls, err)
+		}
+	})
+}
+
+func TestGetStructuredOutputStats(t *testing.T) {
+	log := logger.NewNoOpLogger()
+	before := GetStructuredOutputStats()
+
+	outputs := []string{`{"quotations": [],}`}
+	decodeStructuredOutput[quotationsResult](context.Background(), "test", log, func(ctx context.Context, correction string) (string, error) {
+		return outputs[0], nil
+	})
+	outputs = []string{"nope", "nope"}
+	calls := 0
+	decodeStructuredOutput[quotationsResult](context.Background(), "test", log, func(ctx context.Context, correction string) (string, error) {
+		calls++
+		return outputs[calls-1], nil
+	})
+
+	after := GetStructuredOutputStats()
+	if got := after.Responses - before.Responses; got != 2 {
+		t.Errorf("Expected 2 responses counted, got %d", got)
+	}
+	if got := after.Repaired - before.Repaired; got != 1 {
+		t.Errorf("Expected 1 repair counted, got %d", got)
+	}
+	if got := after.Retried - before.Retried; got != 1 {
+		t.Errorf("Expected 1 retry counted, got %d", got)
+	}
+	if got := after.Failed - before.Failed; got != 1 {
+		t.Errorf("Expected 1 failure counted, got %d", got)
+	}
+}
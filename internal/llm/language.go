@@ -0,0 +1,42 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
+)
+
+// dominantLanguage returns the most common of the languages detected on a
+// document's pages or chunks, normalized to ISO 639-1 codes. Ties go to the
+// language seen first; unrecognized values are ignored.
+func dominantLanguage(languages []string) string {
+	counts := make(map[string]int)
+	dominant := ""
+	for _, language := range languages {
+		code := documents.NormalizeLanguage(language)
+		if code == "" {
+			continue
+		}
+		counts[code]++
+		if counts[code] > counts[dominant] {
+			dominant = code
+		}
+	}
+	return dominant
+}
+
+// targetLanguageInstruction returns the prompt lines asking for output in a
+// target language, or an empty string when none is requested
+func targetLanguageInstruction(targetLanguage string, quotations bool) string {
+	targetLanguage = strings.TrimSpace(targetLanguage)
+	if targetLanguage == "" {
+		return ""
+	}
+	if !quotations {
+		return fmt.Sprintf("\n\nWrite the summary in this language: %s, whatever the language of the text.", targetLanguage)
+	}
+	return fmt.Sprintf(`
+
+Write context and relevance in this language: %[1]s. Keep quotation_text verbatim in the document's original language; never translate it. In translation, give a faithful translation of the quotation into %[1]s.`, targetLanguage)
+}
@@ -0,0 +1,160 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// fakeResponses serves the OpenAI Responses API, answering every request with
+// output and recording the request bodies
+func fakeResponses(t *testing.T, output string) *[]string {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, string(body))
+		mu.Unlock()
+
+		text, _ := json.Marshal(output)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"resp_1","object":"response","created_at":0,"status":"completed","model":"gpt-5-mini",` +
+			`"output":[{"type":"message","id":"msg_1","status":"completed","role":"assistant","content":[{"type":"output_text","text":` + string(text) + `,"annotations":[]}]}],` +
+			`"usage":{"input_tokens":100,"output_tokens":20,"total_tokens":120}}`))
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("OPENAI_BASE_URL", server.URL)
+	return &requests
+}
+
+func TestDominantLanguage(t *testing.T) {
+	tests := []struct {
+		languages []string
+		expected  string
+	}{
+		{nil, ""},
+		{[]string{"", "unknown"}, ""},
+		{[]string{"de", "de-DE", "en"}, "de"},
+		{[]string{"en", "fr", "fr"}, "fr"},
+		{[]string{"en", "de"}, "en"},
+	}
+	for _, tt := range tests {
+		if got := dominantLanguage(tt.languages); got != tt.expected {
+			t.Errorf("dominantLanguage(%q) = %q, want %q", tt.languages, got, tt.expected)
+		}
+	}
+
+	item := mergeTextChunks([]*textParseResult{
+		{Metadata: models.ItemMetadata{Title: "Ein Bericht", Language: "en"}},
+		{Metadata: models.ItemMetadata{Language: "de"}},
+		{Metadata: models.ItemMetadata{Language: "German"}},
+	})
+	if item.Metadata.Language != "de" {
+		t.Errorf("Expected the most common chunk language, got %q", item.Metadata.Language)
+	}
+}
+
+func TestTargetLanguagePrompts(t *testing.T) {
+	if prompt, err := buildSummaryPrompt("Text", SummaryOptions{}); err != nil || strings.Contains(prompt, "Write the summary in this language") {
+		t.Errorf("Expected no language instruction without a target, got (%v):\n%s", err, prompt)
+	}
+	if prompt, err := buildSummaryPrompt("Text", SummaryOptions{TargetLanguage: "English", Style: "brief"}); err != nil || !strings.Contains(prompt, "Write the summary in this language: English") || !strings.HasSuffix(prompt, "\n\nText") {
+		t.Errorf("Expected an English instruction before the content, got (%v):\n%s", err, prompt)
+	}
+	if _, err := buildSummaryPrompt("Text", SummaryOptions{Style: "haiku"}); err == nil {
+		t.Error("Expected an error for an unknown style")
+	}
+
+	opts := QuotationOptions{TargetLanguage: "English"}
+	for _, prompt := range []string{
+		buildPageQuotationPrompt("Titel", "Zusammenfassung", "12", "Seitentext", opts),
+		buildFullTextQuotationPrompt("Titel", "Zusammenfassung", "Volltext", opts),
+	} {
+		if !strings.Contains(prompt, "Keep quotation_text verbatim in the document's original language") || !strings.Contains(prompt, "translation of the quotation into English") {
+			t.Errorf("Expected translation instructions, got:\n%s", prompt)
+		}
+	}
+	if prompt := buildPageQuotationPrompt("Title", "Summary", "12", "Page text", QuotationOptions{}); strings.Contains(prompt, "translation") {
+		t.Errorf("Expected no translation instructions without a target, got:\n%s", prompt)
+	}
+}
+
+func TestBuildQuotationSchema(t *testing.T) {
+	items := func(schema map[string]any) map[string]any {
+		return schema["properties"].(map[string]any)["quotations"].(map[string]any)["items"].(map[string]any)
+	}
+	plain := items(buildQuotationSchema(false))
+	if _, ok := plain["properties"].(map[string]any)["translation"]; ok {
+		t.Error("Expected no translation field without a target language")
+	}
+	translated := items(buildQuotationSchema(true))
+	if _, ok := translated["properties"].(map[string]any)["translation"]; !ok {
+		t.Error("Expected a translation field")
+	}
+	if required := translated["required"].([]string); required[len(required)-1] != "translation" {
+		t.Errorf("Expected translation to be required, got %v", required)
+	}
+}
+
+func TestSummarizeItem_TargetLanguage(t *testing.T) {
+	requests := fakeResponses(t, "The study examines archival records.")
+
+	item := &models.ParsedItem{Metadata: models.ItemMetadata{Title: "Archivstudien", Language: "de"}, Pages: []string{"Die Studie untersucht Archivbestände."}}
+	summary, err := SummarizeItem(context.Background(), "test-key", item, SummaryOptions{TargetLanguage: "English"}, logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("SummarizeItem failed: %v", err)
+	}
+	if summary != "The study examines archival records." {
+		t.Errorf("Unexpected summary %q", summary)
+	}
+	if len(*requests) != 1 || !strings.Contains((*requests)[0], "Write the summary in this language: English") || !strings.Contains((*requests)[0], "Archivbestände") {
+		t.Errorf("Expected one request with the instruction and the content, got %q", *requests)
+	}
+}
+
+func TestExtractQuotations_TargetLanguage(t *testing.T) {
+	requests := fakeResponses(t, `{"quotations":[{"quotation_text":"Das Archiv spricht nicht von selbst.","page_number":"","context":"In the introduction","relevance":"States the book's premise","translation":"The archive does not speak for itself."}]}`)
+
+	item := &models.ParsedItem{
+		Metadata: models.ItemMetadata{Title: "Archivstudien", Language: "de"},
+		Pages:    []string{"Das Archiv spricht nicht von selbst. Es muss befragt werden."},
+	}
+	quotations, err := ExtractQuotations(context.Background(), "test-key", item, "Eine Zusammenfassung", QuotationOptions{TargetLanguage: " English "}, logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("ExtractQuotations failed: %v", err)
+	}
+	if len(quotations) != 1 || quotations[0].QuotationText != "Das Archiv spricht nicht von selbst." || quotations[0].Translation != "The archive does not speak for itself." {
+		t.Errorf("Expected the verbatim quotation with its translation, got %+v", quotations)
+	}
+
+	var request struct {
+		Text struct {
+			Format struct {
+				Schema map[string]any `json:"schema"`
+			} `json:"format"`
+		} `json:"text"`
+	}
+	if len(*requests) != 1 {
+		t.Fatalf("Expected one request, got %d", len(*requests))
+	}
+	if err := json.Unmarshal([]byte((*requests)[0]), &request); err != nil {
+		t.Fatalf("Failed to decode request: %v", err)
+	}
+	properties := request.Text.Format.Schema["properties"].(map[string]any)["quotations"].(map[string]any)["items"].(map[string]any)["properties"].(map[string]any)
+	if _, ok := properties["translation"]; !ok {
+		t.Errorf("Expected the request schema to ask for translations, got %v", properties)
+	}
+	if !strings.Contains((*requests)[0], "Write context and relevance in this language: English") {
+		t.Error("Expected the request prompt to ask for English context and relevance")
+	}
+}
@@ -0,0 +1,65 @@
+package llm
+
+import (
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// consolidateNotes gathers the footnotes and endnotes of per-page parses in
+// page order. A note cut off by a page break arrives as two entries, the rest
+// coming first on the next page, which are joined (see isNoteContinuation).
+// Endnotes are then given their keys (documents.AssignEndnoteKeys).
+func consolidateNotes(pages []*models.ParsedPage) ([]models.Footnote, []models.Endnote) {
+	footnotes := joinNoteContinuations(pages,
+		func(page *models.ParsedPage) []models.Footnote { return page.Footnotes },
+		func(note *models.Footnote) (string, *string) { return note.Marker, &note.Text })
+	endnotes := joinNoteContinuations(pages,
+		func(page *models.ParsedPage) []models.Endnote { return page.Endnotes },
+		func(note *models.Endnote) (string, *string) { return note.Marker, &note.Text })
+	documents.AssignEndnoteKeys(endnotes)
+	return footnotes, endnotes
+}
+
+// joinNoteContinuations collects the notes of pages, appending the first note
+// on a page to the last note on the page before when it continues it. fields
+// returns a note's marker and its text to join onto.
+func joinNoteContinuations[T any](pages []*models.ParsedPage, notesOf func(*models.ParsedPage) []T, fields func(*T) (string, *string)) []T {
+	result := make([]T, 0)
+	lastPage := -1 // Page of the last note in result
+	for i, page := range pages {
+		if page == nil {
+			continue
+		}
+		notes := notesOf(page)
+		for j := range notes {
+			note := notes[j]
+			if j == 0 && lastPage == i-1 && len(result) > 0 {
+				prevMarker, prevText := fields(&result[len(result)-1])
+				marker, text := fields(&note)
+				if isNoteContinuation(prevMarker, *prevText, marker, *text) {
+					*prevText = joinReferenceText(*prevText, *text)
+					continue
+				}
+			}
+			result = append(result, note)
+		}
+		if len(notes) > 0 {
+			lastPage = i
+		}
+	}
+	return result
+}
+
+// isNoteContinuation reports whether a note at the top of a page is the rest of
+// the note ending the page before: it has no marker of its own, or repeats the
+// marker of a note that stops mid-sentence
+func isNoteContinuation(prevMarker, prevText, marker, text string) bool {
+	marker = documents.NormalizeNoteMarker(marker)
+	if text == "" {
+		return false
+	}
+	if marker == "" {
+		return true
+	}
+	return marker == documents.NormalizeNoteMarker(prevMarker) && isIncompleteReference(prevText)
+}
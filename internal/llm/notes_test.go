@@ -0,0 +1,79 @@
+package llm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestConsolidateNotes(t *testing.T) {
+	pages := []*models.ParsedPage{
+		{Endnotes: []models.Endnote{
+			{Marker: "1", Text: "See the archive."},
+			{Marker: "2", Text: "On the history of the collec-"},
+		}},
+		// The rest of note 2, without a marker
+		{Endnotes: []models.Endnote{
+			{Text: "tion, see Smith (2001)."},
+			{Marker: "3", Text: "Ibid., 45, and the letters of"},
+		}},
+		// The rest of note 3, its marker repeated, then chapter 2's notes
+		{Endnotes: []models.Endnote{
+			{Marker: "3", Text: "the period."},
+			{Marker: "1", Text: "A new chapter."},
+		}},
+		nil,
+		// Not a continuation: the page before failed to parse
+		{Endnotes: []models.Endnote{
+			{Text: "Unmarked note after a gap."},
+			{Marker: "2", Text: "Complete note."},
+		}},
+		// A repeated marker after a complete note is a new note
+		{Endnotes: []models.Endnote{{Marker: "2", Text: "ibid."}}},
+	}
+
+	_, endnotes := consolidateNotes(pages)
+	expected := []models.Endnote{
+		{Marker: "1", Key: "ch1-n1", Text: "See the archive."},
+		{Marker: "2", Key: "ch1-n2", Text: "On the history of the collection, see Smith (2001)."},
+		{Marker: "3", Key: "ch1-n3", Text: "Ibid., 45, and the letters of the period."},
+		{Marker: "1", Key: "ch2-n1", Text: "A new chapter."},
+		{Key: "ch2-u1", Text: "Unmarked note after a gap."},
+		{Marker: "2", Key: "ch2-n2", Text: "Complete note."},
+		{Marker: "2", Key: "ch2-n2-2", Text: "ibid."},
+	}
+	if !reflect.DeepEqual(endnotes, expected) {
+		t.Errorf("Unexpected endnotes:\n got %+v\nwant %+v", endnotes, expected)
+	}
+}
+
+func TestConsolidateNotes_Footnotes(t *testing.T) {
+	pages := []*models.ParsedPage{
+		{Footnotes: []models.Footnote{
+			{Marker: "*", Text: "A note that runs over", PageNumber: "10"},
+		}},
+		{},
+		// Page 11 has no notes, so page 12's unmarked note is its own
+		{Footnotes: []models.Footnote{
+			{Text: "the page break.", PageNumber: "12"},
+			{Marker: "*", Text: "Another note.", PageNumber: "12"},
+		}},
+		{Footnotes: []models.Footnote{
+			{Text: "and continues here.", PageNumber: "13"},
+		}},
+	}
+
+	footnotes, endnotes := consolidateNotes(pages)
+	expected := []models.Footnote{
+		{Marker: "*", Text: "A note that runs over", PageNumber: "10"},
+		{Text: "the page break.", PageNumber: "12"},
+		{Marker: "*", Text: "Another note. and continues here.", PageNumber: "12"},
+	}
+	if !reflect.DeepEqual(footnotes, expected) {
+		t.Errorf("Unexpected footnotes:\n got %+v\nwant %+v", footnotes, expected)
+	}
+	if endnotes == nil || len(endnotes) != 0 {
+		t.Errorf("Expected an empty endnote list, got %+v", endnotes)
+	}
+}
@@ -3,7 +3,6 @@ package llm
 import (
 	"context"
 	"encoding/base64"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -11,9 +10,9 @@ import (
 	"github.com/openai/openai-go/v3"
 	"github.com/openai/openai-go/v3/option"
 	"github.com/openai/openai-go/v3/responses"
-	"github.com/openai/openai-go/v3/shared"
 
 	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
+	"github.com/Epistemic-Technology/academic-mcp/internal/llm/prompts"
 	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
 	"github.com/Epistemic-Technology/academic-mcp/models"
 )
@@ -21,7 +20,7 @@ import (
 var (
 
 	// parsedDocumentSchema is the unified JSON schema for parsing all document types
-	// For non-PDF documents: page_number_info fields will be empty/zero values
+	// For non-PDF documents: page_number_info and page_assessment fields will be empty/zero values
 	// For text-only documents: images and tables arrays will be empty
 	parsedDocumentSchema = map[string]any{
 		"type": "object",
@@ -48,8 +47,29 @@ var (
 					"abstract": map[string]any{
 						"type": "string",
 					},
+					"funding_statement": map[string]any{
+						"type":        "string",
+						"description": "The text of the funding or financial support statement, or an empty string if there is none",
+					},
+					"acknowledgments": map[string]any{
+						"type":        "string",
+						"description": "The text of the acknowledgments, or an empty string if there are none",
+					},
+					"data_availability": map[string]any{
+						"type":        "string",
+						"description": "The text of the data availability (or data and code availability) statement, or an empty string if there is none",
+					},
+					"keywords": map[string]any{
+						"type":        "array",
+						"items":       map[string]any{"type": "string"},
+						"description": "The keywords the authors list for the document (e.g., under the abstract), or an empty array if there are none",
+					},
+					"language": map[string]any{
+						"type":        "string",
+						"description": "ISO 639-1 code of the language the main text is written in (e.g., en, de, fr)",
+					},
 				},
-				"required":             []string{"title", "authors", "publication_date", "publication", "doi", "abstract"},
+				"required":             []string{"title", "authors", "publication_date", "publication", "doi", "abstract", "funding_statement", "acknowledgments", "data_availability", "keywords", "language"},
 				"additionalProperties": false,
 			},
 			"content": map[string]any{
@@ -72,7 +92,10 @@ var (
 				"items": map[string]any{
 					"type": "object",
 					"properties": map[string]any{
-						"image_url":         map[string]any{"type": "string"},
+						"image_url": map[string]any{
+							"type":        "string",
+							"description": "The image's URL exactly as written in the text, or an empty string if it gives none (always empty for PDF pages)",
+						},
 						"image_description": map[string]any{"type": "string"},
 						"caption":           map[string]any{"type": "string"},
 					},
@@ -87,7 +110,10 @@ var (
 					"properties": map[string]any{
 						"table_id":    map[string]any{"type": "string"},
 						"table_title": map[string]any{"type": "string"},
-						"table_data":  map[string]any{"type": "string"},
+						"table_data": map[string]any{
+							"type":        "string",
+							"description": "The table as a GitHub-flavored markdown table: one header row, a delimiter row, then one row per line",
+						},
 					},
 					"required":             []string{"table_id", "table_title", "table_data"},
 					"additionalProperties": false,
@@ -141,96 +167,77 @@ var (
 				"required":             []string{"page_number", "confidence", "location", "page_range_info"},
 				"additionalProperties": false,
 			},
+			"page_assessment": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"content_confidence": map[string]any{
+						"type":    "number",
+						"minimum": 0.0,
+						"maximum": 1.0,
+					},
+					"is_blank":           map[string]any{"type": "boolean"},
+					"is_cover":           map[string]any{"type": "boolean"},
+					"is_references_only": map[string]any{"type": "boolean"},
+				},
+				"required":             []string{"content_confidence", "is_blank", "is_cover", "is_references_only"},
+				"additionalProperties": false,
+			},
 		},
 		"additionalProperties": false,
-		"required":             []string{"metadata", "content", "references", "images", "tables", "footnotes", "endnotes", "page_number_info"},
+		"required":             []string{"metadata", "content", "references", "images", "tables", "footnotes", "endnotes", "page_number_info", "page_assessment"},
 	}
 )
 
-// estimateTokens provides a rough estimate of token count for text
-// Uses approximation of ~4 characters per token for English text
-func estimateTokens(text string) int {
-	return len(text) / 4
+// ParsePDFPage parses a single-page PDF that has a text layer
+func ParsePDFPage(ctx context.Context, apiKey string, page *models.DocumentPageData, log logger.Logger) (*models.ParsedPage, error) {
+	return parsePDFPageWithPrompt(ctx, apiKey, page, prompts.PDFPage{}, log)
+}
+
+// ParseScannedPDFPage parses a single-page PDF that is an image-only scan.
+// The prompt asks for a faithful OCR-style transcription rather than a cleaned-up reading.
+func ParseScannedPDFPage(ctx context.Context, apiKey string, page *models.DocumentPageData, log logger.Logger) (*models.ParsedPage, error) {
+	return parsePDFPageWithPrompt(ctx, apiKey, page, prompts.PDFPage{Scanned: true}, log)
 }
 
-func ParsePDFPage(ctx context.Context, apiKey string, page *models.DocumentPageData) (*models.ParsedPage, error) {
-	client := openai.NewClient(option.WithAPIKey(apiKey))
+func parsePDFPageWithPrompt(ctx context.Context, apiKey string, page *models.DocumentPageData, params prompts.PDFPage, log logger.Logger) (*models.ParsedPage, error) {
+	prompt, err := prompts.RenderPDFPage(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render page prompt: %w", err)
+	}
+	client := newClient(ctx, apiKey)
 	encodedPageData := base64.StdEncoding.EncodeToString([]byte(*page))
-	response, err := client.Responses.New(ctx, responses.ResponseNewParams{
-		Model: shared.ChatModelGPT5Mini,
+	pageInput := func(prompt string) responses.ResponseInputMessageContentListParam {
+		return responses.ResponseInputMessageContentListParam{
+			responses.ResponseInputContentUnionParam{
+				OfInputFile: &responses.ResponseInputFileParam{
+					FileData: openai.String("data:application/pdf;base64," + encodedPageData),
+					Filename: openai.String("page.pdf"),
+				},
+			},
+			responses.ResponseInputContentParamOfInputText(prompt),
+		}
+	}
+	parsedPage, truncated, err := newStructuredResponseWithTruncation[models.ParsedPage](ctx, &client, responses.ResponseNewParams{
+		Model:           parseModel,
+		MaxOutputTokens: openai.Int(parseMaxOutputTokens),
 		Input: responses.ResponseNewParamsInputUnion{
 			OfInputItemList: responses.ResponseInputParam{
-				responses.ResponseInputItemParamOfMessage(
-					responses.ResponseInputMessageContentListParam{
-						responses.ResponseInputContentUnionParam{
-							OfInputFile: &responses.ResponseInputFileParam{
-								FileData: openai.String("data:application/pdf;base64," + encodedPageData),
-								Filename: openai.String("page.pdf"),
-							},
-						},
-						responses.ResponseInputContentParamOfInputText(`Parse this page from an academic paper and extract it into the specified JSON structure.
-
-1. If there is document metadata on the page (title, authors, publication date, publication, doi, abstract), extract those into the "metadata" object.
-
-2. Extract the main textual content of the page.
-	- Use markdown syntax to format the text.
-	- This should exclude any headers, footers, image captions, tables, and any other elements not part of the main content.
-	- Any columns should be concatenated in normal reading order.
-	- Footnote or endnote references (normally as superscripts) should be included in the main text using square brackets eg. [1].
-	- Try to identify section headings (for example by font size or weight).
-
-3. If there are any bibliographic references (not in-text citations, but full bibliographic entries), extract those into the "references" array. Note that footnotes are not references. We're looking for a bibliography or works cited section or similar.
-
-4. If there are any images on the page, extract the captions and textual descriptions of those images into the "images" array.
-
-5. If there are any tables on the page, extract the table IDs, titles, and data into the "tables" array.
-
-6. If there are any footnotes on this page (notes appearing at the bottom of the page), extract them into the "footnotes" array:
-   - "marker": The footnote marker/number (e.g., "1", "2", "*", "†", "a")
-   - "text": The full text of the footnote
-   - "page_number": The page number where this footnote appears (use the detected page number from step 8)
-   - "in_text_page": The page number where the footnote marker appears in the main text (usually the same as page_number, but could differ)
-
-7. If there are any endnotes on this page (notes collected at the end of a chapter/document), extract them into the "endnotes" array:
-   - "marker": The endnote marker/number (e.g., "1", "2", "i", "ii")
-   - "text": The full text of the endnote
-   - "page_number": The page number where this endnote definition appears
-
-   IMPORTANT: Distinguish between footnotes and endnotes:
-   - Footnotes appear at the bottom of the same page as their marker
-   - Endnotes are collected in a dedicated section, often at the end of chapters or the document
-   - Do NOT confuse bibliographic references with footnotes or endnotes
-
-8. Extract page numbering information into "page_number_info":
-   - "page_number": The printed page number visible on this page (e.g., "125", "iv", "A-3"). Look in headers, footers, margins, and corners. If no page number is visible, use an empty string "".
-   - "confidence": Your confidence level (0.0-1.0) that the page number is correct. Use 1.0 for clearly printed numbers, 0.5-0.8 for ambiguous cases, and 0.0 if no number is found.
-   - "location": Where the page number appears (e.g., "bottom center", "top right", "footer", "none" if not found).
-   - "page_range_info": Any page range information from the header or title page (e.g., "Pages 125-150" or "pp. 42-68"). Use empty string "" if none found.
-
-IMPORTANT for page numbers: Be conservative. Only report page numbers with high confidence. Consider that:
-- The first page may be unnumbered (title page or cover)
-- Chapter first pages are often unnumbered
-- Pages with full-bleed images may be unnumbered
-- Blank pages may be unnumbered
-- Do not confuse section numbers, figure numbers, or other numbers with page numbers`),
-					},
-					"user",
-				),
+				responses.ResponseInputItemParamOfMessage(pageInput(prompt), "user"),
 			},
 		},
 		Text: responses.ResponseTextConfigParam{
 			Format: responses.ResponseFormatTextConfigParamOfJSONSchema("parsed_page", parsedDocumentSchema),
 		},
-	},
-	)
+	}, "page parse", log)
 	if err != nil {
 		return nil, err
 	}
-	var parsedPage models.ParsedPage
-	outputText := response.OutputText()
-	err = json.Unmarshal([]byte(outputText), &parsedPage)
-	if err != nil {
-		return nil, err
+	if truncated {
+		if isReferenceHeavy(parsedPage.References, parsedPage.PageAssessment.IsReferencesOnly) {
+			parsedPage.References = recoverReferences(ctx, &client, parsedPage.References, prompts.References{}, pageInput, "page references", log)
+		} else {
+			log.Warn("Page parse output was truncated after %d references; the rest of the page may be missing", len(parsedPage.References))
+		}
 	}
 	return &parsedPage, nil
 }
@@ -238,123 +245,318 @@ IMPORTANT for page numbers: Be conservative. Only report page numbers with high
 // ParseDocument parses a document based on its type and returns a ParsedItem
 func ParseDocument(ctx context.Context, apiKey string, docData models.DocumentData, log logger.Logger) (*models.ParsedItem, error) {
 	log.Info("Parsing document of type: %s", docData.Type)
+	var item *models.ParsedItem
+	var err error
 	switch docData.Type {
 	case "pdf":
-		return parsePDF(ctx, apiKey, docData, log)
+		item, err = parsePDF(ctx, apiKey, docData, log)
 	case "html":
-		return parseHTML(ctx, apiKey, docData, log)
-	case "md", "txt":
-		return parseTextDocument(ctx, apiKey, docData, log)
+		item, err = parseHTML(ctx, apiKey, docData, log)
+	case "jats", "tei":
+		// Structured XML needs no model; its markup holds what the model would read
+		log.Info("Reading %s document from its markup", strings.ToUpper(docData.Type))
+		return documents.ParseStructuredXML(docData)
+	case "md", "txt", "xml":
+		item, err = parseTextDocument(ctx, apiKey, docData, log)
+	case "rtf":
+		item, err = parseRTF(ctx, apiKey, docData, log)
+	case "epub":
+		item, err = parseEPUB(ctx, apiKey, docData, log)
 	case "docx":
 		// TODO: Implement DOCX parsing
 		log.Error("Unsupported document type: docx")
-		return nil, errors.New("unsupported document type: docx")
+		return nil, models.WithErrorCode(models.ErrorInvalidInput, errors.New("unsupported document type: docx"))
+	case "zip", "unknown":
+		description := documents.DescribeUnsupported(docData.Data, docData.Type)
+		log.Error("Unsupported document: %s", description)
+		return nil, models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("unsupported document type: %s", description))
 	default:
 		log.Error("Unsupported document type: %s", docData.Type)
-		return nil, errors.New("unsupported document type")
+		return nil, models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("unsupported document type: %s", docData.Type))
+	}
+	if err != nil {
+		return nil, err
 	}
+	item.Provenance = parseProvenance()
+	return item, nil
 }
 
-// parsePDF parses a PDF document and returns a ParsedItem
-func parsePDF(ctx context.Context, apiKey string, pdfData models.DocumentData, log logger.Logger) (*models.ParsedItem, error) {
-	// Split the PDF into individual pages
-	pages, err := documents.SplitPdf(pdfData)
+// metadataPages is how many leading PDF pages a metadata-only parse reads; the
+// title, authors, abstract, and DOI are nearly always on them
+const metadataPages = 2
+
+// ParseDocumentMetadata parses only what is needed to identify and triage a
+// document. For a PDF, the first pages are parsed and only their metadata and
+// abstract are kept, in an item marked Partial with no pages or references.
+// Other document types are parsed in full with ParseDocument, since they are
+// not parsed page by page.
+func ParseDocumentMetadata(ctx context.Context, apiKey string, docData models.DocumentData, log logger.Logger) (*models.ParsedItem, error) {
+	if docData.Type != "pdf" {
+		log.Info("Metadata-only parsing applies to PDFs, parsing the %s document in full", docData.Type)
+		return ParseDocument(ctx, apiKey, docData, log)
+	}
+
+	pages, err := documents.OpenPdfPages(docData)
 	if err != nil {
 		log.Error("Failed to split PDF into pages: %v", err)
 		return nil, err
 	}
+	imageOnly, err := pages.ImageOnly()
+	if err != nil {
+		log.Warn("Failed to detect image-only pages, assuming a text layer: %v", err)
+		imageOnly = nil
+	}
 
-	log.Info("Processing PDF with %d pages (parallel with rate limiting)", len(pages))
+	first := pageIndexes(min(pages.Len(), metadataPages))
+	log.Info("Parsing the first %d of %d PDF pages for metadata", len(first), pages.Len())
+	parsedPages, err := ParallelProcess(ctx, first, log, func(ctx context.Context, i int, idx int) (*models.ParsedPage, error) {
+		return parsePDFPageAt(ctx, apiKey, pages, idx, idx < len(imageOnly) && imageOnly[idx], log)
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	// Process pages using worker pool and rate limiting
-	parsedPages, err := ParallelProcess(ctx, pages, log, func(ctx context.Context, pageNum int, pageData models.DocumentPageData) (*models.ParsedPage, error) {
-		log.Debug("Processing page %d with rate limiting", pageNum+1)
+	item := &models.ParsedItem{Partial: true, IsScanned: documents.IsScannedDocument(imageOnly)}
+	var languages []string
+	for _, page := range parsedPages {
+		if page == nil {
+			continue
+		}
+		mergeMissingMetadata(&item.Metadata, &page.Metadata)
+		languages = append(languages, page.Metadata.Language)
+	}
+	item.Metadata.Language = dominantLanguage(languages)
+	item.Provenance = parseProvenance()
+	return item, nil
+}
 
-		// Wrap the API call with rate limiting and retry logic
-		parsed, err := RateLimitedCall(ctx, estimatedTokensPerPage, log, func(ctx context.Context) (*models.ParsedPage, error) {
-			log.Debug("Calling OpenAI API for page %d", pageNum+1)
-			return ParsePDFPage(ctx, apiKey, &pageData)
-		})
+// parsePDF parses a PDF document and returns a ParsedItem
+func parsePDF(ctx context.Context, apiKey string, pdfData models.DocumentData, log logger.Logger) (*models.ParsedItem, error) {
+	return parsePDFWith(ctx, pdfData, func(ctx context.Context, pageNum int, pageData models.DocumentPageData, scanned bool) (*models.ParsedPage, error) {
+		return parsePDFPageRateLimited(ctx, apiKey, pageNum, pageData, scanned, log)
+	}, log)
+}
 
-		if err != nil {
-			log.Error("Failed to parse page %d: %v", pageNum+1, err)
-			return nil, err
-		}
+// parsePDFWith parses a PDF document's pages with parse and stitches them
+// into a ParsedItem. Pages are aggregated in page order, whatever order their
+// parses finish in.
+func parsePDFWith(ctx context.Context, pdfData models.DocumentData, parse pageParser, log logger.Logger) (*models.ParsedItem, error) {
+	// Open the PDF for its pages to be extracted as workers take them, so
+	// only the pages being parsed are held in memory
+	pages, err := documents.OpenPdfPages(pdfData)
+	if err != nil {
+		log.Error("Failed to split PDF into pages: %v", err)
+		return nil, err
+	}
 
-		return parsed, nil
-	})
+	// Detect pages without a text layer so scans can be transcribed with an OCR-oriented prompt
+	imageOnly, err := pages.ImageOnly()
+	if err != nil {
+		log.Warn("Failed to detect image-only pages, assuming a text layer: %v", err)
+		imageOnly = make([]bool, pages.Len())
+	}
+	isScanned := documents.IsScannedDocument(imageOnly)
+	if isScanned {
+		log.Info("PDF appears to be scanned (no text layer on most pages), using OCR transcription prompt")
+	}
 
+	log.Info("Processing PDF with %d pages (parallel with rate limiting)", pages.Len())
+
+	// Process pages using worker pool and rate limiting, resuming from the
+	// pages an interrupted parse staged
+	parsedPages, err := parsePDFPages(ctx, pdfData, pages, imageOnly, parse, log)
 	if err != nil {
 		return nil, err
 	}
 
-	log.Info("Successfully parsed all %d pages", len(pages))
+	log.Info("Successfully parsed all %d pages", pages.Len())
+
+	// Flag near-empty pages before page number validation so unreliable scans don't skew it
+	pageQuality := assessPageQuality(parsedPages, imageOnly)
 
 	// Validate and determine page numbering scheme
-	pageNumbers := validatePageNumbers(parsedPages)
+	pageNumbers, pageNumbering := validatePageNumbers(parsedPages, pdfData.Pages.Offset())
 
 	// Stitch everything together
 	var parsedItem models.ParsedItem
 	parsedItem.Pages = make([]string, 0, len(parsedPages))
 	parsedItem.PageNumbers = pageNumbers
+	parsedItem.PageNumbering = pageNumbering
+	parsedItem.IsScanned = isScanned
+	parsedItem.PageQuality = pageQuality
 	parsedItem.References = make([]models.Reference, 0)
 	parsedItem.Images = make([]models.Image, 0)
 	parsedItem.Tables = make([]models.Table, 0)
-	parsedItem.Footnotes = make([]models.Footnote, 0)
-	parsedItem.Endnotes = make([]models.Endnote, 0)
 
 	// Aggregate data from all pages
-	for _, page := range parsedPages {
+	var languages []string
+	for i, page := range parsedPages {
 		if page != nil {
-			if page.Metadata.Title != "" && parsedItem.Metadata.Title == "" {
-				parsedItem.Metadata.Title = page.Metadata.Title
-			}
-			if len(page.Metadata.Authors) > 0 && len(parsedItem.Metadata.Authors) == 0 {
-				parsedItem.Metadata.Authors = page.Metadata.Authors
-			}
-			if page.Metadata.PublicationDate != "" && parsedItem.Metadata.PublicationDate == "" {
-				parsedItem.Metadata.PublicationDate = page.Metadata.PublicationDate
+			if !pageQuality[i].NearEmpty {
+				languages = append(languages, page.Metadata.Language)
 			}
-			if page.Metadata.Publication != "" && parsedItem.Metadata.Publication == "" {
-				parsedItem.Metadata.Publication = page.Metadata.Publication
+			// The first page with a value sets each field
+			mergeMissingMetadata(&parsedItem.Metadata, &page.Metadata)
+
+			parsedItem.Pages = append(parsedItem.Pages, page.Content)
+			for _, ref := range page.References {
+				ref.PageNumber = pageNumbers[i]
+				ref.PageIndex = i + 1
+				parsedItem.References = append(parsedItem.References, ref)
 			}
-			if page.Metadata.DOI != "" && parsedItem.Metadata.DOI == "" {
-				parsedItem.Metadata.DOI = page.Metadata.DOI
+			for _, image := range page.Images {
+				image.PageIndex = i + 1
+				parsedItem.Images = append(parsedItem.Images, image)
 			}
-			if page.Metadata.Abstract != "" && parsedItem.Metadata.Abstract == "" {
-				parsedItem.Metadata.Abstract = page.Metadata.Abstract
+			for _, table := range page.Tables {
+				table.PageNumber = pageNumbers[i]
+				parsedItem.Tables = append(parsedItem.Tables, table)
 			}
+		}
+	}
 
-			parsedItem.Pages = append(parsedItem.Pages, page.Content)
-			parsedItem.References = append(parsedItem.References, page.References...)
-			parsedItem.Images = append(parsedItem.Images, page.Images...)
-			parsedItem.Tables = append(parsedItem.Tables, page.Tables...)
-			parsedItem.Footnotes = append(parsedItem.Footnotes, page.Footnotes...)
-			parsedItem.Endnotes = append(parsedItem.Endnotes, page.Endnotes...)
+	parsedItem.Metadata.Language = dominantLanguage(languages)
+	attachPDFImageData(pdfData, parsedItem.Images, log)
+
+	// Notes running over a page break are split in two
+	parsedItem.Footnotes, parsedItem.Endnotes = consolidateNotes(parsedPages)
+
+	// Bibliographies spanning page breaks produce split and repeated entries
+	aggregatedCount := len(parsedItem.References)
+	parsedItem.References = consolidateReferences(parsedItem.References)
+	if len(parsedItem.References) != aggregatedCount {
+		log.Info("Consolidated %d page-level references into %d", aggregatedCount, len(parsedItem.References))
+	}
+
+	var nearEmpty []string
+	for i, q := range pageQuality {
+		if q.NearEmpty {
+			nearEmpty = append(nearEmpty, pageNumbers[i])
 		}
 	}
+	if len(nearEmpty) > 0 {
+		log.Warn("%d of %d pages returned near-empty content (pages: %s)", len(nearEmpty), pages.Len(), strings.Join(nearEmpty, ", "))
+	}
 
 	return &parsedItem, nil
 }
 
+// attachPDFImageData gives the images described on each page the bytes of the
+// images embedded in that page, unless disabled by ACADEMIC_MCP_IMAGE_DATA.
+// Failures are logged rather than failing the parse.
+func attachPDFImageData(pdfData models.DocumentData, images []models.Image, log logger.Logger) {
+	limits, err := documents.ConfiguredImageLimits()
+	if err != nil {
+		log.Warn("Using default image limits: %v", err)
+	}
+	if !limits.Enabled || len(images) == 0 {
+		return
+	}
+	extracted, err := documents.ExtractPDFImages(pdfData, limits)
+	if err != nil {
+		log.Warn("Failed to extract embedded images: %v", err)
+		return
+	}
+	attached := documents.AttachPDFImages(images, extracted)
+	log.Info("Attached data to %d of %d images (%d embedded images extracted)", attached, len(images), len(extracted))
+}
+
+// pageIndexes returns the page indexes 0 to count-1
+func pageIndexes(count int) []int {
+	indexes := make([]int, count)
+	for i := range indexes {
+		indexes[i] = i
+	}
+	return indexes
+}
+
+// parsePDFPageAt extracts a page (0-indexed pageNum) of a PDF and parses it
+// with parsePDFPageRateLimited. The page's bytes are released once it is
+// parsed.
+func parsePDFPageAt(ctx context.Context, apiKey string, pages *documents.PDFPages, pageNum int, scanned bool, log logger.Logger) (*models.ParsedPage, error) {
+	pageData, err := pages.Page(pageNum)
+	if err != nil {
+		log.Error("Failed to extract page %d: %v", pageNum+1, err)
+		return nil, err
+	}
+	return parsePDFPageRateLimited(ctx, apiKey, pageNum, pageData, scanned, log)
+}
+
+// parsePDFPageRateLimited parses one page (0-indexed pageNum) with rate limiting and retries,
+// using the OCR transcription prompt for scanned pages. A page too large to send
+// is parsed from its extracted text instead and marked degraded.
+func parsePDFPageRateLimited(ctx context.Context, apiKey string, pageNum int, pageData models.DocumentPageData, scanned bool, log logger.Logger) (*models.ParsedPage, error) {
+	log = log.With("page", pageNum+1)
+	parse := func(ctx context.Context) (*models.ParsedPage, error) {
+		return RateLimitedCall(ctx, estimatedTokensPerPage, log, func(ctx context.Context) (*models.ParsedPage, error) {
+			log.Debug("Calling OpenAI API for page %d", pageNum+1)
+			if scanned {
+				return ParseScannedPDFPage(ctx, apiKey, &pageData, log)
+			}
+			return ParsePDFPage(ctx, apiKey, &pageData, log)
+		})
+	}
+	fallback := func(ctx context.Context) (*models.ParsedPage, error) {
+		return parsePDFPageText(ctx, apiKey, pageData, log)
+	}
+	parsed, err := routePageParse(ctx, pageNum, parse, fallback, log)
+	if err != nil {
+		log.Error("Failed to parse page %d: %v", pageNum+1, err)
+		return nil, err
+	}
+	return parsed, nil
+}
+
+// ParseSelectedPDFPages parses a subset of the pages of an opened PDF, only
+// extracting those. selected are 0-indexed positions in pages, and imageOnly
+// holds the scan detection result for every page (it may be nil if detection
+// was not run). The returned pages and quality flags are in the same order as
+// selected.
+func ParseSelectedPDFPages(ctx context.Context, apiKey string, pages *documents.PDFPages, imageOnly []bool, selected []int, log logger.Logger) ([]*models.ParsedPage, []models.PageQuality, error) {
+	selectedImageOnly := make([]bool, len(selected))
+	for i, idx := range selected {
+		if idx < 0 || idx >= pages.Len() {
+			return nil, nil, fmt.Errorf("page %d is out of range (document has %d pages)", idx+1, pages.Len())
+		}
+		selectedImageOnly[i] = idx < len(imageOnly) && imageOnly[idx]
+	}
+
+	log.Info("Re-parsing %d of %d PDF pages", len(selected), pages.Len())
+	parsedPages, err := ParallelProcess(ctx, selected, log, func(ctx context.Context, i int, idx int) (*models.ParsedPage, error) {
+		return parsePDFPageAt(ctx, apiKey, pages, idx, selectedImageOnly[i], log)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return parsedPages, assessPageQuality(parsedPages, selectedImageOnly), nil
+}
+
 // parseHTML parses an HTML document and returns a ParsedItem
 func parseHTML(ctx context.Context, apiKey string, htmlData models.DocumentData, log logger.Logger) (*models.ParsedItem, error) {
 	log.Info("Parsing HTML document")
 
 	// Estimate token count before conversion
-	originalTokens := estimateTokens(string(htmlData.Data))
+	originalTokens := countTokens(string(htmlData.Data))
 	log.Info("Original HTML size: %d bytes (~%d tokens)", len(htmlData.Data), originalTokens)
 
+	// Read embedded bibliographic metadata before conversion discards the head
+	htmlMeta := documents.ExtractHTMLMetadata(htmlData.Data)
+
 	// Convert HTML to markdown to reduce context window usage
-	log.Debug("Converting HTML to markdown")
-	markdown, err := documents.PreprocessHTML(htmlData.Data)
+	mode, err := documents.ConfiguredHTMLExtractionMode()
+	if err != nil {
+		log.Warn("Using lenient HTML extraction: %v", err)
+	}
+	log.Debug("Converting HTML to markdown (%s extraction)", mode)
+	markdown, err := documents.PreprocessHTML(htmlData.Data, mode)
 	if err != nil {
 		log.Error("Failed to convert HTML to markdown: %v", err)
 		return nil, err
 	}
 
 	// Estimate token count after conversion
-	markdownTokens := estimateTokens(markdown)
+	markdownTokens := countTokens(markdown)
 	reductionPercent := 100.0 * (1.0 - float64(len(markdown))/float64(len(htmlData.Data)))
 	tokenReductionPercent := 100.0 * (1.0 - float64(markdownTokens)/float64(originalTokens))
 
@@ -375,162 +577,418 @@ func parseHTML(ctx context.Context, apiKey string, htmlData models.DocumentData,
 		Data: []byte(markdown),
 		Type: "md",
 	}
-	return parseTextDocument(ctx, apiKey, mdData, log)
+	item, err := parseTextDocument(ctx, apiKey, mdData, log)
+	if err != nil {
+		return nil, err
+	}
+	applyHTMLMetadata(item, htmlMeta, log)
+	return item, nil
 }
 
-// parseTextDocument parses a text document (markdown or plain text) and returns a ParsedItem
-func parseTextDocument(ctx context.Context, apiKey string, textData models.DocumentData, log logger.Logger) (*models.ParsedItem, error) {
-	log.Info("Parsing text document (type: %s)", textData.Type)
+// parseRTF parses an RTF document as plain text, since RTF exports from word
+// processors carry no structure the text parser needs
+func parseRTF(ctx context.Context, apiKey string, rtfData models.DocumentData, log logger.Logger) (*models.ParsedItem, error) {
+	text, err := documents.RTFToText(rtfData.Data)
+	if err != nil {
+		log.Error("Failed to convert RTF to text: %v", err)
+		return nil, models.WithErrorCode(models.ErrorInvalidInput, err)
+	}
+	if strings.TrimSpace(text) == "" {
+		return nil, models.WithErrorCode(models.ErrorInvalidInput, errors.New("RTF document has no text"))
+	}
+	log.Info("Converted RTF to text: %d bytes → %d bytes", len(rtfData.Data), len(text))
+	return parseTextDocument(ctx, apiKey, models.DocumentData{Data: []byte(text), Type: "txt"}, log)
+}
 
-	// Estimate token count for diagnostics
-	contentTokens := estimateTokens(string(textData.Data))
-	const promptTokens = 500 // Approximate prompt size
-	totalTokens := contentTokens + promptTokens
-	const tokenLimit = 400000
+// parseEPUB parses an EPUB book and returns a ParsedItem. Chapters are parsed
+// like text documents, in parallel, and aggregated the way parsePDF aggregates
+// pages, with each chapter's spine id as its page number. The package metadata
+// is merged over the extracted metadata, as embedded HTML metadata is.
+func parseEPUB(ctx context.Context, apiKey string, epubData models.DocumentData, log logger.Logger) (*models.ParsedItem, error) {
+	mode, err := documents.ConfiguredHTMLExtractionMode()
+	if err != nil {
+		log.Warn("Using lenient HTML extraction: %v", err)
+	}
+	book, err := documents.ExtractEPUB(epubData.Data, mode)
+	if err != nil {
+		log.Error("Failed to extract EPUB chapters: %v", err)
+		return nil, err
+	}
 
-	log.Info("Document size: %d bytes (~%d tokens)", len(textData.Data), contentTokens)
-	log.Info("Estimated total tokens: %d (content) + %d (prompt) = %d (limit: %d)",
-		contentTokens, promptTokens, totalTokens, tokenLimit)
+	log.Info("Processing EPUB with %d chapters (parallel with rate limiting)", len(book.Chapters))
 
-	if totalTokens > tokenLimit {
-		log.Warn("Document may exceed context window! Estimated: %d tokens, Limit: %d tokens",
-			totalTokens, tokenLimit)
-	} else if totalTokens > tokenLimit*0.9 {
-		log.Warn("Document is close to context window limit (%.1f%% used)",
-			float64(totalTokens)/float64(tokenLimit)*100)
+	parsedChapters, err := ParallelProcess(ctx, book.Chapters, log, func(ctx context.Context, i int, chapter documents.EPUBChapter) (*models.ParsedItem, error) {
+		chapterTokens := countTokens(chapter.Markdown)
+		if chapterTokens > textChunkTokenLimit-textPromptTokens {
+			// Long chapters are split and rate limited like any long text document
+			return parseTextDocument(ctx, apiKey, models.DocumentData{Data: []byte(chapter.Markdown), Type: "md"}, log)
+		}
+		estimated := min(2*chapterTokens+textPromptTokens, burstTokens)
+		params := prompts.TextDocument{Part: i + 1, Parts: len(book.Chapters), Chapters: true}
+		result, err := RateLimitedCall(ctx, estimated, log, func(ctx context.Context) (*textParseResult, error) {
+			log.Debug("Calling OpenAI API for EPUB chapter %s", chapter.ID)
+			return parseTextChunk(ctx, apiKey, chapter.Markdown, params, log)
+		})
+		if err != nil {
+			log.Error("Failed to parse EPUB chapter %s: %v", chapter.ID, err)
+			return nil, err
+		}
+		return mergeTextChunks([]*textParseResult{result}), nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	log.Debug("Calling OpenAI API for text parsing")
-	client := openai.NewClient(option.WithAPIKey(apiKey))
-	response, err := client.Responses.New(ctx, responses.ResponseNewParams{
-		Model: shared.ChatModelGPT5Mini,
-		Input: responses.ResponseNewParamsInputUnion{
-			OfInputItemList: responses.ResponseInputParam{
-				responses.ResponseInputItemParamOfMessage(
-					responses.ResponseInputMessageContentListParam{
-						responses.ResponseInputContentParamOfInputText(`Parse this text document from an academic paper and extract it into the specified JSON structure.
+	log.Info("Successfully parsed all %d chapters", len(book.Chapters))
+
+	parsedItem := &models.ParsedItem{
+		Pages:       make([]string, 0, len(parsedChapters)),
+		PageNumbers: make([]string, 0, len(parsedChapters)),
+		References:  make([]models.Reference, 0),
+		Images:      make([]models.Image, 0),
+		Tables:      make([]models.Table, 0),
+		Footnotes:   make([]models.Footnote, 0),
+		Endnotes:    make([]models.Endnote, 0),
+	}
+	var languages []string
+	for i, chapter := range parsedChapters {
+		if chapter == nil {
+			continue
+		}
+		chapterID := book.Chapters[i].ID
+		mergeMissingMetadata(&parsedItem.Metadata, &chapter.Metadata)
+		languages = append(languages, chapter.Metadata.Language)
+		parsedItem.Pages = append(parsedItem.Pages, strings.Join(chapter.Pages, "\n\n"))
+		parsedItem.PageNumbers = append(parsedItem.PageNumbers, chapterID)
+		for _, ref := range chapter.References {
+			ref.PageNumber = chapterID
+			ref.PageIndex = len(parsedItem.Pages)
+			parsedItem.References = append(parsedItem.References, ref)
+		}
+		parsedItem.Images = append(parsedItem.Images, chapter.Images...)
+		parsedItem.Tables = append(parsedItem.Tables, chapter.Tables...)
+		parsedItem.Footnotes = append(parsedItem.Footnotes, chapter.Footnotes...)
+		parsedItem.Endnotes = append(parsedItem.Endnotes, chapter.Endnotes...)
+	}
+
+	parsedItem.Metadata.Language = dominantLanguage(languages)
+	documents.AssignEndnoteKeys(parsedItem.Endnotes)
+
+	// A book's bibliography may be split across chapters or repeated per chapter
+	aggregatedCount := len(parsedItem.References)
+	parsedItem.References = consolidateReferences(parsedItem.References)
+	if len(parsedItem.References) != aggregatedCount {
+		log.Info("Consolidated %d chapter-level references into %d", aggregatedCount, len(parsedItem.References))
+	}
+
+	if book.Metadata != nil {
+		log.Info("Merging EPUB package metadata (title: %s, authors: %d, ISBN: %s)",
+			book.Metadata.Title, len(book.Metadata.Authors), book.Metadata.ISBN)
+		parsedItem.Metadata = *documents.MergeMetadata(book.Metadata, &parsedItem.Metadata)
+	}
+	return parsedItem, nil
+}
+
+// applyHTMLMetadata merges the metadata embedded in an HTML page's meta tags and
+// JSON-LD into the LLM-extracted metadata. Publishers' tags are authoritative, so
+// they take priority over anything the LLM read from the page text.
+func applyHTMLMetadata(item *models.ParsedItem, htmlMeta documents.HTMLMetadata, log logger.Logger) {
+	if htmlMeta.Metadata != nil {
+		log.Info("Merging embedded HTML metadata (title: %s, authors: %d, DOI: %s)",
+			htmlMeta.Metadata.Title, len(htmlMeta.Metadata.Authors), htmlMeta.Metadata.DOI)
+		item.Metadata = *documents.MergeMetadata(htmlMeta.Metadata, &item.Metadata)
+	}
+	if htmlMeta.PDFURL != "" {
+		log.Info("Page links to a full-text PDF: %s", htmlMeta.PDFURL)
+		item.PDFURL = htmlMeta.PDFURL
+	}
+}
 
-1. Extract document metadata (title, authors, publication date, publication, doi, abstract) if present at the beginning.
+// textParseResult is the structured output of parsing a text document or chunk
+type textParseResult struct {
+	Metadata   models.ItemMetadata `json:"metadata"`
+	Content    string              `json:"content"`
+	References []models.Reference  `json:"references"`
+	Images     []models.Image      `json:"images"`
+	Tables     []models.Table      `json:"tables"`
+	Footnotes  []models.Footnote   `json:"footnotes"`
+	Endnotes   []models.Endnote    `json:"endnotes"`
+}
 
-2. Extract the main textual content:
-   - If the document is already in markdown format, preserve the existing markdown syntax (headings, lists, emphasis, etc.).
-   - If the document is plain text, convert it to markdown format by identifying section headings and marking them with appropriate heading levels.
-   - Preserve paragraph structure.
-   - Preserve footnote/endnote references.
+const (
+	// textPromptTokens is the approximate size of the text parsing prompt
+	textPromptTokens = 500
+	// textChunkTokenLimit bounds the tokens sent in one text parsing request. The model
+	// returns the whole content as markdown, so chunks are sized to fit the 128k output
+	// token limit rather than the 400k context window.
+	textChunkTokenLimit = 100000
+)
 
-3. If there are bibliographic references (full bibliographic entries, not in-text citations), extract those into the "references" array.
+// parseTextDocument parses a text document (markdown or plain text) and returns a ParsedItem.
+// Documents too large for a single request are split into chunks at heading boundaries,
+// parsed in parallel, and merged.
+func parseTextDocument(ctx context.Context, apiKey string, textData models.DocumentData, log logger.Logger) (*models.ParsedItem, error) {
+	log.Info("Parsing text document (type: %s)", textData.Type)
 
-4. If there are images (markdown image syntax or image descriptions in text), extract them into the "images" array. For markdown images, use the image URL and alt text. For plain text, this array will typically be empty.
+	content := string(textData.Data)
+	contentTokens := countTokens(content)
+	log.Info("Document size: %d bytes (~%d tokens, limit per request: %d)", len(textData.Data), contentTokens, textChunkTokenLimit)
 
-5. If there are tables (markdown tables or structured tabular data), extract their content into the "tables" array. For plain text, this array will typically be empty.
+	chunks := documents.SplitMarkdownChunks(content, textChunkTokenLimit-textPromptTokens, countTokens)
+	if len(chunks) == 1 {
+		estimated := min(2*contentTokens+textPromptTokens, burstTokens)
+		result, err := RateLimitedCall(ctx, estimated, log, func(ctx context.Context) (*textParseResult, error) {
+			log.Debug("Calling OpenAI API for text parsing")
+			return parseTextChunk(ctx, apiKey, content, prompts.TextDocument{}, log)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return mergeTextChunks([]*textParseResult{result}), nil
+	}
 
-6. If there are footnotes (notes with markers at the bottom of pages), extract them into the "footnotes" array. Use empty strings for page_number and in_text_page fields since text documents don't have reliable page numbers.
+	log.Info("Document exceeds the per-request limit, splitting into %d chunks at heading boundaries", len(chunks))
+	for i, chunk := range chunks {
+		firstLine, _, _ := strings.Cut(strings.TrimSpace(chunk), "\n")
+		log.Info("  Chunk %d/%d: ~%d tokens, starts with %q", i+1, len(chunks), countTokens(chunk), firstLine)
+	}
 
-7. If there are endnotes at the end of the document, extract them into the "endnotes" array. Use empty string for page_number field.
+	results, err := ParallelProcess(ctx, chunks, log, func(ctx context.Context, i int, chunk string) (*textParseResult, error) {
+		// Input and output both carry the chunk content; the limiter can't wait for more than its burst
+		estimated := min(2*countTokens(chunk)+textPromptTokens, burstTokens)
+		params := prompts.TextDocument{Part: i + 1, Parts: len(chunks)}
+		return RateLimitedCall(ctx, estimated, log, func(ctx context.Context) (*textParseResult, error) {
+			log.Debug("Calling OpenAI API for text chunk %d/%d", i+1, len(chunks))
+			return parseTextChunk(ctx, apiKey, chunk, params, log)
+		})
+	})
+	if err != nil {
+		log.Error("Failed to parse text chunks: %v", err)
+		return nil, err
+	}
 
-8. For page_number_info, use empty string for page_number, 0.0 for confidence, "none" for location, and empty string for page_range_info since text documents don't have page numbers.
+	item := mergeTextChunks(results)
+	log.Info("Merged %d chunks: %d references, %d footnotes, %d endnotes", len(chunks), len(item.References), len(item.Footnotes), len(item.Endnotes))
+	return item, nil
+}
 
-Text Content:
-` + string(textData.Data)),
-					},
-					"user",
-				),
+// parseTextChunk sends one piece of a text document to the model. params
+// number the piece when the document was split into chunks.
+func parseTextChunk(ctx context.Context, apiKey string, text string, params prompts.TextDocument, log logger.Logger) (*textParseResult, error) {
+	prompt, err := prompts.RenderTextDocument(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render text prompt: %w", err)
+	}
+	client := newClient(ctx, apiKey)
+	textInput := func(prompt string) responses.ResponseInputMessageContentListParam {
+		return responses.ResponseInputMessageContentListParam{
+			responses.ResponseInputContentParamOfInputText(prompt + text),
+		}
+	}
+	result, truncated, err := newStructuredResponseWithTruncation[textParseResult](ctx, &client, responses.ResponseNewParams{
+		Model:           parseModel,
+		MaxOutputTokens: openai.Int(parseMaxOutputTokens),
+		Input: responses.ResponseNewParamsInputUnion{
+			OfInputItemList: responses.ResponseInputParam{
+				responses.ResponseInputItemParamOfMessage(textInput(prompt), "user"),
 			},
 		},
 		Text: responses.ResponseTextConfigParam{
 			Format: responses.ResponseFormatTextConfigParamOfJSONSchema("parsed_text_document", parsedDocumentSchema),
 		},
-	})
+	}, "text parse", log)
 	if err != nil {
 		return nil, err
 	}
+	if truncated {
+		if isReferenceHeavy(result.References, false) {
+			result.References = recoverReferences(ctx, &client, result.References, prompts.References{Text: true}, textInput, "text references", log)
+		} else {
+			log.Warn("Text parse output was truncated after %d references; the rest of the text may be missing", len(result.References))
+		}
+	}
+	return &result, nil
+}
 
-	var result struct {
-		Metadata   models.ItemMetadata `json:"metadata"`
-		Content    string              `json:"content"`
-		References []models.Reference  `json:"references"`
-		Images     []models.Image      `json:"images"`
-		Tables     []models.Table      `json:"tables"`
-		Footnotes  []models.Footnote   `json:"footnotes"`
-		Endnotes   []models.Endnote    `json:"endnotes"`
+// mergeTextChunks combines the parse results of consecutive chunks into a single
+// ParsedItem, the same way parsePDF aggregates pages: content is concatenated,
+// the first non-empty value of each metadata field wins (except the language,
+// which is the most common one), and references that appear in more than one
+// chunk are kept once, without page locations.
+func mergeTextChunks(results []*textParseResult) *models.ParsedItem {
+	item := &models.ParsedItem{
+		PageNumbers: []string{"1"},
+		References:  make([]models.Reference, 0),
+		Images:      make([]models.Image, 0),
+		Tables:      make([]models.Table, 0),
+		Footnotes:   make([]models.Footnote, 0),
+		Endnotes:    make([]models.Endnote, 0),
+	}
+	if len(results) > 1 {
+		item.ChunkCount = len(results)
 	}
 
-	outputText := response.OutputText()
-	err = json.Unmarshal([]byte(outputText), &result)
-	if err != nil {
-		return nil, err
+	var contents, languages []string
+	seenReferences := make(map[string]bool)
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		mergeMissingMetadata(&item.Metadata, &result.Metadata)
+		languages = append(languages, result.Metadata.Language)
+		contents = append(contents, result.Content)
+		for _, ref := range result.References {
+			key := strings.ToLower(strings.Join(strings.Fields(ref.ReferenceText), " "))
+			if seenReferences[key] {
+				continue
+			}
+			seenReferences[key] = true
+			// Text documents have no pages to locate references on
+			ref.PageNumber, ref.PageIndex = "", 0
+			item.References = append(item.References, ref)
+		}
+		item.Images = append(item.Images, result.Images...)
+		item.Tables = append(item.Tables, result.Tables...)
+		item.Footnotes = append(item.Footnotes, result.Footnotes...)
+		item.Endnotes = append(item.Endnotes, result.Endnotes...)
+	}
+	item.Pages = []string{strings.Join(contents, "\n\n")}
+	item.Metadata.Language = dominantLanguage(languages)
+	documents.AssignEndnoteKeys(item.Endnotes)
+	return item
+}
+
+// mergeMissingMetadata fills empty fields of dst with values from src, and adds
+// the keywords of src that dst lacks
+func mergeMissingMetadata(dst, src *models.ItemMetadata) {
+	if dst.Title == "" {
+		dst.Title = src.Title
+	}
+	if len(dst.Authors) == 0 {
+		dst.Authors = src.Authors
+	}
+	if dst.PublicationDate == "" {
+		dst.PublicationDate = src.PublicationDate
+	}
+	if dst.Publication == "" {
+		dst.Publication = src.Publication
+	}
+	if dst.DOI == "" {
+		dst.DOI = src.DOI
+	}
+	if dst.Abstract == "" {
+		dst.Abstract = src.Abstract
 	}
+	if dst.FundingStatement == "" {
+		dst.FundingStatement = src.FundingStatement
+	}
+	if dst.Acknowledgments == "" {
+		dst.Acknowledgments = src.Acknowledgments
+	}
+	if dst.DataAvailability == "" {
+		dst.DataAvailability = src.DataAvailability
+	}
+	dst.Keywords = documents.MergeKeywords(dst.Keywords, src.Keywords)
+}
 
-	return &models.ParsedItem{
-		Metadata:    result.Metadata,
-		Pages:       []string{result.Content},
-		PageNumbers: []string{"1"},
-		References:  result.References,
-		Images:      result.Images,
-		Tables:      result.Tables,
-		Footnotes:   result.Footnotes,
-		Endnotes:    result.Endnotes,
-	}, nil
+// SummaryOptions controls how SummarizeItem writes a summary
+type SummaryOptions struct {
+	TargetLanguage string // Language to write the summary in (a name or code), "" = the model's choice
+	Style          string // Summary style (see models.SummaryStyles), "" = standard
 }
 
-func SummarizeItem(ctx context.Context, apiKey string, pdfData *models.ParsedItem, log logger.Logger) (string, error) {
-	log.Info("Generating summary for document: %s", pdfData.Metadata.Title)
-	fullContent := strings.Join(pdfData.Pages, "\n")
+func SummarizeItem(ctx context.Context, apiKey string, pdfData *models.ParsedItem, opts SummaryOptions, log logger.Logger) (string, error) {
+	log.Info("Generating summary for document: %s (style: %q, target language: %q)", pdfData.Metadata.Title, opts.Style, opts.TargetLanguage)
+	kept, skipped := analysisPages(pdfData)
+	if len(skipped) > 0 {
+		log.Info("Leaving %d blank or near-empty pages out of the summary (pages: %s)", len(skipped), strings.Join(skipped, ", "))
+	}
+	contents := make([]string, len(kept))
+	for i, pageIndex := range kept {
+		contents[i] = pdfData.Pages[pageIndex]
+	}
+	fullContent := strings.Join(contents, "\n")
+	prompt, err := buildSummaryPrompt(fullContent, opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to render summary prompt: %w", err)
+	}
 	log.Debug("Calling OpenAI API for summarization (content length: %d chars)", len(fullContent))
-	client := openai.NewClient(option.WithAPIKey(apiKey))
-	response, err := client.Responses.New(ctx, responses.ResponseNewParams{
-		Model: shared.ChatModelGPT5Mini,
+	summary, err := generateSummary(ctx, apiKey, prompt, log)
+	if err != nil {
+		log.Error("Failed to generate summary: %v", err)
+		return "", err
+	}
+	log.Info("Successfully generated summary")
+	return summary, nil
+}
+
+// generateSummary sends a summarization prompt, with its content, to the
+// summary model and returns the text of the reply
+func generateSummary(ctx context.Context, apiKey string, prompt string, log logger.Logger) (string, error) {
+	client := newClient(ctx, apiKey)
+	params := responses.ResponseNewParams{
+		Model: summaryModel,
 		Input: responses.ResponseNewParamsInputUnion{
 			OfInputItemList: responses.ResponseInputParam{
 				responses.ResponseInputItemParamOfMessage(
 					responses.ResponseInputMessageContentListParam{
-						responses.ResponseInputContentParamOfInputText(`Summarize this academic text into 1-3 paragraphs. It should be coherent, concise, accurately reflect the original content, and use a detached academic tone. This should be in expository prose, not point form. No lists, just coherent sentences and paragraphs.
-
-` + fullContent),
+						responses.ResponseInputContentParamOfInputText(prompt),
 					},
 					"user",
 				),
 			},
 		},
+	}
+	response, err := callWithTimeout(ctx, summaryTimeout(log), func(ctx context.Context) (*responses.Response, error) {
+		return client.Responses.New(ctx, params)
 	})
 	if err != nil {
-		log.Error("Failed to generate summary: %v", err)
 		return "", err
 	}
-	log.Info("Successfully generated summary")
+	recordUsage(ctx, params.Model, response.Usage)
 	return response.OutputText(), nil
 }
 
+// buildSummaryPrompt builds the summarization prompt for the style; the content
+// follows it
+func buildSummaryPrompt(content string, opts SummaryOptions) (string, error) {
+	style := opts.Style
+	if style == "" {
+		style = models.SummaryStyleStandard
+	}
+	prompt, err := prompts.RenderSummary(prompts.Summary{Style: style})
+	if err != nil {
+		return "", err
+	}
+	return prompt + targetLanguageInstruction(opts.TargetLanguage, false) + "\n\n" + content, nil
+}
+
+// defaultPerPageQuotations is the most quotations extracted from a single page
+// when QuotationOptions.PerPageMax is not set
+const defaultPerPageQuotations = 3
+
+// QuotationOptions controls which quotations ExtractQuotations selects
+type QuotationOptions struct {
+	MaxQuotations  int    // Most quotations to return after prioritization, 0 = unlimited
+	PerPageMax     int    // Most quotations to extract from a single page, 0 = default (3)
+	MinLengthWords int    // Shortest quotation to keep, in words, 0 = no minimum
+	Focus          string // Optional topic or research question the quotations should address
+	TargetLanguage string // Language for context, relevance, and a translation of each quotation, "" = none
+}
+
 // ExtractQuotations extracts representative quotations from a parsed document.
+// Every quotation extracted is returned, with those within opts.MaxQuotations
+// marked selected (see rankQuotations).
 // For paginated documents (PDFs), it processes pages individually to maintain accurate page numbers.
 // For non-paginated documents, it processes the entire content at once.
-func ExtractQuotations(ctx context.Context, apiKey string, parsedItem *models.ParsedItem, summary string, maxQuotations int, log logger.Logger) ([]models.Quotation, error) {
-	log.Info("Extracting quotations from document: %s (max: %d)", parsedItem.Metadata.Title, maxQuotations)
+func ExtractQuotations(ctx context.Context, apiKey string, parsedItem *models.ParsedItem, summary string, opts QuotationOptions, log logger.Logger) ([]models.Quotation, error) {
+	opts.TargetLanguage = strings.TrimSpace(opts.TargetLanguage)
+	maxQuotations := opts.MaxQuotations
+	log.Info("Extracting quotations from document: %s (max: %d, focus: %q, target language: %q)", parsedItem.Metadata.Title, maxQuotations, opts.Focus, opts.TargetLanguage)
 
-	// JSON schema for quotation extraction
-	quotationSchema := map[string]any{
-		"type": "object",
-		"properties": map[string]any{
-			"quotations": map[string]any{
-				"type": "array",
-				"items": map[string]any{
-					"type": "object",
-					"properties": map[string]any{
-						"quotation_text": map[string]any{"type": "string"},
-						"page_number":    map[string]any{"type": "string"},
-						"context":        map[string]any{"type": "string"},
-						"relevance":      map[string]any{"type": "string"},
-					},
-					"required":             []string{"quotation_text", "page_number", "context", "relevance"},
-					"additionalProperties": false,
-				},
-			},
-		},
-		"required":             []string{"quotations"},
-		"additionalProperties": false,
-	}
+	quotationSchema := buildQuotationSchema(opts.TargetLanguage != "")
 
-	client := openai.NewClient(option.WithAPIKey(apiKey))
+	client := newClient(ctx, apiKey)
 
 	// Check if this is a paginated document (PDF with source page numbers)
 	isPaginated := len(parsedItem.PageNumbers) > 0 && parsedItem.PageNumbers[0] != ""
@@ -541,54 +999,106 @@ func ExtractQuotations(ctx context.Context, apiKey string, parsedItem *models.Pa
 	if isPaginated {
 		// Process pages individually for PDFs
 		log.Info("Processing %d pages individually for quotation extraction", len(parsedItem.Pages))
-		quotations, err = extractQuotationsFromPages(ctx, &client, parsedItem, summary, quotationSchema, log)
+		quotations, err = extractQuotationsFromPages(ctx, &client, parsedItem, summary, quotationSchema, opts, log)
 	} else {
 		// Process entire content at once for non-paginated documents
 		log.Info("Processing entire document at once for quotation extraction")
-		quotations, err = extractQuotationsFromFullText(ctx, &client, parsedItem, summary, quotationSchema, log)
+		quotations, err = extractQuotationsFromFullText(ctx, &client, parsedItem, summary, quotationSchema, opts, log)
 	}
 
 	if err != nil {
 		return nil, err
 	}
 
-	// Apply max quotations limit if necessary
-	if maxQuotations > 0 && len(quotations) > maxQuotations {
-		log.Info("Found %d quotations, prioritizing to top %d", len(quotations), maxQuotations)
-		quotations, err = prioritizeQuotations(ctx, &client, quotations, parsedItem, summary, maxQuotations, log)
-		if err != nil {
-			log.Error("Failed to prioritize quotations, returning all: %v", err)
-			// Don't fail completely, just return all quotations if prioritization fails
-			return quotations, nil
+	// The prompt asks for the minimum length, but the model does not always respect it
+	if opts.MinLengthWords > 0 {
+		kept := filterShortQuotations(quotations, opts.MinLengthWords)
+		if len(kept) < len(quotations) {
+			log.Info("Dropped %d quotations shorter than %d words", len(quotations)-len(kept), opts.MinLengthWords)
 		}
-		log.Info("Prioritization complete, returning %d quotations", len(quotations))
+		quotations = kept
 	}
 
+	// Select up to the max, keeping the rest ranked for a later larger max
+	quotations = rankQuotations(ctx, quotations, maxQuotations, func(ctx context.Context, quotations []models.Quotation) ([]int, error) {
+		return prioritizeQuotations(ctx, &client, quotations, parsedItem, summary, opts, log)
+	}, log)
+
 	return quotations, nil
 }
 
-// extractQuotationsFromPages processes each page individually to extract quotations with accurate page numbers
-func extractQuotationsFromPages(ctx context.Context, client *openai.Client, parsedItem *models.ParsedItem, summary string, schema map[string]any, log logger.Logger) ([]models.Quotation, error) {
-	// Define page data struct for parallel processing
-	type pageData struct {
-		content       string
-		sourcePageNum string
+// buildQuotationSchema returns the JSON schema for quotation extraction
+// responses, with a translation field when one is requested
+func buildQuotationSchema(withTranslation bool) map[string]any {
+	properties := map[string]any{
+		"quotation_text": map[string]any{"type": "string"},
+		"page_number":    map[string]any{"type": "string"},
+		"context":        map[string]any{"type": "string"},
+		"relevance":      map[string]any{"type": "string"},
 	}
+	required := []string{"quotation_text", "page_number", "context", "relevance"}
+	if withTranslation {
+		properties["translation"] = map[string]any{"type": "string"}
+		required = append(required, "translation")
+	}
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"quotations": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type":                 "object",
+					"properties":           properties,
+					"required":             required,
+					"additionalProperties": false,
+				},
+			},
+		},
+		"required":             []string{"quotations"},
+		"additionalProperties": false,
+	}
+}
 
-	// Prepare page data
-	pages := make([]pageData, len(parsedItem.Pages))
-	for i := range parsedItem.Pages {
-		pages[i] = pageData{
-			content:       parsedItem.Pages[i],
-			sourcePageNum: parsedItem.PageNumbers[i],
+// filterShortQuotations returns the quotations with at least minWords words
+func filterShortQuotations(quotations []models.Quotation, minWords int) []models.Quotation {
+	kept := make([]models.Quotation, 0, len(quotations))
+	for _, q := range quotations {
+		if len(strings.Fields(q.QuotationText)) >= minWords {
+			kept = append(kept, q)
 		}
 	}
+	return kept
+}
 
-	// Process pages using worker pool and rate limiting
-	pageQuotations, err := ParallelProcess(ctx, pages, log, func(ctx context.Context, pageIndex int, page pageData) ([]models.Quotation, error) {
-		log.Debug("Extracting quotations from page %d (source: %s) with rate limiting", pageIndex+1, page.sourcePageNum)
+// quotationCriteria returns the prompt lines describing what makes a good
+// quotation, including the optional minimum length and focus
+func quotationCriteria(opts QuotationOptions, fullText bool) string {
+	var b strings.Builder
+	b.WriteString(`- A direct quote from the text (exact wording)
+- Significant in presenting key arguments, findings, or theoretical contributions
+- Self-contained enough to be meaningful on its own
+- Memorable or well-articulated
+- NOT a citation or reference to other works`)
+	if fullText {
+		b.WriteString("\n- Distributed throughout the document (introduction, body, conclusion)")
+	}
+	if opts.MinLengthWords > 0 {
+		fmt.Fprintf(&b, "\n- At least %d words long", opts.MinLengthWords)
+	}
+	if focus := strings.TrimSpace(opts.Focus); focus != "" {
+		fmt.Fprintf(&b, "\n- Relevant to this research focus: %q. Prefer passages that address it directly and explain the connection in the relevance field", focus)
+	}
+	return b.String()
+}
 
-		prompt := fmt.Sprintf(`You are analyzing page %s of an academic document.
+// buildPageQuotationPrompt builds the quotation extraction prompt for a single page
+func buildPageQuotationPrompt(title, summary, sourcePageNum, content string, opts QuotationOptions) string {
+	perPageMax := opts.PerPageMax
+	if perPageMax <= 0 {
+		perPageMax = defaultPerPageQuotations
+	}
+
+	return fmt.Sprintf(`You are analyzing page %s of an academic document.
 
 Document Summary:
 %s
@@ -597,26 +1107,87 @@ Document Title: %s
 Page Content:
 %s
 
-Extract 0-3 representative quotations from this page. A good quotation should be:
-- A direct quote from the text (exact wording)
-- Significant in presenting key arguments, findings, or theoretical contributions
-- Self-contained enough to be meaningful on its own
-- Memorable or well-articulated
-- NOT a citation or reference to other works
+Extract 0-%d representative quotations from this page. A good quotation should be:
+%s
 
 For each quotation, provide:
 - quotation_text: The exact quoted text (use quotes around it)
 - page_number: "%s" (the source page number)
 - context: Brief explanation of where this appears (e.g., "in the introduction", "from the methodology section")
-- relevance: Why this quotation is significant (key argument, important finding, etc.)
+- relevance: Why this quotation is significant (key argument, important finding, etc.)%s
 
 If there are no suitable quotations on this page, return an empty array.`,
-			page.sourcePageNum, summary, parsedItem.Metadata.Title, page.content, page.sourcePageNum)
+		sourcePageNum, summary, title, content, perPageMax, quotationCriteria(opts, false), sourcePageNum, targetLanguageInstruction(opts.TargetLanguage, true))
+}
+
+// buildFullTextQuotationPrompt builds the quotation extraction prompt for a non-paginated document
+func buildFullTextQuotationPrompt(title, summary, content string, opts QuotationOptions) string {
+	return fmt.Sprintf(`You are analyzing an academic document.
+
+Document Summary:
+%s
+
+Document Title: %s
+Full Content:
+%s
+
+Extract 5-15 representative quotations from this document. A good quotation should be:
+%s
+
+For each quotation, provide:
+- quotation_text: The exact quoted text (use quotes around it)
+- page_number: "" (empty string since this document doesn't have page numbers)
+- context: Brief explanation of where this appears (e.g., "in the introduction", "from the methodology section")
+- relevance: Why this quotation is significant (key argument, important finding, etc.)%s`,
+		summary, title, content, quotationCriteria(opts, true), targetLanguageInstruction(opts.TargetLanguage, true))
+}
+
+// focusPriority returns the prioritization criterion for an optional research
+// focus, or an empty string when there is none
+func focusPriority(focus string) string {
+	focus = strings.TrimSpace(focus)
+	if focus == "" {
+		return ""
+	}
+	return fmt.Sprintf("\n6. Most importantly, address this research focus: %q", focus)
+}
+
+// quotationsResult is the structured output of the quotation extraction requests
+type quotationsResult struct {
+	Quotations []models.Quotation `json:"quotations"`
+}
+
+// extractQuotationsFromPages processes each page individually to extract quotations with accurate page numbers
+func extractQuotationsFromPages(ctx context.Context, client *openai.Client, parsedItem *models.ParsedItem, summary string, schema map[string]any, opts QuotationOptions, log logger.Logger) ([]models.Quotation, error) {
+	// Define page data struct for parallel processing
+	type pageData struct {
+		content       string
+		sourcePageNum string
+	}
+
+	// Prepare page data, skipping blank pages and those whose content could not be extracted
+	kept, skipped := analysisPages(parsedItem)
+	pages := make([]pageData, 0, len(kept))
+	for _, i := range kept {
+		pages = append(pages, pageData{
+			content:       parsedItem.Pages[i],
+			sourcePageNum: parsedItem.PageNumbers[i],
+		})
+	}
+	if len(skipped) > 0 {
+		log.Warn("Skipping %d blank or near-empty pages for quotation extraction (pages: %s)", len(skipped), strings.Join(skipped, ", "))
+	}
+
+	// Process pages using worker pool and rate limiting
+	pageQuotations, err := ParallelProcess(ctx, pages, log, func(ctx context.Context, pageIndex int, page pageData) ([]models.Quotation, error) {
+		log.Debug("Extracting quotations from page %d (source: %s) with rate limiting", pageIndex+1, page.sourcePageNum)
+
+		prompt := buildPageQuotationPrompt(parsedItem.Metadata.Title, summary, page.sourcePageNum, page.content, opts)
 
 		// Wrap the API call with rate limiting and retry logic
 		quotations, err := RateLimitedCall(ctx, estimatedTokensPerPage, log, func(ctx context.Context) ([]models.Quotation, error) {
-			response, err := client.Responses.New(ctx, responses.ResponseNewParams{
-				Model: shared.ChatModelGPT5Mini,
+			result, err := newStructuredResponse[quotationsResult](ctx, client, responses.ResponseNewParams{
+				Model: quotationModel,
 				Input: responses.ResponseNewParamsInputUnion{
 					OfInputItemList: responses.ResponseInputParam{
 						responses.ResponseInputItemParamOfMessage(
@@ -630,21 +1201,12 @@ If there are no suitable quotations on this page, return an empty array.`,
 				Text: responses.ResponseTextConfigParam{
 					Format: responses.ResponseFormatTextConfigParamOfJSONSchema("quotations", schema),
 				},
-			})
+			}, "quotation extraction", log)
 
 			if err != nil {
 				return nil, err
 			}
 
-			var result struct {
-				Quotations []models.Quotation `json:"quotations"`
-			}
-			outputText := response.OutputText()
-			err = json.Unmarshal([]byte(outputText), &result)
-			if err != nil {
-				return nil, err
-			}
-
 			return result.Quotations, nil
 		})
 
@@ -667,54 +1229,34 @@ If there are no suitable quotations on this page, return an empty array.`,
 		allQuotations = append(allQuotations, quotes...)
 	}
 
-	log.Info("Successfully extracted %d quotations from %d pages", len(allQuotations), len(parsedItem.Pages))
+	log.Info("Successfully extracted %d quotations from %d pages", len(allQuotations), len(pages))
 	return allQuotations, nil
 }
 
 // extractQuotationsFromFullText processes the entire document at once for non-paginated documents
-func extractQuotationsFromFullText(ctx context.Context, client *openai.Client, parsedItem *models.ParsedItem, summary string, schema map[string]any, log logger.Logger) ([]models.Quotation, error) {
+func extractQuotationsFromFullText(ctx context.Context, client *openai.Client, parsedItem *models.ParsedItem, summary string, schema map[string]any, opts QuotationOptions, log logger.Logger) ([]models.Quotation, error) {
 	fullContent := strings.Join(parsedItem.Pages, "\n")
 
-	prompt := fmt.Sprintf(`You are analyzing an academic document.
-
-Document Summary:
-%s
-
-Document Title: %s
-Full Content:
-%s
-
-Extract 5-15 representative quotations from this document. A good quotation should be:
-- A direct quote from the text (exact wording)
-- Significant in presenting key arguments, findings, or theoretical contributions
-- Self-contained enough to be meaningful on its own
-- Memorable or well-articulated
-- NOT a citation or reference to other works
-- Distributed throughout the document (introduction, body, conclusion)
-
-For each quotation, provide:
-- quotation_text: The exact quoted text (use quotes around it)
-- page_number: "" (empty string since this document doesn't have page numbers)
-- context: Brief explanation of where this appears (e.g., "in the introduction", "from the methodology section")
-- relevance: Why this quotation is significant (key argument, important finding, etc.)`,
-		summary, parsedItem.Metadata.Title, fullContent)
+	prompt := buildFullTextQuotationPrompt(parsedItem.Metadata.Title, summary, fullContent, opts)
 
 	log.Debug("Calling OpenAI API for full-text quotation extraction")
-	response, err := client.Responses.New(ctx, responses.ResponseNewParams{
-		Model: shared.ChatModelGPT5Mini,
-		Input: responses.ResponseNewParamsInputUnion{
-			OfInputItemList: responses.ResponseInputParam{
-				responses.ResponseInputItemParamOfMessage(
-					responses.ResponseInputMessageContentListParam{
-						responses.ResponseInputContentParamOfInputText(prompt),
-					},
-					"user",
-				),
+	result, err := callWithTimeout(ctx, summaryTimeout(log), func(ctx context.Context) (quotationsResult, error) {
+		return newStructuredResponse[quotationsResult](ctx, client, responses.ResponseNewParams{
+			Model: quotationModel,
+			Input: responses.ResponseNewParamsInputUnion{
+				OfInputItemList: responses.ResponseInputParam{
+					responses.ResponseInputItemParamOfMessage(
+						responses.ResponseInputMessageContentListParam{
+							responses.ResponseInputContentParamOfInputText(prompt),
+						},
+						"user",
+					),
+				},
 			},
-		},
-		Text: responses.ResponseTextConfigParam{
-			Format: responses.ResponseFormatTextConfigParamOfJSONSchema("quotations", schema),
-		},
+			Text: responses.ResponseTextConfigParam{
+				Format: responses.ResponseFormatTextConfigParamOfJSONSchema("quotations", schema),
+			},
+		}, "quotation extraction", log)
 	})
 
 	if err != nil {
@@ -722,108 +1264,18 @@ For each quotation, provide:
 		return nil, err
 	}
 
-	var result struct {
-		Quotations []models.Quotation `json:"quotations"`
-	}
-	outputText := response.OutputText()
-	err = json.Unmarshal([]byte(outputText), &result)
-	if err != nil {
-		log.Error("Failed to parse quotations: %v", err)
-		return nil, err
-	}
-
 	log.Info("Successfully extracted %d quotations from document", len(result.Quotations))
 	return result.Quotations, nil
 }
 
-// prioritizeQuotations takes a list of quotations and asks the LLM to select the most significant ones
-func prioritizeQuotations(ctx context.Context, client *openai.Client, quotations []models.Quotation, parsedItem *models.ParsedItem, summary string, maxQuotations int, log logger.Logger) ([]models.Quotation, error) {
-	log.Info("Prioritizing %d quotations down to %d", len(quotations), maxQuotations)
-
-	// Build a JSON representation of the quotations for the LLM
-	quotationsJSON, err := json.MarshalIndent(quotations, "", "  ")
+// CheckAPIKey lists the OpenAI models available to apiKey, a cheap request that
+// fails if the key is rejected or the API cannot be reached. It returns the
+// number of models listed. The request is not retried, so ctx bounds it.
+func CheckAPIKey(ctx context.Context, apiKey string) (int, error) {
+	client := newClient(ctx, apiKey, option.WithMaxRetries(0))
+	page, err := client.Models.List(ctx)
 	if err != nil {
-		log.Error("Failed to marshal quotations for prioritization: %v", err)
-		return nil, err
+		return 0, err
 	}
-
-	prompt := fmt.Sprintf(`You are reviewing quotations extracted from an academic document and need to select the %d most significant ones.
-
-Document Title: %s
-Document Summary:
-%s
-
-All Extracted Quotations:
-%s
-
-Your task is to select the %d MOST significant quotations from the list above. Prioritize quotations that:
-1. Present key arguments or theoretical contributions
-2. Contain important findings or conclusions
-3. Are memorable or particularly well-articulated
-4. Represent different sections of the document (diversity)
-5. Are self-contained and meaningful
-
-Return ONLY the selected quotations in the exact same format (with quotation_text, page_number, context, and relevance preserved exactly as provided). Do not modify the quotation text or metadata.
-
-Select exactly %d quotations (or fewer if there aren't enough high-quality ones).`,
-		maxQuotations, parsedItem.Metadata.Title, summary, string(quotationsJSON), maxQuotations, maxQuotations)
-
-	// JSON schema for the response
-	schema := map[string]any{
-		"type": "object",
-		"properties": map[string]any{
-			"quotations": map[string]any{
-				"type": "array",
-				"items": map[string]any{
-					"type": "object",
-					"properties": map[string]any{
-						"quotation_text": map[string]any{"type": "string"},
-						"page_number":    map[string]any{"type": "string"},
-						"context":        map[string]any{"type": "string"},
-						"relevance":      map[string]any{"type": "string"},
-					},
-					"required":             []string{"quotation_text", "page_number", "context", "relevance"},
-					"additionalProperties": false,
-				},
-			},
-		},
-		"required":             []string{"quotations"},
-		"additionalProperties": false,
-	}
-
-	log.Debug("Calling OpenAI API for quotation prioritization")
-	response, err := client.Responses.New(ctx, responses.ResponseNewParams{
-		Model: shared.ChatModelGPT5Mini,
-		Input: responses.ResponseNewParamsInputUnion{
-			OfInputItemList: responses.ResponseInputParam{
-				responses.ResponseInputItemParamOfMessage(
-					responses.ResponseInputMessageContentListParam{
-						responses.ResponseInputContentParamOfInputText(prompt),
-					},
-					"user",
-				),
-			},
-		},
-		Text: responses.ResponseTextConfigParam{
-			Format: responses.ResponseFormatTextConfigParamOfJSONSchema("prioritized_quotations", schema),
-		},
-	})
-
-	if err != nil {
-		log.Error("Failed to prioritize quotations: %v", err)
-		return nil, err
-	}
-
-	var result struct {
-		Quotations []models.Quotation `json:"quotations"`
-	}
-	outputText := response.OutputText()
-	err = json.Unmarshal([]byte(outputText), &result)
-	if err != nil {
-		log.Error("Failed to parse prioritized quotations: %v", err)
-		return nil, err
-	}
-
-	log.Info("Successfully prioritized to %d quotations", len(result.Quotations))
-	return result.Quotations, nil
+	return len(page.Data), nil
 }
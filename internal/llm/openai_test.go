@@ -3,12 +3,17 @@ package llm
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
 	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
 	"github.com/Epistemic-Technology/academic-mcp/models"
 )
 
@@ -66,7 +71,7 @@ func TestParsePDFPage_Integration(t *testing.T) {
 
 			// Test parsing the first page
 			firstPage := pages[0]
-			parsedPage, err := ParsePDFPage(ctx, apiKey, &firstPage)
+			parsedPage, err := ParsePDFPage(ctx, apiKey, &firstPage, logger.NewNoOpLogger())
 			if err != nil {
 				t.Fatalf("ParsePDFPage failed: %v", err)
 			}
@@ -164,7 +169,7 @@ func TestParsePDFPage_InvalidAPIKey(t *testing.T) {
 	// Test with invalid API key
 	invalidAPIKey := "sk-invalid-key-12345"
 	firstPage := pages[0]
-	_, err = ParsePDFPage(ctx, invalidAPIKey, &firstPage)
+	_, err = ParsePDFPage(ctx, invalidAPIKey, &firstPage, logger.NewNoOpLogger())
 	if err == nil {
 		t.Error("Expected error with invalid API key, got nil")
 	}
@@ -180,7 +185,7 @@ func TestParsePDFPage_EmptyPage(t *testing.T) {
 	ctx := context.Background()
 
 	emptyPage := models.DocumentPageData([]byte{})
-	_, err := ParsePDFPage(ctx, apiKey, &emptyPage)
+	_, err := ParsePDFPage(ctx, apiKey, &emptyPage, logger.NewNoOpLogger())
 	if err == nil {
 		t.Error("Expected error with empty page data, got nil")
 	}
@@ -314,6 +319,38 @@ func TestParseDocument_EmptyPDF(t *testing.T) {
 	t.Logf("Got expected error: %v", err)
 }
 
+func TestParseDocument_Unsupported(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     models.DocumentData
+		expected string
+	}{
+		{
+			name:     "unknown data",
+			data:     models.DocumentData{Data: []byte("%!PS-Adobe-3.0\x00"), Type: "unknown"},
+			expected: "unsupported document type: looks like a PostScript file",
+		},
+		{
+			name:     "overridden type",
+			data:     models.DocumentData{Data: []byte("text"), Type: "odt"},
+			expected: "unsupported document type: odt",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseDocument(context.Background(), "test-key", tt.data, logger.NewNoOpLogger())
+			if err == nil || !strings.HasPrefix(err.Error(), tt.expected) {
+				t.Fatalf("Expected an error starting with %q, got %v", tt.expected, err)
+			}
+			var coded *models.CodedError
+			if !errors.As(err, &coded) || coded.Code != models.ErrorInvalidInput {
+				t.Errorf("Expected error code %s, got %v", models.ErrorInvalidInput, err)
+			}
+		})
+	}
+}
+
 func TestParsedPage_JSONSerialization(t *testing.T) {
 	// Test that ParsedPage can be properly serialized/deserialized
 	original := &models.ParsedPage{
@@ -451,3 +488,230 @@ func TestParseDocument_ConcurrentPageProcessing(t *testing.T) {
 		})
 	}
 }
+
+// orderedParser parses the pages of a PDF, finishing them in a given order,
+// into pages with two images, a table, and a footnote each
+type orderedParser struct {
+	done map[int]chan struct{} // Closed when a page is parsed
+	prev map[int]int           // The page parsed just before each page, or -1
+}
+
+func newOrderedParser(order []int) *orderedParser {
+	p := &orderedParser{done: make(map[int]chan struct{}), prev: make(map[int]int)}
+	for i, pageNum := range order {
+		p.done[pageNum] = make(chan struct{})
+		p.prev[pageNum] = -1
+		if i > 0 {
+			p.prev[pageNum] = order[i-1]
+		}
+	}
+	return p
+}
+
+func (p *orderedParser) parse(ctx context.Context, pageNum int, pageData models.DocumentPageData, scanned bool) (*models.ParsedPage, error) {
+	if prev := p.prev[pageNum]; prev >= 0 {
+		<-p.done[prev]
+	}
+	defer close(p.done[pageNum])
+	return &models.ParsedPage{
+		Content:        fmt.Sprintf("Page %d", pageNum+1),
+		PageNumberInfo: models.PageNumberInfo{PageNumber: fmt.Sprintf("%d", pageNum+10), Confidence: 0.9},
+		Images: []models.Image{
+			{Caption: fmt.Sprintf("Figure %d", pageNum+1)},
+			{ImageDescription: "A chart"},
+		},
+		Tables:    []models.Table{{TableID: fmt.Sprintf("Table %d", pageNum+1)}},
+		Footnotes: []models.Footnote{{Marker: fmt.Sprintf("%d", pageNum+1), Text: "A note."}},
+	}, nil
+}
+
+func TestParsePDFWith_StableIDsIndependentOfParseOrder(t *testing.T) {
+	pdfData := models.DocumentData{Data: buildTextPdf("One", "Two", "Three", "Four", "Five"), Type: "pdf"}
+	log := logger.NewNoOpLogger()
+
+	var first *models.ParsedItem
+	for _, order := range [][]int{{0, 1, 2, 3, 4}, {4, 3, 2, 1, 0}, {2, 0, 4, 1, 3}} {
+		item, err := parsePDFWith(context.Background(), pdfData, newOrderedParser(order).parse, log)
+		if err != nil {
+			t.Fatalf("Parse in order %v failed: %v", order, err)
+		}
+		storage.AssignStableIDs(item)
+		if first == nil {
+			first = item
+			if len(item.Images) != 10 || item.Images[2].StableID == "" || item.Images[2].StableID == item.Images[3].StableID {
+				t.Fatalf("Expected distinct stable IDs for 10 images, got %+v", item.Images)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(item.Images, first.Images) || !reflect.DeepEqual(item.Tables, first.Tables) || !reflect.DeepEqual(item.Footnotes, first.Footnotes) {
+			t.Errorf("Parse in order %v: expected the same items and IDs as in page order, got images %+v, tables %+v, footnotes %+v",
+				order, item.Images, item.Tables, item.Footnotes)
+		}
+	}
+}
+
+func TestMergeTextChunks(t *testing.T) {
+	results := []*textParseResult{
+		{
+			Metadata:   models.ItemMetadata{Title: "A Long Report", Authors: []string{"Smith, Jane"}, DataAvailability: "Data are available on request.", Keywords: []string{"Survey methods", "trust"}},
+			Content:    "# Introduction\n\nFirst part.",
+			References: []models.Reference{{ReferenceText: "Doe, J. (2019). A study."}},
+			Footnotes:  []models.Footnote{{Marker: "1", Text: "First note"}},
+		},
+		{
+			Metadata:   models.ItemMetadata{Title: "Section heading mistaken for title", DOI: "10.1000/report", FundingStatement: "Funded by the ERC.", DataAvailability: "Data are on Zenodo.", Keywords: []string{"Trust", "panel data"}},
+			Content:    "# Conclusion\n\nSecond part.",
+			References: []models.Reference{{ReferenceText: "Doe,  J. (2019). A study."}, {ReferenceText: "Roe, R. (2020). Another.", PageNumber: "12", PageIndex: 3}},
+			Endnotes:   []models.Endnote{{Marker: "i", Text: "Endnote"}},
+		},
+	}
+
+	item := mergeTextChunks(results)
+
+	if item.ChunkCount != 2 {
+		t.Errorf("Expected chunk count 2, got %d", item.ChunkCount)
+	}
+	if item.Metadata.Title != "A Long Report" || item.Metadata.DOI != "10.1000/report" {
+		t.Errorf("Expected first non-empty metadata values, got %+v", item.Metadata)
+	}
+	if item.Metadata.FundingStatement != "Funded by the ERC." || item.Metadata.DataAvailability != "Data are available on request." || item.Metadata.Acknowledgments != "" {
+		t.Errorf("Expected first non-empty statements, got %+v", item.Metadata)
+	}
+	if !reflect.DeepEqual(item.Metadata.Keywords, []string{"Survey methods", "trust", "panel data"}) {
+		t.Errorf("Expected keywords of all chunks without duplicates, got %q", item.Metadata.Keywords)
+	}
+	if len(item.Pages) != 1 || item.Pages[0] != "# Introduction\n\nFirst part.\n\n# Conclusion\n\nSecond part." {
+		t.Errorf("Expected concatenated content, got %q", item.Pages)
+	}
+	if len(item.References) != 2 {
+		t.Errorf("Expected duplicate reference to be dropped, got %d references", len(item.References))
+	}
+	for _, ref := range item.References {
+		if ref.PageNumber != "" || ref.PageIndex != 0 {
+			t.Errorf("Expected no page location for a text document's reference, got %+v", ref)
+		}
+	}
+	if len(item.Footnotes) != 1 || len(item.Endnotes) != 1 {
+		t.Errorf("Expected notes from all chunks, got %d footnotes and %d endnotes", len(item.Footnotes), len(item.Endnotes))
+	}
+
+	single := mergeTextChunks(results[:1])
+	if single.ChunkCount != 0 {
+		t.Errorf("Expected no chunk count for an unsplit document, got %d", single.ChunkCount)
+	}
+}
+
+func TestMergeMissingMetadata(t *testing.T) {
+	pages := []models.ItemMetadata{
+		{Title: "Trust in Survey Research", Abstract: "We study trust.", Keywords: []string{"trust", "Survey methods"}, Language: "en"},
+		{},
+		{Title: "Running header", Keywords: []string{"TRUST; nonresponse"}, Language: "en"},
+	}
+	var metadata models.ItemMetadata
+	for i := range pages {
+		mergeMissingMetadata(&metadata, &pages[i])
+	}
+	if metadata.Title != "Trust in Survey Research" || metadata.Abstract != "We study trust." {
+		t.Errorf("Expected first non-empty values, got %+v", metadata)
+	}
+	if want := []string{"trust", "Survey methods", "nonresponse"}; !reflect.DeepEqual(metadata.Keywords, want) {
+		t.Errorf("Expected keywords %q, got %q", want, metadata.Keywords)
+	}
+}
+
+func TestBuildPageQuotationPrompt(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        QuotationOptions
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:        "defaults",
+			opts:        QuotationOptions{},
+			contains:    []string{"Extract 0-3 representative quotations", `page_number: "125"`, "Page text"},
+			notContains: []string{"words long", "research focus", "Distributed throughout"},
+		},
+		{
+			name:     "per-page maximum",
+			opts:     QuotationOptions{PerPageMax: 5},
+			contains: []string{"Extract 0-5 representative quotations"},
+		},
+		{
+			name:     "minimum length",
+			opts:     QuotationOptions{MinLengthWords: 12},
+			contains: []string{"At least 12 words long"},
+		},
+		{
+			name:     "focus",
+			opts:     QuotationOptions{Focus: "  methodological limitations "},
+			contains: []string{`Relevant to this research focus: "methodological limitations"`},
+		},
+		{
+			name:        "blank focus",
+			opts:        QuotationOptions{Focus: "   "},
+			notContains: []string{"research focus"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prompt := buildPageQuotationPrompt("A Title", "A summary", "125", "Page text", tt.opts)
+			for _, want := range tt.contains {
+				if !strings.Contains(prompt, want) {
+					t.Errorf("Expected prompt to contain %q, got:\n%s", want, prompt)
+				}
+			}
+			for _, unwanted := range tt.notContains {
+				if strings.Contains(prompt, unwanted) {
+					t.Errorf("Expected prompt not to contain %q, got:\n%s", unwanted, prompt)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildFullTextQuotationPrompt(t *testing.T) {
+	prompt := buildFullTextQuotationPrompt("A Title", "A summary", "Full text", QuotationOptions{
+		PerPageMax:     7,
+		MinLengthWords: 8,
+		Focus:          "gender and labor",
+	})
+
+	for _, want := range []string{
+		"Extract 5-15 representative quotations",
+		"Distributed throughout the document",
+		"At least 8 words long",
+		`Relevant to this research focus: "gender and labor"`,
+		`page_number: ""`,
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected prompt to contain %q, got:\n%s", want, prompt)
+		}
+	}
+	if strings.Contains(prompt, "0-7") {
+		t.Errorf("Per-page maximum should not apply to full-text extraction, got:\n%s", prompt)
+	}
+}
+
+func TestFocusPriority(t *testing.T) {
+	if got := focusPriority(""); got != "" {
+		t.Errorf("Expected no criterion without a focus, got %q", got)
+	}
+	if got := focusPriority("archival methods"); !strings.Contains(got, `"archival methods"`) {
+		t.Errorf("Expected focus in prioritization criterion, got %q", got)
+	}
+}
+
+func TestFilterShortQuotations(t *testing.T) {
+	quotations := []models.Quotation{
+		{QuotationText: "Too short."},
+		{QuotationText: "This quotation has exactly six words."},
+		{QuotationText: "  Spacing   does not   inflate  the count  "},
+	}
+
+	kept := filterShortQuotations(quotations, 6)
+	if len(kept) != 2 || kept[0].QuotationText != quotations[1].QuotationText || kept[1].QuotationText != quotations[2].QuotationText {
+		t.Errorf("Expected the two quotations of at least six words, got %+v", kept)
+	}
+}
@@ -0,0 +1,94 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/openai/openai-go/v3"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
+	"github.com/Epistemic-Technology/academic-mcp/internal/llm/prompts"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// oversizedErrorCodes are the OpenAI error codes of a request too large for the
+// model or the API to accept
+var oversizedErrorCodes = []string{"context_length_exceeded", "string_above_max_length", "request_too_large"}
+
+// oversizedMessages are phrases of such errors' messages, lowercased, for
+// errors that carry no code
+var oversizedMessages = []string{"context_length_exceeded", "maximum context length", "string_above_max_length", "request entity too large", "payload too large"}
+
+// isOversizedRequestError reports whether err is OpenAI rejecting a request
+// because its payload or context is too large, which retrying cannot fix
+func isOversizedRequestError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) {
+		if apiErr.StatusCode == http.StatusRequestEntityTooLarge {
+			return true
+		}
+		for _, code := range oversizedErrorCodes {
+			if apiErr.Code == code {
+				return true
+			}
+		}
+		return containsAny(strings.ToLower(apiErr.Message), oversizedMessages)
+	}
+	return containsAny(strings.ToLower(err.Error()), oversizedMessages)
+}
+
+// routePageParse parses page pageNum (0-indexed) with parse, and if the page is
+// too large to send, with fallback instead, marking the page degraded. Other
+// errors, and a failed fallback, fail the page.
+func routePageParse(ctx context.Context, pageNum int, parse, fallback func(context.Context) (*models.ParsedPage, error), log logger.Logger) (*models.ParsedPage, error) {
+	parsed, err := parse(ctx)
+	if err == nil || !isOversizedRequestError(err) {
+		return parsed, err
+	}
+
+	log.Warn("Page %d is too large for the model (%v), parsing its extracted text instead", pageNum+1, err)
+	parsed, fallbackErr := fallback(ctx)
+	if fallbackErr != nil {
+		return nil, fmt.Errorf("page %d is too large to parse (%w), and parsing its extracted text failed: %v", pageNum+1, err, fallbackErr)
+	}
+	parsed.Degraded = true
+	return parsed, nil
+}
+
+// parsePDFPageText parses a single-page PDF from the text pdfcpu extracts from
+// it, with the text document prompt. There is no way to render the page at
+// lower fidelity, so its images, tables' layout, and printed page number are
+// lost.
+func parsePDFPageText(ctx context.Context, apiKey string, page models.DocumentPageData, log logger.Logger) (*models.ParsedPage, error) {
+	text, err := documents.ExtractPDFPageText(page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract page text: %w", err)
+	}
+	if strings.TrimSpace(text) == "" {
+		return nil, errors.New("the page has no extractable text")
+	}
+
+	estimated := min(2*countTokens(text)+textPromptTokens, burstTokens)
+	result, err := RateLimitedCall(ctx, estimated, log, func(ctx context.Context) (*textParseResult, error) {
+		log.Debug("Calling OpenAI API for extracted page text")
+		return parseTextChunk(ctx, apiKey, text, prompts.TextDocument{}, log)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.ParsedPage{
+		Metadata:   result.Metadata,
+		Content:    result.Content,
+		References: result.References,
+		Tables:     result.Tables,
+		Footnotes:  result.Footnotes,
+		Endnotes:   result.Endnotes,
+	}, nil
+}
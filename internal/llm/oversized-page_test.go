@@ -0,0 +1,110 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/openai/openai-go/v3"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestIsOversizedRequestError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"nil", nil, false},
+		{"413 status", &openai.Error{StatusCode: http.StatusRequestEntityTooLarge}, true},
+		{"context length code", &openai.Error{StatusCode: http.StatusBadRequest, Code: "context_length_exceeded"}, true},
+		{"string too long code", &openai.Error{StatusCode: http.StatusBadRequest, Code: "string_above_max_length"}, true},
+		{"wrapped API error", fmt.Errorf("page parse: %w", &openai.Error{StatusCode: http.StatusRequestEntityTooLarge}), true},
+		{"context length message", errors.New("This model's maximum context length is 400000 tokens"), true},
+		{"payload message", errors.New("413 Payload Too Large"), true},
+		{"rate limit", &openai.Error{StatusCode: http.StatusTooManyRequests, Code: "rate_limit_exceeded"}, false},
+		{"invalid key", &openai.Error{StatusCode: http.StatusUnauthorized, Code: "invalid_api_key"}, false},
+		{"timeout", ErrCallTimeout, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isOversizedRequestError(tt.err); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRoutePageParse(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewNoOpLogger()
+	oversized := &openai.Error{StatusCode: http.StatusRequestEntityTooLarge}
+	parsedPage := func(content string) func(context.Context) (*models.ParsedPage, error) {
+		return func(context.Context) (*models.ParsedPage, error) {
+			return &models.ParsedPage{Content: content}, nil
+		}
+	}
+	failing := func(err error) func(context.Context) (*models.ParsedPage, error) {
+		return func(context.Context) (*models.ParsedPage, error) { return nil, err }
+	}
+
+	tests := []struct {
+		name            string
+		parse, fallback func(context.Context) (*models.ParsedPage, error)
+		content         string
+		degraded        bool
+		wantErr         error
+	}{
+		{name: "page parsed", parse: parsedPage("page"), fallback: parsedPage("text"), content: "page"},
+		{name: "oversized page falls back", parse: failing(oversized), fallback: parsedPage("text"), content: "text", degraded: true},
+		{name: "other errors fail the page", parse: failing(errors.New("invalid_api_key")), fallback: parsedPage("text"), wantErr: errors.New("invalid_api_key")},
+		{name: "failed fallback keeps the original error", parse: failing(oversized), fallback: failing(errors.New("the page has no extractable text")), wantErr: oversized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fallbackCalled := false
+			fallback := func(ctx context.Context) (*models.ParsedPage, error) {
+				fallbackCalled = true
+				return tt.fallback(ctx)
+			}
+			page, err := routePageParse(ctx, 0, tt.parse, fallback, log)
+			if tt.wantErr != nil {
+				if err == nil {
+					t.Fatalf("Expected an error, got page %+v", page)
+				}
+				var apiErr *openai.Error
+				if errors.As(tt.wantErr, &apiErr) && !errors.As(err, &apiErr) {
+					t.Errorf("Expected the API error in the chain, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("routePageParse failed: %v", err)
+			}
+			if page.Content != tt.content || page.Degraded != tt.degraded {
+				t.Errorf("Expected content %q (degraded %v), got %q (degraded %v)", tt.content, tt.degraded, page.Content, page.Degraded)
+			}
+			if fallbackCalled != tt.degraded {
+				t.Errorf("Expected fallback called %v, got %v", tt.degraded, fallbackCalled)
+			}
+		})
+	}
+}
+
+func TestAssessPageQuality_Degraded(t *testing.T) {
+	pages := []*models.ParsedPage{
+		{Content: "A page the model saw and assessed, with enough text to count.", PageAssessment: models.PageAssessment{ContentConfidence: 0.9}},
+		{Content: "A fold-out table parsed from its extracted text, long enough to count.", Degraded: true},
+	}
+	quality := assessPageQuality(pages, nil)
+	if quality[0].Degraded || quality[0].ContentConfidence == nil {
+		t.Errorf("Expected an assessed page, got %+v", quality[0])
+	}
+	if !quality[1].Degraded || quality[1].ContentConfidence != nil {
+		t.Errorf("Expected a degraded page without an assessment, got %+v", quality[1])
+	}
+}
@@ -2,10 +2,14 @@ package llm
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/Epistemic-Technology/academic-mcp/models"
 )
 
+const minPageNumberConfidence = 0.7
+
 // pageInfo holds detected page number information for validation
 type pageInfo struct {
 	number     string
@@ -13,9 +17,28 @@ type pageInfo struct {
 	index      int
 }
 
+// numberStyle is the numbering system a printed page number is written in
+type numberStyle int
+
+const (
+	styleUnknown numberStyle = iota
+	styleArabic
+	styleRoman
+)
+
+// pageNumber is a confidently detected page number parsed to its numeric value
+type pageNumber struct {
+	index int
+	value int
+	style numberStyle
+}
+
 // validatePageNumbers analyzes detected page numbers and returns a validated page numbering scheme
-// Returns a slice of page number strings (one per page) to use for storage/access
-func validatePageNumbers(pages []*models.ParsedPage) []string {
+// Returns a slice of page number strings (one per page) to use for storage/access,
+// and the scheme they follow, models.PageNumberingSource or models.PageNumberingSequential.
+// offset is the number of physical pages before the first of pages, for a range
+// of a PDF, so that pages without a detected number get their physical number
+func validatePageNumbers(pages []*models.ParsedPage, offset int) ([]string, string) {
 	// Extract detected page numbers with confidence
 	var detectedPages []pageInfo
 	var pageRangeInfo string
@@ -37,120 +60,117 @@ func validatePageNumbers(pages []*models.ParsedPage) []string {
 	}
 
 	// Try to parse and validate source page numbers
-	if useSourceNumbers(detectedPages, pageRangeInfo) {
-		return extractSourceNumbers(detectedPages)
+	if useSourceNumbers(detectedPages, len(pages), pageRangeInfo) {
+		return extractSourceNumbers(detectedPages, len(pages), offset), models.PageNumberingSource
 	}
 
 	// Fallback to sequential 1-n numbering
 	result := make([]string, len(pages))
 	for i := range pages {
-		result[i] = fmt.Sprintf("%d", i+1)
+		result[i] = fmt.Sprintf("%d", offset+i+1)
 	}
-	return result
+	return result, models.PageNumberingSequential
 }
 
 // useSourceNumbers determines if we should use source page numbers based on validation
-func useSourceNumbers(pages []pageInfo, rangeInfo string) bool {
-	const minConfidence = 0.7
+func useSourceNumbers(pages []pageInfo, pageCount int, rangeInfo string) bool {
 	const minCoverageRatio = 0.6 // At least 60% of pages must have numbers
 
+	if pageCount == 0 {
+		return false
+	}
+
 	// Count pages with confident numbers
-	confidenPages := 0
+	confidentPages := 0
 	for _, p := range pages {
-		if p.confidence >= minConfidence && p.number != "" {
-			confidenPages++
+		if p.confidence >= minPageNumberConfidence && p.number != "" {
+			confidentPages++
 		}
 	}
 
 	// Need sufficient coverage
-	coverageRatio := float64(confidenPages) / float64(len(pages))
+	coverageRatio := float64(confidentPages) / float64(pageCount)
 	if coverageRatio < minCoverageRatio {
 		return false
 	}
 
 	// Parse numbers and check for valid sequence
-	parsedNumbers := make(map[int]int) // index -> numeric page number
-	hasNumericSequence := false
+	return isMonotonic(parsePageNumbers(pages))
+}
 
+// parsePageNumbers returns the confidently detected arabic and roman page
+// numbers in document order
+func parsePageNumbers(pages []pageInfo) []pageNumber {
+	var numbers []pageNumber
 	for _, p := range pages {
-		if p.confidence >= minConfidence && p.number != "" {
-			// Try to parse as integer
-			var num int
-			_, err := fmt.Sscanf(p.number, "%d", &num)
-			if err == nil && num > 0 {
-				parsedNumbers[p.index] = num
-				hasNumericSequence = true
-			}
+		if p.confidence < minPageNumberConfidence || p.number == "" {
+			continue
+		}
+		if value, style := parsePageNumber(p.number); style != styleUnknown {
+			numbers = append(numbers, pageNumber{index: p.index, value: value, style: style})
 		}
 	}
-
-	if !hasNumericSequence {
-		return false
-	}
-
-	// Check for monotonicity with allowance for gaps
-	return isMonotonic(parsedNumbers)
+	return numbers
 }
 
-// isMonotonic checks if page numbers generally increase, allowing for small gaps
-func isMonotonic(numbers map[int]int) bool {
+// isMonotonic checks if page numbers generally increase, allowing for small gaps.
+// Roman and arabic numerals are separate sequences: a document may switch once
+// from one numbering regime to the next, either from roman front matter to the
+// arabic body or by restarting its arabic numbering (e.g., a second volume).
+func isMonotonic(numbers []pageNumber) bool {
 	if len(numbers) < 2 {
 		return false
 	}
 
-	// Extract sorted indices
-	indices := make([]int, 0, len(numbers))
-	for idx := range numbers {
-		indices = append(indices, idx)
-	}
-
-	// Simple bubble sort for small slices
-	for i := 0; i < len(indices)-1; i++ {
-		for j := 0; j < len(indices)-i-1; j++ {
-			if indices[j] > indices[j+1] {
-				indices[j], indices[j+1] = indices[j+1], indices[j]
-			}
-		}
-	}
-
 	// Check that page numbers increase with document order
 	// Allow for gaps of up to 3 (for unnumbered pages)
-	prevPageNum := numbers[indices[0]]
 	violations := 0
+	resetAllowed := true
 
-	for i := 1; i < len(indices); i++ {
-		currPageNum := numbers[indices[i]]
-		expectedMin := prevPageNum + 1
-		expectedMax := prevPageNum + 4 // Allow up to 3 unnumbered pages
-
-		if currPageNum < expectedMin || currPageNum > expectedMax {
-			violations++
+	for i := 1; i < len(numbers); i++ {
+		prev, curr := numbers[i-1], numbers[i]
+		if curr.style == prev.style && curr.value >= prev.value+1 && curr.value <= prev.value+4 {
+			continue
 		}
 
-		prevPageNum = currPageNum
+		if resetAllowed && isNumberingReset(prev, curr) {
+			resetAllowed = false
+			continue
+		}
+		violations++
 	}
 
 	// Allow up to 20% violations (e.g., chapter breaks)
-	violationRatio := float64(violations) / float64(len(indices)-1)
+	violationRatio := float64(violations) / float64(len(numbers)-1)
 	return violationRatio <= 0.2
 }
 
-// extractSourceNumbers builds the final page number list from detected numbers
-func extractSourceNumbers(pages []pageInfo) []string {
-	const minConfidence = 0.7
+// isNumberingReset reports whether curr starts a new numbering regime after prev
+func isNumberingReset(prev, curr pageNumber) bool {
+	switch {
+	case prev.style == styleRoman && curr.style == styleArabic:
+		return true
+	case prev.style == styleArabic && curr.style == styleArabic:
+		return curr.value < prev.value
+	}
+	return false
+}
 
-	result := make([]string, len(pages))
+// extractSourceNumbers builds the final page number list from detected numbers
+func extractSourceNumbers(pages []pageInfo, pageCount, offset int) []string {
+	result := make([]string, pageCount)
 
-	// First pass: use high-confidence detected numbers
+	// First pass: use high-confidence detected numbers verbatim
 	numberedIndices := make(map[int]bool)
 	for _, p := range pages {
-		if p.confidence >= minConfidence && p.number != "" {
+		if p.confidence >= minPageNumberConfidence && p.number != "" {
 			result[p.index] = p.number
 			numberedIndices[p.index] = true
 		}
 	}
 
 	// Second pass: interpolate missing numbers if they're between known numbers
+	// in the same numbering system
 	for i := range result {
 		if !numberedIndices[i] {
 			// Try to find surrounding numbers
@@ -173,11 +193,10 @@ func extractSourceNumbers(pages []pageInfo) []string {
 
 			// If we have both prev and next, interpolate
 			if prevIdx >= 0 && nextIdx >= 0 {
-				var prevNum, nextNum int
-				_, errPrev := fmt.Sscanf(result[prevIdx], "%d", &prevNum)
-				_, errNext := fmt.Sscanf(result[nextIdx], "%d", &nextNum)
+				prevNum, prevStyle := parsePageNumber(result[prevIdx])
+				nextNum, nextStyle := parsePageNumber(result[nextIdx])
 
-				if errPrev == nil && errNext == nil {
+				if prevStyle != styleUnknown && prevStyle == nextStyle {
 					// Calculate expected number
 					gap := nextIdx - prevIdx
 					expectedGap := nextNum - prevNum
@@ -185,8 +204,7 @@ func extractSourceNumbers(pages []pageInfo) []string {
 					if gap == expectedGap {
 						// Exact interpolation
 						offset := i - prevIdx
-						result[i] = fmt.Sprintf("%d", prevNum+offset)
-						numberedIndices[i] = true
+						result[i] = formatPageNumber(prevNum+offset, prevStyle, result[prevIdx])
 					}
 				}
 			}
@@ -197,9 +215,86 @@ func extractSourceNumbers(pages []pageInfo) []string {
 	for i := range result {
 		if result[i] == "" {
 			// Use sequential numbering with prefix to indicate uncertainty
-			result[i] = fmt.Sprintf("%d", i+1)
+			result[i] = fmt.Sprintf("%d", offset+i+1)
 		}
 	}
 
 	return result
 }
+
+// parsePageNumber parses a printed page number as an arabic or roman numeral
+func parsePageNumber(s string) (int, numberStyle) {
+	s = strings.TrimSpace(s)
+	if num, err := strconv.Atoi(s); err == nil {
+		if num > 0 {
+			return num, styleArabic
+		}
+		return 0, styleUnknown
+	}
+	if num, ok := parseRomanNumeral(s); ok {
+		return num, styleRoman
+	}
+	return 0, styleUnknown
+}
+
+// formatPageNumber writes value in the given numbering system, matching the
+// case of the roman numeral like when one is given
+func formatPageNumber(value int, style numberStyle, like string) string {
+	if style != styleRoman {
+		return strconv.Itoa(value)
+	}
+	roman := formatRomanNumeral(value)
+	if like != "" && like == strings.ToUpper(like) {
+		return strings.ToUpper(roman)
+	}
+	return roman
+}
+
+// parseRomanNumeral parses a roman numeral written in canonical form in a single
+// case (e.g., "iv" or "XII", but not "iiii" or "Xii")
+func parseRomanNumeral(s string) (int, bool) {
+	lower := strings.ToLower(s)
+	if lower == "" || (s != lower && s != strings.ToUpper(s)) {
+		return 0, false
+	}
+
+	values := map[byte]int{'i': 1, 'v': 5, 'x': 10, 'l': 50, 'c': 100, 'd': 500, 'm': 1000}
+	total := 0
+	for i := 0; i < len(lower); i++ {
+		value, ok := values[lower[i]]
+		if !ok {
+			return 0, false
+		}
+		if i+1 < len(lower) && values[lower[i+1]] > value {
+			total -= value
+		} else {
+			total += value
+		}
+	}
+
+	if total <= 0 || formatRomanNumeral(total) != lower {
+		return 0, false
+	}
+	return total, true
+}
+
+// formatRomanNumeral writes value as a lowercase roman numeral
+func formatRomanNumeral(value int) string {
+	numerals := []struct {
+		value  int
+		symbol string
+	}{
+		{1000, "m"}, {900, "cm"}, {500, "d"}, {400, "cd"},
+		{100, "c"}, {90, "xc"}, {50, "l"}, {40, "xl"},
+		{10, "x"}, {9, "ix"}, {5, "v"}, {4, "iv"}, {1, "i"},
+	}
+
+	var b strings.Builder
+	for _, numeral := range numerals {
+		for value >= numeral.value {
+			b.WriteString(numeral.symbol)
+			value -= numeral.value
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,157 @@
+package llm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// numberedPages builds parsed pages with the given detected page numbers; an
+// empty number is a page without a detected number and "?" one detected with
+// low confidence
+func numberedPages(numbers ...string) []*models.ParsedPage {
+	pages := make([]*models.ParsedPage, len(numbers))
+	for i, number := range numbers {
+		page := &models.ParsedPage{Content: "text"}
+		switch number {
+		case "":
+		case "?":
+			page.PageNumberInfo = models.PageNumberInfo{PageNumber: "7", Confidence: 0.3}
+		default:
+			page.PageNumberInfo = models.PageNumberInfo{PageNumber: number, Confidence: 0.9}
+		}
+		pages[i] = page
+	}
+	return pages
+}
+
+func TestValidatePageNumbers(t *testing.T) {
+	tests := []struct {
+		name       string
+		pages      []*models.ParsedPage
+		expected   []string
+		sequential bool // The detected numbers are not used
+	}{
+		{
+			name:     "arabic numbering",
+			pages:    numberedPages("125", "126", "127", "128"),
+			expected: []string{"125", "126", "127", "128"},
+		},
+		{
+			name:     "roman front matter then arabic body",
+			pages:    numberedPages("i", "ii", "iii", "iv", "1", "2", "3", "4"),
+			expected: []string{"i", "ii", "iii", "iv", "1", "2", "3", "4"},
+		},
+		{
+			name:     "uppercase roman numerals are kept verbatim",
+			pages:    numberedPages("IX", "X", "XI", "1", "2"),
+			expected: []string{"IX", "X", "XI", "1", "2"},
+		},
+		{
+			name:     "missing roman page is interpolated as a roman numeral",
+			pages:    numberedPages("ii", "iii", "", "v", "vi", "1", "2", "3", "4", "5"),
+			expected: []string{"ii", "iii", "iv", "v", "vi", "1", "2", "3", "4", "5"},
+		},
+		{
+			name:     "low-confidence arabic page is interpolated",
+			pages:    numberedPages("xi", "xii", "1", "?", "3", "4"),
+			expected: []string{"xi", "xii", "1", "2", "3", "4"},
+		},
+		{
+			name:     "no interpolation across the reset point",
+			pages:    numberedPages("x", "xi", "", "1", "2", "3", "4"),
+			expected: []string{"x", "xi", "3", "1", "2", "3", "4"},
+		},
+		{
+			name:     "unnumbered pages between gaps fall back to sequential numbers",
+			pages:    numberedPages("", "10", "11", "12", "", "", "16", "17", "18"),
+			expected: []string{"1", "10", "11", "12", "5", "6", "16", "17", "18"},
+		},
+		{
+			name:     "mid-book restart of arabic numbering",
+			pages:    numberedPages("48", "49", "50", "51", "1", "2", "3", "4"),
+			expected: []string{"48", "49", "50", "51", "1", "2", "3", "4"},
+		},
+		{
+			name:     "roman front matter and a later restart",
+			pages:    numberedPages("i", "ii", "1", "2", "3", "4", "5", "6", "1", "2", "3", "4"),
+			expected: []string{"i", "ii", "1", "2", "3", "4", "5", "6", "1", "2", "3", "4"},
+		},
+		{
+			name:       "roman numerals after the body are not a reset",
+			pages:      numberedPages("1", "2", "iii", "iv"),
+			expected:   []string{"1", "2", "3", "4"},
+			sequential: true,
+		},
+		{
+			name:       "repeated restarts fall back to sequential",
+			pages:      numberedPages("1", "2", "1", "2", "1", "2", "1", "2"),
+			expected:   []string{"1", "2", "3", "4", "5", "6", "7", "8"},
+			sequential: true,
+		},
+		{
+			name:       "insufficient coverage falls back to sequential",
+			pages:      numberedPages("i", "", "", "?", "5"),
+			expected:   []string{"1", "2", "3", "4", "5"},
+			sequential: true,
+		},
+		{
+			name:     "nil pages are numbered sequentially",
+			pages:    append(numberedPages("iii", "iv", "1", "2"), nil),
+			expected: []string{"iii", "iv", "1", "2", "5"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, numbering := validatePageNumbers(tt.pages, 0)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("validatePageNumbers() = %q, want %q", got, tt.expected)
+			}
+			want := models.PageNumberingSource
+			if tt.sequential {
+				want = models.PageNumberingSequential
+			}
+			if numbering != want {
+				t.Errorf("validatePageNumbers() numbering = %q, want %q", numbering, want)
+			}
+		})
+	}
+
+	// Pages of a range without detected numbers get their physical numbers
+	if got, _ := validatePageNumbers(numberedPages("", "", ""), 40); !reflect.DeepEqual(got, []string{"41", "42", "43"}) {
+		t.Errorf("validatePageNumbers() with offset 40 = %q, want [41 42 43]", got)
+	}
+	if got, _ := validatePageNumbers(append(numberedPages("212", "213", "214"), nil), 40); !reflect.DeepEqual(got, []string{"212", "213", "214", "44"}) {
+		t.Errorf("validatePageNumbers() with offset 40 = %q, want detected numbers and 44", got)
+	}
+}
+
+func TestParseRomanNumeral(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+		ok       bool
+	}{
+		{"i", 1, true},
+		{"iv", 4, true},
+		{"XII", 12, true},
+		{"xlix", 49, true},
+		{"mcmxc", 1990, true},
+		{"iiii", 0, false},
+		{"Xii", 0, false},
+		{"vx", 0, false},
+		{"a", 0, false},
+		{"", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := parseRomanNumeral(tt.input)
+			if got != tt.expected || ok != tt.ok {
+				t.Errorf("parseRomanNumeral(%q) = %d, %v; want %d, %v", tt.input, got, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package llm
+
+import (
+	"strconv"
+	"unicode"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// minPageContentChars is the number of letters and digits below which a page's
+// extracted content is considered near-empty
+const minPageContentChars = 40
+
+// isNearEmptyContent reports whether extracted page content is empty or too short
+// to be meaningful once markdown syntax and whitespace are ignored
+func isNearEmptyContent(content string) bool {
+	count := 0
+	for _, r := range content {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			count++
+			if count >= minPageContentChars {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// assessPageQuality builds per-page quality information from the image-only flags
+// detected in the PDF, the content the model returned for each page, and the
+// model's assessment of the page, which degraded pages do not have.
+// Near-empty scanned pages have their detected page number confidence cleared so that
+// numbers guessed from blank or illegible scans do not skew page number validation.
+func assessPageQuality(parsedPages []*models.ParsedPage, imageOnly []bool) []models.PageQuality {
+	quality := make([]models.PageQuality, len(parsedPages))
+	for i, page := range parsedPages {
+		if i < len(imageOnly) {
+			quality[i].IsScanned = imageOnly[i]
+		}
+		if page != nil && page.Degraded {
+			// The text prompt does not assess the page
+			quality[i].Degraded = true
+		} else if page != nil {
+			confidence := min(max(page.PageAssessment.ContentConfidence, 0), 1)
+			quality[i].ContentConfidence = &confidence
+			quality[i].IsBlank = page.PageAssessment.IsBlank
+			quality[i].IsCover = page.PageAssessment.IsCover
+			quality[i].IsReferencesOnly = page.PageAssessment.IsReferencesOnly
+		}
+		if page == nil || isNearEmptyContent(page.Content) {
+			quality[i].NearEmpty = true
+			if page != nil && quality[i].IsScanned {
+				page.PageNumberInfo.Confidence = 0
+			}
+		}
+	}
+	return quality
+}
+
+// analysisPages returns the indexes of the pages of item that summaries and
+// quotation extraction use, leaving out pages whose content is near-empty or
+// that the model flagged blank, and the page numbers of those left out (source
+// page numbers where known). If every page would be left out, all are used.
+func analysisPages(item *models.ParsedItem) ([]int, []string) {
+	var kept []int
+	var skipped []string
+	for i := range item.Pages {
+		if i < len(item.PageQuality) && (item.PageQuality[i].NearEmpty || item.PageQuality[i].IsBlank) {
+			if i < len(item.PageNumbers) && item.PageNumbers[i] != "" {
+				skipped = append(skipped, item.PageNumbers[i])
+			} else {
+				skipped = append(skipped, strconv.Itoa(i+1))
+			}
+			continue
+		}
+		kept = append(kept, i)
+	}
+	if len(kept) == 0 {
+		kept = make([]int, len(item.Pages))
+		for i := range kept {
+			kept[i] = i
+		}
+		return kept, nil
+	}
+	return kept, skipped
+}
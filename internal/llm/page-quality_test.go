@@ -0,0 +1,121 @@
+package llm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestIsNearEmptyContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected bool
+	}{
+		{"empty", "", true},
+		{"whitespace only", "  \n\t ", true},
+		{"markdown only", "## \n\n---\n* * *", true},
+		{"illegible marker", "[illegible] [illegible]", true},
+		{"short heading", "# Chapter 3", true},
+		{"full paragraph", strings.Repeat("The quick brown fox jumps over the lazy dog. ", 3), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isNearEmptyContent(tt.content); got != tt.expected {
+				t.Errorf("isNearEmptyContent(%q) = %v, want %v", tt.content, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAssessPageQuality(t *testing.T) {
+	body := strings.Repeat("Transcribed text from a scanned book chapter. ", 3)
+	pages := []*models.ParsedPage{
+		{Content: body, PageNumberInfo: models.PageNumberInfo{PageNumber: "12", Confidence: 0.9}, PageAssessment: models.PageAssessment{ContentConfidence: 0.7}},
+		{Content: "", PageNumberInfo: models.PageNumberInfo{PageNumber: "7", Confidence: 0.8}, PageAssessment: models.PageAssessment{IsBlank: true}},
+		{Content: "", PageNumberInfo: models.PageNumberInfo{PageNumber: "14", Confidence: 0.9}, PageAssessment: models.PageAssessment{ContentConfidence: 0.2, IsCover: true}},
+		{Content: body, PageAssessment: models.PageAssessment{ContentConfidence: 1.5, IsReferencesOnly: true}},
+		nil,
+	}
+	imageOnly := []bool{true, true, false, false, false}
+
+	quality := assessPageQuality(pages, imageOnly)
+
+	expected := []models.PageQuality{
+		{IsScanned: true, ContentConfidence: confidence(0.7)},
+		{IsScanned: true, NearEmpty: true, ContentConfidence: confidence(0), IsBlank: true},
+		{NearEmpty: true, ContentConfidence: confidence(0.2), IsCover: true},
+		{ContentConfidence: confidence(1), IsReferencesOnly: true},
+		{NearEmpty: true},
+	}
+	for i := range expected {
+		if !reflect.DeepEqual(quality[i], expected[i]) {
+			t.Errorf("Page %d: expected %+v, got %+v", i+1, expected[i], quality[i])
+		}
+	}
+
+	if pages[1].PageNumberInfo.Confidence != 0 {
+		t.Errorf("Expected page number confidence of near-empty scanned page to be cleared, got %v", pages[1].PageNumberInfo.Confidence)
+	}
+	if pages[2].PageNumberInfo.Confidence != 0.9 {
+		t.Errorf("Expected page number confidence of near-empty text page to be kept, got %v", pages[2].PageNumberInfo.Confidence)
+	}
+}
+
+func confidence(value float64) *float64 {
+	return &value
+}
+
+func TestAnalysisPages(t *testing.T) {
+	tests := []struct {
+		name            string
+		item            models.ParsedItem
+		expectedKept    []int
+		expectedSkipped []string
+	}{
+		{
+			name:         "no quality information",
+			item:         models.ParsedItem{Pages: []string{"a", "b"}},
+			expectedKept: []int{0, 1},
+		},
+		{
+			name: "blank and near-empty pages are skipped",
+			item: models.ParsedItem{
+				Pages:       []string{"cover", "", "text", "refs", "blank"},
+				PageNumbers: []string{"i", "ii", "1", "2", "3"},
+				PageQuality: []models.PageQuality{{IsCover: true}, {NearEmpty: true}, {}, {IsReferencesOnly: true}, {IsBlank: true}},
+			},
+			expectedKept:    []int{0, 2, 3},
+			expectedSkipped: []string{"ii", "3"},
+		},
+		{
+			name: "sequential numbers without source page numbers",
+			item: models.ParsedItem{
+				Pages:       []string{"text", "blank"},
+				PageQuality: []models.PageQuality{{}, {IsBlank: true}},
+			},
+			expectedKept:    []int{0},
+			expectedSkipped: []string{"2"},
+		},
+		{
+			name: "every page skipped keeps them all",
+			item: models.ParsedItem{
+				Pages:       []string{"", ""},
+				PageQuality: []models.PageQuality{{IsBlank: true}, {NearEmpty: true}},
+			},
+			expectedKept: []int{0, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, skipped := analysisPages(&tt.item)
+			if !reflect.DeepEqual(kept, tt.expectedKept) || !reflect.DeepEqual(skipped, tt.expectedSkipped) {
+				t.Errorf("analysisPages() = %v, %v; expected %v, %v", kept, skipped, tt.expectedKept, tt.expectedSkipped)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// PageStaging keeps the pages of a document's PDF parse as each is parsed, so
+// a parse that is interrupted (by a crash, a cancelled context, or a failed
+// page) can resume without parsing the finished pages again
+type PageStaging interface {
+	// StagedPages returns the pages kept by an earlier attempt
+	StagedPages(ctx context.Context) ([]models.StagedPage, error)
+	// StagePage keeps a parsed page
+	StagePage(ctx context.Context, page models.StagedPage) error
+}
+
+type pageStagingKey struct{}
+
+// WithPageStaging returns a context in which PDF parses stage their pages with
+// staging and resume from the pages it already holds
+func WithPageStaging(ctx context.Context, staging PageStaging) context.Context {
+	return context.WithValue(ctx, pageStagingKey{}, staging)
+}
+
+// pageStagingFrom returns the staging of ctx, or nil if its parses are not staged
+func pageStagingFrom(ctx context.Context) PageStaging {
+	staging, _ := ctx.Value(pageStagingKey{}).(PageStaging)
+	return staging
+}
+
+// stagedPageHash identifies what a page was parsed from: the PDF's bytes, the
+// page's number in the PDF, and the prompt version, so pages staged with older
+// prompts are parsed again. The PDF as a whole is hashed because a page
+// extracted from it is not byte-for-byte the same each time.
+func stagedPageHash(pdfSum [sha256.Size]byte, pageNumber int) string {
+	return fmt.Sprintf("%x-p%d-v%d", pdfSum, pageNumber, PromptVersion)
+}
+
+// pageParser parses one page (0-indexed pageNum) of a PDF
+type pageParser func(ctx context.Context, pageNum int, pageData models.DocumentPageData, scanned bool) (*models.ParsedPage, error)
+
+// parsePDFPages parses every page of a PDF in parallel with parse. When ctx
+// has a PageStaging, each parsed page is staged as soon as it is parsed, and a
+// page staged by an earlier attempt on the same PDF is taken from staging
+// rather than parsed again. Staging failures are logged and do not fail the
+// parse.
+func parsePDFPages(ctx context.Context, pdfData models.DocumentData, pages *documents.PDFPages, imageOnly []bool, parse pageParser, log logger.Logger) ([]*models.ParsedPage, error) {
+	staging := pageStagingFrom(ctx)
+	staged := make(map[int]models.StagedPage)
+	var pdfSum [sha256.Size]byte
+	if staging != nil {
+		pdfSum = sha256.Sum256(pdfData.Data)
+		previous, err := staging.StagedPages(ctx)
+		if err != nil {
+			log.Warn("Failed to read staged pages, parsing every page: %v", err)
+		}
+		for _, page := range previous {
+			staged[page.PageIndex] = page
+		}
+	}
+
+	var resumed atomic.Int32
+	parsedPages, err := ParallelProcess(ctx, pageIndexes(pages.Len()), log, func(ctx context.Context, i int, pageNum int) (*models.ParsedPage, error) {
+		pageData, err := pages.Page(pageNum)
+		if err != nil {
+			log.Error("Failed to extract page %d: %v", pageNum+1, err)
+			return nil, err
+		}
+		var hash string
+		if staging != nil {
+			hash = stagedPageHash(pdfSum, pdfData.Pages.Offset()+pageNum+1)
+			if page, ok := staged[pageNum]; ok && page.ContentHash == hash && page.Page != nil {
+				resumed.Add(1)
+				return page.Page, nil
+			}
+		}
+		log.Debug("Processing page %d with rate limiting", pageNum+1)
+		parsed, err := parse(ctx, pageNum, pageData, pageNum < len(imageOnly) && imageOnly[pageNum])
+		if err != nil || staging == nil {
+			return parsed, err
+		}
+		// A parsed page is kept even if another page's failure is cancelling
+		// the parse, since that is when it is needed
+		if err := staging.StagePage(context.WithoutCancel(ctx), models.StagedPage{PageIndex: pageNum, ContentHash: hash, Page: parsed}); err != nil {
+			log.Warn("Failed to stage page %d: %v", pageNum+1, err)
+		}
+		return parsed, nil
+	})
+	if n := resumed.Load(); n > 0 {
+		log.Info("Resumed %d of %d pages from an interrupted parse", n, pages.Len())
+	}
+	return parsedPages, err
+}
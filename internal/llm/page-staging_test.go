@@ -0,0 +1,160 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// memoryPageStaging is a PageStaging holding its pages in memory
+type memoryPageStaging struct {
+	mu    sync.Mutex
+	pages map[int]models.StagedPage
+}
+
+func (s *memoryPageStaging) StagedPages(ctx context.Context) ([]models.StagedPage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var pages []models.StagedPage
+	for _, page := range s.pages {
+		pages = append(pages, page)
+	}
+	return pages, nil
+}
+
+func (s *memoryPageStaging) StagePage(ctx context.Context, page models.StagedPage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pages[page.PageIndex] = page
+	return nil
+}
+
+// buildTextPdf assembles a minimal PDF with a line of text on each page
+func buildTextPdf(lines ...string) []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	}
+	var kids []string
+	for _, line := range lines {
+		content := fmt.Sprintf("BT /F1 12 Tf 72 720 Td (%s) Tj ET", line)
+		kids = append(kids, fmt.Sprintf("%d 0 R", len(objects)+1))
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", len(objects)+2),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(lines))
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xrefOffset := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xrefOffset)
+	return buf.Bytes()
+}
+
+// countingParser parses a page into its page number, failing every call after
+// the first succeed calls (all calls if succeed is negative), and records the
+// pages it parsed
+type countingParser struct {
+	succeed int32
+	calls   atomic.Int32
+	mu      sync.Mutex
+	parsed  []int
+}
+
+func (p *countingParser) parse(ctx context.Context, pageNum int, pageData models.DocumentPageData, scanned bool) (*models.ParsedPage, error) {
+	if p.succeed >= 0 && p.calls.Add(1) > p.succeed {
+		return nil, errors.New("server stopped")
+	}
+	p.mu.Lock()
+	p.parsed = append(p.parsed, pageNum)
+	p.mu.Unlock()
+	return &models.ParsedPage{Content: fmt.Sprintf("Page %d", pageNum+1)}, nil
+}
+
+func TestParsePDFPages_Resume(t *testing.T) {
+	const pageCount = 8
+	var lines []string
+	for i := range pageCount {
+		lines = append(lines, fmt.Sprintf("Text of page %d", i+1))
+	}
+	pdfData := models.DocumentData{Data: buildTextPdf(lines...), Type: "pdf"}
+	pages, err := documents.OpenPdfPages(pdfData)
+	if err != nil {
+		t.Fatalf("OpenPdfPages failed: %v", err)
+	}
+	log := logger.NewNoOpLogger()
+	staging := &memoryPageStaging{pages: make(map[int]models.StagedPage)}
+	ctx := WithPageStaging(context.Background(), staging)
+
+	// The first attempt stops after three pages
+	first := &countingParser{succeed: 3}
+	if _, err := parsePDFPages(ctx, pdfData, pages, nil, first.parse, log); err == nil {
+		t.Fatal("Expected the first attempt to fail")
+	}
+	if len(first.parsed) != 3 || len(staging.pages) != 3 {
+		t.Fatalf("Expected 3 pages parsed and staged, got %v parsed and %d staged", first.parsed, len(staging.pages))
+	}
+
+	// The second parses only the pages the first did not
+	second := &countingParser{succeed: -1}
+	parsed, err := parsePDFPages(ctx, pdfData, pages, nil, second.parse, log)
+	if err != nil {
+		t.Fatalf("Resumed parse failed: %v", err)
+	}
+	if len(second.parsed) != pageCount-3 {
+		t.Errorf("Expected %d pages parsed on resume, got %v", pageCount-3, second.parsed)
+	}
+	for _, pageNum := range second.parsed {
+		if slices.Contains(first.parsed, pageNum) {
+			t.Errorf("Page %d was parsed again", pageNum+1)
+		}
+	}
+	for i, page := range parsed {
+		if want := fmt.Sprintf("Page %d", i+1); page == nil || page.Content != want {
+			t.Errorf("Page %d: expected %q, got %+v", i+1, want, page)
+		}
+	}
+
+	// A page staged from another PDF, or with older prompts, is parsed again
+	stale := staging.pages[0]
+	stale.ContentHash = strings.Replace(stale.ContentHash, fmt.Sprintf("-v%d", PromptVersion), "-v1", 1)
+	staging.pages[0] = stale
+	third := &countingParser{succeed: -1}
+	if _, err := parsePDFPages(ctx, pdfData, pages, nil, third.parse, log); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if !slices.Equal(third.parsed, []int{0}) {
+		t.Errorf("Expected only the stale page parsed, got %v", third.parsed)
+	}
+
+	// Without staging, every page is parsed
+	unstaged := &countingParser{succeed: -1}
+	if _, err := parsePDFPages(context.Background(), pdfData, pages, nil, unstaged.parse, log); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(unstaged.parsed) != pageCount {
+		t.Errorf("Expected all %d pages parsed without staging, got %v", pageCount, unstaged.parsed)
+	}
+}
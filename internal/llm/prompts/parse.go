@@ -0,0 +1,182 @@
+package prompts
+
+import (
+	"strings"
+	"text/template"
+)
+
+// Hints are optional details about the document that are added to a parsing
+// prompt. Empty fields are left out.
+type Hints struct {
+	// TitleHint is the document's title, if known from its source
+	TitleHint string
+	// PageContext is text from around the part being parsed, such as the end
+	// of the previous page, for continuity
+	PageContext string
+	// Focus is an extra instruction about what to pay attention to
+	Focus string
+}
+
+// PDFPage are the parameters of the prompt sent with each PDF page
+type PDFPage struct {
+	Hints
+	// Scanned asks for an OCR-style transcription of an image-only page
+	Scanned bool
+}
+
+// TextDocument are the parameters of the prompt for a markdown or plain text
+// document. The text to parse follows the rendered prompt.
+type TextDocument struct {
+	Hints
+	// Part and Parts number the piece of a document split into several
+	// requests; Parts is 0 when the document is sent whole
+	Part  int
+	Parts int
+	// Chapters says the parts are the chapters of a book
+	Chapters bool
+}
+
+var parseTemplates = template.Must(template.New("hints").Parse(`{{with .TitleHint}}
+
+The document is titled "{{.}}". Use this to recognize the title, but only extract metadata that appears in the text itself.{{end}}{{with .PageContext}}
+
+Text from around this part of the document, for continuity only (do not extract it):
+{{.}}{{end}}{{with .Focus}}
+
+Pay particular attention to the following: {{.}}{{end}}`))
+
+// pdfPageTemplate is the instruction sent with every PDF page
+var pdfPageTemplate = template.Must(template.Must(parseTemplates.Clone()).New("pdf-page").Parse(`{{if .Scanned}}This page is a scanned image with no embedded text layer. Act as a careful OCR transcriber:
+- Transcribe every legible word of the main text exactly as printed. Do not summarize, paraphrase, or modernize spelling.
+- Do not skip text because the scan is skewed, faint, or noisy. Transcribe what you can and write [illegible] for words you cannot read.
+- Never invent text that is not visible on the page. If the page is blank or entirely illegible, return an empty "content" string.
+- Printed page numbers on scans are often cropped, faint, or belong to one of two facing pages. Only report a page number you can read clearly.
+
+{{end}}Parse this page from an academic paper and extract it into the specified JSON structure.
+
+1. If there is document metadata on the page (title, authors, publication date, publication, doi, abstract), extract those into the "metadata" object. If the page has a funding statement, acknowledgments, or a data availability statement (often short sections or notes near the end or on the first page), copy their text into "funding_statement", "acknowledgments", and "data_availability"; leave them empty otherwise. If the page lists the authors' keywords (usually right under the abstract, after a label such as "Keywords" or "Key words"), put each keyword into "keywords" as written; leave it empty otherwise, and never make up keywords. Always set "language" to the ISO 639-1 code of the language the page's main text is written in (e.g., "en", "de", "fr"), or an empty string if the page has no text.
+
+2. Extract the main textual content of the page.
+	- Use markdown syntax to format the text.
+	- This should exclude any headers, footers, image captions, tables, and any other elements not part of the main content.
+	- Any columns should be concatenated in normal reading order.
+	- Footnote or endnote references (normally as superscripts) should be included in the main text using square brackets eg. [1].
+	- Try to identify section headings (for example by font size or weight).
+
+3. If there are any bibliographic references (not in-text citations, but full bibliographic entries), extract those into the "references" array. Note that footnotes are not references. We're looking for a bibliography or works cited section or similar.
+
+4. If there are any images on the page, extract the captions and textual descriptions of those images into the "images" array. Leave "image_url" empty: a PDF page gives no image URLs, so never make up a file name or data URI.
+
+5. If there are any tables on the page, extract the table IDs, titles, and data into the "tables" array.
+   - "table_data" must always be a GitHub-flavored markdown table: a single header row, a delimiter row (e.g., "| --- | --- |"), then one line per table row, with every row having the same number of cells as the header.
+   - If a header cell spans several columns, repeat its text in each column it covers, combined with the sub-header (e.g., "Accuracy 2019", "Accuracy 2020").
+   - If a body cell spans several rows or columns, repeat its value in each cell it covers. Leave empty cells empty.
+   - Escape any pipe characters within cells as "\|". Put table notes in the title, not in the table data.
+
+6. If there are any footnotes on this page (notes appearing at the bottom of the page), extract them into the "footnotes" array:
+   - "marker": The footnote marker/number (e.g., "1", "2", "*", "†", "a")
+   - "text": The full text of the footnote
+   - "page_number": The page number where this footnote appears (use the detected page number from step 8)
+   - "in_text_page": The page number where the footnote marker appears in the main text (usually the same as page_number, but could differ)
+
+7. If there are any endnotes on this page (notes collected at the end of a chapter/document), extract them into the "endnotes" array:
+   - "marker": The endnote marker/number (e.g., "1", "2", "i", "ii")
+   - "text": The full text of the endnote
+   - "page_number": The page number where this endnote definition appears
+
+   IMPORTANT: Distinguish between footnotes and endnotes:
+   - Footnotes appear at the bottom of the same page as their marker
+   - Endnotes are collected in a dedicated section, often at the end of chapters or the document
+   - Do NOT confuse bibliographic references with footnotes or endnotes
+
+8. Extract page numbering information into "page_number_info":
+   - "page_number": The printed page number visible on this page (e.g., "125", "iv", "A-3"). Look in headers, footers, margins, and corners. If no page number is visible, use an empty string "".
+   - "confidence": Your confidence level (0.0-1.0) that the page number is correct. Use 1.0 for clearly printed numbers, 0.5-0.8 for ambiguous cases, and 0.0 if no number is found.
+   - "location": Where the page number appears (e.g., "bottom center", "top right", "footer", "none" if not found).
+   - "page_range_info": Any page range information from the header or title page (e.g., "Pages 125-150" or "pp. 42-68"). Use empty string "" if none found.
+
+IMPORTANT for page numbers: Be conservative. Only report page numbers with high confidence. Consider that:
+- The first page may be unnumbered (title page or cover)
+- Chapter first pages are often unnumbered
+- Pages with full-bleed images may be unnumbered
+- Blank pages may be unnumbered
+- Do not confuse section numbers, figure numbers, or other numbers with page numbers
+
+9. Assess the page in "page_assessment":
+   - "content_confidence": Your confidence (0.0-1.0) that "content" faithfully captures the page's main text. Use 1.0 for clean, fully legible text, lower values for faint, skewed, rotated, or partly illegible pages, and 0.0 if you could not extract anything meaningful.
+   - "is_blank": true if the page has no meaningful content (blank, or only a page number, running header, or stray marks).
+   - "is_cover": true if the page is a cover, title, half-title, or copyright page rather than part of the body of the work.
+   - "is_references_only": true if the page contains nothing but bibliography entries.{{template "hints" .}}`))
+
+// textDocumentTemplate is the instruction for parsing markdown and plain text
+var textDocumentTemplate = template.Must(template.Must(parseTemplates.Clone()).New("text-document").Parse(`{{if .Parts}}{{if .Chapters}}This text is chapter {{.Part}} of {{.Parts}} of a book. Only extract metadata that appears in this chapter, and do not add content from other chapters.{{else}}This text is part {{.Part}} of {{.Parts}} of a longer document. Only extract metadata that appears in this part, and do not add content from other parts.{{end}}
+
+{{end}}Parse this text document from an academic paper and extract it into the specified JSON structure.
+
+1. Extract document metadata (title, authors, publication date, publication, doi, abstract) if present at the beginning. If the text has a funding statement, acknowledgments, or a data availability statement, copy their text into "funding_statement", "acknowledgments", and "data_availability"; leave them empty otherwise. If the text lists the authors' keywords (usually right under the abstract, after a label such as "Keywords" or "Key words"), put each keyword into "keywords" as written; leave it empty otherwise, and never make up keywords. Always set "language" to the ISO 639-1 code of the language the main text is written in (e.g., "en", "de", "fr").
+
+2. Extract the main textual content:
+   - If the document is already in markdown format, preserve the existing markdown syntax (headings, lists, emphasis, etc.).
+   - If the document is plain text, convert it to markdown format by identifying section headings and marking them with appropriate heading levels.
+   - Preserve paragraph structure.
+   - Preserve footnote/endnote references.
+
+3. If there are bibliographic references (full bibliographic entries, not in-text citations), extract those into the "references" array.
+
+4. If there are images (markdown image syntax or image descriptions in text), extract them into the "images" array. For markdown images, use the image URL exactly as written and the alt text. Leave "image_url" empty when the text gives no URL for an image; never make one up. For plain text, this array will typically be empty.
+
+5. If there are tables (markdown tables or structured tabular data), extract their content into the "tables" array. For plain text, this array will typically be empty.
+   - "table_data" must always be a GitHub-flavored markdown table: a single header row, a delimiter row, then one line per table row, each with the same number of cells as the header. Convert other tabular layouts to this form, repeating the text of merged cells in each column they cover.
+
+6. If there are footnotes (notes with markers at the bottom of pages), extract them into the "footnotes" array. Use empty strings for page_number and in_text_page fields since text documents don't have reliable page numbers.
+
+7. If there are endnotes at the end of the document, extract them into the "endnotes" array. Use empty string for page_number field.
+
+8. For page_number_info, use empty string for page_number, 0.0 for confidence, "none" for location, and empty string for page_range_info since text documents don't have page numbers. For page_assessment, use 0.0 for content_confidence and false for is_blank, is_cover, and is_references_only, since text documents are not assessed by page.{{template "hints" .}}
+
+Text Content:
+`))
+
+// References are the parameters of the prompt that extracts only the
+// references of a PDF page or a piece of a text document, after parsing it
+// whole ran out of output
+type References struct {
+	// Text says the references are in a text document, whose text follows the
+	// rendered prompt, rather than an attached PDF page
+	Text bool
+}
+
+// referencesTemplate is the instruction for the references-only second pass
+var referencesTemplate = template.Must(template.New("references").Parse(`Extract every bibliographic reference from this {{if .Text}}text{{else}}page{{end}} into the "references" array, and nothing else.
+
+- Include each full bibliographic entry (a bibliography, works cited, or references section), in the order they appear. Do not include in-text citations, footnotes, or endnotes.
+- Copy each entry's text exactly as written, complete from its first word to its last, including any numbering.
+- Set "doi" to the entry's DOI if it gives one (e.g., "10.1000/xyz123"), or an empty string otherwise.
+- Do not stop early: the list may be long, and every entry is needed.{{if .Text}}
+
+Text Content:
+{{end}}`))
+
+// RenderPDFPage renders the prompt sent with a PDF page
+func RenderPDFPage(params PDFPage) (string, error) {
+	return render(pdfPageTemplate, params)
+}
+
+// RenderTextDocument renders the prompt for a text document; the text to
+// parse is appended to it
+func RenderTextDocument(params TextDocument) (string, error) {
+	return render(textDocumentTemplate, params)
+}
+
+// RenderReferences renders the prompt of the references-only pass
+func RenderReferences(params References) (string, error) {
+	return render(referencesTemplate, params)
+}
+
+func render(tmpl *template.Template, params any) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, params); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,99 @@
+package prompts
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// checkGolden compares a rendered prompt with testdata/<name>.golden
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s (run go test -update to create it): %v", path, err)
+	}
+	if got != string(want) {
+		t.Errorf("rendered prompt differs from %s (run go test -update to accept it):\n%s", path, got)
+	}
+}
+
+func TestRenderPDFPage(t *testing.T) {
+	tests := []struct {
+		name   string
+		params PDFPage
+	}{
+		{"pdf_page", PDFPage{}},
+		{"pdf_page_scanned", PDFPage{Scanned: true}},
+		{"pdf_page_hints", PDFPage{Hints: Hints{
+			TitleHint:   "The Structure of Scientific Revolutions",
+			PageContext: "...the paradigm had been articulated in",
+			Focus:       "Transcribe Greek passages in the original script.",
+		}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderPDFPage(tt.params)
+			if err != nil {
+				t.Fatalf("RenderPDFPage() error = %v", err)
+			}
+			checkGolden(t, tt.name, got)
+		})
+	}
+}
+
+func TestRenderTextDocument(t *testing.T) {
+	tests := []struct {
+		name   string
+		params TextDocument
+	}{
+		{"text_document", TextDocument{}},
+		{"text_document_part", TextDocument{Part: 2, Parts: 3}},
+		{"text_document_chapter", TextDocument{Part: 1, Parts: 12, Chapters: true}},
+		{"text_document_hints", TextDocument{Hints: Hints{
+			TitleHint: "Notes on Method",
+			Focus:     "Keep the numbered theses as a numbered list.",
+		}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderTextDocument(tt.params)
+			if err != nil {
+				t.Fatalf("RenderTextDocument() error = %v", err)
+			}
+			checkGolden(t, tt.name, got)
+		})
+	}
+}
+
+func TestRenderReferences(t *testing.T) {
+	tests := []struct {
+		name   string
+		params References
+	}{
+		{"references_pdf_page", References{}},
+		{"references_text", References{Text: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderReferences(tt.params)
+			if err != nil {
+				t.Fatalf("RenderReferences() error = %v", err)
+			}
+			checkGolden(t, tt.name, got)
+		})
+	}
+}
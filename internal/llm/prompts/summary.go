@@ -0,0 +1,83 @@
+package prompts
+
+import (
+	"fmt"
+	"text/template"
+)
+
+// Summary are the parameters of the summarization prompt. The document content
+// follows the rendered prompt.
+type Summary struct {
+	// Style is the kind of summary: "brief", "standard", "structured", or
+	// "accessible" (see models.SummaryStyles)
+	Style string
+}
+
+// summaryTemplates holds one template per summary style, named after it
+var summaryTemplates = template.Must(template.New("standard").Parse(`Summarize this academic text into 1-3 paragraphs. It should be coherent, concise, accurately reflect the original content, and use a detached academic tone. This should be in expository prose, not point form. No lists, just coherent sentences and paragraphs.`))
+
+func init() {
+	template.Must(summaryTemplates.New("brief").Parse(`Summarize this academic text in a single sentence of at most 40 words (a TL;DR) that states its central argument or finding. It should accurately reflect the original content and use a detached academic tone. Reply with the sentence only.`))
+
+	template.Must(summaryTemplates.New("structured").Parse(`Write a structured summary of this academic text with exactly these four sections, in this order, each a markdown heading followed by a short paragraph:
+
+## Aims
+The question, problem, or purpose the text addresses.
+
+## Methods
+How the work was done: the approach, data, sources, or methods used.
+
+## Findings
+The main results, arguments, or conclusions.
+
+## Limitations
+Limitations the text acknowledges or that are evident from it.
+
+Accurately reflect the original content and use a detached academic tone. If the text does not address a section (for example, a theoretical essay has no data), say so in one sentence rather than inventing content.`))
+
+	template.Must(summaryTemplates.New("accessible").Parse(`Explain this academic text in 2-3 paragraphs for an undergraduate student who is new to the field. Say what question it asks, how it answers it, and why the answer matters. Define specialized terms in plain words when they first appear and prefer everyday words to jargon, but do not oversimplify: the explanation must accurately reflect the original content. Write in expository prose, not point form.`))
+}
+
+// RenderSummary renders the summarization prompt for a style; the document
+// content is appended to it
+func RenderSummary(params Summary) (string, error) {
+	tmpl := summaryTemplates.Lookup(params.Style)
+	if tmpl == nil {
+		return "", fmt.Errorf("unknown summary style %q", params.Style)
+	}
+	return render(tmpl, params)
+}
+
+// SectionSummary are the parameters of the prompt that summarizes one section
+// of a document summarized section by section. The section content follows the
+// rendered prompt.
+type SectionSummary struct {
+	Title string // Section heading
+	Brief bool   // Summarize in one sentence rather than a paragraph
+}
+
+var sectionSummaryTemplate = template.Must(template.New("section").Parse(`This is the section {{if .Title}}"{{.Title}}" {{end}}of a longer academic text, which is summarized section by section. Summarize this section {{if .Brief}}in a single sentence of at most 30 words{{else}}in one paragraph{{end}}, stating what it argues, does, or finds and how it contributes to the text. It should accurately reflect the original content and use a detached academic tone. Do not summarize other sections or speculate about them. Reply with the summary only.`))
+
+// RenderSectionSummary renders the prompt that summarizes a section; the
+// section content is appended to it
+func RenderSectionSummary(params SectionSummary) (string, error) {
+	return render(sectionSummaryTemplate, params)
+}
+
+// composedSummaryTemplate introduces the section summaries the overall summary
+// is composed from, after the instructions of its style
+var composedSummaryTemplate = template.Must(template.New("composed").Parse(`The text is given below as summaries of its sections in order, each under the section's title rather than in full. Base the summary on them alone, and refer to sections by their titles where it helps the reader find where the text makes a point (e.g., "In 'Methods', ...").`))
+
+// RenderComposedSummary renders the prompt that composes an overall summary in
+// a style from section summaries; the section summaries are appended to it
+func RenderComposedSummary(params Summary) (string, error) {
+	prompt, err := RenderSummary(params)
+	if err != nil {
+		return "", err
+	}
+	sections, err := render(composedSummaryTemplate, params)
+	if err != nil {
+		return "", err
+	}
+	return prompt + "\n\n" + sections, nil
+}
@@ -0,0 +1,51 @@
+package prompts
+
+import "testing"
+
+func TestRenderSummary(t *testing.T) {
+	for _, style := range []string{"brief", "standard", "structured", "accessible"} {
+		t.Run(style, func(t *testing.T) {
+			got, err := RenderSummary(Summary{Style: style})
+			if err != nil {
+				t.Fatalf("RenderSummary() error = %v", err)
+			}
+			checkGolden(t, "summary_"+style, got)
+		})
+	}
+
+	if _, err := RenderSummary(Summary{Style: "haiku"}); err == nil {
+		t.Error("Expected an error for an unknown style")
+	}
+}
+
+func TestRenderSectionSummary(t *testing.T) {
+	tests := []struct {
+		name   string
+		params SectionSummary
+	}{
+		{"section_summary", SectionSummary{Title: "Methods"}},
+		{"section_summary_brief", SectionSummary{Title: "Methods", Brief: true}},
+		{"section_summary_untitled", SectionSummary{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderSectionSummary(tt.params)
+			if err != nil {
+				t.Fatalf("RenderSectionSummary() error = %v", err)
+			}
+			checkGolden(t, tt.name, got)
+		})
+	}
+}
+
+func TestRenderComposedSummary(t *testing.T) {
+	got, err := RenderComposedSummary(Summary{Style: "standard"})
+	if err != nil {
+		t.Fatalf("RenderComposedSummary() error = %v", err)
+	}
+	checkGolden(t, "composed_summary_standard", got)
+
+	if _, err := RenderComposedSummary(Summary{Style: "haiku"}); err == nil {
+		t.Error("Expected an error for an unknown style")
+	}
+}
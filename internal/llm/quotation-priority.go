@@ -0,0 +1,273 @@
+package llm
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/responses"
+)
+
+// indexedQuotation is a quotation as shown to the model for prioritization,
+// identified by its index in the extracted quotations
+type indexedQuotation struct {
+	Index         int    `json:"index"`
+	QuotationText string `json:"quotation_text"`
+	PageNumber    string `json:"page_number,omitempty"`
+	Context       string `json:"context,omitempty"`
+	Relevance     string `json:"relevance,omitempty"`
+}
+
+// prioritizedQuotationsResult is the structured output of the quotation
+// prioritization request: the indices of the selected quotations
+type prioritizedQuotationsResult struct {
+	Selected []int `json:"selected"`
+}
+
+// prioritizedQuotationsSchema is the JSON schema for prioritizedQuotationsResult
+var prioritizedQuotationsSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"selected": map[string]any{
+			"type":  "array",
+			"items": map[string]any{"type": "integer"},
+		},
+	},
+	"required":             []string{"selected"},
+	"additionalProperties": false,
+}
+
+// prioritizeQuotations asks the LLM to select the most significant of the
+// quotations, up to opts.MaxQuotations, and returns the indices of its
+// selection, most significant first. The model only returns indices, so it
+// cannot drop or rewrite the quotations' text, page numbers, or translations.
+// A selection with invalid indices is an error.
+func prioritizeQuotations(ctx context.Context, client *openai.Client, quotations []models.Quotation, parsedItem *models.ParsedItem, summary string, opts QuotationOptions, log logger.Logger) ([]int, error) {
+	maxQuotations := opts.MaxQuotations
+	log.Info("Prioritizing %d quotations down to %d", len(quotations), maxQuotations)
+
+	indexed := make([]indexedQuotation, len(quotations))
+	for i, q := range quotations {
+		indexed[i] = indexedQuotation{Index: i, QuotationText: q.QuotationText, PageNumber: q.PageNumber, Context: q.Context, Relevance: q.Relevance}
+	}
+	quotationsJSON, err := json.MarshalIndent(indexed, "", "  ")
+	if err != nil {
+		log.Error("Failed to marshal quotations for prioritization: %v", err)
+		return nil, err
+	}
+
+	prompt := fmt.Sprintf(`You are reviewing quotations extracted from an academic document and need to select the %d most significant ones.
+
+Document Title: %s
+Document Summary:
+%s
+
+All Extracted Quotations:
+%s
+
+Your task is to select the %d MOST significant quotations from the list above. Prioritize quotations that:
+1. Present key arguments or theoretical contributions
+2. Contain important findings or conclusions
+3. Are memorable or particularly well-articulated
+4. Represent different sections of the document (diversity)
+5. Are self-contained and meaningful%s
+
+Return ONLY the "index" values of the selected quotations, most significant first. Each index must be one from the list above, and no index may appear twice.
+
+Select exactly %d quotations (or fewer if there aren't enough high-quality ones).`,
+		maxQuotations, parsedItem.Metadata.Title, summary, string(quotationsJSON), maxQuotations, focusPriority(opts.Focus), maxQuotations)
+
+	log.Debug("Calling OpenAI API for quotation prioritization")
+	result, err := callWithTimeout(ctx, summaryTimeout(log), func(ctx context.Context) (prioritizedQuotationsResult, error) {
+		return newStructuredResponse[prioritizedQuotationsResult](ctx, client, responses.ResponseNewParams{
+			Model: quotationModel,
+			Input: responses.ResponseNewParamsInputUnion{
+				OfInputItemList: responses.ResponseInputParam{
+					responses.ResponseInputItemParamOfMessage(
+						responses.ResponseInputMessageContentListParam{
+							responses.ResponseInputContentParamOfInputText(prompt),
+						},
+						"user",
+					),
+				},
+			},
+			Text: responses.ResponseTextConfigParam{
+				Format: responses.ResponseFormatTextConfigParamOfJSONSchema("prioritized_quotations", prioritizedQuotationsSchema),
+			},
+		}, "quotation prioritization", log)
+	})
+
+	if err != nil {
+		log.Error("Failed to prioritize quotations: %v", err)
+		return nil, err
+	}
+
+	if err := validateSelection(result.Selected, len(quotations), maxQuotations); err != nil {
+		return nil, fmt.Errorf("invalid quotation selection: %w", err)
+	}
+
+	log.Info("Successfully prioritized to %d quotations", len(result.Selected))
+	return result.Selected, nil
+}
+
+// validateSelection checks the indices the model selected from count
+// quotations. The selection is rejected if it is empty, longer than
+// maxQuotations, or has an index out of range or more than once.
+func validateSelection(indices []int, count int, maxQuotations int) error {
+	if len(indices) == 0 {
+		return errors.New("no quotations selected")
+	}
+	if maxQuotations > 0 && len(indices) > maxQuotations {
+		return fmt.Errorf("%d quotations selected, more than the %d requested", len(indices), maxQuotations)
+	}
+	chosen := make([]bool, count)
+	for _, i := range indices {
+		if i < 0 || i >= count {
+			return fmt.Errorf("quotation index %d out of range (%d quotations)", i, count)
+		}
+		if chosen[i] {
+			return fmt.Errorf("quotation index %d selected more than once", i)
+		}
+		chosen[i] = true
+	}
+	return nil
+}
+
+// quotationPrioritizer returns the indices of the most significant of the
+// quotations, most significant first
+type quotationPrioritizer func(ctx context.Context, quotations []models.Quotation) ([]int, error)
+
+// rankQuotations marks which quotations are selected when there are more than
+// maxQuotations, keeping them all in their original order. The quotations
+// prioritize picks are selected and ranked first, in its order; the others
+// follow in heuristicOrder, unselected, so a later request for more quotations
+// can promote them without extracting again (see PromoteQuotations). If
+// prioritize fails, the first maxQuotations in heuristicOrder are selected.
+// Quotations are all selected, and not ranked, when there are no more than
+// maxQuotations or it is 0 (unlimited).
+func rankQuotations(ctx context.Context, quotations []models.Quotation, maxQuotations int, prioritize quotationPrioritizer, log logger.Logger) []models.Quotation {
+	if maxQuotations <= 0 || len(quotations) <= maxQuotations {
+		for i := range quotations {
+			quotations[i].Selected = true
+			quotations[i].Rank = 0
+		}
+		return quotations
+	}
+
+	log.Info("Found %d quotations, prioritizing to top %d", len(quotations), maxQuotations)
+	order, err := prioritize(ctx, quotations)
+	if err != nil {
+		// Don't fail completely, the limit is still kept by the heuristic
+		log.Warn("Failed to prioritize quotations, selecting by page and length: %v", err)
+		order = nil
+	}
+	selectedCount := len(order)
+	if order == nil {
+		selectedCount = maxQuotations
+	}
+
+	ranked := make([]bool, len(quotations))
+	for _, i := range order {
+		ranked[i] = true
+	}
+	for _, i := range heuristicOrder(quotations) {
+		if !ranked[i] {
+			order = append(order, i)
+		}
+	}
+	for rank, i := range order {
+		quotations[i].Rank = rank + 1
+		quotations[i].Selected = rank < selectedCount
+	}
+	log.Info("Prioritization complete, selected %d of %d quotations", selectedCount, len(quotations))
+	return quotations
+}
+
+// heuristicOrder ranks quotations without the model, spreading them across
+// pages: pages take turns in the order they first appear, each giving its
+// longest quotation not yet ranked. It returns the indices of the quotations
+// in rank order.
+func heuristicOrder(quotations []models.Quotation) []int {
+	// Indices of each page's quotations, longest first
+	var pages []string
+	byPage := make(map[string][]int)
+	for i, q := range quotations {
+		page := strings.TrimSpace(q.PageNumber)
+		if _, ok := byPage[page]; !ok {
+			pages = append(pages, page)
+		}
+		byPage[page] = append(byPage[page], i)
+	}
+	for _, page := range pages {
+		slices.SortStableFunc(byPage[page], func(a, b int) int {
+			return cmp.Compare(len(quotations[b].QuotationText), len(quotations[a].QuotationText))
+		})
+	}
+
+	order := make([]int, 0, len(quotations))
+	for round := 0; len(order) < len(quotations); round++ {
+		for _, page := range pages {
+			if round < len(byPage[page]) {
+				order = append(order, byPage[page][round])
+			}
+		}
+	}
+	return order
+}
+
+// SelectedQuotations returns the selected quotations, in their original order
+func SelectedQuotations(quotations []models.Quotation) []models.Quotation {
+	selected := make([]models.Quotation, 0, len(quotations))
+	for _, q := range quotations {
+		if q.Selected {
+			selected = append(selected, q)
+		}
+	}
+	return selected
+}
+
+// PromoteQuotations selects unselected quotations, best ranked first, until
+// maxQuotations are selected (all of them if maxQuotations is 0), and returns
+// the indices of those it selected. A stored set of quotations can so answer a
+// request for more quotations than were first selected without extracting
+// again.
+func PromoteQuotations(quotations []models.Quotation, maxQuotations int) []int {
+	var candidates []int
+	selected := 0
+	for i, q := range quotations {
+		if q.Selected {
+			selected++
+		} else {
+			candidates = append(candidates, i)
+		}
+	}
+	// Unranked quotations come after ranked ones, in their original order
+	slices.SortStableFunc(candidates, func(a, b int) int {
+		ra, rb := quotations[a].Rank, quotations[b].Rank
+		if (ra == 0) != (rb == 0) {
+			if ra == 0 {
+				return 1
+			}
+			return -1
+		}
+		return cmp.Compare(ra, rb)
+	})
+
+	var promoted []int
+	for _, i := range candidates {
+		if maxQuotations > 0 && selected >= maxQuotations {
+			break
+		}
+		quotations[i].Selected = true
+		selected++
+		promoted = append(promoted, i)
+	}
+	return promoted
+}
@@ -0,0 +1,212 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
+)
+
+// priorityQuotations returns quotations over three pages, with a translation
+// the model is never shown
+func priorityQuotations() []models.Quotation {
+	return []models.Quotation{
+		{QuotationText: "Short one.", PageNumber: "1", Translation: "Kurz.", Verified: true, MatchScore: 1},
+		{QuotationText: "A much longer quotation from the first page.", PageNumber: "1", Verified: true, MatchScore: 1},
+		{QuotationText: "The only quotation on page two.", PageNumber: "2", Verified: true, MatchScore: 0.9},
+		{QuotationText: "Page three, first.", PageNumber: "3"},
+		{QuotationText: "Page three, a longer second quotation.", PageNumber: "3"},
+	}
+}
+
+func quotationTexts(quotations []models.Quotation) []string {
+	texts := make([]string, len(quotations))
+	for i, q := range quotations {
+		texts[i] = q.QuotationText
+	}
+	return texts
+}
+
+func TestPrioritizeQuotations(t *testing.T) {
+	quotations := priorityQuotations()
+	item := &models.ParsedItem{Metadata: models.ItemMetadata{Title: "Archives"}}
+	opts := QuotationOptions{MaxQuotations: 2}
+
+	t.Run("selected by index", func(t *testing.T) {
+		requests := fakeResponses(t, `{"selected":[2,0]}`)
+		client := openai.NewClient(option.WithAPIKey("test-key"))
+
+		selected, err := prioritizeQuotations(context.Background(), &client, quotations, item, "A summary", opts, logger.NewNoOpLogger())
+		if err != nil {
+			t.Fatalf("prioritizeQuotations failed: %v", err)
+		}
+		if !slices.Equal(selected, []int{2, 0}) {
+			t.Errorf("Expected the indices 2 and 0 in the model's order, got %v", selected)
+		}
+		if len(*requests) != 1 || !strings.Contains((*requests)[0], `\"index\": 4`) || strings.Contains((*requests)[0], "Kurz.") {
+			t.Errorf("Expected one request listing indexed quotations without translations, got %q", *requests)
+		}
+	})
+
+	for _, tt := range []struct {
+		name   string
+		output string
+	}{
+		{"out of range", `{"selected":[1,7]}`},
+		{"negative", `{"selected":[-1]}`},
+		{"duplicate", `{"selected":[3,3]}`},
+		{"too many", `{"selected":[0,1,2]}`},
+		{"empty", `{"selected":[]}`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeResponses(t, tt.output)
+			client := openai.NewClient(option.WithAPIKey("test-key"))
+
+			if _, err := prioritizeQuotations(context.Background(), &client, quotations, item, "A summary", opts, logger.NewNoOpLogger()); err == nil {
+				t.Error("Expected the selection to be rejected")
+			}
+		})
+	}
+}
+
+func TestHeuristicOrder(t *testing.T) {
+	// Pages take turns, each giving its longest quotation first
+	if got := heuristicOrder(priorityQuotations()); !slices.Equal(got, []int{1, 2, 4, 0, 3}) {
+		t.Errorf("heuristicOrder() = %v, want [1 2 4 0 3]", got)
+	}
+
+	// Without page numbers the longest quotations come first
+	unpaged := []models.Quotation{{QuotationText: "Brief."}, {QuotationText: "The longest of the three."}, {QuotationText: "Medium length."}}
+	if got := heuristicOrder(unpaged); !slices.Equal(got, []int{1, 2, 0}) {
+		t.Errorf("Expected the longest quotations first, got %v", got)
+	}
+}
+
+// fakePrioritizer returns a fixed selection, or an error, and counts its calls
+type fakePrioritizer struct {
+	selected []int
+	err      error
+	calls    int
+}
+
+func (p *fakePrioritizer) prioritize(ctx context.Context, quotations []models.Quotation) ([]int, error) {
+	p.calls++
+	return p.selected, p.err
+}
+
+// selection returns whether each quotation is selected and its rank
+func selection(quotations []models.Quotation) ([]bool, []int) {
+	selected := make([]bool, len(quotations))
+	ranks := make([]int, len(quotations))
+	for i, q := range quotations {
+		selected[i], ranks[i] = q.Selected, q.Rank
+	}
+	return selected, ranks
+}
+
+func TestRankQuotations(t *testing.T) {
+	log := logger.NewNoOpLogger()
+
+	tests := []struct {
+		name         string
+		max          int
+		prioritizer  *fakePrioritizer
+		wantCalls    int
+		wantSelected []bool
+		wantRanks    []int
+	}{
+		{
+			name:         "model selection first, the rest by heuristic",
+			max:          2,
+			prioritizer:  &fakePrioritizer{selected: []int{3, 0}},
+			wantCalls:    1,
+			wantSelected: []bool{true, false, false, true, false},
+			wantRanks:    []int{2, 3, 4, 1, 5},
+		},
+		{
+			name:         "fewer selected than the max",
+			max:          3,
+			prioritizer:  &fakePrioritizer{selected: []int{2}},
+			wantCalls:    1,
+			wantSelected: []bool{false, false, true, false, false},
+			wantRanks:    []int{4, 2, 1, 5, 3},
+		},
+		{
+			name:         "heuristic when prioritization fails",
+			max:          2,
+			prioritizer:  &fakePrioritizer{err: errors.New("timeout")},
+			wantCalls:    1,
+			wantSelected: []bool{false, true, true, false, false},
+			wantRanks:    []int{4, 1, 2, 5, 3},
+		},
+		{
+			name:         "no more than the max",
+			max:          5,
+			prioritizer:  &fakePrioritizer{},
+			wantSelected: []bool{true, true, true, true, true},
+			wantRanks:    []int{0, 0, 0, 0, 0},
+		},
+		{
+			name:         "unlimited",
+			max:          0,
+			prioritizer:  &fakePrioritizer{},
+			wantSelected: []bool{true, true, true, true, true},
+			wantRanks:    []int{0, 0, 0, 0, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranked := rankQuotations(context.Background(), priorityQuotations(), tt.max, tt.prioritizer.prioritize, log)
+			if !slices.Equal(quotationTexts(ranked), quotationTexts(priorityQuotations())) {
+				t.Fatalf("Expected every quotation in its original order, got %q", quotationTexts(ranked))
+			}
+			selected, ranks := selection(ranked)
+			if tt.prioritizer.calls != tt.wantCalls || !slices.Equal(selected, tt.wantSelected) || !slices.Equal(ranks, tt.wantRanks) {
+				t.Errorf("Expected %d calls, selected %v, ranks %v; got %d, %v, %v",
+					tt.wantCalls, tt.wantSelected, tt.wantRanks, tt.prioritizer.calls, selected, ranks)
+			}
+		})
+	}
+}
+
+func TestPromoteQuotations(t *testing.T) {
+	log := logger.NewNoOpLogger()
+	prioritizer := &fakePrioritizer{selected: []int{3, 0}}
+	quotations := rankQuotations(context.Background(), priorityQuotations(), 2, prioritizer.prioritize, log)
+	if got := quotationTexts(SelectedQuotations(quotations)); !slices.Equal(got, []string{"Short one.", "Page three, first."}) {
+		t.Fatalf("Expected the prioritized quotations selected, got %q", got)
+	}
+
+	// A smaller or equal max promotes nothing
+	if promoted := PromoteQuotations(quotations, 2); promoted != nil {
+		t.Errorf("Expected nothing promoted for the same max, got %v", promoted)
+	}
+
+	// A larger max promotes the best ranked of the rest, without prioritizing again
+	if promoted := PromoteQuotations(quotations, 4); !slices.Equal(promoted, []int{1, 2}) {
+		t.Errorf("Expected quotations 1 and 2 (ranks 3 and 4) promoted, got %v", promoted)
+	}
+	if selected, _ := selection(quotations); !slices.Equal(selected, []bool{true, true, true, true, false}) {
+		t.Errorf("Expected four quotations selected, got %v", selected)
+	}
+
+	// Unlimited promotes the rest
+	if promoted := PromoteQuotations(quotations, 0); !slices.Equal(promoted, []int{4}) {
+		t.Errorf("Expected the last quotation promoted, got %v", promoted)
+	}
+	if prioritizer.calls != 1 {
+		t.Errorf("Expected the prioritizer to be called once, got %d", prioritizer.calls)
+	}
+
+	// Unranked quotations are promoted after ranked ones, in their order
+	mixed := []models.Quotation{{QuotationText: "a"}, {QuotationText: "b", Rank: 2}, {QuotationText: "c", Selected: true, Rank: 1}, {QuotationText: "d"}}
+	if promoted := PromoteQuotations(mixed, 3); !slices.Equal(promoted, []int{1, 0}) {
+		t.Errorf("Expected the ranked quotation promoted first, got %v", promoted)
+	}
+}
@@ -2,6 +2,7 @@ package llm
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"time"
@@ -38,13 +39,20 @@ var (
 	openAIRateLimiter = rate.NewLimiter(rate.Limit(tokensPerSecond), burstTokens)
 )
 
-// RateLimitedCall wraps an API call with rate limiting and retry logic.
-// It waits for rate limiter approval before making the call, and retries on 429 errors.
+// RateLimitedCall wraps a page-sized API call with rate limiting, a timeout, and
+// retry logic. It waits for rate limiter approval before making the call, gives
+// each attempt PageTimeout to finish, and retries on 429 errors and timeouts.
+// Cancelling ctx stops it at once, including between retries.
 func RateLimitedCall[T any](ctx context.Context, estimatedTokens int, log logger.Logger, fn func(context.Context) (T, error)) (T, error) {
 	var zero T
 
+	timeout, err := PageTimeout()
+	if err != nil {
+		log.Warn("Using default page timeout: %v", err)
+	}
+
 	// Wait for rate limiter approval
-	err := openAIRateLimiter.WaitN(ctx, estimatedTokens)
+	err = openAIRateLimiter.WaitN(ctx, estimatedTokens)
 	if err != nil {
 		return zero, fmt.Errorf("rate limiter wait failed: %w", err)
 	}
@@ -71,7 +79,7 @@ func RateLimitedCall[T any](ctx context.Context, estimatedTokens int, log logger
 		}
 
 		// Make the API call
-		result, err := fn(ctx)
+		result, err := callWithTimeout(ctx, timeout, fn)
 		if err == nil {
 			// Success!
 			if attempt > 0 {
@@ -82,6 +90,12 @@ func RateLimitedCall[T any](ctx context.Context, estimatedTokens int, log logger
 
 		lastErr = err
 
+		// A stuck request is retried like a rate limited one
+		if errors.Is(err, ErrCallTimeout) {
+			log.Warn("Request timed out on attempt %d/%d: %v", attempt+1, maxRetries+1, err)
+			continue
+		}
+
 		// Check if this is a rate limit error (429)
 		if !isRateLimitError(err) {
 			// Not a rate limit error, don't retry
@@ -131,6 +145,12 @@ func contains(s, substr string) bool {
 	return false
 }
 
+// MaxWorkers returns the number of OpenAI requests run in parallel for one
+// document
+func MaxWorkers() int {
+	return defaultMaxWorkers
+}
+
 // WorkerPool manages a pool of workers for parallel processing with rate limiting
 type WorkerPool struct {
 	maxWorkers int
@@ -175,6 +195,11 @@ func ParallelProcess[T any, R any](
 		return []R{}, nil
 	}
 
+	// The first failure cancels the items still queued or in flight, since the
+	// results are discarded anyway
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
 	wp := NewWorkerPool(defaultMaxWorkers)
 	results := make([]R, len(items))
 
@@ -186,12 +211,16 @@ func ParallelProcess[T any, R any](
 	resultChan := make(chan result, len(items))
 
 	// Process items in parallel with worker pool control
+	launched := 0
+	var acquireErr error
 	for i, item := range items {
 		// Acquire a worker slot
 		if err := wp.Acquire(ctx); err != nil {
 			// Context cancelled, stop spawning new workers
+			acquireErr = err
 			break
 		}
+		launched++
 
 		go func(idx int, itm T) {
 			defer wp.Release()
@@ -207,13 +236,16 @@ func ParallelProcess[T any, R any](
 
 			// Process the item
 			val, err := processFn(ctx, idx, itm)
+			if err != nil {
+				cancel(err)
+			}
 			resultChan <- result{index: idx, value: val, err: err}
 		}(i, item)
 	}
 
-	// Collect results
-	var firstError error
-	for range len(items) {
+	// Collect results from the workers that were started
+	firstError := acquireErr
+	for range launched {
 		res := <-resultChan
 		if res.err != nil && firstError == nil {
 			firstError = res.err
@@ -223,6 +255,10 @@ func ParallelProcess[T any, R any](
 	close(resultChan)
 
 	if firstError != nil {
+		// Report the failure that stopped processing, not the cancellation it caused
+		if cause := context.Cause(ctx); cause != nil {
+			return nil, cause
+		}
 		return nil, firstError
 	}
 
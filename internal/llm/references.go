@@ -0,0 +1,177 @@
+package llm
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// minReferenceOverlap is the fraction of the shorter reference's words that must
+// also appear in the other for the two to be treated as the same entry
+const minReferenceOverlap = 0.9
+
+// minReferenceWords is the length below which references are only merged when
+// their text is identical, since short fragments overlap by chance
+const minReferenceWords = 4
+
+// referenceStartPattern matches the way bibliography entries usually begin: a
+// numbered marker ("[12]", "12."), a surname followed by a comma or initials
+// ("Smith, J.", "Smith JA,"), or a leading initial ("J. Smith")
+var referenceStartPattern = regexp.MustCompile(`^(\[\d+\]|\d+[.)]\s|\p{Lu}[\p{L}'’\-]+,|\p{Lu}[\p{L}'’\-]+ \p{Lu}{1,3}[\s,.]|\p{Lu}\.\s)`)
+
+// consolidateReferences cleans up references aggregated from per-page parses.
+// Entries cut in half by a page break are joined, entries that appear on two
+// pages (or were transcribed twice) are merged, and the result is ordered by page
+// while keeping the order within each page.
+func consolidateReferences(refs []models.Reference) []models.Reference {
+	refs = joinReferenceContinuations(refs)
+	refs = mergeDuplicateReferences(refs)
+	slices.SortStableFunc(refs, func(a, b models.Reference) int {
+		return cmp.Compare(a.PageIndex, b.PageIndex)
+	})
+	return refs
+}
+
+// joinReferenceContinuations appends the first reference on a page to the last
+// reference on the previous page when the earlier entry stops mid-sentence and
+// the later one doesn't look like the start of a new entry
+func joinReferenceContinuations(refs []models.Reference) []models.Reference {
+	result := make([]models.Reference, 0, len(refs))
+	for i, ref := range refs {
+		firstOnPage := i > 0 && refs[i-1].PageIndex != ref.PageIndex
+		if firstOnPage && len(result) > 0 {
+			prev := &result[len(result)-1]
+			if prev.PageIndex == ref.PageIndex-1 && isIncompleteReference(prev.ReferenceText) && isReferenceContinuation(ref.ReferenceText) {
+				prev.ReferenceText = joinReferenceText(prev.ReferenceText, ref.ReferenceText)
+				if prev.DOI == "" {
+					prev.DOI = ref.DOI
+				}
+				continue
+			}
+		}
+		result = append(result, ref)
+	}
+	return result
+}
+
+// isIncompleteReference reports whether a reference appears to stop mid-sentence.
+// Entries ending in a URL or DOI are complete even without final punctuation.
+func isIncompleteReference(text string) bool {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return false
+	}
+	last, _ := utf8.DecodeLastRuneInString(text)
+	if strings.ContainsRune(".!?", last) {
+		return false
+	}
+	fields := strings.Fields(text)
+	lastField := strings.ToLower(fields[len(fields)-1])
+	return !strings.HasPrefix(lastField, "http") && !strings.HasPrefix(lastField, "doi:") && !strings.HasPrefix(lastField, "10.")
+}
+
+// isReferenceContinuation reports whether text reads as the rest of an entry
+// rather than a new one
+func isReferenceContinuation(text string) bool {
+	text = strings.TrimSpace(text)
+	first, _ := utf8.DecodeRuneInString(text)
+	if unicode.IsLower(first) {
+		return true
+	}
+	return text != "" && !referenceStartPattern.MatchString(text)
+}
+
+// joinReferenceText joins the two halves of a reference, rejoining a word
+// hyphenated across the page break
+func joinReferenceText(head, tail string) string {
+	head = strings.TrimSpace(head)
+	tail = strings.TrimSpace(tail)
+	first, _ := utf8.DecodeRuneInString(tail)
+	if strings.HasSuffix(head, "-") && unicode.IsLower(first) {
+		return strings.TrimSuffix(head, "-") + tail
+	}
+	return head + " " + tail
+}
+
+// mergeDuplicateReferences merges references that share a DOI or whose text
+// largely overlaps. The merged entry keeps the earlier position and page and the
+// longer (less truncated) text.
+func mergeDuplicateReferences(refs []models.Reference) []models.Reference {
+	result := make([]models.Reference, 0, len(refs))
+	words := make([][]string, 0, len(refs))
+	for _, ref := range refs {
+		refWords := referenceWords(ref.ReferenceText)
+		duplicate := -1
+		for j, kept := range result {
+			if isSameReference(kept, ref, words[j], refWords) {
+				duplicate = j
+				break
+			}
+		}
+		if duplicate < 0 {
+			result = append(result, ref)
+			words = append(words, refWords)
+			continue
+		}
+
+		kept := &result[duplicate]
+		if len(ref.ReferenceText) > len(kept.ReferenceText) {
+			kept.ReferenceText = ref.ReferenceText
+			words[duplicate] = refWords
+		}
+		if kept.DOI == "" {
+			kept.DOI = ref.DOI
+		}
+	}
+	return result
+}
+
+// isSameReference reports whether two references describe the same entry
+func isSameReference(a, b models.Reference, aWords, bWords []string) bool {
+	if a.DOI != "" && strings.EqualFold(a.DOI, b.DOI) {
+		return true
+	}
+	if len(aWords) == 0 || len(bWords) == 0 {
+		return false
+	}
+	if len(aWords) < minReferenceWords || len(bWords) < minReferenceWords {
+		return slices.Equal(aWords, bWords)
+	}
+	return wordOverlap(aWords, bWords) >= minReferenceOverlap
+}
+
+// referenceWords splits reference text into lowercase words, ignoring punctuation
+func referenceWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// wordOverlap returns the fraction of the shorter word list's distinct words that
+// also appear in the longer one
+func wordOverlap(a, b []string) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	longer := make(map[string]bool, len(b))
+	for _, word := range b {
+		longer[word] = true
+	}
+	shorter := make(map[string]bool, len(a))
+	shared := 0
+	for _, word := range a {
+		if shorter[word] {
+			continue
+		}
+		shorter[word] = true
+		if longer[word] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(shorter))
+}
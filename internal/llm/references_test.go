@@ -0,0 +1,161 @@
+package llm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestConsolidateReferences(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []models.Reference
+		want  []models.Reference
+	}{
+		{
+			name: "distinct references are unchanged",
+			input: []models.Reference{
+				{ReferenceText: "Smith, J. (2020). A study of things. Journal of Studies, 1(2), 3-4.", PageNumber: "10", PageIndex: 1},
+				{ReferenceText: "Jones, K. (2019). Another study entirely. Review of Reviews, 5, 6-7.", PageNumber: "11", PageIndex: 2},
+			},
+			want: []models.Reference{
+				{ReferenceText: "Smith, J. (2020). A study of things. Journal of Studies, 1(2), 3-4.", PageNumber: "10", PageIndex: 1},
+				{ReferenceText: "Jones, K. (2019). Another study entirely. Review of Reviews, 5, 6-7.", PageNumber: "11", PageIndex: 2},
+			},
+		},
+		{
+			name: "reference repeated on consecutive pages",
+			input: []models.Reference{
+				{ReferenceText: "Smith, J. (2020). A study of things. Journal of Studies, 1(2), 3-4.", PageNumber: "10", PageIndex: 1},
+				{ReferenceText: "Smith, J. (2020). A study of things. Journal of Studies, 1(2), 3-4", PageNumber: "11", PageIndex: 2, DOI: "10.1/abc"},
+				{ReferenceText: "Jones, K. (2019). Another study entirely. Review of Reviews, 5, 6-7.", PageNumber: "11", PageIndex: 2},
+			},
+			want: []models.Reference{
+				{ReferenceText: "Smith, J. (2020). A study of things. Journal of Studies, 1(2), 3-4.", PageNumber: "10", PageIndex: 1, DOI: "10.1/abc"},
+				{ReferenceText: "Jones, K. (2019). Another study entirely. Review of Reviews, 5, 6-7.", PageNumber: "11", PageIndex: 2},
+			},
+		},
+		{
+			name: "truncated duplicate keeps the longer text",
+			input: []models.Reference{
+				{ReferenceText: "Smith, J. (2020). A study of things. Journal of Studies", PageNumber: "10", PageIndex: 1},
+				{ReferenceText: "Brown, A. (2018). Unrelated work on other topics. Press.", PageNumber: "10", PageIndex: 1},
+				{ReferenceText: "Smith, J. (2020). A study of things. Journal of Studies, 1(2), 3-4.", PageNumber: "11", PageIndex: 2},
+			},
+			want: []models.Reference{
+				{ReferenceText: "Smith, J. (2020). A study of things. Journal of Studies, 1(2), 3-4.", PageNumber: "10", PageIndex: 1},
+				{ReferenceText: "Brown, A. (2018). Unrelated work on other topics. Press.", PageNumber: "10", PageIndex: 1},
+			},
+		},
+		{
+			name: "shared DOI merges differently transcribed entries",
+			input: []models.Reference{
+				{ReferenceText: "Smith J. A study of things. J Stud. 2020.", DOI: "10.1/ABC", PageIndex: 1},
+				{ReferenceText: "Smith, John. 'A Study of Things.' Journal of Studies 1, no. 2 (2020): 3-4.", DOI: "10.1/abc", PageIndex: 4},
+			},
+			want: []models.Reference{
+				{ReferenceText: "Smith, John. 'A Study of Things.' Journal of Studies 1, no. 2 (2020): 3-4.", DOI: "10.1/ABC", PageIndex: 1},
+			},
+		},
+		{
+			name: "reference split across a page break is joined",
+			input: []models.Reference{
+				{ReferenceText: "Adams, B. (2017). Early work. Annals, 2, 1-9.", PageNumber: "10", PageIndex: 1},
+				{ReferenceText: "Smith, J. (2020). A study of things that continues onto", PageNumber: "10", PageIndex: 1},
+				{ReferenceText: "the next page. Journal of Studies, 1(2), 3-4.", PageNumber: "11", PageIndex: 2},
+				{ReferenceText: "Taylor, C. (2021). Later work. Letters, 3, 10-12.", PageNumber: "11", PageIndex: 2},
+			},
+			want: []models.Reference{
+				{ReferenceText: "Adams, B. (2017). Early work. Annals, 2, 1-9.", PageNumber: "10", PageIndex: 1},
+				{ReferenceText: "Smith, J. (2020). A study of things that continues onto the next page. Journal of Studies, 1(2), 3-4.", PageNumber: "10", PageIndex: 1},
+				{ReferenceText: "Taylor, C. (2021). Later work. Letters, 3, 10-12.", PageNumber: "11", PageIndex: 2},
+			},
+		},
+		{
+			name: "hyphenated word across a page break is rejoined",
+			input: []models.Reference{
+				{ReferenceText: "Smith, J. (2020). A study of interdis-", PageIndex: 1},
+				{ReferenceText: "ciplinary things. Journal of Studies, 1, 3-4.", PageIndex: 2},
+			},
+			want: []models.Reference{
+				{ReferenceText: "Smith, J. (2020). A study of interdisciplinary things. Journal of Studies, 1, 3-4.", PageIndex: 1},
+			},
+		},
+		{
+			name: "capitalized continuation without an author pattern is joined",
+			input: []models.Reference{
+				{ReferenceText: "[4] J. Smith, \"A study of things,\"", PageIndex: 1},
+				{ReferenceText: "Journal of Studies, vol. 1, pp. 3-4, 2020.", PageIndex: 2},
+			},
+			want: []models.Reference{
+				{ReferenceText: "[4] J. Smith, \"A study of things,\" Journal of Studies, vol. 1, pp. 3-4, 2020.", PageIndex: 1},
+			},
+		},
+		{
+			name: "complete entries at a page break are not joined",
+			input: []models.Reference{
+				{ReferenceText: "Smith, J. (2020). A study of things. https://example.org/study", PageIndex: 1},
+				{ReferenceText: "Taylor, C. (2021). Later work. Letters, 3, 10-12.", PageIndex: 2},
+				{ReferenceText: "[5] Unfinished entry without punctuation", PageIndex: 2},
+				{ReferenceText: "[6] Next numbered entry.", PageIndex: 3},
+			},
+			want: []models.Reference{
+				{ReferenceText: "Smith, J. (2020). A study of things. https://example.org/study", PageIndex: 1},
+				{ReferenceText: "Taylor, C. (2021). Later work. Letters, 3, 10-12.", PageIndex: 2},
+				{ReferenceText: "[5] Unfinished entry without punctuation", PageIndex: 2},
+				{ReferenceText: "[6] Next numbered entry.", PageIndex: 3},
+			},
+		},
+		{
+			name: "continuations only join adjacent pages",
+			input: []models.Reference{
+				{ReferenceText: "Smith, J. (2020). A study of things that continues onto", PageIndex: 1},
+				{ReferenceText: "the next page. Journal of Studies, 1(2), 3-4.", PageIndex: 3},
+			},
+			want: []models.Reference{
+				{ReferenceText: "Smith, J. (2020). A study of things that continues onto", PageIndex: 1},
+				{ReferenceText: "the next page. Journal of Studies, 1(2), 3-4.", PageIndex: 3},
+			},
+		},
+		{
+			name: "short fragments only merge when identical",
+			input: []models.Reference{
+				{ReferenceText: "Ibid.", PageIndex: 1},
+				{ReferenceText: "Ibid., 45.", PageIndex: 1},
+				{ReferenceText: "Ibid.", PageIndex: 2},
+			},
+			want: []models.Reference{
+				{ReferenceText: "Ibid.", PageIndex: 1},
+				{ReferenceText: "Ibid., 45.", PageIndex: 1},
+			},
+		},
+		{
+			name: "stably ordered by page",
+			input: []models.Reference{
+				{ReferenceText: "Zeta, Z. (2001). Listed first on page two.", PageIndex: 2},
+				{ReferenceText: "Alpha, A. (2002). Listed first on page one.", PageIndex: 1},
+				{ReferenceText: "Beta, B. (2003). Listed second on page one.", PageIndex: 1},
+			},
+			want: []models.Reference{
+				{ReferenceText: "Alpha, A. (2002). Listed first on page one.", PageIndex: 1},
+				{ReferenceText: "Beta, B. (2003). Listed second on page one.", PageIndex: 1},
+				{ReferenceText: "Zeta, Z. (2001). Listed first on page two.", PageIndex: 2},
+			},
+		},
+		{
+			name:  "empty input",
+			input: []models.Reference{},
+			want:  []models.Reference{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := consolidateReferences(tt.input)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("consolidateReferences() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/llm/prompts"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// SectionContent is a section of a document to summarize on its own
+type SectionContent struct {
+	Title   string
+	Content string
+}
+
+// SummarizeSections summarizes each section of a document separately, in
+// parallel on the worker pool, and returns the summaries in section order. A
+// brief summary gets one sentence per section; the other styles get a
+// paragraph. The first failure fails the whole document.
+func SummarizeSections(ctx context.Context, apiKey string, sections []SectionContent, opts SummaryOptions, log logger.Logger) ([]string, error) {
+	log.Info("Summarizing %d sections (style: %q, target language: %q)", len(sections), opts.Style, opts.TargetLanguage)
+	return ParallelProcess(ctx, sections, log, func(ctx context.Context, i int, section SectionContent) (string, error) {
+		prompt, err := prompts.RenderSectionSummary(prompts.SectionSummary{Title: section.Title, Brief: opts.Style == models.SummaryStyleBrief})
+		if err != nil {
+			return "", fmt.Errorf("failed to render section summary prompt: %w", err)
+		}
+		prompt += targetLanguageInstruction(opts.TargetLanguage, false) + "\n\n" + section.Content
+		log.Debug("Summarizing section %d %q (content length: %d chars)", i+1, section.Title, len(section.Content))
+		summary, err := generateSummary(ctx, apiKey, prompt, log)
+		if err != nil {
+			return "", fmt.Errorf("failed to summarize section %d (%s): %w", i+1, section.Title, err)
+		}
+		return strings.TrimSpace(summary), nil
+	})
+}
+
+// ComposeSummary writes a document's summary in a style from the summaries of
+// its sections, each given under the section's title and pages so that the
+// summary can refer to them
+func ComposeSummary(ctx context.Context, apiKey string, sections []models.SectionSummary, opts SummaryOptions, log logger.Logger) (string, error) {
+	style := opts.Style
+	if style == "" {
+		style = models.SummaryStyleStandard
+	}
+	prompt, err := prompts.RenderComposedSummary(prompts.Summary{Style: style})
+	if err != nil {
+		return "", fmt.Errorf("failed to render summary prompt: %w", err)
+	}
+
+	var content strings.Builder
+	for i, section := range sections {
+		title := section.Title
+		if title == "" {
+			title = fmt.Sprintf("Section %d", i+1)
+		}
+		fmt.Fprintf(&content, "\n\n## %s%s\n\n%s", title, pageSpanLabel(section.StartPage, section.EndPage), section.Summary)
+	}
+
+	log.Info("Composing %s summary from %d section summaries", style, len(sections))
+	summary, err := generateSummary(ctx, apiKey, prompt+targetLanguageInstruction(opts.TargetLanguage, false)+content.String(), log)
+	if err != nil {
+		log.Error("Failed to compose summary: %v", err)
+		return "", err
+	}
+	return summary, nil
+}
+
+// pageSpanLabel describes the source pages a section spans, or "" if they are
+// not known
+func pageSpanLabel(start, end string) string {
+	switch {
+	case start == "":
+		return ""
+	case end == "" || end == start:
+		return fmt.Sprintf(" (p. %s)", start)
+	default:
+		return fmt.Sprintf(" (pp. %s-%s)", start, end)
+	}
+}
@@ -0,0 +1,86 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+)
+
+const (
+	// pageTimeoutEnv names the environment variable that limits page-sized requests
+	pageTimeoutEnv = "ACADEMIC_MCP_PAGE_TIMEOUT"
+	// summaryTimeoutEnv names the environment variable that limits whole-document requests
+	summaryTimeoutEnv = "ACADEMIC_MCP_SUMMARY_TIMEOUT"
+
+	defaultPageTimeout    = 120 * time.Second
+	defaultSummaryTimeout = 300 * time.Second
+)
+
+// ErrCallTimeout is wrapped by the error of an OpenAI request that ran out of
+// time. It is distinct from context.DeadlineExceeded so that a stuck request is
+// not mistaken for the caller's own deadline.
+var ErrCallTimeout = errors.New("OpenAI request timed out")
+
+// PageTimeout returns how long a single page-sized request (a PDF page, a text
+// chunk or EPUB chapter, or one page of quotation extraction) may take before
+// it is abandoned and retried, set by ACADEMIC_MCP_PAGE_TIMEOUT as a Go duration.
+// Zero disables the timeout. An invalid value returns the default and an error.
+func PageTimeout() (time.Duration, error) {
+	return configuredTimeout(pageTimeoutEnv, defaultPageTimeout)
+}
+
+// SummaryTimeout returns how long a request over a whole document (a summary,
+// full-text quotation extraction, or quotation prioritization) may take, set by
+// ACADEMIC_MCP_SUMMARY_TIMEOUT as a Go duration. Zero disables the timeout. An
+// invalid value returns the default and an error.
+func SummaryTimeout() (time.Duration, error) {
+	return configuredTimeout(summaryTimeoutEnv, defaultSummaryTimeout)
+}
+
+// summaryTimeout returns SummaryTimeout, logging an invalid setting
+func summaryTimeout(log logger.Logger) time.Duration {
+	timeout, err := SummaryTimeout()
+	if err != nil {
+		log.Warn("Using default summary timeout: %v", err)
+	}
+	return timeout
+}
+
+func configuredTimeout(env string, defaultTimeout time.Duration) (time.Duration, error) {
+	value := strings.TrimSpace(os.Getenv(env))
+	if value == "" {
+		return defaultTimeout, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		return defaultTimeout, fmt.Errorf("invalid %s %q (expected a duration such as 90s, or 0 to disable)", env, value)
+	}
+	return timeout, nil
+}
+
+// callWithTimeout runs fn with a context that expires after timeout, or with ctx
+// itself if timeout is zero. If the timeout expires first, the error wraps
+// ErrCallTimeout; if ctx is done, it is ctx's error.
+func callWithTimeout[T any](ctx context.Context, timeout time.Duration, fn func(context.Context) (T, error)) (T, error) {
+	if timeout <= 0 {
+		return fn(ctx)
+	}
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result, err := fn(callCtx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		if callCtx.Err() != nil {
+			return result, fmt.Errorf("%w after %v", ErrCallTimeout, timeout)
+		}
+	}
+	return result, err
+}
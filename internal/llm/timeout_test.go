@@ -0,0 +1,176 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+)
+
+// slowCall blocks until its context is done, like a stuck OpenAI request
+func slowCall(ctx context.Context) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestConfiguredTimeouts(t *testing.T) {
+	tests := []struct {
+		value     string
+		expected  time.Duration
+		expectErr bool
+	}{
+		{"", defaultPageTimeout, false},
+		{"90s", 90 * time.Second, false},
+		{" 2m ", 2 * time.Minute, false},
+		{"0", 0, false},
+		{"-5s", defaultPageTimeout, true},
+		{"soon", defaultPageTimeout, true},
+	}
+	for _, tt := range tests {
+		t.Setenv(pageTimeoutEnv, tt.value)
+		timeout, err := PageTimeout()
+		if timeout != tt.expected || (err != nil) != tt.expectErr {
+			t.Errorf("%s=%q: expected %v (error %v), got %v, %v", pageTimeoutEnv, tt.value, tt.expected, tt.expectErr, timeout, err)
+		}
+	}
+
+	t.Setenv(summaryTimeoutEnv, "")
+	if timeout, err := SummaryTimeout(); timeout != defaultSummaryTimeout || err != nil {
+		t.Errorf("Expected default summary timeout %v, got %v, %v", defaultSummaryTimeout, timeout, err)
+	}
+}
+
+func TestCallWithTimeout(t *testing.T) {
+	t.Run("Timed out", func(t *testing.T) {
+		_, err := callWithTimeout(context.Background(), 20*time.Millisecond, slowCall)
+		if !errors.Is(err, ErrCallTimeout) || errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected ErrCallTimeout distinct from the caller's deadline, got %v", err)
+		}
+	})
+
+	t.Run("Caller cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(20*time.Millisecond, cancel)
+		_, err := callWithTimeout(ctx, time.Minute, slowCall)
+		if !errors.Is(err, context.Canceled) || errors.Is(err, ErrCallTimeout) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	})
+
+	t.Run("No timeout", func(t *testing.T) {
+		result, err := callWithTimeout(context.Background(), 0, func(ctx context.Context) (string, error) {
+			if _, ok := ctx.Deadline(); ok {
+				t.Error("Expected no deadline when the timeout is disabled")
+			}
+			return "done", nil
+		})
+		if result != "done" || err != nil {
+			t.Errorf("Expected done, got %q, %v", result, err)
+		}
+	})
+}
+
+func TestRateLimitedCall_TimeoutRetries(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping retry test in short mode")
+	}
+	t.Setenv(pageTimeoutEnv, "20ms")
+
+	// The first attempt is stuck; the retry succeeds
+	var calls atomic.Int32
+	result, err := RateLimitedCall(context.Background(), 100, logger.NewNoOpLogger(), func(ctx context.Context) (string, error) {
+		if calls.Add(1) == 1 {
+			return slowCall(ctx)
+		}
+		return "parsed", nil
+	})
+	if err != nil || result != "parsed" {
+		t.Fatalf("Expected the retry to succeed, got %q, %v", result, err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("Expected 2 attempts, got %d", calls.Load())
+	}
+}
+
+func TestRateLimitedCall_CancelledDuringCall(t *testing.T) {
+	t.Setenv(pageTimeoutEnv, "1m")
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	start := time.Now()
+	var calls atomic.Int32
+	_, err := RateLimitedCall(ctx, 100, logger.NewNoOpLogger(), func(ctx context.Context) (string, error) {
+		calls.Add(1)
+		return slowCall(ctx)
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if calls.Load() != 1 || time.Since(start) > time.Second {
+		t.Errorf("Expected one attempt abandoned promptly, got %d attempts in %v", calls.Load(), time.Since(start))
+	}
+}
+
+func TestParallelProcess_FailureStopsRemainingItems(t *testing.T) {
+	items := make([]int, 50)
+	testErr := errors.New("page 0 failed")
+
+	start := time.Now()
+	var started atomic.Int32
+	_, err := ParallelProcess(context.Background(), items, logger.NewNoOpLogger(), func(ctx context.Context, idx int, item int) (int, error) {
+		started.Add(1)
+		if idx == 0 {
+			return 0, testErr
+		}
+		// Other items run until they are cancelled, or far longer than the test allows
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(10 * time.Second):
+			return item, nil
+		}
+	})
+
+	if !errors.Is(err, testErr) {
+		t.Errorf("Expected the failing item's error rather than the cancellation it caused, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected in-flight items to stop promptly, took %v", elapsed)
+	}
+	if started.Load() == int32(len(items)) {
+		t.Errorf("Expected queued items not to start after the failure, all %d started", len(items))
+	}
+}
+
+func TestParallelProcess_CancellationStopsQueuedItems(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	items := make([]int, 50)
+
+	var started atomic.Int32
+	done := make(chan error, 1)
+	go func() {
+		_, err := ParallelProcess(ctx, items, logger.NewNoOpLogger(), func(ctx context.Context, idx int, item int) (int, error) {
+			started.Add(1)
+			<-ctx.Done()
+			return 0, ctx.Err()
+		})
+		done <- err
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("ParallelProcess kept running after its context was cancelled")
+	}
+	if started.Load() > defaultMaxWorkers {
+		t.Errorf("Expected at most %d items started (one per worker), got %d", defaultMaxWorkers, started.Load())
+	}
+}
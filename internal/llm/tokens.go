@@ -0,0 +1,28 @@
+package llm
+
+import (
+	"sync"
+
+	"github.com/pkoukk/tiktoken-go"
+	tiktoken_loader "github.com/pkoukk/tiktoken-go-loader"
+)
+
+// tokenEncoding is the tiktoken encoding of the models used (o200k_base for the
+// GPT-4o and GPT-5 families). Its BPE ranks are embedded in the binary by the
+// offline loader rather than downloaded, and loaded on first use.
+var tokenEncoding = sync.OnceValues(func() (*tiktoken.Tiktoken, error) {
+	tiktoken.SetBpeLoader(tiktoken_loader.NewOfflineLoader())
+	return tiktoken.GetEncoding(tiktoken.MODEL_O200K_BASE)
+})
+
+// countTokens returns the number of tokens the model's tokenizer produces for
+// text. Special tokens such as "<|endoftext|>" are counted as ordinary text.
+// If the encoding cannot be loaded, which only a broken build causes, it falls
+// back to four bytes per token.
+func countTokens(text string) int {
+	encoding, err := tokenEncoding()
+	if err != nil {
+		return (len(text) + 3) / 4
+	}
+	return len(encoding.EncodeOrdinary(text))
+}
@@ -0,0 +1,32 @@
+package llm
+
+import "testing"
+
+func TestCountTokens(t *testing.T) {
+	// Counts of the o200k_base encoding, as tiktoken gives them
+	tests := []struct {
+		name string
+		text string
+		want int
+	}{
+		{"empty", "", 0},
+		{"single word", "hello", 1},
+		{"english sentence", "The quick brown fox jumps over the lazy dog.", 10},
+		{"number", "1234567", 3},
+		{"markdown heading", "## Introduction\n\n", 3},
+		{"german", "Die Ergebnisse zeigen, dass dieser Ansatz wirksam ist.", 11},
+		{"french", "Les résultats montrent que cette approche est efficace.", 9},
+		{"chinese", "机器学习是人工智能的一个分支", 9},
+		{"russian", "Машинное обучение", 5},
+		{"russian sentence", "Результаты показывают, что этот подход эффективен.", 12},
+		{"special token as text", "<|endoftext|>", 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := countTokens(tt.text); got != tt.want {
+				t.Errorf("countTokens(%q) = %d, want %d", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package llm
+
+import (
+	"context"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/responses"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/llm/prompts"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+const (
+	// parseMaxOutputTokens is the output budget of a page or text chunk parse,
+	// including the model's reasoning
+	parseMaxOutputTokens = 32000
+	// referencesMaxOutputTokens is the output budget of the references-only
+	// pass, which re-extracts the references of a parse that ran out
+	referencesMaxOutputTokens = 64000
+	// minRecoveredReferences is the number of references at which a truncated
+	// parse is taken to have been cut off in its reference list
+	minRecoveredReferences = 10
+)
+
+// referencesSchema is the JSON schema of the references-only pass, the
+// references array of parsedDocumentSchema alone
+var referencesSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"references": parsedDocumentSchema["properties"].(map[string]any)["references"],
+	},
+	"required":             []string{"references"},
+	"additionalProperties": false,
+}
+
+// referencesResult is the output of the references-only pass
+type referencesResult struct {
+	References []models.Reference `json:"references"`
+}
+
+// outputTruncated reports whether a response was cut short: the API says it
+// stopped at its output token limit, or its output starts a JSON value that
+// never closes. status and reason are the response's status and
+// incomplete_details.reason.
+func outputTruncated(status, reason, output string) bool {
+	if status == string(responses.ResponseStatusIncomplete) && reason == "max_output_tokens" {
+		return true
+	}
+	return isTruncatedJSON(output)
+}
+
+// isReferenceHeavy reports whether a truncated parse with these references
+// was likely cut off in its reference list
+func isReferenceHeavy(references []models.Reference, referencesOnly bool) bool {
+	return referencesOnly || len(references) >= minRecoveredReferences
+}
+
+// extractReferences sends input again asking only for its references, with a
+// larger output budget than a full parse. input is the user message of the
+// parse, with its parsing prompt replaced by the references prompt.
+func extractReferences(ctx context.Context, client *openai.Client, input responses.ResponseInputMessageContentListParam, name string, log logger.Logger) ([]models.Reference, bool, error) {
+	result, truncated, err := newStructuredResponseWithTruncation[referencesResult](ctx, client, responses.ResponseNewParams{
+		Model:           parseModel,
+		MaxOutputTokens: openai.Int(referencesMaxOutputTokens),
+		Input: responses.ResponseNewParamsInputUnion{
+			OfInputItemList: responses.ResponseInputParam{
+				responses.ResponseInputItemParamOfMessage(input, "user"),
+			},
+		},
+		Text: responses.ResponseTextConfigParam{
+			Format: responses.ResponseFormatTextConfigParamOfJSONSchema("references", referencesSchema),
+		},
+	}, name, log)
+	if err != nil {
+		return nil, false, err
+	}
+	return result.References, truncated, nil
+}
+
+// recoverReferences re-extracts the references of a truncated parse whose
+// reference list was likely cut off, merging them with the ones the parse
+// returned. buildInput returns the parse's user message with the given prompt
+// in place of the parsing prompt. If the second pass fails, the parse's own
+// references are kept.
+func recoverReferences(ctx context.Context, client *openai.Client, references []models.Reference, params prompts.References, buildInput func(prompt string) responses.ResponseInputMessageContentListParam, name string, log logger.Logger) []models.Reference {
+	prompt, err := prompts.RenderReferences(params)
+	if err != nil {
+		log.Warn("Failed to render references prompt: %v", err)
+		return references
+	}
+	recovered, truncated, err := extractReferences(ctx, client, buildInput(prompt), name, log)
+	if err != nil {
+		log.Warn("References-only pass failed, keeping %d references: %v", len(references), err)
+		return references
+	}
+	if truncated {
+		log.Warn("References-only pass was also truncated after %d references", len(recovered))
+	}
+	merged := mergeRecoveredReferences(references, recovered)
+	log.Info("Recovered references of a truncated %s: %d parsed, %d re-extracted, %d merged", name, len(references), len(recovered), len(merged))
+	return merged
+}
+
+// mergeRecoveredReferences merges the references of the references-only pass
+// with those of the truncated parse. The second pass's order is kept, and
+// entries only the parse found follow it.
+func mergeRecoveredReferences(parsed, recovered []models.Reference) []models.Reference {
+	refs := make([]models.Reference, 0, len(parsed)+len(recovered))
+	refs = append(refs, recovered...)
+	refs = append(refs, parsed...)
+	return mergeDuplicateReferences(refs)
+}
@@ -0,0 +1,101 @@
+package llm
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// readCapturedOutput reads a page parse output captured in testdata. The
+// truncated ones stop where the model ran out of output tokens.
+func readCapturedOutput(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestOutputTruncated(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     string
+		reason     string
+		file       string
+		output     string
+		expected   bool
+		references int // references decoded from the output, after repair
+	}{
+		{name: "complete", status: "completed", file: "complete_page.json", references: 40},
+		{name: "cut in a reference", status: "incomplete", reason: "max_output_tokens", file: "truncated_mid_reference.json", expected: true, references: 23},
+		{name: "cut after a comma", status: "completed", file: "truncated_after_comma.json", expected: true, references: 30},
+		{name: "token limit flag on closed output", status: "incomplete", reason: "max_output_tokens", file: "complete_page.json", expected: true, references: 40},
+		{name: "content filter", status: "incomplete", reason: "content_filter", file: "complete_page.json", references: 40},
+		{name: "code fenced", status: "completed", output: "```json\n{\"references\": [{\"reference_text\": \"Doe, J.", expected: true},
+		{name: "trailing comma", status: "completed", output: `{"references": [],}`},
+		{name: "not json", status: "completed", output: "I cannot parse this page."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := tt.output
+			if tt.file != "" {
+				output = readCapturedOutput(t, tt.file)
+			}
+			if got := outputTruncated(tt.status, tt.reason, output); got != tt.expected {
+				t.Errorf("outputTruncated() = %v, want %v", got, tt.expected)
+			}
+			if tt.file == "" {
+				return
+			}
+			page, err := decodeJSONOutput[models.ParsedPage](output, "test", logger.NewNoOpLogger())
+			if err != nil {
+				t.Fatalf("decodeJSONOutput failed: %v", err)
+			}
+			if len(page.References) != tt.references {
+				t.Errorf("Expected %d references, got %d", tt.references, len(page.References))
+			}
+		})
+	}
+}
+
+func TestIsReferenceHeavy(t *testing.T) {
+	refs := make([]models.Reference, minRecoveredReferences)
+	if !isReferenceHeavy(refs, false) {
+		t.Errorf("Expected %d references to be reference-heavy", len(refs))
+	}
+	if isReferenceHeavy(refs[:2], false) {
+		t.Error("Expected 2 references not to be reference-heavy")
+	}
+	if !isReferenceHeavy(nil, true) {
+		t.Error("Expected a references-only page to be reference-heavy")
+	}
+}
+
+func TestMergeRecoveredReferences(t *testing.T) {
+	parsed := []models.Reference{
+		{ReferenceText: "Author1, A. (2001). Study number 1 of survey methods. Journal of Surveys, 1(2), 10-19."},
+		{ReferenceText: "Author2, A. (2002). Study number 2 of survey methods. Journal of Surveys, 2(2), 20-29.", DOI: "10.1000/survey.2"},
+		{ReferenceText: "Separately listed, B. (1999). Only the first pass found this entry. Press."},
+	}
+	recovered := []models.Reference{
+		{ReferenceText: "Author1, A. (2001). Study number 1 of survey methods. Journal of Surveys, 1(2), 10-19."},
+		{ReferenceText: "Author2, A. (2002). Study number 2 of survey methods. Journal of Surveys, 2(2), 20-29."},
+		{ReferenceText: "Author3, A. (2003). Study number 3 of survey methods. Journal of Surveys, 3(2), 30-39."},
+	}
+
+	got := mergeRecoveredReferences(parsed, recovered)
+	want := []models.Reference{
+		recovered[0],
+		{ReferenceText: recovered[1].ReferenceText, DOI: "10.1000/survey.2"},
+		recovered[2],
+		parsed[2],
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeRecoveredReferences() =\n%+v\nwant\n%+v", got, want)
+	}
+}
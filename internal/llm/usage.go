@@ -0,0 +1,157 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/openai/openai-go/v3/responses"
+	"github.com/openai/openai-go/v3/shared"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// modelPricingEnv names the JSON object that overrides or extends the model prices
+const modelPricingEnv = "ACADEMIC_MCP_MODEL_PRICING"
+
+// ModelPrice is the price of a model in US dollars per million tokens
+type ModelPrice struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
+}
+
+// defaultModelPricing holds the list prices of the models this server calls
+var defaultModelPricing = map[string]ModelPrice{
+	shared.ChatModelGPT5Mini: {Input: 0.25, Output: 2.00},
+}
+
+// ModelPricing returns the model prices used for cost estimates: the defaults,
+// overridden or extended by ACADEMIC_MCP_MODEL_PRICING, a JSON object such as
+// {"gpt-5-mini": {"input": 0.25, "output": 2.0}}
+func ModelPricing() (map[string]ModelPrice, error) {
+	pricing := make(map[string]ModelPrice, len(defaultModelPricing))
+	for model, price := range defaultModelPricing {
+		pricing[model] = price
+	}
+
+	configured := os.Getenv(modelPricingEnv)
+	if configured == "" {
+		return pricing, nil
+	}
+	var overrides map[string]ModelPrice
+	if err := json.Unmarshal([]byte(configured), &overrides); err != nil {
+		return pricing, fmt.Errorf("invalid %s: %w", modelPricingEnv, err)
+	}
+	for model, price := range overrides {
+		pricing[model] = price
+	}
+	return pricing, nil
+}
+
+// priceFor looks up a model's price, falling back to the longest configured name
+// the model starts with so dated snapshots (e.g., "gpt-5-mini-2025-08-07") match
+func priceFor(pricing map[string]ModelPrice, model string) (ModelPrice, bool) {
+	if price, ok := pricing[model]; ok {
+		return price, true
+	}
+	var match string
+	for name := range pricing {
+		if strings.HasPrefix(model, name) && len(name) > len(match) {
+			match = name
+		}
+	}
+	if match == "" {
+		return ModelPrice{}, false
+	}
+	return pricing[match], true
+}
+
+// SummarizeUsage totals usage and estimates its cost. It returns nil if no
+// requests were made, so cached results report no usage.
+func SummarizeUsage(usage []models.TokenUsage, log logger.Logger) *models.UsageSummary {
+	if len(usage) == 0 {
+		return nil
+	}
+
+	pricing, err := ModelPricing()
+	if err != nil {
+		log.Warn("Using default model prices: %v", err)
+	}
+
+	summary := &models.UsageSummary{Breakdown: make([]models.TokenUsage, len(usage))}
+	for i, u := range usage {
+		if price, ok := priceFor(pricing, u.Model); ok {
+			u.EstimatedCostUSD = (float64(u.InputTokens)*price.Input + float64(u.OutputTokens)*price.Output) / 1e6
+		} else if !slices.Contains(summary.UnpricedModels, u.Model) {
+			summary.UnpricedModels = append(summary.UnpricedModels, u.Model)
+		}
+		summary.Breakdown[i] = u
+		summary.Requests += u.Requests
+		summary.InputTokens += u.InputTokens
+		summary.OutputTokens += u.OutputTokens
+		summary.EstimatedCostUSD += u.EstimatedCostUSD
+	}
+	return summary
+}
+
+// UsageTracker accumulates the tokens used by OpenAI requests made with a
+// context returned by TrackUsage. Trackers nest: usage recorded by a tracker
+// is also added to the tracker of the context it was created from.
+type UsageTracker struct {
+	parent  *UsageTracker
+	mu      sync.Mutex
+	byModel map[string]*models.TokenUsage
+}
+
+type usageTrackerKey struct{}
+
+// TrackUsage returns a context whose OpenAI requests are counted by a new tracker
+func TrackUsage(ctx context.Context) (context.Context, *UsageTracker) {
+	tracker := &UsageTracker{byModel: make(map[string]*models.TokenUsage)}
+	if parent, ok := ctx.Value(usageTrackerKey{}).(*UsageTracker); ok {
+		tracker.parent = parent
+	}
+	return context.WithValue(ctx, usageTrackerKey{}, tracker), tracker
+}
+
+// Add records one request to model and the tokens it used
+func (t *UsageTracker) Add(model string, inputTokens, outputTokens int64) {
+	for ; t != nil; t = t.parent {
+		t.mu.Lock()
+		usage, ok := t.byModel[model]
+		if !ok {
+			usage = &models.TokenUsage{Model: model}
+			t.byModel[model] = usage
+		}
+		usage.Requests++
+		usage.InputTokens += inputTokens
+		usage.OutputTokens += outputTokens
+		t.mu.Unlock()
+	}
+}
+
+// Usage returns the recorded usage per model, sorted by model name
+func (t *UsageTracker) Usage() []models.TokenUsage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	usage := make([]models.TokenUsage, 0, len(t.byModel))
+	for _, u := range t.byModel {
+		usage = append(usage, *u)
+	}
+	slices.SortFunc(usage, func(a, b models.TokenUsage) int {
+		return strings.Compare(a.Model, b.Model)
+	})
+	return usage
+}
+
+// recordUsage adds a response's usage to the tracker in ctx, if there is one
+func recordUsage(ctx context.Context, model string, usage responses.ResponseUsage) {
+	if tracker, ok := ctx.Value(usageTrackerKey{}).(*UsageTracker); ok {
+		tracker.Add(model, usage.InputTokens, usage.OutputTokens)
+	}
+}
@@ -0,0 +1,83 @@
+package llm
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestUsageTracker(t *testing.T) {
+	ctx, call := TrackUsage(context.Background())
+	docCtx, doc := TrackUsage(ctx)
+	_, parse := TrackUsage(docCtx)
+
+	parse.Add("gpt-5-mini", 1000, 200)
+	parse.Add("gpt-5-mini", 3000, 800)
+	doc.Add("gpt-5", 500, 100)
+
+	expectedParse := []models.TokenUsage{{Model: "gpt-5-mini", Requests: 2, InputTokens: 4000, OutputTokens: 1000}}
+	if got := parse.Usage(); !reflect.DeepEqual(got, expectedParse) {
+		t.Errorf("Expected parse usage %+v, got %+v", expectedParse, got)
+	}
+
+	// Usage recorded by nested trackers is added to the trackers they were created from
+	expectedDoc := []models.TokenUsage{
+		{Model: "gpt-5", Requests: 1, InputTokens: 500, OutputTokens: 100},
+		{Model: "gpt-5-mini", Requests: 2, InputTokens: 4000, OutputTokens: 1000},
+	}
+	if got := doc.Usage(); !reflect.DeepEqual(got, expectedDoc) {
+		t.Errorf("Expected document usage %+v, got %+v", expectedDoc, got)
+	}
+	if got := call.Usage(); !reflect.DeepEqual(got, expectedDoc) {
+		t.Errorf("Expected call usage %+v, got %+v", expectedDoc, got)
+	}
+}
+
+func TestSummarizeUsage(t *testing.T) {
+	t.Setenv(modelPricingEnv, `{"custom-model": {"input": 1.0, "output": 4.0}}`)
+	log := logger.NewNoOpLogger()
+
+	summary := SummarizeUsage([]models.TokenUsage{
+		{Operation: "parse", Model: "gpt-5-mini-2025-08-07", Requests: 12, InputTokens: 2_000_000, OutputTokens: 500_000},
+		{Operation: "summarize", Model: "custom-model", Requests: 1, InputTokens: 100_000, OutputTokens: 10_000},
+		{Operation: "quotations", Model: "unknown-model", Requests: 3, InputTokens: 50_000, OutputTokens: 5_000},
+	}, log)
+
+	if summary.Requests != 16 || summary.InputTokens != 2_150_000 || summary.OutputTokens != 515_000 {
+		t.Errorf("Unexpected totals: %+v", summary)
+	}
+
+	// gpt-5-mini snapshot: 2M input at $0.25/M + 0.5M output at $2/M = $1.50
+	// custom-model: 0.1M input at $1/M + 0.01M output at $4/M = $0.14
+	expectedCosts := []float64{1.50, 0.14, 0}
+	for i, expected := range expectedCosts {
+		if got := summary.Breakdown[i].EstimatedCostUSD; math.Abs(got-expected) > 1e-9 {
+			t.Errorf("Breakdown %d: expected cost %.4f, got %.4f", i, expected, got)
+		}
+	}
+	if math.Abs(summary.EstimatedCostUSD-1.64) > 1e-9 {
+		t.Errorf("Expected total cost 1.64, got %.4f", summary.EstimatedCostUSD)
+	}
+	if !reflect.DeepEqual(summary.UnpricedModels, []string{"unknown-model"}) {
+		t.Errorf("Expected unknown-model to be reported as unpriced, got %v", summary.UnpricedModels)
+	}
+
+	if SummarizeUsage(nil, log) != nil {
+		t.Error("Expected no summary without usage")
+	}
+}
+
+func TestModelPricing_InvalidOverride(t *testing.T) {
+	t.Setenv(modelPricingEnv, `not json`)
+	pricing, err := ModelPricing()
+	if err == nil {
+		t.Fatal("Expected an error for invalid pricing JSON")
+	}
+	if _, ok := pricing["gpt-5-mini"]; !ok {
+		t.Errorf("Expected default prices alongside the error, got %v", pricing)
+	}
+}
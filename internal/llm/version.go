@@ -0,0 +1,44 @@
+package llm
+
+import (
+	"github.com/openai/openai-go/v3/shared"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// PromptVersion identifies the document parsing prompts and response schemas.
+// Bump it whenever a change to them would change what parsing extracts, so
+// documents parsed with the older prompts can be found and re-parsed.
+const PromptVersion = 5
+
+// ParserVersion identifies the parsing pipeline around the prompts: splitting,
+// aggregation, and post-processing. Bump it with changes to what is stored.
+const ParserVersion = "4"
+
+// parseModel is the OpenAI model documents are parsed with
+const parseModel = shared.ChatModelGPT5Mini
+
+// summaryModel is the OpenAI model documents are summarized with
+const summaryModel = shared.ChatModelGPT5Mini
+
+// quotationModel is the OpenAI model quotations are extracted and prioritized with
+const quotationModel = shared.ChatModelGPT5Mini
+
+// Models returns the OpenAI model used for each task: parsing, summaries, and
+// quotations
+func Models() map[string]string {
+	return map[string]string{
+		"parse":      string(parseModel),
+		"summarize":  string(summaryModel),
+		"quotations": string(quotationModel),
+	}
+}
+
+// parseProvenance returns the provenance of a document parsed now
+func parseProvenance() *models.Provenance {
+	return &models.Provenance{
+		ParsedModel:   string(parseModel),
+		PromptVersion: PromptVersion,
+		ParserVersion: ParserVersion,
+	}
+}
@@ -0,0 +1,28 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+)
+
+// contextKey is the key of the logger stored in a context
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying log, for code further down the
+// call to log with the fields it was given
+func NewContext(ctx context.Context, log Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, log)
+}
+
+// FromContext returns the logger carried by ctx, or fallback if it has none
+func FromContext(ctx context.Context, fallback Logger) Logger {
+	if log, ok := ctx.Value(contextKey{}).(Logger); ok {
+		return log
+	}
+	return fallback
+}
+
+// NewCorrelationID returns a random ID identifying one request in the logs
+func NewCorrelationID() string {
+	return rand.Text()[:12]
+}
@@ -1,12 +1,18 @@
 package logger
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 )
 
 // Level represents the logging level
@@ -46,6 +52,9 @@ type Logger interface {
 	Error(format string, v ...any)
 	Fatal(format string, v ...any)
 	SetLevel(level Level)
+	// With returns a logger that adds the key-value pairs to each message, after
+	// those of this logger. It shares this logger's output and level.
+	With(keyvals ...any) Logger
 }
 
 // LogConfig holds configuration for the logger
@@ -54,49 +63,49 @@ type LogConfig struct {
 	Output string
 	// Log level: "debug", "info", "warn", "error", "fatal"
 	Level string
+	// Format: "text" (default) or "json", one object per line
+	Format string
 	// FilePath for file output (only used when Output is "file")
 	FilePath string
 }
 
-// standardLogger implements the Logger interface using Go's standard log package
+// standardLogger implements the Logger interface using Go's standard log package.
+// Loggers derived with With share their parent's output and level.
 type standardLogger struct {
+	out    *output
+	fields []field
+}
+
+// output is the destination and settings shared by a logger and those derived from it
+type output struct {
 	logger *log.Logger
-	level  Level
+	level  atomic.Int32
+	json   bool
+}
+
+// field is a key-value pair added to messages by With
+type field struct {
+	key   string
+	value any
 }
 
 // NewLogger creates a new logger based on the provided configuration
 func NewLogger(config LogConfig) (Logger, error) {
 	var writer io.Writer
 
-	// Determine output destination
-	output := config.Output
-	if output == "" {
-		output = os.Getenv("LOG_OUTPUT")
-	}
-	if output == "" {
-		// Auto-detect: if running in container, use stderr; otherwise use file
-		output = detectEnvironment()
-	}
-
-	switch output {
+	switch output := ResolveOutput(config.Output); output {
 	case "stderr":
 		writer = os.Stderr
 	case "file":
-		filePath := config.FilePath
-		if filePath == "" {
-			filePath = os.Getenv("LOG_FILE_PATH")
+		filePath, err := ResolveFilePath(config.FilePath)
+		if err != nil {
+			return nil, err
 		}
-		if filePath == "" {
-			// Default to ~/.academic-mcp/academic.log
-			homeDir, err := os.UserHomeDir()
-			if err != nil {
-				return nil, fmt.Errorf("failed to get user home directory: %w", err)
-			}
-			logDir := filepath.Join(homeDir, ".academic-mcp")
-			if err := os.MkdirAll(logDir, 0755); err != nil {
+		if config.FilePath == "" && os.Getenv("LOG_FILE_PATH") == "" {
+			// Create the default ~/.academic-mcp directory
+			if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
 				return nil, fmt.Errorf("failed to create log directory: %w", err)
 			}
-			filePath = filepath.Join(logDir, "academic.log")
 		}
 
 		// Open log file in append mode
@@ -118,23 +127,64 @@ func NewLogger(config LogConfig) (Logger, error) {
 		levelStr = "info" // default level
 	}
 
-	level := parseLevel(levelStr)
+	format := config.Format
+	if format == "" {
+		format = os.Getenv("LOG_FORMAT")
+	}
+	switch strings.ToLower(format) {
+	case "", "text":
+		return newLogger(writer, parseLevel(levelStr), false), nil
+	case "json":
+		return newLogger(writer, parseLevel(levelStr), true), nil
+	default:
+		return nil, fmt.Errorf("invalid log format: %s (expected 'text' or 'json')", format)
+	}
+}
 
-	// Create standard logger with timestamp
-	stdLog := log.New(writer, "", log.LstdFlags)
+// newLogger creates a logger writing to w. Text messages are timestamped by
+// the log package; JSON messages carry their own time field.
+func newLogger(w io.Writer, level Level, json bool) *standardLogger {
+	flags := log.LstdFlags
+	if json {
+		flags = 0
+	}
+	out := &output{logger: log.New(w, "", flags), json: json}
+	out.level.Store(int32(level))
+	return &standardLogger{out: out}
+}
 
-	return &standardLogger{
-		logger: stdLog,
-		level:  level,
-	}, nil
+// ResolveOutput returns the log destination, "file" or "stderr", for an
+// output that may be empty: LOG_OUTPUT, or detected from the environment
+func ResolveOutput(output string) string {
+	if output == "" {
+		output = os.Getenv("LOG_OUTPUT")
+	}
+	if output == "" {
+		// Auto-detect: if running in container, use stderr; otherwise use file
+		output = detectEnvironment()
+	}
+	return output
+}
+
+// ResolveFilePath returns the log file path for a path that may be empty:
+// LOG_FILE_PATH, or ~/.academic-mcp/academic.log by default
+func ResolveFilePath(filePath string) (string, error) {
+	if filePath == "" {
+		filePath = os.Getenv("LOG_FILE_PATH")
+	}
+	if filePath == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get user home directory: %w", err)
+		}
+		filePath = filepath.Join(homeDir, ".academic-mcp", "academic.log")
+	}
+	return filePath, nil
 }
 
 // NewNoOpLogger creates a logger that discards all output (useful for tests)
 func NewNoOpLogger() Logger {
-	return &standardLogger{
-		logger: log.New(io.Discard, "", 0),
-		level:  FatalLevel, // Only log fatals (essentially nothing)
-	}
+	return newLogger(io.Discard, FatalLevel, false) // Only log fatals (essentially nothing)
 }
 
 // detectEnvironment determines the appropriate output based on the environment
@@ -171,35 +221,55 @@ func parseLevel(level string) Level {
 	}
 }
 
-// SetLevel sets the minimum log level
+// SetLevel sets the minimum log level, for this logger and every logger
+// sharing its output
 func (l *standardLogger) SetLevel(level Level) {
-	l.level = level
+	l.out.level.Store(int32(level))
+}
+
+// enabled reports whether messages at level are logged
+func (l *standardLogger) enabled(level Level) bool {
+	return Level(l.out.level.Load()) <= level
+}
+
+// With returns a logger that adds the key-value pairs to each message. A key
+// without a value is logged with an empty one.
+func (l *standardLogger) With(keyvals ...any) Logger {
+	fields := slices.Clip(l.fields)
+	for i := 0; i < len(keyvals); i += 2 {
+		f := field{key: fmt.Sprint(keyvals[i])}
+		if i+1 < len(keyvals) {
+			f.value = keyvals[i+1]
+		}
+		fields = append(fields, f)
+	}
+	return &standardLogger{out: l.out, fields: fields}
 }
 
 // Debug logs a debug message
 func (l *standardLogger) Debug(format string, v ...any) {
-	if l.level <= DebugLevel {
+	if l.enabled(DebugLevel) {
 		l.log(DebugLevel, format, v...)
 	}
 }
 
 // Info logs an info message
 func (l *standardLogger) Info(format string, v ...any) {
-	if l.level <= InfoLevel {
+	if l.enabled(InfoLevel) {
 		l.log(InfoLevel, format, v...)
 	}
 }
 
 // Warn logs a warning message
 func (l *standardLogger) Warn(format string, v ...any) {
-	if l.level <= WarnLevel {
+	if l.enabled(WarnLevel) {
 		l.log(WarnLevel, format, v...)
 	}
 }
 
 // Error logs an error message
 func (l *standardLogger) Error(format string, v ...any) {
-	if l.level <= ErrorLevel {
+	if l.enabled(ErrorLevel) {
 		l.log(ErrorLevel, format, v...)
 	}
 }
@@ -213,5 +283,52 @@ func (l *standardLogger) Fatal(format string, v ...any) {
 // log performs the actual logging
 func (l *standardLogger) log(level Level, format string, v ...any) {
 	message := fmt.Sprintf(format, v...)
-	l.logger.Printf("[%s] %s", level.String(), message)
+	if l.out.json {
+		l.out.logger.Print(l.jsonLine(level, message))
+		return
+	}
+
+	var line strings.Builder
+	fmt.Fprintf(&line, "[%s] %s", level.String(), message)
+	for _, f := range l.fields {
+		value := fmt.Sprint(f.value)
+		if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+			value = strconv.Quote(value)
+		}
+		fmt.Fprintf(&line, " %s=%s", f.key, value)
+	}
+	l.out.logger.Print(line.String())
+}
+
+// jsonLine formats a message as a JSON object with its time, level, message,
+// and fields, in that order. Values that cannot be encoded are logged as text.
+func (l *standardLogger) jsonLine(level Level, message string) string {
+	var line bytes.Buffer
+	line.WriteString(`{"time":`)
+	writeJSON(&line, time.Now().Format(time.RFC3339Nano))
+	line.WriteString(`,"level":`)
+	writeJSON(&line, level.String())
+	line.WriteString(`,"msg":`)
+	writeJSON(&line, message)
+	for _, f := range l.fields {
+		line.WriteByte(',')
+		writeJSON(&line, f.key)
+		line.WriteByte(':')
+		value := f.value
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		writeJSON(&line, value)
+	}
+	line.WriteByte('}')
+	return line.String()
+}
+
+// writeJSON appends the JSON encoding of v to buf
+func writeJSON(buf *bytes.Buffer, v any) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		encoded, _ = json.Marshal(fmt.Sprint(v))
+	}
+	buf.Write(encoded)
 }
@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLogger_TextFields(t *testing.T) {
+	var buf bytes.Buffer
+	log := newLogger(&buf, InfoLevel, false)
+
+	request := log.With("request_id", "abc123", "tool", "document-parse")
+	page := request.With("document_id", "doc 1", "page", 3)
+	page.Info("Parsing page %d", 3)
+	request.Warn("Done")
+	log.Debug("Not logged")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %q", buf.String())
+	}
+	if !strings.HasSuffix(lines[0], `[INFO] Parsing page 3 request_id=abc123 tool=document-parse document_id="doc 1" page=3`) {
+		t.Errorf("Unexpected nested line %q", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], "[WARN] Done request_id=abc123 tool=document-parse") {
+		t.Errorf("Expected the parent's fields only, got %q", lines[1])
+	}
+}
+
+func TestLogger_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	log := newLogger(&buf, DebugLevel, true)
+
+	request := log.With("request_id", "abc123")
+	// Siblings derived from the same logger do not share fields
+	first := request.With("page", 1)
+	second := request.With("page", 2, "odd")
+	first.Debug("Page %d", 1)
+	second.Error("Failed: %v", "timeout")
+
+	var entries []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Expected a JSON object per line, got %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if e := entries[0]; e["level"] != "DEBUG" || e["msg"] != "Page 1" || e["request_id"] != "abc123" || e["page"] != float64(1) || e["time"] == "" {
+		t.Errorf("Unexpected entry %v", e)
+	}
+	if e := entries[1]; e["level"] != "ERROR" || e["msg"] != "Failed: timeout" || e["page"] != float64(2) || e["odd"] != nil {
+		t.Errorf("Unexpected entry %v", e)
+	}
+	if _, ok := entries[1]["odd"]; !ok {
+		t.Errorf("Expected a key without a value to be logged, got %v", entries[1])
+	}
+}
+
+func TestLogger_SetLevelShared(t *testing.T) {
+	var buf bytes.Buffer
+	log := newLogger(&buf, InfoLevel, false)
+	child := log.With("request_id", "abc123")
+
+	log.SetLevel(ErrorLevel)
+	child.Warn("Hidden")
+	if buf.Len() != 0 {
+		t.Errorf("Expected the parent's level to apply to the child, got %q", buf.String())
+	}
+	child.SetLevel(DebugLevel)
+	log.Debug("Shown")
+	if !strings.Contains(buf.String(), "Shown") {
+		t.Errorf("Expected the child's level to apply to the parent, got %q", buf.String())
+	}
+}
+
+func TestContext(t *testing.T) {
+	var buf bytes.Buffer
+	fallback := newLogger(&buf, InfoLevel, false)
+	ctx := context.Background()
+	if FromContext(ctx, fallback) != fallback {
+		t.Error("Expected the fallback without a logger in the context")
+	}
+
+	ctx = NewContext(ctx, fallback.With("request_id", NewCorrelationID()))
+	FromContext(ctx, fallback).With("document_id", "doc1").Info("Stored")
+	if !strings.Contains(buf.String(), "request_id=") || !strings.Contains(buf.String(), "document_id=doc1") {
+		t.Errorf("Expected the context logger's fields, got %q", buf.String())
+	}
+	if a, b := NewCorrelationID(), NewCorrelationID(); len(a) != 12 || a == b {
+		t.Errorf("Expected distinct 12 character IDs, got %q and %q", a, b)
+	}
+}
+
+func TestNewLogger_Format(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "academic.log")
+	t.Setenv("LOG_FORMAT", "json")
+	log, err := NewLogger(LogConfig{Output: "file", FilePath: path, Level: "info"})
+	if err != nil {
+		t.Fatalf("NewLogger failed: %v", err)
+	}
+	log.With("request_id", "abc123").Info("Started")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entry map[string]any
+	if err := json.Unmarshal(data, &entry); err != nil || entry["request_id"] != "abc123" {
+		t.Errorf("Expected a JSON line from LOG_FORMAT, got %q: %v", data, err)
+	}
+
+	t.Setenv("LOG_FORMAT", "xml")
+	if _, err := NewLogger(LogConfig{Output: "stderr"}); err == nil {
+		t.Error("Expected an invalid format to be rejected")
+	}
+}
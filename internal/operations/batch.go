@@ -0,0 +1,67 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/llm"
+)
+
+// batchDocumentsEnv names the environment variable that sets how many documents
+// of batch tool calls are processed at once
+const batchDocumentsEnv = "ACADEMIC_MCP_BATCH_DOCUMENTS"
+
+// defaultBatchDocuments is how many documents of batch tool calls are processed
+// at once by default. Each document's pages are parsed in parallel on top of
+// this, so the limit keeps a large batch from flooding the OpenAI rate limiter.
+const defaultBatchDocuments = 3
+
+// BatchDocuments returns how many documents of batch tool calls are processed at
+// once, set by ACADEMIC_MCP_BATCH_DOCUMENTS. An invalid value returns the default
+// and an error.
+func BatchDocuments() (int, error) {
+	value := strings.TrimSpace(os.Getenv(batchDocumentsEnv))
+	if value == "" {
+		return defaultBatchDocuments, nil
+	}
+	documents, err := strconv.Atoi(value)
+	if err != nil || documents < 1 {
+		return defaultBatchDocuments, fmt.Errorf("invalid %s %q (expected a positive number)", batchDocumentsEnv, value)
+	}
+	return documents, nil
+}
+
+// batchSlots is shared by every batch tool call, so concurrent calls together
+// stay within the limit
+var batchSlots = newBatchSlots()
+
+func newBatchSlots() *llm.WorkerPool {
+	documents, _ := BatchDocuments()
+	return llm.NewWorkerPool(documents)
+}
+
+// ForEachDocument calls process for each of count documents of a batch and
+// returns once all have finished. The documents are processed concurrently, but
+// no more than BatchDocuments at once across all batches, starting in order as
+// slots free up. Once ctx is cancelled, the documents still waiting are passed
+// to process without a slot, for it to record their cancellation.
+func ForEachDocument(ctx context.Context, count int, process func(i int)) {
+	var wg sync.WaitGroup
+	for i := range count {
+		if err := batchSlots.Acquire(ctx); err != nil {
+			process(i)
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer batchSlots.Release()
+			process(i)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,112 @@
+package operations
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/llm"
+)
+
+// withBatchDocuments limits batch tool calls to documents at once for the test
+func withBatchDocuments(t *testing.T, documents int) {
+	t.Helper()
+	previous := batchSlots
+	batchSlots = llm.NewWorkerPool(documents)
+	t.Cleanup(func() { batchSlots = previous })
+}
+
+// inFlight counts the fake documents being processed, recording the most at once
+type inFlight struct {
+	mu       sync.Mutex
+	current  int
+	most     int
+	finished []int
+}
+
+// process is a fake document that takes a moment to process
+func (f *inFlight) process(i int) {
+	f.mu.Lock()
+	f.current++
+	f.most = max(f.most, f.current)
+	f.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	f.mu.Lock()
+	f.current--
+	f.finished = append(f.finished, i)
+	f.mu.Unlock()
+}
+
+func TestForEachDocument(t *testing.T) {
+	withBatchDocuments(t, 2)
+	ctx := context.Background()
+
+	var docs inFlight
+	ForEachDocument(ctx, 7, docs.process)
+	if docs.most != 2 || len(docs.finished) != 7 {
+		t.Errorf("Expected 7 documents processed 2 at a time, got %d processed and %d at once", len(docs.finished), docs.most)
+	}
+
+	// Concurrent batches share the limit
+	var shared inFlight
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ForEachDocument(ctx, 4, shared.process)
+		}()
+	}
+	wg.Wait()
+	if shared.most != 2 || len(shared.finished) != 12 {
+		t.Errorf("Expected 12 documents processed 2 at a time, got %d processed and %d at once", len(shared.finished), shared.most)
+	}
+}
+
+func TestForEachDocument_Cancelled(t *testing.T) {
+	withBatchDocuments(t, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// The first document holds the only slot until the batch is cancelled, and
+	// the rest are still passed on, to record their cancellation
+	var mu sync.Mutex
+	var cancelled []int
+	ForEachDocument(ctx, 3, func(i int) {
+		if i == 0 {
+			cancel()
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if ctx.Err() != nil {
+			cancelled = append(cancelled, i)
+		}
+	})
+	if len(cancelled) != 2 {
+		t.Errorf("Expected the 2 waiting documents processed as cancelled, got %v", cancelled)
+	}
+}
+
+func TestBatchDocuments(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{"", defaultBatchDocuments, false},
+		{"5", 5, false},
+		{" 1 ", 1, false},
+		{"0", defaultBatchDocuments, true},
+		{"many", defaultBatchDocuments, true},
+	}
+	for _, tt := range tests {
+		t.Setenv(batchDocumentsEnv, tt.value)
+		got, err := BatchDocuments()
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("BatchDocuments() with %q = %d, %v; want %d (error %v)", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
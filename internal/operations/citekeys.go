@@ -0,0 +1,135 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/citations"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// CitekeyRegeneration describes the citekeys of a library generated again
+type CitekeyRegeneration struct {
+	Changes   []models.CitekeyChange // Citekeys that change (or would, if not applied), by library and old citekey
+	Unchanged int                    // Generated citekeys that stay as they are
+	Custom    []string               // Documents whose citekeys were not generated, which are kept
+	Applied   bool
+}
+
+// citekeyDocument is a document whose citekey is being generated again
+type citekeyDocument struct {
+	docID    string
+	citekey  string
+	metadata *models.ItemMetadata
+}
+
+// RegenerateCitekeys generates the citekeys of the documents of library (every
+// library if empty) again in mode, and stores them if apply is set. Only
+// citekeys that could have been generated for a document's metadata, in any
+// mode, are replaced; citekeys set by hand or imported are kept. A citekey that
+// already has the base mode gives (with any collision suffix) is kept too, and
+// the others are generated in the order of their current citekeys, so
+// "garcía2020" and "garcía2020a" become "garcia2020" and "garcia2020a". New
+// citekeys do not collide with the kept ones.
+func RegenerateCitekeys(ctx context.Context, store storage.Store, library string, mode citations.CitekeyMode, apply bool, log logger.Logger) (*CitekeyRegeneration, error) {
+	citekeyMap, err := store.GetCitekeyMap(ctx, library)
+	if err != nil {
+		return nil, models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to retrieve citekeys: %w", err))
+	}
+
+	// Citekeys are unique per library, so each library is regenerated on its own
+	libraries := make(map[string][]citekeyDocument)
+	for docID, citekey := range citekeyMap {
+		metadata, err := store.GetMetadata(ctx, docID)
+		if err != nil {
+			return nil, models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to get metadata for document %s: %w", docID, err))
+		}
+		docLibrary, _ := storage.SplitLibraryDocumentID(docID)
+		libraries[docLibrary] = append(libraries[docLibrary], citekeyDocument{docID: docID, citekey: citekey, metadata: metadata})
+	}
+	names := make([]string, 0, len(libraries))
+	for name := range libraries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := &CitekeyRegeneration{Changes: []models.CitekeyChange{}}
+	for _, name := range names {
+		regenerateLibraryCitekeys(libraries[name], mode, result)
+	}
+	sort.Strings(result.Custom)
+
+	if !apply || len(result.Changes) == 0 {
+		return result, nil
+	}
+	citekeys := make(map[string]string, len(result.Changes))
+	for _, change := range result.Changes {
+		citekeys[change.DocumentID] = change.NewCitekey
+	}
+	if err := store.SetCitekeys(ctx, citekeys); err != nil {
+		return nil, models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to store citekeys: %w", err))
+	}
+	result.Applied = true
+	log.Info("Regenerated %d citekeys in %s mode", len(result.Changes), mode)
+	return result, nil
+}
+
+// regenerateLibraryCitekeys adds the changes to the citekeys of one library's
+// documents to result
+func regenerateLibraryCitekeys(docs []citekeyDocument, mode citations.CitekeyMode, result *CitekeyRegeneration) {
+	sort.Slice(docs, func(i, j int) bool {
+		if docs[i].citekey != docs[j].citekey {
+			return docs[i].citekey < docs[j].citekey
+		}
+		return docs[i].docID < docs[j].docID
+	})
+
+	taken := make(map[string]bool, len(docs))
+	var generated []citekeyDocument
+	for _, doc := range docs {
+		if isGeneratedCitekey(doc.citekey, doc.metadata) {
+			generated = append(generated, doc)
+			continue
+		}
+		taken[doc.citekey] = true
+		result.Custom = append(result.Custom, doc.docID)
+	}
+
+	var regenerate []citekeyDocument
+	for _, doc := range generated {
+		if hasCitekeyBase(doc.citekey, citations.GenerateCitekeyWithMode(doc.metadata, nil, mode)) && !taken[doc.citekey] {
+			taken[doc.citekey] = true
+			result.Unchanged++
+			continue
+		}
+		regenerate = append(regenerate, doc)
+	}
+	for _, doc := range regenerate {
+		citekey := citations.GenerateCitekeyWithMode(doc.metadata, taken, mode)
+		taken[citekey] = true
+		if citekey == doc.citekey {
+			result.Unchanged++
+			continue
+		}
+		result.Changes = append(result.Changes, models.CitekeyChange{
+			DocumentID: doc.docID,
+			Title:      doc.metadata.Title,
+			OldCitekey: doc.citekey,
+			NewCitekey: citekey,
+		})
+	}
+}
+
+// isGeneratedCitekey reports whether citekey could have been generated for
+// metadata in one of the citekey modes
+func isGeneratedCitekey(citekey string, metadata *models.ItemMetadata) bool {
+	for _, mode := range citations.CitekeyModes {
+		if hasCitekeyBase(citekey, citations.GenerateCitekeyWithMode(metadata, nil, mode)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package operations
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/citations"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestRegenerateCitekeys(t *testing.T) {
+	store := newDuplicateTestStore(t)
+	ctx := context.Background()
+	log := logger.NewNoOpLogger()
+
+	items := map[string]models.ItemMetadata{
+		"garcia-1":  {Title: "First", Authors: []string{"García, José"}, PublicationDate: "2020", Citekey: "garcía2020"},
+		"garcia-2":  {Title: "Second", Authors: []string{"García, Ana"}, PublicationDate: "2020", Citekey: "garcía2020a"},
+		"garcia-3":  {Title: "Third", Authors: []string{"Garcia, Luis"}, PublicationDate: "2020", Citekey: "garcia2020"},
+		"muller":    {Title: "Fourth", Authors: []string{"Müller, Hans"}, PublicationDate: "2019", Citekey: "müller2019"},
+		"smith":     {Title: "Fifth", Authors: []string{"Smith, Jane"}, PublicationDate: "2021", Citekey: "smith2021"},
+		"custom":    {Title: "Sixth", Authors: []string{"Gödel, Kurt"}, PublicationDate: "1931", Citekey: "goedel_incompleteness"},
+		"thesis:mu": {Title: "Seventh", Authors: []string{"Müller, Eva"}, PublicationDate: "2019", Citekey: "müller2019"},
+	}
+	for docID, metadata := range items {
+		if err := store.StoreParsedItem(ctx, docID, &models.ParsedItem{Metadata: metadata}, &models.SourceInfo{}); err != nil {
+			t.Fatalf("Failed to store %s: %v", docID, err)
+		}
+	}
+
+	// A dry run reports the changes without making them
+	result, err := RegenerateCitekeys(ctx, store, "", citations.CitekeyASCIIGerman, false, log)
+	if err != nil {
+		t.Fatalf("RegenerateCitekeys failed: %v", err)
+	}
+	expected := []models.CitekeyChange{
+		// garcia2020 already has the base key, so the others are suffixed in order
+		{DocumentID: "garcia-1", Title: "First", OldCitekey: "garcía2020", NewCitekey: "garcia2020a"},
+		{DocumentID: "garcia-2", Title: "Second", OldCitekey: "garcía2020a", NewCitekey: "garcia2020b"},
+		{DocumentID: "muller", Title: "Fourth", OldCitekey: "müller2019", NewCitekey: "mueller2019"},
+		// Each library is regenerated on its own
+		{DocumentID: "thesis:mu", Title: "Seventh", OldCitekey: "müller2019", NewCitekey: "mueller2019"},
+	}
+	if !reflect.DeepEqual(result.Changes, expected) {
+		t.Errorf("Expected changes %+v, got %+v", expected, result.Changes)
+	}
+	if result.Unchanged != 2 || !reflect.DeepEqual(result.Custom, []string{"custom"}) || result.Applied {
+		t.Errorf("Expected 2 unchanged and 1 custom citekey, not applied, got %+v", result)
+	}
+	if citekeys, _ := store.GetCitekeyMap(ctx, ""); citekeys["muller"] != "müller2019" {
+		t.Errorf("Expected a dry run to leave citekeys unchanged, got %v", citekeys)
+	}
+
+	result, err = RegenerateCitekeys(ctx, store, "", citations.CitekeyASCIIGerman, true, log)
+	if err != nil || !result.Applied || len(result.Changes) != len(expected) {
+		t.Fatalf("Expected the changes to be applied, got %+v, %v", result, err)
+	}
+	citekeys, err := store.GetCitekeyMap(ctx, "")
+	if err != nil {
+		t.Fatalf("GetCitekeyMap failed: %v", err)
+	}
+	for _, change := range expected {
+		if citekeys[change.DocumentID] != change.NewCitekey {
+			t.Errorf("Expected %s to have citekey %s, got %s", change.DocumentID, change.NewCitekey, citekeys[change.DocumentID])
+		}
+	}
+
+	// Regenerating again changes nothing, and going back to unicode restores
+	// the accents of the default library's keys only
+	if result, err := RegenerateCitekeys(ctx, store, "", citations.CitekeyASCIIGerman, true, log); err != nil || len(result.Changes) != 0 || result.Applied {
+		t.Errorf("Expected no further changes, got %+v, %v", result, err)
+	}
+	result, err = RegenerateCitekeys(ctx, store, "default", citations.CitekeyUnicode, false, log)
+	if err != nil {
+		t.Fatalf("RegenerateCitekeys failed: %v", err)
+	}
+	var changed []string
+	for _, change := range result.Changes {
+		changed = append(changed, change.DocumentID+"="+change.NewCitekey)
+	}
+	if want := []string{"garcia-1=garcía2020", "garcia-2=garcía2020a", "muller=müller2019"}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("Expected changes %v, got %v", want, changed)
+	}
+}
+
+func TestGenerateCitekey_ConfiguredMode(t *testing.T) {
+	metadata := &models.ItemMetadata{Authors: []string{"Müller, Hans"}, PublicationDate: "2019"}
+	log := logger.NewNoOpLogger()
+	for _, tt := range []struct{ mode, want string }{
+		{"", "müller2019"},
+		{"ascii", "muller2019"},
+		{"ascii-german", "mueller2019"},
+		{"latin1", "müller2019"}, // An invalid mode falls back to the default
+	} {
+		t.Setenv("ACADEMIC_MCP_CITEKEY_MODE", tt.mode)
+		if got := generateCitekey(metadata, map[string]bool{}, log); got != tt.want {
+			t.Errorf("Mode %q: expected %s, got %s", tt.mode, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/config"
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
+	"github.com/Epistemic-Technology/academic-mcp/internal/llm"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// minDetectedPageConfidence is the confidence above which a re-parsed page's
+// detected page number is reported when it disagrees with the stored one
+const minDetectedPageConfidence = 0.7
+
+// DocumentReparseParams contains parameters for re-parsing individual pages.
+type DocumentReparseParams struct {
+	Pages   []int  // Sequential page numbers (1-indexed) to re-parse
+	RawData []byte // Original document bytes; required for documents not fetched from Zotero or a URL
+}
+
+// ReparsedPage describes the outcome of re-parsing one page.
+type ReparsedPage struct {
+	Page               int    // Sequential page number (1-indexed)
+	SourcePageNumber   string // Stored source page number (kept unchanged)
+	DetectedPageNumber string // Page number detected by the re-parse, if it confidently differs from SourcePageNumber
+	OldContentLength   int
+	NewContentLength   int
+	FootnoteCount      int // Footnotes now stored for this page
+	ReferenceCount     int // References now stored for this page
+	Quality            models.PageQuality
+}
+
+// DocumentReparseResult describes the outcome of re-parsing pages of a document.
+type DocumentReparseResult struct {
+	DocumentID string
+	Pages      []ReparsedPage
+	Warnings   []string
+}
+
+// ReparseDocumentPages re-parses selected pages of a stored PDF document and
+// replaces only those pages' content along with the footnotes, endnotes, and
+// references attributed to them. Everything else, including the source page
+// numbering, metadata, summary, and quotations, is left unchanged.
+//
+// The original PDF is re-fetched from Zotero or the URL it came from. Documents
+// parsed from raw data must supply the same bytes in params.RawData.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeouts
+//   - docID: Stored document ID
+//   - params: Pages to re-parse and optional original document bytes
+//   - store: Storage backend for reading and updating the document
+//   - log: Logger for recording operations
+//
+// Returns:
+//   - result: Old and new content lengths and stored note counts per page
+//   - error: Any error encountered while fetching, parsing, or storing
+func ReparseDocumentPages(ctx context.Context, docID string, params DocumentReparseParams, store storage.Store, log logger.Logger) (*DocumentReparseResult, error) {
+	log = log.With("document_id", docID)
+	if len(params.Pages) == 0 {
+		return nil, errors.New("at least one page is required")
+	}
+
+	item, err := store.GetParsedItem(ctx, docID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve document: %w", err)
+	}
+	sourceInfo, err := store.GetSourceInfo(ctx, docID)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := slices.Clone(params.Pages)
+	slices.Sort(pages)
+	pages = slices.Compact(pages)
+	for _, page := range pages {
+		if page < 1 || page > len(item.Pages) {
+			return nil, fmt.Errorf("page %d is out of range (document has %d pages)", page, len(item.Pages))
+		}
+	}
+
+	data, err := getReparseSourceData(ctx, docID, sourceInfo, params.RawData)
+	if err != nil {
+		return nil, err
+	}
+	if data.Type != "pdf" {
+		return nil, fmt.Errorf("page re-parsing is only supported for PDF documents (document type: %s)", data.Type)
+	}
+
+	pdfPages, err := documents.OpenPdfPages(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to split PDF: %w", err)
+	}
+	if pdfPages.Len() != len(item.Pages) {
+		return nil, fmt.Errorf("source PDF has %d pages but the stored document has %d; re-parse the whole document instead", pdfPages.Len(), len(item.Pages))
+	}
+
+	imageOnly, err := pdfPages.ImageOnly()
+	if err != nil {
+		log.Warn("Failed to detect image-only pages, assuming a text layer: %v", err)
+		imageOnly = nil
+	}
+
+	apiKey, err := config.FromContext(ctx).OpenAIAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	pageIndexes := make([]int, len(pages))
+	for i, page := range pages {
+		pageIndexes[i] = page - 1
+	}
+	parseCtx, usage := llm.TrackUsage(ctx)
+	parsedPages, quality, err := llm.ParseSelectedPDFPages(parseCtx, apiKey, pdfPages, imageOnly, pageIndexes, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to re-parse pages: %w", err)
+	}
+
+	result := &DocumentReparseResult{DocumentID: docID}
+	if hasUnattributedReferences(item) {
+		result.Warnings = append(result.Warnings, "references were stored without page numbers and were left unchanged; re-parse the whole document to refresh them")
+	}
+	// Anchors on other pages refer to notes by index, which the re-parsed pages'
+	// notes shift, so they are restored to markers and linked again afterwards
+	documents.UnlinkNotes(item)
+	for i, page := range pages {
+		result.Pages = append(result.Pages, applyPageReparse(item, page-1, parsedPages[i], quality[i]))
+	}
+	documents.LinkNotes(item, noteAnchorKey(docID, item))
+	item.Sections = documents.ExtractSections(item.Pages, item.PageNumbers)
+	buildFullText(item, log)
+	item.PageSentences = documents.PageSentences(item.Pages)
+	documents.StructureTables(item)
+
+	if err := store.StoreParsedItem(ctx, docID, item, sourceInfo); err != nil {
+		return nil, fmt.Errorf("failed to store re-parsed pages: %w", err)
+	}
+
+	RecordUsage(ctx, store, docID, UsageReparse, usage, log)
+	log.Info("Re-parsed %d pages of document %s", len(pages), docID)
+	return result, nil
+}
+
+// getReparseSourceData returns the original document bytes, preferring raw data
+// supplied by the caller and otherwise re-fetching from the stored source.
+func getReparseSourceData(ctx context.Context, docID string, sourceInfo *models.SourceInfo, rawData []byte) (models.DocumentData, error) {
+	if rawData != nil {
+		data := models.DocumentData{Data: rawData, Type: documents.DetectDocumentType(rawData), Pages: sourceInfo.Pages}
+		// Documents parsed from raw data are identified by a hash of that data
+		source := &models.SourceInfo{Pages: sourceInfo.Pages}
+		_, id := storage.SplitLibraryDocumentID(docID)
+		if strings.HasPrefix(id, "data_") && storage.GenerateDocumentID(source, data) != id && storage.LegacyDocumentID(source, data) != id {
+			return models.DocumentData{}, fmt.Errorf("raw_data does not match document %s", docID)
+		}
+		return data, nil
+	}
+	if sourceInfo.ZoteroID == "" && sourceInfo.URL == "" {
+		return models.DocumentData{}, fmt.Errorf("document %s was parsed from raw data; provide raw_data to re-parse its pages", docID)
+	}
+	data, err := documents.GetData(ctx, *sourceInfo)
+	if err != nil {
+		return models.DocumentData{}, fmt.Errorf("failed to fetch document data: %w", err)
+	}
+	return data, nil
+}
+
+// hasUnattributedReferences reports whether a document has references that were
+// stored before reference page numbers were recorded
+func hasUnattributedReferences(item *models.ParsedItem) bool {
+	for _, ref := range item.References {
+		if ref.PageNumber == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// applyPageReparse replaces the content of the page at pageIndex (0-indexed) with a
+// re-parsed version. Footnotes, endnotes, and references attributed to the page's
+// source page number are replaced in place; the source page number itself is kept
+// so numbering stays consistent with the surrounding pages.
+func applyPageReparse(item *models.ParsedItem, pageIndex int, parsed *models.ParsedPage, quality models.PageQuality) ReparsedPage {
+	sourcePage := item.PageNumbers[pageIndex]
+	summary := ReparsedPage{
+		Page:             pageIndex + 1,
+		SourcePageNumber: sourcePage,
+		OldContentLength: len(item.Pages[pageIndex]),
+		NewContentLength: len(parsed.Content),
+		Quality:          quality,
+	}
+
+	detected := parsed.PageNumberInfo
+	if detected.PageNumber != "" && detected.PageNumber != sourcePage && detected.Confidence >= minDetectedPageConfidence {
+		summary.DetectedPageNumber = detected.PageNumber
+	}
+
+	item.Pages[pageIndex] = parsed.Content
+
+	if item.PageQuality == nil && quality != (models.PageQuality{}) {
+		item.PageQuality = make([]models.PageQuality, len(item.Pages))
+	}
+	if pageIndex < len(item.PageQuality) {
+		item.PageQuality[pageIndex] = quality
+	}
+
+	// Map source page numbers to their position so replacements keep page order
+	pageOrder := make(map[string]int, len(item.PageNumbers))
+	for i, number := range item.PageNumbers {
+		if _, seen := pageOrder[number]; !seen {
+			pageOrder[number] = i
+		}
+	}
+
+	footnotes := make([]models.Footnote, len(parsed.Footnotes))
+	for i, footnote := range parsed.Footnotes {
+		footnote.PageNumber = sourcePage
+		if footnote.InTextPage == "" {
+			footnote.InTextPage = sourcePage
+		}
+		footnotes[i] = footnote
+	}
+	item.Footnotes = spliceByPage(item.Footnotes, func(f models.Footnote) string { return f.PageNumber }, pageOrder, sourcePage, footnotes)
+	summary.FootnoteCount = len(footnotes)
+
+	endnotes := make([]models.Endnote, len(parsed.Endnotes))
+	for i, endnote := range parsed.Endnotes {
+		endnote.PageNumber = sourcePage
+		endnotes[i] = endnote
+	}
+	item.Endnotes = spliceByPage(item.Endnotes, func(e models.Endnote) string { return e.PageNumber }, pageOrder, sourcePage, endnotes)
+	documents.AssignEndnoteKeys(item.Endnotes)
+
+	if hasUnattributedReferences(item) {
+		// Without page attribution the page's old references can't be identified
+		return summary
+	}
+	references := make([]models.Reference, len(parsed.References))
+	for i, ref := range parsed.References {
+		ref.PageNumber = sourcePage
+		ref.PageIndex = pageIndex + 1
+		references[i] = ref
+	}
+	item.References = spliceByPage(item.References, func(r models.Reference) string { return r.PageNumber }, pageOrder, sourcePage, references)
+	summary.ReferenceCount = len(references)
+
+	return summary
+}
+
+// spliceByPage removes the elements belonging to page and inserts replacement
+// where they were, or before the first element from a later page if the page had
+// none. Elements whose page is unknown keep their position.
+func spliceByPage[T any](elements []T, pageOf func(T) string, pageOrder map[string]int, page string, replacement []T) []T {
+	target := pageOrder[page]
+	result := make([]T, 0, len(elements)+len(replacement))
+	inserted := false
+	for _, element := range elements {
+		elementPage := pageOf(element)
+		if elementPage == page {
+			if !inserted {
+				result = append(result, replacement...)
+				inserted = true
+			}
+			continue
+		}
+		if order, known := pageOrder[elementPage]; known && order > target && !inserted {
+			result = append(result, replacement...)
+			inserted = true
+		}
+		result = append(result, element)
+	}
+	if !inserted {
+		result = append(result, replacement...)
+	}
+	return result
+}
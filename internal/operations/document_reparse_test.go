@@ -0,0 +1,158 @@
+package operations
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// reparseTestItem builds a three-page document numbered 10-12 with notes and references
+func reparseTestItem() *models.ParsedItem {
+	return &models.ParsedItem{
+		Pages:       []string{"Page ten", "Mangled page eleven", "Page twelve"},
+		PageNumbers: []string{"10", "11", "12"},
+		Footnotes: []models.Footnote{
+			{Marker: "1", Text: "Note on ten", PageNumber: "10"},
+			{Marker: "2", Text: "Bad note on eleven", PageNumber: "11"},
+			{Marker: "3", Text: "Another bad note on eleven", PageNumber: "11"},
+			{Marker: "4", Text: "Note on twelve", PageNumber: "12"},
+		},
+		References: []models.Reference{
+			{ReferenceText: "Old reference on eleven", PageNumber: "11"},
+			{ReferenceText: "Reference on twelve", PageNumber: "12"},
+		},
+	}
+}
+
+func TestApplyPageReparse(t *testing.T) {
+	item := reparseTestItem()
+	parsed := &models.ParsedPage{
+		Content:        "Clean page eleven content",
+		Footnotes:      []models.Footnote{{Marker: "2", Text: "Fixed note on eleven"}},
+		References:     []models.Reference{{ReferenceText: "New reference A"}, {ReferenceText: "New reference B"}},
+		PageNumberInfo: models.PageNumberInfo{PageNumber: "11", Confidence: 1.0},
+	}
+
+	summary := applyPageReparse(item, 1, parsed, models.PageQuality{})
+
+	expected := ReparsedPage{
+		Page:             2,
+		SourcePageNumber: "11",
+		OldContentLength: len("Mangled page eleven"),
+		NewContentLength: len("Clean page eleven content"),
+		FootnoteCount:    1,
+		ReferenceCount:   2,
+	}
+	if summary != expected {
+		t.Errorf("Expected summary %+v, got %+v", expected, summary)
+	}
+
+	if item.Pages[1] != "Clean page eleven content" {
+		t.Errorf("Expected page content to be replaced, got %q", item.Pages[1])
+	}
+	if !reflect.DeepEqual(item.PageNumbers, []string{"10", "11", "12"}) {
+		t.Errorf("Expected page numbers to be unchanged, got %v", item.PageNumbers)
+	}
+
+	var footnoteTexts []string
+	for _, f := range item.Footnotes {
+		footnoteTexts = append(footnoteTexts, f.Text)
+	}
+	wantFootnotes := []string{"Note on ten", "Fixed note on eleven", "Note on twelve"}
+	if !reflect.DeepEqual(footnoteTexts, wantFootnotes) {
+		t.Errorf("Expected footnotes %v, got %v", wantFootnotes, footnoteTexts)
+	}
+	if item.Footnotes[1].PageNumber != "11" || item.Footnotes[1].InTextPage != "11" {
+		t.Errorf("Expected replacement footnote on page 11, got %+v", item.Footnotes[1])
+	}
+
+	var referenceTexts []string
+	for _, r := range item.References {
+		referenceTexts = append(referenceTexts, r.ReferenceText)
+	}
+	wantReferences := []string{"New reference A", "New reference B", "Reference on twelve"}
+	if !reflect.DeepEqual(referenceTexts, wantReferences) {
+		t.Errorf("Expected references %v, got %v", wantReferences, referenceTexts)
+	}
+}
+
+func TestApplyPageReparse_PageWithoutPreviousNotes(t *testing.T) {
+	item := reparseTestItem()
+	item.Footnotes = []models.Footnote{item.Footnotes[0], item.Footnotes[3]}
+	parsed := &models.ParsedPage{
+		Content:        "Page eleven",
+		Footnotes:      []models.Footnote{{Marker: "2", Text: "Newly found note"}},
+		PageNumberInfo: models.PageNumberInfo{PageNumber: "17", Confidence: 0.9},
+	}
+
+	summary := applyPageReparse(item, 1, parsed, models.PageQuality{NearEmpty: true})
+
+	if summary.DetectedPageNumber != "17" {
+		t.Errorf("Expected conflicting detected page number to be reported, got %q", summary.DetectedPageNumber)
+	}
+	if len(item.Footnotes) != 3 || item.Footnotes[1].Text != "Newly found note" {
+		t.Errorf("Expected new footnote between pages 10 and 12, got %+v", item.Footnotes)
+	}
+	if len(item.PageQuality) != 3 || !item.PageQuality[1].NearEmpty {
+		t.Errorf("Expected page quality to be recorded for page 2, got %+v", item.PageQuality)
+	}
+}
+
+func TestApplyPageReparse_UnattributedReferences(t *testing.T) {
+	item := reparseTestItem()
+	item.References = []models.Reference{{ReferenceText: "Legacy reference"}}
+	parsed := &models.ParsedPage{
+		Content:    "Page eleven",
+		References: []models.Reference{{ReferenceText: "New reference"}},
+	}
+
+	summary := applyPageReparse(item, 1, parsed, models.PageQuality{})
+
+	if len(item.References) != 1 || item.References[0].ReferenceText != "Legacy reference" {
+		t.Errorf("Expected legacy references to be left unchanged, got %+v", item.References)
+	}
+	if summary.ReferenceCount != 0 {
+		t.Errorf("Expected no references reported for page, got %d", summary.ReferenceCount)
+	}
+}
+
+func TestReparseDocumentPages_Validation(t *testing.T) {
+	store, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"), logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	if err := store.StoreParsedItem(ctx, "data_0123456789abcdef", reparseTestItem(), &models.SourceInfo{}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		docID       string
+		params      DocumentReparseParams
+		errContains string
+	}{
+		{"no pages", "data_0123456789abcdef", DocumentReparseParams{}, "at least one page"},
+		{"unknown document", "missing", DocumentReparseParams{Pages: []int{1}}, "failed to retrieve document"},
+		{"page out of range", "data_0123456789abcdef", DocumentReparseParams{Pages: []int{4}}, "out of range"},
+		{"raw data required", "data_0123456789abcdef", DocumentReparseParams{Pages: []int{2}}, "provide raw_data"},
+		{"raw data mismatch", "data_0123456789abcdef", DocumentReparseParams{Pages: []int{2}, RawData: []byte("%PDF-1.4 other")}, "does not match"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReparseDocumentPages(ctx, tt.docID, tt.params, store, logger.NewNoOpLogger())
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
+	"github.com/Epistemic-Technology/academic-mcp/internal/llm"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// doiVerifyConcurrency limits the DOI resolver requests made at once
+const doiVerifyConcurrency = 4
+
+// VerifyDocumentDOIs checks the DOIs of a stored document's metadata and
+// references against the DOI resolver. DOIs the resolver does not know are
+// cleared from the stored document and returned. A DOI that cannot be checked,
+// because the resolver is unreachable or fails, is kept and logged.
+func VerifyDocumentDOIs(ctx context.Context, docID string, store storage.Store, log logger.Logger) ([]models.InvalidDOI, error) {
+	item, err := store.GetParsedItem(ctx, docID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve document: %w", err)
+	}
+
+	// Each DOI to check, with the field it came from
+	type candidate struct {
+		doi            *string
+		source         string
+		referenceIndex *int
+	}
+	var candidates []candidate
+	if item.Metadata.DOI != "" {
+		candidates = append(candidates, candidate{doi: &item.Metadata.DOI, source: "metadata"})
+	}
+	for i := range item.References {
+		if item.References[i].DOI != "" {
+			candidates = append(candidates, candidate{doi: &item.References[i].DOI, source: "reference", referenceIndex: &i})
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	unresolved := make([]bool, len(candidates))
+	wp := llm.NewWorkerPool(doiVerifyConcurrency)
+	var wg sync.WaitGroup
+	for i, c := range candidates {
+		if err := wp.Acquire(ctx); err != nil {
+			wg.Wait()
+			return nil, fmt.Errorf("DOI verification cancelled: %w", err)
+		}
+		wg.Add(1)
+		go func(doi string, unresolved *bool) {
+			defer wg.Done()
+			defer wp.Release()
+
+			registered, err := documents.VerifyDOI(ctx, doi)
+			if err != nil {
+				log.Warn("Could not verify DOI %s: %v", doi, err)
+				return
+			}
+			*unresolved = !registered
+		}(*c.doi, &unresolved[i])
+	}
+	wg.Wait()
+
+	var invalid []models.InvalidDOI
+	for i, c := range candidates {
+		if unresolved[i] {
+			invalid = append(invalid, models.InvalidDOI{Value: *c.doi, Reason: models.DOIUnresolved, Source: c.source, ReferenceIndex: c.referenceIndex})
+			*c.doi = ""
+		}
+	}
+	if len(invalid) == 0 {
+		return nil, nil
+	}
+
+	sourceInfo, err := store.GetSourceInfo(ctx, docID)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.StoreParsedItem(ctx, docID, item, sourceInfo); err != nil {
+		return nil, fmt.Errorf("failed to store document: %w", err)
+	}
+	log.Info("Cleared %d unresolved DOIs from document %s", len(invalid), docID)
+	return invalid, nil
+}
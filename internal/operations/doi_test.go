@@ -0,0 +1,69 @@
+package operations
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestVerifyDocumentDOIs(t *testing.T) {
+	resolver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/10.1000/unregistered":
+			w.WriteHeader(http.StatusNotFound)
+		case "/10.1000/flaky":
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.WriteHeader(http.StatusFound)
+		}
+	}))
+	defer resolver.Close()
+	t.Setenv("ACADEMIC_MCP_DOI_RESOLVER_URL", resolver.URL)
+
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(":memory:", log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	item := &models.ParsedItem{
+		Metadata: models.ItemMetadata{Title: "Paper", DOI: "10.1000/registered"},
+		Pages:    []string{"Text"},
+		References: []models.Reference{
+			{ReferenceText: "Hallucinated", DOI: "10.1000/unregistered"},
+			{ReferenceText: "Unchecked", DOI: "10.1000/flaky"},
+			{ReferenceText: "No DOI"},
+		},
+	}
+	if err := store.StoreParsedItem(ctx, "doc-1", item, &models.SourceInfo{URL: "https://example.com/paper"}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+
+	invalid, err := VerifyDocumentDOIs(ctx, "doc-1", store, log)
+	if err != nil {
+		t.Fatalf("VerifyDocumentDOIs failed: %v", err)
+	}
+	if len(invalid) != 1 || invalid[0].Value != "10.1000/unregistered" || invalid[0].Reason != models.DOIUnresolved ||
+		invalid[0].ReferenceIndex == nil || *invalid[0].ReferenceIndex != 0 {
+		t.Fatalf("Expected the unregistered reference DOI reported, got %+v", invalid)
+	}
+
+	stored, err := store.GetParsedItem(ctx, "doc-1")
+	if err != nil {
+		t.Fatalf("GetParsedItem failed: %v", err)
+	}
+	if stored.Metadata.DOI != "10.1000/registered" || stored.References[0].DOI != "" || stored.References[1].DOI != "10.1000/flaky" {
+		t.Errorf("Expected only the unregistered DOI cleared, got %q and references %+v", stored.Metadata.DOI, stored.References)
+	}
+	sourceInfo, err := store.GetSourceInfo(ctx, "doc-1")
+	if err != nil || sourceInfo.URL != "https://example.com/paper" {
+		t.Errorf("Expected the source kept, got %+v (%v)", sourceInfo, err)
+	}
+}
@@ -0,0 +1,105 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// ImportResult describes the outcome of importing a document
+type ImportResult struct {
+	DocumentID      string
+	Item            *models.ParsedItem // The stored document, or the existing one for a duplicate
+	Duplicate       *Duplicate         // Set when the document is a work the store already holds
+	AlreadyImported bool               // The same payload was imported before
+}
+
+// ImportDocument stores a document parsed elsewhere, without the model.
+// payload is the document as it was submitted (JSON or Markdown), whose hash
+// gives the document its ID and content hash, so importing it again returns the
+// stored document. item is validated with documents.ValidateImport, whose
+// ImportError lists the problems found (with code ErrorInvalidInput); a citekey
+// already used by another document is one of them, and one is generated if the
+// item has none. As when parsing, a work the store already holds by DOI, or by
+// title, first author, and year, is returned rather than stored again. The
+// document's sections, full text, note links, and table structure are derived
+// from its pages as for a parsed one.
+func ImportDocument(ctx context.Context, store storage.Store, item *models.ParsedItem, payload []byte, log logger.Logger) (*ImportResult, error) {
+	library := storage.LibraryFromContext(ctx)
+	docID := storage.LibraryDocumentID(library, storage.GenerateDocumentID(&models.SourceInfo{}, models.DocumentData{Data: payload}))
+
+	exists, err := store.DocumentExists(ctx, docID)
+	if err != nil {
+		return nil, models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to check document existence: %w", err))
+	}
+	if exists {
+		log.Info("Document %s was already imported, using the stored document", docID)
+		stored, err := store.GetParsedItem(ctx, docID)
+		if err != nil {
+			return nil, models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to retrieve existing document: %w", err))
+		}
+		return &ImportResult{DocumentID: docID, Item: stored, AlreadyImported: true}, nil
+	}
+
+	if err := documents.ValidateImport(item); err != nil {
+		return nil, models.WithErrorCode(models.ErrorInvalidInput, err)
+	}
+
+	existing, err := existingCitekeys(ctx, store, library, log)
+	if err != nil {
+		return nil, err
+	}
+	if item.Metadata.Citekey != "" && existing[item.Metadata.Citekey] {
+		problem := &documents.ImportError{Problems: []documents.ImportProblem{{
+			Field:   "metadata.citekey",
+			Message: fmt.Sprintf("%q is already used by another document", item.Metadata.Citekey),
+		}}}
+		return nil, models.WithErrorCode(models.ErrorInvalidInput, problem)
+	}
+
+	duplicate, err := findDuplicate(ctx, store, library, &item.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	if duplicate != nil {
+		stored, err := useDuplicate(ctx, store, duplicate, docID, &models.SourceInfo{}, false, log)
+		if err != nil {
+			return nil, err
+		}
+		return &ImportResult{DocumentID: duplicate.DocumentID, Item: stored, Duplicate: duplicate}, nil
+	}
+
+	if item.Metadata.Citekey == "" {
+		item.Metadata.Citekey = generateCitekey(&item.Metadata, existing, log)
+		log.Info("Generated citekey for document: %s", item.Metadata.Citekey)
+	}
+	item.Metadata.MetadataSource = documents.MetadataSourceImported
+	for _, endnote := range item.Endnotes {
+		if endnote.Key == "" {
+			documents.AssignEndnoteKeys(item.Endnotes)
+			break
+		}
+	}
+
+	// What a parse records about itself does not carry over from elsewhere
+	item.ContentHash = storage.ContentHash(payload)
+	item.Fetch = documents.DescribeData(payload)
+	item.Provenance = &models.Provenance{ParserVersion: documents.ImportParserVersion}
+	item.Partial, item.Basic, item.ChunkCount, item.InvalidDOIs = false, false, 0, nil
+	if dropped := documents.SanitizeImages(item.Images, "", ""); dropped > 0 {
+		log.Info("Dropped %d image URLs that cannot be fetched", dropped)
+	}
+
+	finishParsedItem(docID, item, log)
+
+	if err := store.StoreParsedItem(ctx, docID, item, &models.SourceInfo{}); err != nil {
+		log.Error("Failed to store imported document: %v", err)
+		return nil, models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to store imported document: %w", err))
+	}
+	log.Info("Imported document %s with %d pages and %d references", docID, len(item.Pages), len(item.References))
+	return &ImportResult{DocumentID: docID, Item: item}, nil
+}
@@ -0,0 +1,137 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestImportDocument(t *testing.T) {
+	store := newDuplicateTestStore(t)
+	ctx := context.Background()
+	log := logger.NewNoOpLogger()
+
+	payload := []byte("# Field Notes\n\nSee the note.[1]")
+	item := &models.ParsedItem{
+		Metadata: models.ItemMetadata{Title: "Field Notes", Authors: []string{"Robert Jones"}, PublicationDate: "1987"},
+		Pages:    []string{"# Field Notes\n\nSee the note.[1]"},
+		Endnotes: []models.Endnote{{Marker: "1", Text: "A note."}},
+	}
+	result, err := ImportDocument(ctx, store, item, payload, log)
+	if err != nil {
+		t.Fatalf("ImportDocument failed: %v", err)
+	}
+	if !strings.HasPrefix(result.DocumentID, "data_") || result.Duplicate != nil || result.AlreadyImported {
+		t.Errorf("Expected a new document, got %+v", result)
+	}
+
+	stored, err := store.GetParsedItem(ctx, result.DocumentID)
+	if err != nil {
+		t.Fatalf("GetParsedItem failed: %v", err)
+	}
+	if stored.Metadata.Citekey != "jones1987" || stored.Metadata.MetadataSource != documents.MetadataSourceImported {
+		t.Errorf("Expected a generated citekey and the imported source, got %+v", stored.Metadata)
+	}
+	if stored.Provenance == nil || stored.Provenance.ParserVersion != documents.ImportParserVersion || stored.ContentHash == "" {
+		t.Errorf("Expected the import's provenance and content hash, got %+v, %q", stored.Provenance, stored.ContentHash)
+	}
+	if len(stored.Sections) != 1 || stored.FullText == "" || len(stored.Endnotes) != 1 || stored.Endnotes[0].Key != "ch1-n1" {
+		t.Errorf("Expected sections, full text, and keyed endnotes to be derived, got %+v", stored)
+	}
+
+	// The same content again returns the stored document
+	again, err := ImportDocument(ctx, store, &models.ParsedItem{}, payload, log)
+	if err != nil {
+		t.Fatalf("ImportDocument failed: %v", err)
+	}
+	if again.DocumentID != result.DocumentID || !again.AlreadyImported {
+		t.Errorf("Expected the stored document, got %+v", again)
+	}
+
+	// A citekey already in use is refused
+	_, err = ImportDocument(ctx, store, &models.ParsedItem{
+		Metadata: models.ItemMetadata{Title: "Other Notes", Citekey: "jones1987"},
+		Pages:    []string{"Other text"},
+	}, []byte("Other text"), log)
+	var importErr *documents.ImportError
+	var coded *models.CodedError
+	if !errors.As(err, &importErr) || importErr.Problems[0].Field != "metadata.citekey" || !errors.As(err, &coded) || coded.Code != models.ErrorInvalidInput {
+		t.Errorf("Expected the citekey to be refused, got %v", err)
+	}
+
+	// A work already stored is returned in place of the import
+	duplicate, err := ImportDocument(ctx, store, &models.ParsedItem{
+		Metadata: models.ItemMetadata{Title: "Memory and the Archive", DOI: "10.1000/JMS.2020.1"},
+		Pages:    []string{"Page one"},
+	}, []byte("Page one"), log)
+	if err != nil {
+		t.Fatalf("ImportDocument failed: %v", err)
+	}
+	if duplicate.DocumentID != "url_1" || duplicate.Duplicate == nil || duplicate.Duplicate.MatchedOn != MatchDOI {
+		t.Errorf("Expected the stored duplicate, got %+v", duplicate)
+	}
+	if exists, _ := store.DocumentExists(ctx, storage.GenerateDocumentID(&models.SourceInfo{}, models.DocumentData{Data: []byte("Page one")})); exists {
+		t.Error("Expected the duplicate not to be stored")
+	}
+}
+
+func TestImportDocument_Libraries(t *testing.T) {
+	store := newDuplicateTestStore(t)
+	log := logger.NewNoOpLogger()
+
+	payload := []byte("# Memory and the Archive\n\nPage one")
+	newItem := func() *models.ParsedItem {
+		return &models.ParsedItem{
+			Metadata: models.ItemMetadata{Title: "Memory and the Archive", DOI: "10.1000/JMS.2020.1", Citekey: "smith2020"},
+			Pages:    []string{"Page one"},
+		}
+	}
+
+	// The default library already holds this work, with the same citekey
+	if err := store.SetCitekey(context.Background(), "url_1", "smith2020"); err != nil {
+		t.Fatalf("SetCitekey failed: %v", err)
+	}
+
+	// In another library, neither the citekey nor the DOI collides
+	ctx := storage.NewLibraryContext(context.Background(), "thesis")
+	result, err := ImportDocument(ctx, store, newItem(), payload, log)
+	if err != nil {
+		t.Fatalf("ImportDocument failed: %v", err)
+	}
+	if !strings.HasPrefix(result.DocumentID, "thesis:data_") || result.Duplicate != nil {
+		t.Errorf("Expected a new document in the thesis library, got %+v", result)
+	}
+	if docID, err := store.GetDocumentByCitekey(ctx, "thesis", "smith2020"); err != nil || docID != result.DocumentID {
+		t.Errorf("Expected smith2020 to resolve to %s in the thesis library, got %s, %v", result.DocumentID, docID, err)
+	}
+	if docID, err := store.GetDocumentByCitekey(ctx, storage.DefaultLibrary, "smith2020"); err != nil || docID != "url_1" {
+		t.Errorf("Expected smith2020 to still resolve to url_1 in the default library, got %s, %v", docID, err)
+	}
+
+	// Within the library, the citekey is now taken
+	_, err = ImportDocument(ctx, store, &models.ParsedItem{
+		Metadata: models.ItemMetadata{Title: "Other Notes", Citekey: "smith2020"},
+		Pages:    []string{"Other text"},
+	}, []byte("Other text"), log)
+	var importErr *documents.ImportError
+	if !errors.As(err, &importErr) || importErr.Problems[0].Field != "metadata.citekey" {
+		t.Errorf("Expected the citekey to be refused within the library, got %v", err)
+	}
+
+	// The same work in the default library is a duplicate of its copy there
+	item := newItem()
+	item.Metadata.Citekey = ""
+	duplicate, err := ImportDocument(context.Background(), store, item, payload, log)
+	if err != nil {
+		t.Fatalf("ImportDocument failed: %v", err)
+	}
+	if duplicate.DocumentID != "url_1" || duplicate.Duplicate == nil {
+		t.Errorf("Expected the default library's copy, got %+v", duplicate)
+	}
+}
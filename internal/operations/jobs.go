@@ -0,0 +1,266 @@
+package operations
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/config"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// jobWorkersEnv names the environment variable that sets how many job items are
+// parsed at once
+const jobWorkersEnv = "ACADEMIC_MCP_JOB_WORKERS"
+
+// defaultJobWorkers is how many job items are parsed at once by default. Each
+// document's pages are still parsed in parallel under the OpenAI rate limiter.
+const defaultJobWorkers = 2
+
+// JobWorkers returns how many job items are parsed at once, set by
+// ACADEMIC_MCP_JOB_WORKERS. An invalid value returns the default and an error.
+func JobWorkers() (int, error) {
+	value := strings.TrimSpace(os.Getenv(jobWorkersEnv))
+	if value == "" {
+		return defaultJobWorkers, nil
+	}
+	workers, err := strconv.Atoi(value)
+	if err != nil || workers < 1 {
+		return defaultJobWorkers, fmt.Errorf("invalid %s %q (expected a positive number)", jobWorkersEnv, value)
+	}
+	return workers, nil
+}
+
+// jobItemKey identifies a job item
+type jobItemKey struct {
+	jobID string
+	index int
+}
+
+// JobRunner parses the documents of queued jobs in the background. Workers start
+// when items are queued and stop when none are left, so an idle runner has no
+// goroutines. Jobs are kept in the store, so items queued or interrupted by a
+// previous process are picked up again by Resume.
+type JobRunner struct {
+	store   storage.Store
+	log     logger.Logger
+	workers int
+	// parse parses one item's document, returning its document ID
+	parse func(ctx context.Context, item *models.JobItem) (string, error)
+	// onParsed, if set, is called with the document ID of each item parsed
+	onParsed func(docID string)
+	// credentials, if set, are the credentials items are parsed with
+	credentials *config.Provider
+
+	mu      sync.Mutex
+	active  int                               // Running worker goroutines
+	queued  bool                              // Items were queued since a worker last found none
+	running map[jobItemKey]context.CancelFunc // Cancels each item being parsed
+	idle    chan struct{}                     // Closed when the last worker stops
+}
+
+// NewJobRunner returns a runner that parses job items with GetOrParseDocumentWithDuplicates,
+// using ACADEMIC_MCP_JOB_WORKERS workers
+func NewJobRunner(store storage.Store, log logger.Logger) *JobRunner {
+	workers, err := JobWorkers()
+	if err != nil {
+		log.Warn("Using default job workers: %v", err)
+	}
+	r := &JobRunner{store: store, log: log, workers: workers, running: make(map[jobItemKey]context.CancelFunc)}
+	r.parse = func(ctx context.Context, item *models.JobItem) (string, error) {
+		docID, _, _, err := GetOrParseDocumentWithDuplicates(ctx, item.ZoteroID, item.URL, item.RawData, item.DocType, item.Library, models.PageRange{}, item.LinkDuplicates, ParseModeFull, store, logger.FromContext(ctx, log))
+		return docID, err
+	}
+	return r
+}
+
+// OnParsed sets fn to be called with the document ID of each item parsed, once
+// its outcome is recorded. Set it before jobs are queued or resumed.
+func (r *JobRunner) OnParsed(fn func(docID string)) {
+	r.onParsed = fn
+}
+
+// UseCredentials sets the credentials items are parsed with, in place of those
+// in the environment. Set it before jobs are queued or resumed.
+func (r *JobRunner) UseCredentials(credentials *config.Provider) {
+	r.credentials = credentials
+}
+
+// Resume requeues items a previous process left running and starts workers for
+// any pending items
+func (r *JobRunner) Resume(ctx context.Context) error {
+	requeued, err := r.store.RequeueRunningJobItems(ctx)
+	if err != nil {
+		return err
+	}
+	if requeued > 0 {
+		r.log.Info("Requeued %d interrupted job items", requeued)
+	}
+	r.wake()
+	return nil
+}
+
+// Submit stores a job for items, which only need their source fields set, and
+// starts parsing them in the background. It returns the job with its new ID.
+func (r *JobRunner) Submit(ctx context.Context, items []models.JobItem, linkDuplicates bool) (*models.Job, error) {
+	if len(items) == 0 {
+		return nil, models.WithErrorCode(models.ErrorInvalidInput, errors.New("a job needs at least one document"))
+	}
+	id, err := newJobID()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.store.CreateJob(ctx, &models.Job{ID: id, LinkDuplicates: linkDuplicates, Items: items}); err != nil {
+		return nil, models.WithErrorCode(models.ErrorStorage, err)
+	}
+	r.log.Info("Queued job %s with %d documents", id, len(items))
+	r.wake()
+	return r.store.GetJob(ctx, id)
+}
+
+// Cancel cancels a job's pending items and stops its items being parsed, which
+// are then recorded as cancelled. Items already finished keep their results.
+func (r *JobRunner) Cancel(ctx context.Context, jobID string) (*models.Job, error) {
+	cancelled, err := r.store.CancelJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	for key, cancel := range r.running {
+		if key.jobID == jobID {
+			cancel()
+			cancelled++
+		}
+	}
+	r.mu.Unlock()
+	r.log.Info("Cancelled %d items of job %s", cancelled, jobID)
+	return r.store.GetJob(ctx, jobID)
+}
+
+// Wait blocks until no worker is running or ctx is done. Tests use it to wait
+// for queued jobs to finish.
+func (r *JobRunner) Wait(ctx context.Context) error {
+	r.mu.Lock()
+	idle := r.idle
+	r.mu.Unlock()
+	if idle == nil {
+		return nil
+	}
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// wake starts workers up to the limit and makes sure a running worker looks for
+// newly queued items before stopping
+func (r *JobRunner) wake() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.queued = true
+	if r.active == 0 {
+		r.idle = make(chan struct{})
+	}
+	for r.active < r.workers {
+		r.active++
+		go r.work()
+	}
+}
+
+// work parses claimed items until none is pending
+func (r *JobRunner) work() {
+	for {
+		item, err := r.store.ClaimJobItem(context.Background())
+		if err != nil {
+			r.log.Error("Failed to claim job item: %v", err)
+		}
+		if item != nil {
+			r.process(item)
+			continue
+		}
+
+		r.mu.Lock()
+		if r.queued && err == nil {
+			r.queued = false
+			r.mu.Unlock()
+			continue
+		}
+		r.active--
+		if r.active == 0 {
+			close(r.idle)
+			r.idle = nil
+		}
+		r.mu.Unlock()
+		return
+	}
+}
+
+// process parses one item and records its outcome
+func (r *JobRunner) process(item *models.JobItem) {
+	key := jobItemKey{item.JobID, item.Index}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctx = logger.NewContext(ctx, r.log.With("job_id", item.JobID, "job_item", item.Index))
+	if r.credentials != nil {
+		ctx = config.NewContext(ctx, r.credentials)
+	}
+	r.mu.Lock()
+	r.running[key] = cancel
+	r.mu.Unlock()
+
+	r.log.Info("Parsing job %s item %d", item.JobID, item.Index)
+	docID, err := r.parse(ctx, item)
+
+	r.mu.Lock()
+	delete(r.running, key)
+	r.mu.Unlock()
+
+	switch {
+	case err == nil:
+		r.log.Info("Job %s item %d parsed as %s", item.JobID, item.Index, docID)
+		item.Status, item.DocumentID = models.JobDone, docID
+	case ctx.Err() != nil:
+		item.Status, item.Error, item.ErrorCode = models.JobCancelled, "cancelled while parsing", models.ErrorCancelled
+	default:
+		r.log.Error("Job %s item %d failed: %v", item.JobID, item.Index, err)
+		item.Status, item.Error, item.ErrorCode = models.JobFailed, err.Error(), jobErrorCode(err)
+	}
+	if err := r.store.FinishJobItem(context.Background(), item); err != nil {
+		r.log.Error("Failed to record the outcome of job %s item %d: %v", item.JobID, item.Index, err)
+	}
+	if item.Status == models.JobDone && r.onParsed != nil {
+		r.onParsed(docID)
+	}
+}
+
+// jobErrorCode classifies a failed item as tools classify call errors
+func jobErrorCode(err error) models.ErrorCode {
+	var coded *models.CodedError
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return models.ErrorNotFound
+	case errors.As(err, &coded):
+		return coded.Code
+	default:
+		return models.ErrorInternal
+	}
+}
+
+// newJobID returns a random job ID such as job_1f2e3d4c5b6a7980
+func newJobID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate job ID: %w", err)
+	}
+	return "job_" + hex.EncodeToString(b), nil
+}
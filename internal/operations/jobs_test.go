@@ -0,0 +1,193 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// fakeParse parses an item by returning "doc-" plus its URL, failing for URLs
+// starting with "fail" and blocking on URLs starting with "block" until ctx is done
+func fakeParse(ctx context.Context, item *models.JobItem) (string, error) {
+	switch {
+	case strings.HasPrefix(item.URL, "fail"):
+		return "", models.WithErrorCode(models.ErrorUpstreamFetch, errors.New("download failed"))
+	case strings.HasPrefix(item.URL, "block"):
+		<-ctx.Done()
+		return "", ctx.Err()
+	}
+	return "doc-" + item.URL, nil
+}
+
+func newTestJobRunner(t *testing.T, store storage.Store) *JobRunner {
+	t.Helper()
+	runner := NewJobRunner(store, logger.NewNoOpLogger())
+	runner.parse = fakeParse
+	return runner
+}
+
+func waitForRunner(t *testing.T, runner *JobRunner) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := runner.Wait(ctx); err != nil {
+		t.Fatalf("Jobs did not finish: %v", err)
+	}
+}
+
+func TestJobRunner_Submit(t *testing.T) {
+	store, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "jobs.db"), logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+	runner := newTestJobRunner(t, store)
+	ctx := context.Background()
+	var mu sync.Mutex
+	var parsed []string
+	runner.OnParsed(func(docID string) {
+		mu.Lock()
+		parsed = append(parsed, docID)
+		mu.Unlock()
+	})
+
+	var items []models.JobItem
+	for _, url := range []string{"a", "fail-b", "c", "d", "e"} {
+		items = append(items, models.JobItem{URL: url})
+	}
+	job, err := runner.Submit(ctx, items, true)
+	if err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if job.ID == "" || len(job.Items) != 5 {
+		t.Fatalf("Expected a job with 5 items, got %+v", job)
+	}
+	waitForRunner(t, runner)
+
+	job, err = store.GetJob(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("GetJob failed: %v", err)
+	}
+	if job.Status != models.JobCompleted || job.Counts["done"] != 4 || job.Counts["failed"] != 1 {
+		t.Errorf("Expected a completed job with 4 done and 1 failed, got %+v", job)
+	}
+	if job.Items[0].DocumentID != "doc-a" || job.Items[1].ErrorCode != models.ErrorUpstreamFetch {
+		t.Errorf("Expected document IDs and error codes per item, got %+v", job.Items)
+	}
+	slices.Sort(parsed)
+	if !slices.Equal(parsed, []string{"doc-a", "doc-c", "doc-d", "doc-e"}) {
+		t.Errorf("Expected OnParsed for each parsed document, got %v", parsed)
+	}
+
+	if _, err := runner.Submit(ctx, nil, true); err == nil {
+		t.Error("Expected an error for a job without documents")
+	}
+}
+
+func TestJobRunner_Cancel(t *testing.T) {
+	store, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "jobs.db"), logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+	runner := newTestJobRunner(t, store)
+	runner.workers = 1
+
+	// The only worker blocks on the first item until the job is cancelled
+	started := make(chan struct{})
+	var once sync.Once
+	runner.parse = func(ctx context.Context, item *models.JobItem) (string, error) {
+		once.Do(func() { close(started) })
+		return fakeParse(ctx, item)
+	}
+	ctx := context.Background()
+	job, err := runner.Submit(ctx, []models.JobItem{{URL: "block"}, {URL: "b"}, {URL: "c"}}, true)
+	if err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	<-started
+
+	if _, err := runner.Cancel(ctx, job.ID); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	waitForRunner(t, runner)
+
+	job, err = store.GetJob(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("GetJob failed: %v", err)
+	}
+	if job.Status != models.JobCompleted || job.Counts["cancelled"] != 3 {
+		t.Errorf("Expected every item cancelled, got %+v", job)
+	}
+	if _, err := runner.Cancel(ctx, "missing"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound cancelling a missing job, got %v", err)
+	}
+}
+
+func TestJobRunner_Resume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.db")
+	store, err := storage.NewSQLiteStore(path, logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	ctx := context.Background()
+
+	// A previous process queued a job and stopped while parsing its first item
+	if err := store.CreateJob(ctx, &models.Job{ID: "job-1", Items: []models.JobItem{{URL: "a"}, {URL: "b"}}}); err != nil {
+		t.Fatalf("CreateJob failed: %v", err)
+	}
+	if _, err := store.ClaimJobItem(ctx); err != nil {
+		t.Fatalf("ClaimJobItem failed: %v", err)
+	}
+	store.Close()
+
+	store, err = storage.NewSQLiteStore(path, logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	defer store.Close()
+	runner := newTestJobRunner(t, store)
+	if err := runner.Resume(ctx); err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+	waitForRunner(t, runner)
+
+	job, err := store.GetJob(ctx, "job-1")
+	if err != nil {
+		t.Fatalf("GetJob failed: %v", err)
+	}
+	if job.Status != models.JobCompleted || job.Items[0].DocumentID != "doc-a" || job.Items[1].DocumentID != "doc-b" {
+		t.Errorf("Expected both items parsed after resuming, got %+v", job)
+	}
+}
+
+func TestJobWorkers(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected int
+		wantErr  bool
+	}{
+		{"", defaultJobWorkers, false},
+		{"4", 4, false},
+		{"0", defaultJobWorkers, true},
+		{"many", defaultJobWorkers, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv(jobWorkersEnv, tt.value)
+			workers, err := JobWorkers()
+			if workers != tt.expected || (err != nil) != tt.wantErr {
+				t.Errorf("Expected %d (error %v), got %d (%v)", tt.expected, tt.wantErr, workers, err)
+			}
+		})
+	}
+}
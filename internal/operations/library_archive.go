@@ -0,0 +1,314 @@
+package operations
+
+import (
+	"bufio"
+	"cmp"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// LibraryArchiveFormat names the format of library archives, recorded in their
+// header so other gzipped JSON files are not taken for one
+const LibraryArchiveFormat = "academic-mcp-library"
+
+// LibraryArchiveVersion is the version of the library archive format written
+// by ExportLibrary; ImportLibrary reads archives up to it
+const LibraryArchiveVersion = 1
+
+// Ways ImportLibrary handles a document that conflicts with the library
+const (
+	ConflictSkip      = "skip"      // Keep the library's document and skip the archived one
+	ConflictOverwrite = "overwrite" // Replace the library's document with the same ID
+	ConflictRename    = "rename"    // Give the archived document a new citekey if its own is taken
+)
+
+// libraryArchiveHeader is the first line of a library archive
+type libraryArchiveHeader struct {
+	Format     string `json:"format"`
+	Version    int    `json:"version"`
+	ExportedAt string `json:"exported_at"`
+	Documents  int    `json:"documents"`
+}
+
+// libraryRecord is a line of a library archive after the header: one stored
+// document with everything kept alongside it
+type libraryRecord struct {
+	DocumentID  string              `json:"document_id"`
+	Item        *models.ParsedItem  `json:"item"`
+	Source      *models.SourceInfo  `json:"source,omitempty"`
+	Summaries   map[string]string   `json:"summaries,omitempty"` // Keyed by style, including the standard summary
+	Annotations []models.Annotation `json:"annotations,omitempty"`
+	ImageData   []archivedImage     `json:"image_data,omitempty"`
+}
+
+// archivedImage is the data of one of a document's images, which the image
+// itself does not carry in JSON
+type archivedImage struct {
+	Index int    `json:"index"`
+	Data  []byte `json:"data"`
+}
+
+// ExportLibrary writes every stored document to w as a library archive: gzipped
+// JSON lines, a header followed by one document per line with its metadata,
+// content, summaries in every style, annotations, source, and image data.
+// Documents are read and written one at a time, oldest first, so the library is
+// never held in memory. It returns how many documents were written.
+func ExportLibrary(ctx context.Context, store storage.Store, w io.Writer, log logger.Logger) (int, error) {
+	docs, err := store.ListDocuments(ctx, "")
+	if err != nil {
+		return 0, models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to list documents: %w", err))
+	}
+
+	gz := gzip.NewWriter(w)
+	encoder := json.NewEncoder(gz)
+	encoder.SetEscapeHTML(false)
+	header := libraryArchiveHeader{
+		Format:     LibraryArchiveFormat,
+		Version:    LibraryArchiveVersion,
+		ExportedAt: time.Now().UTC().Format(time.RFC3339),
+		Documents:  len(docs),
+	}
+	if err := encoder.Encode(header); err != nil {
+		return 0, fmt.Errorf("failed to write archive header: %w", err)
+	}
+
+	// Documents are written oldest first, so importing them keeps their order
+	slices.SortStableFunc(docs, func(a, b models.DocumentInfo) int {
+		return cmp.Or(cmp.Compare(a.Provenance.CreatedAt, b.Provenance.CreatedAt), cmp.Compare(a.DocumentID, b.DocumentID))
+	})
+	written := 0
+	for _, doc := range docs {
+		if err := ctx.Err(); err != nil {
+			return written, models.WithErrorCode(models.ErrorCancelled, err)
+		}
+		record, err := readLibraryRecord(ctx, store, doc.DocumentID)
+		if err != nil {
+			log.Error("Failed to read document %s for export: %v", doc.DocumentID, err)
+			return written, models.WithErrorCode(models.ErrorStorage, err)
+		}
+		if err := encoder.Encode(record); err != nil {
+			return written, fmt.Errorf("failed to write document %s: %w", doc.DocumentID, err)
+		}
+		written++
+	}
+	if err := gz.Close(); err != nil {
+		return written, fmt.Errorf("failed to finish archive: %w", err)
+	}
+	log.Info("Exported %d documents", written)
+	return written, nil
+}
+
+// readLibraryRecord reads a stored document and what is kept alongside it
+func readLibraryRecord(ctx context.Context, store storage.Store, docID string) (*libraryRecord, error) {
+	item, err := store.GetParsedItem(ctx, docID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve document %s: %w", docID, err)
+	}
+	record := &libraryRecord{DocumentID: docID, Item: item}
+	if record.Source, err = store.GetSourceInfo(ctx, docID); err != nil {
+		return nil, fmt.Errorf("failed to retrieve source of document %s: %w", docID, err)
+	}
+	if record.Summaries, err = store.GetSummaries(ctx, docID); err != nil {
+		return nil, fmt.Errorf("failed to retrieve summaries of document %s: %w", docID, err)
+	}
+	if record.Annotations, err = store.GetAnnotations(ctx, docID); err != nil {
+		return nil, fmt.Errorf("failed to retrieve annotations of document %s: %w", docID, err)
+	}
+	for i, img := range item.Images {
+		if img.MIMEType == "" {
+			continue
+		}
+		data, _, err := store.GetImageData(ctx, docID, i)
+		if errors.Is(err, storage.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve image %d of document %s: %w", i, docID, err)
+		}
+		record.ImageData = append(record.ImageData, archivedImage{Index: i, Data: data})
+	}
+	return record, nil
+}
+
+// LibraryImportResult describes the outcome of importing a library archive
+type LibraryImportResult struct {
+	Imported    []string          // IDs of the documents stored
+	Overwritten []string          // IDs of imported documents that replaced one in the library
+	Renamed     map[string]string // New citekeys given to imported documents, by document ID
+	Skipped     []SkippedDocument // Archived documents not imported
+}
+
+// SkippedDocument is an archived document ImportLibrary did not import
+type SkippedDocument struct {
+	DocumentID string
+	Citekey    string
+	Reason     string
+}
+
+// ImportLibrary loads a library archive written by ExportLibrary into store,
+// reading one document at a time. A document conflicts with the library if one
+// with its ID is stored, or its citekey belongs to another document; onConflict
+// ("skip", the default, "overwrite", or "rename") decides what happens. With
+// overwrite, a document with the same ID is replaced by the archived one; with
+// rename, an archived document whose citekey is taken is given a new one. A
+// conflict neither option resolves skips the document. Annotations get new IDs
+// and timestamps, and the documents their dates of import.
+func ImportLibrary(ctx context.Context, store storage.Store, r io.Reader, onConflict string, log logger.Logger) (*LibraryImportResult, error) {
+	if onConflict == "" {
+		onConflict = ConflictSkip
+	}
+	if onConflict != ConflictSkip && onConflict != ConflictOverwrite && onConflict != ConflictRename {
+		return nil, models.WithErrorCode(models.ErrorInvalidInput,
+			fmt.Errorf("unsupported on_conflict: %s (supported: 'skip', 'overwrite', 'rename')", onConflict))
+	}
+
+	gz, err := gzip.NewReader(bufio.NewReader(r))
+	if err != nil {
+		return nil, models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("not a library archive: %w", err))
+	}
+	defer gz.Close()
+	decoder := json.NewDecoder(gz)
+
+	var header libraryArchiveHeader
+	if err := decoder.Decode(&header); err != nil || header.Format != LibraryArchiveFormat {
+		return nil, models.WithErrorCode(models.ErrorInvalidInput, errors.New("not a library archive: missing archive header"))
+	}
+	if header.Version < 1 || header.Version > LibraryArchiveVersion {
+		return nil, models.WithErrorCode(models.ErrorInvalidInput,
+			fmt.Errorf("unsupported library archive version %d (supported up to %d)", header.Version, LibraryArchiveVersion))
+	}
+
+	citekeyMap, err := store.GetCitekeyMap(ctx, "")
+	if err != nil {
+		return nil, models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to retrieve existing citekeys: %w", err))
+	}
+	owners := make(map[string]map[string]string)
+	for docID, citekey := range citekeyMap {
+		library, _ := storage.SplitLibraryDocumentID(docID)
+		if owners[library] == nil {
+			owners[library] = make(map[string]string)
+		}
+		owners[library][citekey] = docID
+	}
+
+	result := &LibraryImportResult{Renamed: make(map[string]string)}
+	for line := 2; ; line++ {
+		if err := ctx.Err(); err != nil {
+			return result, models.WithErrorCode(models.ErrorCancelled, err)
+		}
+		var record libraryRecord
+		err := decoder.Decode(&record)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return result, models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("invalid archive record on line %d: %w", line, err))
+		}
+		if record.DocumentID == "" || record.Item == nil {
+			return result, models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("invalid archive record on line %d: missing document", line))
+		}
+		if err := importLibraryRecord(ctx, store, &record, onConflict, owners, result, log); err != nil {
+			return result, err
+		}
+	}
+
+	log.Info("Imported %d documents from library archive (%d overwritten, %d renamed, %d skipped)",
+		len(result.Imported), len(result.Overwritten), len(result.Renamed), len(result.Skipped))
+	return result, nil
+}
+
+// importLibraryRecord stores one archived document, resolving its conflicts
+// with the library by onConflict. owners maps the citekeys in use in each
+// library to their documents and is updated with the stored document's.
+func importLibraryRecord(ctx context.Context, store storage.Store, record *libraryRecord, onConflict string, owners map[string]map[string]string, result *LibraryImportResult, log logger.Logger) error {
+	docID, item := record.DocumentID, record.Item
+	skip := func(reason string) {
+		log.Info("Skipping archived document %s: %s", docID, reason)
+		result.Skipped = append(result.Skipped, SkippedDocument{DocumentID: docID, Citekey: item.Metadata.Citekey, Reason: reason})
+	}
+
+	exists, err := store.DocumentExists(ctx, docID)
+	if err != nil {
+		return models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to check document existence: %w", err))
+	}
+	if exists && onConflict != ConflictOverwrite {
+		skip("a document with the same ID is in the library")
+		return nil
+	}
+
+	// Citekeys only need to be unique within the document's library
+	library, _ := storage.SplitLibraryDocumentID(docID)
+	citekeyOwners := owners[library]
+	if citekeyOwners == nil {
+		citekeyOwners = make(map[string]string)
+		owners[library] = citekeyOwners
+	}
+	citekey := item.Metadata.Citekey
+	if owner, taken := citekeyOwners[citekey]; citekey != "" && taken && owner != docID {
+		if onConflict != ConflictRename {
+			skip(fmt.Sprintf("citekey %q is used by document %s", citekey, owner))
+			return nil
+		}
+		existing := make(map[string]bool, len(citekeyOwners))
+		for key := range citekeyOwners {
+			existing[key] = true
+		}
+		item.Metadata.Citekey = generateCitekey(&item.Metadata, existing, log)
+		result.Renamed[docID] = item.Metadata.Citekey
+		log.Info("Renamed citekey of archived document %s from %s to %s", docID, citekey, item.Metadata.Citekey)
+	}
+
+	// The archived document replaces the stored one entirely, so annotations and
+	// summaries in other styles are not left over from it
+	if exists {
+		if err := store.DeleteDocument(ctx, docID); err != nil {
+			return models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to replace document %s: %w", docID, err))
+		}
+		for key, owner := range citekeyOwners {
+			if owner == docID {
+				delete(citekeyOwners, key)
+			}
+		}
+		result.Overwritten = append(result.Overwritten, docID)
+	}
+
+	for _, img := range record.ImageData {
+		if img.Index >= 0 && img.Index < len(item.Images) {
+			item.Images[img.Index].Data = img.Data
+		}
+	}
+	source := record.Source
+	if source == nil {
+		source = &models.SourceInfo{}
+	}
+	if err := store.StoreParsedItem(ctx, docID, item, source); err != nil {
+		return models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to store document %s: %w", docID, err))
+	}
+	for style, summary := range record.Summaries {
+		if err := store.StoreSummary(ctx, docID, style, summary); err != nil {
+			return models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to store %s summary of document %s: %w", style, docID, err))
+		}
+	}
+	for _, annotation := range record.Annotations {
+		annotation.DocumentID = docID
+		if _, err := store.AddAnnotation(ctx, annotation); err != nil {
+			return models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to store annotation of document %s: %w", docID, err))
+		}
+	}
+
+	if item.Metadata.Citekey != "" {
+		citekeyOwners[item.Metadata.Citekey] = docID
+	}
+	result.Imported = append(result.Imported, docID)
+	return nil
+}
@@ -0,0 +1,286 @@
+package operations
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// newArchiveTestStore returns an in-memory store holding count synthetic
+// documents, doc_1 through doc_count, each with a summary in two styles, an
+// annotation, and an image with data
+func newArchiveTestStore(t *testing.T, count int) storage.Store {
+	t.Helper()
+	store, err := storage.NewSQLiteStore(":memory:", logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	ctx := context.Background()
+	for n := 1; n <= count; n++ {
+		docID := fmt.Sprintf("doc_%d", n)
+		item := &models.ParsedItem{
+			Metadata: models.ItemMetadata{
+				Title:           fmt.Sprintf("Paper %d", n),
+				Authors:         []string{"Jane Smith"},
+				PublicationDate: fmt.Sprint(2000 + n),
+				Citekey:         fmt.Sprintf("smith%d", 2000+n),
+				Tags:            []string{"archive"},
+			},
+			Pages:       []string{fmt.Sprintf("# Paper %d\n\nText of paper %d.[1]", n, n)},
+			PageNumbers: []string{"1"},
+			References:  []models.Reference{{ReferenceText: "Derrida, J. (1995). Archive Fever."}},
+			Endnotes:    []models.Endnote{{Marker: "1", Key: "ch1-n1", Text: "A note.", PageNumber: "1"}},
+			Quotations:  []models.Quotation{{QuotationText: fmt.Sprintf("Text of paper %d.", n), PageNumber: "1"}},
+			Images:      []models.Image{{Caption: "Figure 1", MIMEType: "image/png", Data: []byte{0x89, 'P', 'N', 'G', byte(n)}}},
+			Summary:     fmt.Sprintf("Summary of paper %d.", n),
+		}
+		if err := store.StoreParsedItem(ctx, docID, item, &models.SourceInfo{URL: "https://example.com/" + docID}); err != nil {
+			t.Fatalf("Failed to store document: %v", err)
+		}
+		if err := store.StoreSummary(ctx, docID, models.SummaryStyleBrief, "Brief."); err != nil {
+			t.Fatalf("Failed to store summary: %v", err)
+		}
+		if _, err := store.AddAnnotation(ctx, models.Annotation{DocumentID: docID, PageNumber: "1", Text: "Check this."}); err != nil {
+			t.Fatalf("Failed to store annotation: %v", err)
+		}
+	}
+	return store
+}
+
+// exportTestLibrary exports store and returns the archive
+func exportTestLibrary(t *testing.T, store storage.Store) []byte {
+	t.Helper()
+	var archive bytes.Buffer
+	if _, err := ExportLibrary(context.Background(), store, &archive, logger.NewNoOpLogger()); err != nil {
+		t.Fatalf("ExportLibrary failed: %v", err)
+	}
+	return archive.Bytes()
+}
+
+func TestExportLibrary(t *testing.T) {
+	store := newArchiveTestStore(t, 3)
+	var archive bytes.Buffer
+	count, err := ExportLibrary(context.Background(), store, &archive, logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("ExportLibrary failed: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 documents exported, got %d", count)
+	}
+
+	// A header line, then one document per line
+	gz, err := gzip.NewReader(&archive)
+	if err != nil {
+		t.Fatalf("Expected a gzipped archive: %v", err)
+	}
+	var lines []string
+	var data bytes.Buffer
+	if _, err := data.ReadFrom(gz); err != nil {
+		t.Fatalf("Failed to read archive: %v", err)
+	}
+	lines = strings.Split(strings.TrimSuffix(data.String(), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected 4 lines, got %d", len(lines))
+	}
+	var header libraryArchiveHeader
+	if err := json.Unmarshal([]byte(lines[0]), &header); err != nil || header.Format != LibraryArchiveFormat || header.Version != LibraryArchiveVersion || header.Documents != 3 {
+		t.Errorf("Unexpected header %s: %v", lines[0], err)
+	}
+	var first libraryRecord
+	if err := json.Unmarshal([]byte(lines[1]), &first); err != nil {
+		t.Fatalf("Failed to decode record: %v", err)
+	}
+	if first.DocumentID != "doc_1" || first.Item.Metadata.Citekey != "smith2001" || len(first.ImageData) != 1 {
+		t.Errorf("Expected the oldest document first with its image data, got %+v", first)
+	}
+}
+
+func TestImportLibrary_RoundTrip(t *testing.T) {
+	source := newArchiveTestStore(t, 3)
+	archive := exportTestLibrary(t, source)
+
+	target := newArchiveTestStore(t, 0)
+	ctx := context.Background()
+	result, err := ImportLibrary(ctx, target, bytes.NewReader(archive), "", logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("ImportLibrary failed: %v", err)
+	}
+	if !slices.Equal(result.Imported, []string{"doc_1", "doc_2", "doc_3"}) || len(result.Skipped) != 0 {
+		t.Fatalf("Expected every document imported, got %+v", result)
+	}
+
+	for _, docID := range result.Imported {
+		want, err := source.GetParsedItem(ctx, docID)
+		if err != nil {
+			t.Fatalf("GetParsedItem failed: %v", err)
+		}
+		got, err := target.GetParsedItem(ctx, docID)
+		if err != nil {
+			t.Fatalf("GetParsedItem failed: %v", err)
+		}
+		// Only the dates of storage differ
+		want.Provenance.CreatedAt, want.Provenance.UpdatedAt = "", ""
+		got.Provenance.CreatedAt, got.Provenance.UpdatedAt = "", ""
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Document %s changed in the round trip:\n got %+v\nwant %+v", docID, got, want)
+		}
+
+		wantSummaries, _ := source.GetSummaries(ctx, docID)
+		gotSummaries, err := target.GetSummaries(ctx, docID)
+		if err != nil || !reflect.DeepEqual(gotSummaries, wantSummaries) {
+			t.Errorf("Expected summaries %v, got %v, %v", wantSummaries, gotSummaries, err)
+		}
+		annotations, err := target.GetAnnotations(ctx, docID)
+		if err != nil || len(annotations) != 1 || annotations[0].Text != "Check this." || annotations[0].PageNumber != "1" {
+			t.Errorf("Expected the annotation, got %+v, %v", annotations, err)
+		}
+		wantData, _, _ := source.GetImageData(ctx, docID, 0)
+		gotData, mimeType, err := target.GetImageData(ctx, docID, 0)
+		if err != nil || !bytes.Equal(gotData, wantData) || mimeType != "image/png" {
+			t.Errorf("Expected the image data, got %v %q, %v", gotData, mimeType, err)
+		}
+		sourceInfo, err := target.GetSourceInfo(ctx, docID)
+		if err != nil || sourceInfo.URL != "https://example.com/"+docID {
+			t.Errorf("Expected the source, got %+v, %v", sourceInfo, err)
+		}
+	}
+}
+
+func TestImportLibrary_Conflicts(t *testing.T) {
+	archive := exportTestLibrary(t, newArchiveTestStore(t, 2))
+	ctx := context.Background()
+	log := logger.NewNoOpLogger()
+
+	// A library holding doc_1 (changed) and another document using smith2002
+	newTarget := func(t *testing.T) storage.Store {
+		target := newArchiveTestStore(t, 0)
+		if err := target.StoreParsedItem(ctx, "doc_1", &models.ParsedItem{
+			Metadata: models.ItemMetadata{Title: "Local Paper", Citekey: "smith2001"},
+			Pages:    []string{"Local text"},
+		}, &models.SourceInfo{}); err != nil {
+			t.Fatalf("Failed to store document: %v", err)
+		}
+		if _, err := target.AddAnnotation(ctx, models.Annotation{DocumentID: "doc_1", Text: "Local note."}); err != nil {
+			t.Fatalf("Failed to store annotation: %v", err)
+		}
+		if err := target.StoreParsedItem(ctx, "other", &models.ParsedItem{
+			Metadata: models.ItemMetadata{Title: "Other Paper", Authors: []string{"Jane Smith"}, PublicationDate: "2002", Citekey: "smith2002"},
+			Pages:    []string{"Other text"},
+		}, &models.SourceInfo{}); err != nil {
+			t.Fatalf("Failed to store document: %v", err)
+		}
+		return target
+	}
+	title := func(t *testing.T, store storage.Store, docID string) string {
+		metadata, err := store.GetMetadata(ctx, docID)
+		if err != nil {
+			t.Fatalf("GetMetadata failed: %v", err)
+		}
+		return metadata.Title
+	}
+
+	t.Run("skip", func(t *testing.T) {
+		target := newTarget(t)
+		result, err := ImportLibrary(ctx, target, bytes.NewReader(archive), ConflictSkip, log)
+		if err != nil {
+			t.Fatalf("ImportLibrary failed: %v", err)
+		}
+		if len(result.Imported) != 0 || len(result.Skipped) != 2 {
+			t.Fatalf("Expected both documents skipped, got %+v", result)
+		}
+		if !strings.Contains(result.Skipped[0].Reason, "same ID") || !strings.Contains(result.Skipped[1].Reason, `"smith2002" is used by document other`) {
+			t.Errorf("Unexpected reasons: %+v", result.Skipped)
+		}
+		if title(t, target, "doc_1") != "Local Paper" {
+			t.Error("Expected the library's document kept")
+		}
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		target := newTarget(t)
+		result, err := ImportLibrary(ctx, target, bytes.NewReader(archive), ConflictOverwrite, log)
+		if err != nil {
+			t.Fatalf("ImportLibrary failed: %v", err)
+		}
+		if !slices.Equal(result.Imported, []string{"doc_1"}) || !slices.Equal(result.Overwritten, []string{"doc_1"}) || len(result.Skipped) != 1 {
+			t.Fatalf("Expected doc_1 overwritten and doc_2 skipped, got %+v", result)
+		}
+		if title(t, target, "doc_1") != "Paper 1" {
+			t.Error("Expected the archived document to replace the library's")
+		}
+		annotations, err := target.GetAnnotations(ctx, "doc_1")
+		if err != nil || len(annotations) != 1 || annotations[0].Text != "Check this." {
+			t.Errorf("Expected only the archived annotation, got %+v, %v", annotations, err)
+		}
+	})
+
+	t.Run("rename", func(t *testing.T) {
+		target := newTarget(t)
+		result, err := ImportLibrary(ctx, target, bytes.NewReader(archive), ConflictRename, log)
+		if err != nil {
+			t.Fatalf("ImportLibrary failed: %v", err)
+		}
+		if !slices.Equal(result.Imported, []string{"doc_2"}) || len(result.Skipped) != 1 || result.Skipped[0].DocumentID != "doc_1" {
+			t.Fatalf("Expected doc_2 imported and doc_1 skipped, got %+v", result)
+		}
+		if result.Renamed["doc_2"] != "smith2002a" {
+			t.Errorf("Expected doc_2 renamed to smith2002a, got %v", result.Renamed)
+		}
+		docID, err := target.GetDocumentByCitekey(ctx, "", "smith2002a")
+		if err != nil || docID != "doc_2" {
+			t.Errorf("Expected the new citekey stored, got %q, %v", docID, err)
+		}
+	})
+}
+
+func TestImportLibrary_InvalidArchive(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewNoOpLogger()
+	target := newArchiveTestStore(t, 0)
+
+	gzipped := func(content string) []byte {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		gz.Write([]byte(content))
+		gz.Close()
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name          string
+		archive       []byte
+		onConflict    string
+		expectedError string
+	}{
+		{"not gzipped", []byte(`{"format":"academic-mcp-library"}`), "", "not a library archive"},
+		{"no header", gzipped(`{"document_id":"doc_1"}` + "\n"), "", "missing archive header"},
+		{"newer version", gzipped(`{"format":"academic-mcp-library","version":99}` + "\n"), "", "unsupported library archive version"},
+		{"bad record", gzipped(`{"format":"academic-mcp-library","version":1}` + "\n{\n"), "", "line 2"},
+		{"unknown option", gzipped(""), "merge", "unsupported on_conflict"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ImportLibrary(ctx, target, bytes.NewReader(tt.archive), tt.onConflict, log)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Fatalf("Expected error containing %q, got %v", tt.expectedError, err)
+			}
+			var coded *models.CodedError
+			if !errors.As(err, &coded) || coded.Code != models.ErrorInvalidInput {
+				t.Errorf("Expected an invalid input error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// MetadataRefreshResult describes a document's metadata after it was merged
+// again with its Zotero metadata
+type MetadataRefreshResult struct {
+	DocumentID string
+	Metadata   *models.ItemMetadata // Metadata after the refresh
+	Changed    []string             // Fields whose value changed, in MetadataFieldNames order
+	OldCitekey string               // The citekey before the refresh, if it changed
+}
+
+// RefreshZoteroMetadata fetches the Zotero metadata of a document parsed from a
+// Zotero attachment again, retrying as a parse does, and merges it with the
+// metadata extracted from the document (see documents.RemergeMetadata) without
+// parsing it again. This repairs a document parsed while Zotero could not be
+// reached, and picks up corrections made in Zotero since. The item's tags
+// replace the stored ones. Unless keepCitekey is set, the citekey is generated
+// again from the new metadata, and changed if it no longer fits it.
+func RefreshZoteroMetadata(ctx context.Context, apiKey string, docID string, keepCitekey bool, store storage.Store, log logger.Logger) (*MetadataRefreshResult, error) {
+	source, err := store.GetSourceInfo(ctx, docID)
+	if err != nil {
+		return nil, models.WithErrorCode(models.ErrorStorage, err)
+	}
+	if source.ZoteroID == "" {
+		return nil, models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("document %s was not parsed from a Zotero attachment", docID))
+	}
+	library, err := documents.ResolveZoteroLibrary(ctx, source.ZoteroLibraryType, source.ZoteroLibraryID)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := store.GetMetadata(ctx, docID)
+	if err != nil {
+		return nil, models.WithErrorCode(models.ErrorStorage, err)
+	}
+
+	external, err := documents.FetchZoteroMetadataWithRetry(ctx, source.ZoteroID, apiKey, library)
+	if err != nil {
+		return nil, models.WithErrorCode(models.ErrorUpstreamZotero, fmt.Errorf("failed to fetch Zotero metadata: %w", err))
+	}
+	if external == nil {
+		return nil, models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("Zotero attachment %s has no parent item to take metadata from", source.ZoteroID))
+	}
+
+	metadata := documents.RemergeMetadata(external, stored)
+	result := &MetadataRefreshResult{DocumentID: docID, Metadata: metadata, Changed: documents.ChangedMetadataFields(stored, metadata)}
+
+	if !keepCitekey {
+		citekey, err := refreshedCitekey(ctx, store, docID, metadata, log)
+		if err != nil {
+			return nil, err
+		}
+		if citekey != metadata.Citekey {
+			if err := store.SetCitekey(ctx, docID, citekey); err != nil {
+				return nil, models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to store citekey: %w", err))
+			}
+			log.Info("Changed citekey of document %s from %s to %s", docID, metadata.Citekey, citekey)
+			result.OldCitekey, metadata.Citekey = metadata.Citekey, citekey
+		}
+	}
+
+	if err := store.UpdateMetadata(ctx, docID, metadata); err != nil {
+		return nil, models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to store metadata: %w", err))
+	}
+	if err := store.SetTags(ctx, docID, metadata.Tags); err != nil {
+		return nil, models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to store tags: %w", err))
+	}
+	log.Info("Refreshed Zotero metadata of document %s: %d fields changed", docID, len(result.Changed))
+	return result, nil
+}
+
+// refreshedCitekey returns the citekey a document should have with metadata:
+// its current one if generateCitekey could have given it for the metadata (the
+// base key, or the base key with a collision suffix), or a new one otherwise
+func refreshedCitekey(ctx context.Context, store storage.Store, docID string, metadata *models.ItemMetadata, log logger.Logger) (string, error) {
+	base := generateCitekey(metadata, nil, log)
+	if hasCitekeyBase(metadata.Citekey, base) {
+		return metadata.Citekey, nil
+	}
+	library, _ := storage.SplitLibraryDocumentID(docID)
+	citekeys, err := store.GetCitekeyMap(ctx, library)
+	if err != nil {
+		log.Error("Failed to retrieve existing citekeys: %v", err)
+		return "", models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to retrieve existing citekeys: %w", err))
+	}
+	existing := make(map[string]bool, len(citekeys))
+	for id, citekey := range citekeys {
+		if id != docID {
+			existing[citekey] = true
+		}
+	}
+	return generateCitekey(metadata, existing, log), nil
+}
+
+// hasCitekeyBase reports whether citekey is base, or base with one of the
+// suffixes citations.GenerateCitekey adds on a collision ("a" to "z", then "z1" on)
+func hasCitekeyBase(citekey, base string) bool {
+	suffix, ok := strings.CutPrefix(citekey, base)
+	if !ok {
+		return false
+	}
+	switch {
+	case suffix == "":
+		return true
+	case len(suffix) == 1:
+		return suffix[0] >= 'a' && suffix[0] <= 'z'
+	default:
+		digits := strings.TrimPrefix(suffix, "z")
+		return digits != suffix && digits != "" && strings.IndexFunc(digits, func(r rune) bool { return !unicode.IsDigit(r) }) < 0
+	}
+}
@@ -0,0 +1,194 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// fakeZoteroParent serves attachment ATT of a journal article, failing the
+// first failures requests for the article with a server error
+func fakeZoteroParent(t *testing.T, failures int) *atomic.Int32 {
+	t.Helper()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/111/items/ATT":
+			w.Write([]byte(`{"key":"ATT","data":{"itemType":"attachment","linkMode":"imported_file","contentType":"application/pdf","parentItem":"PARENT"}}`))
+		case "/users/111/items/PARENT":
+			if int(requests.Add(1)) <= failures {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`{"key":"PARENT","data":{"itemType":"journalArticle","title":"Field Notes","creators":[{"creatorType":"author","firstName":"Jane","lastName":"Smith"}],"date":"2019","tags":[{"tag":"archives"}]}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("ZOTERO_API_BASE_URL", server.URL)
+	// User library document IDs do not record the library
+	t.Setenv("ZOTERO_LIBRARY_ID", "111")
+	return &requests
+}
+
+// storeUnmergedZoteroDocument stores a document parsed from attachment ATT
+// while Zotero could not be reached, with only extracted metadata
+func storeUnmergedZoteroDocument(t *testing.T, store storage.Store) {
+	t.Helper()
+	item := &models.ParsedItem{
+		Metadata: models.ItemMetadata{
+			Title:    "Field notes (draft)",
+			Abstract: "Notes from the archive.",
+			Citekey:  "unknown",
+		},
+		Pages:       []string{"Notes from the archive."},
+		PageNumbers: []string{"1"},
+	}
+	if err := store.StoreParsedItem(context.Background(), "zotero_ATT", item, &models.SourceInfo{ZoteroID: "ATT"}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+}
+
+func TestRefreshZoteroMetadata(t *testing.T) {
+	store := newDuplicateTestStore(t)
+	ctx := context.Background()
+	log := logger.NewNoOpLogger()
+	storeUnmergedZoteroDocument(t, store)
+	requests := fakeZoteroParent(t, 1)
+
+	result, err := RefreshZoteroMetadata(ctx, "key", "zotero_ATT", false, store, log)
+	if err != nil {
+		t.Fatalf("RefreshZoteroMetadata failed: %v", err)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("Expected the failed request retried once, got %d requests", requests.Load())
+	}
+
+	// Zotero's fields win over the extracted ones, which fill the gaps
+	metadata, err := store.GetMetadata(ctx, "zotero_ATT")
+	if err != nil {
+		t.Fatalf("GetMetadata failed: %v", err)
+	}
+	if metadata.Title != "Field Notes" || metadata.Abstract != "Notes from the archive." || !reflect.DeepEqual(metadata.Authors, []string{"Jane Smith"}) {
+		t.Errorf("Expected the merged metadata stored, got %+v", metadata)
+	}
+	if metadata.FieldSources["title"] != "external" || metadata.FieldSources["abstract"] != "extracted" {
+		t.Errorf("Expected field sources for the merge, got %v", metadata.FieldSources)
+	}
+	if !reflect.DeepEqual(metadata.Tags, []string{"archives"}) {
+		t.Errorf("Expected the item's tags stored, got %v", metadata.Tags)
+	}
+	for _, field := range []string{"title", "authors", "publication_date"} {
+		if !slices.Contains(result.Changed, field) {
+			t.Errorf("Expected %s among the changed fields, got %v", field, result.Changed)
+		}
+	}
+	if slices.Contains(result.Changed, "abstract") {
+		t.Errorf("Expected the abstract unchanged, got %v", result.Changed)
+	}
+
+	// The citekey no longer fits the metadata, so it is generated again
+	if metadata.Citekey != "smith2019" || result.OldCitekey != "unknown" {
+		t.Errorf("Expected citekey smith2019 replacing unknown, got %s (old %s)", metadata.Citekey, result.OldCitekey)
+	}
+	if docID, err := store.GetDocumentByCitekey(ctx, "", "smith2019"); err != nil || docID != "zotero_ATT" {
+		t.Errorf("Expected the new citekey to resolve, got %s, %v", docID, err)
+	}
+
+	// A second refresh changes nothing and keeps the citekey
+	result, err = RefreshZoteroMetadata(ctx, "key", "zotero_ATT", false, store, log)
+	if err != nil {
+		t.Fatalf("RefreshZoteroMetadata failed: %v", err)
+	}
+	if len(result.Changed) != 0 || result.OldCitekey != "" || result.Metadata.Citekey != "smith2019" {
+		t.Errorf("Expected an unchanged refresh, got %+v", result)
+	}
+}
+
+func TestRefreshZoteroMetadata_KeepsManualFields(t *testing.T) {
+	store := newDuplicateTestStore(t)
+	ctx := context.Background()
+	storeUnmergedZoteroDocument(t, store)
+	fakeZoteroParent(t, 0)
+
+	metadata, err := store.GetMetadata(ctx, "zotero_ATT")
+	if err != nil {
+		t.Fatalf("GetMetadata failed: %v", err)
+	}
+	metadata.Title = "Corrected Title"
+	metadata.FieldSources = map[string]string{"title": "manual", "abstract": "extracted"}
+	if err := store.UpdateMetadata(ctx, "zotero_ATT", metadata); err != nil {
+		t.Fatalf("UpdateMetadata failed: %v", err)
+	}
+
+	result, err := RefreshZoteroMetadata(ctx, "key", "zotero_ATT", true, store, logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("RefreshZoteroMetadata failed: %v", err)
+	}
+	if result.Metadata.Title != "Corrected Title" || result.Metadata.FieldSources["title"] != "manual" {
+		t.Errorf("Expected the manual title kept, got %q (%v)", result.Metadata.Title, result.Metadata.FieldSources)
+	}
+	if result.Metadata.Citekey != "unknown" || result.OldCitekey != "" {
+		t.Errorf("Expected the citekey kept, got %s", result.Metadata.Citekey)
+	}
+}
+
+func TestRefreshZoteroMetadata_Errors(t *testing.T) {
+	store := newDuplicateTestStore(t)
+	ctx := context.Background()
+	log := logger.NewNoOpLogger()
+	storeUnmergedZoteroDocument(t, store)
+	requests := fakeZoteroParent(t, 10)
+
+	var coded *models.CodedError
+	_, err := RefreshZoteroMetadata(ctx, "key", "zotero_ATT", false, store, log)
+	if !errors.As(err, &coded) || coded.Code != models.ErrorUpstreamZotero {
+		t.Errorf("Expected an upstream_zotero error when Zotero keeps failing, got %v", err)
+	}
+	if requests.Load() != 3 {
+		t.Errorf("Expected 3 attempts, got %d", requests.Load())
+	}
+	if metadata, _ := store.GetMetadata(ctx, "zotero_ATT"); metadata.Title != "Field notes (draft)" {
+		t.Errorf("Expected the stored metadata untouched, got %+v", metadata)
+	}
+
+	_, err = RefreshZoteroMetadata(ctx, "key", "url_1", false, store, log)
+	if !errors.As(err, &coded) || coded.Code != models.ErrorInvalidInput {
+		t.Errorf("Expected invalid_input for a document not from Zotero, got %v", err)
+	}
+
+	if _, err := RefreshZoteroMetadata(ctx, "key", "missing", false, store, log); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown document, got %v", err)
+	}
+}
+
+func TestHasCitekeyBase(t *testing.T) {
+	tests := []struct {
+		citekey  string
+		expected bool
+	}{
+		{"smith2019", true},
+		{"smith2019b", true},
+		{"smith2019z12", true},
+		{"smith2019z", true},
+		{"smith2019ab", false},
+		{"smith2019B", false},
+		{"smith2020", false},
+		{"jones2019", false},
+	}
+	for _, tt := range tests {
+		if got := hasCitekeyBase(tt.citekey, "smith2019"); got != tt.expected {
+			t.Errorf("hasCitekeyBase(%q): expected %v, got %v", tt.citekey, tt.expected, got)
+		}
+	}
+}
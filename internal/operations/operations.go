@@ -2,11 +2,10 @@ package operations
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"os"
 
 	"github.com/Epistemic-Technology/academic-mcp/internal/citations"
+	"github.com/Epistemic-Technology/academic-mcp/internal/config"
 	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
 	"github.com/Epistemic-Technology/academic-mcp/internal/llm"
 	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
@@ -18,7 +17,8 @@ import (
 // or fetches and parses it if it doesn't. This function encapsulates the
 // common logic shared by tools that need parsed documents.
 //
-// Supports multiple document types: PDF, HTML, Markdown, plain text, and DOCX.
+// Supports multiple document types: PDF, HTML, EPUB, RTF, Markdown, plain text,
+// and JATS and TEI XML.
 // The document type is automatically detected from the content.
 //
 // Parameters:
@@ -26,14 +26,70 @@ import (
 //   - zoteroID: Optional Zotero item ID (mutually exclusive with URL and rawData)
 //   - url: Optional URL to fetch document from (mutually exclusive with zoteroID and rawData)
 //   - rawData: Optional raw document bytes (mutually exclusive with zoteroID and URL)
-//   - docType: Optional document type override (e.g., "pdf", "html", "md", "txt"). If empty, type will be auto-detected.
+//   - docType: Optional document type override (e.g., "pdf", "html", "epub", "rtf", "md", "txt", "jats", "tei"). If empty, type will be auto-detected.
+//   - library: Optional Zotero library for zoteroID. Empty fields fall back to ZOTERO_LIBRARY_TYPE/ZOTERO_LIBRARY_ID.
 //   - store: Storage backend for checking existence and retrieving/storing documents
 //
 // Returns:
-//   - documentID: The generated document ID
+//   - documentID: The generated document ID, or that of an existing document for the same work
 //   - parsedItem: The parsed document with all extracted data
 //   - error: Any error encountered during the process
-func GetOrParseDocument(ctx context.Context, zoteroID, url string, rawData []byte, docType string, store storage.Store, log logger.Logger) (string, *models.ParsedItem, error) {
+//
+// A source that turns out to be a work already in the store is linked onto the
+// existing document (see GetOrParseDocumentWithDuplicates). A document stored
+// as partial (parsed for its metadata only) is returned as it is, with
+// parsedItem.Partial set, rather than parsed again.
+func GetOrParseDocument(ctx context.Context, zoteroID, url string, rawData []byte, docType string, library models.ZoteroLibrary, store storage.Store, log logger.Logger) (string, *models.ParsedItem, error) {
+	docID, parsedItem, _, err := GetOrParseDocumentWithDuplicates(ctx, zoteroID, url, rawData, docType, library, models.PageRange{}, true, "", store, log)
+	return docID, parsedItem, err
+}
+
+// How much of a document GetOrParseDocumentWithDuplicates parses, and how. With
+// no mode, a document that is not yet stored is parsed in full, but a partial
+// or basic one is returned as it is.
+const (
+	ParseModeFull     = "full"     // Every page and everything on it
+	ParseModeMetadata = "metadata" // Only the metadata and abstract of a PDF's first pages
+	ParseModeBasic    = "basic"    // Every page's extracted text, without the model (see documents.ParseDocumentBasic)
+)
+
+// Ways a source can match an existing document for the same work
+const (
+	MatchContentHash     = "content_hash"
+	MatchDOI             = "doi"
+	MatchTitleAuthorYear = "title_author_year"
+)
+
+// Duplicate describes an existing document found to be the same work as the
+// source being parsed
+type Duplicate struct {
+	DocumentID   string // The existing document, returned in place of a new one
+	MatchedOn    string // MatchContentHash, MatchDOI, or MatchTitleAuthorYear
+	SourceLinked bool   // The source was recorded as another source of the document
+}
+
+// GetOrParseDocumentWithDuplicates is GetOrParseDocument, also reporting whether
+// the source is a work the store already holds from another source. The same
+// file is recognized by its content hash before parsing. A match on DOI, or else
+// on title, first author, and year, is looked for using the source's external
+// metadata before parsing and the merged metadata after parsing. The
+// existing document is returned rather than storing a second copy; if
+// linkDuplicates is set, the source is also recorded against it, so later
+// requests for the source resolve to the document without fetching it again.
+//
+// With ParseModeMetadata, a PDF that is not yet stored is parsed for its
+// metadata only and stored as a partial document. With ParseModeBasic, or when
+// OPENAI_API_KEY is not set, it is parsed from its extracted text without the
+// model and stored as a basic document. A partial document found by any of the
+// checks above is upgraded in place with ParseModeFull, keeping its document ID,
+// citekey, and source, and so is a basic one when there is an API key.
+//
+// With a page range set, only those physical pages of a PDF are parsed, under
+// a document ID of their own (see storage.GenerateDocumentID), and pages
+// without a detected page number are numbered from the start of the range. As
+// a chapter shares the DOI and title of its book, such a document is matched
+// to another only by content hash, which includes the range.
+func GetOrParseDocumentWithDuplicates(ctx context.Context, zoteroID, url string, rawData []byte, docType string, library models.ZoteroLibrary, pages models.PageRange, linkDuplicates bool, mode string, store storage.Store, log logger.Logger) (string, *models.ParsedItem, *Duplicate, error) {
 	if zoteroID != "" {
 		log.Info("Processing document from Zotero: %s", zoteroID)
 	} else if url != "" {
@@ -45,11 +101,23 @@ func GetOrParseDocument(ctx context.Context, zoteroID, url string, rawData []byt
 	sourceInfo := &models.SourceInfo{
 		ZoteroID: zoteroID,
 		URL:      url,
+		Pages:    pages,
+	}
+
+	// Resolve the Zotero library up front so the document ID reflects it
+	if zoteroID != "" {
+		resolved, err := documents.ResolveZoteroLibrary(ctx, library.Type, library.ID)
+		if err != nil {
+			return "", nil, nil, models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("failed to resolve Zotero library: %w", err))
+		}
+		sourceInfo.ZoteroLibraryType = resolved.Type
+		sourceInfo.ZoteroLibraryID = resolved.ID
 	}
 
 	// Get document data from appropriate source
 	var data models.DocumentData
 	var externalMetadata *models.ItemMetadata
+	var metadataWarning *models.MetadataWarning
 	var err error
 
 	if rawData != nil {
@@ -59,16 +127,22 @@ func GetOrParseDocument(ctx context.Context, zoteroID, url string, rawData []byt
 			detectedType = documents.DetectDocumentType(rawData)
 		}
 		data = models.DocumentData{
-			Data: rawData,
-			Type: detectedType,
+			Data:  rawData,
+			Type:  detectedType,
+			Pages: pages,
+			Fetch: documents.DescribeData(rawData),
 		}
 		// No external metadata for raw data
 		externalMetadata = nil
 	} else {
 		// Fetch both data and external metadata (if available)
-		data, externalMetadata, err = documents.GetDataWithMetadata(ctx, *sourceInfo)
+		data, externalMetadata, metadataWarning, err = documents.GetDataWithMetadata(ctx, *sourceInfo)
 		if err != nil {
-			return "", nil, fmt.Errorf("failed to fetch document data: %w", err)
+			code := models.ErrorUpstreamFetch
+			if zoteroID != "" {
+				code = models.ErrorUpstreamZotero
+			}
+			return "", nil, nil, models.WithErrorCode(code, fmt.Errorf("failed to fetch document data: %w", err))
 		}
 		// Override detected type if docType parameter is provided
 		if docType != "" {
@@ -78,45 +152,182 @@ func GetOrParseDocument(ctx context.Context, zoteroID, url string, rawData []byt
 		// Log metadata fetch result
 		if externalMetadata != nil {
 			log.Info("Retrieved external metadata from %s for document", externalMetadata.MetadataSource)
+		} else if metadataWarning != nil {
+			log.Warn("Failed to fetch %s metadata, the document will have extracted metadata only: %s", metadataWarning.Source, metadataWarning.Message)
 		} else {
 			log.Debug("No external metadata available")
 		}
 	}
 
-	// Generate document ID
-	docID := storage.GenerateDocumentID(sourceInfo, data)
+	if err := documents.CheckPageRange(data); err != nil {
+		return "", nil, nil, err
+	}
+
+	// Generate document ID, in the library the context names
+	docLibrary := storage.LibraryFromContext(ctx)
+	docID := storage.LibraryDocumentID(docLibrary, storage.GenerateDocumentID(documents.DocumentIDSource(ctx, sourceInfo), data))
+	log = log.With("document_id", docID)
 
 	// Check if document already exists in store
 	exists, err := store.DocumentExists(ctx, docID)
 	if err != nil {
 		log.Error("Failed to check document existence: %v", err)
-		return "", nil, fmt.Errorf("failed to check document existence: %w", err)
+		return "", nil, nil, models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to check document existence: %w", err))
+	}
+
+	// The source may have been linked onto a document parsed from another
+	// source, or stored under its ID from before IDs were lengthened
+	if !exists {
+		for _, sourceID := range []string{docID, storage.LegacyDocumentID(sourceInfo, data)} {
+			if sourceID == "" {
+				continue
+			}
+			sourceID = storage.LibraryDocumentID(docLibrary, sourceID)
+			linkedID, err := store.GetDocumentBySource(ctx, sourceID)
+			if err != nil {
+				return "", nil, nil, models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to check document sources: %w", err))
+			}
+			// A Zotero item's key alone may be another user library's item
+			if linkedID != "" && sourceInfo.ZoteroID != "" && sourceID != docID {
+				linkedID, err = sameZoteroLibrary(ctx, store, linkedID, sourceID, sourceInfo)
+				if err != nil {
+					return "", nil, nil, models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to check document sources: %w", err))
+				}
+			}
+			if linkedID != "" {
+				log.Info("Source %s is linked to document %s", sourceID, linkedID)
+				docID, exists = linkedID, true
+				break
+			}
+		}
+	}
+
+	// The same file may already have been parsed from another source
+	contentHash := storage.DocumentContentHash(data)
+	var duplicate *Duplicate
+	if !exists {
+		hashID, err := store.FindDocumentByContentHash(ctx, docLibrary, contentHash)
+		if err != nil {
+			return "", nil, nil, models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to check for duplicate documents: %w", err))
+		}
+		if hashID != "" {
+			duplicate = &Duplicate{DocumentID: hashID, MatchedOn: MatchContentHash}
+		}
+	}
+
+	// External metadata can identify a duplicate before paying for a parse
+	if !exists && duplicate == nil && externalMetadata != nil && !pages.IsSet() {
+		duplicate, err = findDuplicate(ctx, store, docLibrary, externalMetadata)
+		if err != nil {
+			return "", nil, nil, err
+		}
 	}
 
 	var parsedItem *models.ParsedItem
+	apiKey := config.FromContext(ctx).Credentials().OpenAIAPIKey
 
-	if exists {
+	if duplicate != nil {
+		parsedItem, err = useDuplicate(ctx, store, duplicate, docID, sourceInfo, linkDuplicates, log)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		if !needsFullParse(parsedItem, mode, apiKey) {
+			return duplicate.DocumentID, parsedItem, duplicate, nil
+		}
+		docID = duplicate.DocumentID
+	} else if exists {
 		log.Info("Document %s already exists, retrieving from storage", docID)
 		// Document already parsed, retrieve from store
 		parsedItem, err = store.GetParsedItem(ctx, docID)
 		if err != nil {
 			log.Error("Failed to retrieve existing document %s: %v", docID, err)
-			return "", nil, fmt.Errorf("failed to retrieve existing document: %w", err)
+			return "", nil, nil, models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to retrieve existing document: %w", err))
 		}
-	} else {
-		log.Info("Document %s not found, parsing new document (type: %s)", docID, data.Type)
-		// Document needs to be parsed
-		apiKey := os.Getenv("OPENAI_API_KEY")
-		if apiKey == "" {
-			log.Error("OPENAI_API_KEY environment variable not set")
-			return "", nil, errors.New("OPENAI_API_KEY environment variable not set")
+	}
+
+	// A document parsed for its metadata only, or without the model, is replaced
+	// by a full parse, which keeps its citekey and source
+	upgrade := parsedItem != nil && needsFullParse(parsedItem, mode, apiKey)
+	if parsedItem == nil || upgrade {
+		// Concurrent requests for the document wait for one parse rather than
+		// each paying for their own
+		release, err := lockParse(ctx, store, docID, log)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		defer release()
+		exists, err := store.DocumentExists(ctx, docID)
+		if err != nil {
+			return "", nil, nil, models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to check document existence: %w", err))
+		}
+		if exists {
+			current, err := store.GetParsedItem(ctx, docID)
+			if err != nil {
+				return "", nil, nil, models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to retrieve existing document: %w", err))
+			}
+			if !needsFullParse(current, mode, apiKey) {
+				log.Info("Document %s was parsed while waiting for it, using the stored parse", docID)
+				return docID, current, duplicate, nil
+			}
+			parsedItem, upgrade = current, true
+		}
+
+		var citekey string
+		var previous *models.ParsedItem
+		if upgrade {
+			log.Info("Document %s was parsed for metadata only or without the model, replacing it with a full parse (type: %s)", docID, data.Type)
+			previous = parsedItem
+			citekey = parsedItem.Metadata.Citekey
+			sourceInfo, err = store.GetSourceInfo(ctx, docID)
+			if err != nil {
+				return "", nil, nil, models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to retrieve document source: %w", err))
+			}
+		} else {
+			log.Info("Document %s not found, parsing new document (type: %s, mode: %s)", docID, data.Type, mode)
+		}
+		if data.Type == "xml" {
+			log.Warn("XML document %s is neither JATS nor TEI, parsing it as text", docID)
+		}
+		// Without an API key, documents can still be parsed from their text
+		if apiKey == "" && mode != ParseModeBasic && !documents.IsStructuredXML(data.Type) {
+			log.Warn("OPENAI_API_KEY not set, parsing document %s without the model", docID)
+			mode = ParseModeBasic
 		}
 
 		// Parse document using type-specific parser (PDF, HTML, Markdown, Text, etc.)
-		parsedItem, err = llm.ParseDocument(ctx, apiKey, data, log)
+		parseCtx, usage := llm.TrackUsage(ctx)
+		switch {
+		case mode == ParseModeBasic:
+			parsedItem, err = documents.ParseDocumentBasic(data)
+		case mode == ParseModeMetadata && !upgrade:
+			parsedItem, err = llm.ParseDocumentMetadata(parseCtx, apiKey, data, log)
+		default:
+			staging := storePageStaging{store: store, docID: docID}
+			parsedItem, err = llm.ParseDocument(llm.WithPageStaging(parseCtx, staging), apiKey, data, log)
+		}
 		if err != nil {
 			log.Error("Failed to parse document: %v", err)
-			return "", nil, fmt.Errorf("failed to parse document: %w", err)
+			if mode == ParseModeBasic {
+				return "", nil, nil, fmt.Errorf("failed to parse document: %w", err)
+			}
+			return "", nil, nil, models.WithErrorCode(models.ErrorUpstreamLLM, fmt.Errorf("failed to parse document: %w", err))
+		}
+		parsedItem.ContentHash = contentHash
+		parsedItem.Fetch = data.Fetch
+		parsedItem.MetadataWarning = metadataWarning
+		if dropped := documents.SanitizeImages(parsedItem.Images, data.Type, sourceInfo.URL); dropped > 0 {
+			log.Info("Dropped %d image URLs that cannot be fetched", dropped)
+		}
+		// The model's reading of a partial document's metadata beats the patterns
+		// of a basic parse
+		if parsedItem.Basic && previous != nil && previous.Partial {
+			parsedItem.Metadata = previous.Metadata
+		}
+
+		// Malformed DOIs are reported rather than merged or stored
+		parsedItem.InvalidDOIs = documents.NormalizeDOIs(parsedItem, externalMetadata)
+		for _, invalid := range parsedItem.InvalidDOIs {
+			log.Warn("Dropping malformed %s DOI %q", invalid.Source, invalid.Value)
 		}
 
 		// Merge external metadata with extracted metadata (if external metadata is available)
@@ -128,36 +339,214 @@ func GetOrParseDocument(ctx context.Context, zoteroID, url string, rawData []byt
 			parsedItem.Metadata.MetadataSource = "extracted"
 		}
 
-		// Generate citekey for the document
-		citekeyMap, err := store.GetCitekeyMap(ctx)
-		if err != nil {
-			log.Error("Failed to retrieve existing citekeys: %v", err)
-			return "", nil, fmt.Errorf("failed to retrieve existing citekeys: %w", err)
+		// The parsed metadata may identify a duplicate the source's metadata did
+		// not; an upgraded document is already the stored copy of its work
+		if !upgrade && !pages.IsSet() {
+			duplicate, err = findDuplicate(ctx, store, docLibrary, &parsedItem.Metadata)
+			if err != nil {
+				return "", nil, nil, err
+			}
+			if duplicate != nil {
+				clearStagedPages(ctx, store, docID, log)
+				RecordUsage(ctx, store, duplicate.DocumentID, UsageParse, usage, log)
+				parsedItem, err = useDuplicate(ctx, store, duplicate, docID, sourceInfo, linkDuplicates, log)
+				if err != nil {
+					return "", nil, nil, err
+				}
+				return duplicate.DocumentID, parsedItem, duplicate, nil
+			}
 		}
-		// Build a set of existing citekeys for collision detection
-		existingCitekeys := make(map[string]bool)
-		for _, citekey := range citekeyMap {
-			existingCitekeys[citekey] = true
+
+		// Generate citekey for the document
+		if citekey == "" {
+			existing, err := existingCitekeys(ctx, store, docLibrary, log)
+			if err != nil {
+				return "", nil, nil, err
+			}
+			citekey = generateCitekey(&parsedItem.Metadata, existing, log)
+			log.Info("Generated citekey for document: %s", citekey)
 		}
-		// Generate citekey
-		citekey := citations.GenerateCitekey(&parsedItem.Metadata, existingCitekeys)
 		parsedItem.Metadata.Citekey = citekey
-		log.Info("Generated citekey for document: %s", citekey)
+
+		finishParsedItem(docID, parsedItem, log)
 
 		// Store the newly parsed document
 		err = store.StoreParsedItem(ctx, docID, parsedItem, sourceInfo)
 		if err != nil {
 			log.Error("Failed to store parsed document: %v", err)
-			return "", nil, fmt.Errorf("failed to store parsed item: %w", err)
+			return "", nil, nil, models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to store parsed item: %w", err))
 		}
 		log.Info("Successfully parsed and stored document %s", docID)
+		clearStagedPages(ctx, store, docID, log)
+		RecordUsage(ctx, store, docID, UsageParse, usage, log)
+	}
+
+	return docID, parsedItem, duplicate, nil
+}
+
+// existingCitekeys returns the set of citekeys in use in library, for collision
+// detection. Citekeys only need to be unique within a library.
+func existingCitekeys(ctx context.Context, store storage.Store, library string, log logger.Logger) (map[string]bool, error) {
+	citekeyMap, err := store.GetCitekeyMap(ctx, library)
+	if err != nil {
+		log.Error("Failed to retrieve existing citekeys: %v", err)
+		return nil, models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to retrieve existing citekeys: %w", err))
+	}
+	existing := make(map[string]bool)
+	for _, citekey := range citekeyMap {
+		existing[citekey] = true
 	}
+	return existing, nil
+}
 
-	return docID, parsedItem, nil
+// generateCitekey generates a citekey for metadata that is not in existing, in
+// the mode set by ACADEMIC_MCP_CITEKEY_MODE
+func generateCitekey(metadata *models.ItemMetadata, existing map[string]bool, log logger.Logger) string {
+	mode, err := citations.ConfiguredCitekeyMode()
+	if err != nil {
+		log.Warn("Using the default citekey mode: %v", err)
+	}
+	return citations.GenerateCitekeyWithMode(metadata, existing, mode)
+}
+
+// finishParsedItem derives what a parsed document's stored form needs from its
+// final content: it links note markers to their notes, then indexes the
+// document's sections from the headings in its page content (section and
+// sentence offsets depend on the final text), builds its full text, segments
+// its pages into sentences, and structures its tables
+func finishParsedItem(docID string, item *models.ParsedItem, log logger.Logger) {
+	linked := documents.LinkNotes(item, noteAnchorKey(docID, item))
+	log.Info("Linked %d of %d notes to in-text markers", linked, len(item.Footnotes)+len(item.Endnotes))
+	item.Sections = documents.ExtractSections(item.Pages, item.PageNumbers)
+	log.Info("Found %d sections", len(item.Sections))
+	buildFullText(item, log)
+	item.PageSentences = documents.PageSentences(item.Pages)
+	documents.StructureTables(item)
+	for i, table := range item.Tables {
+		if table.ParseError != "" {
+			log.Warn("Table %d could not be parsed: %s", i, table.ParseError)
+		}
+	}
+}
+
+// needsFullParse reports whether a stored document must be parsed again to
+// serve a request in mode: the request is for the full document, and the
+// document was parsed for its metadata only, or without the model while there
+// is now an API key to parse it with
+func needsFullParse(item *models.ParsedItem, mode, apiKey string) bool {
+	if mode != ParseModeFull {
+		return false
+	}
+	return item.Partial || (item.Basic && apiKey != "")
+}
+
+// findDuplicate looks for a stored document in library that is the same work
+// as metadata describes, by DOI and then by title, first author, and year. A
+// work stored in another library is not a duplicate.
+func findDuplicate(ctx context.Context, store storage.Store, library string, metadata *models.ItemMetadata) (*Duplicate, error) {
+	docID, err := store.FindDocumentByDOI(ctx, library, metadata.DOI)
+	if err != nil {
+		return nil, models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to check for duplicate documents: %w", err))
+	}
+	if docID != "" {
+		return &Duplicate{DocumentID: docID, MatchedOn: MatchDOI}, nil
+	}
+
+	if len(metadata.Authors) == 0 {
+		return nil, nil
+	}
+	docID, err = store.FindDocumentByTitleAuthorYear(ctx, library, metadata.Title, metadata.Authors[0], metadata.PublicationDate)
+	if err != nil {
+		return nil, models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to check for duplicate documents: %w", err))
+	}
+	if docID != "" {
+		return &Duplicate{DocumentID: docID, MatchedOn: MatchTitleAuthorYear}, nil
+	}
+	return nil, nil
+}
+
+// sameZoteroLibrary returns docID if the Zotero source recorded as sourceID
+// for it is in sourceInfo's library, or "". A user library item stored before
+// its library was part of the ID has its key alone as its ID, which an item
+// of another user library with the same key also has.
+func sameZoteroLibrary(ctx context.Context, store storage.Store, docID, sourceID string, sourceInfo *models.SourceInfo) (string, error) {
+	sources, err := store.GetDocumentSources(ctx, docID)
+	if err != nil {
+		return "", err
+	}
+	for _, source := range sources {
+		if source.SourceID == sourceID && source.ZoteroID == sourceInfo.ZoteroID &&
+			source.ZoteroLibraryType == sourceInfo.ZoteroLibraryType && source.ZoteroLibraryID == sourceInfo.ZoteroLibraryID {
+			return docID, nil
+		}
+	}
+	return "", nil
+}
+
+// useDuplicate retrieves the existing document for a duplicate source, first
+// recording the source against it if link is set
+func useDuplicate(ctx context.Context, store storage.Store, duplicate *Duplicate, sourceID string, sourceInfo *models.SourceInfo, link bool, log logger.Logger) (*models.ParsedItem, error) {
+	log.Info("Source %s is the same work as document %s (matched on %s)", sourceID, duplicate.DocumentID, duplicate.MatchedOn)
+	if link {
+		if err := store.AddDocumentSource(ctx, duplicate.DocumentID, sourceID, sourceInfo); err != nil {
+			log.Error("Failed to link source %s to document %s: %v", sourceID, duplicate.DocumentID, err)
+			return nil, models.WithErrorCode(models.ErrorStorage, err)
+		}
+		duplicate.SourceLinked = true
+		log.Info("Linked source %s to document %s", sourceID, duplicate.DocumentID)
+	}
+
+	parsedItem, err := store.GetParsedItem(ctx, duplicate.DocumentID)
+	if err != nil {
+		log.Error("Failed to retrieve existing document %s: %v", duplicate.DocumentID, err)
+		return nil, models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to retrieve existing document: %w", err))
+	}
+	return parsedItem, nil
+}
+
+// Operations whose OpenAI usage is recorded per document
+const (
+	UsageParse      = "parse"
+	UsageReparse    = "reparse"
+	UsageSummarize  = "summarize"
+	UsageQuotations = "quotations"
+)
+
+// RecordUsage stores the usage tracked for an operation on a document. A failure
+// is logged rather than returned, since the operation itself succeeded.
+func RecordUsage(ctx context.Context, store storage.Store, docID, operation string, tracker *llm.UsageTracker, log logger.Logger) {
+	usage := tracker.Usage()
+	if err := store.RecordUsage(ctx, docID, operation, usage); err != nil {
+		log.Warn("Failed to record %s usage for document %s: %v", operation, docID, err)
+		return
+	}
+	for _, u := range usage {
+		log.Info("Recorded %s usage for document %s: %d %s requests, %d input and %d output tokens", operation, docID, u.Requests, u.Model, u.InputTokens, u.OutputTokens)
+	}
+}
+
+// noteAnchorKey returns the prefix for a document's note anchors: its citekey, or
+// its document ID if it has none
+func noteAnchorKey(docID string, item *models.ParsedItem) string {
+	if item.Metadata.Citekey != "" {
+		return item.Metadata.Citekey
+	}
+	return docID
+}
+
+// buildFullText records a document's continuous text, with the hyphenation and
+// paragraph breaks of its page layout undone (see documents.FullText). It runs
+// after note linking, since the text is built from the final page contents.
+func buildFullText(item *models.ParsedItem, log logger.Logger) {
+	mode, err := documents.ConfiguredHyphenationMode()
+	if err != nil {
+		log.Warn("Using the default hyphenation mode: %v", err)
+	}
+	item.FullText, item.PageOffsets = documents.FullText(item.Pages, mode)
 }
 
 // GetOrParsePDF is a convenience wrapper around GetOrParseDocument for PDF-specific use cases.
 // Deprecated: Use GetOrParseDocument instead for better multi-format support.
 func GetOrParsePDF(ctx context.Context, zoteroID, url string, rawData []byte, store storage.Store, log logger.Logger) (string, *models.ParsedItem, error) {
-	return GetOrParseDocument(ctx, zoteroID, url, rawData, "pdf", store, log)
+	return GetOrParseDocument(ctx, zoteroID, url, rawData, "pdf", models.ZoteroLibrary{}, store, log)
 }
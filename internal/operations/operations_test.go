@@ -0,0 +1,587 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// newDuplicateTestStore returns a store holding one document, "url_1", for the
+// work "Memory and the Archive" by Jane Smith (2020), DOI 10.1000/jms.2020.1
+func newDuplicateTestStore(t *testing.T) storage.Store {
+	t.Helper()
+	store, err := storage.NewSQLiteStore(":memory:", logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	item := &models.ParsedItem{
+		Metadata: models.ItemMetadata{
+			Title:           "Memory and the Archive",
+			Authors:         []string{"Smith, Jane"},
+			PublicationDate: "2020",
+			DOI:             "10.1000/jms.2020.1",
+		},
+		Pages: []string{"Page one"},
+	}
+	if err := store.StoreParsedItem(context.Background(), "url_1", item, &models.SourceInfo{URL: "https://example.com/paper"}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+	return store
+}
+
+func TestFindDuplicate(t *testing.T) {
+	store := newDuplicateTestStore(t)
+
+	tests := []struct {
+		name     string
+		metadata models.ItemMetadata
+		expected *Duplicate
+	}{
+		{
+			name:     "DOI with resolver prefix",
+			metadata: models.ItemMetadata{Title: "Unrelated title", DOI: "https://doi.org/10.1000/JMS.2020.1"},
+			expected: &Duplicate{DocumentID: "url_1", MatchedOn: MatchDOI},
+		},
+		{
+			name:     "title, first author, and year",
+			metadata: models.ItemMetadata{Title: "Memory and the archive: a study", Authors: []string{"Jane Smith", "John Doe"}, PublicationDate: "2020-05-01"},
+			expected: &Duplicate{DocumentID: "url_1", MatchedOn: MatchTitleAuthorYear},
+		},
+		{
+			name:     "same title in another year",
+			metadata: models.ItemMetadata{Title: "Memory and the Archive", Authors: []string{"Smith, Jane"}, PublicationDate: "2021"},
+		},
+		{
+			name:     "no identifying metadata",
+			metadata: models.ItemMetadata{Title: "Memory and the Archive"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findDuplicate(context.Background(), store, storage.DefaultLibrary, &tt.metadata)
+			if err != nil {
+				t.Fatalf("findDuplicate failed: %v", err)
+			}
+			if (got == nil) != (tt.expected == nil) || (got != nil && *got != *tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestUseDuplicate(t *testing.T) {
+	ctx := context.Background()
+	zoteroSource := &models.SourceInfo{ZoteroID: "ABC123"}
+
+	for _, link := range []bool{true, false} {
+		store := newDuplicateTestStore(t)
+		duplicate := &Duplicate{DocumentID: "url_1", MatchedOn: MatchDOI}
+
+		item, err := useDuplicate(ctx, store, duplicate, "zotero_ABC123", zoteroSource, link, logger.NewNoOpLogger())
+		if err != nil {
+			t.Fatalf("useDuplicate(link=%v) failed: %v", link, err)
+		}
+		if item.Metadata.Title != "Memory and the Archive" {
+			t.Errorf("Expected the existing document, got %+v", item.Metadata)
+		}
+		if duplicate.SourceLinked != link {
+			t.Errorf("Expected SourceLinked %v, got %v", link, duplicate.SourceLinked)
+		}
+
+		linkedTo, err := store.GetDocumentBySource(ctx, "zotero_ABC123")
+		if err != nil {
+			t.Fatalf("GetDocumentBySource failed: %v", err)
+		}
+		expected := ""
+		if link {
+			expected = "url_1"
+		}
+		if linkedTo != expected {
+			t.Errorf("link=%v: expected source linked to %q, got %q", link, expected, linkedTo)
+		}
+	}
+}
+
+func TestGetOrParseDocument_LinkedSource(t *testing.T) {
+	// A source linked onto an existing document resolves to it without parsing
+	t.Setenv("OPENAI_API_KEY", "")
+	store := newDuplicateTestStore(t)
+	ctx := context.Background()
+
+	rawData := []byte("Memory and the Archive, as a plain text copy")
+	sourceID := storage.GenerateDocumentID(&models.SourceInfo{}, models.DocumentData{Data: rawData})
+	if err := store.AddDocumentSource(ctx, "url_1", sourceID, &models.SourceInfo{}); err != nil {
+		t.Fatalf("AddDocumentSource failed: %v", err)
+	}
+
+	docID, item, err := GetOrParseDocument(ctx, "", "", rawData, "", models.ZoteroLibrary{}, store, logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("GetOrParseDocument failed: %v", err)
+	}
+	if docID != "url_1" || item.Metadata.Title != "Memory and the Archive" {
+		t.Errorf("Expected the linked document url_1, got %s (%+v)", docID, item.Metadata)
+	}
+}
+
+// storeParsedSource stores a document as GetOrParseDocument would after parsing
+// data from source, returning its ID
+func storeParsedSource(t *testing.T, store storage.Store, source *models.SourceInfo, data []byte) string {
+	t.Helper()
+	docID := storage.GenerateDocumentID(source, models.DocumentData{Data: data})
+	item := &models.ParsedItem{
+		Metadata:    models.ItemMetadata{Title: "Field Notes"},
+		Pages:       []string{"Page one"},
+		ContentHash: storage.ContentHash(data),
+	}
+	if err := store.StoreParsedItem(context.Background(), docID, item, source); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+	return docID
+}
+
+func TestGetOrParseDocument_ContentHash(t *testing.T) {
+	// Re-uploads of a stored file resolve to it without parsing
+	t.Setenv("OPENAI_API_KEY", "")
+	ctx := context.Background()
+	log := logger.NewNoOpLogger()
+	fileData := []byte("Field notes, as fetched and as uploaded")
+
+	t.Run("URL then raw data", func(t *testing.T) {
+		store := newDuplicateTestStore(t)
+		urlDocID := storeParsedSource(t, store, &models.SourceInfo{URL: "https://example.com/notes.txt"}, fileData)
+
+		docID, item, duplicate, err := GetOrParseDocumentWithDuplicates(ctx, "", "", fileData, "", models.ZoteroLibrary{}, models.PageRange{}, true, ParseModeFull, store, log)
+		if err != nil {
+			t.Fatalf("GetOrParseDocumentWithDuplicates failed: %v", err)
+		}
+		if docID != urlDocID || item.Metadata.Title != "Field Notes" {
+			t.Errorf("Expected document %s, got %s (%+v)", urlDocID, docID, item.Metadata)
+		}
+		if duplicate == nil || duplicate.MatchedOn != MatchContentHash || !duplicate.SourceLinked {
+			t.Errorf("Expected a linked content hash match, got %+v", duplicate)
+		}
+	})
+
+	t.Run("raw data then Zotero", func(t *testing.T) {
+		store := newDuplicateTestStore(t)
+		dataDocID := storeParsedSource(t, store, &models.SourceInfo{}, fileData)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/users/111/items/ATT1":
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{"key":"ATT1","data":{"key":"ATT1","itemType":"attachment","contentType":"text/plain","filename":"notes.txt","linkMode":"imported_file"}}`)
+			case "/users/111/items/ATT1/file":
+				w.Write(fileData)
+			default:
+				http.Error(w, "Not found", http.StatusNotFound)
+			}
+		}))
+		t.Cleanup(server.Close)
+		t.Setenv("ZOTERO_API_BASE_URL", server.URL)
+		t.Setenv("ZOTERO_LIBRARY_ID", "111")
+
+		library := models.ZoteroLibrary{Type: "user", ID: "111"}
+		docID, _, duplicate, err := GetOrParseDocumentWithDuplicates(ctx, "ATT1", "", nil, "", library, models.PageRange{}, true, ParseModeFull, store, log)
+		if err != nil {
+			t.Fatalf("GetOrParseDocumentWithDuplicates failed: %v", err)
+		}
+		if docID != dataDocID {
+			t.Errorf("Expected document %s, got %s", dataDocID, docID)
+		}
+		if duplicate == nil || duplicate.MatchedOn != MatchContentHash {
+			t.Errorf("Expected a content hash match, got %+v", duplicate)
+		}
+
+		linkedTo, err := store.GetDocumentBySource(ctx, "zotero_ATT1")
+		if err != nil {
+			t.Fatalf("GetDocumentBySource failed: %v", err)
+		}
+		if linkedTo != dataDocID {
+			t.Errorf("Expected zotero_ATT1 linked to %s, got %q", dataDocID, linkedTo)
+		}
+	})
+
+	t.Run("document stored under a legacy ID", func(t *testing.T) {
+		store := newDuplicateTestStore(t)
+		legacyID := storage.LegacyDocumentID(&models.SourceInfo{}, models.DocumentData{Data: fileData})
+		if err := store.StoreParsedItem(ctx, legacyID, &models.ParsedItem{Metadata: models.ItemMetadata{Title: "Field Notes"}}, &models.SourceInfo{}); err != nil {
+			t.Fatalf("Failed to store document: %v", err)
+		}
+
+		docID, _, duplicate, err := GetOrParseDocumentWithDuplicates(ctx, "", "", fileData, "", models.ZoteroLibrary{}, models.PageRange{}, true, ParseModeFull, store, log)
+		if err != nil {
+			t.Fatalf("GetOrParseDocumentWithDuplicates failed: %v", err)
+		}
+		if docID != legacyID || duplicate != nil {
+			t.Errorf("Expected existing document %s, got %s (duplicate %+v)", legacyID, docID, duplicate)
+		}
+	})
+}
+
+func TestGetOrParseDocument_UserLibraries(t *testing.T) {
+	// The same item key in two user libraries is two documents
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("ZOTERO_LIBRARY_ID", "111")
+	ctx := context.Background()
+	log := logger.NewNoOpLogger()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		libraryPath, file, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/users/"), "/items/ATT1")
+		switch file {
+		case "":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"key":"ATT1","data":{"key":"ATT1","itemType":"attachment","contentType":"text/plain","filename":"notes.txt","linkMode":"imported_file"}}`)
+		case "/file":
+			fmt.Fprintf(w, "Field notes kept in library %s", libraryPath)
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("ZOTERO_API_BASE_URL", server.URL)
+
+	parse := func(store storage.Store, libraryID string) string {
+		t.Helper()
+		docID, _, _, err := GetOrParseDocumentWithDuplicates(ctx, "ATT1", "", nil, "", models.ZoteroLibrary{Type: "user", ID: libraryID}, models.PageRange{}, true, ParseModeFull, store, log)
+		if err != nil {
+			t.Fatalf("GetOrParseDocumentWithDuplicates failed for library %s: %v", libraryID, err)
+		}
+		return docID
+	}
+
+	t.Run("new documents", func(t *testing.T) {
+		store := newDuplicateTestStore(t)
+		ids := []string{parse(store, "111"), parse(store, "222"), parse(store, "333")}
+		if want := []string{"zotero_ATT1", "zotero_user_222_ATT1", "zotero_user_333_ATT1"}; !reflect.DeepEqual(ids, want) {
+			t.Errorf("Expected documents %v, got %v", want, ids)
+		}
+		for _, docID := range ids[1:] {
+			source, err := store.GetSourceInfo(ctx, docID)
+			if err != nil {
+				t.Fatalf("GetSourceInfo failed: %v", err)
+			}
+			if source.ZoteroLibraryID != strings.Split(docID, "_")[2] {
+				t.Errorf("Expected %s to keep its library, got %+v", docID, source)
+			}
+		}
+	})
+
+	t.Run("document stored under the key alone", func(t *testing.T) {
+		// Stored from library 222 before user libraries were part of the ID
+		store := newDuplicateTestStore(t)
+		legacy := &models.SourceInfo{ZoteroID: "ATT1", ZoteroLibraryType: "user", ZoteroLibraryID: "222"}
+		if err := store.StoreParsedItem(ctx, "zotero_ATT1", &models.ParsedItem{Metadata: models.ItemMetadata{Title: "Field Notes"}}, legacy); err != nil {
+			t.Fatalf("Failed to store document: %v", err)
+		}
+
+		if docID := parse(store, "222"); docID != "zotero_ATT1" {
+			t.Errorf("Expected the stored document of library 222, got %s", docID)
+		}
+		if docID := parse(store, "333"); docID != "zotero_user_333_ATT1" {
+			t.Errorf("Expected a new document for library 333, got %s", docID)
+		}
+	})
+}
+
+func TestGetOrParseDocument_UpgradesPartial(t *testing.T) {
+	// A document parsed for its metadata only is parsed in full in place
+	ctx := context.Background()
+	log := logger.NewNoOpLogger()
+	fileData := []byte("Field notes, parsed for triage first")
+
+	calls := 0
+	openAI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"resp_1","object":"response","created_at":0,"status":"completed","model":"gpt-5-mini","output":[{"type":"message","id":"msg_1","status":"completed","role":"assistant","content":[{"type":"output_text","text":"{\"metadata\":{\"title\":\"Field Notes\",\"authors\":[\"Smith, Jane\"]},\"content\":\"The full field notes.\",\"references\":[],\"images\":[],\"tables\":[],\"footnotes\":[],\"endnotes\":[]}","annotations":[]}]}]}`)
+	}))
+	t.Cleanup(openAI.Close)
+	t.Setenv("OPENAI_BASE_URL", openAI.URL)
+	t.Setenv("OPENAI_API_KEY", "test-key")
+
+	store := newDuplicateTestStore(t)
+	docID := storage.GenerateDocumentID(&models.SourceInfo{}, models.DocumentData{Data: fileData})
+	partial := &models.ParsedItem{
+		Metadata:    models.ItemMetadata{Title: "Field Notes", Citekey: "smithFieldNotes"},
+		ContentHash: storage.ContentHash(fileData),
+		Partial:     true,
+	}
+	if err := store.StoreParsedItem(ctx, docID, partial, &models.SourceInfo{}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+
+	// Without a mode, the partial document is returned as it is
+	_, item, err := GetOrParseDocument(ctx, "", "", fileData, "", models.ZoteroLibrary{}, store, log)
+	if err != nil {
+		t.Fatalf("GetOrParseDocument failed: %v", err)
+	}
+	if !item.Partial || calls != 0 {
+		t.Errorf("Expected the partial document without parsing, got partial=%v after %d calls", item.Partial, calls)
+	}
+
+	gotID, item, duplicate, err := GetOrParseDocumentWithDuplicates(ctx, "", "", fileData, "", models.ZoteroLibrary{}, models.PageRange{}, true, ParseModeFull, store, log)
+	if err != nil {
+		t.Fatalf("GetOrParseDocumentWithDuplicates failed: %v", err)
+	}
+	if gotID != docID || duplicate != nil {
+		t.Errorf("Expected document %s upgraded in place, got %s (duplicate %+v)", docID, gotID, duplicate)
+	}
+	if item.Partial || len(item.Pages) != 1 || item.Metadata.Citekey != "smithFieldNotes" {
+		t.Errorf("Expected a full parse keeping the citekey, got partial=%v, %d pages, citekey %q", item.Partial, len(item.Pages), item.Metadata.Citekey)
+	}
+
+	partialNow, err := store.IsPartial(ctx, docID)
+	if err != nil {
+		t.Fatalf("IsPartial failed: %v", err)
+	}
+	if partialNow {
+		t.Error("Expected the stored document to no longer be partial")
+	}
+}
+
+func TestGetOrParseDocument_Basic(t *testing.T) {
+	// Without an API key a document is parsed from its text, and once there is
+	// one, a full parse replaces it in place
+	ctx := context.Background()
+	log := logger.NewNoOpLogger()
+	fileData := []byte("Field Notes\nJane Smith\nThe full field notes.")
+	store := newDuplicateTestStore(t)
+
+	t.Setenv("OPENAI_API_KEY", "")
+	docID, item, _, err := GetOrParseDocumentWithDuplicates(ctx, "", "", fileData, "", models.ZoteroLibrary{}, models.PageRange{}, true, ParseModeFull, store, log)
+	if err != nil {
+		t.Fatalf("GetOrParseDocumentWithDuplicates failed: %v", err)
+	}
+	if !item.Basic || item.Metadata.Title != "Field Notes" || item.Metadata.MetadataSource != documents.MetadataSourceBasic || item.Metadata.Citekey == "" {
+		t.Fatalf("Expected a basic document with a citekey, got %+v", item)
+	}
+	citekey := item.Metadata.Citekey
+
+	// Still without a key, the basic document is returned as it is
+	_, item, _, err = GetOrParseDocumentWithDuplicates(ctx, "", "", fileData, "", models.ZoteroLibrary{}, models.PageRange{}, true, ParseModeFull, store, log)
+	if err != nil || !item.Basic {
+		t.Fatalf("Expected the stored basic document, got %+v, %v", item, err)
+	}
+
+	calls := 0
+	openAI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"resp_1","object":"response","created_at":0,"status":"completed","model":"gpt-5-mini","output":[{"type":"message","id":"msg_1","status":"completed","role":"assistant","content":[{"type":"output_text","text":"{\"metadata\":{\"title\":\"Field Notes\",\"authors\":[\"Smith, Jane\"]},\"content\":\"The full field notes.\",\"references\":[],\"images\":[],\"tables\":[],\"footnotes\":[],\"endnotes\":[]}","annotations":[]}]}]}`)
+	}))
+	t.Cleanup(openAI.Close)
+	t.Setenv("OPENAI_BASE_URL", openAI.URL)
+	t.Setenv("OPENAI_API_KEY", "test-key")
+
+	// The basic parser is used on request, without upgrading
+	_, item, _, err = GetOrParseDocumentWithDuplicates(ctx, "", "", fileData, "", models.ZoteroLibrary{}, models.PageRange{}, true, ParseModeBasic, store, log)
+	if err != nil || !item.Basic || calls != 0 {
+		t.Fatalf("Expected the basic document without parsing, got %+v after %d calls (%v)", item, calls, err)
+	}
+
+	gotID, item, _, err := GetOrParseDocumentWithDuplicates(ctx, "", "", fileData, "", models.ZoteroLibrary{}, models.PageRange{}, true, ParseModeFull, store, log)
+	if err != nil {
+		t.Fatalf("GetOrParseDocumentWithDuplicates failed: %v", err)
+	}
+	if gotID != docID || item.Basic || calls == 0 || item.Metadata.Citekey != citekey || len(item.Metadata.Authors) != 1 {
+		t.Errorf("Expected %s parsed with the model keeping citekey %q, got %s: %+v", docID, citekey, gotID, item.Metadata)
+	}
+}
+
+func TestGetOrParseDocument_JATS(t *testing.T) {
+	// A JATS article is parsed in full from its markup, with or without an API key
+	t.Setenv("OPENAI_API_KEY", "")
+	ctx := context.Background()
+	store := newDuplicateTestStore(t)
+	fileData, err := os.ReadFile(filepath.Join("..", "documents", "testdata", "jats", "article.xml"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	_, item, duplicate, err := GetOrParseDocumentWithDuplicates(ctx, "", "", fileData, "", models.ZoteroLibrary{}, models.PageRange{}, true, ParseModeFull, store, logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("GetOrParseDocumentWithDuplicates failed: %v", err)
+	}
+	if duplicate != nil || item.Basic || item.Metadata.MetadataSource != documents.MetadataSourceJATS || item.Metadata.Citekey != "smithJones2020" {
+		t.Errorf("Expected a full JATS parse, got %+v", item.Metadata)
+	}
+	if len(item.References) != 2 || len(item.Tables) != 1 || item.Tables[0].Columns == nil || len(item.Sections) == 0 {
+		t.Errorf("Expected references, a structured table, and sections, got %+v", item)
+	}
+}
+
+func TestGetOrParseDocument_PageRange(t *testing.T) {
+	// Each range of a file is parsed as a document of its own
+	ctx := context.Background()
+	log := logger.NewNoOpLogger()
+	t.Setenv("OPENAI_API_KEY", "")
+	store := newDuplicateTestStore(t)
+	pdfBytes, err := os.ReadFile(filepath.Join("..", "samples", "hewitt.pdf"))
+	if err != nil {
+		t.Skipf("Sample PDF not available: %v", err)
+	}
+
+	wholeID, whole, _, err := GetOrParseDocumentWithDuplicates(ctx, "", "", pdfBytes, "pdf", models.ZoteroLibrary{}, models.PageRange{}, true, ParseModeFull, store, log)
+	if err != nil {
+		t.Fatalf("GetOrParseDocumentWithDuplicates failed: %v", err)
+	}
+	rangeID, ranged, duplicate, err := GetOrParseDocumentWithDuplicates(ctx, "", "", pdfBytes, "pdf", models.ZoteroLibrary{}, models.PageRange{Start: 2, End: 3}, true, ParseModeFull, store, log)
+	if err != nil {
+		t.Fatalf("GetOrParseDocumentWithDuplicates failed: %v", err)
+	}
+	if rangeID != wholeID+"_p2-3" || duplicate != nil {
+		t.Errorf("Expected document %s_p2-3, got %s (duplicate %+v)", wholeID, rangeID, duplicate)
+	}
+	if len(ranged.Pages) != 2 || ranged.PageNumbers[0] != "2" || ranged.Pages[0] != whole.Pages[1] {
+		t.Errorf("Expected physical pages 2 and 3, got %d pages numbered %v", len(ranged.Pages), ranged.PageNumbers)
+	}
+	sourceInfo, err := store.GetSourceInfo(ctx, rangeID)
+	if err != nil || sourceInfo.Pages != (models.PageRange{Start: 2, End: 3}) {
+		t.Errorf("Expected the stored source to keep the range, got %+v (%v)", sourceInfo, err)
+	}
+
+	_, _, _, err = GetOrParseDocumentWithDuplicates(ctx, "", "", pdfBytes, "pdf", models.ZoteroLibrary{}, models.PageRange{Start: len(whole.Pages) + 1}, true, ParseModeFull, store, log)
+	var coded *models.CodedError
+	if !errors.As(err, &coded) || coded.Code != models.ErrorInvalidInput || !strings.Contains(err.Error(), fmt.Sprintf("has %d pages", len(whole.Pages))) {
+		t.Errorf("Expected invalid_input giving the page count, got %v", err)
+	}
+}
+
+func TestGetOrParseDocument_ArXiv(t *testing.T) {
+	calls := fakeParser(t)
+	arXiv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/query" {
+			fmt.Fprint(w, `<feed xmlns="http://www.w3.org/2005/Atom" xmlns:arxiv="http://arxiv.org/schemas/atom"><entry>
+<id>http://arxiv.org/abs/2101.01234v2</id><published>2021-01-04T18:59:59Z</published>
+<title>Field Notes from
+ the Archive</title><summary>An abstract.</summary><author><name>Jane Smith</name></author>
+<arxiv:primary_category term="cs.DL"/></entry></feed>`)
+			return
+		}
+		fmt.Fprintf(w, "The field notes, fetched from %s", r.URL.Path)
+	}))
+	defer arXiv.Close()
+	t.Setenv("ACADEMIC_MCP_ARXIV_URL", arXiv.URL)
+	store := newDuplicateTestStore(t)
+	ctx := context.Background()
+	log := logger.NewNoOpLogger()
+
+	// Parsed as text, as the fake PDF is not a PDF
+	docID, item, err := GetOrParseDocument(ctx, "", "https://arxiv.org/abs/2101.01234v2", nil, "txt", models.ZoteroLibrary{}, store, log)
+	if err != nil {
+		t.Fatalf("GetOrParseDocument failed: %v", err)
+	}
+	if docID != "arxiv_2101.01234v2" {
+		t.Errorf("Expected the document ID of the arXiv identifier, got %s", docID)
+	}
+	if item.Metadata.Title != "Field Notes from the Archive" || item.Metadata.PublicationDate != "2021-01-04" || item.Metadata.MetadataSource != "merged" {
+		t.Errorf("Expected the arXiv metadata merged, got %+v", item.Metadata)
+	}
+	if len(item.Metadata.Tags) != 1 || item.Metadata.Tags[0] != "cs.DL" {
+		t.Errorf("Expected the primary category as a tag, got %q", item.Metadata.Tags)
+	}
+
+	// The PDF URL of the same version is the same document
+	pdfID, _, err := GetOrParseDocument(ctx, "", "https://arxiv.org/pdf/2101.01234v2.pdf", nil, "txt", models.ZoteroLibrary{}, store, log)
+	if err != nil {
+		t.Fatalf("GetOrParseDocument failed: %v", err)
+	}
+	if pdfID != docID || calls.Load() != 1 {
+		t.Errorf("Expected %s without another parse, got %s after %d parses", docID, pdfID, calls.Load())
+	}
+}
+
+func TestGetOrParseDocument_ImageURLs(t *testing.T) {
+	// Image URLs relative to an HTML page are resolved against its URL, and
+	// those that cannot be fetched are dropped
+	ctx := context.Background()
+	log := logger.NewNoOpLogger()
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><head><title>Field Notes</title></head><body><h1>Field Notes</h1><p>The notes.</p><img src="img/map.png" alt="Map"></body></html>`)
+	}))
+	t.Cleanup(site.Close)
+	openAI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"resp_1","object":"response","created_at":0,"status":"completed","model":"gpt-5-mini","output":[{"type":"message","id":"msg_1","status":"completed","role":"assistant","content":[{"type":"output_text","text":"{\"metadata\":{\"title\":\"Field Notes\",\"authors\":[]},\"content\":\"# Field Notes\\n\\nThe notes.\",\"references\":[],\"images\":[{\"image_url\":\"img/map.png\",\"image_description\":\"Map\",\"caption\":\"\"},{\"image_url\":\"data:image/png;base64,???\",\"image_description\":\"Chart\",\"caption\":\"\"}],\"tables\":[],\"footnotes\":[],\"endnotes\":[]}","annotations":[]}]}]}`)
+	}))
+	t.Cleanup(openAI.Close)
+	t.Setenv("OPENAI_BASE_URL", openAI.URL)
+	t.Setenv("OPENAI_API_KEY", "test-key")
+
+	store := newDuplicateTestStore(t)
+	docID, _, err := GetOrParseDocument(ctx, "", site.URL+"/posts/notes.html", nil, "", models.ZoteroLibrary{}, store, log)
+	if err != nil {
+		t.Fatalf("GetOrParseDocument failed: %v", err)
+	}
+	images, err := store.GetImages(ctx, docID)
+	if err != nil {
+		t.Fatalf("GetImages failed: %v", err)
+	}
+	for i := range images {
+		if images[i].StableID == "" {
+			t.Errorf("Expected image %d to have a stable ID", i)
+		}
+		images[i].StableID = ""
+	}
+	expected := []models.Image{
+		{ImageURL: site.URL + "/posts/img/map.png", ImageDescription: "Map", SourceKind: models.ImageSourceExternalURL},
+		{ImageDescription: "Chart", SourceKind: models.ImageSourceUnavailable},
+	}
+	if !reflect.DeepEqual(images, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, images)
+	}
+}
+
+func TestGetOrParseDocument_FetchInfo(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewNoOpLogger()
+	t.Setenv("OPENAI_API_KEY", "")
+	store := newDuplicateTestStore(t)
+
+	// Raw data records its checksum, which also identifies it
+	raw := []byte("Field notes taken on the second survey of the marsh.")
+	docID, _, err := GetOrParseDocument(ctx, "", "", raw, "txt", models.ZoteroLibrary{}, store, log)
+	if err != nil {
+		t.Fatalf("GetOrParseDocument failed: %v", err)
+	}
+	fetch, err := store.GetFetchInfo(ctx, docID)
+	if err != nil {
+		t.Fatalf("GetFetchInfo failed: %v", err)
+	}
+	if fetch == nil || fetch.Size != int64(len(raw)) || docID != "data_"+fetch.SHA256[:32] || fetch.SourceURL != "" || fetch.FetchedAt != "" {
+		t.Errorf("Expected the checksum and size of the raw data for %s, got %+v", docID, fetch)
+	}
+
+	// A URL records where and when it was fetched from
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, "Notes from the third survey.")
+	}))
+	t.Cleanup(site.Close)
+	docID, _, err = GetOrParseDocument(ctx, "", site.URL+"/notes.txt", nil, "", models.ZoteroLibrary{}, store, log)
+	if err != nil {
+		t.Fatalf("GetOrParseDocument failed: %v", err)
+	}
+	fetch, err = store.GetFetchInfo(ctx, docID)
+	if err != nil {
+		t.Fatalf("GetFetchInfo failed: %v", err)
+	}
+	if fetch == nil || fetch.SourceURL != site.URL+"/notes.txt" || fetch.FinalURL != site.URL+"/notes.txt" || fetch.ContentType != "text/plain" || fetch.FetchedAt == "" {
+		t.Errorf("Expected the fetch info of the URL, got %+v", fetch)
+	}
+}
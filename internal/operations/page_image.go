@@ -0,0 +1,82 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// PageImage is a page of a stored document rendered from its source PDF
+type PageImage struct {
+	DocumentID       string
+	Page             int    // Sequential page number (1-indexed)
+	SourcePageNumber string // Page number as printed in the source
+	DPI              int
+	Data             []byte // PNG
+	Cached           bool   // Rendered by an earlier request rather than this one
+}
+
+// RenderPageImage returns the PNG of a stored document's page, named by its
+// source page number, at the resolution set by ACADEMIC_MCP_PAGE_IMAGE_DPI.
+// Pages are rendered on their first request, from the PDF re-fetched from
+// Zotero or the URL the document came from, and kept until the document is
+// stored again. Documents parsed from raw data have no source to render from
+// and return a not_found error; see documents.RenderPDFPage for the pages
+// that can be rendered.
+func RenderPageImage(ctx context.Context, store storage.Store, docID string, sourcePage string, log logger.Logger) (*PageImage, error) {
+	log = log.With("document_id", docID)
+	dpi, err := documents.PageImageDPI()
+	if err != nil {
+		log.Warn("%v", err)
+	}
+
+	sourceInfo, err := store.GetSourceInfo(ctx, docID)
+	if err != nil {
+		return nil, err
+	}
+	mapping, err := store.GetPageMapping(ctx, docID)
+	if err != nil {
+		return nil, err
+	}
+	page, ok := mapping[sourcePage]
+	if !ok {
+		return nil, fmt.Errorf("page %w: %s has no page %s", storage.ErrNotFound, docID, sourcePage)
+	}
+	image := &PageImage{DocumentID: docID, Page: page, SourcePageNumber: sourcePage, DPI: dpi}
+
+	data, err := store.GetPageImage(ctx, docID, page, dpi)
+	if err == nil {
+		image.Data, image.Cached = data, true
+		return image, nil
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+
+	if sourceInfo.ZoteroID == "" && sourceInfo.URL == "" {
+		return nil, models.WithErrorCode(models.ErrorNotFound, fmt.Errorf("document %s has no source PDF to render pages from: it was parsed from raw data", docID))
+	}
+	source, err := documents.GetData(ctx, *sourceInfo)
+	if err != nil {
+		return nil, models.WithErrorCode(models.ErrorUpstreamFetch, fmt.Errorf("failed to fetch document data: %w", err))
+	}
+	if source.Type != "pdf" {
+		return nil, models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("pages can only be rendered from PDF documents (document type: %s)", source.Type))
+	}
+
+	log.Info("Rendering page %s (page %d) at %d dpi", sourcePage, page, dpi)
+	data, err = documents.RenderPDFPage(source, page, dpi)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render page %s: %w", sourcePage, err)
+	}
+	if err := store.StorePageImage(ctx, docID, page, dpi, data); err != nil {
+		return nil, models.WithErrorCode(models.ErrorStorage, err)
+	}
+	image.Data = data
+	return image, nil
+}
@@ -0,0 +1,138 @@
+package operations
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// buildScanPdf builds a one-page PDF that only draws a 64 pixel gray image,
+// as a scanned page does
+func buildScanPdf() []byte {
+	const size = 64
+	const content = "q 612 0 0 792 0 0 cm /Im0 Do Q"
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /XObject << /Im0 4 0 R >> >> /Contents 5 0 R >>",
+		fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceGray /BitsPerComponent 8 /Length %d >>\nstream\n%s\nendstream",
+			size, size, size*size, strings.Repeat("\x80", size*size)),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xrefOffset := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xrefOffset)
+	return buf.Bytes()
+}
+
+// storeScannedDocument stores a one-page document whose source is a scanned
+// PDF served over HTTP, returning how many times the PDF has been fetched
+func storeScannedDocument(t *testing.T, store storage.Store, docID string) *atomic.Int32 {
+	t.Helper()
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write(buildScanPdf())
+	}))
+	t.Cleanup(server.Close)
+
+	item := &models.ParsedItem{
+		Metadata:    models.ItemMetadata{Title: "Field Notes"},
+		Pages:       []string{"Transcribed notes"},
+		PageNumbers: []string{"iv"},
+	}
+	if err := store.StoreParsedItem(context.Background(), docID, item, &models.SourceInfo{URL: server.URL + "/notes.pdf"}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+	return &fetches
+}
+
+func TestRenderPageImage_Caching(t *testing.T) {
+	t.Setenv("ACADEMIC_MCP_PAGE_IMAGE_DPI", "72")
+	store := newDuplicateTestStore(t)
+	ctx := context.Background()
+	log := logger.NewNoOpLogger()
+	fetches := storeScannedDocument(t, store, "url_scan")
+
+	first, err := RenderPageImage(ctx, store, "url_scan", "iv", log)
+	if err != nil {
+		t.Fatalf("RenderPageImage failed: %v", err)
+	}
+	if first.Cached || first.Page != 1 || first.DPI != 72 || !bytes.HasPrefix(first.Data, []byte("\x89PNG")) {
+		t.Errorf("Expected a fresh PNG of page 1 at 72 dpi, got %+v", first)
+	}
+	if fetches.Load() != 1 {
+		t.Errorf("Expected the source fetched once, got %d", fetches.Load())
+	}
+
+	// The second request is served from the cache without fetching the source
+	second, err := RenderPageImage(ctx, store, "url_scan", "iv", log)
+	if err != nil {
+		t.Fatalf("RenderPageImage failed: %v", err)
+	}
+	if !second.Cached || !bytes.Equal(second.Data, first.Data) {
+		t.Errorf("Expected the cached image, got %+v", second)
+	}
+	if fetches.Load() != 1 {
+		t.Errorf("Expected no fetch for a cached page, got %d fetches", fetches.Load())
+	}
+
+	// Another resolution is rendered separately
+	t.Setenv("ACADEMIC_MCP_PAGE_IMAGE_DPI", "144")
+	larger, err := RenderPageImage(ctx, store, "url_scan", "iv", log)
+	if err != nil {
+		t.Fatalf("RenderPageImage failed: %v", err)
+	}
+	if larger.Cached || larger.DPI != 144 || fetches.Load() != 2 {
+		t.Errorf("Expected a fresh render at 144 dpi, got %+v after %d fetches", larger, fetches.Load())
+	}
+}
+
+func TestRenderPageImage_Errors(t *testing.T) {
+	store := newDuplicateTestStore(t)
+	ctx := context.Background()
+	log := logger.NewNoOpLogger()
+	fetches := storeScannedDocument(t, store, "url_scan")
+	if err := store.StoreParsedItem(ctx, "data_0123456789abcdef", &models.ParsedItem{Pages: []string{"Text"}, PageNumbers: []string{"1"}}, &models.SourceInfo{}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+
+	// A document parsed from raw data has no source to render from
+	_, err := RenderPageImage(ctx, store, "data_0123456789abcdef", "1", log)
+	var coded *models.CodedError
+	if !errors.As(err, &coded) || coded.Code != models.ErrorNotFound || !strings.Contains(err.Error(), "no source PDF") {
+		t.Errorf("Expected a not_found error for a document without a source, got %v", err)
+	}
+
+	if _, err := RenderPageImage(ctx, store, "url_scan", "v", log); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown page, got %v", err)
+	}
+	if _, err := RenderPageImage(ctx, store, "missing", "1", log); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown document, got %v", err)
+	}
+	if fetches.Load() != 0 {
+		t.Errorf("Expected no fetch for requests that cannot be rendered, got %d", fetches.Load())
+	}
+}
@@ -0,0 +1,33 @@
+package operations
+
+import (
+	"context"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// storePageStaging stages the pages of a document's parse in the store, so a
+// parse interrupted by a crash or cancellation resumes from them the next time
+// the document is parsed (see llm.PageStaging)
+type storePageStaging struct {
+	store storage.Store
+	docID string
+}
+
+func (s storePageStaging) StagedPages(ctx context.Context) ([]models.StagedPage, error) {
+	return s.store.GetStagedPages(ctx, s.docID)
+}
+
+func (s storePageStaging) StagePage(ctx context.Context, page models.StagedPage) error {
+	return s.store.StagePage(ctx, s.docID, page)
+}
+
+// clearStagedPages removes the pages staged for a document once its parse has
+// finished. A failure only leaves rows behind, so it is logged.
+func clearStagedPages(ctx context.Context, store storage.Store, docID string, log logger.Logger) {
+	if err := store.ClearStagedPages(context.WithoutCancel(ctx), docID); err != nil {
+		log.Warn("Failed to clear staged pages of %s: %v", docID, err)
+	}
+}
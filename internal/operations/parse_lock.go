@@ -0,0 +1,132 @@
+package operations
+
+import (
+	"context"
+	"crypto/rand"
+	"sync"
+	"time"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// parseLockTTL is how long a document's parsing marker lasts unless refreshed.
+// The marker is refreshed while the parse runs, so one left by a process that
+// crashed mid-parse stops blocking other processes within this time.
+const parseLockTTL = 2 * time.Minute
+
+// parseLockPoll is how often a parse waiting on another process's marker
+// checks it again
+const parseLockPoll = time.Second
+
+// parseLockOwner identifies this process's parsing markers in the store
+var parseLockOwner = rand.Text()
+
+// parseLocks serializes parses of the same document within this process
+var parseLocks = &documentLocks{locks: make(map[string]*documentLock)}
+
+// documentLocks holds a lock for each document some caller is waiting on or
+// holding. A document's lock is dropped once nobody needs it.
+type documentLocks struct {
+	mu    sync.Mutex
+	locks map[string]*documentLock
+}
+
+type documentLock struct {
+	held  chan struct{} // Holds a value while the lock is held
+	users int           // Callers holding or waiting for the lock
+}
+
+// lock waits until the document's lock is free or ctx is done, returning the
+// function that releases it
+func (l *documentLocks) lock(ctx context.Context, docID string) (func(), error) {
+	l.mu.Lock()
+	dl, ok := l.locks[docID]
+	if !ok {
+		dl = &documentLock{held: make(chan struct{}, 1)}
+		l.locks[docID] = dl
+	}
+	dl.users++
+	l.mu.Unlock()
+
+	done := func() {
+		l.mu.Lock()
+		dl.users--
+		if dl.users == 0 {
+			delete(l.locks, docID)
+		}
+		l.mu.Unlock()
+	}
+
+	select {
+	case dl.held <- struct{}{}:
+		return func() {
+			<-dl.held
+			done()
+		}, nil
+	case <-ctx.Done():
+		done()
+		return nil, ctx.Err()
+	}
+}
+
+// lockParse waits until no other caller is parsing the document, in this
+// process or in another sharing the store, and marks it as being parsed. The
+// caller should check the store again once it holds the lock, as the parse it
+// waited on has usually stored the document. The returned function releases
+// the lock.
+func lockParse(ctx context.Context, store storage.Store, docID string, log logger.Logger) (func(), error) {
+	unlock, err := parseLocks.lock(ctx, docID)
+	if err != nil {
+		return nil, err
+	}
+
+	for waited := false; ; waited = true {
+		acquired, err := store.AcquireParseLock(ctx, docID, parseLockOwner, parseLockTTL)
+		if err != nil {
+			unlock()
+			return nil, models.WithErrorCode(models.ErrorStorage, err)
+		}
+		if acquired {
+			break
+		}
+		if !waited {
+			log.Info("Document %s is being parsed by another process, waiting for it", docID)
+		}
+		select {
+		case <-ctx.Done():
+			unlock()
+			return nil, ctx.Err()
+		case <-time.After(parseLockPoll):
+		}
+	}
+
+	// Keep the marker from expiring while the parse runs
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(parseLockTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if _, err := store.AcquireParseLock(context.WithoutCancel(ctx), docID, parseLockOwner, parseLockTTL); err != nil {
+					log.Warn("Failed to refresh parsing marker of document %s: %v", docID, err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+		<-stopped
+		if err := store.ReleaseParseLock(context.WithoutCancel(ctx), docID, parseLockOwner); err != nil {
+			log.Warn("Failed to release parsing marker of document %s: %v", docID, err)
+		}
+		unlock()
+	}, nil
+}
@@ -0,0 +1,102 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// fakeParser serves OpenAI parse responses slowly enough for concurrent callers
+// to overlap, counting the requests
+func fakeParser(t *testing.T) *atomic.Int32 {
+	t.Helper()
+	var calls atomic.Int32
+	openAI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"resp_1","object":"response","created_at":0,"status":"completed","model":"gpt-5-mini","output":[{"type":"message","id":"msg_1","status":"completed","role":"assistant","content":[{"type":"output_text","text":"{\"metadata\":{\"title\":\"Field Notes\",\"authors\":[\"Smith, Jane\"]},\"content\":\"The full field notes.\",\"references\":[],\"images\":[],\"tables\":[],\"footnotes\":[],\"endnotes\":[]}","annotations":[]}]}]}`)
+	}))
+	t.Cleanup(openAI.Close)
+	t.Setenv("OPENAI_BASE_URL", openAI.URL)
+	t.Setenv("OPENAI_API_KEY", "test-key")
+	return &calls
+}
+
+func TestGetOrParseDocument_ConcurrentCallsParseOnce(t *testing.T) {
+	calls := fakeParser(t)
+	store := newDuplicateTestStore(t)
+	fileData := []byte("Field notes, requested by several tools at once")
+
+	const callers = 8
+	docIDs := make([]string, callers)
+	errs := make([]error, callers)
+	var wg sync.WaitGroup
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			docIDs[i], _, errs[i] = GetOrParseDocument(context.Background(), "", "", fileData, "txt", models.ZoteroLibrary{}, store, logger.NewNoOpLogger())
+		}()
+	}
+	wg.Wait()
+
+	for i := range callers {
+		if errs[i] != nil {
+			t.Fatalf("GetOrParseDocument failed: %v", errs[i])
+		}
+		if docIDs[i] != docIDs[0] {
+			t.Errorf("Expected every caller to get document %s, got %s", docIDs[0], docIDs[i])
+		}
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("Expected exactly one parse, got %d", got)
+	}
+	if len(parseLocks.locks) != 0 {
+		t.Errorf("Expected no document locks left, got %d", len(parseLocks.locks))
+	}
+}
+
+func TestGetOrParseDocument_WaitsForAnotherProcess(t *testing.T) {
+	calls := fakeParser(t)
+	store := newDuplicateTestStore(t)
+	ctx := context.Background()
+	fileData := []byte("Field notes, parsed by another process")
+	docID := storage.GenerateDocumentID(&models.SourceInfo{}, models.DocumentData{Data: fileData})
+
+	// Another process is parsing the document
+	if ok, err := store.AcquireParseLock(ctx, docID, "other-process", time.Minute); err != nil || !ok {
+		t.Fatalf("AcquireParseLock failed: %v", err)
+	}
+	shortCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if _, _, err := GetOrParseDocument(shortCtx, "", "", fileData, "txt", models.ZoteroLibrary{}, store, logger.NewNoOpLogger()); err == nil {
+		t.Fatal("Expected the call to wait on the other process's parse until cancelled")
+	}
+	if calls.Load() != 0 {
+		t.Errorf("Expected no parse while another process holds the marker, got %d", calls.Load())
+	}
+
+	// It finishes, storing the document, and the waiting call uses its parse
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		store.StoreParsedItem(ctx, docID, &models.ParsedItem{Metadata: models.ItemMetadata{Title: "Field Notes"}, Pages: []string{"Parsed elsewhere"}}, &models.SourceInfo{})
+		store.ReleaseParseLock(ctx, docID, "other-process")
+	}()
+	gotID, item, err := GetOrParseDocument(ctx, "", "", fileData, "txt", models.ZoteroLibrary{}, store, logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("GetOrParseDocument failed: %v", err)
+	}
+	if gotID != docID || len(item.Pages) != 1 || item.Pages[0] != "Parsed elsewhere" || calls.Load() != 0 {
+		t.Errorf("Expected the other process's parse of %s, got %s with %v after %d parses", docID, gotID, item.Pages, calls.Load())
+	}
+}
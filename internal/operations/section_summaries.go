@@ -0,0 +1,59 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
+	"github.com/Epistemic-Technology/academic-mcp/internal/llm"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// SummarizeBySections summarizes a long document section by section: each
+// top-level section of its section index (see documents.TopLevelSections) is
+// summarized on its own, in parallel, and the overall summary is then composed
+// from the section summaries, referring to the sections by title. The section
+// index is built from the pages if the document has none stored. A document
+// without headings returns an invalid_input error; it can only be summarized
+// as a whole.
+func SummarizeBySections(ctx context.Context, apiKey string, item *models.ParsedItem, opts llm.SummaryOptions, log logger.Logger) (string, []models.SectionSummary, error) {
+	sections := item.Sections
+	if len(sections) == 0 {
+		sections = documents.ExtractSections(item.Pages, item.PageNumbers)
+	}
+	top := documents.TopLevelSections(sections)
+	if len(top) == 0 {
+		return "", nil, models.WithErrorCode(models.ErrorInvalidInput, errors.New("document has no section headings to summarize by; use granularity \"document\""))
+	}
+
+	text := documents.JoinPages(item.Pages)
+	contents := make([]llm.SectionContent, len(top))
+	summaries := make([]models.SectionSummary, len(top))
+	for i, index := range top {
+		section := sections[index]
+		start, end := min(section.StartOffset, len(text)), min(section.EndOffset, len(text))
+		contents[i] = llm.SectionContent{Title: section.Title, Content: strings.TrimSpace(text[start:max(start, end)])}
+		summaries[i] = models.SectionSummary{
+			SectionIndex: index,
+			Title:        section.Title,
+			StartPage:    section.StartPage,
+			EndPage:      section.EndPage,
+		}
+	}
+
+	sectionSummaries, err := llm.SummarizeSections(ctx, apiKey, contents, opts, log)
+	if err != nil {
+		return "", nil, err
+	}
+	for i := range summaries {
+		summaries[i].Summary = sectionSummaries[i]
+	}
+
+	summary, err := llm.ComposeSummary(ctx, apiKey, summaries, opts, log)
+	if err != nil {
+		return "", nil, err
+	}
+	return summary, summaries, nil
+}
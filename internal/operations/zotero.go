@@ -3,21 +3,36 @@ package operations
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
+	"sync"
 
+	"github.com/Epistemic-Technology/academic-mcp/internal/citations"
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
+	"github.com/Epistemic-Technology/academic-mcp/internal/llm"
 	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
 	"github.com/Epistemic-Technology/zotero/zotero"
 )
 
 // ZoteroSearchParams contains parameters for searching a Zotero library.
 type ZoteroSearchParams struct {
 	Query      string   // Quick search text (searches title, creator, year)
+	DOI        string   // Find the items with this DOI (instead of Query)
+	ISBN       string   // Find the items with this ISBN, as ISBN-10 or ISBN-13 (instead of Query)
 	Tags       []string // Filter by tags
 	ItemTypes  []string // Filter by type (e.g., "book", "article", "-attachment")
 	Collection string   // Filter by collection key (optional)
 	Limit      int      // Max results (default 25)
 	Sort       string   // Sort field (default "dateModified")
+	Refresh    bool     // Fetch attachment listings from Zotero even if cached
 }
 
+// zoteroConcurrency caps the attachment listings fetched from the Zotero API at
+// once, keeping well within its rate limits
+const zoteroConcurrency = 4
+
 // ZoteroItemResult represents a Zotero item with its attachments.
 type ZoteroItemResult struct {
 	Key         string
@@ -40,26 +55,40 @@ type AttachmentInfo struct {
 // processed results with attachments. This function encapsulates the common
 // logic for searching Zotero and can be reused across multiple tools.
 //
+// Attachment listings are fetched in parallel and cached in the store for
+// ZoteroCacheTTL, so repeating a search only costs the search request itself.
+// params.Refresh bypasses the cache, and a nil store disables it.
+//
+// A search by params.DOI or params.ISBN looks for the identifier in every
+// field and keeps only the items that have it in their DOI or ISBN field or in
+// a "DOI:" or "ISBN:" line of their Extra field.
+//
 // Parameters:
 //   - ctx: Context for cancellation and timeouts
 //   - apiKey: Zotero API key for authentication
-//   - libraryID: Zotero library ID (user or group)
-//   - params: Search parameters (query, tags, item types, limit, sort)
+//   - library: Zotero library (user or group) to search
+//   - params: Search parameters (query or identifier, tags, item types, limit, sort, refresh)
+//   - store: Storage backend caching attachment listings (optional)
 //   - log: Logger for recording operations
 //
 // Returns:
 //   - results: Array of processed items with metadata and attachments
 //   - error: Any error encountered during the search
-func SearchZotero(ctx context.Context, apiKey, libraryID string, params ZoteroSearchParams, log logger.Logger) ([]ZoteroItemResult, error) {
+func SearchZotero(ctx context.Context, apiKey string, library models.ZoteroLibrary, params ZoteroSearchParams, store storage.Store, log logger.Logger) ([]ZoteroItemResult, error) {
 	if apiKey == "" {
 		return nil, fmt.Errorf("Zotero API key is required")
 	}
-	if libraryID == "" {
+	if library.ID == "" {
 		return nil, fmt.Errorf("Zotero library ID is required")
 	}
 
+	match, queries, err := identifierMatcher(params)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create Zotero client
-	client := zotero.NewClient(libraryID, zotero.LibraryTypeUser, zotero.WithAPIKey(apiKey))
+	client := documents.NewZoteroClient(library, apiKey)
 
 	// Set up query parameters
 	queryParams := &zotero.QueryParams{
@@ -82,84 +111,311 @@ func SearchZotero(ctx context.Context, apiKey, libraryID string, params ZoteroSe
 		queryParams.ItemType = []string{"-attachment"}
 	}
 
-	// Search for items (either in a specific collection or the entire library)
 	var items []zotero.Item
-	var err error
-	if params.Collection != "" {
-		// If we're retriving items in a collection, we want to retrieve the max number of items (100)
-		if queryParams.Limit == 0 {
-			queryParams.Limit = 100
-		}
-		items, err = client.CollectionItems(ctx, params.Collection, queryParams)
+	if match == nil {
+		items, err = searchZoteroItems(ctx, client, params.Collection, queryParams, false, log)
 		if err != nil {
-			log.Error("Failed to search collection %s: %v", params.Collection, err)
-			return nil, fmt.Errorf("failed to search collection %s: %w", params.Collection, err)
+			return nil, err
 		}
 	} else {
-		// Search the entire library
-		items, err = client.Items(ctx, queryParams)
-		if err != nil {
-			log.Error("Failed to search Zotero library: %v", err)
-			return nil, fmt.Errorf("failed to search Zotero library: %w", err)
+		// Identifiers can sit in any field, including Extra, so search
+		// everything and keep only the items whose identifiers match. The
+		// stored form of an ISBN varies, so each form is tried in turn.
+		queryParams.QMode = "everything"
+		for _, q := range queries {
+			queryParams.Q = q
+			found, err := searchZoteroItems(ctx, client, params.Collection, queryParams, true, log)
+			if err != nil {
+				return nil, err
+			}
+			for i := range found {
+				if match(&found[i]) {
+					items = append(items, found[i])
+				}
+			}
+			if len(items) > 0 {
+				break
+			}
 		}
 	}
 
 	log.Info("Found %d items in Zotero library", len(items))
 
-	// Process each item and retrieve attachments
+	// Process each item (skipping attachment items themselves, since we want
+	// parent items with attachments)
 	results := make([]ZoteroItemResult, 0, len(items))
-	for _, item := range items {
-		// Skip attachment items themselves (we want parent items with attachments)
-		if item.Data.ItemType == "attachment" {
+	for i := range items {
+		if items[i].Data.ItemType == "attachment" {
 			continue
 		}
+		results = append(results, zoteroItemResult(&items[i]))
+	}
 
-		result := ZoteroItemResult{
-			Key:      item.Key,
-			Title:    item.Data.Title,
-			ItemType: item.Data.ItemType,
-			Date:     item.Data.DateAdded,
+	// Retrieve attachments for each item, from the cache where possible
+	cache := newZoteroCache(store, library, params.Refresh, log)
+	failed, err := fetchAttachments(ctx, client, cache, results, log)
+	if err != nil {
+		return nil, err
+	}
+
+	// Drop items whose attachments could not be retrieved
+	kept := results[:0]
+	for i, result := range results {
+		if !failed[i] {
+			kept = append(kept, result)
 		}
+	}
+	results = kept
 
-		// Extract creator names
-		for _, creator := range item.Data.Creators {
-			if creator.Name != "" {
-				result.Creators = append(result.Creators, creator.Name)
-			} else if creator.FirstName != "" || creator.LastName != "" {
-				name := creator.FirstName
-				if name != "" && creator.LastName != "" {
-					name += " "
-				}
-				name += creator.LastName
-				result.Creators = append(result.Creators, name)
+	log.Info("Returning %d processed items", len(results))
+
+	return results, nil
+}
+
+// searchZoteroItems runs one search, either in a specific collection or the
+// entire library. A search by identifier needs every field of the items found,
+// which only documents.SearchZoteroItems decodes.
+func searchZoteroItems(ctx context.Context, client *zotero.Client, collection string, queryParams *zotero.QueryParams, allFields bool, log logger.Logger) ([]zotero.Item, error) {
+	var items []zotero.Item
+	var err error
+	switch {
+	case allFields:
+		items, err = documents.SearchZoteroItems(ctx, client, collection, queryParams)
+	case collection != "":
+		items, err = client.CollectionItems(ctx, collection, queryParams)
+	default:
+		items, err = client.Items(ctx, queryParams)
+	}
+	if err != nil {
+		if collection != "" {
+			log.Error("Failed to search collection %s: %v", collection, err)
+			return nil, models.WithErrorCode(models.ErrorUpstreamZotero, fmt.Errorf("failed to search collection %s: %w", collection, err))
+		}
+		log.Error("Failed to search Zotero library: %v", err)
+		return nil, models.WithErrorCode(models.ErrorUpstreamZotero, fmt.Errorf("failed to search Zotero library: %w", err))
+	}
+	return items, nil
+}
+
+// identifierMatcher returns, for a search by DOI or ISBN, a function reporting
+// whether an item has that identifier and the quick search texts to find it
+// with. It returns a nil function for a search by query.
+func identifierMatcher(params ZoteroSearchParams) (func(*zotero.Item) bool, []string, error) {
+	set := 0
+	for _, value := range []string{params.Query, params.DOI, params.ISBN} {
+		if strings.TrimSpace(value) != "" {
+			set++
+		}
+	}
+	if set > 1 {
+		return nil, nil, models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("query, doi, and isbn cannot be combined"))
+	}
+
+	switch {
+	case strings.TrimSpace(params.DOI) != "":
+		doi, ok := citations.NormalizeDOI(params.DOI)
+		if !ok {
+			return nil, nil, models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("invalid DOI %q (expected the form 10.1234/suffix)", params.DOI))
+		}
+		match := func(item *zotero.Item) bool {
+			dois, _ := documents.ZoteroItemIdentifiers(item)
+			return slices.Contains(dois, doi)
+		}
+		return match, []string{doi}, nil
+	case strings.TrimSpace(params.ISBN) != "":
+		isbn, ok := citations.NormalizeISBN(params.ISBN)
+		if !ok {
+			return nil, nil, models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("invalid ISBN %q", params.ISBN))
+		}
+		match := func(item *zotero.Item) bool {
+			_, isbns := documents.ZoteroItemIdentifiers(item)
+			return slices.Contains(isbns, isbn)
+		}
+		queries := []string{strings.TrimSpace(params.ISBN)}
+		for _, form := range []string{isbn, isbn10(isbn)} {
+			if form != "" && !slices.Contains(queries, form) {
+				queries = append(queries, form)
 			}
 		}
+		return match, queries, nil
+	}
+	return nil, nil, nil
+}
 
-		// Retrieve attachments for this item
-		children, err := client.Children(ctx, item.Key, nil)
-		if err != nil {
-			log.Error("Failed to retrieve children for item %s: %v", item.Key, err)
-			// Continue processing other items
+// isbn10 returns the ISBN-10 form of a normalized ISBN, or "" if it has none
+func isbn10(isbn string) string {
+	short, _ := citations.ISBN10(isbn)
+	return short
+}
+
+// zoteroItemResult returns the search result for an item, without its attachments
+func zoteroItemResult(item *zotero.Item) ZoteroItemResult {
+	result := ZoteroItemResult{
+		Key:      item.Key,
+		Title:    item.Data.Title,
+		ItemType: item.Data.ItemType,
+		Date:     item.Data.DateAdded,
+	}
+
+	// Extract creator names
+	for _, creator := range item.Data.Creators {
+		if creator.Name != "" {
+			result.Creators = append(result.Creators, creator.Name)
+		} else if creator.FirstName != "" || creator.LastName != "" {
+			name := creator.FirstName
+			if name != "" && creator.LastName != "" {
+				name += " "
+			}
+			name += creator.LastName
+			result.Creators = append(result.Creators, name)
+		}
+	}
+	return result
+}
+
+// fetchAttachments fills in the attachments of each result, from the cache
+// where possible and otherwise from Zotero a few at a time. It reports which
+// results' attachments could not be retrieved.
+func fetchAttachments(ctx context.Context, client *zotero.Client, cache *zoteroCache, results []ZoteroItemResult, log logger.Logger) ([]bool, error) {
+	wp := llm.NewWorkerPool(zoteroConcurrency)
+	failed := make([]bool, len(results))
+	var wg sync.WaitGroup
+	cached := 0
+
+	for i := range results {
+		if attachments, ok := cache.getAttachments(ctx, results[i].Key); ok {
+			results[i].Attachments = attachments
+			cached++
 			continue
 		}
 
-		// Filter for attachment-type children
-		for _, child := range children {
-			if child.Data.ItemType == "attachment" {
-				attachment := AttachmentInfo{
-					Key:         child.Key,
-					Filename:    child.Data.Filename,
-					ContentType: child.Data.ContentType,
-					LinkMode:    child.Data.LinkMode,
+		if err := wp.Acquire(ctx); err != nil {
+			wg.Wait()
+			return nil, fmt.Errorf("Zotero search cancelled: %w", err)
+		}
+		wg.Add(1)
+		go func(result *ZoteroItemResult, failed *bool) {
+			defer wg.Done()
+			defer wp.Release()
+
+			children, err := client.Children(ctx, result.Key, nil)
+			if err != nil {
+				log.Error("Failed to retrieve children for item %s: %v", result.Key, err)
+				// Continue processing other items
+				*failed = true
+				return
+			}
+
+			// Filter for attachment-type children
+			for _, child := range children {
+				if child.Data.ItemType == "attachment" {
+					result.Attachments = append(result.Attachments, attachmentInfo(&child))
 				}
-				result.Attachments = append(result.Attachments, attachment)
 			}
+			cache.putAttachments(ctx, result.Key, result.Attachments)
+		}(&results[i], &failed[i])
+	}
+	wg.Wait()
+
+	if cached > 0 {
+		log.Info("Used cached attachments for %d of %d items", cached, len(results))
+	}
+	return failed, nil
+}
+
+func attachmentInfo(item *zotero.Item) AttachmentInfo {
+	return AttachmentInfo{
+		Key:         item.Key,
+		Filename:    item.Data.Filename,
+		ContentType: item.Data.ContentType,
+		LinkMode:    item.Data.LinkMode,
+	}
+}
+
+// ZoteroItemDetails is a Zotero item's full metadata with its attachments
+type ZoteroItemDetails struct {
+	Key          string
+	Metadata     *models.ItemMetadata
+	DateAdded    string
+	DateModified string
+	Tags         []string
+	Attachments  []AttachmentInfo
+	// AttachmentKey is set when the requested key was one of the item's
+	// attachments rather than the item itself
+	AttachmentKey string
+}
+
+// GetZoteroItem fetches one Zotero item by key, without a search, and returns
+// its metadata (converted as in FetchZoteroMetadata) and attachments. The key
+// of an attachment returns its parent item, with AttachmentKey set; an
+// attachment without a parent is returned as its own only attachment.
+// Attachment listings share the cache of SearchZotero.
+func GetZoteroItem(ctx context.Context, apiKey string, library models.ZoteroLibrary, key string, refresh bool, store storage.Store, log logger.Logger) (*ZoteroItemDetails, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("Zotero API key is required")
+	}
+	if library.ID == "" {
+		return nil, fmt.Errorf("Zotero library ID is required")
+	}
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return nil, models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("item key is required"))
+	}
+
+	client := documents.NewZoteroClient(library, apiKey)
+	item, err := getZoteroItem(ctx, client, key, log)
+	if err != nil {
+		return nil, err
+	}
+
+	var attachmentKey string
+	if item.Data.ItemType == "attachment" {
+		if item.Data.ParentItem == "" {
+			details := zoteroItemDetails(item)
+			details.Attachments = []AttachmentInfo{attachmentInfo(item)}
+			details.AttachmentKey = item.Key
+			return details, nil
 		}
+		attachmentKey = item.Key
+		if item, err = getZoteroItem(ctx, client, item.Data.ParentItem, log); err != nil {
+			return nil, err
+		}
+	}
 
-		results = append(results, result)
+	details := zoteroItemDetails(item)
+	details.AttachmentKey = attachmentKey
+	results := []ZoteroItemResult{{Key: item.Key}}
+	cache := newZoteroCache(store, library, refresh, log)
+	failed, err := fetchAttachments(ctx, client, cache, results, log)
+	if err != nil {
+		return nil, err
+	}
+	if failed[0] {
+		return nil, models.WithErrorCode(models.ErrorUpstreamZotero, fmt.Errorf("failed to retrieve attachments of Zotero item %s", item.Key))
 	}
+	details.Attachments = results[0].Attachments
+	return details, nil
+}
 
-	log.Info("Returning %d processed items", len(results))
+// getZoteroItem fetches one item with all of its fields
+func getZoteroItem(ctx context.Context, client *zotero.Client, key string, log logger.Logger) (*zotero.Item, error) {
+	item, err := documents.GetZoteroItem(ctx, client, key)
+	if err != nil {
+		log.Error("Failed to fetch Zotero item %s: %v", key, err)
+		return nil, models.WithErrorCode(models.ErrorUpstreamZotero, err)
+	}
+	return item, nil
+}
 
-	return results, nil
+func zoteroItemDetails(item *zotero.Item) *ZoteroItemDetails {
+	details := &ZoteroItemDetails{
+		Key:          item.Key,
+		Metadata:     documents.ZoteroItemMetadata(item),
+		DateAdded:    item.Data.DateAdded,
+		DateModified: item.Data.DateModified,
+	}
+	for _, tag := range item.Data.Tags {
+		details.Tags = append(details.Tags, tag.Tag)
+	}
+	return details
 }
@@ -0,0 +1,103 @@
+package operations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// zoteroCacheTTLEnv names the environment variable that sets how long cached
+// Zotero responses are used
+const zoteroCacheTTLEnv = "ACADEMIC_MCP_ZOTERO_CACHE_TTL"
+
+// defaultZoteroCacheTTL is how long cached Zotero responses are used by default
+const defaultZoteroCacheTTL = time.Hour
+
+// ZoteroCacheTTL returns how long cached Zotero responses are used, set by
+// ACADEMIC_MCP_ZOTERO_CACHE_TTL as a Go duration (e.g., "30m"). Zero disables
+// the cache. An invalid value returns the default and an error.
+func ZoteroCacheTTL() (time.Duration, error) {
+	value := strings.TrimSpace(os.Getenv(zoteroCacheTTLEnv))
+	if value == "" {
+		return defaultZoteroCacheTTL, nil
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl < 0 {
+		return defaultZoteroCacheTTL, fmt.Errorf("invalid %s %q (expected a duration such as 30m, or 0 to disable)", zoteroCacheTTLEnv, value)
+	}
+	return ttl, nil
+}
+
+// zoteroCache caches the attachment listings of Zotero items in the store.
+// A nil *zoteroCache caches nothing.
+type zoteroCache struct {
+	store   storage.Store
+	library models.ZoteroLibrary
+	ttl     time.Duration
+	refresh bool // Ignore cached entries, replacing them with fresh ones
+	log     logger.Logger
+}
+
+// newZoteroCache returns the cache for a library, or nil if there is no store
+// or caching is disabled
+func newZoteroCache(store storage.Store, library models.ZoteroLibrary, refresh bool, log logger.Logger) *zoteroCache {
+	if store == nil {
+		return nil
+	}
+	ttl, err := ZoteroCacheTTL()
+	if err != nil {
+		log.Warn("Using default Zotero cache TTL: %v", err)
+	}
+	if ttl == 0 {
+		return nil
+	}
+	return &zoteroCache{store: store, library: library, ttl: ttl, refresh: refresh, log: log}
+}
+
+// key identifies a cached response for an item of the cache's library
+func (c *zoteroCache) key(kind string, itemKey string) string {
+	return fmt.Sprintf("%s/%s/%s/%s", c.library.Type, c.library.ID, itemKey, kind)
+}
+
+// getAttachments returns the cached attachments of an item, or false if they are
+// not cached or have expired
+func (c *zoteroCache) getAttachments(ctx context.Context, itemKey string) ([]AttachmentInfo, bool) {
+	if c == nil || c.refresh {
+		return nil, false
+	}
+	data, fetchedAt, err := c.store.GetZoteroCache(ctx, c.key("attachments", itemKey))
+	if err != nil {
+		c.log.Warn("Failed to read Zotero cache for item %s: %v", itemKey, err)
+		return nil, false
+	}
+	if data == nil || time.Since(fetchedAt) >= c.ttl {
+		return nil, false
+	}
+	var attachments []AttachmentInfo
+	if err := json.Unmarshal(data, &attachments); err != nil {
+		return nil, false
+	}
+	return attachments, true
+}
+
+// putAttachments caches the attachments of an item. Failures are logged, since
+// the listing has already been fetched.
+func (c *zoteroCache) putAttachments(ctx context.Context, itemKey string, attachments []AttachmentInfo) {
+	if c == nil {
+		return
+	}
+	data, err := json.Marshal(attachments)
+	if err == nil {
+		err = c.store.PutZoteroCache(ctx, c.key("attachments", itemKey), data)
+	}
+	if err != nil {
+		c.log.Warn("Failed to cache attachments of Zotero item %s: %v", itemKey, err)
+	}
+}
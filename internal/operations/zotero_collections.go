@@ -3,8 +3,12 @@ package operations
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
 	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
 	"github.com/Epistemic-Technology/zotero/zotero"
 )
 
@@ -30,23 +34,23 @@ type CollectionResult struct {
 // Parameters:
 //   - ctx: Context for cancellation and timeouts
 //   - apiKey: Zotero API key for authentication
-//   - libraryID: Zotero library ID (user or group)
+//   - library: Zotero library (user or group) to list collections from
 //   - params: Collection listing parameters
 //   - log: Logger for recording operations
 //
 // Returns:
 //   - results: Array of collections with metadata
 //   - error: Any error encountered during the operation
-func ListZoteroCollections(ctx context.Context, apiKey, libraryID string, params ListCollectionsParams, log logger.Logger) ([]CollectionResult, error) {
+func ListZoteroCollections(ctx context.Context, apiKey string, library models.ZoteroLibrary, params ListCollectionsParams, log logger.Logger) ([]CollectionResult, error) {
 	if apiKey == "" {
 		return nil, fmt.Errorf("Zotero API key is required")
 	}
-	if libraryID == "" {
+	if library.ID == "" {
 		return nil, fmt.Errorf("Zotero library ID is required")
 	}
 
 	// Create Zotero client
-	client := zotero.NewClient(libraryID, zotero.LibraryTypeUser, zotero.WithAPIKey(apiKey))
+	client := documents.NewZoteroClient(library, apiKey)
 
 	// Set up query parameters
 	queryParams := &zotero.QueryParams{
@@ -82,7 +86,7 @@ func ListZoteroCollections(ctx context.Context, apiKey, libraryID string, params
 
 	if err != nil {
 		log.Error("Failed to retrieve Zotero collections: %v", err)
-		return nil, fmt.Errorf("failed to retrieve Zotero collections: %w", err)
+		return nil, models.WithErrorCode(models.ErrorUpstreamZotero, fmt.Errorf("failed to retrieve Zotero collections: %w", err))
 	}
 
 	log.Info("Found %d collections in Zotero library", len(collections))
@@ -102,3 +106,156 @@ func ListZoteroCollections(ctx context.Context, apiKey, libraryID string, params
 
 	return results, nil
 }
+
+// CollectionDocumentsParams selects the Zotero collection whose documents to find.
+type CollectionDocumentsParams struct {
+	Collection string // Collection key, or its name (matched case-insensitively)
+	Recursive  bool   // Include subcollections at any depth
+}
+
+// CollectionAttachment is an attachment found in a Zotero collection.
+type CollectionAttachment struct {
+	CollectionKey string // Collection (or subcollection) the item was found in
+	ItemKey       string // Parent item key
+	AttachmentKey string // Attachment key (zotero_id)
+	Title         string // Parent item title
+	ContentType   string // MIME type (e.g., "application/pdf")
+	DocumentID    string // Stored document, or the ID the attachment would be parsed as
+}
+
+// CollectionDocumentsResult lists a collection's attachments by whether they
+// have been parsed.
+type CollectionDocumentsResult struct {
+	Collection  CollectionResult       // The resolved collection
+	Collections []CollectionResult     // Collections searched: the collection, then any subcollections
+	Parsed      []CollectionAttachment // Attachments with a stored document
+	Unparsed    []CollectionAttachment // Attachments not parsed yet
+}
+
+// CollectionDocuments resolves a Zotero collection by key or name and maps the
+// attachments of its items to stored documents. An attachment counts as parsed
+// if its document ID (see storage.GenerateDocumentID) in the library the
+// context names (see storage.LibraryFromContext) is stored or is recorded as a
+// source of a stored document.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeouts
+//   - apiKey: Zotero API key for authentication
+//   - library: Zotero library (user or group) the collection belongs to
+//   - params: The collection and whether to include its subcollections
+//   - store: Storage backend for looking up parsed documents
+//   - log: Logger for recording operations
+//
+// Returns:
+//   - result: The collection and its parsed and unparsed attachments
+//   - error: Any error encountered resolving or searching the collection
+func CollectionDocuments(ctx context.Context, apiKey string, library models.ZoteroLibrary, params CollectionDocumentsParams, store storage.Store, log logger.Logger) (*CollectionDocumentsResult, error) {
+	all, err := ListZoteroCollections(ctx, apiKey, library, ListCollectionsParams{}, log)
+	if err != nil {
+		return nil, err
+	}
+	collection, err := resolveCollection(all, params.Collection)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CollectionDocumentsResult{Collection: collection, Collections: []CollectionResult{collection}}
+	if params.Recursive {
+		result.Collections = append(result.Collections, subcollections(all, collection.Key)...)
+	}
+	log.Info("Finding documents in collection %s (%s) and %d subcollections", collection.Key, collection.Name, len(result.Collections)-1)
+
+	seen := make(map[string]bool)
+	for _, c := range result.Collections {
+		items, err := SearchZotero(ctx, apiKey, library, ZoteroSearchParams{Collection: c.Key, Limit: 100}, store, log)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			for _, att := range item.Attachments {
+				if seen[att.Key] {
+					continue
+				}
+				seen[att.Key] = true
+
+				entry := CollectionAttachment{
+					CollectionKey: c.Key,
+					ItemKey:       item.Key,
+					AttachmentKey: att.Key,
+					Title:         item.Title,
+					ContentType:   att.ContentType,
+					DocumentID: storage.LibraryDocumentID(storage.LibraryFromContext(ctx), storage.GenerateDocumentID(documents.DocumentIDSource(ctx, &models.SourceInfo{
+						ZoteroID:          att.Key,
+						ZoteroLibraryType: library.Type,
+						ZoteroLibraryID:   library.ID,
+					}), models.DocumentData{})),
+				}
+				docID, err := store.GetDocumentBySource(ctx, entry.DocumentID)
+				if err != nil {
+					return nil, models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to check document sources: %w", err))
+				}
+				if docID == "" {
+					result.Unparsed = append(result.Unparsed, entry)
+					continue
+				}
+				entry.DocumentID = docID
+				result.Parsed = append(result.Parsed, entry)
+			}
+		}
+	}
+
+	log.Info("Collection %s has %d parsed and %d unparsed attachments", collection.Key, len(result.Parsed), len(result.Unparsed))
+	return result, nil
+}
+
+// resolveCollection finds a collection by key, or else by name. A name shared
+// by several collections is rejected, since the key is needed to tell them apart.
+func resolveCollection(collections []CollectionResult, keyOrName string) (CollectionResult, error) {
+	keyOrName = strings.TrimSpace(keyOrName)
+	if keyOrName == "" {
+		return CollectionResult{}, models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("a collection key or name is required"))
+	}
+	for _, c := range collections {
+		if c.Key == keyOrName {
+			return c, nil
+		}
+	}
+
+	var matches []CollectionResult
+	for _, c := range collections {
+		if strings.EqualFold(strings.TrimSpace(c.Name), keyOrName) {
+			matches = append(matches, c)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return CollectionResult{}, models.WithErrorCode(models.ErrorNotFound, fmt.Errorf("no Zotero collection with key or name %q", keyOrName))
+	case 1:
+		return matches[0], nil
+	}
+	keys := make([]string, len(matches))
+	for i, c := range matches {
+		keys[i] = c.Key
+	}
+	return CollectionResult{}, models.WithErrorCode(models.ErrorInvalidInput,
+		fmt.Errorf("collection name %q is ambiguous (keys: %s); use the collection key", keyOrName, strings.Join(keys, ", ")))
+}
+
+// subcollections returns the descendants of a collection, breadth first
+func subcollections(collections []CollectionResult, parentKey string) []CollectionResult {
+	var descendants []CollectionResult
+	queue := []string{parentKey}
+	visited := map[string]bool{parentKey: true}
+	for len(queue) > 0 {
+		parent := queue[0]
+		queue = queue[1:]
+		for _, c := range collections {
+			if c.ParentCollection == parent && !visited[c.Key] {
+				visited[c.Key] = true
+				descendants = append(descendants, c)
+				queue = append(queue, c.Key)
+			}
+		}
+	}
+	return descendants
+}
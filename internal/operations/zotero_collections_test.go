@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/models"
 )
 
 func TestListZoteroCollections_Integration(t *testing.T) {
@@ -12,7 +13,7 @@ func TestListZoteroCollections_Integration(t *testing.T) {
 		t.Skip("Skipping integration test in short mode")
 	}
 
-	apiKey, libraryID := getZoteroCredentials(t)
+	apiKey, library := getZoteroCredentials(t)
 	ctx := context.Background()
 	log := logger.NewNoOpLogger()
 
@@ -44,7 +45,7 @@ func TestListZoteroCollections_Integration(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			results, err := ListZoteroCollections(ctx, apiKey, libraryID, tt.params, log)
+			results, err := ListZoteroCollections(ctx, apiKey, library, tt.params, log)
 			if err != nil {
 				t.Fatalf("ListZoteroCollections failed: %v", err)
 			}
@@ -85,7 +86,7 @@ func TestListZoteroCollections_Subcollections(t *testing.T) {
 		t.Skip("Skipping integration test in short mode")
 	}
 
-	apiKey, libraryID := getZoteroCredentials(t)
+	apiKey, library := getZoteroCredentials(t)
 	ctx := context.Background()
 	log := logger.NewNoOpLogger()
 
@@ -94,7 +95,7 @@ func TestListZoteroCollections_Subcollections(t *testing.T) {
 		Limit: 100,
 	}
 
-	allCollections, err := ListZoteroCollections(ctx, apiKey, libraryID, allParams, log)
+	allCollections, err := ListZoteroCollections(ctx, apiKey, library, allParams, log)
 	if err != nil {
 		t.Fatalf("ListZoteroCollections failed: %v", err)
 	}
@@ -132,7 +133,7 @@ func TestListZoteroCollections_Subcollections(t *testing.T) {
 		Limit:            50,
 	}
 
-	subCollections, err := ListZoteroCollections(ctx, apiKey, libraryID, subParams, log)
+	subCollections, err := ListZoteroCollections(ctx, apiKey, library, subParams, log)
 	if err != nil {
 		t.Fatalf("ListZoteroCollections for subcollections failed: %v", err)
 	}
@@ -177,7 +178,7 @@ func TestListZoteroCollections_MissingCredentials(t *testing.T) {
 				Limit: 50,
 			}
 
-			_, err := ListZoteroCollections(ctx, tt.apiKey, tt.libraryID, params, log)
+			_, err := ListZoteroCollections(ctx, tt.apiKey, models.ZoteroLibrary{Type: "user", ID: tt.libraryID}, params, log)
 			if err == nil {
 				t.Fatal("Expected error but got none")
 			}
@@ -194,14 +195,14 @@ func TestListZoteroCollections_DefaultParameters(t *testing.T) {
 		t.Skip("Skipping integration test in short mode")
 	}
 
-	apiKey, libraryID := getZoteroCredentials(t)
+	apiKey, library := getZoteroCredentials(t)
 	ctx := context.Background()
 	log := logger.NewNoOpLogger()
 
 	// Test with empty parameters - should use defaults
 	params := ListCollectionsParams{}
 
-	results, err := ListZoteroCollections(ctx, apiKey, libraryID, params, log)
+	results, err := ListZoteroCollections(ctx, apiKey, library, params, log)
 	if err != nil {
 		t.Fatalf("ListZoteroCollections failed: %v", err)
 	}
@@ -219,7 +220,7 @@ func TestListZoteroCollections_HierarchyStructure(t *testing.T) {
 		t.Skip("Skipping integration test in short mode")
 	}
 
-	apiKey, libraryID := getZoteroCredentials(t)
+	apiKey, library := getZoteroCredentials(t)
 	ctx := context.Background()
 	log := logger.NewNoOpLogger()
 
@@ -228,7 +229,7 @@ func TestListZoteroCollections_HierarchyStructure(t *testing.T) {
 		Limit: 100,
 	}
 
-	results, err := ListZoteroCollections(ctx, apiKey, libraryID, params, log)
+	results, err := ListZoteroCollections(ctx, apiKey, library, params, log)
 	if err != nil {
 		t.Fatalf("ListZoteroCollections failed: %v", err)
 	}
@@ -269,3 +270,41 @@ func TestListZoteroCollections_HierarchyStructure(t *testing.T) {
 		}
 	}
 }
+
+func TestListZoteroCollections_LibrarySelection(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewNoOpLogger()
+
+	tests := []struct {
+		name     string
+		library  models.ZoteroLibrary
+		params   ListCollectionsParams
+		wantPath string
+	}{
+		{
+			name:     "User library",
+			library:  models.ZoteroLibrary{Type: "user", ID: "111"},
+			wantPath: "/users/111/collections",
+		},
+		{
+			name:     "Group library top-level",
+			library:  models.ZoteroLibrary{Type: "group", ID: "222"},
+			params:   ListCollectionsParams{TopLevelOnly: true},
+			wantPath: "/groups/222/collections/top",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths := newMockZoteroServer(t)
+
+			if _, err := ListZoteroCollections(ctx, "test-key", tt.library, tt.params, log); err != nil {
+				t.Fatalf("ListZoteroCollections failed: %v", err)
+			}
+
+			if len(*paths) != 1 || (*paths)[0] != tt.wantPath {
+				t.Errorf("Expected request to %s, got %v", tt.wantPath, *paths)
+			}
+		})
+	}
+}
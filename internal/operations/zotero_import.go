@@ -0,0 +1,181 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
+	"github.com/Epistemic-Technology/academic-mcp/internal/llm"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// defaultImportConcurrency is the number of documents parsed at once during an import.
+// Each document already parses its pages in parallel, so this is kept small.
+const defaultImportConcurrency = 3
+
+// ZoteroImportParams contains parameters for bulk-importing documents from Zotero.
+type ZoteroImportParams struct {
+	Collection   string // Collection key to import from (optional)
+	Query        string // Quick search text to select items (optional)
+	Limit        int    // Max Zotero items to enumerate (default 100)
+	MaxDocuments int    // Max documents to parse in this call (0 = no cap)
+	DryRun       bool   // Only report what would be parsed
+}
+
+// ZoteroImportItem describes the outcome for a single Zotero attachment.
+type ZoteroImportItem struct {
+	ItemKey       string // Parent item key
+	AttachmentKey string // Attachment key (zotero_id)
+	Title         string // Parent item title
+	DocumentID    string // Document ID in the store
+	Citekey       string // Citekey (for imported or already parsed documents)
+	Reason        string // Why the item was skipped or failed
+	Err           error  // Why the item failed, for classifying the failure
+}
+
+// ZoteroImportResult summarizes a bulk import.
+type ZoteroImportResult struct {
+	Imported  []ZoteroImportItem // Newly parsed documents
+	Skipped   []ZoteroImportItem // Documents already in the store
+	Failed    []ZoteroImportItem // Documents that could not be parsed
+	Pending   []ZoteroImportItem // Documents that would be parsed (dry run only)
+	Remaining int                // Documents not processed because of MaxDocuments
+}
+
+// ImportZoteroDocuments enumerates the PDF attachments of items matching a collection
+// or search query, skips those already in the store, and parses the rest concurrently.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeouts
+//   - apiKey: Zotero API key for authentication
+//   - library: Zotero library (user or group) to import from
+//   - params: Import parameters (collection, query, caps, dry run)
+//   - store: Storage backend for existence checks and storing parsed documents
+//   - log: Logger for recording operations
+//
+// Returns:
+//   - result: Imported, skipped, failed, and pending documents
+//   - error: Any error encountered while enumerating the library
+func ImportZoteroDocuments(ctx context.Context, apiKey string, library models.ZoteroLibrary, params ZoteroImportParams, store storage.Store, log logger.Logger) (*ZoteroImportResult, error) {
+	if params.Collection == "" && params.Query == "" {
+		return nil, models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("either a collection key or a search query is required"))
+	}
+
+	limit := params.Limit
+	if limit == 0 {
+		limit = 100
+	}
+
+	items, err := SearchZotero(ctx, apiKey, library, ZoteroSearchParams{
+		Query:      params.Query,
+		Collection: params.Collection,
+		Limit:      limit,
+	}, store, log)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ZoteroImportResult{}
+	var toParse []ZoteroImportItem
+	seen := make(map[string]bool)
+
+	for _, item := range items {
+		for _, att := range item.Attachments {
+			if att.ContentType != "application/pdf" || seen[att.Key] {
+				continue
+			}
+			seen[att.Key] = true
+
+			entry := ZoteroImportItem{
+				ItemKey:       item.Key,
+				AttachmentKey: att.Key,
+				Title:         item.Title,
+				DocumentID: storage.GenerateDocumentID(documents.DocumentIDSource(ctx, &models.SourceInfo{
+					ZoteroID:          att.Key,
+					ZoteroLibraryType: library.Type,
+					ZoteroLibraryID:   library.ID,
+				}), models.DocumentData{}),
+			}
+
+			exists, err := store.DocumentExists(ctx, entry.DocumentID)
+			if err != nil {
+				return nil, models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to check document existence: %w", err))
+			}
+			if exists {
+				if metadata, err := store.GetMetadata(ctx, entry.DocumentID); err == nil {
+					entry.Citekey = metadata.Citekey
+				}
+				entry.Reason = "already parsed"
+				result.Skipped = append(result.Skipped, entry)
+				continue
+			}
+
+			toParse = append(toParse, entry)
+		}
+	}
+
+	if params.MaxDocuments > 0 && len(toParse) > params.MaxDocuments {
+		result.Remaining = len(toParse) - params.MaxDocuments
+		toParse = toParse[:params.MaxDocuments]
+	}
+
+	log.Info("Zotero import: %d to parse, %d already parsed, %d over limit", len(toParse), len(result.Skipped), result.Remaining)
+
+	if params.DryRun {
+		result.Pending = toParse
+		return result, nil
+	}
+
+	// Parse documents concurrently, bounded by the worker pool
+	wp := llm.NewWorkerPool(defaultImportConcurrency)
+	outcomes := make([]ZoteroImportItem, len(toParse))
+	failed := make([]bool, len(toParse))
+	var wg sync.WaitGroup
+
+	for i, entry := range toParse {
+		if err := wp.Acquire(ctx); err != nil {
+			for j := i; j < len(toParse); j++ {
+				outcomes[j] = toParse[j]
+				outcomes[j].Reason = fmt.Sprintf("cancelled: %v", err)
+				outcomes[j].Err = err
+				failed[j] = true
+			}
+			break
+		}
+
+		wg.Add(1)
+		go func(idx int, entry ZoteroImportItem) {
+			defer wg.Done()
+			defer wp.Release()
+
+			docID, parsedItem, err := GetOrParseDocument(ctx, entry.AttachmentKey, "", nil, "", library, store, log)
+			if err != nil {
+				log.Error("Failed to import Zotero attachment %s: %v", entry.AttachmentKey, err)
+				entry.Reason = err.Error()
+				entry.Err = err
+				failed[idx] = true
+			} else {
+				entry.DocumentID = docID
+				entry.Citekey = parsedItem.Metadata.Citekey
+			}
+			outcomes[idx] = entry
+		}(i, entry)
+	}
+
+	wg.Wait()
+
+	for i, entry := range outcomes {
+		if failed[i] {
+			result.Failed = append(result.Failed, entry)
+		} else {
+			result.Imported = append(result.Imported, entry)
+		}
+	}
+
+	log.Info("Zotero import complete: %d imported, %d skipped, %d failed", len(result.Imported), len(result.Skipped), len(result.Failed))
+
+	return result, nil
+}
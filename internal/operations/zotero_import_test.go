@@ -0,0 +1,75 @@
+package operations
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestImportZoteroDocuments_DryRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/children") {
+			w.Write([]byte(`[
+				{"key":"ATT1","data":{"key":"ATT1","itemType":"attachment","contentType":"application/pdf"}},
+				{"key":"ATT2","data":{"key":"ATT2","itemType":"attachment","contentType":"application/pdf"}},
+				{"key":"ATT3","data":{"key":"ATT3","itemType":"attachment","contentType":"application/pdf"}},
+				{"key":"SNAP","data":{"key":"SNAP","itemType":"attachment","contentType":"text/html"}}
+			]`))
+			return
+		}
+		w.Write([]byte(`[{"key":"ITEM1","data":{"key":"ITEM1","itemType":"book","title":"A Book"}}]`))
+	}))
+	defer server.Close()
+	t.Setenv("ZOTERO_API_BASE_URL", server.URL)
+	t.Setenv("ZOTERO_LIBRARY_ID", "111")
+
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(":memory:", log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	library := models.ZoteroLibrary{Type: "user", ID: "111"}
+
+	// ATT1 has already been parsed
+	parsed := &models.ParsedItem{Metadata: models.ItemMetadata{Title: "A Book", Citekey: "book2020"}}
+	if err := store.StoreParsedItem(ctx, "zotero_ATT1", parsed, &models.SourceInfo{ZoteroID: "ATT1"}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+
+	params := ZoteroImportParams{Collection: "COLL1", MaxDocuments: 1, DryRun: true}
+	result, err := ImportZoteroDocuments(ctx, "test-key", library, params, store, log)
+	if err != nil {
+		t.Fatalf("ImportZoteroDocuments failed: %v", err)
+	}
+
+	if len(result.Skipped) != 1 || result.Skipped[0].AttachmentKey != "ATT1" || result.Skipped[0].Citekey != "book2020" {
+		t.Errorf("Expected ATT1 to be skipped with its citekey, got %+v", result.Skipped)
+	}
+	if len(result.Pending) != 1 || result.Pending[0].AttachmentKey != "ATT2" {
+		t.Errorf("Expected ATT2 to be pending, got %+v", result.Pending)
+	}
+	if result.Remaining != 1 {
+		t.Errorf("Expected 1 remaining document over the cap, got %d", result.Remaining)
+	}
+	if len(result.Imported) != 0 || len(result.Failed) != 0 {
+		t.Errorf("Expected no documents to be parsed in dry run, got %d imported and %d failed", len(result.Imported), len(result.Failed))
+	}
+}
+
+func TestImportZoteroDocuments_RequiresSelection(t *testing.T) {
+	log := logger.NewNoOpLogger()
+	_, err := ImportZoteroDocuments(context.Background(), "test-key", models.ZoteroLibrary{Type: "user", ID: "111"}, ZoteroImportParams{}, nil, log)
+	if err == nil {
+		t.Fatal("Expected error when neither collection nor query is provided")
+	}
+}
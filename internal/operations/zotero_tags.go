@@ -0,0 +1,127 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// zoteroTagAttempts is how many times a tag update is read and written before a
+// version conflict is given up on
+const zoteroTagAttempts = 3
+
+// ZoteroTagParams contains the tags to add to and remove from a document's
+// Zotero item
+type ZoteroTagParams struct {
+	Add    []string // Tags to add; tags the item already has are left alone
+	Remove []string // Tags to remove; tags the item does not have are ignored
+	DryRun bool     // Only report what would change
+}
+
+// ZoteroTagResult describes the outcome of tagging one document's Zotero item
+type ZoteroTagResult struct {
+	DocumentID string
+	ItemKey    string   // Parent item whose tags were changed
+	Added      []string // Tags added (or that would be, in dry run)
+	Removed    []string // Tags removed (or that would be, in dry run)
+	Tags       []string // The item's tags after the change
+}
+
+// TagZoteroItem adds and removes tags on the parent Zotero item of a document
+// parsed from a Zotero attachment, and stores the item's resulting tags with
+// the document. Other tags, including automatic ones, are kept as they are.
+//
+// The update is conditional on the item's version, so a change made in Zotero
+// between reading the tags and writing them is not lost: on a version conflict
+// the item is read again and the change re-applied, up to zoteroTagAttempts
+// times.
+func TagZoteroItem(ctx context.Context, apiKey, docID string, params ZoteroTagParams, store storage.Store, log logger.Logger) (*ZoteroTagResult, error) {
+	client, itemKey, err := zoteroParentItem(ctx, apiKey, docID, store)
+	if err != nil {
+		return nil, err
+	}
+	result := &ZoteroTagResult{DocumentID: docID, ItemKey: itemKey}
+
+	for attempt := 1; ; attempt++ {
+		item, version, err := getZoteroItemData(ctx, client, itemKey)
+		if err != nil {
+			return nil, err
+		}
+		tags, added, removed := applyTagChanges(itemTags(item), params.Add, params.Remove)
+		result.Added, result.Removed, result.Tags = added, removed, tagNames(tags)
+
+		if params.DryRun || (len(added) == 0 && len(removed) == 0) {
+			break
+		}
+		err = patchZoteroItem(ctx, client, itemKey, version, map[string]any{"tags": tags})
+		if errors.Is(err, errZoteroVersionConflict) && attempt < zoteroTagAttempts {
+			log.Warn("Zotero item %s changed while tagging it, retrying (attempt %d of %d)", itemKey, attempt, zoteroTagAttempts)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		log.Info("Tagged Zotero item %s: %d added, %d removed", itemKey, len(added), len(removed))
+		break
+	}
+
+	if params.DryRun {
+		return result, nil
+	}
+	if err := store.SetTags(ctx, docID, result.Tags); err != nil {
+		return nil, models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to store tags: %w", err))
+	}
+	return result, nil
+}
+
+// itemTags returns the tags of raw Zotero item data, each an object with a
+// "tag" name and an optional "type" (1 for automatic tags)
+func itemTags(item map[string]any) []map[string]any {
+	raw, _ := item["tags"].([]any)
+	var tags []map[string]any
+	for _, entry := range raw {
+		if tag, ok := entry.(map[string]any); ok && stringField(tag, "tag") != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// applyTagChanges returns tags with add appended and remove taken out, and the
+// tags actually added and removed. A tag in both lists is added.
+func applyTagChanges(tags []map[string]any, add, remove []string) (updated []map[string]any, added, removed []string) {
+	for _, tag := range tags {
+		name := stringField(tag, "tag")
+		if slices.Contains(remove, name) && !slices.Contains(add, name) {
+			removed = append(removed, name)
+			continue
+		}
+		updated = append(updated, tag)
+	}
+	for _, name := range add {
+		if name == "" || slices.Contains(tagNames(updated), name) {
+			continue
+		}
+		updated = append(updated, map[string]any{"tag": name})
+		added = append(added, name)
+	}
+	if updated == nil {
+		updated = []map[string]any{}
+	}
+	return updated, added, removed
+}
+
+// tagNames returns the names of tags in raw Zotero item data, sorted
+func tagNames(tags []map[string]any) []string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, stringField(tag, "tag"))
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,167 @@
+package operations
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// mockTagServer serves a parent item with an attachment, accepting tag updates
+// only against the item's current version
+type mockTagServer struct {
+	mu        sync.Mutex
+	version   int
+	tags      []any
+	conflicts int // Updates to reject as if the item had just changed in Zotero
+	patches   int
+}
+
+func (m *mockTagServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+
+	switch {
+	case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/items/ATT1"):
+		json.NewEncoder(w).Encode(map[string]any{
+			"key":  "ATT1",
+			"data": map[string]any{"itemType": "attachment", "parentItem": "PARENT1"},
+		})
+	case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/items/PARENT1"):
+		json.NewEncoder(w).Encode(map[string]any{"key": "PARENT1", "version": m.version, "data": map[string]any{"itemType": "journalArticle", "tags": m.tags}})
+	case r.Method == http.MethodPatch && strings.HasSuffix(r.URL.Path, "/items/PARENT1"):
+		m.patches++
+		if m.conflicts > 0 {
+			// Someone else tagged the item in the meantime
+			m.conflicts--
+			m.version++
+			m.tags = append(m.tags, map[string]any{"tag": "added-elsewhere"})
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		if r.Header.Get("If-Unmodified-Since-Version") != strconv.Itoa(m.version) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		var patch struct {
+			Tags []any `json:"tags"`
+		}
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &patch)
+		m.tags = patch.Tags
+		m.version++
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func setupTagTest(t *testing.T, mock *mockTagServer) storage.Store {
+	t.Helper()
+	server := httptest.NewServer(mock)
+	t.Cleanup(server.Close)
+	t.Setenv("ZOTERO_API_BASE_URL", server.URL)
+	t.Setenv("ZOTERO_LIBRARY_TYPE", "user")
+	t.Setenv("ZOTERO_LIBRARY_ID", "111")
+
+	store, err := storage.NewSQLiteStore(":memory:", logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	item := &models.ParsedItem{Metadata: models.ItemMetadata{Title: "Paper", Tags: []string{"to-read", "auto"}}}
+	if err := store.StoreParsedItem(context.Background(), "zotero_ATT1", item, &models.SourceInfo{ZoteroID: "ATT1"}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+	return store
+}
+
+func TestTagZoteroItem(t *testing.T) {
+	mock := &mockTagServer{version: 7, tags: []any{
+		map[string]any{"tag": "to-read"},
+		map[string]any{"tag": "auto", "type": 1},
+	}}
+	store := setupTagTest(t, mock)
+	ctx := context.Background()
+
+	params := ZoteroTagParams{Add: []string{"reviewed-2024", "auto"}, Remove: []string{"to-read", "missing"}}
+	result, err := TagZoteroItem(ctx, "test-key", "zotero_ATT1", params, store, logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("TagZoteroItem failed: %v", err)
+	}
+	if result.ItemKey != "PARENT1" || !slices.Equal(result.Added, []string{"reviewed-2024"}) || !slices.Equal(result.Removed, []string{"to-read"}) {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if !slices.Equal(result.Tags, []string{"auto", "reviewed-2024"}) {
+		t.Errorf("Expected tags [auto reviewed-2024], got %v", result.Tags)
+	}
+
+	// The automatic tag keeps its type
+	if len(mock.tags) != 2 || mock.tags[0].(map[string]any)["type"] != float64(1) {
+		t.Errorf("Expected the automatic tag kept as it was, got %v", mock.tags)
+	}
+	stored, err := store.GetTags(ctx, "zotero_ATT1")
+	if err != nil || !slices.Equal(stored, result.Tags) {
+		t.Errorf("Expected stored tags %v, got %v (%v)", result.Tags, stored, err)
+	}
+
+	// Nothing left to change makes no update
+	patches := mock.patches
+	if _, err := TagZoteroItem(ctx, "test-key", "zotero_ATT1", params, store, logger.NewNoOpLogger()); err != nil || mock.patches != patches {
+		t.Errorf("Expected no update for tags already applied, got %d updates (%v)", mock.patches-patches, err)
+	}
+}
+
+func TestTagZoteroItem_VersionConflict(t *testing.T) {
+	t.Run("retried on the changed item", func(t *testing.T) {
+		mock := &mockTagServer{version: 7, conflicts: 1}
+		store := setupTagTest(t, mock)
+
+		result, err := TagZoteroItem(context.Background(), "test-key", "zotero_ATT1", ZoteroTagParams{Add: []string{"reviewed-2024"}}, store, logger.NewNoOpLogger())
+		if err != nil {
+			t.Fatalf("TagZoteroItem failed: %v", err)
+		}
+		if mock.patches != 2 || !slices.Equal(result.Tags, []string{"added-elsewhere", "reviewed-2024"}) {
+			t.Errorf("Expected a retry keeping the concurrent tag, got %d updates and tags %v", mock.patches, result.Tags)
+		}
+	})
+
+	t.Run("given up after repeated conflicts", func(t *testing.T) {
+		mock := &mockTagServer{version: 7, conflicts: zoteroTagAttempts}
+		store := setupTagTest(t, mock)
+
+		_, err := TagZoteroItem(context.Background(), "test-key", "zotero_ATT1", ZoteroTagParams{Add: []string{"reviewed-2024"}}, store, logger.NewNoOpLogger())
+		if err == nil || mock.patches != zoteroTagAttempts {
+			t.Errorf("Expected failure after %d attempts, got %d updates (%v)", zoteroTagAttempts, mock.patches, err)
+		}
+		stored, _ := store.GetTags(context.Background(), "zotero_ATT1")
+		if !slices.Equal(stored, []string{"auto", "to-read"}) {
+			t.Errorf("Expected the stored tags unchanged, got %v", stored)
+		}
+	})
+}
+
+func TestTagZoteroItem_DryRun(t *testing.T) {
+	mock := &mockTagServer{version: 7, tags: []any{map[string]any{"tag": "to-read"}}}
+	store := setupTagTest(t, mock)
+
+	result, err := TagZoteroItem(context.Background(), "test-key", "zotero_ATT1", ZoteroTagParams{Add: []string{"reviewed-2024"}, DryRun: true}, store, logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("TagZoteroItem failed: %v", err)
+	}
+	if mock.patches != 0 || !slices.Equal(result.Added, []string{"reviewed-2024"}) {
+		t.Errorf("Expected a preview without updates, got %d updates and %+v", mock.patches, result)
+	}
+}
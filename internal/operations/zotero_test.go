@@ -2,23 +2,39 @@ package operations
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
 	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
 )
 
 // getZoteroCredentials retrieves Zotero credentials from environment.
 // Skips the test if credentials are not available.
-func getZoteroCredentials(t *testing.T) (apiKey, libraryID string) {
+func getZoteroCredentials(t *testing.T) (apiKey string, library models.ZoteroLibrary) {
 	apiKey = os.Getenv("ZOTERO_API_KEY")
-	libraryID = os.Getenv("ZOTERO_LIBRARY_ID")
+	libraryID := os.Getenv("ZOTERO_LIBRARY_ID")
 
 	if apiKey == "" || libraryID == "" {
 		t.Skip("ZOTERO_API_KEY and ZOTERO_LIBRARY_ID not set, skipping integration test")
 	}
 
-	return apiKey, libraryID
+	library, err := documents.ResolveZoteroLibrary(context.Background(), "", libraryID)
+	if err != nil {
+		t.Fatalf("Failed to resolve Zotero library: %v", err)
+	}
+
+	return apiKey, library
 }
 
 func TestSearchZotero_Integration(t *testing.T) {
@@ -26,7 +42,7 @@ func TestSearchZotero_Integration(t *testing.T) {
 		t.Skip("Skipping integration test in short mode")
 	}
 
-	apiKey, libraryID := getZoteroCredentials(t)
+	apiKey, library := getZoteroCredentials(t)
 	ctx := context.Background()
 	log := logger.NewNoOpLogger()
 
@@ -65,7 +81,7 @@ func TestSearchZotero_Integration(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			results, err := SearchZotero(ctx, apiKey, libraryID, tt.params, log)
+			results, err := SearchZotero(ctx, apiKey, library, tt.params, nil, log)
 			if err != nil {
 				t.Fatalf("SearchZotero failed: %v", err)
 			}
@@ -123,7 +139,7 @@ func TestSearchZotero_MissingCredentials(t *testing.T) {
 				Limit: 5,
 			}
 
-			_, err := SearchZotero(ctx, tt.apiKey, tt.libraryID, params, log)
+			_, err := SearchZotero(ctx, tt.apiKey, models.ZoteroLibrary{Type: "user", ID: tt.libraryID}, params, nil, log)
 			if err == nil {
 				t.Fatal("Expected error but got none")
 			}
@@ -140,14 +156,14 @@ func TestSearchZotero_DefaultParameters(t *testing.T) {
 		t.Skip("Skipping integration test in short mode")
 	}
 
-	apiKey, libraryID := getZoteroCredentials(t)
+	apiKey, library := getZoteroCredentials(t)
 	ctx := context.Background()
 	log := logger.NewNoOpLogger()
 
 	// Test with empty parameters - should use defaults
 	params := ZoteroSearchParams{}
 
-	results, err := SearchZotero(ctx, apiKey, libraryID, params, log)
+	results, err := SearchZotero(ctx, apiKey, library, params, nil, log)
 	if err != nil {
 		t.Fatalf("SearchZotero failed: %v", err)
 	}
@@ -165,7 +181,7 @@ func TestSearchZotero_ItemsWithAttachments(t *testing.T) {
 		t.Skip("Skipping integration test in short mode")
 	}
 
-	apiKey, libraryID := getZoteroCredentials(t)
+	apiKey, library := getZoteroCredentials(t)
 	ctx := context.Background()
 	log := logger.NewNoOpLogger()
 
@@ -175,7 +191,7 @@ func TestSearchZotero_ItemsWithAttachments(t *testing.T) {
 		Limit:     10,
 	}
 
-	results, err := SearchZotero(ctx, apiKey, libraryID, params, log)
+	results, err := SearchZotero(ctx, apiKey, library, params, nil, log)
 	if err != nil {
 		t.Fatalf("SearchZotero failed: %v", err)
 	}
@@ -207,3 +223,354 @@ func TestSearchZotero_ItemsWithAttachments(t *testing.T) {
 	t.Logf("Items with attachments: %d/%d", itemsWithAttachments, len(results))
 	t.Logf("Total attachments: %d", totalAttachments)
 }
+
+// newMockZoteroServer starts a fake Zotero API that records request paths and
+// responds with an empty JSON array. ZOTERO_API_BASE_URL is pointed at it.
+func newMockZoteroServer(t *testing.T) *[]string {
+	t.Helper()
+	var mu sync.Mutex
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("[]"))
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("ZOTERO_API_BASE_URL", server.URL)
+	return &paths
+}
+
+func TestSearchZotero_LibrarySelection(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewNoOpLogger()
+
+	tests := []struct {
+		name     string
+		library  models.ZoteroLibrary
+		params   ZoteroSearchParams
+		wantPath string
+	}{
+		{
+			name:     "User library",
+			library:  models.ZoteroLibrary{Type: "user", ID: "111"},
+			wantPath: "/users/111/items",
+		},
+		{
+			name:     "Group library",
+			library:  models.ZoteroLibrary{Type: "group", ID: "222"},
+			wantPath: "/groups/222/items",
+		},
+		{
+			name:     "Group library collection",
+			library:  models.ZoteroLibrary{Type: "group", ID: "222"},
+			params:   ZoteroSearchParams{Collection: "COLL1"},
+			wantPath: "/groups/222/collections/COLL1/items",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths := newMockZoteroServer(t)
+
+			if _, err := SearchZotero(ctx, "test-key", tt.library, tt.params, nil, log); err != nil {
+				t.Fatalf("SearchZotero failed: %v", err)
+			}
+
+			if len(*paths) != 1 || (*paths)[0] != tt.wantPath {
+				t.Errorf("Expected request to %s, got %v", tt.wantPath, *paths)
+			}
+		})
+	}
+}
+
+// newCountingZoteroServer starts a fake Zotero API serving three items with one
+// attachment each, counting the children requests it receives
+func newCountingZoteroServer(t *testing.T) *atomic.Int32 {
+	t.Helper()
+	var childrenRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if itemKey, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/users/111/items/"), "/children"); ok {
+			childrenRequests.Add(1)
+			fmt.Fprintf(w, `[{"key":"ATT_%s","data":{"key":"ATT_%s","itemType":"attachment","contentType":"application/pdf","filename":"%s.pdf"}}]`, itemKey, itemKey, itemKey)
+			return
+		}
+		w.Write([]byte(`[
+			{"key":"ITEM1","data":{"key":"ITEM1","itemType":"journalArticle","title":"First"}},
+			{"key":"ITEM2","data":{"key":"ITEM2","itemType":"book","title":"Second"}},
+			{"key":"ITEM3","data":{"key":"ITEM3","itemType":"thesis","title":"Third"}}
+		]`))
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("ZOTERO_API_BASE_URL", server.URL)
+	return &childrenRequests
+}
+
+func TestSearchZotero_CachesAttachments(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewNoOpLogger()
+	library := models.ZoteroLibrary{Type: "user", ID: "111"}
+	childrenRequests := newCountingZoteroServer(t)
+
+	store, err := storage.NewSQLiteStore(":memory:", log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	search := func(params ZoteroSearchParams) []ZoteroItemResult {
+		t.Helper()
+		results, err := SearchZotero(ctx, "test-key", library, params, store, log)
+		if err != nil {
+			t.Fatalf("SearchZotero failed: %v", err)
+		}
+		return results
+	}
+
+	first := search(ZoteroSearchParams{})
+	if got := childrenRequests.Load(); got != 3 {
+		t.Fatalf("Expected 3 children requests on the first search, got %d", got)
+	}
+
+	// The second identical search is served from the cache
+	second := search(ZoteroSearchParams{})
+	if got := childrenRequests.Load(); got != 3 {
+		t.Errorf("Expected the second search to use the cache, got %d children requests", got)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("Expected cached results to match fetched ones:\n%+v\n%+v", first, second)
+	}
+	for i, key := range []string{"ITEM1", "ITEM2", "ITEM3"} {
+		if second[i].Key != key || len(second[i].Attachments) != 1 || second[i].Attachments[0].Key != "ATT_"+key {
+			t.Errorf("Expected item %s with attachment ATT_%s in order, got %+v", key, key, second[i])
+		}
+	}
+
+	// Refresh bypasses the cache
+	search(ZoteroSearchParams{Refresh: true})
+	if got := childrenRequests.Load(); got != 6 {
+		t.Errorf("Expected refresh to fetch every listing again, got %d children requests", got)
+	}
+
+	// Expired entries are fetched again
+	t.Setenv(zoteroCacheTTLEnv, "1ns")
+	search(ZoteroSearchParams{})
+	if got := childrenRequests.Load(); got != 9 {
+		t.Errorf("Expected expired listings to be fetched again, got %d children requests", got)
+	}
+}
+
+func TestZoteroCacheTTL(t *testing.T) {
+	tests := []struct {
+		value     string
+		expected  time.Duration
+		expectErr bool
+	}{
+		{"", defaultZoteroCacheTTL, false},
+		{"30m", 30 * time.Minute, false},
+		{"0", 0, false},
+		{"-1h", defaultZoteroCacheTTL, true},
+		{"soon", defaultZoteroCacheTTL, true},
+	}
+	for _, tt := range tests {
+		t.Setenv(zoteroCacheTTLEnv, tt.value)
+		ttl, err := ZoteroCacheTTL()
+		if ttl != tt.expected || (err != nil) != tt.expectErr {
+			t.Errorf("%s=%q: expected %v (error %v), got %v, %v", zoteroCacheTTLEnv, tt.value, tt.expected, tt.expectErr, ttl, err)
+		}
+	}
+}
+
+// newIdentifierZoteroServer starts a fake Zotero API with a book, an article,
+// and an orphaned attachment, recording the quick search texts it receives.
+// Quick search matches any item whose JSON contains the text, as Zotero's
+// everything mode matches any field.
+func newIdentifierZoteroServer(t *testing.T) *[]string {
+	t.Helper()
+	items := map[string]string{
+		"BOOK1": `{"key":"BOOK1","data":{"key":"BOOK1","itemType":"book","title":"Algorithms","creators":[{"creatorType":"author","firstName":"Thomas","lastName":"Cormen"}],"ISBN":"978-0-262-03384-8","publisher":"MIT Press","tags":[{"tag":"textbook"}]}}`,
+		"BOOK2": `{"key":"BOOK2","data":{"key":"BOOK2","itemType":"book","title":"Chemistry","ISBN":"9780306406157"}}`,
+		"ART1":  `{"key":"ART1","data":{"key":"ART1","itemType":"journalArticle","title":"Soil Carbon","DOI":"10.1000/soil.2020","extra":"Cites: 10.1000/other"}}`,
+		"ART2":  `{"key":"ART2","data":{"key":"ART2","itemType":"bookSection","title":"Carbon Chapter","extra":"DOI: 10.1000/other"}}`,
+		"ATT1":  `{"key":"ATT1","data":{"key":"ATT1","itemType":"attachment","parentItem":"BOOK1","contentType":"application/pdf","filename":"cormen.pdf","linkMode":"imported_file"}}`,
+		"ORPH1": `{"key":"ORPH1","data":{"key":"ORPH1","itemType":"attachment","title":"Loose PDF","contentType":"application/pdf","filename":"loose.pdf","linkMode":"imported_file"}}`,
+	}
+	var mu sync.Mutex
+	var searches []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		path := strings.TrimPrefix(r.URL.Path, "/users/111/items")
+		switch {
+		case path == "":
+			q := r.URL.Query().Get("q")
+			mu.Lock()
+			searches = append(searches, r.URL.Query().Get("qmode")+":"+q)
+			mu.Unlock()
+			var found []string
+			for _, key := range []string{"BOOK1", "BOOK2", "ART1", "ART2"} {
+				if strings.Contains(items[key], q) {
+					found = append(found, items[key])
+				}
+			}
+			fmt.Fprintf(w, "[%s]", strings.Join(found, ","))
+		case path == "/BOOK1/children":
+			fmt.Fprintf(w, "[%s]", items["ATT1"])
+		case strings.HasSuffix(path, "/children"):
+			w.Write([]byte("[]"))
+		default:
+			item, ok := items[strings.TrimPrefix(path, "/")]
+			if !ok {
+				http.Error(w, "Not found", http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(item))
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("ZOTERO_API_BASE_URL", server.URL)
+	return &searches
+}
+
+func TestSearchZotero_Identifiers(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewNoOpLogger()
+	library := models.ZoteroLibrary{Type: "user", ID: "111"}
+
+	tests := []struct {
+		name         string
+		params       ZoteroSearchParams
+		wantKeys     []string
+		wantSearches []string
+	}{
+		{
+			name:         "DOI field",
+			params:       ZoteroSearchParams{DOI: "https://doi.org/10.1000/SOIL.2020"},
+			wantKeys:     []string{"ART1"},
+			wantSearches: []string{"everything:10.1000/soil.2020"},
+		},
+		{
+			name:         "DOI in the Extra field only, not a cited DOI",
+			params:       ZoteroSearchParams{DOI: "10.1000/other"},
+			wantKeys:     []string{"ART2"},
+			wantSearches: []string{"everything:10.1000/other"},
+		},
+		{
+			name:         "ISBN-10 tries each form until one matches",
+			params:       ZoteroSearchParams{ISBN: "0-306-40615-2"},
+			wantKeys:     []string{"BOOK2"},
+			wantSearches: []string{"everything:0-306-40615-2", "everything:9780306406157"},
+		},
+		{
+			name:         "hyphenated ISBN-13",
+			params:       ZoteroSearchParams{ISBN: "978-0-262-03384-8"},
+			wantKeys:     []string{"BOOK1"},
+			wantSearches: []string{"everything:978-0-262-03384-8"},
+		},
+		{
+			name:         "unknown ISBN tries every form",
+			params:       ZoteroSearchParams{ISBN: "ISBN 0-19-852663-6"},
+			wantSearches: []string{"everything:ISBN 0-19-852663-6", "everything:9780198526636", "everything:0198526636"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			searches := newIdentifierZoteroServer(t)
+			results, err := SearchZotero(ctx, "test-key", library, tt.params, nil, log)
+			if err != nil {
+				t.Fatalf("SearchZotero failed: %v", err)
+			}
+			var keys []string
+			for _, result := range results {
+				keys = append(keys, result.Key)
+			}
+			if !reflect.DeepEqual(keys, tt.wantKeys) {
+				t.Errorf("Expected items %v, got %v", tt.wantKeys, keys)
+			}
+			if !reflect.DeepEqual(*searches, tt.wantSearches) {
+				t.Errorf("Expected searches %v, got %v", tt.wantSearches, *searches)
+			}
+		})
+	}
+}
+
+func TestSearchZotero_InvalidIdentifiers(t *testing.T) {
+	library := models.ZoteroLibrary{Type: "user", ID: "111"}
+	tests := []struct {
+		name   string
+		params ZoteroSearchParams
+	}{
+		{"Invalid DOI", ZoteroSearchParams{DOI: "10.1000"}},
+		{"Invalid ISBN", ZoteroSearchParams{ISBN: "978-0-262-03384-9"}},
+		{"Query and DOI", ZoteroSearchParams{Query: "soil", DOI: "10.1000/soil.2020"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths := newMockZoteroServer(t)
+			_, err := SearchZotero(context.Background(), "test-key", library, tt.params, nil, logger.NewNoOpLogger())
+			if jobErrorCode(err) != models.ErrorInvalidInput {
+				t.Errorf("Expected an invalid input error, got %v", err)
+			}
+			if len(*paths) != 0 {
+				t.Errorf("Expected no requests, got %v", *paths)
+			}
+		})
+	}
+}
+
+func TestGetZoteroItem(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewNoOpLogger()
+	library := models.ZoteroLibrary{Type: "user", ID: "111"}
+	newIdentifierZoteroServer(t)
+
+	tests := []struct {
+		name              string
+		key               string
+		wantKey           string
+		wantTitle         string
+		wantAttachments   []string
+		wantAttachmentKey string
+	}{
+		{"Parent item", "BOOK1", "BOOK1", "Algorithms", []string{"ATT1"}, ""},
+		{"Attachment returns its parent", "ATT1", "BOOK1", "Algorithms", []string{"ATT1"}, "ATT1"},
+		{"Orphaned attachment", "ORPH1", "ORPH1", "Loose PDF", []string{"ORPH1"}, "ORPH1"},
+		{"Item without attachments", "ART1", "ART1", "Soil Carbon", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			details, err := GetZoteroItem(ctx, "test-key", library, tt.key, false, nil, log)
+			if err != nil {
+				t.Fatalf("GetZoteroItem failed: %v", err)
+			}
+			if details.Key != tt.wantKey || details.Metadata.Title != tt.wantTitle || details.AttachmentKey != tt.wantAttachmentKey {
+				t.Errorf("Expected item %s %q (attachment %q), got %s %q (attachment %q)", tt.wantKey, tt.wantTitle, tt.wantAttachmentKey, details.Key, details.Metadata.Title, details.AttachmentKey)
+			}
+			var attachments []string
+			for _, attachment := range details.Attachments {
+				attachments = append(attachments, attachment.Key)
+			}
+			if !reflect.DeepEqual(attachments, tt.wantAttachments) {
+				t.Errorf("Expected attachments %v, got %v", tt.wantAttachments, attachments)
+			}
+		})
+	}
+
+	details, err := GetZoteroItem(ctx, "test-key", library, "BOOK1", false, nil, log)
+	if err != nil {
+		t.Fatalf("GetZoteroItem failed: %v", err)
+	}
+	metadata := details.Metadata
+	if metadata.MetadataSource != "zotero" || metadata.ISBN != "978-0-262-03384-8" || metadata.Publisher != "MIT Press" || !reflect.DeepEqual(metadata.Authors, []string{"Thomas Cormen"}) {
+		t.Errorf("Expected full Zotero metadata, got %+v", metadata)
+	}
+	if !reflect.DeepEqual(details.Tags, []string{"textbook"}) {
+		t.Errorf("Expected tags [textbook], got %v", details.Tags)
+	}
+
+	if _, err := GetZoteroItem(ctx, "test-key", library, "MISSING", false, nil, log); jobErrorCode(err) != models.ErrorNotFound {
+		t.Errorf("Expected a not found error for a missing item, got %v", err)
+	}
+}
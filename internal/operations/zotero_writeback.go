@@ -0,0 +1,367 @@
+package operations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+	"github.com/Epistemic-Technology/zotero/zotero"
+)
+
+// summaryNoteHeading marks notes created by writeback so they are not duplicated
+const summaryNoteHeading = "Summary (academic-mcp)"
+
+// errZoteroVersionConflict is returned by patchZoteroItem when the item changed
+// in Zotero since the version the update was based on
+var errZoteroVersionConflict = errors.New("the item was modified in Zotero since it was read")
+
+// ZoteroWritebackParams contains parameters for writing parsed data back to Zotero.
+type ZoteroWritebackParams struct {
+	WriteSummaryNote bool // Attach the stored summary as a child note
+	DryRun           bool // Only report what would be written
+}
+
+// ZoteroFieldChange describes a single field written (or to be written) to Zotero.
+type ZoteroFieldChange struct {
+	Field string // Zotero field name (e.g., "DOI", "abstractNote")
+	Value string // Value written
+}
+
+// ZoteroWritebackResult describes the outcome of writeback for one document.
+type ZoteroWritebackResult struct {
+	DocumentID    string
+	ItemKey       string              // Parent item that was updated
+	FieldsWritten []ZoteroFieldChange // Fields filled in (or that would be, in dry run)
+	NoteCreated   bool                // Whether a summary note was created (or would be)
+	NoteKey       string              // Key of the created note
+	Skipped       []string            // Reasons fields or notes were not written
+	NoteError     error               // Why the summary note could not be created after the fields were written
+}
+
+// writebackFields lists the Zotero fields that may be filled from extracted metadata
+var writebackFields = []string{"DOI", "abstractNote"}
+
+// WritebackToZotero fills blank fields of a document's parent Zotero item with
+// extracted metadata (DOI, abstract) and optionally attaches the stored summary
+// as a child note. Existing Zotero values are never overwritten, and a summary
+// note is only created if one from a previous writeback does not already exist.
+//
+// The zotero client does not serialize type-specific fields such as DOI or note
+// content, so item reads and writes use raw JSON against the client's endpoint.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeouts
+//   - apiKey: Zotero API key (requires write access unless DryRun is set)
+//   - docID: Stored document ID; must originate from a Zotero attachment
+//   - params: Writeback options
+//   - store: Storage backend for reading extracted metadata and summary
+//   - log: Logger for recording operations
+//
+// Returns:
+//   - result: The fields and notes that were written or skipped, with NoteError
+//     set if the fields were written but the note could not be created
+//   - error: Any error encountered before anything was written
+func WritebackToZotero(ctx context.Context, apiKey, docID string, params ZoteroWritebackParams, store storage.Store, log logger.Logger) (*ZoteroWritebackResult, error) {
+	client, itemKey, err := zoteroParentItem(ctx, apiKey, docID, store)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := store.GetMetadata(ctx, docID)
+	if err != nil {
+		return nil, models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to get metadata: %w", err))
+	}
+	result := &ZoteroWritebackResult{DocumentID: docID, ItemKey: itemKey}
+
+	item, version, err := getZoteroItemData(ctx, client, itemKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fill blank fields only
+	extracted := map[string]string{
+		"DOI":          metadata.DOI,
+		"abstractNote": metadata.Abstract,
+	}
+	patch := make(map[string]any)
+	for _, field := range writebackFields {
+		value := extracted[field]
+		if value == "" {
+			continue
+		}
+		current, supported := item[field]
+		if !supported {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: not supported for item type %s", field, stringField(item, "itemType")))
+			continue
+		}
+		if s, _ := current.(string); s != "" {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: already set in Zotero", field))
+			continue
+		}
+		patch[field] = value
+		result.FieldsWritten = append(result.FieldsWritten, ZoteroFieldChange{Field: field, Value: value})
+	}
+
+	// Summary note
+	var noteHTML string
+	if params.WriteSummaryNote {
+		summary, err := store.GetSummary(ctx, docID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get summary: %w", err)
+		}
+		if summary == "" {
+			result.Skipped = append(result.Skipped, "note: document has no summary (run document-summarize first)")
+		} else {
+			children, err := getZoteroChildrenData(ctx, client, itemKey)
+			if err != nil {
+				return nil, err
+			}
+			if hasSummaryNote(children) {
+				result.Skipped = append(result.Skipped, "note: summary note already exists")
+			} else {
+				noteHTML = formatSummaryNote(summary)
+				result.NoteCreated = true
+			}
+		}
+	}
+
+	if params.DryRun {
+		log.Info("Dry run writeback for %s: %d fields, note=%v", docID, len(result.FieldsWritten), result.NoteCreated)
+		return result, nil
+	}
+
+	if len(patch) > 0 {
+		if err := patchZoteroItem(ctx, client, itemKey, version, patch); err != nil {
+			return nil, err
+		}
+		log.Info("Updated %d fields on Zotero item %s", len(patch), itemKey)
+	}
+
+	if noteHTML != "" {
+		// The fields are written by now, so the result reports them with the failure
+		noteKey, err := createZoteroNote(ctx, client, itemKey, noteHTML)
+		if err != nil {
+			log.Error("Failed to create summary note on Zotero item %s: %v", itemKey, err)
+			result.NoteCreated = false
+			result.NoteError = err
+			return result, nil
+		}
+		result.NoteKey = noteKey
+		log.Info("Created summary note %s on Zotero item %s", noteKey, itemKey)
+	}
+
+	return result, nil
+}
+
+// zoteroParentItem returns a client for the Zotero library of a document parsed
+// from a Zotero attachment, and the key of the attachment's parent item, which
+// holds the metadata, tags, and notes. A document parsed from a regular item
+// rather than an attachment resolves to that item.
+func zoteroParentItem(ctx context.Context, apiKey, docID string, store storage.Store) (*zotero.Client, string, error) {
+	if apiKey == "" {
+		return nil, "", fmt.Errorf("Zotero API key is required")
+	}
+
+	source, ok := storage.ParseZoteroDocumentID(docID)
+	if !ok {
+		return nil, "", models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("document %s was not imported from Zotero", docID))
+	}
+	// A document stored under its item key alone may be from any user library,
+	// which is recorded with its source
+	if source.ZoteroLibraryType == "" {
+		stored, err := store.GetSourceInfo(ctx, docID)
+		if err != nil {
+			return nil, "", err
+		}
+		source.ZoteroLibraryType, source.ZoteroLibraryID = stored.ZoteroLibraryType, stored.ZoteroLibraryID
+	}
+
+	library, err := documents.ResolveZoteroLibrary(ctx, source.ZoteroLibraryType, source.ZoteroLibraryID)
+	if err != nil {
+		return nil, "", err
+	}
+	client := documents.NewZoteroClient(library, apiKey)
+
+	attachment, _, err := getZoteroItemData(ctx, client, source.ZoteroID)
+	if err != nil {
+		return nil, "", err
+	}
+	itemKey := source.ZoteroID
+	if stringField(attachment, "itemType") == "attachment" {
+		itemKey = stringField(attachment, "parentItem")
+		if itemKey == "" {
+			return nil, "", models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("attachment %s has no parent item to update", source.ZoteroID))
+		}
+	}
+	return client, itemKey, nil
+}
+
+// hasSummaryNote reports whether any child note was created by a previous writeback
+func hasSummaryNote(children []map[string]any) bool {
+	for _, child := range children {
+		if stringField(child, "itemType") == "note" && strings.Contains(stringField(child, "note"), summaryNoteHeading) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatSummaryNote converts a plain-text summary into Zotero note HTML
+func formatSummaryNote(summary string) string {
+	var b strings.Builder
+	b.WriteString("<h1>" + summaryNoteHeading + "</h1>")
+	for _, para := range strings.Split(summary, "\n\n") {
+		para = strings.TrimSpace(para)
+		if para == "" {
+			continue
+		}
+		b.WriteString("<p>" + strings.ReplaceAll(html.EscapeString(para), "\n", "<br/>") + "</p>")
+	}
+	return b.String()
+}
+
+// stringField returns a string value from raw Zotero item data
+func stringField(data map[string]any, field string) string {
+	s, _ := data[field].(string)
+	return s
+}
+
+// zoteroRequest performs a raw request against the client's library endpoint
+func zoteroRequest(ctx context.Context, client *zotero.Client, method, path string, body []byte, version int) ([]byte, *http.Response, error) {
+	url := fmt.Sprintf("%s/%s/%s%s", client.BaseURL, client.LibraryType, client.LibraryID, path)
+
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create Zotero request: %w", err)
+	}
+	req.Header.Set("Zotero-API-Key", client.APIKey)
+	req.Header.Set("Zotero-API-Version", "3")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if version > 0 {
+		req.Header.Set("If-Unmodified-Since-Version", strconv.Itoa(version))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Zotero request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read Zotero response: %w", err)
+	}
+	return respBody, resp, nil
+}
+
+// getZoteroItemData fetches an item's raw data fields and version
+func getZoteroItemData(ctx context.Context, client *zotero.Client, key string) (map[string]any, int, error) {
+	body, resp, err := zoteroRequest(ctx, client, http.MethodGet, "/items/"+key, nil, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("failed to fetch Zotero item %s: status %d", key, resp.StatusCode)
+	}
+
+	var item struct {
+		Version int            `json:"version"`
+		Data    map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(body, &item); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode Zotero item %s: %w", key, err)
+	}
+	return item.Data, item.Version, nil
+}
+
+// getZoteroChildrenData fetches the raw data fields of an item's children
+func getZoteroChildrenData(ctx context.Context, client *zotero.Client, key string) ([]map[string]any, error) {
+	body, resp, err := zoteroRequest(ctx, client, http.MethodGet, "/items/"+key+"/children", nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch children of Zotero item %s: status %d", key, resp.StatusCode)
+	}
+
+	var children []struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(body, &children); err != nil {
+		return nil, fmt.Errorf("failed to decode children of Zotero item %s: %w", key, err)
+	}
+	data := make([]map[string]any, len(children))
+	for i, child := range children {
+		data[i] = child.Data
+	}
+	return data, nil
+}
+
+// patchZoteroItem updates only the given fields of an item
+func patchZoteroItem(ctx context.Context, client *zotero.Client, key string, version int, fields map[string]any) error {
+	body, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("failed to encode Zotero update: %w", err)
+	}
+	respBody, resp, err := zoteroRequest(ctx, client, http.MethodPatch, "/items/"+key, body, version)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusPreconditionFailed {
+		return fmt.Errorf("failed to update Zotero item %s: %w", key, errZoteroVersionConflict)
+	}
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to update Zotero item %s: status %d: %s", key, resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// createZoteroNote creates a child note and returns its key
+func createZoteroNote(ctx context.Context, client *zotero.Client, parentKey, noteHTML string) (string, error) {
+	body, err := json.Marshal([]map[string]any{{
+		"itemType":   "note",
+		"parentItem": parentKey,
+		"note":       noteHTML,
+	}})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode Zotero note: %w", err)
+	}
+	respBody, resp, err := zoteroRequest(ctx, client, http.MethodPost, "/items", body, 0)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to create Zotero note: status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var writeResp zotero.WriteResponse
+	if err := json.Unmarshal(respBody, &writeResp); err != nil {
+		return "", fmt.Errorf("failed to decode Zotero write response: %w", err)
+	}
+	if len(writeResp.Failed) > 0 {
+		return "", fmt.Errorf("Zotero rejected note: %v", writeResp.Failed)
+	}
+	for _, key := range writeResp.Success {
+		if s, ok := key.(string); ok {
+			return s, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,250 @@
+package operations
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// mockWritebackServer serves a parent item with an attachment and records writes
+type mockWritebackServer struct {
+	mu       sync.Mutex
+	parent   map[string]any
+	children []map[string]any
+	patches  []map[string]any
+	notes    []map[string]any
+	versions []string
+	paths    []string
+	noteFail bool // Reject note creation
+}
+
+func (m *mockWritebackServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.paths = append(m.paths, r.URL.Path)
+	w.Header().Set("Content-Type", "application/json")
+
+	switch {
+	case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/items/ATT1"):
+		json.NewEncoder(w).Encode(map[string]any{
+			"key":  "ATT1",
+			"data": map[string]any{"itemType": "attachment", "parentItem": "PARENT1"},
+		})
+	case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/items/PARENT1"):
+		json.NewEncoder(w).Encode(map[string]any{"key": "PARENT1", "version": 42, "data": m.parent})
+	case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/items/PARENT1/children"):
+		var children []map[string]any
+		for _, child := range m.children {
+			children = append(children, map[string]any{"data": child})
+		}
+		json.NewEncoder(w).Encode(children)
+	case r.Method == http.MethodPatch:
+		var patch map[string]any
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &patch)
+		m.patches = append(m.patches, patch)
+		m.versions = append(m.versions, r.Header.Get("If-Unmodified-Since-Version"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPost && m.noteFail:
+		w.WriteHeader(http.StatusInternalServerError)
+	case r.Method == http.MethodPost:
+		var notes []map[string]any
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &notes)
+		m.notes = append(m.notes, notes...)
+		w.Write([]byte(`{"success":{"0":"NOTE1"},"unchanged":{},"failed":{}}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func setupWritebackTest(t *testing.T, parent map[string]any, children []map[string]any) (*mockWritebackServer, storage.Store) {
+	t.Helper()
+	mock := &mockWritebackServer{parent: parent, children: children}
+	server := httptest.NewServer(mock)
+	t.Cleanup(server.Close)
+	t.Setenv("ZOTERO_API_BASE_URL", server.URL)
+	t.Setenv("ZOTERO_LIBRARY_TYPE", "user")
+	t.Setenv("ZOTERO_LIBRARY_ID", "111")
+
+	store, err := storage.NewSQLiteStore(":memory:", logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	item := &models.ParsedItem{
+		Metadata: models.ItemMetadata{
+			Title:    "Paper",
+			DOI:      "10.1000/extracted",
+			Abstract: "Extracted abstract",
+		},
+		Summary: "First paragraph.\n\nSecond <paragraph>.",
+	}
+	if err := store.StoreParsedItem(context.Background(), "zotero_ATT1", item, &models.SourceInfo{ZoteroID: "ATT1"}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+	return mock, store
+}
+
+func TestWritebackToZotero_FillsBlanksOnly(t *testing.T) {
+	parent := map[string]any{"itemType": "journalArticle", "DOI": "", "abstractNote": "Existing abstract"}
+	mock, store := setupWritebackTest(t, parent, nil)
+
+	params := ZoteroWritebackParams{WriteSummaryNote: true}
+	result, err := WritebackToZotero(context.Background(), "test-key", "zotero_ATT1", params, store, logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("WritebackToZotero failed: %v", err)
+	}
+
+	if result.ItemKey != "PARENT1" {
+		t.Errorf("Expected parent item PARENT1, got %s", result.ItemKey)
+	}
+	if len(result.FieldsWritten) != 1 || result.FieldsWritten[0].Field != "DOI" {
+		t.Errorf("Expected only DOI to be written, got %+v", result.FieldsWritten)
+	}
+	if len(mock.patches) != 1 {
+		t.Fatalf("Expected 1 PATCH request, got %d", len(mock.patches))
+	}
+	if _, ok := mock.patches[0]["abstractNote"]; ok {
+		t.Error("Existing abstract must not be overwritten")
+	}
+	if mock.patches[0]["DOI"] != "10.1000/extracted" {
+		t.Errorf("Expected DOI in patch, got %v", mock.patches[0])
+	}
+	if mock.versions[0] != "42" {
+		t.Errorf("Expected If-Unmodified-Since-Version 42, got %q", mock.versions[0])
+	}
+
+	if !result.NoteCreated || result.NoteKey != "NOTE1" {
+		t.Errorf("Expected note NOTE1 to be created, got %+v", result)
+	}
+	if len(mock.notes) != 1 {
+		t.Fatalf("Expected 1 note to be posted, got %d", len(mock.notes))
+	}
+	note, _ := mock.notes[0]["note"].(string)
+	if !strings.Contains(note, summaryNoteHeading) || !strings.Contains(note, "Second &lt;paragraph&gt;.") {
+		t.Errorf("Unexpected note content: %s", note)
+	}
+	if mock.notes[0]["parentItem"] != "PARENT1" {
+		t.Errorf("Expected note parent PARENT1, got %v", mock.notes[0]["parentItem"])
+	}
+}
+
+func TestWritebackToZotero_DryRun(t *testing.T) {
+	parent := map[string]any{"itemType": "journalArticle", "DOI": "", "abstractNote": ""}
+	mock, store := setupWritebackTest(t, parent, nil)
+
+	params := ZoteroWritebackParams{WriteSummaryNote: true, DryRun: true}
+	result, err := WritebackToZotero(context.Background(), "test-key", "zotero_ATT1", params, store, logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("WritebackToZotero failed: %v", err)
+	}
+
+	if len(result.FieldsWritten) != 2 || !result.NoteCreated {
+		t.Errorf("Expected DOI, abstract, and note to be reported, got %+v", result)
+	}
+	if len(mock.patches) != 0 || len(mock.notes) != 0 {
+		t.Errorf("Expected no writes in dry run, got %d patches and %d notes", len(mock.patches), len(mock.notes))
+	}
+}
+
+func TestWritebackToZotero_SkipsUnsupportedAndExistingNote(t *testing.T) {
+	// Books have no DOI field in Zotero
+	parent := map[string]any{"itemType": "book", "abstractNote": "Existing abstract"}
+	children := []map[string]any{{"itemType": "note", "note": "<h1>" + summaryNoteHeading + "</h1><p>Old</p>"}}
+	mock, store := setupWritebackTest(t, parent, children)
+
+	params := ZoteroWritebackParams{WriteSummaryNote: true}
+	result, err := WritebackToZotero(context.Background(), "test-key", "zotero_ATT1", params, store, logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("WritebackToZotero failed: %v", err)
+	}
+
+	if len(result.FieldsWritten) != 0 || result.NoteCreated {
+		t.Errorf("Expected nothing to be written, got %+v", result)
+	}
+	if len(result.Skipped) != 3 {
+		t.Errorf("Expected 3 skip reasons (DOI, abstract, note), got %v", result.Skipped)
+	}
+	if len(mock.patches) != 0 || len(mock.notes) != 0 {
+		t.Errorf("Expected no writes, got %d patches and %d notes", len(mock.patches), len(mock.notes))
+	}
+}
+
+func TestWritebackToZotero_NoteFails(t *testing.T) {
+	// Fields already written are reported when the note cannot be created
+	parent := map[string]any{"itemType": "journalArticle", "DOI": "", "abstractNote": ""}
+	mock, store := setupWritebackTest(t, parent, nil)
+	mock.noteFail = true
+
+	params := ZoteroWritebackParams{WriteSummaryNote: true}
+	result, err := WritebackToZotero(context.Background(), "test-key", "zotero_ATT1", params, store, logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("WritebackToZotero failed: %v", err)
+	}
+
+	if len(mock.patches) != 1 || len(result.FieldsWritten) != 2 {
+		t.Errorf("Expected DOI and abstract to be written and reported, got %d patches and %+v", len(mock.patches), result.FieldsWritten)
+	}
+	if result.NoteCreated || result.NoteKey != "" {
+		t.Errorf("Expected no note to be reported, got %+v", result)
+	}
+	if result.NoteError == nil || !strings.Contains(result.NoteError.Error(), "status 500") {
+		t.Errorf("Expected the note failure to be recorded, got %v", result.NoteError)
+	}
+}
+
+func TestWritebackToZotero_UserLibrary(t *testing.T) {
+	// Documents from a user library other than ZOTERO_LIBRARY_ID's are written
+	// back to their own library
+	ctx := context.Background()
+	item := &models.ParsedItem{Metadata: models.ItemMetadata{Title: "Paper", DOI: "10.1000/extracted"}}
+
+	tests := []struct {
+		name    string
+		docID   string
+		library string
+	}{
+		{"library in the ID", "zotero_user_222_ATT1", "222"},
+		{"library recorded with the source", "zotero_ATT1", "333"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock, store := setupWritebackTest(t, map[string]any{"itemType": "journalArticle", "DOI": ""}, nil)
+			source := &models.SourceInfo{ZoteroID: "ATT1", ZoteroLibraryType: "user", ZoteroLibraryID: tt.library}
+			if err := store.StoreParsedItem(ctx, tt.docID, item, source); err != nil {
+				t.Fatalf("Failed to store document: %v", err)
+			}
+
+			result, err := WritebackToZotero(ctx, "test-key", tt.docID, ZoteroWritebackParams{}, store, logger.NewNoOpLogger())
+			if err != nil {
+				t.Fatalf("WritebackToZotero failed: %v", err)
+			}
+			if len(result.FieldsWritten) != 1 || len(mock.patches) != 1 {
+				t.Errorf("Expected the DOI to be written, got %+v", result)
+			}
+			for _, path := range mock.paths {
+				if !strings.HasPrefix(path, "/users/"+tt.library+"/") {
+					t.Errorf("Expected requests to library %s, got %s", tt.library, path)
+				}
+			}
+		})
+	}
+}
+
+func TestWritebackToZotero_NonZoteroDocument(t *testing.T) {
+	_, err := WritebackToZotero(context.Background(), "test-key", "url_0123456789abcdef", ZoteroWritebackParams{}, nil, logger.NewNoOpLogger())
+	if err == nil {
+		t.Fatal("Expected error for a document not imported from Zotero")
+	}
+}
@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// annotationColumns are the columns read into a models.Annotation by scanAnnotation
+const annotationColumns = `id, document_id, page_number, quotation_index, text, COALESCE(created_at, ''), COALESCE(updated_at, '')`
+
+// scanAnnotation reads an annotation selected with annotationColumns
+func scanAnnotation(row interface{ Scan(...any) error }) (*models.Annotation, error) {
+	var annotation models.Annotation
+	var quotationIndex sql.NullInt64
+	if err := row.Scan(&annotation.ID, &annotation.DocumentID, &annotation.PageNumber, &quotationIndex,
+		&annotation.Text, &annotation.CreatedAt, &annotation.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if quotationIndex.Valid {
+		index := int(quotationIndex.Int64)
+		annotation.QuotationIndex = &index
+	}
+	return &annotation, nil
+}
+
+// AddAnnotation stores a new annotation on a document. Each annotation is its
+// own row, so clients annotating the same document concurrently do not
+// overwrite each other.
+func (s *SQLiteStore) AddAnnotation(ctx context.Context, annotation models.Annotation) (*models.Annotation, error) {
+	var quotationIndex sql.NullInt64
+	if annotation.QuotationIndex != nil {
+		quotationIndex = sql.NullInt64{Int64: int64(*annotation.QuotationIndex), Valid: true}
+	}
+
+	row := s.db.QueryRowContext(ctx, `
+		INSERT INTO annotations (document_id, page_number, quotation_index, text)
+		VALUES (?, ?, ?, ?)
+		RETURNING `+annotationColumns,
+		annotation.DocumentID, annotation.PageNumber, quotationIndex, annotation.Text)
+	stored, err := scanAnnotation(row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert annotation: %w", err)
+	}
+	return stored, nil
+}
+
+// GetAnnotations lists a document's annotations, oldest first
+func (s *SQLiteStore) GetAnnotations(ctx context.Context, docID string) ([]models.Annotation, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+annotationColumns+`
+		FROM annotations
+		WHERE document_id = ?
+		ORDER BY id
+	`, docID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query annotations: %w", err)
+	}
+	defer rows.Close()
+
+	annotations := make([]models.Annotation, 0)
+	for rows.Next() {
+		annotation, err := scanAnnotation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan annotation: %w", err)
+		}
+		annotations = append(annotations, *annotation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating annotations: %w", err)
+	}
+	return annotations, nil
+}
+
+// UpdateAnnotation replaces the text of one of a document's annotations
+func (s *SQLiteStore) UpdateAnnotation(ctx context.Context, docID string, id int64, text string) (*models.Annotation, error) {
+	row := s.db.QueryRowContext(ctx, `
+		UPDATE annotations SET text = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND document_id = ?
+		RETURNING `+annotationColumns,
+		text, id, docID)
+	annotation, err := scanAnnotation(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("annotation %d %w for document %s", id, ErrNotFound, docID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update annotation: %w", err)
+	}
+	return annotation, nil
+}
+
+// DeleteAnnotation removes one of a document's annotations
+func (s *SQLiteStore) DeleteAnnotation(ctx context.Context, docID string, id int64) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM annotations WHERE id = ? AND document_id = ?`, id, docID)
+	if err != nil {
+		return fmt.Errorf("failed to delete annotation: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("annotation %d %w for document %s", id, ErrNotFound, docID)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestAnnotations(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	if err := store.StoreParsedItem(ctx, "doc-1", syntheticItem(3), &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+
+	quotation := 1
+	first, err := store.AddAnnotation(ctx, models.Annotation{DocumentID: "doc-1", Text: "Overall argument is weak"})
+	if err != nil {
+		t.Fatalf("AddAnnotation failed: %v", err)
+	}
+	second, err := store.AddAnnotation(ctx, models.Annotation{DocumentID: "doc-1", PageNumber: "2", QuotationIndex: &quotation, Text: "Compare with chapter 3"})
+	if err != nil {
+		t.Fatalf("AddAnnotation failed: %v", err)
+	}
+	if first.ID == 0 || second.ID == first.ID || first.CreatedAt == "" || first.UpdatedAt == "" {
+		t.Errorf("Expected distinct IDs and timestamps, got %+v and %+v", first, second)
+	}
+	if second.PageNumber != "2" || second.QuotationIndex == nil || *second.QuotationIndex != 1 || first.QuotationIndex != nil {
+		t.Errorf("Expected page and quotation targets to round-trip, got %+v and %+v", first, second)
+	}
+
+	updated, err := store.UpdateAnnotation(ctx, "doc-1", first.ID, "Argument is stronger than it first seems")
+	if err != nil {
+		t.Fatalf("UpdateAnnotation failed: %v", err)
+	}
+	if updated.Text != "Argument is stronger than it first seems" || updated.CreatedAt != first.CreatedAt {
+		t.Errorf("Expected updated text with the original creation time, got %+v", updated)
+	}
+
+	// Re-parsing the document keeps the annotations
+	if err := store.StoreParsedItem(ctx, "doc-1", syntheticItem(2), &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	annotations, err := store.GetAnnotations(ctx, "doc-1")
+	if err != nil {
+		t.Fatalf("GetAnnotations failed: %v", err)
+	}
+	if len(annotations) != 2 || annotations[0].ID != first.ID || annotations[1].ID != second.ID {
+		t.Fatalf("Expected both annotations in creation order after re-parsing, got %+v", annotations)
+	}
+
+	if err := store.DeleteAnnotation(ctx, "doc-1", second.ID); err != nil {
+		t.Fatalf("DeleteAnnotation failed: %v", err)
+	}
+
+	// Annotations belong to their document
+	if _, err := store.UpdateAnnotation(ctx, "doc-2", first.ID, "elsewhere"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected not found error updating through another document, got %v", err)
+	}
+	if err := store.DeleteAnnotation(ctx, "doc-1", second.ID); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected not found error deleting twice, got %v", err)
+	}
+
+	if err := store.DeleteDocument(ctx, "doc-1"); err != nil {
+		t.Fatalf("DeleteDocument failed: %v", err)
+	}
+	annotations, err = store.GetAnnotations(ctx, "doc-1")
+	if err != nil {
+		t.Fatalf("GetAnnotations failed: %v", err)
+	}
+	if len(annotations) != 0 {
+		t.Errorf("Expected deleting the document to delete its annotations, got %+v", annotations)
+	}
+}
+
+func TestAddAnnotation_Concurrent(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"), logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+	if err := store.StoreParsedItem(ctx, "doc-1", syntheticItem(1), &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+
+	// Two clients annotating the same document keep all of each other's notes
+	const clients = 2
+	const notesPerClient = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, clients*notesPerClient)
+	for c := 0; c < clients; c++ {
+		wg.Add(1)
+		go func(c int) {
+			defer wg.Done()
+			for i := 0; i < notesPerClient; i++ {
+				if _, err := store.AddAnnotation(ctx, models.Annotation{DocumentID: "doc-1", Text: fmt.Sprintf("client %d note %d", c, i)}); err != nil {
+					errs <- err
+				}
+			}
+		}(c)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	annotations, err := store.GetAnnotations(ctx, "doc-1")
+	if err != nil {
+		t.Fatalf("GetAnnotations failed: %v", err)
+	}
+	if len(annotations) != clients*notesPerClient {
+		t.Errorf("Expected %d annotations, got %d", clients*notesPerClient, len(annotations))
+	}
+}
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/citations"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// insertAuthors stores the parsed form of each of a document's authors
+func insertAuthors(ctx context.Context, tx *sql.Tx, docID string, authors []string) error {
+	return insertRows(ctx, tx, "author", `
+		INSERT INTO document_authors (document_id, position, family, given, suffix, family_key, given_key, raw)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, len(authors), func(i int) []any {
+		author := citations.ParseAuthor(authors[i])
+		return []any{docID, i, author.Family, author.Given, author.Suffix,
+			citations.NameKey(author.Family), citations.NameKey(author.Given), author.Raw}
+	})
+}
+
+// backfillDocumentAuthors parses the authors of documents stored before the
+// document_authors table existed
+func backfillDocumentAuthors(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT id, COALESCE(authors, '') FROM documents`)
+	if err != nil {
+		return fmt.Errorf("failed to query document authors: %w", err)
+	}
+	authorsByDoc := make(map[string][]string)
+	for rows.Next() {
+		var docID, authorsJSON string
+		if err := rows.Scan(&docID, &authorsJSON); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan document authors: %w", err)
+		}
+		var authors []string
+		if json.Unmarshal([]byte(authorsJSON), &authors) == nil {
+			authorsByDoc[docID] = authors
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating document authors: %w", err)
+	}
+
+	for docID, authors := range authorsByDoc {
+		if err := insertAuthors(context.Background(), tx, docID, authors); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetAuthors lists the distinct authors in library, or in every library if
+// library is empty, most documents first.
+// Authors are the same when their family and given names match ignoring case,
+// punctuation, and spacing, so "Smith, J.R." and "J. R. Smith" are one author,
+// but "J. Smith" and "John Smith" are not merged since the initial is ambiguous.
+func (s *SQLiteStore) GetAuthors(ctx context.Context, library string) ([]models.LibraryAuthor, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT family, given, suffix, family_key, given_key, document_id
+		FROM document_authors
+		WHERE family_key != '' AND `+libraryDocumentFilter+`
+		ORDER BY document_id, position
+	`, library, library)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query authors: %w", err)
+	}
+	defer rows.Close()
+
+	byKey := make(map[string]*models.LibraryAuthor)
+	for rows.Next() {
+		var author models.Author
+		var familyKey, givenKey, docID string
+		if err := rows.Scan(&author.Family, &author.Given, &author.Suffix, &familyKey, &givenKey, &docID); err != nil {
+			return nil, fmt.Errorf("failed to scan author: %w", err)
+		}
+
+		key := familyKey + "\x00" + givenKey
+		entry, ok := byKey[key]
+		if !ok {
+			entry = &models.LibraryAuthor{Family: author.Family, Given: author.Given, Suffix: author.Suffix}
+			byKey[key] = entry
+		}
+		// An author listed twice in one document counts once
+		if n := len(entry.DocumentIDs); n == 0 || entry.DocumentIDs[n-1] != docID {
+			entry.DocumentIDs = append(entry.DocumentIDs, docID)
+		}
+		if entry.Suffix == "" {
+			entry.Suffix = author.Suffix
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating authors: %w", err)
+	}
+
+	authors := make([]models.LibraryAuthor, 0, len(byKey))
+	for _, entry := range byKey {
+		entry.Name = citations.FormatAuthor(models.Author{Family: entry.Family, Given: entry.Given, Suffix: entry.Suffix})
+		entry.DocumentCount = len(entry.DocumentIDs)
+		authors = append(authors, *entry)
+	}
+	sort.Slice(authors, func(i, j int) bool {
+		if authors[i].DocumentCount != authors[j].DocumentCount {
+			return authors[i].DocumentCount > authors[j].DocumentCount
+		}
+		return authors[i].Name < authors[j].Name
+	})
+	return authors, nil
+}
+
+// getTopAuthors returns the limit authors of library attributed to the most
+// documents
+func (s *SQLiteStore) getTopAuthors(ctx context.Context, library string, limit int) ([]models.AuthorCount, error) {
+	authors, err := s.GetAuthors(ctx, library)
+	if err != nil {
+		return nil, err
+	}
+
+	var counts []models.AuthorCount
+	for _, author := range authors[:min(limit, len(authors))] {
+		counts = append(counts, models.AuthorCount{Author: author.Name, Count: author.DocumentCount})
+	}
+	return counts, nil
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestGetAuthors(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	documents := []struct {
+		id      string
+		authors []string
+	}{
+		{"doc-1", []string{"Smith, J.R.", "Jan van der Berg"}},
+		{"doc-2", []string{"J. R. Smith", "O’Neill, Onora"}},
+		{"doc-3", []string{"van der Berg, Jan", "John Smith", "Smith JR"}},
+	}
+	for _, doc := range documents {
+		item := syntheticItem(0)
+		item.Metadata.Authors = doc.authors
+		if err := store.StoreParsedItem(ctx, doc.id, item, &models.SourceInfo{}); err != nil {
+			t.Fatalf("StoreParsedItem failed: %v", err)
+		}
+	}
+
+	authors, err := store.GetAuthors(ctx, "")
+	if err != nil {
+		t.Fatalf("GetAuthors failed: %v", err)
+	}
+	expected := []models.LibraryAuthor{
+		{Name: "Smith, J. R.", Family: "Smith", Given: "J. R.", DocumentCount: 3, DocumentIDs: []string{"doc-1", "doc-2", "doc-3"}},
+		{Name: "van der Berg, Jan", Family: "van der Berg", Given: "Jan", DocumentCount: 2, DocumentIDs: []string{"doc-1", "doc-3"}},
+		{Name: "O'Neill, Onora", Family: "O'Neill", Given: "Onora", DocumentCount: 1, DocumentIDs: []string{"doc-2"}},
+		{Name: "Smith, John", Family: "Smith", Given: "John", DocumentCount: 1, DocumentIDs: []string{"doc-3"}},
+	}
+	if !reflect.DeepEqual(authors, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, authors)
+	}
+
+	// Re-storing and deleting documents keep the index in step
+	item := syntheticItem(0)
+	item.Metadata.Authors = []string{"Plato"}
+	if err := store.StoreParsedItem(ctx, "doc-1", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	for _, docID := range []string{"doc-2", "doc-3"} {
+		if err := store.DeleteDocument(ctx, docID); err != nil {
+			t.Fatalf("DeleteDocument failed: %v", err)
+		}
+	}
+	authors, err = store.GetAuthors(ctx, "")
+	if err != nil {
+		t.Fatalf("GetAuthors failed: %v", err)
+	}
+	if len(authors) != 1 || authors[0].Name != "Plato" || authors[0].DocumentCount != 1 {
+		t.Errorf("Expected only Plato after re-storing and deleting, got %+v", authors)
+	}
+}
@@ -0,0 +1,229 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/citations"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// FindDocumentByDOI returns the ID of the earliest stored document in library
+// whose DOI matches doi, ignoring case and resolver prefixes, or "" if there is
+// none. An empty library searches every library.
+func (s *SQLiteStore) FindDocumentByDOI(ctx context.Context, library string, doi string) (string, error) {
+	normalized := normalizeDOI(doi)
+	if normalized == "" {
+		return "", nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, doi FROM documents
+		WHERE doi IS NOT NULL AND doi != '' AND (? = '' OR library = ?)
+		ORDER BY created_at, id
+	`, library, library)
+	if err != nil {
+		return "", fmt.Errorf("failed to query DOIs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var docID, storedDOI string
+		if err := rows.Scan(&docID, &storedDOI); err != nil {
+			return "", fmt.Errorf("failed to scan DOI: %w", err)
+		}
+		if normalizeDOI(storedDOI) == normalized {
+			return docID, nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("error iterating DOIs: %w", err)
+	}
+	return "", nil
+}
+
+// FindDocumentByContentHash returns the ID of the earliest stored document in
+// library parsed from data with the given content hash, or "" if there is none
+func (s *SQLiteStore) FindDocumentByContentHash(ctx context.Context, library string, hash string) (string, error) {
+	if hash == "" {
+		return "", nil
+	}
+	var docID string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id FROM documents WHERE content_hash = ? AND (? = '' OR library = ?)
+		ORDER BY created_at, id LIMIT 1
+	`, hash, library, library).Scan(&docID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to query content hashes: %w", err)
+	}
+	return docID, nil
+}
+
+// FindDocumentByTitleAuthorYear returns the ID of the earliest stored document
+// in library with the same title, first author family name, and publication year, or "" if
+// there is none. Titles are compared ignoring case and punctuation, and a title
+// without a subtitle matches the same title with one. All three values are
+// required, since a title alone is too weak a match.
+func (s *SQLiteStore) FindDocumentByTitleAuthorYear(ctx context.Context, library string, title string, firstAuthor string, year string) (string, error) {
+	year = citations.ExtractYear(year)
+	family := authorFamilyName(firstAuthor)
+	if normalizeTitle(title) == "" || family == "" || year == "" {
+		return "", nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, COALESCE(title, ''), COALESCE(authors, ''), publication_date FROM documents
+		WHERE publication_date LIKE '%' || ? || '%' ESCAPE '\' AND (? = '' OR library = ?)
+		ORDER BY created_at, id
+	`, escapeLike(year), library, library)
+	if err != nil {
+		return "", fmt.Errorf("failed to query titles: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var docID, storedTitle, authorsJSON, pubDate string
+		if err := rows.Scan(&docID, &storedTitle, &authorsJSON, &pubDate); err != nil {
+			return "", fmt.Errorf("failed to scan title: %w", err)
+		}
+		if citations.ExtractYear(pubDate) != year || !titlesMatch(title, storedTitle) {
+			continue
+		}
+		var authors []string
+		if json.Unmarshal([]byte(authorsJSON), &authors) != nil || len(authors) == 0 {
+			continue
+		}
+		if authorFamilyName(authors[0]) == family {
+			return docID, nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("error iterating titles: %w", err)
+	}
+	return "", nil
+}
+
+// AddDocumentSource records another source of a stored document
+func (s *SQLiteStore) AddDocumentSource(ctx context.Context, docID string, sourceID string, sourceInfo *models.SourceInfo) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO document_sources (source_id, document_id, zotero_id, zotero_library_type, zotero_library_id, url)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (source_id) DO UPDATE SET
+			document_id = excluded.document_id,
+			zotero_id = excluded.zotero_id,
+			zotero_library_type = excluded.zotero_library_type,
+			zotero_library_id = excluded.zotero_library_id,
+			url = excluded.url
+	`, sourceID, docID, sourceInfo.ZoteroID, sourceInfo.ZoteroLibraryType, sourceInfo.ZoteroLibraryID, sourceInfo.URL)
+	if err != nil {
+		return fmt.Errorf("failed to add document source: %w", err)
+	}
+	return nil
+}
+
+// GetDocumentBySource returns the ID of the document a source is recorded
+// against, or "" if the source is unknown
+func (s *SQLiteStore) GetDocumentBySource(ctx context.Context, sourceID string) (string, error) {
+	var docID string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT document_id FROM document_sources WHERE source_id = ?
+	`, sourceID).Scan(&docID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to query document source: %w", err)
+	}
+	return docID, nil
+}
+
+// GetDocumentSources lists the sources of a document, oldest first
+func (s *SQLiteStore) GetDocumentSources(ctx context.Context, docID string) ([]models.DocumentSource, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT source_id, zotero_id, zotero_library_type, zotero_library_id, url, COALESCE(created_at, '')
+		FROM document_sources
+		WHERE document_id = ?
+		ORDER BY created_at, rowid
+	`, docID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query document sources: %w", err)
+	}
+	defer rows.Close()
+
+	var sources []models.DocumentSource
+	for rows.Next() {
+		var source models.DocumentSource
+		if err := rows.Scan(&source.SourceID, &source.ZoteroID, &source.ZoteroLibraryType,
+			&source.ZoteroLibraryID, &source.URL, &source.AddedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan document source: %w", err)
+		}
+		// Sources recorded before libraries were stored encode the library in their ID
+		if source.ZoteroLibraryType == "" {
+			if zoteroSource, ok := ParseZoteroDocumentID(source.SourceID); ok && zoteroSource.ZoteroID == source.ZoteroID {
+				source.ZoteroLibraryType = zoteroSource.ZoteroLibraryType
+				source.ZoteroLibraryID = zoteroSource.ZoteroLibraryID
+			}
+		}
+		source.Pages = ParsePageRangeDocumentID(source.SourceID)
+		sources = append(sources, source)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating document sources: %w", err)
+	}
+	return sources, nil
+}
+
+// normalizeDOI returns a DOI in canonical form (see citations.NormalizeDOI), so
+// "https://doi.org/10.1000/ABC." and "doi:10.1000/abc" compare equal, or "" if
+// it is not a valid DOI
+func normalizeDOI(doi string) string {
+	normalized, _ := citations.NormalizeDOI(doi)
+	return normalized
+}
+
+// storedDOI returns the canonical form of a DOI about to be stored, logging and
+// dropping one that is not valid rather than storing it
+func (s *SQLiteStore) storedDOI(docID string, doi string) string {
+	normalized, ok := citations.NormalizeDOI(doi)
+	if !ok && strings.TrimSpace(doi) != "" {
+		s.logger.Warn("Dropping invalid DOI %q of document %s", doi, docID)
+	}
+	return normalized
+}
+
+// normalizeTitle lowercases a title and reduces it to its letters and digits
+// separated by single spaces
+func normalizeTitle(title string) string {
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
+
+// titlesMatch reports whether two titles name the same work: they are equal once
+// normalized, or one lacks the other's subtitle
+func titlesMatch(a, b string) bool {
+	if normalizeTitle(a) == normalizeTitle(b) {
+		return true
+	}
+	mainA, _, subtitledA := strings.Cut(a, ":")
+	mainB, _, subtitledB := strings.Cut(b, ":")
+	if subtitledA == subtitledB {
+		return false
+	}
+	main := normalizeTitle(mainA)
+	return main != "" && main == normalizeTitle(mainB)
+}
+
+// authorFamilyName returns the normalized family name of an author written as
+// "Family, Given", "Given Family", or any other form citations.ParseAuthor accepts
+func authorFamilyName(author string) string {
+	return citations.NameKey(citations.ParseAuthor(author).Family)
+}
@@ -0,0 +1,182 @@
+package storage
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestFindDocumentByDOI(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	item := syntheticItem(1)
+	item.Metadata.DOI = "10.1000/ABC.123"
+	if err := store.StoreParsedItem(ctx, "doc-1", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	if err := store.StoreParsedItem(ctx, "doc-2", syntheticItem(1), &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+
+	tests := []struct {
+		doi      string
+		expected string
+	}{
+		{"10.1000/ABC.123", "doc-1"},
+		{"https://doi.org/10.1000/abc.123", "doc-1"},
+		{"doi:10.1000/abc.123.", "doc-1"},
+		{"10.1000/other", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.doi, func(t *testing.T) {
+			got, err := store.FindDocumentByDOI(ctx, "", tt.doi)
+			if err != nil {
+				t.Fatalf("FindDocumentByDOI failed: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFindDocumentByContentHash(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	hash := ContentHash([]byte("file contents"))
+	for _, docID := range []string{"doc-1", "doc-2"} {
+		item := syntheticItem(1)
+		if docID == "doc-1" {
+			item.ContentHash = hash
+		}
+		if err := store.StoreParsedItem(ctx, docID, item, &models.SourceInfo{}); err != nil {
+			t.Fatalf("StoreParsedItem failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		hash     string
+		expected string
+	}{
+		{hash, "doc-1"},
+		{ContentHash([]byte("other contents")), ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		got, err := store.FindDocumentByContentHash(ctx, "", tt.hash)
+		if err != nil {
+			t.Fatalf("FindDocumentByContentHash failed: %v", err)
+		}
+		if got != tt.expected {
+			t.Errorf("FindDocumentByContentHash(%q): expected %q, got %q", tt.hash, tt.expected, got)
+		}
+	}
+
+	// The hash is kept when a document is read back and stored again
+	item, err := store.GetParsedItem(ctx, "doc-1")
+	if err != nil {
+		t.Fatalf("GetParsedItem failed: %v", err)
+	}
+	if item.ContentHash != hash {
+		t.Errorf("Expected content hash %q, got %q", hash, item.ContentHash)
+	}
+}
+
+func TestFindDocumentByTitleAuthorYear(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	item := syntheticItem(1)
+	item.Metadata.Title = "Memory and the Archive: A Study"
+	item.Metadata.Authors = []string{"Smith, Jane", "Doe, John"}
+	item.Metadata.PublicationDate = "2020-03-01"
+	if err := store.StoreParsedItem(ctx, "doc-1", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		title    string
+		author   string
+		year     string
+		expected string
+	}{
+		{"same work", "Memory and the Archive: A Study", "Smith, Jane", "2020", "doc-1"},
+		{"case, punctuation, and name order", "memory and the archive -- a study", "Jane Smith", "March 2020", "doc-1"},
+		{"title without subtitle", "Memory and the Archive", "J. Smith", "2020", "doc-1"},
+		{"different subtitle", "Memory and the Archive: Another Study", "Smith, Jane", "2020", ""},
+		{"different year", "Memory and the Archive: A Study", "Smith, Jane", "2021", ""},
+		{"different first author", "Memory and the Archive: A Study", "Doe, John", "2020", ""},
+		{"missing author", "Memory and the Archive: A Study", "", "2020", ""},
+		{"missing year", "Memory and the Archive: A Study", "Smith, Jane", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := store.FindDocumentByTitleAuthorYear(ctx, "", tt.title, tt.author, tt.year)
+			if err != nil {
+				t.Fatalf("FindDocumentByTitleAuthorYear failed: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDocumentSources(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	urlSource := &models.SourceInfo{URL: "https://example.com/paper.pdf"}
+	if err := store.StoreParsedItem(ctx, "url_1", syntheticItem(1), urlSource); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+
+	zoteroSource := &models.SourceInfo{ZoteroID: "ABC123", ZoteroLibraryType: "group", ZoteroLibraryID: "42"}
+	if err := store.AddDocumentSource(ctx, "url_1", "zotero_group_42_ABC123", zoteroSource); err != nil {
+		t.Fatalf("AddDocumentSource failed: %v", err)
+	}
+
+	// Re-storing the document keeps its linked sources
+	if err := store.StoreParsedItem(ctx, "url_1", syntheticItem(2), urlSource); err != nil {
+		t.Fatalf("StoreParsedItem (again) failed: %v", err)
+	}
+
+	sources, err := store.GetDocumentSources(ctx, "url_1")
+	if err != nil {
+		t.Fatalf("GetDocumentSources failed: %v", err)
+	}
+	var got []models.SourceInfo
+	for _, source := range sources {
+		got = append(got, source.SourceInfo)
+	}
+	expected := []models.SourceInfo{*urlSource, *zoteroSource}
+	if len(sources) != 2 || sources[1].SourceID != "zotero_group_42_ABC123" || !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected URL and Zotero sources, got %+v", sources)
+	}
+
+	for sourceID, expected := range map[string]string{"url_1": "url_1", "zotero_group_42_ABC123": "url_1", "zotero_OTHER": ""} {
+		docID, err := store.GetDocumentBySource(ctx, sourceID)
+		if err != nil {
+			t.Fatalf("GetDocumentBySource(%s) failed: %v", sourceID, err)
+		}
+		if docID != expected {
+			t.Errorf("GetDocumentBySource(%s): expected %q, got %q", sourceID, expected, docID)
+		}
+	}
+
+	if err := store.DeleteDocument(ctx, "url_1"); err != nil {
+		t.Fatalf("DeleteDocument failed: %v", err)
+	}
+	if docID, err := store.GetDocumentBySource(ctx, "zotero_group_42_ABC123"); err != nil || docID != "" {
+		t.Errorf("Expected linked source removed with the document, got %q, %v", docID, err)
+	}
+}
@@ -0,0 +1,286 @@
+package storage
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+)
+
+const (
+	// dbKeyEnv names the environment variable holding the database key
+	dbKeyEnv = "ACADEMIC_MCP_DB_KEY"
+
+	// encryptedPrefix marks an encrypted value: the prefix, then the base64 of
+	// the nonce and the sealed text
+	encryptedPrefix = "enc1:"
+
+	// keyCheckText is sealed into the encryption row so a wrong key is detected
+	// when the database is opened rather than when content is first read
+	keyCheckText = "academic-mcp"
+)
+
+// kdfIterations is the PBKDF2-SHA256 iteration count for newly encrypted
+// databases; each database records the count its key was derived with
+var kdfIterations = 600_000
+
+var (
+	// ErrDatabaseKeyRequired is returned when opening an encrypted database without a key
+	ErrDatabaseKeyRequired = errors.New("database is encrypted; set " + dbKeyEnv + " to its key")
+
+	// ErrWrongDatabaseKey is returned when opening an encrypted database with another key
+	ErrWrongDatabaseKey = errors.New(dbKeyEnv + " is not the key the database was encrypted with")
+
+	// ErrDatabaseNotEncrypted is returned when opening a database holding
+	// plaintext documents with a key
+	ErrDatabaseNotEncrypted = errors.New("database holds documents that are not encrypted; encrypt it with `academic-mcp-local-server encrypt-db` first")
+)
+
+// encryptedColumns are the content columns encrypted at rest, with the column
+// holding each row's document ID. Metadata, references, and the other columns
+// that are searched or matched in SQL stay in plaintext.
+var encryptedColumns = []struct {
+	table, column, docIDColumn string
+}{
+	{"pages", "content", "document_id"},
+	{"documents", "full_text", "id"},
+	{"footnotes", "text", "document_id"},
+	{"endnotes", "text", "document_id"},
+	{"quotations", "quotation_text", "document_id"},
+	{"quotations", "context", "document_id"},
+	{"staged_pages", "page", "document_id"},
+}
+
+// DatabaseKey returns the key encrypted databases are opened with:
+// ACADEMIC_MCP_DB_KEY, or "" for a plaintext database
+func DatabaseKey() string {
+	return os.Getenv(dbKeyEnv)
+}
+
+// contentCipher encrypts content columns with AES-256-GCM. A nil cipher, for a
+// plaintext database, leaves values unchanged.
+type contentCipher struct {
+	aead cipher.AEAD
+}
+
+func newContentCipher(key string, salt []byte, iterations int) (*contentCipher, error) {
+	derived, err := pbkdf2.Key(sha256.New, key, salt, iterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive database key: %w", err)
+	}
+	block, err := aes.NewCipher(derived)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &contentCipher{aead: aead}, nil
+}
+
+// seal encrypts value, authenticating the column ("table.column") and document
+// it is stored in so a value cannot be moved to another row unnoticed. Empty
+// values are left empty.
+func (c *contentCipher) seal(column, docID, value string) string {
+	if c == nil || value == "" {
+		return value
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	rand.Read(nonce) // Never fails
+	sealed := c.aead.Seal(nonce, nonce, []byte(value), additionalData(column, docID))
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed)
+}
+
+// open decrypts a value sealed for column and docID. Values without the
+// encrypted prefix (empty ones) are returned as they are.
+func (c *contentCipher) open(column, docID, value string) (string, error) {
+	encoded, ok := strings.CutPrefix(value, encryptedPrefix)
+	if c == nil || !ok {
+		return value, nil
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return "", fmt.Errorf("malformed encrypted %s of document %s", column, docID)
+	}
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, additionalData(column, docID))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt %s of document %s: %w", column, docID, err)
+	}
+	return string(plaintext), nil
+}
+
+// openAll decrypts each value in place
+func (c *contentCipher) openAll(column, docID string, values ...*string) error {
+	for _, value := range values {
+		plaintext, err := c.open(column, docID, *value)
+		if err != nil {
+			return err
+		}
+		*value = plaintext
+	}
+	return nil
+}
+
+func additionalData(column, docID string) []byte {
+	return []byte(column + "\x00" + docID)
+}
+
+// loadCipher returns the cipher for a database opened with key, or nil for a
+// plaintext database opened without one. A key given for a database with no
+// documents yet encrypts it from then on.
+func loadCipher(db *sql.DB, key string) (*contentCipher, error) {
+	var salt []byte
+	var iterations int
+	var check string
+	err := db.QueryRow(`SELECT salt, iterations, key_check FROM encryption WHERE id = 1`).Scan(&salt, &iterations, &check)
+	if err == sql.ErrNoRows {
+		if key == "" {
+			return nil, nil
+		}
+		var documents int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM documents`).Scan(&documents); err != nil {
+			return nil, fmt.Errorf("failed to count documents: %w", err)
+		}
+		if documents > 0 {
+			return nil, ErrDatabaseNotEncrypted
+		}
+		tx, err := db.Begin()
+		if err != nil {
+			return nil, fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer tx.Rollback()
+		c, err := enableEncryption(tx, key)
+		if err != nil {
+			return nil, err
+		}
+		return c, tx.Commit()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encryption settings: %w", err)
+	}
+
+	if key == "" {
+		return nil, ErrDatabaseKeyRequired
+	}
+	c, err := newContentCipher(key, salt, iterations)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.open("key_check", "", check); err != nil {
+		return nil, ErrWrongDatabaseKey
+	}
+	return c, nil
+}
+
+// enableEncryption records a new salt and key check for key, unless another
+// connection has just done so, and returns the cipher for key
+func enableEncryption(tx *sql.Tx, key string) (*contentCipher, error) {
+	salt := make([]byte, 16)
+	rand.Read(salt) // Never fails
+	c, err := newContentCipher(key, salt, kdfIterations)
+	if err != nil {
+		return nil, err
+	}
+	result, err := tx.Exec(`INSERT OR IGNORE INTO encryption (id, salt, iterations, key_check) VALUES (1, ?, ?, ?)`,
+		salt, kdfIterations, c.seal("key_check", "", keyCheckText))
+	if err != nil {
+		return nil, fmt.Errorf("failed to record encryption settings: %w", err)
+	}
+	if inserted, _ := result.RowsAffected(); inserted == 0 {
+		return nil, errors.New("database was encrypted by another process while opening it; open it again")
+	}
+	return c, nil
+}
+
+// EncryptDatabase encrypts the content columns of a plaintext database with
+// key, returning the number of values encrypted. The server must not have the
+// database open. The database is vacuumed afterwards so no plaintext remains
+// in its free pages or write-ahead log.
+func EncryptDatabase(ctx context.Context, dbPath, key string, log logger.Logger) (int, error) {
+	if key == "" {
+		return 0, errors.New(dbKeyEnv + " must be set to the key to encrypt the database with")
+	}
+	if !isInMemory(dbPath) {
+		if _, err := os.Stat(dbPath); err != nil {
+			return 0, fmt.Errorf("failed to find database: %w", err)
+		}
+	}
+	store, err := NewSQLiteStore(dbPath, log)
+	if errors.Is(err, ErrDatabaseKeyRequired) {
+		return 0, fmt.Errorf("database %s is already encrypted", dbPath)
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer store.Close()
+
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	c, err := enableEncryption(tx, key)
+	if err != nil {
+		return 0, err
+	}
+
+	type value struct {
+		rowID       int64
+		docID, text string
+	}
+	encrypted := 0
+	for _, col := range encryptedColumns {
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf(`SELECT rowid, %s, COALESCE(%s, '') FROM %s`, col.docIDColumn, col.column, col.table))
+		if err != nil {
+			return 0, fmt.Errorf("failed to read %s.%s: %w", col.table, col.column, err)
+		}
+		var values []value
+		for rows.Next() {
+			var v value
+			if err := rows.Scan(&v.rowID, &v.docID, &v.text); err != nil {
+				rows.Close()
+				return 0, fmt.Errorf("failed to scan %s.%s: %w", col.table, col.column, err)
+			}
+			if v.text != "" {
+				values = append(values, v)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return 0, fmt.Errorf("error iterating %s.%s: %w", col.table, col.column, err)
+		}
+
+		update := fmt.Sprintf(`UPDATE %s SET %s = ? WHERE rowid = ?`, col.table, col.column)
+		for _, v := range values {
+			if _, err := tx.ExecContext(ctx, update, c.seal(col.table+"."+col.column, v.docID, v.text), v.rowID); err != nil {
+				return 0, fmt.Errorf("failed to encrypt %s.%s: %w", col.table, col.column, err)
+			}
+		}
+		encrypted += len(values)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit encryption: %w", err)
+	}
+	log.Info("Encrypted %d values in %s", encrypted, dbPath)
+
+	// Rewrite the file and empty the log, both of which hold the old plaintext
+	if _, err := store.db.ExecContext(ctx, `VACUUM`); err != nil {
+		return encrypted, fmt.Errorf("failed to vacuum database: %w", err)
+	}
+	if _, err := store.db.ExecContext(ctx, `PRAGMA wal_checkpoint(TRUNCATE)`); err != nil {
+		return encrypted, fmt.Errorf("failed to checkpoint database: %w", err)
+	}
+	return encrypted, nil
+}
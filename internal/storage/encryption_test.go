@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// embargoedItem is a document whose content columns all hold the word "embargoed"
+func embargoedItem() *models.ParsedItem {
+	return &models.ParsedItem{
+		Metadata:    models.ItemMetadata{Title: "Unpublished Manuscript", Citekey: "doe2025"},
+		Pages:       []string{"The embargoed findings.", "More embargoed text."},
+		PageNumbers: []string{"1", "2"},
+		FullText:    "The embargoed findings. More embargoed text.",
+		PageOffsets: []int{0, 24},
+		Footnotes:   []models.Footnote{{Marker: "1", Text: "An embargoed footnote.", PageNumber: "1"}},
+		Endnotes:    []models.Endnote{{Marker: "1", Key: "ch1-n1", Text: "An embargoed endnote."}},
+		Quotations:  []models.Quotation{{QuotationText: "embargoed findings", PageNumber: "1", Context: "Embargoed context."}},
+	}
+}
+
+// withFastKeyDerivation lowers the key derivation cost for the test
+func withFastKeyDerivation(t *testing.T) {
+	t.Helper()
+	previous := kdfIterations
+	kdfIterations = 1000
+	t.Cleanup(func() { kdfIterations = previous })
+}
+
+// assertNoPlaintext fails if any encrypted column, or the database file and its
+// log, holds the word "embargoed"
+func assertNoPlaintext(t *testing.T, store *SQLiteStore, dbPath string) {
+	t.Helper()
+	for _, col := range encryptedColumns {
+		var leaked int
+		err := store.db.QueryRow(`SELECT COUNT(*) FROM ` + col.table + ` WHERE lower(` + col.column + `) LIKE '%embargoed%'`).Scan(&leaked)
+		if err != nil {
+			t.Fatalf("Failed to query %s.%s: %v", col.table, col.column, err)
+		}
+		if leaked > 0 {
+			t.Errorf("%s.%s holds plaintext", col.table, col.column)
+		}
+	}
+	var page string
+	if err := store.db.QueryRow(`SELECT content FROM pages LIMIT 1`).Scan(&page); err != nil || !strings.HasPrefix(page, encryptedPrefix) {
+		t.Errorf("Expected an encrypted page, got %q (%v)", page, err)
+	}
+
+	if _, err := store.db.Exec(`PRAGMA wal_checkpoint(TRUNCATE)`); err != nil {
+		t.Fatalf("Checkpoint failed: %v", err)
+	}
+	for _, path := range []string{dbPath, dbPath + "-wal"} {
+		data, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("Failed to read %s: %v", path, err)
+		}
+		if bytes.Contains(bytes.ToLower(data), []byte("embargoed")) {
+			t.Errorf("%s holds plaintext content", filepath.Base(path))
+		}
+	}
+}
+
+func TestEncryptedStore(t *testing.T) {
+	withFastKeyDerivation(t)
+	log := logger.NewNoOpLogger()
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "academic.db")
+
+	store, err := NewSQLiteStoreWithKey(dbPath, "correct horse", log)
+	if err != nil {
+		t.Fatalf("NewSQLiteStoreWithKey failed: %v", err)
+	}
+	item := embargoedItem()
+	if err := store.StoreParsedItem(ctx, "doc-1", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	assertNoPlaintext(t, store, dbPath)
+
+	got, err := store.GetParsedItem(ctx, "doc-1")
+	if err != nil {
+		t.Fatalf("GetParsedItem failed: %v", err)
+	}
+	if !reflect.DeepEqual(got.Pages, item.Pages) || got.FullText != item.FullText ||
+		!reflect.DeepEqual(got.Footnotes, item.Footnotes) || !reflect.DeepEqual(got.Endnotes, item.Endnotes) ||
+		got.Quotations[0].QuotationText != "embargoed findings" || got.Quotations[0].Context != "Embargoed context." {
+		t.Errorf("Expected the content decrypted, got %+v", got)
+	}
+	if page, err := store.GetPageBySourceNumber(ctx, "doc-1", "2"); err != nil || page != "More embargoed text." {
+		t.Errorf("Expected page 2 decrypted, got %q (%v)", page, err)
+	}
+	if footnote, err := store.GetFootnote(ctx, "doc-1", 0); err != nil || footnote.Text != "An embargoed footnote." {
+		t.Errorf("Expected the footnote decrypted, got %+v (%v)", footnote, err)
+	}
+
+	// A value moved to another document does not decrypt
+	if _, err := store.db.Exec(`INSERT INTO documents (id, title) VALUES ('doc-2', 'Copy')`); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	if _, err := store.db.Exec(`INSERT INTO pages (document_id, page_number, source_page_number, content)
+		SELECT 'doc-2', page_number, source_page_number, content FROM pages WHERE document_id = 'doc-1'`); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	if _, err := store.GetPages(ctx, "doc-2"); err == nil {
+		t.Error("Expected a page moved to another document to fail to decrypt")
+	}
+	store.Close()
+
+	if _, err := NewSQLiteStore(dbPath, log); !errors.Is(err, ErrDatabaseKeyRequired) {
+		t.Errorf("Expected opening without the key to fail, got %v", err)
+	}
+	if _, err := NewSQLiteStoreWithKey(dbPath, "wrong", log); !errors.Is(err, ErrWrongDatabaseKey) {
+		t.Errorf("Expected opening with another key to fail, got %v", err)
+	}
+	reopened, err := NewSQLiteStoreWithKey(dbPath, "correct horse", log)
+	if err != nil {
+		t.Fatalf("Reopening with the key failed: %v", err)
+	}
+	defer reopened.Close()
+	if pages, err := reopened.GetPages(ctx, "doc-1"); err != nil || !reflect.DeepEqual(pages, item.Pages) {
+		t.Errorf("Expected the pages after reopening, got %q (%v)", pages, err)
+	}
+}
+
+func TestEncryptDatabase(t *testing.T) {
+	withFastKeyDerivation(t)
+	log := logger.NewNoOpLogger()
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "academic.db")
+
+	plain, err := NewSQLiteStore(dbPath, log)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore failed: %v", err)
+	}
+	item := embargoedItem()
+	if err := plain.StoreParsedItem(ctx, "doc-1", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	plain.Close()
+
+	if _, err := NewSQLiteStoreWithKey(dbPath, "secret", log); !errors.Is(err, ErrDatabaseNotEncrypted) {
+		t.Errorf("Expected a key for a plaintext database to be refused, got %v", err)
+	}
+	if _, err := EncryptDatabase(ctx, dbPath, "", log); err == nil {
+		t.Error("Expected encrypting without a key to fail")
+	}
+
+	count, err := EncryptDatabase(ctx, dbPath, "secret", log)
+	if err != nil {
+		t.Fatalf("EncryptDatabase failed: %v", err)
+	}
+	// Two pages, the full text, a footnote, an endnote, and a quotation's text and context
+	if count != 7 {
+		t.Errorf("Expected 7 values encrypted, got %d", count)
+	}
+	if _, err := EncryptDatabase(ctx, dbPath, "secret", log); err == nil || !strings.Contains(err.Error(), "already encrypted") {
+		t.Errorf("Expected encrypting twice to fail, got %v", err)
+	}
+
+	store, err := NewSQLiteStoreWithKey(dbPath, "secret", log)
+	if err != nil {
+		t.Fatalf("Opening the encrypted database failed: %v", err)
+	}
+	defer store.Close()
+	assertNoPlaintext(t, store, dbPath)
+	got, err := store.GetParsedItem(ctx, "doc-1")
+	if err != nil {
+		t.Fatalf("GetParsedItem failed: %v", err)
+	}
+	if !reflect.DeepEqual(got.Pages, item.Pages) || got.FullText != item.FullText || got.Quotations[0].Context != "Embargoed context." {
+		t.Errorf("Expected the encrypted content to read back, got %+v", got)
+	}
+
+	if _, err := EncryptDatabase(ctx, filepath.Join(t.TempDir(), "missing.db"), "secret", log); err == nil {
+		t.Error("Expected encrypting a missing database to fail")
+	}
+}
@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// GetFetchInfo retrieves how the bytes a document was parsed from were
+// fetched, or nil if that was not recorded, as for documents parsed before it
+// was
+func (s *SQLiteStore) GetFetchInfo(ctx context.Context, docID string) (*models.FetchInfo, error) {
+	exists, err := s.DocumentExists(ctx, docID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("document %w: %s", ErrNotFound, docID)
+	}
+	return s.fetchInfo(ctx, docID)
+}
+
+// fetchInfo reads a document's fetch info without checking that the document
+// exists
+func (s *SQLiteStore) fetchInfo(ctx context.Context, docID string) (*models.FetchInfo, error) {
+	var fetch models.FetchInfo
+	err := s.db.QueryRowContext(ctx, `
+		SELECT source_url, final_url, content_type, fetched_at, sha256, size
+		FROM document_fetch_info
+		WHERE document_id = ?
+	`, docID).Scan(&fetch.SourceURL, &fetch.FinalURL, &fetch.ContentType, &fetch.FetchedAt, &fetch.SHA256, &fetch.Size)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query fetch info: %w", err)
+	}
+	return &fetch, nil
+}
+
+// storeFetchInfo records a document's fetch info, replacing any earlier one
+func storeFetchInfo(ctx context.Context, tx *sql.Tx, docID string, fetch *models.FetchInfo) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT OR REPLACE INTO document_fetch_info (document_id, source_url, final_url, content_type, fetched_at, sha256, size)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, docID, fetch.SourceURL, fetch.FinalURL, fetch.ContentType, fetch.FetchedAt, fetch.SHA256, fetch.Size)
+	if err != nil {
+		return fmt.Errorf("failed to store fetch info: %w", err)
+	}
+	return nil
+}
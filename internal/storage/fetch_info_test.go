@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestFetchInfo(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	// A document stored without fetch info has none
+	item := syntheticItem(1)
+	if err := store.StoreParsedItem(ctx, "doc", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	if fetch, err := store.GetFetchInfo(ctx, "doc"); err != nil || fetch != nil {
+		t.Fatalf("Expected no fetch info, got %+v, %v", fetch, err)
+	}
+
+	fetch := models.FetchInfo{
+		SourceURL:   "http://example.com/paper",
+		FinalURL:    "https://example.com/files/paper.pdf",
+		ContentType: "application/pdf",
+		FetchedAt:   "2026-01-02T03:04:05Z",
+		SHA256:      "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		Size:        4,
+	}
+	item.Fetch = &fetch
+	if err := store.StoreParsedItem(ctx, "doc", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	got, err := store.GetFetchInfo(ctx, "doc")
+	if err != nil || got == nil || *got != fetch {
+		t.Fatalf("Expected %+v, got %+v, %v", fetch, got, err)
+	}
+	stored, err := store.GetParsedItem(ctx, "doc")
+	if err != nil {
+		t.Fatalf("GetParsedItem failed: %v", err)
+	}
+	if stored.Fetch == nil || *stored.Fetch != fetch {
+		t.Errorf("Expected the parsed item to carry %+v, got %+v", fetch, stored.Fetch)
+	}
+
+	// Storing the document again without a fetch keeps what it was parsed from
+	stored.Fetch = nil
+	if err := store.StoreParsedItem(ctx, "doc", stored, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	if got, err := store.GetFetchInfo(ctx, "doc"); err != nil || got == nil || *got != fetch {
+		t.Errorf("Expected the fetch info kept, got %+v, %v", got, err)
+	}
+
+	// A new fetch replaces it
+	refetched := fetch
+	refetched.FetchedAt, refetched.SHA256, refetched.Size = "2026-02-03T04:05:06Z", "0badc0de", 8
+	stored.Fetch = &refetched
+	if err := store.StoreParsedItem(ctx, "doc", stored, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	if got, err := store.GetFetchInfo(ctx, "doc"); err != nil || got == nil || *got != refetched {
+		t.Errorf("Expected the fetch info replaced with %+v, got %+v, %v", refetched, got, err)
+	}
+
+	if err := store.DeleteDocument(ctx, "doc"); err != nil {
+		t.Fatalf("DeleteDocument failed: %v", err)
+	}
+	var rows int
+	if err := store.db.QueryRow(`SELECT COUNT(*) FROM document_fetch_info`).Scan(&rows); err != nil || rows != 0 {
+		t.Errorf("Expected the fetch info deleted with the document, got %d rows, %v", rows, err)
+	}
+	if _, err := store.GetFetchInfo(ctx, "doc"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown document, got %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// imageColumns are the columns read into a models.Image by scanImage
+const imageColumns = `image_url, image_description, caption, page_index, mime_type, width, height, source_kind, stable_id`
+
+// scanImage reads an image selected with imageColumns
+func scanImage(row interface{ Scan(...any) error }) (*models.Image, error) {
+	var img models.Image
+	if err := row.Scan(&img.ImageURL, &img.ImageDescription, &img.Caption,
+		&img.PageIndex, &img.MIMEType, &img.Width, &img.Height, &img.SourceKind, &img.StableID); err != nil {
+		return nil, err
+	}
+	return &img, nil
+}
+
+// storeImageBlobs brings a document's stored image data in line with its
+// images: data set on an image replaces what is stored, an image with a MIME
+// type but no data (as loaded by GetParsedItem) keeps its stored data, and data
+// for images without a MIME type or beyond the last image is removed
+func storeImageBlobs(ctx context.Context, tx *sql.Tx, docID string, images []models.Image) error {
+	for i, img := range images {
+		var err error
+		switch {
+		case len(img.Data) > 0:
+			_, err = tx.ExecContext(ctx, `
+				INSERT OR REPLACE INTO image_blobs (document_id, image_index, mime_type, data)
+				VALUES (?, ?, ?, ?)
+			`, docID, i, img.MIMEType, img.Data)
+		case img.MIMEType == "":
+			_, err = tx.ExecContext(ctx, `DELETE FROM image_blobs WHERE document_id = ? AND image_index = ?`, docID, i)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to store data for image %d: %w", i, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM image_blobs WHERE document_id = ? AND image_index >= ?`, docID, len(images)); err != nil {
+		return fmt.Errorf("failed to prune image data: %w", err)
+	}
+	return nil
+}
+
+// GetImageData retrieves the bytes and MIME type of an image extracted from the
+// document (0-indexed), or ErrNotFound if none was stored
+func (s *SQLiteStore) GetImageData(ctx context.Context, docID string, imageIndex int) ([]byte, string, error) {
+	var data []byte
+	var mimeType string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT data, mime_type FROM image_blobs
+		WHERE document_id = ? AND image_index = ?
+	`, docID, imageIndex).Scan(&data, &mimeType)
+	if err == sql.ErrNoRows {
+		return nil, "", fmt.Errorf("image data %w: %s index %d", ErrNotFound, docID, imageIndex)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to query image data: %w", err)
+	}
+	return data, mimeType, nil
+}
+
+// GetPageImage retrieves the PNG rendered of a document's page (1-indexed) at
+// dpi, or ErrNotFound if it has not been rendered
+func (s *SQLiteStore) GetPageImage(ctx context.Context, docID string, pageNum int, dpi int) ([]byte, error) {
+	var data []byte
+	err := s.db.QueryRowContext(ctx, `
+		SELECT data FROM page_images
+		WHERE document_id = ? AND page_number = ? AND dpi = ?
+	`, docID, pageNum, dpi).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("page image %w: %s page %d at %d dpi", ErrNotFound, docID, pageNum, dpi)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query page image: %w", err)
+	}
+	return data, nil
+}
+
+// StorePageImage stores the PNG rendered of a document's page (1-indexed) at
+// dpi, replacing any stored before
+func (s *SQLiteStore) StorePageImage(ctx context.Context, docID string, pageNum int, dpi int, data []byte) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT OR REPLACE INTO page_images (document_id, page_number, dpi, data)
+		VALUES (?, ?, ?, ?)
+	`, docID, pageNum, dpi, data)
+	if err != nil {
+		return fmt.Errorf("failed to store page image: %w", err)
+	}
+	return nil
+}
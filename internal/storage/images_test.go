@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestImageData(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	item := syntheticItem(2)
+	item.Images = []models.Image{
+		{Caption: "Figure 1", PageIndex: 1, MIMEType: "image/png", Width: 40, Height: 30, Data: []byte("png-bytes")},
+		{Caption: "Chart", PageIndex: 2},
+		{Caption: "Figure 2", PageIndex: 2, MIMEType: "image/jpeg", Data: []byte("jpeg-bytes")},
+	}
+	if err := store.StoreParsedItem(ctx, "doc-1", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+
+	image, err := store.GetImage(ctx, "doc-1", 0)
+	if err != nil {
+		t.Fatalf("GetImage failed: %v", err)
+	}
+	if image.PageIndex != 1 || image.MIMEType != "image/png" || image.Width != 40 || image.Height != 30 || image.Data != nil {
+		t.Errorf("Expected image details without data, got %+v", image)
+	}
+	data, mimeType, err := store.GetImageData(ctx, "doc-1", 0)
+	if err != nil || string(data) != "png-bytes" || mimeType != "image/png" {
+		t.Errorf("Expected PNG data, got %q, %q, %v", data, mimeType, err)
+	}
+	if _, _, err := store.GetImageData(ctx, "doc-1", 1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an image without data, got %v", err)
+	}
+
+	// Re-storing the loaded document, as page re-parsing does, keeps the data
+	loaded, err := store.GetParsedItem(ctx, "doc-1")
+	if err != nil {
+		t.Fatalf("GetParsedItem failed: %v", err)
+	}
+	if err := store.StoreParsedItem(ctx, "doc-1", loaded, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	if data, _, err := store.GetImageData(ctx, "doc-1", 2); err != nil || string(data) != "jpeg-bytes" {
+		t.Errorf("Expected data to survive re-storing, got %q, %v", data, err)
+	}
+
+	// A re-parse with fewer images, or without data, drops the stale data
+	item.Images = []models.Image{{Caption: "Figure 1", PageIndex: 1}}
+	if err := store.StoreParsedItem(ctx, "doc-1", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	for _, index := range []int{0, 2} {
+		if _, _, err := store.GetImageData(ctx, "doc-1", index); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Image %d: expected stale data to be removed, got %v", index, err)
+		}
+	}
+
+	item.Images = []models.Image{{Caption: "Figure 1", MIMEType: "image/png", Data: []byte("png-bytes")}}
+	if err := store.StoreParsedItem(ctx, "doc-1", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	if err := store.DeleteDocument(ctx, "doc-1"); err != nil {
+		t.Fatalf("DeleteDocument failed: %v", err)
+	}
+	if _, _, err := store.GetImageData(ctx, "doc-1", 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected image data to be deleted with the document, got %v", err)
+	}
+}
+
+func TestPageImages(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	if err := store.StoreParsedItem(ctx, "doc-1", syntheticItem(2), &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+
+	if _, err := store.GetPageImage(ctx, "doc-1", 1, 150); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound before rendering, got %v", err)
+	}
+	if err := store.StorePageImage(ctx, "doc-1", 1, 150, []byte("png-150")); err != nil {
+		t.Fatalf("StorePageImage failed: %v", err)
+	}
+	if err := store.StorePageImage(ctx, "doc-1", 1, 300, []byte("png-300")); err != nil {
+		t.Fatalf("StorePageImage failed: %v", err)
+	}
+	if data, err := store.GetPageImage(ctx, "doc-1", 1, 150); err != nil || string(data) != "png-150" {
+		t.Errorf("Expected the 150 dpi image, got %q, %v", data, err)
+	}
+	if data, err := store.GetPageImage(ctx, "doc-1", 1, 300); err != nil || string(data) != "png-300" {
+		t.Errorf("Expected the 300 dpi image, got %q, %v", data, err)
+	}
+	if _, err := store.GetPageImage(ctx, "doc-1", 2, 150); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for another page, got %v", err)
+	}
+
+	// Storing the document again, whose pages may have changed, clears its images
+	if err := store.StoreParsedItem(ctx, "doc-1", syntheticItem(2), &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	if _, err := store.GetPageImage(ctx, "doc-1", 1, 150); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected images cleared when the document is stored again, got %v", err)
+	}
+
+	// So does deleting it
+	if err := store.StorePageImage(ctx, "doc-1", 1, 150, []byte("png-150")); err != nil {
+		t.Fatalf("StorePageImage failed: %v", err)
+	}
+	if err := store.DeleteDocument(ctx, "doc-1"); err != nil {
+		t.Fatalf("DeleteDocument failed: %v", err)
+	}
+	if _, err := store.GetPageImage(ctx, "doc-1", 1, 150); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected images deleted with the document, got %v", err)
+	}
+}
@@ -0,0 +1,182 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// jobItemColumns are the columns read into a models.JobItem by scanJobItem
+const jobItemColumns = `job_items.job_id, item_index, zotero_id, url, raw_data, doc_type, library_type, library_id,
+	status, document_id, error, error_code, COALESCE(started_at, ''), COALESCE(finished_at, ''),
+	(SELECT link_duplicates FROM jobs WHERE jobs.id = job_items.job_id)`
+
+// scanJobItem reads a job item selected with jobItemColumns
+func scanJobItem(row interface{ Scan(...any) error }) (*models.JobItem, error) {
+	var item models.JobItem
+	var status, errorCode string
+	if err := row.Scan(&item.JobID, &item.Index, &item.ZoteroID, &item.URL, &item.RawData, &item.DocType,
+		&item.Library.Type, &item.Library.ID, &status, &item.DocumentID, &item.Error, &errorCode,
+		&item.StartedAt, &item.FinishedAt, &item.LinkDuplicates); err != nil {
+		return nil, err
+	}
+	item.Status = models.JobStatus(status)
+	item.ErrorCode = models.ErrorCode(errorCode)
+	return &item, nil
+}
+
+// CreateJob stores a job and its items, all pending. The caller sets the job ID.
+func (s *SQLiteStore) CreateJob(ctx context.Context, job *models.Job) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `INSERT INTO jobs (id, link_duplicates) VALUES (?, ?)`, job.ID, job.LinkDuplicates); err != nil {
+		return fmt.Errorf("failed to insert job: %w", err)
+	}
+	err = insertRows(ctx, tx, "job item", `
+		INSERT INTO job_items (job_id, item_index, zotero_id, url, raw_data, doc_type, library_type, library_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, len(job.Items), func(i int) []any {
+		item := job.Items[i]
+		return []any{job.ID, i, item.ZoteroID, item.URL, item.RawData, item.DocType, item.Library.Type, item.Library.ID}
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetJob retrieves a job with its items and their progress, or ErrNotFound
+func (s *SQLiteStore) GetJob(ctx context.Context, jobID string) (*models.Job, error) {
+	job := models.Job{ID: jobID, Counts: make(map[string]int)}
+	err := s.db.QueryRowContext(ctx, `SELECT link_duplicates, COALESCE(created_at, '') FROM jobs WHERE id = ?`, jobID).
+		Scan(&job.LinkDuplicates, &job.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("job %w: %s", ErrNotFound, jobID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query job: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+jobItemColumns+` FROM job_items
+		WHERE job_id = ?
+		ORDER BY item_index
+	`, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query job items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := scanJobItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job item: %w", err)
+		}
+		job.Items = append(job.Items, *item)
+		job.Counts[string(item.Status)]++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating job items: %w", err)
+	}
+
+	job.Status = jobStatus(job.Counts, len(job.Items))
+	return &job, nil
+}
+
+// jobStatus derives a job's status from the number of its items in each status:
+// pending until one starts, completed once none is pending or running, and
+// running in between
+func jobStatus(counts map[string]int, total int) models.JobStatus {
+	switch {
+	case counts[string(models.JobPending)] == total:
+		return models.JobPending
+	case counts[string(models.JobPending)] == 0 && counts[string(models.JobRunning)] == 0:
+		return models.JobCompleted
+	default:
+		return models.JobRunning
+	}
+}
+
+// ClaimJobItem marks the oldest pending job item running and returns it, or
+// nil if none is pending
+func (s *SQLiteStore) ClaimJobItem(ctx context.Context) (*models.JobItem, error) {
+	item, err := scanJobItem(s.db.QueryRowContext(ctx, `
+		UPDATE job_items SET status = ?, started_at = CURRENT_TIMESTAMP
+		WHERE id = (SELECT id FROM job_items WHERE status = ? ORDER BY id LIMIT 1)
+		RETURNING `+jobItemColumns,
+		models.JobRunning, models.JobPending))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job item: %w", err)
+	}
+	return item, nil
+}
+
+// FinishJobItem records the outcome of a running job item (its Status,
+// DocumentID, Error, and ErrorCode) and drops its raw data
+func (s *SQLiteStore) FinishJobItem(ctx context.Context, item *models.JobItem) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE job_items
+		SET status = ?, document_id = ?, error = ?, error_code = ?, raw_data = NULL, finished_at = CURRENT_TIMESTAMP
+		WHERE job_id = ? AND item_index = ? AND status = ?
+	`, item.Status, item.DocumentID, item.Error, item.ErrorCode, item.JobID, item.Index, models.JobRunning)
+	if err != nil {
+		return fmt.Errorf("failed to update job item: %w", err)
+	}
+	return nil
+}
+
+// CancelJob cancels a job's pending items, returning how many were cancelled,
+// or ErrNotFound if there is no such job
+func (s *SQLiteStore) CancelJob(ctx context.Context, jobID string) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM jobs WHERE id = ?)`, jobID).Scan(&exists); err != nil {
+		return 0, fmt.Errorf("failed to query job: %w", err)
+	}
+	if !exists {
+		return 0, fmt.Errorf("job %w: %s", ErrNotFound, jobID)
+	}
+	result, err := tx.ExecContext(ctx, `
+		UPDATE job_items SET status = ?, raw_data = NULL, finished_at = CURRENT_TIMESTAMP
+		WHERE job_id = ? AND status = ?
+	`, models.JobCancelled, jobID, models.JobPending)
+	if err != nil {
+		return 0, fmt.Errorf("failed to cancel job items: %w", err)
+	}
+	cancelled, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	return int(cancelled), tx.Commit()
+}
+
+// RequeueRunningJobItems returns items left running by a process that stopped
+// to pending, returning how many there were
+func (s *SQLiteStore) RequeueRunningJobItems(ctx context.Context) (int, error) {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE job_items SET status = ?, started_at = NULL
+		WHERE status = ?
+	`, models.JobPending, models.JobRunning)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue job items: %w", err)
+	}
+	requeued, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	return int(requeued), nil
+}
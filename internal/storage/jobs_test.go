@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestJobs(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	job := &models.Job{ID: "job-1", LinkDuplicates: true, Items: []models.JobItem{
+		{URL: "https://example.com/a.pdf"},
+		{RawData: []byte("%PDF-raw"), DocType: "pdf"},
+		{ZoteroID: "ABC123", Library: models.ZoteroLibrary{Type: "group", ID: "42"}},
+	}}
+	if err := store.CreateJob(ctx, job); err != nil {
+		t.Fatalf("CreateJob failed: %v", err)
+	}
+	stored, err := store.GetJob(ctx, "job-1")
+	if err != nil {
+		t.Fatalf("GetJob failed: %v", err)
+	}
+	if stored.Status != models.JobPending || len(stored.Items) != 3 || stored.Counts["pending"] != 3 || stored.CreatedAt == "" {
+		t.Errorf("Expected a pending job with 3 items, got %+v", stored)
+	}
+
+	// Items are claimed in order, with their job's settings
+	first, err := store.ClaimJobItem(ctx)
+	if err != nil || first == nil || first.Index != 0 || first.URL != "https://example.com/a.pdf" || !first.LinkDuplicates || first.Status != models.JobRunning {
+		t.Fatalf("Expected the first item to be claimed, got %+v, %v", first, err)
+	}
+	second, err := store.ClaimJobItem(ctx)
+	if err != nil || second == nil || string(second.RawData) != "%PDF-raw" || second.DocType != "pdf" {
+		t.Fatalf("Expected the second item with its raw data, got %+v, %v", second, err)
+	}
+
+	first.Status, first.DocumentID = models.JobDone, "doc-1"
+	if err := store.FinishJobItem(ctx, first); err != nil {
+		t.Fatalf("FinishJobItem failed: %v", err)
+	}
+	if cancelled, err := store.CancelJob(ctx, "job-1"); err != nil || cancelled != 1 {
+		t.Errorf("Expected 1 pending item cancelled, got %d, %v", cancelled, err)
+	}
+	if item, err := store.ClaimJobItem(ctx); err != nil || item != nil {
+		t.Errorf("Expected nothing left to claim, got %+v, %v", item, err)
+	}
+
+	stored, err = store.GetJob(ctx, "job-1")
+	if err != nil {
+		t.Fatalf("GetJob failed: %v", err)
+	}
+	if stored.Status != models.JobRunning || stored.Items[0].DocumentID != "doc-1" || stored.Items[0].FinishedAt == "" || stored.Items[2].Status != models.JobCancelled {
+		t.Errorf("Expected a running job with one done and one cancelled item, got %+v", stored)
+	}
+
+	second.Status, second.Error, second.ErrorCode = models.JobFailed, "parse failed", models.ErrorUpstreamLLM
+	if err := store.FinishJobItem(ctx, second); err != nil {
+		t.Fatalf("FinishJobItem failed: %v", err)
+	}
+	stored, err = store.GetJob(ctx, "job-1")
+	if err != nil {
+		t.Fatalf("GetJob failed: %v", err)
+	}
+	if stored.Status != models.JobCompleted || stored.Items[1].ErrorCode != models.ErrorUpstreamLLM || stored.Items[1].RawData != nil {
+		t.Errorf("Expected a completed job whose failed item dropped its raw data, got %+v", stored)
+	}
+
+	if _, err := store.GetJob(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing job, got %v", err)
+	}
+	if _, err := store.CancelJob(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound cancelling a missing job, got %v", err)
+	}
+}
+
+func TestRequeueRunningJobItems(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.db")
+	store, err := NewSQLiteStore(path, logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("NewSQLiteStore failed: %v", err)
+	}
+	ctx := context.Background()
+	if err := store.CreateJob(ctx, &models.Job{ID: "job-1", Items: []models.JobItem{{URL: "a"}, {URL: "b"}}}); err != nil {
+		t.Fatalf("CreateJob failed: %v", err)
+	}
+	if _, err := store.ClaimJobItem(ctx); err != nil {
+		t.Fatalf("ClaimJobItem failed: %v", err)
+	}
+	store.Close()
+
+	// A new process finds the item it was working on and queues it again
+	store, err = NewSQLiteStore(path, logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("NewSQLiteStore failed: %v", err)
+	}
+	defer store.Close()
+	if requeued, err := store.RequeueRunningJobItems(ctx); err != nil || requeued != 1 {
+		t.Errorf("Expected 1 item requeued, got %d, %v", requeued, err)
+	}
+	item, err := store.ClaimJobItem(ctx)
+	if err != nil || item == nil || item.URL != "a" {
+		t.Errorf("Expected the interrupted item to be claimed first, got %+v, %v", item, err)
+	}
+}
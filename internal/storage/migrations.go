@@ -0,0 +1,719 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// migration is one step of schema evolution. Steps must be idempotent: databases
+// created before versioning was introduced already contain some of the tables
+// and columns that later migrations add.
+type migration struct {
+	version     int
+	description string
+	apply       func(tx *sql.Tx) error
+}
+
+// migrations are applied in order on startup. Append new migrations to the end and
+// never edit one that has shipped; the schema_version table records each one applied.
+var migrations = []migration{
+	{1, "create base tables", execStatements(`
+		CREATE TABLE IF NOT EXISTS documents (
+			id TEXT PRIMARY KEY,
+			title TEXT,
+			authors TEXT,
+			publication_date TEXT,
+			publication TEXT,
+			doi TEXT,
+			abstract TEXT,
+			zotero_id TEXT,
+			url TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS pages (
+			document_id TEXT NOT NULL,
+			page_number INTEGER NOT NULL,
+			source_page_number TEXT NOT NULL,
+			content TEXT,
+			PRIMARY KEY (document_id, page_number),
+			FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_pages_source_number ON pages(document_id, source_page_number);
+
+		CREATE TABLE IF NOT EXISTS document_references (
+			document_id TEXT NOT NULL,
+			ref_index INTEGER NOT NULL,
+			reference_text TEXT,
+			doi TEXT,
+			PRIMARY KEY (document_id, ref_index),
+			FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE
+		);
+
+		CREATE TABLE IF NOT EXISTS images (
+			document_id TEXT NOT NULL,
+			image_index INTEGER NOT NULL,
+			image_url TEXT,
+			image_description TEXT,
+			caption TEXT,
+			PRIMARY KEY (document_id, image_index),
+			FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE
+		);
+
+		CREATE TABLE IF NOT EXISTS document_tables (
+			document_id TEXT NOT NULL,
+			table_index INTEGER NOT NULL,
+			table_id TEXT,
+			table_title TEXT,
+			table_data TEXT,
+			PRIMARY KEY (document_id, table_index),
+			FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE
+		);
+
+		CREATE TABLE IF NOT EXISTS footnotes (
+			document_id TEXT NOT NULL,
+			footnote_index INTEGER NOT NULL,
+			marker TEXT,
+			text TEXT,
+			page_number TEXT,
+			in_text_page TEXT,
+			PRIMARY KEY (document_id, footnote_index),
+			FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE
+		);
+
+		CREATE TABLE IF NOT EXISTS endnotes (
+			document_id TEXT NOT NULL,
+			endnote_index INTEGER NOT NULL,
+			marker TEXT,
+			text TEXT,
+			page_number TEXT,
+			PRIMARY KEY (document_id, endnote_index),
+			FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_documents_doi ON documents(doi);
+		CREATE INDEX IF NOT EXISTS idx_documents_zotero_id ON documents(zotero_id);
+		CREATE INDEX IF NOT EXISTS idx_documents_publication_date ON documents(publication_date);
+	`)},
+	// Existing rows get '' rather than NULL since these columns are read into plain strings
+	{2, "add document summaries", steps(
+		addColumns(column{"documents", "summary", "TEXT"}),
+		execStatements(`UPDATE documents SET summary = '' WHERE summary IS NULL`),
+	)},
+	{3, "add extended bibliographic metadata", steps(
+		addColumns(
+			column{"documents", "item_type", "TEXT"},
+			column{"documents", "publisher", "TEXT"},
+			column{"documents", "volume", "TEXT"},
+			column{"documents", "issue", "TEXT"},
+			column{"documents", "pages", "TEXT"},
+			column{"documents", "issn", "TEXT"},
+			column{"documents", "isbn", "TEXT"},
+			column{"documents", "metadata_url", "TEXT"},
+			column{"documents", "metadata_source", "TEXT"},
+		),
+		execStatements(`
+			UPDATE documents SET item_type = '' WHERE item_type IS NULL;
+			UPDATE documents SET publisher = '' WHERE publisher IS NULL;
+			UPDATE documents SET volume = '' WHERE volume IS NULL;
+			UPDATE documents SET issue = '' WHERE issue IS NULL;
+			UPDATE documents SET pages = '' WHERE pages IS NULL;
+			UPDATE documents SET issn = '' WHERE issn IS NULL;
+			UPDATE documents SET isbn = '' WHERE isbn IS NULL;
+			UPDATE documents SET metadata_url = '' WHERE metadata_url IS NULL;
+			UPDATE documents SET metadata_source = '' WHERE metadata_source IS NULL;
+		`),
+	)},
+	// citekey stays NULL when unset so the unique index ignores documents without one
+	{4, "add citekeys", steps(
+		addColumns(column{"documents", "citekey", "TEXT"}),
+		execStatements(`CREATE UNIQUE INDEX IF NOT EXISTS idx_documents_citekey ON documents(citekey) WHERE citekey IS NOT NULL`),
+	)},
+	{5, "add quotations", execStatements(`
+		CREATE TABLE IF NOT EXISTS quotations (
+			document_id TEXT NOT NULL,
+			quotation_index INTEGER NOT NULL,
+			quotation_text TEXT,
+			page_number TEXT,
+			context TEXT,
+			relevance TEXT,
+			PRIMARY KEY (document_id, quotation_index),
+			FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE
+		);
+	`)},
+	{6, "add scan detection flags", addColumns(
+		column{"documents", "is_scanned", "INTEGER NOT NULL DEFAULT 0"},
+		column{"pages", "is_scanned", "INTEGER NOT NULL DEFAULT 0"},
+		column{"pages", "near_empty", "INTEGER NOT NULL DEFAULT 0"},
+	)},
+	{7, "add reference page numbers", addColumns(
+		column{"document_references", "page_number", "TEXT NOT NULL DEFAULT ''"},
+	)},
+	{8, "add text chunk counts", addColumns(
+		column{"documents", "chunk_count", "INTEGER NOT NULL DEFAULT 0"},
+	)},
+	{9, "add linked PDF URLs", addColumns(
+		column{"documents", "pdf_url", "TEXT NOT NULL DEFAULT ''"},
+	)},
+	{10, "add reference page indexes", addColumns(
+		column{"document_references", "page_index", "INTEGER NOT NULL DEFAULT 0"},
+	)},
+	{11, "add sections", execStatements(`
+		CREATE TABLE IF NOT EXISTS sections (
+			document_id TEXT NOT NULL,
+			section_index INTEGER NOT NULL,
+			title TEXT NOT NULL,
+			level INTEGER NOT NULL,
+			start_page TEXT NOT NULL,
+			end_page TEXT NOT NULL,
+			start_page_index INTEGER NOT NULL,
+			end_page_index INTEGER NOT NULL,
+			start_offset INTEGER NOT NULL,
+			end_offset INTEGER NOT NULL,
+			PRIMARY KEY (document_id, section_index),
+			FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE
+		);
+	`)},
+	{12, "add quotation verification", addColumns(
+		column{"quotations", "verified", "INTEGER NOT NULL DEFAULT 0"},
+		column{"quotations", "match_score", "REAL NOT NULL DEFAULT 0"},
+	)},
+	// usage has no foreign key: re-storing a document replaces its documents row,
+	// which would cascade, so DeleteDocument removes usage rows itself
+	{13, "add token usage", execStatements(`
+		CREATE TABLE IF NOT EXISTS usage (
+			document_id TEXT NOT NULL,
+			operation TEXT NOT NULL,
+			model TEXT NOT NULL,
+			requests INTEGER NOT NULL DEFAULT 0,
+			input_tokens INTEGER NOT NULL DEFAULT 0,
+			output_tokens INTEGER NOT NULL DEFAULT 0,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (document_id, operation, model)
+		);
+	`)},
+	// Like usage, document_sources has no foreign key and is cleared by DeleteDocument.
+	// Each existing document is recorded as its own source.
+	{14, "add document sources", execStatements(`
+		CREATE TABLE IF NOT EXISTS document_sources (
+			source_id TEXT PRIMARY KEY,
+			document_id TEXT NOT NULL,
+			zotero_id TEXT NOT NULL DEFAULT '',
+			zotero_library_type TEXT NOT NULL DEFAULT '',
+			zotero_library_id TEXT NOT NULL DEFAULT '',
+			url TEXT NOT NULL DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_document_sources_document ON document_sources(document_id);
+
+		INSERT OR IGNORE INTO document_sources (source_id, document_id, zotero_id, url)
+		SELECT id, id, COALESCE(zotero_id, ''), COALESCE(url, '') FROM documents;
+	`)},
+	// Tables parsed before this hold only their raw data; the resources structure them on read
+	{15, "add structured tables", addColumns(
+		column{"document_tables", "table_columns", "TEXT NOT NULL DEFAULT ''"},
+		column{"document_tables", "table_rows", "TEXT NOT NULL DEFAULT ''"},
+		column{"document_tables", "parse_error", "TEXT NOT NULL DEFAULT ''"},
+	)},
+	// The authors JSON on documents is kept; document_authors holds each author
+	// split into parts, with keys for matching name variants
+	{16, "add document authors", steps(
+		execStatements(`
+			CREATE TABLE IF NOT EXISTS document_authors (
+				document_id TEXT NOT NULL,
+				position INTEGER NOT NULL,
+				family TEXT NOT NULL,
+				given TEXT NOT NULL,
+				suffix TEXT NOT NULL,
+				family_key TEXT NOT NULL,
+				given_key TEXT NOT NULL,
+				raw TEXT NOT NULL,
+				PRIMARY KEY (document_id, position),
+				FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_document_authors_family_key ON document_authors(family_key);
+		`),
+		backfillDocumentAuthors,
+	)},
+	// Zotero API responses, keyed by library and item; fetched_at is in Unix nanoseconds
+	{17, "add zotero cache", execStatements(`
+		CREATE TABLE IF NOT EXISTS zotero_cache (
+			cache_key TEXT PRIMARY KEY,
+			data BLOB NOT NULL,
+			fetched_at INTEGER NOT NULL
+		);
+	`)},
+	// Annotations are the reader's own notes, so like usage they have no foreign key
+	// and survive re-parsing; DeleteDocument removes them
+	{18, "add annotations", execStatements(`
+		CREATE TABLE IF NOT EXISTS annotations (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			document_id TEXT NOT NULL,
+			page_number TEXT NOT NULL DEFAULT '',
+			quotation_index INTEGER,
+			text TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_annotations_document ON annotations(document_id);
+	`)},
+	{19, "add document languages", addColumns(
+		column{"documents", "language", "TEXT NOT NULL DEFAULT ''"},
+	)},
+	// Field sources and conflicts are JSON, empty for documents stored before them
+	{20, "add metadata provenance", addColumns(
+		column{"documents", "field_sources", "TEXT NOT NULL DEFAULT ''"},
+		column{"documents", "metadata_conflicts", "TEXT NOT NULL DEFAULT ''"},
+	)},
+	// Image bytes are kept apart from the images rows and, like annotations, have no
+	// foreign key so that re-storing a document keeps them; storeImageBlobs prunes
+	// them and DeleteDocument removes them
+	{21, "add image data", steps(
+		addColumns(
+			column{"images", "page_index", "INTEGER NOT NULL DEFAULT 0"},
+			column{"images", "mime_type", "TEXT NOT NULL DEFAULT ''"},
+			column{"images", "width", "INTEGER NOT NULL DEFAULT 0"},
+			column{"images", "height", "INTEGER NOT NULL DEFAULT 0"},
+		),
+		execStatements(`
+			CREATE TABLE IF NOT EXISTS image_blobs (
+				document_id TEXT NOT NULL,
+				image_index INTEGER NOT NULL,
+				mime_type TEXT NOT NULL,
+				data BLOB NOT NULL,
+				PRIMARY KEY (document_id, image_index)
+			);
+		`),
+	)},
+	// Background parsing jobs. Items are claimed in id order; raw_data is cleared
+	// once an item finishes. Times are SQLite DATETIME text.
+	{22, "add jobs", execStatements(`
+		CREATE TABLE IF NOT EXISTS jobs (
+			id TEXT PRIMARY KEY,
+			link_duplicates INTEGER NOT NULL DEFAULT 1,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS job_items (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			job_id TEXT NOT NULL,
+			item_index INTEGER NOT NULL,
+			zotero_id TEXT NOT NULL DEFAULT '',
+			url TEXT NOT NULL DEFAULT '',
+			raw_data BLOB,
+			doc_type TEXT NOT NULL DEFAULT '',
+			library_type TEXT NOT NULL DEFAULT '',
+			library_id TEXT NOT NULL DEFAULT '',
+			status TEXT NOT NULL DEFAULT 'pending',
+			document_id TEXT NOT NULL DEFAULT '',
+			error TEXT NOT NULL DEFAULT '',
+			error_code TEXT NOT NULL DEFAULT '',
+			started_at DATETIME,
+			finished_at DATETIME,
+			UNIQUE (job_id, item_index),
+			FOREIGN KEY (job_id) REFERENCES jobs(id) ON DELETE CASCADE
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_job_items_status ON job_items(status);
+	`)},
+	// Parsing provenance. Documents stored earlier have no updated_at and a
+	// prompt_version of 0, so they count as parsed before any prompt version.
+	{23, "add parse provenance", addColumns(
+		column{"documents", "updated_at", "DATETIME"},
+		column{"documents", "parsed_model", "TEXT NOT NULL DEFAULT ''"},
+		column{"documents", "prompt_version", "INTEGER NOT NULL DEFAULT 0"},
+		column{"documents", "parser_version", "TEXT NOT NULL DEFAULT ''"},
+	)},
+	// Continuous text with page breaks undone, and where each page begins in it.
+	// Documents stored earlier have no full text, which readers build on demand.
+	{24, "add full text", addColumns(
+		column{"documents", "full_text", "TEXT NOT NULL DEFAULT ''"},
+		column{"pages", "full_text_offset", "INTEGER NOT NULL DEFAULT 0"},
+	)},
+	// The model's assessment of each PDF page; pages parsed earlier have no
+	// content confidence
+	{25, "add page assessment", addColumns(
+		column{"pages", "content_confidence", "REAL"},
+		column{"pages", "is_blank", "INTEGER NOT NULL DEFAULT 0"},
+		column{"pages", "is_cover", "INTEGER NOT NULL DEFAULT 0"},
+		column{"pages", "is_references_only", "INTEGER NOT NULL DEFAULT 0"},
+	)},
+	// Hash of the data each document was parsed from, so the same file is
+	// recognized whatever its source. Documents stored earlier have none.
+	{26, "add content hash", steps(
+		addColumns(column{"documents", "content_hash", "TEXT NOT NULL DEFAULT ''"}),
+		execStatements(`CREATE INDEX IF NOT EXISTS idx_documents_content_hash ON documents(content_hash);`),
+	)},
+	// References of one stored document that cite another. Like document_sources
+	// it has no foreign keys and is cleared by DeleteDocument, so re-storing a
+	// cited document keeps the links to it. Existing references are linked here.
+	{27, "add reference links", steps(
+		execStatements(`
+			CREATE TABLE IF NOT EXISTS reference_links (
+				document_id TEXT NOT NULL,
+				ref_index INTEGER NOT NULL,
+				cited_document_id TEXT NOT NULL,
+				match_method TEXT NOT NULL,
+				score REAL NOT NULL,
+				PRIMARY KEY (document_id, ref_index)
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_reference_links_cited ON reference_links(cited_document_id);
+		`),
+		backfillReferenceLinks,
+	)},
+	// Summaries keyed by style, so summarizing a document in one style does not
+	// replace its summary in another. The existing summaries are the standard
+	// style; documents.summary is no longer written.
+	{28, "add summaries", execStatements(`
+		CREATE TABLE IF NOT EXISTS summaries (
+			document_id TEXT NOT NULL,
+			style TEXT NOT NULL,
+			summary TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (document_id, style)
+		);
+
+		INSERT OR IGNORE INTO summaries (document_id, style, summary)
+		SELECT id, 'standard', summary FROM documents WHERE summary IS NOT NULL AND summary != '';
+	`)},
+	// Documents parsed for their metadata only, until a full parse replaces them
+	{29, "add partial documents", addColumns(
+		column{"documents", "partial", "INTEGER NOT NULL DEFAULT 0"},
+	)},
+	// Pages too large for the model, parsed from their extracted text instead
+	{30, "add degraded pages", addColumns(
+		column{"pages", "degraded", "INTEGER NOT NULL DEFAULT 0"},
+	)},
+	// Documents parsed from their extracted text without the model
+	{31, "add basic documents", addColumns(
+		column{"documents", "basic", "INTEGER NOT NULL DEFAULT 0"},
+	)},
+	// Tags of the Zotero items documents came from
+	{32, "add document tags", execStatements(`
+		CREATE TABLE IF NOT EXISTS document_tags (
+			document_id TEXT NOT NULL,
+			tag TEXT NOT NULL,
+			PRIMARY KEY (document_id, tag),
+			FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_document_tags_tag ON document_tags(tag);
+	`)},
+	// Markers of documents being parsed, so processes sharing the database do not
+	// parse the same document twice; expires_at is in Unix nanoseconds. There is
+	// no foreign key, as the document is not stored until the parse finishes.
+	{33, "add parse locks", execStatements(`
+		CREATE TABLE IF NOT EXISTS parse_locks (
+			document_id TEXT PRIMARY KEY,
+			owner TEXT NOT NULL,
+			expires_at INTEGER NOT NULL
+		);
+	`)},
+	// Keys that tell endnotes apart where their numbering restarts by chapter;
+	// endnotes stored before have none until their document is parsed again
+	{34, "add endnote keys", addColumns(
+		column{"endnotes", "note_key", "TEXT NOT NULL DEFAULT ''"},
+	)},
+	// The key derivation settings of a database whose content columns are
+	// encrypted (see encryption.go); plaintext databases have no row
+	{35, "add encryption settings", execStatements(`
+		CREATE TABLE IF NOT EXISTS encryption (
+			id INTEGER PRIMARY KEY CHECK (id = 1),
+			salt BLOB NOT NULL,
+			iterations INTEGER NOT NULL,
+			key_check TEXT NOT NULL
+		);
+	`)},
+	// Publication years as numbers, so documents can be searched by a range of
+	// years; documents without a recognizable year have none
+	{36, "add publication years", steps(
+		addColumns(column{"documents", "publication_year", "INTEGER"}),
+		execStatements(`CREATE INDEX IF NOT EXISTS idx_documents_publication_year ON documents(publication_year);`),
+		backfillPublicationYears,
+	)},
+	// Page images rendered from source PDFs on request, by sequential page and
+	// resolution; they are cleared when their document is stored again
+	{37, "add page images", execStatements(`
+		CREATE TABLE IF NOT EXISTS page_images (
+			document_id TEXT NOT NULL,
+			page_number INTEGER NOT NULL,
+			dpi INTEGER NOT NULL,
+			data BLOB NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (document_id, page_number, dpi),
+			FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE
+		);
+	`)},
+	// A document summarized section by section keeps the summary of each
+	// top-level section and the summary composed from them, per style. Both
+	// are derived from the section index, so they go when it is rebuilt.
+	{38, "add section summaries", execStatements(`
+		CREATE TABLE IF NOT EXISTS section_summaries (
+			document_id TEXT NOT NULL,
+			style TEXT NOT NULL,
+			section_index INTEGER NOT NULL,
+			title TEXT,
+			start_page TEXT,
+			end_page TEXT,
+			summary TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (document_id, style, section_index),
+			FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE
+		);
+		CREATE TABLE IF NOT EXISTS composed_summaries (
+			document_id TEXT NOT NULL,
+			style TEXT NOT NULL,
+			summary TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (document_id, style),
+			FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE
+		);
+	`)},
+	// Where each image can be had from. Image URLs the parser gave that are not
+	// absolute http(s) URLs (file names made up for PDF figures, data URIs) were
+	// never fetchable and are cleared.
+	{39, "add image source kinds", steps(
+		addColumns(column{"images", "source_kind", "TEXT NOT NULL DEFAULT ''"}),
+		execStatements(`
+			UPDATE images SET image_url = ''
+			WHERE image_url IS NOT NULL
+			  AND lower(image_url) NOT LIKE 'http://%' AND lower(image_url) NOT LIKE 'https://%';
+			UPDATE images SET source_kind = CASE
+				WHEN mime_type != '' THEN 'embedded'
+				WHEN COALESCE(image_url, '') != '' THEN 'external_url'
+				ELSE 'unavailable'
+			END
+			WHERE source_kind = '';
+		`),
+	)},
+	// Replacing a document's row cascades to tables with a foreign key, which
+	// dropped section summaries whenever a document was stored again (say,
+	// with its quotations). Like summaries, they now have none; StoreParsedItem
+	// clears them when the section index changes and DeleteDocument removes them.
+	{40, "keep section summaries across re-stores", execStatements(`
+		CREATE TABLE section_summaries_kept (
+			document_id TEXT NOT NULL,
+			style TEXT NOT NULL,
+			section_index INTEGER NOT NULL,
+			title TEXT,
+			start_page TEXT,
+			end_page TEXT,
+			summary TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (document_id, style, section_index)
+		);
+		INSERT INTO section_summaries_kept SELECT * FROM section_summaries;
+		DROP TABLE section_summaries;
+		ALTER TABLE section_summaries_kept RENAME TO section_summaries;
+		CREATE TABLE composed_summaries_kept (
+			document_id TEXT NOT NULL,
+			style TEXT NOT NULL,
+			summary TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (document_id, style)
+		);
+		INSERT INTO composed_summaries_kept SELECT * FROM composed_summaries;
+		DROP TABLE composed_summaries;
+		ALTER TABLE composed_summaries_kept RENAME TO composed_summaries;
+	`)},
+	// How a document's bytes were fetched. The row has no foreign key, so it
+	// survives a document being stored again without a fetch; DeleteDocument
+	// removes it.
+	{41, "add document fetch info", execStatements(`
+		CREATE TABLE document_fetch_info (
+			document_id TEXT PRIMARY KEY,
+			source_url TEXT NOT NULL DEFAULT '',
+			final_url TEXT NOT NULL DEFAULT '',
+			content_type TEXT NOT NULL DEFAULT '',
+			fetched_at TEXT NOT NULL DEFAULT '',
+			sha256 TEXT NOT NULL,
+			size INTEGER NOT NULL
+		);
+	`)},
+	// Sentence offsets of each page, as JSON, and where in its page a verified
+	// quotation was found. Pages stored before have no sentences recorded.
+	{42, "add sentence and quotation spans", addColumns(
+		column{"pages", "sentences", "TEXT"},
+		column{"quotations", "span_start", "INTEGER"},
+		column{"quotations", "span_end", "INTEGER"},
+		column{"quotations", "page_hash", "TEXT NOT NULL DEFAULT ''"},
+	)},
+	// Funding, acknowledgments, and data availability statements extracted
+	// from the document. Documents parsed before have none.
+	{43, "add document statements", addColumns(
+		column{"documents", "funding_statement", "TEXT NOT NULL DEFAULT ''"},
+		column{"documents", "acknowledgments", "TEXT NOT NULL DEFAULT ''"},
+		column{"documents", "data_availability", "TEXT NOT NULL DEFAULT ''"},
+	)},
+	// The library a document belongs to, which its ID is prefixed with outside
+	// the default library. Citekeys are unique within a library rather than
+	// across the store.
+	{44, "add document libraries", steps(
+		addColumns(column{"documents", "library", "TEXT NOT NULL DEFAULT 'default'"}),
+		execStatements(`
+			DROP INDEX IF EXISTS idx_documents_citekey;
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_documents_library_citekey ON documents(library, citekey) WHERE citekey IS NOT NULL;
+			CREATE INDEX IF NOT EXISTS idx_documents_library ON documents(library);
+		`),
+	)},
+	// Keywords the authors list for a document, as a JSON list. Documents parsed
+	// before have none.
+	{45, "add document keywords", addColumns(
+		column{"documents", "keywords", "TEXT NOT NULL DEFAULT '[]'"},
+	)},
+	// Pages of PDF parses in progress, kept as each is parsed so an interrupted
+	// parse resumes where it stopped; page is the parsed page as JSON. There is
+	// no foreign key, as the document is not stored until the parse finishes.
+	{46, "add staged pages", execStatements(`
+		CREATE TABLE IF NOT EXISTS staged_pages (
+			document_id TEXT NOT NULL,
+			page_index INTEGER NOT NULL,
+			content_hash TEXT NOT NULL,
+			page TEXT NOT NULL,
+			degraded BOOLEAN NOT NULL DEFAULT 0,
+			staged_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (document_id, page_index)
+		);
+	`)},
+	// How a PDF's page numbers were assigned, "source" or "sequential".
+	// Documents parsed before, and documents that are not PDFs, have none.
+	{47, "add document page numbering", addColumns(
+		column{"documents", "page_numbering", "TEXT NOT NULL DEFAULT ''"},
+	)},
+	// IDs of images, tables, and footnotes that survive re-parsing (see
+	// AssignStableIDs), and the source page of tables. Existing rows are given
+	// their IDs when the store is opened (backfillStableIDs), as footnote text
+	// may be encrypted; tables stored before have no page.
+	{48, "add stable item IDs", addColumns(
+		column{"images", "stable_id", "TEXT NOT NULL DEFAULT ''"},
+		column{"document_tables", "stable_id", "TEXT NOT NULL DEFAULT ''"},
+		column{"document_tables", "page_number", "TEXT NOT NULL DEFAULT ''"},
+		column{"footnotes", "stable_id", "TEXT NOT NULL DEFAULT ''"},
+	)},
+	// Quotations prioritization leaves out are kept unselected, with each
+	// quotation's place in the prioritization (0 when not prioritized). Only the
+	// selected quotations were stored before.
+	{49, "add quotation selection", addColumns(
+		column{"quotations", "selected", "INTEGER NOT NULL DEFAULT 1"},
+		column{"quotations", "priority_rank", "INTEGER NOT NULL DEFAULT 0"},
+	)},
+	{50, "add corporate authors", addColumns(
+		column{"documents", "corporate_authors", "TEXT NOT NULL DEFAULT '[]'"},
+	)},
+}
+
+// column describes a column added by a migration
+type column struct {
+	table      string
+	name       string
+	definition string
+}
+
+// steps combines migration steps into one, run in order
+func steps(all ...func(tx *sql.Tx) error) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		for _, step := range all {
+			if err := step(tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// execStatements returns a migration step that runs SQL statements, which should
+// use IF NOT EXISTS so they can be re-run safely
+func execStatements(statements string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		_, err := tx.Exec(statements)
+		return err
+	}
+}
+
+// addColumns returns a migration step that adds each column its table lacks
+func addColumns(columns ...column) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		for _, c := range columns {
+			var exists bool
+			err := tx.QueryRow(`SELECT COUNT(*) > 0 FROM pragma_table_info(?) WHERE name = ?`, c.table, c.name).Scan(&exists)
+			if err != nil {
+				return fmt.Errorf("failed to inspect table %s: %w", c.table, err)
+			}
+			if exists {
+				continue
+			}
+			if _, err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", c.table, c.name, c.definition)); err != nil {
+				return fmt.Errorf("failed to add column %s.%s: %w", c.table, c.name, err)
+			}
+		}
+		return nil
+	}
+}
+
+// migrate brings the schema up to the latest version, applying each pending
+// migration in its own transaction
+func (s *SQLiteStore) migrate() error {
+	_, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_version (
+			version INTEGER PRIMARY KEY,
+			description TEXT NOT NULL,
+			applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_version table: %w", err)
+	}
+
+	for _, m := range migrations {
+		applied, err := s.applyMigration(m)
+		if err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", m.version, m.description, err)
+		}
+		if applied {
+			s.logger.Info("Applied schema migration %d: %s", m.version, m.description)
+		}
+	}
+	return nil
+}
+
+// applyMigration applies m unless the database already records it. The check runs
+// inside the migration's transaction so concurrent processes don't both apply it.
+func (s *SQLiteStore) applyMigration(m migration) (bool, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var done bool
+	if err := tx.QueryRow(`SELECT COUNT(*) > 0 FROM schema_version WHERE version = ?`, m.version).Scan(&done); err != nil {
+		return false, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if done {
+		return false, nil
+	}
+
+	if err := m.apply(tx); err != nil {
+		return false, err
+	}
+	if _, err := tx.Exec(`INSERT INTO schema_version (version, description) VALUES (?, ?)`, m.version, m.description); err != nil {
+		return false, fmt.Errorf("failed to record schema version: %w", err)
+	}
+	return true, tx.Commit()
+}
+
+// SchemaVersion returns the highest migration version applied to the database
+func (s *SQLiteStore) SchemaVersion(ctx context.Context) (int, error) {
+	var version int
+	err := s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
@@ -0,0 +1,204 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestMigrations_Ordered(t *testing.T) {
+	for i, m := range migrations {
+		if m.version != i+1 {
+			t.Errorf("Migration at position %d has version %d, want %d", i, m.version, i+1)
+		}
+		if m.description == "" {
+			t.Errorf("Migration %d has no description", m.version)
+		}
+	}
+}
+
+func TestMigrate_FreshDatabase(t *testing.T) {
+	store := newTestStore(t)
+
+	version, err := store.SchemaVersion(context.Background())
+	if err != nil {
+		t.Fatalf("schemaVersion failed: %v", err)
+	}
+	if version != len(migrations) {
+		t.Errorf("Expected schema version %d, got %d", len(migrations), version)
+	}
+
+	ctx := context.Background()
+	item := syntheticItem(2)
+	item.Metadata.Citekey = "smith2020"
+	item.Summary = "A summary"
+	if err := store.StoreParsedItem(ctx, "doc-1", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed on fresh schema: %v", err)
+	}
+}
+
+func TestMigrate_FromOriginalSchema(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "original.db")
+
+	// A database from before extended metadata, citekeys, quotations, schema
+	// versioning, and summaries keyed by style
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	_, err = db.Exec(`
+		CREATE TABLE documents (id TEXT PRIMARY KEY, title TEXT, authors TEXT, publication_date TEXT,
+			publication TEXT, doi TEXT, abstract TEXT, summary TEXT, zotero_id TEXT, url TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP);
+		CREATE TABLE pages (document_id TEXT NOT NULL, page_number INTEGER NOT NULL,
+			source_page_number TEXT NOT NULL, content TEXT, PRIMARY KEY (document_id, page_number));
+		CREATE TABLE document_references (document_id TEXT NOT NULL, ref_index INTEGER NOT NULL,
+			reference_text TEXT, doi TEXT, PRIMARY KEY (document_id, ref_index));
+		CREATE TABLE images (document_id TEXT NOT NULL, image_index INTEGER NOT NULL, image_url TEXT,
+			image_description TEXT, caption TEXT, PRIMARY KEY (document_id, image_index));
+		CREATE TABLE document_tables (document_id TEXT NOT NULL, table_index INTEGER NOT NULL, table_id TEXT,
+			table_title TEXT, table_data TEXT, PRIMARY KEY (document_id, table_index));
+		CREATE TABLE footnotes (document_id TEXT NOT NULL, footnote_index INTEGER NOT NULL, marker TEXT,
+			text TEXT, page_number TEXT, in_text_page TEXT, PRIMARY KEY (document_id, footnote_index));
+		CREATE TABLE endnotes (document_id TEXT NOT NULL, endnote_index INTEGER NOT NULL, marker TEXT,
+			text TEXT, page_number TEXT, PRIMARY KEY (document_id, endnote_index));
+
+		INSERT INTO documents (id, title, authors, publication_date, publication, doi, abstract, summary, zotero_id, url)
+			VALUES ('old-doc', 'Old document', '["Jane Smith"]', '2019', 'Old Journal', '10.1/old', 'Old abstract', 'Old summary', '', 'https://example.com/old.pdf');
+		INSERT INTO pages VALUES ('old-doc', 1, 'iv', 'Old page one'), ('old-doc', 2, 'v', 'Old page two');
+		INSERT INTO document_references VALUES ('old-doc', 0, 'Old reference', '10.1/ref');
+		INSERT INTO images VALUES ('old-doc', 0, 'figure1.png', 'A chart', 'Figure 1'),
+			('old-doc', 1, 'https://example.com/figure2.png', 'A map', 'Figure 2');
+		INSERT INTO documents (id, title) VALUES ('citing-doc', 'Citing document');
+		INSERT INTO document_references VALUES ('citing-doc', 0, 'Smith, J. Old document. 2019.', '10.1/old');
+	`)
+	db.Close()
+	if err != nil {
+		t.Fatalf("Failed to create original schema: %v", err)
+	}
+
+	store, err := NewSQLiteStore(dbPath, logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("NewSQLiteStore failed to migrate original database: %v", err)
+	}
+	defer store.Close()
+
+	version, err := store.SchemaVersion(context.Background())
+	if err != nil {
+		t.Fatalf("schemaVersion failed: %v", err)
+	}
+	if version != len(migrations) {
+		t.Errorf("Expected schema version %d, got %d", len(migrations), version)
+	}
+
+	// Existing data is preserved
+	ctx := context.Background()
+	got, err := store.GetParsedItem(ctx, "old-doc")
+	if err != nil {
+		t.Fatalf("GetParsedItem failed for migrated document: %v", err)
+	}
+	if got.Metadata.Title != "Old document" || got.Metadata.DOI != "10.1/old" || len(got.Metadata.Authors) != 1 {
+		t.Errorf("Migrated metadata not preserved: %+v", got.Metadata)
+	}
+	if len(got.Pages) != 2 || got.Pages[1] != "Old page two" || got.PageNumbers[0] != "iv" {
+		t.Errorf("Migrated pages not preserved: %v %v", got.Pages, got.PageNumbers)
+	}
+	if len(got.References) != 1 || got.References[0].ReferenceText != "Old reference" {
+		t.Errorf("Migrated references not preserved: %+v", got.References)
+	}
+	if got.Provenance == nil || got.Provenance.CreatedAt == "" || got.Provenance.UpdatedAt != "" || got.Provenance.PromptVersion != 0 {
+		t.Errorf("Expected a migrated document to have no parse provenance, got %+v", got.Provenance)
+	}
+
+	// Image URLs that cannot be fetched are cleared, image source kinds set,
+	// and stable IDs assigned when the store is opened
+	wantImages := []models.Image{
+		{ImageDescription: "A chart", Caption: "Figure 1", SourceKind: models.ImageSourceUnavailable, StableID: "img-1-e5750b"},
+		{ImageURL: "https://example.com/figure2.png", ImageDescription: "A map", Caption: "Figure 2", SourceKind: models.ImageSourceExternalURL, StableID: "img-2-fedd61"},
+	}
+	if !reflect.DeepEqual(got.Images, wantImages) {
+		t.Errorf("Migrated images = %+v, want %+v", got.Images, wantImages)
+	}
+
+	// Existing summaries are the standard style
+	if got.Summary != "Old summary" {
+		t.Errorf("Expected the migrated summary, got %q", got.Summary)
+	}
+	if summaries, err := store.GetSummaries(ctx, "citing-doc"); err != nil || len(summaries) != 0 {
+		t.Errorf("GetSummaries for a document without a summary = %v, %v", summaries, err)
+	}
+
+	// Authors of existing documents are parsed
+	if authors, err := store.GetAuthors(ctx, ""); err != nil || len(authors) != 1 || authors[0].Name != "Smith, Jane" {
+		t.Errorf("GetAuthors after migration = %+v, %v", authors, err)
+	}
+
+	// References of existing documents are linked
+	want := []models.ReferenceLink{{DocumentID: "citing-doc", CitedDocumentID: "old-doc", MatchMethod: "title", Score: 1}}
+	if links, err := store.GetReferenceLinks(ctx); err != nil || !reflect.DeepEqual(links, want) {
+		t.Errorf("GetReferenceLinks after migration = %+v, %v", links, err)
+	}
+
+	// Publication years of existing documents are set
+	if results, total, err := store.SearchDocuments(ctx, models.LibrarySearch{YearFrom: 2019, YearTo: 2019}); err != nil || total != 1 || results[0].DocumentID != "old-doc" {
+		t.Errorf("SearchDocuments by year after migration = %+v, %d, %v", results, total, err)
+	}
+
+	// Columns and tables added by migrations are usable
+	got.Summary = "New summary"
+	got.Quotations = []models.Quotation{{QuotationText: "Quoted", PageNumber: "iv"}}
+	if err := store.StoreParsedItem(ctx, "old-doc", got, &models.SourceInfo{URL: "https://example.com/old.pdf"}); err != nil {
+		t.Fatalf("Re-storing migrated document failed: %v", err)
+	}
+	if summary, err := store.GetSummary(ctx, "old-doc"); err != nil || summary != "New summary" {
+		t.Errorf("GetSummary after migration = %q, %v", summary, err)
+	}
+	if quotations, err := store.GetQuotations(ctx, "old-doc"); err != nil || len(quotations) != 1 {
+		t.Errorf("GetQuotations after migration = %+v, %v", quotations, err)
+	}
+	item := syntheticItem(1)
+	item.Metadata.Citekey = "smith2019"
+	item.Metadata.Volume = "3"
+	if err := store.StoreParsedItem(ctx, "new-doc", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed after migration: %v", err)
+	}
+	if docID, err := store.GetDocumentByCitekey(ctx, "", "smith2019"); err != nil || docID != "new-doc" {
+		t.Errorf("Citekey lookup after migration = %q, %v", docID, err)
+	}
+}
+
+func TestMigrate_Reopen(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	log := logger.NewNoOpLogger()
+
+	store, err := NewSQLiteStore(dbPath, log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	if err := store.StoreParsedItem(context.Background(), "doc-1", syntheticItem(2), &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	store.Close()
+
+	store, err = NewSQLiteStore(dbPath, log)
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	defer store.Close()
+
+	var rows int
+	if err := store.db.QueryRow(`SELECT COUNT(*) FROM schema_version`).Scan(&rows); err != nil {
+		t.Fatalf("Failed to count schema versions: %v", err)
+	}
+	if rows != len(migrations) {
+		t.Errorf("Expected each migration recorded once, got %d rows for %d migrations", rows, len(migrations))
+	}
+	if _, err := store.GetParsedItem(context.Background(), "doc-1"); err != nil {
+		t.Errorf("GetParsedItem failed after reopening: %v", err)
+	}
+}
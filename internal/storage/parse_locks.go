@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// AcquireParseLock marks a document as being parsed by owner until ttl from
+// now. It reports false, changing nothing, if another owner's marker has not
+// expired; an expired marker, left by a parse that crashed, is taken over.
+func (s *SQLiteStore) AcquireParseLock(ctx context.Context, docID, owner string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	result, err := s.db.ExecContext(ctx, `
+		INSERT INTO parse_locks (document_id, owner, expires_at) VALUES (?, ?, ?)
+		ON CONFLICT (document_id) DO UPDATE SET owner = excluded.owner, expires_at = excluded.expires_at
+		WHERE parse_locks.owner = excluded.owner OR parse_locks.expires_at <= ?
+	`, docID, owner, now.Add(ttl).UnixNano(), now.UnixNano())
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire parse lock: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire parse lock: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// ReleaseParseLock removes owner's marker for a document. A marker that has
+// since been taken over by another owner is left alone.
+func (s *SQLiteStore) ReleaseParseLock(ctx context.Context, docID, owner string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM parse_locks WHERE document_id = ? AND owner = ?`, docID, owner)
+	if err != nil {
+		return fmt.Errorf("failed to release parse lock: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParseLocks(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	acquire := func(owner string, ttl time.Duration) bool {
+		t.Helper()
+		ok, err := store.AcquireParseLock(ctx, "url_1", owner, ttl)
+		if err != nil {
+			t.Fatalf("AcquireParseLock failed: %v", err)
+		}
+		return ok
+	}
+
+	if !acquire("first", time.Minute) {
+		t.Fatal("Expected the first owner to acquire the lock")
+	}
+	if acquire("second", time.Minute) {
+		t.Error("Expected the lock held by another owner to be refused")
+	}
+	if !acquire("first", time.Minute) {
+		t.Error("Expected the owner to extend its own lock")
+	}
+
+	// Another owner's release leaves the lock in place
+	if err := store.ReleaseParseLock(ctx, "url_1", "second"); err != nil {
+		t.Fatalf("ReleaseParseLock failed: %v", err)
+	}
+	if acquire("second", time.Minute) {
+		t.Error("Expected the lock to survive another owner's release")
+	}
+	if err := store.ReleaseParseLock(ctx, "url_1", "first"); err != nil {
+		t.Fatalf("ReleaseParseLock failed: %v", err)
+	}
+	if !acquire("second", time.Minute) {
+		t.Error("Expected the released lock to be acquired")
+	}
+
+	// A lock left by a crashed parse expires
+	if err := store.ReleaseParseLock(ctx, "url_1", "second"); err != nil {
+		t.Fatalf("ReleaseParseLock failed: %v", err)
+	}
+	if !acquire("crashed", -time.Second) {
+		t.Fatal("Expected the released lock to be acquired")
+	}
+	if !acquire("third", time.Minute) {
+		t.Error("Expected an expired lock to be taken over")
+	}
+}
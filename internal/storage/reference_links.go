@@ -0,0 +1,193 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/citations"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// storedReference is a reference of a stored document, for matching against the
+// library
+type storedReference struct {
+	docID    string
+	refIndex int
+	text     string
+	doi      string
+}
+
+// linkReferences records the links between a stored document and the rest of
+// the library: its own references are matched against every other document, and
+// the unlinked references of other documents against it. Links from and to the
+// document are found again, so a re-stored document with new references or
+// metadata is not left with stale links. Called within the transaction that
+// stores or updates the document.
+func linkReferences(ctx context.Context, tx *sql.Tx, docID string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM reference_links WHERE document_id = ? OR cited_document_id = ?`, docID, docID); err != nil {
+		return fmt.Errorf("failed to clear reference links: %w", err)
+	}
+
+	works, err := loadWorks(ctx, tx, "")
+	if err != nil {
+		return err
+	}
+	own, err := loadReferences(ctx, tx, `WHERE r.document_id = ?`, docID)
+	if err != nil {
+		return err
+	}
+	if err := matchReferences(ctx, tx, own, works); err != nil {
+		return err
+	}
+
+	self, err := loadWorks(ctx, tx, docID)
+	if err != nil {
+		return err
+	}
+	others, err := loadReferences(ctx, tx, `
+		LEFT JOIN reference_links l ON l.document_id = r.document_id AND l.ref_index = r.ref_index
+		WHERE r.document_id != ? AND l.document_id IS NULL
+	`, docID)
+	if err != nil {
+		return err
+	}
+	return matchReferences(ctx, tx, others, self)
+}
+
+// backfillReferenceLinks links the references of documents stored before the
+// reference_links table existed
+func backfillReferenceLinks(tx *sql.Tx) error {
+	ctx := context.Background()
+	works, err := loadWorks(ctx, tx, "")
+	if err != nil {
+		return err
+	}
+	refs, err := loadReferences(ctx, tx, "")
+	if err != nil {
+		return err
+	}
+	return matchReferences(ctx, tx, refs, works)
+}
+
+// loadWorks returns the stored documents as works a reference may cite: all of
+// them, or only docID if it is set
+func loadWorks(ctx context.Context, tx *sql.Tx, docID string) ([]citations.Work, error) {
+	query := `SELECT id, COALESCE(title, ''), COALESCE(doi, ''), COALESCE(publication_date, ''), COALESCE(authors, '') FROM documents`
+	var args []any
+	if docID != "" {
+		query += ` WHERE id = ?`
+		args = append(args, docID)
+	}
+	rows, err := tx.QueryContext(ctx, query+` ORDER BY created_at, id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query documents: %w", err)
+	}
+	defer rows.Close()
+
+	var works []citations.Work
+	for rows.Next() {
+		var work citations.Work
+		var pubDate, authorsJSON string
+		if err := rows.Scan(&work.DocumentID, &work.Title, &work.DOI, &pubDate, &authorsJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		work.Year = citations.ExtractYear(pubDate)
+		var authors []string
+		if json.Unmarshal([]byte(authorsJSON), &authors) == nil && len(authors) > 0 {
+			work.FirstAuthor = authors[0]
+		}
+		works = append(works, work)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating documents: %w", err)
+	}
+	return works, nil
+}
+
+// loadReferences returns the stored references selected by a clause on
+// document_references r
+func loadReferences(ctx context.Context, tx *sql.Tx, clause string, args ...any) ([]storedReference, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT r.document_id, r.ref_index, r.reference_text, r.doi FROM document_references r
+		`+clause+`
+		ORDER BY r.document_id, r.ref_index
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query references: %w", err)
+	}
+	defer rows.Close()
+
+	var refs []storedReference
+	for rows.Next() {
+		var ref storedReference
+		if err := rows.Scan(&ref.docID, &ref.refIndex, &ref.text, &ref.doi); err != nil {
+			return nil, fmt.Errorf("failed to scan reference: %w", err)
+		}
+		refs = append(refs, ref)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating references: %w", err)
+	}
+	return refs, nil
+}
+
+// matchReferences records a link for each reference that cites one of works,
+// other than the document the reference belongs to
+func matchReferences(ctx context.Context, tx *sql.Tx, refs []storedReference, works []citations.Work) error {
+	var links []models.ReferenceLink
+	for _, ref := range refs {
+		candidates := make([]citations.Work, 0, len(works))
+		for _, work := range works {
+			if work.DocumentID != ref.docID {
+				candidates = append(candidates, work)
+			}
+		}
+		match, ok := citations.MatchReference(ref.text, ref.doi, candidates)
+		if !ok {
+			continue
+		}
+		links = append(links, models.ReferenceLink{
+			DocumentID:      ref.docID,
+			RefIndex:        ref.refIndex,
+			CitedDocumentID: match.DocumentID,
+			MatchMethod:     match.Method,
+			Score:           match.Score,
+		})
+	}
+
+	return insertRows(ctx, tx, "reference link", `
+		INSERT OR REPLACE INTO reference_links (document_id, ref_index, cited_document_id, match_method, score)
+		VALUES (?, ?, ?, ?, ?)
+	`, len(links), func(i int) []any {
+		link := links[i]
+		return []any{link.DocumentID, link.RefIndex, link.CitedDocumentID, link.MatchMethod, link.Score}
+	})
+}
+
+// GetReferenceLinks returns every link between stored documents, ordered by
+// citing document and reference
+func (s *SQLiteStore) GetReferenceLinks(ctx context.Context) ([]models.ReferenceLink, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT document_id, ref_index, cited_document_id, match_method, score FROM reference_links
+		ORDER BY document_id, ref_index
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reference links: %w", err)
+	}
+	defer rows.Close()
+
+	var links []models.ReferenceLink
+	for rows.Next() {
+		var link models.ReferenceLink
+		if err := rows.Scan(&link.DocumentID, &link.RefIndex, &link.CitedDocumentID, &link.MatchMethod, &link.Score); err != nil {
+			return nil, fmt.Errorf("failed to scan reference link: %w", err)
+		}
+		links = append(links, link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reference links: %w", err)
+	}
+	return links, nil
+}
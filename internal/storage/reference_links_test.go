@@ -0,0 +1,182 @@
+package storage
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// citedItem returns a document that the references of citingItem cite
+func citedItem(title string, doi string, citekey string) *models.ParsedItem {
+	return &models.ParsedItem{
+		Metadata: models.ItemMetadata{
+			Title:           title,
+			Authors:         []string{"Smith, Jane"},
+			PublicationDate: "2020",
+			DOI:             doi,
+			Citekey:         citekey,
+		},
+		Pages: []string{"Page one"},
+	}
+}
+
+// citingItem returns a document whose references cite "Memory and the Archive"
+// by title and 10.1000/cited by DOI
+func citingItem() *models.ParsedItem {
+	return &models.ParsedItem{
+		Metadata: models.ItemMetadata{Title: "A Later Study", Authors: []string{"Jones, Kim"}, PublicationDate: "2023"},
+		Pages:    []string{"Page one"},
+		References: []models.Reference{
+			{ReferenceText: "Smith, J. (2020). Memory and the Archive. Journal of History, 12."},
+			{ReferenceText: "Brown, A. (2019). Something else entirely. Press."},
+			{ReferenceText: "Smith, J. (2020). Unrelated wording.", DOI: "10.1000/cited"},
+		},
+	}
+}
+
+func TestReferenceLinks(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		citedFirst bool
+	}{
+		{name: "cited document stored first", citedFirst: true},
+		{name: "citing document stored first"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestStore(t)
+			store1 := func(docID string, item *models.ParsedItem) {
+				t.Helper()
+				if err := store.StoreParsedItem(ctx, docID, item, &models.SourceInfo{}); err != nil {
+					t.Fatalf("StoreParsedItem failed: %v", err)
+				}
+			}
+			if tt.citedFirst {
+				store1("cited-title", citedItem("Memory and the Archive", "", "smith2020"))
+				store1("cited-doi", citedItem("A Different Title", "10.1000/CITED", "smith2020a"))
+				store1("citing", citingItem())
+			} else {
+				store1("citing", citingItem())
+				store1("cited-title", citedItem("Memory and the Archive", "", "smith2020"))
+				store1("cited-doi", citedItem("A Different Title", "10.1000/CITED", "smith2020a"))
+			}
+
+			links, err := store.GetReferenceLinks(ctx)
+			if err != nil {
+				t.Fatalf("GetReferenceLinks failed: %v", err)
+			}
+			expected := []models.ReferenceLink{
+				{DocumentID: "citing", RefIndex: 0, CitedDocumentID: "cited-title", MatchMethod: "title", Score: 1},
+				{DocumentID: "citing", RefIndex: 2, CitedDocumentID: "cited-doi", MatchMethod: "doi", Score: 1},
+			}
+			if !reflect.DeepEqual(links, expected) {
+				t.Errorf("Expected links %+v, got %+v", expected, links)
+			}
+		})
+	}
+}
+
+func TestReferenceLinks_Enrichment(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	if err := store.StoreParsedItem(ctx, "cited", citedItem("Memory and the Archive", "", "smith2020"), &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	if err := store.StoreParsedItem(ctx, "citing", citingItem(), &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+
+	refs, err := store.GetReferences(ctx, "citing")
+	if err != nil {
+		t.Fatalf("GetReferences failed: %v", err)
+	}
+	if refs[0].CitedDocumentID != "cited" || refs[0].Citekey != "smith2020" {
+		t.Errorf("Expected reference 0 linked to cited (smith2020), got %+v", refs[0])
+	}
+	if refs[1].CitedDocumentID != "" || refs[1].Citekey != "" {
+		t.Errorf("Expected reference 1 unlinked, got %+v", refs[1])
+	}
+
+	ref, err := store.GetReference(ctx, "citing", 0)
+	if err != nil {
+		t.Fatalf("GetReference failed: %v", err)
+	}
+	if ref.CitedDocumentID != "cited" || ref.Citekey != "smith2020" {
+		t.Errorf("Expected reference linked to cited (smith2020), got %+v", ref)
+	}
+}
+
+func TestReferenceLinks_Updates(t *testing.T) {
+	ctx := context.Background()
+	countLinks := func(t *testing.T, store *SQLiteStore) int {
+		t.Helper()
+		links, err := store.GetReferenceLinks(ctx)
+		if err != nil {
+			t.Fatalf("GetReferenceLinks failed: %v", err)
+		}
+		return len(links)
+	}
+	setup := func(t *testing.T) *SQLiteStore {
+		t.Helper()
+		store := newTestStore(t)
+		if err := store.StoreParsedItem(ctx, "cited", citedItem("Memory and the Archive", "", "smith2020"), &models.SourceInfo{}); err != nil {
+			t.Fatalf("StoreParsedItem failed: %v", err)
+		}
+		if err := store.StoreParsedItem(ctx, "citing", citingItem(), &models.SourceInfo{}); err != nil {
+			t.Fatalf("StoreParsedItem failed: %v", err)
+		}
+		if n := countLinks(t, store); n != 1 {
+			t.Fatalf("Expected 1 link, got %d", n)
+		}
+		return store
+	}
+
+	t.Run("re-storing the cited document keeps the link", func(t *testing.T) {
+		store := setup(t)
+		if err := store.StoreParsedItem(ctx, "cited", citedItem("Memory and the Archive", "", "smith2020"), &models.SourceInfo{}); err != nil {
+			t.Fatalf("StoreParsedItem failed: %v", err)
+		}
+		if n := countLinks(t, store); n != 1 {
+			t.Errorf("Expected 1 link, got %d", n)
+		}
+	})
+
+	t.Run("re-storing the citing document without the reference drops the link", func(t *testing.T) {
+		store := setup(t)
+		item := citingItem()
+		item.References = item.References[1:]
+		if err := store.StoreParsedItem(ctx, "citing", item, &models.SourceInfo{}); err != nil {
+			t.Fatalf("StoreParsedItem failed: %v", err)
+		}
+		if n := countLinks(t, store); n != 0 {
+			t.Errorf("Expected no links, got %d", n)
+		}
+	})
+
+	t.Run("correcting the cited title drops the link", func(t *testing.T) {
+		store := setup(t)
+		metadata := citedItem("Memory and the Museum", "", "smith2020").Metadata
+		if err := store.UpdateMetadata(ctx, "cited", &metadata); err != nil {
+			t.Fatalf("UpdateMetadata failed: %v", err)
+		}
+		if n := countLinks(t, store); n != 0 {
+			t.Errorf("Expected no links, got %d", n)
+		}
+	})
+
+	t.Run("deleting the cited document drops the link", func(t *testing.T) {
+		store := setup(t)
+		if err := store.DeleteDocument(ctx, "cited"); err != nil {
+			t.Fatalf("DeleteDocument failed: %v", err)
+		}
+		if n := countLinks(t, store); n != 0 {
+			t.Errorf("Expected no links, got %d", n)
+		}
+	})
+}
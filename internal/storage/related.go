@@ -0,0 +1,324 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/citations"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// Weights of the signals in a related document's score: each reference DOI or
+// author two documents share counts as much as their titles and abstracts
+// being half alike, and a shared venue as much as a quarter
+const (
+	relatedReferenceWeight = 1.0
+	relatedAuthorWeight    = 1.0
+	relatedVenueWeight     = 0.5
+	relatedTermWeight      = 2.0
+)
+
+// maxSharedTerms is the number of shared terms listed for a related document
+const maxSharedTerms = 5
+
+// relatedStopWords are common English words left out of the term index
+var relatedStopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "from": true, "into": true, "that": true,
+	"this": true, "these": true, "those": true, "are": true, "was": true, "were": true, "been": true,
+	"being": true, "have": true, "has": true, "had": true, "its": true, "their": true, "our": true,
+	"not": true, "but": true, "can": true, "may": true, "which": true, "who": true, "how": true,
+	"what": true, "when": true, "where": true, "why": true, "than": true, "then": true, "there": true,
+	"also": true, "such": true, "both": true, "between": true, "among": true, "over": true,
+	"under": true, "about": true, "after": true, "before": true, "more": true, "most": true,
+	"other": true, "some": true, "all": true, "any": true, "each": true, "use": true, "using": true,
+	"used": true, "via": true, "study": true, "paper": true, "article": true, "we": true, "they": true,
+}
+
+// relatedProfile is what a stored document is compared on
+type relatedProfile struct {
+	docID      string
+	library    string
+	title      string
+	citekey    string
+	venue      string             // Publication reduced with citations.NameKey
+	authors    map[string]string  // Raw author names by family and given name keys
+	references map[string]bool    // Normalized DOIs of the document's references
+	terms      map[string]float64 // TF-IDF weights of title, abstract, and keyword terms, with unit length
+}
+
+// relatedIndex holds the profiles of every stored document
+type relatedIndex struct {
+	profiles map[string]*relatedProfile
+}
+
+// relatedCache keeps the related index between changes to documents. It is
+// built on first use and dropped whenever a document is stored, has its
+// metadata updated, or is deleted.
+type relatedCache struct {
+	mu         sync.Mutex
+	index      *relatedIndex
+	generation uint64 // Incremented on each invalidation, so an index built across a change is not kept
+}
+
+// invalidate drops the cached index
+func (c *relatedCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.index = nil
+	c.generation++
+}
+
+// relatedIndex returns the cached related index, building it if documents
+// changed since it was built
+func (s *SQLiteStore) relatedIndex(ctx context.Context) (*relatedIndex, error) {
+	s.related.mu.Lock()
+	index, generation := s.related.index, s.related.generation
+	s.related.mu.Unlock()
+	if index != nil {
+		return index, nil
+	}
+
+	index, err := s.buildRelatedIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.related.mu.Lock()
+	if s.related.generation == generation {
+		s.related.index = index
+	}
+	s.related.mu.Unlock()
+	return index, nil
+}
+
+// buildRelatedIndex loads the profile of every stored document
+func (s *SQLiteStore) buildRelatedIndex(ctx context.Context) (*relatedIndex, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, library, COALESCE(title, ''), COALESCE(abstract, ''), COALESCE(publication, ''),
+			COALESCE(keywords, ''), COALESCE(citekey, '')
+		FROM documents
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query documents: %w", err)
+	}
+	profiles := make(map[string]*relatedProfile)
+	counts := make(map[string]map[string]int)
+	for rows.Next() {
+		var p relatedProfile
+		var abstract, publication, keywordsJSON string
+		if err := rows.Scan(&p.docID, &p.library, &p.title, &abstract, &publication, &keywordsJSON, &p.citekey); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		var keywords []string
+		if keywordsJSON != "" {
+			json.Unmarshal([]byte(keywordsJSON), &keywords)
+		}
+		p.venue = citations.NameKey(publication)
+		p.authors = make(map[string]string)
+		p.references = make(map[string]bool)
+		profiles[p.docID] = &p
+		counts[p.docID] = termCounts(p.title + "\n" + abstract + "\n" + strings.Join(keywords, "\n"))
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating documents: %w", err)
+	}
+
+	rows, err = s.db.QueryContext(ctx, `SELECT document_id, family_key, given_key, raw FROM document_authors ORDER BY document_id, position`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query authors: %w", err)
+	}
+	for rows.Next() {
+		var docID, familyKey, givenKey, raw string
+		if err := rows.Scan(&docID, &familyKey, &givenKey, &raw); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan author: %w", err)
+		}
+		if p, ok := profiles[docID]; ok && familyKey != "" {
+			key := familyKey + "|" + givenKey
+			if _, seen := p.authors[key]; !seen {
+				p.authors[key] = raw
+			}
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating authors: %w", err)
+	}
+
+	rows, err = s.db.QueryContext(ctx, `SELECT document_id, doi FROM document_references WHERE COALESCE(doi, '') != ''`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query references: %w", err)
+	}
+	for rows.Next() {
+		var docID, doi string
+		if err := rows.Scan(&docID, &doi); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan reference: %w", err)
+		}
+		if p, ok := profiles[docID]; ok {
+			if normalized := normalizeDOI(doi); normalized != "" {
+				p.references[normalized] = true
+			}
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating references: %w", err)
+	}
+
+	weighTerms(profiles, counts)
+	return &relatedIndex{profiles: profiles}, nil
+}
+
+// termCounts counts the terms of text: lowercase runs of letters and digits of
+// at least three characters that are not stop words or numbers
+func termCounts(text string) map[string]int {
+	counts := make(map[string]int)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len([]rune(word)) < 3 || relatedStopWords[word] || strings.IndexFunc(word, unicode.IsLetter) < 0 {
+			continue
+		}
+		counts[word]++
+	}
+	return counts
+}
+
+// weighTerms sets each profile's terms to the TF-IDF weights of its term
+// counts, using the smoothed inverse document frequency ln((1+n)/(1+df))+1 so
+// terms every document has still count, scaled to unit length
+func weighTerms(profiles map[string]*relatedProfile, counts map[string]map[string]int) {
+	df := make(map[string]int)
+	for _, terms := range counts {
+		for term := range terms {
+			df[term]++
+		}
+	}
+	n := float64(len(counts))
+	for docID, terms := range counts {
+		weights := make(map[string]float64, len(terms))
+		var norm float64
+		for term, count := range terms {
+			w := float64(count) * (math.Log((1+n)/(1+float64(df[term]))) + 1)
+			weights[term] = w
+			norm += w * w
+		}
+		norm = math.Sqrt(norm)
+		for term := range weights {
+			weights[term] /= norm
+		}
+		profiles[docID].terms = weights
+	}
+}
+
+// FindRelatedDocuments returns up to limit documents of the library of docID
+// that are most like it, scored on the reference DOIs and authors they share
+// with it, whether they appeared in the same venue, and the similarity of their
+// titles, abstracts, and keywords. Documents sharing nothing are left out.
+func (s *SQLiteStore) FindRelatedDocuments(ctx context.Context, docID string, limit int) ([]models.RelatedDocument, error) {
+	index, err := s.relatedIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	target, ok := index.profiles[docID]
+	if !ok {
+		return nil, fmt.Errorf("document %w: %s", ErrNotFound, docID)
+	}
+	return rankRelated(target, index, limit), nil
+}
+
+// rankRelated scores the other documents of target's library, best first
+// (ties by document ID), keeping the first limit with a score
+func rankRelated(target *relatedProfile, index *relatedIndex, limit int) []models.RelatedDocument {
+	related := []models.RelatedDocument{}
+	for _, candidate := range index.profiles {
+		if candidate.docID == target.docID || candidate.library != target.library {
+			continue
+		}
+		if doc := scoreRelated(target, candidate); doc.Score > 0 {
+			related = append(related, doc)
+		}
+	}
+	sort.Slice(related, func(i, j int) bool {
+		if related[i].Score != related[j].Score {
+			return related[i].Score > related[j].Score
+		}
+		return related[i].DocumentID < related[j].DocumentID
+	})
+	if limit > 0 && len(related) > limit {
+		related = related[:limit]
+	}
+	return related
+}
+
+// scoreRelated compares a candidate document with the target
+func scoreRelated(target, candidate *relatedProfile) models.RelatedDocument {
+	doc := models.RelatedDocument{
+		DocumentID: candidate.docID,
+		Title:      candidate.title,
+		Citekey:    candidate.citekey,
+	}
+
+	for doi := range target.references {
+		if candidate.references[doi] {
+			doc.SharedReferences = append(doc.SharedReferences, doi)
+		}
+	}
+	slices.Sort(doc.SharedReferences)
+
+	for key, raw := range target.authors {
+		if _, ok := candidate.authors[key]; ok {
+			doc.SharedAuthors = append(doc.SharedAuthors, raw)
+		}
+	}
+	slices.Sort(doc.SharedAuthors)
+
+	doc.SameVenue = target.venue != "" && target.venue == candidate.venue
+
+	type sharedTerm struct {
+		term   string
+		weight float64
+	}
+	var shared []sharedTerm
+	for term, w := range target.terms {
+		if cw, ok := candidate.terms[term]; ok {
+			doc.TermSimilarity += w * cw
+			shared = append(shared, sharedTerm{term, w * cw})
+		}
+	}
+	sort.Slice(shared, func(i, j int) bool {
+		if shared[i].weight != shared[j].weight {
+			return shared[i].weight > shared[j].weight
+		}
+		return shared[i].term < shared[j].term
+	})
+	for _, t := range shared[:min(len(shared), maxSharedTerms)] {
+		doc.SharedTerms = append(doc.SharedTerms, t.term)
+	}
+	doc.TermSimilarity = roundScore(doc.TermSimilarity)
+
+	doc.Scores = models.RelatedScores{
+		References: roundScore(relatedReferenceWeight * float64(len(doc.SharedReferences))),
+		Authors:    roundScore(relatedAuthorWeight * float64(len(doc.SharedAuthors))),
+		Terms:      roundScore(relatedTermWeight * doc.TermSimilarity),
+	}
+	if doc.SameVenue {
+		doc.Scores.Venue = relatedVenueWeight
+	}
+	doc.Score = roundScore(doc.Scores.References + doc.Scores.Authors + doc.Scores.Venue + doc.Scores.Terms)
+	return doc
+}
+
+// roundScore rounds a score to three decimal places
+func roundScore(score float64) float64 {
+	return math.Round(score*1000) / 1000
+}
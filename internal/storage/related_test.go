@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// storeRelatedLibrary stores a document on soil carbon and others that share
+// more or less with it
+func storeRelatedLibrary(t *testing.T, store *SQLiteStore) {
+	t.Helper()
+	refs := func(dois ...string) []models.Reference {
+		var references []models.Reference
+		for _, doi := range dois {
+			references = append(references, models.Reference{ReferenceText: "Reference " + doi, DOI: doi})
+		}
+		return references
+	}
+	items := map[string]*models.ParsedItem{
+		"target": {
+			Metadata: models.ItemMetadata{
+				Title:       "Soil carbon dynamics in boreal forests",
+				Abstract:    "We measure soil carbon flux across boreal forest stands.",
+				Authors:     []string{"Chen, Wei", "Lee, Ann"},
+				Publication: "Global Change Biology",
+				Keywords:    []string{"soil carbon", "boreal forest"},
+			},
+			References: refs("10.1000/r1", "https://doi.org/10.1000/R2", "10.1000/r3"),
+		},
+		// Shares two references, an author, the venue, and the topic
+		"strong": {
+			Metadata: models.ItemMetadata{
+				Title:       "Boreal forest soil carbon storage",
+				Abstract:    "Carbon storage in boreal soils under warming.",
+				Authors:     []string{"Wei Chen", "Park, Min"},
+				Publication: "Global change biology",
+			},
+			References: refs("10.1000/r1", "10.1000/r2", "10.1000/r9"),
+		},
+		// Shares every reference but nothing else
+		"references": {
+			Metadata:   models.ItemMetadata{Title: "Urban heat islands", Authors: []string{"Diaz, Rosa"}},
+			References: refs("10.1000/r1", "10.1000/r2", "10.1000/r3"),
+		},
+		// Shares only the topic
+		"topic": {
+			Metadata: models.ItemMetadata{
+				Title:    "Carbon flux of boreal soils",
+				Abstract: "Soil carbon in the boreal zone.",
+				Authors:  []string{"Okafor, Ngozi"},
+			},
+		},
+		// Shares only the venue
+		"venue": {
+			Metadata: models.ItemMetadata{Title: "Coral reef bleaching", Publication: "Global Change Biology"},
+		},
+		"unrelated":          {Metadata: models.ItemMetadata{Title: "Medieval manuscripts", Authors: []string{"Brown, Tom"}}},
+		"thesis:strong-copy": {Metadata: models.ItemMetadata{Title: "Boreal forest soil carbon storage", Authors: []string{"Chen, Wei"}}},
+	}
+	for docID, item := range items {
+		if err := store.StoreParsedItem(context.Background(), docID, item, &models.SourceInfo{}); err != nil {
+			t.Fatalf("Failed to store %s: %v", docID, err)
+		}
+	}
+}
+
+func TestFindRelatedDocuments(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	storeRelatedLibrary(t, store)
+
+	related, err := store.FindRelatedDocuments(ctx, "target", 0)
+	if err != nil {
+		t.Fatalf("FindRelatedDocuments failed: %v", err)
+	}
+	var ranking []string
+	for _, doc := range related {
+		ranking = append(ranking, doc.DocumentID)
+	}
+	// Documents of other libraries and those sharing nothing are left out
+	if want := []string{"strong", "references", "topic", "venue"}; !reflect.DeepEqual(ranking, want) {
+		t.Fatalf("Expected ranking %v, got %v (%+v)", want, ranking, related)
+	}
+
+	strong := related[0]
+	if !reflect.DeepEqual(strong.SharedReferences, []string{"10.1000/r1", "10.1000/r2"}) {
+		t.Errorf("Expected the normalized shared DOIs, got %v", strong.SharedReferences)
+	}
+	if !reflect.DeepEqual(strong.SharedAuthors, []string{"Chen, Wei"}) {
+		t.Errorf("Expected the shared author as written for the target, got %v", strong.SharedAuthors)
+	}
+	if !strong.SameVenue || strong.Scores.References != 2 || strong.Scores.Authors != 1 || strong.Scores.Venue != 0.5 {
+		t.Errorf("Expected reference, author, and venue scores of 2, 1, and 0.5, got %+v", strong)
+	}
+	if strong.TermSimilarity <= 0 || strong.Scores.Terms != roundScore(2*strong.TermSimilarity) || len(strong.SharedTerms) == 0 {
+		t.Errorf("Expected a term score from shared terms, got %+v", strong)
+	}
+	if sum := roundScore(strong.Scores.References + strong.Scores.Authors + strong.Scores.Venue + strong.Scores.Terms); strong.Score != sum {
+		t.Errorf("Expected the score %v to be the sum of the signal scores %v", strong.Score, sum)
+	}
+
+	if refs := related[1]; refs.Scores.References != 3 || refs.Scores.Authors != 0 || refs.Scores.Venue != 0 || refs.TermSimilarity != 0 {
+		t.Errorf("Expected only shared references, got %+v", refs)
+	}
+	if topic := related[2]; topic.Scores.Terms == 0 || topic.Scores.References != 0 || topic.Scores.Authors != 0 || topic.SameVenue {
+		t.Errorf("Expected only shared terms, got %+v", topic)
+	}
+
+	limited, err := store.FindRelatedDocuments(ctx, "target", 2)
+	if err != nil || len(limited) != 2 || limited[1].DocumentID != "references" {
+		t.Errorf("Expected the best two documents, got %+v, %v", limited, err)
+	}
+
+	if _, err := store.FindRelatedDocuments(ctx, "missing", 5); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a document that is not stored, got %v", err)
+	}
+}
+
+func TestFindRelatedDocuments_Invalidation(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	storeRelatedLibrary(t, store)
+
+	if _, err := store.FindRelatedDocuments(ctx, "target", 0); err != nil {
+		t.Fatalf("FindRelatedDocuments failed: %v", err)
+	}
+	if store.related.index == nil {
+		t.Fatal("Expected the index to be cached")
+	}
+	cached := store.related.index
+	if _, err := store.FindRelatedDocuments(ctx, "strong", 0); err != nil {
+		t.Fatalf("FindRelatedDocuments failed: %v", err)
+	}
+	if store.related.index != cached {
+		t.Error("Expected the cached index to be reused")
+	}
+
+	// Giving the unrelated document the target's venue makes it related
+	if err := store.UpdateMetadata(ctx, "unrelated", &models.ItemMetadata{Title: "Medieval manuscripts", Publication: "Global Change Biology"}); err != nil {
+		t.Fatalf("UpdateMetadata failed: %v", err)
+	}
+	related, err := store.FindRelatedDocuments(ctx, "target", 0)
+	if err != nil {
+		t.Fatalf("FindRelatedDocuments failed: %v", err)
+	}
+	if !containsRelated(related, "unrelated") {
+		t.Errorf("Expected the updated document to be related, got %+v", related)
+	}
+
+	// A deleted document is no longer suggested
+	if err := store.DeleteDocument(ctx, "strong"); err != nil {
+		t.Fatalf("DeleteDocument failed: %v", err)
+	}
+	related, err = store.FindRelatedDocuments(ctx, "target", 0)
+	if err != nil {
+		t.Fatalf("FindRelatedDocuments failed: %v", err)
+	}
+	if containsRelated(related, "strong") {
+		t.Errorf("Expected the deleted document to be gone, got %+v", related)
+	}
+
+	// A newly stored document is found
+	if err := store.StoreParsedItem(ctx, "new", &models.ParsedItem{Metadata: models.ItemMetadata{Title: "Soil carbon", Authors: []string{"Lee, Ann"}}}, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	related, err = store.FindRelatedDocuments(ctx, "target", 0)
+	if err != nil {
+		t.Fatalf("FindRelatedDocuments failed: %v", err)
+	}
+	if !containsRelated(related, "new") {
+		t.Errorf("Expected the new document to be related, got %+v", related)
+	}
+}
+
+func containsRelated(related []models.RelatedDocument, docID string) bool {
+	for _, doc := range related {
+		if doc.DocumentID == docID {
+			return true
+		}
+	}
+	return false
+}
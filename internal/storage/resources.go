@@ -19,15 +19,31 @@ func CalculateResourcePaths(docID string, parsedItem *models.ParsedItem) []strin
 	if len(parsedItem.PageNumbers) > 0 {
 		firstPage := parsedItem.PageNumbers[0]
 		lastPage := parsedItem.PageNumbers[len(parsedItem.PageNumbers)-1]
-		resourcePaths = append(resourcePaths,
-			fmt.Sprintf("pdf://%s/pages/%s", docID, firstPage),
-			fmt.Sprintf("pdf://%s/pages/%s", docID, lastPage),
-		)
+		resourcePaths = append(resourcePaths, fmt.Sprintf("pdf://%s/pages/%s", docID, firstPage))
+		if lastPage != firstPage {
+			resourcePaths = append(resourcePaths, fmt.Sprintf("pdf://%s/pages/%s", docID, lastPage))
+		}
 	}
 
 	// Add template for accessing any page
 	resourcePaths = append(resourcePaths, fmt.Sprintf("pdf://%s/pages/{sourcePageNumber}", docID))
 
+	// Scanned pages can be rendered as images on request
+	for _, quality := range parsedItem.PageQuality {
+		if quality.IsScanned {
+			resourcePaths = append(resourcePaths, fmt.Sprintf("pdf://%s/pages/{sourcePageNumber}/image", docID))
+			break
+		}
+	}
+
+	// Add section paths if sections exist
+	if len(parsedItem.Sections) > 0 {
+		resourcePaths = append(resourcePaths,
+			fmt.Sprintf("pdf://%s/sections", docID),
+			fmt.Sprintf("pdf://%s/sections/{sectionIndex}", docID),
+		)
+	}
+
 	// Add reference paths if references exist
 	if len(parsedItem.References) > 0 {
 		resourcePaths = append(resourcePaths,
@@ -42,6 +58,12 @@ func CalculateResourcePaths(docID string, parsedItem *models.ParsedItem) []strin
 			fmt.Sprintf("pdf://%s/images", docID),
 			fmt.Sprintf("pdf://%s/images/{imageIndex}", docID),
 		)
+		for _, img := range parsedItem.Images {
+			if img.MIMEType != "" {
+				resourcePaths = append(resourcePaths, fmt.Sprintf("pdf://%s/images/{imageIndex}/data", docID))
+				break
+			}
+		}
 	}
 
 	// Add table paths if tables exist
@@ -68,6 +90,11 @@ func CalculateResourcePaths(docID string, parsedItem *models.ParsedItem) []strin
 		)
 	}
 
+	// Add the combined notes path if there are any footnotes or endnotes
+	if len(parsedItem.Footnotes) > 0 || len(parsedItem.Endnotes) > 0 {
+		resourcePaths = append(resourcePaths, fmt.Sprintf("pdf://%s/notes", docID))
+	}
+
 	// Add quotation paths if quotations exist
 	if len(parsedItem.Quotations) > 0 {
 		resourcePaths = append(resourcePaths,
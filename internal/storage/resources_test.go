@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestCalculateResourcePaths(t *testing.T) {
+	tests := []struct {
+		name     string
+		item     *models.ParsedItem
+		expected []string
+	}{
+		{
+			name: "no optional content",
+			item: &models.ParsedItem{Pages: []string{"Only page"}},
+			expected: []string{
+				"pdf://doc-1",
+				"pdf://doc-1/metadata",
+				"pdf://doc-1/pages",
+				"pdf://doc-1/pages/{sourcePageNumber}",
+			},
+		},
+		{
+			name: "source page numbers",
+			item: &models.ParsedItem{
+				Pages:       []string{"First", "Middle", "Last"},
+				PageNumbers: []string{"125", "126", "127"},
+			},
+			expected: []string{
+				"pdf://doc-1",
+				"pdf://doc-1/metadata",
+				"pdf://doc-1/pages",
+				"pdf://doc-1/pages/125",
+				"pdf://doc-1/pages/127",
+				"pdf://doc-1/pages/{sourcePageNumber}",
+			},
+		},
+		{
+			name: "all optional content",
+			item: &models.ParsedItem{
+				Pages:       []string{"Page"},
+				PageNumbers: []string{"iv"},
+				Sections:    []models.Section{{Title: "Introduction"}},
+				References:  []models.Reference{{ReferenceText: "Ref"}},
+				Images:      []models.Image{{Caption: "Chart"}, {Caption: "Figure", MIMEType: "image/png"}},
+				Tables:      []models.Table{{TableID: "Table 1"}},
+				Footnotes:   []models.Footnote{{Marker: "1"}},
+				Endnotes:    []models.Endnote{{Marker: "1"}},
+				Quotations:  []models.Quotation{{QuotationText: "Quote"}},
+			},
+			expected: []string{
+				"pdf://doc-1",
+				"pdf://doc-1/metadata",
+				"pdf://doc-1/pages",
+				"pdf://doc-1/pages/iv",
+				"pdf://doc-1/pages/{sourcePageNumber}",
+				"pdf://doc-1/sections",
+				"pdf://doc-1/sections/{sectionIndex}",
+				"pdf://doc-1/references",
+				"pdf://doc-1/references/{refIndex}",
+				"pdf://doc-1/images",
+				"pdf://doc-1/images/{imageIndex}",
+				"pdf://doc-1/images/{imageIndex}/data",
+				"pdf://doc-1/tables",
+				"pdf://doc-1/tables/{tableIndex}",
+				"pdf://doc-1/footnotes",
+				"pdf://doc-1/footnotes/{footnoteIndex}",
+				"pdf://doc-1/endnotes",
+				"pdf://doc-1/endnotes/{endnoteIndex}",
+				"pdf://doc-1/notes",
+				"pdf://doc-1/quotations",
+				"pdf://doc-1/quotations/{quotationIndex}",
+			},
+		},
+		{
+			name: "scanned pages",
+			item: &models.ParsedItem{
+				Pages:       []string{"Text", "Scan"},
+				PageQuality: []models.PageQuality{{}, {IsScanned: true}},
+			},
+			expected: []string{
+				"pdf://doc-1",
+				"pdf://doc-1/metadata",
+				"pdf://doc-1/pages",
+				"pdf://doc-1/pages/{sourcePageNumber}",
+				"pdf://doc-1/pages/{sourcePageNumber}/image",
+			},
+		},
+		{
+			name: "only quotations",
+			item: &models.ParsedItem{
+				Pages:      []string{"Page"},
+				Quotations: []models.Quotation{{QuotationText: "Quote"}},
+			},
+			expected: []string{
+				"pdf://doc-1",
+				"pdf://doc-1/metadata",
+				"pdf://doc-1/pages",
+				"pdf://doc-1/pages/{sourcePageNumber}",
+				"pdf://doc-1/quotations",
+				"pdf://doc-1/quotations/{quotationIndex}",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CalculateResourcePaths("doc-1", tt.item)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
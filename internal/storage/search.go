@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/citations"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// likeEscaper escapes the LIKE wildcards and the escape character itself, for
+// patterns written with ESCAPE '\'
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike returns s escaped to match itself literally in a LIKE pattern
+// with ESCAPE '\'
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// publicationYear returns the year of a publication date for the
+// publication_year column, or nil if it has none
+func publicationYear(date string) any {
+	year, err := strconv.Atoi(citations.ExtractYear(date))
+	if err != nil {
+		return nil
+	}
+	return year
+}
+
+// backfillPublicationYears sets the publication year of documents stored before
+// the publication_year column existed
+func backfillPublicationYears(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT id, COALESCE(publication_date, '') FROM documents`)
+	if err != nil {
+		return fmt.Errorf("failed to query publication dates: %w", err)
+	}
+	dates := make(map[string]string)
+	for rows.Next() {
+		var docID, date string
+		if err := rows.Scan(&docID, &date); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan publication date: %w", err)
+		}
+		dates[docID] = date
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating publication dates: %w", err)
+	}
+
+	for docID, date := range dates {
+		if _, err := tx.Exec(`UPDATE documents SET publication_year = ? WHERE id = ?`, publicationYear(date), docID); err != nil {
+			return fmt.Errorf("failed to set publication year: %w", err)
+		}
+	}
+	return nil
+}
+
+// SearchDocuments returns the page of stored documents selected by search's
+// limit and offset among those matching its bibliographic fields, most
+// recently added first, and how many match in all. Authors are matched by
+// their parsed names (family name, given and family name, or the name as
+// written), and by the stored author list for documents without parsed names.
+func (s *SQLiteStore) SearchDocuments(ctx context.Context, search models.LibrarySearch) ([]models.LibrarySearchResult, int, error) {
+	// A prefix pattern matches the start of a value, a substring one anywhere in it
+	pattern := func(value string) string {
+		if search.Prefix {
+			return escapeLike(value) + "%"
+		}
+		return "%" + escapeLike(value) + "%"
+	}
+
+	var conditions []string
+	var args []any
+	for _, field := range []struct{ column, value string }{
+		{"title", search.Title},
+		{"publication", search.Publication},
+		{"doi", search.DOI},
+	} {
+		if value := strings.TrimSpace(field.value); value != "" {
+			conditions = append(conditions, fmt.Sprintf(`COALESCE(d.%s, '') LIKE ? ESCAPE '\'`, field.column))
+			args = append(args, pattern(value))
+		}
+	}
+	if author := strings.TrimSpace(search.Author); author != "" {
+		// The stored list is a JSON array, where a name starts after its quote
+		listPattern := pattern(author)
+		if search.Prefix {
+			listPattern = `%"` + listPattern
+		}
+		nameConditions := []string{
+			`a.family LIKE ? ESCAPE '\'`,
+			`a.raw LIKE ? ESCAPE '\'`,
+			`TRIM(a.given || ' ' || a.family) LIKE ? ESCAPE '\'`,
+		}
+		nameArgs := []any{pattern(author), pattern(author), pattern(author)}
+		// Keys ignore case beyond ASCII, and punctuation and spacing
+		if key := citations.NameKey(author); key != "" {
+			nameConditions = append(nameConditions, `a.family_key LIKE ? ESCAPE '\'`, `a.given_key || a.family_key LIKE ? ESCAPE '\'`)
+			nameArgs = append(nameArgs, pattern(key), pattern(key))
+		}
+		conditions = append(conditions, `(
+			EXISTS (
+				SELECT 1 FROM document_authors a
+				WHERE a.document_id = d.id AND (`+strings.Join(nameConditions, " OR ")+`)
+			)
+			OR (
+				NOT EXISTS (SELECT 1 FROM document_authors a WHERE a.document_id = d.id)
+				AND COALESCE(d.authors, '') LIKE ? ESCAPE '\'
+			)
+		)`)
+		args = append(append(args, nameArgs...), listPattern)
+	}
+	if search.YearFrom > 0 {
+		conditions = append(conditions, `d.publication_year >= ?`)
+		args = append(args, search.YearFrom)
+	}
+	if search.YearTo > 0 {
+		conditions = append(conditions, `d.publication_year <= ?`)
+		args = append(args, search.YearTo)
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM documents d `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count matching documents: %w", err)
+	}
+
+	limit := search.Limit
+	if limit <= 0 {
+		limit = -1 // No limit
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+documentInfoColumns+`,
+		       EXISTS (SELECT 1 FROM summaries sm WHERE sm.document_id = d.id AND sm.summary != '')
+		FROM documents d
+		`+where+`
+		ORDER BY d.created_at DESC, d.id
+		LIMIT ? OFFSET ?
+	`, append(args, limit, max(search.Offset, 0))...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search documents: %w", err)
+	}
+	defer rows.Close()
+
+	var results []models.LibrarySearchResult
+	for rows.Next() {
+		var result models.LibrarySearchResult
+		if err := scanDocumentInfo(rows, &result.DocumentInfo, &result.HasSummary); err != nil {
+			return nil, 0, err
+		}
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating documents: %w", err)
+	}
+
+	tags, err := s.tagsByDocument(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range results {
+		results[i].Tags = tags[results[i].DocumentID]
+	}
+	return results, total, nil
+}
@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// newSearchTestStore returns a store holding documents for library searches
+func newSearchTestStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+	store := newTestStore(t)
+	ctx := context.Background()
+	documents := []struct {
+		docID    string
+		metadata models.ItemMetadata
+		summary  string
+	}{
+		{"cyborg", models.ItemMetadata{Title: "A Cyborg Manifesto", Authors: []string{"Donna J. Haraway"}, PublicationDate: "1991-01-01",
+			Publication: "Simians, Cyborgs, and Women", DOI: "10.4324/9780203873106"}, "About cyborgs."},
+		{"situated", models.ItemMetadata{Title: "Situated Knowledges", Authors: []string{"Haraway, Donna"}, PublicationDate: "Autumn 1988",
+			Publication: "Feminist Studies", DOI: "10.2307/3178066"}, ""},
+		{"percent", models.ItemMetadata{Title: "Growth of 100% Renewables", Authors: []string{"Jane Smith", "Émile Durkheim"}, PublicationDate: "2015",
+			Publication: "Energy_Policy"}, ""},
+		{"percentless", models.ItemMetadata{Title: "Growth of 1000 Renewables", Authors: []string{"Jane Smith"}, PublicationDate: "2016",
+			Publication: "EnergyXPolicy"}, ""},
+		{"undated", models.ItemMetadata{Title: "Undated Notes", Authors: []string{"Robert Jones"}}, ""},
+	}
+	for _, doc := range documents {
+		item := &models.ParsedItem{Metadata: doc.metadata, Pages: []string{"Text"}, Summary: doc.summary}
+		if err := store.StoreParsedItem(ctx, doc.docID, item, &models.SourceInfo{}); err != nil {
+			t.Fatalf("Failed to store document: %v", err)
+		}
+	}
+	return store
+}
+
+func TestSearchDocuments(t *testing.T) {
+	store := newSearchTestStore(t)
+
+	tests := []struct {
+		name     string
+		search   models.LibrarySearch
+		expected []string // In any order
+	}{
+		{"everything", models.LibrarySearch{}, []string{"cyborg", "situated", "percent", "percentless", "undated"}},
+		{"title substring ignoring case", models.LibrarySearch{Title: "cyborg"}, []string{"cyborg"}},
+		{"title prefix", models.LibrarySearch{Title: "situ", Prefix: true}, []string{"situated"}},
+		{"title prefix does not match inside", models.LibrarySearch{Title: "knowledges", Prefix: true}, nil},
+		{"author family name", models.LibrarySearch{Author: "haraway"}, []string{"cyborg", "situated"}},
+		{"author as written", models.LibrarySearch{Author: "Haraway, Donna"}, []string{"situated"}},
+		{"author given and family name", models.LibrarySearch{Author: "donna j. haraway"}, []string{"cyborg"}},
+		{"author prefix", models.LibrarySearch{Author: "Har", Prefix: true}, []string{"cyborg", "situated"}},
+		{"author beyond ASCII ignoring case", models.LibrarySearch{Author: "émile"}, []string{"percent"}},
+		{"author family key", models.LibrarySearch{Author: "DURKHEIM"}, []string{"percent"}},
+		{"publication", models.LibrarySearch{Publication: "feminist"}, []string{"situated"}},
+		{"doi prefix", models.LibrarySearch{DOI: "10.4324", Prefix: true}, []string{"cyborg"}},
+		{"year range", models.LibrarySearch{YearFrom: 1990, YearTo: 1999}, []string{"cyborg"}},
+		{"year from a date in words", models.LibrarySearch{YearTo: 1988}, []string{"situated"}},
+		{"fields combined", models.LibrarySearch{Author: "haraway", YearFrom: 1980, YearTo: 1989}, []string{"situated"}},
+		{"percent sign is literal", models.LibrarySearch{Title: "100%"}, []string{"percent"}},
+		{"percent sign alone", models.LibrarySearch{Title: "%"}, []string{"percent"}},
+		{"underscore is literal", models.LibrarySearch{Publication: "Energy_Policy"}, []string{"percent"}},
+		{"backslash is literal", models.LibrarySearch{Title: `\`}, nil},
+		{"author with wildcards", models.LibrarySearch{Author: "_"}, nil},
+		{"no match", models.LibrarySearch{Title: "quantum"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, total, err := store.SearchDocuments(context.Background(), tt.search)
+			if err != nil {
+				t.Fatalf("SearchDocuments failed: %v", err)
+			}
+			var got []string
+			for _, result := range results {
+				got = append(got, result.DocumentID)
+			}
+			slices.Sort(got)
+			expected := slices.Clone(tt.expected)
+			slices.Sort(expected)
+			if !slices.Equal(got, expected) || total != len(expected) {
+				t.Errorf("Expected %v, got %v (total %d)", expected, got, total)
+			}
+		})
+	}
+}
+
+func TestSearchDocuments_Results(t *testing.T) {
+	store := newSearchTestStore(t)
+	ctx := context.Background()
+
+	results, _, err := store.SearchDocuments(ctx, models.LibrarySearch{Author: "haraway"})
+	if err != nil {
+		t.Fatalf("SearchDocuments failed: %v", err)
+	}
+	byID := make(map[string]models.LibrarySearchResult)
+	for _, result := range results {
+		byID[result.DocumentID] = result
+	}
+	cyborg := byID["cyborg"]
+	if cyborg.Title != "A Cyborg Manifesto" || cyborg.PublicationDate != "1991-01-01" || !cyborg.HasSummary || cyborg.Provenance.CreatedAt == "" {
+		t.Errorf("Unexpected result: %+v", cyborg)
+	}
+	if byID["situated"].HasSummary {
+		t.Error("Expected no summary for situated")
+	}
+
+	// A corrected date moves the document to another year
+	metadata, err := store.GetMetadata(ctx, "situated")
+	if err != nil {
+		t.Fatalf("GetMetadata failed: %v", err)
+	}
+	metadata.PublicationDate = "1998"
+	if err := store.UpdateMetadata(ctx, "situated", metadata); err != nil {
+		t.Fatalf("UpdateMetadata failed: %v", err)
+	}
+	if results, _, err := store.SearchDocuments(ctx, models.LibrarySearch{YearFrom: 1995}); err != nil || len(results) != 3 {
+		t.Errorf("Expected the corrected year searched, got %+v, %v", results, err)
+	}
+}
+
+func TestSearchDocuments_Paging(t *testing.T) {
+	store := newSearchTestStore(t)
+	ctx := context.Background()
+
+	all, total, err := store.SearchDocuments(ctx, models.LibrarySearch{})
+	if err != nil || total != 5 || len(all) != 5 {
+		t.Fatalf("SearchDocuments = %d results of %d, %v", len(all), total, err)
+	}
+
+	var paged []models.LibrarySearchResult
+	for offset := 0; offset < total; offset += 2 {
+		page, pageTotal, err := store.SearchDocuments(ctx, models.LibrarySearch{Limit: 2, Offset: offset})
+		if err != nil {
+			t.Fatalf("SearchDocuments failed: %v", err)
+		}
+		if pageTotal != 5 || len(page) > 2 {
+			t.Fatalf("Expected a page of at most 2 of 5, got %d of %d", len(page), pageTotal)
+		}
+		paged = append(paged, page...)
+	}
+	for i := range all {
+		if paged[i].DocumentID != all[i].DocumentID {
+			t.Errorf("Expected pages in the order of the full result, got %s at %d, want %s", paged[i].DocumentID, i, all[i].DocumentID)
+		}
+	}
+
+	if page, total, err := store.SearchDocuments(ctx, models.LibrarySearch{Limit: 2, Offset: 10}); err != nil || len(page) != 0 || total != 5 {
+		t.Errorf("Expected an empty page past the end, got %d of %d, %v", len(page), total, err)
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	tests := map[string]string{
+		"plain":     "plain",
+		"100%":      `100\%`,
+		"a_b":       `a\_b`,
+		`back\hand`: `back\\hand`,
+		`\%_`:       `\\\%\_`,
+	}
+	for input, expected := range tests {
+		if got := escapeLike(input); got != expected {
+			t.Errorf("escapeLike(%q) = %q, want %q", input, got, expected)
+		}
+	}
+}
@@ -5,143 +5,132 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 
 	_ "github.com/mattn/go-sqlite3"
 
+	"github.com/Epistemic-Technology/academic-mcp/internal/citations"
 	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
 	"github.com/Epistemic-Technology/academic-mcp/models"
 )
 
+// childTables lists the per-document tables that hold rows keyed by document_id.
+// They are cleared before a document is re-stored so that a smaller version of
+// the document does not leave stale rows behind.
+var childTables = []string{
+	"pages",
+	"document_references",
+	"images",
+	"document_tables",
+	"footnotes",
+	"endnotes",
+	"quotations",
+	"sections",
+	"document_authors",
+	"document_tags",
+	"page_images",
+}
+
+// nullIfEmpty converts an empty string to NULL so that optional columns with
+// unique indexes (e.g. citekey) do not collide on empty values
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
 // SQLiteStore implements the Store interface using SQLite
 type SQLiteStore struct {
 	db     *sql.DB
 	logger logger.Logger
+	cipher *contentCipher // Encrypts content columns; nil for a plaintext database
+
+	related relatedCache // The index of FindRelatedDocuments, built on first use
 }
 
-// NewSQLiteStore creates a new SQLite store
+// connectionPragmas are applied to every connection the pool opens:
+//   - WAL lets readers proceed while another connection writes
+//   - busy_timeout makes a connection wait for a lock instead of failing with "database is locked"
+//   - foreign_keys enforces the ON DELETE CASCADE constraints on child tables
+//   - synchronous=NORMAL is durable across application crashes in WAL mode and avoids an fsync per commit
+//   - txlock=immediate takes the write lock when a transaction begins, so concurrent writers
+//     queue on busy_timeout rather than failing when a read lock can't be upgraded
+const connectionPragmas = "_journal_mode=WAL&_busy_timeout=5000&_foreign_keys=on&_synchronous=NORMAL&_txlock=immediate"
+
+// maxOpenConns caps the connection pool. SQLite allows one writer at a time, so more
+// connections only add readers contending for the same file.
+const maxOpenConns = 4
+
+// NewSQLiteStore creates a new SQLite store for a plaintext database
 func NewSQLiteStore(dbPath string, log logger.Logger) (*SQLiteStore, error) {
-	db, err := sql.Open("sqlite3", dbPath)
+	return NewSQLiteStoreWithKey(dbPath, "", log)
+}
+
+// NewSQLiteStoreWithKey creates a new SQLite store whose content columns are
+// encrypted with key (see encryption.go), or a plaintext one if key is empty.
+// A new database is encrypted from the start; opening an encrypted database
+// without its key, or with another one, fails.
+func NewSQLiteStoreWithKey(dbPath, key string, log logger.Logger) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite3", sqliteDSN(dbPath))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	if isInMemory(dbPath) {
+		// Each connection to :memory: opens a separate, empty database
+		db.SetMaxOpenConns(1)
+	} else {
+		db.SetMaxOpenConns(maxOpenConns)
+		db.SetMaxIdleConns(maxOpenConns)
+	}
 
 	store := &SQLiteStore{db: db, logger: log}
-	if err := store.initSchema(); err != nil {
+	if err := store.migrate(); err != nil {
 		db.Close()
 		return nil, fmt.Errorf("failed to initialize schema: %w", err)
 	}
+	store.cipher, err = loadCipher(db, key)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := store.backfillStableIDs(context.Background()); err != nil {
+		db.Close()
+		return nil, err
+	}
 
 	log.Debug("SQLite store initialized successfully")
 
 	return store, nil
 }
 
-// initSchema creates the database tables if they don't exist
-func (s *SQLiteStore) initSchema() error {
-	schema := `
-	CREATE TABLE IF NOT EXISTS documents (
-		id TEXT PRIMARY KEY,
-		title TEXT,
-		authors TEXT,
-		publication_date TEXT,
-		publication TEXT,
-		doi TEXT,
-		abstract TEXT,
-		summary TEXT,
-		zotero_id TEXT,
-		url TEXT,
-		item_type TEXT,
-		publisher TEXT,
-		volume TEXT,
-		issue TEXT,
-		pages TEXT,
-		issn TEXT,
-		isbn TEXT,
-		metadata_url TEXT,
-		metadata_source TEXT,
-		citekey TEXT,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);
-
-	CREATE TABLE IF NOT EXISTS pages (
-		document_id TEXT NOT NULL,
-		page_number INTEGER NOT NULL,
-		source_page_number TEXT NOT NULL,
-		content TEXT,
-		PRIMARY KEY (document_id, page_number),
-		FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_pages_source_number ON pages(document_id, source_page_number);
-
-	CREATE TABLE IF NOT EXISTS document_references (
-		document_id TEXT NOT NULL,
-		ref_index INTEGER NOT NULL,
-		reference_text TEXT,
-		doi TEXT,
-		PRIMARY KEY (document_id, ref_index),
-		FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE
-	);
-
-	CREATE TABLE IF NOT EXISTS images (
-		document_id TEXT NOT NULL,
-		image_index INTEGER NOT NULL,
-		image_url TEXT,
-		image_description TEXT,
-		caption TEXT,
-		PRIMARY KEY (document_id, image_index),
-		FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE
-	);
-
-	CREATE TABLE IF NOT EXISTS document_tables (
-		document_id TEXT NOT NULL,
-		table_index INTEGER NOT NULL,
-		table_id TEXT,
-		table_title TEXT,
-		table_data TEXT,
-		PRIMARY KEY (document_id, table_index),
-		FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE
-	);
-
-	CREATE TABLE IF NOT EXISTS footnotes (
-		document_id TEXT NOT NULL,
-		footnote_index INTEGER NOT NULL,
-		marker TEXT,
-		text TEXT,
-		page_number TEXT,
-		in_text_page TEXT,
-		PRIMARY KEY (document_id, footnote_index),
-		FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE
-	);
-
-	CREATE TABLE IF NOT EXISTS endnotes (
-		document_id TEXT NOT NULL,
-		endnote_index INTEGER NOT NULL,
-		marker TEXT,
-		text TEXT,
-		page_number TEXT,
-		PRIMARY KEY (document_id, endnote_index),
-		FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE
-	);
-
-	CREATE TABLE IF NOT EXISTS quotations (
-		document_id TEXT NOT NULL,
-		quotation_index INTEGER NOT NULL,
-		quotation_text TEXT,
-		page_number TEXT,
-		context TEXT,
-		relevance TEXT,
-		PRIMARY KEY (document_id, quotation_index),
-		FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_documents_doi ON documents(doi);
-	CREATE INDEX IF NOT EXISTS idx_documents_zotero_id ON documents(zotero_id);
-	CREATE UNIQUE INDEX IF NOT EXISTS idx_documents_citekey ON documents(citekey) WHERE citekey IS NOT NULL;
-	`
-
-	_, err := s.db.Exec(schema)
-	return err
+// DatabasePath returns the database path the server uses: ACADEMIC_MCP_DB_PATH,
+// or ~/.academic-mcp/academic.db by default
+func DatabasePath() (string, error) {
+	if dbPath := os.Getenv("ACADEMIC_MCP_DB_PATH"); dbPath != "" {
+		return dbPath, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".academic-mcp", "academic.db"), nil
+}
+
+// sqliteDSN appends the connection pragmas to a database path, which may already carry parameters
+func sqliteDSN(dbPath string) string {
+	if strings.Contains(dbPath, "?") {
+		return dbPath + "&" + connectionPragmas
+	}
+	return dbPath + "?" + connectionPragmas
+}
+
+func isInMemory(dbPath string) bool {
+	return strings.HasPrefix(dbPath, ":memory:") || strings.Contains(dbPath, "mode=memory")
 }
 
 // StoreParsedItem stores a parsed PDF with the provided document ID
@@ -161,131 +150,330 @@ func (s *SQLiteStore) StoreParsedItem(ctx context.Context, docID string, item *m
 	if err != nil {
 		return fmt.Errorf("failed to marshal authors: %w", err)
 	}
+	keywordsJSON, err := marshalKeywords(item.Metadata.Keywords)
+	if err != nil {
+		return err
+	}
+	corporateJSON, err := marshalKeywords(item.Metadata.CorporateAuthors)
+	if err != nil {
+		return err
+	}
+	fieldSources, conflicts := encodeProvenance(&item.Metadata)
+
+	library, _ := SplitLibraryDocumentID(docID)
+
+	// Replacing the row would reset created_at, so a re-stored document keeps its
+	// own. It is read as text, since the driver would reformat a DATETIME column.
+	var createdAt string
+	err = tx.QueryRowContext(ctx, `SELECT COALESCE(created_at, '') FROM documents WHERE id = ?`, docID).Scan(&createdAt)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to read document creation time: %w", err)
+	}
+	var provenance models.Provenance
+	if item.Provenance != nil {
+		provenance = *item.Provenance
+	}
+
+	// Section summaries outlive a re-store that keeps the section index, such
+	// as storing quotations; a changed index makes them stale
+	if err := clearStaleSectionSummaries(ctx, tx, docID, item.Sections); err != nil {
+		return err
+	}
 
 	_, err = tx.ExecContext(ctx, `
 		INSERT OR REPLACE INTO documents (
-			id, title, authors, publication_date, publication, doi, abstract, summary,
+			id, library, title, authors, publication_date, publication, doi, abstract,
+			funding_statement, acknowledgments, data_availability, keywords, corporate_authors,
 			zotero_id, url, item_type, publisher, volume, issue, pages, issn, isbn,
-			metadata_url, metadata_source, citekey
+			metadata_url, metadata_source, citekey, is_scanned, chunk_count, page_numbering, pdf_url, language,
+			field_sources, metadata_conflicts, publication_year,
+			created_at, updated_at, parsed_model, prompt_version, parser_version, full_text, content_hash, partial, basic
 		)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, docID, item.Metadata.Title, string(authorsJSON), item.Metadata.PublicationDate,
-		item.Metadata.Publication, item.Metadata.DOI, item.Metadata.Abstract, item.Summary,
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?,
+			COALESCE(?, CURRENT_TIMESTAMP), CURRENT_TIMESTAMP, ?, ?, ?, ?, ?, ?, ?)
+	`, docID, library, item.Metadata.Title, string(authorsJSON), item.Metadata.PublicationDate,
+		item.Metadata.Publication, s.storedDOI(docID, item.Metadata.DOI), item.Metadata.Abstract,
+		item.Metadata.FundingStatement, item.Metadata.Acknowledgments, item.Metadata.DataAvailability, keywordsJSON, corporateJSON,
 		sourceInfo.ZoteroID, sourceInfo.URL, item.Metadata.ItemType, item.Metadata.Publisher,
 		item.Metadata.Volume, item.Metadata.Issue, item.Metadata.Pages, item.Metadata.ISSN,
-		item.Metadata.ISBN, item.Metadata.URL, item.Metadata.MetadataSource, item.Metadata.Citekey)
+		item.Metadata.ISBN, item.Metadata.URL, item.Metadata.MetadataSource, nullIfEmpty(item.Metadata.Citekey),
+		item.IsScanned, item.ChunkCount, item.PageNumbering, item.PDFURL, item.Metadata.Language,
+		fieldSources, conflicts, publicationYear(item.Metadata.PublicationDate),
+		nullIfEmpty(createdAt), provenance.ParsedModel, provenance.PromptVersion, provenance.ParserVersion, s.cipher.seal("documents.full_text", docID, item.FullText), item.ContentHash, item.Partial, item.Basic)
 	if err != nil {
 		return fmt.Errorf("failed to insert document: %w", err)
 	}
 
+	// A document is always a source of itself
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO document_sources (source_id, document_id, zotero_id, zotero_library_type, zotero_library_id, url)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (source_id) DO UPDATE SET
+			document_id = excluded.document_id,
+			zotero_id = excluded.zotero_id,
+			zotero_library_type = excluded.zotero_library_type,
+			zotero_library_id = excluded.zotero_library_id,
+			url = excluded.url
+	`, docID, docID, sourceInfo.ZoteroID, sourceInfo.ZoteroLibraryType, sourceInfo.ZoteroLibraryID, sourceInfo.URL)
+	if err != nil {
+		return fmt.Errorf("failed to record document source: %w", err)
+	}
+
+	// The item's summary is its standard one; summaries in other styles are kept
+	if err := storeSummary(ctx, tx, docID, models.SummaryStyleStandard, item.Summary); err != nil {
+		return err
+	}
+
+	// Fetch info is only replaced by a new fetch; a document stored again
+	// without one, say with its quotations, keeps what it was parsed from
+	if item.Fetch != nil {
+		if err := storeFetchInfo(ctx, tx, docID, item.Fetch); err != nil {
+			return err
+		}
+	}
+
+	// Remove rows from any previous version of this document
+	for _, table := range childTables {
+		_, err = tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE document_id = ?", table), docID)
+		if err != nil {
+			return fmt.Errorf("failed to clear %s: %w", table, err)
+		}
+	}
+
 	// Store pages
-	for i, pageContent := range item.Pages {
+	err = insertRows(ctx, tx, "page", `
+		INSERT INTO pages (document_id, page_number, source_page_number, content, is_scanned, near_empty, full_text_offset,
+			content_confidence, is_blank, is_cover, is_references_only, degraded, sentences)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, len(item.Pages), func(i int) []any {
 		sourcePageNum := fmt.Sprintf("%d", i+1) // Default to sequential numbering
 		if i < len(item.PageNumbers) && item.PageNumbers[i] != "" {
 			sourcePageNum = item.PageNumbers[i]
 		}
 
-		_, err = tx.ExecContext(ctx, `
-			INSERT OR REPLACE INTO pages (document_id, page_number, source_page_number, content)
-			VALUES (?, ?, ?, ?)
-		`, docID, i+1, sourcePageNum, pageContent)
-		if err != nil {
-			return fmt.Errorf("failed to insert page %d: %w", i+1, err)
+		var quality models.PageQuality
+		if i < len(item.PageQuality) {
+			quality = item.PageQuality[i]
+		}
+
+		var offset int
+		if i < len(item.PageOffsets) {
+			offset = item.PageOffsets[i]
+		}
+
+		var sentences any
+		if i < len(item.PageSentences) && item.PageSentences[i] != nil {
+			data, _ := json.Marshal(item.PageSentences[i])
+			sentences = string(data)
 		}
+
+		return []any{docID, i + 1, sourcePageNum, s.cipher.seal("pages.content", docID, item.Pages[i]), quality.IsScanned, quality.NearEmpty, offset,
+			quality.ContentConfidence, quality.IsBlank, quality.IsCover, quality.IsReferencesOnly, quality.Degraded, sentences}
+	})
+	if err != nil {
+		return err
 	}
 
 	// Store references
-	for i, ref := range item.References {
-		_, err = tx.ExecContext(ctx, `
-			INSERT OR REPLACE INTO document_references (document_id, ref_index, reference_text, doi)
-			VALUES (?, ?, ?, ?)
-		`, docID, i, ref.ReferenceText, ref.DOI)
-		if err != nil {
-			return fmt.Errorf("failed to insert reference %d: %w", i, err)
-		}
+	err = insertRows(ctx, tx, "reference", `
+		INSERT INTO document_references (document_id, ref_index, reference_text, doi, page_number, page_index)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, len(item.References), func(i int) []any {
+		ref := item.References[i]
+		return []any{docID, i, ref.ReferenceText, s.storedDOI(docID, ref.DOI), ref.PageNumber, ref.PageIndex}
+	})
+	if err != nil {
+		return err
 	}
 
+	// Images, tables, and footnotes are identified across re-parses by their
+	// page and content
+	AssignStableIDs(item)
+
 	// Store images
-	for i, img := range item.Images {
-		_, err = tx.ExecContext(ctx, `
-			INSERT OR REPLACE INTO images (document_id, image_index, image_url, image_description, caption)
-			VALUES (?, ?, ?, ?, ?)
-		`, docID, i, img.ImageURL, img.ImageDescription, img.Caption)
-		if err != nil {
-			return fmt.Errorf("failed to insert image %d: %w", i, err)
+	err = insertRows(ctx, tx, "image", `
+		INSERT INTO images (document_id, image_index, image_url, image_description, caption, page_index, mime_type, width, height, source_kind, stable_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, len(item.Images), func(i int) []any {
+		img := item.Images[i]
+		sourceKind := img.SourceKind
+		if sourceKind == "" {
+			sourceKind = img.ImpliedSourceKind()
 		}
+		return []any{docID, i, img.ImageURL, img.ImageDescription, img.Caption, img.PageIndex, img.MIMEType, img.Width, img.Height, sourceKind, img.StableID}
+	})
+	if err != nil {
+		return err
+	}
+	if err := storeImageBlobs(ctx, tx, docID, item.Images); err != nil {
+		return err
 	}
 
 	// Store tables
-	for i, tbl := range item.Tables {
-		_, err = tx.ExecContext(ctx, `
-			INSERT OR REPLACE INTO document_tables (document_id, table_index, table_id, table_title, table_data)
-			VALUES (?, ?, ?, ?, ?)
-		`, docID, i, tbl.TableID, tbl.TableTitle, tbl.TableData)
-		if err != nil {
-			return fmt.Errorf("failed to insert table %d: %w", i, err)
-		}
+	err = insertRows(ctx, tx, "table", `
+		INSERT INTO document_tables (document_id, table_index, table_id, table_title, table_data, table_columns, table_rows, parse_error, page_number, stable_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, len(item.Tables), func(i int) []any {
+		tbl := item.Tables[i]
+		columns, rows := encodeTableStructure(tbl)
+		return []any{docID, i, tbl.TableID, tbl.TableTitle, tbl.TableData, columns, rows, tbl.ParseError, tbl.PageNumber, tbl.StableID}
+	})
+	if err != nil {
+		return err
 	}
 
 	// Store footnotes
-	for i, footnote := range item.Footnotes {
-		_, err = tx.ExecContext(ctx, `
-			INSERT OR REPLACE INTO footnotes (document_id, footnote_index, marker, text, page_number, in_text_page)
-			VALUES (?, ?, ?, ?, ?, ?)
-		`, docID, i, footnote.Marker, footnote.Text, footnote.PageNumber, footnote.InTextPage)
-		if err != nil {
-			return fmt.Errorf("failed to insert footnote %d: %w", i, err)
-		}
+	err = insertRows(ctx, tx, "footnote", `
+		INSERT INTO footnotes (document_id, footnote_index, marker, text, page_number, in_text_page, stable_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, len(item.Footnotes), func(i int) []any {
+		footnote := item.Footnotes[i]
+		return []any{docID, i, footnote.Marker, s.cipher.seal("footnotes.text", docID, footnote.Text), footnote.PageNumber, footnote.InTextPage, footnote.StableID}
+	})
+	if err != nil {
+		return err
 	}
 
 	// Store endnotes
-	for i, endnote := range item.Endnotes {
-		_, err = tx.ExecContext(ctx, `
-			INSERT OR REPLACE INTO endnotes (document_id, endnote_index, marker, text, page_number)
-			VALUES (?, ?, ?, ?, ?)
-		`, docID, i, endnote.Marker, endnote.Text, endnote.PageNumber)
-		if err != nil {
-			return fmt.Errorf("failed to insert endnote %d: %w", i, err)
+	err = insertRows(ctx, tx, "endnote", `
+		INSERT INTO endnotes (document_id, endnote_index, marker, note_key, text, page_number)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, len(item.Endnotes), func(i int) []any {
+		endnote := item.Endnotes[i]
+		return []any{docID, i, endnote.Marker, endnote.Key, s.cipher.seal("endnotes.text", docID, endnote.Text), endnote.PageNumber}
+	})
+	if err != nil {
+		return err
+	}
+
+	// Store quotations. A set none of which is selected was chosen before
+	// quotations were ranked (as in older archives), so all are selected.
+	allSelected := !slices.ContainsFunc(item.Quotations, func(q models.Quotation) bool { return q.Selected })
+	err = insertRows(ctx, tx, "quotation", `
+		INSERT INTO quotations (document_id, quotation_index, quotation_text, page_number, context, relevance,
+			verified, match_score, span_start, span_end, page_hash, selected, priority_rank)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, len(item.Quotations), func(i int) []any {
+		quotation := item.Quotations[i]
+		var spanStart, spanEnd any
+		if quotation.Span != nil {
+			spanStart, spanEnd = quotation.Span.Start, quotation.Span.End
 		}
+		return []any{docID, i, s.cipher.seal("quotations.quotation_text", docID, quotation.QuotationText), quotation.PageNumber,
+			s.cipher.seal("quotations.context", docID, quotation.Context), quotation.Relevance,
+			quotation.Verified, quotation.MatchScore, spanStart, spanEnd, quotation.PageHash, quotation.Selected || allSelected, quotation.Rank}
+	})
+	if err != nil {
+		return err
 	}
 
-	// Store quotations
-	for i, quotation := range item.Quotations {
-		_, err = tx.ExecContext(ctx, `
-			INSERT OR REPLACE INTO quotations (document_id, quotation_index, quotation_text, page_number, context, relevance)
-			VALUES (?, ?, ?, ?, ?, ?)
-		`, docID, i, quotation.QuotationText, quotation.PageNumber, quotation.Context, quotation.Relevance)
-		if err != nil {
-			return fmt.Errorf("failed to insert quotation %d: %w", i, err)
-		}
+	// Store the parsed authors alongside the JSON list kept on the document
+	if err := insertAuthors(ctx, tx, docID, item.Metadata.Authors); err != nil {
+		return err
+	}
+	if err := insertTags(ctx, tx, docID, item.Metadata.Tags); err != nil {
+		return err
+	}
+
+	// Store sections
+	err = insertRows(ctx, tx, "section", `
+		INSERT INTO sections (document_id, section_index, title, level, start_page, end_page,
+			start_page_index, end_page_index, start_offset, end_offset)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, len(item.Sections), func(i int) []any {
+		section := item.Sections[i]
+		return []any{docID, i, section.Title, section.Level, section.StartPage, section.EndPage,
+			section.StartPageIndex, section.EndPageIndex, section.StartOffset, section.EndOffset}
+	})
+	if err != nil {
+		return err
+	}
+
+	// Link the document's references to the library, and the library's to it
+	if err := linkReferences(ctx, tx, docID); err != nil {
+		return err
 	}
 
 	if err := tx.Commit(); err != nil {
 		s.logger.Error("Failed to commit transaction for document %s: %v", docID, err)
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
+	s.related.invalidate()
 
 	s.logger.Debug("Successfully stored document %s", docID)
 	return nil
 }
 
+// insertRows inserts count rows in tx, preparing the statement once and reusing it
+// for every row rather than re-preparing it per row. args returns the values for
+// row i; what names the element in error messages.
+func insertRows(ctx context.Context, tx *sql.Tx, what string, query string, count int, args func(i int) []any) error {
+	if count == 0 {
+		return nil
+	}
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare %s insert: %w", what, err)
+	}
+	defer stmt.Close()
+
+	for i := 0; i < count; i++ {
+		if _, err := stmt.ExecContext(ctx, args(i)...); err != nil {
+			return fmt.Errorf("failed to insert %s %d: %w", what, i, err)
+		}
+	}
+	return nil
+}
+
+// marshalKeywords encodes a document's keywords for the keywords column, which
+// holds an empty list rather than null when there are none. It also encodes the
+// corporate_authors column.
+func marshalKeywords(keywords []string) (string, error) {
+	if keywords == nil {
+		keywords = []string{}
+	}
+	data, err := json.Marshal(keywords)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal keywords: %w", err)
+	}
+	return string(data), nil
+}
+
+// unmarshalKeywords decodes the keywords column, returning nil when there are
+// no keywords
+func unmarshalKeywords(data string) ([]string, error) {
+	var keywords []string
+	if err := json.Unmarshal([]byte(data), &keywords); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal keywords: %w", err)
+	}
+	if len(keywords) == 0 {
+		return nil, nil
+	}
+	return keywords, nil
+}
+
 // GetMetadata retrieves metadata for a document by ID
 func (s *SQLiteStore) GetMetadata(ctx context.Context, docID string) (*models.ItemMetadata, error) {
 	var metadata models.ItemMetadata
-	var authorsJSON string
+	var authorsJSON, keywordsJSON, corporateJSON, fieldSources, conflicts string
 
 	err := s.db.QueryRowContext(ctx, `
 		SELECT title, authors, publication_date, publication, doi, abstract,
-		       item_type, publisher, volume, issue, pages, issn, isbn, metadata_url, metadata_source, citekey
+		       funding_statement, acknowledgments, data_availability, keywords, corporate_authors,
+		       item_type, publisher, volume, issue, pages, issn, isbn, metadata_url, metadata_source, COALESCE(citekey, ''), language,
+		       field_sources, metadata_conflicts
 		FROM documents
 		WHERE id = ?
 	`, docID).Scan(&metadata.Title, &authorsJSON, &metadata.PublicationDate,
 		&metadata.Publication, &metadata.DOI, &metadata.Abstract,
+		&metadata.FundingStatement, &metadata.Acknowledgments, &metadata.DataAvailability, &keywordsJSON, &corporateJSON,
 		&metadata.ItemType, &metadata.Publisher, &metadata.Volume, &metadata.Issue,
-		&metadata.Pages, &metadata.ISSN, &metadata.ISBN, &metadata.URL, &metadata.MetadataSource, &metadata.Citekey)
+		&metadata.Pages, &metadata.ISSN, &metadata.ISBN, &metadata.URL, &metadata.MetadataSource, &metadata.Citekey,
+		&metadata.Language, &fieldSources, &conflicts)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("document not found: %s", docID)
+		return nil, fmt.Errorf("document %w: %s", ErrNotFound, docID)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to query metadata: %w", err)
@@ -294,26 +482,161 @@ func (s *SQLiteStore) GetMetadata(ctx context.Context, docID string) (*models.It
 	if err := json.Unmarshal([]byte(authorsJSON), &metadata.Authors); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal authors: %w", err)
 	}
+	metadata.Keywords, err = unmarshalKeywords(keywordsJSON)
+	if err != nil {
+		return nil, err
+	}
+	metadata.CorporateAuthors, err = unmarshalKeywords(corporateJSON)
+	if err != nil {
+		return nil, err
+	}
+	if err := decodeProvenance(&metadata, fieldSources, conflicts); err != nil {
+		return nil, err
+	}
+	metadata.Tags, err = s.GetTags(ctx, docID)
+	if err != nil {
+		return nil, err
+	}
 
 	return &metadata, nil
 }
 
-// GetSummary retrieves the summary for a document by ID
-func (s *SQLiteStore) GetSummary(ctx context.Context, docID string) (string, error) {
-	var summary string
-	err := s.db.QueryRowContext(ctx, `
-		SELECT summary FROM documents
+// UpdateMetadata replaces the bibliographic metadata of a stored document,
+// keeping its content, citekey, and sources
+func (s *SQLiteStore) UpdateMetadata(ctx context.Context, docID string, metadata *models.ItemMetadata) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	authorsJSON, err := json.Marshal(metadata.Authors)
+	if err != nil {
+		return fmt.Errorf("failed to marshal authors: %w", err)
+	}
+	keywordsJSON, err := marshalKeywords(metadata.Keywords)
+	if err != nil {
+		return err
+	}
+	corporateJSON, err := marshalKeywords(metadata.CorporateAuthors)
+	if err != nil {
+		return err
+	}
+	fieldSources, conflicts := encodeProvenance(metadata)
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE documents SET
+			title = ?, authors = ?, publication_date = ?, publication = ?, doi = ?, abstract = ?,
+			funding_statement = ?, acknowledgments = ?, data_availability = ?, keywords = ?, corporate_authors = ?,
+			item_type = ?, publisher = ?, volume = ?, issue = ?, pages = ?, issn = ?, isbn = ?,
+			metadata_url = ?, metadata_source = ?, language = ?, field_sources = ?, metadata_conflicts = ?,
+			publication_year = ?
 		WHERE id = ?
-	`, docID).Scan(&summary)
+	`, metadata.Title, string(authorsJSON), metadata.PublicationDate, metadata.Publication, s.storedDOI(docID, metadata.DOI), metadata.Abstract,
+		metadata.FundingStatement, metadata.Acknowledgments, metadata.DataAvailability, keywordsJSON, corporateJSON,
+		metadata.ItemType, metadata.Publisher, metadata.Volume, metadata.Issue, metadata.Pages, metadata.ISSN, metadata.ISBN,
+		metadata.URL, metadata.MetadataSource, metadata.Language, fieldSources, conflicts,
+		publicationYear(metadata.PublicationDate), docID)
+	if err != nil {
+		return fmt.Errorf("failed to update metadata: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to update metadata: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("document %w: %s", ErrNotFound, docID)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM document_authors WHERE document_id = ?`, docID); err != nil {
+		return fmt.Errorf("failed to clear document_authors: %w", err)
+	}
+	if err := insertAuthors(ctx, tx, docID, metadata.Authors); err != nil {
+		return err
+	}
+
+	// A corrected title, DOI, author, or year can change which references cite
+	// the document
+	if err := linkReferences(ctx, tx, docID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	s.related.invalidate()
+	return nil
+}
 
+// encodeProvenance returns the field sources and conflicts of metadata as JSON,
+// or empty strings if there are none
+func encodeProvenance(metadata *models.ItemMetadata) (string, string) {
+	var fieldSources, conflicts string
+	// Maps of strings and slices of string structs always marshal
+	if len(metadata.FieldSources) > 0 {
+		data, _ := json.Marshal(metadata.FieldSources)
+		fieldSources = string(data)
+	}
+	if len(metadata.Conflicts) > 0 {
+		data, _ := json.Marshal(metadata.Conflicts)
+		conflicts = string(data)
+	}
+	return fieldSources, conflicts
+}
+
+// decodeProvenance sets the field sources and conflicts of metadata from their stored JSON
+func decodeProvenance(metadata *models.ItemMetadata, fieldSources string, conflicts string) error {
+	if fieldSources != "" {
+		if err := json.Unmarshal([]byte(fieldSources), &metadata.FieldSources); err != nil {
+			return fmt.Errorf("failed to unmarshal field sources: %w", err)
+		}
+	}
+	if conflicts != "" {
+		if err := json.Unmarshal([]byte(conflicts), &metadata.Conflicts); err != nil {
+			return fmt.Errorf("failed to unmarshal metadata conflicts: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetSourceInfo retrieves where a document was originally obtained from
+func (s *SQLiteStore) GetSourceInfo(ctx context.Context, docID string) (*models.SourceInfo, error) {
+	var sourceInfo models.SourceInfo
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COALESCE(zotero_id, ''), COALESCE(url, '') FROM documents WHERE id = ?
+	`, docID).Scan(&sourceInfo.ZoteroID, &sourceInfo.URL)
 	if err == sql.ErrNoRows {
-		return "", fmt.Errorf("document not found: %s", docID)
+		return nil, fmt.Errorf("document %w: %s", ErrNotFound, docID)
 	}
 	if err != nil {
-		return "", fmt.Errorf("failed to query summary: %w", err)
+		return nil, fmt.Errorf("failed to query source info: %w", err)
+	}
+
+	// The Zotero library is recorded with the document as its own source. A
+	// document stored before sources recorded libraries has it encoded in its ID,
+	// unless it is from the default user library.
+	if sourceInfo.ZoteroID != "" {
+		err := s.db.QueryRowContext(ctx, `
+			SELECT zotero_library_type, zotero_library_id FROM document_sources
+			WHERE source_id = ? AND document_id = ? AND zotero_id = ?
+		`, docID, docID, sourceInfo.ZoteroID).Scan(&sourceInfo.ZoteroLibraryType, &sourceInfo.ZoteroLibraryID)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, fmt.Errorf("failed to query source library: %w", err)
+		}
+	}
+	if sourceInfo.ZoteroLibraryType == "" {
+		if zoteroSource, ok := ParseZoteroDocumentID(docID); ok && zoteroSource.ZoteroID == sourceInfo.ZoteroID {
+			sourceInfo.ZoteroLibraryType = zoteroSource.ZoteroLibraryType
+			sourceInfo.ZoteroLibraryID = zoteroSource.ZoteroLibraryID
+		}
 	}
+	// So is the page range of a document parsed from part of a PDF
+	sourceInfo.Pages = ParsePageRangeDocumentID(docID)
 
-	return summary, nil
+	return &sourceInfo, nil
+}
+
+// GetSummary retrieves the standard summary for a document by ID
+func (s *SQLiteStore) GetSummary(ctx context.Context, docID string) (string, error) {
+	return s.GetStyledSummary(ctx, docID, models.SummaryStyleStandard)
 }
 
 // GetPage retrieves a specific page by document ID and page number (1-indexed sequential)
@@ -325,13 +648,13 @@ func (s *SQLiteStore) GetPage(ctx context.Context, docID string, pageNum int) (s
 	`, docID, pageNum).Scan(&content)
 
 	if err == sql.ErrNoRows {
-		return "", fmt.Errorf("page not found: %s page %d", docID, pageNum)
+		return "", fmt.Errorf("page %w: %s page %d", ErrNotFound, docID, pageNum)
 	}
 	if err != nil {
 		return "", fmt.Errorf("failed to query page: %w", err)
 	}
 
-	return content, nil
+	return s.cipher.open("pages.content", docID, content)
 }
 
 // GetPageBySourceNumber retrieves a page by its source page number (e.g., "125", "iv")
@@ -343,13 +666,78 @@ func (s *SQLiteStore) GetPageBySourceNumber(ctx context.Context, docID string, s
 	`, docID, sourcePageNum).Scan(&content)
 
 	if err == sql.ErrNoRows {
-		return "", fmt.Errorf("page not found: %s source page %s", docID, sourcePageNum)
+		return "", fmt.Errorf("page %w: %s source page %s", ErrNotFound, docID, sourcePageNum)
 	}
 	if err != nil {
 		return "", fmt.Errorf("failed to query page by source number: %w", err)
 	}
 
-	return content, nil
+	return s.cipher.open("pages.content", docID, content)
+}
+
+// GetPageSentences retrieves the sentence offsets recorded for a page, by its
+// source page number, or nil if none were recorded
+func (s *SQLiteStore) GetPageSentences(ctx context.Context, docID string, sourcePageNum string) ([]models.TextSpan, error) {
+	var data sql.NullString
+	err := s.db.QueryRowContext(ctx, `
+		SELECT sentences FROM pages
+		WHERE document_id = ? AND source_page_number = ?
+	`, docID, sourcePageNum).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("page %w: %s source page %s", ErrNotFound, docID, sourcePageNum)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query page sentences: %w", err)
+	}
+	return decodeSentences(data)
+}
+
+// pageSentences retrieves the sentence offsets of each of a document's pages,
+// or nil if none were recorded
+func (s *SQLiteStore) pageSentences(ctx context.Context, docID string) ([][]models.TextSpan, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT sentences FROM pages
+		WHERE document_id = ?
+		ORDER BY page_number
+	`, docID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query page sentences: %w", err)
+	}
+	defer rows.Close()
+
+	var all [][]models.TextSpan
+	recorded := false
+	for rows.Next() {
+		var data sql.NullString
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to scan page sentences: %w", err)
+		}
+		sentences, err := decodeSentences(data)
+		if err != nil {
+			return nil, err
+		}
+		recorded = recorded || sentences != nil
+		all = append(all, sentences)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating page sentences: %w", err)
+	}
+	if !recorded {
+		return nil, nil
+	}
+	return all, nil
+}
+
+// decodeSentences decodes a page's sentences column
+func decodeSentences(data sql.NullString) ([]models.TextSpan, error) {
+	if !data.Valid {
+		return nil, nil
+	}
+	sentences := []models.TextSpan{}
+	if err := json.Unmarshal([]byte(data.String), &sentences); err != nil {
+		return nil, fmt.Errorf("failed to decode page sentences: %w", err)
+	}
+	return sentences, nil
 }
 
 // GetPageMapping returns a map of source page numbers to sequential page numbers
@@ -399,6 +787,9 @@ func (s *SQLiteStore) GetPages(ctx context.Context, docID string) ([]string, err
 		if err := rows.Scan(&content); err != nil {
 			return nil, fmt.Errorf("failed to scan page: %w", err)
 		}
+		if err := s.cipher.openAll("pages.content", docID, &content); err != nil {
+			return nil, err
+		}
 		pages = append(pages, content)
 	}
 
@@ -409,12 +800,27 @@ func (s *SQLiteStore) GetPages(ctx context.Context, docID string) ([]string, err
 	return pages, nil
 }
 
-// GetReferences retrieves all references for a document
+// referenceColumns are the columns scanned by scanReference, from
+// document_references r joined by referenceLinkJoin
+const referenceColumns = `r.reference_text, r.doi, r.page_number, r.page_index,
+	COALESCE(l.cited_document_id, ''), COALESCE(d.citekey, '')`
+
+// referenceLinkJoin joins a reference to the stored document it cites, if any
+const referenceLinkJoin = `LEFT JOIN reference_links l ON l.document_id = r.document_id AND l.ref_index = r.ref_index
+		LEFT JOIN documents d ON d.id = l.cited_document_id`
+
+func scanReference(row interface{ Scan(...any) error }, ref *models.Reference) error {
+	return row.Scan(&ref.ReferenceText, &ref.DOI, &ref.PageNumber, &ref.PageIndex, &ref.CitedDocumentID, &ref.Citekey)
+}
+
+// GetReferences retrieves all references for a document, with the stored
+// document each cites if it has been linked to one
 func (s *SQLiteStore) GetReferences(ctx context.Context, docID string) ([]models.Reference, error) {
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT reference_text, doi FROM document_references
-		WHERE document_id = ?
-		ORDER BY ref_index
+		SELECT `+referenceColumns+` FROM document_references r
+		`+referenceLinkJoin+`
+		WHERE r.document_id = ?
+		ORDER BY r.ref_index
 	`, docID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query references: %w", err)
@@ -424,7 +830,7 @@ func (s *SQLiteStore) GetReferences(ctx context.Context, docID string) ([]models
 	var references []models.Reference
 	for rows.Next() {
 		var ref models.Reference
-		if err := rows.Scan(&ref.ReferenceText, &ref.DOI); err != nil {
+		if err := scanReference(rows, &ref); err != nil {
 			return nil, fmt.Errorf("failed to scan reference: %w", err)
 		}
 		references = append(references, ref)
@@ -437,16 +843,18 @@ func (s *SQLiteStore) GetReferences(ctx context.Context, docID string) ([]models
 	return references, nil
 }
 
-// GetReference retrieves a specific reference by index (0-indexed)
+// GetReference retrieves a specific reference by index (0-indexed), with the
+// stored document it cites if it has been linked to one
 func (s *SQLiteStore) GetReference(ctx context.Context, docID string, refIndex int) (*models.Reference, error) {
 	var ref models.Reference
-	err := s.db.QueryRowContext(ctx, `
-		SELECT reference_text, doi FROM document_references
-		WHERE document_id = ? AND ref_index = ?
-	`, docID, refIndex).Scan(&ref.ReferenceText, &ref.DOI)
+	err := scanReference(s.db.QueryRowContext(ctx, `
+		SELECT `+referenceColumns+` FROM document_references r
+		`+referenceLinkJoin+`
+		WHERE r.document_id = ? AND r.ref_index = ?
+	`, docID, refIndex), &ref)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("reference not found: %s index %d", docID, refIndex)
+		return nil, fmt.Errorf("reference %w: %s index %d", ErrNotFound, docID, refIndex)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to query reference: %w", err)
@@ -455,10 +863,50 @@ func (s *SQLiteStore) GetReference(ctx context.Context, docID string, refIndex i
 	return &ref, nil
 }
 
+// GetReferencesBySourcePage retrieves the references that appear on a page,
+// identified by its source page number, with the stored document each cites
+func (s *SQLiteStore) GetReferencesBySourcePage(ctx context.Context, docID string, sourcePageNum string) ([]models.PageReference, error) {
+	var found bool
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) > 0 FROM pages WHERE document_id = ? AND source_page_number = ?
+	`, docID, sourcePageNum).Scan(&found)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query page: %w", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("page %w: %s source page %s", ErrNotFound, docID, sourcePageNum)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT r.ref_index, `+referenceColumns+` FROM document_references r
+		`+referenceLinkJoin+`
+		WHERE r.document_id = ? AND r.page_number = ?
+		ORDER BY r.ref_index
+	`, docID, sourcePageNum)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query references: %w", err)
+	}
+	defer rows.Close()
+
+	references := []models.PageReference{}
+	for rows.Next() {
+		var ref models.PageReference
+		err := rows.Scan(&ref.RefIndex, &ref.ReferenceText, &ref.DOI, &ref.PageNumber, &ref.PageIndex, &ref.CitedDocumentID, &ref.Citekey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan reference: %w", err)
+		}
+		references = append(references, ref)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating references: %w", err)
+	}
+	return references, nil
+}
+
 // GetImages retrieves all images for a document
 func (s *SQLiteStore) GetImages(ctx context.Context, docID string) ([]models.Image, error) {
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT image_url, image_description, caption FROM images
+		SELECT `+imageColumns+` FROM images
 		WHERE document_id = ?
 		ORDER BY image_index
 	`, docID)
@@ -469,11 +917,11 @@ func (s *SQLiteStore) GetImages(ctx context.Context, docID string) ([]models.Ima
 
 	var images []models.Image
 	for rows.Next() {
-		var img models.Image
-		if err := rows.Scan(&img.ImageURL, &img.ImageDescription, &img.Caption); err != nil {
+		img, err := scanImage(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan image: %w", err)
 		}
-		images = append(images, img)
+		images = append(images, *img)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -485,26 +933,25 @@ func (s *SQLiteStore) GetImages(ctx context.Context, docID string) ([]models.Ima
 
 // GetImage retrieves a specific image by index (0-indexed)
 func (s *SQLiteStore) GetImage(ctx context.Context, docID string, imageIndex int) (*models.Image, error) {
-	var img models.Image
-	err := s.db.QueryRowContext(ctx, `
-		SELECT image_url, image_description, caption FROM images
+	img, err := scanImage(s.db.QueryRowContext(ctx, `
+		SELECT `+imageColumns+` FROM images
 		WHERE document_id = ? AND image_index = ?
-	`, docID, imageIndex).Scan(&img.ImageURL, &img.ImageDescription, &img.Caption)
+	`, docID, imageIndex))
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("image not found: %s index %d", docID, imageIndex)
+		return nil, fmt.Errorf("image %w: %s index %d", ErrNotFound, docID, imageIndex)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to query image: %w", err)
 	}
 
-	return &img, nil
+	return img, nil
 }
 
 // GetTables retrieves all tables for a document
 func (s *SQLiteStore) GetTables(ctx context.Context, docID string) ([]models.Table, error) {
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT table_id, table_title, table_data FROM document_tables
+		SELECT table_id, table_title, table_data, table_columns, table_rows, parse_error, page_number, stable_id FROM document_tables
 		WHERE document_id = ?
 		ORDER BY table_index
 	`, docID)
@@ -516,9 +963,13 @@ func (s *SQLiteStore) GetTables(ctx context.Context, docID string) ([]models.Tab
 	var tables []models.Table
 	for rows.Next() {
 		var tbl models.Table
-		if err := rows.Scan(&tbl.TableID, &tbl.TableTitle, &tbl.TableData); err != nil {
+		var columns, tableRows string
+		if err := rows.Scan(&tbl.TableID, &tbl.TableTitle, &tbl.TableData, &columns, &tableRows, &tbl.ParseError, &tbl.PageNumber, &tbl.StableID); err != nil {
 			return nil, fmt.Errorf("failed to scan table: %w", err)
 		}
+		if err := decodeTableStructure(&tbl, columns, tableRows); err != nil {
+			return nil, err
+		}
 		tables = append(tables, tbl)
 	}
 
@@ -532,25 +983,55 @@ func (s *SQLiteStore) GetTables(ctx context.Context, docID string) ([]models.Tab
 // GetTable retrieves a specific table by index (0-indexed)
 func (s *SQLiteStore) GetTable(ctx context.Context, docID string, tableIndex int) (*models.Table, error) {
 	var tbl models.Table
+	var columns, rows string
 	err := s.db.QueryRowContext(ctx, `
-		SELECT table_id, table_title, table_data FROM document_tables
+		SELECT table_id, table_title, table_data, table_columns, table_rows, parse_error, page_number, stable_id FROM document_tables
 		WHERE document_id = ? AND table_index = ?
-	`, docID, tableIndex).Scan(&tbl.TableID, &tbl.TableTitle, &tbl.TableData)
+	`, docID, tableIndex).Scan(&tbl.TableID, &tbl.TableTitle, &tbl.TableData, &columns, &rows, &tbl.ParseError, &tbl.PageNumber, &tbl.StableID)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("table not found: %s index %d", docID, tableIndex)
+		return nil, fmt.Errorf("table %w: %s index %d", ErrNotFound, docID, tableIndex)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to query table: %w", err)
 	}
+	if err := decodeTableStructure(&tbl, columns, rows); err != nil {
+		return nil, err
+	}
 
 	return &tbl, nil
 }
 
+// encodeTableStructure returns a table's columns and rows as JSON, or empty
+// strings if the table was not parsed into them
+func encodeTableStructure(tbl models.Table) (string, string) {
+	if tbl.Columns == nil {
+		return "", ""
+	}
+	// String slices always marshal
+	columns, _ := json.Marshal(tbl.Columns)
+	rows, _ := json.Marshal(tbl.Rows)
+	return string(columns), string(rows)
+}
+
+// decodeTableStructure sets a table's columns and rows from their stored JSON
+func decodeTableStructure(tbl *models.Table, columns string, rows string) error {
+	if columns == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(columns), &tbl.Columns); err != nil {
+		return fmt.Errorf("failed to unmarshal table columns: %w", err)
+	}
+	if err := json.Unmarshal([]byte(rows), &tbl.Rows); err != nil {
+		return fmt.Errorf("failed to unmarshal table rows: %w", err)
+	}
+	return nil
+}
+
 // GetFootnotes retrieves all footnotes for a document
 func (s *SQLiteStore) GetFootnotes(ctx context.Context, docID string) ([]models.Footnote, error) {
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT marker, text, page_number, in_text_page FROM footnotes
+		SELECT marker, text, page_number, in_text_page, stable_id FROM footnotes
 		WHERE document_id = ?
 		ORDER BY footnote_index
 	`, docID)
@@ -562,9 +1043,12 @@ func (s *SQLiteStore) GetFootnotes(ctx context.Context, docID string) ([]models.
 	var footnotes []models.Footnote
 	for rows.Next() {
 		var fn models.Footnote
-		if err := rows.Scan(&fn.Marker, &fn.Text, &fn.PageNumber, &fn.InTextPage); err != nil {
+		if err := rows.Scan(&fn.Marker, &fn.Text, &fn.PageNumber, &fn.InTextPage, &fn.StableID); err != nil {
 			return nil, fmt.Errorf("failed to scan footnote: %w", err)
 		}
+		if err := s.cipher.openAll("footnotes.text", docID, &fn.Text); err != nil {
+			return nil, err
+		}
 		footnotes = append(footnotes, fn)
 	}
 
@@ -579,16 +1063,19 @@ func (s *SQLiteStore) GetFootnotes(ctx context.Context, docID string) ([]models.
 func (s *SQLiteStore) GetFootnote(ctx context.Context, docID string, footnoteIndex int) (*models.Footnote, error) {
 	var fn models.Footnote
 	err := s.db.QueryRowContext(ctx, `
-		SELECT marker, text, page_number, in_text_page FROM footnotes
+		SELECT marker, text, page_number, in_text_page, stable_id FROM footnotes
 		WHERE document_id = ? AND footnote_index = ?
-	`, docID, footnoteIndex).Scan(&fn.Marker, &fn.Text, &fn.PageNumber, &fn.InTextPage)
+	`, docID, footnoteIndex).Scan(&fn.Marker, &fn.Text, &fn.PageNumber, &fn.InTextPage, &fn.StableID)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("footnote not found: %s index %d", docID, footnoteIndex)
+		return nil, fmt.Errorf("footnote %w: %s index %d", ErrNotFound, docID, footnoteIndex)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to query footnote: %w", err)
 	}
+	if err := s.cipher.openAll("footnotes.text", docID, &fn.Text); err != nil {
+		return nil, err
+	}
 
 	return &fn, nil
 }
@@ -596,7 +1083,7 @@ func (s *SQLiteStore) GetFootnote(ctx context.Context, docID string, footnoteInd
 // GetEndnotes retrieves all endnotes for a document
 func (s *SQLiteStore) GetEndnotes(ctx context.Context, docID string) ([]models.Endnote, error) {
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT marker, text, page_number FROM endnotes
+		SELECT marker, note_key, text, page_number FROM endnotes
 		WHERE document_id = ?
 		ORDER BY endnote_index
 	`, docID)
@@ -608,9 +1095,12 @@ func (s *SQLiteStore) GetEndnotes(ctx context.Context, docID string) ([]models.E
 	var endnotes []models.Endnote
 	for rows.Next() {
 		var en models.Endnote
-		if err := rows.Scan(&en.Marker, &en.Text, &en.PageNumber); err != nil {
+		if err := rows.Scan(&en.Marker, &en.Key, &en.Text, &en.PageNumber); err != nil {
 			return nil, fmt.Errorf("failed to scan endnote: %w", err)
 		}
+		if err := s.cipher.openAll("endnotes.text", docID, &en.Text); err != nil {
+			return nil, err
+		}
 		endnotes = append(endnotes, en)
 	}
 
@@ -625,16 +1115,19 @@ func (s *SQLiteStore) GetEndnotes(ctx context.Context, docID string) ([]models.E
 func (s *SQLiteStore) GetEndnote(ctx context.Context, docID string, endnoteIndex int) (*models.Endnote, error) {
 	var en models.Endnote
 	err := s.db.QueryRowContext(ctx, `
-		SELECT marker, text, page_number FROM endnotes
+		SELECT marker, note_key, text, page_number FROM endnotes
 		WHERE document_id = ? AND endnote_index = ?
-	`, docID, endnoteIndex).Scan(&en.Marker, &en.Text, &en.PageNumber)
+	`, docID, endnoteIndex).Scan(&en.Marker, &en.Key, &en.Text, &en.PageNumber)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("endnote not found: %s index %d", docID, endnoteIndex)
+		return nil, fmt.Errorf("endnote %w: %s index %d", ErrNotFound, docID, endnoteIndex)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to query endnote: %w", err)
 	}
+	if err := s.cipher.openAll("endnotes.text", docID, &en.Text); err != nil {
+		return nil, err
+	}
 
 	return &en, nil
 }
@@ -642,7 +1135,8 @@ func (s *SQLiteStore) GetEndnote(ctx context.Context, docID string, endnoteIndex
 // GetQuotations retrieves all quotations for a document
 func (s *SQLiteStore) GetQuotations(ctx context.Context, docID string) ([]models.Quotation, error) {
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT quotation_text, page_number, context, relevance FROM quotations
+		SELECT quotation_text, page_number, context, relevance, verified, match_score, span_start, span_end, page_hash,
+			selected, priority_rank FROM quotations
 		WHERE document_id = ?
 		ORDER BY quotation_index
 	`, docID)
@@ -654,9 +1148,14 @@ func (s *SQLiteStore) GetQuotations(ctx context.Context, docID string) ([]models
 	var quotations []models.Quotation
 	for rows.Next() {
 		var q models.Quotation
-		if err := rows.Scan(&q.QuotationText, &q.PageNumber, &q.Context, &q.Relevance); err != nil {
+		var spanStart, spanEnd sql.NullInt64
+		if err := rows.Scan(&q.QuotationText, &q.PageNumber, &q.Context, &q.Relevance, &q.Verified, &q.MatchScore, &spanStart, &spanEnd, &q.PageHash, &q.Selected, &q.Rank); err != nil {
 			return nil, fmt.Errorf("failed to scan quotation: %w", err)
 		}
+		q.Span = quotationSpan(spanStart, spanEnd)
+		if err := s.openQuotation(docID, &q); err != nil {
+			return nil, err
+		}
 		quotations = append(quotations, q)
 	}
 
@@ -670,28 +1169,126 @@ func (s *SQLiteStore) GetQuotations(ctx context.Context, docID string) ([]models
 // GetQuotation retrieves a specific quotation by index (0-indexed)
 func (s *SQLiteStore) GetQuotation(ctx context.Context, docID string, quotationIndex int) (*models.Quotation, error) {
 	var q models.Quotation
+	var spanStart, spanEnd sql.NullInt64
 	err := s.db.QueryRowContext(ctx, `
-		SELECT quotation_text, page_number, context, relevance FROM quotations
+		SELECT quotation_text, page_number, context, relevance, verified, match_score, span_start, span_end, page_hash,
+			selected, priority_rank FROM quotations
 		WHERE document_id = ? AND quotation_index = ?
-	`, docID, quotationIndex).Scan(&q.QuotationText, &q.PageNumber, &q.Context, &q.Relevance)
+	`, docID, quotationIndex).Scan(&q.QuotationText, &q.PageNumber, &q.Context, &q.Relevance, &q.Verified, &q.MatchScore, &spanStart, &spanEnd, &q.PageHash, &q.Selected, &q.Rank)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("quotation not found: %s index %d", docID, quotationIndex)
+		return nil, fmt.Errorf("quotation %w: %s index %d", ErrNotFound, docID, quotationIndex)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to query quotation: %w", err)
 	}
+	q.Span = quotationSpan(spanStart, spanEnd)
+	if err := s.openQuotation(docID, &q); err != nil {
+		return nil, err
+	}
 
 	return &q, nil
 }
 
-// ListDocuments returns a list of all stored document IDs with their metadata
-func (s *SQLiteStore) ListDocuments(ctx context.Context) ([]models.DocumentInfo, error) {
+// SelectQuotations marks the quotations of a document at indexes (0-indexed)
+// selected, as when a request for more quotations promotes them (see
+// llm.PromoteQuotations)
+func (s *SQLiteStore) SelectQuotations(ctx context.Context, docID string, indexes []int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, index := range indexes {
+		result, err := tx.ExecContext(ctx, `UPDATE quotations SET selected = 1 WHERE document_id = ? AND quotation_index = ?`, docID, index)
+		if err != nil {
+			return fmt.Errorf("failed to select quotation %d: %w", index, err)
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return fmt.Errorf("failed to select quotation %d: %w", index, err)
+		} else if n == 0 {
+			return fmt.Errorf("quotation %w: %s index %d", ErrNotFound, docID, index)
+		}
+	}
+	return tx.Commit()
+}
+
+// quotationSpan returns the span stored for a quotation, or nil if it has none
+func quotationSpan(start, end sql.NullInt64) *models.TextSpan {
+	if !start.Valid || !end.Valid {
+		return nil
+	}
+	return &models.TextSpan{Start: int(start.Int64), End: int(end.Int64)}
+}
+
+// openQuotation decrypts the text and context of a quotation read from an encrypted database
+func (s *SQLiteStore) openQuotation(docID string, q *models.Quotation) error {
+	if err := s.cipher.openAll("quotations.quotation_text", docID, &q.QuotationText); err != nil {
+		return err
+	}
+	return s.cipher.openAll("quotations.context", docID, &q.Context)
+}
+
+// GetSections retrieves the section index for a document
+func (s *SQLiteStore) GetSections(ctx context.Context, docID string) ([]models.Section, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT title, level, start_page, end_page, start_page_index, end_page_index, start_offset, end_offset
+		FROM sections
+		WHERE document_id = ?
+		ORDER BY section_index
+	`, docID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sections: %w", err)
+	}
+	defer rows.Close()
+
+	var sections []models.Section
+	for rows.Next() {
+		var sec models.Section
+		if err := rows.Scan(&sec.Title, &sec.Level, &sec.StartPage, &sec.EndPage,
+			&sec.StartPageIndex, &sec.EndPageIndex, &sec.StartOffset, &sec.EndOffset); err != nil {
+			return nil, fmt.Errorf("failed to scan section: %w", err)
+		}
+		sections = append(sections, sec)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sections: %w", err)
+	}
+
+	return sections, nil
+}
+
+// GetSection retrieves a specific section by index (0-indexed)
+func (s *SQLiteStore) GetSection(ctx context.Context, docID string, sectionIndex int) (*models.Section, error) {
+	var sec models.Section
+	err := s.db.QueryRowContext(ctx, `
+		SELECT title, level, start_page, end_page, start_page_index, end_page_index, start_offset, end_offset
+		FROM sections
+		WHERE document_id = ? AND section_index = ?
+	`, docID, sectionIndex).Scan(&sec.Title, &sec.Level, &sec.StartPage, &sec.EndPage,
+		&sec.StartPageIndex, &sec.EndPageIndex, &sec.StartOffset, &sec.EndOffset)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("section %w: %s index %d", ErrNotFound, docID, sectionIndex)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query section: %w", err)
+	}
+
+	return &sec, nil
+}
+
+// ListDocuments returns a list of all stored document IDs with their metadata,
+// in library, or in every library if library is empty
+func (s *SQLiteStore) ListDocuments(ctx context.Context, library string) ([]models.DocumentInfo, error) {
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, title, authors, doi, zotero_id, url
+		SELECT `+documentInfoColumns+`
 		FROM documents
+		WHERE ? = '' OR library = ?
 		ORDER BY created_at DESC
-	`)
+	`, library, library)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query documents: %w", err)
 	}
@@ -700,16 +1297,9 @@ func (s *SQLiteStore) ListDocuments(ctx context.Context) ([]models.DocumentInfo,
 	var documents []models.DocumentInfo
 	for rows.Next() {
 		var doc models.DocumentInfo
-		var authorsJSON string
-		if err := rows.Scan(&doc.DocumentID, &doc.Title, &authorsJSON, &doc.DOI,
-			&doc.SourceInfo.ZoteroID, &doc.SourceInfo.URL); err != nil {
-			return nil, fmt.Errorf("failed to scan document: %w", err)
-		}
-
-		if err := json.Unmarshal([]byte(authorsJSON), &doc.Authors); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal authors: %w", err)
+		if err := scanDocumentInfo(rows, &doc); err != nil {
+			return nil, err
 		}
-
 		documents = append(documents, doc)
 	}
 
@@ -717,12 +1307,26 @@ func (s *SQLiteStore) ListDocuments(ctx context.Context) ([]models.DocumentInfo,
 		return nil, fmt.Errorf("error iterating documents: %w", err)
 	}
 
+	tags, err := s.tagsByDocument(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range documents {
+		documents[i].Tags = tags[documents[i].DocumentID]
+	}
+
 	return documents, nil
 }
 
 // DeleteDocument removes a document and all associated data
 func (s *SQLiteStore) DeleteDocument(ctx context.Context, docID string) error {
-	result, err := s.db.ExecContext(ctx, `DELETE FROM documents WHERE id = ?`, docID)
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM documents WHERE id = ?`, docID)
 	if err != nil {
 		return fmt.Errorf("failed to delete document: %w", err)
 	}
@@ -733,9 +1337,41 @@ func (s *SQLiteStore) DeleteDocument(ctx context.Context, docID string) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("document not found: %s", docID)
+		return fmt.Errorf("document %w: %s", ErrNotFound, docID)
 	}
 
+	// Usage, source, annotation, image data, reference link, summary, and fetch
+	// info rows are not removed by the foreign key cascade (see migrations 13,
+	// 14, 18, 21, 27, 28, 40, and 41)
+	if _, err := tx.ExecContext(ctx, `DELETE FROM usage WHERE document_id = ?`, docID); err != nil {
+		return fmt.Errorf("failed to delete usage: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM document_sources WHERE document_id = ?`, docID); err != nil {
+		return fmt.Errorf("failed to delete document sources: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM annotations WHERE document_id = ?`, docID); err != nil {
+		return fmt.Errorf("failed to delete annotations: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM image_blobs WHERE document_id = ?`, docID); err != nil {
+		return fmt.Errorf("failed to delete image data: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM reference_links WHERE document_id = ? OR cited_document_id = ?`, docID, docID); err != nil {
+		return fmt.Errorf("failed to delete reference links: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM summaries WHERE document_id = ?`, docID); err != nil {
+		return fmt.Errorf("failed to delete summaries: %w", err)
+	}
+	if err := clearSectionSummaries(ctx, tx, docID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM document_fetch_info WHERE document_id = ?`, docID); err != nil {
+		return fmt.Errorf("failed to delete fetch info: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	s.related.invalidate()
 	return nil
 }
 
@@ -751,6 +1387,34 @@ func (s *SQLiteStore) DocumentExists(ctx context.Context, docID string) (bool, e
 	return exists, nil
 }
 
+// IsPartial reports whether a document was parsed for its metadata only
+func (s *SQLiteStore) IsPartial(ctx context.Context, docID string) (bool, error) {
+	var partial bool
+	err := s.db.QueryRowContext(ctx, `SELECT partial FROM documents WHERE id = ?`, docID).Scan(&partial)
+	if err == sql.ErrNoRows {
+		return false, fmt.Errorf("document %w: %s", ErrNotFound, docID)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to query partial flag: %w", err)
+	}
+	return partial, nil
+}
+
+// GetPageNumbering returns how a document's page numbers were assigned,
+// models.PageNumberingSource or models.PageNumberingSequential, or "" if they
+// were not validated
+func (s *SQLiteStore) GetPageNumbering(ctx context.Context, docID string) (string, error) {
+	var numbering string
+	err := s.db.QueryRowContext(ctx, `SELECT page_numbering FROM documents WHERE id = ?`, docID).Scan(&numbering)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("document %w: %s", ErrNotFound, docID)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to query page numbering: %w", err)
+	}
+	return numbering, nil
+}
+
 // GetParsedItem retrieves a complete ParsedItem for a document by ID
 func (s *SQLiteStore) GetParsedItem(ctx context.Context, docID string) (*models.ParsedItem, error) {
 	// Get metadata
@@ -815,33 +1479,217 @@ func (s *SQLiteStore) GetParsedItem(ctx context.Context, docID string) (*models.
 		return nil, fmt.Errorf("failed to get quotations: %w", err)
 	}
 
+	// Get sections
+	sections, err := s.GetSections(ctx, docID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sections: %w", err)
+	}
+
 	// Get summary
 	summary, err := s.GetSummary(ctx, docID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get summary: %w", err)
 	}
 
+	// Get parse details
+	var isScanned, partial, basic bool
+	var chunkCount int
+	var pageNumbering, pdfURL, contentHash string
+	err = s.db.QueryRowContext(ctx, `SELECT is_scanned, chunk_count, page_numbering, pdf_url, content_hash, partial, basic FROM documents WHERE id = ?`, docID).Scan(&isScanned, &chunkCount, &pageNumbering, &pdfURL, &contentHash, &partial, &basic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parse details: %w", err)
+	}
+	pageQuality, err := s.GetPageQuality(ctx, docID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page quality: %w", err)
+	}
+	provenance, err := s.GetProvenance(ctx, docID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get provenance: %w", err)
+	}
+	fullText, pageOffsets, err := s.GetFullText(ctx, docID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get full text: %w", err)
+	}
+	pageSentences, err := s.pageSentences(ctx, docID)
+	if err != nil {
+		return nil, err
+	}
+	fetch, err := s.fetchInfo(ctx, docID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Construct and return ParsedItem
 	return &models.ParsedItem{
-		Metadata:    *metadata,
-		Pages:       pages,
-		PageNumbers: pageNumbers,
-		References:  references,
-		Images:      images,
-		Tables:      tables,
-		Footnotes:   footnotes,
-		Endnotes:    endnotes,
-		Quotations:  quotations,
-		Summary:     summary,
+		Metadata:      *metadata,
+		Pages:         pages,
+		PageNumbers:   pageNumbers,
+		References:    references,
+		Images:        images,
+		Tables:        tables,
+		Footnotes:     footnotes,
+		Endnotes:      endnotes,
+		Quotations:    quotations,
+		Sections:      sections,
+		FullText:      fullText,
+		PageOffsets:   pageOffsets,
+		PageSentences: pageSentences,
+		Summary:       summary,
+		ChunkCount:    chunkCount,
+		PageNumbering: pageNumbering,
+		PDFURL:        pdfURL,
+		ContentHash:   contentHash,
+		Partial:       partial,
+		Basic:         basic,
+		IsScanned:     isScanned,
+		PageQuality:   pageQuality,
+		Provenance:    provenance,
+		Fetch:         fetch,
 	}, nil
 }
 
-// GetCitekeyMap retrieves all docID→citekey mappings
-func (s *SQLiteStore) GetCitekeyMap(ctx context.Context) (map[string]string, error) {
+// documentInfoColumns are the documents columns scanned by scanDocumentInfo
+const documentInfoColumns = `id, library, title, authors, keywords, COALESCE(publication_date, ''), COALESCE(publication, ''), doi,
+	COALESCE(item_type, ''), language, COALESCE(citekey, ''), partial, basic, zotero_id, url,
+	funding_statement != '', acknowledgments != '', data_availability != '', ` + provenanceColumns
+
+// scanDocumentInfo scans documentInfoColumns, followed by any extra columns
+// selected after them, into doc and extra
+func scanDocumentInfo(row interface{ Scan(...any) error }, doc *models.DocumentInfo, extra ...any) error {
+	var authorsJSON, keywordsJSON string
+	var statements [3]bool
+	dest := []any{&doc.DocumentID, &doc.Library, &doc.Title, &authorsJSON, &keywordsJSON, &doc.PublicationDate, &doc.Publication,
+		&doc.DOI, &doc.ItemType, &doc.Language, &doc.Citekey, &doc.Partial, &doc.Basic, &doc.SourceInfo.ZoteroID, &doc.SourceInfo.URL,
+		&statements[0], &statements[1], &statements[2],
+		&doc.Provenance.CreatedAt, &doc.Provenance.UpdatedAt, &doc.Provenance.ParsedModel, &doc.Provenance.PromptVersion,
+		&doc.Provenance.ParserVersion}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return fmt.Errorf("failed to scan document: %w", err)
+	}
+	if err := json.Unmarshal([]byte(authorsJSON), &doc.Authors); err != nil {
+		return fmt.Errorf("failed to unmarshal authors: %w", err)
+	}
+	keywords, err := unmarshalKeywords(keywordsJSON)
+	if err != nil {
+		return err
+	}
+	doc.Keywords = keywords
+	// In the order of models.Statements
+	for i, present := range statements {
+		if present {
+			doc.Statements = append(doc.Statements, models.Statements[i])
+		}
+	}
+	return nil
+}
+
+// provenanceColumns are the documents columns scanned by scanProvenance
+const provenanceColumns = `COALESCE(created_at, ''), COALESCE(updated_at, ''), parsed_model, prompt_version, parser_version`
+
+func scanProvenance(row interface{ Scan(...any) error }, provenance *models.Provenance) error {
+	return row.Scan(&provenance.CreatedAt, &provenance.UpdatedAt, &provenance.ParsedModel, &provenance.PromptVersion, &provenance.ParserVersion)
+}
+
+// GetProvenance retrieves when a document was stored and how it was parsed
+func (s *SQLiteStore) GetProvenance(ctx context.Context, docID string) (*models.Provenance, error) {
+	var provenance models.Provenance
+	err := scanProvenance(s.db.QueryRowContext(ctx, `SELECT `+provenanceColumns+` FROM documents WHERE id = ?`, docID), &provenance)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("document %w: %s", ErrNotFound, docID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query provenance: %w", err)
+	}
+	return &provenance, nil
+}
+
+// GetFullText retrieves a document's continuous text and the byte offset in it
+// at which each page begins. Both are empty for documents stored before full
+// text was recorded.
+func (s *SQLiteStore) GetFullText(ctx context.Context, docID string) (string, []int, error) {
+	var fullText string
+	err := s.db.QueryRowContext(ctx, `SELECT full_text FROM documents WHERE id = ?`, docID).Scan(&fullText)
+	if err == sql.ErrNoRows {
+		return "", nil, fmt.Errorf("document %w: %s", ErrNotFound, docID)
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to query full text: %w", err)
+	}
+	if fullText, err = s.cipher.open("documents.full_text", docID, fullText); err != nil {
+		return "", nil, err
+	}
+	if fullText == "" {
+		return "", nil, nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT full_text_offset FROM pages
+		WHERE document_id = ?
+		ORDER BY page_number
+	`, docID)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to query page offsets: %w", err)
+	}
+	defer rows.Close()
+
+	var offsets []int
+	for rows.Next() {
+		var offset int
+		if err := rows.Scan(&offset); err != nil {
+			return "", nil, fmt.Errorf("failed to scan page offset: %w", err)
+		}
+		offsets = append(offsets, offset)
+	}
+	if err := rows.Err(); err != nil {
+		return "", nil, fmt.Errorf("error iterating page offsets: %w", err)
+	}
+	return fullText, offsets, nil
+}
+
+// GetPageQuality retrieves the per-page quality flags for a document.
+// Returns nil when no page was flagged or assessed, which is the case for all
+// non-PDF documents.
+func (s *SQLiteStore) GetPageQuality(ctx context.Context, docID string) ([]models.PageQuality, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT is_scanned, near_empty, content_confidence, is_blank, is_cover, is_references_only, degraded FROM pages
+		WHERE document_id = ?
+		ORDER BY page_number
+	`, docID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var quality []models.PageQuality
+	flagged := false
+	for rows.Next() {
+		var q models.PageQuality
+		if err := rows.Scan(&q.IsScanned, &q.NearEmpty, &q.ContentConfidence, &q.IsBlank, &q.IsCover, &q.IsReferencesOnly, &q.Degraded); err != nil {
+			return nil, err
+		}
+		if q != (models.PageQuality{}) {
+			flagged = true
+		}
+		quality = append(quality, q)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if !flagged {
+		return nil, nil
+	}
+	return quality, nil
+}
+
+// GetCitekeyMap retrieves the docID→citekey mappings of library, or of every
+// library if library is empty
+func (s *SQLiteStore) GetCitekeyMap(ctx context.Context, library string) (map[string]string, error) {
 	rows, err := s.db.QueryContext(ctx, `
 		SELECT id, citekey FROM documents
-		WHERE citekey IS NOT NULL AND citekey != ''
-	`)
+		WHERE citekey IS NOT NULL AND citekey != '' AND (? = '' OR library = ?)
+	`, library, library)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query citekey map: %w", err)
 	}
@@ -863,16 +1711,18 @@ func (s *SQLiteStore) GetCitekeyMap(ctx context.Context) (map[string]string, err
 	return citekeyMap, nil
 }
 
-// GetDocumentByCitekey retrieves a document ID by its citekey
-func (s *SQLiteStore) GetDocumentByCitekey(ctx context.Context, citekey string) (string, error) {
+// GetDocumentByCitekey retrieves a document ID by its citekey in library, or
+// the earliest document with the citekey in any library if library is empty
+func (s *SQLiteStore) GetDocumentByCitekey(ctx context.Context, library string, citekey string) (string, error) {
 	var docID string
 	err := s.db.QueryRowContext(ctx, `
 		SELECT id FROM documents
-		WHERE citekey = ?
-	`, citekey).Scan(&docID)
+		WHERE citekey = ? AND (? = '' OR library = ?)
+		ORDER BY created_at, id LIMIT 1
+	`, citekey, library, library).Scan(&docID)
 
 	if err == sql.ErrNoRows {
-		return "", fmt.Errorf("document not found with citekey: %s", citekey)
+		return "", fmt.Errorf("document %w with citekey: %s", ErrNotFound, citekey)
 	}
 	if err != nil {
 		return "", fmt.Errorf("failed to query document by citekey: %w", err)
@@ -881,6 +1731,232 @@ func (s *SQLiteStore) GetDocumentByCitekey(ctx context.Context, citekey string)
 	return docID, nil
 }
 
+// SetCitekey replaces a document's citekey
+func (s *SQLiteStore) SetCitekey(ctx context.Context, docID string, citekey string) error {
+	result, err := s.db.ExecContext(ctx, `UPDATE documents SET citekey = ? WHERE id = ?`, nullIfEmpty(citekey), docID)
+	if err != nil {
+		return fmt.Errorf("failed to update citekey: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to update citekey: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("document %w: %s", ErrNotFound, docID)
+	}
+	s.related.invalidate()
+	return nil
+}
+
+// SetCitekeys replaces the citekeys of several documents in one transaction.
+// Their current citekeys are cleared first, so a new citekey may be one another
+// of the documents is giving up.
+func (s *SQLiteStore) SetCitekeys(ctx context.Context, citekeys map[string]string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for docID := range citekeys {
+		result, err := tx.ExecContext(ctx, `UPDATE documents SET citekey = NULL WHERE id = ?`, docID)
+		if err != nil {
+			return fmt.Errorf("failed to clear citekey of %s: %w", docID, err)
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return fmt.Errorf("failed to clear citekey of %s: %w", docID, err)
+		} else if n == 0 {
+			return fmt.Errorf("document %w: %s", ErrNotFound, docID)
+		}
+	}
+	for docID, citekey := range citekeys {
+		if _, err := tx.ExecContext(ctx, `UPDATE documents SET citekey = ? WHERE id = ?`, nullIfEmpty(citekey), docID); err != nil {
+			return fmt.Errorf("failed to update citekey of %s: %w", docID, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit citekeys: %w", err)
+	}
+	s.related.invalidate()
+	return nil
+}
+
+// CountDocuments returns the number of stored documents
+func (s *SQLiteStore) CountDocuments(ctx context.Context) (int, error) {
+	var count int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM documents`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count documents: %w", err)
+	}
+	return count, nil
+}
+
+// GetLibraryStats computes aggregate statistics across the documents of
+// library, or all stored documents if library is empty. Only aggregate queries
+// are used so page content is never loaded.
+func (s *SQLiteStore) GetLibraryStats(ctx context.Context, library string, topAuthors int) (*models.LibraryStats, error) {
+	stats := &models.LibraryStats{}
+
+	err := s.db.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*),
+			COALESCE(SUM(CASE WHEN doi IS NULL OR doi = '' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN citekey IS NULL OR citekey = '' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN NOT EXISTS (
+				SELECT 1 FROM summaries WHERE summaries.document_id = documents.id AND style = 'standard'
+			) THEN 1 ELSE 0 END), 0)
+		FROM documents
+		WHERE ? = '' OR library = ?
+	`, library, library).Scan(&stats.DocumentCount, &stats.MissingDOI, &stats.MissingCitekey, &stats.MissingSummary)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query document counts: %w", err)
+	}
+
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM pages WHERE `+libraryDocumentFilter, library, library).Scan(&stats.TotalPages); err != nil {
+		return nil, fmt.Errorf("failed to count pages: %w", err)
+	}
+
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM quotations WHERE selected = 1 AND `+libraryDocumentFilter, library, library).Scan(&stats.TotalQuotations); err != nil {
+		return nil, fmt.Errorf("failed to count quotations: %w", err)
+	}
+
+	stats.DocumentsByYear, stats.UndatedDocuments, err = s.getDocumentsByYear(ctx, library)
+	if err != nil {
+		return nil, err
+	}
+
+	if topAuthors > 0 {
+		stats.TopAuthors, err = s.getTopAuthors(ctx, library, topAuthors)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return stats, nil
+}
+
+// libraryDocumentFilter restricts the rows of a table with a document_id
+// column to the documents of a library, given twice, or to all of them if it
+// is empty
+const libraryDocumentFilter = `(? = '' OR document_id IN (SELECT id FROM documents WHERE library = ?))`
+
+// getDocumentsByYear counts documents per publication year, returning the counts
+// sorted by year along with the number of documents without a recognizable year.
+// Publication dates are free-form ("2020", "2020-01-15", "January 2020"), so rows
+// are grouped by the raw value and bucketed into years afterwards.
+func (s *SQLiteStore) getDocumentsByYear(ctx context.Context, library string) ([]models.YearCount, int, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT COALESCE(publication_date, ''), COUNT(*)
+		FROM documents
+		WHERE ? = '' OR library = ?
+		GROUP BY publication_date
+	`, library, library)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query publication dates: %w", err)
+	}
+	defer rows.Close()
+
+	yearCounts := make(map[string]int)
+	undated := 0
+	for rows.Next() {
+		var pubDate string
+		var count int
+		if err := rows.Scan(&pubDate, &count); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan publication date: %w", err)
+		}
+		year := citations.ExtractYear(pubDate)
+		if year == "" {
+			undated += count
+			continue
+		}
+		yearCounts[year] += count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating publication dates: %w", err)
+	}
+
+	var byYear []models.YearCount
+	for year, count := range yearCounts {
+		byYear = append(byYear, models.YearCount{Year: year, Count: count})
+	}
+	sort.Slice(byYear, func(i, j int) bool {
+		return byYear[i].Year < byYear[j].Year
+	})
+
+	return byYear, undated, nil
+}
+
+// RecordUsage adds the OpenAI usage of an operation on a document to the totals
+// stored for it, so repeated operations accumulate
+func (s *SQLiteStore) RecordUsage(ctx context.Context, docID string, operation string, usage []models.TokenUsage) error {
+	if len(usage) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = insertRows(ctx, tx, "usage", `
+		INSERT INTO usage (document_id, operation, model, requests, input_tokens, output_tokens)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (document_id, operation, model) DO UPDATE SET
+			requests = requests + excluded.requests,
+			input_tokens = input_tokens + excluded.input_tokens,
+			output_tokens = output_tokens + excluded.output_tokens,
+			updated_at = CURRENT_TIMESTAMP
+	`, len(usage), func(i int) []any {
+		u := usage[i]
+		return []any{docID, operation, u.Model, u.Requests, u.InputTokens, u.OutputTokens}
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetUsage returns the stored OpenAI usage of a document, or of the whole library
+// if docID is empty, totalled by operation and model
+func (s *SQLiteStore) GetUsage(ctx context.Context, docID string) ([]models.TokenUsage, error) {
+	return s.queryUsage(ctx, `? = '' OR document_id = ?`, docID, docID)
+}
+
+// GetLibraryUsage returns the stored OpenAI usage of the documents in library,
+// or of every library if library is empty, totalled by operation and model
+func (s *SQLiteStore) GetLibraryUsage(ctx context.Context, library string) ([]models.TokenUsage, error) {
+	return s.queryUsage(ctx, libraryDocumentFilter, library, library)
+}
+
+// queryUsage totals the usage rows matching the where clause by operation and
+// model
+func (s *SQLiteStore) queryUsage(ctx context.Context, where string, args ...any) ([]models.TokenUsage, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT operation, model, SUM(requests), SUM(input_tokens), SUM(output_tokens)
+		FROM usage
+		WHERE `+where+`
+		GROUP BY operation, model
+		ORDER BY operation, model
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage: %w", err)
+	}
+	defer rows.Close()
+
+	var usage []models.TokenUsage
+	for rows.Next() {
+		var u models.TokenUsage
+		if err := rows.Scan(&u.Operation, &u.Model, &u.Requests, &u.InputTokens, &u.OutputTokens); err != nil {
+			return nil, fmt.Errorf("failed to scan usage: %w", err)
+		}
+		usage = append(usage, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating usage: %w", err)
+	}
+
+	return usage, nil
+}
+
 // Close closes the database connection
 func (s *SQLiteStore) Close() error {
 	if s.db != nil {
@@ -0,0 +1,1198 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// newTestStore creates an in-memory SQLite store for tests
+func newTestStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+	store, err := NewSQLiteStore(":memory:", logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// syntheticItem builds a ParsedItem with n of each child element
+func syntheticItem(n int) *models.ParsedItem {
+	item := &models.ParsedItem{
+		Metadata: models.ItemMetadata{
+			Title:   fmt.Sprintf("Synthetic document with %d elements", n),
+			Authors: []string{"Smith, John"},
+		},
+	}
+	for i := 0; i < n; i++ {
+		item.Pages = append(item.Pages, fmt.Sprintf("Page %d content", i+1))
+		item.PageNumbers = append(item.PageNumbers, fmt.Sprintf("%d", i+1))
+		item.References = append(item.References, models.Reference{ReferenceText: fmt.Sprintf("Reference %d", i)})
+		item.Images = append(item.Images, models.Image{Caption: fmt.Sprintf("Figure %d", i)})
+		item.Tables = append(item.Tables, models.Table{TableID: fmt.Sprintf("Table %d", i)})
+		item.Footnotes = append(item.Footnotes, models.Footnote{Marker: fmt.Sprintf("%d", i+1), Text: "Footnote"})
+		item.Endnotes = append(item.Endnotes, models.Endnote{Marker: fmt.Sprintf("%d", i+1), Text: "Endnote"})
+		item.Quotations = append(item.Quotations, models.Quotation{QuotationText: fmt.Sprintf("Quotation %d", i)})
+		item.Sections = append(item.Sections, models.Section{Title: fmt.Sprintf("Section %d", i), Level: 2})
+	}
+	return item
+}
+
+func TestStoreParsedItem_RestoreWithFewerElements(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	sourceInfo := &models.SourceInfo{URL: "https://example.com/paper.pdf"}
+
+	if err := store.StoreParsedItem(ctx, "doc-1", syntheticItem(20), sourceInfo); err != nil {
+		t.Fatalf("StoreParsedItem (large) failed: %v", err)
+	}
+
+	smaller := syntheticItem(12)
+	if err := store.StoreParsedItem(ctx, "doc-1", smaller, sourceInfo); err != nil {
+		t.Fatalf("StoreParsedItem (small) failed: %v", err)
+	}
+
+	got, err := store.GetParsedItem(ctx, "doc-1")
+	if err != nil {
+		t.Fatalf("GetParsedItem failed: %v", err)
+	}
+
+	counts := []struct {
+		name string
+		got  int
+		want int
+	}{
+		{"pages", len(got.Pages), len(smaller.Pages)},
+		{"page numbers", len(got.PageNumbers), len(smaller.PageNumbers)},
+		{"references", len(got.References), len(smaller.References)},
+		{"images", len(got.Images), len(smaller.Images)},
+		{"tables", len(got.Tables), len(smaller.Tables)},
+		{"footnotes", len(got.Footnotes), len(smaller.Footnotes)},
+		{"endnotes", len(got.Endnotes), len(smaller.Endnotes)},
+		{"quotations", len(got.Quotations), len(smaller.Quotations)},
+		{"sections", len(got.Sections), len(smaller.Sections)},
+	}
+	for _, c := range counts {
+		t.Run(c.name, func(t *testing.T) {
+			if c.got != c.want {
+				t.Errorf("Expected %d %s, got %d", c.want, c.name, c.got)
+			}
+		})
+	}
+
+	mapping, err := store.GetPageMapping(ctx, "doc-1")
+	if err != nil {
+		t.Fatalf("GetPageMapping failed: %v", err)
+	}
+	if _, ok := mapping["20"]; ok {
+		t.Error("Expected stale page 20 to be removed from page mapping")
+	}
+}
+
+func TestStoreParsedItem_RestoreDoesNotAffectOtherDocuments(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	sourceInfo := &models.SourceInfo{}
+
+	if err := store.StoreParsedItem(ctx, "doc-1", syntheticItem(5), sourceInfo); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	if err := store.StoreParsedItem(ctx, "doc-2", syntheticItem(7), sourceInfo); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	if err := store.StoreParsedItem(ctx, "doc-1", syntheticItem(2), sourceInfo); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+
+	pages, err := store.GetPages(ctx, "doc-2")
+	if err != nil {
+		t.Fatalf("GetPages failed: %v", err)
+	}
+	if len(pages) != 7 {
+		t.Errorf("Expected doc-2 to keep 7 pages, got %d", len(pages))
+	}
+}
+
+func TestGetLibraryStats(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	docs := []struct {
+		id   string
+		meta models.ItemMetadata
+	}{
+		{"doc-1", models.ItemMetadata{Title: "A", Authors: []string{"Smith, John", "Doe, Jane"}, PublicationDate: "2020-01-15", DOI: "10.1000/a", Citekey: "smith2020"}},
+		{"doc-2", models.ItemMetadata{Title: "B", Authors: []string{"Smith, John"}, PublicationDate: "2020"}},
+		{"doc-3", models.ItemMetadata{Title: "C", Authors: []string{"Brown, Alice"}, PublicationDate: "March 2018"}},
+		{"doc-4", models.ItemMetadata{Title: "D"}},
+	}
+	for _, d := range docs {
+		item := syntheticItem(3)
+		item.Metadata = d.meta
+		if d.id == "doc-1" {
+			item.Summary = "A summary"
+		}
+		if err := store.StoreParsedItem(ctx, d.id, item, &models.SourceInfo{}); err != nil {
+			t.Fatalf("StoreParsedItem failed: %v", err)
+		}
+	}
+
+	stats, err := store.GetLibraryStats(ctx, "", 2)
+	if err != nil {
+		t.Fatalf("GetLibraryStats failed: %v", err)
+	}
+
+	counts := []struct {
+		name string
+		got  int
+		want int
+	}{
+		{"documents", stats.DocumentCount, 4},
+		{"pages", stats.TotalPages, 12},
+		{"quotations", stats.TotalQuotations, 12},
+		{"missing doi", stats.MissingDOI, 3},
+		{"missing citekey", stats.MissingCitekey, 3},
+		{"missing summary", stats.MissingSummary, 3},
+		{"undated", stats.UndatedDocuments, 1},
+	}
+	for _, c := range counts {
+		t.Run(c.name, func(t *testing.T) {
+			if c.got != c.want {
+				t.Errorf("Expected %d %s, got %d", c.want, c.name, c.got)
+			}
+		})
+	}
+
+	t.Run("documents by year", func(t *testing.T) {
+		want := []models.YearCount{{Year: "2018", Count: 1}, {Year: "2020", Count: 2}}
+		if len(stats.DocumentsByYear) != len(want) {
+			t.Fatalf("Expected %v, got %v", want, stats.DocumentsByYear)
+		}
+		for i := range want {
+			if stats.DocumentsByYear[i] != want[i] {
+				t.Errorf("Expected %v at index %d, got %v", want[i], i, stats.DocumentsByYear[i])
+			}
+		}
+	})
+
+	t.Run("top authors", func(t *testing.T) {
+		if len(stats.TopAuthors) != 2 {
+			t.Fatalf("Expected 2 top authors, got %d", len(stats.TopAuthors))
+		}
+		if stats.TopAuthors[0] != (models.AuthorCount{Author: "Smith, John", Count: 2}) {
+			t.Errorf("Expected Smith, John with 2 documents first, got %v", stats.TopAuthors[0])
+		}
+	})
+}
+
+func TestGetLibraryStats_EmptyLibrary(t *testing.T) {
+	store := newTestStore(t)
+
+	stats, err := store.GetLibraryStats(context.Background(), "", 10)
+	if err != nil {
+		t.Fatalf("GetLibraryStats failed: %v", err)
+	}
+	if stats.DocumentCount != 0 || stats.TotalPages != 0 || len(stats.TopAuthors) != 0 {
+		t.Errorf("Expected empty stats, got %+v", stats)
+	}
+}
+
+func BenchmarkGetLibraryStats(b *testing.B) {
+	store, err := NewSQLiteStore(":memory:", logger.NewNoOpLogger())
+	if err != nil {
+		b.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 300; i++ {
+		item := syntheticItem(10)
+		item.Metadata.PublicationDate = fmt.Sprintf("%d-06-01", 1990+i%30)
+		item.Metadata.Authors = []string{fmt.Sprintf("Author %d", i%40), fmt.Sprintf("Author %d", i%7)}
+		if err := store.StoreParsedItem(ctx, fmt.Sprintf("doc-%d", i), item, &models.SourceInfo{}); err != nil {
+			b.Fatalf("StoreParsedItem failed: %v", err)
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := store.GetLibraryStats(ctx, "", 10); err != nil {
+			b.Fatalf("GetLibraryStats failed: %v", err)
+		}
+	}
+}
+
+func TestStoreParsedItem_PageQuality(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	scanned := syntheticItem(4)
+	scanned.IsScanned = true
+	low, high := 0.3, 0.95
+	scanned.PageQuality = []models.PageQuality{
+		{IsScanned: true, ContentConfidence: &high, IsCover: true},
+		{IsScanned: true, NearEmpty: true, ContentConfidence: &low, IsBlank: true},
+		{IsScanned: false, ContentConfidence: &high, IsReferencesOnly: true},
+		{Degraded: true},
+	}
+	if err := store.StoreParsedItem(ctx, "scanned", scanned, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	if err := store.StoreParsedItem(ctx, "text", syntheticItem(3), &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+
+	t.Run("scanned document", func(t *testing.T) {
+		got, err := store.GetParsedItem(ctx, "scanned")
+		if err != nil {
+			t.Fatalf("GetParsedItem failed: %v", err)
+		}
+		if !got.IsScanned {
+			t.Error("Expected document to be flagged as scanned")
+		}
+		if len(got.PageQuality) != len(scanned.PageQuality) {
+			t.Fatalf("Expected %d page quality entries, got %d", len(scanned.PageQuality), len(got.PageQuality))
+		}
+		for i := range scanned.PageQuality {
+			if !reflect.DeepEqual(got.PageQuality[i], scanned.PageQuality[i]) {
+				t.Errorf("Page %d: expected %+v, got %+v", i+1, scanned.PageQuality[i], got.PageQuality[i])
+			}
+		}
+	})
+
+	t.Run("text document", func(t *testing.T) {
+		got, err := store.GetParsedItem(ctx, "text")
+		if err != nil {
+			t.Fatalf("GetParsedItem failed: %v", err)
+		}
+		if got.IsScanned || got.PageQuality != nil {
+			t.Errorf("Expected no scan flags, got is_scanned=%v page_quality=%v", got.IsScanned, got.PageQuality)
+		}
+	})
+}
+
+func TestNewSQLiteStore_AddsMissingColumns(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "old.db")
+
+	// Create a database with the pages and documents tables as they were before scan detection
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	_, err = db.Exec(`
+		CREATE TABLE documents (id TEXT PRIMARY KEY, title TEXT, authors TEXT, publication_date TEXT,
+			publication TEXT, doi TEXT, abstract TEXT, summary TEXT, zotero_id TEXT, url TEXT,
+			item_type TEXT, publisher TEXT, volume TEXT, issue TEXT, pages TEXT, issn TEXT, isbn TEXT,
+			metadata_url TEXT, metadata_source TEXT, citekey TEXT, created_at DATETIME DEFAULT CURRENT_TIMESTAMP);
+		CREATE TABLE pages (document_id TEXT NOT NULL, page_number INTEGER NOT NULL,
+			source_page_number TEXT NOT NULL, content TEXT, PRIMARY KEY (document_id, page_number));
+		INSERT INTO documents VALUES ('old-doc', 'Old document', '[]', '', '', '', '', '', '', '', '', '', '', '', '', '', '', '', '', NULL, CURRENT_TIMESTAMP);
+		INSERT INTO pages (document_id, page_number, source_page_number, content) VALUES ('old-doc', 1, '1', 'Old page');
+	`)
+	db.Close()
+	if err != nil {
+		t.Fatalf("Failed to create old schema: %v", err)
+	}
+
+	store, err := NewSQLiteStore(dbPath, logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("NewSQLiteStore failed on old database: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	got, err := store.GetParsedItem(ctx, "old-doc")
+	if err != nil {
+		t.Fatalf("GetParsedItem failed for existing document: %v", err)
+	}
+	if got.IsScanned || got.PageQuality != nil {
+		t.Errorf("Expected existing document to have no scan flags, got %+v", got.PageQuality)
+	}
+
+	item := syntheticItem(1)
+	item.PageQuality = []models.PageQuality{{IsScanned: true, NearEmpty: true}}
+	if err := store.StoreParsedItem(ctx, "new-doc", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed after adding columns: %v", err)
+	}
+}
+
+func TestGetSourceInfo(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	docs := []struct {
+		id     string
+		source models.SourceInfo
+	}{
+		{"zotero_ABC", models.SourceInfo{ZoteroID: "ABC"}},
+		{"zotero_group_42_DEF", models.SourceInfo{ZoteroID: "DEF", ZoteroLibraryType: "group", ZoteroLibraryID: "42"}},
+		{"zotero_user_222_GHI", models.SourceInfo{ZoteroID: "GHI", ZoteroLibraryType: "user", ZoteroLibraryID: "222"}},
+		// Stored from a user library before its library was part of the ID
+		{"zotero_JKL", models.SourceInfo{ZoteroID: "JKL", ZoteroLibraryType: "user", ZoteroLibraryID: "333"}},
+		{"url_0011223344556677", models.SourceInfo{URL: "https://example.com/paper.pdf"}},
+		{"data_0011223344556677", models.SourceInfo{}},
+	}
+	for _, d := range docs {
+		source := d.source
+		if err := store.StoreParsedItem(ctx, d.id, syntheticItem(1), &source); err != nil {
+			t.Fatalf("StoreParsedItem failed: %v", err)
+		}
+	}
+
+	for _, d := range docs {
+		t.Run(d.id, func(t *testing.T) {
+			got, err := store.GetSourceInfo(ctx, d.id)
+			if err != nil {
+				t.Fatalf("GetSourceInfo failed: %v", err)
+			}
+			if *got != d.source {
+				t.Errorf("Expected %+v, got %+v", d.source, *got)
+			}
+		})
+	}
+
+	if _, err := store.GetSourceInfo(ctx, "missing"); err == nil {
+		t.Error("Expected error for missing document, got nil")
+	}
+}
+
+func TestIsPartial(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	partial := &models.ParsedItem{Metadata: models.ItemMetadata{Title: "Triage", Abstract: "First pages only."}, Partial: true}
+	if err := store.StoreParsedItem(ctx, "doc-partial", partial, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	if err := store.StoreParsedItem(ctx, "doc-full", syntheticItem(1), &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+
+	for docID, expected := range map[string]bool{"doc-partial": true, "doc-full": false} {
+		got, err := store.IsPartial(ctx, docID)
+		if err != nil || got != expected {
+			t.Errorf("IsPartial(%s): expected %v, got %v (%v)", docID, expected, got, err)
+		}
+		item, err := store.GetParsedItem(ctx, docID)
+		if err != nil || item.Partial != expected {
+			t.Errorf("GetParsedItem(%s): expected partial %v, got %+v (%v)", docID, expected, item, err)
+		}
+	}
+
+	// A full parse stored over a partial document replaces it
+	if err := store.StoreParsedItem(ctx, "doc-partial", syntheticItem(2), &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	if got, err := store.IsPartial(ctx, "doc-partial"); err != nil || got {
+		t.Errorf("Expected doc-partial upgraded, got partial %v (%v)", got, err)
+	}
+
+	if _, err := store.IsPartial(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing document, got %v", err)
+	}
+}
+
+func TestGetReferences_PageNumber(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	item := syntheticItem(0)
+	item.References = []models.Reference{
+		{ReferenceText: "Reference on page 12", PageNumber: "12", PageIndex: 3},
+		{ReferenceText: "Reference without page"},
+	}
+	if err := store.StoreParsedItem(ctx, "doc-1", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+
+	refs, err := store.GetReferences(ctx, "doc-1")
+	if err != nil {
+		t.Fatalf("GetReferences failed: %v", err)
+	}
+	if len(refs) != 2 || refs[0].PageNumber != "12" || refs[0].PageIndex != 3 || refs[1].PageNumber != "" || refs[1].PageIndex != 0 {
+		t.Errorf("Expected reference page numbers to round-trip, got %+v", refs)
+	}
+}
+
+func TestGetReferencesBySourcePage(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	item := syntheticItem(0)
+	item.Pages = []string{"Text", "References begin", "References end", "Appendix"}
+	item.PageNumbers = []string{"11", "12", "13", "A-1"}
+	item.References = []models.Reference{
+		{ReferenceText: "Abbott (2001)", PageNumber: "12", PageIndex: 2},
+		{ReferenceText: "Baker (1999)", PageNumber: "12", PageIndex: 2},
+		{ReferenceText: "Clark (2010)", PageNumber: "13", PageIndex: 3},
+		{ReferenceText: "Unlocated"},
+	}
+	if err := store.StoreParsedItem(ctx, "doc-1", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+
+	refs, err := store.GetReferencesBySourcePage(ctx, "doc-1", "12")
+	if err != nil {
+		t.Fatalf("GetReferencesBySourcePage failed: %v", err)
+	}
+	if len(refs) != 2 || refs[0].RefIndex != 0 || refs[0].ReferenceText != "Abbott (2001)" || refs[1].RefIndex != 1 || refs[1].PageIndex != 2 {
+		t.Errorf("Expected the two references on page 12, got %+v", refs)
+	}
+	if refs, err := store.GetReferencesBySourcePage(ctx, "doc-1", "13"); err != nil || len(refs) != 1 || refs[0].RefIndex != 2 {
+		t.Errorf("Expected reference 2 on page 13, got %+v, %v", refs, err)
+	}
+
+	// A page without references is empty; an unknown page is not found
+	if refs, err := store.GetReferencesBySourcePage(ctx, "doc-1", "A-1"); err != nil || refs == nil || len(refs) != 0 {
+		t.Errorf("Expected no references on page A-1, got %+v, %v", refs, err)
+	}
+	if _, err := store.GetReferencesBySourcePage(ctx, "doc-1", "99"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown page, got %v", err)
+	}
+	if _, err := store.GetReferencesBySourcePage(ctx, "missing", "12"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing document, got %v", err)
+	}
+}
+
+func TestGetEndnotes_Keys(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	item := syntheticItem(0)
+	item.Endnotes = []models.Endnote{
+		{Marker: "1", Key: "ch1-n1", Text: "First chapter", PageNumber: "40"},
+		{Marker: "1", Key: "ch2-n1", Text: "Second chapter", PageNumber: "41"},
+	}
+	if err := store.StoreParsedItem(ctx, "doc-1", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+
+	endnotes, err := store.GetEndnotes(ctx, "doc-1")
+	if err != nil {
+		t.Fatalf("GetEndnotes failed: %v", err)
+	}
+	if !reflect.DeepEqual(endnotes, item.Endnotes) {
+		t.Errorf("Expected %+v, got %+v", item.Endnotes, endnotes)
+	}
+	endnote, err := store.GetEndnote(ctx, "doc-1", 1)
+	if err != nil || endnote.Marker != "1" || endnote.Key != "ch2-n1" {
+		t.Errorf("Expected the second chapter's note 1, got %+v, %v", endnote, err)
+	}
+}
+
+func TestGetTables_Structure(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	item := syntheticItem(0)
+	item.Tables = []models.Table{
+		{TableID: "Table 1", TableData: "| A | B |\n|---|---|\n| 1 | |", Columns: []string{"A", "B"}, Rows: [][]string{{"1", ""}}},
+		{TableID: "Table 2", TableData: "A: 1", ParseError: "no markdown table rows found"},
+	}
+	if err := store.StoreParsedItem(ctx, "doc-1", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+
+	tables, err := store.GetTables(ctx, "doc-1")
+	if err != nil {
+		t.Fatalf("GetTables failed: %v", err)
+	}
+	if !reflect.DeepEqual(tables, item.Tables) {
+		t.Errorf("Expected %+v, got %+v", item.Tables, tables)
+	}
+
+	table, err := store.GetTable(ctx, "doc-1", 1)
+	if err != nil {
+		t.Fatalf("GetTable failed: %v", err)
+	}
+	if !reflect.DeepEqual(*table, item.Tables[1]) {
+		t.Errorf("Expected %+v, got %+v", item.Tables[1], *table)
+	}
+}
+
+func TestGetQuotations_Verification(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	item := syntheticItem(0)
+	item.Quotations = []models.Quotation{
+		{QuotationText: "Found verbatim", PageNumber: "3", Verified: true, MatchScore: 1, Span: &models.TextSpan{Start: 0, End: 14}, PageHash: "abc123", Selected: true, Rank: 1},
+		{QuotationText: "A paraphrase", PageNumber: "4", MatchScore: 0.42, Rank: 2},
+	}
+	if err := store.StoreParsedItem(ctx, "doc-1", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+
+	quotations, err := store.GetQuotations(ctx, "doc-1")
+	if err != nil {
+		t.Fatalf("GetQuotations failed: %v", err)
+	}
+	if !reflect.DeepEqual(quotations, item.Quotations) {
+		t.Errorf("Expected verification fields, spans, and selection to round-trip, got %+v", quotations)
+	}
+
+	quotation, err := store.GetQuotation(ctx, "doc-1", 1)
+	if err != nil {
+		t.Fatalf("GetQuotation failed: %v", err)
+	}
+	if quotation.Verified || quotation.MatchScore != 0.42 || quotation.Span != nil {
+		t.Errorf("Expected unverified quotation with score 0.42 and no span, got %+v", quotation)
+	}
+}
+
+func TestSelectQuotations(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	item := syntheticItem(0)
+	item.Quotations = []models.Quotation{
+		{QuotationText: "First", Selected: true, Rank: 1},
+		{QuotationText: "Second", Rank: 3},
+		{QuotationText: "Third", Rank: 2},
+	}
+	if err := store.StoreParsedItem(ctx, "doc-1", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	if err := store.SelectQuotations(ctx, "doc-1", []int{2}); err != nil {
+		t.Fatalf("SelectQuotations failed: %v", err)
+	}
+	quotations, err := store.GetQuotations(ctx, "doc-1")
+	if err != nil {
+		t.Fatalf("GetQuotations failed: %v", err)
+	}
+	if !quotations[0].Selected || quotations[1].Selected || !quotations[2].Selected || quotations[2].Rank != 2 {
+		t.Errorf("Expected the first and third quotations selected, got %+v", quotations)
+	}
+	if err := store.SelectQuotations(ctx, "doc-1", []int{5}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing quotation, got %v", err)
+	}
+
+	// Quotations none of which is selected predate selection and are all selected
+	item.Quotations = []models.Quotation{{QuotationText: "Archived"}, {QuotationText: "Also archived"}}
+	if err := store.StoreParsedItem(ctx, "doc-1", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	quotations, err = store.GetQuotations(ctx, "doc-1")
+	if err != nil {
+		t.Fatalf("GetQuotations failed: %v", err)
+	}
+	for i, q := range quotations {
+		if !q.Selected {
+			t.Errorf("Quotation %d: expected to be stored selected", i)
+		}
+	}
+}
+
+func TestStoreParsedItem_PageSentences(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	item := syntheticItem(2)
+	item.PageSentences = [][]models.TextSpan{{{Start: 0, End: 4}, {Start: 5, End: 14}}, {}}
+	if err := store.StoreParsedItem(ctx, "doc-1", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	if err := store.StoreParsedItem(ctx, "doc-2", syntheticItem(2), &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+
+	sentences, err := store.GetPageSentences(ctx, "doc-1", "1")
+	if err != nil || !reflect.DeepEqual(sentences, item.PageSentences[0]) {
+		t.Errorf("Expected %+v, got %+v (%v)", item.PageSentences[0], sentences, err)
+	}
+	if sentences, err := store.GetPageSentences(ctx, "doc-1", "2"); err != nil || sentences == nil || len(sentences) != 0 {
+		t.Errorf("Expected an empty segmentation, got %#v (%v)", sentences, err)
+	}
+	stored, err := store.GetParsedItem(ctx, "doc-1")
+	if err != nil {
+		t.Fatalf("GetParsedItem failed: %v", err)
+	}
+	if !reflect.DeepEqual(stored.PageSentences, item.PageSentences) {
+		t.Errorf("Expected the sentences in the parsed item, got %+v", stored.PageSentences)
+	}
+
+	// A document stored without sentences has none recorded
+	if sentences, err := store.GetPageSentences(ctx, "doc-2", "1"); err != nil || sentences != nil {
+		t.Errorf("Expected no sentences, got %+v (%v)", sentences, err)
+	}
+	if stored, err := store.GetParsedItem(ctx, "doc-2"); err != nil || stored.PageSentences != nil {
+		t.Errorf("Expected no sentences in the parsed item, got %+v (%v)", stored.PageSentences, err)
+	}
+	if _, err := store.GetPageSentences(ctx, "doc-1", "9"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown page, got %v", err)
+	}
+}
+
+func TestGetSections(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	item := syntheticItem(0)
+	item.Sections = []models.Section{
+		{Title: "Introduction", Level: 2, StartPage: "iv", EndPage: "1", StartPageIndex: 1, EndPageIndex: 2, StartOffset: 0, EndOffset: 120},
+		{Title: "Methods", Level: 2, StartPage: "1", EndPage: "1", StartPageIndex: 2, EndPageIndex: 2, StartOffset: 120, EndOffset: 300},
+	}
+	if err := store.StoreParsedItem(ctx, "doc-1", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+
+	sections, err := store.GetSections(ctx, "doc-1")
+	if err != nil {
+		t.Fatalf("GetSections failed: %v", err)
+	}
+	if !reflect.DeepEqual(sections, item.Sections) {
+		t.Errorf("Expected %+v, got %+v", item.Sections, sections)
+	}
+
+	section, err := store.GetSection(ctx, "doc-1", 1)
+	if err != nil {
+		t.Fatalf("GetSection failed: %v", err)
+	}
+	if *section != item.Sections[1] {
+		t.Errorf("Expected %+v, got %+v", item.Sections[1], *section)
+	}
+
+	if _, err := store.GetSection(ctx, "doc-1", 2); err == nil {
+		t.Error("Expected error for out-of-range section index")
+	}
+}
+
+func TestGetCitekeyMap(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	citekeyMap, err := store.GetCitekeyMap(ctx, "")
+	if err != nil {
+		t.Fatalf("GetCitekeyMap failed on empty library: %v", err)
+	}
+	if len(citekeyMap) != 0 {
+		t.Errorf("Expected empty map, got %v", citekeyMap)
+	}
+
+	citekeys := map[string]string{"doc-1": "smith2020", "doc-2": "jones2021", "doc-3": ""}
+	for docID, citekey := range citekeys {
+		item := syntheticItem(1)
+		item.Metadata.Citekey = citekey
+		if err := store.StoreParsedItem(ctx, docID, item, &models.SourceInfo{}); err != nil {
+			t.Fatalf("StoreParsedItem failed for %s: %v", docID, err)
+		}
+	}
+
+	citekeyMap, err = store.GetCitekeyMap(ctx, "")
+	if err != nil {
+		t.Fatalf("GetCitekeyMap failed: %v", err)
+	}
+	expected := map[string]string{"doc-1": "smith2020", "doc-2": "jones2021"}
+	if !reflect.DeepEqual(citekeyMap, expected) {
+		t.Errorf("Expected %v (documents without citekeys omitted), got %v", expected, citekeyMap)
+	}
+
+	if err := store.SetCitekey(ctx, "doc-3", "brown2019"); err != nil {
+		t.Fatalf("SetCitekey failed: %v", err)
+	}
+	if docID, err := store.GetDocumentByCitekey(ctx, "", "brown2019"); err != nil || docID != "doc-3" {
+		t.Errorf("Expected the new citekey to resolve to doc-3, got %s, %v", docID, err)
+	}
+	if err := store.SetCitekey(ctx, "missing", "brown2019"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown document, got %v", err)
+	}
+
+	// Documents can trade citekeys
+	if err := store.SetCitekeys(ctx, map[string]string{"doc-1": "jones2021", "doc-2": "smith2020"}); err != nil {
+		t.Fatalf("SetCitekeys failed: %v", err)
+	}
+	citekeyMap, err = store.GetCitekeyMap(ctx, "")
+	if err != nil {
+		t.Fatalf("GetCitekeyMap failed: %v", err)
+	}
+	expected = map[string]string{"doc-1": "jones2021", "doc-2": "smith2020", "doc-3": "brown2019"}
+	if !reflect.DeepEqual(citekeyMap, expected) {
+		t.Errorf("Expected traded citekeys %v, got %v", expected, citekeyMap)
+	}
+
+	// A citekey taken by a document not in the change, or an unknown
+	// document, leaves every citekey as it was
+	for _, citekeys := range []map[string]string{
+		{"doc-1": "smith2020a", "doc-2": "brown2019"},
+		{"doc-1": "smith2020a", "missing": "doe2018"},
+	} {
+		if err := store.SetCitekeys(ctx, citekeys); err == nil {
+			t.Errorf("Expected SetCitekeys(%v) to fail", citekeys)
+		}
+	}
+	if citekeyMap, _ := store.GetCitekeyMap(ctx, ""); !reflect.DeepEqual(citekeyMap, expected) {
+		t.Errorf("Expected citekeys unchanged after failed updates, got %v", citekeyMap)
+	}
+}
+
+func TestLibraries_CitekeysAndListing(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	// The same citekey and DOI in two libraries belong to different documents
+	for _, docID := range []string{"url_1", "thesis:url_1", "thesis:url_2"} {
+		item := syntheticItem(1)
+		item.Metadata.DOI = "10.1000/shared"
+		item.Metadata.Citekey = "smith2020"
+		if docID == "thesis:url_2" {
+			item.Metadata.DOI, item.Metadata.Citekey = "", "jones2021"
+		}
+		if err := store.StoreParsedItem(ctx, docID, item, &models.SourceInfo{}); err != nil {
+			t.Fatalf("StoreParsedItem failed for %s: %v", docID, err)
+		}
+	}
+
+	// Citekeys stay unique within a library
+	if err := store.SetCitekey(ctx, "thesis:url_2", "smith2020"); err == nil {
+		t.Error("Expected a citekey used in the same library to be refused")
+	}
+
+	for _, tt := range []struct {
+		library string
+		want    string
+	}{
+		{DefaultLibrary, "url_1"},
+		{"thesis", "thesis:url_1"},
+	} {
+		docID, err := store.GetDocumentByCitekey(ctx, tt.library, "smith2020")
+		if err != nil || docID != tt.want {
+			t.Errorf("GetDocumentByCitekey(%q): expected %s, got %s, %v", tt.library, tt.want, docID, err)
+		}
+		if docID, err := store.FindDocumentByDOI(ctx, tt.library, "10.1000/shared"); err != nil || docID != tt.want {
+			t.Errorf("FindDocumentByDOI(%q): expected %s, got %s, %v", tt.library, tt.want, docID, err)
+		}
+	}
+	if _, err := store.GetDocumentByCitekey(ctx, "other", "smith2020"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound in a library without the citekey, got %v", err)
+	}
+
+	citekeys, err := store.GetCitekeyMap(ctx, "thesis")
+	if err != nil {
+		t.Fatalf("GetCitekeyMap failed: %v", err)
+	}
+	if want := map[string]string{"thesis:url_1": "smith2020", "thesis:url_2": "jones2021"}; !reflect.DeepEqual(citekeys, want) {
+		t.Errorf("Expected %v, got %v", want, citekeys)
+	}
+
+	docs, err := store.ListDocuments(ctx, "thesis")
+	if err != nil {
+		t.Fatalf("ListDocuments failed: %v", err)
+	}
+	if len(docs) != 2 || docs[0].Library != "thesis" || docs[1].Library != "thesis" {
+		t.Errorf("Expected the two thesis documents, got %+v", docs)
+	}
+	if docs, err := store.ListDocuments(ctx, ""); err != nil || len(docs) != 3 {
+		t.Errorf("Expected every library's documents, got %d, %v", len(docs), err)
+	}
+
+	stats, err := store.GetLibraryStats(ctx, DefaultLibrary, 0)
+	if err != nil {
+		t.Fatalf("GetLibraryStats failed: %v", err)
+	}
+	if stats.DocumentCount != 1 || stats.TotalPages != 1 {
+		t.Errorf("Expected the default library's document and page, got %+v", stats)
+	}
+	if stats, err := store.GetLibraryStats(ctx, "", 0); err != nil || stats.DocumentCount != 3 || stats.TotalPages != 3 {
+		t.Errorf("Expected every library's documents and pages, got %+v, %v", stats, err)
+	}
+}
+
+func TestNewSQLiteStore_ConnectionPragmas(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"), logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	tests := []struct {
+		pragma string
+		want   string
+	}{
+		{"journal_mode", "wal"},
+		{"busy_timeout", "5000"},
+		{"foreign_keys", "1"},
+		{"synchronous", "1"}, // NORMAL
+	}
+	for _, tt := range tests {
+		t.Run(tt.pragma, func(t *testing.T) {
+			var got string
+			if err := store.db.QueryRow("PRAGMA " + tt.pragma).Scan(&got); err != nil {
+				t.Fatalf("Failed to query %s: %v", tt.pragma, err)
+			}
+			if got != tt.want {
+				t.Errorf("PRAGMA %s = %q, want %q", tt.pragma, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeleteDocument_CascadesToChildTables(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	if err := store.StoreParsedItem(ctx, "doc-1", syntheticItem(3), &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	if err := store.DeleteDocument(ctx, "doc-1"); err != nil {
+		t.Fatalf("DeleteDocument failed: %v", err)
+	}
+
+	for _, table := range childTables {
+		var count int
+		if err := store.db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %s", table)).Scan(&count); err != nil {
+			t.Fatalf("Failed to count %s: %v", table, err)
+		}
+		if count != 0 {
+			t.Errorf("Expected %s to be empty after delete, found %d rows", table, count)
+		}
+	}
+}
+
+func TestRecordUsage(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	for _, docID := range []string{"doc-1", "doc-2"} {
+		if err := store.StoreParsedItem(ctx, docID, syntheticItem(2), &models.SourceInfo{}); err != nil {
+			t.Fatalf("StoreParsedItem failed: %v", err)
+		}
+	}
+
+	record := func(docID, operation string, usage ...models.TokenUsage) {
+		t.Helper()
+		if err := store.RecordUsage(ctx, docID, operation, usage); err != nil {
+			t.Fatalf("RecordUsage failed: %v", err)
+		}
+	}
+	record("doc-1", "parse", models.TokenUsage{Model: "gpt-5-mini", Requests: 2, InputTokens: 4000, OutputTokens: 1000})
+	record("doc-1", "quotations", models.TokenUsage{Model: "gpt-5-mini", Requests: 3, InputTokens: 6000, OutputTokens: 900})
+	record("doc-1", "quotations", models.TokenUsage{Model: "gpt-5-mini", Requests: 1, InputTokens: 2000, OutputTokens: 100})
+	record("doc-2", "parse", models.TokenUsage{Model: "gpt-5-mini", Requests: 5, InputTokens: 10000, OutputTokens: 2500})
+
+	// Re-storing a document (as summarizing does) keeps its usage
+	if err := store.StoreParsedItem(ctx, "doc-1", syntheticItem(2), &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+
+	docUsage, err := store.GetUsage(ctx, "doc-1")
+	if err != nil {
+		t.Fatalf("GetUsage failed: %v", err)
+	}
+	expectedDoc := []models.TokenUsage{
+		{Operation: "parse", Model: "gpt-5-mini", Requests: 2, InputTokens: 4000, OutputTokens: 1000},
+		{Operation: "quotations", Model: "gpt-5-mini", Requests: 4, InputTokens: 8000, OutputTokens: 1000},
+	}
+	if !reflect.DeepEqual(docUsage, expectedDoc) {
+		t.Errorf("Expected document usage %+v, got %+v", expectedDoc, docUsage)
+	}
+
+	libraryUsage, err := store.GetUsage(ctx, "")
+	if err != nil {
+		t.Fatalf("GetUsage failed: %v", err)
+	}
+	expectedLibrary := []models.TokenUsage{
+		{Operation: "parse", Model: "gpt-5-mini", Requests: 7, InputTokens: 14000, OutputTokens: 3500},
+		{Operation: "quotations", Model: "gpt-5-mini", Requests: 4, InputTokens: 8000, OutputTokens: 1000},
+	}
+	if !reflect.DeepEqual(libraryUsage, expectedLibrary) {
+		t.Errorf("Expected library usage %+v, got %+v", expectedLibrary, libraryUsage)
+	}
+
+	if err := store.DeleteDocument(ctx, "doc-1"); err != nil {
+		t.Fatalf("DeleteDocument failed: %v", err)
+	}
+	if docUsage, err := store.GetUsage(ctx, "doc-1"); err != nil || len(docUsage) != 0 {
+		t.Errorf("Expected no usage after deleting the document, got %+v, %v", docUsage, err)
+	}
+}
+
+func TestSQLiteStore_ConcurrentStress(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"), logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	const workers = 8
+	const documentsPerWorker = 6
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*documentsPerWorker)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < documentsPerWorker; i++ {
+				docID := fmt.Sprintf("doc-%d-%d", w, i)
+				item := syntheticItem(5 + i)
+				item.Metadata.Title = docID
+				if err := store.StoreParsedItem(ctx, docID, item, &models.SourceInfo{}); err != nil {
+					errs <- fmt.Errorf("store %s: %w", docID, err)
+					return
+				}
+				// Re-store to exercise replacing rows while other workers write
+				if err := store.StoreParsedItem(ctx, docID, item, &models.SourceInfo{}); err != nil {
+					errs <- fmt.Errorf("re-store %s: %w", docID, err)
+					return
+				}
+				got, err := store.GetParsedItem(ctx, docID)
+				if err != nil {
+					errs <- fmt.Errorf("read %s: %w", docID, err)
+					return
+				}
+				if got.Metadata.Title != docID || len(got.Pages) != len(item.Pages) || len(got.References) != len(item.References) {
+					errs <- fmt.Errorf("%s read back with title %q, %d pages, %d references", docID, got.Metadata.Title, len(got.Pages), len(got.References))
+					return
+				}
+				if _, err := store.ListDocuments(ctx, ""); err != nil {
+					errs <- fmt.Errorf("list documents: %w", err)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	docs, err := store.ListDocuments(ctx, "")
+	if err != nil {
+		t.Fatalf("ListDocuments failed: %v", err)
+	}
+	if len(docs) != workers*documentsPerWorker {
+		t.Errorf("Expected %d documents, got %d", workers*documentsPerWorker, len(docs))
+	}
+}
+
+func TestGetParsedItem_MetadataRoundTrip(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	metadata := models.ItemMetadata{
+		Title:            "A Fully Described Article",
+		Authors:          []string{"Jane Smith", "Robert Jones", "Example Lab"},
+		CorporateAuthors: []string{"Example Lab"},
+		PublicationDate:  "2021-03-15",
+		Publication:      "Journal of Examples",
+		DOI:              "10.1234/example.5678",
+		Abstract:         "An abstract.",
+		FundingStatement: "Funded by the Example Foundation.",
+		Acknowledgments:  "We thank the reviewers.",
+		DataAvailability: "Data are available on request.",
+		ItemType:         "journalArticle",
+		Publisher:        "Example Press",
+		Volume:           "12",
+		Issue:            "3",
+		Pages:            "101-120",
+		ISSN:             "1234-5678",
+		ISBN:             "978-3-16-148410-0",
+		URL:              "https://example.org/article",
+		Language:         "de",
+		Keywords:         []string{"examples", "survey methods"},
+		Citekey:          "smithJones2021",
+		MetadataSource:   "merged",
+		FieldSources:     map[string]string{"title": "external", "abstract": "extracted"},
+		Conflicts:        []models.MetadataConflict{{Field: "title", ExternalValue: "A Fully Described Article", ExtractedValue: "A Described Article"}},
+	}
+	item := syntheticItem(1)
+	item.Metadata = metadata
+	if err := store.StoreParsedItem(ctx, "doc-1", item, &models.SourceInfo{ZoteroID: "ABC"}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+
+	got, err := store.GetParsedItem(ctx, "doc-1")
+	if err != nil {
+		t.Fatalf("GetParsedItem failed: %v", err)
+	}
+	if !reflect.DeepEqual(got.Metadata, metadata) {
+		t.Errorf("Metadata did not round-trip:\ngot  %+v\nwant %+v", got.Metadata, metadata)
+	}
+
+	docs, err := store.ListDocuments(ctx, "")
+	if err != nil {
+		t.Fatalf("ListDocuments failed: %v", err)
+	}
+	want := models.DocumentInfo{
+		DocumentID:      "doc-1",
+		Library:         DefaultLibrary,
+		Title:           metadata.Title,
+		Authors:         metadata.Authors,
+		PublicationDate: metadata.PublicationDate,
+		Publication:     metadata.Publication,
+		DOI:             metadata.DOI,
+		ItemType:        metadata.ItemType,
+		Language:        metadata.Language,
+		Citekey:         metadata.Citekey,
+		Keywords:        metadata.Keywords,
+		Statements:      models.Statements,
+		SourceInfo:      models.SourceInfo{ZoteroID: "ABC"},
+	}
+	if len(docs) != 1 {
+		t.Fatalf("Expected 1 document, got %d", len(docs))
+	}
+	// Storage times are checked in TestStoreParsedItem_Provenance
+	docs[0].Provenance.CreatedAt, docs[0].Provenance.UpdatedAt = "", ""
+	if !reflect.DeepEqual(docs[0], want) {
+		t.Errorf("ListDocuments = %+v, want [%+v]", docs, want)
+	}
+}
+
+func TestStoreParsedItem_FullText(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	item := syntheticItem(2)
+	item.FullText = "Page 1 content Page 2 content"
+	item.PageOffsets = []int{0, 15}
+	if err := store.StoreParsedItem(ctx, "doc-1", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	if err := store.StoreParsedItem(ctx, "doc-2", syntheticItem(2), &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+
+	text, offsets, err := store.GetFullText(ctx, "doc-1")
+	if err != nil {
+		t.Fatalf("GetFullText failed: %v", err)
+	}
+	if text != item.FullText || !reflect.DeepEqual(offsets, item.PageOffsets) {
+		t.Errorf("Expected %q %v, got %q %v", item.FullText, item.PageOffsets, text, offsets)
+	}
+	stored, err := store.GetParsedItem(ctx, "doc-1")
+	if err != nil {
+		t.Fatalf("GetParsedItem failed: %v", err)
+	}
+	if stored.FullText != item.FullText || !reflect.DeepEqual(stored.PageOffsets, item.PageOffsets) {
+		t.Errorf("Expected the full text in the parsed item, got %q %v", stored.FullText, stored.PageOffsets)
+	}
+
+	// A document without full text has no offsets either
+	if text, offsets, err := store.GetFullText(ctx, "doc-2"); err != nil || text != "" || offsets != nil {
+		t.Errorf("Expected no full text, got %q %v %v", text, offsets, err)
+	}
+	if _, _, err := store.GetFullText(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestStoreParsedItem_Provenance(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	item := syntheticItem(1)
+	item.Provenance = &models.Provenance{ParsedModel: "gpt-5-mini", PromptVersion: 3, ParserVersion: "2"}
+	if err := store.StoreParsedItem(ctx, "doc-1", item, &models.SourceInfo{URL: "https://example.com"}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	// Documents parsed before provenance was recorded have none
+	if err := store.StoreParsedItem(ctx, "doc-2", syntheticItem(1), &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+
+	// Backdate doc-1 to see that storing it again keeps its creation time
+	if _, err := store.db.Exec(`UPDATE documents SET created_at = '2020-01-01 00:00:00', updated_at = '2020-01-01 00:00:00' WHERE id = 'doc-1'`); err != nil {
+		t.Fatalf("Failed to backdate document: %v", err)
+	}
+	stored, err := store.GetParsedItem(ctx, "doc-1")
+	if err != nil {
+		t.Fatalf("GetParsedItem failed: %v", err)
+	}
+	if err := store.StoreParsedItem(ctx, "doc-1", stored, &models.SourceInfo{URL: "https://example.com"}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+
+	got, err := store.GetProvenance(ctx, "doc-1")
+	if err != nil {
+		t.Fatalf("GetProvenance failed: %v", err)
+	}
+	if got.CreatedAt != "2020-01-01 00:00:00" || got.UpdatedAt == "" || got.UpdatedAt == got.CreatedAt {
+		t.Errorf("Expected the creation time kept and the update time refreshed, got %+v", got)
+	}
+	if got.ParsedModel != "gpt-5-mini" || got.PromptVersion != 3 || got.ParserVersion != "2" {
+		t.Errorf("Expected the parse provenance kept across a re-store, got %+v", got)
+	}
+
+	unrecorded, err := store.GetProvenance(ctx, "doc-2")
+	if err != nil {
+		t.Fatalf("GetProvenance failed: %v", err)
+	}
+	if unrecorded.CreatedAt == "" || unrecorded.ParsedModel != "" || unrecorded.PromptVersion != 0 {
+		t.Errorf("Expected only storage times for doc-2, got %+v", unrecorded)
+	}
+
+	if _, err := store.GetProvenance(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing document, got %v", err)
+	}
+}
+
+func TestUpdateMetadata(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	item := syntheticItem(2)
+	item.Metadata = models.ItemMetadata{Title: "Wrong Title", Authors: []string{"Jane Smith"}, Citekey: "smith2021"}
+	if err := store.StoreParsedItem(ctx, "doc-1", item, &models.SourceInfo{ZoteroID: "ABC"}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+
+	metadata := models.ItemMetadata{
+		Title:        "Right Title",
+		Authors:      []string{"Robert Jones"},
+		FieldSources: map[string]string{"title": "manual", "authors": "manual"},
+	}
+	if err := store.UpdateMetadata(ctx, "doc-1", &metadata); err != nil {
+		t.Fatalf("UpdateMetadata failed: %v", err)
+	}
+
+	got, err := store.GetParsedItem(ctx, "doc-1")
+	if err != nil {
+		t.Fatalf("GetParsedItem failed: %v", err)
+	}
+	if got.Metadata.Title != "Right Title" || !reflect.DeepEqual(got.Metadata.FieldSources, metadata.FieldSources) {
+		t.Errorf("Metadata not updated: %+v", got.Metadata)
+	}
+	if got.Metadata.Citekey != "smith2021" || len(got.Pages) != 2 {
+		t.Errorf("Citekey and content should be kept, got citekey %q and %d pages", got.Metadata.Citekey, len(got.Pages))
+	}
+
+	authors, err := store.GetAuthors(ctx, "")
+	if err != nil {
+		t.Fatalf("GetAuthors failed: %v", err)
+	}
+	if len(authors) != 1 || authors[0].Family != "Jones" {
+		t.Errorf("Expected the document's authors to be replaced, got %+v", authors)
+	}
+
+	if err := store.UpdateMetadata(ctx, "missing", &metadata); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing document, got %v", err)
+	}
+}
+
+func BenchmarkStoreParsedItem(b *testing.B) {
+	store, err := NewSQLiteStore(filepath.Join(b.TempDir(), "bench.db"), logger.NewNoOpLogger())
+	if err != nil {
+		b.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	item := syntheticItem(1000)
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := store.StoreParsedItem(ctx, "doc-1", item, &models.SourceInfo{}); err != nil {
+			b.Fatalf("StoreParsedItem failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// Prefixes of the stable IDs of each kind of item
+const (
+	stableIDImage    = "img"
+	stableIDTable    = "tbl"
+	stableIDFootnote = "fn"
+)
+
+// stableIDTables are the tables of the items with stable IDs, by resource type,
+// with the column holding each item's index
+var stableIDTables = map[string]struct{ table, indexColumn string }{
+	"images":    {"images", "image_index"},
+	"tables":    {"document_tables", "table_index"},
+	"footnotes": {"footnotes", "footnote_index"},
+}
+
+// AssignStableIDs gives the images, tables, and footnotes of item IDs that do
+// not depend on their position in the document: the kind of item, its source
+// page number, its place among the items of that kind on the page, and a short
+// hash of its content, e.g. "img-p12-2-3fa9c1". Re-parsing a document gives an
+// item whose page and content are unchanged the same ID, wherever other pages'
+// items put it in the document's list. Items without a page (as in documents
+// that are not PDFs) have IDs without one, e.g. "tbl-3-0b77de".
+func AssignStableIDs(item *models.ParsedItem) {
+	images := make([]stableIDInput, len(item.Images))
+	for i, img := range item.Images {
+		page := ""
+		if img.PageIndex > 0 && img.PageIndex <= len(item.PageNumbers) {
+			page = item.PageNumbers[img.PageIndex-1]
+		}
+		content := img.Caption
+		if content == "" {
+			content = img.ImageDescription
+		}
+		images[i] = stableIDInput{page: page, content: content}
+	}
+	for i, id := range stableIDs(stableIDImage, images) {
+		item.Images[i].StableID = id
+	}
+
+	tables := make([]stableIDInput, len(item.Tables))
+	for i, tbl := range item.Tables {
+		content := tbl.TableID + "\n" + tbl.TableTitle
+		if tbl.TableID == "" && tbl.TableTitle == "" {
+			content = tbl.TableData
+		}
+		tables[i] = stableIDInput{page: tbl.PageNumber, content: content}
+	}
+	for i, id := range stableIDs(stableIDTable, tables) {
+		item.Tables[i].StableID = id
+	}
+
+	footnotes := make([]stableIDInput, len(item.Footnotes))
+	for i, fn := range item.Footnotes {
+		footnotes[i] = stableIDInput{page: fn.PageNumber, content: fn.Marker + "\n" + fn.Text}
+	}
+	for i, id := range stableIDs(stableIDFootnote, footnotes) {
+		item.Footnotes[i].StableID = id
+	}
+}
+
+// stableIDInput is what the stable ID of an item is derived from
+type stableIDInput struct {
+	page    string // Source page number, or "" if the item has none
+	content string
+}
+
+// stableIDs returns the stable IDs of items of one kind, in document order
+func stableIDs(kind string, items []stableIDInput) []string {
+	ids := make([]string, len(items))
+	ordinals := make(map[string]int)
+	for i, item := range items {
+		ordinals[item.page]++
+		hash := sha256.Sum256([]byte(strings.ToLower(strings.Join(strings.Fields(item.content), " "))))
+		hashPrefix := hex.EncodeToString(hash[:3])
+		if item.page == "" {
+			ids[i] = fmt.Sprintf("%s-%d-%s", kind, ordinals[item.page], hashPrefix)
+		} else {
+			ids[i] = fmt.Sprintf("%s-p%s-%d-%s", kind, stableIDPage(item.page), ordinals[item.page], hashPrefix)
+		}
+	}
+	return ids
+}
+
+// stableIDPage returns a source page number with anything but ASCII letters
+// and digits replaced by underscores, so IDs need no escaping in URIs
+func stableIDPage(page string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, page)
+}
+
+// IsStableID reports whether id has the form of a stable ID of items of the
+// resource type ("images", "tables", or "footnotes"), as opposed to an index
+func IsStableID(resourceType, id string) bool {
+	prefix := map[string]string{"images": stableIDImage, "tables": stableIDTable, "footnotes": stableIDFootnote}[resourceType]
+	return prefix != "" && strings.HasPrefix(id, prefix+"-")
+}
+
+// ResolveStableID returns the index (0-indexed) of the image, table, or
+// footnote of a document with a stable ID. resourceType is "images",
+// "tables", or "footnotes".
+func (s *SQLiteStore) ResolveStableID(ctx context.Context, docID, resourceType, stableID string) (int, error) {
+	t, ok := stableIDTables[resourceType]
+	if !ok {
+		return 0, fmt.Errorf("%s have no stable IDs", resourceType)
+	}
+	var index int
+	err := s.db.QueryRowContext(ctx, `SELECT `+t.indexColumn+` FROM `+t.table+` WHERE document_id = ? AND stable_id = ?`, docID, stableID).Scan(&index)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("%s %w: %s %s", strings.TrimSuffix(resourceType, "s"), ErrNotFound, docID, stableID)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to query stable ID: %w", err)
+	}
+	return index, nil
+}
+
+// backfillStableIDs gives the images, tables, and footnotes stored before
+// stable IDs were assigned their IDs. It runs when the store is opened, after
+// the migrations, as footnote text may be encrypted and can only be hashed
+// with the cipher loaded; once every row has an ID it finds nothing to do.
+func (s *SQLiteStore) backfillStableIDs(ctx context.Context) error {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT document_id FROM images WHERE stable_id = ''
+		UNION SELECT document_id FROM document_tables WHERE stable_id = ''
+		UNION SELECT document_id FROM footnotes WHERE stable_id = ''
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to find items without stable IDs: %w", err)
+	}
+	var docIDs []string
+	for rows.Next() {
+		var docID string
+		if err := rows.Scan(&docID); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan document ID: %w", err)
+		}
+		docIDs = append(docIDs, docID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating documents: %w", err)
+	}
+
+	for _, docID := range docIDs {
+		if err := s.backfillDocumentStableIDs(ctx, docID); err != nil {
+			return err
+		}
+	}
+	if len(docIDs) > 0 {
+		s.logger.Info("Assigned stable IDs to the images, tables, and footnotes of %d documents", len(docIDs))
+	}
+	return nil
+}
+
+// backfillDocumentStableIDs assigns the stable IDs of one document's items
+func (s *SQLiteStore) backfillDocumentStableIDs(ctx context.Context, docID string) error {
+	var item models.ParsedItem
+	var err error
+	if item.PageNumbers, err = s.sourcePageNumbers(ctx, docID); err != nil {
+		return err
+	}
+	if item.Images, err = s.GetImages(ctx, docID); err != nil {
+		return err
+	}
+	if item.Tables, err = s.GetTables(ctx, docID); err != nil {
+		return err
+	}
+	if item.Footnotes, err = s.GetFootnotes(ctx, docID); err != nil {
+		return err
+	}
+	AssignStableIDs(&item)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	update := func(resourceType string, count int, id func(i int) string) error {
+		t := stableIDTables[resourceType]
+		for i := 0; i < count; i++ {
+			if _, err := tx.ExecContext(ctx, `UPDATE `+t.table+` SET stable_id = ? WHERE document_id = ? AND `+t.indexColumn+` = ?`, id(i), docID, i); err != nil {
+				return fmt.Errorf("failed to set stable ID of %s %d of %s: %w", resourceType, i, docID, err)
+			}
+		}
+		return nil
+	}
+	if err := update("images", len(item.Images), func(i int) string { return item.Images[i].StableID }); err != nil {
+		return err
+	}
+	if err := update("tables", len(item.Tables), func(i int) string { return item.Tables[i].StableID }); err != nil {
+		return err
+	}
+	if err := update("footnotes", len(item.Footnotes), func(i int) string { return item.Footnotes[i].StableID }); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// sourcePageNumbers returns the source page numbers of a document's pages, in
+// page order
+func (s *SQLiteStore) sourcePageNumbers(ctx context.Context, docID string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT source_page_number FROM pages WHERE document_id = ? ORDER BY page_number`, docID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query page numbers: %w", err)
+	}
+	defer rows.Close()
+	var numbers []string
+	for rows.Next() {
+		var number sql.NullString
+		if err := rows.Scan(&number); err != nil {
+			return nil, fmt.Errorf("failed to scan page number: %w", err)
+		}
+		numbers = append(numbers, number.String)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pages: %w", err)
+	}
+	return numbers, nil
+}
@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestAssignStableIDs(t *testing.T) {
+	item := &models.ParsedItem{
+		PageNumbers: []string{"iv", "12", "13"},
+		Images: []models.Image{
+			{Caption: "Figure 1", PageIndex: 2},
+			{Caption: "Figure 2", PageIndex: 2},
+			{ImageDescription: "A map", PageIndex: 3},
+		},
+		Tables:    []models.Table{{TableID: "Table 1", PageNumber: "13"}, {TableData: "| a |"}},
+		Footnotes: []models.Footnote{{Marker: "1", Text: "See above.", PageNumber: "iv"}},
+	}
+	AssignStableIDs(item)
+
+	for _, tt := range []struct{ id, prefix string }{
+		{item.Images[0].StableID, "img-p12-1-"},
+		{item.Images[1].StableID, "img-p12-2-"},
+		{item.Images[2].StableID, "img-p13-1-"},
+		{item.Tables[0].StableID, "tbl-p13-1-"},
+		{item.Tables[1].StableID, "tbl-1-"},
+		{item.Footnotes[0].StableID, "fn-piv-1-"},
+	} {
+		if !strings.HasPrefix(tt.id, tt.prefix) || len(tt.id) != len(tt.prefix)+6 {
+			t.Errorf("Expected an ID of the form %s{hash}, got %q", tt.prefix, tt.id)
+		}
+	}
+
+	// A re-parse that finds another image on an earlier page, and reflows
+	// whitespace in a caption, leaves the other IDs unchanged
+	before := []string{item.Images[0].StableID, item.Images[1].StableID, item.Images[2].StableID}
+	item.Images = []models.Image{
+		{Caption: "Frontispiece", PageIndex: 1},
+		{Caption: "Figure  1", PageIndex: 2},
+		{Caption: "Figure 2", PageIndex: 2},
+		{ImageDescription: "A map", PageIndex: 3},
+	}
+	AssignStableIDs(item)
+	for i, id := range before {
+		if got := item.Images[i+1].StableID; got != id {
+			t.Errorf("Image %d: expected ID %s to be kept, got %s", i, id, got)
+		}
+	}
+	if item.Images[0].StableID == before[0] {
+		t.Errorf("Expected the new image to have its own ID, got %s", item.Images[0].StableID)
+	}
+}
+
+func TestResolveStableID(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	if err := store.StoreParsedItem(ctx, "doc-1", syntheticItem(3), &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+
+	tables, err := store.GetTables(ctx, "doc-1")
+	if err != nil {
+		t.Fatalf("GetTables failed: %v", err)
+	}
+	for i, tbl := range tables {
+		if !IsStableID("tables", tbl.StableID) {
+			t.Fatalf("Table %d: expected a stable ID, got %q", i, tbl.StableID)
+		}
+		index, err := store.ResolveStableID(ctx, "doc-1", "tables", tbl.StableID)
+		if err != nil || index != i {
+			t.Errorf("Expected table %s at %d, got %d, %v", tbl.StableID, i, index, err)
+		}
+	}
+
+	if _, err := store.ResolveStableID(ctx, "doc-1", "tables", "tbl-p9-1-000000"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown ID, got %v", err)
+	}
+	if _, err := store.ResolveStableID(ctx, "doc-2", "tables", tables[0].StableID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for another document, got %v", err)
+	}
+}
+
+func TestBackfillStableIDs(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	item := syntheticItem(2)
+	item.Images[1].PageIndex = 2
+	if err := store.StoreParsedItem(ctx, "doc-1", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	want, err := store.GetParsedItem(ctx, "doc-1")
+	if err != nil {
+		t.Fatalf("GetParsedItem failed: %v", err)
+	}
+
+	// Rows stored before migration 48 have no IDs
+	for _, table := range []string{"images", "document_tables", "footnotes"} {
+		if _, err := store.db.ExecContext(ctx, `UPDATE `+table+` SET stable_id = ''`); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.backfillStableIDs(ctx); err != nil {
+		t.Fatalf("backfillStableIDs failed: %v", err)
+	}
+
+	got, err := store.GetParsedItem(ctx, "doc-1")
+	if err != nil {
+		t.Fatalf("GetParsedItem failed: %v", err)
+	}
+	if !strings.HasPrefix(got.Images[1].StableID, "img-p2-1-") {
+		t.Errorf("Expected the image's ID to name its source page, got %s", got.Images[1].StableID)
+	}
+	for i := range want.Images {
+		if got.Images[i].StableID != want.Images[i].StableID {
+			t.Errorf("Image %d: expected ID %s, got %s", i, want.Images[i].StableID, got.Images[i].StableID)
+		}
+	}
+	for i := range want.Tables {
+		if got.Tables[i].StableID != want.Tables[i].StableID {
+			t.Errorf("Table %d: expected ID %s, got %s", i, want.Tables[i].StableID, got.Tables[i].StableID)
+		}
+	}
+	for i := range want.Footnotes {
+		if got.Footnotes[i].StableID != want.Footnotes[i].StableID {
+			t.Errorf("Footnote %d: expected ID %s, got %s", i, want.Footnotes[i].StableID, got.Footnotes[i].StableID)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// StagePage keeps a page of a document's parse in progress. The page is stored
+// as JSON, encrypted like page content, with its Degraded flag, which the JSON
+// leaves out, in a column of its own.
+func (s *SQLiteStore) StagePage(ctx context.Context, docID string, page models.StagedPage) error {
+	if page.Page == nil {
+		return fmt.Errorf("no parsed page to stage for page %d", page.PageIndex+1)
+	}
+	data, err := json.Marshal(page.Page)
+	if err != nil {
+		return fmt.Errorf("failed to marshal staged page: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT OR REPLACE INTO staged_pages (document_id, page_index, content_hash, page, degraded)
+		VALUES (?, ?, ?, ?, ?)
+	`, docID, page.PageIndex, page.ContentHash, s.cipher.seal("staged_pages.page", docID, string(data)), page.Page.Degraded)
+	if err != nil {
+		return fmt.Errorf("failed to stage page: %w", err)
+	}
+	return nil
+}
+
+// GetStagedPages returns the pages staged for a document, ordered by index
+func (s *SQLiteStore) GetStagedPages(ctx context.Context, docID string) ([]models.StagedPage, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT page_index, content_hash, page, degraded
+		FROM staged_pages
+		WHERE document_id = ?
+		ORDER BY page_index
+	`, docID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query staged pages: %w", err)
+	}
+	defer rows.Close()
+
+	var pages []models.StagedPage
+	for rows.Next() {
+		var staged models.StagedPage
+		var data string
+		var degraded bool
+		if err := rows.Scan(&staged.PageIndex, &staged.ContentHash, &data, &degraded); err != nil {
+			return nil, fmt.Errorf("failed to scan staged page: %w", err)
+		}
+		if data, err = s.cipher.open("staged_pages.page", docID, data); err != nil {
+			return nil, err
+		}
+		staged.Page = &models.ParsedPage{}
+		if err := json.Unmarshal([]byte(data), staged.Page); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal staged page %d: %w", staged.PageIndex+1, err)
+		}
+		staged.Page.Degraded = degraded
+		pages = append(pages, staged)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating staged pages: %w", err)
+	}
+	return pages, nil
+}
+
+// ClearStagedPages removes the pages staged for a document
+func (s *SQLiteStore) ClearStagedPages(ctx context.Context, docID string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM staged_pages WHERE document_id = ?`, docID); err != nil {
+		return fmt.Errorf("failed to clear staged pages: %w", err)
+	}
+	return nil
+}
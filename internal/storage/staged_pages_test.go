@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestStagedPages(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	pages := []models.StagedPage{
+		{PageIndex: 2, ContentHash: "hash-2", Page: &models.ParsedPage{Content: "Third page", Degraded: true}},
+		{PageIndex: 0, ContentHash: "hash-0", Page: &models.ParsedPage{
+			Metadata:   models.ItemMetadata{Title: "A Long Report", Keywords: []string{"trust"}},
+			Content:    "First page",
+			References: []models.Reference{{ReferenceText: "Doe, J. (2019). A study."}},
+		}},
+	}
+	for _, page := range pages {
+		if err := store.StagePage(ctx, "url_1", page); err != nil {
+			t.Fatalf("StagePage failed: %v", err)
+		}
+	}
+	if err := store.StagePage(ctx, "url_2", models.StagedPage{PageIndex: 0, ContentHash: "other", Page: &models.ParsedPage{Content: "Other"}}); err != nil {
+		t.Fatalf("StagePage failed: %v", err)
+	}
+
+	got, err := store.GetStagedPages(ctx, "url_1")
+	if err != nil {
+		t.Fatalf("GetStagedPages failed: %v", err)
+	}
+	want := []models.StagedPage{pages[1], pages[0]}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetStagedPages =\n%+v\nwant\n%+v", got, want)
+	}
+
+	// Staging a page again replaces it
+	replaced := models.StagedPage{PageIndex: 2, ContentHash: "hash-2b", Page: &models.ParsedPage{Content: "Third page, reparsed"}}
+	if err := store.StagePage(ctx, "url_1", replaced); err != nil {
+		t.Fatalf("StagePage failed: %v", err)
+	}
+	if got, err = store.GetStagedPages(ctx, "url_1"); err != nil || len(got) != 2 || !reflect.DeepEqual(got[1], replaced) {
+		t.Errorf("Expected the page replaced, got %+v (%v)", got, err)
+	}
+
+	if err := store.ClearStagedPages(ctx, "url_1"); err != nil {
+		t.Fatalf("ClearStagedPages failed: %v", err)
+	}
+	if got, err = store.GetStagedPages(ctx, "url_1"); err != nil || len(got) != 0 {
+		t.Errorf("Expected no staged pages after clearing, got %+v (%v)", got, err)
+	}
+	if got, err = store.GetStagedPages(ctx, "url_2"); err != nil || len(got) != 1 {
+		t.Errorf("Expected another document's staged page kept, got %+v (%v)", got, err)
+	}
+
+	if err := store.StagePage(ctx, "url_1", models.StagedPage{PageIndex: 0}); err == nil {
+		t.Error("Expected an error staging no page")
+	}
+}
@@ -3,26 +3,228 @@ package storage
 import (
 	"context"
 	"crypto/sha256"
+	"errors"
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/Epistemic-Technology/academic-mcp/internal/citations"
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
 	"github.com/Epistemic-Technology/academic-mcp/models"
 )
 
+// ErrNotFound is wrapped by the errors returned when a requested document, or a
+// page, reference, or other part of one, does not exist
+var ErrNotFound = errors.New("not found")
+
 // GenerateDocumentID creates a unique document ID from source info and document data.
 // This function can be called before parsing to check if a document already exists.
-// Priority: Zotero ID > URL hash > document data hash
+// Priority: Zotero ID > arXiv ID > URL hash > document data hash
+// Items from a library include its type and ID (e.g., "zotero_group_222_ABCD1234"
+// or "zotero_user_111_ABCD1234") so identical item keys in different libraries
+// do not collide. An item without a library, which documents.DocumentIDSource
+// makes of the default user library, keeps the original "zotero_ABCD1234".
+// An arXiv abstract or PDF URL is identified by its arXiv identifier, with the
+// version if the URL names one (e.g., "arxiv_2101.01234v2").
+// URL and data IDs use the first 16 bytes of a SHA-256 hash; documents stored
+// before that have the shorter IDs of LegacyDocumentID. A document parsed from
+// a range of a PDF's pages has the range appended (e.g., "zotero_ABCD1234_p12-40"),
+// so each range of a file is a document of its own.
 func GenerateDocumentID(sourceInfo *models.SourceInfo, documentData models.DocumentData) string {
+	return documentID(sourceInfo, documentData, 16) + pageRangeSuffix(sourceInfo.Pages)
+}
+
+// LegacyDocumentID returns the ID GenerateDocumentID gave a URL or raw data
+// source when its hashes were truncated to 8 bytes, or "" for a page range,
+// which had no ID then. An arXiv URL gets the hash of the URL it was identified
+// by before arXiv IDs. An item of a user library was identified by its key
+// alone before user libraries were encoded, so any user library's item could
+// have that ID; the library recorded with it in document_sources tells which.
+// A group item's ID has not changed, so it has no legacy ID.
+func LegacyDocumentID(sourceInfo *models.SourceInfo, documentData models.DocumentData) string {
+	if sourceInfo.ZoteroID != "" {
+		if sourceInfo.ZoteroLibraryType != documents.ZoteroLibraryTypeUser || sourceInfo.ZoteroLibraryID == "" {
+			return ""
+		}
+		return "zotero_" + sourceInfo.ZoteroID + pageRangeSuffix(sourceInfo.Pages)
+	}
+	if sourceInfo.Pages.IsSet() {
+		return ""
+	}
+	if _, ok := citations.ParseArXivURL(sourceInfo.URL); ok {
+		return urlDocumentID(sourceInfo.URL, 16)
+	}
+	return documentID(sourceInfo, documentData, 8)
+}
+
+func documentID(sourceInfo *models.SourceInfo, documentData models.DocumentData, hashBytes int) string {
 	if sourceInfo.ZoteroID != "" {
+		switch sourceInfo.ZoteroLibraryType {
+		case documents.ZoteroLibraryTypeGroup, documents.ZoteroLibraryTypeUser:
+			if sourceInfo.ZoteroLibraryID != "" {
+				return fmt.Sprintf("zotero_%s_%s_%s", sourceInfo.ZoteroLibraryType, sourceInfo.ZoteroLibraryID, sourceInfo.ZoteroID)
+			}
+		}
 		return "zotero_" + sourceInfo.ZoteroID
 	}
 	if sourceInfo.URL != "" {
-		// Use SHA-256 hash of the URL
-		hash := sha256.Sum256([]byte(sourceInfo.URL))
-		return fmt.Sprintf("url_%x", hash[:8]) // Use first 8 bytes for shorter IDs
+		// The abstract and PDF pages of an arXiv paper are the same document
+		if arXivID, ok := citations.ParseArXivURL(sourceInfo.URL); ok {
+			return "arxiv_" + strings.ReplaceAll(arXivID, "/", "_")
+		}
+		return urlDocumentID(sourceInfo.URL, hashBytes)
+	}
+	// Fallback to the checksum of the document data
+	return "data_" + documentData.Checksum()[:2*hashBytes]
+}
+
+// urlDocumentID identifies a URL source by the SHA-256 hash of the URL
+func urlDocumentID(url string, hashBytes int) string {
+	hash := sha256.Sum256([]byte(url))
+	return fmt.Sprintf("url_%x", hash[:hashBytes])
+}
+
+// ContentHash returns the hex SHA-256 of document data, which identifies the
+// same file whether it was fetched from a URL, Zotero, or uploaded directly
+func ContentHash(data []byte) string {
+	hash := sha256.Sum256(data)
+	return fmt.Sprintf("%x", hash)
+}
+
+// DocumentContentHash returns the ContentHash of the data a document is parsed
+// from, with the page range appended for a range of a PDF, so that only a parse
+// of the same pages of the same file matches it
+func DocumentContentHash(data models.DocumentData) string {
+	return ContentHash(data.Data) + pageRangeSuffix(data.Pages)
+}
+
+// pageRangeSuffix returns the suffix identifying a page range in document IDs
+// and content hashes, or "" for a whole document
+func pageRangeSuffix(pages models.PageRange) string {
+	if !pages.IsSet() {
+		return ""
+	}
+	return "_p" + pages.String()
+}
+
+// pageRangeIDPattern matches the page range suffix of a document ID
+var pageRangeIDPattern = regexp.MustCompile(`_p(\d+)-(\d+|end)$`)
+
+// cutPageRange splits the page range suffix, if any, from a document ID
+func cutPageRange(docID string) (string, models.PageRange) {
+	match := pageRangeIDPattern.FindStringSubmatchIndex(docID)
+	if match == nil {
+		return docID, models.PageRange{}
+	}
+	var pages models.PageRange
+	pages.Start, _ = strconv.Atoi(docID[match[2]:match[3]])
+	pages.End, _ = strconv.Atoi(docID[match[4]:match[5]]) // 0 for "end"
+	return docID[:match[0]], pages
+}
+
+// ParsePageRangeDocumentID returns the page range a document ID was generated
+// for by GenerateDocumentID, unset if the document is a whole file
+func ParsePageRangeDocumentID(docID string) models.PageRange {
+	_, pages := cutPageRange(docID)
+	return pages
+}
+
+// ParseZoteroDocumentID extracts the Zotero source of a document ID created by
+// GenerateDocumentID, with its page range if it has one. The library fields are
+// empty for documents of the default user library, whose IDs do not encode the
+// library. The library prefix of LibraryDocumentID is ignored. Returns false
+// for non-Zotero document IDs.
+func ParseZoteroDocumentID(docID string) (models.SourceInfo, bool) {
+	_, docID = SplitLibraryDocumentID(docID)
+	docID, pages := cutPageRange(docID)
+	for _, libraryType := range []string{documents.ZoteroLibraryTypeGroup, documents.ZoteroLibraryTypeUser} {
+		rest, ok := strings.CutPrefix(docID, "zotero_"+libraryType+"_")
+		if !ok {
+			continue
+		}
+		libraryID, zoteroID, found := strings.Cut(rest, "_")
+		if !found || libraryID == "" || zoteroID == "" {
+			return models.SourceInfo{}, false
+		}
+		return models.SourceInfo{
+			ZoteroID:          zoteroID,
+			ZoteroLibraryType: libraryType,
+			ZoteroLibraryID:   libraryID,
+			Pages:             pages,
+		}, true
+	}
+	if zoteroID, ok := strings.CutPrefix(docID, "zotero_"); ok && zoteroID != "" {
+		return models.SourceInfo{ZoteroID: zoteroID, Pages: pages}, true
 	}
-	// Fallback to hash of document data
-	hash := sha256.Sum256(documentData.Data)
-	return fmt.Sprintf("data_%x", hash[:8])
+	return models.SourceInfo{}, false
+}
+
+// DefaultLibrary is the library of documents whose IDs have no library prefix,
+// including every document stored before libraries existed
+const DefaultLibrary = "default"
+
+// libraryNamePattern matches valid library names
+var libraryNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// ValidateLibrary checks that a library name can prefix document IDs: up to 64
+// lowercase letters, digits, hyphens, and underscores, starting with a letter
+// or digit
+func ValidateLibrary(library string) error {
+	if !libraryNamePattern.MatchString(library) {
+		return fmt.Errorf("invalid library name %q: use up to 64 lowercase letters, digits, hyphens, and underscores", library)
+	}
+	return nil
+}
+
+// LibraryDocumentID returns the ID of a document in a library. Documents in
+// DefaultLibrary keep the ID GenerateDocumentID gave them, so existing IDs and
+// URIs are unchanged; in any other library the ID is prefixed with the library
+// name and a colon (e.g., "thesis:zotero_ABCD1234"). An empty library is the
+// default one, and an ID that already names a library is returned as it is.
+func LibraryDocumentID(library string, docID string) string {
+	if strings.Contains(docID, ":") {
+		return NormalizeDocumentID(docID)
+	}
+	if library == "" || library == DefaultLibrary {
+		return docID
+	}
+	return library + ":" + docID
+}
+
+// NormalizeDocumentID removes an explicit "default:" prefix from a document ID,
+// which names the same document as the bare ID
+func NormalizeDocumentID(docID string) string {
+	if id, ok := strings.CutPrefix(docID, DefaultLibrary+":"); ok {
+		return id
+	}
+	return docID
+}
+
+// SplitLibraryDocumentID splits a document ID into its library and the ID
+// GenerateDocumentID gave it within the library
+func SplitLibraryDocumentID(docID string) (library string, id string) {
+	if library, id, ok := strings.Cut(docID, ":"); ok {
+		return library, id
+	}
+	return DefaultLibrary, docID
+}
+
+type libraryContextKey struct{}
+
+// NewLibraryContext returns a context whose parses store documents in library
+func NewLibraryContext(ctx context.Context, library string) context.Context {
+	return context.WithValue(ctx, libraryContextKey{}, library)
+}
+
+// LibraryFromContext returns the library set by NewLibraryContext, or
+// DefaultLibrary if there is none
+func LibraryFromContext(ctx context.Context) string {
+	if library, ok := ctx.Value(libraryContextKey{}).(string); ok && library != "" {
+		return library
+	}
+	return DefaultLibrary
 }
 
 // Store defines the interface for storing and retrieving parsed PDF data
@@ -33,15 +235,57 @@ type Store interface {
 	// GetMetadata retrieves metadata for a document by ID
 	GetMetadata(ctx context.Context, docID string) (*models.ItemMetadata, error)
 
+	// UpdateMetadata replaces the bibliographic metadata of a stored document,
+	// keeping its content, citekey, and sources
+	UpdateMetadata(ctx context.Context, docID string, metadata *models.ItemMetadata) error
+
+	// GetProvenance retrieves when a document was stored and how it was parsed
+	GetProvenance(ctx context.Context, docID string) (*models.Provenance, error)
+
+	// GetFetchInfo retrieves how the bytes a document was parsed from were fetched (nil if not recorded)
+	GetFetchInfo(ctx context.Context, docID string) (*models.FetchInfo, error)
+
+	// GetSourceInfo retrieves where a document was originally obtained from (Zotero item or URL)
+	GetSourceInfo(ctx context.Context, docID string) (*models.SourceInfo, error)
+
+	// GetSummary retrieves the stored standard summary for a document (empty if not summarized)
+	GetSummary(ctx context.Context, docID string) (string, error)
+
+	// GetStyledSummary retrieves the stored summary for a document in a style (empty if not summarized in it)
+	GetStyledSummary(ctx context.Context, docID string, style string) (string, error)
+
+	// GetSummaries retrieves every stored summary for a document, keyed by style
+	GetSummaries(ctx context.Context, docID string) (map[string]string, error)
+
+	// StoreSummary stores a document's summary in a style, replacing any earlier one in that style
+	StoreSummary(ctx context.Context, docID string, style string, summary string) error
+
+	// GetSectionSummaries retrieves a document's summary in a style composed from its section summaries, and those section summaries ("" and none if not summarized by section)
+	GetSectionSummaries(ctx context.Context, docID string, style string) (string, []models.SectionSummary, error)
+
+	// StoreSectionSummaries stores a document's section summaries in a style and the summary composed from them, replacing any earlier ones in that style
+	StoreSectionSummaries(ctx context.Context, docID string, style string, summary string, sections []models.SectionSummary) error
+
 	// GetPage retrieves a specific page by document ID and page number (1-indexed sequential)
 	GetPage(ctx context.Context, docID string, pageNum int) (string, error)
 
 	// GetPageBySourceNumber retrieves a page by its source page number (e.g., "125", "iv")
 	GetPageBySourceNumber(ctx context.Context, docID string, sourcePageNum string) (string, error)
+	// GetPageSentences retrieves the sentence offsets recorded for a page, by its
+	// source page number, or nil if none were recorded
+	GetPageSentences(ctx context.Context, docID string, sourcePageNum string) ([]models.TextSpan, error)
 
 	// GetPages retrieves all pages for a document
 	GetPages(ctx context.Context, docID string) ([]string, error)
 
+	// GetFullText retrieves a document's continuous text and the byte offset in it at
+	// which each page begins; both are empty if the document has no recorded full text
+	GetFullText(ctx context.Context, docID string) (string, []int, error)
+
+	// GetPageQuality retrieves the quality flags and assessment of each page, in
+	// page order; nil if no page was flagged or assessed (e.g., non-PDF documents)
+	GetPageQuality(ctx context.Context, docID string) ([]models.PageQuality, error)
+
 	// GetPageMapping returns a map of source page numbers to sequential page numbers
 	GetPageMapping(ctx context.Context, docID string) (map[string]int, error)
 
@@ -51,12 +295,61 @@ type Store interface {
 	// GetReference retrieves a specific reference by index (0-indexed)
 	GetReference(ctx context.Context, docID string, refIndex int) (*models.Reference, error)
 
+	// GetReferencesBySourcePage retrieves the references that appear on the page
+	// with the given source page number (e.g., "125", "iv"), in document order.
+	// A page without references gives an empty list; an unknown page is an
+	// ErrNotFound error.
+	GetReferencesBySourcePage(ctx context.Context, docID string, sourcePageNum string) ([]models.PageReference, error)
+
+	// GetReferenceLinks returns every link from a reference of a stored document
+	// to another stored document it cites
+	GetReferenceLinks(ctx context.Context) ([]models.ReferenceLink, error)
+
+	// FindRelatedDocuments returns up to limit documents of the same library
+	// most like a document, best first, by shared reference DOIs and authors,
+	// venue, and title, abstract, and keyword terms
+	FindRelatedDocuments(ctx context.Context, docID string, limit int) ([]models.RelatedDocument, error)
+
 	// GetImages retrieves all images for a document
 	GetImages(ctx context.Context, docID string) ([]models.Image, error)
 
 	// GetImage retrieves a specific image by index (0-indexed)
 	GetImage(ctx context.Context, docID string, imageIndex int) (*models.Image, error)
 
+	// CreateJob stores a job and its items, all pending. The caller sets the job ID.
+	CreateJob(ctx context.Context, job *models.Job) error
+
+	// GetJob retrieves a job with its items and their progress, or ErrNotFound
+	GetJob(ctx context.Context, jobID string) (*models.Job, error)
+
+	// ClaimJobItem marks the oldest pending job item running and returns it, or
+	// nil if none is pending
+	ClaimJobItem(ctx context.Context) (*models.JobItem, error)
+
+	// FinishJobItem records the outcome of a running job item (its Status,
+	// DocumentID, Error, and ErrorCode) and drops its raw data
+	FinishJobItem(ctx context.Context, item *models.JobItem) error
+
+	// CancelJob cancels a job's pending items, returning how many were cancelled,
+	// or ErrNotFound if there is no such job
+	CancelJob(ctx context.Context, jobID string) (int, error)
+
+	// RequeueRunningJobItems returns items left running by a process that stopped
+	// to pending, returning how many there were
+	RequeueRunningJobItems(ctx context.Context) (int, error)
+
+	// GetImageData retrieves the bytes and MIME type of an image extracted from the
+	// document (0-indexed), or ErrNotFound if none was stored
+	GetImageData(ctx context.Context, docID string, imageIndex int) ([]byte, string, error)
+
+	// GetPageImage retrieves the PNG rendered of a document's page (1-indexed) at
+	// dpi, or ErrNotFound if it has not been rendered
+	GetPageImage(ctx context.Context, docID string, pageNum int, dpi int) ([]byte, error)
+
+	// StorePageImage stores the PNG rendered of a document's page (1-indexed) at
+	// dpi, replacing any stored before
+	StorePageImage(ctx context.Context, docID string, pageNum int, dpi int, data []byte) error
+
 	// GetTables retrieves all tables for a document
 	GetTables(ctx context.Context, docID string) ([]models.Table, error)
 
@@ -78,11 +371,27 @@ type Store interface {
 	// GetQuotations retrieves all quotations for a document
 	GetQuotations(ctx context.Context, docID string) ([]models.Quotation, error)
 
+	// SelectQuotations marks the quotations of a document at indexes (0-indexed)
+	// selected
+	SelectQuotations(ctx context.Context, docID string, indexes []int) error
+
 	// GetQuotation retrieves a specific quotation by index (0-indexed)
 	GetQuotation(ctx context.Context, docID string, quotationIndex int) (*models.Quotation, error)
 
-	// ListDocuments returns a list of all stored document IDs with their metadata
-	ListDocuments(ctx context.Context) ([]models.DocumentInfo, error)
+	// GetSections retrieves the section index for a document
+	GetSections(ctx context.Context, docID string) ([]models.Section, error)
+
+	// GetSection retrieves a specific section by index (0-indexed)
+	GetSection(ctx context.Context, docID string, sectionIndex int) (*models.Section, error)
+
+	// ListDocuments returns a list of all stored document IDs with their metadata,
+	// in library, or in every library if library is empty
+	ListDocuments(ctx context.Context, library string) ([]models.DocumentInfo, error)
+
+	// SearchDocuments returns the page of stored documents selected by search's
+	// limit and offset among those matching its bibliographic fields, most
+	// recently added first, and how many match in all
+	SearchDocuments(ctx context.Context, search models.LibrarySearch) ([]models.LibrarySearchResult, int, error)
 
 	// DeleteDocument removes a document and all associated data
 	DeleteDocument(ctx context.Context, docID string) error
@@ -90,14 +399,141 @@ type Store interface {
 	// DocumentExists checks if a document with the given ID already exists
 	DocumentExists(ctx context.Context, docID string) (bool, error)
 
+	// IsPartial reports whether a document was parsed for its metadata only
+	IsPartial(ctx context.Context, docID string) (bool, error)
+
+	// ResolveStableID returns the index (0-indexed) of the image, table, or
+	// footnote of a document with a stable ID; resourceType is "images",
+	// "tables", or "footnotes"
+	ResolveStableID(ctx context.Context, docID, resourceType, stableID string) (int, error)
+
+	// GetPageNumbering returns how a document's page numbers were assigned:
+	// "source", "sequential", or "" if they were not validated
+	GetPageNumbering(ctx context.Context, docID string) (string, error)
+
+	// GetTags retrieves the Zotero tags of a document, in alphabetical order
+	GetTags(ctx context.Context, docID string) ([]string, error)
+
+	// SetTags replaces the Zotero tags of a document, as after they are changed
+	// in Zotero
+	SetTags(ctx context.Context, docID string, tags []string) error
+
 	// GetParsedItem retrieves a complete ParsedItem for a document by ID
 	GetParsedItem(ctx context.Context, docID string) (*models.ParsedItem, error)
 
-	// GetCitekeyMap retrieves all docID→citekey mappings
-	GetCitekeyMap(ctx context.Context) (map[string]string, error)
+	// GetCitekeyMap retrieves the docID→citekey mappings of library, or of every
+	// library if library is empty
+	GetCitekeyMap(ctx context.Context, library string) (map[string]string, error)
+
+	// GetDocumentByCitekey retrieves a document ID by its citekey in library.
+	// Citekeys are unique within a library; if library is empty, the earliest
+	// document with the citekey in any library is returned.
+	GetDocumentByCitekey(ctx context.Context, library string, citekey string) (string, error)
+
+	// SetCitekey replaces a document's citekey
+	SetCitekey(ctx context.Context, docID string, citekey string) error
+
+	// SetCitekeys replaces the citekeys of several documents (docID→citekey) at
+	// once, so documents can trade citekeys; if one cannot be set, none are
+	SetCitekeys(ctx context.Context, citekeys map[string]string) error
+
+	// VerifyStore checks the store for orphaned rows, fully parsed documents
+	// without pages, gaps in page numbers, and citekeys shared ignoring case,
+	// and with repair fixes what it safely can in one transaction, giving
+	// documents new citekeys generated in mode
+	VerifyStore(ctx context.Context, repair bool, mode citations.CitekeyMode) (*models.VerifyReport, error)
+
+	// FindDocumentByDOI returns the ID of a stored document in library with the
+	// same DOI, ignoring case and resolver prefixes, or "" if there is none.
+	// Like the other finders, it searches every library if library is empty.
+	FindDocumentByDOI(ctx context.Context, library string, doi string) (string, error)
+
+	// FindDocumentByContentHash returns the ID of a stored document in library
+	// parsed from data with the given content hash (see ContentHash), or "" if
+	// there is none
+	FindDocumentByContentHash(ctx context.Context, library string, hash string) (string, error)
+
+	// FindDocumentByTitleAuthorYear returns the ID of a stored document in
+	// library with the same normalized title, first author family name, and
+	// publication year, or "" if there is none
+	FindDocumentByTitleAuthorYear(ctx context.Context, library string, title string, firstAuthor string, year string) (string, error)
+
+	// AddDocumentSource records another source of a stored document. sourceID is
+	// the document ID the source alone would be given (see GenerateDocumentID).
+	AddDocumentSource(ctx context.Context, docID string, sourceID string, sourceInfo *models.SourceInfo) error
+
+	// GetDocumentBySource returns the ID of the document a source is recorded
+	// against, or "" if the source is unknown
+	GetDocumentBySource(ctx context.Context, sourceID string) (string, error)
+
+	// GetDocumentSources lists the sources of a document, oldest first
+	GetDocumentSources(ctx context.Context, docID string) ([]models.DocumentSource, error)
+
+	// GetLibraryStats computes aggregate statistics across the documents of
+	// library, or all stored documents if library is empty, including the
+	// topAuthors most frequent authors
+	GetLibraryStats(ctx context.Context, library string, topAuthors int) (*models.LibraryStats, error)
+
+	// CountDocuments returns the number of stored documents
+	CountDocuments(ctx context.Context) (int, error)
+
+	// SchemaVersion returns the highest schema migration applied to the database
+	SchemaVersion(ctx context.Context) (int, error)
+
+	// GetAuthors lists the distinct authors in library, or in every library if
+	// library is empty, with the documents attributed to each, most documents first
+	GetAuthors(ctx context.Context, library string) ([]models.LibraryAuthor, error)
+
+	// RecordUsage adds the OpenAI usage of an operation ("parse", "summarize", ...)
+	// on a document to the totals stored for it
+	RecordUsage(ctx context.Context, docID string, operation string, usage []models.TokenUsage) error
+
+	// GetUsage returns the stored OpenAI usage of a document by operation and model,
+	// or that of the whole library if docID is empty
+	GetUsage(ctx context.Context, docID string) ([]models.TokenUsage, error)
+
+	// GetLibraryUsage returns the stored OpenAI usage of the documents in
+	// library by operation and model, or that of every library if library is empty
+	GetLibraryUsage(ctx context.Context, library string) ([]models.TokenUsage, error)
+
+	// AddAnnotation stores a new annotation on a document and returns it with its
+	// ID and timestamps
+	AddAnnotation(ctx context.Context, annotation models.Annotation) (*models.Annotation, error)
+
+	// GetAnnotations lists a document's annotations, oldest first
+	GetAnnotations(ctx context.Context, docID string) ([]models.Annotation, error)
+
+	// UpdateAnnotation replaces the text of one of a document's annotations
+	UpdateAnnotation(ctx context.Context, docID string, id int64, text string) (*models.Annotation, error)
+
+	// DeleteAnnotation removes one of a document's annotations
+	DeleteAnnotation(ctx context.Context, docID string, id int64) error
+
+	// GetZoteroCache returns a cached Zotero API response and when it was
+	// fetched, or nil data if nothing is cached under the key
+	GetZoteroCache(ctx context.Context, key string) ([]byte, time.Time, error)
+
+	// PutZoteroCache caches a Zotero API response under the key
+	PutZoteroCache(ctx context.Context, key string, data []byte) error
+
+	// AcquireParseLock marks a document as being parsed by owner until ttl from
+	// now, reporting false if another owner's marker has not yet expired. An
+	// owner that already holds the marker extends it.
+	AcquireParseLock(ctx context.Context, docID, owner string, ttl time.Duration) (bool, error)
+
+	// ReleaseParseLock removes owner's marker for a document, if it still holds it
+	ReleaseParseLock(ctx context.Context, docID, owner string) error
+
+	// StagePage keeps a page of a document's parse in progress, replacing any
+	// page staged before at its index
+	StagePage(ctx context.Context, docID string, page models.StagedPage) error
+
+	// GetStagedPages returns the pages staged for a document, ordered by index
+	GetStagedPages(ctx context.Context, docID string) ([]models.StagedPage, error)
 
-	// GetDocumentByCitekey retrieves a document ID by its citekey
-	GetDocumentByCitekey(ctx context.Context, citekey string) (string, error)
+	// ClearStagedPages removes the pages staged for a document, once its parse
+	// has finished
+	ClearStagedPages(ctx context.Context, docID string) error
 
 	// Close closes the database connection
 	Close() error
@@ -0,0 +1,217 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestGenerateDocumentID(t *testing.T) {
+	tests := []struct {
+		name       string
+		sourceInfo models.SourceInfo
+		want       string
+	}{
+		{
+			name:       "Zotero item without library",
+			sourceInfo: models.SourceInfo{ZoteroID: "ABCD1234"},
+			want:       "zotero_ABCD1234",
+		},
+		{
+			name:       "Zotero item in user library",
+			sourceInfo: models.SourceInfo{ZoteroID: "ABCD1234", ZoteroLibraryType: "user", ZoteroLibraryID: "111"},
+			want:       "zotero_user_111_ABCD1234",
+		},
+		{
+			name:       "Zotero item in group library",
+			sourceInfo: models.SourceInfo{ZoteroID: "ABCD1234", ZoteroLibraryType: "group", ZoteroLibraryID: "222"},
+			want:       "zotero_group_222_ABCD1234",
+		},
+		{
+			name:       "URL",
+			sourceInfo: models.SourceInfo{URL: "https://example.com/paper.pdf"},
+			want:       "url_065f30cd516e3c8b8f7987803fe0b6ef",
+		},
+		{
+			name:       "arXiv abstract page",
+			sourceInfo: models.SourceInfo{URL: "https://arxiv.org/abs/2101.01234v2"},
+			want:       "arxiv_2101.01234v2",
+		},
+		{
+			name:       "arXiv PDF of the same version",
+			sourceInfo: models.SourceInfo{URL: "https://arxiv.org/pdf/2101.01234v2.pdf"},
+			want:       "arxiv_2101.01234v2",
+		},
+		{
+			name:       "old style arXiv identifier",
+			sourceInfo: models.SourceInfo{URL: "https://arxiv.org/abs/hep-th/9901001", Pages: models.PageRange{Start: 2, End: 5}},
+			want:       "arxiv_hep-th_9901001_p2-5",
+		},
+		{
+			name: "raw data",
+			want: "data_2cf24dba5fb0a30e26e83b2ac5b9e29e",
+		},
+		{
+			name:       "page range of a Zotero item",
+			sourceInfo: models.SourceInfo{ZoteroID: "ABCD1234", Pages: models.PageRange{Start: 12, End: 40}},
+			want:       "zotero_ABCD1234_p12-40",
+		},
+		{
+			name:       "page range to the end of raw data",
+			sourceInfo: models.SourceInfo{Pages: models.PageRange{Start: 480}},
+			want:       "data_2cf24dba5fb0a30e26e83b2ac5b9e29e_p480-end",
+		},
+		{
+			name:       "range of every page",
+			sourceInfo: models.SourceInfo{URL: "https://example.com/paper.pdf", Pages: models.PageRange{Start: 1}},
+			want:       "url_065f30cd516e3c8b8f7987803fe0b6ef",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GenerateDocumentID(&tt.sourceInfo, models.DocumentData{Data: []byte("hello")})
+			if got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("Raw data is identified by its checksum", func(t *testing.T) {
+		data := models.DocumentData{Data: []byte("hello"), Fetch: &models.FetchInfo{SHA256: "00112233445566778899aabbccddeeff00112233445566778899aabbccddeeff"}}
+		if got := GenerateDocumentID(&models.SourceInfo{}, data); got != "data_00112233445566778899aabbccddeeff" {
+			t.Errorf("Expected the ID from the recorded checksum, got %s", got)
+		}
+	})
+
+	t.Run("Same key in different groups does not collide", func(t *testing.T) {
+		a := GenerateDocumentID(&models.SourceInfo{ZoteroID: "KEY", ZoteroLibraryType: "group", ZoteroLibraryID: "1"}, models.DocumentData{})
+		b := GenerateDocumentID(&models.SourceInfo{ZoteroID: "KEY", ZoteroLibraryType: "group", ZoteroLibraryID: "2"}, models.DocumentData{})
+		if a == b {
+			t.Errorf("Expected different IDs, both were %s", a)
+		}
+	})
+
+	t.Run("Same key in different user libraries does not collide", func(t *testing.T) {
+		a := GenerateDocumentID(&models.SourceInfo{ZoteroID: "KEY", ZoteroLibraryType: "user", ZoteroLibraryID: "1"}, models.DocumentData{})
+		b := GenerateDocumentID(&models.SourceInfo{ZoteroID: "KEY", ZoteroLibraryType: "user", ZoteroLibraryID: "2"}, models.DocumentData{})
+		if a == b {
+			t.Errorf("Expected different IDs, both were %s", a)
+		}
+	})
+}
+
+func TestLegacyDocumentID(t *testing.T) {
+	data := models.DocumentData{Data: []byte("hello")}
+	tests := []struct {
+		sourceInfo models.SourceInfo
+		want       string
+	}{
+		{models.SourceInfo{ZoteroID: "ABCD1234"}, ""},
+		{models.SourceInfo{ZoteroID: "ABCD1234", ZoteroLibraryType: "user", ZoteroLibraryID: "111"}, "zotero_ABCD1234"},
+		{models.SourceInfo{ZoteroID: "ABCD1234", ZoteroLibraryType: "user", ZoteroLibraryID: "111", Pages: models.PageRange{Start: 2, End: 3}}, "zotero_ABCD1234_p2-3"},
+		{models.SourceInfo{ZoteroID: "ABCD1234", ZoteroLibraryType: "group", ZoteroLibraryID: "222"}, ""},
+		{models.SourceInfo{URL: "https://example.com/paper.pdf"}, "url_065f30cd516e3c8b"},
+		{models.SourceInfo{URL: "https://arxiv.org/abs/2101.01234"}, urlDocumentID("https://arxiv.org/abs/2101.01234", 16)},
+		{models.SourceInfo{}, "data_2cf24dba5fb0a30e"},
+		{models.SourceInfo{Pages: models.PageRange{Start: 2, End: 3}}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := LegacyDocumentID(&tt.sourceInfo, data); got != tt.want {
+			t.Errorf("LegacyDocumentID(%+v): expected %q, got %q", tt.sourceInfo, tt.want, got)
+		}
+	}
+}
+
+func TestParseZoteroDocumentID(t *testing.T) {
+	tests := []struct {
+		docID  string
+		want   models.SourceInfo
+		wantOK bool
+	}{
+		{"zotero_ABCD1234", models.SourceInfo{ZoteroID: "ABCD1234"}, true},
+		{"zotero_group_222_ABCD1234", models.SourceInfo{ZoteroID: "ABCD1234", ZoteroLibraryType: "group", ZoteroLibraryID: "222"}, true},
+		{"zotero_user_111_ABCD1234", models.SourceInfo{ZoteroID: "ABCD1234", ZoteroLibraryType: "user", ZoteroLibraryID: "111"}, true},
+		{"zotero_group_222_ABCD1234_p5-end", models.SourceInfo{ZoteroID: "ABCD1234", ZoteroLibraryType: "group", ZoteroLibraryID: "222", Pages: models.PageRange{Start: 5}}, true},
+		{"zotero_ABCD1234_p1-20", models.SourceInfo{ZoteroID: "ABCD1234", Pages: models.PageRange{Start: 1, End: 20}}, true},
+		{"thesis:zotero_ABCD1234", models.SourceInfo{ZoteroID: "ABCD1234"}, true},
+		{"zotero_group_222", models.SourceInfo{}, false},
+		{"url_0123456789abcdef", models.SourceInfo{}, false},
+		{"zotero_", models.SourceInfo{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.docID, func(t *testing.T) {
+			got, ok := ParseZoteroDocumentID(tt.docID)
+			if ok != tt.wantOK {
+				t.Fatalf("Expected ok=%v, got %v", tt.wantOK, ok)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("Round trip", func(t *testing.T) {
+		source := models.SourceInfo{ZoteroID: "KEY1", ZoteroLibraryType: "group", ZoteroLibraryID: "987", Pages: models.PageRange{Start: 3, End: 9}}
+		got, ok := ParseZoteroDocumentID(GenerateDocumentID(&source, models.DocumentData{}))
+		if !ok || got != source {
+			t.Errorf("Expected %+v, got %+v", source, got)
+		}
+	})
+}
+
+func TestLibraryDocumentID(t *testing.T) {
+	tests := []struct {
+		library string
+		docID   string
+		want    string
+	}{
+		{"", "zotero_ABCD1234", "zotero_ABCD1234"},
+		{DefaultLibrary, "zotero_ABCD1234", "zotero_ABCD1234"},
+		{"thesis", "zotero_ABCD1234", "thesis:zotero_ABCD1234"},
+		{"thesis", "thesis:zotero_ABCD1234", "thesis:zotero_ABCD1234"},
+		{"thesis", "default:url_1", "url_1"},
+		{"", "default:url_1", "url_1"},
+	}
+	for _, tt := range tests {
+		if got := LibraryDocumentID(tt.library, tt.docID); got != tt.want {
+			t.Errorf("LibraryDocumentID(%q, %q): expected %q, got %q", tt.library, tt.docID, tt.want, got)
+		}
+	}
+
+	if library, id := SplitLibraryDocumentID("thesis:url_1_p2-5"); library != "thesis" || id != "url_1_p2-5" {
+		t.Errorf("Expected thesis and url_1_p2-5, got %q and %q", library, id)
+	}
+	if library, id := SplitLibraryDocumentID("url_1"); library != DefaultLibrary || id != "url_1" {
+		t.Errorf("Expected the default library, got %q and %q", library, id)
+	}
+
+	for _, library := range []string{"thesis", "lab-2024", "a_b"} {
+		if err := ValidateLibrary(library); err != nil {
+			t.Errorf("ValidateLibrary(%q): %v", library, err)
+		}
+	}
+	for _, library := range []string{"", "Thesis", "my library", "a:b", "-x", strings.Repeat("a", 65)} {
+		if err := ValidateLibrary(library); err == nil {
+			t.Errorf("Expected ValidateLibrary(%q) to fail", library)
+		}
+	}
+}
+
+func TestDocumentContentHash(t *testing.T) {
+	whole := DocumentContentHash(models.DocumentData{Data: []byte("hello")})
+	if whole != ContentHash([]byte("hello")) {
+		t.Errorf("Expected the plain content hash for a whole document, got %s", whole)
+	}
+	ranged := DocumentContentHash(models.DocumentData{Data: []byte("hello"), Pages: models.PageRange{Start: 2, End: 5}})
+	other := DocumentContentHash(models.DocumentData{Data: []byte("hello"), Pages: models.PageRange{Start: 6, End: 9}})
+	if ranged == whole || ranged == other {
+		t.Errorf("Expected each range to hash differently, got %s and %s", ranged, other)
+	}
+	if got := ParsePageRangeDocumentID("data_2cf24dba5fb0a30e_p2-5"); got != (models.PageRange{Start: 2, End: 5}) {
+		t.Errorf("Expected range 2-5, got %+v", got)
+	}
+}
@@ -0,0 +1,222 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// GetStyledSummary retrieves the summary for a document in a style, or "" if
+// the document has not been summarized in it
+func (s *SQLiteStore) GetStyledSummary(ctx context.Context, docID string, style string) (string, error) {
+	var summary string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COALESCE(s.summary, '') FROM documents d
+		LEFT JOIN summaries s ON s.document_id = d.id AND s.style = ?
+		WHERE d.id = ?
+	`, style, docID).Scan(&summary)
+
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("document %w: %s", ErrNotFound, docID)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to query summary: %w", err)
+	}
+
+	return summary, nil
+}
+
+// GetSummaries retrieves every summary of a document, keyed by style
+func (s *SQLiteStore) GetSummaries(ctx context.Context, docID string) (map[string]string, error) {
+	exists, err := s.DocumentExists(ctx, docID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("document %w: %s", ErrNotFound, docID)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT style, summary FROM summaries WHERE document_id = ?`, docID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query summaries: %w", err)
+	}
+	defer rows.Close()
+
+	summaries := make(map[string]string)
+	for rows.Next() {
+		var style, summary string
+		if err := rows.Scan(&style, &summary); err != nil {
+			return nil, fmt.Errorf("failed to scan summary: %w", err)
+		}
+		summaries[style] = summary
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating summaries: %w", err)
+	}
+	return summaries, nil
+}
+
+// StoreSummary stores a document's summary in a style, replacing its earlier
+// summary in that style only
+func (s *SQLiteStore) StoreSummary(ctx context.Context, docID string, style string, summary string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM documents WHERE id = ?)`, docID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check document existence: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("document %w: %s", ErrNotFound, docID)
+	}
+	if err := storeSummary(ctx, tx, docID, style, summary); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// storeSummary replaces a document's summary in a style; an empty summary
+// removes it
+func storeSummary(ctx context.Context, tx *sql.Tx, docID string, style string, summary string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM summaries WHERE document_id = ? AND style = ?`, docID, style); err != nil {
+		return fmt.Errorf("failed to clear summary: %w", err)
+	}
+	if summary == "" {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO summaries (document_id, style, summary) VALUES (?, ?, ?)`, docID, style, summary); err != nil {
+		return fmt.Errorf("failed to store summary: %w", err)
+	}
+	return nil
+}
+
+// GetSectionSummaries retrieves a document's summary in a style composed from
+// its section summaries, with the section summaries in section order. A
+// document that has not been summarized by section in the style returns "" and
+// no sections.
+func (s *SQLiteStore) GetSectionSummaries(ctx context.Context, docID string, style string) (string, []models.SectionSummary, error) {
+	var summary string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COALESCE(c.summary, '') FROM documents d
+		LEFT JOIN composed_summaries c ON c.document_id = d.id AND c.style = ?
+		WHERE d.id = ?
+	`, style, docID).Scan(&summary)
+	if err == sql.ErrNoRows {
+		return "", nil, fmt.Errorf("document %w: %s", ErrNotFound, docID)
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to query composed summary: %w", err)
+	}
+	if summary == "" {
+		return "", nil, nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT section_index, COALESCE(title, ''), COALESCE(start_page, ''), COALESCE(end_page, ''), summary
+		FROM section_summaries
+		WHERE document_id = ? AND style = ?
+		ORDER BY section_index
+	`, docID, style)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to query section summaries: %w", err)
+	}
+	defer rows.Close()
+
+	var sections []models.SectionSummary
+	for rows.Next() {
+		var section models.SectionSummary
+		if err := rows.Scan(&section.SectionIndex, &section.Title, &section.StartPage, &section.EndPage, &section.Summary); err != nil {
+			return "", nil, fmt.Errorf("failed to scan section summary: %w", err)
+		}
+		sections = append(sections, section)
+	}
+	if err := rows.Err(); err != nil {
+		return "", nil, fmt.Errorf("error iterating section summaries: %w", err)
+	}
+	return summary, sections, nil
+}
+
+// StoreSectionSummaries stores a document's section summaries in a style and
+// the summary composed from them, replacing those stored earlier in that style
+func (s *SQLiteStore) StoreSectionSummaries(ctx context.Context, docID string, style string, summary string, sections []models.SectionSummary) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM documents WHERE id = ?)`, docID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check document existence: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("document %w: %s", ErrNotFound, docID)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM section_summaries WHERE document_id = ? AND style = ?`, docID, style); err != nil {
+		return fmt.Errorf("failed to clear section summaries: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM composed_summaries WHERE document_id = ? AND style = ?`, docID, style); err != nil {
+		return fmt.Errorf("failed to clear composed summary: %w", err)
+	}
+	for _, section := range sections {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO section_summaries (document_id, style, section_index, title, start_page, end_page, summary)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, docID, style, section.SectionIndex, section.Title, section.StartPage, section.EndPage, section.Summary); err != nil {
+			return fmt.Errorf("failed to store summary of section %d: %w", section.SectionIndex, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO composed_summaries (document_id, style, summary) VALUES (?, ?, ?)`, docID, style, summary); err != nil {
+		return fmt.Errorf("failed to store composed summary: %w", err)
+	}
+	return tx.Commit()
+}
+
+// clearStaleSectionSummaries removes a document's section summaries unless
+// sections is the section index they were written from, the one stored
+func clearStaleSectionSummaries(ctx context.Context, tx *sql.Tx, docID string, sections []models.Section) error {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT title, level, start_page, end_page, start_page_index, end_page_index, start_offset, end_offset
+		FROM sections
+		WHERE document_id = ?
+		ORDER BY section_index
+	`, docID)
+	if err != nil {
+		return fmt.Errorf("failed to query sections: %w", err)
+	}
+	defer rows.Close()
+
+	var stored []models.Section
+	for rows.Next() {
+		var sec models.Section
+		if err := rows.Scan(&sec.Title, &sec.Level, &sec.StartPage, &sec.EndPage,
+			&sec.StartPageIndex, &sec.EndPageIndex, &sec.StartOffset, &sec.EndOffset); err != nil {
+			return fmt.Errorf("failed to scan section: %w", err)
+		}
+		stored = append(stored, sec)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating sections: %w", err)
+	}
+	if slices.Equal(stored, sections) {
+		return nil
+	}
+	return clearSectionSummaries(ctx, tx, docID)
+}
+
+// clearSectionSummaries removes a document's section summaries in every style
+func clearSectionSummaries(ctx context.Context, tx *sql.Tx, docID string) error {
+	for _, table := range []string{"section_summaries", "composed_summaries"} {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE document_id = ?", table), docID); err != nil {
+			return fmt.Errorf("failed to clear %s: %w", table, err)
+		}
+	}
+	return nil
+}
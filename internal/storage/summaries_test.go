@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestSummaries(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	item := syntheticItem(1)
+	item.Summary = "Standard summary"
+	if err := store.StoreParsedItem(ctx, "doc", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	if err := store.StoreSummary(ctx, "doc", models.SummaryStyleBrief, "Brief summary"); err != nil {
+		t.Fatalf("StoreSummary failed: %v", err)
+	}
+	if err := store.StoreSummary(ctx, "doc", models.SummaryStyleStructured, "## Aims\nOld"); err != nil {
+		t.Fatalf("StoreSummary failed: %v", err)
+	}
+	if err := store.StoreSummary(ctx, "doc", models.SummaryStyleStructured, "## Aims\nNew"); err != nil {
+		t.Fatalf("StoreSummary failed: %v", err)
+	}
+
+	tests := []struct {
+		style    string
+		expected string
+	}{
+		{models.SummaryStyleStandard, "Standard summary"},
+		{models.SummaryStyleBrief, "Brief summary"},
+		{models.SummaryStyleStructured, "## Aims\nNew"},
+		{models.SummaryStyleAccessible, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.style, func(t *testing.T) {
+			summary, err := store.GetStyledSummary(ctx, "doc", tt.style)
+			if err != nil {
+				t.Fatalf("GetStyledSummary failed: %v", err)
+			}
+			if summary != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, summary)
+			}
+		})
+	}
+
+	// Re-storing the document replaces its standard summary and keeps the others
+	item.Summary = "Revised summary"
+	if err := store.StoreParsedItem(ctx, "doc", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	summaries, err := store.GetSummaries(ctx, "doc")
+	if err != nil {
+		t.Fatalf("GetSummaries failed: %v", err)
+	}
+	expected := map[string]string{
+		models.SummaryStyleStandard:   "Revised summary",
+		models.SummaryStyleBrief:      "Brief summary",
+		models.SummaryStyleStructured: "## Aims\nNew",
+	}
+	if !reflect.DeepEqual(summaries, expected) {
+		t.Errorf("Expected %v, got %v", expected, summaries)
+	}
+	if got, err := store.GetParsedItem(ctx, "doc"); err != nil || got.Summary != "Revised summary" {
+		t.Errorf("Expected the standard summary on the parsed item, got %q (%v)", got.Summary, err)
+	}
+
+	// Summaries go with the document
+	if err := store.DeleteDocument(ctx, "doc"); err != nil {
+		t.Fatalf("DeleteDocument failed: %v", err)
+	}
+	var count int
+	if err := store.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM summaries`).Scan(&count); err != nil || count != 0 {
+		t.Errorf("Expected no summaries after deleting the document, got %d (%v)", count, err)
+	}
+}
+
+func TestSummaries_UnknownDocument(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	if _, err := store.GetStyledSummary(ctx, "missing", models.SummaryStyleBrief); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetStyledSummary: expected ErrNotFound, got %v", err)
+	}
+	if _, err := store.GetSummaries(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetSummaries: expected ErrNotFound, got %v", err)
+	}
+	if err := store.StoreSummary(ctx, "missing", models.SummaryStyleBrief, "Brief"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("StoreSummary: expected ErrNotFound, got %v", err)
+	}
+	if _, _, err := store.GetSectionSummaries(ctx, "missing", models.SummaryStyleBrief); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetSectionSummaries: expected ErrNotFound, got %v", err)
+	}
+	if err := store.StoreSectionSummaries(ctx, "missing", models.SummaryStyleBrief, "Brief", nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("StoreSectionSummaries: expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestSectionSummaries(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	item := syntheticItem(2)
+	if err := store.StoreParsedItem(ctx, "doc", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	if summary, sections, err := store.GetSectionSummaries(ctx, "doc", models.SummaryStyleStandard); err != nil || summary != "" || sections != nil {
+		t.Errorf("Expected no section summaries, got %q, %+v, %v", summary, sections, err)
+	}
+
+	old := []models.SectionSummary{{SectionIndex: 0, Title: "Old", Summary: "Old section."}}
+	if err := store.StoreSectionSummaries(ctx, "doc", models.SummaryStyleStandard, "Old summary", old); err != nil {
+		t.Fatalf("StoreSectionSummaries failed: %v", err)
+	}
+	sections := []models.SectionSummary{
+		{SectionIndex: 3, Title: "Methods", StartPage: "2", EndPage: "4", Summary: "Methods section."},
+		{SectionIndex: 1, Title: "Introduction", StartPage: "1", EndPage: "1", Summary: "Introduction section."},
+	}
+	if err := store.StoreSectionSummaries(ctx, "doc", models.SummaryStyleStandard, "Composed summary", sections); err != nil {
+		t.Fatalf("StoreSectionSummaries failed: %v", err)
+	}
+	if err := store.StoreSectionSummaries(ctx, "doc", models.SummaryStyleBrief, "Brief summary", sections[:1]); err != nil {
+		t.Fatalf("StoreSectionSummaries failed: %v", err)
+	}
+
+	// The summaries replace earlier ones in their style, in section order
+	summary, got, err := store.GetSectionSummaries(ctx, "doc", models.SummaryStyleStandard)
+	if err != nil {
+		t.Fatalf("GetSectionSummaries failed: %v", err)
+	}
+	expected := []models.SectionSummary{sections[1], sections[0]}
+	if summary != "Composed summary" || !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected the composed summary with %+v, got %q with %+v", expected, summary, got)
+	}
+	if summary, got, _ := store.GetSectionSummaries(ctx, "doc", models.SummaryStyleBrief); summary != "Brief summary" || len(got) != 1 {
+		t.Errorf("Expected the brief summary with one section, got %q with %+v", summary, got)
+	}
+	// They are kept apart from the whole-document summaries
+	if summaries, err := store.GetSummaries(ctx, "doc"); err != nil || len(summaries) != 0 {
+		t.Errorf("Expected no whole-document summaries, got %v (%v)", summaries, err)
+	}
+
+	// Re-storing the document with the same section index keeps them
+	item.Quotations = []models.Quotation{{QuotationText: "Quoted", PageNumber: "1"}}
+	if err := store.StoreParsedItem(ctx, "doc", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	if summary, got, err := store.GetSectionSummaries(ctx, "doc", models.SummaryStyleStandard); err != nil || summary != "Composed summary" || len(got) != 2 {
+		t.Errorf("Expected the section summaries kept, got %q, %+v, %v", summary, got, err)
+	}
+
+	// A changed section index makes them stale
+	item.Sections = append(item.Sections, models.Section{Title: "Appendix", Level: 1, StartOffset: 10, EndOffset: 20})
+	if err := store.StoreParsedItem(ctx, "doc", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	if summary, got, err := store.GetSectionSummaries(ctx, "doc", models.SummaryStyleBrief); err != nil || summary != "" || got != nil {
+		t.Errorf("Expected no section summaries after the section index changed, got %q, %+v, %v", summary, got, err)
+	}
+
+	// They go with the document
+	if err := store.StoreSectionSummaries(ctx, "doc", models.SummaryStyleStandard, "Composed summary", sections); err != nil {
+		t.Fatalf("StoreSectionSummaries failed: %v", err)
+	}
+	if err := store.DeleteDocument(ctx, "doc"); err != nil {
+		t.Fatalf("DeleteDocument failed: %v", err)
+	}
+	for _, table := range []string{"section_summaries", "composed_summaries"} {
+		var count int
+		if err := store.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+table).Scan(&count); err != nil || count != 0 {
+			t.Errorf("Expected no rows in %s after deleting the document, got %d (%v)", table, count, err)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// insertTags stores a document's Zotero tags, skipping blanks and repeats
+func insertTags(ctx context.Context, tx *sql.Tx, docID string, tags []string) error {
+	var cleaned []string
+	for _, tag := range tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			cleaned = append(cleaned, tag)
+		}
+	}
+	return insertRows(ctx, tx, "tag", `
+		INSERT OR IGNORE INTO document_tags (document_id, tag) VALUES (?, ?)
+	`, len(cleaned), func(i int) []any {
+		return []any{docID, cleaned[i]}
+	})
+}
+
+// GetTags retrieves the Zotero tags of a document, in alphabetical order
+func (s *SQLiteStore) GetTags(ctx context.Context, docID string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT tag FROM document_tags WHERE document_id = ? ORDER BY tag`, docID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tags: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []string
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tags: %w", err)
+	}
+	return tags, nil
+}
+
+// tagsByDocument retrieves the Zotero tags of every document, in alphabetical
+// order, by document ID
+func (s *SQLiteStore) tagsByDocument(ctx context.Context) (map[string][]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT document_id, tag FROM document_tags ORDER BY document_id, tag`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make(map[string][]string)
+	for rows.Next() {
+		var docID, tag string
+		if err := rows.Scan(&docID, &tag); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags[docID] = append(tags[docID], tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tags: %w", err)
+	}
+	return tags, nil
+}
+
+// SetTags replaces the Zotero tags of a document
+func (s *SQLiteStore) SetTags(ctx context.Context, docID string, tags []string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM documents WHERE id = ?)`, docID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check document existence: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("document %w: %s", ErrNotFound, docID)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM document_tags WHERE document_id = ?`, docID); err != nil {
+		return fmt.Errorf("failed to clear document_tags: %w", err)
+	}
+	if err := insertTags(ctx, tx, docID, tags); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestTags(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	item := syntheticItem(0)
+	item.Metadata.Tags = []string{"to-read", " ", "Archives", "to-read"}
+	if err := store.StoreParsedItem(ctx, "doc-1", item, &models.SourceInfo{ZoteroID: "ATT1"}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+
+	metadata, err := store.GetMetadata(ctx, "doc-1")
+	if err != nil {
+		t.Fatalf("GetMetadata failed: %v", err)
+	}
+	if expected := []string{"Archives", "to-read"}; !reflect.DeepEqual(metadata.Tags, expected) {
+		t.Errorf("Expected tags %v, got %v", expected, metadata.Tags)
+	}
+
+	if err := store.SetTags(ctx, "doc-1", []string{"reviewed-2024"}); err != nil {
+		t.Fatalf("SetTags failed: %v", err)
+	}
+	docs, err := store.ListDocuments(ctx, "")
+	if err != nil {
+		t.Fatalf("ListDocuments failed: %v", err)
+	}
+	if len(docs) != 1 || !reflect.DeepEqual(docs[0].Tags, []string{"reviewed-2024"}) {
+		t.Errorf("Expected the replaced tags listed, got %+v", docs)
+	}
+
+	// Re-storing the document replaces its tags
+	item.Metadata.Tags = nil
+	if err := store.StoreParsedItem(ctx, "doc-1", item, &models.SourceInfo{ZoteroID: "ATT1"}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	if tags, err := store.GetTags(ctx, "doc-1"); err != nil || len(tags) != 0 {
+		t.Errorf("Expected no tags after re-storing, got %v (%v)", tags, err)
+	}
+
+	if err := store.SetTags(ctx, "missing", []string{"tag"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing document, got %v", err)
+	}
+}
@@ -0,0 +1,452 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/citations"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// unlinkedDocumentTables lists the tables besides childTables whose rows belong
+// to a document but have no foreign key to it, which DeleteDocument clears.
+// parse_locks and staged_pages are left out, as their documents are not stored
+// until the parse finishes.
+var unlinkedDocumentTables = []string{
+	"usage",
+	"document_sources",
+	"annotations",
+	"image_blobs",
+	"reference_links",
+	"summaries",
+	"section_summaries",
+	"composed_summaries",
+	"document_fetch_info",
+}
+
+// orphanCheck finds the rows of a table that refer to a row that is not stored
+type orphanCheck struct {
+	table    string
+	missing  string // SQL condition true of the table's rows that refer to nothing
+	idColumn string // Column of document IDs to list for the rows found, if any
+	what     string // What the rows refer to, for the finding's message
+}
+
+// orphanChecks lists the references between tables that VerifyStore checks.
+// Foreign keys were not always enforced and most tables have none, so rows of
+// removed documents, references, images, and jobs can remain.
+func orphanChecks() []orphanCheck {
+	var checks []orphanCheck
+	for _, table := range slices.Concat(childTables, unlinkedDocumentTables) {
+		checks = append(checks, orphanCheck{table, `document_id NOT IN (SELECT id FROM documents)`, "document_id", "documents that are not stored"})
+	}
+	return append(checks,
+		orphanCheck{"reference_links", `document_id IN (SELECT id FROM documents) AND cited_document_id NOT IN (SELECT id FROM documents)`,
+			"cited_document_id", "cited documents that are not stored"},
+		orphanCheck{"reference_links", `document_id IN (SELECT id FROM documents) AND NOT EXISTS (
+			SELECT 1 FROM document_references r WHERE r.document_id = reference_links.document_id AND r.ref_index = reference_links.ref_index
+		)`, "document_id", "references that are not stored"},
+		orphanCheck{"image_blobs", `document_id IN (SELECT id FROM documents) AND NOT EXISTS (
+			SELECT 1 FROM images i WHERE i.document_id = image_blobs.document_id AND i.image_index = image_blobs.image_index
+		)`, "document_id", "images that are not stored"},
+		orphanCheck{"job_items", `job_id NOT IN (SELECT id FROM jobs)`, "", "jobs that are not stored"},
+	)
+}
+
+// pageNumbering is the sequential page numbers of a document that do not run
+// from 1 without gaps
+type pageNumbering struct {
+	docID   string
+	numbers []int // In order
+}
+
+// citekeyHolder is a document with a citekey, as checked for duplicates
+type citekeyHolder struct {
+	docID    string
+	library  string
+	citekey  string
+	metadata models.ItemMetadata // Only what citekeys are generated from
+}
+
+// VerifyStore checks the store for inconsistencies: rows that refer to
+// documents (and their references and images) or jobs that are not stored,
+// fully parsed documents without pages, sequential page numbers with gaps,
+// and citekeys of a library that are the same ignoring case, which BibTeX
+// rejects. With repair, what can be fixed safely is, in one transaction:
+// orphaned rows are deleted; pages are renumbered from 1, along with the pages
+// references, images, and sections point to, and the page images rendered of
+// the document are dropped; and every document sharing a citekey but the one
+// stored first is given a new citekey generated in mode. Documents without
+// pages cannot be repaired, as their content has to be parsed again.
+func (s *SQLiteStore) VerifyStore(ctx context.Context, repair bool, mode citations.CitekeyMode) (*models.VerifyReport, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	report := &models.VerifyReport{
+		Checks:   []models.VerifyCheck{models.VerifyOrphanedRows, models.VerifyEmptyDocuments, models.VerifyPageNumbering, models.VerifyDuplicateCitekey},
+		Errors:   []models.VerifyFinding{},
+		Warnings: []models.VerifyFinding{},
+	}
+
+	checks := orphanChecks()
+	orphaned := make([]int, len(checks))
+	for i, check := range checks {
+		finding, err := findOrphans(ctx, tx, check)
+		if err != nil {
+			return nil, err
+		}
+		if finding != nil {
+			orphaned[i] = finding.Count
+			report.Warnings = append(report.Warnings, *finding)
+		}
+	}
+
+	empty, err := findEmptyDocuments(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, docID := range empty {
+		report.Errors = append(report.Errors, models.VerifyFinding{
+			Check:       models.VerifyEmptyDocuments,
+			Message:     "The document has no pages; parse it again to restore its content",
+			DocumentIDs: []string{docID},
+		})
+	}
+
+	numberings, err := findPageGaps(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, numbering := range numberings {
+		report.Errors = append(report.Errors, models.VerifyFinding{
+			Check:       models.VerifyPageNumbering,
+			Message:     fmt.Sprintf("The document's %d pages are numbered %s rather than 1-%d", len(numbering.numbers), pageRanges(numbering.numbers), len(numbering.numbers)),
+			Table:       "pages",
+			DocumentIDs: []string{numbering.docID},
+			Repairable:  true,
+		})
+	}
+
+	holders, err := loadCitekeyHolders(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	duplicates := duplicateCitekeys(holders)
+	for _, group := range duplicates {
+		var citekeys, docIDs []string
+		for _, holder := range group {
+			if !slices.Contains(citekeys, holder.citekey) {
+				citekeys = append(citekeys, holder.citekey)
+			}
+			docIDs = append(docIDs, holder.docID)
+		}
+		report.Errors = append(report.Errors, models.VerifyFinding{
+			Check:       models.VerifyDuplicateCitekey,
+			Message:     fmt.Sprintf("%d documents of library %s share the citekey %s, ignoring case", len(group), group[0].library, strings.Join(citekeys, " / ")),
+			DocumentIDs: docIDs,
+			Repairable:  true,
+		})
+	}
+
+	if !repair {
+		return report, nil
+	}
+
+	for i, check := range checks {
+		if orphaned[i] == 0 {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM `+check.table+` WHERE `+check.missing); err != nil {
+			return nil, fmt.Errorf("failed to delete orphaned %s rows: %w", check.table, err)
+		}
+		report.Actions = append(report.Actions, fmt.Sprintf("Deleted %d %s rows referring to %s", orphaned[i], check.table, check.what))
+	}
+	for _, numbering := range numberings {
+		if err := renumberPages(ctx, tx, numbering); err != nil {
+			return nil, err
+		}
+		report.Actions = append(report.Actions, fmt.Sprintf("Renumbered the %d pages of %s from 1", len(numbering.numbers), numbering.docID))
+	}
+	changes, err := regenerateDuplicateCitekeys(ctx, tx, holders, duplicates, mode)
+	if err != nil {
+		return nil, err
+	}
+	for _, change := range changes {
+		report.Actions = append(report.Actions, fmt.Sprintf("Changed the citekey of %s from %s to %s", change.DocumentID, change.OldCitekey, change.NewCitekey))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit repairs: %w", err)
+	}
+	report.Repaired = true
+	if len(changes) > 0 {
+		s.related.invalidate()
+	}
+	return report, nil
+}
+
+// findOrphans returns the finding of an orphan check, or nil if no row refers
+// to nothing
+func findOrphans(ctx context.Context, tx *sql.Tx, check orphanCheck) (*models.VerifyFinding, error) {
+	var count int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+check.table+` WHERE `+check.missing).Scan(&count); err != nil {
+		return nil, fmt.Errorf("failed to count orphaned %s rows: %w", check.table, err)
+	}
+	if count == 0 {
+		return nil, nil
+	}
+	finding := &models.VerifyFinding{
+		Check:      models.VerifyOrphanedRows,
+		Message:    fmt.Sprintf("%d %s rows refer to %s", count, check.table, check.what),
+		Table:      check.table,
+		Count:      count,
+		Repairable: true,
+	}
+	if check.idColumn == "" {
+		return finding, nil
+	}
+	rows, err := tx.QueryContext(ctx, `SELECT DISTINCT `+check.idColumn+` FROM `+check.table+` WHERE `+check.missing+` ORDER BY 1`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query orphaned %s rows: %w", check.table, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var docID string
+		if err := rows.Scan(&docID); err != nil {
+			return nil, fmt.Errorf("failed to scan orphaned %s row: %w", check.table, err)
+		}
+		finding.DocumentIDs = append(finding.DocumentIDs, docID)
+	}
+	return finding, rows.Err()
+}
+
+// findEmptyDocuments returns the documents without pages that were not parsed
+// for their metadata only
+func findEmptyDocuments(ctx context.Context, tx *sql.Tx) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id FROM documents
+		WHERE partial = 0 AND NOT EXISTS (SELECT 1 FROM pages WHERE pages.document_id = documents.id)
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query documents without pages: %w", err)
+	}
+	defer rows.Close()
+	var docIDs []string
+	for rows.Next() {
+		var docID string
+		if err := rows.Scan(&docID); err != nil {
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		docIDs = append(docIDs, docID)
+	}
+	return docIDs, rows.Err()
+}
+
+// findPageGaps returns the page numbers of the stored documents whose
+// sequential page numbers do not run from 1 to their page count
+func findPageGaps(ctx context.Context, tx *sql.Tx) ([]pageNumbering, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT document_id, page_number FROM pages
+		WHERE document_id IN (
+			SELECT document_id FROM pages
+			WHERE document_id IN (SELECT id FROM documents)
+			GROUP BY document_id
+			HAVING MIN(page_number) != 1 OR MAX(page_number) != COUNT(*)
+		)
+		ORDER BY document_id, page_number
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query page numbers: %w", err)
+	}
+	defer rows.Close()
+	var numberings []pageNumbering
+	for rows.Next() {
+		var docID string
+		var number int
+		if err := rows.Scan(&docID, &number); err != nil {
+			return nil, fmt.Errorf("failed to scan page number: %w", err)
+		}
+		if len(numberings) == 0 || numberings[len(numberings)-1].docID != docID {
+			numberings = append(numberings, pageNumbering{docID: docID})
+		}
+		last := &numberings[len(numberings)-1]
+		last.numbers = append(last.numbers, number)
+	}
+	return numberings, rows.Err()
+}
+
+// pageRanges writes ascending page numbers as ranges, such as "1-3, 5, 7-9"
+func pageRanges(numbers []int) string {
+	var ranges []string
+	for i := 0; i < len(numbers); {
+		j := i
+		for j+1 < len(numbers) && numbers[j+1] == numbers[j]+1 {
+			j++
+		}
+		if i == j {
+			ranges = append(ranges, fmt.Sprint(numbers[i]))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", numbers[i], numbers[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(ranges, ", ")
+}
+
+// renumberPages numbers a document's pages from 1 in their order. The pages
+// its references, images, and sections point to are moved with them; one in
+// a gap moves to the page after it. Its rendered page images are dropped, as
+// they are keyed on the old numbers.
+func renumberPages(ctx context.Context, tx *sql.Tx, numbering pageNumbering) error {
+	// The page a reference, image, or section points to (1-indexed, 0 if not
+	// known) becomes one more than the number of pages before it
+	for _, update := range []struct{ table, set string }{
+		{"document_references", "page_index = " + renumberedPage("document_references.page_index")},
+		{"images", "page_index = " + renumberedPage("images.page_index")},
+		{"sections", "start_page_index = " + renumberedPage("sections.start_page_index") +
+			", end_page_index = " + renumberedPage("sections.end_page_index")},
+	} {
+		if _, err := tx.ExecContext(ctx, `UPDATE `+update.table+` SET `+update.set+` WHERE document_id = ?`, numbering.docID); err != nil {
+			return fmt.Errorf("failed to renumber the pages of %s: %w", update.table, err)
+		}
+	}
+
+	// The pages whose number grows all come before those whose number shrinks,
+	// so moving the former from the last and the latter from the first never
+	// takes a number still in use
+	move := func(i int) error {
+		if numbering.numbers[i] == i+1 {
+			return nil
+		}
+		_, err := tx.ExecContext(ctx, `UPDATE pages SET page_number = ? WHERE document_id = ? AND page_number = ?`, i+1, numbering.docID, numbering.numbers[i])
+		if err != nil {
+			return fmt.Errorf("failed to renumber page %d: %w", numbering.numbers[i], err)
+		}
+		return nil
+	}
+	for i := len(numbering.numbers) - 1; i >= 0; i-- {
+		if numbering.numbers[i] < i+1 {
+			if err := move(i); err != nil {
+				return err
+			}
+		}
+	}
+	for i := range numbering.numbers {
+		if numbering.numbers[i] > i+1 {
+			if err := move(i); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM page_images WHERE document_id = ?`, numbering.docID); err != nil {
+		return fmt.Errorf("failed to delete page images: %w", err)
+	}
+	return nil
+}
+
+// renumberedPage is the SQL expression of the page a column of a row of the
+// same document points to once its pages are numbered from 1
+func renumberedPage(column string) string {
+	table, _, _ := strings.Cut(column, ".")
+	return fmt.Sprintf(`CASE WHEN %[1]s > 0 THEN 1 + (
+		SELECT COUNT(*) FROM pages p WHERE p.document_id = %[2]s.document_id AND p.page_number < %[1]s
+	) ELSE %[1]s END`, column, table)
+}
+
+// loadCitekeyHolders loads every document with a citekey, by library and in the
+// order they were stored
+func loadCitekeyHolders(ctx context.Context, tx *sql.Tx) ([]citekeyHolder, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, library, citekey, COALESCE(authors, '[]'), corporate_authors, COALESCE(publication_date, '')
+		FROM documents
+		WHERE citekey IS NOT NULL
+		ORDER BY library, created_at, id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query citekeys: %w", err)
+	}
+	defer rows.Close()
+	var holders []citekeyHolder
+	for rows.Next() {
+		var holder citekeyHolder
+		var authorsJSON, corporateJSON string
+		if err := rows.Scan(&holder.docID, &holder.library, &holder.citekey, &authorsJSON, &corporateJSON, &holder.metadata.PublicationDate); err != nil {
+			return nil, fmt.Errorf("failed to scan citekey: %w", err)
+		}
+		if err := json.Unmarshal([]byte(authorsJSON), &holder.metadata.Authors); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal authors of %s: %w", holder.docID, err)
+		}
+		if holder.metadata.CorporateAuthors, err = unmarshalKeywords(corporateJSON); err != nil {
+			return nil, err
+		}
+		holders = append(holders, holder)
+	}
+	return holders, rows.Err()
+}
+
+// duplicateCitekeys groups the documents sharing a citekey of their library,
+// ignoring case, each group in the order the documents were stored
+func duplicateCitekeys(holders []citekeyHolder) [][]citekeyHolder {
+	groups := make(map[string][]citekeyHolder)
+	var keys []string
+	for _, holder := range holders {
+		key := holder.library + "\x00" + strings.ToLower(holder.citekey)
+		if len(groups[key]) == 1 {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], holder)
+	}
+	sort.Strings(keys)
+	duplicates := make([][]citekeyHolder, len(keys))
+	for i, key := range keys {
+		duplicates[i] = groups[key]
+	}
+	return duplicates
+}
+
+// regenerateDuplicateCitekeys gives every document of each group but the first
+// a citekey generated in mode, one no other document of its library has
+// ignoring case, and returns the changes
+func regenerateDuplicateCitekeys(ctx context.Context, tx *sql.Tx, holders []citekeyHolder, duplicates [][]citekeyHolder, mode citations.CitekeyMode) ([]models.CitekeyChange, error) {
+	taken := make(map[string]map[string]bool)
+	folded := make(map[string]map[string]bool)
+	for _, holder := range holders {
+		if taken[holder.library] == nil {
+			taken[holder.library] = make(map[string]bool)
+			folded[holder.library] = make(map[string]bool)
+		}
+		taken[holder.library][holder.citekey] = true
+		folded[holder.library][strings.ToLower(holder.citekey)] = true
+	}
+
+	var changes []models.CitekeyChange
+	for _, group := range duplicates {
+		for _, holder := range group[1:] {
+			// GenerateCitekeyWithMode avoids the taken citekeys as spelled, so one
+			// differing from a taken citekey only in case is taken in turn
+			citekey := citations.GenerateCitekeyWithMode(&holder.metadata, taken[holder.library], mode)
+			for folded[holder.library][strings.ToLower(citekey)] {
+				taken[holder.library][citekey] = true
+				citekey = citations.GenerateCitekeyWithMode(&holder.metadata, taken[holder.library], mode)
+			}
+			taken[holder.library][citekey] = true
+			folded[holder.library][strings.ToLower(citekey)] = true
+
+			if _, err := tx.ExecContext(ctx, `UPDATE documents SET citekey = ? WHERE id = ?`, citekey, holder.docID); err != nil {
+				return nil, fmt.Errorf("failed to update citekey of %s: %w", holder.docID, err)
+			}
+			changes = append(changes, models.CitekeyChange{DocumentID: holder.docID, OldCitekey: holder.citekey, NewCitekey: citekey})
+		}
+	}
+	return changes, nil
+}
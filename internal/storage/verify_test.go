@@ -0,0 +1,248 @@
+package storage
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/citations"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// corrupt runs statements on the store with foreign keys off, as databases
+// written before they were enforced were
+func corrupt(t *testing.T, store *SQLiteStore, statements ...string) {
+	t.Helper()
+	ctx := context.Background()
+	// An in-memory store has one connection, so the pragma applies to the statements
+	if _, err := store.db.ExecContext(ctx, `PRAGMA foreign_keys = OFF`); err != nil {
+		t.Fatal(err)
+	}
+	defer store.db.ExecContext(ctx, `PRAGMA foreign_keys = ON`)
+	for _, statement := range statements {
+		if _, err := store.db.ExecContext(ctx, statement); err != nil {
+			t.Fatalf("Failed to run %q: %v", statement, err)
+		}
+	}
+}
+
+// findings returns the findings of a check
+func findings(report *models.VerifyReport, check models.VerifyCheck) []models.VerifyFinding {
+	var found []models.VerifyFinding
+	for _, finding := range append(append([]models.VerifyFinding{}, report.Errors...), report.Warnings...) {
+		if finding.Check == check {
+			found = append(found, finding)
+		}
+	}
+	return found
+}
+
+func verify(t *testing.T, store *SQLiteStore, repair bool) *models.VerifyReport {
+	t.Helper()
+	report, err := store.VerifyStore(context.Background(), repair, citations.CitekeyUnicode)
+	if err != nil {
+		t.Fatalf("VerifyStore failed: %v", err)
+	}
+	return report
+}
+
+func TestVerifyStore_Consistent(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	if err := store.StoreParsedItem(ctx, "doc-1", syntheticItem(3), &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	// A document parsed for its metadata only has no pages
+	if err := store.StoreParsedItem(ctx, "doc-2", &models.ParsedItem{Partial: true}, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+
+	report := verify(t, store, true)
+	if len(report.Errors) != 0 || len(report.Warnings) != 0 || len(report.Actions) != 0 || !report.Repaired {
+		t.Errorf("Expected a consistent store, got %+v", report)
+	}
+	if len(report.Checks) != 4 {
+		t.Errorf("Expected the checks run to be listed, got %v", report.Checks)
+	}
+}
+
+func TestVerifyStore_OrphanedRows(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	for _, docID := range []string{"doc-1", "doc-2"} {
+		if err := store.StoreParsedItem(ctx, docID, syntheticItem(3), &models.SourceInfo{}); err != nil {
+			t.Fatalf("StoreParsedItem failed: %v", err)
+		}
+	}
+	corrupt(t, store,
+		// A document removed without its rows
+		`DELETE FROM documents WHERE id = 'doc-2'`,
+		`INSERT INTO annotations (document_id, text) VALUES ('gone', 'A note')`,
+		// Links to a document that is not stored and from a reference that is not
+		`INSERT INTO reference_links (document_id, ref_index, cited_document_id, match_method, score) VALUES ('doc-1', 0, 'gone', 'doi', 1)`,
+		`INSERT INTO reference_links (document_id, ref_index, cited_document_id, match_method, score) VALUES ('doc-1', 9, 'doc-1', 'doi', 1)`,
+		`INSERT INTO job_items (job_id, item_index) VALUES ('job-gone', 0)`,
+	)
+
+	report := verify(t, store, false)
+	if len(report.Errors) != 0 || report.Repaired {
+		t.Errorf("Expected only warnings, got %+v", report)
+	}
+	byTable := make(map[string][]models.VerifyFinding)
+	for _, finding := range findings(report, models.VerifyOrphanedRows) {
+		byTable[finding.Table] = append(byTable[finding.Table], finding)
+	}
+	if pages := byTable["pages"]; len(pages) != 1 || pages[0].Count != 3 || !reflect.DeepEqual(pages[0].DocumentIDs, []string{"doc-2"}) || !pages[0].Repairable {
+		t.Errorf("Expected the pages of doc-2 to be orphaned, got %+v", pages)
+	}
+	if annotations := byTable["annotations"]; len(annotations) != 1 || !reflect.DeepEqual(annotations[0].DocumentIDs, []string{"gone"}) {
+		t.Errorf("Expected the orphaned annotation, got %+v", annotations)
+	}
+	if links := byTable["reference_links"]; len(links) != 2 {
+		t.Errorf("Expected the link to a missing document and the link from a missing reference, got %+v", links)
+	}
+	if items := byTable["job_items"]; len(items) != 1 || items[0].Count != 1 || items[0].DocumentIDs != nil {
+		t.Errorf("Expected the orphaned job item, got %+v", items)
+	}
+
+	report = verify(t, store, true)
+	if !report.Repaired || len(report.Actions) != len(report.Warnings) {
+		t.Errorf("Expected an action for each finding, got %+v", report)
+	}
+	if report := verify(t, store, false); len(report.Warnings) != 0 {
+		t.Errorf("Expected the orphaned rows to be gone, got %+v", report.Warnings)
+	}
+	got, err := store.GetParsedItem(ctx, "doc-1")
+	if err != nil || len(got.Pages) != 3 || len(got.References) != 3 {
+		t.Errorf("Expected doc-1 to be kept whole, got %+v, %v", got, err)
+	}
+}
+
+func TestVerifyStore_EmptyDocuments(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	if err := store.StoreParsedItem(ctx, "doc-1", syntheticItem(2), &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	corrupt(t, store, `DELETE FROM pages WHERE document_id = 'doc-1'`)
+
+	report := verify(t, store, true)
+	empty := findings(report, models.VerifyEmptyDocuments)
+	if len(empty) != 1 || !reflect.DeepEqual(empty[0].DocumentIDs, []string{"doc-1"}) || empty[0].Repairable {
+		t.Errorf("Expected doc-1 to be found without pages and not repairable, got %+v", empty)
+	}
+	if len(report.Actions) != 0 {
+		t.Errorf("Expected nothing to be repaired, got %v", report.Actions)
+	}
+	if exists, _ := store.DocumentExists(ctx, "doc-1"); !exists {
+		t.Error("Expected the document to be kept")
+	}
+}
+
+func TestVerifyStore_PageNumbering(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	gapped := syntheticItem(5)
+	gapped.References[0].PageIndex = 3 // In the gap
+	gapped.References[1].PageIndex = 5
+	gapped.Images[0].PageIndex = 4
+	gapped.Sections[0].StartPageIndex, gapped.Sections[0].EndPageIndex = 1, 5
+	if err := store.StoreParsedItem(ctx, "gapped", gapped, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	if err := store.StoreParsedItem(ctx, "zero-based", syntheticItem(3), &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	if err := store.StorePageImage(ctx, "gapped", 4, 150, []byte("png")); err != nil {
+		t.Fatalf("StorePageImage failed: %v", err)
+	}
+	corrupt(t, store,
+		`DELETE FROM pages WHERE document_id = 'gapped' AND page_number = 3`,
+		`UPDATE pages SET page_number = page_number - 1 WHERE document_id = 'zero-based'`,
+	)
+
+	report := verify(t, store, false)
+	numbering := findings(report, models.VerifyPageNumbering)
+	if len(numbering) != 2 {
+		t.Fatalf("Expected two documents with gaps, got %+v", numbering)
+	}
+	if want := "The document's 4 pages are numbered 1-2, 4-5 rather than 1-4"; numbering[0].Message != want || numbering[0].DocumentIDs[0] != "gapped" {
+		t.Errorf("Expected %q for gapped, got %+v", want, numbering[0])
+	}
+	if want := "The document's 3 pages are numbered 0-2 rather than 1-3"; numbering[1].Message != want {
+		t.Errorf("Expected %q, got %q", want, numbering[1].Message)
+	}
+
+	verify(t, store, true)
+	if report := verify(t, store, false); len(report.Errors) != 0 {
+		t.Fatalf("Expected the pages to be renumbered, got %+v", report.Errors)
+	}
+
+	got, err := store.GetParsedItem(ctx, "gapped")
+	if err != nil {
+		t.Fatalf("GetParsedItem failed: %v", err)
+	}
+	if want := []string{"Page 1 content", "Page 2 content", "Page 4 content", "Page 5 content"}; !reflect.DeepEqual(got.Pages, want) {
+		t.Errorf("Expected pages %v, got %v", want, got.Pages)
+	}
+	if page, err := store.GetPage(ctx, "gapped", 3); err != nil || page != "Page 4 content" {
+		t.Errorf("Expected page 3 to be the old page 4, got %q, %v", page, err)
+	}
+	if got.References[0].PageIndex != 3 || got.References[1].PageIndex != 4 || got.Images[0].PageIndex != 3 {
+		t.Errorf("Expected references and images to move with their pages, got %+v, %+v", got.References[:2], got.Images[0])
+	}
+	if section := got.Sections[0]; section.StartPageIndex != 1 || section.EndPageIndex != 4 {
+		t.Errorf("Expected the section to span pages 1-4, got %+v", section)
+	}
+	if _, err := store.GetPageImage(ctx, "gapped", 4, 150); err == nil {
+		t.Error("Expected the rendered page image to be dropped")
+	}
+
+	if page, err := store.GetPage(ctx, "zero-based", 1); err != nil || page != "Page 1 content" {
+		t.Errorf("Expected the first page to be page 1, got %q, %v", page, err)
+	}
+}
+
+func TestVerifyStore_DuplicateCitekeys(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	item := func(citekey string) *models.ParsedItem {
+		return &models.ParsedItem{
+			Metadata: models.ItemMetadata{Authors: []string{"Smith, Jane"}, PublicationDate: "2020", Citekey: citekey},
+			Pages:    []string{"Content"},
+		}
+	}
+	for _, doc := range []struct{ docID, citekey string }{
+		{"doc-a", "smith2020"},
+		{"doc-b", "Smith2020"},
+		{"doc-c", "SMITH2020"},
+		{"doc-d", "smith2020a"},
+		{"other:doc-e", "smith2020"}, // Another library
+	} {
+		if err := store.StoreParsedItem(ctx, doc.docID, item(doc.citekey), &models.SourceInfo{}); err != nil {
+			t.Fatalf("StoreParsedItem failed: %v", err)
+		}
+	}
+
+	report := verify(t, store, false)
+	duplicates := findings(report, models.VerifyDuplicateCitekey)
+	if len(duplicates) != 1 || !reflect.DeepEqual(duplicates[0].DocumentIDs, []string{"doc-a", "doc-b", "doc-c"}) {
+		t.Fatalf("Expected doc-a, doc-b, and doc-c to share a citekey, got %+v", duplicates)
+	}
+
+	report = verify(t, store, true)
+	if len(report.Actions) != 2 {
+		t.Errorf("Expected two citekeys to change, got %v", report.Actions)
+	}
+	citekeys, err := store.GetCitekeyMap(ctx, DefaultLibrary)
+	if err != nil {
+		t.Fatalf("GetCitekeyMap failed: %v", err)
+	}
+	want := map[string]string{"doc-a": "smith2020", "doc-b": "smith2020b", "doc-c": "smith2020c", "doc-d": "smith2020a"}
+	if !reflect.DeepEqual(citekeys, want) {
+		t.Errorf("Expected citekeys %v, got %v", want, citekeys)
+	}
+	if other, err := store.GetCitekeyMap(ctx, "other"); err != nil || other["other:doc-e"] != "smith2020" {
+		t.Errorf("Expected the other library's citekey to be kept, got %v, %v", other, err)
+	}
+}
@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// GetZoteroCache returns a cached Zotero API response and when it was fetched,
+// or nil data if nothing is cached under the key
+func (s *SQLiteStore) GetZoteroCache(ctx context.Context, key string) ([]byte, time.Time, error) {
+	var data []byte
+	var fetchedAt int64
+	err := s.db.QueryRowContext(ctx, `
+		SELECT data, fetched_at FROM zotero_cache WHERE cache_key = ?
+	`, key).Scan(&data, &fetchedAt)
+	if err == sql.ErrNoRows {
+		return nil, time.Time{}, nil
+	}
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to query Zotero cache: %w", err)
+	}
+	return data, time.Unix(0, fetchedAt), nil
+}
+
+// PutZoteroCache caches a Zotero API response under the key, replacing any
+// earlier one
+func (s *SQLiteStore) PutZoteroCache(ctx context.Context, key string, data []byte) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO zotero_cache (cache_key, data, fetched_at) VALUES (?, ?, ?)
+		ON CONFLICT (cache_key) DO UPDATE SET data = excluded.data, fetched_at = excluded.fetched_at
+	`, key, data, time.Now().UnixNano())
+	if err != nil {
+		return fmt.Errorf("failed to store Zotero cache entry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestZoteroCache(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	data, _, err := store.GetZoteroCache(ctx, "user/111/ITEM1/attachments")
+	if err != nil {
+		t.Fatalf("GetZoteroCache failed: %v", err)
+	}
+	if data != nil {
+		t.Fatalf("Expected no cached data, got %q", data)
+	}
+
+	before := time.Now()
+	for _, value := range []string{`["first"]`, `["second"]`} {
+		if err := store.PutZoteroCache(ctx, "user/111/ITEM1/attachments", []byte(value)); err != nil {
+			t.Fatalf("PutZoteroCache failed: %v", err)
+		}
+	}
+
+	data, fetchedAt, err := store.GetZoteroCache(ctx, "user/111/ITEM1/attachments")
+	if err != nil {
+		t.Fatalf("GetZoteroCache failed: %v", err)
+	}
+	if string(data) != `["second"]` {
+		t.Errorf("Expected the latest entry to replace the first, got %q", data)
+	}
+	if fetchedAt.Before(before) || fetchedAt.After(time.Now()) {
+		t.Errorf("Expected fetch time between %v and now, got %v", before, fetchedAt)
+	}
+}
@@ -1,5 +1,12 @@
 package models
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+)
+
 type ParsedItem struct {
 	Metadata    ItemMetadata `json:"metadata,omitempty"`
 	Pages       []string     `json:"pages,omitempty"`
@@ -10,7 +17,63 @@ type ParsedItem struct {
 	Footnotes   []Footnote   `json:"footnotes,omitempty"`
 	Endnotes    []Endnote    `json:"endnotes,omitempty"`
 	Quotations  []Quotation  `json:"quotations,omitempty"`
-	Summary     string       `json:"summary,omitempty"` // AI-generated summary of the document
+	Sections    []Section    `json:"sections,omitempty"`     // Heading-delimited sections of the page content
+	FullText    string       `json:"full_text,omitempty"`    // Continuous text of the pages with layout breaks undone
+	PageOffsets []int        `json:"page_offsets,omitempty"` // Byte offset in FullText at which each page begins
+	// Sentences of each page (see documents.SegmentSentences), corresponding to Pages
+	PageSentences [][]TextSpan `json:"page_sentences,omitempty"`
+	Summary       string       `json:"summary,omitempty"`        // AI-generated summary of the document, in the standard style
+	ChunkCount    int          `json:"chunk_count,omitempty"`    // Number of chunks a large text document was split into for parsing
+	PageNumbering string       `json:"page_numbering,omitempty"` // How PageNumbers were assigned, PageNumberingSource or PageNumberingSequential; empty if not validated
+	PDFURL        string       `json:"pdf_url,omitempty"`        // Full-text PDF linked from an HTML page's citation_pdf_url meta tag
+	ContentHash   string       `json:"content_hash,omitempty"`   // SHA-256 of the document data the item was parsed from
+	Partial       bool         `json:"partial,omitempty"`        // Only the metadata and abstract were parsed, from the first pages; a full parse replaces it
+	Basic         bool         `json:"basic,omitempty"`          // Parsed from extracted text without the model; a parse with the model replaces it
+
+	// Scan detection (PDF only)
+	IsScanned   bool          `json:"is_scanned,omitempty"`   // Most pages have no extractable text layer
+	PageQuality []PageQuality `json:"page_quality,omitempty"` // Extraction quality corresponding to Pages
+
+	InvalidDOIs     []InvalidDOI     `json:"invalid_dois,omitempty"`     // DOIs dropped while parsing; not stored
+	MetadataWarning *MetadataWarning `json:"metadata_warning,omitempty"` // External metadata that could not be fetched for the parse; not stored
+
+	Provenance *Provenance `json:"provenance,omitempty"` // How and when the document was parsed
+	Fetch      *FetchInfo  `json:"fetch,omitempty"`      // How the bytes it was parsed from were fetched; kept when stored again without it
+}
+
+// Provenance records how a stored document was parsed, so documents parsed
+// with older prompts or parser versions can be found and re-parsed
+type Provenance struct {
+	CreatedAt     string `json:"created_at,omitempty"`     // When the document was first stored (set by the store)
+	UpdatedAt     string `json:"updated_at,omitempty"`     // When the document was last stored, e.g. re-parsed or summarized (set by the store)
+	ParsedModel   string `json:"parsed_model,omitempty"`   // OpenAI model that parsed the document
+	PromptVersion int    `json:"prompt_version,omitempty"` // Version of the parsing prompts; 0 if parsed before versions were recorded
+	ParserVersion string `json:"parser_version,omitempty"` // Version of the parsing pipeline
+}
+
+// PageQuality describes how reliably the content of a page could be extracted
+type PageQuality struct {
+	IsScanned bool `json:"is_scanned,omitempty"` // The page has no extractable text layer (image-only scan)
+	NearEmpty bool `json:"near_empty,omitempty"` // The extracted content is empty or nearly empty
+
+	// The model's own assessment of the page (PDF pages parsed since it was
+	// recorded; ContentConfidence is nil otherwise)
+	ContentConfidence *float64 `json:"content_confidence,omitempty"` // Confidence (0.0-1.0) that the content is a faithful extraction of the page's text
+	IsBlank           bool     `json:"is_blank,omitempty"`           // The page has no meaningful content (blank, or only a page number or running header)
+	IsCover           bool     `json:"is_cover,omitempty"`           // The page is a cover, title, or copyright page
+	IsReferencesOnly  bool     `json:"is_references_only,omitempty"` // The page holds nothing but bibliography entries
+
+	// The page was too large to send to the model and was parsed from its
+	// extracted text only, without its images, layout, or printed page number
+	Degraded bool `json:"degraded,omitempty"`
+}
+
+// PageAssessment is the model's assessment of a parsed PDF page
+type PageAssessment struct {
+	ContentConfidence float64 `json:"content_confidence"`
+	IsBlank           bool    `json:"is_blank"`
+	IsCover           bool    `json:"is_cover"`
+	IsReferencesOnly  bool    `json:"is_references_only"`
 }
 
 type ParsedPage struct {
@@ -22,6 +85,16 @@ type ParsedPage struct {
 	Footnotes      []Footnote     `json:"footnotes,omitempty"`
 	Endnotes       []Endnote      `json:"endnotes,omitempty"`
 	PageNumberInfo PageNumberInfo `json:"page_number_info,omitempty"`
+	PageAssessment PageAssessment `json:"page_assessment,omitempty"`
+	Degraded       bool           `json:"-"` // Parsed from the page's extracted text because the page was too large to send
+}
+
+// StagedPage is a page of a PDF parse kept as soon as it was parsed, so a parse
+// that is interrupted can resume without parsing it again
+type StagedPage struct {
+	PageIndex   int         // 0-based index of the page in the PDF
+	ContentHash string      // Identifies what the page was parsed from; a page whose hash changed is parsed again
+	Page        *ParsedPage // The parsed page, including whether it was Degraded
 }
 
 // PageNumberInfo contains information about the printed page number on a page
@@ -43,6 +116,15 @@ type ItemMetadata struct {
 	Publication     string   `json:"publication,omitempty"`
 	DOI             string   `json:"doi,omitempty"`
 	Abstract        string   `json:"abstract,omitempty"`
+	// Authors the source gives as one name rather than a person's family and
+	// given names (Zotero's single-field creators), treated as organizations;
+	// others are recognized by citations.IsCorporateAuthor
+	CorporateAuthors []string `json:"corporate_authors,omitempty"`
+
+	// Statements from the document's own sections, extracted when it is parsed
+	FundingStatement string `json:"funding_statement,omitempty"` // Who funded the research (e.g., "Funding" section)
+	Acknowledgments  string `json:"acknowledgments,omitempty"`
+	DataAvailability string `json:"data_availability,omitempty"` // Where the research data can be found, or why it cannot
 
 	// Additional bibliographic fields (primarily from external sources like Zotero)
 	ItemType  string `json:"item_type,omitempty"` // e.g., "book", "article", "conferencePaper"
@@ -53,29 +135,177 @@ type ItemMetadata struct {
 	ISSN      string `json:"issn,omitempty"`
 	ISBN      string `json:"isbn,omitempty"`
 	URL       string `json:"url,omitempty"`
+	Language  string `json:"language,omitempty"` // ISO 639-1 code of the document's main language (e.g., "en", "de")
+
+	// Keywords the authors list for the document, extracted when it is parsed
+	Keywords []string `json:"keywords,omitempty"`
+
+	// Tags of the Zotero item the document came from, stored in document_tags
+	Tags []string `json:"tags,omitempty"`
 
 	// Citation information
 	Citekey string `json:"citekey,omitempty"` // Pandoc-style citekey (e.g., "smith2020", "smithJones2021")
 
 	// Metadata source tracking
-	MetadataSource string `json:"metadata_source,omitempty"` // "zotero", "extracted", "merged"
+	MetadataSource string             `json:"metadata_source,omitempty"`    // "zotero", "extracted", "merged"
+	FieldSources   map[string]string  `json:"field_sources,omitempty"`      // Source of each field: "external", "extracted", or "manual"
+	Conflicts      []MetadataConflict `json:"metadata_conflicts,omitempty"` // Fields on which the external and extracted metadata disagree
+}
+
+// MetadataConflict records a field on which the external metadata (e.g., from
+// Zotero) and the metadata extracted from the document disagree. The external
+// value is the one kept unless the field is set manually.
+type MetadataConflict struct {
+	Field          string `json:"field"` // e.g., "title", "publication_date"
+	ExternalValue  string `json:"external_value"`
+	ExtractedValue string `json:"extracted_value"`
+}
+
+// Styles a document can be summarized in. A document has at most one stored
+// summary per style; ParsedItem.Summary is the standard one.
+const (
+	SummaryStyleBrief      = "brief"      // One-sentence TL;DR
+	SummaryStyleStandard   = "standard"   // 1-3 paragraphs of expository prose in a detached academic tone
+	SummaryStyleStructured = "structured" // Aims, methods, findings, and limitations
+	SummaryStyleAccessible = "accessible" // Explanation for an undergraduate new to the field
+)
+
+// SummaryStyles lists the summary styles
+var SummaryStyles = []string{SummaryStyleBrief, SummaryStyleStandard, SummaryStyleStructured, SummaryStyleAccessible}
+
+// How a PDF's page numbers were assigned, recorded as ParsedItem.PageNumbering
+const (
+	PageNumberingSource     = "source"     // The numbers printed on the pages, where enough were detected in sequence
+	PageNumberingSequential = "sequential" // Physical page numbers, counted from 1
+)
+
+// MetadataWarning reports that a document's external metadata could not be
+// fetched, so it was parsed with only the metadata extracted from it. The
+// document itself was parsed and stored.
+type MetadataWarning struct {
+	Source  string `json:"source"`  // Where the metadata was to come from: "zotero" or "arxiv"
+	Message string `json:"message"` // Why the fetch failed
+}
+
+// Reasons a DOI is reported as invalid
+const (
+	DOIMalformed  = "malformed"  // Not the syntax of a DOI
+	DOIUnresolved = "unresolved" // The DOI resolver does not know it
+)
+
+// InvalidDOI records a DOI found in a document's metadata or references that
+// was dropped rather than stored
+type InvalidDOI struct {
+	Value          string `json:"value"`
+	Reason         string `json:"reason"`                    // DOIMalformed or DOIUnresolved
+	Source         string `json:"source"`                    // "external", "extracted", or stored "metadata", or "reference"
+	ReferenceIndex *int   `json:"reference_index,omitempty"` // 0-indexed, for references
 }
 
 type Reference struct {
 	ReferenceText string `json:"reference_text,omitempty"`
 	DOI           string `json:"doi,omitempty"`
+	PageNumber    string `json:"page_number,omitempty"` // The source page where this reference appears (PDF only)
+	PageIndex     int    `json:"page_index,omitempty"`  // Sequential page (1-indexed) the reference was parsed from; the first page for entries spanning a page break (PDF only)
+
+	// Set when reading a reference linked to a stored document; not stored with the reference
+	CitedDocumentID string `json:"cited_document_id,omitempty"` // The stored document the reference cites
+	Citekey         string `json:"citekey,omitempty"`           // Citekey of the cited document
 }
 
+// PageReference is a reference listed by the page it appears on, with its
+// position among all of the document's references
+type PageReference struct {
+	RefIndex int `json:"ref_index"` // 0-indexed, as in pdf://{docID}/references/{index}
+	Reference
+}
+
+// ReferenceLink records that a reference of one stored document cites another
+type ReferenceLink struct {
+	DocumentID      string  `json:"document_id"`       // The citing document
+	RefIndex        int     `json:"ref_index"`         // 0-indexed position of the reference in the citing document
+	CitedDocumentID string  `json:"cited_document_id"` // The cited document
+	MatchMethod     string  `json:"match_method"`      // "doi" or "title"
+	Score           float64 `json:"score"`             // 1 for a DOI match; the title similarity otherwise
+}
+
+// CitekeyChange is a document's citekey before and after its citekey is
+// generated again
+type CitekeyChange struct {
+	DocumentID string `json:"document_id"`
+	Title      string `json:"title,omitempty"`
+	OldCitekey string `json:"old_citekey"`
+	NewCitekey string `json:"new_citekey"`
+}
+
+// RelatedDocument is a stored document suggested as related to another, with
+// what the two have in common
+type RelatedDocument struct {
+	DocumentID       string        `json:"document_id"`
+	Title            string        `json:"title,omitempty"`
+	Citekey          string        `json:"citekey,omitempty"`
+	Score            float64       `json:"score"`                       // The sum of Scores
+	Scores           RelatedScores `json:"scores"`                      // Each signal's part of the score
+	SharedReferences []string      `json:"shared_references,omitempty"` // Normalized DOIs both documents cite
+	SharedAuthors    []string      `json:"shared_authors,omitempty"`    // Authors of both, as written for the given document
+	SameVenue        bool          `json:"same_venue,omitempty"`        // Both appeared in the same journal or other publication
+	TermSimilarity   float64       `json:"term_similarity"`             // Cosine similarity (0-1) of the TF-IDF terms of their titles, abstracts, and keywords
+	SharedTerms      []string      `json:"shared_terms,omitempty"`      // The shared terms that add most to the similarity
+}
+
+// RelatedScores breaks a RelatedDocument's score down by signal
+type RelatedScores struct {
+	References float64 `json:"references"`
+	Authors    float64 `json:"authors"`
+	Venue      float64 `json:"venue"`
+	Terms      float64 `json:"terms"`
+}
+
+// Where an image can be had from, recorded as Image.SourceKind
+const (
+	ImageSourceExternalURL = "external_url" // Fetchable from ImageURL
+	ImageSourceEmbedded    = "embedded"     // Its data was extracted from the document
+	ImageSourceUnavailable = "unavailable"  // Only described
+)
+
 type Image struct {
-	ImageURL         string `json:"image_url,omitempty"`
+	ImageURL         string `json:"image_url,omitempty"` // Absolute http(s) URL the image can be fetched from
 	ImageDescription string `json:"image_description,omitempty"`
 	Caption          string `json:"caption,omitempty"`
+	SourceKind       string `json:"source_kind,omitempty"` // ImageSourceExternalURL, ImageSourceEmbedded, or ImageSourceUnavailable
+	StableID         string `json:"stable_id,omitempty"`   // ID that survives re-parsing (e.g., "img-p12-1-3fa9c1"), set when stored
+
+	// Embedded image data (PDF only), readable at pdf://{docID}/images/{index}/data
+	PageIndex int    `json:"page_index,omitempty"` // Sequential page (1-indexed) the image appears on
+	MIMEType  string `json:"mime_type,omitempty"`  // Type of the image data; empty if none was extracted
+	Width     int    `json:"width,omitempty"`      // Pixels
+	Height    int    `json:"height,omitempty"`     // Pixels
+	Data      []byte `json:"-"`                    // Set when parsing; stored apart from the image and loaded with GetImageData
+}
+
+// ImpliedSourceKind returns the source kind an image's data and URL imply:
+// embedded if its data was extracted, external_url if it has a URL, and
+// unavailable otherwise
+func (img Image) ImpliedSourceKind() string {
+	switch {
+	case len(img.Data) > 0 || img.MIMEType != "":
+		return ImageSourceEmbedded
+	case img.ImageURL != "":
+		return ImageSourceExternalURL
+	default:
+		return ImageSourceUnavailable
+	}
 }
 
 type Table struct {
-	TableID    string `json:"table_id,omitempty"`
-	TableTitle string `json:"table_title,omitempty"`
-	TableData  string `json:"table_data,omitempty"`
+	TableID    string     `json:"table_id,omitempty"`
+	TableTitle string     `json:"table_title,omitempty"`
+	TableData  string     `json:"table_data,omitempty"`    // The table as GitHub-flavored markdown
+	Columns    []string   `json:"table_columns,omitempty"` // Column names parsed from TableData
+	Rows       [][]string `json:"table_rows,omitempty"`    // Rows parsed from TableData, one cell per column
+	ParseError string     `json:"parse_error,omitempty"`   // Why TableData could not be parsed into columns and rows
+	PageNumber string     `json:"page_number,omitempty"`   // The source page where the table appears (PDF only)
+	StableID   string     `json:"stable_id,omitempty"`     // ID that survives re-parsing (e.g., "tbl-p12-1-0b77de"), set when stored
 }
 
 // Footnote represents a footnote appearing at the bottom of a specific page
@@ -83,28 +313,135 @@ type Footnote struct {
 	Marker     string `json:"marker,omitempty"`       // The footnote marker (e.g., "1", "*", "a")
 	Text       string `json:"text,omitempty"`         // The full text of the footnote
 	PageNumber string `json:"page_number,omitempty"`  // The page where this footnote appears
-	InTextPage string `json:"in_text_page,omitempty"` // The page where the marker appears in text (if different)
+	InTextPage string `json:"in_text_page,omitempty"` // The sequential page (1-indexed) where the marker appears in the text
+	StableID   string `json:"stable_id,omitempty"`    // ID that survives re-parsing (e.g., "fn-p12-1-5d20e4"), set when stored
 }
 
 // Endnote represents an endnote appearing at the end of a document/chapter
 type Endnote struct {
 	Marker     string `json:"marker,omitempty"`      // The endnote marker (e.g., "1", "i", "a")
+	Key        string `json:"key,omitempty"`         // Stable key of the note within the document (e.g., "ch3-n17"), unique where markers restart by chapter
 	Text       string `json:"text,omitempty"`        // The full text of the endnote
 	PageNumber string `json:"page_number,omitempty"` // The page where this endnote definition appears
 }
 
 // Quotation represents a significant or representative direct quotation from the document
 type Quotation struct {
-	QuotationText string `json:"quotation_text,omitempty"` // The exact quoted text
-	PageNumber    string `json:"page_number,omitempty"`    // The source page number where the quote appears
-	Context       string `json:"context,omitempty"`        // Brief context about where this appears in the document
-	Relevance     string `json:"relevance,omitempty"`      // Explanation of why this quotation is significant
+	QuotationText string  `json:"quotation_text,omitempty"` // The exact quoted text
+	PageNumber    string  `json:"page_number,omitempty"`    // The source page number where the quote appears
+	Context       string  `json:"context,omitempty"`        // Brief context about where this appears in the document
+	Relevance     string  `json:"relevance,omitempty"`      // Explanation of why this quotation is significant
+	Translation   string  `json:"translation,omitempty"`    // The quotation translated into the requested target language
+	Verified      bool    `json:"verified"`                 // Whether the text was found verbatim in the document
+	MatchScore    float64 `json:"match_score"`              // Fraction of the quotation's words found in order in the source text (0-1)
+	// Where a verified quotation was found in its page's stored content, and
+	// the hash of that content (see documents.PageContentHash). The offsets are
+	// stale once the page's content no longer has the hash.
+	Span     *TextSpan `json:"span,omitempty"`
+	PageHash string    `json:"page_hash,omitempty"`
+	// Whether the quotation is among those chosen for the document, and its
+	// place in the prioritization (1 = most significant; 0 if the quotations
+	// were not prioritized). Quotations prioritization left out are kept
+	// unselected so a larger max_quotations can promote them.
+	Selected bool `json:"selected"`
+	Rank     int  `json:"rank,omitempty"`
+}
+
+// TextSpan is a range of byte offsets into a page's stored content, from Start
+// up to but not including End
+type TextSpan struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Annotation is a reader's note on a document, optionally tied to a page or quotation
+type Annotation struct {
+	ID             int64  `json:"id"`
+	DocumentID     string `json:"document_id"`
+	PageNumber     string `json:"page_number,omitempty"`     // Source page number the note is about
+	QuotationIndex *int   `json:"quotation_index,omitempty"` // Index (0-based) of the quotation the note is about
+	Text           string `json:"text"`
+	CreatedAt      string `json:"created_at,omitempty"`
+	UpdatedAt      string `json:"updated_at,omitempty"`
+}
+
+// Section is a part of a document delimited by a markdown heading. It runs to the next
+// heading of the same or a higher level, so it includes its subsections.
+type Section struct {
+	Title          string `json:"title,omitempty"`
+	Level          int    `json:"level,omitempty"`            // Heading level, 1 ("#") through 6
+	StartPage      string `json:"start_page,omitempty"`       // Source page number where the section starts
+	EndPage        string `json:"end_page,omitempty"`         // Source page number where the section ends
+	StartPageIndex int    `json:"start_page_index,omitempty"` // Sequential page (1-indexed) where the section starts
+	EndPageIndex   int    `json:"end_page_index,omitempty"`   // Sequential page (1-indexed) where the section ends
+	StartOffset    int    `json:"start_offset"`               // Byte offset of the heading in the page contents joined by blank lines
+	EndOffset      int    `json:"end_offset"`                 // Byte offset where the section ends (exclusive)
+}
+
+// SectionSummary is the summary of one top-level section of a document, written
+// when it is summarized section by section
+type SectionSummary struct {
+	SectionIndex int    `json:"section_index"` // Index (0-based) of the section in the document's section index
+	Title        string `json:"title,omitempty"`
+	StartPage    string `json:"start_page,omitempty"` // Source page number where the section starts
+	EndPage      string `json:"end_page,omitempty"`   // Source page number where the section ends
+	Summary      string `json:"summary"`
 }
 
 // DocumentData represents a document in various formats
 type DocumentData struct {
-	Data []byte
-	Type string // pdf, html, md, docx, etc.
+	Data  []byte
+	Type  string     // pdf, html, md, docx, etc.
+	Pages PageRange  // For a PDF, the pages to read; the whole document if unset
+	Fetch *FetchInfo // Where Data came from and its checksum; nil if unknown
+}
+
+// Checksum returns the hex SHA-256 of the document's bytes, from its fetch
+// info if it has one
+func (d DocumentData) Checksum() string {
+	if d.Fetch != nil && d.Fetch.SHA256 != "" {
+		return d.Fetch.SHA256
+	}
+	hash := sha256.Sum256(d.Data)
+	return hex.EncodeToString(hash[:])
+}
+
+// FetchInfo records how the bytes a document was parsed from were obtained,
+// so a parse can be traced back to exactly what produced it. The checksum and
+// size are of the bytes as fetched, before a Zotero snapshot is unzipped.
+type FetchInfo struct {
+	SourceURL   string `json:"source_url,omitempty"`   // URL requested; for a Zotero file, its API download URL
+	FinalURL    string `json:"final_url,omitempty"`    // URL the bytes came from after redirects, if known
+	ContentType string `json:"content_type,omitempty"` // Content-Type of the response, or of the Zotero attachment
+	FetchedAt   string `json:"fetched_at,omitempty"`   // When the bytes were fetched (RFC 3339); empty for raw data
+	SHA256      string `json:"sha256"`                 // Hex SHA-256 of the bytes
+	Size        int64  `json:"size"`                   // Number of bytes
+}
+
+// PageRange selects physical pages of a PDF, 1-indexed and inclusive. A zero
+// Start or End stands for the first or last page.
+type PageRange struct {
+	Start int `json:"start,omitempty"`
+	End   int `json:"end,omitempty"`
+}
+
+// IsSet reports whether the range selects fewer than all pages
+func (r PageRange) IsSet() bool {
+	return r.Start > 1 || r.End > 0
+}
+
+// Offset returns the number of pages before the range
+func (r PageRange) Offset() int {
+	return max(r.Start-1, 0)
+}
+
+// String formats the range as e.g. "12-40" or "12-end"
+func (r PageRange) String() string {
+	end := "end"
+	if r.End > 0 {
+		end = strconv.Itoa(r.End)
+	}
+	return strconv.Itoa(max(r.Start, 1)) + "-" + end
 }
 
 type DocumentPageData []byte
@@ -114,13 +451,259 @@ type DocumentPages []DocumentPageData
 type SourceInfo struct {
 	ZoteroID string `json:"zotero_id,omitempty"`
 	URL      string `json:"url,omitempty"`
+
+	// Zotero library containing ZoteroID (empty values fall back to environment defaults)
+	ZoteroLibraryType string `json:"zotero_library_type,omitempty"` // "user" or "group"
+	ZoteroLibraryID   string `json:"zotero_library_id,omitempty"`
+
+	// Pages of a PDF the document was parsed from, if not all of them
+	Pages PageRange `json:"pages,omitzero"`
+}
+
+// ZoteroLibrary identifies a Zotero user or group library
+type ZoteroLibrary struct {
+	Type string `json:"type"` // "user" or "group"
+	ID   string `json:"id"`
+}
+
+// DocumentSource is one of the sources a stored document was obtained from. A work
+// parsed from several sources (e.g., a URL and a Zotero attachment) is stored once,
+// with every source recorded against it.
+type DocumentSource struct {
+	SourceID string `json:"source_id"` // Document ID the source alone would be given
+	SourceInfo
+	AddedAt string `json:"added_at,omitempty"`
 }
 
 // DocumentInfo contains basic information about a stored document
 type DocumentInfo struct {
-	DocumentID string     `json:"document_id"`
-	Title      string     `json:"title,omitempty"`
-	Authors    []string   `json:"authors,omitempty"`
-	DOI        string     `json:"doi,omitempty"`
-	SourceInfo SourceInfo `json:"source_info,omitempty"`
+	DocumentID      string     `json:"document_id"`
+	Library         string     `json:"library"` // The library the document belongs to (see storage.DefaultLibrary)
+	Title           string     `json:"title,omitempty"`
+	Authors         []string   `json:"authors,omitempty"`
+	PublicationDate string     `json:"publication_date,omitempty"`
+	Publication     string     `json:"publication,omitempty"`
+	DOI             string     `json:"doi,omitempty"`
+	ItemType        string     `json:"item_type,omitempty"`
+	Language        string     `json:"language,omitempty"`
+	Citekey         string     `json:"citekey,omitempty"`
+	Partial         bool       `json:"partial,omitempty"`    // Parsed for metadata only (see ParsedItem.Partial)
+	Basic           bool       `json:"basic,omitempty"`      // Parsed without the model (see ParsedItem.Basic)
+	Keywords        []string   `json:"keywords,omitempty"`   // Keywords the authors list for the document
+	Tags            []string   `json:"tags,omitempty"`       // Tags of the Zotero item the document came from
+	Statements      []string   `json:"statements,omitempty"` // The statements the document has (see Statements)
+	SourceInfo      SourceInfo `json:"source_info,omitempty"`
+	Provenance      Provenance `json:"provenance"`
+}
+
+// Names of the statements a document may have (see ItemMetadata), as listed in
+// DocumentInfo.Statements
+const (
+	StatementFunding          = "funding_statement"
+	StatementAcknowledgments  = "acknowledgments"
+	StatementDataAvailability = "data_availability"
+)
+
+// Statements lists the statement names
+var Statements = []string{StatementFunding, StatementAcknowledgments, StatementDataAvailability}
+
+// LibrarySearch selects stored documents by their bibliographic fields. Text
+// fields match case-insensitively anywhere in the field, or at its start (or the
+// start of an author's name) with Prefix; every field set must match.
+type LibrarySearch struct {
+	Title       string
+	Author      string
+	Publication string
+	DOI         string
+	YearFrom    int // Earliest publication year; documents without a year do not match a range
+	YearTo      int // Latest publication year
+	Prefix      bool
+	Limit       int // Most documents to return; 0 for all
+	Offset      int // Matching documents to skip
+}
+
+// LibrarySearchResult is a stored document found by a library search
+type LibrarySearchResult struct {
+	DocumentInfo
+	HasSummary bool `json:"has_summary"` // The document has a summary in some style
+}
+
+// LibraryStats contains aggregate statistics about all stored documents
+type LibraryStats struct {
+	DocumentCount    int           `json:"document_count"`
+	TotalPages       int           `json:"total_pages"`
+	TotalQuotations  int           `json:"total_quotations"` // Selected quotations (see Quotation.Selected)
+	DocumentsByYear  []YearCount   `json:"documents_by_year,omitempty"`
+	TopAuthors       []AuthorCount `json:"top_authors,omitempty"`
+	MissingDOI       int           `json:"missing_doi"`
+	MissingCitekey   int           `json:"missing_citekey"`
+	MissingSummary   int           `json:"missing_summary"`
+	UndatedDocuments int           `json:"undated_documents"`
+	LibraryUsage     *UsageSummary `json:"library_usage,omitempty"` // OpenAI usage recorded for all documents, by operation
+}
+
+// VerifyCheck names a check of the store's consistency
+type VerifyCheck string
+
+const (
+	VerifyOrphanedRows     VerifyCheck = "orphaned_rows"     // Rows referring to a document, reference, image, or job that is not stored
+	VerifyEmptyDocuments   VerifyCheck = "empty_documents"   // Fully parsed documents without pages
+	VerifyPageNumbering    VerifyCheck = "page_numbering"    // Sequential page numbers that do not run from 1 without gaps
+	VerifyDuplicateCitekey VerifyCheck = "duplicate_citekey" // Citekeys shared by documents of a library, ignoring case
+)
+
+// VerifyFinding is one inconsistency found in the store
+type VerifyFinding struct {
+	Check       VerifyCheck `json:"check"`
+	Message     string      `json:"message"`
+	Table       string      `json:"table,omitempty"`
+	Count       int         `json:"count,omitempty"`        // Rows affected, for orphaned rows
+	DocumentIDs []string    `json:"document_ids,omitempty"` // Documents concerned; for orphaned rows, the missing ones referred to
+	Repairable  bool        `json:"repairable"`             // Whether repairing the store fixes it
+}
+
+// VerifyReport is what verifying the store found, by severity, and what
+// repairing it did
+type VerifyReport struct {
+	Checks   []VerifyCheck   `json:"checks"`
+	Errors   []VerifyFinding `json:"errors"`   // Documents read wrongly or citekeys BibTeX rejects
+	Warnings []VerifyFinding `json:"warnings"` // Rows nothing reads, left behind by removed documents
+	Repaired bool            `json:"repaired"`
+	Actions  []string        `json:"actions,omitempty"` // What repairing did
+}
+
+// YearCount is the number of documents published in a given year
+type YearCount struct {
+	Year  string `json:"year"`
+	Count int    `json:"count"`
+}
+
+// Author is an author name split into its parts (see citations.ParseAuthor)
+type Author struct {
+	Family string `json:"family"`
+	Given  string `json:"given,omitempty"`
+	Suffix string `json:"suffix,omitempty"` // Generational suffix such as "Jr." or "III"
+	Raw    string `json:"raw,omitempty"`    // The author as extracted from the document
+}
+
+// LibraryAuthor is a distinct author in the library and the documents attributed to them
+type LibraryAuthor struct {
+	Name          string   `json:"name"` // "Family, Given"
+	Family        string   `json:"family"`
+	Given         string   `json:"given,omitempty"`
+	Suffix        string   `json:"suffix,omitempty"`
+	DocumentCount int      `json:"document_count"`
+	DocumentIDs   []string `json:"document_ids"`
+}
+
+// AuthorCount is the number of documents attributed to a given author
+type AuthorCount struct {
+	Author string `json:"author"`
+	Count  int    `json:"count"`
+}
+
+// TokenUsage counts the OpenAI requests made with one model and the tokens they used
+type TokenUsage struct {
+	Operation        string  `json:"operation,omitempty"` // "parse", "reparse", "summarize", or "quotations"
+	Model            string  `json:"model"`
+	Requests         int     `json:"requests"`
+	InputTokens      int64   `json:"input_tokens"`
+	OutputTokens     int64   `json:"output_tokens"`
+	EstimatedCostUSD float64 `json:"estimated_cost_usd"`
+}
+
+// UsageSummary totals OpenAI usage with an estimated cost in US dollars
+type UsageSummary struct {
+	Requests         int          `json:"requests"`
+	InputTokens      int64        `json:"input_tokens"`
+	OutputTokens     int64        `json:"output_tokens"`
+	EstimatedCostUSD float64      `json:"estimated_cost_usd"`
+	UnpricedModels   []string     `json:"unpriced_models,omitempty"` // Models without a configured price, not included in the cost
+	Breakdown        []TokenUsage `json:"breakdown,omitempty"`
+}
+
+// JobStatus is the state of a parsing job or of one document in it
+type JobStatus string
+
+const (
+	JobPending   JobStatus = "pending"   // Waiting for a worker
+	JobRunning   JobStatus = "running"   // Being parsed; for a job, some documents have started and some remain
+	JobDone      JobStatus = "done"      // Parsed; the document ID is set
+	JobFailed    JobStatus = "failed"    // Parsing failed; the error is set
+	JobCancelled JobStatus = "cancelled" // Cancelled before it finished
+	JobCompleted JobStatus = "completed" // For a job: every document is done, failed, or cancelled
+)
+
+// Job is a batch of documents queued for parsing in the background
+type Job struct {
+	ID             string         `json:"job_id"`
+	Status         JobStatus      `json:"status"`
+	LinkDuplicates bool           `json:"link_duplicates"`
+	CreatedAt      string         `json:"created_at,omitempty"`
+	Items          []JobItem      `json:"items"`
+	Counts         map[string]int `json:"counts"` // Number of items in each status
+}
+
+// JobItem is one document of a Job: its source, as given to document-parse, and
+// its progress
+type JobItem struct {
+	JobID          string        `json:"-"`
+	Index          int           `json:"index"`
+	ZoteroID       string        `json:"zotero_id,omitempty"`
+	URL            string        `json:"url,omitempty"`
+	RawData        []byte        `json:"-"` // Cleared once the item finishes
+	DocType        string        `json:"doc_type,omitempty"`
+	Library        ZoteroLibrary `json:"-"`
+	Status         JobStatus     `json:"status"`
+	DocumentID     string        `json:"document_id,omitempty"`
+	Error          string        `json:"error,omitempty"`
+	ErrorCode      ErrorCode     `json:"error_code,omitempty"`
+	StartedAt      string        `json:"started_at,omitempty"`
+	FinishedAt     string        `json:"finished_at,omitempty"`
+	LinkDuplicates bool          `json:"-"` // Copied from the job
+}
+
+// ErrorCode classifies why a tool call, or one document in it, failed
+type ErrorCode string
+
+const (
+	ErrorInvalidInput   ErrorCode = "invalid_input"   // The request is malformed or names a source that cannot be used
+	ErrorNotFound       ErrorCode = "not_found"       // A document, Zotero item, or stored part of a document does not exist
+	ErrorUpstreamLLM    ErrorCode = "upstream_llm"    // OpenAI failed, rejected the request (e.g., quota exceeded), or returned unusable output
+	ErrorUpstreamZotero ErrorCode = "upstream_zotero" // The Zotero API failed or rejected the request
+	ErrorUpstreamFetch  ErrorCode = "upstream_fetch"  // A document could not be downloaded from its URL
+	ErrorStorage        ErrorCode = "storage"         // The document store failed
+	ErrorCancelled      ErrorCode = "cancelled"       // The call was cancelled or timed out
+	ErrorDisabled       ErrorCode = "disabled"        // The tool is disabled by the server's configuration
+	ErrorConfiguration  ErrorCode = "configuration"   // A credential the call needs is not set, or the config file is invalid
+	ErrorInternal       ErrorCode = "internal"        // Any other failure
+)
+
+// ToolError is the machine-readable form of an error reported by a tool
+type ToolError struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+}
+
+// CodedError is an error classified with an ErrorCode where its cause is known
+type CodedError struct {
+	Code ErrorCode
+	Err  error
+}
+
+func (e *CodedError) Error() string { return e.Err.Error() }
+
+func (e *CodedError) Unwrap() error { return e.Err }
+
+// WithErrorCode classifies err with code. An error already classified further
+// down its chain keeps its code, since that is closer to the cause; nil stays nil.
+func WithErrorCode(code ErrorCode, err error) error {
+	if err == nil {
+		return nil
+	}
+	var coded *CodedError
+	if errors.As(err, &coded) {
+		return err
+	}
+	return &CodedError{Code: code, Err: err}
 }
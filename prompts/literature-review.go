@@ -0,0 +1,115 @@
+package prompts
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+)
+
+const (
+	defaultReviewDocuments  = 5
+	defaultReviewQuotations = 5
+	defaultReviewSearch     = 25
+)
+
+// literatureReviewTemplate is the workflow the model is asked to follow
+var literatureReviewTemplate = template.Must(template.New("literature-review").Parse(`Write a literature review on the topic: "{{.Topic}}".
+
+Work through the following steps using the academic-mcp tools.
+
+1. Find candidate sources. Call zotero-search with query "{{.Topic}}"{{if .Collection}} and collection "{{.Collection}}"{{end}}, limit {{.SearchLimit}}, and item_types ["-attachment", "-note"]. If the quick search finds too little, retry with narrower or broader keywords drawn from the topic.
+2. Choose the {{.MaxDocuments}} most relevant items based on their titles, creators, and dates. Skip items without a PDF, HTML, or other document attachment.
+3. Parse the chosen documents. Call document-parse with the attachment keys as zotero_id, passing them together in the documents array so they are processed concurrently. Note each document's citekey.
+4. Summarize each document with document-summarize.
+5. Extract up to {{.MaxQuotations}} quotations from each document with document-quotations (max_quotations: {{.MaxQuotations}}).
+6. Synthesize the review. Organize it by theme rather than source by source: identify points of agreement, disagreement, methodological differences, and open questions. Support each claim with the sources it comes from.
+
+Citation rules:
+- Cite sources with Pandoc citekeys, e.g. [@smith2020] or [@smith2020, p. 12] when quoting.
+- Use the page_number recorded with each quotation for page citations.
+- Quote only text returned by document-quotations or read from the documents' resources; never invent quotations or page numbers.
+
+Finish with a bibliography of the cited works, which you can produce with bibliography-export using the cited documents' IDs.`))
+
+// literatureReviewArgs are the values substituted into the literature review template
+type literatureReviewArgs struct {
+	Topic         string
+	Collection    string
+	MaxDocuments  int
+	MaxQuotations int
+	SearchLimit   int
+}
+
+func LiteratureReviewPrompt() *mcp.Prompt {
+	return &mcp.Prompt{
+		Name:        "literature-review",
+		Title:       "Literature review",
+		Description: "Guides a literature review on a topic: search Zotero, parse and summarize the most relevant documents, extract quotations, and synthesize a review citing sources by citekey.",
+		Arguments: []*mcp.PromptArgument{
+			{Name: "topic", Description: "Research topic or question to review", Required: true},
+			{Name: "collection", Description: "Zotero collection key to restrict the search to (optional)"},
+			{Name: "max_documents", Description: fmt.Sprintf("Maximum number of documents to include (default %d)", defaultReviewDocuments)},
+			{Name: "max_quotations", Description: fmt.Sprintf("Maximum quotations to extract per document (default %d)", defaultReviewQuotations)},
+			{Name: "search_limit", Description: fmt.Sprintf("Maximum Zotero search results to consider (default %d)", defaultReviewSearch)},
+		},
+	}
+}
+
+func LiteratureReviewPromptHandler(ctx context.Context, req *mcp.GetPromptRequest, log logger.Logger) (*mcp.GetPromptResult, error) {
+	log.Info("literature-review prompt requested")
+
+	arguments := req.Params.Arguments
+	args := literatureReviewArgs{
+		Topic:      strings.TrimSpace(arguments["topic"]),
+		Collection: strings.TrimSpace(arguments["collection"]),
+	}
+	if args.Topic == "" {
+		return nil, fmt.Errorf("topic is required")
+	}
+
+	var err error
+	if args.MaxDocuments, err = positiveIntArgument(arguments, "max_documents", defaultReviewDocuments); err != nil {
+		return nil, err
+	}
+	if args.MaxQuotations, err = positiveIntArgument(arguments, "max_quotations", defaultReviewQuotations); err != nil {
+		return nil, err
+	}
+	if args.SearchLimit, err = positiveIntArgument(arguments, "search_limit", defaultReviewSearch); err != nil {
+		return nil, err
+	}
+	if args.SearchLimit < args.MaxDocuments {
+		args.SearchLimit = args.MaxDocuments
+	}
+
+	var text strings.Builder
+	if err := literatureReviewTemplate.Execute(&text, args); err != nil {
+		return nil, fmt.Errorf("failed to render literature-review prompt: %w", err)
+	}
+
+	return &mcp.GetPromptResult{
+		Description: fmt.Sprintf("Literature review on %q", args.Topic),
+		Messages: []*mcp.PromptMessage{
+			{Role: "user", Content: &mcp.TextContent{Text: text.String()}},
+		},
+	}, nil
+}
+
+// positiveIntArgument parses an optional integer prompt argument, returning
+// defaultValue when it is absent
+func positiveIntArgument(arguments map[string]string, name string, defaultValue int) (int, error) {
+	raw := strings.TrimSpace(arguments[name])
+	if raw == "" {
+		return defaultValue, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 1 {
+		return 0, fmt.Errorf("%s must be a positive integer, got %q", name, raw)
+	}
+	return value, nil
+}
@@ -0,0 +1,108 @@
+package prompts
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+)
+
+func renderLiteratureReview(t *testing.T, arguments map[string]string) (*mcp.GetPromptResult, error) {
+	t.Helper()
+	req := &mcp.GetPromptRequest{Params: &mcp.GetPromptParams{Name: "literature-review", Arguments: arguments}}
+	return LiteratureReviewPromptHandler(context.Background(), req, logger.NewNoOpLogger())
+}
+
+func TestLiteratureReviewPromptHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		arguments      map[string]string
+		wantContain    []string
+		wantNotContain []string
+	}{
+		{
+			name:      "topic only uses defaults",
+			arguments: map[string]string{"topic": "urban heat islands"},
+			wantContain: []string{
+				`topic: "urban heat islands"`,
+				`query "urban heat islands", limit 25`,
+				"Choose the 5 most relevant items",
+				"max_quotations: 5",
+				"[@smith2020]",
+			},
+			wantNotContain: []string{"collection \""},
+		},
+		{
+			name: "all arguments substituted",
+			arguments: map[string]string{
+				"topic":          "soil carbon",
+				"collection":     "ABCD1234",
+				"max_documents":  "8",
+				"max_quotations": "3",
+				"search_limit":   "40",
+			},
+			wantContain: []string{
+				`topic: "soil carbon"`,
+				`collection "ABCD1234"`,
+				"limit 40",
+				"Choose the 8 most relevant items",
+				"Extract up to 3 quotations",
+				"max_quotations: 3",
+			},
+		},
+		{
+			name:        "search limit raised to cover max documents",
+			arguments:   map[string]string{"topic": "soil carbon", "max_documents": "30", "search_limit": "10"},
+			wantContain: []string{"limit 30", "Choose the 30 most relevant items"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := renderLiteratureReview(t, tt.arguments)
+			if err != nil {
+				t.Fatalf("LiteratureReviewPromptHandler() error = %v", err)
+			}
+			if len(result.Messages) != 1 {
+				t.Fatalf("Expected 1 message, got %d", len(result.Messages))
+			}
+			content, ok := result.Messages[0].Content.(*mcp.TextContent)
+			if !ok {
+				t.Fatalf("Expected text content, got %T", result.Messages[0].Content)
+			}
+			for _, want := range tt.wantContain {
+				if !strings.Contains(content.Text, want) {
+					t.Errorf("Prompt missing %q:\n%s", want, content.Text)
+				}
+			}
+			for _, notWant := range tt.wantNotContain {
+				if strings.Contains(content.Text, notWant) {
+					t.Errorf("Prompt unexpectedly contains %q", notWant)
+				}
+			}
+		})
+	}
+}
+
+func TestLiteratureReviewPromptHandler_InvalidArguments(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments map[string]string
+	}{
+		{name: "missing topic", arguments: map[string]string{"collection": "ABCD1234"}},
+		{name: "blank topic", arguments: map[string]string{"topic": "   "}},
+		{name: "non-numeric limit", arguments: map[string]string{"topic": "soil carbon", "max_documents": "many"}},
+		{name: "zero quotations", arguments: map[string]string{"topic": "soil carbon", "max_quotations": "0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := renderLiteratureReview(t, tt.arguments); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
@@ -1,97 +1,107 @@
 package resources
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"os"
+	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/Epistemic-Technology/academic-mcp/internal/citations"
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
+	"github.com/Epistemic-Technology/academic-mcp/internal/llm"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/operations"
 	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
 )
 
+// libraryResourceID is the reserved path segment for library-wide resources (pdf://library/...)
+const libraryResourceID = "library"
+
+// defaultMaxPageRange is the most pages a pdf://{docID}/pages/{start}-{end} request
+// or one window of pdf://{docID}/pages may span
+const defaultMaxPageRange = 20
+
+// defaultMaxBibliographyBytes is the most BibTeX pdf://library/bibliography
+// returns at once; a larger library is paged through with offset
+const defaultMaxBibliographyBytes = 512 * 1024
+
+// bibTeXMIMEType is the MIME type of the BibTeX resources
+const bibTeXMIMEType = "application/x-bibtex"
+
 // PDFResourceHandler handles resource requests for parsed PDF documents
 type PDFResourceHandler struct {
-	store storage.Store
+	store                storage.Store
+	maxPageRange         int
+	maxBibliographyBytes int
 }
 
-// NewPDFResourceHandler creates a new PDF resource handler
+// NewPDFResourceHandler creates a new PDF resource handler. The maximum span of a page
+// range request can be set with ACADEMIC_MCP_MAX_PAGE_RANGE.
 func NewPDFResourceHandler(store storage.Store) *PDFResourceHandler {
-	return &PDFResourceHandler{store: store}
+	maxPageRange := defaultMaxPageRange
+	if value := os.Getenv("ACADEMIC_MCP_MAX_PAGE_RANGE"); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n > 0 {
+			maxPageRange = n
+		}
+	}
+	return &PDFResourceHandler{store: store, maxPageRange: maxPageRange, maxBibliographyBytes: defaultMaxBibliographyBytes}
 }
 
-// ListResources returns a list of available resources
+// ListResources returns a resource for each stored document: its summary
+// (pdf://{docID}), which gives the URIs of the document's other resources.
+// Resources are named by citekey and title, so a client can browse the library
+// without knowing document IDs.
 func (h *PDFResourceHandler) ListResources(ctx context.Context) ([]mcp.Resource, error) {
-	docs, err := h.store.ListDocuments(ctx)
+	docs, err := h.store.ListDocuments(ctx, "")
 	if err != nil {
 		return nil, fmt.Errorf("failed to list documents: %w", err)
 	}
 
-	var resources []mcp.Resource
+	resources := make([]mcp.Resource, 0, len(docs))
 	for _, doc := range docs {
-		// Add main document resource
-		resources = append(resources, mcp.Resource{
-			URI:         fmt.Sprintf("pdf://%s", doc.DocumentID),
-			Name:        fmt.Sprintf("%s (Document)", doc.Title),
-			Description: fmt.Sprintf("Parsed PDF document: %s", doc.Title),
-			MIMEType:    "application/json",
-		})
-
-		// Add metadata resource
-		resources = append(resources, mcp.Resource{
-			URI:         fmt.Sprintf("pdf://%s/metadata", doc.DocumentID),
-			Name:        fmt.Sprintf("%s (Metadata)", doc.Title),
-			Description: "Document metadata including title, authors, DOI, and abstract",
-			MIMEType:    "application/json",
-		})
-
-		// Add pages resource
-		resources = append(resources, mcp.Resource{
-			URI:         fmt.Sprintf("pdf://%s/pages", doc.DocumentID),
-			Name:        fmt.Sprintf("%s (All Pages)", doc.Title),
-			Description: "All pages of the document",
-			MIMEType:    "application/json",
-		})
-
-		// Add references resource
-		resources = append(resources, mcp.Resource{
-			URI:         fmt.Sprintf("pdf://%s/references", doc.DocumentID),
-			Name:        fmt.Sprintf("%s (References)", doc.Title),
-			Description: "All references cited in the document",
-			MIMEType:    "application/json",
-		})
-
-		// Add images resource
-		resources = append(resources, mcp.Resource{
-			URI:         fmt.Sprintf("pdf://%s/images", doc.DocumentID),
-			Name:        fmt.Sprintf("%s (Images)", doc.Title),
-			Description: "All images from the document",
-			MIMEType:    "application/json",
-		})
-
-		// Add tables resource
-		resources = append(resources, mcp.Resource{
-			URI:         fmt.Sprintf("pdf://%s/tables", doc.DocumentID),
-			Name:        fmt.Sprintf("%s (Tables)", doc.Title),
-			Description: "All tables from the document",
-			MIMEType:    "application/json",
-		})
+		title := doc.Title
+		if title == "" {
+			title = "Untitled document"
+		}
+		name := title
+		if doc.Citekey != "" {
+			name = fmt.Sprintf("%s: %s", doc.Citekey, title)
+		}
 
-		// Add footnotes resource
-		resources = append(resources, mcp.Resource{
-			URI:         fmt.Sprintf("pdf://%s/footnotes", doc.DocumentID),
-			Name:        fmt.Sprintf("%s (Footnotes)", doc.Title),
-			Description: "All footnotes from the document",
-			MIMEType:    "application/json",
-		})
+		description := "Parsed document"
+		if len(doc.Authors) > 0 {
+			description += " by " + strings.Join(doc.Authors, "; ")
+		}
+		if doc.PublicationDate != "" {
+			description += fmt.Sprintf(" (%s)", doc.PublicationDate)
+		}
+		if doc.Language != "" {
+			description += fmt.Sprintf(", language: %s", doc.Language)
+		}
+		if doc.Library != storage.DefaultLibrary {
+			description += fmt.Sprintf(", library: %s", doc.Library)
+		}
+		if doc.Partial {
+			description += ", metadata only"
+		}
+		if doc.Basic {
+			description += ", extracted text only"
+		}
+		description += ". The summary lists the URIs of its pages, references, and other resources."
 
-		// Add endnotes resource
 		resources = append(resources, mcp.Resource{
-			URI:         fmt.Sprintf("pdf://%s/endnotes", doc.DocumentID),
-			Name:        fmt.Sprintf("%s (Endnotes)", doc.Title),
-			Description: "All endnotes from the document",
+			URI:         fmt.Sprintf("pdf://%s", doc.DocumentID),
+			Name:        name,
+			Title:       title,
+			Description: description,
 			MIMEType:    "application/json",
 		})
 	}
@@ -101,35 +111,69 @@ func (h *PDFResourceHandler) ListResources(ctx context.Context) ([]mcp.Resource,
 
 // ReadResource reads a specific resource by URI
 func (h *PDFResourceHandler) ReadResource(ctx context.Context, uri string) (*mcp.ReadResourceResult, error) {
-	// Parse URI: pdf://doc_id/resource_type/optional_index
-	if !strings.HasPrefix(uri, "pdf://") {
-		return nil, fmt.Errorf("invalid URI scheme, expected pdf://")
+	parsed, err := parseResourceURI(uri)
+	if err != nil {
+		return nil, err
 	}
-
-	path := strings.TrimPrefix(uri, "pdf://")
-	parts := strings.Split(path, "/")
-
-	if len(parts) == 0 {
-		return nil, fmt.Errorf("invalid URI, missing document ID")
+	docID, resourceType, index, query := parsed.DocumentID, parsed.Type, parsed.Index, parsed.Query
+	if parsed.ByStableID {
+		index, err = h.store.ResolveStableID(ctx, docID, resourceType, parsed.Item)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	docID := parts[0]
-	resourceType := ""
-	index := -1
-
-	if len(parts) > 1 {
-		resourceType = parts[1]
+	// Query parameters page through the all-pages resource, choose a table's format,
+	// and size a page's context window
+	pagesListing := resourceType == "pages" && parsed.Item == ""
+	singleTable := resourceType == "tables" && index >= 0
+	libraryResource := docID == libraryResourceID
+	if len(query) > 0 && !pagesListing && !singleTable && resourceType != "context" && !parsed.PageSpan && !libraryResource {
+		return nil, fmt.Errorf("%w: query parameters are only supported for pdf://{documentId}/pages, pdf://{documentId}/pages/{sourcePage}/span, pdf://{documentId}/tables/{tableIndex}, pdf://{documentId}/context/{sourcePage}, and the pdf://library resources", ErrBadRequest)
 	}
-	if len(parts) > 2 {
-		var err error
-		index, err = strconv.Atoi(parts[2])
+
+	var content string
+	mimeType := "application/json"
+
+	if libraryResource {
+		// The library query parameter restricts library resources to one library
+		library := query.Get("library")
+		if library != "" {
+			if err := storage.ValidateLibrary(library); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrBadRequest, err)
+			}
+		}
+		switch resourceType {
+		case "stats":
+			content, err = h.getLibraryStats(ctx, library)
+		case "authors":
+			content, err = h.getLibraryAuthors(ctx, library)
+		case "bibliography":
+			content, err = h.getLibraryBibliography(ctx, library, query)
+			mimeType = bibTeXMIMEType
+		default:
+			return nil, fmt.Errorf("%w: unknown library resource: %s", ErrResourceNotFound, resourceType)
+		}
 		if err != nil {
-			return nil, fmt.Errorf("invalid index: %s", parts[2])
+			return nil, err
 		}
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{
+				{
+					URI:      uri,
+					MIMEType: mimeType,
+					Text:     content,
+				},
+			},
+		}, nil
 	}
 
-	var content string
-	var err error
+	if parsed.Data {
+		return h.getImageData(ctx, uri, docID, index)
+	}
+	if parsed.PageImage {
+		return h.getPageImage(ctx, uri, docID, parsed.Item)
+	}
 
 	switch resourceType {
 	case "":
@@ -137,16 +181,36 @@ func (h *PDFResourceHandler) ReadResource(ctx context.Context, uri string) (*mcp
 		content, err = h.getDocumentSummary(ctx, docID)
 	case "metadata":
 		content, err = h.getMetadata(ctx, docID)
+	case "bibtex":
+		content, err = h.getBibTeX(ctx, docID)
+		mimeType = bibTeXMIMEType
 	case "pages":
-		if len(parts) > 2 {
-			// Try to get page by source page number (e.g., "125" or "iv")
-			pageIdentifier := parts[2]
-			content, err = h.getPageByIdentifier(ctx, docID, pageIdentifier)
+		if parsed.PageSpan {
+			content, err = h.getPageSpan(ctx, docID, parsed.Item, query)
+		} else if parsed.Item != "" {
+			pageIdentifier := parsed.Item
+			if start, end, isRange := strings.Cut(pageIdentifier, "-"); isRange && start != "" && end != "" {
+				// Page range (e.g., "12-18" or "iv-x")
+				content, err = h.getPageRange(ctx, docID, pageIdentifier, start, end)
+			} else {
+				// Try to get page by source page number (e.g., "125" or "iv")
+				content, err = h.getPageByIdentifier(ctx, docID, pageIdentifier)
+			}
 		} else {
-			content, err = h.getAllPages(ctx, docID)
+			content, err = h.getAllPages(ctx, docID, query)
 		}
-	case "references":
+	case "context":
+		content, err = h.getPageContext(ctx, docID, parsed.Item, query)
+	case "sections":
 		if index >= 0 {
+			content, err = h.getSection(ctx, docID, index)
+		} else {
+			content, err = h.getAllSections(ctx, docID)
+		}
+	case "references":
+		if parsed.ByPage {
+			content, err = h.getReferencesOnPage(ctx, docID, parsed.Item)
+		} else if index >= 0 {
 			content, err = h.getReference(ctx, docID, index)
 		} else {
 			content, err = h.getAllReferences(ctx, docID)
@@ -159,7 +223,7 @@ func (h *PDFResourceHandler) ReadResource(ctx context.Context, uri string) (*mcp
 		}
 	case "tables":
 		if index >= 0 {
-			content, err = h.getTable(ctx, docID, index)
+			content, mimeType, err = h.getTable(ctx, docID, index, query.Get("format"))
 		} else {
 			content, err = h.getAllTables(ctx, docID)
 		}
@@ -175,14 +239,20 @@ func (h *PDFResourceHandler) ReadResource(ctx context.Context, uri string) (*mcp
 		} else {
 			content, err = h.getAllEndnotes(ctx, docID)
 		}
+	case "fulltext":
+		content, err = h.getFullText(ctx, docID)
+	case "notes":
+		content, err = h.getNotes(ctx, docID)
 	case "quotations":
 		if index >= 0 {
 			content, err = h.getQuotation(ctx, docID, index)
 		} else {
 			content, err = h.getAllQuotations(ctx, docID)
 		}
+	case "annotations":
+		content, err = h.getAnnotations(ctx, docID)
 	default:
-		return nil, fmt.Errorf("unknown resource type: %s", resourceType)
+		return nil, fmt.Errorf("%w: unknown resource type: %s", ErrResourceNotFound, resourceType)
 	}
 
 	if err != nil {
@@ -193,7 +263,7 @@ func (h *PDFResourceHandler) ReadResource(ctx context.Context, uri string) (*mcp
 		Contents: []*mcp.ResourceContents{
 			{
 				URI:      uri,
-				MIMEType: "application/json",
+				MIMEType: mimeType,
 				Text:     content,
 			},
 		},
@@ -202,6 +272,44 @@ func (h *PDFResourceHandler) ReadResource(ctx context.Context, uri string) (*mcp
 
 // Helper functions to retrieve specific content
 
+func (h *PDFResourceHandler) getLibraryStats(ctx context.Context, library string) (string, error) {
+	stats, err := h.store.GetLibraryStats(ctx, library, 10)
+	if err != nil {
+		return "", err
+	}
+	usage, err := h.store.GetLibraryUsage(ctx, library)
+	if err != nil {
+		return "", err
+	}
+	stats.LibraryUsage = llm.SummarizeUsage(usage, logger.NewNoOpLogger())
+
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal library stats: %w", err)
+	}
+
+	return string(data), nil
+}
+
+func (h *PDFResourceHandler) getLibraryAuthors(ctx context.Context, library string) (string, error) {
+	authors, err := h.store.GetAuthors(ctx, library)
+	if err != nil {
+		return "", err
+	}
+
+	result := map[string]interface{}{
+		"author_count": len(authors),
+		"authors":      authors,
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal authors: %w", err)
+	}
+
+	return string(data), nil
+}
+
 func (h *PDFResourceHandler) getDocumentSummary(ctx context.Context, docID string) (string, error) {
 	metadata, err := h.store.GetMetadata(ctx, docID)
 	if err != nil {
@@ -243,28 +351,69 @@ func (h *PDFResourceHandler) getDocumentSummary(ctx context.Context, docID strin
 		return "", err
 	}
 
+	sections, err := h.store.GetSections(ctx, docID)
+	if err != nil {
+		return "", err
+	}
+
+	sources, err := h.store.GetDocumentSources(ctx, docID)
+	if err != nil {
+		return "", err
+	}
+
+	annotations, err := h.store.GetAnnotations(ctx, docID)
+	if err != nil {
+		return "", err
+	}
+
+	partial, err := h.store.IsPartial(ctx, docID)
+	if err != nil {
+		return "", err
+	}
+
+	pageNumbering, err := h.store.GetPageNumbering(ctx, docID)
+	if err != nil {
+		return "", err
+	}
+
 	summary := map[string]interface{}{
-		"document_id":     docID,
-		"metadata":        metadata,
-		"page_count":      len(pages),
-		"ref_count":       len(refs),
-		"image_count":     len(images),
-		"table_count":     len(tables),
-		"footnote_count":  len(footnotes),
-		"endnote_count":   len(endnotes),
-		"quotation_count": len(quotations),
+		"document_id":      docID,
+		"metadata":         metadata,
+		"page_count":       len(pages),
+		"ref_count":        len(refs),
+		"image_count":      len(images),
+		"table_count":      len(tables),
+		"footnote_count":   len(footnotes),
+		"endnote_count":    len(endnotes),
+		"quotation_count":  len(quotations),
+		"section_count":    len(sections),
+		"annotation_count": len(annotations),
+		"metadata_source":  metadata.MetadataSource,
+		"has_doi":          metadata.DOI != "",
+		"partial":          partial,
+		"sources":          sources,
 		"available_resources": []string{
 			fmt.Sprintf("pdf://%s/metadata", docID),
+			fmt.Sprintf("pdf://%s/bibtex", docID),
 			fmt.Sprintf("pdf://%s/pages", docID),
+			fmt.Sprintf("pdf://%s/sections", docID),
 			fmt.Sprintf("pdf://%s/references", docID),
 			fmt.Sprintf("pdf://%s/images", docID),
 			fmt.Sprintf("pdf://%s/tables", docID),
 			fmt.Sprintf("pdf://%s/footnotes", docID),
 			fmt.Sprintf("pdf://%s/endnotes", docID),
 			fmt.Sprintf("pdf://%s/quotations", docID),
+			fmt.Sprintf("pdf://%s/annotations", docID),
 		},
 	}
 
+	if pageNumbering != "" {
+		summary["page_numbering"] = pageNumbering
+	}
+	if partial {
+		summary["partial_note"] = "Only the metadata and abstract were parsed (document-parse mode \"metadata\"); parse the document again without a mode for its pages, references, and other content"
+	}
+
 	data, err := json.MarshalIndent(summary, "", "  ")
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal summary: %w", err)
@@ -278,8 +427,20 @@ func (h *PDFResourceHandler) getMetadata(ctx context.Context, docID string) (str
 	if err != nil {
 		return "", err
 	}
+	provenance, err := h.store.GetProvenance(ctx, docID)
+	if err != nil {
+		return "", err
+	}
+	fetch, err := h.store.GetFetchInfo(ctx, docID)
+	if err != nil {
+		return "", err
+	}
 
-	data, err := json.MarshalIndent(metadata, "", "  ")
+	data, err := json.MarshalIndent(struct {
+		*models.ItemMetadata
+		Provenance *models.Provenance `json:"provenance"`
+		Fetch      *models.FetchInfo  `json:"fetch,omitempty"`
+	}{metadata, provenance, fetch}, "", "  ")
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal metadata: %w", err)
 	}
@@ -287,23 +448,102 @@ func (h *PDFResourceHandler) getMetadata(ctx context.Context, docID string) (str
 	return string(data), nil
 }
 
-func (h *PDFResourceHandler) getPage(ctx context.Context, docID string, pageNum int) (string, error) {
-	page, err := h.store.GetPage(ctx, docID, pageNum+1) // Convert 0-indexed to 1-indexed
+// getBibTeX returns the document's BibTeX entry, generated from its stored
+// metadata as bibliography-export writes it
+func (h *PDFResourceHandler) getBibTeX(ctx context.Context, docID string) (string, error) {
+	metadata, err := h.store.GetMetadata(ctx, docID)
 	if err != nil {
 		return "", err
 	}
+	if metadata.Citekey == "" {
+		return "", fmt.Errorf("document %s has no citekey, so it has no BibTeX entry; parse it again or run document-refresh-metadata to assign one", docID)
+	}
+	return citations.GenerateBibTeXEntry(docID, metadata, metadata.Citekey), nil
+}
 
-	result := map[string]interface{}{
-		"page_number": pageNum,
-		"content":     page,
+// getLibraryBibliography returns the .bib file of every document with a
+// citekey in library, or in every library if it is empty, ordered by citekey. Entries are returned from the offset query
+// parameter (0 by default), at most limit of them if it is given, and no more
+// than maxBibliographyBytes of them, though always at least one; when entries
+// remain, a comment at the top gives the URI of the next window.
+func (h *PDFResourceHandler) getLibraryBibliography(ctx context.Context, library string, query url.Values) (string, error) {
+	offset, limit := 0, 0
+	if value := query.Get("offset"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return "", fmt.Errorf("invalid offset: %s", value)
+		}
+		offset = n
+	}
+	if value := query.Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return "", fmt.Errorf("invalid limit: %s", value)
+		}
+		limit = n
 	}
 
-	data, err := json.MarshalIndent(result, "", "  ")
+	docs, err := h.store.ListDocuments(ctx, library)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal page: %w", err)
+		return "", fmt.Errorf("failed to list documents: %w", err)
+	}
+	var cited []models.DocumentInfo
+	missing := 0
+	for _, doc := range docs {
+		if doc.Citekey == "" {
+			missing++
+			continue
+		}
+		cited = append(cited, doc)
+	}
+	slices.SortStableFunc(cited, func(a, b models.DocumentInfo) int {
+		return cmp.Or(cmp.Compare(strings.ToLower(a.Citekey), strings.ToLower(b.Citekey)), cmp.Compare(a.Citekey, b.Citekey))
+	})
+	if offset > 0 && offset >= len(cited) {
+		return "", fmt.Errorf("offset %d is past the last entry (library has %d entries)", offset, len(cited))
 	}
 
-	return string(data), nil
+	var entries []string
+	size := 0
+	end := offset
+	for _, doc := range cited[offset:] {
+		if limit > 0 && len(entries) == limit {
+			break
+		}
+		metadata, err := h.store.GetMetadata(ctx, doc.DocumentID)
+		if err != nil {
+			return "", err
+		}
+		entry := citations.GenerateBibTeXEntry(doc.DocumentID, metadata, metadata.Citekey)
+		if len(entries) > 0 && size+len(entry) > h.maxBibliographyBytes {
+			break
+		}
+		entries = append(entries, entry)
+		size += len(entry) + 1
+		end++
+	}
+
+	var header strings.Builder
+	if offset > 0 || end < len(cited) {
+		fmt.Fprintf(&header, "%% Entries %d-%d of %d\n", offset+1, end, len(cited))
+		if end < len(cited) {
+			next := fmt.Sprintf("pdf://library/bibliography?offset=%d", end)
+			if limit > 0 {
+				next += fmt.Sprintf("&limit=%d", limit)
+			}
+			if library != "" {
+				next += "&library=" + url.QueryEscape(library)
+			}
+			fmt.Fprintf(&header, "%% Next: %s\n", next)
+		}
+	}
+	if missing > 0 {
+		fmt.Fprintf(&header, "%% %d documents without citekeys are not included\n", missing)
+	}
+	if header.Len() > 0 {
+		header.WriteString("\n")
+	}
+	return header.String() + citations.GenerateBibTeXFile(entries), nil
 }
 
 // getPageByIdentifier retrieves a page by source page number (e.g., "125", "iv")
@@ -319,6 +559,20 @@ func (h *PDFResourceHandler) getPageByIdentifier(ctx context.Context, docID stri
 		"content":            content,
 	}
 
+	quality, err := h.store.GetPageQuality(ctx, docID)
+	if err != nil {
+		return "", err
+	}
+	if quality != nil {
+		mapping, err := h.store.GetPageMapping(ctx, docID)
+		if err != nil {
+			return "", err
+		}
+		if seq, ok := mapping[pageIdentifier]; ok && seq <= len(quality) {
+			result["quality"] = quality[seq-1]
+		}
+	}
+
 	data, err := json.MarshalIndent(result, "", "  ")
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal page: %w", err)
@@ -327,7 +581,92 @@ func (h *PDFResourceHandler) getPageByIdentifier(ctx context.Context, docID stri
 	return string(data), nil
 }
 
-func (h *PDFResourceHandler) getAllPages(ctx context.Context, docID string) (string, error) {
+// defaultSpanContext and maxSpanContext are the default and largest number of
+// bytes of text on each side of a span that pdf://{docID}/pages/{sourcePage}/span
+// returns
+const (
+	defaultSpanContext = 200
+	maxSpanContext     = 2000
+)
+
+// getPageSpan returns the text of a page between the byte offsets given by the
+// start and end query parameters, such as a quotation's span, with the text
+// around it (context bytes on each side) and the sentences it falls in. The
+// response has the page's content hash; given the hash the offsets were taken
+// with (the hash query parameter), it also reports whether the page has changed
+// since, which makes the offsets stale.
+func (h *PDFResourceHandler) getPageSpan(ctx context.Context, docID string, sourcePage string, query url.Values) (string, error) {
+	var span models.TextSpan
+	for _, param := range []struct {
+		name   string
+		target *int
+	}{{"start", &span.Start}, {"end", &span.End}} {
+		value := query.Get(param.name)
+		if value == "" {
+			return "", fmt.Errorf("%w: the %s query parameter is required", ErrBadRequest, param.name)
+		}
+		n, err := parseIndex(value)
+		if err != nil {
+			return "", fmt.Errorf("%w: invalid %s: %s", ErrBadRequest, param.name, value)
+		}
+		*param.target = n
+	}
+	around := defaultSpanContext
+	if value := query.Get("context"); value != "" {
+		n, err := parseIndex(value)
+		if err != nil || n > maxSpanContext {
+			return "", fmt.Errorf("%w: context must be a number of bytes from 0 to %d: %s", ErrBadRequest, maxSpanContext, value)
+		}
+		around = n
+	}
+
+	content, err := h.store.GetPageBySourceNumber(ctx, docID, sourcePage)
+	if err != nil {
+		return "", err
+	}
+	excerpt, err := documents.ExcerptSpan(content, span, around)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrBadRequest, err)
+	}
+	sentences, err := h.store.GetPageSentences(ctx, docID, sourcePage)
+	if err != nil {
+		return "", err
+	}
+	if sentences == nil {
+		// Pages stored before sentences were recorded
+		sentences = documents.SegmentSentences(content)
+	}
+
+	hash := documents.PageContentHash(content)
+	result := map[string]interface{}{
+		"document_id":        docID,
+		"source_page_number": sourcePage,
+		"start":              span.Start,
+		"end":                span.End,
+		"text":               excerpt.Text,
+		"before":             excerpt.Before,
+		"after":              excerpt.After,
+		"sentences":          documents.OverlappingSentences(sentences, span),
+		"page_length":        len(content),
+		"content_hash":       hash,
+	}
+	if expected := query.Get("hash"); expected != "" {
+		result["stale"] = expected != hash
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal span: %w", err)
+	}
+	return string(data), nil
+}
+
+func (h *PDFResourceHandler) getAllPages(ctx context.Context, docID string, query url.Values) (string, error) {
+	window, err := h.parsePageWindow(query)
+	if err != nil {
+		return "", err
+	}
+
 	pages, err := h.store.GetPages(ctx, docID)
 	if err != nil {
 		return "", err
@@ -339,41 +678,409 @@ func (h *PDFResourceHandler) getAllPages(ctx context.Context, docID string) (str
 		return "", err
 	}
 
+	if window.all {
+		pageList := buildPageList(pages, mapping, 1, len(pages))
+		if err := h.attachPageQuality(ctx, docID, pageList); err != nil {
+			return "", err
+		}
+		result := map[string]interface{}{
+			"page_count": len(pages),
+			"pages":      pageList,
+			"note":       "Access individual pages using source page numbers, e.g., pdf://" + docID + "/pages/125",
+		}
+
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal pages: %w", err)
+		}
+		return string(data), nil
+	}
+
+	if window.offset > 0 && window.offset >= len(pages) {
+		return "", fmt.Errorf("offset %d is past the last page (document has %d pages)", window.offset, len(pages))
+	}
+	end := min(window.offset+window.limit, len(pages))
+	pageList := buildPageList(pages, mapping, window.offset+1, end)
+	if err := h.attachPageQuality(ctx, docID, pageList); err != nil {
+		return "", err
+	}
+
+	result := map[string]interface{}{
+		"page_count": len(pages),
+		"offset":     window.offset,
+		"limit":      window.limit,
+		"pages":      pageList,
+		"note":       "Access individual pages using source page numbers, e.g., pdf://" + docID + "/pages/125",
+	}
+	if end < len(pages) {
+		result["next"] = fmt.Sprintf("pdf://%s/pages?offset=%d&limit=%d", docID, end, window.limit)
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal pages: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// fullTextPage is where a page begins in the full text
+type fullTextPage struct {
+	SequentialNumber int    `json:"sequential_number"`
+	SourcePageNumber string `json:"source_page_number"`
+	Offset           int    `json:"offset"`
+}
+
+// getFullText returns the document's continuous text with the byte offset at
+// which each page begins. Documents stored before full text was recorded have
+// it built from their pages.
+func (h *PDFResourceHandler) getFullText(ctx context.Context, docID string) (string, error) {
+	text, offsets, err := h.store.GetFullText(ctx, docID)
+	if err != nil {
+		return "", err
+	}
+	pages, err := h.store.GetPages(ctx, docID)
+	if err != nil {
+		return "", err
+	}
+	if text == "" || len(offsets) != len(pages) {
+		mode, _ := documents.ConfiguredHyphenationMode()
+		text, offsets = documents.FullText(pages, mode)
+	}
+	mapping, err := h.store.GetPageMapping(ctx, docID)
+	if err != nil {
+		return "", err
+	}
+
+	pageList := make([]fullTextPage, len(offsets))
+	for i, page := range buildPageList(pages, mapping, 1, len(pages)) {
+		pageList[i] = fullTextPage{SequentialNumber: page.SequentialNumber, SourcePageNumber: page.SourcePageNumber, Offset: offsets[i]}
+	}
+
+	result := map[string]interface{}{
+		"page_count": len(pageList),
+		"pages":      pageList,
+		"text":       text,
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal full text: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// defaultContextWindow is the number of pages on each side of the requested
+// page that pdf://{docID}/context/{sourcePage} returns
+const defaultContextWindow = 1
+
+// contextFootnote is a footnote in a page's context, with its index in the
+// footnotes resource
+type contextFootnote struct {
+	Index int `json:"index"`
+	models.Footnote
+}
+
+// contextQuotation is a stored quotation in a page's context, with its index
+// in the quotations resource
+type contextQuotation struct {
+	Index int `json:"index"`
+	models.Quotation
+}
+
+// getPageContext returns a page with the pages around it and the footnotes,
+// quotations, and annotations recorded for it, so a citation can be checked in
+// one read. The page is identified like a page range end, by source page
+// number or else sequential number; the window query parameter sets how many
+// pages on each side are included (default 1, at most the maximum page range).
+// Pages past the start or end of the document are left out.
+func (h *PDFResourceHandler) getPageContext(ctx context.Context, docID string, pageIdentifier string, query url.Values) (string, error) {
+	window := defaultContextWindow
+	if value := query.Get("window"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return "", fmt.Errorf("%w: invalid window: %s", ErrBadRequest, value)
+		}
+		if n > h.maxPageRange {
+			return "", fmt.Errorf("%w: window %d exceeds the maximum of %d pages", ErrBadRequest, n, h.maxPageRange)
+		}
+		window = n
+	}
+
+	pages, err := h.store.GetPages(ctx, docID)
+	if err != nil {
+		return "", err
+	}
+	if len(pages) == 0 {
+		return "", fmt.Errorf("document %w: %s", storage.ErrNotFound, docID)
+	}
+	mapping, err := h.store.GetPageMapping(ctx, docID)
+	if err != nil {
+		return "", err
+	}
+	seq, err := resolvePageNumber(mapping, pageIdentifier, len(pages))
+	if err != nil {
+		return "", fmt.Errorf("page %w: %s source page %s", storage.ErrNotFound, docID, pageIdentifier)
+	}
+	pageList := buildPageList(pages, mapping, max(seq-window, 1), min(seq+window, len(pages)))
+	if err := h.attachPageQuality(ctx, docID, pageList); err != nil {
+		return "", err
+	}
+	current := seq - max(seq-window, 1)
+	sourcePage := pageList[current].SourcePageNumber
+
+	footnotes, err := h.store.GetFootnotes(ctx, docID)
+	if err != nil {
+		return "", err
+	}
+	pageFootnotes := make([]contextFootnote, 0)
+	for i, footnote := range footnotes {
+		if footnote.PageNumber == sourcePage {
+			pageFootnotes = append(pageFootnotes, contextFootnote{Index: i, Footnote: footnote})
+		}
+	}
+
+	quotations, err := h.store.GetQuotations(ctx, docID)
+	if err != nil {
+		return "", err
+	}
+	pageQuotations := make([]contextQuotation, 0)
+	for i, quotation := range quotations {
+		if quotation.PageNumber == sourcePage {
+			pageQuotations = append(pageQuotations, contextQuotation{Index: i, Quotation: quotation})
+		}
+	}
+
+	annotations, err := h.store.GetAnnotations(ctx, docID)
+	if err != nil {
+		return "", err
+	}
+	pageAnnotations := make([]models.Annotation, 0)
+	for _, annotation := range annotations {
+		if annotation.PageNumber == sourcePage {
+			pageAnnotations = append(pageAnnotations, annotation)
+		}
+	}
+
+	result := map[string]interface{}{
+		"source_page_number": sourcePage,
+		"sequential_number":  seq,
+		"window":             window,
+		"page":               pageList[current],
+		"previous_pages":     pageList[:current],
+		"next_pages":         pageList[current+1:],
+		"footnotes":          pageFootnotes,
+		"quotations":         pageQuotations,
+		"annotations":        pageAnnotations,
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal page context: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// pageWindow selects the pages returned by pdf://{docID}/pages
+type pageWindow struct {
+	offset int  // Zero-based index of the first page
+	limit  int  // Most pages to return
+	all    bool // Return every page, ignoring offset and limit
+}
+
+// parsePageWindow reads the offset, limit, and all query parameters. The limit
+// defaults to, and may not exceed, the maximum page range.
+func (h *PDFResourceHandler) parsePageWindow(query url.Values) (pageWindow, error) {
+	window := pageWindow{limit: h.maxPageRange}
+
+	if value := query.Get("all"); value != "" {
+		all, err := strconv.ParseBool(value)
+		if err != nil {
+			return window, fmt.Errorf("invalid all: %s", value)
+		}
+		window.all = all
+	}
+	if value := query.Get("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			return window, fmt.Errorf("invalid offset: %s", value)
+		}
+		window.offset = offset
+	}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			return window, fmt.Errorf("invalid limit: %s", value)
+		}
+		if limit > h.maxPageRange && !window.all {
+			return window, fmt.Errorf("limit %d exceeds the maximum of %d pages; page through with offset or request all=true", limit, h.maxPageRange)
+		}
+		window.limit = limit
+	}
+	return window, nil
+}
+
+// getPageRange retrieves the pages from start to end inclusive. Each end is matched
+// against source page numbers first and falls back to a sequential page number.
+func (h *PDFResourceHandler) getPageRange(ctx context.Context, docID string, pageRange string, start string, end string) (string, error) {
+	pages, err := h.store.GetPages(ctx, docID)
+	if err != nil {
+		return "", err
+	}
+	if len(pages) == 0 {
+		return "", fmt.Errorf("document not found or has no pages: %s", docID)
+	}
+
+	mapping, err := h.store.GetPageMapping(ctx, docID)
+	if err != nil {
+		return "", err
+	}
+
+	// A source page number may itself contain a hyphen (e.g., "A-1")
+	if _, ok := mapping[pageRange]; ok {
+		return h.getPageByIdentifier(ctx, docID, pageRange)
+	}
+
+	startPage, err := resolvePageNumber(mapping, start, len(pages))
+	if err != nil {
+		return "", err
+	}
+	endPage, err := resolvePageNumber(mapping, end, len(pages))
+	if err != nil {
+		return "", err
+	}
+	if startPage > endPage {
+		return "", fmt.Errorf("invalid page range %s: start page comes after end page", pageRange)
+	}
+	if span := endPage - startPage + 1; span > h.maxPageRange {
+		return "", fmt.Errorf("page range %s spans %d pages, more than the maximum of %d", pageRange, span, h.maxPageRange)
+	}
+
+	pageList := buildPageList(pages, mapping, startPage, endPage)
+	if err := h.attachPageQuality(ctx, docID, pageList); err != nil {
+		return "", err
+	}
+
+	result := map[string]interface{}{
+		"start_page": start,
+		"end_page":   end,
+		"page_count": len(pageList),
+		"pages":      pageList,
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal pages: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// resolvePageNumber returns the 1-indexed sequential number of the page identified
+// by a source page number or, failing that, a sequential page number
+func resolvePageNumber(mapping map[string]int, pageIdentifier string, pageCount int) (int, error) {
+	if seq, ok := mapping[pageIdentifier]; ok {
+		return seq, nil
+	}
+	if n, err := strconv.Atoi(pageIdentifier); err == nil && n >= 1 && n <= pageCount {
+		return n, nil
+	}
+	return 0, fmt.Errorf("page not found: %s", pageIdentifier)
+}
+
+// pageInfo is a page with both its sequential and source page numbers
+type pageInfo struct {
+	SequentialNumber int                 `json:"sequential_number"`
+	SourcePageNumber string              `json:"source_page_number"`
+	Content          string              `json:"content"`
+	Quality          *models.PageQuality `json:"quality,omitempty"` // Quality flags and assessment, for PDF pages that have them
+}
+
+// attachPageQuality sets the quality of each page in pageList, if the
+// document records any
+func (h *PDFResourceHandler) attachPageQuality(ctx context.Context, docID string, pageList []pageInfo) error {
+	quality, err := h.store.GetPageQuality(ctx, docID)
+	if err != nil {
+		return err
+	}
+	for i := range pageList {
+		if seq := pageList[i].SequentialNumber; seq <= len(quality) {
+			pageList[i].Quality = &quality[seq-1]
+		}
+	}
+	return nil
+}
+
+// buildPageList returns the pages from sequential page first to last inclusive (1-indexed)
+func buildPageList(pages []string, mapping map[string]int, first int, last int) []pageInfo {
 	// Build reverse mapping (sequential -> source)
 	reverseMapping := make(map[int]string)
 	for source, seq := range mapping {
 		reverseMapping[seq] = source
 	}
 
-	// Build page list with both sequential and source numbers
-	type pageInfo struct {
-		SequentialNumber int    `json:"sequential_number"`
-		SourcePageNumber string `json:"source_page_number"`
-		Content          string `json:"content"`
-	}
-
-	pageList := make([]pageInfo, len(pages))
-	for i, content := range pages {
-		sourceNum := reverseMapping[i+1] // i+1 because pages are 1-indexed in DB
+	pageList := make([]pageInfo, 0, last-first+1)
+	for seq := first; seq <= last; seq++ {
+		sourceNum := reverseMapping[seq]
 		if sourceNum == "" {
-			sourceNum = fmt.Sprintf("%d", i+1)
+			sourceNum = fmt.Sprintf("%d", seq)
 		}
-		pageList[i] = pageInfo{
-			SequentialNumber: i + 1,
+		pageList = append(pageList, pageInfo{
+			SequentialNumber: seq,
 			SourcePageNumber: sourceNum,
-			Content:          content,
-		}
+			Content:          pages[seq-1], // pages are 1-indexed in DB
+		})
+	}
+	return pageList
+}
+
+func (h *PDFResourceHandler) getAllSections(ctx context.Context, docID string) (string, error) {
+	sections, err := h.store.GetSections(ctx, docID)
+	if err != nil {
+		return "", err
 	}
 
 	result := map[string]interface{}{
-		"page_count": len(pages),
-		"pages":      pageList,
-		"note":       "Access individual pages using source page numbers, e.g., pdf://" + docID + "/pages/125",
+		"section_count": len(sections),
+		"sections":      sections,
+		"note":          "Read a section's text using its index, e.g., pdf://" + docID + "/sections/0",
 	}
 
 	data, err := json.MarshalIndent(result, "", "  ")
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal pages: %w", err)
+		return "", fmt.Errorf("failed to marshal sections: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// getSection retrieves a section along with its text, cut from the document's joined page contents
+func (h *PDFResourceHandler) getSection(ctx context.Context, docID string, sectionIndex int) (string, error) {
+	section, err := h.store.GetSection(ctx, docID, sectionIndex)
+	if err != nil {
+		return "", err
+	}
+
+	pages, err := h.store.GetPages(ctx, docID)
+	if err != nil {
+		return "", err
+	}
+	text := documents.JoinPages(pages)
+	if section.StartOffset < 0 || section.EndOffset > len(text) || section.StartOffset > section.EndOffset {
+		return "", fmt.Errorf("section %d of %s is out of date with the stored pages", sectionIndex, docID)
+	}
+
+	result := map[string]interface{}{
+		"section_index": sectionIndex,
+		"section":       section,
+		"content":       strings.TrimSpace(text[section.StartOffset:section.EndOffset]),
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal section: %w", err)
 	}
 
 	return string(data), nil
@@ -412,6 +1119,26 @@ func (h *PDFResourceHandler) getAllReferences(ctx context.Context, docID string)
 	return string(data), nil
 }
 
+func (h *PDFResourceHandler) getReferencesOnPage(ctx context.Context, docID string, sourcePage string) (string, error) {
+	refs, err := h.store.GetReferencesBySourcePage(ctx, docID, sourcePage)
+	if err != nil {
+		return "", err
+	}
+
+	result := map[string]interface{}{
+		"source_page_number": sourcePage,
+		"reference_count":    len(refs),
+		"references":         refs,
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal references: %w", err)
+	}
+
+	return string(data), nil
+}
+
 func (h *PDFResourceHandler) getImage(ctx context.Context, docID string, imageIndex int) (string, error) {
 	img, err := h.store.GetImage(ctx, docID, imageIndex)
 	if err != nil {
@@ -426,6 +1153,41 @@ func (h *PDFResourceHandler) getImage(ctx context.Context, docID string, imageIn
 	return string(data), nil
 }
 
+// getImageData returns the bytes of an image extracted from the PDF as a blob
+// with the image's MIME type
+func (h *PDFResourceHandler) getImageData(ctx context.Context, uri string, docID string, imageIndex int) (*mcp.ReadResourceResult, error) {
+	data, mimeType, err := h.store.GetImageData(ctx, docID, imageIndex)
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      uri,
+				MIMEType: mimeType,
+				Blob:     data,
+			},
+		},
+	}, nil
+}
+
+// getPageImage returns the PNG of a page, rendering it on its first request
+func (h *PDFResourceHandler) getPageImage(ctx context.Context, uri string, docID string, sourcePage string) (*mcp.ReadResourceResult, error) {
+	image, err := operations.RenderPageImage(ctx, h.store, docID, sourcePage, logger.FromContext(ctx, logger.NewNoOpLogger()))
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      uri,
+				MIMEType: "image/png",
+				Blob:     image.Data,
+			},
+		},
+	}, nil
+}
+
 func (h *PDFResourceHandler) getAllImages(ctx context.Context, docID string) (string, error) {
 	images, err := h.store.GetImages(ctx, docID)
 	if err != nil {
@@ -445,18 +1207,41 @@ func (h *PDFResourceHandler) getAllImages(ctx context.Context, docID string) (st
 	return string(data), nil
 }
 
-func (h *PDFResourceHandler) getTable(ctx context.Context, docID string, tableIndex int) (string, error) {
+// getTable returns a table as JSON (the default), CSV, or markdown, along with
+// the MIME type of that format
+func (h *PDFResourceHandler) getTable(ctx context.Context, docID string, tableIndex int, format string) (string, string, error) {
 	tbl, err := h.store.GetTable(ctx, docID, tableIndex)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
+	structureStoredTable(tbl)
 
-	data, err := json.MarshalIndent(tbl, "", "  ")
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal table: %w", err)
+	switch strings.ToLower(format) {
+	case "", "json":
+		data, err := json.MarshalIndent(tbl, "", "  ")
+		if err != nil {
+			return "", "", fmt.Errorf("failed to marshal table: %w", err)
+		}
+		return string(data), "application/json", nil
+	case "csv":
+		content, err := documents.TableCSV(*tbl)
+		if err != nil {
+			return "", "", fmt.Errorf("table %d cannot be exported as CSV: %w", tableIndex, err)
+		}
+		return content, "text/csv", nil
+	case "markdown", "md":
+		return documents.TableMarkdown(*tbl), "text/markdown", nil
+	default:
+		return "", "", fmt.Errorf("unsupported table format %q (expected csv, json, or markdown)", format)
 	}
+}
 
-	return string(data), nil
+// structureStoredTable parses the data of a table stored before tables were
+// structured on parse
+func structureStoredTable(tbl *models.Table) {
+	if tbl.Columns == nil && tbl.ParseError == "" {
+		documents.StructureTable(tbl)
+	}
 }
 
 func (h *PDFResourceHandler) getAllTables(ctx context.Context, docID string) (string, error) {
@@ -464,6 +1249,9 @@ func (h *PDFResourceHandler) getAllTables(ctx context.Context, docID string) (st
 	if err != nil {
 		return "", err
 	}
+	for i := range tables {
+		structureStoredTable(&tables[i])
+	}
 
 	result := map[string]interface{}{
 		"table_count": len(tables),
@@ -544,6 +1332,80 @@ func (h *PDFResourceHandler) getAllEndnotes(ctx context.Context, docID string) (
 	return string(data), nil
 }
 
+// noteInfo is a footnote or endnote in the combined notes resource
+type noteInfo struct {
+	Type       string `json:"type"`  // "footnote" or "endnote"
+	Index      int    `json:"index"` // Index in the footnotes or endnotes resource (0-indexed)
+	Anchor     string `json:"anchor,omitempty"`
+	Marker     string `json:"marker,omitempty"`
+	Key        string `json:"key,omitempty"` // Stable key of an endnote, e.g. "ch3-n17"
+	Text       string `json:"text,omitempty"`
+	PageNumber string `json:"page_number,omitempty"`
+	InTextPage string `json:"in_text_page,omitempty"`
+}
+
+// getNotes returns footnotes and endnotes merged in the order their anchors first
+// occur in the text. Notes without an anchor follow in stored order.
+func (h *PDFResourceHandler) getNotes(ctx context.Context, docID string) (string, error) {
+	footnotes, err := h.store.GetFootnotes(ctx, docID)
+	if err != nil {
+		return "", err
+	}
+	endnotes, err := h.store.GetEndnotes(ctx, docID)
+	if err != nil {
+		return "", err
+	}
+	pages, err := h.store.GetPages(ctx, docID)
+	if err != nil {
+		return "", err
+	}
+
+	note := func(kind string, index int) (noteInfo, bool) {
+		switch {
+		case kind == documents.NoteKindFootnote && index < len(footnotes):
+			f := footnotes[index]
+			return noteInfo{Type: "footnote", Index: index, Marker: f.Marker, Text: f.Text, PageNumber: f.PageNumber, InTextPage: f.InTextPage}, true
+		case kind == documents.NoteKindEndnote && index < len(endnotes):
+			e := endnotes[index]
+			return noteInfo{Type: "endnote", Index: index, Marker: e.Marker, Key: e.Key, Text: e.Text, PageNumber: e.PageNumber}, true
+		}
+		return noteInfo{}, false
+	}
+
+	notes := make([]noteInfo, 0, len(footnotes)+len(endnotes))
+	listed := make(map[string]bool)
+	for _, ref := range documents.NoteOrder(pages) {
+		if n, ok := note(ref.Kind, ref.Index); ok {
+			n.Anchor = ref.Anchor
+			notes = append(notes, n)
+			listed[fmt.Sprintf("%s%d", ref.Kind, ref.Index)] = true
+		}
+	}
+	for _, kind := range []string{documents.NoteKindFootnote, documents.NoteKindEndnote} {
+		for index := 0; ; index++ {
+			n, ok := note(kind, index)
+			if !ok {
+				break
+			}
+			if !listed[fmt.Sprintf("%s%d", kind, index)] {
+				notes = append(notes, n)
+			}
+		}
+	}
+
+	result := map[string]interface{}{
+		"note_count": len(notes),
+		"notes":      notes,
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal notes: %w", err)
+	}
+
+	return string(data), nil
+}
+
 func (h *PDFResourceHandler) getQuotation(ctx context.Context, docID string, quotationIndex int) (string, error) {
 	quotation, err := h.store.GetQuotation(ctx, docID, quotationIndex)
 	if err != nil {
@@ -576,3 +1438,30 @@ func (h *PDFResourceHandler) getAllQuotations(ctx context.Context, docID string)
 
 	return string(data), nil
 }
+
+func (h *PDFResourceHandler) getAnnotations(ctx context.Context, docID string) (string, error) {
+	exists, err := h.store.DocumentExists(ctx, docID)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", fmt.Errorf("document not found: %s", docID)
+	}
+
+	annotations, err := h.store.GetAnnotations(ctx, docID)
+	if err != nil {
+		return "", err
+	}
+
+	result := map[string]interface{}{
+		"annotation_count": len(annotations),
+		"annotations":      annotations,
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal annotations: %w", err)
+	}
+
+	return string(data), nil
+}
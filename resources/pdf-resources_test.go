@@ -0,0 +1,1244 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/citations"
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// newTestHandler returns a handler backed by an in-memory store holding a
+// six-page document numbered from journal page "iv" through "125", with one
+// image that has extracted data and one that does not
+func newTestHandler(t *testing.T) *PDFResourceHandler {
+	t.Helper()
+	store, err := storage.NewSQLiteStore(":memory:", logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	item := &models.ParsedItem{
+		Metadata:    models.ItemMetadata{Title: "Test Document"},
+		Pages:       []string{"Preface", "Contents", "Introduction", "Methods", "Results", "Discussion"},
+		PageNumbers: []string{"iv", "v", "122", "123", "124", "125"},
+		Images: []models.Image{
+			{Caption: "Figure 1", PageIndex: 3, MIMEType: "image/png", Width: 40, Height: 40, Data: []byte("\x89PNG-data")},
+			{Caption: "Chart", PageIndex: 5},
+		},
+	}
+	if err := store.StoreParsedItem(context.Background(), "doc-1", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+
+	handler := NewPDFResourceHandler(store)
+	handler.maxPageRange = 4
+	return handler
+}
+
+func TestReadResource_PageRange(t *testing.T) {
+	handler := newTestHandler(t)
+
+	tests := []struct {
+		name          string
+		uri           string
+		expectedPages []string // content of each returned page, in order
+		expectedError string
+	}{
+		{
+			name:          "source page numbers",
+			uri:           "pdf://doc-1/pages/122-124",
+			expectedPages: []string{"Introduction", "Methods", "Results"},
+		},
+		{
+			name:          "roman numerals",
+			uri:           "pdf://doc-1/pages/iv-v",
+			expectedPages: []string{"Preface", "Contents"},
+		},
+		{
+			name:          "across numbering schemes",
+			uri:           "pdf://doc-1/pages/v-122",
+			expectedPages: []string{"Contents", "Introduction"},
+		},
+		{
+			name:          "sequential fallback",
+			uri:           "pdf://doc-1/pages/1-3",
+			expectedPages: []string{"Preface", "Contents", "Introduction"},
+		},
+		{
+			name:          "single page range",
+			uri:           "pdf://doc-1/pages/125-125",
+			expectedPages: []string{"Discussion"},
+		},
+		{
+			name:          "reversed range",
+			uri:           "pdf://doc-1/pages/124-122",
+			expectedError: "start page comes after end page",
+		},
+		{
+			name:          "span over maximum",
+			uri:           "pdf://doc-1/pages/iv-125",
+			expectedError: "more than the maximum of 4",
+		},
+		{
+			name:          "unknown page",
+			uri:           "pdf://doc-1/pages/122-300",
+			expectedError: "page not found: 300",
+		},
+		{
+			name:          "unknown document",
+			uri:           "pdf://missing/pages/1-2",
+			expectedError: "no pages",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handler.ReadResource(context.Background(), tt.uri)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadResource failed: %v", err)
+			}
+
+			var decoded struct {
+				PageCount int        `json:"page_count"`
+				Pages     []pageInfo `json:"pages"`
+			}
+			if err := json.Unmarshal([]byte(result.Contents[0].Text), &decoded); err != nil {
+				t.Fatalf("Failed to decode result: %v", err)
+			}
+			if decoded.PageCount != len(tt.expectedPages) || len(decoded.Pages) != len(tt.expectedPages) {
+				t.Fatalf("Expected %d pages, got %+v", len(tt.expectedPages), decoded)
+			}
+			for i, page := range decoded.Pages {
+				if page.Content != tt.expectedPages[i] {
+					t.Errorf("Page %d: expected %q, got %q", i, tt.expectedPages[i], page.Content)
+				}
+				if i > 0 && page.SequentialNumber != decoded.Pages[i-1].SequentialNumber+1 {
+					t.Errorf("Pages not contiguous: %+v", decoded.Pages)
+				}
+			}
+		})
+	}
+}
+
+func TestReadResource_AllPagesPagination(t *testing.T) {
+	handler := newTestHandler(t)
+
+	tests := []struct {
+		name          string
+		uri           string
+		expectedPages []string
+		expectedNext  string
+		expectedError string
+	}{
+		{
+			name:          "default window is capped",
+			uri:           "pdf://doc-1/pages",
+			expectedPages: []string{"Preface", "Contents", "Introduction", "Methods"},
+			expectedNext:  "pdf://doc-1/pages?offset=4&limit=4",
+		},
+		{
+			name:          "offset and limit",
+			uri:           "pdf://doc-1/pages?offset=2&limit=2",
+			expectedPages: []string{"Introduction", "Methods"},
+			expectedNext:  "pdf://doc-1/pages?offset=4&limit=2",
+		},
+		{
+			name:          "last partial window has no next",
+			uri:           "pdf://doc-1/pages?offset=4",
+			expectedPages: []string{"Results", "Discussion"},
+		},
+		{
+			name:          "window ending on the last page has no next",
+			uri:           "pdf://doc-1/pages?offset=2&limit=4",
+			expectedPages: []string{"Introduction", "Methods", "Results", "Discussion"},
+		},
+		{
+			name:          "all pages on request",
+			uri:           "pdf://doc-1/pages?all=true",
+			expectedPages: []string{"Preface", "Contents", "Introduction", "Methods", "Results", "Discussion"},
+		},
+		{
+			name:          "offset past the last page",
+			uri:           "pdf://doc-1/pages?offset=6",
+			expectedError: "past the last page",
+		},
+		{
+			name:          "limit above the maximum",
+			uri:           "pdf://doc-1/pages?limit=5",
+			expectedError: "exceeds the maximum",
+		},
+		{
+			name:          "zero limit",
+			uri:           "pdf://doc-1/pages?limit=0",
+			expectedError: "invalid limit",
+		},
+		{
+			name:          "negative offset",
+			uri:           "pdf://doc-1/pages?offset=-1",
+			expectedError: "invalid offset",
+		},
+		{
+			name:          "query on another resource",
+			uri:           "pdf://doc-1/metadata?offset=2",
+			expectedError: "only supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handler.ReadResource(context.Background(), tt.uri)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadResource failed: %v", err)
+			}
+
+			var decoded struct {
+				PageCount int        `json:"page_count"`
+				Next      string     `json:"next"`
+				Pages     []pageInfo `json:"pages"`
+			}
+			if err := json.Unmarshal([]byte(result.Contents[0].Text), &decoded); err != nil {
+				t.Fatalf("Failed to decode result: %v", err)
+			}
+			if decoded.PageCount != 6 {
+				t.Errorf("Expected page_count 6, got %d", decoded.PageCount)
+			}
+			if decoded.Next != tt.expectedNext {
+				t.Errorf("Expected next %q, got %q", tt.expectedNext, decoded.Next)
+			}
+			if len(decoded.Pages) != len(tt.expectedPages) {
+				t.Fatalf("Expected %d pages, got %d: %+v", len(tt.expectedPages), len(decoded.Pages), decoded.Pages)
+			}
+			for i, page := range decoded.Pages {
+				if page.Content != tt.expectedPages[i] {
+					t.Errorf("Page %d: expected %q, got %q", i, tt.expectedPages[i], page.Content)
+				}
+			}
+		})
+	}
+}
+
+func TestReadResource_SinglePage(t *testing.T) {
+	handler := newTestHandler(t)
+
+	tests := []struct {
+		uri      string
+		expected string
+	}{
+		{"pdf://doc-1/pages/123", "Methods"},
+		{"pdf://doc-1/pages/iv", "Preface"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			result, err := handler.ReadResource(context.Background(), tt.uri)
+			if err != nil {
+				t.Fatalf("ReadResource failed: %v", err)
+			}
+			if !strings.Contains(result.Contents[0].Text, tt.expected) {
+				t.Errorf("Expected content %q, got %s", tt.expected, result.Contents[0].Text)
+			}
+		})
+	}
+}
+
+func TestReadResource_ReferencesOnPage(t *testing.T) {
+	store, err := storage.NewSQLiteStore(":memory:", logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	item := &models.ParsedItem{
+		Metadata:    models.ItemMetadata{Title: "Test Document"},
+		Pages:       []string{"Discussion", "References", "More references", "Appendix"},
+		PageNumbers: []string{"124", "125", "126", "A/1"},
+		References: []models.Reference{
+			{ReferenceText: "Abbott (2001)", PageNumber: "125", PageIndex: 2},
+			{ReferenceText: "Baker (1999)", DOI: "10.1000/baker", PageNumber: "125", PageIndex: 2},
+			{ReferenceText: "Clark (2010)", PageNumber: "126", PageIndex: 3},
+		},
+	}
+	if err := store.StoreParsedItem(ctx, "doc-1", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+	handler := NewPDFResourceHandler(store)
+
+	var page struct {
+		SourcePageNumber string                 `json:"source_page_number"`
+		ReferenceCount   int                    `json:"reference_count"`
+		References       []models.PageReference `json:"references"`
+	}
+	readPage := func(uri string) {
+		t.Helper()
+		result, err := handler.ReadResource(ctx, uri)
+		if err != nil {
+			t.Fatalf("ReadResource(%s) failed: %v", uri, err)
+		}
+		page.References = nil
+		if err := json.Unmarshal([]byte(result.Contents[0].Text), &page); err != nil {
+			t.Fatalf("Failed to decode %s: %v", uri, err)
+		}
+	}
+
+	readPage("pdf://doc-1/references/pages/125")
+	if page.SourcePageNumber != "125" || page.ReferenceCount != 2 || len(page.References) != 2 ||
+		page.References[1].RefIndex != 1 || page.References[1].DOI != "10.1000/baker" || page.References[1].PageIndex != 2 {
+		t.Errorf("Unexpected references on page 125: %+v", page)
+	}
+	readPage("pdf://doc-1/references/pages/126")
+	if len(page.References) != 1 || page.References[0].RefIndex != 2 || page.References[0].ReferenceText != "Clark (2010)" {
+		t.Errorf("Unexpected references on page 126: %+v", page)
+	}
+	readPage("pdf://doc-1/references/pages/A/1")
+	if page.ReferenceCount != 0 || page.References == nil {
+		t.Errorf("Expected an empty list for a page without references, got %+v", page)
+	}
+
+	// The references resource gives every reference's page
+	result, err := handler.ReadResource(ctx, "pdf://doc-1/references")
+	if err != nil {
+		t.Fatalf("ReadResource failed: %v", err)
+	}
+	if !strings.Contains(result.Contents[0].Text, `"page_number": "126"`) || !strings.Contains(result.Contents[0].Text, `"page_index": 3`) {
+		t.Errorf("Expected page locations in the references resource, got %s", result.Contents[0].Text)
+	}
+
+	if _, err := handler.ReadResource(ctx, "pdf://doc-1/references/pages/999"); !IsNotFound(err) {
+		t.Errorf("Expected not found for an unknown page, got %v", err)
+	}
+}
+
+func TestReadResource_Sections(t *testing.T) {
+	store, err := storage.NewSQLiteStore(":memory:", logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	pages := []string{
+		"## Introduction\n\nWe introduce the study.",
+		"It continues here.\n\n## Methods\n\nWe sampled.\n\n### Sampling\n\nDetails.",
+		"## Results\n\nFindings.",
+	}
+	pageNumbers := []string{"1", "2", "3"}
+	item := &models.ParsedItem{
+		Metadata:    models.ItemMetadata{Title: "Sectioned Document"},
+		Pages:       pages,
+		PageNumbers: pageNumbers,
+		Sections:    documents.ExtractSections(pages, pageNumbers),
+	}
+	if err := store.StoreParsedItem(context.Background(), "doc-1", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+	handler := NewPDFResourceHandler(store)
+
+	t.Run("section index", func(t *testing.T) {
+		result, err := handler.ReadResource(context.Background(), "pdf://doc-1/sections")
+		if err != nil {
+			t.Fatalf("ReadResource failed: %v", err)
+		}
+		var decoded struct {
+			SectionCount int              `json:"section_count"`
+			Sections     []models.Section `json:"sections"`
+		}
+		if err := json.Unmarshal([]byte(result.Contents[0].Text), &decoded); err != nil {
+			t.Fatalf("Failed to decode result: %v", err)
+		}
+		if decoded.SectionCount != 4 || decoded.Sections[1].Title != "Methods" || decoded.Sections[0].EndPage != "2" {
+			t.Errorf("Unexpected section index: %+v", decoded)
+		}
+	})
+
+	t.Run("section text", func(t *testing.T) {
+		tests := []struct {
+			uri        string
+			contains   []string
+			notContain string
+		}{
+			{"pdf://doc-1/sections/0", []string{"## Introduction", "It continues here."}, "Methods"},
+			{"pdf://doc-1/sections/1", []string{"## Methods", "### Sampling", "Details."}, "Results"},
+			{"pdf://doc-1/sections/3", []string{"## Results", "Findings."}, "Methods"},
+		}
+		for _, tt := range tests {
+			result, err := handler.ReadResource(context.Background(), tt.uri)
+			if err != nil {
+				t.Fatalf("ReadResource(%s) failed: %v", tt.uri, err)
+			}
+			var decoded struct {
+				Content string `json:"content"`
+			}
+			if err := json.Unmarshal([]byte(result.Contents[0].Text), &decoded); err != nil {
+				t.Fatalf("Failed to decode result: %v", err)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(decoded.Content, want) {
+					t.Errorf("%s: expected content to contain %q, got %q", tt.uri, want, decoded.Content)
+				}
+			}
+			if strings.Contains(decoded.Content, tt.notContain) {
+				t.Errorf("%s: expected content not to contain %q, got %q", tt.uri, tt.notContain, decoded.Content)
+			}
+		}
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		if _, err := handler.ReadResource(context.Background(), "pdf://doc-1/sections/9"); err == nil {
+			t.Error("Expected error for out-of-range section")
+		}
+	})
+}
+
+func TestReadResource_Notes(t *testing.T) {
+	store, err := storage.NewSQLiteStore(":memory:", logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	item := &models.ParsedItem{
+		Metadata:    models.ItemMetadata{Title: "Annotated Document"},
+		Pages:       []string{"An endnoted claim.[i] A footnoted claim.[1]", "Another footnote.[2]"},
+		PageNumbers: []string{"1", "2"},
+		Footnotes: []models.Footnote{
+			{Marker: "1", Text: "First footnote", PageNumber: "1"},
+			{Marker: "2", Text: "Second footnote", PageNumber: "2"},
+			{Marker: "9", Text: "Unmatched footnote", PageNumber: "2"},
+		},
+		Endnotes: []models.Endnote{{Marker: "i", Key: "ch1-ni", Text: "An endnote", PageNumber: "2"}},
+	}
+	documents.LinkNotes(item, "doe2021")
+	if err := store.StoreParsedItem(context.Background(), "doc-1", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+
+	result, err := NewPDFResourceHandler(store).ReadResource(context.Background(), "pdf://doc-1/notes")
+	if err != nil {
+		t.Fatalf("ReadResource failed: %v", err)
+	}
+	var decoded struct {
+		NoteCount int        `json:"note_count"`
+		Notes     []noteInfo `json:"notes"`
+	}
+	if err := json.Unmarshal([]byte(result.Contents[0].Text), &decoded); err != nil {
+		t.Fatalf("Failed to decode result: %v", err)
+	}
+
+	expected := []noteInfo{
+		{Type: "endnote", Index: 0, Anchor: "[^doe2021-en1]", Marker: "i", Key: "ch1-ni", Text: "An endnote", PageNumber: "2"},
+		{Type: "footnote", Index: 0, Anchor: "[^doe2021-fn1]", Marker: "1", Text: "First footnote", PageNumber: "1", InTextPage: "1"},
+		{Type: "footnote", Index: 1, Anchor: "[^doe2021-fn2]", Marker: "2", Text: "Second footnote", PageNumber: "2", InTextPage: "2"},
+		{Type: "footnote", Index: 2, Marker: "9", Text: "Unmatched footnote", PageNumber: "2"},
+	}
+	if decoded.NoteCount != len(expected) || len(decoded.Notes) != len(expected) {
+		t.Fatalf("Expected %d notes, got %+v", len(expected), decoded)
+	}
+	for i, want := range expected {
+		if decoded.Notes[i] != want {
+			t.Errorf("Note %d: expected %+v, got %+v", i, want, decoded.Notes[i])
+		}
+	}
+}
+
+func TestReadResource_TableFormats(t *testing.T) {
+	handler := newTestHandler(t)
+	item := &models.ParsedItem{
+		Metadata:    models.ItemMetadata{Title: "Tables"},
+		Pages:       []string{"Page"},
+		PageNumbers: []string{"1"},
+		Tables: []models.Table{
+			{TableID: "Table 1", TableData: "| Group | Mean |\n|---|---|\n| Control, n=12 | 4.1 |"},
+			{TableID: "Table 2", TableData: "Group: Control"},
+		},
+	}
+	documents.StructureTables(item)
+	if err := handler.store.StoreParsedItem(context.Background(), "doc-2", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+
+	tests := []struct {
+		name             string
+		uri              string
+		expectedMIMEType string
+		expectedText     string
+		expectedError    string
+	}{
+		{"csv", "pdf://doc-2/tables/0?format=csv", "text/csv", "Group,Mean\n\"Control, n=12\",4.1\n", ""},
+		{"markdown", "pdf://doc-2/tables/0?format=markdown", "text/markdown", "| Group | Mean |\n| --- | --- |\n| Control, n=12 | 4.1 |\n", ""},
+		{"malformed table as markdown", "pdf://doc-2/tables/1?format=markdown", "text/markdown", "Group: Control\n", ""},
+		{"malformed table as csv", "pdf://doc-2/tables/1?format=csv", "", "", "could not be parsed"},
+		{"unknown format", "pdf://doc-2/tables/0?format=xlsx", "", "", "unsupported table format"},
+		{"format on all tables", "pdf://doc-2/tables?format=csv", "", "", "only supported"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handler.ReadResource(context.Background(), tt.uri)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadResource failed: %v", err)
+			}
+			if got := result.Contents[0]; got.MIMEType != tt.expectedMIMEType || got.Text != tt.expectedText {
+				t.Errorf("Expected %s %q, got %s %q", tt.expectedMIMEType, tt.expectedText, got.MIMEType, got.Text)
+			}
+		})
+	}
+
+	t.Run("json by default", func(t *testing.T) {
+		result, err := handler.ReadResource(context.Background(), "pdf://doc-2/tables/1")
+		if err != nil {
+			t.Fatalf("ReadResource failed: %v", err)
+		}
+		var table models.Table
+		if err := json.Unmarshal([]byte(result.Contents[0].Text), &table); err != nil {
+			t.Fatalf("Failed to decode table: %v", err)
+		}
+		if table.TableData != "Group: Control" || table.ParseError == "" || table.Columns != nil {
+			t.Errorf("Expected raw data with a parse error, got %+v", table)
+		}
+	})
+}
+
+func TestReadResource_Annotations(t *testing.T) {
+	handler := newTestHandler(t)
+	ctx := context.Background()
+	for _, annotation := range []models.Annotation{
+		{DocumentID: "doc-1", Text: "Read the preface again"},
+		{DocumentID: "doc-1", PageNumber: "123", Text: "Sampling is unclear"},
+	} {
+		if _, err := handler.store.AddAnnotation(ctx, annotation); err != nil {
+			t.Fatalf("AddAnnotation failed: %v", err)
+		}
+	}
+
+	result, err := handler.ReadResource(ctx, "pdf://doc-1/annotations")
+	if err != nil {
+		t.Fatalf("ReadResource failed: %v", err)
+	}
+	var decoded struct {
+		AnnotationCount int                 `json:"annotation_count"`
+		Annotations     []models.Annotation `json:"annotations"`
+	}
+	if err := json.Unmarshal([]byte(result.Contents[0].Text), &decoded); err != nil {
+		t.Fatalf("Failed to decode result: %v", err)
+	}
+	if decoded.AnnotationCount != 2 || decoded.Annotations[1].PageNumber != "123" || decoded.Annotations[1].Text != "Sampling is unclear" {
+		t.Errorf("Unexpected annotations: %+v", decoded)
+	}
+
+	summary, err := handler.ReadResource(ctx, "pdf://doc-1")
+	if err != nil {
+		t.Fatalf("ReadResource failed: %v", err)
+	}
+	var decodedSummary struct {
+		AnnotationCount int `json:"annotation_count"`
+	}
+	if err := json.Unmarshal([]byte(summary.Contents[0].Text), &decodedSummary); err != nil {
+		t.Fatalf("Failed to decode summary: %v", err)
+	}
+	if decodedSummary.AnnotationCount != 2 {
+		t.Errorf("Expected annotation_count 2 in the document summary, got %d", decodedSummary.AnnotationCount)
+	}
+
+	if _, err := handler.ReadResource(ctx, "pdf://missing/annotations"); err == nil || !strings.Contains(err.Error(), "document not found") {
+		t.Errorf("Expected document not found error, got %v", err)
+	}
+}
+
+func TestReadResource_ImageData(t *testing.T) {
+	handler := newTestHandler(t)
+
+	result, err := handler.ReadResource(context.Background(), "pdf://doc-1/images/0/data")
+	if err != nil {
+		t.Fatalf("ReadResource failed: %v", err)
+	}
+	contents := result.Contents[0]
+	if contents.MIMEType != "image/png" || string(contents.Blob) != "\x89PNG-data" || contents.Text != "" {
+		t.Errorf("Expected the PNG bytes as a blob, got %q with %q", contents.MIMEType, contents.Blob)
+	}
+
+	result, err = handler.ReadResource(context.Background(), "pdf://doc-1/images/0")
+	if err != nil {
+		t.Fatalf("ReadResource failed: %v", err)
+	}
+	if text := result.Contents[0].Text; !strings.Contains(text, `"mime_type": "image/png"`) || !strings.Contains(text, `"page_index": 3`) {
+		t.Errorf("Expected the image's JSON to describe its data, got %s", text)
+	}
+
+	// Images the parser described but whose data was not extracted have none
+	for _, uri := range []string{"pdf://doc-1/images/1/data", "pdf://doc-1/images/2/data"} {
+		if _, err := handler.ReadResource(context.Background(), uri); !IsNotFound(err) {
+			t.Errorf("%s: expected not found, got %v", uri, err)
+		}
+	}
+}
+
+func TestReadResource_StableIDs(t *testing.T) {
+	handler := newTestHandler(t)
+
+	result, err := handler.ReadResource(context.Background(), "pdf://doc-1/images")
+	if err != nil {
+		t.Fatalf("ReadResource failed: %v", err)
+	}
+	var all struct {
+		Images []models.Image `json:"images"`
+	}
+	if err := json.Unmarshal([]byte(result.Contents[0].Text), &all); err != nil {
+		t.Fatalf("Failed to decode images: %v", err)
+	}
+	images := all.Images
+	if len(images) != 2 || !strings.HasPrefix(images[0].StableID, "img-p122-1-") || !strings.HasPrefix(images[1].StableID, "img-p124-1-") {
+		t.Fatalf("Expected images with stable IDs of their source pages, got %+v", images)
+	}
+
+	result, err = handler.ReadResource(context.Background(), "pdf://doc-1/images/"+images[1].StableID)
+	if err != nil {
+		t.Fatalf("ReadResource failed: %v", err)
+	}
+	if text := result.Contents[0].Text; !strings.Contains(text, `"caption": "Chart"`) {
+		t.Errorf("Expected the image with the stable ID, got %s", text)
+	}
+
+	result, err = handler.ReadResource(context.Background(), "pdf://doc-1/images/"+images[0].StableID+"/data")
+	if err != nil {
+		t.Fatalf("ReadResource failed: %v", err)
+	}
+	if string(result.Contents[0].Blob) != "\x89PNG-data" {
+		t.Errorf("Expected the PNG bytes, got %q", result.Contents[0].Blob)
+	}
+
+	if _, err := handler.ReadResource(context.Background(), "pdf://doc-1/images/img-p1-1-000000"); !IsNotFound(err) {
+		t.Errorf("Expected not found for an unknown stable ID, got %v", err)
+	}
+}
+
+func TestReadResource_PageImage(t *testing.T) {
+	t.Setenv("ACADEMIC_MCP_PAGE_IMAGE_DPI", "")
+	handler := newTestHandler(t)
+	ctx := context.Background()
+
+	// A page rendered before is served from the store
+	if err := handler.store.StorePageImage(ctx, "doc-1", 1, 150, []byte("\x89PNG-page")); err != nil {
+		t.Fatalf("StorePageImage failed: %v", err)
+	}
+	result, err := handler.ReadResource(ctx, "pdf://doc-1/pages/iv/image")
+	if err != nil {
+		t.Fatalf("ReadResource failed: %v", err)
+	}
+	contents := result.Contents[0]
+	if contents.MIMEType != "image/png" || string(contents.Blob) != "\x89PNG-page" || contents.Text != "" {
+		t.Errorf("Expected the page PNG as a blob, got %q with %q", contents.MIMEType, contents.Blob)
+	}
+
+	// Other pages of a document parsed from raw data cannot be rendered
+	_, err = handler.ReadResource(ctx, "pdf://doc-1/pages/v/image")
+	if err == nil || IsNotFound(err) || !strings.Contains(err.Error(), "no source PDF") {
+		t.Errorf("Expected an error for a document without a source PDF, got %v", err)
+	}
+}
+
+func TestReadResource_PageSpan(t *testing.T) {
+	handler := newTestHandler(t)
+	ctx := context.Background()
+
+	page := "Smith argues otherwise. The data are sparse. Later work agrees."
+	item := &models.ParsedItem{
+		Pages:         []string{page},
+		PageNumbers:   []string{"7"},
+		PageSentences: documents.PageSentences([]string{page}),
+	}
+	if err := handler.store.StoreParsedItem(ctx, "doc-2", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+	hash := documents.PageContentHash(page)
+
+	read := func(uri string) map[string]any {
+		t.Helper()
+		result, err := handler.ReadResource(ctx, uri)
+		if err != nil {
+			t.Fatalf("ReadResource(%s) failed: %v", uri, err)
+		}
+		var span map[string]any
+		if err := json.Unmarshal([]byte(result.Contents[0].Text), &span); err != nil {
+			t.Fatalf("Failed to decode span: %v", err)
+		}
+		return span
+	}
+
+	span := read("pdf://doc-2/pages/7/span?start=28&end=44&context=5")
+	if span["text"] != "data are sparse." || span["before"] != " The " || span["after"] != " Late" {
+		t.Errorf("Unexpected excerpt: %+v", span)
+	}
+	if span["content_hash"] != hash || span["page_length"] != float64(len(page)) {
+		t.Errorf("Expected the page hash and length, got %+v", span)
+	}
+	if _, ok := span["stale"]; ok {
+		t.Errorf("Expected no staleness without a hash, got %+v", span)
+	}
+	sentences, _ := span["sentences"].([]any)
+	if len(sentences) != 1 || !reflect.DeepEqual(sentences[0], map[string]any{"start": float64(24), "end": float64(44)}) {
+		t.Errorf("Expected the second sentence, got %+v", span["sentences"])
+	}
+
+	if span := read("pdf://doc-2/pages/7/span?start=0&end=5&hash=" + hash); span["stale"] != false {
+		t.Errorf("Expected a current span, got %+v", span)
+	}
+	if span := read("pdf://doc-2/pages/7/span?start=0&end=5&hash=old"); span["stale"] != true {
+		t.Errorf("Expected a stale span, got %+v", span)
+	}
+
+	// Pages stored without sentences are segmented on demand
+	span = read("pdf://doc-1/pages/iv/span?start=0&end=3")
+	if span["text"] != "Pre" || len(span["sentences"].([]any)) != 1 {
+		t.Errorf("Expected a span of the preface with its sentence, got %+v", span)
+	}
+
+	for _, uri := range []string{
+		"pdf://doc-2/pages/7/span",
+		"pdf://doc-2/pages/7/span?start=5",
+		"pdf://doc-2/pages/7/span?start=9&end=4",
+		"pdf://doc-2/pages/7/span?start=0&end=999",
+		"pdf://doc-2/pages/7/span?start=0&end=5&context=-1",
+		"pdf://doc-2/pages/7/span?start=0&end=5&context=99999",
+	} {
+		if _, err := handler.ReadResource(ctx, uri); !errors.Is(err, ErrBadRequest) {
+			t.Errorf("%s: expected a bad request, got %v", uri, err)
+		}
+	}
+	if _, err := handler.ReadResource(ctx, "pdf://doc-2/pages/8/span?start=0&end=1"); !IsNotFound(err) {
+		t.Errorf("Expected not found for an unknown page, got %v", err)
+	}
+}
+
+func TestReadResource_MetadataProvenance(t *testing.T) {
+	handler := newTestHandler(t)
+
+	result, err := handler.ReadResource(context.Background(), "pdf://doc-1/metadata")
+	if err != nil {
+		t.Fatalf("ReadResource failed: %v", err)
+	}
+	var decoded struct {
+		Title      string            `json:"title"`
+		Provenance models.Provenance `json:"provenance"`
+		Fetch      *models.FetchInfo `json:"fetch"`
+	}
+	if err := json.Unmarshal([]byte(result.Contents[0].Text), &decoded); err != nil {
+		t.Fatalf("Failed to decode metadata: %v", err)
+	}
+	if decoded.Title != "Test Document" || decoded.Provenance.CreatedAt == "" || decoded.Provenance.UpdatedAt == "" {
+		t.Errorf("Expected metadata with storage times, got %+v", decoded)
+	}
+	if decoded.Fetch != nil {
+		t.Errorf("Expected no fetch info for a document stored without it, got %+v", decoded.Fetch)
+	}
+
+	// A fetched document shows where its bytes came from
+	ctx := context.Background()
+	item, err := handler.store.GetParsedItem(ctx, "doc-1")
+	if err != nil {
+		t.Fatalf("GetParsedItem failed: %v", err)
+	}
+	fetch := models.FetchInfo{SourceURL: "http://example.com/paper", FinalURL: "https://example.com/paper.pdf", ContentType: "application/pdf", FetchedAt: "2026-01-02T03:04:05Z", SHA256: "abc123", Size: 2048}
+	item.Fetch = &fetch
+	if err := handler.store.StoreParsedItem(ctx, "doc-1", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	result, err = handler.ReadResource(ctx, "pdf://doc-1/metadata")
+	if err != nil {
+		t.Fatalf("ReadResource failed: %v", err)
+	}
+	if err := json.Unmarshal([]byte(result.Contents[0].Text), &decoded); err != nil {
+		t.Fatalf("Failed to decode metadata: %v", err)
+	}
+	if decoded.Fetch == nil || *decoded.Fetch != fetch {
+		t.Errorf("Expected fetch info %+v, got %+v", fetch, decoded.Fetch)
+	}
+}
+
+func TestReadResource_Summary(t *testing.T) {
+	handler := newTestHandler(t)
+	ctx := context.Background()
+
+	item := &models.ParsedItem{
+		Metadata:      models.ItemMetadata{Title: "Notes on Notes", DOI: "10.1000/notes", MetadataSource: "zotero"},
+		Pages:         []string{"one", "two"},
+		PageNumbers:   []string{"41", "42"},
+		PageNumbering: models.PageNumberingSource,
+		Footnotes:     []models.Footnote{{Marker: "1", Text: "A", PageNumber: "41"}, {Marker: "2", Text: "B", PageNumber: "42"}},
+		Endnotes:      []models.Endnote{{Marker: "i", Text: "C", PageNumber: "42"}},
+	}
+	if err := handler.store.StoreParsedItem(ctx, "doc-2", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+
+	type summary struct {
+		FootnoteCount  int    `json:"footnote_count"`
+		EndnoteCount   int    `json:"endnote_count"`
+		PageNumbering  string `json:"page_numbering"`
+		MetadataSource string `json:"metadata_source"`
+		HasDOI         bool   `json:"has_doi"`
+	}
+	read := func(docID string) summary {
+		t.Helper()
+		result, err := handler.ReadResource(ctx, "pdf://"+docID)
+		if err != nil {
+			t.Fatalf("ReadResource failed: %v", err)
+		}
+		var decoded summary
+		if err := json.Unmarshal([]byte(result.Contents[0].Text), &decoded); err != nil {
+			t.Fatalf("Failed to decode summary: %v", err)
+		}
+		return decoded
+	}
+
+	want := summary{FootnoteCount: 2, EndnoteCount: 1, PageNumbering: models.PageNumberingSource, MetadataSource: "zotero", HasDOI: true}
+	if got := read("doc-2"); got != want {
+		t.Errorf("Expected summary %+v, got %+v", want, got)
+	}
+	// A document stored without validated page numbers has no numbering
+	if got := read("doc-1"); got != (summary{}) {
+		t.Errorf("Expected an empty summary for doc-1, got %+v", got)
+	}
+}
+
+func TestReadResource_PageContext(t *testing.T) {
+	handler := newTestHandler(t)
+	ctx := context.Background()
+
+	item, err := handler.store.GetParsedItem(ctx, "doc-1")
+	if err != nil {
+		t.Fatalf("GetParsedItem failed: %v", err)
+	}
+	item.Footnotes = []models.Footnote{
+		{Marker: "1", Text: "On the preface", PageNumber: "iv"},
+		{Marker: "2", Text: "On the method", PageNumber: "123"},
+		{Marker: "3", Text: "On the results", PageNumber: "124"},
+	}
+	item.Quotations = []models.Quotation{
+		{QuotationText: "A claim about results", PageNumber: "124"},
+		{QuotationText: "A claim about method", PageNumber: "123"},
+	}
+	if err := handler.store.StoreParsedItem(ctx, "doc-1", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+	if _, err := handler.store.AddAnnotation(ctx, models.Annotation{DocumentID: "doc-1", PageNumber: "123", Text: "Sampling is unclear"}); err != nil {
+		t.Fatalf("AddAnnotation failed: %v", err)
+	}
+
+	type decodedContext struct {
+		SourcePageNumber string     `json:"source_page_number"`
+		Page             pageInfo   `json:"page"`
+		PreviousPages    []pageInfo `json:"previous_pages"`
+		NextPages        []pageInfo `json:"next_pages"`
+		Footnotes        []struct {
+			Index int    `json:"index"`
+			Text  string `json:"text"`
+		} `json:"footnotes"`
+		Quotations []struct {
+			Index         int    `json:"index"`
+			QuotationText string `json:"quotation_text"`
+		} `json:"quotations"`
+		Annotations []models.Annotation `json:"annotations"`
+	}
+	contents := func(pages []pageInfo) []string {
+		result := make([]string, len(pages))
+		for i, page := range pages {
+			result[i] = page.Content
+		}
+		return result
+	}
+
+	tests := []struct {
+		name             string
+		uri              string
+		expectedPage     string
+		expectedPrevious []string
+		expectedNext     []string
+		expectedNotes    int
+		expectedQuotes   int
+		expectedComments int
+	}{
+		{
+			name:             "middle page",
+			uri:              "pdf://doc-1/context/123",
+			expectedPage:     "Methods",
+			expectedPrevious: []string{"Introduction"},
+			expectedNext:     []string{"Results"},
+			expectedNotes:    1,
+			expectedQuotes:   1,
+			expectedComments: 1,
+		},
+		{
+			name:             "first page by roman numeral",
+			uri:              "pdf://doc-1/context/iv",
+			expectedPage:     "Preface",
+			expectedPrevious: []string{},
+			expectedNext:     []string{"Contents"},
+			expectedNotes:    1,
+		},
+		{
+			name:             "last page",
+			uri:              "pdf://doc-1/context/125",
+			expectedPage:     "Discussion",
+			expectedPrevious: []string{"Results"},
+			expectedNext:     []string{},
+		},
+		{
+			name:             "wider window clipped at the start",
+			uri:              "pdf://doc-1/context/v?window=3",
+			expectedPage:     "Contents",
+			expectedPrevious: []string{"Preface"},
+			expectedNext:     []string{"Introduction", "Methods", "Results"},
+		},
+		{
+			name:             "no neighbors",
+			uri:              "pdf://doc-1/context/124?window=0",
+			expectedPage:     "Results",
+			expectedPrevious: []string{},
+			expectedNext:     []string{},
+			expectedNotes:    1,
+			expectedQuotes:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handler.ReadResource(ctx, tt.uri)
+			if err != nil {
+				t.Fatalf("ReadResource failed: %v", err)
+			}
+			var decoded decodedContext
+			if err := json.Unmarshal([]byte(result.Contents[0].Text), &decoded); err != nil {
+				t.Fatalf("Failed to decode result: %v", err)
+			}
+			if decoded.Page.Content != tt.expectedPage {
+				t.Errorf("Expected page %q, got %q", tt.expectedPage, decoded.Page.Content)
+			}
+			if got := contents(decoded.PreviousPages); !reflect.DeepEqual(got, tt.expectedPrevious) {
+				t.Errorf("Expected previous pages %v, got %v", tt.expectedPrevious, got)
+			}
+			if got := contents(decoded.NextPages); !reflect.DeepEqual(got, tt.expectedNext) {
+				t.Errorf("Expected next pages %v, got %v", tt.expectedNext, got)
+			}
+			if len(decoded.Footnotes) != tt.expectedNotes || len(decoded.Quotations) != tt.expectedQuotes || len(decoded.Annotations) != tt.expectedComments {
+				t.Errorf("Expected %d footnotes, %d quotations, and %d annotations, got %+v", tt.expectedNotes, tt.expectedQuotes, tt.expectedComments, decoded)
+			}
+		})
+	}
+
+	// Indices point back into the footnotes and quotations resources
+	result, err := handler.ReadResource(ctx, "pdf://doc-1/context/123")
+	if err != nil {
+		t.Fatalf("ReadResource failed: %v", err)
+	}
+	var decoded decodedContext
+	if err := json.Unmarshal([]byte(result.Contents[0].Text), &decoded); err != nil {
+		t.Fatalf("Failed to decode result: %v", err)
+	}
+	if decoded.Footnotes[0].Index != 1 || decoded.Quotations[0].Index != 1 || decoded.SourcePageNumber != "123" {
+		t.Errorf("Unexpected indices: %+v", decoded)
+	}
+
+	for _, uri := range []string{"pdf://doc-1/context/999", "pdf://missing/context/1"} {
+		if _, err := handler.ReadResource(ctx, uri); !IsNotFound(err) {
+			t.Errorf("ReadResource(%s): expected a not-found error, got %v", uri, err)
+		}
+	}
+	for _, uri := range []string{"pdf://doc-1/context/123?window=-1", "pdf://doc-1/context/123?window=5"} {
+		if _, err := handler.ReadResource(ctx, uri); !errors.Is(err, ErrBadRequest) {
+			t.Errorf("ReadResource(%s): expected a bad request error, got %v", uri, err)
+		}
+	}
+}
+
+func TestReadResource_FullText(t *testing.T) {
+	handler := newTestHandler(t)
+	ctx := context.Background()
+
+	type decodedFullText struct {
+		PageCount int            `json:"page_count"`
+		Pages     []fullTextPage `json:"pages"`
+		Text      string         `json:"text"`
+	}
+	read := func(uri string) decodedFullText {
+		t.Helper()
+		result, err := handler.ReadResource(ctx, uri)
+		if err != nil {
+			t.Fatalf("ReadResource(%s) failed: %v", uri, err)
+		}
+		var decoded decodedFullText
+		if err := json.Unmarshal([]byte(result.Contents[0].Text), &decoded); err != nil {
+			t.Fatalf("Failed to decode result: %v", err)
+		}
+		return decoded
+	}
+
+	// The test document has no recorded full text, so it is built from the pages
+	built := read("pdf://doc-1/fulltext")
+	if built.PageCount != 6 || !strings.HasPrefix(built.Text, "Preface\n\nContents") {
+		t.Errorf("Unexpected full text: %+v", built)
+	}
+	if page := built.Pages[3]; page.SourcePageNumber != "123" || !strings.HasPrefix(built.Text[page.Offset:], "Methods") {
+		t.Errorf("Unexpected page 4 entry %+v", page)
+	}
+
+	item := &models.ParsedItem{
+		Pages:       []string{"The results were inter-", "preted with care."},
+		PageNumbers: []string{"7", "8"},
+	}
+	item.FullText, item.PageOffsets = documents.FullText(item.Pages, documents.HyphenationJoin)
+	if err := handler.store.StoreParsedItem(ctx, "doc-2", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+	stored := read("pdf://doc-2/fulltext")
+	if stored.Text != "The results were interpreted with care." {
+		t.Errorf("Unexpected full text %q", stored.Text)
+	}
+	if len(stored.Pages) != 2 || stored.Pages[1].SourcePageNumber != "8" || stored.Text[stored.Pages[1].Offset:] != "preted with care." {
+		t.Errorf("Unexpected pages %+v", stored.Pages)
+	}
+
+	if _, err := handler.ReadResource(ctx, "pdf://missing/fulltext"); !IsNotFound(err) {
+		t.Errorf("Expected a not-found error, got %v", err)
+	}
+}
+
+func TestReadResource_PageQuality(t *testing.T) {
+	handler := newTestHandler(t)
+	ctx := context.Background()
+
+	// The test document has no quality information, so pages have none
+	result, err := handler.ReadResource(ctx, "pdf://doc-1/pages/122")
+	if err != nil {
+		t.Fatalf("ReadResource failed: %v", err)
+	}
+	if strings.Contains(result.Contents[0].Text, "quality") {
+		t.Errorf("Expected no quality for an unassessed page, got %s", result.Contents[0].Text)
+	}
+
+	confidence := 0.2
+	item := &models.ParsedItem{
+		Pages:       []string{"Cover", "", "Body text"},
+		PageNumbers: []string{"i", "1", "2"},
+		PageQuality: []models.PageQuality{
+			{ContentConfidence: &confidence, IsCover: true},
+			{NearEmpty: true, ContentConfidence: &confidence, IsBlank: true},
+			{},
+		},
+	}
+	if err := handler.store.StoreParsedItem(ctx, "doc-2", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+
+	result, err = handler.ReadResource(ctx, "pdf://doc-2/pages/1")
+	if err != nil {
+		t.Fatalf("ReadResource failed: %v", err)
+	}
+	var page struct {
+		Quality *models.PageQuality `json:"quality"`
+	}
+	if err := json.Unmarshal([]byte(result.Contents[0].Text), &page); err != nil {
+		t.Fatalf("Failed to decode page: %v", err)
+	}
+	if page.Quality == nil || !page.Quality.IsBlank || !page.Quality.NearEmpty || page.Quality.ContentConfidence == nil || *page.Quality.ContentConfidence != 0.2 {
+		t.Errorf("Expected the blank page's quality, got %+v", page.Quality)
+	}
+
+	result, err = handler.ReadResource(ctx, "pdf://doc-2/pages")
+	if err != nil {
+		t.Fatalf("ReadResource failed: %v", err)
+	}
+	var pages struct {
+		Pages []pageInfo `json:"pages"`
+	}
+	if err := json.Unmarshal([]byte(result.Contents[0].Text), &pages); err != nil {
+		t.Fatalf("Failed to decode pages: %v", err)
+	}
+	if len(pages.Pages) != 3 || pages.Pages[0].Quality == nil || !pages.Pages[0].Quality.IsCover || pages.Pages[2].Quality == nil || *pages.Pages[2].Quality != (models.PageQuality{}) {
+		t.Errorf("Expected each page's quality in the page list, got %+v", pages.Pages)
+	}
+}
+
+func TestReadResource_BibTeX(t *testing.T) {
+	handler := newTestHandler(t)
+	ctx := context.Background()
+
+	metadata := models.ItemMetadata{
+		Title:           "Cited Work",
+		Authors:         []string{"Jane Smith"},
+		PublicationDate: "2020",
+		Publication:     "Journal of Examples",
+		ItemType:        "journalArticle",
+		Citekey:         "smith2020",
+	}
+	item := &models.ParsedItem{Metadata: metadata, Pages: []string{"Text"}}
+	if err := handler.store.StoreParsedItem(ctx, "doc-2", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+
+	result, err := handler.ReadResource(ctx, "pdf://doc-2/bibtex")
+	if err != nil {
+		t.Fatalf("ReadResource failed: %v", err)
+	}
+	stored, err := handler.store.GetMetadata(ctx, "doc-2")
+	if err != nil {
+		t.Fatalf("GetMetadata failed: %v", err)
+	}
+	if want := citations.GenerateBibTeXEntry("doc-2", stored, "smith2020"); result.Contents[0].Text != want {
+		t.Errorf("Expected the generated entry:\n%s\ngot:\n%s", want, result.Contents[0].Text)
+	}
+	if result.Contents[0].MIMEType != "application/x-bibtex" {
+		t.Errorf("Expected the BibTeX MIME type, got %s", result.Contents[0].MIMEType)
+	}
+
+	// The test document has no citekey
+	if _, err := handler.ReadResource(ctx, "pdf://doc-1/bibtex"); err == nil || !strings.Contains(err.Error(), "no citekey") {
+		t.Errorf("Expected an error naming the missing citekey, got %v", err)
+	}
+	if _, err := handler.ReadResource(ctx, "pdf://missing/bibtex"); !IsNotFound(err) {
+		t.Errorf("Expected a not-found error, got %v", err)
+	}
+}
+
+func TestReadResource_LibraryBibliography(t *testing.T) {
+	handler := newTestHandler(t)
+	ctx := context.Background()
+
+	entries := make(map[string]string)
+	for _, citekey := range []string{"doe2019", "Roe2021", "smith2020"} {
+		item := &models.ParsedItem{Metadata: models.ItemMetadata{Title: "Work by " + citekey, Citekey: citekey}, Pages: []string{"Text"}}
+		if err := handler.store.StoreParsedItem(ctx, "doc-"+citekey, item, &models.SourceInfo{}); err != nil {
+			t.Fatalf("Failed to store document: %v", err)
+		}
+		metadata, err := handler.store.GetMetadata(ctx, "doc-"+citekey)
+		if err != nil {
+			t.Fatalf("GetMetadata failed: %v", err)
+		}
+		entries[citekey] = citations.GenerateBibTeXEntry("doc-"+citekey, metadata, citekey)
+	}
+	read := func(uri string) string {
+		t.Helper()
+		result, err := handler.ReadResource(ctx, uri)
+		if err != nil {
+			t.Fatalf("ReadResource(%s) failed: %v", uri, err)
+		}
+		if result.Contents[0].MIMEType != "application/x-bibtex" {
+			t.Errorf("Expected the BibTeX MIME type, got %s", result.Contents[0].MIMEType)
+		}
+		return result.Contents[0].Text
+	}
+
+	// The whole library fits, ordered by citekey, without the document that has none
+	all := read("pdf://library/bibliography")
+	want := "% 1 documents without citekeys are not included\n\n" +
+		citations.GenerateBibTeXFile([]string{entries["doe2019"], entries["Roe2021"], entries["smith2020"]})
+	if all != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, all)
+	}
+	if keys := citations.ScanBibTeXKeys(all); !reflect.DeepEqual(keys, []string{"doe2019", "Roe2021", "smith2020"}) {
+		t.Errorf("Unexpected entries %v", keys)
+	}
+
+	// Past the size cap, whole entries are returned with the next window's URI
+	handler.maxBibliographyBytes = len(entries["doe2019"]) + 1
+	first := read("pdf://library/bibliography")
+	if keys := citations.ScanBibTeXKeys(first); !reflect.DeepEqual(keys, []string{"doe2019"}) {
+		t.Errorf("Expected the first entry, got %v", keys)
+	}
+	if !strings.Contains(first, "% Entries 1-1 of 3\n% Next: pdf://library/bibliography?offset=1\n") {
+		t.Errorf("Expected the next window's URI, got:\n%s", first)
+	}
+	handler.maxBibliographyBytes = defaultMaxBibliographyBytes
+	last := read("pdf://library/bibliography?offset=1&limit=1")
+	if keys := citations.ScanBibTeXKeys(last); !reflect.DeepEqual(keys, []string{"Roe2021"}) {
+		t.Errorf("Expected the second entry, got %v", keys)
+	}
+	if !strings.Contains(last, "% Next: pdf://library/bibliography?offset=2&limit=1\n") {
+		t.Errorf("Expected the next window to keep the limit, got:\n%s", last)
+	}
+
+	for _, uri := range []string{"pdf://library/bibliography?offset=3", "pdf://library/bibliography?limit=0"} {
+		if _, err := handler.ReadResource(ctx, uri); err == nil {
+			t.Errorf("Expected an error for %s", uri)
+		}
+	}
+}
+
+func TestReadResource_Libraries(t *testing.T) {
+	handler := newTestHandler(t)
+	ctx := context.Background()
+
+	for _, docID := range []string{"doc-a", "thesis:doc-a", "thesis:doc-b"} {
+		citekey := "smith2020"
+		if docID == "thesis:doc-b" {
+			citekey = "jones2021"
+		}
+		item := &models.ParsedItem{Metadata: models.ItemMetadata{Title: "Work in " + docID, Citekey: citekey}, Pages: []string{"Text"}}
+		if err := handler.store.StoreParsedItem(ctx, docID, item, &models.SourceInfo{}); err != nil {
+			t.Fatalf("Failed to store document: %v", err)
+		}
+	}
+	read := func(uri string) string {
+		t.Helper()
+		result, err := handler.ReadResource(ctx, uri)
+		if err != nil {
+			t.Fatalf("ReadResource(%s) failed: %v", uri, err)
+		}
+		return result.Contents[0].Text
+	}
+
+	// Documents outside the default library are addressed by prefixed IDs, and
+	// the default library's prefix is optional
+	if metadata := read("pdf://thesis:doc-a/metadata"); !strings.Contains(metadata, "Work in thesis:doc-a") {
+		t.Errorf("Expected the thesis document's metadata, got %s", metadata)
+	}
+	if metadata := read("pdf://default:doc-a/metadata"); !strings.Contains(metadata, `"Work in doc-a"`) {
+		t.Errorf("Expected the default document's metadata, got %s", metadata)
+	}
+
+	// Each library's bibliography has its own smith2020
+	if keys := citations.ScanBibTeXKeys(read("pdf://library/bibliography?library=thesis")); !reflect.DeepEqual(keys, []string{"jones2021", "smith2020"}) {
+		t.Errorf("Expected the thesis library's entries, got %v", keys)
+	}
+	if keys := citations.ScanBibTeXKeys(read("pdf://library/bibliography?library=default")); !reflect.DeepEqual(keys, []string{"smith2020"}) {
+		t.Errorf("Expected the default library's entry, got %v", keys)
+	}
+
+	var stats models.LibraryStats
+	if err := json.Unmarshal([]byte(read("pdf://library/stats?library=thesis")), &stats); err != nil {
+		t.Fatalf("Failed to decode stats: %v", err)
+	}
+	if stats.DocumentCount != 2 {
+		t.Errorf("Expected the thesis library's 2 documents, got %d", stats.DocumentCount)
+	}
+
+	if _, err := handler.ReadResource(ctx, "pdf://library/stats?library=Not%20A%20Library"); !errors.Is(err, ErrBadRequest) {
+		t.Errorf("Expected ErrBadRequest for an invalid library, got %v", err)
+	}
+}
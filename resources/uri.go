@@ -0,0 +1,205 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ErrBadRequest is wrapped by the errors returned for malformed resource URIs:
+// a missing document ID, bad percent-encoding, or an index that is not a
+// non-negative integer
+var ErrBadRequest = errors.New("invalid resource URI")
+
+// ErrResourceNotFound is wrapped by the errors returned for well-formed URIs
+// that name no resource, such as an unknown resource type. Missing documents and
+// out-of-range indices wrap storage.ErrNotFound instead; IsNotFound matches both.
+var ErrResourceNotFound = errors.New("resource not found")
+
+// IsNotFound reports whether err means the requested resource does not exist,
+// as opposed to the request being malformed or failing
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrResourceNotFound) || errors.Is(err, storage.ErrNotFound)
+}
+
+// indexedResourceTypes are the resource types whose items are addressed by a
+// 0-indexed position, e.g. pdf://{docID}/references/3. Images, tables, and
+// footnotes can also be addressed by their stable IDs (see
+// storage.AssignStableIDs), e.g. pdf://{docID}/images/img-p12-1-3fa9c1.
+var indexedResourceTypes = map[string]bool{
+	"sections":   true,
+	"references": true,
+	"images":     true,
+	"tables":     true,
+	"footnotes":  true,
+	"endnotes":   true,
+	"quotations": true,
+}
+
+// documentResourceTypes are the resource types of a document ("" is the
+// document summary at pdf://{docID})
+var documentResourceTypes = map[string]bool{
+	"": true, "metadata": true, "pages": true, "notes": true, "annotations": true, "context": true,
+	"fulltext": true, "bibtex": true,
+}
+
+// pageResourceTypes are the resource types whose item is a page identifier,
+// which may itself contain slashes
+var pageResourceTypes = map[string]bool{
+	"pages":   true,
+	"context": true,
+}
+
+// resourceURI is a parsed pdf:// resource URI
+type resourceURI struct {
+	DocumentID string     // Percent-decoded; libraryResourceID for library-wide resources
+	Type       string     // Resource type, e.g. "pages"; empty for the document summary
+	Item       string     // Percent-decoded item segment, e.g. a source page number; empty if absent
+	Index      int        // Item as a 0-indexed position for indexed types, or -1 if absent
+	Data       bool       // The item's raw data (pdf://{docID}/images/{index}/data) rather than its JSON
+	PageImage  bool       // The rendered image of a page (pdf://{docID}/pages/{sourcePage}/image) rather than its text
+	PageSpan   bool       // A range of a page's text (pdf://{docID}/pages/{sourcePage}/span) rather than the whole page
+	ByPage     bool       // The item is a source page (pdf://{docID}/references/pages/{sourcePage}) rather than an index
+	ByStableID bool       // The item is a stable ID (pdf://{docID}/images/img-p12-1-3fa9c1) rather than an index
+	Query      url.Values // Query parameters
+}
+
+// parseResourceURI parses pdf://{docID}[/{type}[/{item}]][?query],
+// pdf://{docID}/images/{index}/data for an image's bytes,
+// pdf://{docID}/pages/{sourcePage}/image for a page's rendered image,
+// pdf://{docID}/pages/{sourcePage}/span?start=..&end=.. for a range of its text, or
+// pdf://{docID}/references/pages/{sourcePage} for a page's references. Each path
+// segment is percent-decoded, so a document ID or page number containing a slash
+// can be given as %2F; for pages and context, the rest of the path is also taken
+// as the page identifier. A document outside the default library is addressed
+// by its library-prefixed ID (pdf://{library}:{docID}); the default library's
+// "default:" prefix may be given or left out. A single trailing slash is
+// ignored. Errors wrap
+// ErrBadRequest for malformed URIs and ErrResourceNotFound for paths that name
+// no resource.
+func parseResourceURI(uri string) (*resourceURI, error) {
+	rest, ok := strings.CutPrefix(uri, "pdf://")
+	if !ok {
+		return nil, fmt.Errorf("%w: expected the pdf:// scheme: %s", ErrBadRequest, uri)
+	}
+	rest, _, _ = strings.Cut(rest, "#")
+	path, rawQuery, _ := strings.Cut(rest, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid query: %v", ErrBadRequest, err)
+	}
+
+	path = strings.TrimSuffix(path, "/")
+	rawSegments := strings.Split(path, "/")
+	data := len(rawSegments) == 4 && rawSegments[1] == "images" && rawSegments[3] == "data"
+	if data {
+		rawSegments = rawSegments[:3]
+	}
+	pageImage := len(rawSegments) > 3 && rawSegments[1] == "pages" && rawSegments[len(rawSegments)-1] == "image"
+	if pageImage {
+		rawSegments = rawSegments[:len(rawSegments)-1]
+	}
+	pageSpan := len(rawSegments) > 3 && rawSegments[1] == "pages" && rawSegments[len(rawSegments)-1] == "span"
+	if pageSpan {
+		rawSegments = rawSegments[:len(rawSegments)-1]
+	}
+	byPage := len(rawSegments) > 2 && rawSegments[1] == "references" && rawSegments[2] == "pages"
+	if byPage {
+		if len(rawSegments) == 3 {
+			return nil, fmt.Errorf("%w: missing page for the references resource: %s", ErrResourceNotFound, uri)
+		}
+		rawSegments = append(rawSegments[:2], rawSegments[3:]...)
+	}
+	// Page identifiers may themselves contain slashes
+	if len(rawSegments) > 3 && (pageResourceTypes[rawSegments[1]] || byPage) {
+		rawSegments = append(rawSegments[:2], strings.Join(rawSegments[2:], "/"))
+	}
+	if len(rawSegments) > 3 {
+		return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, uri)
+	}
+
+	segments := make([]string, len(rawSegments))
+	for i, raw := range rawSegments {
+		segment, err := url.PathUnescape(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: bad percent-encoding in %q", ErrBadRequest, raw)
+		}
+		if strings.TrimSpace(segment) == "" {
+			if i == 0 {
+				return nil, fmt.Errorf("%w: missing document ID: %s", ErrBadRequest, uri)
+			}
+			return nil, fmt.Errorf("%w: empty path segment: %s", ErrBadRequest, uri)
+		}
+		segments[i] = segment
+	}
+
+	parsed := &resourceURI{DocumentID: storage.NormalizeDocumentID(segments[0]), Index: -1, Data: data, PageImage: pageImage, PageSpan: pageSpan, ByPage: byPage, Query: query}
+	if len(segments) > 1 {
+		parsed.Type = segments[1]
+	}
+	if len(segments) > 2 {
+		parsed.Item = segments[2]
+	}
+
+	if parsed.DocumentID == libraryResourceID {
+		if parsed.Type == "" || parsed.Item != "" {
+			return nil, fmt.Errorf("%w: unknown library resource: %s", ErrResourceNotFound, uri)
+		}
+		return parsed, nil
+	}
+
+	switch {
+	case indexedResourceTypes[parsed.Type]:
+		if parsed.Item != "" && !parsed.ByPage {
+			if storage.IsStableID(parsed.Type, parsed.Item) {
+				parsed.ByStableID = true
+				break
+			}
+			parsed.Index, err = parseIndex(parsed.Item)
+			if err != nil {
+				return nil, err
+			}
+		}
+	case documentResourceTypes[parsed.Type]:
+		if parsed.Item != "" && !pageResourceTypes[parsed.Type] {
+			return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, uri)
+		}
+		if parsed.Item == "" && parsed.Type == "context" {
+			return nil, fmt.Errorf("%w: missing page for the context resource: %s", ErrResourceNotFound, uri)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown resource type: %s", ErrResourceNotFound, parsed.Type)
+	}
+	return parsed, nil
+}
+
+// parseIndex parses a 0-indexed position, which must be written as plain
+// decimal digits; signs, spaces, and values too large for an int are rejected
+func parseIndex(value string) (int, error) {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return 0, fmt.Errorf("%w: index must be a non-negative integer: %s", ErrBadRequest, value)
+		}
+	}
+	index, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: index out of range: %s", ErrBadRequest, value)
+	}
+	return index, nil
+}
+
+// HandleReadResource reads a resource for the MCP server, reporting resources
+// that do not exist with the protocol's resource-not-found error
+func (h *PDFResourceHandler) HandleReadResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	result, err := h.ReadResource(ctx, req.Params.URI)
+	if IsNotFound(err) {
+		return nil, mcp.ResourceNotFoundError(req.Params.URI)
+	}
+	return result, err
+}
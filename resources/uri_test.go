@@ -0,0 +1,183 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestParseResourceURI(t *testing.T) {
+	tests := []struct {
+		uri           string
+		expectedDocID string
+		expectedType  string
+		expectedItem  string
+		expectedIndex int
+		expectedData  bool
+		expectedPage  bool
+		expectedImage bool
+		expectedSpan  bool
+		expectedID    bool
+		expectedError error
+	}{
+		// Valid URIs
+		{uri: "pdf://doc-1", expectedDocID: "doc-1", expectedIndex: -1},
+		{uri: "pdf://doc-1/", expectedDocID: "doc-1", expectedIndex: -1},
+		{uri: "pdf://doc-1/metadata", expectedDocID: "doc-1", expectedType: "metadata", expectedIndex: -1},
+		{uri: "pdf://doc-1/metadata/", expectedDocID: "doc-1", expectedType: "metadata", expectedIndex: -1},
+		{uri: "pdf://doc-1/pages", expectedDocID: "doc-1", expectedType: "pages", expectedIndex: -1},
+		{uri: "pdf://doc-1/pages?offset=5&limit=2", expectedDocID: "doc-1", expectedType: "pages", expectedIndex: -1},
+		{uri: "pdf://doc-1/pages/125", expectedDocID: "doc-1", expectedType: "pages", expectedItem: "125", expectedIndex: -1},
+		{uri: "pdf://doc-1/pages/iv/", expectedDocID: "doc-1", expectedType: "pages", expectedItem: "iv", expectedIndex: -1},
+		{uri: "pdf://doc-1/pages/122-124", expectedDocID: "doc-1", expectedType: "pages", expectedItem: "122-124", expectedIndex: -1},
+		{uri: "pdf://doc-1/pages/12%2F13", expectedDocID: "doc-1", expectedType: "pages", expectedItem: "12/13", expectedIndex: -1},
+		{uri: "pdf://doc-1/pages/12/13", expectedDocID: "doc-1", expectedType: "pages", expectedItem: "12/13", expectedIndex: -1},
+		{uri: "pdf://doc-1/pages/A%201", expectedDocID: "doc-1", expectedType: "pages", expectedItem: "A 1", expectedIndex: -1},
+		{uri: "pdf://doc-1/context/iv?window=2", expectedDocID: "doc-1", expectedType: "context", expectedItem: "iv", expectedIndex: -1},
+		{uri: "pdf://doc-1/context/12/13", expectedDocID: "doc-1", expectedType: "context", expectedItem: "12/13", expectedIndex: -1},
+		{uri: "pdf://doc%2F1/metadata", expectedDocID: "doc/1", expectedType: "metadata", expectedIndex: -1},
+		{uri: "pdf://zotero_group_222_ABC/references", expectedDocID: "zotero_group_222_ABC", expectedType: "references", expectedIndex: -1},
+		{uri: "pdf://thesis:zotero_ABC/pages/3", expectedDocID: "thesis:zotero_ABC", expectedType: "pages", expectedItem: "3", expectedIndex: -1},
+		{uri: "pdf://default:doc-1/metadata", expectedDocID: "doc-1", expectedType: "metadata", expectedIndex: -1},
+		{uri: "pdf://doc-1/references/0", expectedDocID: "doc-1", expectedType: "references", expectedItem: "0", expectedIndex: 0},
+		{uri: "pdf://doc-1/references/12/", expectedDocID: "doc-1", expectedType: "references", expectedItem: "12", expectedIndex: 12},
+		{uri: "pdf://doc-1/references/pages/125", expectedDocID: "doc-1", expectedType: "references", expectedItem: "125", expectedIndex: -1, expectedPage: true},
+		{uri: "pdf://doc-1/references/pages/12/13/", expectedDocID: "doc-1", expectedType: "references", expectedItem: "12/13", expectedIndex: -1, expectedPage: true},
+		{uri: "pdf://doc-1/references/pages/A%201", expectedDocID: "doc-1", expectedType: "references", expectedItem: "A 1", expectedIndex: -1, expectedPage: true},
+		{uri: "pdf://doc-1/images/2/data", expectedDocID: "doc-1", expectedType: "images", expectedItem: "2", expectedIndex: 2, expectedData: true},
+		{uri: "pdf://doc-1/images/2/data/", expectedDocID: "doc-1", expectedType: "images", expectedItem: "2", expectedIndex: 2, expectedData: true},
+		{uri: "pdf://doc-1/pages/125/image", expectedDocID: "doc-1", expectedType: "pages", expectedItem: "125", expectedIndex: -1, expectedImage: true},
+		{uri: "pdf://doc-1/pages/12/13/image/", expectedDocID: "doc-1", expectedType: "pages", expectedItem: "12/13", expectedIndex: -1, expectedImage: true},
+		{uri: "pdf://doc-1/pages/12/span?start=1&end=4", expectedDocID: "doc-1", expectedType: "pages", expectedItem: "12", expectedIndex: -1, expectedSpan: true},
+		{uri: "pdf://doc-1/pages/12/13/span/", expectedDocID: "doc-1", expectedType: "pages", expectedItem: "12/13", expectedIndex: -1, expectedSpan: true},
+		{uri: "pdf://doc-1/pages/image", expectedDocID: "doc-1", expectedType: "pages", expectedItem: "image", expectedIndex: -1},
+		{uri: "pdf://doc-1/sections/3", expectedDocID: "doc-1", expectedType: "sections", expectedItem: "3", expectedIndex: 3},
+		{uri: "pdf://doc-1/images/1", expectedDocID: "doc-1", expectedType: "images", expectedItem: "1", expectedIndex: 1},
+		{uri: "pdf://doc-1/tables/2?format=csv", expectedDocID: "doc-1", expectedType: "tables", expectedItem: "2", expectedIndex: 2},
+		{uri: "pdf://doc-1/footnotes/4", expectedDocID: "doc-1", expectedType: "footnotes", expectedItem: "4", expectedIndex: 4},
+		{uri: "pdf://doc-1/endnotes/5", expectedDocID: "doc-1", expectedType: "endnotes", expectedItem: "5", expectedIndex: 5},
+		{uri: "pdf://doc-1/quotations/6", expectedDocID: "doc-1", expectedType: "quotations", expectedItem: "6", expectedIndex: 6},
+		{uri: "pdf://doc-1/images/img-p12-1-3fa9c1", expectedDocID: "doc-1", expectedType: "images", expectedItem: "img-p12-1-3fa9c1", expectedIndex: -1, expectedID: true},
+		{uri: "pdf://doc-1/images/img-p12-1-3fa9c1/data", expectedDocID: "doc-1", expectedType: "images", expectedItem: "img-p12-1-3fa9c1", expectedIndex: -1, expectedData: true, expectedID: true},
+		{uri: "pdf://doc-1/tables/tbl-2-0b77de?format=csv", expectedDocID: "doc-1", expectedType: "tables", expectedItem: "tbl-2-0b77de", expectedIndex: -1, expectedID: true},
+		{uri: "pdf://doc-1/footnotes/fn-piv-1-9c0e12/", expectedDocID: "doc-1", expectedType: "footnotes", expectedItem: "fn-piv-1-9c0e12", expectedIndex: -1, expectedID: true},
+		{uri: "pdf://doc-1/notes", expectedDocID: "doc-1", expectedType: "notes", expectedIndex: -1},
+		{uri: "pdf://doc-1/annotations#top", expectedDocID: "doc-1", expectedType: "annotations", expectedIndex: -1},
+		{uri: "pdf://library/stats", expectedDocID: "library", expectedType: "stats", expectedIndex: -1},
+		{uri: "pdf://library/authors/", expectedDocID: "library", expectedType: "authors", expectedIndex: -1},
+		{uri: "pdf://library/bibliography?offset=10", expectedDocID: "library", expectedType: "bibliography", expectedIndex: -1},
+		{uri: "pdf://doc-1/bibtex", expectedDocID: "doc-1", expectedType: "bibtex", expectedIndex: -1},
+
+		// Malformed URIs
+		{uri: "http://doc-1/metadata", expectedError: ErrBadRequest},
+		{uri: "pdf://", expectedError: ErrBadRequest},
+		{uri: "pdf:///metadata", expectedError: ErrBadRequest},
+		{uri: "pdf://%20/metadata", expectedError: ErrBadRequest},
+		{uri: "pdf://doc-1//metadata", expectedError: ErrBadRequest},
+		{uri: "pdf://doc-1/pages//", expectedError: ErrBadRequest},
+		{uri: "pdf://doc%ZZ/metadata", expectedError: ErrBadRequest},
+		{uri: "pdf://doc-1/pages/%G1", expectedError: ErrBadRequest},
+		{uri: "pdf://doc-1/references/-1", expectedError: ErrBadRequest},
+		{uri: "pdf://doc-1/references/+1", expectedError: ErrBadRequest},
+		{uri: "pdf://doc-1/references/1.5", expectedError: ErrBadRequest},
+		{uri: "pdf://doc-1/references/abc", expectedError: ErrBadRequest},
+		{uri: "pdf://doc-1/references/%201", expectedError: ErrBadRequest},
+		{uri: "pdf://doc-1/references/99999999999999999999", expectedError: ErrBadRequest},
+		{uri: "pdf://doc-1/pages?offset=%ZZ", expectedError: ErrBadRequest},
+		{uri: "pdf://doc-1/images/x/data", expectedError: ErrBadRequest},
+		{uri: "pdf://doc-1/references/img-p12-1-3fa9c1", expectedError: ErrBadRequest},
+		{uri: "pdf://doc-1/tables/img-p12-1-3fa9c1", expectedError: ErrBadRequest},
+
+		// Paths that name no resource
+		{uri: "pdf://doc-1/unknown", expectedError: ErrResourceNotFound},
+		{uri: "pdf://doc-1/metadata/1", expectedError: ErrResourceNotFound},
+		{uri: "pdf://doc-1/references/1/2", expectedError: ErrResourceNotFound},
+		{uri: "pdf://doc-1/references/1/data", expectedError: ErrResourceNotFound},
+		{uri: "pdf://doc-1/images/1/raw", expectedError: ErrResourceNotFound},
+		{uri: "pdf://doc-1/context", expectedError: ErrResourceNotFound},
+		{uri: "pdf://doc-1/references/pages", expectedError: ErrResourceNotFound},
+		{uri: "pdf://doc-1/images/pages/1", expectedError: ErrResourceNotFound},
+		{uri: "pdf://doc-1/sections/1/image", expectedError: ErrResourceNotFound},
+		{uri: "pdf://library", expectedError: ErrResourceNotFound},
+		{uri: "pdf://library/stats/1", expectedError: ErrResourceNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			parsed, err := parseResourceURI(tt.uri)
+			if tt.expectedError != nil {
+				if !errors.Is(err, tt.expectedError) {
+					t.Fatalf("Expected error wrapping %v, got %v (%+v)", tt.expectedError, err, parsed)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseResourceURI failed: %v", err)
+			}
+			got := []any{parsed.DocumentID, parsed.Type, parsed.Item, parsed.Index, parsed.Data, parsed.ByPage, parsed.PageImage, parsed.PageSpan, parsed.ByStableID}
+			want := []any{tt.expectedDocID, tt.expectedType, tt.expectedItem, tt.expectedIndex, tt.expectedData, tt.expectedPage, tt.expectedImage, tt.expectedSpan, tt.expectedID}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Expected %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestReadResource_ErrorKinds(t *testing.T) {
+	handler := newTestHandler(t)
+
+	tests := []struct {
+		uri      string
+		notFound bool
+	}{
+		{"pdf:///metadata", false},
+		{"pdf://doc-1/references/-1", false},
+		{"pdf://doc-1/metadata?format=csv", false},
+		{"pdf://missing/metadata", true},
+		{"pdf://doc-1/references/9", true},
+		{"pdf://doc-1/pages/999", true},
+		{"pdf://doc-1/pages/999/image", true},
+		{"pdf://doc-1/unknown", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			_, err := handler.ReadResource(context.Background(), tt.uri)
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if IsNotFound(err) != tt.notFound || errors.Is(err, ErrBadRequest) == tt.notFound {
+				t.Errorf("Expected not found %v, got %v", tt.notFound, err)
+			}
+
+			_, err = handler.HandleReadResource(context.Background(), &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: tt.uri}})
+			isProtocolNotFound := err != nil && err.Error() == mcp.ResourceNotFoundError(tt.uri).Error()
+			if isProtocolNotFound != tt.notFound {
+				t.Errorf("Expected protocol not-found error %v, got %v", tt.notFound, err)
+			}
+		})
+	}
+
+	if !IsNotFound(storage.ErrNotFound) {
+		t.Error("Expected storage.ErrNotFound to be a not-found error")
+	}
+}
+
+func TestReadResource_TrailingSlashAndEscaping(t *testing.T) {
+	handler := newTestHandler(t)
+
+	for _, uri := range []string{"pdf://doc-1/pages/123/", "pdf://doc%2D1/pages/123", "pdf://doc-1/pages/%31%32%33"} {
+		result, err := handler.ReadResource(context.Background(), uri)
+		if err != nil {
+			t.Fatalf("ReadResource(%s) failed: %v", uri, err)
+		}
+		if got := result.Contents[0].Text; !strings.Contains(got, "Methods") {
+			t.Errorf("ReadResource(%s): expected page 123, got %s", uri, got)
+		}
+	}
+}
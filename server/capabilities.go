@@ -0,0 +1,142 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/config"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/tools"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// disabledToolsEnv names the environment variable listing tools to leave out
+	disabledToolsEnv = "ACADEMIC_MCP_DISABLED_TOOLS"
+	// readOnlyEnv names the environment variable that disables every ReadOnlyDisabledTools tool
+	readOnlyEnv = "ACADEMIC_MCP_READ_ONLY"
+)
+
+// ReadOnlyDisabledTools are the tools a read-only server leaves out: those that
+// call OpenAI, change stored documents or the Zotero library, or cancel parsing
+// jobs. Reading stored documents, searching Zotero, exporting, job status, and
+// library statistics remain.
+var ReadOnlyDisabledTools = []string{
+	"document-parse",
+	"document-summarize",
+	"document-quotations",
+	"document-reparse-pages",
+	"document-annotate",
+	"document-metadata-set",
+	"document-refresh-metadata",
+	"citekeys-regenerate",
+	"library-verify",
+	"document-import",
+	"library-import",
+	"zotero-import",
+	"zotero-writeback",
+	"zotero-tag",
+	"job-cancel",
+}
+
+// Capabilities selects the tools a server registers. Resources and prompts are
+// always available.
+type Capabilities struct {
+	DisabledTools []string // Names of tools not to register
+}
+
+// ReadOnlyCapabilities disables the tools in ReadOnlyDisabledTools
+func ReadOnlyCapabilities() Capabilities {
+	return Capabilities{DisabledTools: slices.Clone(ReadOnlyDisabledTools)}
+}
+
+// ToolEnabled reports whether the named tool is registered
+func (c Capabilities) ToolEnabled(name string) bool {
+	return !slices.Contains(c.DisabledTools, name)
+}
+
+// ConfiguredCapabilities returns the capabilities set by the environment:
+// ACADEMIC_MCP_READ_ONLY=true disables ReadOnlyDisabledTools, and
+// ACADEMIC_MCP_DISABLED_TOOLS disables a comma-separated list of tools, in
+// addition. An invalid ACADEMIC_MCP_READ_ONLY is ignored and returns an error.
+func ConfiguredCapabilities() (Capabilities, error) {
+	var capabilities Capabilities
+	var err error
+	if value := strings.TrimSpace(os.Getenv(readOnlyEnv)); value != "" {
+		readOnly, parseErr := strconv.ParseBool(value)
+		if parseErr != nil {
+			err = fmt.Errorf("invalid %s %q (expected true or false)", readOnlyEnv, value)
+		} else if readOnly {
+			capabilities = ReadOnlyCapabilities()
+		}
+	}
+	for _, name := range strings.Split(os.Getenv(disabledToolsEnv), ",") {
+		name = strings.TrimSpace(name)
+		if name != "" && capabilities.ToolEnabled(name) {
+			capabilities.DisabledTools = append(capabilities.DisabledTools, name)
+		}
+	}
+	return capabilities, err
+}
+
+// toolRegistry registers the tools a server's capabilities enable, remembering
+// every tool offered so that disabled ones can be recognized
+type toolRegistry struct {
+	server       *mcp.Server
+	capabilities Capabilities
+	credentials  *config.Provider
+	log          logger.Logger
+	known        map[string]bool
+}
+
+func newToolRegistry(server *mcp.Server, capabilities Capabilities, credentials *config.Provider, log logger.Logger) *toolRegistry {
+	return &toolRegistry{server: server, capabilities: capabilities, credentials: credentials, log: log, known: make(map[string]bool)}
+}
+
+// addTool registers tool with the server unless its capabilities disable it.
+// Each call gets a correlation ID: the handler's context carries a logger that
+// adds it and the tool name to every message (see logger.FromContext). The
+// context also carries the server's credentials (see config.FromContext).
+func addTool[In, Out any](r *toolRegistry, tool *mcp.Tool, handler mcp.ToolHandlerFor[In, Out]) {
+	r.known[tool.Name] = true
+	if !r.capabilities.ToolEnabled(tool.Name) {
+		return
+	}
+	mcp.AddTool(r.server, tool, func(ctx context.Context, req *mcp.CallToolRequest, input In) (*mcp.CallToolResult, Out, error) {
+		callLog := r.log.With("request_id", logger.NewCorrelationID(), "tool", tool.Name)
+		ctx = config.NewContext(logger.NewContext(ctx, callLog), r.credentials)
+		return handler(ctx, req, input)
+	})
+}
+
+// gate logs the disabled tools, warns about names that match no tool, and makes
+// calls to disabled tools return tools.DisabledToolResult instead of the
+// protocol's unknown-tool error. Call it after every tool has been added.
+func (r *toolRegistry) gate(log logger.Logger) {
+	disabled := make(map[string]bool)
+	for _, name := range r.capabilities.DisabledTools {
+		if !r.known[name] {
+			log.Warn("Cannot disable unknown tool %q", name)
+			continue
+		}
+		disabled[name] = true
+	}
+	if len(disabled) == 0 {
+		return
+	}
+	log.Info("Tools disabled by configuration: %s", strings.Join(slices.Sorted(maps.Keys(disabled)), ", "))
+
+	r.server.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if call, ok := req.(*mcp.CallToolRequest); ok && call.Params != nil && disabled[call.Params.Name] {
+				return tools.DisabledToolResult(call.Params.Name), nil
+			}
+			return next(ctx, method, req)
+		}
+	})
+}
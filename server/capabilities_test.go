@@ -0,0 +1,199 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/config"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// environmentCredentials returns a provider of the credentials set in the
+// environment
+func environmentCredentials(t *testing.T) *config.Provider {
+	t.Helper()
+	credentials, err := config.NewProvider("")
+	if err != nil {
+		t.Fatalf("Failed to read credentials: %v", err)
+	}
+	return credentials
+}
+
+// connectInMemory connects a client to a server built with capabilities
+func connectInMemory(t *testing.T, capabilities Capabilities) *mcp.ClientSession {
+	t.Helper()
+	return connectInMemoryWithLogger(t, capabilities, logger.NewNoOpLogger())
+}
+
+// connectInMemoryWithLogger connects a client to a server built with
+// capabilities that logs to log
+func connectInMemoryWithLogger(t *testing.T, capabilities Capabilities, log logger.Logger) *mcp.ClientSession {
+	t.Helper()
+	store, err := storage.NewSQLiteStore(":memory:", logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	srv := NewServerWithCapabilities(store, environmentCredentials(t), log, capabilities)
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := srv.Connect(context.Background(), serverTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	t.Cleanup(func() { serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
+	session, err := client.Connect(context.Background(), clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { session.Close() })
+	return session
+}
+
+func listToolNames(t *testing.T, session *mcp.ClientSession) []string {
+	t.Helper()
+	result, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	var names []string
+	for _, tool := range result.Tools {
+		names = append(names, tool.Name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestCapabilities_ToolList(t *testing.T) {
+	all := listToolNames(t, connectInMemory(t, Capabilities{}))
+	for _, name := range ReadOnlyDisabledTools {
+		if !slices.Contains(all, name) {
+			t.Errorf("Expected %s among the tools, got %v", name, all)
+		}
+	}
+
+	tests := []struct {
+		name         string
+		capabilities Capabilities
+		removed      []string
+	}{
+		{"read only", ReadOnlyCapabilities(), ReadOnlyDisabledTools},
+		{"selected tools", Capabilities{DisabledTools: []string{"document-parse", "document-delete"}}, []string{"document-parse"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var expected []string
+			for _, name := range all {
+				if !slices.Contains(tt.removed, name) {
+					expected = append(expected, name)
+				}
+			}
+			if got := listToolNames(t, connectInMemory(t, tt.capabilities)); !reflect.DeepEqual(got, expected) {
+				t.Errorf("Expected tools %v, got %v", expected, got)
+			}
+		})
+	}
+}
+
+func TestCapabilities_DisabledToolCall(t *testing.T) {
+	session := connectInMemory(t, ReadOnlyCapabilities())
+
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "document-parse", Arguments: map[string]any{"url": "https://example.com/paper.pdf"}})
+	if err != nil {
+		t.Fatalf("Expected a tool error result, got protocol error: %v", err)
+	}
+	var content struct {
+		Error models.ToolError `json:"error"`
+	}
+	if !result.IsError || json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &content) != nil || content.Error.Code != models.ErrorDisabled {
+		t.Errorf("Expected a disabled error result, got %+v", result)
+	}
+
+	// Enabled tools and resources still work
+	if _, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "library-stats", Arguments: map[string]any{}}); err != nil {
+		t.Errorf("Expected library-stats to remain available, got %v", err)
+	}
+	if _, err := session.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: "pdf://library/stats"}); err != nil {
+		t.Errorf("Expected resources to remain available, got %v", err)
+	}
+}
+
+func TestConfiguredCapabilities(t *testing.T) {
+	tests := []struct {
+		name     string
+		readOnly string
+		disabled string
+		expected []string
+		wantErr  bool
+	}{
+		{"defaults", "", "", nil, false},
+		{"read only", "true", "", ReadOnlyDisabledTools, false},
+		{"disabled list", "", " document-parse, zotero-search ,", []string{"document-parse", "zotero-search"}, false},
+		{"combined", "1", "zotero-search,document-parse", append(slices.Clone(ReadOnlyDisabledTools), "zotero-search"), false},
+		{"invalid read only", "maybe", "document-parse", []string{"document-parse"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(readOnlyEnv, tt.readOnly)
+			t.Setenv(disabledToolsEnv, tt.disabled)
+			capabilities, err := ConfiguredCapabilities()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if !reflect.DeepEqual(capabilities.DisabledTools, tt.expected) {
+				t.Errorf("Expected disabled tools %v, got %v", tt.expected, capabilities.DisabledTools)
+			}
+		})
+	}
+}
+
+func TestToolCall_CorrelationID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "academic.log")
+	log, err := logger.NewLogger(logger.LogConfig{Output: "file", FilePath: path, Level: "info", Format: "json"})
+	if err != nil {
+		t.Fatalf("NewLogger failed: %v", err)
+	}
+	session := connectInMemoryWithLogger(t, Capabilities{}, log)
+
+	for range 2 {
+		if _, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "library-stats", Arguments: map[string]any{}}); err != nil {
+			t.Fatalf("CallTool failed: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Each call's messages, including those of the handler, share its ID
+	messages := make(map[string][]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Expected JSON log lines, got %q", line)
+		}
+		if entry["tool"] == "library-stats" {
+			id, _ := entry["request_id"].(string)
+			messages[id] = append(messages[id], entry["msg"].(string))
+		}
+	}
+	if len(messages) != 2 {
+		t.Fatalf("Expected two calls with distinct request IDs, got %v", messages)
+	}
+	for id, msgs := range messages {
+		if id == "" || !slices.Contains(msgs, "library-stats tool called") || len(msgs) < 2 {
+			t.Errorf("Expected call %q to tag every handler message, got %q", id, msgs)
+		}
+	}
+}
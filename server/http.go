@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+)
+
+// shutdownTimeout bounds how long in-flight requests may take to finish after shutdown begins
+const shutdownTimeout = 30 * time.Second
+
+// NewHTTPHandler serves srv over the MCP streamable HTTP transport. All clients share
+// srv and its store. If authToken is non-empty, requests must present it as a bearer token.
+func NewHTTPHandler(srv *mcp.Server, authToken string) http.Handler {
+	handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return srv }, nil)
+	if authToken == "" {
+		return handler
+	}
+	return auth.RequireBearerToken(staticTokenVerifier(authToken), nil)(handler)
+}
+
+// staticTokenVerifier accepts exactly one shared token
+func staticTokenVerifier(authToken string) auth.TokenVerifier {
+	return func(ctx context.Context, token string, req *http.Request) (*auth.TokenInfo, error) {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(authToken)) != 1 {
+			return nil, auth.ErrInvalidToken
+		}
+		// The shared token doesn't expire, but the middleware requires an expiration
+		return &auth.TokenInfo{Expiration: time.Now().Add(time.Hour)}, nil
+	}
+}
+
+// RunHTTP serves handler on listener until ctx is cancelled, then stops accepting
+// connections and waits up to shutdownTimeout for in-flight requests to finish.
+func RunHTTP(ctx context.Context, listener net.Listener, handler http.Handler, log logger.Logger) error {
+	httpServer := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.Serve(listener)
+	}()
+	log.Info("Serving MCP over HTTP at %s", listener.Addr())
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("HTTP server failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	log.Info("Shutting down HTTP server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		// Long-lived event streams keep connections open past the grace period
+		log.Warn("HTTP server did not shut down cleanly: %v", err)
+		httpServer.Close()
+	}
+	if err := <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("HTTP server failed: %w", err)
+	}
+	return nil
+}
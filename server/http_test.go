@@ -0,0 +1,266 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// newTestHTTPServer serves a server backed by a file-based store, so concurrent
+// requests use separate SQLite connections as they would in production
+func newTestHTTPServer(t *testing.T, authToken string) (*httptest.Server, storage.Store) {
+	t.Helper()
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"), log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	httpServer := httptest.NewServer(NewHTTPHandler(NewServer(store, environmentCredentials(t), log), authToken))
+	t.Cleanup(func() {
+		httpServer.Close()
+		store.Close()
+	})
+	return httpServer, store
+}
+
+// bearerTransport adds a bearer token to every request
+type bearerTransport struct {
+	token string
+}
+
+func (b bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+b.token)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func connectClient(ctx context.Context, endpoint, token string) (*mcp.ClientSession, error) {
+	transport := &mcp.StreamableClientTransport{Endpoint: endpoint, MaxRetries: -1}
+	if token != "" {
+		transport.HTTPClient = &http.Client{Transport: bearerTransport{token: token}}
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
+	return client.Connect(ctx, transport, nil)
+}
+
+func testDocument(n int) *models.ParsedItem {
+	return &models.ParsedItem{
+		Metadata: models.ItemMetadata{
+			Title:           fmt.Sprintf("Concurrent document %d", n),
+			Authors:         []string{"Jane Smith"},
+			PublicationDate: "2020",
+		},
+		Pages:       []string{"First page", "Second page"},
+		PageNumbers: []string{"1", "2"},
+		References:  []models.Reference{{ReferenceText: fmt.Sprintf("Reference for %d", n)}},
+	}
+}
+
+func TestNewHTTPHandler_BearerToken(t *testing.T) {
+	httpServer, _ := newTestHTTPServer(t, "secret-token")
+
+	tests := []struct {
+		name       string
+		authHeader string
+		wantStatus int
+	}{
+		{name: "missing token", wantStatus: http.StatusUnauthorized},
+		{name: "wrong token", authHeader: "Bearer wrong-token", wantStatus: http.StatusUnauthorized},
+		{name: "wrong scheme", authHeader: "Basic secret-token", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, httpServer.URL, nil)
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+		})
+	}
+
+	t.Run("valid token", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		session, err := connectClient(ctx, httpServer.URL, "secret-token")
+		if err != nil {
+			t.Fatalf("Failed to connect with valid token: %v", err)
+		}
+		defer session.Close()
+		if _, err := session.ListTools(ctx, nil); err != nil {
+			t.Errorf("ListTools failed: %v", err)
+		}
+	})
+}
+
+func TestHTTPServer_ToolErrorResult(t *testing.T) {
+	httpServer, _ := newTestHTTPServer(t, "")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	session, err := connectClient(ctx, httpServer.URL, "")
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer session.Close()
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "bibliography-export", Arguments: map[string]any{"document_ids": []string{"missing"}}})
+	if err != nil {
+		t.Fatalf("Expected a tool error result, got protocol error: %v", err)
+	}
+	if !result.IsError {
+		t.Fatalf("Expected IsError to be set, got %+v", result)
+	}
+	var content struct {
+		Error models.ToolError `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &content); err != nil {
+		t.Fatalf("Failed to decode error content: %v", err)
+	}
+	if content.Error.Code != models.ErrorNotFound {
+		t.Errorf("Expected not_found error, got %+v", content.Error)
+	}
+}
+
+func TestHTTPServer_ConcurrentClients(t *testing.T) {
+	httpServer, store := newTestHTTPServer(t, "")
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	const clients = 5
+	const documentsPerWriter = 10
+
+	var wg sync.WaitGroup
+	errs := make(chan error, clients*documentsPerWriter*3)
+
+	// Writers store documents directly while clients read over HTTP
+	for w := 0; w < clients; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < documentsPerWriter; i++ {
+				n := w*documentsPerWriter + i
+				if err := store.StoreParsedItem(ctx, fmt.Sprintf("doc-%d", n), testDocument(n), &models.SourceInfo{}); err != nil {
+					errs <- fmt.Errorf("store doc-%d: %w", n, err)
+				}
+			}
+		}(w)
+	}
+
+	for c := 0; c < clients; c++ {
+		wg.Add(1)
+		go func(c int) {
+			defer wg.Done()
+			session, err := connectClient(ctx, httpServer.URL, "")
+			if err != nil {
+				errs <- fmt.Errorf("client %d connect: %w", c, err)
+				return
+			}
+			defer session.Close()
+			for i := 0; i < documentsPerWriter; i++ {
+				result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "library-stats", Arguments: map[string]any{}})
+				if err != nil {
+					errs <- fmt.Errorf("client %d library-stats: %w", c, err)
+					return
+				}
+				if result.IsError {
+					errs <- fmt.Errorf("client %d library-stats returned an error: %+v", c, result.Content)
+					return
+				}
+				if _, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "pdf://library/stats"}); err != nil {
+					errs <- fmt.Errorf("client %d read stats resource: %w", c, err)
+					return
+				}
+			}
+		}(c)
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	// Every write must be visible and intact once the clients are done
+	session, err := connectClient(ctx, httpServer.URL, "")
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer session.Close()
+	resource, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "pdf://library/stats"})
+	if err != nil {
+		t.Fatalf("Failed to read stats: %v", err)
+	}
+	var stats models.LibraryStats
+	if err := json.Unmarshal([]byte(resource.Contents[0].Text), &stats); err != nil {
+		t.Fatalf("Failed to decode stats: %v", err)
+	}
+	if stats.DocumentCount != clients*documentsPerWriter || stats.TotalPages != 2*clients*documentsPerWriter {
+		t.Errorf("Expected %d documents with %d pages, got %d documents with %d pages",
+			clients*documentsPerWriter, 2*clients*documentsPerWriter, stats.DocumentCount, stats.TotalPages)
+	}
+	for n := 0; n < clients*documentsPerWriter; n++ {
+		refs, err := store.GetReferences(ctx, fmt.Sprintf("doc-%d", n))
+		if err != nil || len(refs) != 1 || refs[0].ReferenceText != fmt.Sprintf("Reference for %d", n) {
+			t.Errorf("doc-%d references corrupted: %+v (err: %v)", n, refs, err)
+		}
+	}
+}
+
+func TestRunHTTP_ShutsDownOnCancel(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- RunHTTP(ctx, listener, handler, logger.NewNoOpLogger())
+	}()
+
+	resp, err := http.Get("http://" + listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("RunHTTP returned error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunHTTP did not return after cancellation")
+	}
+
+	if _, err := http.Get("http://" + listener.Addr().String()); err == nil {
+		t.Error("Expected connections to be refused after shutdown")
+	}
+}
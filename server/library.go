@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/resources"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// libraryResources registers a concrete resource for each stored document (see
+// PDFResourceHandler.ListResources), so that clients calling resources/list can
+// browse the library, and keeps them in step with the store. The SDK notifies
+// connected clients with notifications/resources/list_changed whenever a
+// resource is added, renamed, or removed.
+type libraryResources struct {
+	server  *mcp.Server
+	handler *resources.PDFResourceHandler
+	log     logger.Logger
+
+	mu         sync.Mutex
+	registered map[string]mcp.Resource // Registered document resources by URI
+}
+
+func newLibraryResources(server *mcp.Server, handler *resources.PDFResourceHandler, log logger.Logger) *libraryResources {
+	return &libraryResources{server: server, handler: handler, log: log, registered: make(map[string]mcp.Resource)}
+}
+
+// sync registers the resources of documents stored since the last sync, updates
+// those whose name or description changed, and removes those of documents no
+// longer stored. Failures are logged; the previous resources stay registered.
+func (l *libraryResources) sync(ctx context.Context) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	list, err := l.handler.ListResources(ctx)
+	if err != nil {
+		l.log.Warn("Failed to list document resources: %v", err)
+		return
+	}
+
+	current := make(map[string]mcp.Resource, len(list))
+	added := 0
+	for _, resource := range list {
+		current[resource.URI] = resource
+		if old, ok := l.registered[resource.URI]; ok && sameResource(old, resource) {
+			continue
+		}
+		l.server.AddResource(&resource, l.handler.HandleReadResource)
+		added++
+	}
+
+	var stale []string
+	for uri := range l.registered {
+		if _, ok := current[uri]; !ok {
+			stale = append(stale, uri)
+		}
+	}
+	if len(stale) > 0 {
+		l.server.RemoveResources(stale...)
+	}
+	l.registered = current
+
+	if added > 0 || len(stale) > 0 {
+		l.log.Info("Document resources updated: %d added or changed, %d removed", added, len(stale))
+	}
+}
+
+func sameResource(a, b mcp.Resource) bool {
+	return a.Name == b.Name && a.Title == b.Title && a.Description == b.Description && a.MIMEType == b.MIMEType
+}
+
+// syncAfter wraps a tool handler that stores or changes documents so that the
+// document resources are synced once it returns
+func syncAfter[In, Out any](library *libraryResources, handler mcp.ToolHandlerFor[In, Out]) mcp.ToolHandlerFor[In, Out] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, input In) (*mcp.CallToolResult, Out, error) {
+		result, output, err := handler(ctx, req, input)
+		library.sync(context.WithoutCancel(ctx))
+		return result, output, err
+	}
+}
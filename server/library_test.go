@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func listDocumentResources(t *testing.T, session *mcp.ClientSession) map[string]*mcp.Resource {
+	t.Helper()
+	result, err := session.ListResources(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListResources failed: %v", err)
+	}
+	resources := make(map[string]*mcp.Resource)
+	for _, resource := range result.Resources {
+		resources[resource.URI] = resource
+	}
+	return resources
+}
+
+func TestLibraryResources(t *testing.T) {
+	parsed, _ := json.Marshal(map[string]any{
+		"metadata":   map[string]any{"title": "Field Notes", "authors": []string{"Smith, Jane"}, "publication_date": "2021"},
+		"content":    "The full field notes.",
+		"references": []any{}, "images": []any{}, "tables": []any{}, "footnotes": []any{}, "endnotes": []any{},
+	})
+	text, _ := json.Marshal(string(parsed))
+	openAI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"resp_1","object":"response","created_at":0,"status":"completed","model":"gpt-5-mini","output":[{"type":"message","id":"msg_1","status":"completed","role":"assistant","content":[{"type":"output_text","text":` + string(text) + `,"annotations":[]}]}]}`))
+	}))
+	defer openAI.Close()
+	t.Setenv("OPENAI_BASE_URL", openAI.URL)
+	t.Setenv("OPENAI_API_KEY", "test-key")
+
+	ctx := context.Background()
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(":memory:", log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+	stored := &models.ParsedItem{Metadata: models.ItemMetadata{Title: "Memory and the Archive", Citekey: "smith2020"}, Pages: []string{"Page one"}}
+	if err := store.StoreParsedItem(ctx, "url_1", stored, &models.SourceInfo{URL: "https://example.com/paper"}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+
+	srv := NewServerWithCapabilities(store, environmentCredentials(t), log, Capabilities{})
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := srv.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer serverSession.Close()
+
+	listChanged := make(chan struct{}, 16)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, &mcp.ClientOptions{
+		ResourceListChangedHandler: func(context.Context, *mcp.ResourceListChangedRequest) {
+			listChanged <- struct{}{}
+		},
+	})
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer session.Close()
+
+	// Documents stored before the server started are listed
+	before := listDocumentResources(t, session)
+	if resource := before["pdf://url_1"]; resource == nil || resource.Name != "smith2020: Memory and the Archive" {
+		t.Fatalf("Expected pdf://url_1 named by citekey and title, got %+v", before)
+	}
+
+	// The schema of raw_data is an array of bytes
+	var rawData []int
+	for _, b := range []byte("The full field notes.") {
+		rawData = append(rawData, int(b))
+	}
+	_, err = session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "document-parse",
+		Arguments: map[string]any{"raw_data": rawData, "doc_type": "txt"},
+	})
+	if err != nil {
+		t.Fatalf("document-parse failed: %v", err)
+	}
+
+	select {
+	case <-listChanged:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected a resource list changed notification after parsing")
+	}
+
+	after := listDocumentResources(t, session)
+	if len(after) != len(before)+1 {
+		t.Fatalf("Expected one more resource after parsing, got %d before and %d after", len(before), len(after))
+	}
+	found := false
+	for uri, resource := range after {
+		if before[uri] == nil {
+			found = true
+			if resource.Title != "Field Notes" || resource.MIMEType != "application/json" {
+				t.Errorf("Unexpected resource for the parsed document: %+v", resource)
+			}
+			read, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri})
+			if err != nil || len(read.Contents) != 1 {
+				t.Errorf("Failed to read %s: %v", uri, err)
+			}
+		}
+	}
+	if !found {
+		t.Error("Expected a resource for the parsed document")
+	}
+}
@@ -6,57 +6,216 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/Epistemic-Technology/academic-mcp/internal/config"
 	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/operations"
 	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/prompts"
 	"github.com/Epistemic-Technology/academic-mcp/resources"
 	"github.com/Epistemic-Technology/academic-mcp/tools"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
-func CreateServer(log logger.Logger) *mcp.Server {
-	server := mcp.NewServer(&mcp.Implementation{Name: "academic-mcp", Version: "v0.0.1"}, nil)
-
-	store, err := initializeStorage(log)
+// NewServer returns a server with the tools enabled by the environment (see
+// ConfiguredCapabilities) and all prompts and resources registered against store.
+// Tools and parsing jobs call OpenAI and Zotero with the keys credentials holds.
+// The caller owns store and is responsible for closing it.
+func NewServer(store storage.Store, credentials *config.Provider, log logger.Logger) *mcp.Server {
+	capabilities, err := ConfiguredCapabilities()
 	if err != nil {
-		log.Fatal("Failed to initialize storage: %v", err)
+		log.Warn("Ignoring invalid tool configuration: %v", err)
 	}
+	return NewServerWithCapabilities(store, credentials, log, capabilities)
+}
+
+// NewServerWithCapabilities returns a server with the tools capabilities enables
+// and all prompts and resources registered against store. Calls to disabled
+// tools return an error result with the disabled code.
+func NewServerWithCapabilities(store storage.Store, credentials *config.Provider, log logger.Logger, capabilities Capabilities) *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: "academic-mcp", Version: "v0.0.1"}, nil)
 
 	pdfResourceHandler := resources.NewPDFResourceHandler(store)
 
+	// Each stored document is listed as a resource, and the list follows the
+	// tools and jobs that store or change documents
+	library := newLibraryResources(server, pdfResourceHandler, log)
+	library.sync(context.Background())
+
+	// Background parsing jobs resume where a previous process left them, unless
+	// parsing is disabled
+	jobs := operations.NewJobRunner(store, log)
+	jobs.OnParsed(func(string) { library.sync(context.Background()) })
+	jobs.UseCredentials(credentials)
+	if capabilities.ToolEnabled("document-parse") {
+		if err := jobs.Resume(context.Background()); err != nil {
+			log.Warn("Failed to resume parsing jobs: %v", err)
+		}
+	}
+
 	// Register tools with storage and logger dependencies
-	mcp.AddTool(server, tools.DocumentParseTool(), func(ctx context.Context, req *mcp.CallToolRequest, query tools.DocumentParseQuery) (*mcp.CallToolResult, *tools.DocumentParseResponse, error) {
-		return tools.DocumentParseToolHandler(ctx, req, query, store, log)
+	registry := newToolRegistry(server, capabilities, credentials, log)
+	addTool(registry, tools.DocumentParseTool(), syncAfter(library, func(ctx context.Context, req *mcp.CallToolRequest, query tools.DocumentParseQuery) (*mcp.CallToolResult, *tools.DocumentParseResponse, error) {
+		return tools.DocumentParseToolHandler(ctx, req, query, store, jobs, logger.FromContext(ctx, log))
+	}))
+
+	addTool(registry, tools.JobStatusTool(), func(ctx context.Context, req *mcp.CallToolRequest, query tools.JobStatusQuery) (*mcp.CallToolResult, *tools.JobStatusResponse, error) {
+		return tools.JobStatusToolHandler(ctx, req, query, store, logger.FromContext(ctx, log))
+	})
+
+	addTool(registry, tools.JobCancelTool(), func(ctx context.Context, req *mcp.CallToolRequest, query tools.JobCancelQuery) (*mcp.CallToolResult, *tools.JobCancelResponse, error) {
+		return tools.JobCancelToolHandler(ctx, req, query, jobs, logger.FromContext(ctx, log))
+	})
+
+	addTool(registry, tools.DocumentSummarizeTool(), func(ctx context.Context, req *mcp.CallToolRequest, query tools.DocumentSummarizeQuery) (*mcp.CallToolResult, *tools.DocumentSummarizeResponse, error) {
+		return tools.DocumentSummarizeToolHandler(ctx, req, query, store, logger.FromContext(ctx, log))
+	})
+
+	addTool(registry, tools.DocumentQuotationsTool(), func(ctx context.Context, req *mcp.CallToolRequest, query tools.DocumentQuotationsQuery) (*mcp.CallToolResult, *tools.DocumentQuotationsResponse, error) {
+		return tools.DocumentQuotationsToolHandler(ctx, req, query, store, logger.FromContext(ctx, log))
+	})
+
+	addTool(registry, tools.DocumentReparsePagesTool(), func(ctx context.Context, req *mcp.CallToolRequest, query tools.DocumentReparsePagesQuery) (*mcp.CallToolResult, *tools.DocumentReparsePagesResponse, error) {
+		return tools.DocumentReparsePagesToolHandler(ctx, req, query, store, logger.FromContext(ctx, log))
+	})
+
+	addTool(registry, tools.DocumentRenderPageTool(), func(ctx context.Context, req *mcp.CallToolRequest, query tools.DocumentRenderPageQuery) (*mcp.CallToolResult, *tools.DocumentRenderPageResponse, error) {
+		return tools.DocumentRenderPageToolHandler(ctx, req, query, store, logger.FromContext(ctx, log))
+	})
+
+	addTool(registry, tools.ZoteroSearchTool(), func(ctx context.Context, req *mcp.CallToolRequest, query tools.ZoteroSearchQuery) (*mcp.CallToolResult, *tools.ZoteroSearchResponse, error) {
+		return tools.ZoteroSearchToolHandler(ctx, req, query, store, logger.FromContext(ctx, log))
+	})
+
+	addTool(registry, tools.ZoteroGetItemTool(), func(ctx context.Context, req *mcp.CallToolRequest, query tools.ZoteroGetItemQuery) (*mcp.CallToolResult, *tools.ZoteroGetItemResponse, error) {
+		return tools.ZoteroGetItemToolHandler(ctx, req, query, store, logger.FromContext(ctx, log))
+	})
+
+	addTool(registry, tools.ZoteroCollectionsTool(), func(ctx context.Context, req *mcp.CallToolRequest, query tools.ZoteroCollectionsQuery) (*mcp.CallToolResult, *tools.ZoteroCollectionsResponse, error) {
+		return tools.ZoteroCollectionsToolHandler(ctx, req, query, store, logger.FromContext(ctx, log))
+	})
+
+	addTool(registry, tools.ZoteroImportTool(), syncAfter(library, func(ctx context.Context, req *mcp.CallToolRequest, query tools.ZoteroImportQuery) (*mcp.CallToolResult, *tools.ZoteroImportResponse, error) {
+		return tools.ZoteroImportToolHandler(ctx, req, query, store, logger.FromContext(ctx, log))
+	}))
+
+	addTool(registry, tools.ZoteroWritebackTool(), func(ctx context.Context, req *mcp.CallToolRequest, query tools.ZoteroWritebackQuery) (*mcp.CallToolResult, *tools.ZoteroWritebackResponse, error) {
+		return tools.ZoteroWritebackToolHandler(ctx, req, query, store, logger.FromContext(ctx, log))
 	})
 
-	mcp.AddTool(server, tools.DocumentSummarizeTool(), func(ctx context.Context, req *mcp.CallToolRequest, query tools.DocumentSummarizeQuery) (*mcp.CallToolResult, *tools.DocumentSummarizeResponse, error) {
-		return tools.DocumentSummarizeToolHandler(ctx, req, query, store, log)
+	addTool(registry, tools.ZoteroTagTool(), func(ctx context.Context, req *mcp.CallToolRequest, query tools.ZoteroTagQuery) (*mcp.CallToolResult, *tools.ZoteroTagResponse, error) {
+		return tools.ZoteroTagToolHandler(ctx, req, query, store, logger.FromContext(ctx, log))
 	})
 
-	mcp.AddTool(server, tools.DocumentQuotationsTool(), func(ctx context.Context, req *mcp.CallToolRequest, query tools.DocumentQuotationsQuery) (*mcp.CallToolResult, *tools.DocumentQuotationsResponse, error) {
-		return tools.DocumentQuotationsToolHandler(ctx, req, query, store, log)
+	addTool(registry, tools.BibliographyExportTool(), func(ctx context.Context, req *mcp.CallToolRequest, query tools.BibliographyExportQuery) (*mcp.CallToolResult, *tools.BibliographyExportResponse, error) {
+		return tools.BibliographyExportToolHandler(ctx, req, query, store, logger.FromContext(ctx, log))
 	})
 
-	mcp.AddTool(server, tools.ZoteroSearchTool(), func(ctx context.Context, req *mcp.CallToolRequest, query tools.ZoteroSearchQuery) (*mcp.CallToolResult, *tools.ZoteroSearchResponse, error) {
-		return tools.ZoteroSearchToolHandler(ctx, req, query, store, log)
+	addTool(registry, tools.DocumentExportTool(), func(ctx context.Context, req *mcp.CallToolRequest, query tools.DocumentExportQuery) (*mcp.CallToolResult, *tools.DocumentExportResponse, error) {
+		return tools.DocumentExportToolHandler(ctx, req, query, store, logger.FromContext(ctx, log))
 	})
 
-	mcp.AddTool(server, tools.ZoteroCollectionsTool(), func(ctx context.Context, req *mcp.CallToolRequest, query tools.ZoteroCollectionsQuery) (*mcp.CallToolResult, *tools.ZoteroCollectionsResponse, error) {
-		return tools.ZoteroCollectionsToolHandler(ctx, req, query, store, log)
+	addTool(registry, tools.QuotationsExportTool(), func(ctx context.Context, req *mcp.CallToolRequest, query tools.QuotationsExportQuery) (*mcp.CallToolResult, *tools.QuotationsExportResponse, error) {
+		return tools.QuotationsExportToolHandler(ctx, req, query, store, logger.FromContext(ctx, log))
 	})
 
-	mcp.AddTool(server, tools.BibliographyExportTool(), func(ctx context.Context, req *mcp.CallToolRequest, query tools.BibliographyExportQuery) (*mcp.CallToolResult, *tools.BibliographyExportResponse, error) {
-		return tools.BibliographyExportToolHandler(ctx, req, query, store, log)
+	addTool(registry, tools.DocumentAnnotateTool(), func(ctx context.Context, req *mcp.CallToolRequest, query tools.DocumentAnnotateQuery) (*mcp.CallToolResult, *tools.DocumentAnnotateResponse, error) {
+		return tools.DocumentAnnotateToolHandler(ctx, req, query, store, logger.FromContext(ctx, log))
 	})
 
+	addTool(registry, tools.DocumentMetadataSetTool(), syncAfter(library, func(ctx context.Context, req *mcp.CallToolRequest, query tools.DocumentMetadataSetQuery) (*mcp.CallToolResult, *tools.DocumentMetadataSetResponse, error) {
+		return tools.DocumentMetadataSetToolHandler(ctx, req, query, store, logger.FromContext(ctx, log))
+	}))
+
+	addTool(registry, tools.DocumentRefreshMetadataTool(), syncAfter(library, func(ctx context.Context, req *mcp.CallToolRequest, query tools.DocumentRefreshMetadataQuery) (*mcp.CallToolResult, *tools.DocumentRefreshMetadataResponse, error) {
+		return tools.DocumentRefreshMetadataToolHandler(ctx, req, query, store, logger.FromContext(ctx, log))
+	}))
+
+	addTool(registry, tools.CitekeysRegenerateTool(), syncAfter(library, func(ctx context.Context, req *mcp.CallToolRequest, query tools.CitekeysRegenerateQuery) (*mcp.CallToolResult, *tools.CitekeysRegenerateResponse, error) {
+		return tools.CitekeysRegenerateToolHandler(ctx, req, query, store, logger.FromContext(ctx, log))
+	}))
+
+	addTool(registry, tools.DocumentImportTool(), syncAfter(library, func(ctx context.Context, req *mcp.CallToolRequest, query tools.DocumentImportQuery) (*mcp.CallToolResult, *tools.DocumentImportResponse, error) {
+		return tools.DocumentImportToolHandler(ctx, req, query, store, logger.FromContext(ctx, log))
+	}))
+
+	addTool(registry, tools.DocumentListTool(), func(ctx context.Context, req *mcp.CallToolRequest, query tools.DocumentListQuery) (*mcp.CallToolResult, *tools.DocumentListResponse, error) {
+		return tools.DocumentListToolHandler(ctx, req, query, store, logger.FromContext(ctx, log))
+	})
+
+	addTool(registry, tools.LibrarySearchTool(), func(ctx context.Context, req *mcp.CallToolRequest, query tools.LibrarySearchQuery) (*mcp.CallToolResult, *tools.LibrarySearchResponse, error) {
+		return tools.LibrarySearchToolHandler(ctx, req, query, store, logger.FromContext(ctx, log))
+	})
+
+	addTool(registry, tools.LibraryStatsTool(), func(ctx context.Context, req *mcp.CallToolRequest, query tools.LibraryStatsQuery) (*mcp.CallToolResult, *tools.LibraryStatsResponse, error) {
+		return tools.LibraryStatsToolHandler(ctx, req, query, store, logger.FromContext(ctx, log))
+	})
+
+	addTool(registry, tools.LibraryVerifyTool(), syncAfter(library, func(ctx context.Context, req *mcp.CallToolRequest, query tools.LibraryVerifyQuery) (*mcp.CallToolResult, *tools.LibraryVerifyResponse, error) {
+		return tools.LibraryVerifyToolHandler(ctx, req, query, store, logger.FromContext(ctx, log))
+	}))
+
+	addTool(registry, tools.LibraryExportTool(), func(ctx context.Context, req *mcp.CallToolRequest, query tools.LibraryExportQuery) (*mcp.CallToolResult, *tools.LibraryExportResponse, error) {
+		return tools.LibraryExportToolHandler(ctx, req, query, store, logger.FromContext(ctx, log))
+	})
+
+	addTool(registry, tools.LibraryImportTool(), syncAfter(library, func(ctx context.Context, req *mcp.CallToolRequest, query tools.LibraryImportQuery) (*mcp.CallToolResult, *tools.LibraryImportResponse, error) {
+		return tools.LibraryImportToolHandler(ctx, req, query, store, logger.FromContext(ctx, log))
+	}))
+
+	addTool(registry, tools.LibraryCitationGraphTool(), func(ctx context.Context, req *mcp.CallToolRequest, query tools.LibraryCitationGraphQuery) (*mcp.CallToolResult, *tools.LibraryCitationGraphResponse, error) {
+		return tools.LibraryCitationGraphToolHandler(ctx, req, query, store, logger.FromContext(ctx, log))
+	})
+
+	addTool(registry, tools.DocumentRelatedTool(), func(ctx context.Context, req *mcp.CallToolRequest, query tools.DocumentRelatedQuery) (*mcp.CallToolResult, *tools.DocumentRelatedResponse, error) {
+		return tools.DocumentRelatedToolHandler(ctx, req, query, store, logger.FromContext(ctx, log))
+	})
+
+	addTool(registry, tools.ServerStatusTool(), func(ctx context.Context, req *mcp.CallToolRequest, query tools.ServerStatusQuery) (*mcp.CallToolResult, *tools.ServerStatusResponse, error) {
+		return tools.ServerStatusToolHandler(ctx, req, query, store, logger.FromContext(ctx, log))
+	})
+
+	addTool(registry, tools.ServerReloadConfigTool(), func(ctx context.Context, req *mcp.CallToolRequest, query tools.ServerReloadConfigQuery) (*mcp.CallToolResult, *tools.ServerReloadConfigResponse, error) {
+		return tools.ServerReloadConfigToolHandler(ctx, req, query, credentials, logger.FromContext(ctx, log))
+	})
+
+	registry.gate(log)
+
+	// Register prompts
+	server.AddPrompt(prompts.LiteratureReviewPrompt(), func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		return prompts.LiteratureReviewPromptHandler(ctx, req, log)
+	})
+
+	// Library-wide statistics
+	server.AddResource(&mcp.Resource{
+		URI:         "pdf://library/stats",
+		Name:        "library-stats",
+		Description: "Aggregate statistics across all stored documents",
+		MIMEType:    "application/json",
+	}, pdfResourceHandler.HandleReadResource)
+
+	// Distinct authors across the library
+	server.AddResource(&mcp.Resource{
+		URI:         "pdf://library/authors",
+		Name:        "library-authors",
+		Description: "Distinct authors across all stored documents, with normalized names and document counts",
+		MIMEType:    "application/json",
+	}, pdfResourceHandler.HandleReadResource)
+
+	// The library's .bib file
+	server.AddResource(&mcp.Resource{
+		URI:         "pdf://library/bibliography",
+		Name:        "library-bibliography",
+		Description: "BibTeX entries of every stored document with a citekey, ordered by citekey. At most 512 KiB of entries are returned at a time; a larger library starts with a comment giving the URI of the next window (pdf://library/bibliography?offset=N, with an optional limit on the number of entries)",
+		MIMEType:    "application/x-bibtex",
+	}, pdfResourceHandler.HandleReadResource)
+
 	// Template for document summary
 	server.AddResourceTemplate(&mcp.ResourceTemplate{
 		URITemplate: "pdf://{documentId}",
 		Name:        "pdf-document",
 		Description: "Parsed PDF document with metadata and content summary",
 		MIMEType:    "application/json",
-	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-		return pdfResourceHandler.ReadResource(ctx, req.Params.URI)
-	})
+	}, pdfResourceHandler.HandleReadResource)
 
 	// Template for metadata
 	server.AddResourceTemplate(&mcp.ResourceTemplate{
@@ -64,29 +223,79 @@ func CreateServer(log logger.Logger) *mcp.Server {
 		Name:        "pdf-metadata",
 		Description: "Document metadata including title, authors, DOI, and abstract",
 		MIMEType:    "application/json",
-	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-		return pdfResourceHandler.ReadResource(ctx, req.Params.URI)
-	})
+	}, pdfResourceHandler.HandleReadResource)
+
+	// Template for a document's BibTeX entry
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: "pdf://{documentId}/bibtex",
+		Name:        "pdf-bibtex",
+		Description: "The document's BibTeX entry, generated from its stored metadata as bibliography-export writes it; documents without a citekey have none",
+		MIMEType:    "application/x-bibtex",
+	}, pdfResourceHandler.HandleReadResource)
 
 	// Template for pages
 	server.AddResourceTemplate(&mcp.ResourceTemplate{
-		URITemplate: "pdf://{documentId}/pages",
+		URITemplate: "pdf://{documentId}/pages{?offset,limit,all}",
 		Name:        "pdf-pages",
-		Description: "All pages of the document",
+		Description: "Pages of the document, at most ACADEMIC_MCP_MAX_PAGE_RANGE (default 20) at a time. Page through with offset (zero-based) and limit, following the next URI in each response; all=true returns every page.",
 		MIMEType:    "application/json",
-	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-		return pdfResourceHandler.ReadResource(ctx, req.Params.URI)
-	})
+	}, pdfResourceHandler.HandleReadResource)
 
 	// Template for individual page
 	server.AddResourceTemplate(&mcp.ResourceTemplate{
-		URITemplate: "pdf://{documentId}/pages/{pageIndex}",
+		URITemplate: "pdf://{documentId}/pages/{pageNumber}",
 		Name:        "pdf-page",
-		Description: "A specific page from the document (0-indexed)",
+		Description: "A specific page by its source page number as printed in the document (e.g., \"125\" or \"iv\"); percent-encode a page number containing a slash",
 		MIMEType:    "application/json",
-	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-		return pdfResourceHandler.ReadResource(ctx, req.Params.URI)
-	})
+	}, pdfResourceHandler.HandleReadResource)
+
+	// Template for page ranges
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: "pdf://{documentId}/pages/{startPage}-{endPage}",
+		Name:        "pdf-page-range",
+		Description: "A contiguous range of pages by source page number (sequential numbers as fallback), inclusive",
+		MIMEType:    "application/json",
+	}, pdfResourceHandler.HandleReadResource)
+
+	// Template for a span of a page's text
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: "pdf://{documentId}/pages/{sourcePage}/span{?start,end,context,hash}",
+		Name:        "pdf-page-span",
+		Description: "The text of a page between two byte offsets (start and end), such as a quotation's span, with context bytes of text on each side (default 200), the sentences it falls in, and the page's content hash; with the hash the offsets were taken with, stale reports whether the page has changed since",
+		MIMEType:    "application/json",
+	}, pdfResourceHandler.HandleReadResource)
+
+	// Template for full text
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: "pdf://{documentId}/fulltext",
+		Name:        "pdf-fulltext",
+		Description: "Continuous text of the document with hyphenation and paragraph breaks at page boundaries undone, with the offset at which each page begins",
+		MIMEType:    "application/json",
+	}, pdfResourceHandler.HandleReadResource)
+
+	// Template for a page with its surroundings
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: "pdf://{documentId}/context/{sourcePage}{?window}",
+		Name:        "pdf-page-context",
+		Description: "A page with its neighboring pages (window pages on each side, default 1) and the footnotes, stored quotations, and annotations for it",
+		MIMEType:    "application/json",
+	}, pdfResourceHandler.HandleReadResource)
+
+	// Template for sections
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: "pdf://{documentId}/sections",
+		Name:        "pdf-sections",
+		Description: "Section index built from the document's headings, with titles, levels, and page spans",
+		MIMEType:    "application/json",
+	}, pdfResourceHandler.HandleReadResource)
+
+	// Template for individual section
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: "pdf://{documentId}/sections/{sectionIndex}",
+		Name:        "pdf-section",
+		Description: "The text of a specific section, including its subsections (0-indexed)",
+		MIMEType:    "application/json",
+	}, pdfResourceHandler.HandleReadResource)
 
 	// Template for references
 	server.AddResourceTemplate(&mcp.ResourceTemplate{
@@ -94,9 +303,7 @@ func CreateServer(log logger.Logger) *mcp.Server {
 		Name:        "pdf-references",
 		Description: "All references cited in the document",
 		MIMEType:    "application/json",
-	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-		return pdfResourceHandler.ReadResource(ctx, req.Params.URI)
-	})
+	}, pdfResourceHandler.HandleReadResource)
 
 	// Template for individual reference
 	server.AddResourceTemplate(&mcp.ResourceTemplate{
@@ -104,9 +311,15 @@ func CreateServer(log logger.Logger) *mcp.Server {
 		Name:        "pdf-reference",
 		Description: "A specific reference from the document (0-indexed)",
 		MIMEType:    "application/json",
-	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-		return pdfResourceHandler.ReadResource(ctx, req.Params.URI)
-	})
+	}, pdfResourceHandler.HandleReadResource)
+
+	// Template for the references on one page
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: "pdf://{documentId}/references/pages/{sourcePage}",
+		Name:        "pdf-page-references",
+		Description: "The references that appear on a page, identified by its source page number (e.g., '125' or 'iv'), each with its ref_index",
+		MIMEType:    "application/json",
+	}, pdfResourceHandler.HandleReadResource)
 
 	// Template for images
 	server.AddResourceTemplate(&mcp.ResourceTemplate{
@@ -114,9 +327,7 @@ func CreateServer(log logger.Logger) *mcp.Server {
 		Name:        "pdf-images",
 		Description: "All images from the document",
 		MIMEType:    "application/json",
-	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-		return pdfResourceHandler.ReadResource(ctx, req.Params.URI)
-	})
+	}, pdfResourceHandler.HandleReadResource)
 
 	// Template for individual image
 	server.AddResourceTemplate(&mcp.ResourceTemplate{
@@ -124,9 +335,14 @@ func CreateServer(log logger.Logger) *mcp.Server {
 		Name:        "pdf-image",
 		Description: "A specific image from the document (0-indexed)",
 		MIMEType:    "application/json",
-	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-		return pdfResourceHandler.ReadResource(ctx, req.Params.URI)
-	})
+	}, pdfResourceHandler.HandleReadResource)
+
+	// Template for image data
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: "pdf://{documentId}/images/{imageIndex}/data",
+		Name:        "pdf-image-data",
+		Description: "The bytes of an image embedded in a PDF, with the image's MIME type (0-indexed; only images whose mime_type is set have data)",
+	}, pdfResourceHandler.HandleReadResource)
 
 	// Template for tables
 	server.AddResourceTemplate(&mcp.ResourceTemplate{
@@ -134,19 +350,15 @@ func CreateServer(log logger.Logger) *mcp.Server {
 		Name:        "pdf-tables",
 		Description: "All tables from the document",
 		MIMEType:    "application/json",
-	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-		return pdfResourceHandler.ReadResource(ctx, req.Params.URI)
-	})
+	}, pdfResourceHandler.HandleReadResource)
 
 	// Template for individual table
 	server.AddResourceTemplate(&mcp.ResourceTemplate{
-		URITemplate: "pdf://{documentId}/tables/{tableIndex}",
+		URITemplate: "pdf://{documentId}/tables/{tableIndex}{?format}",
 		Name:        "pdf-table",
-		Description: "A specific table from the document (0-indexed)",
+		Description: "A specific table from the document (0-indexed). Use ?format=csv or ?format=markdown for the table alone; the default JSON includes its parsed columns and rows.",
 		MIMEType:    "application/json",
-	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-		return pdfResourceHandler.ReadResource(ctx, req.Params.URI)
-	})
+	}, pdfResourceHandler.HandleReadResource)
 
 	// Template for footnotes
 	server.AddResourceTemplate(&mcp.ResourceTemplate{
@@ -154,9 +366,7 @@ func CreateServer(log logger.Logger) *mcp.Server {
 		Name:        "pdf-footnotes",
 		Description: "All footnotes from the document",
 		MIMEType:    "application/json",
-	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-		return pdfResourceHandler.ReadResource(ctx, req.Params.URI)
-	})
+	}, pdfResourceHandler.HandleReadResource)
 
 	// Template for individual footnote
 	server.AddResourceTemplate(&mcp.ResourceTemplate{
@@ -164,9 +374,7 @@ func CreateServer(log logger.Logger) *mcp.Server {
 		Name:        "pdf-footnote",
 		Description: "A specific footnote from the document (0-indexed)",
 		MIMEType:    "application/json",
-	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-		return pdfResourceHandler.ReadResource(ctx, req.Params.URI)
-	})
+	}, pdfResourceHandler.HandleReadResource)
 
 	// Template for endnotes
 	server.AddResourceTemplate(&mcp.ResourceTemplate{
@@ -174,9 +382,7 @@ func CreateServer(log logger.Logger) *mcp.Server {
 		Name:        "pdf-endnotes",
 		Description: "All endnotes from the document",
 		MIMEType:    "application/json",
-	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-		return pdfResourceHandler.ReadResource(ctx, req.Params.URI)
-	})
+	}, pdfResourceHandler.HandleReadResource)
 
 	// Template for individual endnote
 	server.AddResourceTemplate(&mcp.ResourceTemplate{
@@ -184,9 +390,15 @@ func CreateServer(log logger.Logger) *mcp.Server {
 		Name:        "pdf-endnote",
 		Description: "A specific endnote from the document (0-indexed)",
 		MIMEType:    "application/json",
-	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-		return pdfResourceHandler.ReadResource(ctx, req.Params.URI)
-	})
+	}, pdfResourceHandler.HandleReadResource)
+
+	// Template for combined notes
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: "pdf://{documentId}/notes",
+		Name:        "pdf-notes",
+		Description: "Footnotes and endnotes merged and ordered by where their markers first occur in the text",
+		MIMEType:    "application/json",
+	}, pdfResourceHandler.HandleReadResource)
 
 	// Template for quotations
 	server.AddResourceTemplate(&mcp.ResourceTemplate{
@@ -194,9 +406,7 @@ func CreateServer(log logger.Logger) *mcp.Server {
 		Name:        "pdf-quotations",
 		Description: "All quotations from the document",
 		MIMEType:    "application/json",
-	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-		return pdfResourceHandler.ReadResource(ctx, req.Params.URI)
-	})
+	}, pdfResourceHandler.HandleReadResource)
 
 	// Template for individual quotation
 	server.AddResourceTemplate(&mcp.ResourceTemplate{
@@ -204,36 +414,42 @@ func CreateServer(log logger.Logger) *mcp.Server {
 		Name:        "pdf-quotation",
 		Description: "A specific quotation from the document (0-indexed)",
 		MIMEType:    "application/json",
-	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-		return pdfResourceHandler.ReadResource(ctx, req.Params.URI)
-	})
+	}, pdfResourceHandler.HandleReadResource)
+
+	// Template for annotations
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: "pdf://{documentId}/annotations",
+		Name:        "pdf-annotations",
+		Description: "The reader's own notes on the document, recorded with document-annotate",
+		MIMEType:    "application/json",
+	}, pdfResourceHandler.HandleReadResource)
 
 	return server
 }
 
-// initializeStorage creates and initializes the storage backend
-func initializeStorage(log logger.Logger) (storage.Store, error) {
-	// Determine database path
-	dbPath := os.Getenv("ACADEMIC_MCP_DB_PATH")
-	if dbPath == "" {
-		// Default to ~/.academic-mcp/academic.db
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get user home directory: %w", err)
-		}
-		dbDir := filepath.Join(homeDir, ".academic-mcp")
-		if err := os.MkdirAll(dbDir, 0755); err != nil {
+// InitializeStorage creates and initializes the storage backend
+func InitializeStorage(log logger.Logger) (storage.Store, error) {
+	dbPath, err := storage.DatabasePath()
+	if err != nil {
+		return nil, err
+	}
+	if os.Getenv("ACADEMIC_MCP_DB_PATH") == "" {
+		// Create the default ~/.academic-mcp directory
+		if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
 			return nil, fmt.Errorf("failed to create database directory: %w", err)
 		}
-		dbPath = filepath.Join(dbDir, "academic.db")
 	}
 
 	log.Info("Initializing SQLite database at: %s", dbPath)
 
-	store, err := storage.NewSQLiteStore(dbPath, log)
+	key := storage.DatabaseKey()
+	store, err := storage.NewSQLiteStoreWithKey(dbPath, key, log)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create SQLite store: %w", err)
 	}
+	if key != "" {
+		log.Info("Document content is encrypted at rest")
+	}
 
 	return store, nil
 }
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+)
+
+// errShuttingDown is returned for requests that arrive after shutdown has begun
+var errShuttingDown = errors.New("server is shutting down")
+
+// RunStdio serves srv over transport until the client disconnects or ctx is
+// cancelled (e.g., on SIGINT or SIGTERM). Once ctx is cancelled, new requests are
+// refused and in-flight ones get up to shutdownTimeout to finish before the
+// session is closed. store is closed before RunStdio returns.
+func RunStdio(ctx context.Context, srv *mcp.Server, transport mcp.Transport, store storage.Store, log logger.Logger) error {
+	return runStdio(ctx, srv, transport, store, shutdownTimeout, log)
+}
+
+func runStdio(ctx context.Context, srv *mcp.Server, transport mcp.Transport, store storage.Store, gracePeriod time.Duration, log logger.Logger) error {
+	defer func() {
+		if err := store.Close(); err != nil {
+			log.Error("Failed to close storage: %v", err)
+		}
+	}()
+
+	var requests drainer
+	srv.AddReceivingMiddleware(requests.middleware)
+
+	// The session outlives ctx so in-flight requests can finish after cancellation
+	sessionCtx, closeSession := context.WithCancel(context.WithoutCancel(ctx))
+	defer closeSession()
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- srv.Run(sessionCtx, transport)
+	}()
+	log.Info("Serving MCP over stdio")
+
+	select {
+	case err := <-runErr:
+		// The client disconnected
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Info("Shutting down; waiting up to %s for in-flight requests", gracePeriod)
+	if !requests.drain(gracePeriod) {
+		log.Warn("In-flight requests did not finish within %s", gracePeriod)
+	}
+	closeSession()
+	if err := <-runErr; err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
+
+// drainer tracks in-flight requests so shutdown can wait for them, and refuses
+// requests once shutdown has begun
+type drainer struct {
+	mu       sync.Mutex
+	draining bool
+	inFlight sync.WaitGroup
+}
+
+// middleware counts each request handled by next as in flight until it returns
+func (d *drainer) middleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		d.mu.Lock()
+		if d.draining {
+			d.mu.Unlock()
+			return nil, errShuttingDown
+		}
+		d.inFlight.Add(1)
+		d.mu.Unlock()
+		defer d.inFlight.Done()
+
+		return next(ctx, method, req)
+	}
+}
+
+// drain stops admitting requests and waits up to timeout for in-flight ones,
+// reporting whether they all finished
+func (d *drainer) drain(timeout time.Duration) bool {
+	d.mu.Lock()
+	d.draining = true
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
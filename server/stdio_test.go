@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+)
+
+// closeCountingStore counts calls to Close
+type closeCountingStore struct {
+	storage.Store
+	closes atomic.Int32
+}
+
+func (s *closeCountingStore) Close() error {
+	s.closes.Add(1)
+	return s.Store.Close()
+}
+
+func newCloseCountingStore(t *testing.T) *closeCountingStore {
+	t.Helper()
+	store, err := storage.NewSQLiteStore(":memory:", logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	return &closeCountingStore{Store: store}
+}
+
+func TestRunStdio_DrainsAndClosesStoreOnCancel(t *testing.T) {
+	log := logger.NewNoOpLogger()
+	store := newCloseCountingStore(t)
+	srv := NewServer(store, environmentCredentials(t), log)
+
+	// A tool that runs until released, recording whether it was cancelled
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var finished, cancelled atomic.Bool
+	mcp.AddTool(srv, &mcp.Tool{Name: "slow"}, func(ctx context.Context, req *mcp.CallToolRequest, in struct{}) (*mcp.CallToolResult, any, error) {
+		close(started)
+		<-release
+		cancelled.Store(ctx.Err() != nil)
+		finished.Store(true)
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "done"}}}, nil, nil
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- runStdio(ctx, srv, serverTransport, store, 5*time.Second, log)
+	}()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
+	session, err := client.Connect(context.Background(), clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer session.Close()
+
+	go session.CallTool(context.Background(), &mcp.CallToolParams{Name: "slow"})
+	<-started
+
+	cancel()
+	select {
+	case <-done:
+		t.Fatal("runStdio returned while a tool call was in flight")
+	case <-time.After(100 * time.Millisecond):
+	}
+	if store.closes.Load() != 0 {
+		t.Fatal("Store closed while a tool call was in flight")
+	}
+
+	close(release)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("runStdio returned error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("runStdio did not return after the tool call finished")
+	}
+
+	if !finished.Load() || cancelled.Load() {
+		t.Errorf("Expected the in-flight tool call to run to completion (finished: %v, cancelled: %v)", finished.Load(), cancelled.Load())
+	}
+	if closes := store.closes.Load(); closes != 1 {
+		t.Errorf("Expected Close to be called once, got %d", closes)
+	}
+}
+
+func TestRunStdio_ClosesStoreOnDisconnect(t *testing.T) {
+	log := logger.NewNoOpLogger()
+	store := newCloseCountingStore(t)
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	done := make(chan error, 1)
+	go func() {
+		done <- runStdio(context.Background(), NewServer(store, environmentCredentials(t), log), serverTransport, store, time.Second, log)
+	}()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
+	session, err := client.Connect(context.Background(), clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	session.Close()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("runStdio did not return after the client disconnected")
+	}
+	if closes := store.closes.Load(); closes != 1 {
+		t.Errorf("Expected Close to be called once, got %d", closes)
+	}
+}
+
+func TestDrainer(t *testing.T) {
+	var d drainer
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	handler := d.middleware(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		started <- struct{}{}
+		<-release
+		return nil, nil
+	})
+
+	go handler(context.Background(), "tools/call", nil)
+	<-started
+
+	if d.drain(50 * time.Millisecond) {
+		t.Error("Expected drain to time out with a request in flight")
+	}
+	if _, err := handler(context.Background(), "tools/call", nil); !errors.Is(err, errShuttingDown) {
+		t.Errorf("Expected new requests to be refused while draining, got %v", err)
+	}
+
+	close(release)
+	if !d.drain(time.Second) {
+		t.Error("Expected drain to finish once the request completed")
+	}
+}
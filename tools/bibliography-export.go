@@ -1,27 +1,72 @@
 package tools
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"slices"
 	"strings"
 
 	"github.com/Epistemic-Technology/academic-mcp/internal/citations"
+	"github.com/Epistemic-Technology/academic-mcp/internal/config"
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
 	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/operations"
 	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
 	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 type BibliographyExportQuery struct {
 	DocumentIDs []string `json:"document_ids,omitempty"`
-	Format      string   `json:"format,omitempty"` // Currently only "bibtex" is supported
+	Format      string   `json:"format,omitempty"`     // Currently only "bibtex" is supported
+	Collection  string   `json:"collection,omitempty"` // Zotero collection key or name to export (alternative to document_ids)
+	Recursive   bool     `json:"recursive,omitempty"`  // Include the collection's subcollections
+	// Library selection for collection (defaults to ZOTERO_LIBRARY_TYPE / ZOTERO_LIBRARY_ID)
+	LibraryType string `json:"library_type,omitempty"` // "user" or "group"
+	LibraryID   string `json:"library_id,omitempty"`   // User or group library ID
+	// Ordering and output
+	OrderBy    string `json:"order_by,omitempty"`    // "citekey", "author", "year", or "date_added" (default: the order documents were requested or listed)
+	OutputPath string `json:"output_path,omitempty"` // Optional .bib file to write instead of returning the content, relative to ACADEMIC_MCP_EXPORT_DIR
+	Merge      bool   `json:"merge,omitempty"`       // With output_path, only append entries whose citekeys the existing file lacks
+	// Document library of this server to export (not a Zotero library). Without
+	// document_ids or collection, only its documents are exported; document_ids
+	// and a collection's attachments are looked up in it. Empty exports every
+	// library, and looks documents up in the default one.
+	Library string `json:"library,omitempty"`
+}
+
+// bibliographyOrders are the accepted values of order_by
+var bibliographyOrders = []string{"citekey", "author", "year", "date_added"}
+
+// UnparsedAttachment is an attachment in an exported collection that has not been parsed
+type UnparsedAttachment struct {
+	ItemKey       string `json:"item_key"`
+	AttachmentKey string `json:"attachment_key"` // Use as zotero_id in document-parse
+	Title         string `json:"title,omitempty"`
+	ContentType   string `json:"content_type,omitempty"`
+	CollectionKey string `json:"collection_key"` // Collection or subcollection the item is in
 }
 
 type BibliographyExportResponse struct {
-	Format         string   `json:"format"`
-	Content        string   `json:"content"`
-	DocumentCount  int      `json:"document_count"`
-	MissingCitekey []string `json:"missing_citekey,omitempty"`
+	Format          string               `json:"format"`
+	Content         string               `json:"content,omitempty"` // Omitted when written to output_path
+	DocumentCount   int                  `json:"document_count"`    // Entries exported (with merge, entries added to the file)
+	MissingCitekey  []string             `json:"missing_citekey,omitempty"`
+	WrittenPath     string               `json:"written_path,omitempty"`
+	ExistingCitekey []string             `json:"existing_citekey,omitempty"` // With merge, entries skipped because the file already has their citekeys
+	Collection      string               `json:"collection,omitempty"`       // Key of the exported collection
+	Unparsed        []UnparsedAttachment `json:"unparsed,omitempty"`         // Collection attachments not parsed yet
+}
+
+// bibliographyEntry is a document's BibTeX entry with what it is ordered by
+type bibliographyEntry struct {
+	docID    string
+	metadata *models.ItemMetadata
+	entry    string
 }
 
 func BibliographyExportTool() *mcp.Tool {
@@ -31,7 +76,7 @@ func BibliographyExportTool() *mcp.Tool {
 	}
 	return &mcp.Tool{
 		Name:        "bibliography-export",
-		Description: "Export bibliography in BibTeX format. If document_ids are specified, exports only those documents. If not specified, exports the entire library. All documents must have been previously parsed.",
+		Description: "Export bibliography in BibTeX format. If document_ids are specified, exports only those documents. If collection is specified (a Zotero collection key or name), exports the parsed documents attached to its items, including those of its subcollections if recursive is true, and lists the attachments not parsed yet under unparsed. Otherwise, exports the entire library, or only the documents of one of the server's libraries if library is set; citekeys are unique only within a library, so export libraries separately. All documents must have been previously parsed. Use order_by ('citekey', 'author', 'year', or 'date_added') for a stable order, so exports can be diffed. For large libraries, set output_path to write the .bib file under the directory configured by ACADEMIC_MCP_EXPORT_DIR and return only a summary; with merge, an existing file is kept and only entries whose citekeys it lacks are appended.",
 		InputSchema: inputschema,
 	}
 }
@@ -48,22 +93,64 @@ func BibliographyExportToolHandler(ctx context.Context, req *mcp.CallToolRequest
 	// Currently only BibTeX is supported
 	if strings.ToLower(format) != "bibtex" {
 		log.Error("Unsupported format: %s", format)
-		return nil, nil, fmt.Errorf("unsupported format: %s (only 'bibtex' is supported)", format)
+		return errorResult(fmt.Errorf("unsupported format: %s (only 'bibtex' is supported)", format), models.ErrorInvalidInput), nil, nil
+	}
+
+	if len(query.DocumentIDs) > 0 && query.Collection != "" {
+		return errorResult(errors.New("specify either document_ids or collection, not both"), models.ErrorInvalidInput), nil, nil
+	}
+	orderBy := strings.ToLower(query.OrderBy)
+	if orderBy != "" && !slices.Contains(bibliographyOrders, orderBy) {
+		return errorResult(fmt.Errorf("unsupported order_by: %s (supported: 'citekey', 'author', 'year', 'date_added')", query.OrderBy), models.ErrorInvalidInput), nil, nil
+	}
+	if query.Merge && query.OutputPath == "" {
+		return errorResult(errors.New("merge requires output_path"), models.ErrorInvalidInput), nil, nil
+	}
+	var outputPath string
+	if query.OutputPath != "" {
+		var err error
+		outputPath, err = resolveOutputPath(query.OutputPath)
+		if err != nil {
+			log.Error("Rejected output path %s: %v", query.OutputPath, err)
+			return errorResult(err, models.ErrorInvalidInput), nil, nil
+		}
+	}
+
+	ctx, err := libraryContext(ctx, query.Library)
+	if err != nil {
+		return errorResult(err, models.ErrorInvalidInput), nil, nil
 	}
 
 	// Determine which documents to export
 	var documentIDs []string
-	if len(query.DocumentIDs) > 0 {
+	var collection *operations.CollectionDocumentsResult
+	if query.Collection != "" {
+		var err error
+		collection, err = exportCollectionDocuments(ctx, query, store, log)
+		if err != nil {
+			return errorResult(err, models.ErrorUpstreamZotero), nil, nil
+		}
+		seen := make(map[string]bool)
+		for _, attachment := range collection.Parsed {
+			if !seen[attachment.DocumentID] {
+				seen[attachment.DocumentID] = true
+				documentIDs = append(documentIDs, attachment.DocumentID)
+			}
+		}
+		log.Info("Exporting %d documents from collection %s", len(documentIDs), collection.Collection.Key)
+	} else if len(query.DocumentIDs) > 0 {
 		// Export specific documents
-		documentIDs = query.DocumentIDs
+		for _, docID := range query.DocumentIDs {
+			documentIDs = append(documentIDs, storage.LibraryDocumentID(query.Library, docID))
+		}
 		log.Info("Exporting %d specific documents", len(documentIDs))
 	} else {
 		// Export entire library
 		log.Info("Exporting entire library")
-		docInfos, err := store.ListDocuments(ctx)
+		docInfos, err := store.ListDocuments(ctx, query.Library)
 		if err != nil {
 			log.Error("Failed to list documents: %v", err)
-			return nil, nil, fmt.Errorf("failed to list documents: %w", err)
+			return errorResult(fmt.Errorf("failed to list documents: %w", err), models.ErrorStorage), nil, nil
 		}
 		for _, docInfo := range docInfos {
 			documentIDs = append(documentIDs, docInfo.DocumentID)
@@ -72,7 +159,7 @@ func BibliographyExportToolHandler(ctx context.Context, req *mcp.CallToolRequest
 	}
 
 	// Generate BibTeX entries for each document
-	var entries []string
+	var entries []bibliographyEntry
 	var missingCitekey []string
 
 	for _, docID := range documentIDs {
@@ -80,7 +167,7 @@ func BibliographyExportToolHandler(ctx context.Context, req *mcp.CallToolRequest
 		metadata, err := store.GetMetadata(ctx, docID)
 		if err != nil {
 			log.Error("Failed to get metadata for document %s: %v", docID, err)
-			return nil, nil, fmt.Errorf("failed to get metadata for document %s: %w", docID, err)
+			return errorResult(fmt.Errorf("failed to get metadata for document %s: %w", docID, err), models.ErrorStorage), nil, nil
 		}
 
 		// Check if citekey exists
@@ -92,21 +179,175 @@ func BibliographyExportToolHandler(ctx context.Context, req *mcp.CallToolRequest
 
 		// Generate BibTeX entry
 		entry := citations.GenerateBibTeXEntry(docID, metadata, metadata.Citekey)
-		entries = append(entries, entry)
+		entries = append(entries, bibliographyEntry{docID: docID, metadata: metadata, entry: entry})
 		log.Info("Generated BibTeX entry for %s (citekey: %s)", docID, metadata.Citekey)
 	}
 
-	// Generate complete BibTeX file
-	bibContent := citations.GenerateBibTeXFile(entries)
-
-	log.Info("Successfully generated BibTeX file with %d entries", len(entries))
+	if orderBy != "" {
+		if err := sortBibliography(ctx, entries, orderBy, store); err != nil {
+			log.Error("Failed to order bibliography: %v", err)
+			return errorResult(err, models.ErrorStorage), nil, nil
+		}
+	}
 
 	responseData := &BibliographyExportResponse{
 		Format:         format,
-		Content:        bibContent,
-		DocumentCount:  len(entries),
 		MissingCitekey: missingCitekey,
 	}
 
+	if outputPath == "" {
+		// Generate complete BibTeX file
+		responseData.Content = citations.GenerateBibTeXFile(entryTexts(entries))
+		responseData.DocumentCount = len(entries)
+		log.Info("Successfully generated BibTeX file with %d entries", len(entries))
+	} else {
+		written, existing, err := writeBibliography(outputPath, entries, query.Merge)
+		if err != nil {
+			log.Error("Failed to write bibliography to %s: %v", outputPath, err)
+			return errorResult(err, models.ErrorStorage), nil, nil
+		}
+		responseData.WrittenPath = outputPath
+		responseData.DocumentCount = written
+		responseData.ExistingCitekey = existing
+		log.Info("Wrote %d BibTeX entries to %s (%d already present)", written, outputPath, len(existing))
+	}
+
+	if collection != nil {
+		responseData.Collection = collection.Collection.Key
+		for _, attachment := range collection.Unparsed {
+			responseData.Unparsed = append(responseData.Unparsed, UnparsedAttachment{
+				ItemKey:       attachment.ItemKey,
+				AttachmentKey: attachment.AttachmentKey,
+				Title:         attachment.Title,
+				ContentType:   attachment.ContentType,
+				CollectionKey: attachment.CollectionKey,
+			})
+		}
+	}
+
 	return nil, responseData, nil
 }
+
+// exportCollectionDocuments finds the parsed and unparsed attachments of the
+// Zotero collection named in the query
+func exportCollectionDocuments(ctx context.Context, query BibliographyExportQuery, store storage.Store, log logger.Logger) (*operations.CollectionDocumentsResult, error) {
+	zoteroAPIKey, err := config.FromContext(ctx).ZoteroAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	library, err := documents.ResolveZoteroLibrary(ctx, query.LibraryType, query.LibraryID)
+	if err != nil {
+		return nil, models.WithErrorCode(models.ErrorInvalidInput, err)
+	}
+	return operations.CollectionDocuments(ctx, zoteroAPIKey, library, operations.CollectionDocumentsParams{
+		Collection: query.Collection,
+		Recursive:  query.Recursive,
+	}, store, log)
+}
+
+// sortBibliography orders entries by orderBy, breaking ties by citekey so the
+// order is the same across exports. Undated documents sort after dated ones.
+func sortBibliography(ctx context.Context, entries []bibliographyEntry, orderBy string, store storage.Store) error {
+	var added map[string]string
+	if orderBy == "date_added" {
+		docInfos, err := store.ListDocuments(ctx, "")
+		if err != nil {
+			return fmt.Errorf("failed to list documents: %w", err)
+		}
+		added = make(map[string]string, len(docInfos))
+		for _, info := range docInfos {
+			added[info.DocumentID] = info.Provenance.CreatedAt
+		}
+	}
+
+	firstAuthor := func(e bibliographyEntry) string {
+		if len(e.metadata.Authors) == 0 {
+			return ""
+		}
+		return strings.ToLower(citations.ParseAuthor(e.metadata.Authors[0]).Family)
+	}
+	// Empty values sort last
+	compareSet := func(a, b string) int {
+		if (a == "") != (b == "") {
+			if a == "" {
+				return 1
+			}
+			return -1
+		}
+		return cmp.Compare(a, b)
+	}
+	year := func(e bibliographyEntry) string {
+		return citations.ExtractYear(e.metadata.PublicationDate)
+	}
+
+	slices.SortStableFunc(entries, func(a, b bibliographyEntry) int {
+		var c int
+		switch orderBy {
+		case "author":
+			c = cmp.Or(compareSet(firstAuthor(a), firstAuthor(b)), compareSet(year(a), year(b)))
+		case "year":
+			c = compareSet(year(a), year(b))
+		case "date_added":
+			c = compareSet(added[a.docID], added[b.docID])
+		}
+		return cmp.Or(c, cmp.Compare(strings.ToLower(a.metadata.Citekey), strings.ToLower(b.metadata.Citekey)), cmp.Compare(a.metadata.Citekey, b.metadata.Citekey))
+	})
+	return nil
+}
+
+// entryTexts returns the BibTeX text of each entry
+func entryTexts(entries []bibliographyEntry) []string {
+	texts := make([]string, len(entries))
+	for i, e := range entries {
+		texts[i] = e.entry
+	}
+	return texts
+}
+
+// writeBibliography writes entries to the .bib file at path, returning how
+// many were written. With merge, an existing file is kept and only the entries
+// whose citekeys it lacks (compared ignoring case, as BibTeX does) are
+// appended; their citekeys are returned as existing.
+func writeBibliography(path string, entries []bibliographyEntry, merge bool) (int, []string, error) {
+	if !merge {
+		if err := os.WriteFile(path, []byte(citations.GenerateBibTeXFile(entryTexts(entries))), 0o644); err != nil {
+			return 0, nil, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		return len(entries), nil, nil
+	}
+
+	current, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return writeBibliography(path, entries, false)
+	}
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	present := make(map[string]bool)
+	for _, key := range citations.ScanBibTeXKeys(string(current)) {
+		present[strings.ToLower(key)] = true
+	}
+	var added []bibliographyEntry
+	var existing []string
+	for _, e := range entries {
+		if present[strings.ToLower(e.metadata.Citekey)] {
+			existing = append(existing, e.metadata.Citekey)
+			continue
+		}
+		added = append(added, e)
+	}
+	if len(added) == 0 {
+		return 0, existing, nil
+	}
+
+	content := string(current)
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	content += "\n" + strings.Join(entryTexts(added), "\n")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return 0, nil, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return len(added), existing, nil
+}
@@ -2,9 +2,15 @@ package tools
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
+	"github.com/Epistemic-Technology/academic-mcp/internal/citations"
 	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
 	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
 	"github.com/Epistemic-Technology/academic-mcp/models"
@@ -158,13 +164,14 @@ func TestBibliographyExportToolHandler(t *testing.T) {
 			Format:      "endnote",
 		}
 
-		_, _, err := BibliographyExportToolHandler(ctx, nil, query, store, log)
-		if err == nil {
-			t.Error("Expected error for unsupported format, got nil")
+		result, _, err := BibliographyExportToolHandler(ctx, nil, query, store, log)
+		if err != nil {
+			t.Fatalf("Expected an error result, got error: %v", err)
 		}
 
-		if !strings.Contains(err.Error(), "unsupported format") {
-			t.Errorf("Expected 'unsupported format' error, got: %v", err)
+		toolErr := resultError(t, result)
+		if toolErr.Code != models.ErrorInvalidInput || !strings.Contains(toolErr.Message, "unsupported format") {
+			t.Errorf("Expected invalid_input 'unsupported format' error, got: %+v", toolErr)
 		}
 	})
 
@@ -174,9 +181,13 @@ func TestBibliographyExportToolHandler(t *testing.T) {
 			Format:      "bibtex",
 		}
 
-		_, _, err := BibliographyExportToolHandler(ctx, nil, query, store, log)
-		if err == nil {
-			t.Error("Expected error for nonexistent document, got nil")
+		result, _, err := BibliographyExportToolHandler(ctx, nil, query, store, log)
+		if err != nil {
+			t.Fatalf("Expected an error result, got error: %v", err)
+		}
+
+		if toolErr := resultError(t, result); toolErr.Code != models.ErrorNotFound {
+			t.Errorf("Expected not_found error for nonexistent document, got: %+v", toolErr)
 		}
 	})
 
@@ -196,3 +207,217 @@ func TestBibliographyExportToolHandler(t *testing.T) {
 		}
 	})
 }
+
+func TestBibliographyExportToolHandler_OrderAndOutput(t *testing.T) {
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(":memory:", log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	for _, doc := range []struct {
+		docID string
+		meta  models.ItemMetadata
+	}{
+		{"doc-b", models.ItemMetadata{Title: "Later Work", Authors: []string{"Abbott, Ann"}, PublicationDate: "2021", Citekey: "abbott2021"}},
+		{"doc-c", models.ItemMetadata{Title: "Undated Work", Authors: []string{"Zhou, Li"}, Citekey: "zhou"}},
+		{"doc-a", models.ItemMetadata{Title: "Early Work", Authors: []string{"Moore, Mary"}, PublicationDate: "1999", Citekey: "moore1999"}},
+		{"doc-d", models.ItemMetadata{Title: "Earlier Work", Authors: []string{"Abbott, Ann"}, PublicationDate: "2010", Citekey: "Abbott2010"}},
+	} {
+		if err := store.StoreParsedItem(ctx, doc.docID, &models.ParsedItem{Metadata: doc.meta, Pages: []string{"Text"}}, &models.SourceInfo{}); err != nil {
+			t.Fatalf("Failed to store %s: %v", doc.docID, err)
+		}
+	}
+	all := []string{"doc-b", "doc-c", "doc-a", "doc-d"}
+
+	for _, tt := range []struct {
+		orderBy string
+		want    []string
+	}{
+		{"citekey", []string{"Abbott2010", "abbott2021", "moore1999", "zhou"}},
+		{"author", []string{"Abbott2010", "abbott2021", "moore1999", "zhou"}},
+		{"year", []string{"moore1999", "Abbott2010", "abbott2021", "zhou"}},
+		{"", []string{"abbott2021", "zhou", "moore1999", "Abbott2010"}},
+	} {
+		t.Run("order by "+tt.orderBy, func(t *testing.T) {
+			_, response, err := BibliographyExportToolHandler(ctx, nil, BibliographyExportQuery{DocumentIDs: all, OrderBy: tt.orderBy}, store, log)
+			if err != nil {
+				t.Fatalf("BibliographyExportToolHandler failed: %v", err)
+			}
+			if got := citations.ScanBibTeXKeys(response.Content); !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("unsupported order", func(t *testing.T) {
+		result, _, _ := BibliographyExportToolHandler(ctx, nil, BibliographyExportQuery{OrderBy: "title"}, store, log)
+		if toolErr := resultError(t, result); toolErr.Code != models.ErrorInvalidInput {
+			t.Errorf("Expected invalid_input, got %+v", toolErr)
+		}
+	})
+
+	dir := t.TempDir()
+	t.Setenv(exportDirEnv, dir)
+
+	t.Run("merge requires output path", func(t *testing.T) {
+		result, _, _ := BibliographyExportToolHandler(ctx, nil, BibliographyExportQuery{Merge: true}, store, log)
+		if toolErr := resultError(t, result); toolErr.Code != models.ErrorInvalidInput {
+			t.Errorf("Expected invalid_input, got %+v", toolErr)
+		}
+	})
+
+	t.Run("write and merge", func(t *testing.T) {
+		_, response, err := BibliographyExportToolHandler(ctx, nil, BibliographyExportQuery{DocumentIDs: []string{"doc-a", "doc-b"}, OrderBy: "citekey", OutputPath: "library.bib"}, store, log)
+		if err != nil {
+			t.Fatalf("BibliographyExportToolHandler failed: %v", err)
+		}
+		path := filepath.Join(dir, "library.bib")
+		if response.Content != "" || response.WrittenPath == "" || response.DocumentCount != 2 {
+			t.Errorf("Expected only a summary of the written file, got %+v", response)
+		}
+		written, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read written file: %v", err)
+		}
+		if got := citations.ScanBibTeXKeys(string(written)); !slices.Equal(got, []string{"abbott2021", "moore1999"}) {
+			t.Errorf("Unexpected written entries %q", got)
+		}
+
+		// An entry edited by hand in the file is kept as it is
+		edited := strings.Replace(string(written), "Early Work", "Early Work, revised", 1)
+		if err := os.WriteFile(path, []byte(edited), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		_, response, err = BibliographyExportToolHandler(ctx, nil, BibliographyExportQuery{DocumentIDs: all, OrderBy: "citekey", OutputPath: "library.bib", Merge: true}, store, log)
+		if err != nil {
+			t.Fatalf("BibliographyExportToolHandler failed: %v", err)
+		}
+		if response.DocumentCount != 2 || !slices.Equal(response.ExistingCitekey, []string{"abbott2021", "moore1999"}) {
+			t.Errorf("Expected two entries added and two already present, got %+v", response)
+		}
+		merged, _ := os.ReadFile(path)
+		if !strings.HasPrefix(string(merged), edited) || !strings.Contains(string(merged), "Early Work, revised") {
+			t.Errorf("Expected the existing file to be kept, got:\n%s", merged)
+		}
+		if got := citations.ScanBibTeXKeys(string(merged)); !slices.Equal(got, []string{"abbott2021", "moore1999", "Abbott2010", "zhou"}) {
+			t.Errorf("Unexpected merged entries %q", got)
+		}
+
+		// Merging again adds nothing
+		_, response, _ = BibliographyExportToolHandler(ctx, nil, BibliographyExportQuery{DocumentIDs: all, OutputPath: "library.bib", Merge: true}, store, log)
+		if response.DocumentCount != 0 || len(response.ExistingCitekey) != 4 {
+			t.Errorf("Expected nothing added, got %+v", response)
+		}
+	})
+
+	t.Run("output outside export dir", func(t *testing.T) {
+		result, _, _ := BibliographyExportToolHandler(ctx, nil, BibliographyExportQuery{OutputPath: "../escape.bib"}, store, log)
+		if toolErr := resultError(t, result); toolErr.Code != models.ErrorInvalidInput {
+			t.Errorf("Expected invalid_input, got %+v", toolErr)
+		}
+	})
+}
+
+func TestBibliographyExportToolHandler_Collection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/collections"):
+			w.Write([]byte(`[
+				{"key":"COLL1","data":{"key":"COLL1","name":"Thesis","parentCollection":false}},
+				{"key":"SUB1","data":{"key":"SUB1","name":"Chapter 1","parentCollection":"COLL1"}},
+				{"key":"DUP1","data":{"key":"DUP1","name":"Reading","parentCollection":false}},
+				{"key":"DUP2","data":{"key":"DUP2","name":"reading","parentCollection":false}}
+			]`))
+		case strings.HasSuffix(r.URL.Path, "/collections/COLL1/items"):
+			w.Write([]byte(`[{"key":"ITEM1","data":{"key":"ITEM1","itemType":"journalArticle","title":"Parsed Paper"}}]`))
+		case strings.HasSuffix(r.URL.Path, "/collections/SUB1/items"):
+			w.Write([]byte(`[{"key":"ITEM2","data":{"key":"ITEM2","itemType":"book","title":"Unparsed Book"}}]`))
+		case strings.HasSuffix(r.URL.Path, "/items/ITEM1/children"):
+			w.Write([]byte(`[{"key":"ATT1","data":{"key":"ATT1","itemType":"attachment","contentType":"application/pdf"}}]`))
+		case strings.HasSuffix(r.URL.Path, "/items/ITEM2/children"):
+			w.Write([]byte(`[{"key":"ATT2","data":{"key":"ATT2","itemType":"attachment","contentType":"application/epub+zip"}}]`))
+		default:
+			w.Write([]byte(`[]`))
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("ZOTERO_API_BASE_URL", server.URL)
+	t.Setenv("ZOTERO_API_KEY", "test-key")
+	t.Setenv("ZOTERO_LIBRARY_TYPE", "user")
+	t.Setenv("ZOTERO_LIBRARY_ID", "111")
+
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(":memory:", log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	item := &models.ParsedItem{Metadata: models.ItemMetadata{Title: "Parsed Paper", Authors: []string{"Smith, John"}, Citekey: "smith2020"}}
+	if err := store.StoreParsedItem(ctx, "zotero_ATT1", item, &models.SourceInfo{ZoteroID: "ATT1"}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+	other := &models.ParsedItem{Metadata: models.ItemMetadata{Title: "Not In Collection", Citekey: "other2021"}}
+	if err := store.StoreParsedItem(ctx, "other-doc", other, &models.SourceInfo{}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+
+	tests := []struct {
+		name             string
+		query            BibliographyExportQuery
+		expectedUnparsed []string
+	}{
+		{"By name", BibliographyExportQuery{Collection: "thesis"}, nil},
+		{"By key", BibliographyExportQuery{Collection: "COLL1"}, nil},
+		{"Recursive", BibliographyExportQuery{Collection: "Thesis", Recursive: true}, []string{"ATT2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, resp, err := BibliographyExportToolHandler(ctx, nil, tt.query, store, log)
+			if err != nil || result != nil {
+				t.Fatalf("BibliographyExportToolHandler failed: %v %+v", err, result)
+			}
+			if resp.Collection != "COLL1" {
+				t.Errorf("Expected collection COLL1, got %q", resp.Collection)
+			}
+			if resp.DocumentCount != 1 || !strings.Contains(resp.Content, "smith2020") || strings.Contains(resp.Content, "other2021") {
+				t.Errorf("Expected only the collection's parsed document, got %d: %s", resp.DocumentCount, resp.Content)
+			}
+			if len(resp.Unparsed) != len(tt.expectedUnparsed) {
+				t.Fatalf("Expected unparsed %v, got %+v", tt.expectedUnparsed, resp.Unparsed)
+			}
+			for i, key := range tt.expectedUnparsed {
+				if resp.Unparsed[i].AttachmentKey != key || resp.Unparsed[i].ItemKey != "ITEM2" || resp.Unparsed[i].CollectionKey != "SUB1" {
+					t.Errorf("Unexpected unparsed attachment: %+v", resp.Unparsed[i])
+				}
+			}
+		})
+	}
+
+	errorTests := []struct {
+		name     string
+		query    BibliographyExportQuery
+		expected models.ErrorCode
+	}{
+		{"Unknown collection", BibliographyExportQuery{Collection: "Nothing"}, models.ErrorNotFound},
+		{"Ambiguous name", BibliographyExportQuery{Collection: "Reading"}, models.ErrorInvalidInput},
+		{"Both document IDs and collection", BibliographyExportQuery{Collection: "Thesis", DocumentIDs: []string{"other-doc"}}, models.ErrorInvalidInput},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := BibliographyExportToolHandler(ctx, nil, tt.query, store, log)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if toolErr := resultError(t, result); toolErr.Code != tt.expected {
+				t.Errorf("Expected code %s, got %+v", tt.expected, toolErr)
+			}
+		})
+	}
+}
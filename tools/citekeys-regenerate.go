@@ -0,0 +1,78 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/citations"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/operations"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type CitekeysRegenerateQuery struct {
+	// Document library of this server to regenerate the citekeys of (not a
+	// Zotero library); empty for every library
+	Library string `json:"library,omitempty"`
+	// "unicode", "ascii", or "ascii-german" (default: ACADEMIC_MCP_CITEKEY_MODE)
+	Mode  string `json:"mode,omitempty"`
+	Apply bool   `json:"apply,omitempty"` // Store the new citekeys; without it only the changes are reported
+}
+
+type CitekeysRegenerateResponse struct {
+	Mode           string                 `json:"mode"`
+	Changes        []models.CitekeyChange `json:"changes"`
+	ChangeCount    int                    `json:"change_count"`
+	UnchangedCount int                    `json:"unchanged_count"`
+	// Documents whose citekeys were not generated (set by hand or imported),
+	// which are kept
+	CustomDocumentIDs []string `json:"custom_document_ids,omitempty"`
+	Applied           bool     `json:"applied"`
+}
+
+func CitekeysRegenerateTool() *mcp.Tool {
+	inputschema, err := jsonschema.For[CitekeysRegenerateQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+	return &mcp.Tool{
+		Name:        "citekeys-regenerate",
+		Description: "Generate the citekeys of a library's stored documents again in a citekey mode: unicode keeps accented letters (garcía2020), ascii folds them to ASCII (garcia2020, muller2019), and ascii-german writes umlauts as ae, oe, and ue (mueller2019). Only citekeys generated for a document's current metadata are replaced; citekeys set by hand or imported are kept and listed. Without apply, nothing is changed and the changes are only reported, so run it once to review them and again with apply to store them. Citekeys already in the mode's form are kept, and new ones do not collide with them. Documents with a changed citekey must be cited by the new one. The mode defaults to ACADEMIC_MCP_CITEKEY_MODE, which also sets the mode of newly generated citekeys.",
+		InputSchema: inputschema,
+	}
+}
+
+func CitekeysRegenerateToolHandler(ctx context.Context, req *mcp.CallToolRequest, query CitekeysRegenerateQuery, store storage.Store, log logger.Logger) (*mcp.CallToolResult, *CitekeysRegenerateResponse, error) {
+	log.Info("citekeys-regenerate tool called")
+
+	if err := validateLibrary(query.Library); err != nil {
+		return errorResult(err, models.ErrorInvalidInput), nil, nil
+	}
+	mode, err := citations.ConfiguredCitekeyMode()
+	if query.Mode != "" {
+		mode, err = citations.ParseCitekeyMode(query.Mode)
+		if err != nil {
+			return errorResult(err, models.ErrorInvalidInput), nil, nil
+		}
+	} else if err != nil {
+		log.Warn("Using the default citekey mode: %v", err)
+	}
+
+	result, err := operations.RegenerateCitekeys(ctx, store, query.Library, mode, query.Apply, log)
+	if err != nil {
+		log.Error("Failed to regenerate citekeys: %v", err)
+		return errorResult(err, models.ErrorStorage), nil, nil
+	}
+
+	log.Info("%d citekeys change in %s mode (applied: %v)", len(result.Changes), mode, result.Applied)
+	return nil, &CitekeysRegenerateResponse{
+		Mode:              string(mode),
+		Changes:           result.Changes,
+		ChangeCount:       len(result.Changes),
+		UnchangedCount:    result.Unchanged,
+		CustomDocumentIDs: result.Custom,
+		Applied:           result.Applied,
+	}, nil
+}
@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestCitekeysRegenerateToolHandler(t *testing.T) {
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(":memory:", log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	item := &models.ParsedItem{Metadata: models.ItemMetadata{Title: "Aufsätze", Authors: []string{"Müller, Hans"}, PublicationDate: "2019", Citekey: "müller2019"}}
+	if err := store.StoreParsedItem(ctx, "doc-1", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+
+	// The configured mode is the default, and nothing is changed without apply
+	t.Setenv("ACADEMIC_MCP_CITEKEY_MODE", "ascii")
+	result, response, err := CitekeysRegenerateToolHandler(ctx, nil, CitekeysRegenerateQuery{}, store, log)
+	if err != nil || result != nil {
+		t.Fatalf("CitekeysRegenerateToolHandler failed: %+v, %v", result, err)
+	}
+	if response.Mode != "ascii" || response.ChangeCount != 1 || response.Changes[0].NewCitekey != "muller2019" || response.Applied {
+		t.Errorf("Expected one unapplied change to muller2019, got %+v", response)
+	}
+	if docID, err := store.GetDocumentByCitekey(ctx, "", "müller2019"); err != nil || docID != "doc-1" {
+		t.Errorf("Expected the citekey to be unchanged, got %s, %v", docID, err)
+	}
+
+	_, response, err = CitekeysRegenerateToolHandler(ctx, nil, CitekeysRegenerateQuery{Mode: "ascii-german", Apply: true}, store, log)
+	if err != nil || response == nil {
+		t.Fatalf("CitekeysRegenerateToolHandler failed: %v", err)
+	}
+	if !response.Applied || response.ChangeCount != 1 || response.Changes[0].NewCitekey != "mueller2019" {
+		t.Errorf("Expected the change to mueller2019 to be applied, got %+v", response)
+	}
+	if docID, err := store.GetDocumentByCitekey(ctx, "", "mueller2019"); err != nil || docID != "doc-1" {
+		t.Errorf("Expected the new citekey to resolve to doc-1, got %s, %v", docID, err)
+	}
+
+	tests := []struct {
+		name  string
+		query CitekeysRegenerateQuery
+	}{
+		{"unknown mode", CitekeysRegenerateQuery{Mode: "latin1"}},
+		{"invalid library", CitekeysRegenerateQuery{Library: "Not A Library"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := CitekeysRegenerateToolHandler(ctx, nil, tt.query, store, log)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if code := resultError(t, result).Code; code != models.ErrorInvalidInput {
+				t.Errorf("Expected error code %s, got %s", models.ErrorInvalidInput, code)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type DocumentAnnotateQuery struct {
+	Action         string `json:"action"` // "create", "list", "update", or "delete"
+	DocumentID     string `json:"document_id,omitempty"`
+	Citekey        string `json:"citekey,omitempty"`         // Alternative to document_id
+	AnnotationID   int64  `json:"annotation_id,omitempty"`   // Annotation to update or delete
+	Text           string `json:"text,omitempty"`            // Note text (create and update)
+	PageNumber     string `json:"page_number,omitempty"`     // Source page number the note is about (create, optional)
+	QuotationIndex *int   `json:"quotation_index,omitempty"` // Quotation (0-indexed) the note is about (create, optional)
+}
+
+type DocumentAnnotateResponse struct {
+	DocumentID  string              `json:"document_id"`
+	Action      string              `json:"action"`
+	Annotation  *models.Annotation  `json:"annotation,omitempty"`  // Created or updated annotation
+	Annotations []models.Annotation `json:"annotations,omitempty"` // All annotations (list)
+	Count       int                 `json:"count"`                 // Annotations on the document after the action
+}
+
+func DocumentAnnotateTool() *mcp.Tool {
+	inputschema, err := jsonschema.For[DocumentAnnotateQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+	return &mcp.Tool{
+		Name:        "document-annotate",
+		Description: "Record your own reading notes on a previously parsed document, identified by document_id or citekey. The action is create (text, optionally tied to a source page_number or a quotation_index), list, update (annotation_id and new text), or delete (annotation_id). Annotations are kept when the document is re-parsed and can be read at pdf://{documentId}/annotations.",
+		InputSchema: inputschema,
+	}
+}
+
+func DocumentAnnotateToolHandler(ctx context.Context, req *mcp.CallToolRequest, query DocumentAnnotateQuery, store storage.Store, log logger.Logger) (*mcp.CallToolResult, *DocumentAnnotateResponse, error) {
+	log.Info("document-annotate tool called")
+
+	action := strings.ToLower(strings.TrimSpace(query.Action))
+	text := strings.TrimSpace(query.Text)
+	switch action {
+	case "create":
+		if text == "" {
+			return nil, nil, errors.New("text is required to create an annotation")
+		}
+	case "update":
+		if query.AnnotationID == 0 || text == "" {
+			return nil, nil, errors.New("annotation_id and text are required to update an annotation")
+		}
+	case "delete":
+		if query.AnnotationID == 0 {
+			return nil, nil, errors.New("annotation_id is required to delete an annotation")
+		}
+	case "list":
+	default:
+		return nil, nil, fmt.Errorf("unsupported action: %q (supported: 'create', 'list', 'update', 'delete')", query.Action)
+	}
+
+	docID, err := resolveDocumentID(ctx, store, "", query.DocumentID, query.Citekey)
+	if err != nil {
+		log.Error("Failed to resolve document: %v", err)
+		return nil, nil, err
+	}
+
+	response := &DocumentAnnotateResponse{DocumentID: docID, Action: action}
+	switch action {
+	case "create":
+		if err := validateAnnotationTarget(ctx, store, docID, query.PageNumber, query.QuotationIndex); err != nil {
+			return nil, nil, err
+		}
+		response.Annotation, err = store.AddAnnotation(ctx, models.Annotation{
+			DocumentID:     docID,
+			PageNumber:     query.PageNumber,
+			QuotationIndex: query.QuotationIndex,
+			Text:           text,
+		})
+	case "update":
+		response.Annotation, err = store.UpdateAnnotation(ctx, docID, query.AnnotationID, text)
+	case "delete":
+		err = store.DeleteAnnotation(ctx, docID, query.AnnotationID)
+	}
+	if err != nil {
+		log.Error("Failed to %s annotation on %s: %v", action, docID, err)
+		return nil, nil, err
+	}
+
+	annotations, err := store.GetAnnotations(ctx, docID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if action == "list" {
+		response.Annotations = annotations
+	}
+	response.Count = len(annotations)
+
+	log.Info("document-annotate %s on %s: %d annotations", action, docID, response.Count)
+	return nil, response, nil
+}
+
+// validateAnnotationTarget checks that the page and quotation a new annotation is
+// tied to exist in the document
+func validateAnnotationTarget(ctx context.Context, store storage.Store, docID string, pageNumber string, quotationIndex *int) error {
+	if pageNumber != "" {
+		mapping, err := store.GetPageMapping(ctx, docID)
+		if err != nil {
+			return err
+		}
+		if _, ok := mapping[pageNumber]; !ok {
+			return fmt.Errorf("page %s not found in document %s", pageNumber, docID)
+		}
+	}
+	if quotationIndex != nil {
+		quotations, err := store.GetQuotations(ctx, docID)
+		if err != nil {
+			return err
+		}
+		if *quotationIndex < 0 || *quotationIndex >= len(quotations) {
+			return fmt.Errorf("quotation %d not found in document %s (it has %d quotations)", *quotationIndex, docID, len(quotations))
+		}
+	}
+	return nil
+}
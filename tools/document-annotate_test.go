@@ -0,0 +1,92 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestDocumentAnnotateToolHandler(t *testing.T) {
+	store, err := storage.NewSQLiteStore(":memory:", logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	item := &models.ParsedItem{
+		Metadata:    models.ItemMetadata{Title: "Annotated Paper", Citekey: "doe2021"},
+		Pages:       []string{"First page.", "Second page."},
+		PageNumbers: []string{"11", "12"},
+		Quotations:  []models.Quotation{{QuotationText: "A quotation", PageNumber: "12"}},
+	}
+	if err := store.StoreParsedItem(ctx, "doc-1", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+	log := logger.NewNoOpLogger()
+
+	quotation := 0
+	_, created, err := DocumentAnnotateToolHandler(ctx, nil, DocumentAnnotateQuery{
+		Action: "create", Citekey: "doe2021", Text: "  Key claim  ", PageNumber: "12", QuotationIndex: &quotation,
+	}, store, log)
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if created.DocumentID != "doc-1" || created.Annotation.Text != "Key claim" || created.Count != 1 {
+		t.Errorf("Unexpected create response: %+v", created)
+	}
+
+	_, updated, err := DocumentAnnotateToolHandler(ctx, nil, DocumentAnnotateQuery{
+		Action: "update", DocumentID: "doc-1", AnnotationID: created.Annotation.ID, Text: "Key claim, revisited",
+	}, store, log)
+	if err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if updated.Annotation.Text != "Key claim, revisited" || updated.Annotation.PageNumber != "12" {
+		t.Errorf("Unexpected update response: %+v", updated.Annotation)
+	}
+
+	_, listed, err := DocumentAnnotateToolHandler(ctx, nil, DocumentAnnotateQuery{Action: "list", DocumentID: "doc-1"}, store, log)
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if listed.Count != 1 || len(listed.Annotations) != 1 || listed.Annotations[0].Text != "Key claim, revisited" {
+		t.Errorf("Unexpected list response: %+v", listed)
+	}
+
+	_, deleted, err := DocumentAnnotateToolHandler(ctx, nil, DocumentAnnotateQuery{Action: "delete", DocumentID: "doc-1", AnnotationID: created.Annotation.ID}, store, log)
+	if err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if deleted.Count != 0 {
+		t.Errorf("Expected no annotations after delete, got %d", deleted.Count)
+	}
+
+	outOfRange := 3
+	errorTests := []struct {
+		name          string
+		query         DocumentAnnotateQuery
+		expectedError string
+	}{
+		{"unknown action", DocumentAnnotateQuery{Action: "archive", DocumentID: "doc-1"}, "unsupported action"},
+		{"create without text", DocumentAnnotateQuery{Action: "create", DocumentID: "doc-1", Text: " "}, "text is required"},
+		{"update without ID", DocumentAnnotateQuery{Action: "update", DocumentID: "doc-1", Text: "x"}, "annotation_id and text are required"},
+		{"delete without ID", DocumentAnnotateQuery{Action: "delete", DocumentID: "doc-1"}, "annotation_id is required"},
+		{"unknown document", DocumentAnnotateQuery{Action: "list", DocumentID: "missing"}, "document not found"},
+		{"unknown page", DocumentAnnotateQuery{Action: "create", DocumentID: "doc-1", Text: "x", PageNumber: "99"}, "page 99 not found"},
+		{"quotation out of range", DocumentAnnotateQuery{Action: "create", DocumentID: "doc-1", Text: "x", QuotationIndex: &outOfRange}, "quotation 3 not found"},
+		{"unknown annotation", DocumentAnnotateQuery{Action: "delete", DocumentID: "doc-1", AnnotationID: 42}, "annotation 42 not found"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := DocumentAnnotateToolHandler(ctx, nil, tt.query, store, log)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
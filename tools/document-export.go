@@ -0,0 +1,198 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// exportDirEnv names the directory that exported files may be written under
+const exportDirEnv = "ACADEMIC_MCP_EXPORT_DIR"
+
+type DocumentExportQuery struct {
+	DocumentID string `json:"document_id,omitempty"`
+	Citekey    string `json:"citekey,omitempty"`     // Alternative to document_id
+	Format     string `json:"format,omitempty"`      // "markdown" (default) or "json"
+	OutputPath string `json:"output_path,omitempty"` // Optional file to write, relative to ACADEMIC_MCP_EXPORT_DIR
+	// Document library of this server to look document_id or citekey up in (not
+	// a Zotero library); empty takes document_id as given and finds citekey in
+	// any library
+	Library string `json:"library,omitempty"`
+}
+
+type DocumentExportResponse struct {
+	DocumentID  string `json:"document_id"`
+	Citekey     string `json:"citekey,omitempty"`
+	Format      string `json:"format"`
+	Content     string `json:"content"`
+	WrittenPath string `json:"written_path,omitempty"`
+}
+
+// documentExport is the JSON export format: the stored document with its ID
+type documentExport struct {
+	DocumentID string `json:"document_id"`
+	*models.ParsedItem
+}
+
+func DocumentExportTool() *mcp.Tool {
+	inputschema, err := jsonschema.For[DocumentExportQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+	return &mcp.Tool{
+		Name:        "document-export",
+		Description: "Export a previously parsed document, identified by document_id or citekey, as a single file. Citekeys are unique within each of the server's libraries; set library to look the document up in one. The markdown format (default) has YAML front matter with the metadata and citekey, the page contents separated by page-break comments with source page numbers, tables placed after the page that mentions them, footnotes and endnotes as Markdown footnotes, and the references. The json format returns the stored document as JSON. The content is returned in the response; set output_path to also write it to a file under the directory configured by ACADEMIC_MCP_EXPORT_DIR.",
+		InputSchema: inputschema,
+	}
+}
+
+func DocumentExportToolHandler(ctx context.Context, req *mcp.CallToolRequest, query DocumentExportQuery, store storage.Store, log logger.Logger) (*mcp.CallToolResult, *DocumentExportResponse, error) {
+	log.Info("document-export tool called")
+
+	format := strings.ToLower(query.Format)
+	if format == "" {
+		format = "markdown"
+	}
+	if format != "markdown" && format != "json" {
+		return nil, nil, fmt.Errorf("unsupported format: %s (supported: 'markdown', 'json')", query.Format)
+	}
+
+	if err := validateLibrary(query.Library); err != nil {
+		return nil, nil, err
+	}
+	docID, err := resolveDocumentID(ctx, store, query.Library, query.DocumentID, query.Citekey)
+	if err != nil {
+		log.Error("Failed to resolve document: %v", err)
+		return nil, nil, err
+	}
+
+	item, err := store.GetParsedItem(ctx, docID)
+	if err != nil {
+		log.Error("Failed to retrieve document %s: %v", docID, err)
+		return nil, nil, fmt.Errorf("failed to retrieve document %s: %w", docID, err)
+	}
+
+	var content string
+	switch format {
+	case "markdown":
+		content = documents.ExportMarkdown(docID, item)
+	case "json":
+		data, err := json.MarshalIndent(documentExport{DocumentID: docID, ParsedItem: item}, "", "  ")
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal document: %w", err)
+		}
+		content = string(data) + "\n"
+	}
+
+	response := &DocumentExportResponse{
+		DocumentID: docID,
+		Citekey:    item.Metadata.Citekey,
+		Format:     format,
+		Content:    content,
+	}
+
+	if query.OutputPath != "" {
+		path, err := resolveOutputPath(query.OutputPath)
+		if err != nil {
+			log.Error("Rejected output path %s: %v", query.OutputPath, err)
+			return nil, nil, err
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			log.Error("Failed to write export to %s: %v", path, err)
+			return nil, nil, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		response.WrittenPath = path
+		log.Info("Wrote %s export of document %s to %s", format, docID, path)
+	}
+
+	log.Info("Exported document %s as %s (%d bytes)", docID, format, len(content))
+	return nil, response, nil
+}
+
+// resolveDocumentID returns the ID of the stored document identified by docID
+// or, if docID is empty, by citekey, in library. An empty library takes docID
+// as given and finds citekey in any library.
+func resolveDocumentID(ctx context.Context, store storage.Store, library, docID, citekey string) (string, error) {
+	if docID == "" && citekey == "" {
+		return "", errors.New("document_id or citekey is required")
+	}
+	if docID == "" {
+		return store.GetDocumentByCitekey(ctx, library, citekey)
+	}
+
+	docID = storage.LibraryDocumentID(library, docID)
+
+	exists, err := store.DocumentExists(ctx, docID)
+	if err != nil {
+		return "", fmt.Errorf("failed to check document existence: %w", err)
+	}
+	if !exists {
+		return "", fmt.Errorf("document not found: %s", docID)
+	}
+	return docID, nil
+}
+
+// resolveOutputPath returns the absolute path for writing an export to path,
+// which must lie inside the directory named by ACADEMIC_MCP_EXPORT_DIR. Relative
+// paths are taken relative to that directory, and missing parent directories
+// are created. Symlinks are resolved so they cannot lead outside it.
+func resolveOutputPath(path string) (string, error) {
+	dir := os.Getenv(exportDirEnv)
+	if dir == "" {
+		return "", fmt.Errorf("writing files requires %s to be set to an allowed output directory", exportDirEnv)
+	}
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %w", exportDirEnv, err)
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+	root, err = filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %w", exportDirEnv, err)
+	}
+
+	target := path
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(root, target)
+	}
+	target = filepath.Clean(target)
+	if !isWithin(root, target) {
+		return "", fmt.Errorf("output path %s is outside %s", path, exportDirEnv)
+	}
+
+	parent := filepath.Dir(target)
+	if err := os.MkdirAll(parent, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+	realParent, err := filepath.EvalSymlinks(parent)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve output directory: %w", err)
+	}
+	target = filepath.Join(realParent, filepath.Base(target))
+	if !isWithin(root, target) {
+		return "", fmt.Errorf("output path %s is outside %s", path, exportDirEnv)
+	}
+	if info, err := os.Lstat(target); err == nil && !info.Mode().IsRegular() {
+		return "", fmt.Errorf("output path %s is not a regular file", path)
+	}
+	return target, nil
+}
+
+// isWithin reports whether path is strictly inside dir
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
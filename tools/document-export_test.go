@@ -0,0 +1,140 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestResolveOutputPath(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv(exportDirEnv, root)
+
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		t.Fatalf("Failed to resolve root: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		path          string
+		expected      string
+		expectedError string
+	}{
+		{"relative file", "paper.md", filepath.Join(realRoot, "paper.md"), ""},
+		{"nested directory is created", "exports/2020/paper.md", filepath.Join(realRoot, "exports", "2020", "paper.md"), ""},
+		{"absolute path inside", filepath.Join(root, "abs.md"), filepath.Join(realRoot, "abs.md"), ""},
+		{"parent traversal", "../paper.md", "", "outside"},
+		{"absolute path outside", filepath.Join(outside, "paper.md"), "", "outside"},
+		{"symlink out of the directory", "escape/paper.md", "", "outside"},
+		{"the directory itself", ".", "", "outside"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveOutputPath(tt.path)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("Expected error containing %q, got %q, %v", tt.expectedError, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveOutputPath failed: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestResolveOutputPath_RequiresExportDir(t *testing.T) {
+	t.Setenv(exportDirEnv, "")
+	if _, err := resolveOutputPath("paper.md"); err == nil || !strings.Contains(err.Error(), exportDirEnv) {
+		t.Errorf("Expected error naming %s, got %v", exportDirEnv, err)
+	}
+}
+
+func TestDocumentExportToolHandler(t *testing.T) {
+	store, err := storage.NewSQLiteStore(":memory:", logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	item := &models.ParsedItem{
+		Metadata:    models.ItemMetadata{Title: "Exported Paper", Citekey: "doe2021"},
+		Pages:       []string{"First page.", "Second page."},
+		PageNumbers: []string{"1", "2"},
+	}
+	if err := store.StoreParsedItem(context.Background(), "doc-1", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+
+	root := t.TempDir()
+	t.Setenv(exportDirEnv, root)
+	log := logger.NewNoOpLogger()
+
+	t.Run("markdown by citekey written to file", func(t *testing.T) {
+		_, response, err := DocumentExportToolHandler(context.Background(), nil, DocumentExportQuery{Citekey: "doe2021", OutputPath: "doe2021.md"}, store, log)
+		if err != nil {
+			t.Fatalf("DocumentExportToolHandler failed: %v", err)
+		}
+		if response.DocumentID != "doc-1" || response.Format != "markdown" || !strings.Contains(response.Content, "<!-- page 2 -->") {
+			t.Errorf("Unexpected response: %+v", response)
+		}
+		written, err := os.ReadFile(response.WrittenPath)
+		if err != nil || string(written) != response.Content {
+			t.Errorf("Expected written file to match content, got %q, %v", written, err)
+		}
+	})
+
+	t.Run("json by document ID", func(t *testing.T) {
+		_, response, err := DocumentExportToolHandler(context.Background(), nil, DocumentExportQuery{DocumentID: "doc-1", Format: "JSON"}, store, log)
+		if err != nil {
+			t.Fatalf("DocumentExportToolHandler failed: %v", err)
+		}
+		var decoded struct {
+			DocumentID string   `json:"document_id"`
+			Pages      []string `json:"pages"`
+		}
+		if err := json.Unmarshal([]byte(response.Content), &decoded); err != nil {
+			t.Fatalf("Failed to decode JSON export: %v", err)
+		}
+		if decoded.DocumentID != "doc-1" || len(decoded.Pages) != 2 || response.WrittenPath != "" {
+			t.Errorf("Unexpected JSON export: %+v (written to %q)", decoded, response.WrittenPath)
+		}
+	})
+
+	errorTests := []struct {
+		name          string
+		query         DocumentExportQuery
+		expectedError string
+	}{
+		{"missing identifier", DocumentExportQuery{}, "document_id or citekey is required"},
+		{"unknown document", DocumentExportQuery{DocumentID: "missing"}, "document not found"},
+		{"unknown citekey", DocumentExportQuery{Citekey: "nobody1999"}, "document not found"},
+		{"unsupported format", DocumentExportQuery{DocumentID: "doc-1", Format: "pdf"}, "unsupported format"},
+		{"path outside export directory", DocumentExportQuery{DocumentID: "doc-1", OutputPath: "../out.md"}, "outside"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := DocumentExportToolHandler(context.Background(), nil, tt.query, store, log)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/operations"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type DocumentImportQuery struct {
+	JSON     string `json:"json,omitempty"`     // A parsed document with the fields document-export's json format writes
+	Markdown string `json:"markdown,omitempty"` // Markdown with YAML front matter, as document-export's markdown format writes; alternative to json
+}
+
+type DocumentImportResponse struct {
+	DocumentID      string   `json:"document_id"`
+	Citekey         string   `json:"citekey,omitempty"`
+	Title           string   `json:"title,omitempty"`
+	PageCount       int      `json:"page_count"`
+	RefCount        int      `json:"reference_count"`
+	ResourcePaths   []string `json:"resource_paths"`
+	DuplicateOf     string   `json:"duplicate_of,omitempty"`     // Existing document for the same work, returned instead of storing the import
+	DuplicateMatch  string   `json:"duplicate_match,omitempty"`  // How the duplicate was matched: "doi" or "title_author_year"
+	AlreadyImported bool     `json:"already_imported,omitempty"` // The same content was imported before; the stored document is returned
+}
+
+func DocumentImportTool() *mcp.Tool {
+	inputschema, err := jsonschema.For[DocumentImportQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+	return &mcp.Tool{
+		Name:        "document-import",
+		Description: "Store a document parsed elsewhere, without calling the model. Provide either json, a parsed document with the fields document-export's json format writes (metadata, pages, page_numbers, references, footnotes, endnotes, tables, and so on), or markdown, with YAML front matter for the metadata (title, author, date, citekey, doi, and the other keys document-export writes), pages separated by <!-- page N --> comments, and a final References list. The document needs a title and at least one page with content; identifiers, the date, and the URL must be well formed. Validation failures list each problem by field. A citekey is generated if none is given. The document is stored with metadata_source \"imported\"; importing the same content again returns the stored document, and a work already in the library (matched by DOI, or by title, first author, and year) is returned as duplicate_of instead of being stored.",
+		InputSchema: inputschema,
+	}
+}
+
+func DocumentImportToolHandler(ctx context.Context, req *mcp.CallToolRequest, query DocumentImportQuery, store storage.Store, log logger.Logger) (*mcp.CallToolResult, *DocumentImportResponse, error) {
+	log.Info("document-import tool called")
+
+	hasJSON, hasMarkdown := strings.TrimSpace(query.JSON) != "", strings.TrimSpace(query.Markdown) != ""
+	if hasJSON == hasMarkdown {
+		return errorResult(errors.New("exactly one of json or markdown is required"), models.ErrorInvalidInput), nil, nil
+	}
+
+	var item *models.ParsedItem
+	var payload []byte
+	var err error
+	if hasJSON {
+		payload = []byte(query.JSON)
+		item, err = documents.DecodeImportJSON(payload)
+	} else {
+		payload = []byte(query.Markdown)
+		item, err = documents.ParseMarkdownImport(query.Markdown)
+	}
+	if err != nil {
+		return errorResult(err, models.ErrorInvalidInput), nil, nil
+	}
+
+	result, err := operations.ImportDocument(ctx, store, item, payload, log)
+	if err != nil {
+		log.Error("Failed to import document: %v", err)
+		return errorResult(err, models.ErrorStorage), nil, nil
+	}
+
+	response := &DocumentImportResponse{
+		DocumentID:      result.DocumentID,
+		Citekey:         result.Item.Metadata.Citekey,
+		Title:           result.Item.Metadata.Title,
+		PageCount:       len(result.Item.Pages),
+		RefCount:        len(result.Item.References),
+		ResourcePaths:   storage.CalculateResourcePaths(result.DocumentID, result.Item),
+		AlreadyImported: result.AlreadyImported,
+	}
+	if result.Duplicate != nil {
+		response.DuplicateOf = result.Duplicate.DocumentID
+		response.DuplicateMatch = result.Duplicate.MatchedOn
+	}
+	return nil, response, nil
+}
@@ -0,0 +1,53 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestDocumentImportToolHandler(t *testing.T) {
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(":memory:", log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	markdown := "---\ntitle: \"Field Notes\"\nauthor:\n  - \"Jane Smith\"\ndate: \"2020\"\n---\n\n" +
+		"<!-- page 3 -->\n\nObservations.\n\n<!-- page 4 -->\n\nMore observations.\n\n## References\n\n- Jones, R. (2005). Another.\n"
+	result, resp, err := DocumentImportToolHandler(ctx, nil, DocumentImportQuery{Markdown: markdown}, store, log)
+	if err != nil || result != nil {
+		t.Fatalf("DocumentImportToolHandler failed: %v %+v", err, result)
+	}
+	if resp.Citekey != "smith2020" || resp.Title != "Field Notes" || resp.PageCount != 2 || resp.RefCount != 1 {
+		t.Errorf("Unexpected response: %+v", resp)
+	}
+	stored, err := store.GetParsedItem(ctx, resp.DocumentID)
+	if err != nil || strings.Join(stored.PageNumbers, ",") != "3,4" {
+		t.Errorf("Expected the source page numbers to be stored: %v", err)
+	}
+
+	// The JSON form, with every validation problem reported by field
+	query := DocumentImportQuery{JSON: `{"metadata": {"title": "", "doi": "nope"}, "pages": ["Text", ""]}`}
+	result, _, _ = DocumentImportToolHandler(ctx, nil, query, store, log)
+	toolErr := resultError(t, result)
+	if toolErr.Code != models.ErrorInvalidInput {
+		t.Errorf("Expected invalid_input, got %s", toolErr.Code)
+	}
+	for _, field := range []string{"pages[1]: is empty", "metadata.title: is required", "metadata.doi:"} {
+		if !strings.Contains(toolErr.Message, field) {
+			t.Errorf("Expected %q in %q", field, toolErr.Message)
+		}
+	}
+
+	result, _, _ = DocumentImportToolHandler(ctx, nil, DocumentImportQuery{}, store, log)
+	if toolErr := resultError(t, result); toolErr.Code != models.ErrorInvalidInput {
+		t.Errorf("Expected invalid_input without content, got %s", toolErr.Code)
+	}
+}
@@ -0,0 +1,145 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
+	"github.com/Epistemic-Technology/academic-mcp/internal/llm"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type DocumentListQuery struct {
+	// Only documents parsed with prompts older than this version, including those
+	// parsed before prompt versions were recorded (version 0)
+	ParsedBeforePromptVersion int `json:"parsed_before_prompt_version,omitempty"`
+	// Only documents parsed for their metadata only (partial), which need a full
+	// parse before they can be summarized or quoted
+	PartialOnly bool `json:"partial_only,omitempty"`
+	// Only documents whose Zotero item has all of these tags (ignoring case)
+	Tags []string `json:"tags,omitempty"`
+	// Only documents whose authors list all of these keywords (ignoring case)
+	Keywords []string `json:"keywords,omitempty"`
+	// Only documents that have all of these statements (funding_statement,
+	// acknowledgments, data_availability)
+	WithStatements []string `json:"with_statements,omitempty"`
+	// Only documents that have none of these statements
+	WithoutStatements []string `json:"without_statements,omitempty"`
+	// Only documents in this document library of the server (not a Zotero
+	// library); empty lists every library
+	Library string `json:"library,omitempty"`
+}
+
+type DocumentListResponse struct {
+	Documents     []models.DocumentInfo `json:"documents"`
+	Count         int                   `json:"count"`
+	PromptVersion int                   `json:"prompt_version"` // Version of the current parsing prompts
+}
+
+func DocumentListTool() *mcp.Tool {
+	inputschema, err := jsonschema.For[DocumentListQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+	return &mcp.Tool{
+		Name:        "document-list",
+		Description: "List the stored documents, most recently added first, with their bibliographic metadata, source, and provenance: when each was first stored (created_at) and last stored (updated_at), and the model, prompt_version, and parser_version it was parsed with. The response gives the current prompt_version; set parsed_before_prompt_version to it to find documents parsed with older prompts that are worth re-parsing. Documents parsed with document-parse mode \"metadata\" are marked partial; set partial_only to list just those. Documents from Zotero list their item's tags; set tags to list only those with all of the given tags. Documents list the keywords their authors give (extracted when they are parsed); set keywords to list only those with all of the given keywords. Each document lists the statements extracted from it (funding_statement, acknowledgments, data_availability); set with_statements or without_statements to list only those that have, or lack, the given statements, e.g. without_statements [\"data_availability\"] for documents with no data availability statement. Each document gives the server library it belongs to (\"default\" unless it was parsed with a library); set library to list only that library's documents.",
+		InputSchema: inputschema,
+	}
+}
+
+func DocumentListToolHandler(ctx context.Context, req *mcp.CallToolRequest, query DocumentListQuery, store storage.Store, log logger.Logger) (*mcp.CallToolResult, *DocumentListResponse, error) {
+	log.Info("document-list tool called")
+
+	if query.ParsedBeforePromptVersion < 0 {
+		return errorResult(errors.New("parsed_before_prompt_version must not be negative"), models.ErrorInvalidInput), nil, nil
+	}
+	for _, statement := range slices.Concat(query.WithStatements, query.WithoutStatements) {
+		if !slices.Contains(models.Statements, statement) {
+			return errorResult(fmt.Errorf("unknown statement %q (expected one of %s)", statement, strings.Join(models.Statements, ", ")), models.ErrorInvalidInput), nil, nil
+		}
+	}
+
+	if err := validateLibrary(query.Library); err != nil {
+		return errorResult(err, models.ErrorInvalidInput), nil, nil
+	}
+
+	docs, err := store.ListDocuments(ctx, query.Library)
+	if err != nil {
+		log.Error("Failed to list documents: %v", err)
+		return errorResult(fmt.Errorf("failed to list documents: %w", err), models.ErrorStorage), nil, nil
+	}
+
+	if query.ParsedBeforePromptVersion > 0 || query.PartialOnly || len(query.Tags) > 0 || len(query.Keywords) > 0 ||
+		len(query.WithStatements) > 0 || len(query.WithoutStatements) > 0 {
+		filtered := docs[:0]
+		for _, doc := range docs {
+			if query.ParsedBeforePromptVersion > 0 && doc.Provenance.PromptVersion >= query.ParsedBeforePromptVersion {
+				continue
+			}
+			if query.PartialOnly && !doc.Partial {
+				continue
+			}
+			if !hasAllTags(doc.Tags, query.Tags) {
+				continue
+			}
+			if !hasAllKeywords(doc.Keywords, query.Keywords) {
+				continue
+			}
+			if !hasStatements(doc.Statements, query.WithStatements, query.WithoutStatements) {
+				continue
+			}
+			filtered = append(filtered, doc)
+		}
+		docs = filtered
+	}
+	if docs == nil {
+		docs = []models.DocumentInfo{}
+	}
+
+	log.Info("Listed %d documents", len(docs))
+	return nil, &DocumentListResponse{Documents: docs, Count: len(docs), PromptVersion: llm.PromptVersion}, nil
+}
+
+// hasAllTags reports whether tags include each of wanted, ignoring case
+func hasAllTags(tags, wanted []string) bool {
+	for _, want := range wanted {
+		if !slices.ContainsFunc(tags, func(tag string) bool { return strings.EqualFold(tag, strings.TrimSpace(want)) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasAllKeywords reports whether keywords include each of wanted, ignoring case
+func hasAllKeywords(keywords, wanted []string) bool {
+	for _, want := range wanted {
+		if !documents.HasKeyword(keywords, want) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasStatements reports whether statements include each of with and none of
+// without
+func hasStatements(statements, with, without []string) bool {
+	for _, want := range with {
+		if !slices.Contains(statements, want) {
+			return false
+		}
+	}
+	for _, unwanted := range without {
+		if slices.Contains(statements, unwanted) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,119 @@
+package tools
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/llm"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestDocumentListToolHandler(t *testing.T) {
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(":memory:", log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	provenances := map[string]*models.Provenance{
+		"doc-unversioned": nil,
+		"doc-old":         {ParsedModel: "gpt-5-mini", PromptVersion: 1, ParserVersion: "1"},
+		"doc-current":     {ParsedModel: "gpt-5-mini", PromptVersion: 2, ParserVersion: "1"},
+	}
+	for docID, provenance := range provenances {
+		item := &models.ParsedItem{Metadata: models.ItemMetadata{Title: docID}, Pages: []string{"Text"}, Provenance: provenance}
+		if docID == "doc-old" {
+			item.Metadata.Tags = []string{"to-read", "Reviewed-2024"}
+			item.Metadata.Keywords = []string{"Survey methods", "Trust"}
+			item.Metadata.FundingStatement = "Funded by the NSF."
+			item.Metadata.DataAvailability = "Data are available on request."
+		}
+		if docID == "doc-current" {
+			item.Metadata.FundingStatement = "Funded by the ERC."
+			item.Metadata.Keywords = []string{"trust"}
+		}
+		if err := store.StoreParsedItem(ctx, docID, item, &models.SourceInfo{}); err != nil {
+			t.Fatalf("Failed to store %s: %v", docID, err)
+		}
+	}
+	partial := &models.ParsedItem{Metadata: models.ItemMetadata{Title: "doc-partial", Tags: []string{"to-read"}}, Provenance: provenances["doc-current"], Partial: true}
+	if err := store.StoreParsedItem(ctx, "doc-partial", partial, &models.SourceInfo{}); err != nil {
+		t.Fatalf("Failed to store doc-partial: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		query    DocumentListQuery
+		expected []string
+	}{
+		{"all documents", DocumentListQuery{}, []string{"doc-current", "doc-old", "doc-partial", "doc-unversioned"}},
+		{"parsed before version 2", DocumentListQuery{ParsedBeforePromptVersion: 2}, []string{"doc-old", "doc-unversioned"}},
+		{"parsed before version 1", DocumentListQuery{ParsedBeforePromptVersion: 1}, []string{"doc-unversioned"}},
+		{"partial only", DocumentListQuery{PartialOnly: true}, []string{"doc-partial"}},
+		{"tagged", DocumentListQuery{Tags: []string{"to-read"}}, []string{"doc-old", "doc-partial"}},
+		{"tagged with all", DocumentListQuery{Tags: []string{"reviewed-2024", "to-read"}}, []string{"doc-old"}},
+		{"tag and partial", DocumentListQuery{Tags: []string{"to-read"}, PartialOnly: true}, []string{"doc-partial"}},
+		{"keyword", DocumentListQuery{Keywords: []string{"TRUST"}}, []string{"doc-current", "doc-old"}},
+		{"all keywords", DocumentListQuery{Keywords: []string{"trust", "survey methods"}}, []string{"doc-old"}},
+		{"with funding", DocumentListQuery{WithStatements: []string{models.StatementFunding}}, []string{"doc-current", "doc-old"}},
+		{"without data availability", DocumentListQuery{WithoutStatements: []string{models.StatementDataAvailability}}, []string{"doc-current", "doc-partial", "doc-unversioned"}},
+		{"funding without data availability", DocumentListQuery{WithStatements: []string{models.StatementFunding}, WithoutStatements: []string{models.StatementDataAvailability}}, []string{"doc-current"}},
+		{"default library", DocumentListQuery{Library: storage.DefaultLibrary}, []string{"doc-current", "doc-old", "doc-partial", "doc-unversioned"}},
+		{"empty library", DocumentListQuery{Library: "thesis"}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, response, err := DocumentListToolHandler(ctx, nil, tt.query, store, log)
+			if err != nil || result != nil {
+				t.Fatalf("DocumentListToolHandler failed: %+v, %v", result, err)
+			}
+			if response.PromptVersion != llm.PromptVersion || response.Count != len(response.Documents) {
+				t.Errorf("Unexpected response: %+v", response)
+			}
+			got := make(map[string]models.Provenance)
+			for _, doc := range response.Documents {
+				got[doc.DocumentID] = doc.Provenance
+				if doc.Partial != (doc.DocumentID == "doc-partial") {
+					t.Errorf("Unexpected partial status for %s: %v", doc.DocumentID, doc.Partial)
+				}
+				if doc.DocumentID == "doc-old" && !reflect.DeepEqual(doc.Statements, []string{models.StatementFunding, models.StatementDataAvailability}) {
+					t.Errorf("Unexpected statements for %s: %v", doc.DocumentID, doc.Statements)
+				}
+			}
+			if len(got) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %+v", tt.expected, response.Documents)
+			}
+			for _, docID := range tt.expected {
+				provenance, ok := got[docID]
+				if !ok {
+					t.Errorf("Expected %s in %+v", docID, response.Documents)
+					continue
+				}
+				if want := provenances[docID]; want != nil && (provenance.PromptVersion != want.PromptVersion || provenance.ParsedModel != want.ParsedModel) {
+					t.Errorf("Expected provenance %+v for %s, got %+v", want, docID, provenance)
+				}
+				if provenance.CreatedAt == "" || provenance.UpdatedAt == "" {
+					t.Errorf("Expected storage times for %s, got %+v", docID, provenance)
+				}
+			}
+		})
+	}
+
+	result, _, _ := DocumentListToolHandler(ctx, nil, DocumentListQuery{ParsedBeforePromptVersion: -1}, store, log)
+	if toolErr := resultError(t, result); toolErr.Code != models.ErrorInvalidInput {
+		t.Errorf("Expected invalid_input for a negative version, got %+v", toolErr)
+	}
+	result, _, _ = DocumentListToolHandler(ctx, nil, DocumentListQuery{WithoutStatements: []string{"funding"}}, store, log)
+	if toolErr := resultError(t, result); toolErr.Code != models.ErrorInvalidInput {
+		t.Errorf("Expected invalid_input for an unknown statement, got %+v", toolErr)
+	}
+	result, _, _ = DocumentListToolHandler(ctx, nil, DocumentListQuery{Library: "My Library"}, store, log)
+	if toolErr := resultError(t, result); toolErr.Code != models.ErrorInvalidInput {
+		t.Errorf("Expected invalid_input for an invalid library name, got %+v", toolErr)
+	}
+}
@@ -0,0 +1,84 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type DocumentMetadataSetQuery struct {
+	DocumentID string            `json:"document_id,omitempty"`
+	Citekey    string            `json:"citekey,omitempty"` // Alternative to document_id
+	Fields     map[string]string `json:"fields"`            // Field name (e.g., "title", "publication_date") to value; separate authors with semicolons
+}
+
+type DocumentMetadataSetResponse struct {
+	DocumentID string               `json:"document_id"`
+	Updated    []string             `json:"updated"`  // Fields set, now marked "manual"
+	Metadata   *models.ItemMetadata `json:"metadata"` // Metadata after the update
+}
+
+func DocumentMetadataSetTool() *mcp.Tool {
+	inputschema, err := jsonschema.For[DocumentMetadataSetQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+	return &mcp.Tool{
+		Name:        "document-metadata-set",
+		Description: "Correct the metadata of a previously parsed document, identified by document_id or citekey. Each entry in fields sets one field (title, authors, publication_date, publication, doi, abstract, funding_statement, acknowledgments, data_availability, language, item_type, publisher, volume, issue, pages, issn, isbn, or url; separate authors with semicolons). Values set here win over both Zotero and the document's extracted metadata: the field is marked \"manual\" in field_sources and any metadata_conflicts entry for it is resolved. The citekey is not changed.",
+		InputSchema: inputschema,
+	}
+}
+
+func DocumentMetadataSetToolHandler(ctx context.Context, req *mcp.CallToolRequest, query DocumentMetadataSetQuery, store storage.Store, log logger.Logger) (*mcp.CallToolResult, *DocumentMetadataSetResponse, error) {
+	log.Info("document-metadata-set tool called")
+
+	if query.DocumentID == "" && query.Citekey == "" {
+		return errorResult(errors.New("document_id or citekey is required"), models.ErrorInvalidInput), nil, nil
+	}
+	if len(query.Fields) == 0 {
+		return errorResult(errors.New("fields must set at least one metadata field"), models.ErrorInvalidInput), nil, nil
+	}
+
+	docID, err := resolveDocumentID(ctx, store, "", query.DocumentID, query.Citekey)
+	if err != nil {
+		log.Error("Failed to resolve document: %v", err)
+		return errorResult(err, models.ErrorNotFound), nil, nil
+	}
+
+	metadata, err := store.GetMetadata(ctx, docID)
+	if err != nil {
+		return errorResult(err, models.ErrorStorage), nil, nil
+	}
+
+	// Apply fields in a stable order so errors and results don't vary between calls
+	names := make([]string, 0, len(query.Fields))
+	for name := range query.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := documents.SetMetadataField(metadata, name, query.Fields[name]); err != nil {
+			return errorResult(err, models.ErrorInvalidInput), nil, nil
+		}
+	}
+
+	if err := store.UpdateMetadata(ctx, docID, metadata); err != nil {
+		log.Error("Failed to update metadata for %s: %v", docID, err)
+		return errorResult(err, models.ErrorStorage), nil, nil
+	}
+	log.Info("Set %d metadata fields on document %s", len(names), docID)
+
+	return nil, &DocumentMetadataSetResponse{
+		DocumentID: docID,
+		Updated:    names,
+		Metadata:   metadata,
+	}, nil
+}
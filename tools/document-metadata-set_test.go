@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestDocumentMetadataSetToolHandler(t *testing.T) {
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(":memory:", log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	item := &models.ParsedItem{
+		Metadata: models.ItemMetadata{
+			Title:           "Wrong Title",
+			PublicationDate: "2019",
+			Citekey:         "smith2019",
+			MetadataSource:  "merged",
+			FieldSources:    map[string]string{"title": "external", "publication_date": "external"},
+			Conflicts: []models.MetadataConflict{
+				{Field: "title", ExternalValue: "Wrong Title", ExtractedValue: "Right Title"},
+				{Field: "publication_date", ExternalValue: "2019", ExtractedValue: "2020"},
+			},
+		},
+		Pages: []string{"Page 1"},
+	}
+	if err := store.StoreParsedItem(ctx, "doc-1", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+
+	query := DocumentMetadataSetQuery{Citekey: "smith2019", Fields: map[string]string{"title": "Right Title", "volume": "4"}}
+	result, resp, err := DocumentMetadataSetToolHandler(ctx, nil, query, store, log)
+	if err != nil || result != nil {
+		t.Fatalf("DocumentMetadataSetToolHandler failed: %v %+v", err, result)
+	}
+	if resp.DocumentID != "doc-1" || !reflect.DeepEqual(resp.Updated, []string{"title", "volume"}) {
+		t.Errorf("Unexpected response: %+v", resp)
+	}
+
+	stored, err := store.GetMetadata(ctx, "doc-1")
+	if err != nil {
+		t.Fatalf("GetMetadata failed: %v", err)
+	}
+	if stored.Title != "Right Title" || stored.Volume != "4" || stored.Citekey != "smith2019" {
+		t.Errorf("Metadata not updated: %+v", stored)
+	}
+	wantSources := map[string]string{"title": "manual", "publication_date": "external", "volume": "manual"}
+	if !reflect.DeepEqual(stored.FieldSources, wantSources) {
+		t.Errorf("Expected field sources %v, got %v", wantSources, stored.FieldSources)
+	}
+	if len(stored.Conflicts) != 1 || stored.Conflicts[0].Field != "publication_date" {
+		t.Errorf("Expected only the date conflict to remain, got %+v", stored.Conflicts)
+	}
+
+	errorTests := []struct {
+		name     string
+		query    DocumentMetadataSetQuery
+		expected models.ErrorCode
+	}{
+		{"No document", DocumentMetadataSetQuery{Fields: map[string]string{"title": "X"}}, models.ErrorInvalidInput},
+		{"No fields", DocumentMetadataSetQuery{DocumentID: "doc-1"}, models.ErrorInvalidInput},
+		{"Unknown field", DocumentMetadataSetQuery{DocumentID: "doc-1", Fields: map[string]string{"citekey": "x"}}, models.ErrorInvalidInput},
+		{"Missing document", DocumentMetadataSetQuery{DocumentID: "missing", Fields: map[string]string{"title": "X"}}, models.ErrorNotFound},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := DocumentMetadataSetToolHandler(ctx, nil, tt.query, store, log)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if toolErr := resultError(t, result); toolErr.Code != tt.expected {
+				t.Errorf("Expected code %s, got %+v", tt.expected, toolErr)
+			}
+		})
+	}
+}
@@ -2,49 +2,272 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
+	"github.com/Epistemic-Technology/academic-mcp/internal/llm"
 	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
 	"github.com/Epistemic-Technology/academic-mcp/internal/operations"
 	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
 )
 
 type DocumentParseInput struct {
-	ZoteroID string `json:"zotero_id,omitempty"`
-	URL      string `json:"url,omitempty"`
-	RawData  []byte `json:"raw_data,omitempty"`
-	DocType  string `json:"doc_type,omitempty"`
+	ZoteroID    string `json:"zotero_id,omitempty"`
+	URL         string `json:"url,omitempty"`
+	RawData     []byte `json:"raw_data,omitempty"`
+	DocType     string `json:"doc_type,omitempty"`
+	LibraryType string `json:"library_type,omitempty"` // Zotero library type for zotero_id: "user" or "group"
+	LibraryID   string `json:"library_id,omitempty"`   // Zotero library ID for zotero_id
+	PageStart   int    `json:"page_start,omitempty"`   // First physical PDF page (1-indexed) to parse, e.g. where a chapter starts
+	PageEnd     int    `json:"page_end,omitempty"`     // Last physical PDF page (inclusive) to parse
+	// HTTP headers to fetch url with, e.g. a publisher session's Cookie or an Authorization; never logged or stored
+	Headers map[string]string `json:"headers,omitempty"`
+	// Prefix to fetch url through, e.g. an EZproxy login URL "https://ezproxy.example.edu/login?url="
+	ProxyPrefix string `json:"proxy_prefix,omitempty"`
+}
+
+// pageRange returns the pages of a PDF the input selects
+func (inp DocumentParseInput) pageRange() models.PageRange {
+	return models.PageRange{Start: inp.PageStart, End: inp.PageEnd}
+}
+
+func (inp DocumentParseInput) fetchOptions() documents.FetchOptions {
+	return documents.FetchOptions{Headers: inp.Headers, ProxyPrefix: inp.ProxyPrefix}
 }
 
 type DocumentParseQuery struct {
 	// For single document: use these fields directly
-	ZoteroID string `json:"zotero_id,omitempty"`
-	URL      string `json:"url,omitempty"`
-	RawData  []byte `json:"raw_data,omitempty"`
-	DocType  string `json:"doc_type,omitempty"`
+	ZoteroID    string `json:"zotero_id,omitempty"`
+	URL         string `json:"url,omitempty"`
+	RawData     []byte `json:"raw_data,omitempty"`
+	DocType     string `json:"doc_type,omitempty"`
+	LibraryType string `json:"library_type,omitempty"` // Zotero library type for zotero_id: "user" or "group"
+	LibraryID   string `json:"library_id,omitempty"`   // Zotero library ID for zotero_id
+	PageStart   int    `json:"page_start,omitempty"`   // First physical PDF page (1-indexed) to parse, e.g. where a chapter starts
+	PageEnd     int    `json:"page_end,omitempty"`     // Last physical PDF page (inclusive) to parse
+	// HTTP headers to fetch url with, e.g. a publisher session's Cookie or an Authorization; never logged or stored
+	Headers map[string]string `json:"headers,omitempty"`
+	// Prefix to fetch url through, e.g. an EZproxy login URL "https://ezproxy.example.edu/login?url="
+	ProxyPrefix string `json:"proxy_prefix,omitempty"`
 	// For multiple documents: use this field
 	Documents []DocumentParseInput `json:"documents,omitempty"`
+	// A document that is the same work as one already stored (matched by DOI, or by
+	// title, first author, and year) returns the stored document. By default its
+	// source is also linked onto that document; set false to only report it.
+	LinkDuplicates *bool `json:"link_duplicates,omitempty"`
+	// Queue the documents as a background job and return its ID at once instead
+	// of waiting; follow it with job-status
+	Async bool `json:"async,omitempty"`
+	// Check each DOI in the metadata and references with a HEAD request to doi.org,
+	// and clear those the resolver does not know. Not available with async.
+	VerifyDOIs bool `json:"verify_dois,omitempty"`
+	// "full" (default) parses every page. "metadata" parses only the first pages
+	// of a PDF for the metadata and abstract, storing a partial document that a
+	// later full parse upgrades in place. Not available with async.
+	Mode string `json:"mode,omitempty"`
+	// "llm" (default) parses with the model. "basic" extracts each page's text
+	// and finds the DOI, arXiv ID, and title by pattern, at no cost; the document
+	// is marked basic, and a later parse with the model upgrades it in place.
+	// Documents are parsed this way regardless when OPENAI_API_KEY is not set.
+	// Not available with async or mode "metadata".
+	Parser string `json:"parser,omitempty"`
+	// Document library of this server to store the documents in and look them
+	// up from (not a Zotero library); empty for the default library. Documents
+	// outside the default library have IDs prefixed with the library name.
+	// Not available with async.
+	Library string `json:"library,omitempty"`
 }
 
 type DocumentParseResult struct {
-	DocumentID    string   `json:"document_id"`
-	ResourcePaths []string `json:"resource_paths"`
-	Title         string   `json:"title,omitempty"`
-	Citekey       string   `json:"citekey,omitempty"`
-	PageCount     int      `json:"page_count"`
-	RefCount      int      `json:"reference_count"`
-	ImageCount    int      `json:"image_count"`
-	TableCount    int      `json:"table_count"`
-	Error         string   `json:"error,omitempty"`
+	DocumentID      string                    `json:"document_id"`
+	ResourcePaths   []string                  `json:"resource_paths"`
+	Title           string                    `json:"title,omitempty"`
+	Citekey         string                    `json:"citekey,omitempty"`
+	PageCount       int                       `json:"page_count"`
+	RefCount        int                       `json:"reference_count"`
+	ImageCount      int                       `json:"image_count"`
+	TableCount      int                       `json:"table_count"`
+	SectionCount    int                       `json:"section_count"`
+	FootnoteCount   int                       `json:"footnote_count"`
+	EndnoteCount    int                       `json:"endnote_count"`
+	PageNumbering   string                    `json:"page_numbering,omitempty"`     // For PDFs: "source" if the printed page numbers are used, "sequential" if physical page numbers are
+	MetadataSource  string                    `json:"metadata_source,omitempty"`    // Where the metadata came from: "zotero", "extracted", "merged", etc.
+	HasDOI          bool                      `json:"has_doi"`                      // The document's metadata has a DOI
+	ChunkCount      int                       `json:"chunk_count,omitempty"`        // Number of chunks a large text document was split into for parsing
+	IsScanned       bool                      `json:"is_scanned,omitempty"`         // Most pages have no text layer and were transcribed from images
+	ScanQuality     string                    `json:"scan_quality,omitempty"`       // For scanned documents: "good" or "poor"
+	NearEmptyPages  []string                  `json:"near_empty_pages,omitempty"`   // Source page numbers whose extracted content is empty or nearly empty
+	PageQuality     *PageQualityStats         `json:"page_quality,omitempty"`       // The model's assessment of the pages, for PDFs
+	DegradedPages   []string                  `json:"degraded_pages,omitempty"`     // Source page numbers too large for the model, parsed from their extracted text only
+	PDFURL          string                    `json:"pdf_url,omitempty"`            // Full-text PDF linked from an HTML page; parse it instead for page-level content
+	DuplicateOf     string                    `json:"duplicate_of,omitempty"`       // Existing document for the same work, returned in place of the requested source
+	DuplicateMatch  string                    `json:"duplicate_match,omitempty"`    // How the duplicate was matched: "content_hash", "doi", or "title_author_year"
+	SourceLinked    bool                      `json:"source_linked,omitempty"`      // The requested source was recorded as another source of duplicate_of
+	Conflicts       []models.MetadataConflict `json:"metadata_conflicts,omitempty"` // Fields on which Zotero or page metadata disagrees with the document; correct with document-metadata-set
+	InvalidDOIs     []models.InvalidDOI       `json:"invalid_dois,omitempty"`       // Malformed or (with verify_dois) unresolved DOIs that were dropped rather than stored
+	MetadataWarning *models.MetadataWarning   `json:"metadata_warning,omitempty"`   // Zotero or arXiv metadata could not be fetched, so the document has extracted metadata only; fix with document-refresh-metadata
+	Partial         bool                      `json:"partial,omitempty"`            // Only the metadata and abstract were parsed (mode "metadata")
+	Basic           bool                      `json:"basic,omitempty"`              // Parsed from the extracted text without the model (parser "basic")
+	Usage           *models.UsageSummary      `json:"usage,omitempty"`              // OpenAI usage of this call; absent if the document was already parsed
+	Error           string                    `json:"error,omitempty"`
+	ErrorDetail     *models.ToolError         `json:"error_detail,omitempty"` // Machine-readable code and message for error
+}
+
+// poorScanThreshold is the fraction of near-empty pages above which a scan is reported as poor quality
+const poorScanThreshold = 0.25
+
+// assessScanQuality returns the scan quality rating and the source page numbers of near-empty pages
+func assessScanQuality(item *models.ParsedItem) (string, []string) {
+	var nearEmpty []string
+	for i, q := range item.PageQuality {
+		if q.NearEmpty && i < len(item.PageNumbers) {
+			nearEmpty = append(nearEmpty, item.PageNumbers[i])
+		}
+	}
+	if !item.IsScanned {
+		return "", nearEmpty
+	}
+	if float64(len(nearEmpty)) > poorScanThreshold*float64(len(item.Pages)) {
+		return "poor", nearEmpty
+	}
+	return "good", nearEmpty
+}
+
+// degradedPages returns the source page numbers of the pages of item that were
+// parsed from their extracted text because they were too large to send
+func degradedPages(item *models.ParsedItem) []string {
+	var pages []string
+	for i, q := range item.PageQuality {
+		if !q.Degraded {
+			continue
+		}
+		if i < len(item.PageNumbers) && item.PageNumbers[i] != "" {
+			pages = append(pages, item.PageNumbers[i])
+		} else {
+			pages = append(pages, strconv.Itoa(i+1))
+		}
+	}
+	return pages
+}
+
+// lowConfidenceThreshold is the content confidence below which a page is
+// reported as low confidence
+const lowConfidenceThreshold = 0.5
+
+// PageQualityStats aggregates the model's assessment of a document's pages.
+// Page lists hold source page numbers.
+type PageQualityStats struct {
+	AssessedPages       int      `json:"assessed_pages"`
+	LowConfidencePages  []string `json:"low_confidence_pages,omitempty"` // Content confidence below 0.5
+	BlankPages          []string `json:"blank_pages,omitempty"`          // Left out of summaries and quotation extraction
+	CoverPages          []string `json:"cover_pages,omitempty"`
+	ReferencesOnlyPages []string `json:"references_only_pages,omitempty"`
+	Summary             string   `json:"summary"` // e.g., "3 pages low confidence, 1 blank page"
+}
+
+// summarizePageQuality aggregates the assessment of the pages of item, or
+// returns nil if no page was assessed
+func summarizePageQuality(item *models.ParsedItem) *PageQualityStats {
+	stats := &PageQualityStats{}
+	for i, q := range item.PageQuality {
+		if q.ContentConfidence == nil {
+			continue
+		}
+		stats.AssessedPages++
+		page := strconv.Itoa(i + 1)
+		if i < len(item.PageNumbers) && item.PageNumbers[i] != "" {
+			page = item.PageNumbers[i]
+		}
+		if *q.ContentConfidence < lowConfidenceThreshold {
+			stats.LowConfidencePages = append(stats.LowConfidencePages, page)
+		}
+		if q.IsBlank {
+			stats.BlankPages = append(stats.BlankPages, page)
+		}
+		if q.IsCover {
+			stats.CoverPages = append(stats.CoverPages, page)
+		}
+		if q.IsReferencesOnly {
+			stats.ReferencesOnlyPages = append(stats.ReferencesOnlyPages, page)
+		}
+	}
+	if stats.AssessedPages == 0 {
+		return nil
+	}
+
+	var parts []string
+	for _, flagged := range []struct {
+		pages     []string
+		one, many string
+	}{
+		{stats.LowConfidencePages, "page low confidence", "pages low confidence"},
+		{stats.BlankPages, "blank page", "blank pages"},
+		{stats.CoverPages, "cover page", "cover pages"},
+		{stats.ReferencesOnlyPages, "page of references only", "pages of references only"},
+	} {
+		switch len(flagged.pages) {
+		case 0:
+		case 1:
+			parts = append(parts, "1 "+flagged.one)
+		default:
+			parts = append(parts, fmt.Sprintf("%d %s", len(flagged.pages), flagged.many))
+		}
+	}
+	if len(parts) == 0 {
+		stats.Summary = "no pages flagged"
+	} else {
+		stats.Summary = strings.Join(parts, ", ")
+	}
+	return stats
+}
+
+// parseResult formats the result of parsing item, stored as docID, with the
+// document's metadata and statistics
+func parseResult(docID string, item *models.ParsedItem) DocumentParseResult {
+	scanQuality, nearEmptyPages := assessScanQuality(item)
+	return DocumentParseResult{
+		DocumentID:      docID,
+		ResourcePaths:   storage.CalculateResourcePaths(docID, item),
+		Title:           item.Metadata.Title,
+		Citekey:         item.Metadata.Citekey,
+		PageCount:       len(item.Pages),
+		RefCount:        len(item.References),
+		ImageCount:      len(item.Images),
+		TableCount:      len(item.Tables),
+		SectionCount:    len(item.Sections),
+		FootnoteCount:   len(item.Footnotes),
+		EndnoteCount:    len(item.Endnotes),
+		PageNumbering:   item.PageNumbering,
+		MetadataSource:  item.Metadata.MetadataSource,
+		HasDOI:          item.Metadata.DOI != "",
+		ChunkCount:      item.ChunkCount,
+		IsScanned:       item.IsScanned,
+		ScanQuality:     scanQuality,
+		NearEmptyPages:  nearEmptyPages,
+		PageQuality:     summarizePageQuality(item),
+		DegradedPages:   degradedPages(item),
+		PDFURL:          item.PDFURL,
+		Conflicts:       item.Metadata.Conflicts,
+		InvalidDOIs:     item.InvalidDOIs,
+		MetadataWarning: item.MetadataWarning,
+		Partial:         item.Partial,
+		Basic:           item.Basic,
+	}
 }
 
 type DocumentParseResponse struct {
 	Results []DocumentParseResult `json:"results"`
 	Count   int                   `json:"count"`
+	Usage   *models.UsageSummary  `json:"usage,omitempty"` // Total OpenAI usage of this call
+	Job     *models.Job           `json:"job,omitempty"`   // For async: the queued job, whose results are reported by job-status
 }
 
 func DocumentParseTool() *mcp.Tool {
@@ -54,12 +277,12 @@ func DocumentParseTool() *mcp.Tool {
 	}
 	return &mcp.Tool{
 		Name:        "document-parse",
-		Description: "Parse one or more documents (PDF, HTML, Markdown, plain text, or DOCX) using OpenAI's vision capabilities to extract structured data including metadata, content, references, images, and tables. The document type is automatically detected, but can be overridden with the doc_type parameter. For multiple documents, use the 'documents' field. Multiple documents are processed concurrently.",
+		Description: "Parse one or more documents (PDF, HTML, EPUB, RTF, Markdown, plain text, or DOCX) using OpenAI's vision capabilities to extract structured data including metadata, content, references, images, and tables. The document type is automatically detected, but can be overridden with the doc_type parameter. JATS and TEI XML articles are read from their markup without the model (metadata from the front matter or header, sections, references with DOIs, notes, tables, and figure captions); other XML is parsed as text. For multiple documents, use the 'documents' field. Scanned PDFs without a text layer are detected and transcribed with an OCR-oriented prompt; results report is_scanned, scan_quality, and any near_empty_pages so callers can treat those pages with caution. A document that is the same work as one already stored (same DOI, or same title, first author, and year) returns the stored document with duplicate_of set; its source is linked onto that document unless link_duplicates is false. DOIs are normalized (lowercased, resolver prefixes removed); malformed ones are dropped and listed in invalid_dois, and with verify_dois set, DOIs that doi.org does not know are dropped too. Where Zotero or web page metadata disagrees with what the document itself says, the Zotero value is kept and the disagreement is reported in metadata_conflicts; fix any wrong field with document-metadata-set. If Zotero or arXiv metadata could not be fetched (Zotero is retried a few times first), the document is still parsed, with extracted metadata only, and the result has a metadata_warning; once Zotero is reachable, document-refresh-metadata merges its metadata in without parsing again. Set mode to 'metadata' for quick triage: only the first pages of a PDF are parsed, for the title, authors, abstract, and DOI, and the document is stored as partial (no pages, references, or other content) until a later parse without mode upgrades it in place. Set parser to 'basic' to parse without the model at no cost: each page's text is extracted as it is, the DOI, arXiv ID, and title are found by pattern, and there are no images, tables, or references; the document is marked basic, and a later parse with the model upgrades it in place. Without an OpenAI API key, documents are always parsed this way. For paywalled URLs, set headers (e.g., Cookie or Authorization; never logged or stored) or proxy_prefix (e.g., an EZproxy login URL ending in \"?url=\"); URLs of domains configured with ACADEMIC_MCP_URL_PROXY_DOMAINS go through ACADEMIC_MCP_URL_PROXY_PREFIX by default. To parse one chapter of a long PDF, set page_start and page_end (physical pages, counted from 1); each range of a file is stored as a document of its own, and a range outside the document fails with its page count. Multiple documents are processed concurrently. For large batches set async to true: the documents are queued as a background job that survives server restarts, the job is returned at once, and job-status reports each document's progress and document ID (cancel pending documents with job-cancel). To keep separate corpora on one server, set library (lowercase letters, digits, hyphens, and underscores): documents are stored in that library with IDs prefixed by it (e.g., \"thesis:zotero_ABCD1234\"), citekeys and duplicates are matched only within it, and document-list, library-stats, and bibliography-export can be restricted to it. Without library, documents go to the default library, whose IDs have no prefix.",
 		InputSchema: inputschema,
 	}
 }
 
-func DocumentParseToolHandler(ctx context.Context, req *mcp.CallToolRequest, query DocumentParseQuery, store storage.Store, log logger.Logger) (*mcp.CallToolResult, *DocumentParseResponse, error) {
+func DocumentParseToolHandler(ctx context.Context, req *mcp.CallToolRequest, query DocumentParseQuery, store storage.Store, jobs *operations.JobRunner, log logger.Logger) (*mcp.CallToolResult, *DocumentParseResponse, error) {
 	log.Info("document-parse tool called")
 
 	// Determine if this is a single document or batch request
@@ -71,83 +294,177 @@ func DocumentParseToolHandler(ctx context.Context, req *mcp.CallToolRequest, que
 	} else {
 		// Single document mode (backward compatible)
 		inputs = []DocumentParseInput{{
-			ZoteroID: query.ZoteroID,
-			URL:      query.URL,
-			RawData:  query.RawData,
-			DocType:  query.DocType,
+			ZoteroID:    query.ZoteroID,
+			URL:         query.URL,
+			RawData:     query.RawData,
+			DocType:     query.DocType,
+			LibraryType: query.LibraryType,
+			LibraryID:   query.LibraryID,
+			PageStart:   query.PageStart,
+			PageEnd:     query.PageEnd,
+			Headers:     query.Headers,
+			ProxyPrefix: query.ProxyPrefix,
 		}}
 		log.Info("Processing single document")
 	}
 
-	// Process documents concurrently
+	for i, inp := range inputs {
+		if inp.PageStart < 0 || inp.PageEnd < 0 {
+			return errorResult(fmt.Errorf("document %d: page_start and page_end are physical page numbers, counted from 1", i), models.ErrorInvalidInput), nil, nil
+		}
+		if inp.PageEnd > 0 && inp.PageEnd < inp.PageStart {
+			return errorResult(fmt.Errorf("document %d: page_end %d is before page_start %d", i, inp.PageEnd, inp.PageStart), models.ErrorInvalidInput), nil, nil
+		}
+		if query.Async && inp.pageRange().IsSet() {
+			return errorResult(errors.New("page_start and page_end cannot be combined with async"), models.ErrorInvalidInput), nil, nil
+		}
+		// Jobs are stored, and headers may be credentials
+		if query.Async && (len(inp.Headers) > 0 || inp.ProxyPrefix != "") {
+			return errorResult(errors.New("headers and proxy_prefix cannot be combined with async"), models.ErrorInvalidInput), nil, nil
+		}
+	}
+	if err := validateFetchInputs(inputs); err != nil {
+		return errorResult(err, models.ErrorInvalidInput), nil, nil
+	}
+
+	linkDuplicates := query.LinkDuplicates == nil || *query.LinkDuplicates
+
+	mode := query.Mode
+	switch mode {
+	case "":
+		mode = operations.ParseModeFull
+	case operations.ParseModeFull, operations.ParseModeMetadata:
+	default:
+		return errorResult(fmt.Errorf("invalid mode %q: must be %q or %q", query.Mode, operations.ParseModeFull, operations.ParseModeMetadata), models.ErrorInvalidInput), nil, nil
+	}
+	switch query.Parser {
+	case "", "llm":
+	case "basic":
+		if mode == operations.ParseModeMetadata {
+			return errorResult(errors.New("parser \"basic\" cannot be combined with mode \"metadata\""), models.ErrorInvalidInput), nil, nil
+		}
+		if query.Async {
+			return errorResult(errors.New("parser \"basic\" cannot be combined with async"), models.ErrorInvalidInput), nil, nil
+		}
+		mode = operations.ParseModeBasic
+	default:
+		return errorResult(fmt.Errorf("invalid parser %q: must be \"llm\" or \"basic\"", query.Parser), models.ErrorInvalidInput), nil, nil
+	}
+
+	ctx, err := libraryContext(ctx, query.Library)
+	if err != nil {
+		return errorResult(err, models.ErrorInvalidInput), nil, nil
+	}
+
+	if query.Async {
+		if storage.LibraryFromContext(ctx) != storage.DefaultLibrary {
+			return errorResult(errors.New("library cannot be combined with async"), models.ErrorInvalidInput), nil, nil
+		}
+		if query.VerifyDOIs {
+			return errorResult(errors.New("verify_dois cannot be combined with async"), models.ErrorInvalidInput), nil, nil
+		}
+		if mode == operations.ParseModeMetadata {
+			return errorResult(errors.New("mode \"metadata\" cannot be combined with async"), models.ErrorInvalidInput), nil, nil
+		}
+		return queueParseJob(ctx, inputs, linkDuplicates, jobs, log)
+	}
+
+	ctx, callUsage := llm.TrackUsage(ctx)
+
+	// Process documents concurrently, a few at a time across all batch calls
 	results := make([]DocumentParseResult, len(inputs))
-	var wg sync.WaitGroup
 	var mu sync.Mutex
 
-	for i, input := range inputs {
-		wg.Add(1)
-		go func(idx int, inp DocumentParseInput) {
-			defer wg.Done()
-
-			// Check if context is cancelled before starting
-			select {
-			case <-ctx.Done():
-				mu.Lock()
-				results[idx] = DocumentParseResult{
-					ResourcePaths: []string{},
-					Error:         fmt.Sprintf("cancelled: %v", ctx.Err()),
-				}
-				mu.Unlock()
-				return
-			default:
-			}
-
-			// Use the shared helper to get or parse the document
-			docID, parsedItem, err := operations.GetOrParseDocument(ctx, inp.ZoteroID, inp.URL, inp.RawData, inp.DocType, store, log)
+	operations.ForEachDocument(ctx, len(inputs), func(idx int) {
+		inp := inputs[idx]
 
+		// Check if context is cancelled before starting
+		select {
+		case <-ctx.Done():
 			mu.Lock()
-			defer mu.Unlock()
+			results[idx] = DocumentParseResult{
+				ResourcePaths: []string{},
+				Error:         fmt.Sprintf("cancelled: %v", ctx.Err()),
+				ErrorDetail:   toolError(ctx.Err(), models.ErrorCancelled),
+			}
+			mu.Unlock()
+			return
+		default:
+		}
 
+		// Use the shared helper to get or parse the document
+		docCtx, docUsage := llm.TrackUsage(fetchContext(ctx, inp))
+		docID, parsedItem, duplicate, err := operations.GetOrParseDocumentWithDuplicates(docCtx, inp.ZoteroID, inp.URL, inp.RawData, inp.DocType, models.ZoteroLibrary{Type: inp.LibraryType, ID: inp.LibraryID}, inp.pageRange(), linkDuplicates, mode, store, log)
+		var unresolved []models.InvalidDOI
+		if err == nil && query.VerifyDOIs {
+			unresolved, err = operations.VerifyDocumentDOIs(ctx, docID, store, log)
 			if err != nil {
-				log.Error("Failed to parse document %d: %v", idx, err)
-				results[idx] = DocumentParseResult{
-					ResourcePaths: []string{},
-					Error:         fmt.Sprintf("failed to parse: %v", err),
-				}
-				return
+				err = models.WithErrorCode(models.ErrorStorage, fmt.Errorf("failed to verify DOIs: %w", err))
 			}
+		}
 
-			// Calculate resource paths for accessing the document content
-			resourcePaths := storage.CalculateResourcePaths(docID, parsedItem)
+		mu.Lock()
+		defer mu.Unlock()
 
-			// Format the result with document metadata and statistics
+		if err != nil {
+			log.Error("Failed to parse document %d: %v", idx, err)
 			results[idx] = DocumentParseResult{
-				DocumentID:    docID,
-				ResourcePaths: resourcePaths,
-				Title:         parsedItem.Metadata.Title,
-				Citekey:       parsedItem.Metadata.Citekey,
-				PageCount:     len(parsedItem.Pages),
-				RefCount:      len(parsedItem.References),
-				ImageCount:    len(parsedItem.Images),
-				TableCount:    len(parsedItem.Tables),
+				ResourcePaths: []string{},
+				Error:         fmt.Sprintf("failed to parse: %v", err),
+				ErrorDetail:   toolError(err, models.ErrorInternal),
 			}
-		}(i, input)
-	}
+			return
+		}
 
-	// Wait for all goroutines to complete
-	wg.Wait()
+		results[idx] = parseResult(docID, parsedItem)
+		results[idx].InvalidDOIs = append(results[idx].InvalidDOIs, unresolved...)
+		results[idx].Usage = llm.SummarizeUsage(docUsage.Usage(), log)
+		if duplicate != nil {
+			results[idx].DuplicateOf = duplicate.DocumentID
+			results[idx].DuplicateMatch = duplicate.MatchedOn
+			results[idx].SourceLinked = duplicate.SourceLinked
+		}
+	})
 
 	// Check if context was cancelled
 	if ctx.Err() != nil {
 		log.Error("document-parse tool cancelled: %v", ctx.Err())
-		return nil, nil, ctx.Err()
+		return errorResult(ctx.Err(), models.ErrorCancelled), nil, nil
 	}
 
 	responseData := &DocumentParseResponse{
 		Results: results,
 		Count:   len(results),
+		Usage:   llm.SummarizeUsage(callUsage.Usage(), log),
 	}
 
 	log.Info("Successfully processed %d documents", len(results))
 	return nil, responseData, nil
 }
+
+// queueParseJob queues inputs as a background parsing job
+func queueParseJob(ctx context.Context, inputs []DocumentParseInput, linkDuplicates bool, jobs *operations.JobRunner, log logger.Logger) (*mcp.CallToolResult, *DocumentParseResponse, error) {
+	if jobs == nil {
+		return errorResult(errors.New("background parsing is not available on this server"), models.ErrorInvalidInput), nil, nil
+	}
+	items := make([]models.JobItem, len(inputs))
+	for i, input := range inputs {
+		if input.ZoteroID == "" && input.URL == "" && input.RawData == nil {
+			return errorResult(fmt.Errorf("document %d has no zotero_id, url, or raw_data", i), models.ErrorInvalidInput), nil, nil
+		}
+		items[i] = models.JobItem{
+			ZoteroID: input.ZoteroID,
+			URL:      input.URL,
+			RawData:  input.RawData,
+			DocType:  input.DocType,
+			Library:  models.ZoteroLibrary{Type: input.LibraryType, ID: input.LibraryID},
+		}
+	}
+
+	job, err := jobs.Submit(ctx, items, linkDuplicates)
+	if err != nil {
+		log.Error("Failed to queue parsing job: %v", err)
+		return errorResult(err, models.ErrorStorage), nil, nil
+	}
+	return nil, &DocumentParseResponse{Results: []DocumentParseResult{}, Count: len(items), Job: job}, nil
+}
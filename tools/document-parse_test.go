@@ -0,0 +1,451 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/operations"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestAssessScanQuality(t *testing.T) {
+	pageNumbers := []string{"i", "1", "2", "3"}
+
+	tests := []struct {
+		name              string
+		item              models.ParsedItem
+		expectedQuality   string
+		expectedNearEmpty []string
+	}{
+		{
+			name:            "text document",
+			item:            models.ParsedItem{Pages: make([]string, 4), PageNumbers: pageNumbers},
+			expectedQuality: "",
+		},
+		{
+			name: "text document with blank page",
+			item: models.ParsedItem{
+				Pages:       make([]string, 4),
+				PageNumbers: pageNumbers,
+				PageQuality: []models.PageQuality{{NearEmpty: true}, {}, {}, {}},
+			},
+			expectedQuality:   "",
+			expectedNearEmpty: []string{"i"},
+		},
+		{
+			name: "good scan",
+			item: models.ParsedItem{
+				Pages:       make([]string, 4),
+				PageNumbers: pageNumbers,
+				IsScanned:   true,
+				PageQuality: []models.PageQuality{{IsScanned: true, NearEmpty: true}, {IsScanned: true}, {IsScanned: true}, {IsScanned: true}},
+			},
+			expectedQuality:   "good",
+			expectedNearEmpty: []string{"i"},
+		},
+		{
+			name: "poor scan",
+			item: models.ParsedItem{
+				Pages:       make([]string, 4),
+				PageNumbers: pageNumbers,
+				IsScanned:   true,
+				PageQuality: []models.PageQuality{{IsScanned: true}, {IsScanned: true, NearEmpty: true}, {IsScanned: true, NearEmpty: true}, {IsScanned: true}},
+			},
+			expectedQuality:   "poor",
+			expectedNearEmpty: []string{"1", "2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quality, nearEmpty := assessScanQuality(&tt.item)
+			if quality != tt.expectedQuality {
+				t.Errorf("Expected scan quality %q, got %q", tt.expectedQuality, quality)
+			}
+			if !reflect.DeepEqual(nearEmpty, tt.expectedNearEmpty) {
+				t.Errorf("Expected near-empty pages %v, got %v", tt.expectedNearEmpty, nearEmpty)
+			}
+		})
+	}
+}
+
+func TestParseResult(t *testing.T) {
+	item := &models.ParsedItem{
+		Metadata:      models.ItemMetadata{Title: "Notes on Notes", DOI: "10.1000/notes", MetadataSource: "merged"},
+		Pages:         []string{"one", "two"},
+		PageNumbers:   []string{"41", "42"},
+		PageNumbering: models.PageNumberingSource,
+		References:    []models.Reference{{ReferenceText: "Ref"}},
+		Footnotes:     []models.Footnote{{Marker: "1", Text: "A"}, {Marker: "2", Text: "B"}},
+		Endnotes:      []models.Endnote{{Marker: "i", Text: "C"}},
+		InvalidDOIs:   []models.InvalidDOI{{Value: "10.x", Reason: models.DOIMalformed, Source: "extracted"}},
+	}
+	got := parseResult("doc-1", item)
+	if got.DocumentID != "doc-1" || got.PageCount != 2 || got.RefCount != 1 {
+		t.Errorf("Expected document doc-1 with 2 pages and 1 reference, got %+v", got)
+	}
+	if got.FootnoteCount != 2 || got.EndnoteCount != 1 {
+		t.Errorf("Expected 2 footnotes and 1 endnote, got %d and %d", got.FootnoteCount, got.EndnoteCount)
+	}
+	if got.PageNumbering != models.PageNumberingSource || got.MetadataSource != "merged" || !got.HasDOI {
+		t.Errorf("Expected source numbering, merged metadata, and a DOI, got %q, %q, %v", got.PageNumbering, got.MetadataSource, got.HasDOI)
+	}
+	if len(got.InvalidDOIs) != 1 {
+		t.Errorf("Expected the item's invalid DOI, got %v", got.InvalidDOIs)
+	}
+
+	got = parseResult("doc-2", &models.ParsedItem{Pages: []string{"text"}, Metadata: models.ItemMetadata{MetadataSource: "extracted"}})
+	if got.HasDOI || got.PageNumbering != "" || got.FootnoteCount != 0 {
+		t.Errorf("Expected no DOI, numbering, or notes, got %+v", got)
+	}
+}
+
+func TestDegradedPages(t *testing.T) {
+	item := &models.ParsedItem{
+		Pages:       make([]string, 3),
+		PageNumbers: []string{"12", "", "14"},
+		PageQuality: []models.PageQuality{{}, {Degraded: true}, {Degraded: true}},
+	}
+	if got := degradedPages(item); !reflect.DeepEqual(got, []string{"2", "14"}) {
+		t.Errorf("Expected degraded pages [2 14], got %v", got)
+	}
+	if got := degradedPages(&models.ParsedItem{Pages: make([]string, 2)}); got != nil {
+		t.Errorf("Expected no degraded pages, got %v", got)
+	}
+}
+
+func TestDocumentParseToolHandler_ErrorCodes(t *testing.T) {
+	// OpenAI rejects every request, as it does with an invalid key or exhausted quota
+	openAI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":{"message":"Incorrect API key provided","type":"invalid_request_error","code":"invalid_api_key"}}`))
+	}))
+	defer openAI.Close()
+	zotero := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/items/MISSING"):
+			w.WriteHeader(http.StatusNotFound)
+		case strings.HasSuffix(r.URL.Path, "/items/PARENT"):
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"key":"PARENT","data":{"key":"PARENT","itemType":"journalArticle"}}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer zotero.Close()
+
+	t.Setenv("OPENAI_API_KEY", "test-key")
+	t.Setenv("OPENAI_BASE_URL", openAI.URL)
+	t.Setenv("ZOTERO_API_BASE_URL", zotero.URL)
+	t.Setenv("ZOTERO_API_KEY", "test-key")
+	t.Setenv("ZOTERO_LIBRARY_TYPE", "user")
+	t.Setenv("ZOTERO_LIBRARY_ID", "111")
+
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(":memory:", log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	inputs := []DocumentParseInput{
+		{},
+		{RawData: []byte("PK"), DocType: "docx"},
+		{RawData: []byte("# Findings\n\nSoil carbon declined."), DocType: "md"},
+		{ZoteroID: "MISSING"},
+		{ZoteroID: "PARENT"},
+		{ZoteroID: "BROKEN"},
+		{ZoteroID: "ATT1", LibraryType: "team"},
+		{RawData: []byte("# Findings"), DocType: "md", PageStart: 2},
+	}
+	expected := []models.ErrorCode{
+		models.ErrorInvalidInput,   // No source
+		models.ErrorInvalidInput,   // Unsupported document type
+		models.ErrorUpstreamLLM,    // OpenAI rejected the request
+		models.ErrorNotFound,       // No such Zotero item
+		models.ErrorInvalidInput,   // A Zotero item that is not an attachment
+		models.ErrorUpstreamZotero, // Zotero failed
+		models.ErrorInvalidInput,   // Invalid library type
+		models.ErrorInvalidInput,   // A page range of a document that is not a PDF
+	}
+
+	result, response, err := DocumentParseToolHandler(context.Background(), nil, DocumentParseQuery{Documents: inputs}, store, nil, log)
+	if err != nil || result != nil {
+		t.Fatalf("Expected per-document errors, got result %+v and error %v", result, err)
+	}
+	for i, res := range response.Results {
+		if res.ErrorDetail == nil || res.ErrorDetail.Code != expected[i] {
+			t.Errorf("Document %d: expected error code %s, got %+v (error: %s)", i, expected[i], res.ErrorDetail, res.Error)
+			continue
+		}
+		if !strings.Contains(res.Error, res.ErrorDetail.Message) {
+			t.Errorf("Document %d: expected error %q to include the detail message %q", i, res.Error, res.ErrorDetail.Message)
+		}
+	}
+}
+
+func TestDocumentParseToolHandler_Cancelled(t *testing.T) {
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(":memory:", log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, _, err := DocumentParseToolHandler(ctx, nil, DocumentParseQuery{RawData: []byte("text")}, store, nil, log)
+	if err != nil {
+		t.Fatalf("Expected an error result, got error: %v", err)
+	}
+	if toolErr := resultError(t, result); toolErr.Code != models.ErrorCancelled {
+		t.Errorf("Expected cancelled error, got %+v", toolErr)
+	}
+}
+
+func TestDocumentParseToolHandler_Async(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(":memory:", log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+	jobs := operations.NewJobRunner(store, log)
+	ctx := context.Background()
+
+	result, response, err := DocumentParseToolHandler(ctx, nil, DocumentParseQuery{RawData: []byte("Some text"), Async: true}, store, jobs, log)
+	if err != nil || result != nil {
+		t.Fatalf("Expected the job to be queued, got %+v, %v", result, err)
+	}
+	if response.Job == nil || response.Job.ID == "" || response.Count != 1 || len(response.Results) != 0 {
+		t.Fatalf("Expected a queued job in place of results, got %+v", response)
+	}
+
+	result, _, _ = DocumentParseToolHandler(ctx, nil, DocumentParseQuery{RawData: []byte("Some text"), Async: true, VerifyDOIs: true}, store, jobs, log)
+	if toolErr := resultError(t, result); toolErr.Code != models.ErrorInvalidInput {
+		t.Errorf("Expected invalid_input for async with verify_dois, got %+v", toolErr)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if err := jobs.Wait(waitCtx); err != nil {
+		t.Fatalf("Job did not finish: %v", err)
+	}
+
+	// Without an API key the document is parsed without the model
+	result, status, err := JobStatusToolHandler(ctx, nil, JobStatusQuery{JobID: response.Job.ID}, store, log)
+	if err != nil || result != nil {
+		t.Fatalf("JobStatusToolHandler failed: %+v, %v", result, err)
+	}
+	if status.Job.Status != models.JobCompleted || status.Job.Items[0].Status != models.JobDone {
+		t.Fatalf("Expected a completed job whose document is done, got %+v", status.Job)
+	}
+	if item, err := store.GetParsedItem(ctx, status.Job.Items[0].DocumentID); err != nil || !item.Basic {
+		t.Errorf("Expected a basic document, got %+v, %v", item, err)
+	}
+
+	// Cancelling a finished job leaves its results alone
+	_, cancelled, err := JobCancelToolHandler(ctx, nil, JobCancelQuery{JobID: response.Job.ID}, jobs, log)
+	if err != nil || cancelled.Job.Items[0].Status != models.JobDone {
+		t.Errorf("Expected the finished job unchanged, got %+v, %v", cancelled, err)
+	}
+
+	tests := []struct {
+		name     string
+		call     func() *mcp.CallToolResult
+		expected models.ErrorCode
+	}{
+		{"async without a runner", func() *mcp.CallToolResult {
+			result, _, _ := DocumentParseToolHandler(ctx, nil, DocumentParseQuery{URL: "https://example.com/a.pdf", Async: true}, store, nil, log)
+			return result
+		}, models.ErrorInvalidInput},
+		{"async without a source", func() *mcp.CallToolResult {
+			result, _, _ := DocumentParseToolHandler(ctx, nil, DocumentParseQuery{Async: true}, store, jobs, log)
+			return result
+		}, models.ErrorInvalidInput},
+		{"async in metadata mode", func() *mcp.CallToolResult {
+			result, _, _ := DocumentParseToolHandler(ctx, nil, DocumentParseQuery{RawData: []byte("Some text"), Async: true, Mode: "metadata"}, store, jobs, log)
+			return result
+		}, models.ErrorInvalidInput},
+		{"unknown mode", func() *mcp.CallToolResult {
+			result, _, _ := DocumentParseToolHandler(ctx, nil, DocumentParseQuery{RawData: []byte("Some text"), Mode: "abstract"}, store, jobs, log)
+			return result
+		}, models.ErrorInvalidInput},
+		{"unknown parser", func() *mcp.CallToolResult {
+			result, _, _ := DocumentParseToolHandler(ctx, nil, DocumentParseQuery{RawData: []byte("Some text"), Parser: "ocr"}, store, jobs, log)
+			return result
+		}, models.ErrorInvalidInput},
+		{"basic parser in metadata mode", func() *mcp.CallToolResult {
+			result, _, _ := DocumentParseToolHandler(ctx, nil, DocumentParseQuery{RawData: []byte("Some text"), Parser: "basic", Mode: "metadata"}, store, jobs, log)
+			return result
+		}, models.ErrorInvalidInput},
+		{"async with the basic parser", func() *mcp.CallToolResult {
+			result, _, _ := DocumentParseToolHandler(ctx, nil, DocumentParseQuery{RawData: []byte("Some text"), Parser: "basic", Async: true}, store, jobs, log)
+			return result
+		}, models.ErrorInvalidInput},
+		{"missing job status", func() *mcp.CallToolResult {
+			result, _, _ := JobStatusToolHandler(ctx, nil, JobStatusQuery{JobID: "job_missing"}, store, log)
+			return result
+		}, models.ErrorNotFound},
+		{"missing job cancel", func() *mcp.CallToolResult {
+			result, _, _ := JobCancelToolHandler(ctx, nil, JobCancelQuery{JobID: "job_missing"}, jobs, log)
+			return result
+		}, models.ErrorNotFound},
+		{"no job ID", func() *mcp.CallToolResult {
+			result, _, _ := JobStatusToolHandler(ctx, nil, JobStatusQuery{}, store, log)
+			return result
+		}, models.ErrorInvalidInput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if toolErr := resultError(t, tt.call()); toolErr.Code != tt.expected {
+				t.Errorf("Expected %s error, got %+v", tt.expected, toolErr)
+			}
+		})
+	}
+}
+
+func TestDocumentParseToolHandler_BasicParser(t *testing.T) {
+	// The basic parser is used on request even with an API key, and never calls the model
+	t.Setenv("OPENAI_API_KEY", "test-key")
+	t.Setenv("OPENAI_BASE_URL", "http://127.0.0.1:1")
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(":memory:", log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	text := "Memory and the Archive\nJane Smith\nhttps://doi.org/10.1234/Archive.2020.\nThe archive remembers."
+	_, response, err := DocumentParseToolHandler(context.Background(), nil, DocumentParseQuery{RawData: []byte(text), DocType: "txt", Parser: "basic"}, store, nil, log)
+	if err != nil || len(response.Results) != 1 {
+		t.Fatalf("DocumentParseToolHandler failed: %+v, %v", response, err)
+	}
+	result := response.Results[0]
+	if result.ErrorDetail != nil || !result.Basic || result.Title != "Memory and the Archive" || result.PageCount != 1 || result.Usage != nil {
+		t.Fatalf("Expected a basic parse without usage, got %+v", result)
+	}
+	item, err := store.GetParsedItem(context.Background(), result.DocumentID)
+	if err != nil {
+		t.Fatalf("GetParsedItem failed: %v", err)
+	}
+	if !item.Basic || item.Metadata.DOI != "10.1234/archive.2020" || item.Metadata.MetadataSource != "extracted-basic" {
+		t.Errorf("Expected a stored basic document with its DOI, got %+v", item.Metadata)
+	}
+}
+
+func TestDocumentParseToolHandler_PageRangeValidation(t *testing.T) {
+	log := logger.NewNoOpLogger()
+	for _, query := range []DocumentParseQuery{
+		{RawData: []byte("%PDF-1.4"), PageStart: -1},
+		{RawData: []byte("%PDF-1.4"), PageStart: 12, PageEnd: 3},
+		{Documents: []DocumentParseInput{{URL: "https://example.com/a.pdf"}, {URL: "https://example.com/b.pdf", PageStart: 5}}, Async: true},
+	} {
+		result, _, err := DocumentParseToolHandler(context.Background(), nil, query, nil, nil, log)
+		if err != nil {
+			t.Fatalf("DocumentParseToolHandler failed: %v", err)
+		}
+		if code := resultError(t, result).Code; code != models.ErrorInvalidInput {
+			t.Errorf("Expected invalid_input for %+v, got %s", query, code)
+		}
+	}
+}
+
+func TestDocumentParseToolHandler_FetchOptionsValidation(t *testing.T) {
+	log := logger.NewNoOpLogger()
+	for _, query := range []DocumentParseQuery{
+		{URL: "https://example.com/a.pdf", Headers: map[string]string{"Bad Name": "x"}},
+		{URL: "https://example.com/a.pdf", ProxyPrefix: "ezproxy.example.edu"},
+		{Documents: []DocumentParseInput{{URL: "https://example.com/a.pdf", Headers: map[string]string{"Cookie": "secret"}}}, Async: true},
+	} {
+		result, _, err := DocumentParseToolHandler(context.Background(), nil, query, nil, nil, log)
+		if err != nil {
+			t.Fatalf("DocumentParseToolHandler failed: %v", err)
+		}
+		toolErr := resultError(t, result)
+		if toolErr.Code != models.ErrorInvalidInput {
+			t.Errorf("Expected invalid_input for %+v, got %s", query, toolErr.Code)
+		}
+		if strings.Contains(toolErr.Message, "secret") {
+			t.Errorf("Expected the error not to include header values, got %q", toolErr.Message)
+		}
+	}
+}
+
+func TestSummarizePageQuality(t *testing.T) {
+	low, high := 0.2, 0.9
+
+	tests := []struct {
+		name     string
+		item     models.ParsedItem
+		expected *PageQualityStats
+	}{
+		{
+			name:     "unassessed document",
+			item:     models.ParsedItem{Pages: make([]string, 2), PageQuality: []models.PageQuality{{NearEmpty: true}, {}}},
+			expected: nil,
+		},
+		{
+			name: "nothing flagged",
+			item: models.ParsedItem{
+				Pages:       make([]string, 2),
+				PageNumbers: []string{"1", "2"},
+				PageQuality: []models.PageQuality{{ContentConfidence: &high}, {ContentConfidence: &high}},
+			},
+			expected: &PageQualityStats{AssessedPages: 2, Summary: "no pages flagged"},
+		},
+		{
+			name: "flagged pages",
+			item: models.ParsedItem{
+				Pages:       make([]string, 5),
+				PageNumbers: []string{"i", "1", "2", "3", "4"},
+				PageQuality: []models.PageQuality{
+					{ContentConfidence: &high, IsCover: true},
+					{ContentConfidence: &low},
+					{ContentConfidence: &low, IsBlank: true},
+					{ContentConfidence: &low},
+					{ContentConfidence: &high, IsReferencesOnly: true},
+				},
+			},
+			expected: &PageQualityStats{
+				AssessedPages:       5,
+				LowConfidencePages:  []string{"1", "2", "3"},
+				BlankPages:          []string{"2"},
+				CoverPages:          []string{"i"},
+				ReferencesOnlyPages: []string{"4"},
+				Summary:             "3 pages low confidence, 1 blank page, 1 cover page, 1 page of references only",
+			},
+		},
+		{
+			name: "pages parsed before assessment are not counted",
+			item: models.ParsedItem{
+				Pages:       make([]string, 3),
+				PageQuality: []models.PageQuality{{}, {ContentConfidence: &low, IsBlank: true}, {ContentConfidence: &low, IsBlank: true}},
+			},
+			expected: &PageQualityStats{
+				AssessedPages:      2,
+				LowConfidencePages: []string{"2", "3"},
+				BlankPages:         []string{"2", "3"},
+				Summary:            "2 pages low confidence, 2 blank pages",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarizePageQuality(&tt.item); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("summarizePageQuality() = %+v, expected %+v", got, tt.expected)
+			}
+		})
+	}
+}
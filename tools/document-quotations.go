@@ -2,11 +2,12 @@ package tools
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"os"
+	"strings"
 	"sync"
 
+	"github.com/Epistemic-Technology/academic-mcp/internal/config"
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
 	"github.com/Epistemic-Technology/academic-mcp/internal/llm"
 	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
 	"github.com/Epistemic-Technology/academic-mcp/internal/operations"
@@ -21,7 +22,26 @@ type DocumentQuotationsInput struct {
 	URL           string `json:"url,omitempty"`
 	RawData       []byte `json:"raw_data,omitempty"`
 	DocType       string `json:"doc_type,omitempty"`
-	MaxQuotations *int   `json:"max_quotations,omitempty"` // Default: 10, 0 = unlimited, nil = use default
+	LibraryType   string `json:"library_type,omitempty"`     // Zotero library type for zotero_id: "user" or "group"
+	LibraryID     string `json:"library_id,omitempty"`       // Zotero library ID for zotero_id
+	MaxQuotations *int   `json:"max_quotations,omitempty"`   // Default: 10, 0 = unlimited, nil = use default
+	PerPageMax    int    `json:"per_page_max,omitempty"`     // Most quotations per page, default: 3
+	MinLength     int    `json:"min_length_words,omitempty"` // Shortest quotation to keep, in words
+	Focus         string `json:"focus,omitempty"`            // Topic or research question to select quotations for
+	// Return quotations not found verbatim in the document (default: false)
+	IncludeUnverified bool `json:"include_unverified,omitempty"`
+	// Also return the quotations prioritization left out, with selected false (default: false)
+	IncludeAll bool `json:"include_all,omitempty"`
+	// Language for context, relevance, and a translation of each quotation (e.g., "en")
+	TargetLanguage string `json:"target_language,omitempty"`
+	// HTTP headers to fetch url with, e.g. a publisher session's Cookie or an Authorization; never logged or stored
+	Headers map[string]string `json:"headers,omitempty"`
+	// Prefix to fetch url through, e.g. an EZproxy login URL "https://ezproxy.example.edu/login?url="
+	ProxyPrefix string `json:"proxy_prefix,omitempty"`
+}
+
+func (inp DocumentQuotationsInput) fetchOptions() documents.FetchOptions {
+	return documents.FetchOptions{Headers: inp.Headers, ProxyPrefix: inp.ProxyPrefix}
 }
 
 type DocumentQuotationsQuery struct {
@@ -30,9 +50,28 @@ type DocumentQuotationsQuery struct {
 	URL           string `json:"url,omitempty"`
 	RawData       []byte `json:"raw_data,omitempty"`
 	DocType       string `json:"doc_type,omitempty"`
-	MaxQuotations *int   `json:"max_quotations,omitempty"` // Default: 10, 0 = unlimited, nil = use default
+	LibraryType   string `json:"library_type,omitempty"`     // Zotero library type for zotero_id: "user" or "group"
+	LibraryID     string `json:"library_id,omitempty"`       // Zotero library ID for zotero_id
+	MaxQuotations *int   `json:"max_quotations,omitempty"`   // Default: 10, 0 = unlimited, nil = use default
+	PerPageMax    int    `json:"per_page_max,omitempty"`     // Most quotations per page, default: 3
+	MinLength     int    `json:"min_length_words,omitempty"` // Shortest quotation to keep, in words
+	Focus         string `json:"focus,omitempty"`            // Topic or research question to select quotations for
+	// Return quotations not found verbatim in the document (default: false)
+	IncludeUnverified bool `json:"include_unverified,omitempty"`
+	// Also return the quotations prioritization left out, with selected false (default: false)
+	IncludeAll bool `json:"include_all,omitempty"`
+	// Language for context, relevance, and a translation of each quotation (e.g., "en")
+	TargetLanguage string `json:"target_language,omitempty"`
+	// HTTP headers to fetch url with, e.g. a publisher session's Cookie or an Authorization; never logged or stored
+	Headers map[string]string `json:"headers,omitempty"`
+	// Prefix to fetch url through, e.g. an EZproxy login URL "https://ezproxy.example.edu/login?url="
+	ProxyPrefix string `json:"proxy_prefix,omitempty"`
 	// For multiple documents: use this field
 	Documents []DocumentQuotationsInput `json:"documents,omitempty"`
+	// Document library of this server to store the documents in and look them
+	// up from (not a Zotero library); empty for the default library. Documents
+	// outside the default library have IDs prefixed with the library name.
+	Library string `json:"library,omitempty"`
 }
 
 type DocumentQuotationsResult struct {
@@ -40,14 +79,24 @@ type DocumentQuotationsResult struct {
 	ResourcePaths  []string           `json:"resource_paths,omitempty"`
 	Title          string             `json:"title,omitempty"`
 	Citekey        string             `json:"citekey,omitempty"`
+	Language       string             `json:"language,omitempty"` // Detected language of the document
 	Quotations     []models.Quotation `json:"quotations,omitempty"`
 	QuotationCount int                `json:"quotation_count"`
-	Error          string             `json:"error,omitempty"`
+	// Quotations not found verbatim in the document; excluded from quotations
+	// unless include_unverified is set
+	UnverifiedCount int `json:"unverified_count,omitempty"`
+	// Stored quotations selected by this call because max_quotations is larger
+	// than when they were extracted
+	PromotedCount int                  `json:"promoted_count,omitempty"`
+	Usage         *models.UsageSummary `json:"usage,omitempty"` // OpenAI usage of this call, including any parse; absent for stored quotations
+	Error         string               `json:"error,omitempty"`
+	ErrorDetail   *models.ToolError    `json:"error_detail,omitempty"` // Machine-readable code and message for error
 }
 
 type DocumentQuotationsResponse struct {
 	Results []DocumentQuotationsResult `json:"results"`
 	Count   int                        `json:"count"`
+	Usage   *models.UsageSummary       `json:"usage,omitempty"` // Total OpenAI usage of this call
 }
 
 func DocumentQuotationsTool() *mcp.Tool {
@@ -57,7 +106,7 @@ func DocumentQuotationsTool() *mcp.Tool {
 	}
 	return &mcp.Tool{
 		Name:        "document-quotations",
-		Description: "Extract representative quotations from one or more documents (PDF, HTML, Markdown, plain text, or DOCX). The document is parsed and summarized first, then an LLM identifies significant quotations with page numbers (for paginated documents). The document type is automatically detected, but can be overridden with the doc_type parameter. Use max_quotations to limit results (default: 10, 0 = unlimited). If more quotations are found than the max, a second LLM pass prioritizes the most significant ones. For multiple documents, use the 'documents' field. Multiple documents are processed concurrently.",
+		Description: "Extract representative quotations from one or more documents (PDF, HTML, Markdown, plain text, or DOCX). The document is parsed and summarized first, then an LLM identifies significant quotations with page numbers (for paginated documents). The document type is automatically detected, but can be overridden with the doc_type parameter. Use max_quotations to limit results (default: 10, 0 = unlimited). If more quotations are found than the max, a second LLM pass prioritizes the most significant ones; the rest are stored too, ranked but not selected, and returned with selected false and their rank when include_all is true. A later call with a larger max_quotations promotes stored quotations by rank (reported as promoted_count) without another LLM call. Use per_page_max (default: 3) and min_length_words to control extraction, and focus (e.g., \"methodological limitations\") to select quotations relevant to a research question. Use target_language (e.g., \"en\") for quotations from a document in another language: quotation_text stays verbatim in the original language, a translation field is added, and context and relevance are written in the target language. Quotations are stored with the document and reused on later calls; focused and translated quotations are always extracted fresh and are not stored. Each quotation is checked against the document text and marked verified with a match_score; quotations not found verbatim are excluded unless include_unverified is true. A verified quotation has the byte offsets of the match in its page's stored text (span) and the page's page_hash; pdf://{documentId}/pages/{page_number}/span?start=..&end=..&hash=.. returns that text with its context and reports whether the page has changed since. Set library to extract quotations from documents of one of the server's libraries, as with document-parse. For multiple documents, use the 'documents' field. Multiple documents are processed concurrently.",
 		InputSchema: inputschema,
 	}
 }
@@ -65,11 +114,16 @@ func DocumentQuotationsTool() *mcp.Tool {
 func DocumentQuotationsToolHandler(ctx context.Context, req *mcp.CallToolRequest, query DocumentQuotationsQuery, store storage.Store, log logger.Logger) (*mcp.CallToolResult, *DocumentQuotationsResponse, error) {
 	log.Info("document-quotations tool called")
 
+	ctx, err := libraryContext(ctx, query.Library)
+	if err != nil {
+		return errorResult(err, models.ErrorInvalidInput), nil, nil
+	}
+
 	// Check for OpenAI API key early
-	apiKey := os.Getenv("OPENAI_API_KEY")
-	if apiKey == "" {
-		log.Error("OPENAI_API_KEY environment variable not set")
-		return nil, nil, errors.New("OPENAI_API_KEY environment variable not set")
+	apiKey, err := config.FromContext(ctx).OpenAIAPIKey()
+	if err != nil {
+		log.Error("%v", err)
+		return errorResult(err, models.ErrorConfiguration), nil, nil
 	}
 
 	// Determine if this is a single document or batch request
@@ -85,155 +139,275 @@ func DocumentQuotationsToolHandler(ctx context.Context, req *mcp.CallToolRequest
 			URL:           query.URL,
 			RawData:       query.RawData,
 			DocType:       query.DocType,
+			LibraryType:   query.LibraryType,
+			LibraryID:     query.LibraryID,
 			MaxQuotations: query.MaxQuotations,
+			PerPageMax:    query.PerPageMax,
+			MinLength:     query.MinLength,
+			Focus:         query.Focus,
+
+			IncludeUnverified: query.IncludeUnverified,
+			IncludeAll:        query.IncludeAll,
+			TargetLanguage:    query.TargetLanguage,
+			Headers:           query.Headers,
+			ProxyPrefix:       query.ProxyPrefix,
 		}}
 		log.Info("Processing single document")
 	}
+	if err := validateFetchInputs(inputs); err != nil {
+		return errorResult(err, models.ErrorInvalidInput), nil, nil
+	}
+
+	ctx, callUsage := llm.TrackUsage(ctx)
 
-	// Process documents concurrently
+	// Process documents concurrently, a few at a time across all batch calls
 	results := make([]DocumentQuotationsResult, len(inputs))
-	var wg sync.WaitGroup
 	var mu sync.Mutex
 
-	for i, input := range inputs {
-		wg.Add(1)
-		go func(idx int, inp DocumentQuotationsInput) {
-			defer wg.Done()
-
-			// Check if context is cancelled before starting
-			select {
-			case <-ctx.Done():
-				mu.Lock()
-				results[idx] = DocumentQuotationsResult{
-					Error: fmt.Sprintf("cancelled: %v", ctx.Err()),
-				}
-				mu.Unlock()
-				return
-			default:
+	operations.ForEachDocument(ctx, len(inputs), func(idx int) {
+		inp := inputs[idx]
+
+		// Check if context is cancelled before starting
+		select {
+		case <-ctx.Done():
+			mu.Lock()
+			results[idx] = DocumentQuotationsResult{
+				Error:       fmt.Sprintf("cancelled: %v", ctx.Err()),
+				ErrorDetail: toolError(ctx.Err(), models.ErrorCancelled),
 			}
+			mu.Unlock()
+			return
+		default:
+		}
 
-			// Set default max quotations if not specified
-			maxQuotations := 10 // default
-			if inp.MaxQuotations != nil {
-				maxQuotations = *inp.MaxQuotations
-				if maxQuotations < 0 {
-					maxQuotations = 10 // Negative values default to 10
-				}
+		// Set default max quotations if not specified
+		maxQuotations := 10 // default
+		if inp.MaxQuotations != nil {
+			maxQuotations = *inp.MaxQuotations
+			if maxQuotations < 0 {
+				maxQuotations = 10 // Negative values default to 10
 			}
+		}
 
-			// Use the shared helper to get or parse the document
-			docID, parsedItem, err := operations.GetOrParseDocument(ctx, inp.ZoteroID, inp.URL, inp.RawData, inp.DocType, store, log)
-			if err != nil {
-				log.Error("Failed to get or parse document %d: %v", idx, err)
-				mu.Lock()
-				results[idx] = DocumentQuotationsResult{
-					Error: fmt.Sprintf("failed to parse: %v", err),
-				}
-				mu.Unlock()
-				return
+		docCtx, docUsage := llm.TrackUsage(fetchContext(ctx, inp))
+		defer func() {
+			mu.Lock()
+			results[idx].Usage = llm.SummarizeUsage(docUsage.Usage(), log)
+			mu.Unlock()
+		}()
+
+		// Use the shared helper to get or parse the document
+		docID, parsedItem, err := operations.GetOrParseDocument(docCtx, inp.ZoteroID, inp.URL, inp.RawData, inp.DocType, models.ZoteroLibrary{Type: inp.LibraryType, ID: inp.LibraryID}, store, log)
+		if err != nil {
+			log.Error("Failed to get or parse document %d: %v", idx, err)
+			mu.Lock()
+			results[idx] = DocumentQuotationsResult{
+				Error:       fmt.Sprintf("failed to parse: %v", err),
+				ErrorDetail: toolError(err, models.ErrorInternal),
 			}
+			mu.Unlock()
+			return
+		}
+		if parsedItem.Partial {
+			err := partialDocumentError(docID)
+			mu.Lock()
+			results[idx] = DocumentQuotationsResult{
+				DocumentID:  docID,
+				Title:       parsedItem.Metadata.Title,
+				Error:       err.Error(),
+				ErrorDetail: toolError(err, models.ErrorInvalidInput),
+			}
+			mu.Unlock()
+			return
+		}
+
+		// Calculate resource paths for accessing the document content
+		resourcePaths := storage.CalculateResourcePaths(docID, parsedItem)
+
+		// Quotations stored with the document are general-purpose and untranslated,
+		// so a focused or translated request always extracts its own and does not
+		// replace them
+		focused := strings.TrimSpace(inp.Focus) != ""
+		translated := translationRequested(inp.TargetLanguage, parsedItem.Metadata.Language)
 
-			// Calculate resource paths for accessing the document content
-			resourcePaths := storage.CalculateResourcePaths(docID, parsedItem)
-
-			// Check if quotations already exist for this document
-			if len(parsedItem.Quotations) > 0 {
-				log.Info("Document %s already has %d quotations, returning existing quotations", docID, len(parsedItem.Quotations))
-				mu.Lock()
-				results[idx] = DocumentQuotationsResult{
-					DocumentID:     docID,
-					ResourcePaths:  resourcePaths,
-					Title:          parsedItem.Metadata.Title,
-					Citekey:        parsedItem.Metadata.Citekey,
-					Quotations:     parsedItem.Quotations,
-					QuotationCount: len(parsedItem.Quotations),
+		// Check if quotations already exist for this document
+		if len(parsedItem.Quotations) > 0 && !focused && !translated {
+			log.Info("Document %s already has %d quotations, returning existing quotations", docID, len(parsedItem.Quotations))
+			// A larger max than the quotations were selected for promotes those
+			// prioritization left out rather than extracting again
+			promoted := llm.PromoteQuotations(parsedItem.Quotations, maxQuotations)
+			if len(promoted) > 0 {
+				log.Info("Promoted %d stored quotations of document %s for a max of %d", len(promoted), docID, maxQuotations)
+				if err := store.SelectQuotations(ctx, docID, promoted); err != nil {
+					log.Warn("Failed to store the promoted quotations of document %s: %v", docID, err)
 				}
-				mu.Unlock()
-				return
 			}
+			// Verification is cheap, so quotations stored before it existed are checked too
+			verified := documents.VerifyQuotations(returnedQuotations(parsedItem.Quotations, inp.IncludeAll), parsedItem.Pages, parsedItem.PageNumbers)
+			returned, unverified := selectVerifiedQuotations(verified, inp.IncludeUnverified)
+			mu.Lock()
+			results[idx] = DocumentQuotationsResult{
+				DocumentID:      docID,
+				ResourcePaths:   resourcePaths,
+				Title:           parsedItem.Metadata.Title,
+				Citekey:         parsedItem.Metadata.Citekey,
+				Language:        parsedItem.Metadata.Language,
+				Quotations:      returned,
+				QuotationCount:  len(returned),
+				UnverifiedCount: unverified,
+				PromotedCount:   len(promoted),
+			}
+			mu.Unlock()
+			return
+		}
 
-			// Generate summary first (needed for quotation extraction context)
-			log.Info("Generating summary for document %s", docID)
-			summary, err := llm.SummarizeItem(ctx, apiKey, parsedItem, log)
-			if err != nil {
-				log.Error("Failed to generate summary for document %s: %v", docID, err)
-				mu.Lock()
-				results[idx] = DocumentQuotationsResult{
-					DocumentID: docID,
-					Title:      parsedItem.Metadata.Title,
-					Error:      fmt.Sprintf("failed to generate summary: %v", err),
-				}
-				mu.Unlock()
-				return
+		// The summary and extraction requests are both recorded as quotations usage
+		quotationsCtx, quotationsUsage := llm.TrackUsage(docCtx)
+		defer operations.RecordUsage(ctx, store, docID, operations.UsageQuotations, quotationsUsage, log)
+
+		// Generate summary first (needed for quotation extraction context)
+		log.Info("Generating summary for document %s", docID)
+		summary, err := llm.SummarizeItem(quotationsCtx, apiKey, parsedItem, llm.SummaryOptions{}, log)
+		if err != nil {
+			log.Error("Failed to generate summary for document %s: %v", docID, err)
+			mu.Lock()
+			results[idx] = DocumentQuotationsResult{
+				DocumentID:  docID,
+				Title:       parsedItem.Metadata.Title,
+				Error:       fmt.Sprintf("failed to generate summary: %v", err),
+				ErrorDetail: toolError(err, models.ErrorUpstreamLLM),
 			}
+			mu.Unlock()
+			return
+		}
 
-			// Extract quotations using the summary as context
-			log.Info("Extracting quotations for document %s (max: %d)", docID, maxQuotations)
-			quotations, err := llm.ExtractQuotations(ctx, apiKey, parsedItem, summary, maxQuotations, log)
-			if err != nil {
-				log.Error("Failed to extract quotations for document %s: %v", docID, err)
-				mu.Lock()
-				results[idx] = DocumentQuotationsResult{
-					DocumentID: docID,
-					Title:      parsedItem.Metadata.Title,
-					Error:      fmt.Sprintf("failed to extract quotations: %v", err),
-				}
-				mu.Unlock()
-				return
+		// Extract quotations using the summary as context
+		log.Info("Extracting quotations for document %s (max: %d)", docID, maxQuotations)
+		quotations, err := llm.ExtractQuotations(quotationsCtx, apiKey, parsedItem, summary, llm.QuotationOptions{
+			MaxQuotations:  maxQuotations,
+			PerPageMax:     inp.PerPageMax,
+			MinLengthWords: inp.MinLength,
+			Focus:          inp.Focus,
+			TargetLanguage: targetLanguage(inp.TargetLanguage, translated),
+		}, log)
+		if err != nil {
+			log.Error("Failed to extract quotations for document %s: %v", docID, err)
+			mu.Lock()
+			results[idx] = DocumentQuotationsResult{
+				DocumentID:  docID,
+				Title:       parsedItem.Metadata.Title,
+				Error:       fmt.Sprintf("failed to extract quotations: %v", err),
+				ErrorDetail: toolError(err, models.ErrorUpstreamLLM),
 			}
+			mu.Unlock()
+			return
+		}
 
-			// Update the parsed item with quotations
-			parsedItem.Quotations = quotations
+		// Check the quotations against the source text to catch paraphrases
+		quotations = documents.VerifyQuotations(quotations, parsedItem.Pages, parsedItem.PageNumbers)
+		returned, unverified := selectVerifiedQuotations(returnedQuotations(quotations, inp.IncludeAll), inp.IncludeUnverified)
+		if unverified > 0 {
+			log.Warn("%d of %d returned quotations for document %s were not found verbatim in the source text", unverified, unverified+len(returned), docID)
+		}
 
-			// Store the updated parsed item (with quotations) back to the database
-			sourceInfo := &models.SourceInfo{
-				ZoteroID: inp.ZoteroID,
-				URL:      inp.URL,
-			}
-			err = store.StoreParsedItem(ctx, docID, parsedItem, sourceInfo)
-			if err != nil {
-				log.Error("Failed to store quotations for document %s: %v", docID, err)
-				mu.Lock()
-				results[idx] = DocumentQuotationsResult{
-					DocumentID:     docID,
-					Title:          parsedItem.Metadata.Title,
-					Quotations:     quotations,
-					QuotationCount: len(quotations),
-					Error:          fmt.Sprintf("warning: quotations extracted but not stored: %v", err),
-				}
-				mu.Unlock()
-				return
+		if focused || translated {
+			log.Info("Extracted %d quotations for document %s with focus %q and target language %q (not stored)", len(quotations), docID, inp.Focus, inp.TargetLanguage)
+			mu.Lock()
+			results[idx] = DocumentQuotationsResult{
+				DocumentID:      docID,
+				ResourcePaths:   resourcePaths,
+				Title:           parsedItem.Metadata.Title,
+				Citekey:         parsedItem.Metadata.Citekey,
+				Language:        parsedItem.Metadata.Language,
+				Quotations:      returned,
+				QuotationCount:  len(returned),
+				UnverifiedCount: unverified,
 			}
+			mu.Unlock()
+			return
+		}
 
-			log.Info("Successfully extracted and stored %d quotations for document %s", len(quotations), docID)
+		// Update the parsed item with quotations, including those left out, for a
+		// larger max to promote
+		parsedItem.Quotations = quotations
 
+		// Store the updated parsed item (with quotations) back to the database
+		sourceInfo := &models.SourceInfo{
+			ZoteroID: inp.ZoteroID,
+			URL:      inp.URL,
+		}
+		err = store.StoreParsedItem(ctx, docID, parsedItem, sourceInfo)
+		if err != nil {
+			log.Error("Failed to store quotations for document %s: %v", docID, err)
 			mu.Lock()
 			results[idx] = DocumentQuotationsResult{
-				DocumentID:     docID,
-				ResourcePaths:  resourcePaths,
-				Title:          parsedItem.Metadata.Title,
-				Citekey:        parsedItem.Metadata.Citekey,
-				Quotations:     quotations,
-				QuotationCount: len(quotations),
+				DocumentID:      docID,
+				Title:           parsedItem.Metadata.Title,
+				Quotations:      returned,
+				QuotationCount:  len(returned),
+				UnverifiedCount: unverified,
+				Error:           fmt.Sprintf("warning: quotations extracted but not stored: %v", err),
+				ErrorDetail:     toolError(err, models.ErrorStorage),
 			}
 			mu.Unlock()
-		}(i, input)
-	}
+			return
+		}
+
+		log.Info("Successfully extracted and stored %d quotations for document %s", len(quotations), docID)
 
-	// Wait for all goroutines to complete
-	wg.Wait()
+		mu.Lock()
+		results[idx] = DocumentQuotationsResult{
+			DocumentID:      docID,
+			ResourcePaths:   resourcePaths,
+			Title:           parsedItem.Metadata.Title,
+			Citekey:         parsedItem.Metadata.Citekey,
+			Language:        parsedItem.Metadata.Language,
+			Quotations:      returned,
+			QuotationCount:  len(returned),
+			UnverifiedCount: unverified,
+		}
+		mu.Unlock()
+	})
 
 	// Check if context was cancelled
 	if ctx.Err() != nil {
 		log.Error("document-quotations tool cancelled: %v", ctx.Err())
-		return nil, nil, ctx.Err()
+		return errorResult(ctx.Err(), models.ErrorCancelled), nil, nil
 	}
 
 	responseData := &DocumentQuotationsResponse{
 		Results: results,
 		Count:   len(results),
+		Usage:   llm.SummarizeUsage(callUsage.Usage(), log),
 	}
 
 	log.Info("Successfully processed %d documents", len(results))
 	return nil, responseData, nil
 }
+
+// returnedQuotations returns the selected quotations, or all of them if
+// includeAll is set
+func returnedQuotations(quotations []models.Quotation, includeAll bool) []models.Quotation {
+	if includeAll {
+		return quotations
+	}
+	return llm.SelectedQuotations(quotations)
+}
+
+// selectVerifiedQuotations returns the quotations to include in a response and
+// the number that could not be verified against the source text. Unverified
+// quotations are dropped unless includeUnverified is set.
+func selectVerifiedQuotations(quotations []models.Quotation, includeUnverified bool) ([]models.Quotation, int) {
+	selected := make([]models.Quotation, 0, len(quotations))
+	unverified := 0
+	for _, q := range quotations {
+		if !q.Verified {
+			unverified++
+			if !includeUnverified {
+				continue
+			}
+		}
+		selected = append(selected, q)
+	}
+	return selected, unverified
+}
@@ -0,0 +1,263 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestSelectVerifiedQuotations(t *testing.T) {
+	quotations := []models.Quotation{
+		{QuotationText: "Verified one", Verified: true, MatchScore: 1},
+		{QuotationText: "Paraphrased", MatchScore: 0.5},
+		{QuotationText: "Verified two", Verified: true, MatchScore: 0.95},
+	}
+
+	tests := []struct {
+		name              string
+		includeUnverified bool
+		expected          []string
+	}{
+		{"excluded by default", false, []string{"Verified one", "Verified two"}},
+		{"included on request", true, []string{"Verified one", "Paraphrased", "Verified two"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, unverified := selectVerifiedQuotations(quotations, tt.includeUnverified)
+			if unverified != 1 {
+				t.Errorf("Expected 1 unverified quotation, got %d", unverified)
+			}
+			if len(selected) != len(tt.expected) {
+				t.Fatalf("Expected %d quotations, got %+v", len(tt.expected), selected)
+			}
+			for i, q := range selected {
+				if q.QuotationText != tt.expected[i] {
+					t.Errorf("Quotation %d: expected %q, got %q", i, tt.expected[i], q.QuotationText)
+				}
+			}
+		})
+	}
+}
+
+func TestDocumentQuotationsToolHandler_ErrorCodes(t *testing.T) {
+	openAI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":{"message":"Incorrect API key provided","type":"invalid_request_error","code":"invalid_api_key"}}`))
+	}))
+	defer openAI.Close()
+	t.Setenv("OPENAI_BASE_URL", openAI.URL)
+
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(":memory:", log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	rawData := []byte("Soil carbon declined after repeated droughts.")
+	docID := storage.GenerateDocumentID(&models.SourceInfo{}, models.DocumentData{Data: rawData})
+	item := &models.ParsedItem{Metadata: models.ItemMetadata{Title: "Drought legacies"}, Pages: []string{string(rawData)}, PageNumbers: []string{"1"}}
+	if err := store.StoreParsedItem(ctx, docID, item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+
+	t.Setenv("OPENAI_API_KEY", "")
+	result, _, err := DocumentQuotationsToolHandler(ctx, nil, DocumentQuotationsQuery{RawData: rawData}, store, log)
+	if err != nil {
+		t.Fatalf("Expected an error result, got error: %v", err)
+	}
+	if toolErr := resultError(t, result); toolErr.Code != models.ErrorConfiguration {
+		t.Errorf("Expected configuration error without an API key, got %+v", toolErr)
+	}
+
+	// The stored document is found, but summarizing it fails
+	t.Setenv("OPENAI_API_KEY", "test-key")
+	result, response, err := DocumentQuotationsToolHandler(ctx, nil, DocumentQuotationsQuery{RawData: rawData}, store, log)
+	if err != nil || result != nil {
+		t.Fatalf("Expected a per-document error, got result %+v and error %v", result, err)
+	}
+	detail := response.Results[0].ErrorDetail
+	if response.Results[0].DocumentID != docID || detail == nil || detail.Code != models.ErrorUpstreamLLM {
+		t.Errorf("Expected upstream_llm error for %s, got %+v", docID, response.Results[0])
+	}
+}
+
+func TestTranslationRequested(t *testing.T) {
+	tests := []struct {
+		target   string
+		document string
+		expected bool
+	}{
+		{"", "de", false},
+		{"  ", "de", false},
+		{"en", "de", true},
+		{"English", "en", false},
+		{"en-GB", "en", false},
+		{"en", "", true},
+		{"Elvish", "en", true},
+	}
+	for _, tt := range tests {
+		if got := translationRequested(tt.target, tt.document); got != tt.expected {
+			t.Errorf("translationRequested(%q, %q) = %v, want %v", tt.target, tt.document, got, tt.expected)
+		}
+	}
+}
+
+func TestDocumentQuotationsToolHandler_TargetLanguage(t *testing.T) {
+	var requests []string
+	openAI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, string(body))
+		output := "Die Studie untersucht Archive."
+		if strings.Contains(string(body), `"quotations"`) {
+			output = `{"quotations":[{"quotation_text":"Das Archiv spricht nicht von selbst.","page_number":"","context":"Introduction","relevance":"Premise","translation":"The archive does not speak for itself."}]}`
+		}
+		text, _ := json.Marshal(output)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"resp_1","object":"response","created_at":0,"status":"completed","model":"gpt-5-mini","output":[{"type":"message","id":"msg_1","status":"completed","role":"assistant","content":[{"type":"output_text","text":` + string(text) + `,"annotations":[]}]}]}`))
+	}))
+	defer openAI.Close()
+	t.Setenv("OPENAI_BASE_URL", openAI.URL)
+	t.Setenv("OPENAI_API_KEY", "test-key")
+
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(":memory:", log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	rawData := []byte("Das Archiv spricht nicht von selbst. Es muss befragt werden.")
+	docID := storage.GenerateDocumentID(&models.SourceInfo{}, models.DocumentData{Data: rawData})
+	stored := []models.Quotation{{QuotationText: "Es muss befragt werden."}}
+	item := &models.ParsedItem{
+		Metadata:    models.ItemMetadata{Title: "Archivstudien", Language: "de"},
+		Pages:       []string{string(rawData)},
+		PageNumbers: []string{""},
+		Quotations:  stored,
+	}
+	if err := store.StoreParsedItem(ctx, docID, item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+
+	// The document is already in the target language, so stored quotations are returned
+	_, response, err := DocumentQuotationsToolHandler(ctx, nil, DocumentQuotationsQuery{RawData: rawData, TargetLanguage: "German"}, store, log)
+	if err != nil {
+		t.Fatalf("DocumentQuotationsToolHandler failed: %v", err)
+	}
+	if len(requests) != 0 || response.Results[0].Quotations[0].QuotationText != stored[0].QuotationText || response.Results[0].Language != "de" {
+		t.Errorf("Expected stored quotations without LLM requests, got %+v after %d requests", response.Results[0], len(requests))
+	}
+
+	_, response, err = DocumentQuotationsToolHandler(ctx, nil, DocumentQuotationsQuery{RawData: rawData, TargetLanguage: "en"}, store, log)
+	if err != nil {
+		t.Fatalf("DocumentQuotationsToolHandler failed: %v", err)
+	}
+	result := response.Results[0]
+	if result.Error != "" || len(result.Quotations) != 1 {
+		t.Fatalf("Expected one translated quotation, got %+v", result)
+	}
+	if q := result.Quotations[0]; q.QuotationText != "Das Archiv spricht nicht von selbst." || q.Translation != "The archive does not speak for itself." || !q.Verified {
+		t.Errorf("Expected a verified verbatim quotation with its translation, got %+v", q)
+	}
+	if len(requests) != 2 || !strings.Contains(requests[1], "Write context and relevance in this language: en") {
+		t.Errorf("Expected a summary and an extraction request asking for English, got %d requests", len(requests))
+	}
+
+	quotations, err := store.GetQuotations(ctx, docID)
+	if err != nil {
+		t.Fatalf("GetQuotations failed: %v", err)
+	}
+	if len(quotations) != 1 || quotations[0].QuotationText != stored[0].QuotationText {
+		t.Errorf("Expected translated quotations not to replace stored ones, got %+v", quotations)
+	}
+}
+
+func TestDocumentQuotationsToolHandler_PromoteStored(t *testing.T) {
+	var requests int
+	openAI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer openAI.Close()
+	t.Setenv("OPENAI_BASE_URL", openAI.URL)
+	t.Setenv("OPENAI_API_KEY", "test-key")
+
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(":memory:", log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	rawData := []byte("First finding. Second finding. Third finding. Fourth finding.")
+	docID := storage.GenerateDocumentID(&models.SourceInfo{}, models.DocumentData{Data: rawData})
+	item := &models.ParsedItem{
+		Metadata:    models.ItemMetadata{Title: "Findings"},
+		Pages:       []string{string(rawData)},
+		PageNumbers: []string{"1"},
+		Quotations: []models.Quotation{
+			{QuotationText: "First finding.", Selected: true, Rank: 2},
+			{QuotationText: "Second finding.", Rank: 4},
+			{QuotationText: "Third finding.", Selected: true, Rank: 1},
+			{QuotationText: "Fourth finding.", Rank: 3},
+		},
+	}
+	if err := store.StoreParsedItem(ctx, docID, item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+
+	texts := func(quotations []models.Quotation) []string {
+		var texts []string
+		for _, q := range quotations {
+			texts = append(texts, q.QuotationText)
+		}
+		return texts
+	}
+
+	two, three := 2, 3
+	_, response, err := DocumentQuotationsToolHandler(ctx, nil, DocumentQuotationsQuery{RawData: rawData, MaxQuotations: &two}, store, log)
+	if err != nil {
+		t.Fatalf("DocumentQuotationsToolHandler failed: %v", err)
+	}
+	if result := response.Results[0]; result.PromotedCount != 0 || strings.Join(texts(result.Quotations), "|") != "First finding.|Third finding." {
+		t.Errorf("Expected the two selected quotations, got %+v", result)
+	}
+
+	_, response, err = DocumentQuotationsToolHandler(ctx, nil, DocumentQuotationsQuery{RawData: rawData, MaxQuotations: &three}, store, log)
+	if err != nil {
+		t.Fatalf("DocumentQuotationsToolHandler failed: %v", err)
+	}
+	if result := response.Results[0]; result.PromotedCount != 1 || strings.Join(texts(result.Quotations), "|") != "First finding.|Third finding.|Fourth finding." {
+		t.Errorf("Expected the next ranked quotation to be promoted, got %+v", result)
+	}
+	if requests != 0 {
+		t.Errorf("Expected promotion without LLM requests, got %d", requests)
+	}
+
+	// The promotion is stored, and include_all returns the rest
+	_, response, err = DocumentQuotationsToolHandler(ctx, nil, DocumentQuotationsQuery{RawData: rawData, MaxQuotations: &three, IncludeAll: true}, store, log)
+	if err != nil {
+		t.Fatalf("DocumentQuotationsToolHandler failed: %v", err)
+	}
+	result := response.Results[0]
+	if result.PromotedCount != 0 || len(result.Quotations) != 4 {
+		t.Fatalf("Expected all four quotations and nothing promoted, got %+v", result)
+	}
+	if q := result.Quotations[1]; q.Selected || q.Rank != 4 {
+		t.Errorf("Expected the unpromoted quotation with selected false and its rank, got %+v", q)
+	}
+}
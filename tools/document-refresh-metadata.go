@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/config"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/operations"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type DocumentRefreshMetadataQuery struct {
+	DocumentID  string `json:"document_id,omitempty"`
+	Citekey     string `json:"citekey,omitempty"`      // Alternative to document_id
+	KeepCitekey bool   `json:"keep_citekey,omitempty"` // Keep the citekey even if the new metadata gives another
+}
+
+type DocumentRefreshMetadataResponse struct {
+	DocumentID string               `json:"document_id"`
+	Changed    []string             `json:"changed"`               // Fields whose value changed
+	Citekey    string               `json:"citekey,omitempty"`     // The citekey after the refresh
+	OldCitekey string               `json:"old_citekey,omitempty"` // The citekey before the refresh, if it changed
+	Metadata   *models.ItemMetadata `json:"metadata"`              // Metadata after the refresh
+}
+
+func DocumentRefreshMetadataTool() *mcp.Tool {
+	inputschema, err := jsonschema.For[DocumentRefreshMetadataQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+	return &mcp.Tool{
+		Name:        "document-refresh-metadata",
+		Description: "Fetch the Zotero metadata of a document parsed from a Zotero attachment again, identified by document_id or citekey, and merge it with the metadata extracted from the document, without parsing it again. Use it when document-parse reported a metadata_warning because Zotero could not be reached, or to pick up corrections made in Zotero. Fields set with document-metadata-set keep their values; field_sources and metadata_conflicts are recomputed, and the item's tags replace the stored ones. The citekey is generated again from the new metadata and changed if it no longer fits (old_citekey is then set), unless keep_citekey is true. Returns the fields that changed and the metadata afterwards.",
+		InputSchema: inputschema,
+	}
+}
+
+func DocumentRefreshMetadataToolHandler(ctx context.Context, req *mcp.CallToolRequest, query DocumentRefreshMetadataQuery, store storage.Store, log logger.Logger) (*mcp.CallToolResult, *DocumentRefreshMetadataResponse, error) {
+	log.Info("document-refresh-metadata tool called")
+
+	if query.DocumentID == "" && query.Citekey == "" {
+		return errorResult(errors.New("document_id or citekey is required"), models.ErrorInvalidInput), nil, nil
+	}
+	zoteroAPIKey, err := config.FromContext(ctx).ZoteroAPIKey()
+	if err != nil {
+		return errorResult(err, models.ErrorConfiguration), nil, nil
+	}
+
+	docID, err := resolveDocumentID(ctx, store, "", query.DocumentID, query.Citekey)
+	if err != nil {
+		log.Error("Failed to resolve document: %v", err)
+		return errorResult(err, models.ErrorNotFound), nil, nil
+	}
+
+	result, err := operations.RefreshZoteroMetadata(ctx, zoteroAPIKey, docID, query.KeepCitekey, store, log)
+	if err != nil {
+		log.Error("Failed to refresh metadata of document %s: %v", docID, err)
+		return errorResult(err, models.ErrorUpstreamZotero), nil, nil
+	}
+
+	changed := result.Changed
+	if changed == nil {
+		changed = []string{}
+	}
+	return nil, &DocumentRefreshMetadataResponse{
+		DocumentID: docID,
+		Changed:    changed,
+		Citekey:    result.Metadata.Citekey,
+		OldCitekey: result.OldCitekey,
+		Metadata:   result.Metadata,
+	}, nil
+}
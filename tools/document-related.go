@@ -0,0 +1,80 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// defaultRelatedLimit is the number of related documents returned when
+	// limit is not specified
+	defaultRelatedLimit = 10
+	// maxRelatedLimit caps limit
+	maxRelatedLimit = 50
+)
+
+type DocumentRelatedQuery struct {
+	DocumentID string `json:"document_id,omitempty"`
+	Citekey    string `json:"citekey,omitempty"` // Alternative to document_id
+	// Document library of this server the document is in (not a Zotero
+	// library); empty for the default library
+	Library string `json:"library,omitempty"`
+	Limit   int    `json:"limit,omitempty"` // Number of related documents to return (default: 10, max: 50)
+}
+
+type DocumentRelatedResponse struct {
+	DocumentID string                   `json:"document_id"`
+	Related    []models.RelatedDocument `json:"related"`
+}
+
+func DocumentRelatedTool() *mcp.Tool {
+	inputschema, err := jsonschema.For[DocumentRelatedQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+	return &mcp.Tool{
+		Name:        "document-related",
+		Description: "Suggest stored documents related to a document, identified by document_id or citekey, from the same library. Other documents are scored on the reference DOIs and authors they share with it, whether they appeared in the same venue, and the TF-IDF similarity of their titles, abstracts, and keywords, without a model call. Returns the best limit documents (default 10, max 50) with each signal's score and what the documents share.",
+		InputSchema: inputschema,
+	}
+}
+
+func DocumentRelatedToolHandler(ctx context.Context, req *mcp.CallToolRequest, query DocumentRelatedQuery, store storage.Store, log logger.Logger) (*mcp.CallToolResult, *DocumentRelatedResponse, error) {
+	log.Info("document-related tool called")
+
+	if query.DocumentID == "" && query.Citekey == "" {
+		return errorResult(errors.New("document_id or citekey is required"), models.ErrorInvalidInput), nil, nil
+	}
+	if err := validateLibrary(query.Library); err != nil {
+		return errorResult(err, models.ErrorInvalidInput), nil, nil
+	}
+	if query.Limit < 0 || query.Limit > maxRelatedLimit {
+		return errorResult(fmt.Errorf("limit must be between 1 and %d", maxRelatedLimit), models.ErrorInvalidInput), nil, nil
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultRelatedLimit
+	}
+
+	docID, err := resolveDocumentID(ctx, store, query.Library, query.DocumentID, query.Citekey)
+	if err != nil {
+		log.Error("Failed to resolve document: %v", err)
+		return errorResult(err, models.ErrorNotFound), nil, nil
+	}
+
+	related, err := store.FindRelatedDocuments(ctx, docID, limit)
+	if err != nil {
+		log.Error("Failed to find documents related to %s: %v", docID, err)
+		return errorResult(fmt.Errorf("failed to find related documents: %w", err), models.ErrorStorage), nil, nil
+	}
+
+	log.Info("Found %d documents related to %s", len(related), docID)
+	return nil, &DocumentRelatedResponse{DocumentID: docID, Related: related}, nil
+}
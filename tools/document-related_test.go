@@ -0,0 +1,64 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestDocumentRelatedToolHandler(t *testing.T) {
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(":memory:", log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	items := map[string]*models.ParsedItem{
+		"a": {Metadata: models.ItemMetadata{Title: "Memory and the Archive", Authors: []string{"Smith, Jane"}, Citekey: "smith2020"}},
+		"b": {Metadata: models.ItemMetadata{Title: "The Archive as Memory", Authors: []string{"Smith, Jane"}}},
+		"c": {Metadata: models.ItemMetadata{Title: "Soil Carbon Dynamics", Authors: []string{"Chen, Wei"}}},
+	}
+	for docID, item := range items {
+		if err := store.StoreParsedItem(ctx, docID, item, &models.SourceInfo{}); err != nil {
+			t.Fatalf("Failed to store %s: %v", docID, err)
+		}
+	}
+
+	result, response, err := DocumentRelatedToolHandler(ctx, nil, DocumentRelatedQuery{Citekey: "smith2020"}, store, log)
+	if err != nil || result != nil {
+		t.Fatalf("DocumentRelatedToolHandler failed: %+v, %v", result, err)
+	}
+	if response.DocumentID != "a" || len(response.Related) != 1 || response.Related[0].DocumentID != "b" {
+		t.Fatalf("Expected b as the one document related to a, got %+v", response)
+	}
+	if related := response.Related[0]; related.Scores.Authors != 1 || related.Scores.Terms == 0 {
+		t.Errorf("Expected author and term scores, got %+v", related)
+	}
+
+	tests := []struct {
+		name  string
+		query DocumentRelatedQuery
+		code  models.ErrorCode
+	}{
+		{"no document", DocumentRelatedQuery{}, models.ErrorInvalidInput},
+		{"limit too large", DocumentRelatedQuery{DocumentID: "a", Limit: maxRelatedLimit + 1}, models.ErrorInvalidInput},
+		{"negative limit", DocumentRelatedQuery{DocumentID: "a", Limit: -1}, models.ErrorInvalidInput},
+		{"unknown document", DocumentRelatedQuery{DocumentID: "missing"}, models.ErrorNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := DocumentRelatedToolHandler(ctx, nil, tt.query, store, log)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if code := resultError(t, result).Code; code != tt.code {
+				t.Errorf("Expected error code %s, got %s", tt.code, code)
+			}
+		})
+	}
+}
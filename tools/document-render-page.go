@@ -0,0 +1,93 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/operations"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type DocumentRenderPageQuery struct {
+	DocumentID string `json:"document_id,omitempty"`
+	Citekey    string `json:"citekey,omitempty"` // Alternative to document_id
+	Page       string `json:"page"`              // Source page number as printed, e.g. "125" or "iv"
+}
+
+type DocumentRenderPageResponse struct {
+	DocumentID       string `json:"document_id"`
+	Page             int    `json:"page"`               // Sequential page number (1-indexed)
+	SourcePageNumber string `json:"source_page_number"` // Page number as printed in the source
+	DPI              int    `json:"dpi"`
+	Bytes            int    `json:"bytes"`
+	Cached           bool   `json:"cached"`       // Rendered by an earlier request
+	ResourceURI      string `json:"resource_uri"` // Where the PNG can be read again
+}
+
+func DocumentRenderPageTool() *mcp.Tool {
+	inputschema, err := jsonschema.For[DocumentRenderPageQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+	return &mcp.Tool{
+		Name:        "document-render-page",
+		Description: "Render a page of a stored PDF document, identified by document_id or citekey and its source page number (page), as a PNG image, to check a quotation or extraction against the page itself. The PDF is re-fetched from Zotero or its URL on the first request for a page and the image is kept for later ones; documents parsed from raw data cannot be rendered. Only scanned pages (without a text layer) can be rendered, from their page image. The resolution is set by ACADEMIC_MCP_PAGE_IMAGE_DPI (default 150). Returns the image and the pdf://{documentId}/pages/{sourcePage}/image resource it can be read from again.",
+		InputSchema: inputschema,
+	}
+}
+
+func DocumentRenderPageToolHandler(ctx context.Context, req *mcp.CallToolRequest, query DocumentRenderPageQuery, store storage.Store, log logger.Logger) (*mcp.CallToolResult, *DocumentRenderPageResponse, error) {
+	log.Info("document-render-page tool called")
+
+	if query.DocumentID == "" && query.Citekey == "" {
+		return errorResult(errors.New("document_id or citekey is required"), models.ErrorInvalidInput), nil, nil
+	}
+	page := strings.TrimSpace(query.Page)
+	if page == "" {
+		return errorResult(errors.New("page is required"), models.ErrorInvalidInput), nil, nil
+	}
+
+	docID, err := resolveDocumentID(ctx, store, "", query.DocumentID, query.Citekey)
+	if err != nil {
+		log.Error("Failed to resolve document: %v", err)
+		return errorResult(err, models.ErrorNotFound), nil, nil
+	}
+
+	image, err := operations.RenderPageImage(ctx, store, docID, page, log)
+	if err != nil {
+		log.Error("Failed to render page %s of document %s: %v", page, docID, err)
+		return errorResult(err, models.ErrorInternal), nil, nil
+	}
+
+	response := &DocumentRenderPageResponse{
+		DocumentID:       docID,
+		Page:             image.Page,
+		SourcePageNumber: image.SourcePageNumber,
+		DPI:              image.DPI,
+		Bytes:            len(image.Data),
+		Cached:           image.Cached,
+		ResourceURI:      fmt.Sprintf("pdf://%s/pages/%s/image", url.PathEscape(docID), url.PathEscape(page)),
+	}
+	// The image goes with the response as text, which clients without
+	// structured content would otherwise not see
+	text, err := json.Marshal(response)
+	if err != nil {
+		return errorResult(fmt.Errorf("failed to marshal response: %w", err), models.ErrorInternal), nil, nil
+	}
+
+	log.Info("Rendered page %s of document %s (%d bytes, cached: %t)", page, docID, len(image.Data), image.Cached)
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.ImageContent{Data: image.Data, MIMEType: "image/png"},
+			&mcp.TextContent{Text: string(text)},
+		},
+	}, response, nil
+}
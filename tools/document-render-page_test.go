@@ -0,0 +1,70 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestDocumentRenderPageToolHandler(t *testing.T) {
+	t.Setenv("ACADEMIC_MCP_PAGE_IMAGE_DPI", "")
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(":memory:", log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	item := &models.ParsedItem{
+		Metadata:    models.ItemMetadata{Title: "Field Notes", Citekey: "notes2020"},
+		Pages:       []string{"Preface", "Notes"},
+		PageNumbers: []string{"iv", "12/13"},
+	}
+	if err := store.StoreParsedItem(ctx, "doc-1", item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+	if err := store.StorePageImage(ctx, "doc-1", 2, 150, []byte("\x89PNG-page")); err != nil {
+		t.Fatalf("StorePageImage failed: %v", err)
+	}
+
+	result, response, err := DocumentRenderPageToolHandler(ctx, nil, DocumentRenderPageQuery{Citekey: "notes2020", Page: "12/13"}, store, log)
+	if err != nil || result == nil || result.IsError {
+		t.Fatalf("DocumentRenderPageToolHandler failed: %+v, %v", result, err)
+	}
+	if image, ok := result.Content[0].(*mcp.ImageContent); !ok || image.MIMEType != "image/png" || string(image.Data) != "\x89PNG-page" {
+		t.Errorf("Expected the PNG as image content, got %+v", result.Content[0])
+	}
+	if response.Page != 2 || !response.Cached || response.DPI != 150 || response.ResourceURI != "pdf://doc-1/pages/12%2F13/image" {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+
+	tests := []struct {
+		name          string
+		query         DocumentRenderPageQuery
+		expectedCode  models.ErrorCode
+		expectedError string
+	}{
+		{"missing document", DocumentRenderPageQuery{Page: "iv"}, models.ErrorInvalidInput, "document_id or citekey is required"},
+		{"missing page", DocumentRenderPageQuery{DocumentID: "doc-1"}, models.ErrorInvalidInput, "page is required"},
+		{"unknown document", DocumentRenderPageQuery{DocumentID: "missing", Page: "iv"}, models.ErrorNotFound, "not found"},
+		{"unknown page", DocumentRenderPageQuery{DocumentID: "doc-1", Page: "99"}, models.ErrorNotFound, "has no page 99"},
+		{"no source PDF", DocumentRenderPageQuery{DocumentID: "doc-1", Page: "iv"}, models.ErrorNotFound, "no source PDF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := DocumentRenderPageToolHandler(ctx, nil, tt.query, store, log)
+			if err != nil {
+				t.Fatalf("Expected an error result, got %v", err)
+			}
+			if toolErr := resultError(t, result); toolErr.Code != tt.expectedCode || !strings.Contains(toolErr.Message, tt.expectedError) {
+				t.Errorf("Expected a %s error containing %q, got %+v", tt.expectedCode, tt.expectedError, toolErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/operations"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+)
+
+type DocumentReparsePagesQuery struct {
+	DocumentID string `json:"document_id"`        // Stored PDF document to fix
+	Pages      []int  `json:"pages"`              // Sequential page numbers (1-indexed) to re-parse
+	RawData    []byte `json:"raw_data,omitempty"` // Original PDF bytes; required for documents parsed from raw data
+}
+
+type ReparsedPageResult struct {
+	Page               int    `json:"page"`
+	SourcePageNumber   string `json:"source_page_number"`
+	DetectedPageNumber string `json:"detected_page_number,omitempty"` // Set when the re-parse confidently detected a different printed page number
+	OldContentLength   int    `json:"old_content_length"`
+	NewContentLength   int    `json:"new_content_length"`
+	FootnoteCount      int    `json:"footnote_count"`
+	ReferenceCount     int    `json:"reference_count"`
+	IsScanned          bool   `json:"is_scanned,omitempty"`
+	NearEmpty          bool   `json:"near_empty,omitempty"`
+	// The model's assessment of the re-parsed page
+	ContentConfidence *float64 `json:"content_confidence,omitempty"`
+	IsBlank           bool     `json:"is_blank,omitempty"`
+	IsCover           bool     `json:"is_cover,omitempty"`
+	IsReferencesOnly  bool     `json:"is_references_only,omitempty"`
+	// The page was too large for the model and was parsed from its extracted text only
+	Degraded bool `json:"degraded,omitempty"`
+}
+
+type DocumentReparsePagesResponse struct {
+	DocumentID string               `json:"document_id"`
+	Pages      []ReparsedPageResult `json:"pages"`
+	Warnings   []string             `json:"warnings,omitempty"`
+}
+
+func DocumentReparsePagesTool() *mcp.Tool {
+	inputschema, err := jsonschema.For[DocumentReparsePagesQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+	return &mcp.Tool{
+		Name:        "document-reparse-pages",
+		Description: "Re-parse selected pages of an already parsed PDF document to fix badly extracted pages without re-parsing the whole document. Pages are sequential page numbers (1-indexed). The PDF is re-fetched from Zotero or its URL; documents parsed from raw data require raw_data. Only the selected pages' content and the footnotes, endnotes, and references on those pages are replaced; source page numbers are kept. Returns old and new content lengths per page.",
+		InputSchema: inputschema,
+	}
+}
+
+func DocumentReparsePagesToolHandler(ctx context.Context, req *mcp.CallToolRequest, query DocumentReparsePagesQuery, store storage.Store, log logger.Logger) (*mcp.CallToolResult, *DocumentReparsePagesResponse, error) {
+	log.Info("document-reparse-pages tool called")
+
+	if query.DocumentID == "" {
+		return nil, nil, fmt.Errorf("document_id is required")
+	}
+	if len(query.Pages) == 0 {
+		return nil, nil, fmt.Errorf("pages is required")
+	}
+
+	result, err := operations.ReparseDocumentPages(ctx, query.DocumentID, operations.DocumentReparseParams{
+		Pages:   query.Pages,
+		RawData: query.RawData,
+	}, store, log)
+	if err != nil {
+		log.Error("Failed to re-parse pages of document %s: %v", query.DocumentID, err)
+		return nil, nil, err
+	}
+
+	response := &DocumentReparsePagesResponse{
+		DocumentID: result.DocumentID,
+		Pages:      make([]ReparsedPageResult, len(result.Pages)),
+		Warnings:   result.Warnings,
+	}
+	for i, page := range result.Pages {
+		response.Pages[i] = ReparsedPageResult{
+			Page:               page.Page,
+			SourcePageNumber:   page.SourcePageNumber,
+			DetectedPageNumber: page.DetectedPageNumber,
+			OldContentLength:   page.OldContentLength,
+			NewContentLength:   page.NewContentLength,
+			FootnoteCount:      page.FootnoteCount,
+			ReferenceCount:     page.ReferenceCount,
+			IsScanned:          page.Quality.IsScanned,
+			NearEmpty:          page.Quality.NearEmpty,
+			ContentConfidence:  page.Quality.ContentConfidence,
+			IsBlank:            page.Quality.IsBlank,
+			IsCover:            page.Quality.IsCover,
+			IsReferencesOnly:   page.Quality.IsReferencesOnly,
+			Degraded:           page.Quality.Degraded,
+		}
+	}
+
+	log.Info("Successfully re-parsed %d pages of document %s", len(response.Pages), query.DocumentID)
+	return nil, response, nil
+}
@@ -2,11 +2,13 @@ package tools
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"os"
+	"slices"
+	"strings"
 	"sync"
 
+	"github.com/Epistemic-Technology/academic-mcp/internal/config"
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
 	"github.com/Epistemic-Technology/academic-mcp/internal/llm"
 	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
 	"github.com/Epistemic-Technology/academic-mcp/internal/operations"
@@ -16,35 +18,79 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
+// Granularities a document can be summarized at
+const (
+	granularityDocument = "document" // The whole text at once
+	granularitySections = "sections" // Each top-level section, then the whole from the section summaries
+)
+
 type DocumentSummarizeInput struct {
-	ZoteroID string `json:"zotero_id,omitempty"`
-	URL      string `json:"url,omitempty"`
-	RawData  []byte `json:"raw_data,omitempty"`
-	DocType  string `json:"doc_type,omitempty"`
+	ZoteroID    string `json:"zotero_id,omitempty"`
+	URL         string `json:"url,omitempty"`
+	RawData     []byte `json:"raw_data,omitempty"`
+	DocType     string `json:"doc_type,omitempty"`
+	LibraryType string `json:"library_type,omitempty"` // Zotero library type for zotero_id: "user" or "group"
+	LibraryID   string `json:"library_id,omitempty"`   // Zotero library ID for zotero_id
+	// Language to write the summary in (e.g., "en" or "English")
+	TargetLanguage string `json:"target_language,omitempty"`
+	Style          string `json:"style,omitempty"` // Summary style: brief, standard (default), structured, or accessible
+	// Granularity: "document" (default) summarizes the whole text at once, "sections" each top-level section and then the whole from them
+	Granularity string `json:"granularity,omitempty"`
+	// HTTP headers to fetch url with, e.g. a publisher session's Cookie or an Authorization; never logged or stored
+	Headers map[string]string `json:"headers,omitempty"`
+	// Prefix to fetch url through, e.g. an EZproxy login URL "https://ezproxy.example.edu/login?url="
+	ProxyPrefix string `json:"proxy_prefix,omitempty"`
+}
+
+func (inp DocumentSummarizeInput) fetchOptions() documents.FetchOptions {
+	return documents.FetchOptions{Headers: inp.Headers, ProxyPrefix: inp.ProxyPrefix}
 }
 
 type DocumentSummarizeQuery struct {
 	// For single document: use these fields directly
-	ZoteroID string `json:"zotero_id,omitempty"`
-	URL      string `json:"url,omitempty"`
-	RawData  []byte `json:"raw_data,omitempty"`
-	DocType  string `json:"doc_type,omitempty"`
+	ZoteroID    string `json:"zotero_id,omitempty"`
+	URL         string `json:"url,omitempty"`
+	RawData     []byte `json:"raw_data,omitempty"`
+	DocType     string `json:"doc_type,omitempty"`
+	LibraryType string `json:"library_type,omitempty"` // Zotero library type for zotero_id: "user" or "group"
+	LibraryID   string `json:"library_id,omitempty"`   // Zotero library ID for zotero_id
+	// Language to write the summary in (e.g., "en" or "English")
+	TargetLanguage string `json:"target_language,omitempty"`
+	Style          string `json:"style,omitempty"` // Summary style: brief, standard (default), structured, or accessible
+	// Granularity: "document" (default) summarizes the whole text at once, "sections" each top-level section and then the whole from them
+	Granularity string `json:"granularity,omitempty"`
+	// HTTP headers to fetch url with, e.g. a publisher session's Cookie or an Authorization; never logged or stored
+	Headers map[string]string `json:"headers,omitempty"`
+	// Prefix to fetch url through, e.g. an EZproxy login URL "https://ezproxy.example.edu/login?url="
+	ProxyPrefix string `json:"proxy_prefix,omitempty"`
 	// For multiple documents: use this field
 	Documents []DocumentSummarizeInput `json:"documents,omitempty"`
+	// Document library of this server to store the documents in and look them
+	// up from (not a Zotero library); empty for the default library. Documents
+	// outside the default library have IDs prefixed with the library name.
+	Library string `json:"library,omitempty"`
 }
 
 type DocumentSummarizeResult struct {
-	DocumentID    string   `json:"document_id,omitempty"`
-	ResourcePaths []string `json:"resource_paths,omitempty"`
-	Title         string   `json:"title,omitempty"`
-	Citekey       string   `json:"citekey,omitempty"`
-	Summary       string   `json:"summary,omitempty"`
-	Error         string   `json:"error,omitempty"`
+	DocumentID    string                  `json:"document_id,omitempty"`
+	ResourcePaths []string                `json:"resource_paths,omitempty"`
+	Title         string                  `json:"title,omitempty"`
+	Citekey       string                  `json:"citekey,omitempty"`
+	Language      string                  `json:"language,omitempty"` // Detected language of the document
+	Summary       string                  `json:"summary,omitempty"`
+	Style         string                  `json:"style,omitempty"` // Style of the summary
+	Granularity   string                  `json:"granularity,omitempty"`
+	Sections      []models.SectionSummary `json:"sections,omitempty"` // Summaries of the top-level sections the summary was composed from (granularity "sections")
+	Cached        bool                    `json:"cached"`             // Whether the summary was stored rather than generated by this call
+	Usage         *models.UsageSummary    `json:"usage,omitempty"`    // OpenAI usage of this call, including any parse; absent for cached summaries
+	Error         string                  `json:"error,omitempty"`
+	ErrorDetail   *models.ToolError       `json:"error_detail,omitempty"` // Machine-readable code and message for error
 }
 
 type DocumentSummarizeResponse struct {
 	Results []DocumentSummarizeResult `json:"results"`
 	Count   int                       `json:"count"`
+	Usage   *models.UsageSummary      `json:"usage,omitempty"` // Total OpenAI usage of this call
 }
 
 func DocumentSummarizeTool() *mcp.Tool {
@@ -52,9 +98,18 @@ func DocumentSummarizeTool() *mcp.Tool {
 	if err != nil {
 		panic(err)
 	}
+	styles := make([]any, len(models.SummaryStyles))
+	for i, style := range models.SummaryStyles {
+		styles[i] = style
+	}
+	inputschema.Properties["style"].Enum = styles
+	inputschema.Properties["documents"].Items.Properties["style"].Enum = styles
+	granularities := []any{granularityDocument, granularitySections}
+	inputschema.Properties["granularity"].Enum = granularities
+	inputschema.Properties["documents"].Items.Properties["granularity"].Enum = granularities
 	return &mcp.Tool{
 		Name:        "document-summarize",
-		Description: "Summarize one or more documents (PDF, HTML, Markdown, plain text, or DOCX) using OpenAI's GPT-5 Mini. If the document hasn't been parsed yet, it will automatically parse it first. The document type is automatically detected, but can be overridden with the doc_type parameter. For multiple documents, use the 'documents' field. Multiple documents are processed concurrently.",
+		Description: "Summarize one or more documents (PDF, HTML, Markdown, plain text, or DOCX) using OpenAI's GPT-5 Mini. If the document hasn't been parsed yet, it will automatically parse it first. The document type is automatically detected, but can be overridden with the doc_type parameter. Set style to brief (a one-sentence TL;DR), standard (1-3 paragraphs, the default), structured (aims, methods, findings, limitations), or accessible (for a non-specialist reader); a document keeps one stored summary per style, and cached tells whether it was served from the store. Use target_language (e.g., \"en\") to get the summary in another language than the document's; such translated summaries are generated fresh and not stored. Set library to summarize documents of one of the server's libraries, as with document-parse. For multiple documents, use the 'documents' field. Multiple documents are processed concurrently.",
 		InputSchema: inputschema,
 	}
 }
@@ -62,11 +117,16 @@ func DocumentSummarizeTool() *mcp.Tool {
 func DocumentSummarizeToolHandler(ctx context.Context, req *mcp.CallToolRequest, query DocumentSummarizeQuery, store storage.Store, log logger.Logger) (*mcp.CallToolResult, *DocumentSummarizeResponse, error) {
 	log.Info("document-summarize tool called")
 
+	ctx, err := libraryContext(ctx, query.Library)
+	if err != nil {
+		return errorResult(err, models.ErrorInvalidInput), nil, nil
+	}
+
 	// Check for OpenAI API key early
-	apiKey := os.Getenv("OPENAI_API_KEY")
-	if apiKey == "" {
-		log.Error("OPENAI_API_KEY environment variable not set")
-		return nil, nil, errors.New("OPENAI_API_KEY environment variable not set")
+	apiKey, err := config.FromContext(ctx).OpenAIAPIKey()
+	if err != nil {
+		log.Error("%v", err)
+		return errorResult(err, models.ErrorConfiguration), nil, nil
 	}
 
 	// Determine if this is a single document or batch request
@@ -78,130 +138,292 @@ func DocumentSummarizeToolHandler(ctx context.Context, req *mcp.CallToolRequest,
 	} else {
 		// Single document mode (backward compatible)
 		inputs = []DocumentSummarizeInput{{
-			ZoteroID: query.ZoteroID,
-			URL:      query.URL,
-			RawData:  query.RawData,
-			DocType:  query.DocType,
+			ZoteroID:    query.ZoteroID,
+			URL:         query.URL,
+			RawData:     query.RawData,
+			DocType:     query.DocType,
+			LibraryType: query.LibraryType,
+			LibraryID:   query.LibraryID,
+
+			TargetLanguage: query.TargetLanguage,
+			Style:          query.Style,
+			Granularity:    query.Granularity,
+			Headers:        query.Headers,
+			ProxyPrefix:    query.ProxyPrefix,
 		}}
 		log.Info("Processing single document")
 	}
+	if err := validateFetchInputs(inputs); err != nil {
+		return errorResult(err, models.ErrorInvalidInput), nil, nil
+	}
 
-	// Process documents concurrently
+	for _, input := range inputs {
+		if input.Style != "" && !slices.Contains(models.SummaryStyles, input.Style) {
+			return errorResult(fmt.Errorf("unknown summary style %q (use one of: %s)", input.Style, strings.Join(models.SummaryStyles, ", ")), models.ErrorInvalidInput), nil, nil
+		}
+		if input.Granularity != "" && input.Granularity != granularityDocument && input.Granularity != granularitySections {
+			return errorResult(fmt.Errorf("unknown granularity %q (use %s or %s)", input.Granularity, granularityDocument, granularitySections), models.ErrorInvalidInput), nil, nil
+		}
+	}
+
+	ctx, callUsage := llm.TrackUsage(ctx)
+
+	// Process documents concurrently, a few at a time across all batch calls
 	results := make([]DocumentSummarizeResult, len(inputs))
-	var wg sync.WaitGroup
 	var mu sync.Mutex
 
-	for i, input := range inputs {
-		wg.Add(1)
-		go func(idx int, inp DocumentSummarizeInput) {
-			defer wg.Done()
+	operations.ForEachDocument(ctx, len(inputs), func(idx int) {
+		inp := inputs[idx]
+		if inp.Style == "" {
+			inp.Style = models.SummaryStyleStandard
+		}
 
-			// Check if context is cancelled before starting
-			select {
-			case <-ctx.Done():
-				mu.Lock()
-				results[idx] = DocumentSummarizeResult{
-					Error: fmt.Sprintf("cancelled: %v", ctx.Err()),
-				}
-				mu.Unlock()
-				return
-			default:
-			}
-
-			// Use the shared helper to get or parse the document
-			docID, parsedItem, err := operations.GetOrParseDocument(ctx, inp.ZoteroID, inp.URL, inp.RawData, inp.DocType, store, log)
-			if err != nil {
-				log.Error("Failed to get or parse document %d: %v", idx, err)
-				mu.Lock()
-				results[idx] = DocumentSummarizeResult{
-					Error: fmt.Sprintf("failed to parse: %v", err),
-				}
-				mu.Unlock()
-				return
+		// Check if context is cancelled before starting
+		select {
+		case <-ctx.Done():
+			mu.Lock()
+			results[idx] = DocumentSummarizeResult{
+				Error:       fmt.Sprintf("cancelled: %v", ctx.Err()),
+				ErrorDetail: toolError(ctx.Err(), models.ErrorCancelled),
 			}
+			mu.Unlock()
+			return
+		default:
+		}
 
-			// Calculate resource paths for accessing the document content
-			resourcePaths := storage.CalculateResourcePaths(docID, parsedItem)
+		docCtx, docUsage := llm.TrackUsage(fetchContext(ctx, inp))
+		defer func() {
+			mu.Lock()
+			results[idx].Usage = llm.SummarizeUsage(docUsage.Usage(), log)
+			mu.Unlock()
+		}()
 
-			// Check if summary already exists
-			if parsedItem.Summary != "" {
-				log.Info("Document %s already has a summary, returning cached summary", docID)
-				mu.Lock()
-				results[idx] = DocumentSummarizeResult{
-					DocumentID:    docID,
-					ResourcePaths: resourcePaths,
-					Title:         parsedItem.Metadata.Title,
-					Citekey:       parsedItem.Metadata.Citekey,
-					Summary:       parsedItem.Summary,
-				}
-				mu.Unlock()
-				return
+		// Use the shared helper to get or parse the document
+		docID, parsedItem, err := operations.GetOrParseDocument(docCtx, inp.ZoteroID, inp.URL, inp.RawData, inp.DocType, models.ZoteroLibrary{Type: inp.LibraryType, ID: inp.LibraryID}, store, log)
+		if err != nil {
+			log.Error("Failed to get or parse document %d: %v", idx, err)
+			mu.Lock()
+			results[idx] = DocumentSummarizeResult{
+				Error:       fmt.Sprintf("failed to parse: %v", err),
+				ErrorDetail: toolError(err, models.ErrorInternal),
 			}
-
-			log.Info("Generating summary for document %s", docID)
-			summary, err := llm.SummarizeItem(ctx, apiKey, parsedItem, log)
-			if err != nil {
-				log.Error("Failed to generate summary for document %s: %v", docID, err)
-				mu.Lock()
-				results[idx] = DocumentSummarizeResult{
-					DocumentID: docID,
-					Title:      parsedItem.Metadata.Title,
-					Error:      fmt.Sprintf("failed to generate summary: %v", err),
-				}
-				mu.Unlock()
-				return
+			mu.Unlock()
+			return
+		}
+		if parsedItem.Partial {
+			err := partialDocumentError(docID)
+			mu.Lock()
+			results[idx] = DocumentSummarizeResult{
+				DocumentID:  docID,
+				Title:       parsedItem.Metadata.Title,
+				Error:       err.Error(),
+				ErrorDetail: toolError(err, models.ErrorInvalidInput),
 			}
+			mu.Unlock()
+			return
+		}
 
-			// Update the parsed item with the summary
-			parsedItem.Summary = summary
+		// Calculate resource paths for accessing the document content
+		resourcePaths := storage.CalculateResourcePaths(docID, parsedItem)
 
-			// Store the updated parsed item (with summary) back to the database
-			sourceInfo := &models.SourceInfo{
-				ZoteroID: inp.ZoteroID,
-				URL:      inp.URL,
-			}
-			err = store.StoreParsedItem(ctx, docID, parsedItem, sourceInfo)
+		// The stored summary is in the document's own language, so a summary in
+		// another language is always generated and does not replace it
+		translated := translationRequested(inp.TargetLanguage, parsedItem.Metadata.Language)
+
+		if inp.Granularity == granularitySections {
+			result := summarizeBySections(ctx, docCtx, apiKey, store, docID, parsedItem, inp, translated, log)
+			result.ResourcePaths = resourcePaths
+			mu.Lock()
+			results[idx] = result
+			mu.Unlock()
+			return
+		}
+
+		// Check if a summary in this style already exists
+		var cached string
+		if !translated {
+			cached, err = store.GetStyledSummary(ctx, docID, inp.Style)
 			if err != nil {
-				log.Error("Failed to store summary for document %s: %v", docID, err)
+				log.Error("Failed to read stored summary for document %s: %v", docID, err)
 				mu.Lock()
 				results[idx] = DocumentSummarizeResult{
-					DocumentID: docID,
-					Title:      parsedItem.Metadata.Title,
-					Summary:    summary,
-					Error:      fmt.Sprintf("warning: summary generated but not stored: %v", err),
+					DocumentID:  docID,
+					Title:       parsedItem.Metadata.Title,
+					Error:       fmt.Sprintf("failed to read stored summary: %v", err),
+					ErrorDetail: toolError(err, models.ErrorStorage),
 				}
 				mu.Unlock()
 				return
 			}
+		}
+		if cached != "" {
+			log.Info("Document %s already has a %s summary, returning cached summary", docID, inp.Style)
+			mu.Lock()
+			results[idx] = DocumentSummarizeResult{
+				DocumentID:    docID,
+				ResourcePaths: resourcePaths,
+				Title:         parsedItem.Metadata.Title,
+				Citekey:       parsedItem.Metadata.Citekey,
+				Language:      parsedItem.Metadata.Language,
+				Summary:       cached,
+				Style:         inp.Style,
+				Cached:        true,
+			}
+			mu.Unlock()
+			return
+		}
 
-			log.Info("Successfully generated and stored summary for document %s", docID)
+		log.Info("Generating %s summary for document %s", inp.Style, docID)
+		summaryCtx, summaryUsage := llm.TrackUsage(docCtx)
+		summary, err := llm.SummarizeItem(summaryCtx, apiKey, parsedItem, llm.SummaryOptions{TargetLanguage: targetLanguage(inp.TargetLanguage, translated), Style: inp.Style}, log)
+		operations.RecordUsage(ctx, store, docID, operations.UsageSummarize, summaryUsage, log)
+		if err != nil {
+			log.Error("Failed to generate summary for document %s: %v", docID, err)
+			mu.Lock()
+			results[idx] = DocumentSummarizeResult{
+				DocumentID:  docID,
+				Title:       parsedItem.Metadata.Title,
+				Error:       fmt.Sprintf("failed to generate summary: %v", err),
+				ErrorDetail: toolError(err, models.ErrorUpstreamLLM),
+			}
+			mu.Unlock()
+			return
+		}
 
+		if translated {
+			log.Info("Generated %s summary for document %s (not stored)", inp.TargetLanguage, docID)
 			mu.Lock()
 			results[idx] = DocumentSummarizeResult{
 				DocumentID:    docID,
 				ResourcePaths: resourcePaths,
 				Title:         parsedItem.Metadata.Title,
 				Citekey:       parsedItem.Metadata.Citekey,
+				Language:      parsedItem.Metadata.Language,
 				Summary:       summary,
+				Style:         inp.Style,
 			}
 			mu.Unlock()
-		}(i, input)
-	}
+			return
+		}
 
-	// Wait for all goroutines to complete
-	wg.Wait()
+		// Store the summary alongside those in other styles
+		err = store.StoreSummary(ctx, docID, inp.Style, summary)
+		if err != nil {
+			log.Error("Failed to store summary for document %s: %v", docID, err)
+			mu.Lock()
+			results[idx] = DocumentSummarizeResult{
+				DocumentID:  docID,
+				Title:       parsedItem.Metadata.Title,
+				Summary:     summary,
+				Style:       inp.Style,
+				Error:       fmt.Sprintf("warning: summary generated but not stored: %v", err),
+				ErrorDetail: toolError(err, models.ErrorStorage),
+			}
+			mu.Unlock()
+			return
+		}
+
+		log.Info("Successfully generated and stored summary for document %s", docID)
+
+		mu.Lock()
+		results[idx] = DocumentSummarizeResult{
+			DocumentID:    docID,
+			ResourcePaths: resourcePaths,
+			Title:         parsedItem.Metadata.Title,
+			Citekey:       parsedItem.Metadata.Citekey,
+			Language:      parsedItem.Metadata.Language,
+			Summary:       summary,
+			Style:         inp.Style,
+		}
+		mu.Unlock()
+	})
 
 	// Check if context was cancelled
 	if ctx.Err() != nil {
 		log.Error("document-summarize tool cancelled: %v", ctx.Err())
-		return nil, nil, ctx.Err()
+		return errorResult(ctx.Err(), models.ErrorCancelled), nil, nil
 	}
 
 	responseData := &DocumentSummarizeResponse{
 		Results: results,
 		Count:   len(results),
+		Usage:   llm.SummarizeUsage(callUsage.Usage(), log),
 	}
 
 	log.Info("Successfully processed %d documents", len(results))
 	return nil, responseData, nil
 }
+
+// summarizeBySections summarizes a document section by section, returning its
+// stored section summaries in the style if it has them. Like whole-document
+// summaries, translated ones are not stored.
+func summarizeBySections(ctx context.Context, docCtx context.Context, apiKey string, store storage.Store, docID string, parsedItem *models.ParsedItem, inp DocumentSummarizeInput, translated bool, log logger.Logger) DocumentSummarizeResult {
+	result := DocumentSummarizeResult{
+		DocumentID:  docID,
+		Title:       parsedItem.Metadata.Title,
+		Citekey:     parsedItem.Metadata.Citekey,
+		Language:    parsedItem.Metadata.Language,
+		Style:       inp.Style,
+		Granularity: granularitySections,
+	}
+
+	if !translated {
+		summary, sections, err := store.GetSectionSummaries(ctx, docID, inp.Style)
+		if err != nil {
+			log.Error("Failed to read stored section summaries for document %s: %v", docID, err)
+			result.Error = fmt.Sprintf("failed to read stored section summaries: %v", err)
+			result.ErrorDetail = toolError(err, models.ErrorStorage)
+			return result
+		}
+		if summary != "" {
+			log.Info("Document %s already has %s section summaries, returning cached summaries", docID, inp.Style)
+			result.Summary, result.Sections, result.Cached = summary, sections, true
+			return result
+		}
+	}
+
+	log.Info("Generating %s summary of document %s section by section", inp.Style, docID)
+	summaryCtx, summaryUsage := llm.TrackUsage(docCtx)
+	summary, sections, err := operations.SummarizeBySections(summaryCtx, apiKey, parsedItem, llm.SummaryOptions{TargetLanguage: targetLanguage(inp.TargetLanguage, translated), Style: inp.Style}, log)
+	operations.RecordUsage(ctx, store, docID, operations.UsageSummarize, summaryUsage, log)
+	if err != nil {
+		log.Error("Failed to summarize document %s by sections: %v", docID, err)
+		result.Error = fmt.Sprintf("failed to generate summary: %v", err)
+		result.ErrorDetail = toolError(err, models.ErrorUpstreamLLM)
+		return result
+	}
+	result.Summary, result.Sections = summary, sections
+
+	if translated {
+		log.Info("Generated %s section summaries for document %s (not stored)", inp.TargetLanguage, docID)
+		return result
+	}
+	if err := store.StoreSectionSummaries(ctx, docID, inp.Style, summary, sections); err != nil {
+		log.Error("Failed to store section summaries for document %s: %v", docID, err)
+		result.Error = fmt.Sprintf("warning: summary generated but not stored: %v", err)
+		result.ErrorDetail = toolError(err, models.ErrorStorage)
+		return result
+	}
+	log.Info("Successfully generated and stored %d section summaries for document %s", len(sections), docID)
+	return result
+}
+
+// translationRequested reports whether a target language was given that differs
+// from the document's detected language. A target that is not a recognized
+// language name or code is assumed to differ.
+func translationRequested(targetLanguage string, documentLanguage string) bool {
+	if strings.TrimSpace(targetLanguage) == "" {
+		return false
+	}
+	code := documents.NormalizeLanguage(targetLanguage)
+	return code == "" || code != documentLanguage
+}
+
+// targetLanguage returns the target language to pass on for a request, or ""
+// if the document is already in it
+func targetLanguage(requested string, translated bool) string {
+	if !translated {
+		return ""
+	}
+	return strings.TrimSpace(requested)
+}
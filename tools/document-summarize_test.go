@@ -0,0 +1,271 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestDocumentSummarizeToolHandler_Styles(t *testing.T) {
+	var requests []string
+	openAI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, string(body))
+		text, _ := json.Marshal("The study examines archives.")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"resp_1","object":"response","created_at":0,"status":"completed","model":"gpt-5-mini","output":[{"type":"message","id":"msg_1","status":"completed","role":"assistant","content":[{"type":"output_text","text":` + string(text) + `,"annotations":[]}]}]}`))
+	}))
+	defer openAI.Close()
+	t.Setenv("OPENAI_BASE_URL", openAI.URL)
+	t.Setenv("OPENAI_API_KEY", "test-key")
+
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(":memory:", log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	rawData := []byte("The archive does not speak for itself. It must be questioned.")
+	docID := storage.GenerateDocumentID(&models.SourceInfo{}, models.DocumentData{Data: rawData})
+	item := &models.ParsedItem{
+		Metadata:    models.ItemMetadata{Title: "Archive Studies", Language: "en"},
+		Pages:       []string{string(rawData)},
+		PageNumbers: []string{""},
+		Summary:     "Stored standard summary.",
+	}
+	if err := store.StoreParsedItem(ctx, docID, item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		style    string
+		summary  string
+		cached   bool
+		requests int
+	}{
+		{name: "default style is the stored standard summary", summary: "Stored standard summary.", cached: true},
+		{name: "new style is generated", style: "brief", summary: "The study examines archives.", requests: 1},
+		{name: "generated style is then cached", style: "brief", summary: "The study examines archives.", cached: true, requests: 1},
+		{name: "standard style is unchanged", style: "standard", summary: "Stored standard summary.", cached: true, requests: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, response, err := DocumentSummarizeToolHandler(ctx, nil, DocumentSummarizeQuery{RawData: rawData, Style: tt.style}, store, log)
+			if err != nil || result != nil {
+				t.Fatalf("DocumentSummarizeToolHandler failed: %+v, %v", result, err)
+			}
+			got := response.Results[0]
+			style := tt.style
+			if style == "" {
+				style = "standard"
+			}
+			if got.Error != "" || got.Summary != tt.summary || got.Style != style || got.Cached != tt.cached {
+				t.Errorf("Expected %q (style %s, cached %v), got %+v", tt.summary, style, tt.cached, got)
+			}
+			if len(requests) != tt.requests {
+				t.Errorf("Expected %d LLM requests in total, got %d", tt.requests, len(requests))
+			}
+		})
+	}
+
+	if !strings.Contains(requests[0], "single sentence") {
+		t.Errorf("Expected the brief prompt, got %s", requests[0])
+	}
+	if summary, err := store.GetStyledSummary(ctx, docID, "brief"); err != nil || summary != "The study examines archives." {
+		t.Errorf("Expected the brief summary to be stored, got %q (%v)", summary, err)
+	}
+
+	t.Run("unknown style", func(t *testing.T) {
+		query := DocumentSummarizeQuery{Documents: []DocumentSummarizeInput{{RawData: rawData}, {RawData: rawData, Style: "haiku"}}}
+		result, _, err := DocumentSummarizeToolHandler(ctx, nil, query, store, log)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if code := resultError(t, result).Code; code != models.ErrorInvalidInput {
+			t.Errorf("Expected error code %s, got %s", models.ErrorInvalidInput, code)
+		}
+	})
+}
+
+func TestDocumentSummarizeToolHandler_Partial(t *testing.T) {
+	// The document is refused before any request to OpenAI
+	t.Setenv("OPENAI_BASE_URL", "http://127.0.0.1:1")
+	t.Setenv("OPENAI_API_KEY", "test-key")
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(":memory:", log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	rawData := []byte("%PDF-1.7 triage copy")
+	docID := storage.GenerateDocumentID(&models.SourceInfo{}, models.DocumentData{Data: rawData})
+	item := &models.ParsedItem{Metadata: models.ItemMetadata{Title: "Archive Studies", Abstract: "On archives."}, Partial: true}
+	if err := store.StoreParsedItem(ctx, docID, item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+
+	result, response, err := DocumentSummarizeToolHandler(ctx, nil, DocumentSummarizeQuery{RawData: rawData}, store, log)
+	if err != nil || result != nil {
+		t.Fatalf("Expected a per-document error, got %+v, %v", result, err)
+	}
+	got := response.Results[0]
+	if got.ErrorDetail == nil || got.ErrorDetail.Code != models.ErrorInvalidInput || !strings.Contains(got.Error, "document-parse") {
+		t.Errorf("Expected partial document %s refused with a pointer to document-parse, got %+v", docID, got)
+	}
+	if got.DocumentID != docID || got.Summary != "" {
+		t.Errorf("Expected no summary for %s, got %+v", docID, got)
+	}
+}
+
+func TestDocumentSummarizeToolHandler_Sections(t *testing.T) {
+	// Each section is answered with a summary naming it, and the overall
+	// summary with one naming the sections it was given
+	var mu sync.Mutex
+	var requests []string
+	openAI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, string(body))
+		mu.Unlock()
+
+		output := "The study draws on all sections."
+		if strings.Contains(string(body), "summarized section by section") {
+			for _, title := range []string{"Introduction", "Methods", "Results"} {
+				if strings.Contains(string(body), `section \"`+title+`\"`) {
+					output = title + " summary."
+				}
+			}
+		}
+		text, _ := json.Marshal(output)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"resp_1","object":"response","created_at":0,"status":"completed","model":"gpt-5-mini","output":[{"type":"message","id":"msg_1","status":"completed","role":"assistant","content":[{"type":"output_text","text":` + string(text) + `,"annotations":[]}]}]}`))
+	}))
+	defer openAI.Close()
+	t.Setenv("OPENAI_BASE_URL", openAI.URL)
+	t.Setenv("OPENAI_API_KEY", "test-key")
+
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(":memory:", log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	rawData := []byte("A study of archives")
+	docID := storage.GenerateDocumentID(&models.SourceInfo{}, models.DocumentData{Data: rawData})
+	item := &models.ParsedItem{
+		Metadata: models.ItemMetadata{Title: "Archive Studies", Language: "en"},
+		Pages: []string{
+			"# Archive Studies\n\n## Introduction\n\nArchives do not speak for themselves.",
+			"## Methods\n\nWe read the records.\n\n### Sampling\n\nWe sampled boxes.",
+			"## Results\n\nThe records were incomplete.",
+		},
+		PageNumbers: []string{"1", "2", "3"},
+	}
+	if err := store.StoreParsedItem(ctx, docID, item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+
+	query := DocumentSummarizeQuery{RawData: rawData, Granularity: "sections"}
+	result, response, err := DocumentSummarizeToolHandler(ctx, nil, query, store, log)
+	if err != nil || result != nil {
+		t.Fatalf("DocumentSummarizeToolHandler failed: %+v, %v", result, err)
+	}
+	got := response.Results[0]
+	if got.Error != "" || got.Cached || got.Granularity != "sections" || got.Summary != "The study draws on all sections." {
+		t.Fatalf("Expected a generated summary composed from the sections, got %+v", got)
+	}
+	expected := []models.SectionSummary{
+		{SectionIndex: 1, Title: "Introduction", StartPage: "1", EndPage: "1", Summary: "Introduction summary."},
+		{SectionIndex: 2, Title: "Methods", StartPage: "2", EndPage: "2", Summary: "Methods summary."},
+		{SectionIndex: 4, Title: "Results", StartPage: "3", EndPage: "3", Summary: "Results summary."},
+	}
+	if !reflect.DeepEqual(got.Sections, expected) {
+		t.Errorf("Expected the top-level section summaries %+v, got %+v", expected, got.Sections)
+	}
+
+	// One request per top-level section, then one composing them
+	if len(requests) != 4 {
+		t.Fatalf("Expected 4 LLM requests, got %d", len(requests))
+	}
+	var compose []string
+	for _, request := range requests {
+		if !strings.Contains(request, "summarized section by section") {
+			compose = append(compose, request)
+		} else if strings.Contains(request, "Sampling") != strings.Contains(request, `section \"Methods\"`) {
+			t.Errorf("Expected only the Methods section to include its subsection, got %s", request)
+		}
+	}
+	if len(compose) != 1 {
+		t.Fatalf("Expected one request composing the summary, got %d", len(compose))
+	}
+	for _, part := range []string{`## Introduction (p. 1)\n\nIntroduction summary.`, `## Methods (p. 2)\n\nMethods summary.`, `## Results (p. 3)\n\nResults summary.`} {
+		if !strings.Contains(compose[0], part) {
+			t.Errorf("Expected the composing request to contain %q, got %s", part, compose[0])
+		}
+	}
+
+	// Both levels are stored, and served from the store on the next call
+	summary, sections, err := store.GetSectionSummaries(ctx, docID, models.SummaryStyleStandard)
+	if err != nil || summary != got.Summary || !reflect.DeepEqual(sections, expected) {
+		t.Errorf("Expected the summaries stored, got %q, %+v, %v", summary, sections, err)
+	}
+	if stored, _ := store.GetStyledSummary(ctx, docID, models.SummaryStyleStandard); stored != "" {
+		t.Errorf("Expected the whole-document summary untouched, got %q", stored)
+	}
+	_, response, _ = DocumentSummarizeToolHandler(ctx, nil, query, store, log)
+	if got := response.Results[0]; !got.Cached || got.Summary != summary || !reflect.DeepEqual(got.Sections, expected) {
+		t.Errorf("Expected the stored summaries, got %+v", got)
+	}
+	if len(requests) != 4 {
+		t.Errorf("Expected no LLM request for stored summaries, got %d in total", len(requests))
+	}
+}
+
+func TestDocumentSummarizeToolHandler_SectionsErrors(t *testing.T) {
+	// Neither request reaches OpenAI
+	t.Setenv("OPENAI_BASE_URL", "http://127.0.0.1:1")
+	t.Setenv("OPENAI_API_KEY", "test-key")
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(":memory:", log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	result, _, _ := DocumentSummarizeToolHandler(ctx, nil, DocumentSummarizeQuery{RawData: []byte("text"), Granularity: "chapters"}, store, log)
+	if code := resultError(t, result).Code; code != models.ErrorInvalidInput {
+		t.Errorf("Expected error code %s for an unknown granularity, got %s", models.ErrorInvalidInput, code)
+	}
+
+	rawData := []byte("Plain text without headings")
+	docID := storage.GenerateDocumentID(&models.SourceInfo{}, models.DocumentData{Data: rawData})
+	item := &models.ParsedItem{Pages: []string{string(rawData)}, PageNumbers: []string{""}}
+	if err := store.StoreParsedItem(ctx, docID, item, &models.SourceInfo{}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+	_, response, err := DocumentSummarizeToolHandler(ctx, nil, DocumentSummarizeQuery{RawData: rawData, Granularity: "sections"}, store, log)
+	if err != nil {
+		t.Fatalf("DocumentSummarizeToolHandler failed: %v", err)
+	}
+	if got := response.Results[0]; got.ErrorDetail == nil || got.ErrorDetail.Code != models.ErrorInvalidInput || !strings.Contains(got.Error, "no section headings") {
+		t.Errorf("Expected a document without headings refused, got %+v", got)
+	}
+}
@@ -0,0 +1,62 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// toolError classifies an error for a tool response. Cancellation and missing
+// stored documents are recognized wherever they occur in the error chain;
+// otherwise the code attached with models.WithErrorCode is used, or fallback if
+// the error has none.
+func toolError(err error, fallback models.ErrorCode) *models.ToolError {
+	if err == nil {
+		return nil
+	}
+	code := fallback
+	var coded *models.CodedError
+	switch {
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		code = models.ErrorCancelled
+	case errors.Is(err, storage.ErrNotFound):
+		code = models.ErrorNotFound
+	case errors.As(err, &coded):
+		code = coded.Code
+	}
+	return &models.ToolError{Code: code, Message: err.Error()}
+}
+
+// errorResult reports a failed tool call: IsError is set and the content is
+// {"error": {"code": ..., "message": ...}}, so clients can tell the failure's
+// cause from its code
+func errorResult(err error, fallback models.ErrorCode) *mcp.CallToolResult {
+	toolErr := toolError(err, fallback)
+	content, marshalErr := json.Marshal(struct {
+		Error *models.ToolError `json:"error"`
+	}{toolErr})
+	if marshalErr != nil {
+		content = []byte(toolErr.Message)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(content)}},
+		IsError: true,
+	}
+}
+
+// DisabledToolResult reports a call to a tool the server's configuration
+// disables, with the disabled code
+func DisabledToolResult(name string) *mcp.CallToolResult {
+	return errorResult(fmt.Errorf("tool %s is disabled on this server", name), models.ErrorDisabled)
+}
+
+// partialDocumentError refuses a document that was parsed for its metadata
+// only, for tools that need its pages
+func partialDocumentError(docID string) error {
+	return models.WithErrorCode(models.ErrorInvalidInput, fmt.Errorf("document %s was parsed for metadata only; run document-parse without mode \"metadata\" to parse it in full first", docID))
+}
@@ -0,0 +1,66 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// resultError decodes the ToolError reported by an error result
+func resultError(t *testing.T, result *mcp.CallToolResult) *models.ToolError {
+	t.Helper()
+	if result == nil || !result.IsError {
+		t.Fatalf("Expected an error result, got %+v", result)
+	}
+	var content struct {
+		Error *models.ToolError `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &content); err != nil || content.Error == nil {
+		t.Fatalf("Failed to decode error result %+v: %v", result.Content, err)
+	}
+	return content.Error
+}
+
+func TestToolError(t *testing.T) {
+	notFound := fmt.Errorf("document %w: doc-1", storage.ErrNotFound)
+	tests := []struct {
+		name     string
+		err      error
+		fallback models.ErrorCode
+		expected models.ErrorCode
+	}{
+		{"Uncoded uses fallback", errors.New("boom"), models.ErrorStorage, models.ErrorStorage},
+		{"Coded", models.WithErrorCode(models.ErrorUpstreamZotero, errors.New("status 500")), models.ErrorInternal, models.ErrorUpstreamZotero},
+		{"Coded deep in chain", fmt.Errorf("outer: %w", models.WithErrorCode(models.ErrorInvalidInput, errors.New("bad"))), models.ErrorInternal, models.ErrorInvalidInput},
+		{"Inner code kept", models.WithErrorCode(models.ErrorUpstreamLLM, models.WithErrorCode(models.ErrorInvalidInput, errors.New("unsupported document type"))), models.ErrorInternal, models.ErrorInvalidInput},
+		{"Missing document", models.WithErrorCode(models.ErrorStorage, notFound), models.ErrorInternal, models.ErrorNotFound},
+		{"Cancelled", models.WithErrorCode(models.ErrorUpstreamLLM, fmt.Errorf("request failed: %w", context.Canceled)), models.ErrorInternal, models.ErrorCancelled},
+		{"Timed out", context.DeadlineExceeded, models.ErrorInternal, models.ErrorCancelled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toolErr := toolError(tt.err, tt.fallback)
+			if toolErr.Code != tt.expected || toolErr.Message != tt.err.Error() {
+				t.Errorf("Expected code %s with message %q, got %+v", tt.expected, tt.err.Error(), toolErr)
+			}
+		})
+	}
+
+	if toolError(nil, models.ErrorInternal) != nil {
+		t.Error("Expected no ToolError for a nil error")
+	}
+}
+
+func TestErrorResult(t *testing.T) {
+	result := errorResult(fmt.Errorf("document %w: doc-1", storage.ErrNotFound), models.ErrorStorage)
+	toolErr := resultError(t, result)
+	if toolErr.Code != models.ErrorNotFound || toolErr.Message != "document not found: doc-1" {
+		t.Errorf("Unexpected error result: %+v", toolErr)
+	}
+}
@@ -0,0 +1,30 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
+)
+
+// fetchInput is a document input whose URL may need headers or a proxy
+// prefix to be fetched
+type fetchInput interface {
+	fetchOptions() documents.FetchOptions
+}
+
+// validateFetchInputs checks the headers and proxy prefix of each input
+func validateFetchInputs[T fetchInput](inputs []T) error {
+	for i, input := range inputs {
+		if err := documents.ValidateFetchOptions(input.fetchOptions()); err != nil {
+			return fmt.Errorf("document %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// fetchContext returns ctx with the input's URL fetched using its headers and
+// proxy prefix
+func fetchContext(ctx context.Context, input fetchInput) context.Context {
+	return documents.WithFetchOptions(ctx, input.fetchOptions())
+}
@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/operations"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+type JobCancelQuery struct {
+	JobID string `json:"job_id"` // ID returned by document-parse with async set
+}
+
+type JobCancelResponse struct {
+	Job *models.Job `json:"job"` // The job after cancelling
+}
+
+func JobCancelTool() *mcp.Tool {
+	inputschema, err := jsonschema.For[JobCancelQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+	return &mcp.Tool{
+		Name:        "job-cancel",
+		Description: "Cancel a background parsing job: documents not yet started are cancelled, and documents being parsed are stopped and reported as cancelled. Documents already parsed keep their results. Returns the job as job-status reports it.",
+		InputSchema: inputschema,
+	}
+}
+
+func JobCancelToolHandler(ctx context.Context, req *mcp.CallToolRequest, query JobCancelQuery, jobs *operations.JobRunner, log logger.Logger) (*mcp.CallToolResult, *JobCancelResponse, error) {
+	log.Info("job-cancel tool called")
+
+	if query.JobID == "" {
+		return errorResult(errors.New("job_id is required"), models.ErrorInvalidInput), nil, nil
+	}
+	job, err := jobs.Cancel(ctx, query.JobID)
+	if err != nil {
+		return errorResult(err, models.ErrorStorage), nil, nil
+	}
+	return nil, &JobCancelResponse{Job: job}, nil
+}
@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+type JobStatusQuery struct {
+	JobID string `json:"job_id"` // ID returned by document-parse with async set
+}
+
+type JobStatusResponse struct {
+	Job *models.Job `json:"job"`
+}
+
+func JobStatusTool() *mcp.Tool {
+	inputschema, err := jsonschema.For[JobStatusQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+	return &mcp.Tool{
+		Name:        "job-status",
+		Description: "Report the progress of a background parsing job queued by document-parse with async set: the job's status (pending, running, or completed), the number of documents in each status, and for each document its status (pending, running, done, failed, or cancelled), its document_id once parsed, and its error if it failed. Read parsed documents through their resources as usual.",
+		InputSchema: inputschema,
+	}
+}
+
+func JobStatusToolHandler(ctx context.Context, req *mcp.CallToolRequest, query JobStatusQuery, store storage.Store, log logger.Logger) (*mcp.CallToolResult, *JobStatusResponse, error) {
+	log.Info("job-status tool called")
+
+	if query.JobID == "" {
+		return errorResult(errors.New("job_id is required"), models.ErrorInvalidInput), nil, nil
+	}
+	job, err := store.GetJob(ctx, query.JobID)
+	if err != nil {
+		return errorResult(err, models.ErrorStorage), nil, nil
+	}
+	return nil, &JobStatusResponse{Job: job}, nil
+}
@@ -0,0 +1,30 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+)
+
+// validateLibrary checks a tool's library parameter, which names one of the
+// server's document libraries rather than a Zotero library. An empty library is
+// valid: tools that parse take it as the default library, and tools that list
+// take it as every library.
+func validateLibrary(library string) error {
+	if library == "" {
+		return nil
+	}
+	return storage.ValidateLibrary(library)
+}
+
+// libraryContext validates a tool's library parameter and returns ctx with
+// documents parsed and looked up in that library
+func libraryContext(ctx context.Context, library string) (context.Context, error) {
+	if err := validateLibrary(library); err != nil {
+		return ctx, err
+	}
+	if library == "" {
+		return ctx, nil
+	}
+	return storage.NewLibraryContext(ctx, library), nil
+}
@@ -0,0 +1,125 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/citations"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type LibraryCitationGraphQuery struct {
+	DocumentID      string `json:"document_id,omitempty"`      // Only this document and the documents it cites or is cited by
+	IncludeUnlinked bool   `json:"include_unlinked,omitempty"` // Also list documents that neither cite nor are cited by another stored document
+}
+
+// CitationGraphNode is a stored document with its citations of other stored
+// documents
+type CitationGraphNode struct {
+	DocumentID string   `json:"document_id"`
+	Citekey    string   `json:"citekey,omitempty"`
+	Title      string   `json:"title,omitempty"`
+	Year       string   `json:"year,omitempty"`
+	Cites      []string `json:"cites"`    // Stored documents this document cites
+	CitedBy    []string `json:"cited_by"` // Stored documents that cite this document
+}
+
+type LibraryCitationGraphResponse struct {
+	Nodes     []CitationGraphNode `json:"nodes"`
+	EdgeCount int                 `json:"edge_count"` // Number of citing-cited document pairs
+}
+
+func LibraryCitationGraphTool() *mcp.Tool {
+	inputschema, err := jsonschema.For[LibraryCitationGraphQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+	return &mcp.Tool{
+		Name:        "library-citation-graph",
+		Description: "Get the citations between stored documents as an adjacency list for graph visualization: each document with the stored documents it cites and is cited by. References are linked to stored documents by DOI, or by title with the first author and year, whenever a document is stored. Set document_id to get one document's neighborhood; include_unlinked also lists documents without internal citations.",
+		InputSchema: inputschema,
+	}
+}
+
+func LibraryCitationGraphToolHandler(ctx context.Context, req *mcp.CallToolRequest, query LibraryCitationGraphQuery, store storage.Store, log logger.Logger) (*mcp.CallToolResult, *LibraryCitationGraphResponse, error) {
+	log.Info("library-citation-graph tool called")
+
+	if query.DocumentID != "" {
+		exists, err := store.DocumentExists(ctx, query.DocumentID)
+		if err != nil {
+			return errorResult(fmt.Errorf("failed to check document existence: %w", err), models.ErrorStorage), nil, nil
+		}
+		if !exists {
+			return errorResult(fmt.Errorf("document not found: %s", query.DocumentID), models.ErrorNotFound), nil, nil
+		}
+	}
+
+	links, err := store.GetReferenceLinks(ctx)
+	if err != nil {
+		log.Error("Failed to retrieve reference links: %v", err)
+		return errorResult(fmt.Errorf("failed to retrieve reference links: %w", err), models.ErrorStorage), nil, nil
+	}
+	docs, err := store.ListDocuments(ctx, "")
+	if err != nil {
+		log.Error("Failed to list documents: %v", err)
+		return errorResult(fmt.Errorf("failed to list documents: %w", err), models.ErrorStorage), nil, nil
+	}
+
+	response := buildCitationGraph(docs, links, query.DocumentID, query.IncludeUnlinked)
+	log.Info("Citation graph has %d documents and %d citations", len(response.Nodes), response.EdgeCount)
+	return nil, response, nil
+}
+
+// buildCitationGraph turns reference links into one node per document, in
+// document ID order. Several references of a document to the same document are
+// one citation.
+func buildCitationGraph(docs []models.DocumentInfo, links []models.ReferenceLink, focus string, includeUnlinked bool) *LibraryCitationGraphResponse {
+	type edge struct{ from, to string }
+	seen := make(map[edge]bool)
+	cites := make(map[string][]string)
+	citedBy := make(map[string][]string)
+	for _, link := range links {
+		e := edge{link.DocumentID, link.CitedDocumentID}
+		if seen[e] || (focus != "" && e.from != focus && e.to != focus) {
+			continue
+		}
+		seen[e] = true
+		cites[e.from] = append(cites[e.from], e.to)
+		citedBy[e.to] = append(citedBy[e.to], e.from)
+	}
+
+	nodes := []CitationGraphNode{}
+	for _, doc := range docs {
+		linked := len(cites[doc.DocumentID]) > 0 || len(citedBy[doc.DocumentID]) > 0
+		if !linked && !(includeUnlinked && (focus == "" || doc.DocumentID == focus)) {
+			continue
+		}
+		node := CitationGraphNode{
+			DocumentID: doc.DocumentID,
+			Citekey:    doc.Citekey,
+			Title:      doc.Title,
+			Year:       citations.ExtractYear(doc.PublicationDate),
+			Cites:      sortedOrEmpty(cites[doc.DocumentID]),
+			CitedBy:    sortedOrEmpty(citedBy[doc.DocumentID]),
+		}
+		nodes = append(nodes, node)
+	}
+	slices.SortFunc(nodes, func(a, b CitationGraphNode) int {
+		return strings.Compare(a.DocumentID, b.DocumentID)
+	})
+	return &LibraryCitationGraphResponse{Nodes: nodes, EdgeCount: len(seen)}
+}
+
+func sortedOrEmpty(ids []string) []string {
+	if ids == nil {
+		return []string{}
+	}
+	slices.Sort(ids)
+	return ids
+}
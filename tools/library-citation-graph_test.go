@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestLibraryCitationGraphToolHandler(t *testing.T) {
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(":memory:", log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	// a cites b twice and c; b cites c; d is unlinked
+	items := map[string]*models.ParsedItem{
+		"a": {
+			Metadata: models.ItemMetadata{Title: "Survey of Archives", Authors: []string{"Lee, Ann"}, PublicationDate: "2022"},
+			References: []models.Reference{
+				{ReferenceText: "Smith, J. (2020). Memory and the Archive. Journal of History."},
+				{ReferenceText: "Smith, J. Memory and the archive. J Hist. 2020.", DOI: "10.1000/b"},
+				{ReferenceText: "Chen, W. (2018). Soil Carbon Dynamics. Nature."},
+			},
+		},
+		"b": {
+			Metadata:   models.ItemMetadata{Title: "Memory and the Archive", Authors: []string{"Smith, Jane"}, PublicationDate: "2020", DOI: "10.1000/b", Citekey: "smith2020"},
+			References: []models.Reference{{ReferenceText: "Chen, W. (2018). Soil Carbon Dynamics. Nature."}},
+		},
+		"c": {Metadata: models.ItemMetadata{Title: "Soil Carbon Dynamics", Authors: []string{"Wei Chen"}, PublicationDate: "2018"}},
+		"d": {Metadata: models.ItemMetadata{Title: "Unrelated Work", PublicationDate: "2001"}},
+	}
+	for _, docID := range []string{"a", "b", "c", "d"} {
+		if err := store.StoreParsedItem(ctx, docID, items[docID], &models.SourceInfo{}); err != nil {
+			t.Fatalf("Failed to store %s: %v", docID, err)
+		}
+	}
+
+	a := CitationGraphNode{DocumentID: "a", Title: "Survey of Archives", Year: "2022", Cites: []string{"b", "c"}, CitedBy: []string{}}
+	b := CitationGraphNode{DocumentID: "b", Citekey: "smith2020", Title: "Memory and the Archive", Year: "2020", Cites: []string{"c"}, CitedBy: []string{"a"}}
+	c := CitationGraphNode{DocumentID: "c", Title: "Soil Carbon Dynamics", Year: "2018", Cites: []string{}, CitedBy: []string{"a", "b"}}
+	d := CitationGraphNode{DocumentID: "d", Title: "Unrelated Work", Year: "2001", Cites: []string{}, CitedBy: []string{}}
+
+	tests := []struct {
+		name      string
+		query     LibraryCitationGraphQuery
+		nodes     []CitationGraphNode
+		edgeCount int
+	}{
+		{"whole library", LibraryCitationGraphQuery{}, []CitationGraphNode{a, b, c}, 3},
+		{"with unlinked documents", LibraryCitationGraphQuery{IncludeUnlinked: true}, []CitationGraphNode{a, b, c, d}, 3},
+		{
+			name:  "one document's neighborhood",
+			query: LibraryCitationGraphQuery{DocumentID: "b"},
+			nodes: []CitationGraphNode{
+				{DocumentID: "a", Title: "Survey of Archives", Year: "2022", Cites: []string{"b"}, CitedBy: []string{}},
+				b,
+				{DocumentID: "c", Title: "Soil Carbon Dynamics", Year: "2018", Cites: []string{}, CitedBy: []string{"b"}},
+			},
+			edgeCount: 2,
+		},
+		{"unlinked document", LibraryCitationGraphQuery{DocumentID: "d"}, []CitationGraphNode{}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, response, err := LibraryCitationGraphToolHandler(ctx, nil, tt.query, store, log)
+			if err != nil || result != nil {
+				t.Fatalf("LibraryCitationGraphToolHandler failed: %+v, %v", result, err)
+			}
+			if !reflect.DeepEqual(response.Nodes, tt.nodes) || response.EdgeCount != tt.edgeCount {
+				t.Errorf("Expected %+v with %d edges, got %+v with %d", tt.nodes, tt.edgeCount, response.Nodes, response.EdgeCount)
+			}
+		})
+	}
+
+	t.Run("unknown document", func(t *testing.T) {
+		result, _, err := LibraryCitationGraphToolHandler(ctx, nil, LibraryCitationGraphQuery{DocumentID: "missing"}, store, log)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if code := resultError(t, result).Code; code != models.ErrorNotFound {
+			t.Errorf("Expected error code %s, got %s", models.ErrorNotFound, code)
+		}
+	})
+}
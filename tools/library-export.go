@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/operations"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type LibraryExportQuery struct {
+	OutputPath string `json:"output_path"` // File to write, relative to ACADEMIC_MCP_EXPORT_DIR (e.g., "library.jsonl.gz")
+}
+
+type LibraryExportResponse struct {
+	WrittenPath   string `json:"written_path"`
+	DocumentCount int    `json:"document_count"`
+	Bytes         int64  `json:"bytes"`
+}
+
+func LibraryExportTool() *mcp.Tool {
+	inputschema, err := jsonschema.For[LibraryExportQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+	return &mcp.Tool{
+		Name:        "library-export",
+		Description: "Back up the whole document library to a single archive file under the directory configured by ACADEMIC_MCP_EXPORT_DIR. The archive is gzipped JSON lines: a header line, then one line per document with its metadata and citekey, pages, references, footnotes, endnotes, tables, images, quotations, summaries in every style, annotations, and source. Documents are written one at a time, so large libraries can be exported. The archive is not encrypted, even if the store is. Load it with library-import.",
+		InputSchema: inputschema,
+	}
+}
+
+func LibraryExportToolHandler(ctx context.Context, req *mcp.CallToolRequest, query LibraryExportQuery, store storage.Store, log logger.Logger) (*mcp.CallToolResult, *LibraryExportResponse, error) {
+	log.Info("library-export tool called")
+
+	if query.OutputPath == "" {
+		return errorResult(errors.New("output_path is required"), models.ErrorInvalidInput), nil, nil
+	}
+	path, err := resolveOutputPath(query.OutputPath)
+	if err != nil {
+		log.Error("Rejected output path %s: %v", query.OutputPath, err)
+		return errorResult(err, models.ErrorInvalidInput), nil, nil
+	}
+
+	// The archive is written beside its destination and moved into place once
+	// complete, so a failed export does not leave a truncated archive behind
+	file, err := os.CreateTemp(filepath.Dir(path), ".library-export-*")
+	if err != nil {
+		return errorResult(fmt.Errorf("failed to create %s: %w", path, err), models.ErrorInternal), nil, nil
+	}
+	defer os.Remove(file.Name())
+
+	count, err := operations.ExportLibrary(ctx, store, file, log)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write %s: %w", path, closeErr)
+	}
+	if err != nil {
+		log.Error("Failed to export library: %v", err)
+		return errorResult(err, models.ErrorInternal), nil, nil
+	}
+	if err := os.Chmod(file.Name(), 0o644); err != nil {
+		return errorResult(fmt.Errorf("failed to write %s: %w", path, err), models.ErrorInternal), nil, nil
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return errorResult(fmt.Errorf("failed to write %s: %w", path, err), models.ErrorInternal), nil, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return errorResult(fmt.Errorf("failed to write %s: %w", path, err), models.ErrorInternal), nil, nil
+	}
+	log.Info("Exported %d documents to %s (%d bytes)", count, path, info.Size())
+	return nil, &LibraryExportResponse{WrittenPath: path, DocumentCount: count, Bytes: info.Size()}, nil
+}
@@ -0,0 +1,114 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestLibraryExportAndImportToolHandlers(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv(exportDirEnv, root)
+	ctx := context.Background()
+	log := logger.NewNoOpLogger()
+
+	source, err := storage.NewSQLiteStore(":memory:", log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer source.Close()
+	for _, docID := range []string{"doc_1", "doc_2"} {
+		item := &models.ParsedItem{
+			Metadata: models.ItemMetadata{Title: "Paper " + docID, Citekey: "key_" + docID},
+			Pages:    []string{"Text of " + docID},
+		}
+		if err := source.StoreParsedItem(ctx, docID, item, &models.SourceInfo{}); err != nil {
+			t.Fatalf("Failed to store document: %v", err)
+		}
+	}
+
+	result, exported, err := LibraryExportToolHandler(ctx, nil, LibraryExportQuery{OutputPath: "backups/library.jsonl.gz"}, source, log)
+	if err != nil || result != nil {
+		t.Fatalf("LibraryExportToolHandler failed: %v, %v", err, result)
+	}
+	if exported.DocumentCount != 2 || !strings.HasSuffix(exported.WrittenPath, filepath.Join("backups", "library.jsonl.gz")) || exported.Bytes == 0 {
+		t.Errorf("Unexpected response: %+v", exported)
+	}
+	entries, err := os.ReadDir(filepath.Join(root, "backups"))
+	if err != nil || len(entries) != 1 {
+		t.Errorf("Expected only the archive written, got %v, %v", entries, err)
+	}
+
+	target, err := storage.NewSQLiteStore(":memory:", log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer target.Close()
+	result, imported, err := LibraryImportToolHandler(ctx, nil, LibraryImportQuery{InputPath: "backups/library.jsonl.gz"}, target, log)
+	if err != nil || result != nil {
+		t.Fatalf("LibraryImportToolHandler failed: %v, %v", err, result)
+	}
+	if imported.ImportedCount != 2 || imported.SkippedCount != 0 {
+		t.Errorf("Expected both documents imported, got %+v", imported)
+	}
+	docID, err := target.GetDocumentByCitekey(ctx, "", "key_doc_2")
+	if err != nil || docID != "doc_2" {
+		t.Errorf("Expected doc_2 imported, got %q, %v", docID, err)
+	}
+
+	// Importing again skips what is already there
+	_, imported, err = LibraryImportToolHandler(ctx, nil, LibraryImportQuery{InputPath: "backups/library.jsonl.gz"}, target, log)
+	if err != nil || imported.ImportedCount != 0 || imported.SkippedCount != 2 {
+		t.Errorf("Expected both documents skipped, got %+v, %v", imported, err)
+	}
+}
+
+func TestLibraryImportToolHandler_Errors(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv(exportDirEnv, root)
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(":memory:", log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	outside := filepath.Join(t.TempDir(), "library.jsonl.gz")
+	if err := os.WriteFile(outside, []byte("data"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "notes.txt"), []byte("not an archive"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		query         LibraryImportQuery
+		expectedError string
+	}{
+		{"missing path", LibraryImportQuery{}, "input_path is required"},
+		{"outside the directory", LibraryImportQuery{InputPath: outside}, "outside"},
+		{"parent traversal", LibraryImportQuery{InputPath: "../library.jsonl.gz"}, "not found"},
+		{"missing file", LibraryImportQuery{InputPath: "missing.jsonl.gz"}, "not found"},
+		{"the directory itself", LibraryImportQuery{InputPath: "."}, "outside"},
+		{"not an archive", LibraryImportQuery{InputPath: "notes.txt"}, "not a library archive"},
+		{"unknown option", LibraryImportQuery{InputPath: "notes.txt", OnConflict: "merge"}, "unsupported on_conflict"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := LibraryImportToolHandler(context.Background(), nil, tt.query, store, log)
+			if err != nil {
+				t.Fatalf("Expected an error result, got %v", err)
+			}
+			if toolErr := resultError(t, result); toolErr.Code != models.ErrorInvalidInput || !strings.Contains(toolErr.Message, tt.expectedError) {
+				t.Errorf("Expected an invalid input error containing %q, got %+v", tt.expectedError, toolErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/operations"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type LibraryImportQuery struct {
+	InputPath  string `json:"input_path"`            // Archive written by library-export, relative to ACADEMIC_MCP_EXPORT_DIR
+	OnConflict string `json:"on_conflict,omitempty"` // "skip" (default), "overwrite", or "rename"
+}
+
+type LibraryImportResponse struct {
+	ImportedCount   int                    `json:"imported_count"`
+	Imported        []string               `json:"imported,omitempty"`
+	Overwritten     []string               `json:"overwritten,omitempty"`
+	RenamedCitekeys map[string]string      `json:"renamed_citekeys,omitempty"` // New citekey by document ID
+	SkippedCount    int                    `json:"skipped_count"`
+	Skipped         []LibraryImportSkipped `json:"skipped,omitempty"`
+	Incomplete      bool                   `json:"incomplete,omitempty"` // The import stopped at an error after storing the documents listed
+	Error           string                 `json:"error,omitempty"`      // Why an incomplete import stopped
+}
+
+// LibraryImportSkipped is an archived document that was not imported
+type LibraryImportSkipped struct {
+	DocumentID string `json:"document_id"`
+	Citekey    string `json:"citekey,omitempty"`
+	Reason     string `json:"reason"`
+}
+
+func LibraryImportTool() *mcp.Tool {
+	inputschema, err := jsonschema.For[LibraryImportQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+	return &mcp.Tool{
+		Name:        "library-import",
+		Description: "Load a library archive written by library-export from a file under the directory configured by ACADEMIC_MCP_EXPORT_DIR, into an empty or existing library. Documents are read one at a time and stored with their content, summaries, annotations, and source. An archived document conflicts with the library if a document with its ID is stored or its citekey belongs to another document. on_conflict chooses what happens: skip (default) keeps the library's document, overwrite replaces a document with the same ID by the archived one, and rename gives an archived document whose citekey is taken a new citekey. Conflicts the chosen option does not resolve skip the document, with the reason listed.",
+		InputSchema: inputschema,
+	}
+}
+
+func LibraryImportToolHandler(ctx context.Context, req *mcp.CallToolRequest, query LibraryImportQuery, store storage.Store, log logger.Logger) (*mcp.CallToolResult, *LibraryImportResponse, error) {
+	log.Info("library-import tool called")
+
+	onConflict := strings.ToLower(query.OnConflict)
+	if onConflict != "" && !slices.Contains([]string{operations.ConflictSkip, operations.ConflictOverwrite, operations.ConflictRename}, onConflict) {
+		return errorResult(fmt.Errorf("unsupported on_conflict: %s (supported: 'skip', 'overwrite', 'rename')", query.OnConflict), models.ErrorInvalidInput), nil, nil
+	}
+	if query.InputPath == "" {
+		return errorResult(errors.New("input_path is required"), models.ErrorInvalidInput), nil, nil
+	}
+	path, err := resolveInputPath(query.InputPath)
+	if err != nil {
+		log.Error("Rejected input path %s: %v", query.InputPath, err)
+		return errorResult(err, models.ErrorInvalidInput), nil, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return errorResult(fmt.Errorf("failed to open %s: %w", path, err), models.ErrorInvalidInput), nil, nil
+	}
+	defer file.Close()
+
+	result, err := operations.ImportLibrary(ctx, store, file, onConflict, log)
+	if err != nil && (result == nil || len(result.Imported) == 0) {
+		log.Error("Failed to import library: %v", err)
+		return errorResult(err, models.ErrorInternal), nil, nil
+	}
+
+	response := &LibraryImportResponse{
+		ImportedCount:   len(result.Imported),
+		Imported:        result.Imported,
+		Overwritten:     result.Overwritten,
+		RenamedCitekeys: result.Renamed,
+		SkippedCount:    len(result.Skipped),
+	}
+	for _, skipped := range result.Skipped {
+		response.Skipped = append(response.Skipped, LibraryImportSkipped(skipped))
+	}
+	if err != nil {
+		// Documents already stored stay; the response says where the import stopped
+		log.Error("Library import stopped after %d documents: %v", len(result.Imported), err)
+		response.Incomplete = true
+		response.Error = err.Error()
+	}
+	return nil, response, nil
+}
+
+// resolveInputPath returns the absolute path of an existing regular file to read
+// at path, which must lie inside the directory named by ACADEMIC_MCP_EXPORT_DIR.
+// Relative paths are taken relative to that directory. Symlinks are resolved so
+// they cannot lead outside it.
+func resolveInputPath(path string) (string, error) {
+	dir := os.Getenv(exportDirEnv)
+	if dir == "" {
+		return "", fmt.Errorf("reading files requires %s to be set to an allowed directory", exportDirEnv)
+	}
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %w", exportDirEnv, err)
+	}
+	root, err = filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %w", exportDirEnv, err)
+	}
+
+	target := path
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(root, target)
+	}
+	target, err = filepath.EvalSymlinks(filepath.Clean(target))
+	if err != nil {
+		return "", fmt.Errorf("input path %s not found", path)
+	}
+	if !isWithin(root, target) {
+		return "", fmt.Errorf("input path %s is outside %s", path, exportDirEnv)
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		return "", fmt.Errorf("input path %s not found", path)
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("input path %s is not a regular file", path)
+	}
+	return target, nil
+}
@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/citations"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// defaultLibrarySearchLimit is the number of documents returned when limit is not specified
+	defaultLibrarySearchLimit = 20
+	// maxLibrarySearchLimit is the most documents one search returns
+	maxLibrarySearchLimit = 100
+)
+
+type LibrarySearchQuery struct {
+	Title       string `json:"title,omitempty"`
+	Author      string `json:"author,omitempty"` // Family name, "Given Family", or the name as written
+	Publication string `json:"publication,omitempty"`
+	DOI         string `json:"doi,omitempty"`
+	YearFrom    int    `json:"year_from,omitempty"` // Earliest publication year
+	YearTo      int    `json:"year_to,omitempty"`   // Latest publication year
+	Match       string `json:"match,omitempty"`     // "substring" (default) or "prefix"
+	Limit       int    `json:"limit,omitempty"`     // Most documents to return (default: 20, at most 100)
+	Offset      int    `json:"offset,omitempty"`    // Matching documents to skip, for the next page
+}
+
+type LibrarySearchResponse struct {
+	Documents []models.LibrarySearchResult `json:"documents"`
+	Count     int                          `json:"count"`    // Documents in this response
+	Total     int                          `json:"total"`    // Documents matching in all
+	HasMore   bool                         `json:"has_more"` // More documents match past this page
+}
+
+func LibrarySearchTool() *mcp.Tool {
+	inputschema, err := jsonschema.For[LibrarySearchQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+	return &mcp.Tool{
+		Name:        "library-search",
+		Description: "Search the stored documents by their bibliographic metadata, without Zotero or the document text: title, author, publication, DOI, and a range of publication years (year_from, year_to). Text fields match ignoring case, anywhere in the field, or with match \"prefix\" at its start (for authors, the start of the family name or full name). Every field given must match; wildcard characters such as % and _ match themselves. Returns the matching documents, most recently added first, with their metadata, citekey, and whether they have a summary (has_summary), a page at a time (limit, default 20, and offset) with the total number of matches.",
+		InputSchema: inputschema,
+	}
+}
+
+func LibrarySearchToolHandler(ctx context.Context, req *mcp.CallToolRequest, query LibrarySearchQuery, store storage.Store, log logger.Logger) (*mcp.CallToolResult, *LibrarySearchResponse, error) {
+	log.Info("library-search tool called")
+
+	match := strings.ToLower(query.Match)
+	if match != "" && match != "substring" && match != "prefix" {
+		return errorResult(fmt.Errorf("unsupported match: %s (supported: 'substring', 'prefix')", query.Match), models.ErrorInvalidInput), nil, nil
+	}
+	if query.YearFrom < 0 || query.YearTo < 0 {
+		return errorResult(errors.New("year_from and year_to must not be negative"), models.ErrorInvalidInput), nil, nil
+	}
+	if query.YearFrom > 0 && query.YearTo > 0 && query.YearFrom > query.YearTo {
+		return errorResult(fmt.Errorf("year_from %d is after year_to %d", query.YearFrom, query.YearTo), models.ErrorInvalidInput), nil, nil
+	}
+	if query.Limit < 0 || query.Offset < 0 {
+		return errorResult(errors.New("limit and offset must not be negative"), models.ErrorInvalidInput), nil, nil
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultLibrarySearchLimit
+	}
+	limit = min(limit, maxLibrarySearchLimit)
+
+	// DOIs are stored normalized, so a DOI given as a URL or with a label still matches
+	doi := query.DOI
+	if normalized, ok := citations.NormalizeDOI(doi); ok {
+		doi = normalized
+	}
+
+	search := models.LibrarySearch{
+		Title:       query.Title,
+		Author:      query.Author,
+		Publication: query.Publication,
+		DOI:         doi,
+		YearFrom:    query.YearFrom,
+		YearTo:      query.YearTo,
+		Prefix:      match == "prefix",
+		Limit:       limit,
+		Offset:      query.Offset,
+	}
+	docs, total, err := store.SearchDocuments(ctx, search)
+	if err != nil {
+		log.Error("Failed to search documents: %v", err)
+		return errorResult(fmt.Errorf("failed to search documents: %w", err), models.ErrorStorage), nil, nil
+	}
+	if docs == nil {
+		docs = []models.LibrarySearchResult{}
+	}
+
+	log.Info("Found %d documents (%d on this page)", total, len(docs))
+	return nil, &LibrarySearchResponse{
+		Documents: docs,
+		Count:     len(docs),
+		Total:     total,
+		HasMore:   query.Offset+len(docs) < total,
+	}, nil
+}
@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestLibrarySearchToolHandler(t *testing.T) {
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(":memory:", log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	for i := range 25 {
+		item := &models.ParsedItem{
+			Metadata: models.ItemMetadata{Title: fmt.Sprintf("Paper %d", i), Authors: []string{"Donna Haraway"}, PublicationDate: fmt.Sprint(1980 + i)},
+			Pages:    []string{"Text"},
+		}
+		if i == 0 {
+			item.Metadata.DOI = "10.2307/3178066"
+		}
+		if err := store.StoreParsedItem(ctx, fmt.Sprintf("doc-%d", i), item, &models.SourceInfo{}); err != nil {
+			t.Fatalf("Failed to store document: %v", err)
+		}
+	}
+
+	// The default page
+	result, response, err := LibrarySearchToolHandler(ctx, nil, LibrarySearchQuery{Author: "haraway"}, store, log)
+	if err != nil || result != nil {
+		t.Fatalf("LibrarySearchToolHandler failed: %+v, %v", result, err)
+	}
+	if response.Count != defaultLibrarySearchLimit || response.Total != 25 || !response.HasMore {
+		t.Errorf("Expected the first %d of 25, got %d of %d", defaultLibrarySearchLimit, response.Count, response.Total)
+	}
+	_, response, err = LibrarySearchToolHandler(ctx, nil, LibrarySearchQuery{Author: "haraway", Offset: 20}, store, log)
+	if err != nil || response.Count != 5 || response.HasMore {
+		t.Errorf("Expected the last 5, got %+v, %v", response, err)
+	}
+
+	// Years from the 1990s
+	_, response, err = LibrarySearchToolHandler(ctx, nil, LibrarySearchQuery{Author: "Har", Match: "prefix", YearFrom: 1990, YearTo: 1999}, store, log)
+	if err != nil || response.Total != 10 {
+		t.Errorf("Expected 10 documents from the 1990s, got %+v, %v", response, err)
+	}
+
+	// A DOI given as a URL
+	_, response, err = LibrarySearchToolHandler(ctx, nil, LibrarySearchQuery{DOI: "https://doi.org/10.2307/3178066"}, store, log)
+	if err != nil || response.Total != 1 || response.Documents[0].DocumentID != "doc-0" {
+		t.Errorf("Expected the document with the DOI, got %+v, %v", response, err)
+	}
+
+	// No match is an empty list
+	_, response, err = LibrarySearchToolHandler(ctx, nil, LibrarySearchQuery{Title: "%"}, store, log)
+	if err != nil || response.Documents == nil || response.Total != 0 {
+		t.Errorf("Expected no documents, got %+v, %v", response, err)
+	}
+}
+
+func TestLibrarySearchToolHandler_InvalidInput(t *testing.T) {
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(":memory:", log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	tests := []struct {
+		name          string
+		query         LibrarySearchQuery
+		expectedError string
+	}{
+		{"unknown match", LibrarySearchQuery{Match: "regex"}, "unsupported match"},
+		{"reversed years", LibrarySearchQuery{YearFrom: 2000, YearTo: 1990}, "is after"},
+		{"negative year", LibrarySearchQuery{YearFrom: -1}, "must not be negative"},
+		{"negative offset", LibrarySearchQuery{Offset: -1}, "must not be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := LibrarySearchToolHandler(context.Background(), nil, tt.query, store, log)
+			if err != nil {
+				t.Fatalf("Expected an error result, got %v", err)
+			}
+			if toolErr := resultError(t, result); toolErr.Code != models.ErrorInvalidInput || !strings.Contains(toolErr.Message, tt.expectedError) {
+				t.Errorf("Expected an invalid input error containing %q, got %+v", tt.expectedError, toolErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/llm"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultTopAuthors is the number of authors reported when top_authors is not specified
+const defaultTopAuthors = 10
+
+type LibraryStatsQuery struct {
+	TopAuthors int `json:"top_authors,omitempty"` // Number of most frequent authors to include (default: 10)
+	// Document library of this server to describe (not a Zotero library); empty
+	// describes every library
+	Library string `json:"library,omitempty"`
+}
+
+type LibraryStatsResponse struct {
+	Stats *models.LibraryStats `json:"stats"`
+}
+
+func LibraryStatsTool() *mcp.Tool {
+	inputschema, err := jsonschema.For[LibraryStatsQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+	return &mcp.Tool{
+		Name:        "library-stats",
+		Description: "Get an overview of the stored document library: document and page counts, documents per publication year, most frequent authors, total quotations, how many documents are missing a DOI, citekey, or summary, and the OpenAI tokens used by parsing, summarizing, and quotation extraction with an estimated cost. Set library to describe only one of the server's document libraries.",
+		InputSchema: inputschema,
+	}
+}
+
+func LibraryStatsToolHandler(ctx context.Context, req *mcp.CallToolRequest, query LibraryStatsQuery, store storage.Store, log logger.Logger) (*mcp.CallToolResult, *LibraryStatsResponse, error) {
+	log.Info("library-stats tool called")
+
+	topAuthors := query.TopAuthors
+	if topAuthors <= 0 {
+		topAuthors = defaultTopAuthors
+	}
+
+	if err := validateLibrary(query.Library); err != nil {
+		return errorResult(err, models.ErrorInvalidInput), nil, nil
+	}
+
+	stats, err := store.GetLibraryStats(ctx, query.Library, topAuthors)
+	if err != nil {
+		log.Error("Failed to compute library stats: %v", err)
+		return nil, nil, fmt.Errorf("failed to compute library stats: %w", err)
+	}
+
+	usage, err := store.GetLibraryUsage(ctx, query.Library)
+	if err != nil {
+		log.Error("Failed to retrieve library usage: %v", err)
+		return nil, nil, fmt.Errorf("failed to retrieve library usage: %w", err)
+	}
+	stats.LibraryUsage = llm.SummarizeUsage(usage, log)
+
+	log.Info("Library contains %d documents (%d pages)", stats.DocumentCount, stats.TotalPages)
+
+	return nil, &LibraryStatsResponse{Stats: stats}, nil
+}
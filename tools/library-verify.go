@@ -0,0 +1,51 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/citations"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type LibraryVerifyQuery struct {
+	Repair bool `json:"repair,omitempty"` // Fix what can be fixed safely; without it the store is only checked
+}
+
+type LibraryVerifyResponse struct {
+	Report *models.VerifyReport `json:"report"`
+}
+
+func LibraryVerifyTool() *mcp.Tool {
+	inputschema, err := jsonschema.For[LibraryVerifyQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+	return &mcp.Tool{
+		Name:        "library-verify",
+		Description: "Check the whole document store, across libraries, for inconsistencies left by earlier versions: rows of documents, references, images, or jobs that are no longer stored (warnings), and fully parsed documents without pages, page numbers with gaps, and citekeys of a library that are the same ignoring case (errors). Findings are grouped by severity and say whether they can be repaired. Without repair nothing is changed. With repair, in one transaction, orphaned rows are deleted, pages are renumbered from 1 along with the pages references, images, and sections point to, and every document sharing a citekey but the one stored first gets a new citekey in ACADEMIC_MCP_CITEKEY_MODE; the actions taken are listed. Documents without pages have to be parsed again.",
+		InputSchema: inputschema,
+	}
+}
+
+func LibraryVerifyToolHandler(ctx context.Context, req *mcp.CallToolRequest, query LibraryVerifyQuery, store storage.Store, log logger.Logger) (*mcp.CallToolResult, *LibraryVerifyResponse, error) {
+	log.Info("library-verify tool called")
+
+	mode, err := citations.ConfiguredCitekeyMode()
+	if err != nil {
+		log.Warn("Using the default citekey mode: %v", err)
+	}
+
+	report, err := store.VerifyStore(ctx, query.Repair, mode)
+	if err != nil {
+		log.Error("Failed to verify the store: %v", err)
+		return errorResult(fmt.Errorf("failed to verify the store: %w", err), models.ErrorStorage), nil, nil
+	}
+
+	log.Info("Store verified: %d errors, %d warnings, %d repairs", len(report.Errors), len(report.Warnings), len(report.Actions))
+	return nil, &LibraryVerifyResponse{Report: report}, nil
+}
@@ -0,0 +1,51 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestLibraryVerifyToolHandler(t *testing.T) {
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(":memory:", log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	for _, doc := range []struct{ docID, citekey string }{{"doc-1", "müller2019"}, {"doc-2", "Müller2019"}} {
+		item := &models.ParsedItem{
+			Metadata: models.ItemMetadata{Authors: []string{"Müller, Hans"}, PublicationDate: "2019", Citekey: doc.citekey},
+			Pages:    []string{"Content"},
+		}
+		if err := store.StoreParsedItem(ctx, doc.docID, item, &models.SourceInfo{}); err != nil {
+			t.Fatalf("Failed to store %s: %v", doc.docID, err)
+		}
+	}
+
+	result, response, err := LibraryVerifyToolHandler(ctx, nil, LibraryVerifyQuery{}, store, log)
+	if err != nil || result != nil {
+		t.Fatalf("LibraryVerifyToolHandler failed: %+v, %v", result, err)
+	}
+	if report := response.Report; len(report.Errors) != 1 || report.Errors[0].Check != models.VerifyDuplicateCitekey || report.Repaired {
+		t.Fatalf("Expected the shared citekey to be reported without repair, got %+v", report)
+	}
+
+	// Repaired citekeys are generated in the configured mode
+	t.Setenv("ACADEMIC_MCP_CITEKEY_MODE", "ascii")
+	_, response, err = LibraryVerifyToolHandler(ctx, nil, LibraryVerifyQuery{Repair: true}, store, log)
+	if err != nil || response == nil {
+		t.Fatalf("LibraryVerifyToolHandler failed: %v", err)
+	}
+	if !response.Report.Repaired || len(response.Report.Actions) != 1 {
+		t.Errorf("Expected one repair, got %+v", response.Report)
+	}
+	if docID, err := store.GetDocumentByCitekey(ctx, "", "muller2019"); err != nil || docID != "doc-2" {
+		t.Errorf("Expected doc-2 to get an ASCII citekey, got %s, %v", docID, err)
+	}
+}
@@ -0,0 +1,158 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
+	"github.com/Epistemic-Technology/academic-mcp/internal/llm"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type QuotationsExportQuery struct {
+	DocumentIDs []string `json:"document_ids,omitempty"`
+	Citekeys    []string `json:"citekeys,omitempty"` // Alternative or addition to document_ids
+	Format      string   `json:"format,omitempty"`   // "markdown" (default), "csv", or "json"
+	// Document library of this server to look document_ids and citekeys up in
+	// (not a Zotero library); empty takes document_ids as given and finds
+	// citekeys in any library
+	Library string `json:"library,omitempty"`
+}
+
+type QuotationsExportResponse struct {
+	Format         string `json:"format"`
+	Content        string `json:"content"`
+	DocumentCount  int    `json:"document_count"`
+	QuotationCount int    `json:"quotation_count"`
+}
+
+// quotationsExport is one document of the JSON export format
+type quotationsExport struct {
+	DocumentID string             `json:"document_id"`
+	Citekey    string             `json:"citekey,omitempty"`
+	Title      string             `json:"title,omitempty"`
+	Authors    []string           `json:"authors,omitempty"`
+	Quotations []models.Quotation `json:"quotations"`
+}
+
+func QuotationsExportTool() *mcp.Tool {
+	inputschema, err := jsonschema.For[QuotationsExportQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+	return &mcp.Tool{
+		Name:        "quotations-export",
+		Description: "Export the stored quotations of one or more documents, identified by document_ids and/or citekeys (looked up in one of the server's libraries if library is set), for a note-taking system or citation manager. The markdown format (default) has a heading per document with its title and authors and each quotation as a blockquote ending in \"(citekey, p. N)\", followed by its context and relevance. The csv format has the columns citekey, page, quotation, context, and relevance. The json format lists each document's quotations with its citekey, title, and authors. Only selected quotations are exported, not those prioritization left out. Nothing is extracted: every document must already have quotations from document-quotations.",
+		InputSchema: inputschema,
+	}
+}
+
+func QuotationsExportToolHandler(ctx context.Context, req *mcp.CallToolRequest, query QuotationsExportQuery, store storage.Store, log logger.Logger) (*mcp.CallToolResult, *QuotationsExportResponse, error) {
+	log.Info("quotations-export tool called")
+
+	format := strings.ToLower(query.Format)
+	if format == "" {
+		format = "markdown"
+	}
+	if format != "markdown" && format != "csv" && format != "json" {
+		return errorResult(fmt.Errorf("unsupported format: %s (supported: 'markdown', 'csv', 'json')", query.Format), models.ErrorInvalidInput), nil, nil
+	}
+	if len(query.DocumentIDs) == 0 && len(query.Citekeys) == 0 {
+		return errorResult(errors.New("document_ids or citekeys is required"), models.ErrorInvalidInput), nil, nil
+	}
+	if err := validateLibrary(query.Library); err != nil {
+		return errorResult(err, models.ErrorInvalidInput), nil, nil
+	}
+
+	// Resolve every document first, keeping the order given and dropping repeats
+	var docIDs []string
+	seen := make(map[string]bool)
+	resolve := func(docID, citekey string) error {
+		resolved, err := resolveDocumentID(ctx, store, query.Library, docID, citekey)
+		if err != nil {
+			return err
+		}
+		if !seen[resolved] {
+			seen[resolved] = true
+			docIDs = append(docIDs, resolved)
+		}
+		return nil
+	}
+	for _, docID := range query.DocumentIDs {
+		if err := resolve(docID, ""); err != nil {
+			log.Error("Failed to resolve document %s: %v", docID, err)
+			return errorResult(err, models.ErrorNotFound), nil, nil
+		}
+	}
+	for _, citekey := range query.Citekeys {
+		if err := resolve("", citekey); err != nil {
+			log.Error("Failed to resolve citekey %s: %v", citekey, err)
+			return errorResult(err, models.ErrorNotFound), nil, nil
+		}
+	}
+
+	sets := make([]documents.QuotationSet, 0, len(docIDs))
+	count := 0
+	for _, docID := range docIDs {
+		quotations, err := store.GetQuotations(ctx, docID)
+		if err != nil {
+			log.Error("Failed to get quotations for document %s: %v", docID, err)
+			return errorResult(fmt.Errorf("failed to get quotations for document %s: %w", docID, err), models.ErrorStorage), nil, nil
+		}
+		// Quotations prioritization left out are kept for promotion but not exported
+		quotations = llm.SelectedQuotations(quotations)
+		if len(quotations) == 0 {
+			return errorResult(fmt.Errorf("document %s has no stored quotations; extract them with document-quotations first", docID), models.ErrorNotFound), nil, nil
+		}
+		metadata, err := store.GetMetadata(ctx, docID)
+		if err != nil {
+			log.Error("Failed to get metadata for document %s: %v", docID, err)
+			return errorResult(fmt.Errorf("failed to get metadata for document %s: %w", docID, err), models.ErrorStorage), nil, nil
+		}
+		sets = append(sets, documents.QuotationSet{DocumentID: docID, Metadata: *metadata, Quotations: quotations})
+		count += len(quotations)
+	}
+
+	var content string
+	switch format {
+	case "markdown":
+		content = documents.ExportQuotationsMarkdown(sets)
+	case "csv":
+		var err error
+		content, err = documents.ExportQuotationsCSV(sets)
+		if err != nil {
+			return errorResult(fmt.Errorf("failed to write CSV: %w", err), models.ErrorInternal), nil, nil
+		}
+	case "json":
+		exports := make([]quotationsExport, len(sets))
+		for i, set := range sets {
+			exports[i] = quotationsExport{
+				DocumentID: set.DocumentID,
+				Citekey:    set.Metadata.Citekey,
+				Title:      set.Metadata.Title,
+				Authors:    set.Metadata.Authors,
+				Quotations: set.Quotations,
+			}
+		}
+		data, err := json.MarshalIndent(exports, "", "  ")
+		if err != nil {
+			return errorResult(fmt.Errorf("failed to marshal quotations: %w", err), models.ErrorInternal), nil, nil
+		}
+		content = string(data) + "\n"
+	}
+
+	log.Info("Exported %d quotations from %d documents as %s", count, len(sets), format)
+	return nil, &QuotationsExportResponse{
+		Format:         format,
+		Content:        content,
+		DocumentCount:  len(sets),
+		QuotationCount: count,
+	}, nil
+}
@@ -0,0 +1,149 @@
+package tools
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// newQuotationsExportStore stores a document with two quotations (smith2020) and
+// one without any (doe2021)
+func newQuotationsExportStore(t *testing.T) storage.Store {
+	t.Helper()
+	store, err := storage.NewSQLiteStore(":memory:", logger.NewNoOpLogger())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	items := map[string]*models.ParsedItem{
+		"doc-quoted": {
+			Metadata: models.ItemMetadata{Title: "Memory and the Archive", Authors: []string{"Smith, Jane", "Doe, John"}, Citekey: "smith2020"},
+			Pages:    []string{"Memory is reconstructive.", "Archives are partial, \"selective\", and political."},
+			Quotations: []models.Quotation{
+				{QuotationText: "Memory is reconstructive.", PageNumber: "12", Context: "Opening claim", Relevance: "Central thesis", Selected: true},
+				{QuotationText: "Archives are partial, \"selective\", and political.", PageNumber: "13", Context: "On archives, briefly", Selected: true},
+				// Left out by prioritization, so not exported
+				{QuotationText: "Forgetting is active.", PageNumber: "14", Rank: 3},
+			},
+		},
+		"doc-unquoted": {
+			Metadata: models.ItemMetadata{Title: "Unquoted", Citekey: "doe2021"},
+			Pages:    []string{"Text"},
+		},
+	}
+	for docID, item := range items {
+		if err := store.StoreParsedItem(context.Background(), docID, item, &models.SourceInfo{}); err != nil {
+			t.Fatalf("Failed to store %s: %v", docID, err)
+		}
+	}
+	return store
+}
+
+func TestQuotationsExportToolHandler_Markdown(t *testing.T) {
+	store := newQuotationsExportStore(t)
+
+	result, response, err := QuotationsExportToolHandler(context.Background(), nil, QuotationsExportQuery{Citekeys: []string{"smith2020"}}, store, logger.NewNoOpLogger())
+	if err != nil || result != nil {
+		t.Fatalf("QuotationsExportToolHandler failed: %+v, %v", result, err)
+	}
+	if response.Format != "markdown" || response.DocumentCount != 1 || response.QuotationCount != 2 {
+		t.Errorf("Unexpected response counts: %+v", response)
+	}
+
+	expected := `## Memory and the Archive — Smith, Jane; Doe, John
+
+> Memory is reconstructive. (smith2020, p. 12)
+
+Context: Opening claim
+
+Relevance: Central thesis
+
+> Archives are partial, "selective", and political. (smith2020, p. 13)
+
+Context: On archives, briefly
+`
+	if response.Content != expected {
+		t.Errorf("Unexpected markdown:\n%s\nwant:\n%s", response.Content, expected)
+	}
+}
+
+func TestQuotationsExportToolHandler_CSV(t *testing.T) {
+	store := newQuotationsExportStore(t)
+
+	_, response, err := QuotationsExportToolHandler(context.Background(), nil, QuotationsExportQuery{DocumentIDs: []string{"doc-quoted"}, Format: "CSV"}, store, logger.NewNoOpLogger())
+	if err != nil || response == nil {
+		t.Fatalf("QuotationsExportToolHandler failed: %v", err)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(response.Content)).ReadAll()
+	if err != nil {
+		t.Fatalf("Export is not valid CSV: %v", err)
+	}
+	expected := [][]string{
+		{"citekey", "page", "quotation", "context", "relevance"},
+		{"smith2020", "12", "Memory is reconstructive.", "Opening claim", "Central thesis"},
+		{"smith2020", "13", "Archives are partial, \"selective\", and political.", "On archives, briefly", ""},
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("Expected records %q, got %q", expected, records)
+	}
+}
+
+func TestQuotationsExportToolHandler_JSON(t *testing.T) {
+	store := newQuotationsExportStore(t)
+
+	// The same document named twice is exported once
+	query := QuotationsExportQuery{DocumentIDs: []string{"doc-quoted"}, Citekeys: []string{"smith2020"}, Format: "json"}
+	_, response, err := QuotationsExportToolHandler(context.Background(), nil, query, store, logger.NewNoOpLogger())
+	if err != nil || response == nil {
+		t.Fatalf("QuotationsExportToolHandler failed: %v", err)
+	}
+
+	var exports []quotationsExport
+	if err := json.Unmarshal([]byte(response.Content), &exports); err != nil {
+		t.Fatalf("Export is not valid JSON: %v", err)
+	}
+	if len(exports) != 1 || exports[0].DocumentID != "doc-quoted" || exports[0].Citekey != "smith2020" ||
+		exports[0].Title != "Memory and the Archive" || len(exports[0].Authors) != 2 || len(exports[0].Quotations) != 2 {
+		t.Fatalf("Unexpected export: %+v", exports)
+	}
+	if exports[0].Quotations[1].PageNumber != "13" {
+		t.Errorf("Expected quotations in stored order, got %+v", exports[0].Quotations)
+	}
+}
+
+func TestQuotationsExportToolHandler_Errors(t *testing.T) {
+	store := newQuotationsExportStore(t)
+
+	tests := []struct {
+		name     string
+		query    QuotationsExportQuery
+		code     models.ErrorCode
+		contains string
+	}{
+		{"no documents", QuotationsExportQuery{}, models.ErrorInvalidInput, "document_ids or citekeys"},
+		{"unsupported format", QuotationsExportQuery{DocumentIDs: []string{"doc-quoted"}, Format: "ris"}, models.ErrorInvalidInput, "unsupported format"},
+		{"unknown document", QuotationsExportQuery{DocumentIDs: []string{"missing"}}, models.ErrorNotFound, "missing"},
+		{"document without quotations", QuotationsExportQuery{Citekeys: []string{"smith2020", "doe2021"}}, models.ErrorNotFound, "document-quotations"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := QuotationsExportToolHandler(context.Background(), nil, tt.query, store, logger.NewNoOpLogger())
+			if err != nil {
+				t.Fatalf("Expected an error result, got error: %v", err)
+			}
+			toolErr := resultError(t, result)
+			if toolErr.Code != tt.code || !strings.Contains(toolErr.Message, tt.contains) {
+				t.Errorf("Expected %s error containing %q, got %+v", tt.code, tt.contains, toolErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/config"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+type ServerReloadConfigQuery struct{}
+
+type ServerReloadConfigResponse struct {
+	Changed     []string          `json:"changed"`     // Names of the settings that changed, never their values
+	Credentials ServerCredentials `json:"credentials"` // The credentials now in use, as server-status reports them
+}
+
+func ServerReloadConfigTool() *mcp.Tool {
+	inputschema, err := jsonschema.For[ServerReloadConfigQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+	return &mcp.Tool{
+		Name:        "server-reload-config",
+		Description: "Reload the server's credentials (OPENAI_API_KEY, ZOTERO_API_KEY, ZOTERO_LIBRARY_TYPE, ZOTERO_LIBRARY_ID) from the environment and the config file named by ACADEMIC_MCP_CONFIG, so keys can be rotated without restarting; sending the server SIGHUP does the same. Calls that start afterwards use the new credentials. Returns the names of the settings that changed and which credentials are now set, never their values. If the config file cannot be read, the current credentials are kept.",
+		InputSchema: inputschema,
+	}
+}
+
+func ServerReloadConfigToolHandler(ctx context.Context, req *mcp.CallToolRequest, query ServerReloadConfigQuery, credentials *config.Provider, log logger.Logger) (*mcp.CallToolResult, *ServerReloadConfigResponse, error) {
+	log.Info("server-reload-config tool called")
+
+	changed, err := credentials.Reload()
+	if err != nil {
+		log.Error("Failed to reload configuration: %v", err)
+		return errorResult(err, models.ErrorConfiguration), nil, nil
+	}
+	if changed == nil {
+		changed = []string{}
+	}
+	log.Info("Reloaded configuration; %d settings changed", len(changed))
+	return nil, &ServerReloadConfigResponse{Changed: changed, Credentials: credentialStatus(credentials)}, nil
+}
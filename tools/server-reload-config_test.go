@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/config"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestServerReloadConfigToolHandler(t *testing.T) {
+	for _, name := range []string{"OPENAI_API_KEY", "ZOTERO_API_KEY", "ZOTERO_LIBRARY_ID", "ZOTERO_LIBRARY_TYPE"} {
+		t.Setenv(name, "")
+	}
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(":memory:", log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeConfig := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeConfig(`{"ZOTERO_LIBRARY_ID": "111"}`)
+	credentials, err := config.NewProvider(path)
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	ctx := config.NewContext(context.Background(), credentials)
+
+	// Without a key, tools that call OpenAI fail with the configuration code
+	result, _, err := DocumentSummarizeToolHandler(ctx, nil, DocumentSummarizeQuery{Style: "unknown"}, store, log)
+	if err != nil {
+		t.Fatalf("Expected an error result, got error: %v", err)
+	}
+	if toolErr := resultError(t, result); toolErr.Code != models.ErrorConfiguration {
+		t.Errorf("Expected configuration error without an API key, got %+v", toolErr)
+	}
+
+	// Reloading picks up the key for later calls
+	writeConfig(`{"OPENAI_API_KEY": "sk-rotated", "ZOTERO_LIBRARY_ID": "111"}`)
+	result, response, err := ServerReloadConfigToolHandler(ctx, nil, ServerReloadConfigQuery{}, credentials, log)
+	if err != nil || result != nil {
+		t.Fatalf("ServerReloadConfigToolHandler failed: %v %+v", err, result)
+	}
+	if !reflect.DeepEqual(response.Changed, []string{"OPENAI_API_KEY"}) {
+		t.Errorf("Expected the OpenAI key to change, got %v", response.Changed)
+	}
+	if !response.Credentials.OpenAIAPIKey || response.Credentials.ZoteroLibraryID != "111" || response.Credentials.ConfigFile != path {
+		t.Errorf("Unexpected credentials after reload: %+v", response.Credentials)
+	}
+	result, _, _ = DocumentSummarizeToolHandler(ctx, nil, DocumentSummarizeQuery{Style: "unknown"}, store, log)
+	if toolErr := resultError(t, result); toolErr.Code != models.ErrorInvalidInput {
+		t.Errorf("Expected the call to get past the key check, got %+v", toolErr)
+	}
+
+	// Reloading again without changes reports none
+	if _, response, _ := ServerReloadConfigToolHandler(ctx, nil, ServerReloadConfigQuery{}, credentials, log); response == nil || response.Changed == nil || len(response.Changed) != 0 {
+		t.Errorf("Expected no changes, got %+v", response)
+	}
+
+	// An invalid file is reported and the current key kept
+	writeConfig(`{"OPENAI_API_KEY": 1}`)
+	result, _, _ = ServerReloadConfigToolHandler(ctx, nil, ServerReloadConfigQuery{}, credentials, log)
+	if toolErr := resultError(t, result); toolErr.Code != models.ErrorConfiguration {
+		t.Errorf("Expected configuration error for an invalid file, got %+v", toolErr)
+	}
+	if key, err := credentials.OpenAIAPIKey(); err != nil || key != "sk-rotated" {
+		t.Errorf("Expected the key to be kept, got %q (%v)", key, err)
+	}
+}
@@ -0,0 +1,237 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/config"
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
+	"github.com/Epistemic-Technology/academic-mcp/internal/llm"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/operations"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+)
+
+// statusProbeTimeout bounds each connectivity probe
+const statusProbeTimeout = 5 * time.Second
+
+type ServerStatusQuery struct {
+	Probe bool `json:"probe,omitempty"` // Check that the OpenAI and Zotero APIs accept the configured keys
+}
+
+// ServerCredentials reports which credentials are configured, never their values
+type ServerCredentials struct {
+	OpenAIAPIKey      bool   `json:"openai_api_key"`
+	ZoteroAPIKey      bool   `json:"zotero_api_key"`
+	ZoteroLibraryID   string `json:"zotero_library_id,omitempty"`
+	ZoteroLibraryType string `json:"zotero_library_type,omitempty"`
+	OpenAIEndpoint    string `json:"openai_endpoint,omitempty"` // Where OpenAI requests go, if not to the OpenAI API
+	ConfigFile        string `json:"config_file,omitempty"`     // The config file read with the environment, if any
+	LoadedAt          string `json:"loaded_at"`                 // When the credentials were last read (RFC 3339)
+}
+
+type ServerDatabase struct {
+	Path          string `json:"path,omitempty"`
+	DocumentCount int    `json:"document_count"`
+	SchemaVersion int    `json:"schema_version"`
+	Error         string `json:"error,omitempty"`
+}
+
+type ServerLogging struct {
+	Output      string `json:"output"`                 // "file" or "stderr"
+	FilePath    string `json:"file_path,omitempty"`    // Only when logging to a file
+	DirWritable bool   `json:"dir_writable,omitempty"` // Whether the log file's directory can be written to
+	Error       string `json:"error,omitempty"`
+}
+
+// ServerProbe is the outcome of a connectivity check against an API
+type ServerProbe struct {
+	OK        bool   `json:"ok"`
+	Skipped   string `json:"skipped,omitempty"` // Why the probe was not run
+	LatencyMS int64  `json:"latency_ms,omitempty"`
+	Detail    string `json:"detail,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+type ServerProbes struct {
+	OpenAI ServerProbe `json:"openai"`
+	Zotero ServerProbe `json:"zotero"`
+}
+
+type ServerStatusResponse struct {
+	Credentials    ServerCredentials `json:"credentials"`
+	Database       ServerDatabase    `json:"database"`
+	Models         map[string]string `json:"models"`          // OpenAI model used for each task
+	ParserVersion  string            `json:"parser_version"`  // Parsing pipeline and prompt version
+	LLMWorkers     int               `json:"llm_workers"`     // OpenAI requests run in parallel per document
+	JobWorkers     int               `json:"job_workers"`     // Documents processed in parallel by async jobs
+	BatchDocuments int               `json:"batch_documents"` // Documents of batch tool calls processed in parallel
+	PageImageDPI   int               `json:"page_image_dpi"`  // Resolution of pages rendered by document-render-page
+	Logging        ServerLogging     `json:"logging"`
+	Probes         *ServerProbes     `json:"probes,omitempty"`
+	Warnings       []string          `json:"warnings,omitempty"`
+}
+
+func ServerStatusTool() *mcp.Tool {
+	inputschema, err := jsonschema.For[ServerStatusQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+	return &mcp.Tool{
+		Name:        "server-status",
+		Description: "Report the server's configuration for troubleshooting: which credentials are set (never their values), the database path, document count, and schema version, the OpenAI models and worker pool sizes, and whether the log directory is writable. With probe set, also checks that the OpenAI and Zotero APIs can be reached and accept the configured keys, each within a few seconds.",
+		InputSchema: inputschema,
+	}
+}
+
+func ServerStatusToolHandler(ctx context.Context, req *mcp.CallToolRequest, query ServerStatusQuery, store storage.Store, log logger.Logger) (*mcp.CallToolResult, *ServerStatusResponse, error) {
+	log.Info("server-status tool called")
+
+	provider := config.FromContext(ctx)
+	credentials := provider.Credentials()
+	status := &ServerStatusResponse{
+		Credentials:   credentialStatus(provider),
+		Database:      databaseStatus(ctx, store),
+		Models:        llm.Models(),
+		ParserVersion: fmt.Sprintf("%s (prompts v%d)", llm.ParserVersion, llm.PromptVersion),
+		LLMWorkers:    llm.MaxWorkers(),
+		Logging:       loggingStatus(),
+	}
+
+	jobWorkers, err := operations.JobWorkers()
+	if err != nil {
+		status.Warnings = append(status.Warnings, err.Error())
+	}
+	status.JobWorkers = jobWorkers
+	batchDocuments, err := operations.BatchDocuments()
+	if err != nil {
+		status.Warnings = append(status.Warnings, err.Error())
+	}
+	status.BatchDocuments = batchDocuments
+	pageImageDPI, err := documents.PageImageDPI()
+	if err != nil {
+		status.Warnings = append(status.Warnings, err.Error())
+	}
+	status.PageImageDPI = pageImageDPI
+
+	if query.Probe {
+		status.Probes = &ServerProbes{
+			OpenAI: probeOpenAI(ctx, credentials.OpenAIAPIKey),
+			Zotero: probeZotero(ctx, credentials.ZoteroAPIKey),
+		}
+	}
+
+	return nil, status, nil
+}
+
+// credentialStatus reports which of the provider's credentials are set
+func credentialStatus(provider *config.Provider) ServerCredentials {
+	credentials := provider.Credentials()
+	return ServerCredentials{
+		OpenAIAPIKey:      credentials.OpenAIAPIKey != "",
+		ZoteroAPIKey:      credentials.ZoteroAPIKey != "",
+		ZoteroLibraryID:   credentials.ZoteroLibraryID,
+		ZoteroLibraryType: credentials.ZoteroLibraryType,
+		OpenAIEndpoint:    openAIEndpoint(credentials),
+		ConfigFile:        provider.Path(),
+		LoadedAt:          provider.LoadedAt().UTC().Format(time.RFC3339),
+	}
+}
+
+// openAIEndpoint describes where OpenAI requests go: an Azure OpenAI endpoint,
+// a base URL, or "" for the OpenAI API
+func openAIEndpoint(credentials config.Credentials) string {
+	if credentials.AzureOpenAIEndpoint != "" {
+		return "Azure OpenAI " + credentials.AzureOpenAIEndpoint
+	}
+	return credentials.OpenAIBaseURL
+}
+
+// databaseStatus reports the database path and what the store holds
+func databaseStatus(ctx context.Context, store storage.Store) ServerDatabase {
+	var status ServerDatabase
+	var err error
+	if status.Path, err = storage.DatabasePath(); err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	if status.DocumentCount, err = store.CountDocuments(ctx); err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	if status.SchemaVersion, err = store.SchemaVersion(ctx); err != nil {
+		status.Error = err.Error()
+	}
+	return status
+}
+
+// loggingStatus reports where the server logs and, for a log file, whether a
+// file can be created in its directory
+func loggingStatus() ServerLogging {
+	status := ServerLogging{Output: logger.ResolveOutput("")}
+	if status.Output != "file" {
+		return status
+	}
+	filePath, err := logger.ResolveFilePath("")
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	status.FilePath = filePath
+
+	probe, err := os.CreateTemp(filepath.Dir(filePath), ".write-check-*")
+	if err != nil {
+		status.Error = fmt.Sprintf("log directory is not writable: %v", err)
+		return status
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	status.DirWritable = true
+	return status
+}
+
+// probeOpenAI checks that OpenAI accepts OPENAI_API_KEY by listing models
+func probeOpenAI(ctx context.Context, apiKey string) ServerProbe {
+	if apiKey == "" {
+		return ServerProbe{Skipped: "OPENAI_API_KEY is not set"}
+	}
+	ctx, cancel := context.WithTimeout(ctx, statusProbeTimeout)
+	defer cancel()
+
+	start := time.Now()
+	count, err := llm.CheckAPIKey(ctx, apiKey)
+	probe := ServerProbe{LatencyMS: time.Since(start).Milliseconds()}
+	if err != nil {
+		probe.Error = err.Error()
+		return probe
+	}
+	probe.OK = true
+	probe.Detail = fmt.Sprintf("%d models available", count)
+	return probe
+}
+
+// probeZotero checks that Zotero accepts ZOTERO_API_KEY by looking up its user
+func probeZotero(ctx context.Context, apiKey string) ServerProbe {
+	if apiKey == "" {
+		return ServerProbe{Skipped: "ZOTERO_API_KEY is not set"}
+	}
+	ctx, cancel := context.WithTimeout(ctx, statusProbeTimeout)
+	defer cancel()
+
+	start := time.Now()
+	info, err := documents.CheckZoteroKey(ctx, apiKey)
+	probe := ServerProbe{LatencyMS: time.Since(start).Milliseconds()}
+	if err != nil {
+		probe.Error = err.Error()
+		return probe
+	}
+	probe.OK = true
+	probe.Detail = fmt.Sprintf("key belongs to user %d (%s)", info.UserID, info.Username)
+	return probe
+}
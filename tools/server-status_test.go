@@ -0,0 +1,165 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+// serverStatusEnv points the server at a database and log file in a temporary
+// directory and clears the credentials
+func serverStatusEnv(t *testing.T) (dbPath, logPath string) {
+	t.Helper()
+	dir := t.TempDir()
+	dbPath = filepath.Join(dir, "academic.db")
+	logPath = filepath.Join(dir, "logs", "academic.log")
+	if err := os.Mkdir(filepath.Dir(logPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ACADEMIC_MCP_DB_PATH", dbPath)
+	t.Setenv("LOG_OUTPUT", "file")
+	t.Setenv("LOG_FILE_PATH", logPath)
+	t.Setenv("ACADEMIC_MCP_JOB_WORKERS", "")
+	for _, name := range []string{"OPENAI_API_KEY", "ZOTERO_API_KEY", "ZOTERO_LIBRARY_ID", "ZOTERO_LIBRARY_TYPE", "OPENAI_BASE_URL", "AZURE_OPENAI_ENDPOINT"} {
+		t.Setenv(name, "")
+	}
+	return dbPath, logPath
+}
+
+func TestServerStatusToolHandler(t *testing.T) {
+	dbPath, logPath := serverStatusEnv(t)
+	t.Setenv("OPENAI_API_KEY", "sk-secret-openai")
+	t.Setenv("ZOTERO_LIBRARY_ID", "12345")
+	t.Setenv("OPENAI_BASE_URL", "https://gateway.example.edu/v1")
+	t.Setenv("ACADEMIC_MCP_JOB_WORKERS", "3")
+
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(dbPath, log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+	if err := store.StoreParsedItem(ctx, "doc1", &models.ParsedItem{Metadata: models.ItemMetadata{Title: "Field Notes"}, Pages: []string{"One"}}, &models.SourceInfo{}); err != nil {
+		t.Fatalf("StoreParsedItem failed: %v", err)
+	}
+
+	result, status, err := ServerStatusToolHandler(ctx, nil, ServerStatusQuery{}, store, log)
+	if err != nil || result != nil {
+		t.Fatalf("ServerStatusToolHandler failed: %v %+v", err, result)
+	}
+
+	if !status.Credentials.OpenAIAPIKey || status.Credentials.ZoteroAPIKey || status.Credentials.ZoteroLibraryID != "12345" ||
+		status.Credentials.OpenAIEndpoint != "https://gateway.example.edu/v1" {
+		t.Errorf("Unexpected credentials: %+v", status.Credentials)
+	}
+	if status.Database.Path != dbPath || status.Database.DocumentCount != 1 || status.Database.SchemaVersion == 0 || status.Database.Error != "" {
+		t.Errorf("Unexpected database status: %+v", status.Database)
+	}
+	if status.Models["parse"] == "" || status.LLMWorkers == 0 || status.JobWorkers != 3 || status.BatchDocuments != 3 || len(status.Warnings) != 0 {
+		t.Errorf("Unexpected configuration: %+v", status)
+	}
+	if status.Logging.Output != "file" || status.Logging.FilePath != logPath || !status.Logging.DirWritable {
+		t.Errorf("Unexpected logging status: %+v", status.Logging)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(logPath)); len(entries) != 0 {
+		t.Errorf("Expected the writability check to leave nothing behind, got %d files", len(entries))
+	}
+	if status.Probes != nil {
+		t.Errorf("Expected no probes unless requested, got %+v", status.Probes)
+	}
+	if report := fmt.Sprintf("%+v", status); strings.Contains(report, "sk-secret-openai") {
+		t.Errorf("Expected the report not to contain the API key, got %s", report)
+	}
+}
+
+func TestServerStatusToolHandler_Misconfigured(t *testing.T) {
+	dbPath, logPath := serverStatusEnv(t)
+	t.Setenv("LOG_FILE_PATH", filepath.Join(filepath.Dir(logPath), "missing", "academic.log"))
+	t.Setenv("ACADEMIC_MCP_JOB_WORKERS", "none")
+
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(dbPath, log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	_, status, err := ServerStatusToolHandler(context.Background(), nil, ServerStatusQuery{Probe: true}, store, log)
+	if err != nil {
+		t.Fatalf("ServerStatusToolHandler failed: %v", err)
+	}
+	if status.Logging.DirWritable || status.Logging.Error == "" {
+		t.Errorf("Expected the missing log directory to be reported, got %+v", status.Logging)
+	}
+	if len(status.Warnings) != 1 || status.JobWorkers == 0 {
+		t.Errorf("Expected a warning about the job worker count and the default, got %d workers and %q", status.JobWorkers, status.Warnings)
+	}
+	if status.Probes == nil || status.Probes.OpenAI.Skipped == "" || status.Probes.Zotero.Skipped == "" || status.Probes.OpenAI.OK {
+		t.Errorf("Expected both probes skipped without credentials, got %+v", status.Probes)
+	}
+}
+
+func TestServerStatusToolHandler_Probes(t *testing.T) {
+	dbPath, _ := serverStatusEnv(t)
+	t.Setenv("OPENAI_API_KEY", "test-key")
+	t.Setenv("ZOTERO_API_KEY", "zotero-key")
+
+	openAI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models" || r.Header.Get("Authorization") != "Bearer test-key" {
+			http.Error(w, `{"error":{"message":"bad request"}}`, http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"object":"list","data":[{"id":"gpt-5-mini","object":"model","created":0,"owned_by":"openai"},{"id":"gpt-5","object":"model","created":0,"owned_by":"openai"}]}`)
+	}))
+	defer openAI.Close()
+	t.Setenv("OPENAI_BASE_URL", openAI.URL)
+
+	zotero := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/keys/current" || r.Header.Get("Zotero-API-Key") != "zotero-key" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprint(w, `{"key":"zotero-key","userID":42,"username":"jsmith","access":{}}`)
+	}))
+	defer zotero.Close()
+	t.Setenv("ZOTERO_API_BASE_URL", zotero.URL)
+
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(dbPath, log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	_, status, err := ServerStatusToolHandler(context.Background(), nil, ServerStatusQuery{Probe: true}, store, log)
+	if err != nil {
+		t.Fatalf("ServerStatusToolHandler failed: %v", err)
+	}
+	if !status.Probes.OpenAI.OK || status.Probes.OpenAI.Detail != "2 models available" {
+		t.Errorf("Expected the OpenAI probe to succeed, got %+v", status.Probes.OpenAI)
+	}
+	if !status.Probes.Zotero.OK || !strings.Contains(status.Probes.Zotero.Detail, "jsmith") {
+		t.Errorf("Expected the Zotero probe to succeed, got %+v", status.Probes.Zotero)
+	}
+
+	// A rejected key fails the probe without failing the report
+	t.Setenv("ZOTERO_API_KEY", "revoked-key")
+	_, status, err = ServerStatusToolHandler(context.Background(), nil, ServerStatusQuery{Probe: true}, store, log)
+	if err != nil {
+		t.Fatalf("ServerStatusToolHandler failed: %v", err)
+	}
+	if status.Probes.Zotero.OK || !strings.Contains(status.Probes.Zotero.Error, "403") {
+		t.Errorf("Expected the Zotero probe to fail, got %+v", status.Probes.Zotero)
+	}
+}
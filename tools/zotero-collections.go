@@ -2,15 +2,15 @@ package tools
 
 import (
 	"context"
-	"fmt"
-	"os"
-
 	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/Epistemic-Technology/academic-mcp/internal/config"
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
 	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
 	"github.com/Epistemic-Technology/academic-mcp/internal/operations"
 	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
 )
 
 type ZoteroCollectionsQuery struct {
@@ -18,6 +18,9 @@ type ZoteroCollectionsQuery struct {
 	ParentCollection string `json:"parent_collection,omitempty"` // Filter by parent collection key (for subcollections)
 	Limit            int    `json:"limit,omitempty"`             // Max results (default 100)
 	Sort             string `json:"sort,omitempty"`              // Sort field (default "title")
+	// Library selection (defaults to ZOTERO_LIBRARY_TYPE / ZOTERO_LIBRARY_ID)
+	LibraryType string `json:"library_type,omitempty"` // "user" or "group"
+	LibraryID   string `json:"library_id,omitempty"`   // User or group library ID
 }
 
 type ZoteroCollectionsResponse struct {
@@ -46,15 +49,14 @@ func ZoteroCollectionsTool() *mcp.Tool {
 func ZoteroCollectionsToolHandler(ctx context.Context, req *mcp.CallToolRequest, query ZoteroCollectionsQuery, store storage.Store, log logger.Logger) (*mcp.CallToolResult, *ZoteroCollectionsResponse, error) {
 	log.Info("zotero-collections tool called")
 
-	// Get Zotero credentials from environment
-	zoteroAPIKey := os.Getenv("ZOTERO_API_KEY")
-	if zoteroAPIKey == "" {
-		return nil, nil, fmt.Errorf("ZOTERO_API_KEY environment variable not set")
+	zoteroAPIKey, err := config.FromContext(ctx).ZoteroAPIKey()
+	if err != nil {
+		return errorResult(err, models.ErrorConfiguration), nil, nil
 	}
 
-	libraryID := os.Getenv("ZOTERO_LIBRARY_ID")
-	if libraryID == "" {
-		return nil, nil, fmt.Errorf("ZOTERO_LIBRARY_ID environment variable not set")
+	library, err := documents.ResolveZoteroLibrary(ctx, query.LibraryType, query.LibraryID)
+	if err != nil {
+		return errorResult(err, models.ErrorInvalidInput), nil, nil
 	}
 
 	// Convert tool query parameters to operations parameters
@@ -66,9 +68,9 @@ func ZoteroCollectionsToolHandler(ctx context.Context, req *mcp.CallToolRequest,
 	}
 
 	// Execute collection listing using internal operation
-	collections, err := operations.ListZoteroCollections(ctx, zoteroAPIKey, libraryID, listParams, log)
+	collections, err := operations.ListZoteroCollections(ctx, zoteroAPIKey, library, listParams, log)
 	if err != nil {
-		return nil, nil, err
+		return errorResult(err, models.ErrorUpstreamZotero), nil, nil
 	}
 
 	// Convert internal results to tool response format
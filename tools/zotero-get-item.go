@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"context"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/config"
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/operations"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+type ZoteroGetItemQuery struct {
+	ItemKey string `json:"item_key"`          // Key of the item, or of one of its attachments
+	Refresh bool   `json:"refresh,omitempty"` // Fetch the attachment listing from Zotero instead of the cache
+	// Library selection (defaults to ZOTERO_LIBRARY_TYPE / ZOTERO_LIBRARY_ID)
+	LibraryType string `json:"library_type,omitempty"` // "user" or "group"
+	LibraryID   string `json:"library_id,omitempty"`   // User or group library ID
+}
+
+type ZoteroGetItemResponse struct {
+	Key           string              `json:"key"`
+	Metadata      models.ItemMetadata `json:"metadata"`
+	DateAdded     string              `json:"date_added,omitempty"`
+	DateModified  string              `json:"date_modified,omitempty"`
+	Tags          []string            `json:"tags,omitempty"`
+	Attachments   []AttachmentInfo    `json:"attachments,omitempty"`
+	AttachmentKey string              `json:"attachment_key,omitempty"` // Set when item_key was an attachment of the item
+	Citekey       string              `json:"citekey,omitempty"`        // Citekey if document has been parsed
+}
+
+func ZoteroGetItemTool() *mcp.Tool {
+	inputschema, err := jsonschema.For[ZoteroGetItemQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+	return &mcp.Tool{
+		Name:        "zotero-get-item",
+		Description: "Fetch a single Zotero item by key, without a search, and return its full bibliographic metadata (title, authors, date, publication, DOI, ISBN, publisher, and so on), tags, and file attachments. Use the attachment keys with document-parse. The key of an attachment returns its parent item, with attachment_key set to the key given.",
+		InputSchema: inputschema,
+	}
+}
+
+func ZoteroGetItemToolHandler(ctx context.Context, req *mcp.CallToolRequest, query ZoteroGetItemQuery, store storage.Store, log logger.Logger) (*mcp.CallToolResult, *ZoteroGetItemResponse, error) {
+	log.Info("zotero-get-item tool called")
+
+	zoteroAPIKey, err := config.FromContext(ctx).ZoteroAPIKey()
+	if err != nil {
+		return errorResult(err, models.ErrorConfiguration), nil, nil
+	}
+
+	library, err := documents.ResolveZoteroLibrary(ctx, query.LibraryType, query.LibraryID)
+	if err != nil {
+		return errorResult(err, models.ErrorInvalidInput), nil, nil
+	}
+
+	details, err := operations.GetZoteroItem(ctx, zoteroAPIKey, library, query.ItemKey, query.Refresh, store, log)
+	if err != nil {
+		return errorResult(err, models.ErrorUpstreamZotero), nil, nil
+	}
+
+	response := &ZoteroGetItemResponse{
+		Key:           details.Key,
+		Metadata:      *details.Metadata,
+		DateAdded:     details.DateAdded,
+		DateModified:  details.DateModified,
+		Tags:          details.Tags,
+		AttachmentKey: details.AttachmentKey,
+	}
+	response.Attachments, response.Citekey = convertAttachments(ctx, details.Attachments, library, zoteroCitekeyMap(ctx, store, log))
+
+	return nil, response, nil
+}
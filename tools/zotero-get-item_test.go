@@ -0,0 +1,84 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestZoteroGetItemToolHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/users/111/items/ATT1":
+			w.Write([]byte(`{"key":"ATT1","data":{"key":"ATT1","itemType":"attachment","parentItem":"ITEM1","contentType":"application/pdf"}}`))
+		case "/users/111/items/ITEM1":
+			w.Write([]byte(`{"key":"ITEM1","data":{"key":"ITEM1","itemType":"journalArticle","title":"Soil Carbon","DOI":"10.1000/soil","publicationTitle":"Soil Science","creators":[{"creatorType":"author","firstName":"Ana","lastName":"Silva"}]}}`))
+		case "/users/111/items/ITEM1/children":
+			w.Write([]byte(`[{"key":"ATT1","data":{"key":"ATT1","itemType":"attachment","contentType":"application/pdf","filename":"silva.pdf"}}]`))
+		default:
+			http.Error(w, "Not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("ZOTERO_API_BASE_URL", server.URL)
+	t.Setenv("ZOTERO_API_KEY", "test-key")
+	t.Setenv("ZOTERO_LIBRARY_TYPE", "user")
+	t.Setenv("ZOTERO_LIBRARY_ID", "111")
+
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(":memory:", log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	item := &models.ParsedItem{Metadata: models.ItemMetadata{Title: "Soil Carbon", Citekey: "silva2020"}}
+	if err := store.StoreParsedItem(ctx, "zotero_ATT1", item, &models.SourceInfo{ZoteroID: "ATT1"}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+
+	_, resp, err := ZoteroGetItemToolHandler(ctx, nil, ZoteroGetItemQuery{ItemKey: "ATT1"}, store, log)
+	if err != nil {
+		t.Fatalf("ZoteroGetItemToolHandler failed: %v", err)
+	}
+	if resp.Key != "ITEM1" || resp.AttachmentKey != "ATT1" {
+		t.Errorf("Expected parent item ITEM1 for attachment ATT1, got %s (attachment %q)", resp.Key, resp.AttachmentKey)
+	}
+	if resp.Metadata.DOI != "10.1000/soil" || resp.Metadata.Publication != "Soil Science" || len(resp.Metadata.Authors) != 1 {
+		t.Errorf("Expected full metadata, got %+v", resp.Metadata)
+	}
+	if len(resp.Attachments) != 1 || resp.Attachments[0].Filename != "silva.pdf" {
+		t.Errorf("Expected attachment silva.pdf, got %+v", resp.Attachments)
+	}
+	if resp.Citekey != "silva2020" {
+		t.Errorf("Expected citekey silva2020, got %q", resp.Citekey)
+	}
+
+	tests := []struct {
+		name     string
+		query    ZoteroGetItemQuery
+		expected models.ErrorCode
+	}{
+		{"Missing key", ZoteroGetItemQuery{}, models.ErrorInvalidInput},
+		{"Unknown item", ZoteroGetItemQuery{ItemKey: "MISSING"}, models.ErrorNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := ZoteroGetItemToolHandler(ctx, nil, tt.query, store, log)
+			if err != nil {
+				t.Fatalf("Expected an error result, got error: %v", err)
+			}
+			if toolErr := resultError(t, result); toolErr.Code != tt.expected {
+				t.Errorf("Expected %s error, got %+v", tt.expected, toolErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/config"
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/operations"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+type ZoteroImportQuery struct {
+	Collection   string `json:"collection,omitempty"`    // Collection key to import from
+	Query        string `json:"query,omitempty"`         // Quick search text to select items (alternative to collection)
+	Limit        int    `json:"limit,omitempty"`         // Max Zotero items to enumerate (default 100)
+	MaxDocuments int    `json:"max_documents,omitempty"` // Max documents to parse in this call (default: no cap)
+	DryRun       bool   `json:"dry_run,omitempty"`       // Only report what would be parsed
+	// Library selection (defaults to ZOTERO_LIBRARY_TYPE / ZOTERO_LIBRARY_ID)
+	LibraryType string `json:"library_type,omitempty"` // "user" or "group"
+	LibraryID   string `json:"library_id,omitempty"`   // User or group library ID
+}
+
+type ZoteroImportItem struct {
+	ItemKey       string `json:"item_key"`
+	AttachmentKey string `json:"attachment_key"`
+	Title         string `json:"title,omitempty"`
+	DocumentID    string `json:"document_id,omitempty"`
+	Citekey       string `json:"citekey,omitempty"`
+	Reason        string `json:"reason,omitempty"`
+	// Machine-readable code and message for a failed item
+	ErrorDetail *models.ToolError `json:"error_detail,omitempty"`
+}
+
+type ZoteroImportResponse struct {
+	Imported       []ZoteroImportItem `json:"imported,omitempty"`
+	Skipped        []ZoteroImportItem `json:"skipped,omitempty"`
+	Failed         []ZoteroImportItem `json:"failed,omitempty"`
+	WouldImport    []ZoteroImportItem `json:"would_import,omitempty"`
+	ImportedCount  int                `json:"imported_count"`
+	SkippedCount   int                `json:"skipped_count"`
+	FailedCount    int                `json:"failed_count"`
+	RemainingCount int                `json:"remaining_count"` // Documents left unparsed because of max_documents
+	DryRun         bool               `json:"dry_run,omitempty"`
+}
+
+func ZoteroImportTool() *mcp.Tool {
+	inputschema, err := jsonschema.For[ZoteroImportQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+	return &mcp.Tool{
+		Name:        "zotero-import",
+		Description: "Bulk-parse the PDF attachments of a Zotero collection or search query. Documents already in the store are skipped; the rest are parsed concurrently. Use max_documents to cap how many are parsed per call (call again to continue) and dry_run to preview what would be parsed without calling the LLM.",
+		InputSchema: inputschema,
+	}
+}
+
+func ZoteroImportToolHandler(ctx context.Context, req *mcp.CallToolRequest, query ZoteroImportQuery, store storage.Store, log logger.Logger) (*mcp.CallToolResult, *ZoteroImportResponse, error) {
+	log.Info("zotero-import tool called")
+
+	zoteroAPIKey, err := config.FromContext(ctx).ZoteroAPIKey()
+	if err != nil {
+		return errorResult(err, models.ErrorConfiguration), nil, nil
+	}
+
+	library, err := documents.ResolveZoteroLibrary(ctx, query.LibraryType, query.LibraryID)
+	if err != nil {
+		return errorResult(err, models.ErrorInvalidInput), nil, nil
+	}
+
+	importParams := operations.ZoteroImportParams{
+		Collection:   query.Collection,
+		Query:        query.Query,
+		Limit:        query.Limit,
+		MaxDocuments: query.MaxDocuments,
+		DryRun:       query.DryRun,
+	}
+
+	result, err := operations.ImportZoteroDocuments(ctx, zoteroAPIKey, library, importParams, store, log)
+	if err != nil {
+		return errorResult(err, models.ErrorUpstreamZotero), nil, nil
+	}
+
+	if ctx.Err() != nil {
+		log.Error("zotero-import tool cancelled: %v", ctx.Err())
+		return errorResult(ctx.Err(), models.ErrorCancelled), nil, nil
+	}
+
+	response := &ZoteroImportResponse{
+		Imported:       convertImportItems(result.Imported),
+		Skipped:        convertImportItems(result.Skipped),
+		Failed:         convertImportItems(result.Failed),
+		WouldImport:    convertImportItems(result.Pending),
+		ImportedCount:  len(result.Imported),
+		SkippedCount:   len(result.Skipped),
+		FailedCount:    len(result.Failed),
+		RemainingCount: result.Remaining,
+		DryRun:         query.DryRun,
+	}
+
+	return nil, response, nil
+}
+
+// convertImportItems converts internal import items to the tool response format
+func convertImportItems(items []operations.ZoteroImportItem) []ZoteroImportItem {
+	if len(items) == 0 {
+		return nil
+	}
+	converted := make([]ZoteroImportItem, len(items))
+	for i, item := range items {
+		converted[i] = ZoteroImportItem{
+			ItemKey:       item.ItemKey,
+			AttachmentKey: item.AttachmentKey,
+			Title:         item.Title,
+			DocumentID:    item.DocumentID,
+			Citekey:       item.Citekey,
+			Reason:        item.Reason,
+			ErrorDetail:   toolError(item.Err, models.ErrorInternal),
+		}
+	}
+	return converted
+}
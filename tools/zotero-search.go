@@ -2,24 +2,30 @@ package tools
 
 import (
 	"context"
-	"fmt"
-	"os"
-
 	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/Epistemic-Technology/academic-mcp/internal/config"
+	"github.com/Epistemic-Technology/academic-mcp/internal/documents"
 	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
 	"github.com/Epistemic-Technology/academic-mcp/internal/operations"
 	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
 )
 
 type ZoteroSearchQuery struct {
 	Query      string   `json:"query,omitempty"`      // Quick search text (searches title, creator, year)
+	DOI        string   `json:"doi,omitempty"`        // Find the items with this DOI (instead of query)
+	ISBN       string   `json:"isbn,omitempty"`       // Find the items with this ISBN-10 or ISBN-13 (instead of query)
 	Tags       []string `json:"tags,omitempty"`       // Filter by tags
 	ItemTypes  []string `json:"item_types,omitempty"` // Filter by type (e.g., "book", "article", "-attachment")
 	Collection string   `json:"collection,omitempty"` // Filter by collection key (optional)
 	Limit      int      `json:"limit,omitempty"`      // Max results (default 25)
 	Sort       string   `json:"sort,omitempty"`       // Sort field (default "dateModified")
+	Refresh    bool     `json:"refresh,omitempty"`    // Fetch attachment listings from Zotero instead of the cache
+	// Library selection (defaults to ZOTERO_LIBRARY_TYPE / ZOTERO_LIBRARY_ID)
+	LibraryType string `json:"library_type,omitempty"` // "user" or "group"
+	LibraryID   string `json:"library_id,omitempty"`   // User or group library ID
 }
 
 type ZoteroSearchResponse struct {
@@ -51,7 +57,7 @@ func ZoteroSearchTool() *mcp.Tool {
 	}
 	return &mcp.Tool{
 		Name:        "zotero-search",
-		Description: "Search for items in a Zotero library and retrieve their metadata and attachment information. Returns bibliographic items with their associated file attachments (PDFs, etc.). Use the attachment keys with document-parse to analyze specific files.",
+		Description: "Search for items in a Zotero library and retrieve their metadata and attachment information. Returns bibliographic items with their associated file attachments (PDFs, etc.). Use the attachment keys with document-parse to analyze specific files. To check whether a known work is in the library, search by doi or isbn instead of query: the identifier is matched against the DOI and ISBN fields and the Extra field. Attachment listings are cached for an hour by default; set refresh to fetch them from Zotero again.",
 		InputSchema: inputschema,
 	}
 }
@@ -59,50 +65,37 @@ func ZoteroSearchTool() *mcp.Tool {
 func ZoteroSearchToolHandler(ctx context.Context, req *mcp.CallToolRequest, query ZoteroSearchQuery, store storage.Store, log logger.Logger) (*mcp.CallToolResult, *ZoteroSearchResponse, error) {
 	log.Info("zotero-search tool called")
 
-	// Get Zotero credentials from environment
-	zoteroAPIKey := os.Getenv("ZOTERO_API_KEY")
-	if zoteroAPIKey == "" {
-		return nil, nil, fmt.Errorf("ZOTERO_API_KEY environment variable not set")
+	zoteroAPIKey, err := config.FromContext(ctx).ZoteroAPIKey()
+	if err != nil {
+		return errorResult(err, models.ErrorConfiguration), nil, nil
 	}
 
-	libraryID := os.Getenv("ZOTERO_LIBRARY_ID")
-	if libraryID == "" {
-		return nil, nil, fmt.Errorf("ZOTERO_LIBRARY_ID environment variable not set")
+	library, err := documents.ResolveZoteroLibrary(ctx, query.LibraryType, query.LibraryID)
+	if err != nil {
+		return errorResult(err, models.ErrorInvalidInput), nil, nil
 	}
 
 	// Convert tool query parameters to operations parameters
 	searchParams := operations.ZoteroSearchParams{
 		Query:      query.Query,
+		DOI:        query.DOI,
+		ISBN:       query.ISBN,
 		Tags:       query.Tags,
 		ItemTypes:  query.ItemTypes,
 		Collection: query.Collection,
 		Limit:      query.Limit,
 		Sort:       query.Sort,
+		Refresh:    query.Refresh,
 	}
 
 	// Execute search using internal operation
-	items, err := operations.SearchZotero(ctx, zoteroAPIKey, libraryID, searchParams, log)
+	items, err := operations.SearchZotero(ctx, zoteroAPIKey, library, searchParams, store, log)
 	if err != nil {
-		return nil, nil, err
+		return errorResult(err, models.ErrorUpstreamZotero), nil, nil
 	}
 
 	// Get existing citekeys for all documents
-	citekeyMap, err := store.GetCitekeyMap(ctx)
-	if err != nil {
-		log.Error("Failed to retrieve citekey map: %v", err)
-		// Don't fail the whole request, just skip citekey enrichment
-		citekeyMap = make(map[string]string)
-	}
-
-	// Build a reverse map from zotero_id to citekey
-	zoteroToCitekey := make(map[string]string)
-	for docID, citekey := range citekeyMap {
-		// Extract zotero ID from document ID (format: "zotero_XXXXX")
-		if len(docID) > 7 && docID[:7] == "zotero_" {
-			zoteroID := docID[7:]
-			zoteroToCitekey[zoteroID] = citekey
-		}
-	}
+	citekeyMap := zoteroCitekeyMap(ctx, store, log)
 
 	// Convert internal results to tool response format
 	results := make([]ZoteroItemResult, len(items))
@@ -114,19 +107,7 @@ func ZoteroSearchToolHandler(ctx context.Context, req *mcp.CallToolRequest, quer
 			ItemType: item.ItemType,
 			Date:     item.Date,
 		}
-		// Convert attachments and check for citekeys
-		for _, att := range item.Attachments {
-			results[i].Attachments = append(results[i].Attachments, AttachmentInfo{
-				Key:         att.Key,
-				Filename:    att.Filename,
-				ContentType: att.ContentType,
-				LinkMode:    att.LinkMode,
-			})
-			// If this attachment has been parsed, add citekey to the result
-			if citekey, found := zoteroToCitekey[att.Key]; found {
-				results[i].Citekey = citekey
-			}
-		}
+		results[i].Attachments, results[i].Citekey = convertAttachments(ctx, item.Attachments, library, citekeyMap)
 	}
 
 	response := &ZoteroSearchResponse{
@@ -136,3 +117,39 @@ func ZoteroSearchToolHandler(ctx context.Context, req *mcp.CallToolRequest, quer
 
 	return nil, response, nil
 }
+
+// zoteroCitekeyMap returns the citekeys of the stored documents, or an empty
+// map if they cannot be read, since citekeys only enrich a Zotero response
+func zoteroCitekeyMap(ctx context.Context, store storage.Store, log logger.Logger) map[string]string {
+	citekeyMap, err := store.GetCitekeyMap(ctx, "")
+	if err != nil {
+		log.Error("Failed to retrieve citekey map: %v", err)
+		return make(map[string]string)
+	}
+	return citekeyMap
+}
+
+// convertAttachments converts attachments to the tool response format and
+// returns the citekey of the one that has been parsed, if any
+func convertAttachments(ctx context.Context, attachments []operations.AttachmentInfo, library models.ZoteroLibrary, citekeyMap map[string]string) ([]AttachmentInfo, string) {
+	var results []AttachmentInfo
+	var citekey string
+	for _, att := range attachments {
+		results = append(results, AttachmentInfo{
+			Key:         att.Key,
+			Filename:    att.Filename,
+			ContentType: att.ContentType,
+			LinkMode:    att.LinkMode,
+		})
+		// If this attachment has been parsed, add citekey to the result
+		attachmentDocID := storage.GenerateDocumentID(documents.DocumentIDSource(ctx, &models.SourceInfo{
+			ZoteroID:          att.Key,
+			ZoteroLibraryType: library.Type,
+			ZoteroLibraryID:   library.ID,
+		}), models.DocumentData{})
+		if found, ok := citekeyMap[attachmentDocID]; ok {
+			citekey = found
+		}
+	}
+	return results, citekey
+}
@@ -0,0 +1,117 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestZoteroSearchToolHandler_GroupLibrary(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/children") {
+			w.Write([]byte(`[{"key":"ATT1","data":{"key":"ATT1","itemType":"attachment","contentType":"application/pdf"}}]`))
+			return
+		}
+		w.Write([]byte(`[{"key":"ITEM1","data":{"key":"ITEM1","itemType":"journalArticle","title":"Group Paper"}}]`))
+	}))
+	defer server.Close()
+
+	t.Setenv("ZOTERO_API_BASE_URL", server.URL)
+	t.Setenv("ZOTERO_API_KEY", "test-key")
+	t.Setenv("ZOTERO_LIBRARY_TYPE", "user")
+	t.Setenv("ZOTERO_LIBRARY_ID", "111")
+
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(":memory:", log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+
+	// The same attachment key parsed from the user library must not match
+	userItem := &models.ParsedItem{Metadata: models.ItemMetadata{Title: "User Paper", Citekey: "user2020"}}
+	if err := store.StoreParsedItem(ctx, "zotero_ATT1", userItem, &models.SourceInfo{ZoteroID: "ATT1"}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+	groupItem := &models.ParsedItem{Metadata: models.ItemMetadata{Title: "Group Paper", Citekey: "group2021"}}
+	if err := store.StoreParsedItem(ctx, "zotero_group_222_ATT1", groupItem, &models.SourceInfo{ZoteroID: "ATT1"}); err != nil {
+		t.Fatalf("Failed to store document: %v", err)
+	}
+
+	query := ZoteroSearchQuery{LibraryType: "group", LibraryID: "222"}
+	_, resp, err := ZoteroSearchToolHandler(ctx, nil, query, store, log)
+	if err != nil {
+		t.Fatalf("ZoteroSearchToolHandler failed: %v", err)
+	}
+
+	for _, path := range paths {
+		if !strings.HasPrefix(path, "/groups/222/") {
+			t.Errorf("Expected request to group library, got %s", path)
+		}
+	}
+
+	if resp.Count != 1 {
+		t.Fatalf("Expected 1 item, got %d", resp.Count)
+	}
+	if resp.Items[0].Citekey != "group2021" {
+		t.Errorf("Expected citekey from group library document, got %q", resp.Items[0].Citekey)
+	}
+}
+
+func TestZoteroSearchToolHandler_ErrorCodes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	t.Setenv("ZOTERO_API_BASE_URL", server.URL)
+	t.Setenv("ZOTERO_LIBRARY_TYPE", "user")
+	t.Setenv("ZOTERO_LIBRARY_ID", "111")
+
+	log := logger.NewNoOpLogger()
+	store, err := storage.NewSQLiteStore(":memory:", log)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	tests := []struct {
+		name     string
+		apiKey   string
+		query    ZoteroSearchQuery
+		expected models.ErrorCode
+	}{
+		{"Missing API key", "", ZoteroSearchQuery{}, models.ErrorConfiguration},
+		{"Invalid library type", "test-key", ZoteroSearchQuery{LibraryType: "team"}, models.ErrorInvalidInput},
+		{"Zotero failure", "test-key", ZoteroSearchQuery{Query: "soil"}, models.ErrorUpstreamZotero},
+		{"Invalid DOI", "test-key", ZoteroSearchQuery{DOI: "soil"}, models.ErrorInvalidInput},
+		{"Zotero failure searching by ISBN", "test-key", ZoteroSearchQuery{ISBN: "0-306-40615-2"}, models.ErrorUpstreamZotero},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ZOTERO_API_KEY", tt.apiKey)
+			result, _, err := ZoteroSearchToolHandler(context.Background(), nil, tt.query, store, log)
+			if err != nil {
+				t.Fatalf("Expected an error result, got error: %v", err)
+			}
+			if toolErr := resultError(t, result); toolErr.Code != tt.expected {
+				t.Errorf("Expected %s error, got %+v", tt.expected, toolErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/config"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/operations"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+type ZoteroTagQuery struct {
+	DocumentIDs []string `json:"document_ids"`      // Parsed documents that originated from Zotero attachments
+	Add         []string `json:"add,omitempty"`     // Tags to add to each document's Zotero item
+	Remove      []string `json:"remove,omitempty"`  // Tags to remove from each document's Zotero item
+	DryRun      bool     `json:"dry_run,omitempty"` // Only report what would change
+}
+
+type ZoteroTagResult struct {
+	DocumentID  string            `json:"document_id"`
+	ItemKey     string            `json:"item_key,omitempty"`
+	Added       []string          `json:"added,omitempty"`
+	Removed     []string          `json:"removed,omitempty"`
+	Tags        []string          `json:"tags,omitempty"` // The item's tags after the change
+	Error       string            `json:"error,omitempty"`
+	ErrorDetail *models.ToolError `json:"error_detail,omitempty"` // Machine-readable code and message for error
+}
+
+type ZoteroTagResponse struct {
+	Results []ZoteroTagResult `json:"results"`
+	Count   int               `json:"count"`
+	DryRun  bool              `json:"dry_run,omitempty"`
+}
+
+func ZoteroTagTool() *mcp.Tool {
+	inputschema, err := jsonschema.For[ZoteroTagQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+	return &mcp.Tool{
+		Name:        "zotero-tag",
+		Description: "Add and remove tags on the parent Zotero items of documents parsed from Zotero attachments, e.g. to tag everything summarized this week as 'reviewed'. Other tags are kept, and a change made in Zotero at the same time is not overwritten. Each result gives the item's tags afterwards, which are also stored with the document (see document-list's tags filter). Use dry_run to preview the changes. Requires a Zotero API key with write access.",
+		InputSchema: inputschema,
+	}
+}
+
+func ZoteroTagToolHandler(ctx context.Context, req *mcp.CallToolRequest, query ZoteroTagQuery, store storage.Store, log logger.Logger) (*mcp.CallToolResult, *ZoteroTagResponse, error) {
+	log.Info("zotero-tag tool called")
+
+	if len(query.DocumentIDs) == 0 {
+		return errorResult(errors.New("document_ids is required"), models.ErrorInvalidInput), nil, nil
+	}
+	if len(query.Add) == 0 && len(query.Remove) == 0 {
+		return errorResult(errors.New("add or remove is required"), models.ErrorInvalidInput), nil, nil
+	}
+	params := operations.ZoteroTagParams{DryRun: query.DryRun}
+	for _, tag := range query.Add {
+		if strings.TrimSpace(tag) == "" {
+			return errorResult(errors.New("tags must not be blank"), models.ErrorInvalidInput), nil, nil
+		}
+		params.Add = append(params.Add, strings.TrimSpace(tag))
+	}
+	for _, tag := range query.Remove {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			return errorResult(errors.New("tags must not be blank"), models.ErrorInvalidInput), nil, nil
+		}
+		if slices.Contains(params.Add, tag) {
+			return errorResult(fmt.Errorf("tag %q cannot be both added and removed", tag), models.ErrorInvalidInput), nil, nil
+		}
+		params.Remove = append(params.Remove, tag)
+	}
+
+	zoteroAPIKey, err := config.FromContext(ctx).ZoteroAPIKey()
+	if err != nil {
+		return errorResult(err, models.ErrorConfiguration), nil, nil
+	}
+
+	// Process sequentially; Zotero write requests are rate limited per library
+	results := make([]ZoteroTagResult, len(query.DocumentIDs))
+	for i, docID := range query.DocumentIDs {
+		if ctx.Err() != nil {
+			log.Error("zotero-tag tool cancelled: %v", ctx.Err())
+			return errorResult(ctx.Err(), models.ErrorCancelled), nil, nil
+		}
+
+		result, err := operations.TagZoteroItem(ctx, zoteroAPIKey, docID, params, store, log)
+		if err != nil {
+			log.Error("Failed to tag document %s: %v", docID, err)
+			results[i] = ZoteroTagResult{
+				DocumentID:  docID,
+				Error:       fmt.Sprintf("failed to tag: %v", err),
+				ErrorDetail: toolError(err, models.ErrorUpstreamZotero),
+			}
+			continue
+		}
+
+		results[i] = ZoteroTagResult{
+			DocumentID: result.DocumentID,
+			ItemKey:    result.ItemKey,
+			Added:      result.Added,
+			Removed:    result.Removed,
+			Tags:       result.Tags,
+		}
+	}
+
+	return nil, &ZoteroTagResponse{Results: results, Count: len(results), DryRun: query.DryRun}, nil
+}
@@ -0,0 +1,35 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+func TestZoteroTagToolHandler_InvalidInput(t *testing.T) {
+	t.Setenv("ZOTERO_API_KEY", "test-key")
+	log := logger.NewNoOpLogger()
+
+	tests := []struct {
+		name  string
+		query ZoteroTagQuery
+	}{
+		{"no documents", ZoteroTagQuery{Add: []string{"reviewed"}}},
+		{"no tags", ZoteroTagQuery{DocumentIDs: []string{"zotero_ATT1"}}},
+		{"blank tag", ZoteroTagQuery{DocumentIDs: []string{"zotero_ATT1"}, Add: []string{" "}}},
+		{"added and removed", ZoteroTagQuery{DocumentIDs: []string{"zotero_ATT1"}, Add: []string{"reviewed"}, Remove: []string{"reviewed "}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := ZoteroTagToolHandler(context.Background(), nil, tt.query, nil, log)
+			if err != nil {
+				t.Fatalf("Expected an error result, got error: %v", err)
+			}
+			if toolErr := resultError(t, result); toolErr.Code != models.ErrorInvalidInput {
+				t.Errorf("Expected invalid_input, got %+v", toolErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/Epistemic-Technology/academic-mcp/internal/config"
+	"github.com/Epistemic-Technology/academic-mcp/internal/logger"
+	"github.com/Epistemic-Technology/academic-mcp/internal/operations"
+	"github.com/Epistemic-Technology/academic-mcp/internal/storage"
+	"github.com/Epistemic-Technology/academic-mcp/models"
+)
+
+type ZoteroWritebackQuery struct {
+	DocumentIDs      []string `json:"document_ids"`                 // Parsed documents that originated from Zotero attachments
+	WriteSummaryNote *bool    `json:"write_summary_note,omitempty"` // Attach the stored summary as a child note (default: true)
+	DryRun           bool     `json:"dry_run,omitempty"`            // Only report what would be written
+}
+
+type ZoteroFieldChange struct {
+	Field string `json:"field"`
+	Value string `json:"value"`
+}
+
+type ZoteroWritebackResult struct {
+	DocumentID    string              `json:"document_id"`
+	ItemKey       string              `json:"item_key,omitempty"`
+	FieldsWritten []ZoteroFieldChange `json:"fields_written,omitempty"`
+	NoteCreated   bool                `json:"note_created"`
+	NoteKey       string              `json:"note_key,omitempty"`
+	Skipped       []string            `json:"skipped,omitempty"`
+	Error         string              `json:"error,omitempty"`
+	ErrorDetail   *models.ToolError   `json:"error_detail,omitempty"` // Machine-readable code and message for error
+}
+
+type ZoteroWritebackResponse struct {
+	Results []ZoteroWritebackResult `json:"results"`
+	Count   int                     `json:"count"`
+	DryRun  bool                    `json:"dry_run,omitempty"`
+}
+
+func ZoteroWritebackTool() *mcp.Tool {
+	inputschema, err := jsonschema.For[ZoteroWritebackQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+	return &mcp.Tool{
+		Name:        "zotero-writeback",
+		Description: "Write extracted metadata back to Zotero for documents parsed from Zotero attachments. Fills the parent item's DOI and abstract only when they are empty in Zotero (existing values are never overwritten) and attaches the stored summary as a child note. Use dry_run to preview the changes. Requires a Zotero API key with write access.",
+		InputSchema: inputschema,
+	}
+}
+
+func ZoteroWritebackToolHandler(ctx context.Context, req *mcp.CallToolRequest, query ZoteroWritebackQuery, store storage.Store, log logger.Logger) (*mcp.CallToolResult, *ZoteroWritebackResponse, error) {
+	log.Info("zotero-writeback tool called")
+
+	if len(query.DocumentIDs) == 0 {
+		return errorResult(errors.New("document_ids is required"), models.ErrorInvalidInput), nil, nil
+	}
+
+	zoteroAPIKey, err := config.FromContext(ctx).ZoteroAPIKey()
+	if err != nil {
+		return errorResult(err, models.ErrorConfiguration), nil, nil
+	}
+
+	params := operations.ZoteroWritebackParams{
+		WriteSummaryNote: query.WriteSummaryNote == nil || *query.WriteSummaryNote,
+		DryRun:           query.DryRun,
+	}
+
+	// Process sequentially; Zotero write requests are rate limited per library
+	results := make([]ZoteroWritebackResult, len(query.DocumentIDs))
+	for i, docID := range query.DocumentIDs {
+		if ctx.Err() != nil {
+			log.Error("zotero-writeback tool cancelled: %v", ctx.Err())
+			return errorResult(ctx.Err(), models.ErrorCancelled), nil, nil
+		}
+
+		result, err := operations.WritebackToZotero(ctx, zoteroAPIKey, docID, params, store, log)
+		if err != nil {
+			log.Error("Failed to write back document %s: %v", docID, err)
+			results[i] = ZoteroWritebackResult{
+				DocumentID:  docID,
+				Error:       fmt.Sprintf("failed to write back: %v", err),
+				ErrorDetail: toolError(err, models.ErrorUpstreamZotero),
+			}
+			continue
+		}
+
+		results[i] = ZoteroWritebackResult{
+			DocumentID:  result.DocumentID,
+			ItemKey:     result.ItemKey,
+			NoteCreated: result.NoteCreated,
+			NoteKey:     result.NoteKey,
+			Skipped:     result.Skipped,
+		}
+		for _, change := range result.FieldsWritten {
+			results[i].FieldsWritten = append(results[i].FieldsWritten, ZoteroFieldChange{
+				Field: change.Field,
+				Value: change.Value,
+			})
+		}
+		if result.NoteError != nil {
+			results[i].Error = fmt.Sprintf("failed to create summary note: %v", result.NoteError)
+			results[i].ErrorDetail = toolError(result.NoteError, models.ErrorUpstreamZotero)
+		}
+	}
+
+	response := &ZoteroWritebackResponse{
+		Results: results,
+		Count:   len(results),
+		DryRun:  query.DryRun,
+	}
+
+	return nil, response, nil
+}